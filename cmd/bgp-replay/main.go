@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/route"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	speed    = flag.Float64("speed", 0, "Factor the time between messages is scaled by (1 = real time), 0 replays as fast as possible")
+	messages = flag.Bool("messages", false, "Print each message received before it is processed")
+	routes   = flag.Bool("routes", false, "Print the routes learned")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] <recording>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.WithError(err).Fatal("Unable to open session recording")
+	}
+	defer f.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	opt := server.SessionReplayOptions{
+		Speed: *speed,
+	}
+
+	if *messages {
+		opt.MessageHandler = func(ts time.Time, msg []byte) {
+			fmt.Printf("%s %x\n", ts.UTC().Format(time.RFC3339Nano), msg)
+		}
+	}
+
+	res, err := server.ReplaySession(ctx, f, opt)
+	if err != nil {
+		log.WithError(err).Error("Replay failed")
+	}
+
+	if res == nil {
+		os.Exit(1)
+	}
+
+	fmt.Printf("Messages processed: %d\n", res.Messages)
+	fmt.Printf("Established: %v\n", res.Established)
+	if res.Reason != "" {
+		fmt.Printf("Reason: %s\n", res.Reason)
+	}
+
+	if n := res.Notification; n != nil {
+		fmt.Printf("NOTIFICATION: %s\n", n.Description())
+	}
+
+	fmt.Printf("IPv4 routes: %d\n", len(res.IPv4Routes))
+	fmt.Printf("IPv6 routes: %d\n", len(res.IPv6Routes))
+
+	if *routes {
+		printRoutes(res.IPv4Routes)
+		printRoutes(res.IPv6Routes)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func printRoutes(routes []*route.Route) {
+	for _, r := range routes {
+		fmt.Print(r.Print())
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+
+	bgpapi "github.com/bio-routing/bio-rd/protocols/bgp/api"
+	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// instanceMetadataKey is the gRPC metadata key used to address a routing instance.
+	// Requests without this key are served by the default instance.
+	instanceMetadataKey = "instance"
+)
+
+// bgpAPIRouter dispatches BGP API calls to the BGP API server of the addressed routing instance
+type bgpAPIRouter struct {
+	instances *instanceRegistry
+}
+
+func newBGPAPIRouter(r *instanceRegistry) *bgpAPIRouter {
+	return &bgpAPIRouter{
+		instances: r,
+	}
+}
+
+func (r *bgpAPIRouter) instance(ctx context.Context) (*routingInstance, error) {
+	name := defaultInstanceName
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(instanceMetadataKey); len(v) > 0 {
+			name = v[0]
+		}
+	}
+
+	ri := r.instances.get(name)
+	if ri == nil {
+		return nil, status.Errorf(codes.NotFound, "instance %q not found", name)
+	}
+
+	return ri, nil
+}
+
+func (r *bgpAPIRouter) bgpAPI(ctx context.Context) (*bgpserver.BGPAPIServer, error) {
+	ri, err := r.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, api := ri.bgp()
+	if api == nil {
+		return nil, status.Errorf(codes.Unavailable, "BGP is disabled in instance %q", ri.name)
+	}
+
+	return api, nil
+}
+
+func (r *bgpAPIRouter) ListSessions(ctx context.Context, in *bgpapi.ListSessionsRequest) (*bgpapi.ListSessionsResponse, error) {
+	api, err := r.bgpAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.ListSessions(ctx, in)
+}
+
+func (r *bgpAPIRouter) DumpRIBIn(in *bgpapi.DumpRIBRequest, stream bgpapi.BgpService_DumpRIBInServer) error {
+	api, err := r.bgpAPI(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return api.DumpRIBIn(in, stream)
+}
+
+func (r *bgpAPIRouter) DumpRIBOut(in *bgpapi.DumpRIBRequest, stream bgpapi.BgpService_DumpRIBOutServer) error {
+	api, err := r.bgpAPI(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return api.DumpRIBOut(in, stream)
+}
+
+func (r *bgpAPIRouter) LookupRoutes(ctx context.Context, in *bgpapi.LookupRoutesRequest) (*bgpapi.LookupRoutesResponse, error) {
+	api, err := r.bgpAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.LookupRoutes(ctx, in)
+}
@@ -0,0 +1,2427 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: github.com/bio-routing/bio-rd/cmd/bio-rd/api/management.proto
+
+package api
+
+import (
+	context "context"
+	fmt "fmt"
+	api "github.com/bio-routing/bio-rd/net/api"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type SaveConfigRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SaveConfigRequest) Reset()         { *m = SaveConfigRequest{} }
+func (m *SaveConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*SaveConfigRequest) ProtoMessage()    {}
+func (*SaveConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{0}
+}
+
+func (m *SaveConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SaveConfigRequest.Unmarshal(m, b)
+}
+func (m *SaveConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SaveConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *SaveConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SaveConfigRequest.Merge(m, src)
+}
+func (m *SaveConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_SaveConfigRequest.Size(m)
+}
+func (m *SaveConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SaveConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SaveConfigRequest proto.InternalMessageInfo
+
+type SaveConfigResponse struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SaveConfigResponse) Reset()         { *m = SaveConfigResponse{} }
+func (m *SaveConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*SaveConfigResponse) ProtoMessage()    {}
+func (*SaveConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{1}
+}
+
+func (m *SaveConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SaveConfigResponse.Unmarshal(m, b)
+}
+func (m *SaveConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SaveConfigResponse.Marshal(b, m, deterministic)
+}
+func (m *SaveConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SaveConfigResponse.Merge(m, src)
+}
+func (m *SaveConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_SaveConfigResponse.Size(m)
+}
+func (m *SaveConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SaveConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SaveConfigResponse proto.InternalMessageInfo
+
+func (m *SaveConfigResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+type SetProtocolStateRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Protocol             string   `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Enabled              bool     `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetProtocolStateRequest) Reset()         { *m = SetProtocolStateRequest{} }
+func (m *SetProtocolStateRequest) String() string { return proto.CompactTextString(m) }
+func (*SetProtocolStateRequest) ProtoMessage()    {}
+func (*SetProtocolStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{2}
+}
+
+func (m *SetProtocolStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetProtocolStateRequest.Unmarshal(m, b)
+}
+func (m *SetProtocolStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetProtocolStateRequest.Marshal(b, m, deterministic)
+}
+func (m *SetProtocolStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetProtocolStateRequest.Merge(m, src)
+}
+func (m *SetProtocolStateRequest) XXX_Size() int {
+	return xxx_messageInfo_SetProtocolStateRequest.Size(m)
+}
+func (m *SetProtocolStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetProtocolStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetProtocolStateRequest proto.InternalMessageInfo
+
+func (m *SetProtocolStateRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *SetProtocolStateRequest) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *SetProtocolStateRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type SetProtocolStateResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetProtocolStateResponse) Reset()         { *m = SetProtocolStateResponse{} }
+func (m *SetProtocolStateResponse) String() string { return proto.CompactTextString(m) }
+func (*SetProtocolStateResponse) ProtoMessage()    {}
+func (*SetProtocolStateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{3}
+}
+
+func (m *SetProtocolStateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetProtocolStateResponse.Unmarshal(m, b)
+}
+func (m *SetProtocolStateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetProtocolStateResponse.Marshal(b, m, deterministic)
+}
+func (m *SetProtocolStateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetProtocolStateResponse.Merge(m, src)
+}
+func (m *SetProtocolStateResponse) XXX_Size() int {
+	return xxx_messageInfo_SetProtocolStateResponse.Size(m)
+}
+func (m *SetProtocolStateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetProtocolStateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetProtocolStateResponse proto.InternalMessageInfo
+
+type GetLogLevelsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLogLevelsRequest) Reset()         { *m = GetLogLevelsRequest{} }
+func (m *GetLogLevelsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLogLevelsRequest) ProtoMessage()    {}
+func (*GetLogLevelsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{4}
+}
+
+func (m *GetLogLevelsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogLevelsRequest.Unmarshal(m, b)
+}
+func (m *GetLogLevelsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogLevelsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLogLevelsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogLevelsRequest.Merge(m, src)
+}
+func (m *GetLogLevelsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLogLevelsRequest.Size(m)
+}
+func (m *GetLogLevelsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogLevelsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogLevelsRequest proto.InternalMessageInfo
+
+type GetLogLevelsResponse struct {
+	Levels               map[string]string `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *GetLogLevelsResponse) Reset()         { *m = GetLogLevelsResponse{} }
+func (m *GetLogLevelsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLogLevelsResponse) ProtoMessage()    {}
+func (*GetLogLevelsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{5}
+}
+
+func (m *GetLogLevelsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogLevelsResponse.Unmarshal(m, b)
+}
+func (m *GetLogLevelsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogLevelsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetLogLevelsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogLevelsResponse.Merge(m, src)
+}
+func (m *GetLogLevelsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLogLevelsResponse.Size(m)
+}
+func (m *GetLogLevelsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogLevelsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogLevelsResponse proto.InternalMessageInfo
+
+func (m *GetLogLevelsResponse) GetLevels() map[string]string {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+type SetLogLevelRequest struct {
+	Subsystem            string   `protobuf:"bytes,1,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+	Level                string   `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetLogLevelRequest) Reset()         { *m = SetLogLevelRequest{} }
+func (m *SetLogLevelRequest) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelRequest) ProtoMessage()    {}
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{6}
+}
+
+func (m *SetLogLevelRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLogLevelRequest.Unmarshal(m, b)
+}
+func (m *SetLogLevelRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLogLevelRequest.Marshal(b, m, deterministic)
+}
+func (m *SetLogLevelRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLogLevelRequest.Merge(m, src)
+}
+func (m *SetLogLevelRequest) XXX_Size() int {
+	return xxx_messageInfo_SetLogLevelRequest.Size(m)
+}
+func (m *SetLogLevelRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLogLevelRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLogLevelRequest proto.InternalMessageInfo
+
+func (m *SetLogLevelRequest) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+func (m *SetLogLevelRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type SetLogLevelResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetLogLevelResponse) Reset()         { *m = SetLogLevelResponse{} }
+func (m *SetLogLevelResponse) String() string { return proto.CompactTextString(m) }
+func (*SetLogLevelResponse) ProtoMessage()    {}
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{7}
+}
+
+func (m *SetLogLevelResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetLogLevelResponse.Unmarshal(m, b)
+}
+func (m *SetLogLevelResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetLogLevelResponse.Marshal(b, m, deterministic)
+}
+func (m *SetLogLevelResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetLogLevelResponse.Merge(m, src)
+}
+func (m *SetLogLevelResponse) XXX_Size() int {
+	return xxx_messageInfo_SetLogLevelResponse.Size(m)
+}
+func (m *SetLogLevelResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetLogLevelResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetLogLevelResponse proto.InternalMessageInfo
+
+type CaptureBGPRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CaptureBGPRequest) Reset()         { *m = CaptureBGPRequest{} }
+func (m *CaptureBGPRequest) String() string { return proto.CompactTextString(m) }
+func (*CaptureBGPRequest) ProtoMessage()    {}
+func (*CaptureBGPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{8}
+}
+
+func (m *CaptureBGPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CaptureBGPRequest.Unmarshal(m, b)
+}
+func (m *CaptureBGPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CaptureBGPRequest.Marshal(b, m, deterministic)
+}
+func (m *CaptureBGPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CaptureBGPRequest.Merge(m, src)
+}
+func (m *CaptureBGPRequest) XXX_Size() int {
+	return xxx_messageInfo_CaptureBGPRequest.Size(m)
+}
+func (m *CaptureBGPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CaptureBGPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CaptureBGPRequest proto.InternalMessageInfo
+
+func (m *CaptureBGPRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *CaptureBGPRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+type CaptureData struct {
+	Pcap                 []byte   `protobuf:"bytes,1,opt,name=pcap,proto3" json:"pcap,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CaptureData) Reset()         { *m = CaptureData{} }
+func (m *CaptureData) String() string { return proto.CompactTextString(m) }
+func (*CaptureData) ProtoMessage()    {}
+func (*CaptureData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{9}
+}
+
+func (m *CaptureData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CaptureData.Unmarshal(m, b)
+}
+func (m *CaptureData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CaptureData.Marshal(b, m, deterministic)
+}
+func (m *CaptureData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CaptureData.Merge(m, src)
+}
+func (m *CaptureData) XXX_Size() int {
+	return xxx_messageInfo_CaptureData.Size(m)
+}
+func (m *CaptureData) XXX_DiscardUnknown() {
+	xxx_messageInfo_CaptureData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CaptureData proto.InternalMessageInfo
+
+func (m *CaptureData) GetPcap() []byte {
+	if m != nil {
+		return m.Pcap
+	}
+	return nil
+}
+
+type GetEventsRequest struct {
+	Subsystem            string   `protobuf:"bytes,1,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetEventsRequest) Reset()         { *m = GetEventsRequest{} }
+func (m *GetEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetEventsRequest) ProtoMessage()    {}
+func (*GetEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{10}
+}
+
+func (m *GetEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEventsRequest.Unmarshal(m, b)
+}
+func (m *GetEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEventsRequest.Merge(m, src)
+}
+func (m *GetEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetEventsRequest.Size(m)
+}
+func (m *GetEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEventsRequest proto.InternalMessageInfo
+
+func (m *GetEventsRequest) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+type GetEventsResponse struct {
+	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetEventsResponse) Reset()         { *m = GetEventsResponse{} }
+func (m *GetEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetEventsResponse) ProtoMessage()    {}
+func (*GetEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{11}
+}
+
+func (m *GetEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEventsResponse.Unmarshal(m, b)
+}
+func (m *GetEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEventsResponse.Merge(m, src)
+}
+func (m *GetEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetEventsResponse.Size(m)
+}
+func (m *GetEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEventsResponse proto.InternalMessageInfo
+
+func (m *GetEventsResponse) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type Event struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Subsystem            string   `protobuf:"bytes,2,opt,name=subsystem,proto3" json:"subsystem,omitempty"`
+	Object               string   `protobuf:"bytes,3,opt,name=object,proto3" json:"object,omitempty"`
+	Message              string   `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{12}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Event) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+func (m *Event) GetObject() string {
+	if m != nil {
+		return m.Object
+	}
+	return ""
+}
+
+func (m *Event) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Event) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type GetFlightRecorderRequest struct {
+	Protocol             string   `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Object               string   `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetFlightRecorderRequest) Reset()         { *m = GetFlightRecorderRequest{} }
+func (m *GetFlightRecorderRequest) String() string { return proto.CompactTextString(m) }
+func (*GetFlightRecorderRequest) ProtoMessage()    {}
+func (*GetFlightRecorderRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{13}
+}
+
+func (m *GetFlightRecorderRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetFlightRecorderRequest.Unmarshal(m, b)
+}
+func (m *GetFlightRecorderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetFlightRecorderRequest.Marshal(b, m, deterministic)
+}
+func (m *GetFlightRecorderRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetFlightRecorderRequest.Merge(m, src)
+}
+func (m *GetFlightRecorderRequest) XXX_Size() int {
+	return xxx_messageInfo_GetFlightRecorderRequest.Size(m)
+}
+func (m *GetFlightRecorderRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetFlightRecorderRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetFlightRecorderRequest proto.InternalMessageInfo
+
+func (m *GetFlightRecorderRequest) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *GetFlightRecorderRequest) GetObject() string {
+	if m != nil {
+		return m.Object
+	}
+	return ""
+}
+
+type GetFlightRecorderResponse struct {
+	Records              []*FlightRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *GetFlightRecorderResponse) Reset()         { *m = GetFlightRecorderResponse{} }
+func (m *GetFlightRecorderResponse) String() string { return proto.CompactTextString(m) }
+func (*GetFlightRecorderResponse) ProtoMessage()    {}
+func (*GetFlightRecorderResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{14}
+}
+
+func (m *GetFlightRecorderResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetFlightRecorderResponse.Unmarshal(m, b)
+}
+func (m *GetFlightRecorderResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetFlightRecorderResponse.Marshal(b, m, deterministic)
+}
+func (m *GetFlightRecorderResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetFlightRecorderResponse.Merge(m, src)
+}
+func (m *GetFlightRecorderResponse) XXX_Size() int {
+	return xxx_messageInfo_GetFlightRecorderResponse.Size(m)
+}
+func (m *GetFlightRecorderResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetFlightRecorderResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetFlightRecorderResponse proto.InternalMessageInfo
+
+func (m *GetFlightRecorderResponse) GetRecords() []*FlightRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+type FlightRecord struct {
+	Protocol             string        `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Object               string        `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	Transitions          []*Transition `protobuf:"bytes,3,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	Scope                string        `protobuf:"bytes,4,opt,name=scope,proto3" json:"scope,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *FlightRecord) Reset()         { *m = FlightRecord{} }
+func (m *FlightRecord) String() string { return proto.CompactTextString(m) }
+func (*FlightRecord) ProtoMessage()    {}
+func (*FlightRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{15}
+}
+
+func (m *FlightRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FlightRecord.Unmarshal(m, b)
+}
+func (m *FlightRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FlightRecord.Marshal(b, m, deterministic)
+}
+func (m *FlightRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FlightRecord.Merge(m, src)
+}
+func (m *FlightRecord) XXX_Size() int {
+	return xxx_messageInfo_FlightRecord.Size(m)
+}
+func (m *FlightRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_FlightRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FlightRecord proto.InternalMessageInfo
+
+func (m *FlightRecord) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *FlightRecord) GetObject() string {
+	if m != nil {
+		return m.Object
+	}
+	return ""
+}
+
+func (m *FlightRecord) GetTransitions() []*Transition {
+	if m != nil {
+		return m.Transitions
+	}
+	return nil
+}
+
+func (m *FlightRecord) GetScope() string {
+	if m != nil {
+		return m.Scope
+	}
+	return ""
+}
+
+type Transition struct {
+	TimestampNs          int64    `protobuf:"varint,1,opt,name=timestamp_ns,json=timestampNs,proto3" json:"timestamp_ns,omitempty"`
+	From                 string   `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Transition) Reset()         { *m = Transition{} }
+func (m *Transition) String() string { return proto.CompactTextString(m) }
+func (*Transition) ProtoMessage()    {}
+func (*Transition) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{16}
+}
+
+func (m *Transition) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transition.Unmarshal(m, b)
+}
+func (m *Transition) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transition.Marshal(b, m, deterministic)
+}
+func (m *Transition) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transition.Merge(m, src)
+}
+func (m *Transition) XXX_Size() int {
+	return xxx_messageInfo_Transition.Size(m)
+}
+func (m *Transition) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transition.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transition proto.InternalMessageInfo
+
+func (m *Transition) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *Transition) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Transition) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *Transition) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type SetBGPPeerDebugRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Enabled              bool     `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBGPPeerDebugRequest) Reset()         { *m = SetBGPPeerDebugRequest{} }
+func (m *SetBGPPeerDebugRequest) String() string { return proto.CompactTextString(m) }
+func (*SetBGPPeerDebugRequest) ProtoMessage()    {}
+func (*SetBGPPeerDebugRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{17}
+}
+
+func (m *SetBGPPeerDebugRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBGPPeerDebugRequest.Unmarshal(m, b)
+}
+func (m *SetBGPPeerDebugRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBGPPeerDebugRequest.Marshal(b, m, deterministic)
+}
+func (m *SetBGPPeerDebugRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBGPPeerDebugRequest.Merge(m, src)
+}
+func (m *SetBGPPeerDebugRequest) XXX_Size() int {
+	return xxx_messageInfo_SetBGPPeerDebugRequest.Size(m)
+}
+func (m *SetBGPPeerDebugRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBGPPeerDebugRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBGPPeerDebugRequest proto.InternalMessageInfo
+
+func (m *SetBGPPeerDebugRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *SetBGPPeerDebugRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *SetBGPPeerDebugRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type SetBGPPeerDebugResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBGPPeerDebugResponse) Reset()         { *m = SetBGPPeerDebugResponse{} }
+func (m *SetBGPPeerDebugResponse) String() string { return proto.CompactTextString(m) }
+func (*SetBGPPeerDebugResponse) ProtoMessage()    {}
+func (*SetBGPPeerDebugResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{18}
+}
+
+func (m *SetBGPPeerDebugResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBGPPeerDebugResponse.Unmarshal(m, b)
+}
+func (m *SetBGPPeerDebugResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBGPPeerDebugResponse.Marshal(b, m, deterministic)
+}
+func (m *SetBGPPeerDebugResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBGPPeerDebugResponse.Merge(m, src)
+}
+func (m *SetBGPPeerDebugResponse) XXX_Size() int {
+	return xxx_messageInfo_SetBGPPeerDebugResponse.Size(m)
+}
+func (m *SetBGPPeerDebugResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBGPPeerDebugResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBGPPeerDebugResponse proto.InternalMessageInfo
+
+type ResetBGPCountersRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetBGPCountersRequest) Reset()         { *m = ResetBGPCountersRequest{} }
+func (m *ResetBGPCountersRequest) String() string { return proto.CompactTextString(m) }
+func (*ResetBGPCountersRequest) ProtoMessage()    {}
+func (*ResetBGPCountersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{19}
+}
+
+func (m *ResetBGPCountersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetBGPCountersRequest.Unmarshal(m, b)
+}
+func (m *ResetBGPCountersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetBGPCountersRequest.Marshal(b, m, deterministic)
+}
+func (m *ResetBGPCountersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetBGPCountersRequest.Merge(m, src)
+}
+func (m *ResetBGPCountersRequest) XXX_Size() int {
+	return xxx_messageInfo_ResetBGPCountersRequest.Size(m)
+}
+func (m *ResetBGPCountersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetBGPCountersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetBGPCountersRequest proto.InternalMessageInfo
+
+func (m *ResetBGPCountersRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *ResetBGPCountersRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+type ResetBGPCountersResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetBGPCountersResponse) Reset()         { *m = ResetBGPCountersResponse{} }
+func (m *ResetBGPCountersResponse) String() string { return proto.CompactTextString(m) }
+func (*ResetBGPCountersResponse) ProtoMessage()    {}
+func (*ResetBGPCountersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{20}
+}
+
+func (m *ResetBGPCountersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetBGPCountersResponse.Unmarshal(m, b)
+}
+func (m *ResetBGPCountersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetBGPCountersResponse.Marshal(b, m, deterministic)
+}
+func (m *ResetBGPCountersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetBGPCountersResponse.Merge(m, src)
+}
+func (m *ResetBGPCountersResponse) XXX_Size() int {
+	return xxx_messageInfo_ResetBGPCountersResponse.Size(m)
+}
+func (m *ResetBGPCountersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetBGPCountersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetBGPCountersResponse proto.InternalMessageInfo
+
+type GetLabelAllocationsRequest struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLabelAllocationsRequest) Reset()         { *m = GetLabelAllocationsRequest{} }
+func (m *GetLabelAllocationsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLabelAllocationsRequest) ProtoMessage()    {}
+func (*GetLabelAllocationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{21}
+}
+
+func (m *GetLabelAllocationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLabelAllocationsRequest.Unmarshal(m, b)
+}
+func (m *GetLabelAllocationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLabelAllocationsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLabelAllocationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLabelAllocationsRequest.Merge(m, src)
+}
+func (m *GetLabelAllocationsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLabelAllocationsRequest.Size(m)
+}
+func (m *GetLabelAllocationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLabelAllocationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLabelAllocationsRequest proto.InternalMessageInfo
+
+func (m *GetLabelAllocationsRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type GetLabelAllocationsResponse struct {
+	DynamicLabels        *LabelRange        `protobuf:"bytes,1,opt,name=dynamic_labels,json=dynamicLabels,proto3" json:"dynamic_labels,omitempty"`
+	Srgb                 *LabelRange        `protobuf:"bytes,2,opt,name=srgb,proto3" json:"srgb,omitempty"`
+	Srlb                 *LabelRange        `protobuf:"bytes,3,opt,name=srlb,proto3" json:"srlb,omitempty"`
+	Allocations          []*LabelAllocation `protobuf:"bytes,4,rep,name=allocations,proto3" json:"allocations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *GetLabelAllocationsResponse) Reset()         { *m = GetLabelAllocationsResponse{} }
+func (m *GetLabelAllocationsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLabelAllocationsResponse) ProtoMessage()    {}
+func (*GetLabelAllocationsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{22}
+}
+
+func (m *GetLabelAllocationsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLabelAllocationsResponse.Unmarshal(m, b)
+}
+func (m *GetLabelAllocationsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLabelAllocationsResponse.Marshal(b, m, deterministic)
+}
+func (m *GetLabelAllocationsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLabelAllocationsResponse.Merge(m, src)
+}
+func (m *GetLabelAllocationsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLabelAllocationsResponse.Size(m)
+}
+func (m *GetLabelAllocationsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLabelAllocationsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLabelAllocationsResponse proto.InternalMessageInfo
+
+func (m *GetLabelAllocationsResponse) GetDynamicLabels() *LabelRange {
+	if m != nil {
+		return m.DynamicLabels
+	}
+	return nil
+}
+
+func (m *GetLabelAllocationsResponse) GetSrgb() *LabelRange {
+	if m != nil {
+		return m.Srgb
+	}
+	return nil
+}
+
+func (m *GetLabelAllocationsResponse) GetSrlb() *LabelRange {
+	if m != nil {
+		return m.Srlb
+	}
+	return nil
+}
+
+func (m *GetLabelAllocationsResponse) GetAllocations() []*LabelAllocation {
+	if m != nil {
+		return m.Allocations
+	}
+	return nil
+}
+
+type LabelRange struct {
+	Start                uint32   `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End                  uint32   `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LabelRange) Reset()         { *m = LabelRange{} }
+func (m *LabelRange) String() string { return proto.CompactTextString(m) }
+func (*LabelRange) ProtoMessage()    {}
+func (*LabelRange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{23}
+}
+
+func (m *LabelRange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelRange.Unmarshal(m, b)
+}
+func (m *LabelRange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelRange.Marshal(b, m, deterministic)
+}
+func (m *LabelRange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelRange.Merge(m, src)
+}
+func (m *LabelRange) XXX_Size() int {
+	return xxx_messageInfo_LabelRange.Size(m)
+}
+func (m *LabelRange) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelRange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelRange proto.InternalMessageInfo
+
+func (m *LabelRange) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *LabelRange) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+type LabelAllocation struct {
+	Label                uint32   `protobuf:"varint,1,opt,name=label,proto3" json:"label,omitempty"`
+	Block                string   `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Description          string   `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LabelAllocation) Reset()         { *m = LabelAllocation{} }
+func (m *LabelAllocation) String() string { return proto.CompactTextString(m) }
+func (*LabelAllocation) ProtoMessage()    {}
+func (*LabelAllocation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{24}
+}
+
+func (m *LabelAllocation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelAllocation.Unmarshal(m, b)
+}
+func (m *LabelAllocation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelAllocation.Marshal(b, m, deterministic)
+}
+func (m *LabelAllocation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelAllocation.Merge(m, src)
+}
+func (m *LabelAllocation) XXX_Size() int {
+	return xxx_messageInfo_LabelAllocation.Size(m)
+}
+func (m *LabelAllocation) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelAllocation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelAllocation proto.InternalMessageInfo
+
+func (m *LabelAllocation) GetLabel() uint32 {
+	if m != nil {
+		return m.Label
+	}
+	return 0
+}
+
+func (m *LabelAllocation) GetBlock() string {
+	if m != nil {
+		return m.Block
+	}
+	return ""
+}
+
+func (m *LabelAllocation) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *LabelAllocation) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type SoftResetBGPPeerRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Inbound              bool     `protobuf:"varint,3,opt,name=inbound,proto3" json:"inbound,omitempty"`
+	Outbound             bool     `protobuf:"varint,4,opt,name=outbound,proto3" json:"outbound,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SoftResetBGPPeerRequest) Reset()         { *m = SoftResetBGPPeerRequest{} }
+func (m *SoftResetBGPPeerRequest) String() string { return proto.CompactTextString(m) }
+func (*SoftResetBGPPeerRequest) ProtoMessage()    {}
+func (*SoftResetBGPPeerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{25}
+}
+
+func (m *SoftResetBGPPeerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SoftResetBGPPeerRequest.Unmarshal(m, b)
+}
+func (m *SoftResetBGPPeerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SoftResetBGPPeerRequest.Marshal(b, m, deterministic)
+}
+func (m *SoftResetBGPPeerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SoftResetBGPPeerRequest.Merge(m, src)
+}
+func (m *SoftResetBGPPeerRequest) XXX_Size() int {
+	return xxx_messageInfo_SoftResetBGPPeerRequest.Size(m)
+}
+func (m *SoftResetBGPPeerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SoftResetBGPPeerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SoftResetBGPPeerRequest proto.InternalMessageInfo
+
+func (m *SoftResetBGPPeerRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *SoftResetBGPPeerRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *SoftResetBGPPeerRequest) GetInbound() bool {
+	if m != nil {
+		return m.Inbound
+	}
+	return false
+}
+
+func (m *SoftResetBGPPeerRequest) GetOutbound() bool {
+	if m != nil {
+		return m.Outbound
+	}
+	return false
+}
+
+type SoftResetBGPPeerResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SoftResetBGPPeerResponse) Reset()         { *m = SoftResetBGPPeerResponse{} }
+func (m *SoftResetBGPPeerResponse) String() string { return proto.CompactTextString(m) }
+func (*SoftResetBGPPeerResponse) ProtoMessage()    {}
+func (*SoftResetBGPPeerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{26}
+}
+
+func (m *SoftResetBGPPeerResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SoftResetBGPPeerResponse.Unmarshal(m, b)
+}
+func (m *SoftResetBGPPeerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SoftResetBGPPeerResponse.Marshal(b, m, deterministic)
+}
+func (m *SoftResetBGPPeerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SoftResetBGPPeerResponse.Merge(m, src)
+}
+func (m *SoftResetBGPPeerResponse) XXX_Size() int {
+	return xxx_messageInfo_SoftResetBGPPeerResponse.Size(m)
+}
+func (m *SoftResetBGPPeerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SoftResetBGPPeerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SoftResetBGPPeerResponse proto.InternalMessageInfo
+
+type SetBGPPeerMaintenanceRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Enabled              bool     `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBGPPeerMaintenanceRequest) Reset()         { *m = SetBGPPeerMaintenanceRequest{} }
+func (m *SetBGPPeerMaintenanceRequest) String() string { return proto.CompactTextString(m) }
+func (*SetBGPPeerMaintenanceRequest) ProtoMessage()    {}
+func (*SetBGPPeerMaintenanceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{27}
+}
+
+func (m *SetBGPPeerMaintenanceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBGPPeerMaintenanceRequest.Unmarshal(m, b)
+}
+func (m *SetBGPPeerMaintenanceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBGPPeerMaintenanceRequest.Marshal(b, m, deterministic)
+}
+func (m *SetBGPPeerMaintenanceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBGPPeerMaintenanceRequest.Merge(m, src)
+}
+func (m *SetBGPPeerMaintenanceRequest) XXX_Size() int {
+	return xxx_messageInfo_SetBGPPeerMaintenanceRequest.Size(m)
+}
+func (m *SetBGPPeerMaintenanceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBGPPeerMaintenanceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBGPPeerMaintenanceRequest proto.InternalMessageInfo
+
+func (m *SetBGPPeerMaintenanceRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *SetBGPPeerMaintenanceRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *SetBGPPeerMaintenanceRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+type SetBGPPeerMaintenanceResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBGPPeerMaintenanceResponse) Reset()         { *m = SetBGPPeerMaintenanceResponse{} }
+func (m *SetBGPPeerMaintenanceResponse) String() string { return proto.CompactTextString(m) }
+func (*SetBGPPeerMaintenanceResponse) ProtoMessage()    {}
+func (*SetBGPPeerMaintenanceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{28}
+}
+
+func (m *SetBGPPeerMaintenanceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBGPPeerMaintenanceResponse.Unmarshal(m, b)
+}
+func (m *SetBGPPeerMaintenanceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBGPPeerMaintenanceResponse.Marshal(b, m, deterministic)
+}
+func (m *SetBGPPeerMaintenanceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBGPPeerMaintenanceResponse.Merge(m, src)
+}
+func (m *SetBGPPeerMaintenanceResponse) XXX_Size() int {
+	return xxx_messageInfo_SetBGPPeerMaintenanceResponse.Size(m)
+}
+func (m *SetBGPPeerMaintenanceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBGPPeerMaintenanceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBGPPeerMaintenanceResponse proto.InternalMessageInfo
+
+type ShutdownBGPPeerRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	DrainTimeSeconds     uint32   `protobuf:"varint,3,opt,name=drain_time_seconds,json=drainTimeSeconds,proto3" json:"drain_time_seconds,omitempty"`
+	Message              string   `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShutdownBGPPeerRequest) Reset()         { *m = ShutdownBGPPeerRequest{} }
+func (m *ShutdownBGPPeerRequest) String() string { return proto.CompactTextString(m) }
+func (*ShutdownBGPPeerRequest) ProtoMessage()    {}
+func (*ShutdownBGPPeerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{29}
+}
+
+func (m *ShutdownBGPPeerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShutdownBGPPeerRequest.Unmarshal(m, b)
+}
+func (m *ShutdownBGPPeerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShutdownBGPPeerRequest.Marshal(b, m, deterministic)
+}
+func (m *ShutdownBGPPeerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShutdownBGPPeerRequest.Merge(m, src)
+}
+func (m *ShutdownBGPPeerRequest) XXX_Size() int {
+	return xxx_messageInfo_ShutdownBGPPeerRequest.Size(m)
+}
+func (m *ShutdownBGPPeerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShutdownBGPPeerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShutdownBGPPeerRequest proto.InternalMessageInfo
+
+func (m *ShutdownBGPPeerRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *ShutdownBGPPeerRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *ShutdownBGPPeerRequest) GetDrainTimeSeconds() uint32 {
+	if m != nil {
+		return m.DrainTimeSeconds
+	}
+	return 0
+}
+
+func (m *ShutdownBGPPeerRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ShutdownBGPPeerResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShutdownBGPPeerResponse) Reset()         { *m = ShutdownBGPPeerResponse{} }
+func (m *ShutdownBGPPeerResponse) String() string { return proto.CompactTextString(m) }
+func (*ShutdownBGPPeerResponse) ProtoMessage()    {}
+func (*ShutdownBGPPeerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{30}
+}
+
+func (m *ShutdownBGPPeerResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShutdownBGPPeerResponse.Unmarshal(m, b)
+}
+func (m *ShutdownBGPPeerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShutdownBGPPeerResponse.Marshal(b, m, deterministic)
+}
+func (m *ShutdownBGPPeerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShutdownBGPPeerResponse.Merge(m, src)
+}
+func (m *ShutdownBGPPeerResponse) XXX_Size() int {
+	return xxx_messageInfo_ShutdownBGPPeerResponse.Size(m)
+}
+func (m *ShutdownBGPPeerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShutdownBGPPeerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShutdownBGPPeerResponse proto.InternalMessageInfo
+
+type ResetBGPPeerRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	Message              string   `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetBGPPeerRequest) Reset()         { *m = ResetBGPPeerRequest{} }
+func (m *ResetBGPPeerRequest) String() string { return proto.CompactTextString(m) }
+func (*ResetBGPPeerRequest) ProtoMessage()    {}
+func (*ResetBGPPeerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{31}
+}
+
+func (m *ResetBGPPeerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetBGPPeerRequest.Unmarshal(m, b)
+}
+func (m *ResetBGPPeerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetBGPPeerRequest.Marshal(b, m, deterministic)
+}
+func (m *ResetBGPPeerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetBGPPeerRequest.Merge(m, src)
+}
+func (m *ResetBGPPeerRequest) XXX_Size() int {
+	return xxx_messageInfo_ResetBGPPeerRequest.Size(m)
+}
+func (m *ResetBGPPeerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetBGPPeerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetBGPPeerRequest proto.InternalMessageInfo
+
+func (m *ResetBGPPeerRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *ResetBGPPeerRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *ResetBGPPeerRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ResetBGPPeerResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetBGPPeerResponse) Reset()         { *m = ResetBGPPeerResponse{} }
+func (m *ResetBGPPeerResponse) String() string { return proto.CompactTextString(m) }
+func (*ResetBGPPeerResponse) ProtoMessage()    {}
+func (*ResetBGPPeerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{32}
+}
+
+func (m *ResetBGPPeerResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetBGPPeerResponse.Unmarshal(m, b)
+}
+func (m *ResetBGPPeerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetBGPPeerResponse.Marshal(b, m, deterministic)
+}
+func (m *ResetBGPPeerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetBGPPeerResponse.Merge(m, src)
+}
+func (m *ResetBGPPeerResponse) XXX_Size() int {
+	return xxx_messageInfo_ResetBGPPeerResponse.Size(m)
+}
+func (m *ResetBGPPeerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetBGPPeerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetBGPPeerResponse proto.InternalMessageInfo
+
+type StartBGPPeerRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StartBGPPeerRequest) Reset()         { *m = StartBGPPeerRequest{} }
+func (m *StartBGPPeerRequest) String() string { return proto.CompactTextString(m) }
+func (*StartBGPPeerRequest) ProtoMessage()    {}
+func (*StartBGPPeerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{33}
+}
+
+func (m *StartBGPPeerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StartBGPPeerRequest.Unmarshal(m, b)
+}
+func (m *StartBGPPeerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StartBGPPeerRequest.Marshal(b, m, deterministic)
+}
+func (m *StartBGPPeerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StartBGPPeerRequest.Merge(m, src)
+}
+func (m *StartBGPPeerRequest) XXX_Size() int {
+	return xxx_messageInfo_StartBGPPeerRequest.Size(m)
+}
+func (m *StartBGPPeerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StartBGPPeerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StartBGPPeerRequest proto.InternalMessageInfo
+
+func (m *StartBGPPeerRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *StartBGPPeerRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+type StartBGPPeerResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StartBGPPeerResponse) Reset()         { *m = StartBGPPeerResponse{} }
+func (m *StartBGPPeerResponse) String() string { return proto.CompactTextString(m) }
+func (*StartBGPPeerResponse) ProtoMessage()    {}
+func (*StartBGPPeerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{34}
+}
+
+func (m *StartBGPPeerResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StartBGPPeerResponse.Unmarshal(m, b)
+}
+func (m *StartBGPPeerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StartBGPPeerResponse.Marshal(b, m, deterministic)
+}
+func (m *StartBGPPeerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StartBGPPeerResponse.Merge(m, src)
+}
+func (m *StartBGPPeerResponse) XXX_Size() int {
+	return xxx_messageInfo_StartBGPPeerResponse.Size(m)
+}
+func (m *StartBGPPeerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StartBGPPeerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StartBGPPeerResponse proto.InternalMessageInfo
+
+type ReplayMRTRequest struct {
+	Instance             string   `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Peer                 *api.IP  `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	SourcePeer           *api.IP  `protobuf:"bytes,3,opt,name=source_peer,json=sourcePeer,proto3" json:"source_peer,omitempty"`
+	Path                 string   `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Speed                float64  `protobuf:"fixed64,5,opt,name=speed,proto3" json:"speed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplayMRTRequest) Reset()         { *m = ReplayMRTRequest{} }
+func (m *ReplayMRTRequest) String() string { return proto.CompactTextString(m) }
+func (*ReplayMRTRequest) ProtoMessage()    {}
+func (*ReplayMRTRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{35}
+}
+
+func (m *ReplayMRTRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplayMRTRequest.Unmarshal(m, b)
+}
+func (m *ReplayMRTRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplayMRTRequest.Marshal(b, m, deterministic)
+}
+func (m *ReplayMRTRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplayMRTRequest.Merge(m, src)
+}
+func (m *ReplayMRTRequest) XXX_Size() int {
+	return xxx_messageInfo_ReplayMRTRequest.Size(m)
+}
+func (m *ReplayMRTRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplayMRTRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplayMRTRequest proto.InternalMessageInfo
+
+func (m *ReplayMRTRequest) GetInstance() string {
+	if m != nil {
+		return m.Instance
+	}
+	return ""
+}
+
+func (m *ReplayMRTRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *ReplayMRTRequest) GetSourcePeer() *api.IP {
+	if m != nil {
+		return m.SourcePeer
+	}
+	return nil
+}
+
+func (m *ReplayMRTRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ReplayMRTRequest) GetSpeed() float64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+type ReplayMRTResponse struct {
+	Updates              uint64   `protobuf:"varint,1,opt,name=updates,proto3" json:"updates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReplayMRTResponse) Reset()         { *m = ReplayMRTResponse{} }
+func (m *ReplayMRTResponse) String() string { return proto.CompactTextString(m) }
+func (*ReplayMRTResponse) ProtoMessage()    {}
+func (*ReplayMRTResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_64a68723134248ad, []int{36}
+}
+
+func (m *ReplayMRTResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplayMRTResponse.Unmarshal(m, b)
+}
+func (m *ReplayMRTResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplayMRTResponse.Marshal(b, m, deterministic)
+}
+func (m *ReplayMRTResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplayMRTResponse.Merge(m, src)
+}
+func (m *ReplayMRTResponse) XXX_Size() int {
+	return xxx_messageInfo_ReplayMRTResponse.Size(m)
+}
+func (m *ReplayMRTResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplayMRTResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplayMRTResponse proto.InternalMessageInfo
+
+func (m *ReplayMRTResponse) GetUpdates() uint64 {
+	if m != nil {
+		return m.Updates
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*SaveConfigRequest)(nil), "bio.management.SaveConfigRequest")
+	proto.RegisterType((*SaveConfigResponse)(nil), "bio.management.SaveConfigResponse")
+	proto.RegisterType((*SetProtocolStateRequest)(nil), "bio.management.SetProtocolStateRequest")
+	proto.RegisterType((*SetProtocolStateResponse)(nil), "bio.management.SetProtocolStateResponse")
+	proto.RegisterType((*GetLogLevelsRequest)(nil), "bio.management.GetLogLevelsRequest")
+	proto.RegisterType((*GetLogLevelsResponse)(nil), "bio.management.GetLogLevelsResponse")
+	proto.RegisterMapType((map[string]string)(nil), "bio.management.GetLogLevelsResponse.LevelsEntry")
+	proto.RegisterType((*SetLogLevelRequest)(nil), "bio.management.SetLogLevelRequest")
+	proto.RegisterType((*SetLogLevelResponse)(nil), "bio.management.SetLogLevelResponse")
+	proto.RegisterType((*CaptureBGPRequest)(nil), "bio.management.CaptureBGPRequest")
+	proto.RegisterType((*CaptureData)(nil), "bio.management.CaptureData")
+	proto.RegisterType((*GetEventsRequest)(nil), "bio.management.GetEventsRequest")
+	proto.RegisterType((*GetEventsResponse)(nil), "bio.management.GetEventsResponse")
+	proto.RegisterType((*Event)(nil), "bio.management.Event")
+	proto.RegisterType((*GetFlightRecorderRequest)(nil), "bio.management.GetFlightRecorderRequest")
+	proto.RegisterType((*GetFlightRecorderResponse)(nil), "bio.management.GetFlightRecorderResponse")
+	proto.RegisterType((*FlightRecord)(nil), "bio.management.FlightRecord")
+	proto.RegisterType((*Transition)(nil), "bio.management.Transition")
+	proto.RegisterType((*SetBGPPeerDebugRequest)(nil), "bio.management.SetBGPPeerDebugRequest")
+	proto.RegisterType((*SetBGPPeerDebugResponse)(nil), "bio.management.SetBGPPeerDebugResponse")
+	proto.RegisterType((*ResetBGPCountersRequest)(nil), "bio.management.ResetBGPCountersRequest")
+	proto.RegisterType((*ResetBGPCountersResponse)(nil), "bio.management.ResetBGPCountersResponse")
+	proto.RegisterType((*GetLabelAllocationsRequest)(nil), "bio.management.GetLabelAllocationsRequest")
+	proto.RegisterType((*GetLabelAllocationsResponse)(nil), "bio.management.GetLabelAllocationsResponse")
+	proto.RegisterType((*LabelRange)(nil), "bio.management.LabelRange")
+	proto.RegisterType((*LabelAllocation)(nil), "bio.management.LabelAllocation")
+	proto.RegisterType((*SoftResetBGPPeerRequest)(nil), "bio.management.SoftResetBGPPeerRequest")
+	proto.RegisterType((*SoftResetBGPPeerResponse)(nil), "bio.management.SoftResetBGPPeerResponse")
+	proto.RegisterType((*SetBGPPeerMaintenanceRequest)(nil), "bio.management.SetBGPPeerMaintenanceRequest")
+	proto.RegisterType((*SetBGPPeerMaintenanceResponse)(nil), "bio.management.SetBGPPeerMaintenanceResponse")
+	proto.RegisterType((*ShutdownBGPPeerRequest)(nil), "bio.management.ShutdownBGPPeerRequest")
+	proto.RegisterType((*ShutdownBGPPeerResponse)(nil), "bio.management.ShutdownBGPPeerResponse")
+	proto.RegisterType((*ResetBGPPeerRequest)(nil), "bio.management.ResetBGPPeerRequest")
+	proto.RegisterType((*ResetBGPPeerResponse)(nil), "bio.management.ResetBGPPeerResponse")
+	proto.RegisterType((*StartBGPPeerRequest)(nil), "bio.management.StartBGPPeerRequest")
+	proto.RegisterType((*StartBGPPeerResponse)(nil), "bio.management.StartBGPPeerResponse")
+	proto.RegisterType((*ReplayMRTRequest)(nil), "bio.management.ReplayMRTRequest")
+	proto.RegisterType((*ReplayMRTResponse)(nil), "bio.management.ReplayMRTResponse")
+}
+
+func init() {
+	proto.RegisterFile("github.com/bio-routing/bio-rd/cmd/bio-rd/api/management.proto", fileDescriptor_64a68723134248ad)
+}
+
+var fileDescriptor_64a68723134248ad = []byte{
+	// 1344 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0xdd, 0x6f, 0xdc, 0x44,
+	0x10, 0xaf, 0xef, 0x2e, 0x69, 0x33, 0x97, 0xb4, 0xc9, 0x26, 0x4d, 0x5c, 0xb7, 0xa8, 0x89, 0x5b,
+	0xd1, 0x03, 0x9a, 0x4b, 0x15, 0x10, 0x02, 0x04, 0x0f, 0x4d, 0x5b, 0x52, 0xa4, 0xb6, 0x8a, 0x7c,
+	0x05, 0x21, 0x78, 0x88, 0xd6, 0xbe, 0xe9, 0xc5, 0x8d, 0x6f, 0xd7, 0x78, 0xd7, 0xa9, 0xf2, 0xcc,
+	0x2b, 0xbc, 0xf2, 0x82, 0x78, 0xe6, 0x9d, 0xbf, 0x10, 0x79, 0xbd, 0xfe, 0x38, 0xdb, 0x77, 0x39,
+	0x50, 0xfa, 0xe6, 0x99, 0x9d, 0xaf, 0x9d, 0x99, 0x9d, 0xf9, 0xdd, 0xc1, 0x37, 0x23, 0x5f, 0x9e,
+	0xc4, 0x6e, 0xdf, 0xe3, 0xe3, 0x3d, 0xd7, 0xe7, 0xbb, 0x11, 0x8f, 0xa5, 0xcf, 0x46, 0xe9, 0xf7,
+	0x70, 0xcf, 0x1b, 0x0f, 0xb3, 0x4f, 0x1a, 0xfa, 0x7b, 0x63, 0xca, 0xe8, 0x08, 0xc7, 0xc8, 0x64,
+	0x3f, 0x8c, 0xb8, 0xe4, 0xe4, 0xba, 0xeb, 0xf3, 0x7e, 0xc1, 0xb5, 0xf6, 0x66, 0x9b, 0x63, 0x28,
+	0x95, 0x1d, 0x86, 0xda, 0x80, 0xbd, 0x0e, 0x6b, 0x03, 0x7a, 0x86, 0x4f, 0x38, 0x7b, 0xe3, 0x8f,
+	0x1c, 0xfc, 0x25, 0x46, 0x21, 0xed, 0x1e, 0x90, 0x32, 0x53, 0x84, 0x9c, 0x09, 0x24, 0x04, 0x3a,
+	0x21, 0x95, 0x27, 0xa6, 0xb1, 0x6d, 0xf4, 0x96, 0x1c, 0xf5, 0x6d, 0x9f, 0xc2, 0xd6, 0x00, 0xe5,
+	0x51, 0x62, 0xca, 0xe3, 0xc1, 0x40, 0x52, 0x89, 0xda, 0x08, 0xb1, 0xe0, 0x9a, 0xcf, 0x84, 0xa4,
+	0xcc, 0x43, 0xad, 0x92, 0xd3, 0xc9, 0x59, 0xa8, 0x75, 0xcc, 0x56, 0x7a, 0x96, 0xd1, 0xc4, 0x84,
+	0xab, 0xc8, 0xa8, 0x1b, 0xe0, 0xd0, 0x6c, 0x6f, 0x1b, 0xbd, 0x6b, 0x4e, 0x46, 0xda, 0x16, 0x98,
+	0x75, 0x67, 0x69, 0x70, 0xf6, 0x4d, 0x58, 0x3f, 0x44, 0xf9, 0x82, 0x8f, 0x5e, 0xe0, 0x19, 0x06,
+	0x22, 0xbb, 0xc9, 0x9f, 0x06, 0x6c, 0x4c, 0xf2, 0xf5, 0x65, 0x9e, 0xc3, 0x62, 0xa0, 0x38, 0xa6,
+	0xb1, 0xdd, 0xee, 0x75, 0xf7, 0x1f, 0xf5, 0x27, 0x33, 0xd9, 0x6f, 0xd2, 0xea, 0xa7, 0xe4, 0x33,
+	0x26, 0xa3, 0x73, 0x47, 0xeb, 0x5b, 0x5f, 0x42, 0xb7, 0xc4, 0x26, 0xab, 0xd0, 0x3e, 0xc5, 0x73,
+	0x7d, 0xe3, 0xe4, 0x93, 0x6c, 0xc0, 0xc2, 0x19, 0x0d, 0x62, 0xd4, 0x37, 0x4d, 0x89, 0xaf, 0x5a,
+	0x5f, 0x18, 0xf6, 0x73, 0x20, 0x83, 0xc2, 0x4d, 0x96, 0xb8, 0x3b, 0xb0, 0x24, 0x62, 0x57, 0x9c,
+	0x0b, 0x89, 0x63, 0x6d, 0xa7, 0x60, 0x24, 0xd6, 0x94, 0xe3, 0xcc, 0x9a, 0x22, 0x92, 0xeb, 0x4f,
+	0x58, 0xd2, 0x59, 0x39, 0x82, 0xb5, 0x27, 0x34, 0x94, 0x71, 0x84, 0x07, 0x87, 0x47, 0xf3, 0x14,
+	0xe6, 0x2e, 0x74, 0x42, 0xc4, 0x48, 0x19, 0xef, 0xee, 0x77, 0x55, 0x52, 0x92, 0x66, 0xf9, 0xee,
+	0xc8, 0x51, 0x07, 0xf6, 0x0e, 0x74, 0xb5, 0xc5, 0xa7, 0x54, 0x52, 0xd5, 0x13, 0x1e, 0x0d, 0x95,
+	0x9d, 0x65, 0x47, 0x7d, 0xdb, 0x8f, 0x60, 0xf5, 0x10, 0xe5, 0xb3, 0x33, 0x64, 0x52, 0xcc, 0x75,
+	0x27, 0xfb, 0x00, 0xd6, 0x4a, 0x1a, 0xba, 0x42, 0xbb, 0xb0, 0x88, 0x8a, 0xa3, 0x2b, 0x74, 0xb3,
+	0x5a, 0x21, 0x25, 0xef, 0x68, 0x21, 0xfb, 0x77, 0x03, 0x16, 0x14, 0x27, 0xf1, 0x25, 0xfd, 0x31,
+	0x0a, 0x49, 0xc7, 0x69, 0x60, 0x6d, 0xa7, 0x60, 0x4c, 0x46, 0xd2, 0xaa, 0x66, 0x77, 0x13, 0x16,
+	0xb9, 0xfb, 0x16, 0x3d, 0xa9, 0x7a, 0x6f, 0xc9, 0xd1, 0x54, 0xd2, 0x94, 0x63, 0x14, 0x82, 0x8e,
+	0xd0, 0xec, 0xa8, 0x83, 0x8c, 0x4c, 0x34, 0x22, 0xa4, 0x82, 0x33, 0x73, 0x21, 0xd5, 0x48, 0x29,
+	0xfb, 0x15, 0x98, 0x87, 0x28, 0xbf, 0x0d, 0xfc, 0xd1, 0x89, 0x74, 0xd0, 0xe3, 0xd1, 0x10, 0xa3,
+	0x52, 0x05, 0xf2, 0xf6, 0x37, 0x2a, 0xed, 0x5f, 0x44, 0xd0, 0x2a, 0x47, 0x60, 0x0f, 0xe0, 0x56,
+	0x83, 0x3d, 0x9d, 0xab, 0xcf, 0xe1, 0x6a, 0xa4, 0x78, 0x59, 0xb2, 0xee, 0x54, 0x93, 0x55, 0x56,
+	0x74, 0x32, 0x61, 0xfb, 0x0f, 0x03, 0x96, 0xcb, 0x27, 0xff, 0x27, 0x32, 0xf2, 0x35, 0x74, 0x65,
+	0x44, 0x99, 0xf0, 0xa5, 0xcf, 0x99, 0x30, 0xdb, 0x2a, 0x00, 0xab, 0x1a, 0xc0, 0xeb, 0x5c, 0xc4,
+	0x29, 0x8b, 0x27, 0xfd, 0x2c, 0x3c, 0x1e, 0x66, 0x79, 0x4d, 0x09, 0xfb, 0x14, 0xa0, 0x50, 0x20,
+	0x3b, 0xb0, 0x9c, 0x17, 0xf0, 0x98, 0x09, 0x5d, 0xd4, 0x6e, 0xce, 0x7b, 0x25, 0x92, 0x46, 0x7c,
+	0x13, 0xf1, 0xac, 0xa2, 0xea, 0x9b, 0x5c, 0x87, 0x96, 0xe4, 0xba, 0x90, 0x2d, 0xc9, 0x4b, 0xa5,
+	0xea, 0x4c, 0x94, 0x8a, 0xc3, 0xe6, 0x00, 0xe5, 0xc1, 0xe1, 0xd1, 0x11, 0x62, 0xf4, 0x14, 0xdd,
+	0x78, 0x74, 0x19, 0x4f, 0x65, 0xc6, 0x20, 0xbb, 0x05, 0x5b, 0x35, 0x87, 0xfa, 0xc5, 0xfe, 0x00,
+	0x5b, 0x0e, 0x0a, 0x75, 0xf8, 0x84, 0xc7, 0x4c, 0x62, 0x24, 0x2e, 0xe5, 0xdd, 0x5a, 0x60, 0xd6,
+	0xed, 0x6a, 0x9f, 0xfb, 0x60, 0x25, 0xd3, 0x8e, 0xba, 0x18, 0x3c, 0x0e, 0x02, 0xee, 0x51, 0x55,
+	0x99, 0xcc, 0xed, 0x06, 0x2c, 0xf0, 0x77, 0x0c, 0x23, 0xed, 0x33, 0x25, 0xec, 0x5f, 0x5b, 0x70,
+	0xbb, 0x51, 0x49, 0x77, 0xe4, 0x63, 0xb8, 0x3e, 0x3c, 0x67, 0x74, 0xec, 0x7b, 0xc7, 0x41, 0x22,
+	0x93, 0x16, 0xad, 0xa1, 0x2f, 0x94, 0x05, 0x87, 0xb2, 0x11, 0x3a, 0x2b, 0x5a, 0x43, 0xb1, 0x04,
+	0xe9, 0x43, 0x47, 0x44, 0x23, 0xd7, 0x6c, 0x5d, 0xa8, 0xa8, 0xe4, 0x52, 0xf9, 0xc0, 0x35, 0xdb,
+	0xf3, 0xc8, 0x07, 0x2e, 0x79, 0x0c, 0x5d, 0x5a, 0x44, 0x6e, 0x76, 0x54, 0xdf, 0xde, 0x6d, 0x54,
+	0x2b, 0x6e, 0xe8, 0x94, 0x75, 0xec, 0xcf, 0x00, 0x0a, 0xb3, 0xaa, 0x95, 0x25, 0x8d, 0xa4, 0xba,
+	0xea, 0x8a, 0x93, 0x12, 0xc9, 0x42, 0x40, 0x36, 0x54, 0xb7, 0x58, 0x71, 0x92, 0x4f, 0x3b, 0x86,
+	0x1b, 0x15, 0xab, 0x6a, 0xaa, 0x27, 0xac, 0x4c, 0x55, 0x11, 0x09, 0xd7, 0x0d, 0xb8, 0x77, 0x9a,
+	0xcd, 0x7a, 0x45, 0x14, 0x05, 0x69, 0x97, 0x0a, 0x42, 0xb6, 0xa1, 0x3b, 0x44, 0xe1, 0x45, 0x7e,
+	0x28, 0xfd, 0xbc, 0xc3, 0xcb, 0x2c, 0xfb, 0x37, 0x03, 0xb6, 0x06, 0xfc, 0x8d, 0xcc, 0xfa, 0x20,
+	0x69, 0xbe, 0xcb, 0x6a, 0x74, 0x9f, 0xb9, 0x3c, 0x66, 0x79, 0xa3, 0x6b, 0x32, 0x31, 0xcb, 0x63,
+	0x99, 0x1e, 0x75, 0xd4, 0x51, 0x4e, 0xab, 0x6d, 0x5e, 0x8b, 0x46, 0x77, 0x64, 0x0c, 0x77, 0x8a,
+	0x07, 0xf2, 0x92, 0xfa, 0x4c, 0x22, 0x4b, 0x62, 0x79, 0xcf, 0xef, 0xf2, 0x2e, 0x7c, 0x30, 0xc5,
+	0xad, 0x8e, 0xeb, 0x2f, 0x03, 0x36, 0x07, 0x27, 0xb1, 0x1c, 0xf2, 0x77, 0xec, 0x32, 0x33, 0xf8,
+	0x10, 0xc8, 0x30, 0xa2, 0x3e, 0x3b, 0x4e, 0x46, 0xda, 0xb1, 0x40, 0x8f, 0xb3, 0xa1, 0x50, 0xd1,
+	0xad, 0x38, 0xab, 0xea, 0xe4, 0xb5, 0x3f, 0xc6, 0x41, 0xca, 0x9f, 0xbe, 0x8c, 0xd4, 0x60, 0xa9,
+	0x86, 0xa7, 0x43, 0x0f, 0x60, 0xfd, 0x7d, 0x14, 0x3e, 0x0b, 0xa4, 0x3d, 0x19, 0xc8, 0x26, 0x6c,
+	0x34, 0x16, 0xd6, 0x81, 0xf5, 0x41, 0xf2, 0x2a, 0x2e, 0x31, 0x8a, 0xc4, 0xd7, 0xa4, 0x4d, 0xed,
+	0xeb, 0x6f, 0x03, 0x56, 0x1d, 0x0c, 0x03, 0x7a, 0xfe, 0xd2, 0x79, 0x7d, 0x49, 0x65, 0xea, 0x0a,
+	0x1e, 0x47, 0x1e, 0x1e, 0x2b, 0xb9, 0x76, 0x5d, 0x0e, 0xd2, 0xf3, 0x24, 0x8e, 0x1c, 0x2f, 0x77,
+	0x0a, 0xbc, 0xac, 0x46, 0x44, 0x88, 0x38, 0x54, 0x60, 0xc1, 0x70, 0x52, 0xc2, 0xde, 0x85, 0xb5,
+	0x52, 0xa0, 0x7a, 0x82, 0x9a, 0x70, 0x35, 0x0e, 0x87, 0x54, 0x62, 0x3a, 0x3a, 0x3b, 0x4e, 0x46,
+	0xee, 0xff, 0xb3, 0x0c, 0x6b, 0x2f, 0xf3, 0x09, 0x35, 0xc0, 0xe8, 0xcc, 0xf7, 0x90, 0x7c, 0x0f,
+	0x50, 0x80, 0x76, 0xb2, 0x53, 0x9d, 0x63, 0x35, 0x94, 0x6f, 0xd9, 0xb3, 0x44, 0x74, 0x0e, 0xaf,
+	0x90, 0x11, 0xac, 0x56, 0x41, 0x37, 0x79, 0x50, 0xd3, 0x6c, 0xfe, 0x0d, 0x60, 0xf5, 0x2e, 0x16,
+	0xcc, 0x1d, 0xfd, 0x0c, 0xcb, 0x65, 0xcc, 0x4d, 0xee, 0xcd, 0x46, 0xe4, 0xa9, 0x83, 0xfb, 0xf3,
+	0xc0, 0x76, 0xfb, 0x0a, 0xf9, 0x11, 0xba, 0x25, 0x7c, 0x4c, 0xec, 0x86, 0xb8, 0x2a, 0x30, 0xdc,
+	0xba, 0x37, 0x53, 0x26, 0xb7, 0x7c, 0x04, 0x50, 0x40, 0xec, 0x7a, 0xda, 0x6b, 0xf0, 0xdb, 0xba,
+	0x3d, 0x45, 0x24, 0xc1, 0xd3, 0xf6, 0x95, 0x47, 0x06, 0x71, 0x60, 0x29, 0x47, 0xc3, 0x64, 0xbb,
+	0xe1, 0x82, 0x13, 0xd0, 0xda, 0xda, 0x99, 0x21, 0x91, 0x47, 0xf9, 0x56, 0x21, 0xec, 0x49, 0xf4,
+	0x48, 0x7a, 0x0d, 0x9a, 0x8d, 0x80, 0xd5, 0xfa, 0x68, 0x0e, 0xc9, 0xdc, 0xd7, 0x10, 0x6e, 0x54,
+	0xd0, 0x0d, 0xf9, 0xb0, 0x21, 0x97, 0x0d, 0x78, 0xcb, 0x7a, 0x70, 0xa1, 0x5c, 0xb9, 0x2f, 0xab,
+	0x80, 0xa6, 0xde, 0x97, 0x53, 0xa0, 0x94, 0xd5, 0xbb, 0x58, 0x30, 0x77, 0x14, 0xa6, 0xbf, 0x2c,
+	0x2b, 0x40, 0x87, 0x7c, 0xdc, 0xd4, 0x79, 0xcd, 0x10, 0xca, 0xfa, 0x64, 0x2e, 0xd9, 0x89, 0x27,
+	0x57, 0xd9, 0x8c, 0x0d, 0x4f, 0xae, 0x79, 0x93, 0x5b, 0xbd, 0x8b, 0x05, 0x73, 0x47, 0x67, 0x70,
+	0xb3, 0x71, 0xdf, 0x91, 0x87, 0xd3, 0xeb, 0x50, 0xdf, 0xc6, 0xd6, 0xee, 0x9c, 0xd2, 0x13, 0x1d,
+	0x32, 0xb9, 0xa6, 0x1a, 0x3a, 0xa4, 0x71, 0xcd, 0x5a, 0x0f, 0x2e, 0x94, 0x2b, 0x0f, 0x94, 0xf2,
+	0x5e, 0xa8, 0x0f, 0x94, 0x86, 0x4d, 0x64, 0xdd, 0x9f, 0x2d, 0x54, 0x36, 0x3e, 0x51, 0x9f, 0x7b,
+	0xd3, 0x3a, 0x6a, 0xa6, 0xf1, 0x29, 0x75, 0x71, 0x60, 0x29, 0xdf, 0x07, 0xf5, 0x09, 0x50, 0xdd,
+	0x69, 0xd6, 0xce, 0x0c, 0x89, 0xcc, 0xe6, 0x41, 0xff, 0xa7, 0x87, 0xff, 0xe5, 0xaf, 0x26, 0x77,
+	0x51, 0xfd, 0xee, 0xfb, 0xf4, 0xdf, 0x01, 0x00, 0x5b, 0x02, 0xa3, 0x28, 0xa1, 0x12, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ManagementServiceClient is the client API for ManagementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ManagementServiceClient interface {
+	SaveConfig(ctx context.Context, in *SaveConfigRequest, opts ...grpc.CallOption) (*SaveConfigResponse, error)
+	SetProtocolState(ctx context.Context, in *SetProtocolStateRequest, opts ...grpc.CallOption) (*SetProtocolStateResponse, error)
+	GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	CaptureBGP(ctx context.Context, in *CaptureBGPRequest, opts ...grpc.CallOption) (ManagementService_CaptureBGPClient, error)
+	GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	GetFlightRecorder(ctx context.Context, in *GetFlightRecorderRequest, opts ...grpc.CallOption) (*GetFlightRecorderResponse, error)
+	SetBGPPeerDebug(ctx context.Context, in *SetBGPPeerDebugRequest, opts ...grpc.CallOption) (*SetBGPPeerDebugResponse, error)
+	ResetBGPCounters(ctx context.Context, in *ResetBGPCountersRequest, opts ...grpc.CallOption) (*ResetBGPCountersResponse, error)
+	GetLabelAllocations(ctx context.Context, in *GetLabelAllocationsRequest, opts ...grpc.CallOption) (*GetLabelAllocationsResponse, error)
+	SoftResetBGPPeer(ctx context.Context, in *SoftResetBGPPeerRequest, opts ...grpc.CallOption) (*SoftResetBGPPeerResponse, error)
+	SetBGPPeerMaintenance(ctx context.Context, in *SetBGPPeerMaintenanceRequest, opts ...grpc.CallOption) (*SetBGPPeerMaintenanceResponse, error)
+	ShutdownBGPPeer(ctx context.Context, in *ShutdownBGPPeerRequest, opts ...grpc.CallOption) (*ShutdownBGPPeerResponse, error)
+	StartBGPPeer(ctx context.Context, in *StartBGPPeerRequest, opts ...grpc.CallOption) (*StartBGPPeerResponse, error)
+	ResetBGPPeer(ctx context.Context, in *ResetBGPPeerRequest, opts ...grpc.CallOption) (*ResetBGPPeerResponse, error)
+	ReplayMRT(ctx context.Context, in *ReplayMRTRequest, opts ...grpc.CallOption) (*ReplayMRTResponse, error)
+}
+
+type managementServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewManagementServiceClient(cc *grpc.ClientConn) ManagementServiceClient {
+	return &managementServiceClient{cc}
+}
+
+func (c *managementServiceClient) SaveConfig(ctx context.Context, in *SaveConfigRequest, opts ...grpc.CallOption) (*SaveConfigResponse, error) {
+	out := new(SaveConfigResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SaveConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetProtocolState(ctx context.Context, in *SetProtocolStateRequest, opts ...grpc.CallOption) (*SetProtocolStateResponse, error) {
+	out := new(SetProtocolStateResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SetProtocolState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error) {
+	out := new(GetLogLevelsResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/GetLogLevels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) CaptureBGP(ctx context.Context, in *CaptureBGPRequest, opts ...grpc.CallOption) (ManagementService_CaptureBGPClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ManagementService_serviceDesc.Streams[0], "/bio.management.ManagementService/CaptureBGP", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &managementServiceCaptureBGPClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ManagementService_CaptureBGPClient interface {
+	Recv() (*CaptureData, error)
+	grpc.ClientStream
+}
+
+type managementServiceCaptureBGPClient struct {
+	grpc.ClientStream
+}
+
+func (x *managementServiceCaptureBGPClient) Recv() (*CaptureData, error) {
+	m := new(CaptureData)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *managementServiceClient) GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
+	out := new(GetEventsResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/GetEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetFlightRecorder(ctx context.Context, in *GetFlightRecorderRequest, opts ...grpc.CallOption) (*GetFlightRecorderResponse, error) {
+	out := new(GetFlightRecorderResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/GetFlightRecorder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetBGPPeerDebug(ctx context.Context, in *SetBGPPeerDebugRequest, opts ...grpc.CallOption) (*SetBGPPeerDebugResponse, error) {
+	out := new(SetBGPPeerDebugResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SetBGPPeerDebug", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ResetBGPCounters(ctx context.Context, in *ResetBGPCountersRequest, opts ...grpc.CallOption) (*ResetBGPCountersResponse, error) {
+	out := new(ResetBGPCountersResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/ResetBGPCounters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) GetLabelAllocations(ctx context.Context, in *GetLabelAllocationsRequest, opts ...grpc.CallOption) (*GetLabelAllocationsResponse, error) {
+	out := new(GetLabelAllocationsResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/GetLabelAllocations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SoftResetBGPPeer(ctx context.Context, in *SoftResetBGPPeerRequest, opts ...grpc.CallOption) (*SoftResetBGPPeerResponse, error) {
+	out := new(SoftResetBGPPeerResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SoftResetBGPPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) SetBGPPeerMaintenance(ctx context.Context, in *SetBGPPeerMaintenanceRequest, opts ...grpc.CallOption) (*SetBGPPeerMaintenanceResponse, error) {
+	out := new(SetBGPPeerMaintenanceResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/SetBGPPeerMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ShutdownBGPPeer(ctx context.Context, in *ShutdownBGPPeerRequest, opts ...grpc.CallOption) (*ShutdownBGPPeerResponse, error) {
+	out := new(ShutdownBGPPeerResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/ShutdownBGPPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) StartBGPPeer(ctx context.Context, in *StartBGPPeerRequest, opts ...grpc.CallOption) (*StartBGPPeerResponse, error) {
+	out := new(StartBGPPeerResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/StartBGPPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ResetBGPPeer(ctx context.Context, in *ResetBGPPeerRequest, opts ...grpc.CallOption) (*ResetBGPPeerResponse, error) {
+	out := new(ResetBGPPeerResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/ResetBGPPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managementServiceClient) ReplayMRT(ctx context.Context, in *ReplayMRTRequest, opts ...grpc.CallOption) (*ReplayMRTResponse, error) {
+	out := new(ReplayMRTResponse)
+	err := c.cc.Invoke(ctx, "/bio.management.ManagementService/ReplayMRT", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ManagementServiceServer is the server API for ManagementService service.
+type ManagementServiceServer interface {
+	SaveConfig(context.Context, *SaveConfigRequest) (*SaveConfigResponse, error)
+	SetProtocolState(context.Context, *SetProtocolStateRequest) (*SetProtocolStateResponse, error)
+	GetLogLevels(context.Context, *GetLogLevelsRequest) (*GetLogLevelsResponse, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	CaptureBGP(*CaptureBGPRequest, ManagementService_CaptureBGPServer) error
+	GetEvents(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
+	GetFlightRecorder(context.Context, *GetFlightRecorderRequest) (*GetFlightRecorderResponse, error)
+	SetBGPPeerDebug(context.Context, *SetBGPPeerDebugRequest) (*SetBGPPeerDebugResponse, error)
+	ResetBGPCounters(context.Context, *ResetBGPCountersRequest) (*ResetBGPCountersResponse, error)
+	GetLabelAllocations(context.Context, *GetLabelAllocationsRequest) (*GetLabelAllocationsResponse, error)
+	SoftResetBGPPeer(context.Context, *SoftResetBGPPeerRequest) (*SoftResetBGPPeerResponse, error)
+	SetBGPPeerMaintenance(context.Context, *SetBGPPeerMaintenanceRequest) (*SetBGPPeerMaintenanceResponse, error)
+	ShutdownBGPPeer(context.Context, *ShutdownBGPPeerRequest) (*ShutdownBGPPeerResponse, error)
+	StartBGPPeer(context.Context, *StartBGPPeerRequest) (*StartBGPPeerResponse, error)
+	ResetBGPPeer(context.Context, *ResetBGPPeerRequest) (*ResetBGPPeerResponse, error)
+	ReplayMRT(context.Context, *ReplayMRTRequest) (*ReplayMRTResponse, error)
+}
+
+func RegisterManagementServiceServer(s *grpc.Server, srv ManagementServiceServer) {
+	s.RegisterService(&_ManagementService_serviceDesc, srv)
+}
+
+func _ManagementService_SaveConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SaveConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SaveConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SaveConfig(ctx, req.(*SaveConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetProtocolState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetProtocolStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetProtocolState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SetProtocolState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetProtocolState(ctx, req.(*SetProtocolStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetLogLevels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogLevelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetLogLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/GetLogLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetLogLevels(ctx, req.(*GetLogLevelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_CaptureBGP_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureBGPRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ManagementServiceServer).CaptureBGP(m, &managementServiceCaptureBGPServer{stream})
+}
+
+type ManagementService_CaptureBGPServer interface {
+	Send(*CaptureData) error
+	grpc.ServerStream
+}
+
+type managementServiceCaptureBGPServer struct {
+	grpc.ServerStream
+}
+
+func (x *managementServiceCaptureBGPServer) Send(m *CaptureData) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ManagementService_GetEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/GetEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetEvents(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetFlightRecorder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlightRecorderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetFlightRecorder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/GetFlightRecorder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetFlightRecorder(ctx, req.(*GetFlightRecorderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetBGPPeerDebug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBGPPeerDebugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetBGPPeerDebug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SetBGPPeerDebug",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetBGPPeerDebug(ctx, req.(*SetBGPPeerDebugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ResetBGPCounters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetBGPCountersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ResetBGPCounters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/ResetBGPCounters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ResetBGPCounters(ctx, req.(*ResetBGPCountersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_GetLabelAllocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLabelAllocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).GetLabelAllocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/GetLabelAllocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).GetLabelAllocations(ctx, req.(*GetLabelAllocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SoftResetBGPPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SoftResetBGPPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SoftResetBGPPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SoftResetBGPPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SoftResetBGPPeer(ctx, req.(*SoftResetBGPPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_SetBGPPeerMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBGPPeerMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).SetBGPPeerMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/SetBGPPeerMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).SetBGPPeerMaintenance(ctx, req.(*SetBGPPeerMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ShutdownBGPPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShutdownBGPPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ShutdownBGPPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/ShutdownBGPPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ShutdownBGPPeer(ctx, req.(*ShutdownBGPPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_StartBGPPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartBGPPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).StartBGPPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/StartBGPPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).StartBGPPeer(ctx, req.(*StartBGPPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ResetBGPPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetBGPPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ResetBGPPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/ResetBGPPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ResetBGPPeer(ctx, req.(*ResetBGPPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ManagementService_ReplayMRT_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayMRTRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagementServiceServer).ReplayMRT(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.management.ManagementService/ReplayMRT",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagementServiceServer).ReplayMRT(ctx, req.(*ReplayMRTRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _ManagementService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bio.management.ManagementService",
+	HandlerType: (*ManagementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SaveConfig",
+			Handler:    _ManagementService_SaveConfig_Handler,
+		},
+		{
+			MethodName: "SetProtocolState",
+			Handler:    _ManagementService_SetProtocolState_Handler,
+		},
+		{
+			MethodName: "GetLogLevels",
+			Handler:    _ManagementService_GetLogLevels_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _ManagementService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "GetEvents",
+			Handler:    _ManagementService_GetEvents_Handler,
+		},
+		{
+			MethodName: "GetFlightRecorder",
+			Handler:    _ManagementService_GetFlightRecorder_Handler,
+		},
+		{
+			MethodName: "SetBGPPeerDebug",
+			Handler:    _ManagementService_SetBGPPeerDebug_Handler,
+		},
+		{
+			MethodName: "ResetBGPCounters",
+			Handler:    _ManagementService_ResetBGPCounters_Handler,
+		},
+		{
+			MethodName: "GetLabelAllocations",
+			Handler:    _ManagementService_GetLabelAllocations_Handler,
+		},
+		{
+			MethodName: "SoftResetBGPPeer",
+			Handler:    _ManagementService_SoftResetBGPPeer_Handler,
+		},
+		{
+			MethodName: "SetBGPPeerMaintenance",
+			Handler:    _ManagementService_SetBGPPeerMaintenance_Handler,
+		},
+		{
+			MethodName: "ShutdownBGPPeer",
+			Handler:    _ManagementService_ShutdownBGPPeer_Handler,
+		},
+		{
+			MethodName: "StartBGPPeer",
+			Handler:    _ManagementService_StartBGPPeer_Handler,
+		},
+		{
+			MethodName: "ResetBGPPeer",
+			Handler:    _ManagementService_ResetBGPPeer_Handler,
+		},
+		{
+			MethodName: "ReplayMRT",
+			Handler:    _ManagementService_ReplayMRT_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CaptureBGP",
+			Handler:       _ManagementService_CaptureBGP_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/bio-routing/bio-rd/cmd/bio-rd/api/management.proto",
+}
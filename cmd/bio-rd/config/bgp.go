@@ -2,15 +2,29 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/pkg/errors"
 )
 
 type BGP struct {
-	Groups []*BGPGroup `yaml:"groups"`
+	Disabled        bool            `yaml:"disabled"`
+	ListenAddresses []string        `yaml:"listen_addresses"`
+	Groups          []*BGPGroup     `yaml:"groups"`
+	Aggregates      []*BGPAggregate `yaml:"aggregates"`
+	BMP             []*BMPStation   `yaml:"bmp"`
+	MRT             *MRTDump        `yaml:"mrt"`
+
+	// OptimalRouteReflection are the optimal route reflection groups (RFC9107) route reflector clients can be assigned to
+	OptimalRouteReflection []*ORRGroup `yaml:"optimal_route_reflection"`
+
+	// FlowSpecEnforcement installs the FlowSpec rules received from peers as nftables rules
+	FlowSpecEnforcement *FlowSpecEnforcement `yaml:"flowspec_enforcement"`
 }
 
 func (b *BGP) load(localAS uint32, policyOptions *PolicyOptions) error {
@@ -21,6 +35,227 @@ func (b *BGP) load(localAS uint32, policyOptions *PolicyOptions) error {
 		}
 	}
 
+	err := b.loadORRGroups()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range b.Aggregates {
+		err := a.load(localAS)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range b.BMP {
+		err := s.load()
+		if err != nil {
+			return err
+		}
+	}
+
+	if b.MRT != nil {
+		err := b.MRT.load()
+		if err != nil {
+			return err
+		}
+	}
+
+	if b.FlowSpecEnforcement != nil {
+		err := b.FlowSpecEnforcement.load()
+		if err != nil {
+			return errors.Wrap(err, "Unable to load flowspec_enforcement")
+		}
+	}
+
+	return nil
+}
+
+// MRTDump configures MRT dumps of all BGP peers (RFC6396)
+type MRTDump struct {
+	Directory string `yaml:"directory"`
+
+	// RIBInterval is the interval adj-RIB-in snapshots are written in seconds, 0 disables them
+	RIBInterval uint32 `yaml:"rib_interval"`
+
+	// UpdatesInterval is the interval the update log is rotated in seconds, 0 disables the log
+	UpdatesInterval uint32 `yaml:"updates_interval"`
+}
+
+// FlowSpecEnforcement is the config of the installation of received FlowSpec rules (RFC8955, RFC8956)
+type FlowSpecEnforcement struct {
+	// Table is the nftables table rules are installed into (default bio-rd-flowspec)
+	Table string `yaml:"table"`
+
+	// RedirectMarks are the firewall marks set on traffic redirected to a VRF or next hop
+	RedirectMarks []*FlowSpecRedirectMark `yaml:"redirect_marks"`
+}
+
+// FlowSpecRedirectMark maps the VRF (given by its route target) or the next hop of redirect actions to a firewall mark.
+// Policy routing rules have to steer marked traffic accordingly.
+type FlowSpecRedirectMark struct {
+	RouteTarget         string `yaml:"route_target"`
+	RouteTargetInternal *types.ExtendedCommunity
+	NextHop             string `yaml:"next_hop"`
+	NextHopIP           *bnet.IP
+	Mark                uint32 `yaml:"mark"`
+}
+
+func (f *FlowSpecEnforcement) load() error {
+	for _, m := range f.RedirectMarks {
+		if (m.RouteTarget == "") == (m.NextHop == "") {
+			return fmt.Errorf("redirect_marks require either a route_target or a next_hop")
+		}
+
+		if m.Mark == 0 {
+			return fmt.Errorf("redirect_marks require a non-zero mark")
+		}
+
+		if m.RouteTarget != "" {
+			rt, err := types.ParseRouteTarget(m.RouteTarget)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to parse route target %q", m.RouteTarget)
+			}
+
+			m.RouteTargetInternal = &rt
+			continue
+		}
+
+		addr, err := bnet.IPFromString(m.NextHop)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse next hop %q", m.NextHop)
+		}
+
+		m.NextHopIP = addr.Dedup()
+	}
+
+	return nil
+}
+
+// ORRGroup is an optimal route reflection group (RFC9107). Best paths advertised to its route reflector clients are
+// selected as if the route reflector was located at the virtual location of the group, given as the IGP costs from
+// there to the BGP next hops.
+type ORRGroup struct {
+	Name     string     `yaml:"name"`
+	IGPCosts []*IGPCost `yaml:"igp_costs"`
+}
+
+// IGPCost is the IGP cost to a BGP next hop
+type IGPCost struct {
+	Address   string `yaml:"address"`
+	AddressIP *bnet.IP
+	Cost      uint32 `yaml:"cost"`
+}
+
+func (o *ORRGroup) load() error {
+	if o.Name == "" {
+		return fmt.Errorf("Optimal route reflection group name is missing")
+	}
+
+	for _, c := range o.IGPCosts {
+		addr, err := bnet.IPFromString(c.Address)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse IGP cost address of optimal route reflection group %q", o.Name)
+		}
+
+		c.AddressIP = addr.Dedup()
+	}
+
+	return nil
+}
+
+// loadORRGroups loads the optimal route reflection groups and checks the groups neighbors are assigned to
+func (b *BGP) loadORRGroups() error {
+	names := make(map[string]struct{})
+	for _, o := range b.OptimalRouteReflection {
+		err := o.load()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := names[o.Name]; ok {
+			return fmt.Errorf("Optimal route reflection group %q is defined twice", o.Name)
+		}
+		names[o.Name] = struct{}{}
+	}
+
+	for _, g := range b.Groups {
+		for _, n := range g.Neighbors {
+			if n.ORRGroup == "" {
+				continue
+			}
+
+			if _, ok := names[n.ORRGroup]; !ok {
+				return fmt.Errorf("Neighbor %q: Optimal route reflection group %q is not defined", n.PeerAddress, n.ORRGroup)
+			}
+
+			if n.RouteReflectorClient == nil || !*n.RouteReflectorClient {
+				return fmt.Errorf("Neighbor %q: Optimal route reflection requires a route reflector client", n.PeerAddress)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *MRTDump) load() error {
+	if m.Directory == "" {
+		return fmt.Errorf("MRT dump directory is missing")
+	}
+
+	return nil
+}
+
+// BMPStation is a BGP monitoring station all peers are reported to (RFC7854)
+type BMPStation struct {
+	Address string `yaml:"address"`
+
+	// ReconnectInterval is the interval between connection attempts in seconds
+	ReconnectInterval uint16 `yaml:"reconnect_interval"`
+
+	// StatsInterval is the interval statistics reports are sent in seconds, 0 disables them
+	StatsInterval uint16 `yaml:"stats_interval"`
+
+	// AdjRIBOut enables the monitoring of the post-policy adj-RIB-out (RFC8671)
+	AdjRIBOut bool `yaml:"adj_rib_out"`
+
+	// LocRIB enables the monitoring of the Loc-RIB (RFC9069)
+	LocRIB bool `yaml:"loc_rib"`
+}
+
+func (s *BMPStation) load() error {
+	_, _, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid BMP station address %q", s.Address)
+	}
+
+	return nil
+}
+
+// BGPAggregate is a route generated while more specific BGP routes exist
+type BGPAggregate struct {
+	Prefix       string `yaml:"prefix"`
+	PrefixParsed *bnet.Prefix
+	LocalAS      uint32 `yaml:"local_as"`
+
+	// SummaryOnly suppresses the advertisement of the more specific routes
+	SummaryOnly bool `yaml:"summary_only"`
+
+	// ASSet carries the AS numbers of the more specific routes as AS_SET
+	ASSet bool `yaml:"as_set"`
+}
+
+func (a *BGPAggregate) load(localAS uint32) error {
+	pfx, err := bnet.PrefixFromString(a.Prefix)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to parse aggregate %q", a.Prefix)
+	}
+	a.PrefixParsed = pfx.Dedup()
+
+	if a.LocalAS == 0 {
+		a.LocalAS = localAS
+	}
+
 	return nil
 }
 
@@ -28,18 +263,63 @@ type BGPGroup struct {
 	Name              string `yaml:"name"`
 	LocalAddress      string `yaml:"local_address"`
 	LocalAddressIP    *bnet.IP
-	TTL               uint8          `yaml:"ttl"`
-	AuthenticationKey string         `yaml:"authentication_key"`
-	PeerAS            uint32         `yaml:"peer_as"`
-	LocalAS           uint32         `yaml:"local_as"`
-	HoldTime          uint16         `yaml:"hold_time"`
-	Multipath         *Multipath     `yaml:"multipath"`
-	Import            []string       `yaml:"import"`
-	Export            []string       `yaml:"export"`
-	RouteServerClient bool           `yaml:"route_server_client"`
-	Passive           bool           `yaml:"passive"`
-	Neighbors         []*BGPNeighbor `yaml:"neighbors"`
-	AFIs              []*AFI         `yaml:"afi"`
+	TTL               uint8             `yaml:"ttl"`
+	TTLSecurityHops   uint8             `yaml:"ttl_security_hops"`
+	EBGPMultihop      uint8             `yaml:"ebgp_multihop"`
+	AuthenticationKey string            `yaml:"authentication_key"` // plaintext or secret reference (env:, file:, exec:), see resolveSecret
+	PeerAS            uint32            `yaml:"peer_as"`
+	LocalAS           uint32            `yaml:"local_as"`
+	HoldTime          uint16            `yaml:"hold_time"`
+	Multipath         *Multipath        `yaml:"multipath"`
+	Import            []string          `yaml:"import"`
+	Export            []string          `yaml:"export"`
+	RouteServerClient bool              `yaml:"route_server_client"`
+	Passive           bool              `yaml:"passive"`
+	ActiveOnly        bool              `yaml:"active_only"`
+	DynamicCapability bool              `yaml:"dynamic_capability"`
+	NextHopTracking   bool              `yaml:"next_hop_tracking"`
+	ExtendedNextHop   bool              `yaml:"extended_next_hop"`
+	RemovePrivateAS   string            `yaml:"remove_private_as"`
+	NextHopSelf       string            `yaml:"next_hop_self"`
+	AllowASIn         uint8             `yaml:"allowas_in"`
+	ASOverride        bool              `yaml:"as_override"`
+	MaxReconnect      uint16            `yaml:"max_reconnect_interval"`
+	Neighbors         []*BGPNeighbor    `yaml:"neighbors"`
+	AFIs              []*AFI            `yaml:"afi"`
+	GracefulRestart   *GracefulRestart  `yaml:"graceful_restart"`
+	LocalASMigration  *LocalASMigration `yaml:"local_as_migration"`
+
+	// AdvertisementInterval (seconds) batches the changes advertised to the neighbors (MRAI)
+	AdvertisementInterval       uint16 `yaml:"advertisement_interval"`
+	NoAdvertisementIntervalIBGP bool   `yaml:"no_advertisement_interval_ibgp"`
+
+	// IgnoreWellKnownCommunities advertises paths regardless of NO_EXPORT, NO_ADVERTISE, NO_EXPORT_SUBCONFED and NOPEER.
+	// BilateralPeer marks the neighbors as bilateral peers, paths carrying NOPEER are not advertised to them.
+	IgnoreWellKnownCommunities bool `yaml:"ignore_well_known_communities"`
+	BilateralPeer              bool `yaml:"bilateral_peer"`
+
+	// Route reflection (RFC4456). ClusterID defaults to the router ID, all other knobs default to enabled.
+	RouteReflectorClient     bool   `yaml:"route_reflector_client"`
+	ClusterID                string `yaml:"cluster_id"`
+	ClientToClientReflection *bool  `yaml:"client_to_client_reflection"`
+	OriginatorIDCheck        *bool  `yaml:"originator_id_check"`
+	ClusterListCheck         *bool  `yaml:"cluster_list_check"`
+	ORRGroup                 string `yaml:"orr_group"`
+
+	// ShutdownCommunication is sent with administrative shutdowns and resets unless the operator gives a message (RFC9003)
+	ShutdownCommunication string `yaml:"shutdown_communication"`
+
+	// RecordDirectory enables recording the raw byte stream of sessions to files in this directory (for debugging)
+	RecordDirectory string `yaml:"record_directory"`
+
+	// FlowSpecNoValidation accepts FlowSpec rules failing the validation against the unicast routes (RFC8955 6)
+	FlowSpecNoValidation bool `yaml:"flowspec_no_validation"`
+
+	// Dynamic neighbors: Sessions from all addresses within the listen ranges are accepted using the group settings
+	ListenRanges            []string `yaml:"listen_ranges"`
+	ListenRangePrefixes     []*bnet.Prefix
+	MaxDynamicPeers         uint `yaml:"max_dynamic_peers"`
+	DynamicNeighborTemplate *BGPNeighbor
 }
 
 func (bg *BGPGroup) load(localAS uint32, policyOptions *PolicyOptions) error {
@@ -60,68 +340,255 @@ func (bg *BGPGroup) load(localAS uint32, policyOptions *PolicyOptions) error {
 		bg.HoldTime = 90
 	}
 
+	enabled := true
+	if bg.ClientToClientReflection == nil {
+		bg.ClientToClientReflection = &enabled
+	}
+
+	if bg.OriginatorIDCheck == nil {
+		bg.OriginatorIDCheck = &enabled
+	}
+
+	if bg.ClusterListCheck == nil {
+		bg.ClusterListCheck = &enabled
+	}
+
 	for _, n := range bg.Neighbors {
-		if n.RouteServerClient == nil {
-			n.RouteServerClient = &bg.RouteServerClient
+		err := bg.loadNeighbor(n, localAS, policyOptions)
+		if err != nil {
+			return err
 		}
+	}
 
-		if n.Passive == nil {
-			n.Passive = &bg.Passive
+	for _, r := range bg.ListenRanges {
+		pfx, err := bnet.PrefixFromString(r)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse listen range %q", r)
 		}
 
-		if n.LocalAddress == "" {
-			n.LocalAddressIP = bg.LocalAddressIP
-		}
+		bg.ListenRangePrefixes = append(bg.ListenRangePrefixes, pfx.Dedup())
+	}
 
-		if n.TTL == 0 {
-			n.TTL = bg.TTL
+	if len(bg.ListenRanges) > 0 {
+		if bg.AuthenticationKey != "" {
+			return fmt.Errorf("authentication_key is not supported with listen_ranges in group %q", bg.Name)
 		}
 
-		if n.AuthenticationKey == "" {
-			n.AuthenticationKey = bg.AuthenticationKey
+		// Dynamic neighbors are instantiated from a neighbor inheriting all settings of the group
+		bg.DynamicNeighborTemplate = &BGPNeighbor{
+			PeerAddress: bg.ListenRangePrefixes[0].Addr().String(),
+			Import:      bg.Import,
+			Export:      bg.Export,
 		}
 
-		if n.LocalAS == 0 {
-			n.LocalAS = localAS
+		err := bg.loadNeighbor(bg.DynamicNeighborTemplate, localAS, policyOptions)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid dynamic neighbor settings in group %q", bg.Name)
 		}
+	}
 
-		if n.LocalAS == 0 {
-			return fmt.Errorf("local_as 0 is invalid")
-		}
+	return nil
+}
 
-		if n.PeerAS == 0 {
-			n.PeerAS = bg.PeerAS
-		}
+// loadNeighbor applies the group defaults to n and loads it
+func (bg *BGPGroup) loadNeighbor(n *BGPNeighbor, localAS uint32, policyOptions *PolicyOptions) error {
+	n.PeerGroup = bg.Name
 
-		if n.PeerAS == 0 {
-			return fmt.Errorf("peer_as 0 is invalid")
-		}
+	if n.RouteServerClient == nil {
+		n.RouteServerClient = &bg.RouteServerClient
+	}
 
-		if n.HoldTime == 0 {
-			n.HoldTime = bg.HoldTime
-		}
+	if n.RouteReflectorClient == nil {
+		n.RouteReflectorClient = &bg.RouteReflectorClient
+	}
 
-		err := n.load(policyOptions)
-		if err != nil {
-			return err
-		}
+	if n.ClusterID == "" {
+		n.ClusterID = bg.ClusterID
+	}
+
+	if n.ClientToClientReflection == nil {
+		n.ClientToClientReflection = bg.ClientToClientReflection
+	}
+
+	if n.OriginatorIDCheck == nil {
+		n.OriginatorIDCheck = bg.OriginatorIDCheck
+	}
+
+	if n.ClusterListCheck == nil {
+		n.ClusterListCheck = bg.ClusterListCheck
+	}
+
+	if n.ORRGroup == "" {
+		n.ORRGroup = bg.ORRGroup
+	}
+
+	if n.ShutdownCommunication == "" {
+		n.ShutdownCommunication = bg.ShutdownCommunication
+	}
+
+	if n.RecordDirectory == "" {
+		n.RecordDirectory = bg.RecordDirectory
+	}
+
+	if n.Passive == nil {
+		n.Passive = &bg.Passive
+	}
+
+	if n.ActiveOnly == nil {
+		n.ActiveOnly = &bg.ActiveOnly
+	}
+
+	if n.MaxReconnect == 0 {
+		n.MaxReconnect = bg.MaxReconnect
+	}
+
+	if n.AdvertisementInterval == 0 {
+		n.AdvertisementInterval = bg.AdvertisementInterval
+	}
+
+	if n.NoAdvertisementIntervalIBGP == nil {
+		n.NoAdvertisementIntervalIBGP = &bg.NoAdvertisementIntervalIBGP
+	}
+
+	if n.IgnoreWellKnownCommunities == nil {
+		n.IgnoreWellKnownCommunities = &bg.IgnoreWellKnownCommunities
+	}
+
+	if n.BilateralPeer == nil {
+		n.BilateralPeer = &bg.BilateralPeer
+	}
+
+	if n.DynamicCapability == nil {
+		n.DynamicCapability = &bg.DynamicCapability
+	}
+
+	if n.NextHopTracking == nil {
+		n.NextHopTracking = &bg.NextHopTracking
+	}
+
+	if n.ExtendedNextHop == nil {
+		n.ExtendedNextHop = &bg.ExtendedNextHop
+	}
+
+	if n.FlowSpecNoValidation == nil {
+		n.FlowSpecNoValidation = &bg.FlowSpecNoValidation
+	}
+
+	if n.RemovePrivateAS == "" {
+		n.RemovePrivateAS = bg.RemovePrivateAS
+	}
+
+	if n.NextHopSelf == "" {
+		n.NextHopSelf = bg.NextHopSelf
+	}
+
+	if n.AllowASIn == nil {
+		n.AllowASIn = &bg.AllowASIn
+	}
+
+	if n.ASOverride == nil {
+		n.ASOverride = &bg.ASOverride
+	}
+
+	if n.LocalAddress == "" {
+		n.LocalAddressIP = bg.LocalAddressIP
+	}
+
+	if n.TTL == 0 {
+		n.TTL = bg.TTL
+	}
+
+	if n.TTLSecurityHops == 0 {
+		n.TTLSecurityHops = bg.TTLSecurityHops
+	}
+
+	if n.EBGPMultihop == 0 {
+		n.EBGPMultihop = bg.EBGPMultihop
+	}
+
+	if n.AuthenticationKey == "" {
+		n.AuthenticationKey = bg.AuthenticationKey
+	}
+
+	if n.LocalAS == 0 {
+		n.LocalAS = localAS
+	}
+
+	if n.LocalAS == 0 {
+		return fmt.Errorf("local_as 0 is invalid")
+	}
+
+	if n.PeerAS == 0 {
+		n.PeerAS = bg.PeerAS
 	}
 
+	if n.PeerAS == 0 {
+		return fmt.Errorf("peer_as 0 is invalid")
+	}
+
+	if n.HoldTime == 0 {
+		n.HoldTime = bg.HoldTime
+	}
+
+	if len(n.AFIs) == 0 {
+		n.AFIs = bg.AFIs
+	}
+
+	if n.GracefulRestart == nil {
+		n.GracefulRestart = bg.GracefulRestart
+	}
+
+	if n.LocalASMigration == nil {
+		n.LocalASMigration = bg.LocalASMigration
+	}
+
+	if n.Multipath == nil {
+		n.Multipath = bg.Multipath
+	}
+
+	return n.load(policyOptions)
+}
+
+// loadTTL validates the TTL settings. ebgp_multihop sets the TTL of eBGP sessions.
+func (bn *BGPNeighbor) loadTTL() error {
+	if bn.TTLSecurityHops != 0 && (bn.TTL != 0 || bn.EBGPMultihop != 0) {
+		return fmt.Errorf("ttl_security_hops of peer %q can not be combined with ttl or ebgp_multihop", bn.PeerAddress)
+	}
+
+	if bn.EBGPMultihop == 0 {
+		return nil
+	}
+
+	if bn.PeerAS == bn.LocalAS {
+		return fmt.Errorf("ebgp_multihop of peer %q requires an eBGP peer", bn.PeerAddress)
+	}
+
+	if bn.TTL != 0 && bn.TTL != bn.EBGPMultihop {
+		return fmt.Errorf("ttl and ebgp_multihop of peer %q differ", bn.PeerAddress)
+	}
+
+	bn.TTL = bn.EBGPMultihop
 	return nil
 }
 
+// Multipath enables the use of equal cost paths received from a neighbor together with paths of other neighbors
 type Multipath struct {
-	Enable    bool `yaml:"enable"`
+	Enable bool `yaml:"enable"`
+
+	// MulipleAS also allows paths received from different neighboring ASes
 	MulipleAS bool `yaml:"multiple_as"`
 }
 
 type BGPNeighbor struct {
+	PeerGroup         string // name of the group the neighbor is configured in
 	PeerAddress       string `yaml:"peer_address"`
 	PeerAddressIP     *bnet.IP
 	LocalAddress      string `yaml:"local_address"`
 	LocalAddressIP    *bnet.IP
 	TTL               uint8  `yaml:"ttl"`
-	AuthenticationKey string `yaml:"authentication_key"`
+	TTLSecurityHops   uint8  `yaml:"ttl_security_hops"`  // GTSM (RFC5082)
+	EBGPMultihop      uint8  `yaml:"ebgp_multihop"`      // hops to eBGP peers not directly connected
+	AuthenticationKey string `yaml:"authentication_key"` // plaintext or secret reference (env:, file:, exec:), see resolveSecret
 	PeerAS            uint32 `yaml:"peer_as"`
 	LocalAS           uint32 `yaml:"local_as"`
 	HoldTime          uint16 `yaml:"hold_time"`
@@ -133,9 +600,47 @@ type BGPNeighbor struct {
 	ExportFilterChain filter.Chain
 	RouteServerClient *bool  `yaml:"route_server_client"`
 	Passive           *bool  `yaml:"passive"`
+	ActiveOnly        *bool  `yaml:"active_only"` // never accept connections from the peer
+	DynamicCapability *bool  `yaml:"dynamic_capability"`
+	NextHopTracking   *bool  `yaml:"next_hop_tracking"`
+	ExtendedNextHop   *bool  `yaml:"extended_next_hop"` // IPv4 routes via IPv6 next hops (RFC8950)
+	RemovePrivateAS   string `yaml:"remove_private_as"` // remove, all or replace
+	NextHopSelf       string `yaml:"next_hop_self"`     // enable, force (including reflected paths) or disable
+	AllowASIn         *uint8 `yaml:"allowas_in"`
+	ASOverride        *bool  `yaml:"as_override"`
+	MaxReconnect      uint16 `yaml:"max_reconnect_interval"` // seconds, the reconnect interval doubles up to this value
 	ClusterID         string `yaml:"cluster_id"`
 	ClusterIDIP       *bnet.IP
-	AFIs              []*AFI `yaml:"afi"`
+	AFIs              []*AFI            `yaml:"afi"`
+	GracefulRestart   *GracefulRestart  `yaml:"graceful_restart"`
+	LocalASMigration  *LocalASMigration `yaml:"local_as_migration"`
+
+	// AdvertisementInterval (seconds) is the minimum interval between advertisements (MRAI) of all address families.
+	// NoAdvertisementIntervalIBGP disables it for iBGP sessions, e.g. route reflector clients of a group.
+	AdvertisementInterval       uint16 `yaml:"advertisement_interval"`
+	NoAdvertisementIntervalIBGP *bool  `yaml:"no_advertisement_interval_ibgp"`
+
+	// IgnoreWellKnownCommunities advertises paths regardless of NO_EXPORT, NO_ADVERTISE, NO_EXPORT_SUBCONFED and NOPEER.
+	// BilateralPeer marks the neighbor as bilateral peer (RFC3765), paths carrying NOPEER are not advertised to it.
+	IgnoreWellKnownCommunities *bool `yaml:"ignore_well_known_communities"`
+	BilateralPeer              *bool `yaml:"bilateral_peer"`
+
+	RouteReflectorClient     *bool `yaml:"route_reflector_client"`
+	ClientToClientReflection *bool `yaml:"client_to_client_reflection"`
+	OriginatorIDCheck        *bool `yaml:"originator_id_check"`
+	ClusterListCheck         *bool `yaml:"cluster_list_check"`
+
+	// ORRGroup is the optimal route reflection group of a route reflector client
+	ORRGroup string `yaml:"orr_group"`
+
+	// ShutdownCommunication is sent with administrative shutdowns and resets unless the operator gives a message (RFC9003)
+	ShutdownCommunication string `yaml:"shutdown_communication"`
+
+	// RecordDirectory enables recording the raw byte stream of sessions to files in this directory (for debugging)
+	RecordDirectory string `yaml:"record_directory"`
+
+	// FlowSpecNoValidation accepts FlowSpec rules failing the validation against the unicast routes (RFC8955 6)
+	FlowSpecNoValidation *bool `yaml:"flowspec_no_validation"`
 }
 
 func (bn *BGPNeighbor) load(po *PolicyOptions) error {
@@ -162,8 +667,58 @@ func (bn *BGPNeighbor) load(po *PolicyOptions) error {
 	}
 
 	bn.PeerAddressIP = b.Dedup()
+
+	if bn.ClusterID != "" {
+		c, err := bnet.IPFromString(bn.ClusterID)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to parse cluster_id of peer %q", bn.PeerAddress)
+		}
+
+		if !c.IsIPv4() {
+			return fmt.Errorf("cluster_id of peer %q must be an IPv4 address", bn.PeerAddress)
+		}
+
+		bn.ClusterIDIP = c.Dedup()
+	}
+
+	if bn.AuthenticationKey != "" {
+		k, err := resolveSecret(bn.AuthenticationKey)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to resolve authentication key of peer %q", bn.PeerAddress)
+		}
+
+		bn.AuthenticationKey = k
+	}
+
 	bn.HoldTimeDuration = time.Second * time.Duration(bn.HoldTime)
 
+	if bn.Multipath != nil && bn.Multipath.MulipleAS && !bn.Multipath.Enable {
+		return fmt.Errorf("multipath multiple_as of peer %q requires multipath to be enabled", bn.PeerAddress)
+	}
+
+	err = bn.loadTTL()
+	if err != nil {
+		return err
+	}
+
+	if bn.Passive != nil && *bn.Passive && bn.ActiveOnly != nil && *bn.ActiveOnly {
+		return fmt.Errorf("Peer %q can not be passive and active_only at the same time", bn.PeerAddress)
+	}
+
+	if len(bn.ShutdownCommunication) > packet.MaxShutdownCommunicationLen {
+		return fmt.Errorf("shutdown_communication of peer %q must not exceed %d bytes", bn.PeerAddress, packet.MaxShutdownCommunicationLen)
+	}
+
+	switch bn.RemovePrivateAS {
+	case "", "remove", "all", "replace":
+	default:
+		return fmt.Errorf("remove_private_as of peer %q must be remove, all or replace", bn.PeerAddress)
+	}
+
+	if !validNextHopSelf(bn.NextHopSelf) {
+		return fmt.Errorf("next_hop_self of peer %q must be enable, force or disable", bn.PeerAddress)
+	}
+
 	for i := range bn.Import {
 		f := po.getPolicyStatementFilter(bn.Import[i])
 		if f == nil {
@@ -181,17 +736,252 @@ func (bn *BGPNeighbor) load(po *PolicyOptions) error {
 
 		bn.ExportFilterChain = append(bn.ExportFilterChain, f)
 	}
+
+	afis := make(map[string]struct{})
+	for _, afi := range bn.AFIs {
+		err := afi.load()
+		if err != nil {
+			return errors.Wrapf(err, "Invalid afi of peer %q", bn.PeerAddress)
+		}
+
+		key := afi.Name + "/" + afi.SAFI.Name
+		if _, exists := afis[key]; exists {
+			return fmt.Errorf("Duplicate afi %q safi %q of peer %q", afi.Name, afi.SAFI.Name, bn.PeerAddress)
+		}
+		afis[key] = struct{}{}
+	}
+
+	if bn.GracefulRestart != nil {
+		err := bn.GracefulRestart.load()
+		if err != nil {
+			return errors.Wrapf(err, "Invalid graceful_restart of peer %q", bn.PeerAddress)
+		}
+	}
+
+	if bn.LocalASMigration != nil {
+		err := bn.LocalASMigration.load(bn.LocalAS)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid local_as_migration of peer %q", bn.PeerAddress)
+		}
+	}
+
+	return nil
+}
+
+// LocalASMigration presents another AS than local_as to a neighbor, e.g. while migrating between ASes
+type LocalASMigration struct {
+	AS        uint32 `yaml:"as"`
+	NoPrepend bool   `yaml:"no_prepend"`
+	ReplaceAS bool   `yaml:"replace_as"`
+}
+
+func (l *LocalASMigration) load(localAS uint32) error {
+	if l.AS == 0 {
+		return fmt.Errorf("as 0 is invalid")
+	}
+
+	if l.AS == localAS {
+		return fmt.Errorf("as must differ from local_as")
+	}
+
 	return nil
 }
 
+// GracefulRestart configures BGP graceful restart (RFC4724). Times are in seconds, 0 selects the default.
+type GracefulRestart struct {
+	Disabled      bool   `yaml:"disabled"`
+	RestartTime   uint16 `yaml:"restart_time"`
+	StalePathTime uint16 `yaml:"stale_path_time"`
+}
+
+func (g *GracefulRestart) load() error {
+	if g.RestartTime > packet.MaxGracefulRestartTime {
+		return fmt.Errorf("restart_time must not exceed %d", packet.MaxGracefulRestartTime)
+	}
+
+	return nil
+}
+
+// AFI names
+const (
+	AFIIPv4      = "ipv4"
+	AFIIPv6      = "ipv6"
+	AFILinkState = "link-state"
+)
+
+// SAFI names
+const (
+	SAFIUnicast        = "unicast"
+	SAFILabeledUnicast = "labeled-unicast"
+	SAFIVPN            = "vpn"
+	SAFILinkState      = "link-state"
+	SAFIRouteTarget    = "route-target"
+	SAFIFlowSpec       = "flowspec"
+	SAFISRPolicy       = "sr-policy"
+)
+
+// next_hop_self modes
+const (
+	NextHopSelfEnable  = "enable"
+	NextHopSelfForce   = "force"
+	NextHopSelfDisable = "disable"
+)
+
 type AFI struct {
 	Name string `yaml:"name"`
 	SAFI SAFI   `yaml:"safi"`
 }
 
+func (a *AFI) load() error {
+	if a.Name == AFILinkState {
+		return a.loadLinkState()
+	}
+
+	if a.SAFI.Name == SAFIRouteTarget {
+		return a.loadRouteTarget()
+	}
+
+	if a.SAFI.Name == SAFIFlowSpec {
+		return a.loadFlowSpec()
+	}
+
+	if a.SAFI.Name == SAFISRPolicy {
+		return a.loadSRPolicy()
+	}
+
+	if a.Name != AFIIPv4 && a.Name != AFIIPv6 {
+		return fmt.Errorf("Unknown afi %q", a.Name)
+	}
+
+	if a.SAFI.Name == "" {
+		a.SAFI.Name = SAFIUnicast
+	}
+
+	if a.SAFI.Name != SAFIUnicast && a.SAFI.Name != SAFILabeledUnicast && a.SAFI.Name != SAFIVPN {
+		return fmt.Errorf("Unsupported safi %q", a.SAFI.Name)
+	}
+
+	if a.SAFI.Name == SAFIVPN && a.SAFI.AddPath != nil {
+		return fmt.Errorf("add_path is not supported for safi %q", a.SAFI.Name)
+	}
+
+	if a.SAFI.Name == SAFIVPN && a.SAFI.AdvertisementInterval != nil {
+		return fmt.Errorf("advertisement_interval is not supported for safi %q", a.SAFI.Name)
+	}
+
+	if a.SAFI.Name == SAFIVPN && a.SAFI.NextHopSelf != "" {
+		return fmt.Errorf("next_hop_self is not supported for safi %q", a.SAFI.Name)
+	}
+
+	if !validNextHopSelf(a.SAFI.NextHopSelf) {
+		return fmt.Errorf("next_hop_self must be enable, force or disable")
+	}
+
+	if a.SAFI.AddPath != nil && a.SAFI.AddPath.Send != nil {
+		send := a.SAFI.AddPath.Send
+		if !send.Multipath && send.PathCount < 2 {
+			return fmt.Errorf("add_path send requires multipath or a path_count of at least 2")
+		}
+	}
+
+	if l := a.SAFI.PrefixLimit; l != nil {
+		if a.SAFI.Name == SAFIVPN {
+			return fmt.Errorf("prefix_limit is not supported for safi %q", a.SAFI.Name)
+		}
+
+		if l.Max == 0 {
+			return fmt.Errorf("prefix_limit requires max to be set")
+		}
+
+		if l.WarningThreshold > 100 {
+			return fmt.Errorf("prefix_limit warning_threshold must be a percentage")
+		}
+
+		for _, t := range l.WarningThresholds {
+			if t == 0 || t > 100 {
+				return fmt.Errorf("prefix_limit warning_thresholds must be percentages")
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadLinkState validates the BGP-LS address family which only supports its own safi
+func (a *AFI) loadLinkState() error {
+	if a.SAFI.Name == "" {
+		a.SAFI.Name = SAFILinkState
+	}
+
+	if a.SAFI.Name != SAFILinkState {
+		return fmt.Errorf("Unsupported safi %q for afi %q", a.SAFI.Name, a.Name)
+	}
+
+	if a.SAFI.AddPath != nil || a.SAFI.PrefixLimit != nil || a.SAFI.AdvertisementInterval != nil || a.SAFI.NextHopSelf != "" {
+		return fmt.Errorf("add_path, prefix_limit, advertisement_interval and next_hop_self are not supported for afi %q", a.Name)
+	}
+
+	return nil
+}
+
+// loadRouteTarget validates the route target constraint address family (RFC4684) which is defined for IPv4 only
+func (a *AFI) loadRouteTarget() error {
+	if a.Name != AFIIPv4 {
+		return fmt.Errorf("safi %q is not supported for afi %q", a.SAFI.Name, a.Name)
+	}
+
+	if a.SAFI.AddPath != nil || a.SAFI.PrefixLimit != nil || a.SAFI.AdvertisementInterval != nil || a.SAFI.NextHopSelf != "" {
+		return fmt.Errorf("add_path, prefix_limit, advertisement_interval and next_hop_self are not supported for safi %q", a.SAFI.Name)
+	}
+
+	return nil
+}
+
+// loadFlowSpec validates the FlowSpec address families (RFC8955, RFC8956). Rules are received only.
+func (a *AFI) loadFlowSpec() error {
+	if a.Name != AFIIPv4 && a.Name != AFIIPv6 {
+		return fmt.Errorf("safi %q is not supported for afi %q", a.SAFI.Name, a.Name)
+	}
+
+	if a.SAFI.AddPath != nil || a.SAFI.PrefixLimit != nil || a.SAFI.AdvertisementInterval != nil || a.SAFI.NextHopSelf != "" {
+		return fmt.Errorf("add_path, prefix_limit, advertisement_interval and next_hop_self are not supported for safi %q", a.SAFI.Name)
+	}
+
+	return nil
+}
+
+// loadSRPolicy validates the SR Policy address families (RFC9830). Candidate paths are received only.
+func (a *AFI) loadSRPolicy() error {
+	if a.Name != AFIIPv4 && a.Name != AFIIPv6 {
+		return fmt.Errorf("safi %q is not supported for afi %q", a.SAFI.Name, a.Name)
+	}
+
+	if a.SAFI.AddPath != nil || a.SAFI.PrefixLimit != nil || a.SAFI.AdvertisementInterval != nil || a.SAFI.NextHopSelf != "" {
+		return fmt.Errorf("add_path, prefix_limit, advertisement_interval and next_hop_self are not supported for safi %q", a.SAFI.Name)
+	}
+
+	return nil
+}
+
 type SAFI struct {
-	Name    string   `yaml:"name"`
-	AddPath *AddPath `yaml:"add_path"`
+	Name        string       `yaml:"name"`
+	AddPath     *AddPath     `yaml:"add_path"`
+	PrefixLimit *PrefixLimit `yaml:"prefix_limit"`
+
+	// AdvertisementInterval (seconds) overrides the advertisement_interval of the neighbor for the address family
+	AdvertisementInterval *uint16 `yaml:"advertisement_interval"`
+
+	// NextHopSelf overrides the next_hop_self of the neighbor for the address family
+	NextHopSelf string `yaml:"next_hop_self"`
+}
+
+func validNextHopSelf(s string) bool {
+	switch s {
+	case "", NextHopSelfEnable, NextHopSelfForce, NextHopSelfDisable:
+		return true
+	}
+
+	return false
 }
 
 type AddPath struct {
@@ -203,3 +993,12 @@ type AddPathSend struct {
 	Multipath bool  `yaml:"multipath"`
 	PathCount uint8 `yaml:"path_count"`
 }
+
+// PrefixLimit limits the number of prefixes received. The session is torn down if max is exceeded and restarted after restart_interval seconds (never if 0).
+type PrefixLimit struct {
+	Max               uint64  `yaml:"max"`
+	WarningThreshold  uint8   `yaml:"warning_threshold"`  // percentage of max
+	WarningThresholds []uint8 `yaml:"warning_thresholds"` // further percentages of max for escalating warnings
+	WarningOnly       bool    `yaml:"warning_only"`
+	RestartInterval   uint32  `yaml:"restart_interval"`
+}
@@ -0,0 +1,1161 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBGPGroupLoadAFIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *BGPGroup
+		wantFail bool
+		expected []*AFI
+	}{
+		{
+			name: "Inherit from group",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				AFIs: []*AFI{
+					{
+						Name: "ipv6",
+						SAFI: SAFI{
+							AddPath: &AddPath{
+								Receive: true,
+							},
+						},
+					},
+				},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "2001:db8::1",
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv6",
+					SAFI: SAFI{
+						Name: "unicast",
+						AddPath: &AddPath{
+							Receive: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Neighbor overrides group",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				AFIs: []*AFI{
+					{
+						Name: "ipv6",
+					},
+				},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									Name: "unicast",
+									AddPath: &AddPath{
+										Send: &AddPathSend{
+											PathCount: 4,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "unicast",
+						AddPath: &AddPath{
+							Send: &AddPathSend{
+								PathCount: 4,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Link state",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "link-state"}},
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "link-state",
+					SAFI: SAFI{
+						Name: "link-state",
+					},
+				},
+			},
+		},
+		{
+			name: "Link state with unicast SAFI",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "link-state", SAFI: SAFI{Name: "unicast"}}},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Route target constraint",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv4", SAFI: SAFI{Name: "route-target"}}},
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "route-target",
+					},
+				},
+			},
+		},
+		{
+			name: "Route target constraint for IPv6",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv6", SAFI: SAFI{Name: "route-target"}}},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unknown AFI",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipx"}},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unsupported SAFI",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv4", SAFI: SAFI{Name: "multicast"}}},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Duplicate AFI",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv4"}, {Name: "ipv4"}},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unicast and VPN",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv4"}, {Name: "ipv4", SAFI: SAFI{Name: "vpn"}}},
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "unicast",
+					},
+				},
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "vpn",
+					},
+				},
+			},
+		},
+		{
+			name: "Unicast and labeled unicast",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs:        []*AFI{{Name: "ipv4"}, {Name: "ipv4", SAFI: SAFI{Name: "labeled-unicast"}}},
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "unicast",
+					},
+				},
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "labeled-unicast",
+					},
+				},
+			},
+		},
+		{
+			name: "Add path for VPN",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									Name: "vpn",
+									AddPath: &AddPath{
+										Receive: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Add path send without path count",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									AddPath: &AddPath{
+										Send: &AddPathSend{},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Prefix limit",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				AFIs: []*AFI{
+					{
+						Name: "ipv4",
+						SAFI: SAFI{
+							PrefixLimit: &PrefixLimit{
+								Max:              1000,
+								WarningThreshold: 80,
+								RestartInterval:  300,
+							},
+						},
+					},
+				},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expected: []*AFI{
+				{
+					Name: "ipv4",
+					SAFI: SAFI{
+						Name: "unicast",
+						PrefixLimit: &PrefixLimit{
+							Max:              1000,
+							WarningThreshold: 80,
+							RestartInterval:  300,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Prefix limit without max",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									PrefixLimit: &PrefixLimit{
+										WarningThreshold: 80,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Prefix limit with invalid warning threshold",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									PrefixLimit: &PrefixLimit{
+										Max:              1000,
+										WarningThreshold: 120,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Prefix limit with invalid warning thresholds",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									PrefixLimit: &PrefixLimit{
+										Max:               1000,
+										WarningThresholds: []uint8{80, 0},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, test.group.Neighbors[0].AFIs, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadGracefulRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *BGPGroup
+		wantFail bool
+		expected *GracefulRestart
+	}{
+		{
+			name: "Inherit from group",
+			group: &BGPGroup{
+				PeerAS:          65001,
+				GracefulRestart: &GracefulRestart{RestartTime: 60},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expected: &GracefulRestart{RestartTime: 60},
+		},
+		{
+			name: "Neighbor disables",
+			group: &BGPGroup{
+				PeerAS:          65001,
+				GracefulRestart: &GracefulRestart{RestartTime: 60},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:     "192.0.2.1",
+						GracefulRestart: &GracefulRestart{Disabled: true},
+					},
+				},
+			},
+			expected: &GracefulRestart{Disabled: true},
+		},
+		{
+			name: "Restart time too long",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:     "192.0.2.1",
+						GracefulRestart: &GracefulRestart{RestartTime: 4096},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, test.group.Neighbors[0].GracefulRestart, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadMultipath(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *BGPGroup
+		wantFail bool
+		expected *Multipath
+	}{
+		{
+			name: "Inherit from group",
+			group: &BGPGroup{
+				PeerAS:    65001,
+				Multipath: &Multipath{Enable: true},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expected: &Multipath{Enable: true},
+		},
+		{
+			name: "Neighbor overrides",
+			group: &BGPGroup{
+				PeerAS:    65001,
+				Multipath: &Multipath{Enable: true},
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						Multipath:   &Multipath{Enable: true, MulipleAS: true},
+					},
+				},
+			},
+			expected: &Multipath{Enable: true, MulipleAS: true},
+		},
+		{
+			name: "Multiple AS without multipath",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						Multipath:   &Multipath{MulipleAS: true},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, test.group.Neighbors[0].Multipath, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadAdvertisementInterval(t *testing.T) {
+	interval := uint16(5)
+	tests := []struct {
+		name             string
+		group            *BGPGroup
+		wantFail         bool
+		expectedInterval uint16
+		expectedNoIBGP   bool
+	}{
+		{
+			name: "Inherit from group",
+			group: &BGPGroup{
+				PeerAS:                      65001,
+				AdvertisementInterval:       30,
+				NoAdvertisementIntervalIBGP: true,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expectedInterval: 30,
+			expectedNoIBGP:   true,
+		},
+		{
+			name: "Neighbor overrides",
+			group: &BGPGroup{
+				PeerAS:                      65001,
+				AdvertisementInterval:       30,
+				NoAdvertisementIntervalIBGP: true,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:                 "192.0.2.1",
+						AdvertisementInterval:       10,
+						NoAdvertisementIntervalIBGP: new(bool),
+					},
+				},
+			},
+			expectedInterval: 10,
+		},
+		{
+			name: "VPN",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						AFIs: []*AFI{
+							{
+								Name: "ipv4",
+								SAFI: SAFI{
+									Name:                  "vpn",
+									AdvertisementInterval: &interval,
+								},
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expectedInterval, test.group.Neighbors[0].AdvertisementInterval, "Test %q", test.name)
+		assert.Equal(t, test.expectedNoIBGP, *test.group.Neighbors[0].NoAdvertisementIntervalIBGP, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadTTL(t *testing.T) {
+	tests := []struct {
+		name                    string
+		group                   *BGPGroup
+		wantFail                bool
+		expectedTTL             uint8
+		expectedTTLSecurityHops uint8
+	}{
+		{
+			name: "eBGP multihop",
+			group: &BGPGroup{
+				PeerAS:       65001,
+				EBGPMultihop: 3,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expectedTTL: 3,
+		},
+		{
+			name: "eBGP multihop of iBGP peer",
+			group: &BGPGroup{
+				PeerAS: 65000,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:  "192.0.2.1",
+						EBGPMultihop: 3,
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "eBGP multihop and different TTL",
+			group: &BGPGroup{
+				PeerAS: 65001,
+				TTL:    2,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:  "192.0.2.1",
+						EBGPMultihop: 3,
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "TTL security inherited from group",
+			group: &BGPGroup{
+				PeerAS:          65001,
+				TTLSecurityHops: 1,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expectedTTLSecurityHops: 1,
+		},
+		{
+			name: "TTL security and eBGP multihop",
+			group: &BGPGroup{
+				PeerAS:          65001,
+				TTLSecurityHops: 1,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress:  "192.0.2.1",
+						EBGPMultihop: 3,
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expectedTTL, test.group.Neighbors[0].TTL, "Test %q", test.name)
+		assert.Equal(t, test.expectedTTLSecurityHops, test.group.Neighbors[0].TTLSecurityHops, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadRouteReflection(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name                      string
+		group                     *BGPGroup
+		wantFail                  bool
+		expectedClient            bool
+		expectedClusterID         *bnet.IP
+		expectedClientToClient    bool
+		expectedOriginatorIDCheck bool
+	}{
+		{
+			name: "Defaults",
+			group: &BGPGroup{
+				PeerAS: 65000,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expectedClientToClient:    true,
+			expectedOriginatorIDCheck: true,
+		},
+		{
+			name: "Inherit from group",
+			group: &BGPGroup{
+				PeerAS:                   65000,
+				RouteReflectorClient:     true,
+				ClusterID:                "10.0.0.1",
+				ClientToClientReflection: &disabled,
+				OriginatorIDCheck:        &disabled,
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			expectedClient:    true,
+			expectedClusterID: bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+		},
+		{
+			name: "Neighbor overrides cluster ID",
+			group: &BGPGroup{
+				PeerAS:               65000,
+				RouteReflectorClient: true,
+				ClusterID:            "10.0.0.1",
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+						ClusterID:   "10.0.0.2",
+					},
+				},
+			},
+			expectedClient:            true,
+			expectedClusterID:         bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+			expectedClientToClient:    true,
+			expectedOriginatorIDCheck: true,
+		},
+		{
+			name: "IPv6 cluster ID",
+			group: &BGPGroup{
+				PeerAS:    65000,
+				ClusterID: "2001:db8::1",
+				Neighbors: []*BGPNeighbor{
+					{
+						PeerAddress: "192.0.2.1",
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		n := test.group.Neighbors[0]
+		assert.Equal(t, test.expectedClient, *n.RouteReflectorClient, "Test %q", test.name)
+		assert.Equal(t, test.expectedClusterID, n.ClusterIDIP, "Test %q", test.name)
+		assert.Equal(t, test.expectedClientToClient, *n.ClientToClientReflection, "Test %q", test.name)
+		assert.Equal(t, test.expectedOriginatorIDCheck, *n.OriginatorIDCheck, "Test %q", test.name)
+		assert.True(t, *n.ClusterListCheck, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadListenRanges(t *testing.T) {
+	tests := []struct {
+		name             string
+		group            *BGPGroup
+		wantFail         bool
+		expectedPrefixes []*bnet.Prefix
+		expectedPeerAS   uint32
+	}{
+		{
+			name: "Listen ranges",
+			group: &BGPGroup{
+				PeerAS:       65001,
+				ListenRanges: []string{"10.0.0.0/24", "2001:db8::/64"},
+			},
+			expectedPrefixes: []*bnet.Prefix{
+				bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+				bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 64).Ptr(),
+			},
+			expectedPeerAS: 65001,
+		},
+		{
+			name: "Invalid listen range",
+			group: &BGPGroup{
+				PeerAS:       65001,
+				ListenRanges: []string{"10.0.0.0"},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Missing peer AS",
+			group: &BGPGroup{
+				ListenRanges: []string{"10.0.0.0/24"},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Authentication key",
+			group: &BGPGroup{
+				PeerAS:            65001,
+				AuthenticationKey: "secret",
+				ListenRanges:      []string{"10.0.0.0/24"},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.group.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expectedPrefixes, test.group.ListenRangePrefixes, "Test %q", test.name)
+		assert.Equal(t, test.expectedPeerAS, test.group.DynamicNeighborTemplate.PeerAS, "Test %q", test.name)
+	}
+}
+
+func TestBGPLoadORRGroups(t *testing.T) {
+	orrGroups := []*ORRGroup{
+		{
+			Name: "pop1",
+			IGPCosts: []*IGPCost{
+				{
+					Address: "10.0.0.1",
+					Cost:    10,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bgp      *BGP
+		wantFail bool
+	}{
+		{
+			name: "Route reflector clients",
+			bgp: &BGP{
+				OptimalRouteReflection: orrGroups,
+				Groups: []*BGPGroup{
+					{
+						PeerAS:               65000,
+						RouteReflectorClient: true,
+						ORRGroup:             "pop1",
+						Neighbors: []*BGPNeighbor{
+							{
+								PeerAddress: "192.0.2.1",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Undefined group",
+			bgp: &BGP{
+				OptimalRouteReflection: orrGroups,
+				Groups: []*BGPGroup{
+					{
+						PeerAS:               65000,
+						RouteReflectorClient: true,
+						Neighbors: []*BGPNeighbor{
+							{
+								PeerAddress: "192.0.2.1",
+								ORRGroup:    "pop2",
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "No route reflector client",
+			bgp: &BGP{
+				OptimalRouteReflection: orrGroups,
+				Groups: []*BGPGroup{
+					{
+						PeerAS:   65000,
+						ORRGroup: "pop1",
+						Neighbors: []*BGPNeighbor{
+							{
+								PeerAddress: "192.0.2.1",
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Invalid address",
+			bgp: &BGP{
+				OptimalRouteReflection: []*ORRGroup{
+					{
+						Name: "pop1",
+						IGPCosts: []*IGPCost{
+							{
+								Address: "foo",
+							},
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.bgp.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, "pop1", test.bgp.Groups[0].Neighbors[0].ORRGroup, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadShutdownCommunication(t *testing.T) {
+	tests := []struct {
+		name          string
+		communication string
+		neighbor      *BGPNeighbor
+		expected      string
+		wantFail      bool
+	}{
+		{
+			name:          "Inherited from group",
+			communication: "maintenance",
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+			},
+			expected: "maintenance",
+		},
+		{
+			name:          "Overridden by neighbor",
+			communication: "maintenance",
+			neighbor: &BGPNeighbor{
+				PeerAddress:           "192.0.2.1",
+				ShutdownCommunication: "decommissioned",
+			},
+			expected: "decommissioned",
+		},
+		{
+			name: "Too long",
+			neighbor: &BGPNeighbor{
+				PeerAddress:           "192.0.2.1",
+				ShutdownCommunication: strings.Repeat("a", 256),
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		bg := &BGPGroup{
+			PeerAS:                65000,
+			ShutdownCommunication: test.communication,
+			Neighbors:             []*BGPNeighbor{test.neighbor},
+		}
+
+		err := bg.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, bg.Neighbors[0].ShutdownCommunication, "Test %q", test.name)
+	}
+}
+
+func TestBGPGroupLoadNextHopSelf(t *testing.T) {
+	tests := []struct {
+		name        string
+		nextHopSelf string
+		neighbor    *BGPNeighbor
+		expected    string
+		wantFail    bool
+	}{
+		{
+			name:        "Inherited from group",
+			nextHopSelf: NextHopSelfEnable,
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+			},
+			expected: NextHopSelfEnable,
+		},
+		{
+			name:        "Disabled by neighbor",
+			nextHopSelf: NextHopSelfEnable,
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+				NextHopSelf: NextHopSelfDisable,
+			},
+			expected: NextHopSelfDisable,
+		},
+		{
+			name: "Invalid mode",
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+				NextHopSelf: "always",
+			},
+			wantFail: true,
+		},
+		{
+			name: "Forced for address family",
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+				AFIs: []*AFI{
+					{
+						Name: AFIIPv6,
+						SAFI: SAFI{
+							Name:        SAFIUnicast,
+							NextHopSelf: NextHopSelfForce,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Not supported for VPN",
+			neighbor: &BGPNeighbor{
+				PeerAddress: "192.0.2.1",
+				AFIs: []*AFI{
+					{
+						Name: AFIIPv4,
+						SAFI: SAFI{
+							Name:        SAFIVPN,
+							NextHopSelf: NextHopSelfEnable,
+						},
+					},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		bg := &BGPGroup{
+			PeerAS:      65000,
+			NextHopSelf: test.nextHopSelf,
+			Neighbors:   []*BGPNeighbor{test.neighbor},
+		}
+
+		err := bg.load(65000, &PolicyOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, bg.Neighbors[0].NextHopSelf, "Test %q", test.name)
+	}
+}
+
+func TestFlowSpecEnforcementLoad(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 100)
+
+	tests := []struct {
+		name            string
+		mark            *FlowSpecRedirectMark
+		expectedRT      *types.ExtendedCommunity
+		expectedNextHop *bnet.IP
+		wantFail        bool
+	}{
+		{
+			name: "Route target",
+			mark: &FlowSpecRedirectMark{
+				RouteTarget: "65000:100",
+				Mark:        100,
+			},
+			expectedRT: &rt,
+		},
+		{
+			name: "Next hop",
+			mark: &FlowSpecRedirectMark{
+				NextHop: "192.0.2.1",
+				Mark:    200,
+			},
+			expectedNextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		},
+		{
+			name: "Route target and next hop",
+			mark: &FlowSpecRedirectMark{
+				RouteTarget: "65000:100",
+				NextHop:     "192.0.2.1",
+				Mark:        100,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Invalid next hop",
+			mark: &FlowSpecRedirectMark{
+				NextHop: "foo",
+				Mark:    100,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		f := &FlowSpecEnforcement{
+			RedirectMarks: []*FlowSpecRedirectMark{test.mark},
+		}
+
+		err := f.load()
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expectedRT, test.mark.RouteTargetInternal, "Test %q", test.name)
+		assert.Equal(t, test.expectedNextHop, test.mark.NextHopIP, "Test %q", test.name)
+	}
+}
@@ -3,16 +3,29 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
+	Version          int                `yaml:"version"`
 	PolicyOptions    *PolicyOptions     `yaml:"policy_options"`
 	RoutingInstances []*RoutingInstance `yaml:"routing_instances"`
 	RoutingOptions   *RoutingOptions    `yaml:"routing_options"`
 	Protocols        *Protocols         `yaml:"protocols"`
+	Instances        []*Instance        `yaml:"instances"`
+	Telemetry        *Telemetry         `yaml:"telemetry"`
+
+	// doc is the config document as read from disk (migrated to the current schema) with all
+	// runtime changes applied. It is what gets persisted on save, so secret references are never
+	// written out in resolved form.
+	doc yaml.MapSlice
+
+	// migrationReport lists all stanzas rewritten by schema migration
+	migrationReport []string
 }
 
 func (c *Config) load() error {
@@ -34,7 +47,7 @@ func (c *Config) load() error {
 
 	for _, ri := range c.RoutingInstances {
 		err := ri.load()
-		if ri != nil {
+		if err != nil {
 			return err
 		}
 	}
@@ -48,6 +61,30 @@ func (c *Config) load() error {
 		}
 	}
 
+	names := make(map[string]struct{})
+	for _, inst := range c.Instances {
+		if inst.Name == DefaultInstanceName {
+			return fmt.Errorf("instance name %q is reserved", inst.Name)
+		}
+
+		if _, exists := names[inst.Name]; exists {
+			return fmt.Errorf("duplicate instance %q", inst.Name)
+		}
+		names[inst.Name] = struct{}{}
+
+		err := inst.load(c.PolicyOptions)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to load instance %q", inst.Name)
+		}
+	}
+
+	if c.Telemetry != nil {
+		err := c.Telemetry.load()
+		if err != nil {
+			return errors.Wrap(err, "Failed to load telemetry")
+		}
+	}
+
 	return nil
 }
 
@@ -58,8 +95,24 @@ func GetConfig(filePath string) (*Config, error) {
 		return nil, errors.Wrap(err, "Unable to read file")
 	}
 
-	c := &Config{}
-	err = yaml.Unmarshal(file, c)
+	return parseConfig(file)
+}
+
+func parseConfig(file []byte) (*Config, error) {
+	migrated, report, err := migrate(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to migrate config")
+	}
+
+	c := &Config{
+		migrationReport: report,
+	}
+	err = yaml.UnmarshalStrict(migrated, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal")
+	}
+
+	err = yaml.Unmarshal(migrated, &c.doc)
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to unmarshal")
 	}
@@ -71,3 +124,97 @@ func GetConfig(filePath string) (*Config, error) {
 
 	return c, nil
 }
+
+// MigrationReport returns all stanzas that were rewritten when migrating the config to the current schema version
+func (c *Config) MigrationReport() []string {
+	return c.migrationReport
+}
+
+// SetBGPDisabled records that BGP has been enabled or disabled at runtime in an instance
+// (the top level protocols if instance is empty), so the change is persisted on save.
+// Nothing is recorded if BGP is not configured in the instance.
+func (c *Config) SetBGPDisabled(instance string, disabled bool) error {
+	if instance == "" {
+		if c.Protocols == nil || c.Protocols.BGP == nil {
+			return nil
+		}
+
+		c.Protocols.BGP.Disabled = disabled
+		c.doc = setBGPDisabled(c.doc, disabled)
+		return nil
+	}
+
+	v, _ := getKey(c.doc, "instances")
+	docInstances := toSlice(v)
+	for i, inst := range c.Instances {
+		if inst.Name != instance {
+			continue
+		}
+
+		if inst.Protocols == nil || inst.Protocols.BGP == nil {
+			return nil
+		}
+
+		if i >= len(docInstances) {
+			return fmt.Errorf("instance %q is missing in config document", instance)
+		}
+
+		m, _ := docInstances[i].(yaml.MapSlice)
+		inst.Protocols.BGP.Disabled = disabled
+		docInstances[i] = setBGPDisabled(m, disabled)
+		return nil
+	}
+
+	return fmt.Errorf("instance %q not found", instance)
+}
+
+// setBGPDisabled sets protocols.bgp.disabled in m
+func setBGPDisabled(m yaml.MapSlice, disabled bool) yaml.MapSlice {
+	v, _ := getKey(m, "protocols")
+	protocols, _ := v.(yaml.MapSlice)
+
+	v, _ = getKey(protocols, "bgp")
+	bgp, _ := v.(yaml.MapSlice)
+
+	bgp = setKey(bgp, "disabled", disabled)
+	protocols = setKey(protocols, "bgp", bgp)
+	return setKey(m, "protocols", protocols)
+}
+
+// Save atomically persists the config including all runtime changes to filePath
+func (c *Config) Save(filePath string) error {
+	data, err := yaml.Marshal(c.doc)
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal config")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "Unable to create temporary file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "Unable to write temporary file")
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "Unable to sync temporary file")
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return errors.Wrap(err, "Unable to close temporary file")
+	}
+
+	err = os.Rename(tmp.Name(), filePath)
+	if err != nil {
+		return errors.Wrap(err, "Unable to replace config file")
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSave(t *testing.T) {
+	input := `version: 1
+routing_options:
+  router_id: 192.0.2.1
+  autonomous_system: 65000
+protocols:
+  bgp:
+    groups:
+    - name: foo
+      peer_as: 65001
+      authentication_key: env:BIO_RD_TEST_SAVE_KEY
+      neighbors:
+      - peer_address: 192.0.2.2
+instances:
+- name: bar
+  routing_options:
+    router_id: 192.0.2.1
+    autonomous_system: 65000
+  protocols:
+    bgp:
+      groups:
+      - name: baz
+        peer_as: 65002
+        neighbors:
+        - peer_address: 192.0.2.3
+`
+
+	tests := []struct {
+		name             string
+		instance         string
+		disabled         bool
+		wantFail         bool
+		expectedDisabled bool
+		expectedInstance bool
+	}{
+		{
+			name: "No runtime changes",
+		},
+		{
+			name:             "BGP disabled at top level",
+			disabled:         true,
+			expectedDisabled: true,
+		},
+		{
+			name:             "BGP disabled in instance",
+			instance:         "bar",
+			disabled:         true,
+			expectedInstance: true,
+		},
+		{
+			name:     "Unknown instance",
+			instance: "qux",
+			disabled: true,
+			wantFail: true,
+		},
+	}
+
+	os.Setenv("BIO_RD_TEST_SAVE_KEY", "secret")
+	defer os.Unsetenv("BIO_RD_TEST_SAVE_KEY")
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "bio-rd-config")
+		if err != nil {
+			t.Fatalf("Unable to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		c, err := parseConfig([]byte(input))
+		if err != nil {
+			t.Fatalf("Unable to parse config: %v", err)
+		}
+
+		if test.disabled {
+			err = c.SetBGPDisabled(test.instance, test.disabled)
+			if err != nil {
+				if test.wantFail {
+					continue
+				}
+
+				t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+				continue
+			}
+
+			if test.wantFail {
+				t.Errorf("Unexpected success for test %q", test.name)
+				continue
+			}
+		}
+
+		path := filepath.Join(dir, "bio-rd.yml")
+		err = c.Save(path)
+		if err != nil {
+			t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+			continue
+		}
+
+		saved, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unable to read saved config: %v", err)
+		}
+
+		assert.Contains(t, string(saved), "env:BIO_RD_TEST_SAVE_KEY", "Test %q", test.name)
+		assert.NotContains(t, string(saved), "secret", "Test %q", test.name)
+
+		reloaded, err := GetConfig(path)
+		if err != nil {
+			t.Errorf("Unable to reload saved config for test %q: %v", test.name, err)
+			continue
+		}
+
+		assert.Equal(t, test.expectedDisabled, reloaded.Protocols.BGP.Disabled, "Test %q", test.name)
+		assert.Equal(t, test.expectedInstance, reloaded.Instances[0].Protocols.BGP.Disabled, "Test %q", test.name)
+	}
+}
+
+func TestLoadInstances(t *testing.T) {
+	instance := func(name string) string {
+		return `- name: ` + name + `
+  routing_options:
+    router_id: 192.0.2.1
+    autonomous_system: 65000
+`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		wantFail bool
+	}{
+		{
+			name:  "Instances",
+			input: instance("foo") + instance("bar"),
+		},
+		{
+			name:     "Duplicate instance",
+			input:    instance("foo") + instance("foo"),
+			wantFail: true,
+		},
+		{
+			name:     "Reserved instance name",
+			input:    instance(DefaultInstanceName),
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := parseConfig([]byte(`version: 1
+routing_options:
+  router_id: 192.0.2.1
+  autonomous_system: 65000
+instances:
+` + test.input))
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultInstanceName is the name of the routing instance configured at the top level. Instances can not use it.
+const DefaultInstanceName = "default"
+
+// Instance is an independent routing instance with its own router ID, protocols, RIBs and FIB table
+type Instance struct {
+	Name           string          `yaml:"name"`
+	RoutingOptions *RoutingOptions `yaml:"routing_options"`
+	Protocols      *Protocols      `yaml:"protocols"`
+}
+
+func (i *Instance) load(policyOptions *PolicyOptions) error {
+	if i.Name == "" {
+		return fmt.Errorf("instance name is missing")
+	}
+
+	if i.RoutingOptions == nil {
+		return fmt.Errorf("instance is lacking routing_options")
+	}
+
+	err := i.RoutingOptions.load()
+	if err != nil {
+		return errors.Wrap(err, "error in routing_options")
+	}
+
+	if i.Protocols != nil {
+		err := i.Protocols.load(i.RoutingOptions.AutonomousSystem, policyOptions)
+		if err != nil {
+			return errors.Wrap(err, "Failed to load protocols")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// SchemaVersion is the config schema version understood by this release
+	SchemaVersion = 1
+
+	versionKey = "version"
+)
+
+// migration migrates a config from one schema version to the next.
+// It returns a human readable entry for every stanza it rewrote.
+type migration func(cfg yaml.MapSlice) []string
+
+// migrations[i] migrates a config from schema version i to version i+1
+var migrations = []migration{
+	migrateV0ToV1,
+}
+
+// migrate upgrades a config file to the current schema version. The migrated
+// config is returned along with a report of all rewritten stanzas.
+func migrate(raw []byte) ([]byte, []string, error) {
+	cfg := yaml.MapSlice{}
+	err := yaml.Unmarshal(raw, &cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to unmarshal")
+	}
+
+	version, err := schemaVersion(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if version > SchemaVersion {
+		return nil, nil, fmt.Errorf("config schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+
+	if version == SchemaVersion {
+		return raw, nil, nil
+	}
+
+	report := make([]string, 0)
+	for v := version; v < SchemaVersion; v++ {
+		for _, r := range migrations[v](cfg) {
+			report = append(report, fmt.Sprintf("v%d->v%d: %s", v, v+1, r))
+		}
+	}
+
+	cfg = setKey(cfg, versionKey, SchemaVersion)
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to marshal migrated config")
+	}
+
+	return out, report, nil
+}
+
+func schemaVersion(cfg yaml.MapSlice) (int, error) {
+	v, found := getKey(cfg, versionKey)
+	if !found {
+		return 0, nil
+	}
+
+	version, ok := v.(int)
+	if !ok || version < 0 {
+		return 0, fmt.Errorf("invalid config schema version: %v", v)
+	}
+
+	return version, nil
+}
+
+// migrateV0ToV1 renames the keys of routing_instances and static_routes that were
+// derived from Go field names in schema version 0 to their snake case names
+func migrateV0ToV1(cfg yaml.MapSlice) []string {
+	report := make([]string, 0)
+
+	if ris, found := getKey(cfg, "routing_instances"); found {
+		for i, ri := range toSlice(ris) {
+			m, ok := ri.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+
+			stanza := fmt.Sprintf("routing_instances[%d]", i)
+			report = append(report, renameKey(m, stanza, "routedistinguisher", "route_distinguisher")...)
+			report = append(report, renameKey(m, stanza, "routingoptions", "routing_options")...)
+			report = append(report, migrateStaticRoutesV0ToV1(m, stanza+".routing_options")...)
+		}
+	}
+
+	report = append(report, migrateStaticRoutesV0ToV1(cfg, "routing_options")...)
+
+	if insts, found := getKey(cfg, "instances"); found {
+		for i, inst := range toSlice(insts) {
+			m, ok := inst.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+
+			report = append(report, migrateStaticRoutesV0ToV1(m, fmt.Sprintf("instances[%d].routing_options", i))...)
+		}
+	}
+
+	return report
+}
+
+func migrateStaticRoutesV0ToV1(parent yaml.MapSlice, stanza string) []string {
+	report := make([]string, 0)
+
+	ro, found := getKey(parent, "routing_options")
+	if !found {
+		return report
+	}
+
+	m, ok := ro.(yaml.MapSlice)
+	if !ok {
+		return report
+	}
+
+	routes, found := getKey(m, "static_routes")
+	if !found {
+		return report
+	}
+
+	for i, r := range toSlice(routes) {
+		rm, ok := r.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		report = append(report, renameKey(rm, fmt.Sprintf("%s.static_routes[%d]", stanza, i), "nexthop", "next_hop")...)
+	}
+
+	return report
+}
+
+func toSlice(v interface{}) []interface{} {
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	return s
+}
+
+func getKey(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+func setKey(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+
+	return append(yaml.MapSlice{{Key: key, Value: value}}, m...)
+}
+
+// renameKey renames key old to new in place
+func renameKey(m yaml.MapSlice, stanza string, old string, new string) []string {
+	for i := range m {
+		if m[i].Key == old {
+			m[i].Key = new
+			return []string{fmt.Sprintf("%s: renamed %q to %q", stanza, old, new)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expected       string
+		expectedReport []string
+		wantFail       bool
+	}{
+		{
+			name: "Current version",
+			input: `version: 1
+routing_options:
+  router_id: 192.0.2.1
+`,
+			expected: `version: 1
+routing_options:
+  router_id: 192.0.2.1
+`,
+		},
+		{
+			name: "Unversioned config",
+			input: `routing_options:
+  router_id: 192.0.2.1
+  static_routes:
+  - prefix: 198.51.100.0/24
+    nexthop: 192.0.2.2
+routing_instances:
+- name: foo
+  routedistinguisher: "1:1"
+`,
+			expected: `version: 1
+routing_options:
+  router_id: 192.0.2.1
+  static_routes:
+  - prefix: 198.51.100.0/24
+    next_hop: 192.0.2.2
+routing_instances:
+- name: foo
+  route_distinguisher: "1:1"
+`,
+			expectedReport: []string{
+				`v0->v1: routing_instances[0]: renamed "routedistinguisher" to "route_distinguisher"`,
+				`v0->v1: routing_options.static_routes[0]: renamed "nexthop" to "next_hop"`,
+			},
+		},
+		{
+			name: "Unversioned config with routing instance static routes",
+			input: `routing_instances:
+- name: foo
+  routingoptions:
+    static_routes:
+    - prefix: 198.51.100.0/24
+      nexthop: 192.0.2.2
+- name: bar
+  routing_options:
+    static_routes:
+    - prefix: 203.0.113.0/24
+      nexthop: 192.0.2.3
+`,
+			expected: `version: 1
+routing_instances:
+- name: foo
+  routing_options:
+    static_routes:
+    - prefix: 198.51.100.0/24
+      next_hop: 192.0.2.2
+- name: bar
+  routing_options:
+    static_routes:
+    - prefix: 203.0.113.0/24
+      next_hop: 192.0.2.3
+`,
+			expectedReport: []string{
+				`v0->v1: routing_instances[0]: renamed "routingoptions" to "routing_options"`,
+				`v0->v1: routing_instances[0].routing_options.static_routes[0]: renamed "nexthop" to "next_hop"`,
+				`v0->v1: routing_instances[1].routing_options.static_routes[0]: renamed "nexthop" to "next_hop"`,
+			},
+		},
+		{
+			name:     "Future version",
+			input:    "version: 2\n",
+			wantFail: true,
+		},
+		{
+			name:     "Invalid version",
+			input:    "version: foo\n",
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		out, report, err := migrate([]byte(test.input))
+		if err != nil {
+			if test.wantFail {
+				continue
+			}
+
+			t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+			continue
+		}
+
+		if test.wantFail {
+			t.Errorf("Unexpected success for test %q", test.name)
+			continue
+		}
+
+		assert.Equal(t, test.expected, string(out), test.name)
+		assert.Equal(t, test.expectedReport, report, test.name)
+	}
+}
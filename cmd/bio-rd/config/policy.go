@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
 	"github.com/pkg/errors"
@@ -31,7 +34,14 @@ type PolicyStatementTerm struct {
 }
 
 type PolicyStatementTermFrom struct {
-	RouteFilters []*RouteFilter `yaml:"route_filters"`
+	RouteFilters     []*RouteFilter `yaml:"route_filters"`
+	ValidationStates []string       `yaml:"validation_states"`
+
+	// ASPathRegex matches paths with an AS path matching one of the expressions
+	ASPathRegex []string `yaml:"as_path_regex"`
+
+	// ASPathRegexSyntax is the syntax of ASPathRegex: juniper (default) or cisco
+	ASPathRegexSyntax string `yaml:"as_path_regex_syntax"`
 }
 
 type RouteFilter struct {
@@ -48,11 +58,33 @@ type PolicyStatementTermThen struct {
 	LocalPref     *uint32        `yaml:"local_pref"`
 	ASPathPrepend *ASPathPrepend `yaml:"as_path_prepend"`
 	NextHop       *NextHop       `yaml:"next_hop"`
+
+	// ASPathTruncate removes all ASNs beyond the given number from the AS path. It is applied before ASPathPrepend.
+	ASPathTruncate *uint16 `yaml:"as_path_truncate"`
+
+	Community         *CommunityActions `yaml:"community"`
+	LargeCommunity    *CommunityActions `yaml:"large_community"`
+	ExtendedCommunity *CommunityActions `yaml:"extended_community"`
+}
+
+// CommunityActions modifies the communities of a path. Set is applied first, then Delete and Add.
+// Communities are given as 65000:100, large communities as 65000:1:2 and extended communities as route targets (e.g. target:65000:100).
+type CommunityActions struct {
+	// Set replaces all communities if present. An empty list removes all communities.
+	Set []string `yaml:"set"`
+
+	// Delete removes communities by value, wildcard (e.g. 65000:*) or regular expression enclosed in slashes (e.g. /^65000:1[0-9]{2}$/)
+	Delete []string `yaml:"delete"`
+
+	Add []string `yaml:"add"`
 }
 
+// ASPathPrepend prepends asn or the sequence asns count times. Without asn and asns the own AS is prepended, which
+// is the first ASN of the AS path when exporting to eBGP peers.
 type ASPathPrepend struct {
-	ASN   uint32 `yaml:"asn"`
-	Count uint16 `yaml:"count"`
+	ASN   uint32   `yaml:"asn"`
+	ASNs  []uint32 `yaml:"asns"`
+	Count uint16   `yaml:"count"`
 }
 
 type NextHop struct {
@@ -135,8 +167,34 @@ func (pst *PolicyStatementTerm) toFilterTerm() (*filter.Term, error) {
 		routeFilters = append(routeFilters, rf)
 	}
 
-	if len(routeFilters) > 0 {
-		conditions = append(conditions, filter.NewTermConditionWithRouteFilters(routeFilters...))
+	validationStates := make([]vrp.ValidationState, 0)
+	for _, x := range pst.From.ValidationStates {
+		s, err := vrp.ParseValidationState(x)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse validation state")
+		}
+
+		validationStates = append(validationStates, s)
+	}
+
+	syntax, err := filter.ParseASPathRegexSyntax(pst.From.ASPathRegexSyntax)
+	if err != nil {
+		return nil, err
+	}
+
+	asPathFilters := make([]*filter.ASPathFilter, 0)
+	for _, x := range pst.From.ASPathRegex {
+		f, err := filter.NewASPathFilter(x, syntax)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse AS path regex")
+		}
+
+		asPathFilters = append(asPathFilters, f)
+	}
+
+	// Route filters, validation states and AS path regular expressions have to match all
+	if len(routeFilters) > 0 || len(validationStates) > 0 || len(asPathFilters) > 0 {
+		conditions = append(conditions, filter.NewTermConditionWithRouteFilters(routeFilters...).MatchValidationStates(validationStates...).MatchASPathFilters(asPathFilters...))
 	}
 
 	if pst.Then.Reject {
@@ -151,9 +209,11 @@ func (pst *PolicyStatementTerm) toFilterTerm() (*filter.Term, error) {
 		a = append(a, actions.NewSetMEDAction(*pst.Then.MED))
 	}
 
-	if pst.Then.ASPathPrepend != nil {
-		a = append(a, actions.NewASPathPrependAction(pst.Then.ASPathPrepend.ASN, pst.Then.ASPathPrepend.Count))
+	asPathActions, err := pst.Then.asPathActions()
+	if err != nil {
+		return nil, err
 	}
+	a = append(a, asPathActions...)
 
 	if pst.Then.NextHop != nil {
 		addr, err := bnet.IPFromString(pst.Then.NextHop.Address)
@@ -164,9 +224,196 @@ func (pst *PolicyStatementTerm) toFilterTerm() (*filter.Term, error) {
 		a = append(a, actions.NewSetNextHopAction(addr.Dedup()))
 	}
 
+	communityActions, err := pst.Then.communityActions()
+	if err != nil {
+		return nil, err
+	}
+	a = append(a, communityActions...)
+
 	if pst.Then.Accept {
 		a = append(a, actions.NewAcceptAction())
 	}
 
 	return filter.NewTerm(pst.Name, conditions, a), nil
 }
+
+func (t *PolicyStatementTermThen) asPathActions() ([]actions.Action, error) {
+	res := make([]actions.Action, 0)
+
+	if t.ASPathTruncate != nil {
+		res = append(res, actions.NewASPathTruncateAction(*t.ASPathTruncate))
+	}
+
+	pp := t.ASPathPrepend
+	if pp == nil {
+		return res, nil
+	}
+
+	switch {
+	case pp.ASN != 0 && len(pp.ASNs) > 0:
+		return nil, fmt.Errorf("as_path_prepend: asn and asns are mutually exclusive")
+	case pp.ASN != 0:
+		res = append(res, actions.NewASPathPrependAction(pp.ASN, pp.Count))
+	case len(pp.ASNs) > 0:
+		for _, asn := range pp.ASNs {
+			if asn == 0 {
+				return nil, fmt.Errorf("as_path_prepend: AS 0 is invalid")
+			}
+		}
+
+		res = append(res, actions.NewASPathPrependSequenceAction(pp.ASNs, pp.Count))
+	default:
+		res = append(res, actions.NewASPathExpandAction(pp.Count))
+	}
+
+	return res, nil
+}
+
+func (t *PolicyStatementTermThen) communityActions() ([]actions.Action, error) {
+	res := make([]actions.Action, 0)
+
+	if c := t.Community; c != nil {
+		set, err := parseCommunities(c.Set)
+		if err != nil {
+			return nil, err
+		}
+
+		add, err := parseCommunities(c.Add)
+		if err != nil {
+			return nil, err
+		}
+
+		del, err := parseCommunityPatterns(c.Delete)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Set != nil {
+			res = append(res, actions.NewSetCommunityAction(set))
+		}
+
+		if len(del) > 0 {
+			res = append(res, actions.NewDeleteCommunityAction(del...))
+		}
+
+		if len(add) > 0 {
+			res = append(res, actions.NewAddCommunityAction(&add))
+		}
+	}
+
+	if c := t.LargeCommunity; c != nil {
+		set, err := parseLargeCommunities(c.Set)
+		if err != nil {
+			return nil, err
+		}
+
+		add, err := parseLargeCommunities(c.Add)
+		if err != nil {
+			return nil, err
+		}
+
+		del, err := parseCommunityPatterns(c.Delete)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Set != nil {
+			res = append(res, actions.NewSetLargeCommunityAction(set))
+		}
+
+		if len(del) > 0 {
+			res = append(res, actions.NewDeleteLargeCommunityAction(del...))
+		}
+
+		if len(add) > 0 {
+			res = append(res, actions.NewAddLargeCommunityAction(&add))
+		}
+	}
+
+	if c := t.ExtendedCommunity; c != nil {
+		set, err := parseExtendedCommunities(c.Set)
+		if err != nil {
+			return nil, err
+		}
+
+		add, err := parseExtendedCommunities(c.Add)
+		if err != nil {
+			return nil, err
+		}
+
+		del, err := parseCommunityPatterns(c.Delete)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Set != nil {
+			res = append(res, actions.NewSetExtendedCommunityAction(set))
+		}
+
+		if len(del) > 0 {
+			res = append(res, actions.NewDeleteExtendedCommunityAction(del...))
+		}
+
+		if len(add) > 0 {
+			res = append(res, actions.NewAddExtendedCommunityAction(add))
+		}
+	}
+
+	return res, nil
+}
+
+func parseCommunities(x []string) (types.Communities, error) {
+	res := make(types.Communities, 0, len(x))
+	for _, s := range x {
+		c, err := types.ParseCommunityString(strings.ReplaceAll(s, ":", ","))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid community %q", s)
+		}
+
+		res = append(res, c)
+	}
+
+	return res, nil
+}
+
+func parseLargeCommunities(x []string) (types.LargeCommunities, error) {
+	res := make(types.LargeCommunities, 0, len(x))
+	for _, s := range x {
+		c, err := types.ParseLargeCommunityString(strings.ReplaceAll(s, ":", ","))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid large community %q", s)
+		}
+
+		res = append(res, c)
+	}
+
+	return res, nil
+}
+
+func parseExtendedCommunities(x []string) (types.ExtendedCommunities, error) {
+	res := make(types.ExtendedCommunities, 0, len(x))
+	for _, s := range x {
+		c, err := types.ParseRouteTarget(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid extended community %q", s)
+		}
+
+		res = append(res, c)
+	}
+
+	return res, nil
+}
+
+func parseCommunityPatterns(x []string) ([]*actions.CommunityPattern, error) {
+	res := make([]*actions.CommunityPattern, 0, len(x))
+	for _, s := range x {
+		p, err := actions.NewCommunityPattern(s)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, p)
+	}
+
+	return res, nil
+}
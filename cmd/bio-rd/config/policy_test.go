@@ -0,0 +1,168 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunityActions(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 100)
+
+	tests := []struct {
+		name     string
+		then     PolicyStatementTermThen
+		wantFail bool
+		expected []actions.Action
+	}{
+		{
+			name: "Set, delete and add communities",
+			then: PolicyStatementTermThen{
+				Community: &CommunityActions{
+					Set:    []string{"65000:1"},
+					Delete: []string{"65000:*"},
+					Add:    []string{"(65000,2)"},
+				},
+			},
+			expected: []actions.Action{
+				actions.NewSetCommunityAction(types.Communities{65000<<16 + 1}),
+				actions.NewDeleteCommunityAction(mustCommunityPattern("65000:*")),
+				actions.NewAddCommunityAction(&types.Communities{65000<<16 + 2}),
+			},
+		},
+		{
+			name: "Remove all large communities",
+			then: PolicyStatementTermThen{
+				LargeCommunity: &CommunityActions{
+					Set: []string{},
+				},
+			},
+			expected: []actions.Action{
+				actions.NewSetLargeCommunityAction(types.LargeCommunities{}),
+			},
+		},
+		{
+			name: "Add route target",
+			then: PolicyStatementTermThen{
+				ExtendedCommunity: &CommunityActions{
+					Add: []string{"target:65000:100"},
+				},
+			},
+			expected: []actions.Action{
+				actions.NewAddExtendedCommunityAction(types.ExtendedCommunities{rt}),
+			},
+		},
+		{
+			name: "Invalid community",
+			then: PolicyStatementTermThen{
+				Community: &CommunityActions{
+					Add: []string{"65000:70000"},
+				},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Invalid pattern",
+			then: PolicyStatementTermThen{
+				LargeCommunity: &CommunityActions{
+					Delete: []string{"/(/"},
+				},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := test.then.communityActions()
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		if !assert.Equal(t, len(test.expected), len(res), "Test %q", test.name) {
+			continue
+		}
+
+		for i := range res {
+			assert.True(t, test.expected[i].Equal(res[i]), "Test %q: action %d", test.name, i)
+		}
+	}
+}
+
+func mustCommunityPattern(s string) *actions.CommunityPattern {
+	p, err := actions.NewCommunityPattern(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestASPathActions(t *testing.T) {
+	truncate := uint16(3)
+
+	tests := []struct {
+		name     string
+		then     PolicyStatementTermThen
+		wantFail bool
+		expected []actions.Action
+	}{
+		{
+			name: "Prepend ASN",
+			then: PolicyStatementTermThen{
+				ASPathPrepend: &ASPathPrepend{ASN: 65001, Count: 2},
+			},
+			expected: []actions.Action{
+				actions.NewASPathPrependAction(65001, 2),
+			},
+		},
+		{
+			name: "Prepend own AS",
+			then: PolicyStatementTermThen{
+				ASPathPrepend: &ASPathPrepend{Count: 3},
+			},
+			expected: []actions.Action{
+				actions.NewASPathExpandAction(3),
+			},
+		},
+		{
+			name: "Truncate and prepend sequence",
+			then: PolicyStatementTermThen{
+				ASPathTruncate: &truncate,
+				ASPathPrepend:  &ASPathPrepend{ASNs: []uint32{65001, 65002}, Count: 1},
+			},
+			expected: []actions.Action{
+				actions.NewASPathTruncateAction(3),
+				actions.NewASPathPrependSequenceAction([]uint32{65001, 65002}, 1),
+			},
+		},
+		{
+			name: "ASN and sequence",
+			then: PolicyStatementTermThen{
+				ASPathPrepend: &ASPathPrepend{ASN: 65001, ASNs: []uint32{65002}, Count: 1},
+			},
+			wantFail: true,
+		},
+		{
+			name: "AS 0 in sequence",
+			then: PolicyStatementTermThen{
+				ASPathPrepend: &ASPathPrepend{ASNs: []uint32{65002, 0}, Count: 1},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := test.then.asPathActions()
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, res, "Test %q", test.name)
+	}
+}
@@ -7,6 +7,10 @@ type Protocols struct {
 }
 
 func (p *Protocols) load(localAS uint32, policyOptions *PolicyOptions) error {
+	if p.BGP == nil {
+		return nil
+	}
+
 	err := p.BGP.load(localAS, policyOptions)
 	if err != nil {
 		return errors.Wrap(err, "BGP error")
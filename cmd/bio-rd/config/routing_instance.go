@@ -5,15 +5,20 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 	"github.com/pkg/errors"
 )
 
 type RoutingInstance struct {
-	Name                       string
-	RouteDistinguisher         string
-	InternalRouteDistinguisher uint64
-	RoutingOptions             *RoutingOptions
-	Protocols                  *Protocols
+	Name                       string                    `yaml:"name"`
+	RouteDistinguisher         string                    `yaml:"route_distinguisher"`
+	InternalRouteDistinguisher uint64                    `yaml:"-"`
+	ImportRouteTargets         []string                  `yaml:"import_route_targets"`
+	InternalImportRouteTargets types.ExtendedCommunities `yaml:"-"`
+	ExportRouteTargets         []string                  `yaml:"export_route_targets"`
+	InternalExportRouteTargets types.ExtendedCommunities `yaml:"-"`
+	RoutingOptions             *RoutingOptions           `yaml:"routing_options"`
+	Protocols                  *Protocols                `yaml:"protocols"`
 }
 
 func (ri *RoutingInstance) load() error {
@@ -22,9 +27,33 @@ func (ri *RoutingInstance) load() error {
 		return errors.Wrap(err, "Unable to load route distinguisher")
 	}
 
+	ri.InternalImportRouteTargets, err = loadRouteTargets(ri.ImportRouteTargets)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load import route targets")
+	}
+
+	ri.InternalExportRouteTargets, err = loadRouteTargets(ri.ExportRouteTargets)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load export route targets")
+	}
+
 	return nil
 }
 
+func loadRouteTargets(rts []string) (types.ExtendedCommunities, error) {
+	ret := make(types.ExtendedCommunities, 0, len(rts))
+	for _, x := range rts {
+		rt, err := types.ParseRouteTarget(x)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid route target %q", x)
+		}
+
+		ret = append(ret, rt)
+	}
+
+	return ret, nil
+}
+
 func (ri *RoutingInstance) loadRD() error {
 	parts := strings.Split(ri.RouteDistinguisher, ":")
 	if len(parts) != 2 {
@@ -10,6 +10,24 @@ type RoutingOptions struct {
 	RouterID         string        `yaml:"router_id"`
 	RouterIDUint32   uint32
 	AutonomousSystem uint32 `yaml:"autonomous_system"`
+	KernelTable      *int   `yaml:"kernel_table"`
+
+	// PrefixIndependentConvergence installs routes via shared next hop groups switched to precomputed backup paths on failures
+	PrefixIndependentConvergence bool `yaml:"prefix_independent_convergence"`
+
+	// BestPath tunes the BGP decision process
+	BestPath *BestPath `yaml:"best_path"`
+}
+
+// BestPath tunes the BGP decision process, e.g. to match the behavior of other implementations during migrations
+type BestPath struct {
+	AlwaysCompareMED   bool `yaml:"always_compare_med"`
+	DeterministicMED   bool `yaml:"deterministic_med"`
+	IgnoreASPathLength bool `yaml:"ignore_as_path_length"`
+
+	// CompareRouterID breaks ties by the router ID (default true)
+	CompareRouterID *bool `yaml:"compare_router_id"`
+	PreferOldest    bool  `yaml:"prefer_oldest"`
 }
 
 func (r *RoutingOptions) load() error {
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+	secretExecPrefix = "exec:"
+)
+
+// resolveSecret resolves a secret reference. Supported references are:
+//
+//	env:NAME        value of environment variable NAME
+//	file:/path      content of file /path (trailing newline stripped)
+//	exec:/path args stdout of the external secret provider /path
+//
+// The exec: command line is split at whitespace. Quoting and escaping are not
+// supported, so neither the provider path nor its arguments may contain spaces;
+// references containing quote characters or backslashes are rejected.
+// Any other value is returned unmodified (plaintext secret).
+// Secrets are resolved on every config load, so a rotated secret is picked up on reload.
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretEnvPrefix):
+		name := strings.TrimPrefix(ref, secretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q not set", name)
+		}
+
+		return v, nil
+	case strings.HasPrefix(ref, secretFilePrefix):
+		path := strings.TrimPrefix(ref, secretFilePrefix)
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "Unable to read secret file %q", path)
+		}
+
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case strings.HasPrefix(ref, secretExecPrefix):
+		cmd := strings.TrimPrefix(ref, secretExecPrefix)
+		if strings.ContainsAny(cmd, "\"'\\") {
+			return "", fmt.Errorf("secret provider command %q must not contain quotes or backslashes", cmd)
+		}
+
+		args := strings.Fields(cmd)
+		if len(args) == 0 {
+			return "", fmt.Errorf("secret provider command missing")
+		}
+
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return "", errors.Wrapf(err, "Secret provider %q failed", args[0])
+		}
+
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+
+	return ref, nil
+}
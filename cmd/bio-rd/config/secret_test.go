@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bio-rd-secret")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	secretFile := filepath.Join(dir, "secret")
+	err = ioutil.WriteFile(secretFile, []byte("foo\n"), 0600)
+	if err != nil {
+		t.Fatalf("Unable to write secret file: %v", err)
+	}
+
+	os.Setenv("BIO_RD_TEST_SECRET", "bar")
+	defer os.Unsetenv("BIO_RD_TEST_SECRET")
+	os.Unsetenv("BIO_RD_TEST_SECRET_UNSET")
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+		wantFail bool
+	}{
+		{
+			name:     "Plaintext",
+			ref:      "plain",
+			expected: "plain",
+		},
+		{
+			name:     "Environment variable",
+			ref:      "env:BIO_RD_TEST_SECRET",
+			expected: "bar",
+		},
+		{
+			name:     "Environment variable not set",
+			ref:      "env:BIO_RD_TEST_SECRET_UNSET",
+			wantFail: true,
+		},
+		{
+			name:     "File with trailing newline",
+			ref:      "file:" + secretFile,
+			expected: "foo",
+		},
+		{
+			name:     "Missing file",
+			ref:      "file:" + filepath.Join(dir, "missing"),
+			wantFail: true,
+		},
+		{
+			name:     "Exec with arguments",
+			ref:      "exec:/bin/echo foo  bar",
+			expected: "foo bar",
+		},
+		{
+			name:     "Exec without command",
+			ref:      "exec: ",
+			wantFail: true,
+		},
+		{
+			name:     "Exec failing provider",
+			ref:      "exec:/bin/false",
+			wantFail: true,
+		},
+		{
+			name:     "Exec with quoted argument",
+			ref:      `exec:/bin/echo "foo bar"`,
+			wantFail: true,
+		},
+		{
+			name:     "Exec with escaped space",
+			ref:      `exec:/bin/echo foo\ bar`,
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := resolveSecret(test.ref)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, res, "Test %q", test.name)
+	}
+}
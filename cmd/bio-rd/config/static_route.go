@@ -1,8 +1,8 @@
 package config
 
 type StaticRoute struct {
-	Prefix  string
-	Discard bool
-	NextHop string
-	Resolve bool
+	Prefix  string `yaml:"prefix"`
+	Discard bool   `yaml:"discard"`
+	NextHop string `yaml:"next_hop"`
+	Resolve bool   `yaml:"resolve"`
 }
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultTelemetrySampleInterval = 10
+
+// Telemetry configures collectors state is pushed to
+type Telemetry struct {
+	Collectors []*TelemetryCollector `yaml:"collectors"`
+}
+
+// TelemetryCollector is a GRPC telemetry collector
+type TelemetryCollector struct {
+	Address       string                   `yaml:"address"`
+	TLSCA         string                   `yaml:"tls_ca"`
+	TLSCert       string                   `yaml:"tls_cert"`
+	TLSKey        string                   `yaml:"tls_key"`
+	Subscriptions []*TelemetrySubscription `yaml:"subscriptions"`
+}
+
+// TelemetrySubscription selects state published to a collector
+type TelemetrySubscription struct {
+	Path                   string `yaml:"path"`
+	SampleInterval         uint32 `yaml:"sample_interval"`
+	SampleIntervalDuration time.Duration
+	OnChange               bool `yaml:"on_change"`
+}
+
+func (t *Telemetry) load() error {
+	for _, c := range t.Collectors {
+		err := c.load()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *TelemetryCollector) load() error {
+	if c.Address == "" {
+		return fmt.Errorf("telemetry collector is lacking address")
+	}
+
+	if len(c.Subscriptions) == 0 {
+		return fmt.Errorf("telemetry collector %q has no subscriptions", c.Address)
+	}
+
+	for _, s := range c.Subscriptions {
+		if s.SampleInterval == 0 {
+			s.SampleInterval = defaultTelemetrySampleInterval
+		}
+
+		s.SampleIntervalDuration = time.Second * time.Duration(s.SampleInterval)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	log "github.com/sirupsen/logrus"
+)
+
+type peerState struct {
+	Instance string    `json:"instance"`
+	Peer     string    `json:"peer"`
+	VRF      string    `json:"vrf"`
+	State    uint8     `json:"state"`
+	Since    time.Time `json:"since,omitempty"`
+}
+
+type ribSummary struct {
+	Instance             string            `json:"instance"`
+	VRF                  string            `json:"vrf"`
+	RIB                  string            `json:"rib"`
+	RouteCount           uint64            `json:"route_count"`
+	RouteCountByProtocol map[string]uint64 `json:"route_count_by_protocol"`
+}
+
+type transitionDump struct {
+	Protocol    string   `json:"protocol"`
+	Scope       string   `json:"scope,omitempty"`
+	Object      string   `json:"object"`
+	Transitions []string `json:"transitions"`
+}
+
+// newCrashDumper creates the crash dumper and makes sure a dump is written on log.Fatal
+func newCrashDumper(dir string) *crashdump.Dumper {
+	d := crashdump.New(dir, "bio-rd")
+	d.AddSection("peers", dumpPeerStates)
+	d.AddSection("ribs", dumpRIBSummaries)
+	d.AddSection("flight_recorder", dumpFlightRecorder)
+	d.AddSection("events", func() interface{} {
+		return eventLog.Events("")
+	})
+
+	log.RegisterExitHandler(func() {
+		writeCrashDump(d, "fatal error")
+	})
+
+	// Goroutines of the protocol implementations and gRPC handlers write their dumps via the default dumper
+	crashdump.SetDefault(d)
+
+	return d
+}
+
+func writeCrashDump(d *crashdump.Dumper, reason string) {
+	path, err := d.Write(reason)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write crash dump: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Crash dump written to %s\n", path)
+}
+
+func dumpPeerStates() interface{} {
+	ret := make([]peerState, 0)
+	for _, ri := range instances.list() {
+		bgpSrv, _ := ri.bgp()
+		if bgpSrv == nil {
+			continue
+		}
+
+		m, err := bgpSrv.Metrics()
+		if err != nil {
+			continue
+		}
+
+		for _, p := range m.Peers {
+			ret = append(ret, peerState{
+				Instance: ri.name,
+				Peer:     p.IP.String(),
+				VRF:      p.VRF,
+				State:    p.State,
+				Since:    p.Since,
+			})
+		}
+	}
+
+	return ret
+}
+
+func dumpRIBSummaries() interface{} {
+	ret := make([]ribSummary, 0)
+	for _, ri := range instances.list() {
+		for _, v := range vrf.Metrics(ri.vrfReg) {
+			for _, rib := range v.RIBs {
+				ret = append(ret, ribSummary{
+					Instance:             ri.name,
+					VRF:                  v.Name,
+					RIB:                  rib.Name,
+					RouteCount:           rib.RouteCount,
+					RouteCountByProtocol: rib.RouteCountByProtocol,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+func dumpFlightRecorder() interface{} {
+	ret := make([]transitionDump, 0)
+	for _, rec := range flightRecorder.Recorders("", "") {
+		d := transitionDump{
+			Protocol:    rec.Protocol(),
+			Scope:       rec.Scope(),
+			Object:      rec.Object(),
+			Transitions: make([]string, 0),
+		}
+
+		for _, t := range rec.Transitions() {
+			d.Transitions = append(d.Transitions, t.String())
+		}
+
+		ret = append(ret, d)
+	}
+
+	return ret
+}
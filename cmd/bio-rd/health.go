@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bio-routing/bio-rd/util/health"
+)
+
+func newHealthChecker() *health.Checker {
+	c := health.New()
+	c.AddReadinessCheck("config", configReady)
+	c.AddReadinessCheck("bgp", bgpReady)
+
+	return c
+}
+
+func configReady() error {
+	runCfgMu.RLock()
+	defer runCfgMu.RUnlock()
+
+	if runCfg == nil {
+		return fmt.Errorf("initial config not loaded")
+	}
+
+	return nil
+}
+
+// bgpReady checks that BGP is running in all instances it is enabled in and initial convergence is complete.
+// Initial convergence is considered complete once all sessions are established or the convergence timeout expired.
+func bgpReady() error {
+	for _, ri := range instances.list() {
+		err := ri.bgpReady(*convergenceTimeout)
+		if err != nil {
+			return fmt.Errorf("instance %q: %v", ri.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (ri *routingInstance) bgpReady(convergenceTimeout time.Duration) error {
+	ri.bgpMu.RLock()
+	defer ri.bgpMu.RUnlock()
+
+	if ri.bgpCfg == nil || ri.bgpCfg.Disabled {
+		return nil
+	}
+
+	if ri.bgpSrv == nil {
+		return fmt.Errorf("BGP not running")
+	}
+
+	if time.Since(ri.bgpStarted) > convergenceTimeout {
+		return nil
+	}
+
+	m, err := ri.bgpSrv.Metrics()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range m.Peers {
+		if !p.Up {
+			return fmt.Errorf("BGP session to %s not established", p.IP.String())
+		}
+	}
+
+	return nil
+}
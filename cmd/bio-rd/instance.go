@@ -0,0 +1,886 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/cmd/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/flowspec"
+	"github.com/bio-routing/bio-rd/protocols/kernel"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultInstanceName = config.DefaultInstanceName
+)
+
+var defaultBGPListenAddrs = []string{
+	"[::]:179",
+	"0.0.0.0:179",
+}
+
+// routingInstance is a fully independent routing instance with its own router ID, protocols, RIBs and FIB table
+type routingInstance struct {
+	name           string
+	routerID       uint32
+	bgpListenAddrs []string
+	vrfReg         *vrf.VRFRegistry
+	kernel         *kernel.Kernel
+	collector      prometheus.Collector
+
+	// guarded by bgpMu
+	bgpSrv      bgpserver.BGPServer
+	bgpAPI      *bgpserver.BGPAPIServer
+	bgpCfg      *config.BGP
+	bgpStarted  time.Time
+	flowSpec    *flowspec.Enforcer
+	flowSpecCfg *config.FlowSpecEnforcement
+	bgpMu       sync.RWMutex
+}
+
+func newRoutingInstance(name string, ro *config.RoutingOptions, bgpListenAddrs []string) (*routingInstance, error) {
+	ri := &routingInstance{
+		name:           name,
+		routerID:       ro.RouterIDUint32,
+		bgpListenAddrs: bgpListenAddrs,
+		vrfReg:         vrf.NewVRFRegistry(),
+	}
+
+	masterVRF := ri.vrfReg.CreateVRFIfNotExists("master", 0)
+
+	if ro.KernelTable != nil {
+		var err error
+		ri.kernel, err = kernel.NewWithTable(*ro.KernelTable)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to initialize kernel")
+		}
+
+		if ro.PrefixIndependentConvergence {
+			masterVRF.IPv4UnicastRIB().RegisterNextHopGroupClient(ri.kernel)
+		} else {
+			// Equal cost paths of BGP multipath are installed as ECMP routes
+			masterVRF.IPv4UnicastRIB().RegisterWithOptions(ri.kernel, routingtable.ClientOptions{
+				EcmpOnly: true,
+			})
+		}
+	}
+
+	c, err := registerInstanceMetrics(ri)
+	if err != nil {
+		ri.dispose()
+		return nil, errors.Wrap(err, "Unable to register metrics")
+	}
+	ri.collector = c
+
+	return ri, nil
+}
+
+func (ri *routingInstance) loadConfig(ro *config.RoutingOptions, routingInstances []*config.RoutingInstance, protocols *config.Protocols) error {
+	ri.vrfReg.GetVRFByName("master").SetBestPathOptions(bestPathOptions(ro))
+
+	for _, vri := range routingInstances {
+		err := ri.configureRoutingInstance(vri)
+		_ = err
+	}
+
+	var bgp *config.BGP
+	if protocols != nil {
+		bgp = protocols.BGP
+	}
+
+	err := ri.setBGP(bgp, bgp != nil && !bgp.Disabled)
+	if err != nil {
+		return errors.Wrap(err, "Unable to configure BGP")
+	}
+
+	return nil
+}
+
+// bgp returns the BGP server and its API server. Both are nil if BGP is disabled.
+func (ri *routingInstance) bgp() (bgpserver.BGPServer, *bgpserver.BGPAPIServer) {
+	ri.bgpMu.RLock()
+	defer ri.bgpMu.RUnlock()
+
+	return ri.bgpSrv, ri.bgpAPI
+}
+
+// setBGPEnabled enables or disables BGP at runtime using the last loaded BGP config
+func (ri *routingInstance) setBGPEnabled(enabled bool) error {
+	ri.bgpMu.RLock()
+	cfg := ri.bgpCfg
+	ri.bgpMu.RUnlock()
+
+	if enabled && cfg == nil {
+		return fmt.Errorf("BGP is not configured")
+	}
+
+	return ri.setBGP(cfg, enabled)
+}
+
+func (ri *routingInstance) setBGP(cfg *config.BGP, enabled bool) error {
+	ri.bgpMu.Lock()
+	defer ri.bgpMu.Unlock()
+
+	ri.bgpCfg = cfg
+	if !enabled {
+		ri.stopBGP()
+		return nil
+	}
+
+	if ri.bgpSrv == nil {
+		err := ri.startBGP()
+		if err != nil {
+			return err
+		}
+	}
+
+	return ri.configureProtocolsBGP(cfg)
+}
+
+// startBGP initializes a fresh BGP server. bgpMu must be held.
+func (ri *routingInstance) startBGP() error {
+	srv := bgpserver.NewBGPServer(ri.routerID, ri.bgpListenAddrs)
+	srv.SetEventLog(eventLog)
+	srv.SetFlightRecorder(flightRecorder.Scope(ri.name))
+	srv.SetLabelManager(labelManager)
+	if vrps := getROVVRPs(); vrps != nil {
+		srv.SetVRPs(vrps)
+	}
+
+	// Only the first BGP start of the process follows a restart
+	if *bgpRestarting && ri.bgpStarted.IsZero() {
+		srv.SetRestarting(*bgpSelectionDeferral, *bgpForwardingState)
+	}
+
+	err := srv.Start()
+	if err != nil {
+		srv.Stop()
+		return errors.Wrap(err, "Unable to start BGP server")
+	}
+
+	ri.bgpSrv = srv
+	ri.bgpAPI = bgpserver.NewBGPAPIServer(srv)
+	ri.bgpStarted = time.Now()
+	log.Infof("BGP enabled in instance %q", ri.name)
+	eventLog.Record("bgp", ri.name, "BGP enabled", "")
+	return nil
+}
+
+// stopBGP tears down all BGP sessions (withdrawing their routes) and closes all sockets. bgpMu must be held.
+func (ri *routingInstance) stopBGP() {
+	if ri.bgpSrv == nil {
+		return
+	}
+
+	ri.stopFlowSpec()
+	ri.bgpSrv.Stop()
+	ri.bgpSrv = nil
+	ri.bgpAPI = nil
+	log.Infof("BGP disabled in instance %q", ri.name)
+	eventLog.Record("bgp", ri.name, "BGP disabled", "")
+}
+
+// configureProtocolsBGP applies the BGP config to the running BGP server. bgpMu must be held.
+func (ri *routingInstance) configureProtocolsBGP(bgp *config.BGP) error {
+	// Groups are resolved by the config already. They are registered as peer groups so their members share update generation.
+	for _, g := range bgp.Groups {
+		if g.Name == "" {
+			continue
+		}
+
+		err := ri.bgpSrv.AddPeerGroup(bgpserver.PeerGroupConfig{
+			Name: g.Name,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Unable to add BGP peer group %q", g.Name)
+		}
+	}
+
+	err := ri.configureListenRanges(bgp)
+	if err != nil {
+		return err
+	}
+
+	ri.configureAggregates(bgp)
+	ri.configureORR(bgp)
+
+	err = ri.configureBMP(bgp)
+	if err != nil {
+		return err
+	}
+
+	err = ri.configureMRT(bgp)
+	if err != nil {
+		return err
+	}
+
+	err = ri.configureFlowSpec(bgp)
+	if err != nil {
+		return err
+	}
+
+	// Tear down peers that are to be removed. Dynamic peers are kept unless their address got configured explicitly.
+	for _, p := range ri.bgpSrv.GetPeers() {
+		found := false
+		for _, g := range bgp.Groups {
+			for _, n := range g.Neighbors {
+				if n.PeerAddressIP == p {
+					found = true
+					break
+				}
+			}
+		}
+
+		if found == ri.bgpSrv.IsDynamicPeer(p) {
+			ri.bgpSrv.DisposePeer(p)
+		}
+	}
+
+	// Tear down peers that need new sessions as they changed too significantly
+	for _, g := range bgp.Groups {
+		for _, n := range g.Neighbors {
+			newCfg := BGPPeerConfig(n, ri.vrfReg, ri.bgpSrv.RouterID())
+			oldCfg := ri.bgpSrv.GetPeerConfig(n.PeerAddressIP)
+			if oldCfg == nil {
+				continue
+			}
+
+			if !oldCfg.NeedsRestart(newCfg) {
+				err := ri.bgpSrv.ReplaceAddressFamilies(*newCfg)
+				if err != nil {
+					return errors.Wrap(err, "Unable to replace address families")
+				}
+
+				err = ri.bgpSrv.ReplaceFilterChains(n.PeerAddressIP, n.ImportFilterChain, n.ExportFilterChain)
+				if err != nil {
+					return errors.Wrap(err, "Unable to replace filter chains")
+				}
+				continue
+			}
+
+			ri.bgpSrv.DisposePeer(oldCfg.PeerAddress)
+		}
+	}
+
+	// Turn up all sessions that are missing
+	for _, g := range bgp.Groups {
+		for _, n := range g.Neighbors {
+			if ri.bgpSrv.GetPeerConfig(n.PeerAddressIP) != nil {
+				continue
+			}
+
+			newCfg := BGPPeerConfig(n, ri.vrfReg, ri.bgpSrv.RouterID())
+			err := ri.bgpSrv.AddPeer(*newCfg)
+			if err != nil {
+				return errors.Wrap(err, "Unable to add BGP peer")
+			}
+		}
+	}
+
+	return ri.removeStalePeerGroups(bgp)
+}
+
+// configureAggregates replaces the aggregates of the master VRF. bgpMu must be held.
+func (ri *routingInstance) configureAggregates(bgp *config.BGP) {
+	ipv4 := make([]bgpserver.AggregateConfig, 0)
+	ipv6 := make([]bgpserver.AggregateConfig, 0)
+	for _, a := range bgp.Aggregates {
+		c := bgpserver.AggregateConfig{
+			Prefix:      a.PrefixParsed,
+			LocalAS:     a.LocalAS,
+			SummaryOnly: a.SummaryOnly,
+			ASSet:       a.ASSet,
+		}
+
+		if a.PrefixParsed.Addr().IsIPv4() {
+			ipv4 = append(ipv4, c)
+		} else {
+			ipv6 = append(ipv6, c)
+		}
+	}
+
+	v := ri.vrfReg.GetVRFByRD(0)
+	ri.bgpSrv.ReplaceAggregates(v.IPv4UnicastRIB(), ipv4)
+	ri.bgpSrv.ReplaceAggregates(v.IPv6UnicastRIB(), ipv6)
+}
+
+// configureORR sets the IGP costs of the virtual locations of the optimal route reflection groups. bgpMu must be held.
+func (ri *routingInstance) configureORR(bgp *config.BGP) {
+	for _, o := range bgp.OptimalRouteReflection {
+		costs := make(map[bnet.IP]uint32, len(o.IGPCosts))
+		for _, c := range o.IGPCosts {
+			costs[*c.AddressIP] = c.Cost
+		}
+
+		ri.bgpSrv.SetORRIGPCosts(o.Name, route.NewIGPCostTable(costs))
+	}
+}
+
+// removeStalePeerGroups removes peer groups of BGP groups no longer configured. bgpMu must be held.
+func (ri *routingInstance) removeStalePeerGroups(bgp *config.BGP) error {
+	configured := make(map[string]struct{})
+	for _, g := range bgp.Groups {
+		configured[g.Name] = struct{}{}
+	}
+
+	for _, g := range ri.bgpSrv.GetPeerGroups() {
+		if _, ok := configured[g.Name]; ok {
+			continue
+		}
+
+		err := ri.bgpSrv.RemovePeerGroup(g.Name)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to remove BGP peer group %q", g.Name)
+		}
+	}
+
+	return nil
+}
+
+// configureListenRanges applies the listen ranges of all BGP groups. bgpMu must be held.
+func (ri *routingInstance) configureListenRanges(bgp *config.BGP) error {
+	configured := make(map[string]struct{})
+	for _, g := range bgp.Groups {
+		for _, pfx := range g.ListenRangePrefixes {
+			configured[pfx.String()] = struct{}{}
+
+			err := ri.bgpSrv.AddListenRange(bgpserver.ListenRange{
+				Prefix:   pfx,
+				Template: *BGPPeerConfig(g.DynamicNeighborTemplate, ri.vrfReg, ri.bgpSrv.RouterID()),
+				MaxPeers: g.MaxDynamicPeers,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "Unable to add listen range %s", pfx.String())
+			}
+		}
+	}
+
+	for _, r := range ri.bgpSrv.GetListenRanges() {
+		if _, ok := configured[r.Prefix.String()]; !ok {
+			ri.bgpSrv.RemoveListenRange(r.Prefix)
+		}
+	}
+
+	return nil
+}
+
+// configureBMP applies the BMP stations. bgpMu must be held.
+func (ri *routingInstance) configureBMP(bgp *config.BGP) error {
+	configured := make(map[string]struct{})
+	for _, s := range bgp.BMP {
+		configured[s.Address] = struct{}{}
+
+		err := ri.bgpSrv.AddBMPStation(bgpserver.BMPStationConfig{
+			Address:           s.Address,
+			ReconnectInterval: time.Second * time.Duration(s.ReconnectInterval),
+			StatsInterval:     time.Second * time.Duration(s.StatsInterval),
+			AdjRIBOut:         s.AdjRIBOut,
+			LocRIB:            s.LocRIB,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Unable to add BMP station %q", s.Address)
+		}
+	}
+
+	for _, s := range ri.bgpSrv.GetBMPStations() {
+		if _, ok := configured[s.Address]; !ok {
+			ri.bgpSrv.RemoveBMPStation(s.Address)
+		}
+	}
+
+	return nil
+}
+
+// configureFlowSpec (re)starts the installation of received FlowSpec rules if its config changed. bgpMu must be held.
+func (ri *routingInstance) configureFlowSpec(bgp *config.BGP) error {
+	if ri.flowSpec != nil && reflect.DeepEqual(ri.flowSpecCfg, bgp.FlowSpecEnforcement) {
+		return nil
+	}
+
+	ri.stopFlowSpec()
+	if bgp.FlowSpecEnforcement == nil {
+		return nil
+	}
+
+	cfg := flowspec.Config{
+		Table:        bgp.FlowSpecEnforcement.Table,
+		VRFMarks:     make(map[types.ExtendedCommunity]uint32),
+		NextHopMarks: make(map[bnet.IP]uint32),
+	}
+
+	for _, m := range bgp.FlowSpecEnforcement.RedirectMarks {
+		if m.RouteTargetInternal != nil {
+			cfg.VRFMarks[*m.RouteTargetInternal] = m.Mark
+			continue
+		}
+
+		cfg.NextHopMarks[*m.NextHopIP] = m.Mark
+	}
+
+	e, err := flowspec.New(cfg)
+	if err != nil {
+		return errors.Wrap(err, "Unable to start FlowSpec enforcement")
+	}
+
+	e.Start()
+	ri.bgpSrv.RegisterFlowSpecClient(e)
+	ri.flowSpec = e
+	ri.flowSpecCfg = bgp.FlowSpecEnforcement
+
+	return nil
+}
+
+// stopFlowSpec stops the installation of received FlowSpec rules and removes installed rules. bgpMu must be held.
+func (ri *routingInstance) stopFlowSpec() {
+	if ri.flowSpec == nil {
+		return
+	}
+
+	ri.bgpSrv.UnregisterFlowSpecClient(ri.flowSpec)
+	ri.flowSpec.Stop()
+	ri.flowSpec = nil
+	ri.flowSpecCfg = nil
+}
+
+// configureMRT applies the MRT dump config. bgpMu must be held.
+func (ri *routingInstance) configureMRT(bgp *config.BGP) error {
+	if bgp.MRT == nil {
+		return ri.bgpSrv.SetMRTDump(nil)
+	}
+
+	err := ri.bgpSrv.SetMRTDump(&bgpserver.MRTDumpConfig{
+		Directory:       bgp.MRT.Directory,
+		RIBInterval:     time.Second * time.Duration(bgp.MRT.RIBInterval),
+		UpdatesInterval: time.Second * time.Duration(bgp.MRT.UpdatesInterval),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Unable to configure MRT dumps")
+	}
+
+	return nil
+}
+
+func (ri *routingInstance) configureRoutingInstance(vri *config.RoutingInstance) error {
+	vrf := ri.vrfReg.GetVRFByName(vri.Name)
+
+	// RD Change
+	if vrf != nil && vrf.RD() != vri.InternalRouteDistinguisher {
+		// TODO: Drop all routing adjacencies
+		vrf.Dispose()
+		ri.vrfReg.UnregisterVRF(vrf)
+		vrf = nil
+	}
+
+	if vrf == nil {
+		vrf = ri.vrfReg.CreateVRFIfNotExists(vri.Name, vri.InternalRouteDistinguisher)
+		// TODO: Add all routing adjacencies
+	}
+
+	// Changed route targets take effect for VPN sessions established afterwards
+	vrf.SetRouteTargets(vri.InternalImportRouteTargets, vri.InternalExportRouteTargets)
+	vrf.SetBestPathOptions(bestPathOptions(vri.RoutingOptions))
+	return nil
+}
+
+// bestPathOptions converts the best path config of ro into options of the BGP decision process
+func bestPathOptions(ro *config.RoutingOptions) *route.BestPathOptions {
+	if ro == nil || ro.BestPath == nil {
+		return nil
+	}
+
+	bp := ro.BestPath
+	return &route.BestPathOptions{
+		AlwaysCompareMED:   bp.AlwaysCompareMED,
+		DeterministicMED:   bp.DeterministicMED,
+		IgnoreASPathLength: bp.IgnoreASPathLength,
+		IgnoreRouterID:     bp.CompareRouterID != nil && !*bp.CompareRouterID,
+		PreferOldest:       bp.PreferOldest,
+	}
+}
+
+// dispose tears down all sessions of the instance and removes its routes from the FIB
+func (ri *routingInstance) dispose() {
+	ri.bgpMu.Lock()
+	ri.stopBGP()
+	ri.bgpMu.Unlock()
+
+	if ri.kernel != nil {
+		ri.kernel.Dispose()
+	}
+
+	if ri.collector != nil {
+		unregisterInstanceMetrics(ri, ri.collector)
+	}
+}
+
+// BGPPeerConfig converts a BGPNeighbor config into a PeerConfig. Unicast routes are exchanged with the master VRF of vrfReg,
+// VPN routes with all VRFs of vrfReg.
+func BGPPeerConfig(n *config.BGPNeighbor, vrfReg *vrf.VRFRegistry, routerID uint32) *bgpserver.PeerConfig {
+	r := &bgpserver.PeerConfig{
+		PeerGroup:            n.PeerGroup,
+		AuthenticationKey:    n.AuthenticationKey,
+		LocalAS:              n.LocalAS,
+		PeerAS:               n.PeerAS,
+		PeerAddress:          n.PeerAddressIP,
+		LocalAddress:         n.LocalAddressIP,
+		TTL:                  n.TTL,
+		TTLSecurityHops:      n.TTLSecurityHops,
+		ReconnectInterval:    time.Second * 15,
+		MaxReconnectInterval: time.Second * time.Duration(n.MaxReconnect),
+		HoldTime:             n.HoldTimeDuration,
+		KeepAlive:            n.HoldTimeDuration / 3,
+		RouterID:             routerID,
+		VRF:                  vrfReg.GetVRFByRD(0),
+	}
+
+	// Peers without address family config keep the historic default of IPv4 unicast sending up to 10 paths
+	if len(n.AFIs) == 0 {
+		r.IPv4 = &bgpserver.AddressFamilyConfig{
+			ImportFilterChain: n.ImportFilterChain,
+			ExportFilterChain: n.ExportFilterChain,
+			AddPathSend: routingtable.ClientOptions{
+				MaxPaths: 10,
+			},
+		}
+		setAdvertisementInterval(r.IPv4, n, nil)
+		setNextHopSelf(r.IPv4, n, "")
+	}
+
+	for _, afi := range n.AFIs {
+		if afi.Name == config.AFILinkState {
+			r.LinkState = true
+			continue
+		}
+
+		if afi.SAFI.Name == config.SAFIRouteTarget {
+			r.RouteTargetConstraint = true
+			continue
+		}
+
+		if afi.SAFI.Name == config.SAFIFlowSpec {
+			r.FlowSpecImportFilterChain = n.ImportFilterChain
+			switch afi.Name {
+			case config.AFIIPv4:
+				r.IPv4FlowSpec = true
+			case config.AFIIPv6:
+				r.IPv6FlowSpec = true
+			}
+			continue
+		}
+
+		if afi.SAFI.Name == config.SAFISRPolicy {
+			switch afi.Name {
+			case config.AFIIPv4:
+				r.IPv4SRPolicy = true
+			case config.AFIIPv6:
+				r.IPv6SRPolicy = true
+			}
+			continue
+		}
+
+		if afi.SAFI.Name == config.SAFIVPN {
+			vpn := &bgpserver.VPNConfig{
+				ImportFilterChain: n.ImportFilterChain,
+				ExportFilterChain: n.ExportFilterChain,
+				VRFs:              vrfReg,
+			}
+
+			switch afi.Name {
+			case config.AFIIPv4:
+				r.IPv4VPN = vpn
+			case config.AFIIPv6:
+				r.IPv6VPN = vpn
+			}
+
+			continue
+		}
+
+		afc := &bgpserver.AddressFamilyConfig{
+			ImportFilterChain: n.ImportFilterChain,
+			ExportFilterChain: n.ExportFilterChain,
+			AddPathSend: routingtable.ClientOptions{
+				BestOnly: true,
+			},
+		}
+
+		setAdvertisementInterval(afc, n, afi.SAFI.AdvertisementInterval)
+		setNextHopSelf(afc, n, afi.SAFI.NextHopSelf)
+
+		if l := afi.SAFI.PrefixLimit; l != nil {
+			afc.PrefixLimit = &bgpserver.PrefixLimit{
+				Max:               l.Max,
+				WarningThreshold:  l.WarningThreshold,
+				WarningThresholds: l.WarningThresholds,
+				WarningOnly:       l.WarningOnly,
+				RestartInterval:   time.Second * time.Duration(l.RestartInterval),
+			}
+		}
+
+		if ap := afi.SAFI.AddPath; ap != nil {
+			afc.AddPathRecv = ap.Receive
+			if ap.Send != nil {
+				afc.AddPathSend = routingtable.ClientOptions{
+					EcmpOnly: ap.Send.Multipath,
+					MaxPaths: uint(ap.Send.PathCount),
+				}
+			}
+		}
+
+		switch {
+		case afi.Name == config.AFIIPv4 && afi.SAFI.Name == config.SAFILabeledUnicast:
+			r.IPv4LabeledUnicast = afc
+		case afi.Name == config.AFIIPv6 && afi.SAFI.Name == config.SAFILabeledUnicast:
+			r.IPv6LabeledUnicast = afc
+		case afi.Name == config.AFIIPv4:
+			r.IPv4 = afc
+		case afi.Name == config.AFIIPv6:
+			r.IPv6 = afc
+		}
+	}
+
+	if gr := n.GracefulRestart; gr != nil && !gr.Disabled {
+		r.GracefulRestart = &bgpserver.GracefulRestartConfig{
+			RestartTime:   time.Second * time.Duration(gr.RestartTime),
+			StalePathTime: time.Second * time.Duration(gr.StalePathTime),
+		}
+	}
+
+	if n.Passive != nil {
+		r.Passive = *n.Passive
+	}
+
+	if n.ActiveOnly != nil {
+		r.ActiveOnly = *n.ActiveOnly
+	}
+
+	if n.DynamicCapability != nil {
+		r.DynamicCapability = *n.DynamicCapability
+	}
+
+	if n.NextHopTracking != nil {
+		r.NextHopTracking = *n.NextHopTracking
+	}
+
+	if n.ExtendedNextHop != nil {
+		r.ExtendedNextHop = *n.ExtendedNextHop
+	}
+
+	if n.FlowSpecNoValidation != nil {
+		r.FlowSpecNoValidation = *n.FlowSpecNoValidation
+	}
+
+	if m := n.LocalASMigration; m != nil {
+		r.LocalASMigration = &bgpserver.LocalASMigrationConfig{
+			ASN:       m.AS,
+			NoPrepend: m.NoPrepend,
+			ReplaceAS: m.ReplaceAS,
+		}
+	}
+
+	if n.AllowASIn != nil {
+		r.AllowASIn = *n.AllowASIn
+	}
+
+	if n.ASOverride != nil {
+		r.ASOverride = *n.ASOverride
+	}
+
+	switch n.RemovePrivateAS {
+	case "remove":
+		r.RemovePrivateAS = route.PrivateASRemove
+	case "all":
+		r.RemovePrivateAS = route.PrivateASRemoveAll
+	case "replace":
+		r.RemovePrivateAS = route.PrivateASReplace
+	}
+
+	if n.RouteServerClient != nil {
+		r.RouteServerClient = *n.RouteServerClient
+	}
+
+	if n.RouteReflectorClient != nil {
+		r.RouteReflectorClient = *n.RouteReflectorClient
+	}
+
+	if n.ClusterIDIP != nil {
+		r.RouteReflectorClusterID = n.ClusterIDIP.ToUint32()
+	}
+
+	if n.ClientToClientReflection != nil {
+		r.NoClientToClientReflection = !*n.ClientToClientReflection
+	}
+
+	if n.OriginatorIDCheck != nil {
+		r.SkipOriginatorIDCheck = !*n.OriginatorIDCheck
+	}
+
+	if n.ClusterListCheck != nil {
+		r.SkipClusterListCheck = !*n.ClusterListCheck
+	}
+
+	if n.IgnoreWellKnownCommunities != nil {
+		r.IgnoreWellKnownCommunities = *n.IgnoreWellKnownCommunities
+	}
+
+	if n.BilateralPeer != nil {
+		r.BilateralPeer = *n.BilateralPeer
+	}
+
+	r.ORRGroup = n.ORRGroup
+	r.ShutdownCommunication = n.ShutdownCommunication
+	r.RecordDirectory = n.RecordDirectory
+
+	if mp := n.Multipath; mp != nil && mp.Enable {
+		r.Multipath = route.MultipathSameAS
+		if mp.MulipleAS {
+			r.Multipath = route.MultipathMultipleAS
+		}
+	}
+
+	return r
+}
+
+// setAdvertisementInterval sets the MRAI of an address family. The interval of the safi takes precedence over the one of the neighbor.
+func setAdvertisementInterval(afc *bgpserver.AddressFamilyConfig, n *config.BGPNeighbor, safiInterval *uint16) {
+	interval := n.AdvertisementInterval
+	if safiInterval != nil {
+		interval = *safiInterval
+	}
+
+	afc.AdvertisementInterval = time.Second * time.Duration(interval)
+	if n.NoAdvertisementIntervalIBGP != nil {
+		afc.NoAdvertisementIntervalIBGP = *n.NoAdvertisementIntervalIBGP
+	}
+}
+
+// setNextHopSelf sets the next hop self mode of an address family. The mode of the safi takes precedence over the one of the neighbor.
+func setNextHopSelf(afc *bgpserver.AddressFamilyConfig, n *config.BGPNeighbor, safiMode string) {
+	mode := n.NextHopSelf
+	if safiMode != "" {
+		mode = safiMode
+	}
+
+	switch mode {
+	case config.NextHopSelfEnable:
+		afc.NextHopSelf = route.NextHopSelfEnabled
+	case config.NextHopSelfForce:
+		afc.NextHopSelf = route.NextHopSelfForce
+	}
+}
+
+// instanceRegistry holds all routing instances of the process
+type instanceRegistry struct {
+	instances map[string]*routingInstance
+	mu        sync.RWMutex
+}
+
+func newInstanceRegistry() *instanceRegistry {
+	return &instanceRegistry{
+		instances: make(map[string]*routingInstance),
+	}
+}
+
+func (r *instanceRegistry) get(name string) *routingInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.instances[name]
+}
+
+func (r *instanceRegistry) add(ri *routingInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.instances[ri.name]; exists {
+		return fmt.Errorf("instance %q already exists", ri.name)
+	}
+
+	r.instances[ri.name] = ri
+	return nil
+}
+
+func (r *instanceRegistry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.instances, name)
+}
+
+func (r *instanceRegistry) list() []*routingInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ret := make([]*routingInstance, 0, len(r.instances))
+	for _, ri := range r.instances {
+		ret = append(ret, ri)
+	}
+
+	return ret
+}
+
+// loadInstances creates, reconfigures and removes the additional routing instances defined in cfg
+func loadInstances(cfg *config.Config) error {
+	wanted := make(map[string]struct{})
+	wanted[defaultInstanceName] = struct{}{}
+
+	for _, inst := range cfg.Instances {
+		wanted[inst.Name] = struct{}{}
+
+		ri := instances.get(inst.Name)
+		if ri != nil && ri.routerID != inst.RoutingOptions.RouterIDUint32 {
+			log.Infof("Router ID of instance %q changed. Restarting instance.", inst.Name)
+			ri.dispose()
+			instances.remove(inst.Name)
+			ri = nil
+		}
+
+		if ri == nil {
+			var err error
+			ri, err = newRoutingInstance(inst.Name, inst.RoutingOptions, bgpListenAddrs(inst.Protocols, nil))
+			if err != nil {
+				return errors.Wrapf(err, "Unable to create instance %q", inst.Name)
+			}
+
+			err = instances.add(ri)
+			if err != nil {
+				ri.dispose()
+				return errors.Wrapf(err, "Unable to add instance %q", inst.Name)
+			}
+		}
+
+		err := ri.loadConfig(inst.RoutingOptions, nil, inst.Protocols)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to configure instance %q", inst.Name)
+		}
+	}
+
+	for _, ri := range instances.list() {
+		if _, ok := wanted[ri.name]; ok {
+			continue
+		}
+
+		log.Infof("Removing instance %q", ri.name)
+		ri.dispose()
+		instances.remove(ri.name)
+	}
+
+	return nil
+}
+
+func bgpListenAddrs(p *config.Protocols, def []string) []string {
+	if p == nil || p.BGP == nil || len(p.BGP.ListenAddresses) == 0 {
+		return def
+	}
+
+	return p.BGP.ListenAddresses
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceRegistryAdd(t *testing.T) {
+	r := newInstanceRegistry()
+
+	foo := &routingInstance{name: "foo"}
+	assert.NoError(t, r.add(foo))
+	assert.Error(t, r.add(&routingInstance{name: "foo"}), "Duplicate instance")
+	assert.True(t, foo == r.get("foo"), "Existing instance must be kept")
+}
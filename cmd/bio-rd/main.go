@@ -1,65 +1,158 @@
 package main
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/bio-routing/bio-rd/cmd/bio-rd/api"
 	"github.com/bio-routing/bio-rd/cmd/bio-rd/config"
+	bioconfig "github.com/bio-routing/bio-rd/config"
 	bgpapi "github.com/bio-routing/bio-rd/protocols/bgp/api"
 	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
-	"github.com/bio-routing/bio-rd/routingtable"
-	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/bio-routing/bio-rd/util/eventlog"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
+	"github.com/bio-routing/bio-rd/util/logging"
 	"github.com/bio-routing/bio-rd/util/servicewrapper"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 )
 
 var (
-	configFilePath       = flag.String("config.file", "bio-rd.yml", "bio-rd config file")
+	configFilePath       = flag.String("config.file", "bio-rd.yml", "bio-rd startup config file")
+	savedConfigFilePath  = flag.String("config.saved_file", "bio-rd.saved.yml", "File the running config is saved to")
+	bootSavedConfig      = flag.Bool("config.boot_saved", false, "Boot with the last saved config (if present) instead of the startup config")
 	grpcPort             = flag.Uint("grpc_port", 5566, "GRPC API server port")
 	grpcKeepaliveMinTime = flag.Uint("grpc_keepalive_min_time", 1, "Minimum time (seconds) for a client to wait between GRPC keepalive pings")
+	grpcTLSCert          = flag.String("grpc_tls_cert", "", "GRPC server TLS certificate file (enables TLS)")
+	grpcTLSKey           = flag.String("grpc_tls_key", "", "GRPC server TLS key file")
+	grpcTLSClientCA      = flag.String("grpc_tls_client_ca", "", "CA file to verify GRPC client certificates against (enables mutual TLS)")
+	grpcAuthzFile        = flag.String("grpc_authz_file", "", "GRPC authorization config file (enables per method authorization)")
 	metricsPort          = flag.Uint("metrics_port", 55667, "Metrics HTTP server port")
-	sigHUP               = make(chan os.Signal)
-	vrfReg               = vrf.NewVRFRegistry()
-	bgpSrv               bgpserver.BGPServer
+	logLevel             = flag.String("log.level", "info", "Default log level")
+	logFormat            = flag.String("log.format", "text", "Log format (text or json)")
+	eventLogSize         = flag.Int("eventlog.size", 1000, "Number of events kept in memory")
+	eventLogFile         = flag.String("eventlog.file", "", "File to append events to (JSON lines)")
+	eventLogSyslog       = flag.Bool("eventlog.syslog", false, "Send events to syslog")
+	flightRecorderSize   = flag.Int("flightrecorder.size", 32, "Number of state transitions kept per state machine")
+	crashDumpDir         = flag.String("crashdump.dir", os.TempDir(), "Directory state dumps are written to on panic or fatal error")
+	convergenceTimeout   = flag.Duration("health.convergence_timeout", time.Minute, "Time after which initial BGP convergence is considered complete even if not all sessions are established")
+	configReloadInterval = flag.Duration("config.reload_interval", 0, "Interval to periodically reload the config to pick up rotated secrets (0 = disabled)")
+	mplsDynamicLabels    = flag.String("mpls.dynamic_labels", "24000-1048575", "Range labels are dynamically allocated from")
+	mplsSRGB             = flag.String("mpls.srgb", "16000-23999", "Segment routing global block")
+	mplsSRLB             = flag.String("mpls.srlb", "15000-15999", "Segment routing local block")
+	rtrListen            = flag.String("rtr.listen", "", "Address to serve VRPs to routers via RTR on (empty = disabled)")
+	rtrVRPFile           = flag.String("rtr.vrp_file", "", "JSON file to load VRPs from")
+	rtrSLURMFile         = flag.String("rtr.slurm_file", "", "SLURM file with local exceptions applied to the VRPs served")
+	rtrUpstream          = flag.String("rtr.upstream", "", "RTR cache (host:port) to fetch VRPs from instead of a file")
+	rtrReloadInterval    = flag.Duration("rtr.reload_interval", 5*time.Minute, "Interval to reload the VRP and SLURM files")
+	rovRTRServer         = flag.String("rov.rtr_server", "", "RTR cache (host:port) to fetch VRPs for BGP origin validation from (empty = disabled)")
+	rovSLURMFile         = flag.String("rov.slurm_file", "", "SLURM file applied to the VRPs used for origin validation. Without -rov.rtr_server its local assertions are used as VRPs.")
+	rovReloadInterval    = flag.Duration("rov.reload_interval", 5*time.Minute, "Interval to reload the origin validation SLURM file")
+	mrtReplayDir         = flag.String("mrt.replay_dir", "", "Directory MRT files replayed via the management API are read from (empty = replay disabled)")
+	bgpRestarting        = flag.Bool("bgp.restarting", false, "Tell graceful restart capable BGP peers we restarted and defer advertisements until they sent End-of-RIB")
+	bgpSelectionDeferral = flag.Duration("bgp.selection_deferral_time", bgpserver.DefaultSelectionDeferralTime, "Maximum time advertisements are deferred with -bgp.restarting")
+	bgpForwardingState   = flag.Bool("bgp.forwarding_state_preserved", false, "Tell BGP peers our forwarding state survived the restart (only with -bgp.restarting)")
+	sigHUP               = make(chan os.Signal, 1)
+	instances            = newInstanceRegistry()
+	eventLog             *eventlog.EventLog
+	flightRecorder       *flightrecorder.Registry
+	labelManager         *labelmanager.LabelManager
+	crashDumper          *crashdump.Dumper
+	activeConfigFilePath string
 	runCfg               *config.Config
+	runCfgMu             sync.RWMutex
 )
 
 func main() {
 	flag.Parse()
 
-	startCfg, err := config.GetConfig(*configFilePath)
+	err := logging.SetFormat(*logFormat)
+	if err != nil {
+		log.Fatalf("Unable to set log format: %v", err)
+	}
+
+	err = logging.SetDefaultLevel(*logLevel)
+	if err != nil {
+		log.Fatalf("Unable to set log level: %v", err)
+	}
+
+	eventLog, err = newEventLog()
+	if err != nil {
+		log.Fatalf("Unable to create event log: %v", err)
+	}
+
+	flightRecorder = newFlightRecorder()
+	labelManager, err = newLabelManager()
+	if err != nil {
+		log.Fatalf("Unable to create label manager: %v", err)
+	}
+
+	crashDumper = newCrashDumper(*crashDumpDir)
+	defer crashDumper.Recover()
+
+	activeConfigFilePath = *configFilePath
+	if *bootSavedConfig {
+		if _, err := os.Stat(*savedConfigFilePath); err == nil {
+			activeConfigFilePath = *savedConfigFilePath
+		} else {
+			log.Warningf("No saved config found at %q. Booting with startup config.", *savedConfigFilePath)
+		}
+	}
+
+	log.Infof("Using config file %q", activeConfigFilePath)
+	startCfg, err := config.GetConfig(activeConfigFilePath)
 	if err != nil {
 		log.Errorf("Unable to get config: %v", err)
 		os.Exit(1)
 	}
+	logMigrationReport(startCfg)
 
-	bgpSrv = bgpserver.NewBGPServer(
-		startCfg.RoutingOptions.RouterIDUint32,
-		[]string{
-			"[::]:179",
-			"0.0.0.0:179",
-		},
-	)
-
-	err = bgpSrv.Start()
+	defaultInstance, err := newRoutingInstance(defaultInstanceName, startCfg.RoutingOptions, bgpListenAddrs(startCfg.Protocols, defaultBGPListenAddrs))
 	if err != nil {
-		log.Fatalf("Unable to start BGP server: %v", err)
+		log.Fatalf("Unable to create default instance: %v", err)
 		os.Exit(1)
 	}
+	err = instances.add(defaultInstance)
+	if err != nil {
+		log.Fatalf("Unable to add default instance: %v", err)
+	}
 
-	vrfReg.CreateVRFIfNotExists("master", 0)
+	err = startRTRCache()
+	if err != nil {
+		log.Fatalf("Unable to start RTR cache: %v", err)
+	}
+
+	err = startOriginValidation()
+	if err != nil {
+		log.Fatalf("Unable to start origin validation: %v", err)
+	}
 
 	go configReloader()
 	sigHUP <- syscall.SIGHUP
 	installSignalHandler()
+	if *configReloadInterval > 0 {
+		go periodicConfigReloader(*configReloadInterval)
+	}
+
+	sec, err := servicewrapper.NewSecurityConfig(*grpcTLSCert, *grpcTLSKey, *grpcTLSClientCA, *grpcAuthzFile)
+	if err != nil {
+		log.Fatalf("Unable to configure GRPC security: %v", err)
+	}
+	setMethodRoles(sec.Authorizer)
 
-	s := bgpserver.NewBGPAPIServer(bgpSrv)
 	unaryInterceptors := []grpc.UnaryServerInterceptor{}
 	streamInterceptors := []grpc.StreamServerInterceptor{}
 	srv, err := servicewrapper.New(
@@ -71,13 +164,23 @@ func main() {
 			MinTime:             time.Duration(*grpcKeepaliveMinTime) * time.Second,
 			PermitWithoutStream: true,
 		},
+		sec,
 	)
 	if err != nil {
 		log.Errorf("failed to listen: %v", err)
 		os.Exit(1)
 	}
 
-	bgpapi.RegisterBgpServiceServer(srv.GRPC(), s)
+	bgpapi.RegisterBgpServiceServer(srv.GRPC(), newBGPAPIRouter(instances))
+	api.RegisterManagementServiceServer(srv.GRPC(), &managementAPIServer{})
+
+	hc := newHealthChecker()
+	http.Handle("/healthz", hc.LiveHandler())
+	http.Handle("/readyz", hc.ReadyHandler())
+	grpcHealth := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(srv.GRPC(), grpcHealth)
+	go hc.SyncGRPC(grpcHealth, time.Second)
+
 	if err := srv.Serve(); err != nil {
 		log.Fatalf("failed to start server: %v", err)
 	}
@@ -85,151 +188,189 @@ func main() {
 	select {}
 }
 
+// readMethods are the GRPC methods not changing state. They require the read role if authorization is enabled,
+// all other methods require the write role.
+var readMethods = []string{
+	"/bio.bgp.BgpService/ListSessions",
+	"/bio.bgp.BgpService/DumpRIBIn",
+	"/bio.bgp.BgpService/DumpRIBOut",
+	"/bio.bgp.BgpService/LookupRoutes",
+	"/bio.management.ManagementService/GetLogLevels",
+	"/bio.management.ManagementService/GetEvents",
+	"/bio.management.ManagementService/GetFlightRecorder",
+	"/bio.management.ManagementService/GetLabelAllocations",
+}
+
+// setMethodRoles marks the readMethods as read only unless configured otherwise
+func setMethodRoles(a servicewrapper.Authorizer) {
+	ra, ok := a.(*servicewrapper.RoleAuthorizer)
+	if !ok {
+		return
+	}
+
+	for _, m := range readMethods {
+		ra.SetMethodRoleIfUnset(m, servicewrapper.RoleRead)
+	}
+}
+
 func installSignalHandler() {
 	signal.Notify(sigHUP, syscall.SIGHUP)
 }
 
+// periodicConfigReloader triggers a config reload in the given interval.
+// This makes sure secrets referenced from environment, files or external providers are re-read after rotation.
+func periodicConfigReloader(interval time.Duration) {
+	t := time.NewTicker(interval)
+	for range t.C {
+		sigHUP <- syscall.SIGHUP
+	}
+}
+
 func configReloader() {
+	defer crashDumper.Recover()
+
 	for {
 		<-sigHUP
 		log.Infof("Reloading configuration")
-		newCfg, err := config.GetConfig(*configFilePath)
+		newCfg, err := config.GetConfig(activeConfigFilePath)
 		if err != nil {
 			log.Errorf("Failed to get config: %v", err)
+			eventLog.Record("config", activeConfigFilePath, "config reload failed", err.Error())
 			continue
 		}
+		logMigrationReport(newCfg)
 
 		err = loadConfig(newCfg)
 		if err != nil {
 			log.Errorf("Unable to load config: %v", err)
+			eventLog.Record("config", activeConfigFilePath, "config reload failed", err.Error())
 			continue
 		}
 
+		eventLog.Record("config", activeConfigFilePath, "config reloaded", "")
+
+		runCfgMu.Lock()
+		runCfg = newCfg
+		runCfgMu.Unlock()
 		log.Infof("Configuration reloaded")
 	}
 }
 
-func loadConfig(cfg *config.Config) error {
+func newEventLog() (*eventlog.EventLog, error) {
+	l := eventlog.New(*eventLogSize)
 
-	for _, ri := range cfg.RoutingInstances {
-		err := configureRoutingInstance(ri)
-		_ = err
+	if *eventLogFile != "" {
+		s, err := eventlog.NewFileSink(*eventLogFile)
+		if err != nil {
+			return nil, err
+		}
 
+		l.AddSink(s)
 	}
 
-	if cfg.Protocols != nil {
-		if cfg.Protocols.BGP != nil {
-			err := configureProtocolsBGP(cfg.Protocols.BGP)
-			if err != nil {
-				return errors.Wrap(err, "Unable to configure BGP")
-			}
+	if *eventLogSyslog {
+		s, err := eventlog.NewSyslogSink("bio-rd")
+		if err != nil {
+			return nil, err
 		}
+
+		l.AddSink(s)
 	}
 
-	return nil
+	return l, nil
 }
 
-func configureProtocolsBGP(bgp *config.BGP) error {
-	// Tear down peers that are to be removed
-	for _, p := range bgpSrv.GetPeers() {
-		found := false
-		for _, g := range bgp.Groups {
-			for _, n := range g.Neighbors {
-				if n.PeerAddressIP == p {
-					found = true
-					break
-				}
-			}
-		}
+// newFlightRecorder creates the flight recorder shared by all state machines.
+// Recorders of state machines making unexpected transitions are dumped to the log.
+func newFlightRecorder() *flightrecorder.Registry {
+	r := flightrecorder.NewRegistry(*flightRecorderSize)
+	l := logging.Subsystem("flightrecorder")
+	r.SetDumpFunc(func(rec *flightrecorder.Recorder) {
+		buf := bytes.NewBuffer(nil)
+		rec.Dump(buf)
+		l.Warningf("Unexpected state transition. Flight recorder dump:\n%s", buf.String())
+	})
 
-		if !found {
-			bgpSrv.DisposePeer(p)
+	return r
+}
+
+// newLabelManager creates the label manager shared by all MPLS applications
+func newLabelManager() (*labelmanager.LabelManager, error) {
+	cfg := &bioconfig.MPLSConfig{}
+	for _, x := range []struct {
+		flag  string
+		value string
+		r     *bioconfig.LabelRange
+	}{
+		{flag: "mpls.dynamic_labels", value: *mplsDynamicLabels, r: &cfg.DynamicLabels},
+		{flag: "mpls.srgb", value: *mplsSRGB, r: &cfg.SRGB},
+		{flag: "mpls.srlb", value: *mplsSRLB, r: &cfg.SRLB},
+	} {
+		_, err := fmt.Sscanf(x.value, "%d-%d", &x.r.Start, &x.r.End)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid %s %q", x.flag, x.value)
 		}
 	}
 
-	// Tear down peers that need new sessions as they changed too significantly
-	for _, g := range bgp.Groups {
-		for _, n := range g.Neighbors {
-			newCfg := BGPPeerConfig(n, vrfReg.GetVRFByRD(0))
-			oldCfg := bgpSrv.GetPeerConfig(n.PeerAddressIP)
-			if oldCfg == nil {
-				continue
-			}
+	return labelmanager.New(cfg)
+}
 
-			if !oldCfg.NeedsRestart(newCfg) {
-				bgpSrv.ReplaceImportFilterChain(n.PeerAddressIP, newCfg.IPv4.ImportFilterChain)
-				bgpSrv.ReplaceExportFilterChain(n.PeerAddressIP, newCfg.IPv4.ExportFilterChain)
-				continue
-			}
+func logMigrationReport(cfg *config.Config) {
+	for _, r := range cfg.MigrationReport() {
+		log.Warningf("Config migrated to schema version %d: %s", config.SchemaVersion, r)
+	}
+}
 
-			bgpSrv.DisposePeer(oldCfg.PeerAddress)
-		}
+func loadConfig(cfg *config.Config) error {
+	err := instances.get(defaultInstanceName).loadConfig(cfg.RoutingOptions, cfg.RoutingInstances, cfg.Protocols)
+	if err != nil {
+		return err
 	}
 
-	// Turn up all sessions that are missing
-	for _, g := range bgp.Groups {
-		for _, n := range g.Neighbors {
-			if bgpSrv.GetPeerConfig(n.PeerAddressIP) != nil {
-				continue
-			}
+	err = loadInstances(cfg)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load instances")
+	}
 
-			newCfg := BGPPeerConfig(n, vrfReg.GetVRFByRD(0))
-			err := bgpSrv.AddPeer(*newCfg)
-			if err != nil {
-				return errors.Wrap(err, "Unable to add BGP peer")
-			}
-		}
+	err = loadTelemetry(cfg.Telemetry)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load telemetry")
 	}
 
 	return nil
 }
 
-// BGPPeerConfig converts a BGPNeighbor config into a PeerConfig
-func BGPPeerConfig(n *config.BGPNeighbor, vrf *vrf.VRF) *bgpserver.PeerConfig {
-	r := &bgpserver.PeerConfig{
-		AuthenticationKey: n.AuthenticationKey,
-		LocalAS:           n.LocalAS,
-		PeerAS:            n.PeerAS,
-		PeerAddress:       n.PeerAddressIP,
-		LocalAddress:      n.LocalAddressIP,
-		TTL:               n.TTL,
-		ReconnectInterval: time.Second * 15,
-		HoldTime:          n.HoldTimeDuration,
-		KeepAlive:         n.HoldTimeDuration / 3,
-		RouterID:          bgpSrv.RouterID(),
-		IPv4: &bgpserver.AddressFamilyConfig{
-			ImportFilterChain: n.ImportFilterChain,
-			ExportFilterChain: n.ExportFilterChain,
-			AddPathSend: routingtable.ClientOptions{
-				MaxPaths: 10,
-			},
-		},
-		VRF: vrf,
-	}
+// setRunningBGPDisabled records a runtime change of the BGP state of an instance (the default instance if empty)
+// in the running config
+func setRunningBGPDisabled(instance string, disabled bool) error {
+	runCfgMu.Lock()
+	defer runCfgMu.Unlock()
 
-	if n.Passive != nil {
-		r.Passive = *n.Passive
+	if runCfg == nil {
+		return fmt.Errorf("no running config")
 	}
 
-	if n.RouteServerClient != nil {
-		r.RouteServerClient = *n.RouteServerClient
+	if instance == defaultInstanceName {
+		instance = ""
 	}
 
-	return r
+	return runCfg.SetBGPDisabled(instance, disabled)
 }
 
-func configureRoutingInstance(ri *config.RoutingInstance) error {
-	vrf := vrfReg.GetVRFByName(ri.Name)
+// saveRunningConfig atomically persists the running config to the saved config file
+func saveRunningConfig() error {
+	runCfgMu.RLock()
+	defer runCfgMu.RUnlock()
 
-	// RD Change
-	if vrf.RD() != ri.InternalRouteDistinguisher {
-		// TODO: Drop all routing adjacencies
-		vrf.Dispose()
-		vrfReg.UnregisterVRF(vrf)
+	if runCfg == nil {
+		return fmt.Errorf("no running config")
+	}
 
-		vrf = vrfReg.CreateVRFIfNotExists(ri.Name, ri.InternalRouteDistinguisher)
-		// TODO: Add all routing adjacencies
+	err := runCfg.Save(*savedConfigFilePath)
+	if err != nil {
+		return errors.Wrap(err, "Unable to save config")
 	}
 
+	log.Infof("Running config saved to %q", *savedConfigFilePath)
 	return nil
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bio-routing/bio-rd/cmd/bio-rd/api"
+	bgpapi "github.com/bio-routing/bio-rd/protocols/bgp/api"
+	"github.com/bio-routing/bio-rd/util/servicewrapper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestSetMethodRoles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "authz.yml")
+	err = ioutil.WriteFile(path, []byte("anonymous: read\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := servicewrapper.LoadRoleAuthorizer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setMethodRoles(a)
+
+	s := grpc.NewServer()
+	bgpapi.RegisterBgpServiceServer(s, newBGPAPIRouter(newInstanceRegistry()))
+	api.RegisterManagementServiceServer(s, &managementAPIServer{})
+
+	methods := make(map[string]struct{})
+	for name, info := range s.GetServiceInfo() {
+		for _, m := range info.Methods {
+			methods["/"+name+"/"+m.Name] = struct{}{}
+		}
+	}
+
+	for _, m := range readMethods {
+		_, ok := methods[m]
+		assert.True(t, ok, "Read method %q does not exist", m)
+	}
+
+	tests := []struct {
+		method  string
+		allowed bool
+	}{
+		{
+			method:  "/bio.bgp.BgpService/DumpRIBIn",
+			allowed: true,
+		},
+		{
+			method:  "/bio.management.ManagementService/GetEvents",
+			allowed: true,
+		},
+		{
+			method: "/bio.management.ManagementService/SaveConfig",
+		},
+		{
+			method: "/bio.management.ManagementService/ShutdownBGPPeer",
+		},
+		{
+			method: "/bio.management.ManagementService/StartBGPPeer",
+		},
+		{
+			method: "/bio.management.ManagementService/ResetBGPPeer",
+		},
+		{
+			method: "/bio.management.ManagementService/ReplayMRT",
+		},
+	}
+
+	for _, test := range tests {
+		_, ok := methods[test.method]
+		assert.True(t, ok, "Test %q: method does not exist", test.method)
+
+		err := a.Authorize(context.Background(), test.method)
+		assert.Equal(t, test.allowed, err == nil, "Test %q", test.method)
+	}
+}
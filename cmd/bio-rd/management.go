@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bio-routing/bio-rd/cmd/bio-rd/api"
+	bioconfig "github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/util/logging"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type managementAPIServer struct{}
+
+// SaveConfig persists the running config
+func (m *managementAPIServer) SaveConfig(ctx context.Context, in *api.SaveConfigRequest) (*api.SaveConfigResponse, error) {
+	err := saveRunningConfig()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &api.SaveConfigResponse{
+		Path: *savedConfigFilePath,
+	}, nil
+}
+
+// SetProtocolState enables or disables a protocol at runtime. The state is recorded in the running config,
+// so SaveConfig persists it. A config reload applies the state of the reloaded file.
+func (m *managementAPIServer) SetProtocolState(ctx context.Context, in *api.SetProtocolStateRequest) (*api.SetProtocolStateResponse, error) {
+	name := in.Instance
+	if name == "" {
+		name = defaultInstanceName
+	}
+
+	ri := instances.get(name)
+	if ri == nil {
+		return nil, status.Errorf(codes.NotFound, "instance %q not found", name)
+	}
+
+	switch in.Protocol {
+	case "bgp":
+		err := ri.setBGPEnabled(in.Enabled)
+		if err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		err = setRunningBGPDisabled(in.Instance, !in.Enabled)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Unable to record BGP state in running config: %v", err)
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "protocol %q is not supported", in.Protocol)
+	}
+
+	return &api.SetProtocolStateResponse{}, nil
+}
+
+// GetLogLevels gets the log levels of all subsystems
+func (m *managementAPIServer) GetLogLevels(ctx context.Context, in *api.GetLogLevelsRequest) (*api.GetLogLevelsResponse, error) {
+	return &api.GetLogLevelsResponse{
+		Levels: logging.Levels(),
+	}, nil
+}
+
+// SetLogLevel sets the log level of a subsystem
+func (m *managementAPIServer) SetLogLevel(ctx context.Context, in *api.SetLogLevelRequest) (*api.SetLogLevelResponse, error) {
+	err := logging.SetLevel(in.Subsystem, in.Level)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	return &api.SetLogLevelResponse{}, nil
+}
+
+// instanceBGPServer gets the BGP server of an instance (the default instance if name is empty)
+func instanceBGPServer(name string) (bgpserver.BGPServer, error) {
+	if name == "" {
+		name = defaultInstanceName
+	}
+
+	ri := instances.get(name)
+	if ri == nil {
+		return nil, status.Errorf(codes.NotFound, "instance %q not found", name)
+	}
+
+	bgpSrv, _ := ri.bgp()
+	if bgpSrv == nil {
+		return nil, status.Errorf(codes.Unavailable, "BGP is disabled in instance %q", name)
+	}
+
+	return bgpSrv, nil
+}
+
+// CaptureBGP streams all BGP messages exchanged with a peer (all peers if not set) in pcap format
+func (m *managementAPIServer) CaptureBGP(in *api.CaptureBGPRequest, stream api.ManagementService_CaptureBGPServer) error {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return err
+	}
+
+	var peer *bnet.IP
+	if in.Peer != nil {
+		peer = bnet.IPFromProtoIP(in.Peer).Dedup()
+	}
+
+	w := newCaptureStreamWriter()
+	id, err := bgpSrv.StartCapture(peer, w)
+	if err != nil {
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	defer bgpSrv.StopCapture(id)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case data := <-w.ch:
+			err := stream.Send(&api.CaptureData{
+				Pcap: data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// captureStreamWriter decouples capturing from streaming to the client.
+// Data is dropped if the client is not able to keep up.
+type captureStreamWriter struct {
+	ch chan []byte
+}
+
+func newCaptureStreamWriter() *captureStreamWriter {
+	return &captureStreamWriter{
+		ch: make(chan []byte, 1024),
+	}
+}
+
+func (w *captureStreamWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	select {
+	case w.ch <- data:
+	default:
+		log.Warning("Packet capture client too slow. Dropping data.")
+	}
+
+	return len(p), nil
+}
+
+// GetEvents gets all recorded events of a subsystem (all subsystems if not set)
+func (m *managementAPIServer) GetEvents(ctx context.Context, in *api.GetEventsRequest) (*api.GetEventsResponse, error) {
+	events := eventLog.Events(in.Subsystem)
+	res := &api.GetEventsResponse{
+		Events: make([]*api.Event, 0, len(events)),
+	}
+
+	for _, e := range events {
+		res.Events = append(res.Events, &api.Event{
+			Timestamp: e.Timestamp.Unix(),
+			Subsystem: e.Subsystem,
+			Object:    e.Object,
+			Message:   e.Message,
+			Reason:    e.Reason,
+		})
+	}
+
+	return res, nil
+}
+
+// GetFlightRecorder gets the recorded state transitions of all state machines matching protocol and object (all if not set)
+func (m *managementAPIServer) GetFlightRecorder(ctx context.Context, in *api.GetFlightRecorderRequest) (*api.GetFlightRecorderResponse, error) {
+	recs := flightRecorder.Recorders(in.Protocol, in.Object)
+	res := &api.GetFlightRecorderResponse{
+		Records: make([]*api.FlightRecord, 0, len(recs)),
+	}
+
+	for _, rec := range recs {
+		r := &api.FlightRecord{
+			Protocol: rec.Protocol(),
+			Scope:    rec.Scope(),
+			Object:   rec.Object(),
+		}
+
+		for _, t := range rec.Transitions() {
+			r.Transitions = append(r.Transitions, &api.Transition{
+				TimestampNs: t.Timestamp.UnixNano(),
+				From:        t.From,
+				To:          t.To,
+				Reason:      t.Reason,
+			})
+		}
+
+		res.Records = append(res.Records, r)
+	}
+
+	return res, nil
+}
+
+// SetBGPPeerDebug enables or disables logging of decoded messages received from a BGP peer
+func (m *managementAPIServer) SetBGPPeerDebug(ctx context.Context, in *api.SetBGPPeerDebugRequest) (*api.SetBGPPeerDebugResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Peer == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "peer not set")
+	}
+
+	err = bgpSrv.SetPeerDebug(bnet.IPFromProtoIP(in.Peer).Dedup(), in.Enabled)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	return &api.SetBGPPeerDebugResponse{}, nil
+}
+
+// ResetBGPCounters resets the counters of a BGP peer (all peers if not set) without restarting sessions
+func (m *managementAPIServer) ResetBGPCounters(ctx context.Context, in *api.ResetBGPCountersRequest) (*api.ResetBGPCountersResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var peer *bnet.IP
+	object := "all peers"
+	if in.Peer != nil {
+		peer = bnet.IPFromProtoIP(in.Peer).Dedup()
+		object = peer.String()
+	}
+
+	err = bgpSrv.ResetCounters(peer)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	eventLog.Record("bgp", object, "counters reset", "")
+	return &api.ResetBGPCountersResponse{}, nil
+}
+
+// GetLabelAllocations gets the label ranges and all allocated labels (of an owner if set)
+func (m *managementAPIServer) GetLabelAllocations(ctx context.Context, in *api.GetLabelAllocationsRequest) (*api.GetLabelAllocationsResponse, error) {
+	res := &api.GetLabelAllocationsResponse{
+		DynamicLabels: labelRangeToProto(labelManager.DynamicLabels()),
+		Srgb:          labelRangeToProto(labelManager.SRGB()),
+		Srlb:          labelRangeToProto(labelManager.SRLB()),
+	}
+
+	for _, a := range labelManager.Allocations() {
+		if in.Owner != "" && a.Owner != in.Owner {
+			continue
+		}
+
+		res.Allocations = append(res.Allocations, &api.LabelAllocation{
+			Label:       a.Label,
+			Block:       a.Block.String(),
+			Owner:       a.Owner,
+			Description: a.Description,
+		})
+	}
+
+	return res, nil
+}
+
+func labelRangeToProto(r bioconfig.LabelRange) *api.LabelRange {
+	return &api.LabelRange{
+		Start: r.Start,
+		End:   r.End,
+	}
+}
+
+// SoftResetBGPPeer refreshes the routes exchanged with a BGP peer without restarting the session
+func (m *managementAPIServer) SoftResetBGPPeer(ctx context.Context, in *api.SoftResetBGPPeerRequest) (*api.SoftResetBGPPeerResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Peer == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "peer not set")
+	}
+
+	peer := bnet.IPFromProtoIP(in.Peer).Dedup()
+	err = bgpSrv.SoftReset(peer, in.Inbound, in.Outbound)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	eventLog.Record("bgp", peer.String(), "soft reset", fmt.Sprintf("inbound: %t, outbound: %t", in.Inbound, in.Outbound))
+	return &api.SoftResetBGPPeerResponse{}, nil
+}
+
+// SetBGPPeerMaintenance enables or disables the graceful shutdown maintenance mode of a BGP peer (RFC8326)
+func (m *managementAPIServer) SetBGPPeerMaintenance(ctx context.Context, in *api.SetBGPPeerMaintenanceRequest) (*api.SetBGPPeerMaintenanceResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Peer == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "peer not set")
+	}
+
+	peer := bnet.IPFromProtoIP(in.Peer).Dedup()
+	err = bgpSrv.SetGracefulShutdown(peer, in.Enabled)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	eventLog.Record("bgp", peer.String(), "maintenance mode", fmt.Sprintf("enabled: %t", in.Enabled))
+	return &api.SetBGPPeerMaintenanceResponse{}, nil
+}
+
+// ShutdownBGPPeer drains a BGP peer (all peers if not set) and shuts it down administratively after the drain time
+func (m *managementAPIServer) ShutdownBGPPeer(ctx context.Context, in *api.ShutdownBGPPeerRequest) (*api.ShutdownBGPPeerResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var peer *bnet.IP
+	object := "all peers"
+	if in.Peer != nil {
+		peer = bnet.IPFromProtoIP(in.Peer).Dedup()
+		object = peer.String()
+	}
+
+	drainTime := time.Duration(in.DrainTimeSeconds) * time.Second
+	err = bgpSrv.AdminShutdown(peer, drainTime, in.Message)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	eventLog.Record("bgp", object, "admin shutdown", fmt.Sprintf("drain time: %s, message: %q", drainTime, in.Message))
+	return &api.ShutdownBGPPeerResponse{}, nil
+}
+
+// ResetBGPPeer resets the session of a BGP peer (all peers if not set) with an Administrative Reset NOTIFICATION
+func (m *managementAPIServer) ResetBGPPeer(ctx context.Context, in *api.ResetBGPPeerRequest) (*api.ResetBGPPeerResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var peer *bnet.IP
+	object := "all peers"
+	if in.Peer != nil {
+		peer = bnet.IPFromProtoIP(in.Peer).Dedup()
+		object = peer.String()
+	}
+
+	err = bgpSrv.AdminReset(peer, in.Message)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	eventLog.Record("bgp", object, "admin reset", fmt.Sprintf("message: %q", in.Message))
+	return &api.ResetBGPPeerResponse{}, nil
+}
+
+// StartBGPPeer starts a BGP peer (all peers if not set) shut down administratively again
+func (m *managementAPIServer) StartBGPPeer(ctx context.Context, in *api.StartBGPPeerRequest) (*api.StartBGPPeerResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var peer *bnet.IP
+	object := "all peers"
+	if in.Peer != nil {
+		peer = bnet.IPFromProtoIP(in.Peer).Dedup()
+		object = peer.String()
+	}
+
+	err = bgpSrv.AdminStart(peer)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	eventLog.Record("bgp", object, "admin start", "")
+	return &api.StartBGPPeerResponse{}, nil
+}
+
+// ReplayMRT injects the routes of an MRT file into the adj-RIB-in of an established BGP peer
+func (m *managementAPIServer) ReplayMRT(ctx context.Context, in *api.ReplayMRTRequest) (*api.ReplayMRTResponse, error) {
+	bgpSrv, err := instanceBGPServer(in.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Peer == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "peer not set")
+	}
+
+	opt := bgpserver.MRTReplayOptions{
+		Peer:  bnet.IPFromProtoIP(in.Peer).Dedup(),
+		Speed: in.Speed,
+	}
+	if in.SourcePeer != nil {
+		opt.SourcePeer = bnet.IPFromProtoIP(in.SourcePeer).Dedup()
+	}
+
+	if *mrtReplayDir == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "MRT replay is disabled (-mrt.replay_dir not set)")
+	}
+
+	path, err := mrtReplayPath(*mrtReplayDir, in.Path)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	// The OS error is not returned to not disclose anything about the file system to the caller
+	f, err := os.Open(path)
+	if err != nil {
+		log.WithError(err).Warningf("Unable to open MRT file %q", path)
+		return nil, status.Errorf(codes.NotFound, "MRT file %q not found", in.Path)
+	}
+	defer f.Close()
+
+	n, err := bgpSrv.ReplayMRT(ctx, bufio.NewReader(f), opt)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "replay aborted after %d updates: %v", n, err)
+	}
+
+	eventLog.Record("bgp", opt.Peer.String(), "mrt replay", fmt.Sprintf("%s: %d updates", in.Path, n))
+	return &api.ReplayMRTResponse{
+		Updates: n,
+	}, nil
+}
+
+// mrtReplayPath gets the path of the MRT file name in dir. Names referring to files outside of dir are rejected.
+func mrtReplayPath(dir string, name string) (string, error) {
+	if name == "" || filepath.IsAbs(name) {
+		return "", fmt.Errorf("MRT file must be given relative to the replay directory")
+	}
+
+	rel := filepath.Clean(name)
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("MRT file must be in the replay directory")
+	}
+
+	return filepath.Join(dir, rel), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMRTReplayPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected string
+		wantFail bool
+	}{
+		{
+			name:     "File in directory",
+			file:     "updates.20200101.0000.bz2",
+			expected: filepath.Join("/var/lib/mrt", "updates.20200101.0000.bz2"),
+		},
+		{
+			name:     "File in sub directory",
+			file:     "rrc00/./updates.mrt",
+			expected: filepath.Join("/var/lib/mrt", "rrc00", "updates.mrt"),
+		},
+		{
+			name:     "Absolute path",
+			file:     "/etc/passwd",
+			wantFail: true,
+		},
+		{
+			name:     "Parent directory",
+			file:     "../../etc/passwd",
+			wantFail: true,
+		},
+		{
+			name:     "Parent directory via sub directory",
+			file:     "rrc00/../../secret",
+			wantFail: true,
+		},
+		{
+			name:     "Empty",
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := mrtReplayPath("/var/lib/mrt", test.file)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, p, "Test %q", test.name)
+	}
+}
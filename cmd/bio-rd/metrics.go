@@ -0,0 +1,61 @@
+package main
+
+import (
+	prom_bgp "github.com/bio-routing/bio-rd/metrics/bgp/adapter/prom"
+	prom_kernel "github.com/bio-routing/bio-rd/metrics/kernel/adapter/prom"
+	prom_vrf "github.com/bio-routing/bio-rd/metrics/vrf/adapter/prom"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	instanceLabel = "routing_instance"
+)
+
+// instanceCollector collects the metrics of all subsystems of a routing instance.
+// As subsystems can be enabled and disabled at runtime it resolves them on every scrape.
+type instanceCollector struct {
+	ri *routingInstance
+}
+
+// registerInstanceMetrics registers the collector of a routing instance with the default registry.
+// All metrics are labeled with the instance name to keep them apart.
+func registerInstanceMetrics(ri *routingInstance) (prometheus.Collector, error) {
+	c := &instanceCollector{
+		ri: ri,
+	}
+
+	reg := prometheus.WrapRegistererWith(prometheus.Labels{instanceLabel: ri.name}, prometheus.DefaultRegisterer)
+	err := reg.Register(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// unregisterInstanceMetrics removes the collector of a routing instance from the default registry
+func unregisterInstanceMetrics(ri *routingInstance, c prometheus.Collector) {
+	reg := prometheus.WrapRegistererWith(prometheus.Labels{instanceLabel: ri.name}, prometheus.DefaultRegisterer)
+	reg.Unregister(c)
+}
+
+// Describe conforms to the prometheus collector interface
+func (c *instanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	prom_bgp.NewCollector(nil).Describe(ch)
+	prom_kernel.NewCollector(nil).Describe(ch)
+	prom_vrf.NewCollector(c.ri.vrfReg).Describe(ch)
+}
+
+// Collect conforms to the prometheus collector interface
+func (c *instanceCollector) Collect(ch chan<- prometheus.Metric) {
+	bgpSrv, _ := c.ri.bgp()
+	if bgpSrv != nil {
+		prom_bgp.NewCollector(bgpSrv).Collect(ch)
+	}
+
+	prom_vrf.NewCollector(c.ri.vrfReg).Collect(ch)
+
+	if c.ri.kernel != nil {
+		prom_kernel.NewCollector(c.ri.kernel).Collect(ch)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	bioconfig "github.com/bio-routing/bio-rd/config"
+	"github.com/bio-routing/bio-rd/protocols/rpki/client"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// rovVRPs holds the latest VRPs used for origin validation. It is nil as long as origin validation is disabled.
+var rovVRPs struct {
+	mu   sync.RWMutex
+	vrps []vrp.VRP
+}
+
+// startOriginValidation starts fetching VRPs BGP routes are validated against if enabled.
+// VRPs are either fetched from an RTR cache or taken from the local assertions of a SLURM file.
+func startOriginValidation() error {
+	if *rovRTRServer != "" {
+		c := client.New(&bioconfig.RTRClientConfig{
+			Address: *rovRTRServer,
+		}, func(vrps []vrp.VRP) {
+			vrps, err := applyROVSLURM(vrps)
+			if err != nil {
+				log.Errorf("Unable to apply SLURM file for origin validation: %v", err)
+				return
+			}
+
+			setROVVRPs(vrps)
+		})
+		c.Start()
+		return nil
+	}
+
+	if *rovSLURMFile == "" {
+		return nil
+	}
+
+	err := reloadROVSLURM()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for range time.Tick(*rovReloadInterval) {
+			err := reloadROVSLURM()
+			if err != nil {
+				log.Errorf("Unable to reload SLURM file for origin validation: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func reloadROVSLURM() error {
+	vrps, err := applyROVSLURM(nil)
+	if err != nil {
+		return err
+	}
+
+	setROVVRPs(vrps)
+	return nil
+}
+
+// applyROVSLURM applies the origin validation SLURM file (if set)
+func applyROVSLURM(vrps []vrp.VRP) ([]vrp.VRP, error) {
+	if *rovSLURMFile == "" {
+		return vrps, nil
+	}
+
+	f, err := os.Open(*rovSLURMFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open SLURM file")
+	}
+	defer f.Close()
+
+	s, err := vrp.LoadSLURM(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to load %q", *rovSLURMFile)
+	}
+
+	return s.Apply(vrps), nil
+}
+
+// setROVVRPs stores VRPs and hands them to the BGP servers of all instances
+func setROVVRPs(vrps []vrp.VRP) {
+	if vrps == nil {
+		vrps = []vrp.VRP{}
+	}
+
+	rovVRPs.mu.Lock()
+	rovVRPs.vrps = vrps
+	rovVRPs.mu.Unlock()
+
+	for _, ri := range instances.list() {
+		srv, _ := ri.bgp()
+		if srv != nil {
+			srv.SetVRPs(vrps)
+		}
+	}
+}
+
+// getROVVRPs returns the latest VRPs. Returns nil if origin validation is disabled or no VRPs have been received yet.
+func getROVVRPs() []vrp.VRP {
+	rovVRPs.mu.RLock()
+	defer rovVRPs.mu.RUnlock()
+
+	return rovVRPs.vrps
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	bioconfig "github.com/bio-routing/bio-rd/config"
+	"github.com/bio-routing/bio-rd/protocols/rpki/cache"
+	"github.com/bio-routing/bio-rd/protocols/rpki/client"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// startRTRCache starts serving VRPs to routers if enabled. VRPs are either loaded from a file or fetched from an upstream cache.
+func startRTRCache() error {
+	if *rtrListen == "" {
+		return nil
+	}
+
+	if (*rtrVRPFile == "") == (*rtrUpstream == "") {
+		return fmt.Errorf("Exactly one of -rtr.vrp_file and -rtr.upstream must be set")
+	}
+
+	srv := cache.New(&bioconfig.RTRCacheConfig{
+		ListenAddress: *rtrListen,
+	})
+
+	if *rtrUpstream != "" {
+		c := client.New(&bioconfig.RTRClientConfig{
+			Address: *rtrUpstream,
+		}, func(vrps []vrp.VRP) {
+			vrps, err := applySLURM(vrps)
+			if err != nil {
+				log.Errorf("Unable to apply SLURM file: %v", err)
+				return
+			}
+
+			srv.SetVRPs(vrps)
+		})
+		c.Start()
+	} else {
+		err := reloadVRPFile(srv)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			for range time.Tick(*rtrReloadInterval) {
+				err := reloadVRPFile(srv)
+				if err != nil {
+					log.Errorf("Unable to reload VRPs: %v", err)
+				}
+			}
+		}()
+	}
+
+	return srv.Start()
+}
+
+func reloadVRPFile(srv *cache.Server) error {
+	f, err := os.Open(*rtrVRPFile)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open VRP file")
+	}
+	defer f.Close()
+
+	vrps, err := vrp.LoadJSON(f)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to load %q", *rtrVRPFile)
+	}
+
+	vrps, err = applySLURM(vrps)
+	if err != nil {
+		return err
+	}
+
+	srv.SetVRPs(vrps)
+	return nil
+}
+
+// applySLURM applies the SLURM file (if set). The file is read every time so changes take effect with the next update.
+func applySLURM(vrps []vrp.VRP) ([]vrp.VRP, error) {
+	if *rtrSLURMFile == "" {
+		return vrps, nil
+	}
+
+	f, err := os.Open(*rtrSLURMFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open SLURM file")
+	}
+	defer f.Close()
+
+	s, err := vrp.LoadSLURM(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to load %q", *rtrSLURMFile)
+	}
+
+	return s.Apply(vrps), nil
+}
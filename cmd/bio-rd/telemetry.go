@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/cmd/bio-rd/config"
+	"github.com/bio-routing/bio-rd/util/servicewrapper"
+	"github.com/bio-routing/bio-rd/util/telemetry"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+var (
+	telemetryMu        sync.Mutex
+	telemetryPublisher *telemetry.Publisher
+	telemetryCfg       *config.Telemetry
+)
+
+// loadTelemetry (re)starts the telemetry publisher if the telemetry config changed
+func loadTelemetry(cfg *config.Telemetry) error {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+
+	if reflect.DeepEqual(cfg, telemetryCfg) {
+		return nil
+	}
+
+	collectors, err := telemetryCollectors(cfg)
+	if err != nil {
+		return err
+	}
+
+	if telemetryPublisher != nil {
+		telemetryPublisher.Stop()
+		telemetryPublisher = nil
+	}
+	telemetryCfg = cfg
+
+	if len(collectors) == 0 {
+		return nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return errors.Wrap(err, "Unable to get hostname")
+	}
+
+	p := telemetry.NewPublisher(hostname)
+	p.AddSource("bgp/peers", dumpPeerStates)
+	p.AddSource("rib/summary", dumpRIBSummaries)
+	p.AddSource("flight_recorder", dumpFlightRecorder)
+	p.Start(collectors)
+	telemetryPublisher = p
+
+	return nil
+}
+
+func telemetryCollectors(cfg *config.Telemetry) ([]telemetry.Collector, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	ret := make([]telemetry.Collector, 0, len(cfg.Collectors))
+	for _, c := range cfg.Collectors {
+		tlsOpt, err := servicewrapper.ClientTLS(c.TLSCA, c.TLSCert, c.TLSKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to configure TLS for telemetry collector %q", c.Address)
+		}
+
+		col := telemetry.Collector{
+			Address:     c.Address,
+			DialOptions: []grpc.DialOption{tlsOpt},
+		}
+
+		for _, s := range c.Subscriptions {
+			col.Subscriptions = append(col.Subscriptions, telemetry.Subscription{
+				Path:           s.Path,
+				SampleInterval: s.SampleIntervalDuration,
+				OnChange:       s.OnChange,
+			})
+		}
+
+		ret = append(ret, col)
+	}
+
+	return ret, nil
+}
@@ -6,25 +6,41 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	mgmtapi "github.com/bio-routing/bio-rd/cmd/bio-rd/api"
 	bnet "github.com/bio-routing/bio-rd/net"
 	bgpapi "github.com/bio-routing/bio-rd/protocols/bgp/api"
 	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/util/servicewrapper"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 var (
 	bioAddr      = flag.String("bio-rd", "localhost:5566", "bio-rd grpc endpoint")
 	cmd          = flag.String("cmd", "", "command to execute")
+	tlsCA        = flag.String("tls_ca", "", "CA file to verify the bio-rd certificate against (enables TLS)")
+	tlsCert      = flag.String("tls_cert", "", "Client certificate file")
+	tlsKey       = flag.String("tls_key", "", "Client key file")
+	instance     = flag.String("instance", "", "routing instance to query (default instance if empty)")
 	bgpAPIClient bgpapi.BgpServiceClient
+	mgmtClient   mgmtapi.ManagementServiceClient
 )
 
 func main() {
 	flag.Parse()
 
-	conn, err := grpc.Dial(*bioAddr, grpc.WithInsecure())
+	transport, err := servicewrapper.ClientTLS(*tlsCA, *tlsCert, *tlsKey)
+	if err != nil {
+		log.Errorf("Unable to configure TLS: %v", err)
+		os.Exit(1)
+	}
+
+	conn, err := grpc.Dial(*bioAddr, transport)
 	if err != nil {
 		log.Errorf("GRPC dial failed: %v", err)
 		os.Exit(1)
@@ -32,6 +48,7 @@ func main() {
 	defer conn.Close()
 
 	bgpAPIClient = bgpapi.NewBgpServiceClient(conn)
+	mgmtClient = mgmtapi.NewManagementServiceClient(conn)
 
 	cmdParts := strings.Split(*cmd, " ")
 	if len(cmdParts) == 0 {
@@ -45,9 +62,441 @@ func main() {
 		show(cmdParts[1:])
 	}
 
+	if cmdParts[0] == "save" {
+		saveConfig()
+	}
+
+	if cmdParts[0] == "capture" {
+		if len(cmdParts) < 4 || cmdParts[1] != "bgp" {
+			return
+		}
+		captureBGP(cmdParts[2], cmdParts[3])
+	}
+
+	if cmdParts[0] == "set" {
+		if len(cmdParts) < 4 || cmdParts[1] != "log-level" {
+			return
+		}
+		setLogLevel(cmdParts[2], cmdParts[3])
+	}
+
+	if cmdParts[0] == "clear" {
+		if len(cmdParts) < 3 || cmdParts[1] != "bgp" {
+			return
+		}
+
+		switch cmdParts[2] {
+		case "counters":
+			resetBGPCounters(cmdParts[3:])
+		case "soft":
+			softResetBGPPeer(cmdParts[3:])
+		}
+	}
+
+	if cmdParts[0] == "debug" {
+		if len(cmdParts) < 4 || cmdParts[1] != "bgp" {
+			return
+		}
+		setBGPPeerDebug(cmdParts[2], cmdParts[3] == "on")
+	}
+
+	if cmdParts[0] == "maintenance" {
+		if len(cmdParts) < 4 || cmdParts[1] != "bgp" {
+			return
+		}
+		setBGPPeerMaintenance(cmdParts[2], cmdParts[3] == "on")
+	}
+
+	if cmdParts[0] == "shutdown" {
+		if len(cmdParts) < 3 || cmdParts[1] != "bgp" {
+			return
+		}
+		shutdownBGPPeer(cmdParts[2], cmdParts[3:])
+	}
+
+	if cmdParts[0] == "reset" {
+		if len(cmdParts) < 3 || cmdParts[1] != "bgp" {
+			return
+		}
+		resetBGPPeer(cmdParts[2], strings.Join(cmdParts[3:], " "))
+	}
+
+	if cmdParts[0] == "start" {
+		if len(cmdParts) < 3 || cmdParts[1] != "bgp" {
+			return
+		}
+		startBGPPeer(cmdParts[2])
+	}
+
+	if cmdParts[0] == "enable" || cmdParts[0] == "disable" {
+		if len(cmdParts) == 1 {
+			return
+		}
+		setProtocolState(cmdParts[1], cmdParts[0] == "enable")
+	}
+
+}
+
+func saveConfig() {
+	res, err := mgmtClient.SaveConfig(context.Background(), &mgmtapi.SaveConfigRequest{})
+	if err != nil {
+		log.Errorf("Unable to save config: %v", err)
+		return
+	}
+
+	fmt.Printf("Running config saved to %s\n", res.Path)
+}
+
+func setProtocolState(protocol string, enabled bool) {
+	_, err := mgmtClient.SetProtocolState(context.Background(), &mgmtapi.SetProtocolStateRequest{
+		Instance: *instance,
+		Protocol: protocol,
+		Enabled:  enabled,
+	})
+	if err != nil {
+		log.Errorf("Unable to set protocol state: %v", err)
+		return
+	}
+}
+
+// resetBGPCounters resets the counters of the given BGP peer (all peers if not given)
+func resetBGPCounters(parts []string) {
+	req := &mgmtapi.ResetBGPCountersRequest{
+		Instance: *instance,
+	}
+
+	if len(parts) > 0 {
+		addr, err := bnet.IPFromString(parts[0])
+		if err != nil {
+			log.Errorf("Unable to convert peer address: %v", err)
+			return
+		}
+
+		req.Peer = addr.ToProto()
+	}
+
+	_, err := mgmtClient.ResetBGPCounters(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to reset counters: %v", err)
+		return
+	}
+}
+
+// softResetBGPPeer refreshes the routes exchanged with a BGP peer in the given direction (in, out or both if not given)
+func softResetBGPPeer(parts []string) {
+	if len(parts) == 0 {
+		log.Errorf("Peer address missing")
+		return
+	}
+
+	addr, err := bnet.IPFromString(parts[0])
+	if err != nil {
+		log.Errorf("Unable to convert peer address: %v", err)
+		return
+	}
+
+	req := &mgmtapi.SoftResetBGPPeerRequest{
+		Instance: *instance,
+		Peer:     addr.ToProto(),
+		Inbound:  true,
+		Outbound: true,
+	}
+
+	if len(parts) > 1 {
+		req.Inbound = parts[1] == "in"
+		req.Outbound = parts[1] == "out"
+	}
+
+	_, err = mgmtClient.SoftResetBGPPeer(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to soft reset peer: %v", err)
+		return
+	}
+}
+
+// setBGPPeerDebug enables or disables logging of decoded messages received from a BGP peer
+func setBGPPeerDebug(peer string, enabled bool) {
+	addr, err := bnet.IPFromString(peer)
+	if err != nil {
+		log.Errorf("Unable to convert peer address: %v", err)
+		return
+	}
+
+	_, err = mgmtClient.SetBGPPeerDebug(context.Background(), &mgmtapi.SetBGPPeerDebugRequest{
+		Instance: *instance,
+		Peer:     addr.ToProto(),
+		Enabled:  enabled,
+	})
+	if err != nil {
+		log.Errorf("Unable to set debug mode: %v", err)
+		return
+	}
+}
+
+// setBGPPeerMaintenance drains traffic from a BGP peer before it is shut down or restores it
+func setBGPPeerMaintenance(peer string, enabled bool) {
+	addr, err := bnet.IPFromString(peer)
+	if err != nil {
+		log.Errorf("Unable to convert peer address: %v", err)
+		return
+	}
+
+	_, err = mgmtClient.SetBGPPeerMaintenance(context.Background(), &mgmtapi.SetBGPPeerMaintenanceRequest{
+		Instance: *instance,
+		Peer:     addr.ToProto(),
+		Enabled:  enabled,
+	})
+	if err != nil {
+		log.Errorf("Unable to set maintenance mode: %v", err)
+		return
+	}
+}
+
+// shutdownBGPPeer drains a BGP peer ("all" for all peers) for the given number of seconds (none if not given) and shuts it down.
+// Any further words are sent as shutdown communication.
+func shutdownBGPPeer(peer string, parts []string) {
+	req := &mgmtapi.ShutdownBGPPeerRequest{
+		Instance: *instance,
+	}
+
+	if peer != "all" {
+		addr, err := bnet.IPFromString(peer)
+		if err != nil {
+			log.Errorf("Unable to convert peer address: %v", err)
+			return
+		}
+
+		req.Peer = addr.ToProto()
+	}
+
+	if len(parts) > 0 {
+		drainTime, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			log.Errorf("Unable to parse drain time: %v", err)
+			return
+		}
+
+		req.DrainTimeSeconds = uint32(drainTime)
+		req.Message = strings.Join(parts[1:], " ")
+	}
+
+	_, err := mgmtClient.ShutdownBGPPeer(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to shut down peer: %v", err)
+		return
+	}
+}
+
+// resetBGPPeer resets the session of a BGP peer ("all" for all peers) sending message as shutdown communication
+func resetBGPPeer(peer string, message string) {
+	req := &mgmtapi.ResetBGPPeerRequest{
+		Instance: *instance,
+		Message:  message,
+	}
+
+	if peer != "all" {
+		addr, err := bnet.IPFromString(peer)
+		if err != nil {
+			log.Errorf("Unable to convert peer address: %v", err)
+			return
+		}
+
+		req.Peer = addr.ToProto()
+	}
+
+	_, err := mgmtClient.ResetBGPPeer(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to reset peer: %v", err)
+		return
+	}
+}
+
+// startBGPPeer starts a BGP peer ("all" for all peers) shut down administratively again
+func startBGPPeer(peer string) {
+	req := &mgmtapi.StartBGPPeerRequest{
+		Instance: *instance,
+	}
+
+	if peer != "all" {
+		addr, err := bnet.IPFromString(peer)
+		if err != nil {
+			log.Errorf("Unable to convert peer address: %v", err)
+			return
+		}
+
+		req.Peer = addr.ToProto()
+	}
+
+	_, err := mgmtClient.StartBGPPeer(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to start peer: %v", err)
+		return
+	}
+}
+
+// captureBGP writes all BGP messages exchanged with peer ("all" for all peers) to a pcap file until interrupted
+func captureBGP(peer string, file string) {
+	req := &mgmtapi.CaptureBGPRequest{
+		Instance: *instance,
+	}
+
+	if peer != "all" {
+		addr, err := bnet.IPFromString(peer)
+		if err != nil {
+			log.Errorf("Unable to convert peer address: %v", err)
+			return
+		}
+
+		req.Peer = addr.ToProto()
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		log.Errorf("Unable to create file: %v", err)
+		return
+	}
+	defer f.Close()
+
+	c, err := mgmtClient.CaptureBGP(context.Background(), req)
+	if err != nil {
+		log.Errorf("Failed to get streaming RPC client: %v", err)
+		return
+	}
+
+	for {
+		data, err := c.Recv()
+		if err == io.EOF {
+			return
+		}
+
+		if err != nil {
+			log.Errorf("Recv() failed: %v", err)
+			return
+		}
+
+		_, err = f.Write(data.Pcap)
+		if err != nil {
+			log.Errorf("Unable to write to file: %v", err)
+			return
+		}
+	}
+}
+
+func setLogLevel(subsystem string, level string) {
+	_, err := mgmtClient.SetLogLevel(context.Background(), &mgmtapi.SetLogLevelRequest{
+		Subsystem: subsystem,
+		Level:     level,
+	})
+	if err != nil {
+		log.Errorf("Unable to set log level: %v", err)
+		return
+	}
+}
+
+func showLogLevels() {
+	res, err := mgmtClient.GetLogLevels(context.Background(), &mgmtapi.GetLogLevelsRequest{})
+	if err != nil {
+		log.Errorf("Unable to get log levels: %v", err)
+		return
+	}
+
+	for subsystem, level := range res.Levels {
+		fmt.Printf("%s: %s\n", subsystem, level)
+	}
+}
+
+// showEvents prints the event log, optionally limited to a subsystem
+func showEvents(parts []string) {
+	req := &mgmtapi.GetEventsRequest{}
+	if len(parts) > 0 {
+		req.Subsystem = parts[0]
+	}
+
+	res, err := mgmtClient.GetEvents(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to get events: %v", err)
+		return
+	}
+
+	for _, e := range res.Events {
+		fmt.Printf("%s [%s] %s: %s", time.Unix(e.Timestamp, 0).Format(time.RFC3339), e.Subsystem, e.Object, e.Message)
+		if e.Reason != "" {
+			fmt.Printf(" (%s)", e.Reason)
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// showFlightRecorder prints the recorded state transitions, optionally limited to a protocol and object
+func showFlightRecorder(parts []string) {
+	req := &mgmtapi.GetFlightRecorderRequest{}
+	if len(parts) > 0 {
+		req.Protocol = parts[0]
+	}
+	if len(parts) > 1 {
+		req.Object = parts[1]
+	}
+
+	res, err := mgmtClient.GetFlightRecorder(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to get flight recorder: %v", err)
+		return
+	}
+
+	for _, r := range res.Records {
+		name := r.Object
+		if r.Scope != "" {
+			name = r.Scope + "/" + r.Object
+		}
+
+		fmt.Printf("%s %s:\n", r.Protocol, name)
+		for _, t := range r.Transitions {
+			fmt.Printf("  %s %s -> %s (%s)\n", time.Unix(0, t.TimestampNs).Format(time.RFC3339Nano), t.From, t.To, t.Reason)
+		}
+	}
+}
+
+// showLabels prints the label ranges and allocated labels, optionally limited to an owner
+func showLabels(parts []string) {
+	req := &mgmtapi.GetLabelAllocationsRequest{}
+	if len(parts) > 0 {
+		req.Owner = parts[0]
+	}
+
+	res, err := mgmtClient.GetLabelAllocations(context.Background(), req)
+	if err != nil {
+		log.Errorf("Unable to get label allocations: %v", err)
+		return
+	}
+
+	fmt.Printf("Dynamic: %d-%d, SRGB: %d-%d, SRLB: %d-%d\n", res.DynamicLabels.Start, res.DynamicLabels.End,
+		res.Srgb.Start, res.Srgb.End, res.Srlb.Start, res.Srlb.End)
+	for _, a := range res.Allocations {
+		fmt.Printf("%8d %-8s %-8s %s\n", a.Label, a.Block, a.Owner, a.Description)
+	}
 }
 
 func show(parts []string) {
+	if parts[0] == "log-levels" {
+		showLogLevels()
+		return
+	}
+
+	if parts[0] == "events" {
+		showEvents(parts[1:])
+		return
+	}
+
+	if parts[0] == "flight-recorder" {
+		showFlightRecorder(parts[1:])
+		return
+	}
+
+	if parts[0] == "labels" {
+		showLabels(parts[1:])
+		return
+	}
+
 	if parts[0] == "routes" {
 		if len(parts) == 1 {
 			return
@@ -84,7 +533,7 @@ func showRouteReceiveBGP(parts []string) {
 		return
 	}
 
-	c, err := bgpAPIClient.DumpRIBIn(context.Background(), &bgpapi.DumpRIBRequest{
+	c, err := bgpAPIClient.DumpRIBIn(requestContext(), &bgpapi.DumpRIBRequest{
 		Peer: peer.ToProto(),
 		Afi:  1,
 		Safi: 1,
@@ -111,3 +560,13 @@ func showRouteReceiveBGP(parts []string) {
 	}
 
 }
+
+// requestContext returns the context for API requests addressing the selected routing instance
+func requestContext() context.Context {
+	ctx := context.Background()
+	if *instance == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "instance", *instance)
+}
@@ -23,8 +23,15 @@ var (
 	grpcPort             = flag.Uint("grpc_port", 4321, "gRPC server port")
 	httpPort             = flag.Uint("http_port", 4320, "HTTP server port")
 	grpcKeepaliveMinTime = flag.Uint("grpc_keepalive_min_time", 1, "Minimum time (seconds) for a client to wait between GRPC keepalive pings")
+	grpcTLSCert          = flag.String("grpc_tls_cert", "", "GRPC server TLS certificate file (enables TLS)")
+	grpcTLSKey           = flag.String("grpc_tls_key", "", "GRPC server TLS key file")
+	grpcTLSClientCA      = flag.String("grpc_tls_client_ca", "", "CA file to verify GRPC client certificates against (enables mutual TLS)")
+	grpcAuthzFile        = flag.String("grpc_authz_file", "", "GRPC authorization config file (enables per method authorization)")
 	risTimeout           = flag.Uint("ris_timeout", 5, "RIS timeout in seconds")
 	configFilePath       = flag.String("config.file", "ris_mirror.yml", "Configuration file")
+	risTLSCA             = flag.String("ris_tls_ca", "", "CA file to verify RIS server certificates against (enables TLS)")
+	risTLSCert           = flag.String("ris_tls_cert", "", "Client certificate file presented to RIS servers")
+	risTLSKey            = flag.String("ris_tls_key", "", "Client key file")
 )
 
 func main() {
@@ -35,9 +42,14 @@ func main() {
 		log.WithError(err).Fatal("Failed to load config")
 	}
 
+	risTransport, err := servicewrapper.ClientTLS(*risTLSCA, *risTLSCert, *risTLSKey)
+	if err != nil {
+		log.WithError(err).Fatal("Unable to configure RIS client TLS")
+	}
+
 	grpcClientManager := clientmanager.New()
 	for _, instance := range cfg.GetRISInstances() {
-		err := grpcClientManager.AddIfNotExists(instance, grpc.WithInsecure(), grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		err := grpcClientManager.AddIfNotExists(instance, risTransport, grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:                time.Second * 10,
 			Timeout:             time.Second * time.Duration(*risTimeout),
 			PermitWithoutStream: true,
@@ -64,6 +76,15 @@ func main() {
 	}
 
 	s := risserver.NewServer(m)
+	sec, err := servicewrapper.NewSecurityConfig(*grpcTLSCert, *grpcTLSKey, *grpcTLSClientCA, *grpcAuthzFile)
+	if err != nil {
+		log.Fatalf("Unable to configure GRPC security: %v", err)
+	}
+	if a, ok := sec.Authorizer.(*servicewrapper.RoleAuthorizer); ok {
+		for _, m := range risserver.ReadMethods {
+			a.SetMethodRoleIfUnset(m, servicewrapper.RoleRead)
+		}
+	}
 	unaryInterceptors := []grpc.UnaryServerInterceptor{}
 	streamInterceptors := []grpc.StreamServerInterceptor{}
 	srv, err := servicewrapper.New(
@@ -75,6 +96,7 @@ func main() {
 			MinTime:             time.Duration(*grpcKeepaliveMinTime) * time.Second,
 			PermitWithoutStream: true,
 		},
+		sec,
 	)
 	if err != nil {
 		log.Errorf("failed to listen: %v", err)
@@ -24,6 +24,10 @@ var (
 	grpcPort             = flag.Uint("grpc_port", 4321, "gRPC server port")
 	httpPort             = flag.Uint("http_port", 4320, "HTTP server port")
 	grpcKeepaliveMinTime = flag.Uint("grpc_keepalive_min_time", 1, "Minimum time (seconds) for a client to wait between GRPC keepalive pings")
+	grpcTLSCert          = flag.String("grpc_tls_cert", "", "GRPC server TLS certificate file (enables TLS)")
+	grpcTLSKey           = flag.String("grpc_tls_key", "", "GRPC server TLS key file")
+	grpcTLSClientCA      = flag.String("grpc_tls_client_ca", "", "CA file to verify GRPC client certificates against (enables mutual TLS)")
+	grpcAuthzFile        = flag.String("grpc_authz_file", "", "GRPC authorization config file (enables per method authorization)")
 	configFilePath       = flag.String("config.file", "ris_config.yml", "Configuration file")
 )
 
@@ -49,6 +53,16 @@ func main() {
 	}
 
 	s := risserver.NewServer(b)
+	sec, err := servicewrapper.NewSecurityConfig(*grpcTLSCert, *grpcTLSKey, *grpcTLSClientCA, *grpcAuthzFile)
+	if err != nil {
+		log.Fatalf("Unable to configure GRPC security: %v", err)
+	}
+	if a, ok := sec.Authorizer.(*servicewrapper.RoleAuthorizer); ok {
+		for _, m := range risserver.ReadMethods {
+			a.SetMethodRoleIfUnset(m, servicewrapper.RoleRead)
+		}
+	}
+
 	unaryInterceptors := []grpc.UnaryServerInterceptor{}
 	streamInterceptors := []grpc.StreamServerInterceptor{}
 	srv, err := servicewrapper.New(
@@ -60,6 +74,7 @@ func main() {
 			MinTime:             time.Duration(*grpcKeepaliveMinTime) * time.Second,
 			PermitWithoutStream: true,
 		},
+		sec,
 	)
 	if err != nil {
 		log.Errorf("failed to listen: %v", err)
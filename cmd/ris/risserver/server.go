@@ -25,6 +25,16 @@ var (
 	risObserveFIBClients *prometheus.GaugeVec
 )
 
+// ReadMethods are the GRPC methods of the RIS. None of them changes state, so they only require the read role.
+var ReadMethods = []string{
+	"/bio.ris.RoutingInformationService/LPM",
+	"/bio.ris.RoutingInformationService/Get",
+	"/bio.ris.RoutingInformationService/GetRouters",
+	"/bio.ris.RoutingInformationService/GetLonger",
+	"/bio.ris.RoutingInformationService/ObserveRIB",
+	"/bio.ris.RoutingInformationService/DumpRIB",
+}
+
 func init() {
 	risObserveFIBClients = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
-	"google.golang.org/grpc"
 )
 
 // NewDumpLocRIBCommand creates a new dump local rib command
@@ -25,7 +24,7 @@ func NewDumpLocRIBCommand() cli.Command {
 	}
 
 	cmd.Action = func(c *cli.Context) error {
-		conn, err := grpc.Dial(c.GlobalString("ris"), grpc.WithInsecure())
+		conn, err := dialRIS(c)
 		if err != nil {
 			log.Errorf("GRPC dial failed: %v", err)
 			os.Exit(1)
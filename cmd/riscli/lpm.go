@@ -9,7 +9,6 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
-	"google.golang.org/grpc"
 )
 
 // NewLPMCommand creates a new LPM command
@@ -23,7 +22,7 @@ func NewLPMCommand() cli.Command {
 	}
 
 	cmd.Action = func(c *cli.Context) error {
-		conn, err := grpc.Dial(c.GlobalString("ris"), grpc.WithInsecure())
+		conn, err := dialRIS(c)
 		if err != nil {
 			log.Errorf("GRPC dial failed: %v", err)
 			os.Exit(1)
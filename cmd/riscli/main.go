@@ -3,6 +3,9 @@ package main
 import (
 	"os"
 
+	"github.com/bio-routing/bio-rd/util/servicewrapper"
+	"google.golang.org/grpc"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -32,6 +35,21 @@ func main() {
 			Usage: "VRF",
 			Value: "",
 		},
+		cli.StringFlag{
+			Name:  "tls_ca",
+			Usage: "CA file to verify the RIS certificate against (enables TLS)",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "tls_cert",
+			Usage: "Client certificate file",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "tls_key",
+			Usage: "Client key file",
+			Value: "",
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -45,3 +63,12 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func dialRIS(c *cli.Context) (*grpc.ClientConn, error) {
+	transport, err := servicewrapper.ClientTLS(c.GlobalString("tls_ca"), c.GlobalString("tls_cert"), c.GlobalString("tls_key"))
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.Dial(c.GlobalString("ris"), transport)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// LDP defaults (RFC 5036)
+const (
+	DefaultLDPHelloInterval = 5
+	DefaultLDPHoldTime      = 15
+	DefaultLDPKeepaliveTime = 180
+)
+
+// LDPConfig is the configuration of an LDP server
+type LDPConfig struct {
+	LSRID            bnet.IP
+	TransportAddress bnet.IP
+	HelloInterval    uint16
+	HoldTime         uint16
+	KeepaliveTime    uint16
+	Interfaces       []LDPInterfaceConfig
+}
+
+// LDPInterfaceConfig is the configuration of an interface LDP discovery runs on
+type LDPInterfaceConfig struct {
+	Name string
+}
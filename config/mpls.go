@@ -0,0 +1,52 @@
+package config
+
+// MPLS label range defaults
+const (
+	DefaultSRGBStart         = 16000
+	DefaultSRGBEnd           = 23999
+	DefaultSRLBStart         = 15000
+	DefaultSRLBEnd           = 15999
+	DefaultDynamicLabelStart = 24000
+	DefaultDynamicLabelEnd   = 1<<20 - 1
+)
+
+// MPLSConfig is the configuration of the MPLS label manager
+type MPLSConfig struct {
+	// DynamicLabels is the range labels are dynamically allocated from (e.g. by LDP or BGP)
+	DynamicLabels LabelRange
+
+	// SRGB is the segment routing global block prefix SIDs are mapped into
+	SRGB LabelRange
+
+	// SRLB is the segment routing local block adjacency SIDs are allocated from
+	SRLB LabelRange
+}
+
+// LabelRange is a range of MPLS labels (both ends included)
+type LabelRange struct {
+	Start uint32
+	End   uint32
+}
+
+// Size returns the number of labels in the range
+func (r LabelRange) Size() uint32 {
+	if r.End < r.Start {
+		return 0
+	}
+
+	return r.End - r.Start + 1
+}
+
+// Overlaps returns if r and x have any labels in common
+func (r LabelRange) Overlaps(x LabelRange) bool {
+	return r.Start <= x.End && x.Start <= r.End
+}
+
+// DefaultMPLSConfig returns the default label ranges
+func DefaultMPLSConfig() *MPLSConfig {
+	return &MPLSConfig{
+		DynamicLabels: LabelRange{Start: DefaultDynamicLabelStart, End: DefaultDynamicLabelEnd},
+		SRGB:          LabelRange{Start: DefaultSRGBStart, End: DefaultSRGBEnd},
+		SRLB:          LabelRange{Start: DefaultSRLBStart, End: DefaultSRLBEnd},
+	}
+}
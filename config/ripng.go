@@ -0,0 +1,33 @@
+package config
+
+// RIPng defaults (RFC 2080)
+const (
+	DefaultRIPngUpdateInterval    = 30
+	DefaultRIPngTimeout           = 180
+	DefaultRIPngGarbageCollection = 120
+	DefaultRIPngInterfaceMetric   = 1
+)
+
+// RIPngConfig is the configuration of a RIPng server
+type RIPngConfig struct {
+	// UpdateInterval, Timeout and GarbageCollection are the protocol timers in seconds
+	UpdateInterval    uint16
+	Timeout           uint16
+	GarbageCollection uint16
+	Interfaces        []RIPngInterfaceConfig
+}
+
+// RIPngInterfaceConfig is the configuration of an interface RIPng runs on
+type RIPngInterfaceConfig struct {
+	Name string
+
+	// Metric is the cost added to routes learned via the interface
+	Metric uint8
+
+	// Passive interfaces don't send updates. Their prefixes are advertised on other interfaces.
+	Passive bool
+
+	// PoisonedReverse advertises routes learned via the interface back to it with a metric of infinity
+	// instead of omitting them (split horizon)
+	PoisonedReverse bool
+}
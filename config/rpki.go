@@ -0,0 +1,29 @@
+package config
+
+// RTR defaults
+const (
+	DefaultRTRCacheListenAddress = ":323"
+	DefaultRTRHistorySize        = 32
+)
+
+// RTRCacheConfig is the configuration of an RTR cache server
+type RTRCacheConfig struct {
+	ListenAddress string
+
+	// Timing parameters sent to version 1 clients (seconds)
+	RefreshInterval uint32
+	RetryInterval   uint32
+	ExpireInterval  uint32
+
+	// HistorySize is the number of serials incremental updates are served for
+	HistorySize int
+}
+
+// RTRClientConfig is the configuration of an RTR client
+type RTRClientConfig struct {
+	// Address is the address (host:port) of the cache
+	Address string
+
+	// RetryInterval is the time (seconds) to wait before reconnecting to the cache until it told us otherwise
+	RetryInterval uint32
+}
@@ -6,7 +6,7 @@ import (
 	"net"
 	"strings"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -45,7 +45,7 @@ func (g *Global) SetDefaultGlobalConfigValues() error {
 func generateRouterID() (uint32, error) {
 	addr, err := getLoopbackIP()
 	if err == nil {
-		return convert.Uint32b([]byte(addr)[12:16]), nil
+		return endian.Uint32([]byte(addr)[12:16]), nil
 	}
 
 	return 0, fmt.Errorf("Unable to determine router id")
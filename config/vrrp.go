@@ -0,0 +1,36 @@
+package config
+
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// VRRP defaults (RFC 5798)
+const (
+	DefaultVRRPVersion  = 3
+	DefaultVRRPPriority = 100
+
+	// DefaultVRRPAdvertisementInterval is the default advertisement interval in centiseconds
+	DefaultVRRPAdvertisementInterval = 100
+)
+
+// VRRPConfig is the configuration of a VRRP server
+type VRRPConfig struct {
+	Instances []VRRPInstanceConfig
+}
+
+// VRRPInstanceConfig is the configuration of a virtual router
+type VRRPInstanceConfig struct {
+	Interface string
+	VRID      uint8
+	Version   uint8
+	Priority  uint8
+
+	// AdvertisementInterval is the advertisement interval in centiseconds
+	AdvertisementInterval uint16
+
+	// Preempt makes a backup router with a higher priority take over from a master with a lower priority
+	Preempt bool
+
+	// VirtualAddresses are the addresses of the virtual router. All addresses must be of the same address family.
+	VirtualAddresses []bnet.Prefix
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
 	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -21,6 +22,17 @@ var (
 	uptimeDesc                *prometheus.Desc
 	updatesReceivedDesc       *prometheus.Desc
 	updatesSentDesc           *prometheus.Desc
+	flapsDesc                 *prometheus.Desc
+	prefixLimitHitsDesc       *prometheus.Desc
+	updateLatencyDesc         *prometheus.Desc
+	messagesReceivedDesc      *prometheus.Desc
+	messagesSentDesc          *prometheus.Desc
+	prefixesReceivedDesc      *prometheus.Desc
+	prefixesSentDesc          *prometheus.Desc
+	malformedAttributesDesc   *prometheus.Desc
+	attributeErrorsDesc       *prometheus.Desc
+	lastNotificationDesc      *prometheus.Desc
+	fsmTransitionsDesc        *prometheus.Desc
 	upDescRouter              *prometheus.Desc
 	stateDescRouter           *prometheus.Desc
 	uptimeDescRouter          *prometheus.Desc
@@ -30,6 +42,10 @@ var (
 	routesSentDesc            *prometheus.Desc
 	routesRejectedDesc        *prometheus.Desc
 	routesAcceptedDesc        *prometheus.Desc
+	routesValidationDesc      *prometheus.Desc
+	prefixLimitDesc           *prometheus.Desc
+	prefixLimitWarningDesc    *prometheus.Desc
+	prefixLimitThresholdDesc  *prometheus.Desc
 	routesReceivedDescRouter  *prometheus.Desc
 	routesSentDescRouter      *prometheus.Desc
 	routesRejectedDescRouter  *prometheus.Desc
@@ -43,6 +59,17 @@ func init() {
 	uptimeDesc = prometheus.NewDesc(prefix+"uptime_second", "Time since the session was established in seconds", labels, nil)
 	updatesReceivedDesc = prometheus.NewDesc(prefix+"update_received_count", "Number of updates received", labels, nil)
 	updatesSentDesc = prometheus.NewDesc(prefix+"update_sent_count", "Number of updates sent", labels, nil)
+	flapsDesc = prometheus.NewDesc(prefix+"flap_count", "Number of times the session dropped out of established state", labels, nil)
+	prefixLimitHitsDesc = prometheus.NewDesc(prefix+"prefix_limit_hit_count", "Number of times the session was torn down for exceeding a prefix limit", labels, nil)
+	updateLatencyDesc = prometheus.NewDesc(prefix+"update_latency_seconds", "Time spent processing updates (rib = receipt until Loc-RIB, FIB and adj-RIBs-out are updated, adj_rib_out = queued in adj-RIB-out until sent)", append(labels, "stage"), nil)
+	messagesReceivedDesc = prometheus.NewDesc(prefix+"message_received_count", "Number of messages other than updates received by type", append(labels, "type"), nil)
+	messagesSentDesc = prometheus.NewDesc(prefix+"message_sent_count", "Number of messages other than updates sent by type", append(labels, "type"), nil)
+	prefixesReceivedDesc = prometheus.NewDesc(prefix+"prefix_received_count", "Number of prefixes advertised and withdrawn by the updates received", append(labels, "action"), nil)
+	prefixesSentDesc = prometheus.NewDesc(prefix+"prefix_sent_count", "Number of prefixes advertised and withdrawn by the updates sent", append(labels, "action"), nil)
+	malformedAttributesDesc = prometheus.NewDesc(prefix+"malformed_attribute_count", "Number of updates received with malformed path attributes", labels, nil)
+	attributeErrorsDesc = prometheus.NewDesc(prefix+"attribute_error_count", "Number of malformed path attributes received by error handling action (RFC7606)", append(labels, "action"), nil)
+	lastNotificationDesc = prometheus.NewDesc(prefix+"last_notification_timestamp_seconds", "Time of the last NOTIFICATION sent or received", append(labels, "direction", "code", "subcode", "description"), nil)
+	fsmTransitionsDesc = prometheus.NewDesc(prefix+"fsm_transition_count", "Number of state changes of the BGP FSM", labels, nil)
 
 	labelsRouter := append(labels, "sys_name", "agent_address")
 	upDescRouter = prometheus.NewDesc(prefix+"up", "Returns if the session is up", labelsRouter, nil)
@@ -56,6 +83,10 @@ func init() {
 	routesSentDesc = prometheus.NewDesc(prefix+"route_sent_count", "Number of routes sent", labels, nil)
 	routesRejectedDesc = prometheus.NewDesc(prefix+"route_rejected_count", "Number of routes rejected", labels, nil)
 	routesAcceptedDesc = prometheus.NewDesc(prefix+"route_accepted_count", "Number of routes accepted", labels, nil)
+	routesValidationDesc = prometheus.NewDesc(prefix+"route_validation_count", "Number of routes received per RPKI origin validation state", append(labels, "state"), nil)
+	prefixLimitDesc = prometheus.NewDesc(prefix+"prefix_limit", "Maximum number of routes accepted", labels, nil)
+	prefixLimitWarningDesc = prometheus.NewDesc(prefix+"prefix_limit_warning", "Returns if the number of routes received reached a warning threshold of the prefix limit", labels, nil)
+	prefixLimitThresholdDesc = prometheus.NewDesc(prefix+"prefix_limit_threshold", "Highest warning threshold of the prefix limit reached in percent, 0 if none", labels, nil)
 
 	labelsRouter = append(labelsRouter, "afi", "safi")
 	routesReceivedDescRouter = prometheus.NewDesc(prefix+"route_received_count", "Number of routes received", labelsRouter, nil)
@@ -81,10 +112,25 @@ func (c *bgpCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- uptimeDesc
 	ch <- updatesReceivedDesc
 	ch <- updatesSentDesc
+	ch <- flapsDesc
+	ch <- prefixLimitHitsDesc
+	ch <- updateLatencyDesc
+	ch <- messagesReceivedDesc
+	ch <- messagesSentDesc
+	ch <- prefixesReceivedDesc
+	ch <- prefixesSentDesc
+	ch <- malformedAttributesDesc
+	ch <- attributeErrorsDesc
+	ch <- lastNotificationDesc
+	ch <- fsmTransitionsDesc
 	ch <- routesReceivedDesc
 	ch <- routesSentDesc
 	ch <- routesRejectedDesc
 	ch <- routesAcceptedDesc
+	ch <- routesValidationDesc
+	ch <- prefixLimitDesc
+	ch <- prefixLimitWarningDesc
+	ch <- prefixLimitThresholdDesc
 }
 
 func DescribeRouter(ch chan<- *prometheus.Desc) {
@@ -132,12 +178,46 @@ func collectForPeer(ch chan<- prometheus.Metric, peer *metrics.BGPPeerMetrics) {
 
 	ch <- prometheus.MustNewConstMetric(updatesReceivedDesc, prometheus.CounterValue, float64(peer.UpdatesReceived), l...)
 	ch <- prometheus.MustNewConstMetric(updatesSentDesc, prometheus.CounterValue, float64(peer.UpdatesSent), l...)
+	ch <- prometheus.MustNewConstMetric(flapsDesc, prometheus.CounterValue, float64(peer.Flaps), l...)
+	ch <- prometheus.MustNewConstMetric(prefixLimitHitsDesc, prometheus.CounterValue, float64(peer.PrefixLimitHits), l...)
+	ch <- prometheus.MustNewConstHistogram(updateLatencyDesc, peer.RIBLatency.Count, peer.RIBLatency.Sum, peer.RIBLatency.Buckets, append(l, "rib")...)
+	ch <- prometheus.MustNewConstHistogram(updateLatencyDesc, peer.AdjRIBOutLatency.Count, peer.AdjRIBOutLatency.Sum, peer.AdjRIBOutLatency.Buckets, append(l, "adj_rib_out")...)
+
+	collectMessageCounts(ch, messagesReceivedDesc, peer.MessagesReceived, l)
+	collectMessageCounts(ch, messagesSentDesc, peer.MessagesSent, l)
+	ch <- prometheus.MustNewConstMetric(prefixesReceivedDesc, prometheus.CounterValue, float64(peer.PrefixesAdvertisedReceived), append(l, "advertised")...)
+	ch <- prometheus.MustNewConstMetric(prefixesReceivedDesc, prometheus.CounterValue, float64(peer.PrefixesWithdrawnReceived), append(l, "withdrawn")...)
+	ch <- prometheus.MustNewConstMetric(prefixesSentDesc, prometheus.CounterValue, float64(peer.PrefixesAdvertisedSent), append(l, "advertised")...)
+	ch <- prometheus.MustNewConstMetric(prefixesSentDesc, prometheus.CounterValue, float64(peer.PrefixesWithdrawnSent), append(l, "withdrawn")...)
+	ch <- prometheus.MustNewConstMetric(malformedAttributesDesc, prometheus.CounterValue, float64(peer.MalformedAttributes), l...)
+	ch <- prometheus.MustNewConstMetric(attributeErrorsDesc, prometheus.CounterValue, float64(peer.AttributeErrors.TreatAsWithdraw), append(l, "treat_as_withdraw")...)
+	ch <- prometheus.MustNewConstMetric(attributeErrorsDesc, prometheus.CounterValue, float64(peer.AttributeErrors.AttributeDiscard), append(l, "attribute_discard")...)
+	ch <- prometheus.MustNewConstMetric(attributeErrorsDesc, prometheus.CounterValue, float64(peer.AttributeErrors.SessionReset), append(l, "session_reset")...)
+	ch <- prometheus.MustNewConstMetric(fsmTransitionsDesc, prometheus.CounterValue, float64(peer.FSMTransitions), l...)
+	collectLastNotification(ch, peer.LastNotificationSent, append(l, "sent"))
+	collectLastNotification(ch, peer.LastNotificationReceived, append(l, "received"))
 
 	for _, family := range peer.AddressFamilies {
 		collectForFamily(ch, family, l)
 	}
 }
 
+func collectLastNotification(ch chan<- prometheus.Metric, n *metrics.BGPNotification, l []string) {
+	if n == nil {
+		return
+	}
+
+	l = append(l, strconv.Itoa(int(n.ErrorCode)), strconv.Itoa(int(n.ErrorSubcode)), n.Description)
+	ch <- prometheus.MustNewConstMetric(lastNotificationDesc, prometheus.GaugeValue, float64(n.Time.Unix()), l...)
+}
+
+func collectMessageCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, c metrics.BGPMessageCounts, l []string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(c.Opens), append(l, "open")...)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(c.Keepalives), append(l, "keepalive")...)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(c.Notifications), append(l, "notification")...)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(c.RouteRefreshes), append(l, "route_refresh")...)
+}
+
 func CollectForPeerRouter(ch chan<- prometheus.Metric, sysName string, agentAddress string, peer *metrics.BGPPeerMetrics) {
 	l := []string{
 		peer.IP.String(),
@@ -171,6 +251,23 @@ func collectForFamily(ch chan<- prometheus.Metric, family *metrics.BGPAddressFam
 
 	ch <- prometheus.MustNewConstMetric(routesReceivedDesc, prometheus.CounterValue, float64(family.RoutesReceived), l...)
 	ch <- prometheus.MustNewConstMetric(routesSentDesc, prometheus.CounterValue, float64(family.RoutesSent), l...)
+
+	if family.PrefixLimit != 0 {
+		var warning float64
+		if family.PrefixLimitWarning {
+			warning = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(prefixLimitDesc, prometheus.GaugeValue, float64(family.PrefixLimit), l...)
+		ch <- prometheus.MustNewConstMetric(prefixLimitWarningDesc, prometheus.GaugeValue, warning, l...)
+		ch <- prometheus.MustNewConstMetric(prefixLimitThresholdDesc, prometheus.GaugeValue, float64(family.PrefixLimitThreshold), l...)
+	}
+
+	if family.OriginValidation {
+		ch <- prometheus.MustNewConstMetric(routesValidationDesc, prometheus.GaugeValue, float64(family.RoutesValid), append(l, vrp.Valid.String())...)
+		ch <- prometheus.MustNewConstMetric(routesValidationDesc, prometheus.GaugeValue, float64(family.RoutesInvalid), append(l, vrp.Invalid.String())...)
+		ch <- prometheus.MustNewConstMetric(routesValidationDesc, prometheus.GaugeValue, float64(family.RoutesNotFound), append(l, vrp.NotFound.String())...)
+	}
 }
 
 func collectForFamilyRouter(ch chan<- prometheus.Metric, family *metrics.BGPAddressFamilyMetrics, l []string) {
@@ -0,0 +1,42 @@
+package prom
+
+import (
+	"github.com/bio-routing/bio-rd/protocols/kernel"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	prefix = "bio_kernel_"
+)
+
+var (
+	fibProgrammingLatencyDesc *prometheus.Desc
+)
+
+func init() {
+	fibProgrammingLatencyDesc = prometheus.NewDesc(prefix+"fib_programming_seconds", "Time taken to install or remove routes in the kernel by protocol", []string{"protocol"}, nil)
+}
+
+// NewCollector creates a new collector instance for the given kernel
+func NewCollector(k *kernel.Kernel) prometheus.Collector {
+	return &kernelCollector{
+		kernel: k,
+	}
+}
+
+// kernelCollector provides a collector for kernel metrics of BIO to use with Prometheus
+type kernelCollector struct {
+	kernel *kernel.Kernel
+}
+
+// Describe conforms to the prometheus collector interface
+func (c *kernelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- fibProgrammingLatencyDesc
+}
+
+// Collect conforms to the prometheus collector interface
+func (c *kernelCollector) Collect(ch chan<- prometheus.Metric) {
+	for protocol, h := range c.kernel.Metrics().FIBProgrammingLatency {
+		ch <- prometheus.MustNewConstHistogram(fibProgrammingLatencyDesc, h.Count, h.Sum, h.Buckets, protocol)
+	}
+}
@@ -13,14 +13,23 @@ const (
 )
 
 var (
-	routeCountDesc       *prometheus.Desc
-	routeCountDescRouter *prometheus.Desc
+	routeCountDesc                 *prometheus.Desc
+	routeCountDescRouter           *prometheus.Desc
+	routeCountByProtocolDesc       *prometheus.Desc
+	routeCountByProtocolDescRouter *prometheus.Desc
+	memoryEstimateDesc             *prometheus.Desc
+	memoryEstimateDescRouter       *prometheus.Desc
 )
 
 func init() {
 	labels := []string{"vrf_name", "vrf_rd", "rib", "afi", "safi"}
+	labelsRouter := append([]string{"sys_name", "agent_address"}, labels...)
 	routeCountDesc = prometheus.NewDesc(prefix+"route_count", "Number of routes in the RIB", labels, nil)
-	routeCountDescRouter = prometheus.NewDesc(prefix+"route_count", "Number of routes in the RIB", append([]string{"sys_name", "agent_address"}, labels...), nil)
+	routeCountDescRouter = prometheus.NewDesc(prefix+"route_count", "Number of routes in the RIB", labelsRouter, nil)
+	routeCountByProtocolDesc = prometheus.NewDesc(prefix+"route_count_by_protocol", "Number of routes in the RIB by protocol of the best path", append(labels, "protocol"), nil)
+	routeCountByProtocolDescRouter = prometheus.NewDesc(prefix+"route_count_by_protocol", "Number of routes in the RIB by protocol of the best path", append(labelsRouter, "protocol"), nil)
+	memoryEstimateDesc = prometheus.NewDesc(prefix+"memory_estimate_bytes", "Estimated memory used by the routes in the RIB", labels, nil)
+	memoryEstimateDescRouter = prometheus.NewDesc(prefix+"memory_estimate_bytes", "Estimated memory used by the routes in the RIB", labelsRouter, nil)
 }
 
 // NewCollector creates a new collector instance for the given BGP server
@@ -38,11 +47,15 @@ type vrfCollector struct {
 // Describe conforms to the prometheus collector interface
 func (c *vrfCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- routeCountDesc
+	ch <- routeCountByProtocolDesc
+	ch <- memoryEstimateDesc
 }
 
 // DescribeRouter conforms to the prometheus collector interface (used by BMP Server)
 func DescribeRouter(ch chan<- *prometheus.Desc) {
 	ch <- routeCountDescRouter
+	ch <- routeCountByProtocolDescRouter
+	ch <- memoryEstimateDescRouter
 }
 
 // Collect conforms to the prometheus collector interface
@@ -54,15 +67,25 @@ func (c *vrfCollector) Collect(ch chan<- prometheus.Metric) {
 
 func (c *vrfCollector) collectForVRF(ch chan<- prometheus.Metric, v *metrics.VRFMetrics) {
 	for _, rib := range v.RIBs {
-		ch <- prometheus.MustNewConstMetric(routeCountDesc, prometheus.GaugeValue, float64(rib.RouteCount),
-			v.Name, vrf.RouteDistinguisherHumanReadable(v.RD), rib.Name, strconv.Itoa(int(rib.AFI)), strconv.Itoa(int(rib.SAFI)))
+		l := []string{v.Name, vrf.RouteDistinguisherHumanReadable(v.RD), rib.Name, strconv.Itoa(int(rib.AFI)), strconv.Itoa(int(rib.SAFI))}
+		ch <- prometheus.MustNewConstMetric(routeCountDesc, prometheus.GaugeValue, float64(rib.RouteCount), l...)
+		ch <- prometheus.MustNewConstMetric(memoryEstimateDesc, prometheus.GaugeValue, float64(rib.MemoryEstimate), l...)
+
+		for protocol, count := range rib.RouteCountByProtocol {
+			ch <- prometheus.MustNewConstMetric(routeCountByProtocolDesc, prometheus.GaugeValue, float64(count), append(l, protocol)...)
+		}
 	}
 }
 
 // CollectForVRFRouter collects metrics for a certain router (used by BMP Server)
 func CollectForVRFRouter(ch chan<- prometheus.Metric, sysName string, agentAddress string, v *metrics.VRFMetrics) {
 	for _, rib := range v.RIBs {
-		ch <- prometheus.MustNewConstMetric(routeCountDescRouter, prometheus.GaugeValue, float64(rib.RouteCount),
-			sysName, agentAddress, v.Name, vrf.RouteDistinguisherHumanReadable(v.RD), rib.Name, strconv.Itoa(int(rib.AFI)), strconv.Itoa(int(rib.SAFI)))
+		l := []string{sysName, agentAddress, v.Name, vrf.RouteDistinguisherHumanReadable(v.RD), rib.Name, strconv.Itoa(int(rib.AFI)), strconv.Itoa(int(rib.SAFI))}
+		ch <- prometheus.MustNewConstMetric(routeCountDescRouter, prometheus.GaugeValue, float64(rib.RouteCount), l...)
+		ch <- prometheus.MustNewConstMetric(memoryEstimateDescRouter, prometheus.GaugeValue, float64(rib.MemoryEstimate), l...)
+
+		for protocol, count := range rib.RouteCountByProtocol {
+			ch <- prometheus.MustNewConstMetric(routeCountByProtocolDescRouter, prometheus.GaugeValue, float64(count), append(l, protocol)...)
+		}
 	}
 }
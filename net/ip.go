@@ -1,8 +1,10 @@
 package net
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
+	"net/netip"
 
 	api "github.com/bio-routing/bio-rd/net/api"
 )
@@ -265,6 +267,31 @@ func (ip *IP) ToNetIP() net.IP {
 	return net.IP(ip.Bytes())
 }
 
+// IPFromNetIPAddr creates an IP address from a netip.Addr. IPv4-mapped IPv6 addresses stay IPv6 addresses.
+func IPFromNetIPAddr(a netip.Addr) IP {
+	if a.Is4() {
+		b := a.As4()
+		return IPv4(binary.BigEndian.Uint32(b[:]))
+	}
+
+	b := a.As16()
+	return IPv6(binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:]))
+}
+
+// ToNetIPAddr converts the IP address into a netip.Addr
+func (ip IP) ToNetIPAddr() netip.Addr {
+	if ip.isLegacy {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], ip.ToUint32())
+		return netip.AddrFrom4(b)
+	}
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], ip.higher)
+	binary.BigEndian.PutUint64(b[8:], ip.lower)
+	return netip.AddrFrom16(b)
+}
+
 // BitAtPosition returns the bit at position pos
 func (ip *IP) BitAtPosition(pos uint8) bool {
 	if ip.isLegacy {
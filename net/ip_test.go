@@ -3,6 +3,7 @@ package net
 import (
 	"math"
 	"net"
+	"net/netip"
 	"testing"
 
 	"github.com/bio-routing/bio-rd/net/api"
@@ -449,6 +450,37 @@ func TestToNetIP(t *testing.T) {
 	}
 }
 
+func TestNetIPAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       IP
+		expected netip.Addr
+	}{
+		{
+			name:     "IPv4",
+			ip:       IPv4FromOctets(192, 168, 1, 1),
+			expected: netip.MustParseAddr("192.168.1.1"),
+		},
+		{
+			name:     "IPv6",
+			ip:       IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x1234, 0x5678, 0xdead, 0xbeef, 0xcafe),
+			expected: netip.MustParseAddr("2001:678:1e0:1234:5678:dead:beef:cafe"),
+		},
+		{
+			name:     "IPv4-mapped IPv6",
+			ip:       IPv6(0, 0xffffc0a80101),
+			expected: netip.MustParseAddr("::ffff:192.168.1.1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.ip.ToNetIPAddr())
+			assert.Equal(t, test.ip, IPFromNetIPAddr(test.expected))
+		})
+	}
+}
+
 func TestBitAtPosition(t *testing.T) {
 	tests := []struct {
 		name     string
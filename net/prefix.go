@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	gonet "net"
+	"net/netip"
 	"strconv"
 	"strings"
 
@@ -11,9 +12,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Prefix represents an IPv4 prefix
+// Prefix represents an IPv4 or IPv6 prefix. Prefix is a comparable value type and can be used as map key.
 type Prefix struct {
-	addr   *IP
+	addr   IP
 	pfxlen uint8
 }
 
@@ -31,7 +32,7 @@ func (p Prefix) Ptr() *Prefix {
 // NewPrefixFromProtoPrefix creates a Prefix from a proto Prefix
 func NewPrefixFromProtoPrefix(pfx *api.Prefix) *Prefix {
 	return &Prefix{
-		addr:   IPFromProtoIP(pfx.Address),
+		addr:   *IPFromProtoIP(pfx.Address),
 		pfxlen: uint8(pfx.Pfxlen),
 	}
 }
@@ -54,7 +55,7 @@ func PrefixFromString(s string) (*Prefix, error) {
 	}
 
 	return &Prefix{
-		addr:   ip.Dedup(),
+		addr:   ip,
 		pfxlen: uint8(l),
 	}, nil
 }
@@ -70,7 +71,7 @@ func (p Prefix) ToProto() *api.Prefix {
 // NewPfx creates a new Prefix
 func NewPfx(addr IP, pfxlen uint8) Prefix {
 	return Prefix{
-		addr:   addr.Dedup(),
+		addr:   addr,
 		pfxlen: pfxlen,
 	}
 }
@@ -81,11 +82,24 @@ func NewPfxFromIPNet(ipNet *gonet.IPNet) *Prefix {
 	ip, _ := IPFromBytes(ipNet.IP)
 
 	return &Prefix{
-		addr:   ip.Dedup(),
+		addr:   ip,
 		pfxlen: uint8(ones),
 	}
 }
 
+// PrefixFromNetIPPrefix creates a Prefix from a netip.Prefix
+func PrefixFromNetIPPrefix(p netip.Prefix) Prefix {
+	return Prefix{
+		addr:   IPFromNetIPAddr(p.Addr()),
+		pfxlen: uint8(p.Bits()),
+	}
+}
+
+// ToNetIPPrefix converts the prefix into a netip.Prefix
+func (pfx Prefix) ToNetIPPrefix() netip.Prefix {
+	return netip.PrefixFrom(pfx.addr.ToNetIPAddr(), int(pfx.pfxlen))
+}
+
 // StrToAddr converts an IP address string to it's uint32 representation
 func StrToAddr(x string) (uint32, error) {
 	parts := strings.Split(x, ".")
@@ -112,7 +126,7 @@ func StrToAddr(x string) (uint32, error) {
 
 // Addr returns the address of the prefix
 func (pfx *Prefix) Addr() *IP {
-	return pfx.addr
+	return &pfx.addr
 }
 
 // Pfxlen returns the length of the prefix
@@ -122,7 +136,7 @@ func (pfx *Prefix) Pfxlen() uint8 {
 
 // String returns a string representation of pfx
 func (pfx *Prefix) String() string {
-	return fmt.Sprintf("%s/%d", pfx.addr, pfx.pfxlen)
+	return fmt.Sprintf("%s/%d", pfx.addr.String(), pfx.pfxlen)
 }
 
 // GetIPNet returns the gonet.IP object for a Prefix object
@@ -174,7 +188,7 @@ func (pfx *Prefix) containsIPv6(x *Prefix) bool {
 
 // Equal checks if pfx and x are equal
 func (pfx *Prefix) Equal(x *Prefix) bool {
-	return pfx.addr.Equal(x.addr) && pfx.pfxlen == x.pfxlen
+	return *pfx == *x
 }
 
 // GetSupernet gets the next common supernet of pfx and x
@@ -198,7 +212,7 @@ func (pfx *Prefix) supernetIPv4(x *Prefix) Prefix {
 	}
 
 	return Prefix{
-		addr:   IPv4(a << (32 - maxPfxLen)).Dedup(),
+		addr:   IPv4(a << (32 - maxPfxLen)),
 		pfxlen: maxPfxLen,
 	}
 }
@@ -8,7 +8,7 @@ import (
 
 func TestPrefixCache(t *testing.T) {
 	a := &Prefix{
-		addr: &IP{
+		addr: IP{
 			higher:   100,
 			lower:    200,
 			isLegacy: false,
@@ -16,7 +16,7 @@ func TestPrefixCache(t *testing.T) {
 		pfxlen: 64,
 	}
 	b := &Prefix{
-		addr: &IP{
+		addr: IP{
 			higher:   100,
 			lower:    200,
 			isLegacy: false,
@@ -24,9 +24,6 @@ func TestPrefixCache(t *testing.T) {
 		pfxlen: 64,
 	}
 
-	a.addr = a.addr.Dedup()
-	b.addr = b.addr.Dedup()
-
 	x := a.Dedup()
 	y := b.Dedup()
 
@@ -2,6 +2,7 @@ package net
 
 import (
 	gonet "net"
+	"net/netip"
 	"testing"
 
 	"github.com/bio-routing/bio-rd/net/api"
@@ -66,7 +67,7 @@ func TestPrefixToProto(t *testing.T) {
 		{
 			name: "IPv4",
 			pfx: Prefix{
-				addr: &IP{
+				addr: IP{
 					lower:    200,
 					isLegacy: true,
 				},
@@ -83,7 +84,7 @@ func TestPrefixToProto(t *testing.T) {
 		{
 			name: "IPv6",
 			pfx: Prefix{
-				addr: &IP{
+				addr: IP{
 					higher:   100,
 					lower:    200,
 					isLegacy: false,
@@ -124,7 +125,7 @@ func TestNewPrefixFromProtoPrefix(t *testing.T) {
 				Pfxlen: 24,
 			},
 			expected: Prefix{
-				addr: &IP{
+				addr: IP{
 					higher:   0,
 					lower:    2000,
 					isLegacy: true,
@@ -143,7 +144,7 @@ func TestNewPrefixFromProtoPrefix(t *testing.T) {
 				Pfxlen: 64,
 			},
 			expected: Prefix{
-				addr: &IP{
+				addr: IP{
 					higher:   1000,
 					lower:    2000,
 					isLegacy: false,
@@ -161,7 +162,7 @@ func TestNewPrefixFromProtoPrefix(t *testing.T) {
 
 func TestNewPfx(t *testing.T) {
 	p := NewPfx(IPv4(123), 11)
-	if *p.addr != IPv4(123) || p.pfxlen != 11 {
+	if p.addr != IPv4(123) || p.pfxlen != 11 {
 		t.Errorf("NewPfx() failed: Unexpected values")
 	}
 }
@@ -214,75 +215,75 @@ func TestGetSupernet(t *testing.T) {
 		{
 			name: "Supernet of 10.0.0.0 and 11.100.123.0 -> 10.0.0.0/7",
 			a: &Prefix{
-				addr:   IPv4FromOctets(10, 0, 0, 0).Ptr(),
+				addr:   IPv4FromOctets(10, 0, 0, 0),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4FromOctets(11, 100, 123, 0).Ptr(),
+				addr:   IPv4FromOctets(11, 100, 123, 0),
 				pfxlen: 24,
 			},
 			expected: &Prefix{
-				addr:   IPv4FromOctets(10, 0, 0, 0).Dedup(),
+				addr:   IPv4FromOctets(10, 0, 0, 0),
 				pfxlen: 7,
 			},
 		},
 		{
 			name: "Supernet of 10.0.0.0 and 192.168.0.0 -> 0.0.0.0/0",
 			a: &Prefix{
-				addr:   IPv4FromOctets(10, 0, 0, 0).Ptr(),
+				addr:   IPv4FromOctets(10, 0, 0, 0),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4FromOctets(192, 168, 0, 0).Ptr(),
+				addr:   IPv4FromOctets(192, 168, 0, 0),
 				pfxlen: 24,
 			},
 			expected: &Prefix{
-				addr:   IPv4(0).Dedup(),
+				addr:   IPv4(0),
 				pfxlen: 0,
 			},
 		},
 		{
 			name: "Supernet of 2001:678:1e0:100:23::/64 and 2001:678:1e0:1ff::/64 -> 2001:678:1e0:100::/56",
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0x23, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0x23, 0, 0, 0),
 				pfxlen: 64,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x1ff, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x1ff, 0, 0, 0, 0),
 				pfxlen: 64,
 			},
 			expected: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0).Dedup(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0),
 				pfxlen: 56,
 			},
 		},
 		{
 			name: "Supernet of 2001:678:1e0::/128 and 2001:678:1e0::1/128 -> 2001:678:1e0:100::/127",
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0),
 				pfxlen: 128,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 1).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 1),
 				pfxlen: 128,
 			},
 			expected: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0).Dedup(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0),
 				pfxlen: 127,
 			},
 		},
 		{
 			name: "Supernet of all ones and all zeros -> ::/0",
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF).Ptr(),
+				addr:   IPv6FromBlocks(0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF),
 				pfxlen: 128,
 			},
 			b: &Prefix{
-				addr:   IPv6(0, 0).Ptr(),
+				addr:   IPv6(0, 0),
 				pfxlen: 128,
 			},
 			expected: &Prefix{
-				addr:   IPv6FromBlocks(0, 0, 0, 0, 0, 0, 0, 0).Dedup(),
+				addr:   IPv6FromBlocks(0, 0, 0, 0, 0, 0, 0, 0),
 				pfxlen: 0,
 			},
 		},
@@ -306,132 +307,132 @@ func TestContains(t *testing.T) {
 	}{
 		{
 			a: &Prefix{
-				addr:   IPv4(0).Ptr(),
+				addr:   IPv4(0),
 				pfxlen: 0,
 			},
 			b: &Prefix{
-				addr:   IPv4(100).Ptr(),
+				addr:   IPv4(100),
 				pfxlen: 24,
 			},
 			expected: true,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4(100).Ptr(),
+				addr:   IPv4(100),
 				pfxlen: 24,
 			},
 			b: &Prefix{
-				addr:   IPv4(0).Ptr(),
+				addr:   IPv4(0),
 				pfxlen: 0,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4(167772160).Ptr(),
+				addr:   IPv4(167772160),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4(167772160).Ptr(),
+				addr:   IPv4(167772160),
 				pfxlen: 9,
 			},
 			expected: true,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4(167772160).Ptr(),
+				addr:   IPv4(167772160),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4(174391040).Ptr(),
+				addr:   IPv4(174391040),
 				pfxlen: 24,
 			},
 			expected: true,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4(167772160).Ptr(),
+				addr:   IPv4(167772160),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4(184549377).Ptr(),
+				addr:   IPv4(184549377),
 				pfxlen: 24,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4(167772160).Ptr(),
+				addr:   IPv4(167772160),
 				pfxlen: 8,
 			},
 			b: &Prefix{
-				addr:   IPv4(191134464).Ptr(),
+				addr:   IPv4(191134464),
 				pfxlen: 24,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv4FromOctets(169, 0, 0, 0).Ptr(),
+				addr:   IPv4FromOctets(169, 0, 0, 0),
 				pfxlen: 25,
 			},
 			b: &Prefix{
-				addr:   IPv4FromOctets(169, 1, 1, 0).Ptr(),
+				addr:   IPv4FromOctets(169, 1, 1, 0),
 				pfxlen: 26,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0),
 				pfxlen: 48,
 			},
 			expected: true,
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0),
 				pfxlen: 56,
 			},
 		},
 		{
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x200, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x200, 0, 0, 0, 0),
 				pfxlen: 56,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0),
 				pfxlen: 64,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x200, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x200, 0, 0, 0, 0),
 				pfxlen: 65,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 0, 0, 0, 0),
 				pfxlen: 64,
 			},
 			expected: false,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 0),
 				pfxlen: 72,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 1).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 1),
 				pfxlen: 127,
 			},
 			expected: true,
 		},
 		{
 			a: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 0).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 0, 0),
 				pfxlen: 126,
 			},
 			b: &Prefix{
-				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 100, 1).Ptr(),
+				addr:   IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0x100, 100, 0, 100, 1),
 				pfxlen: 127,
 			},
 			expected: false,
@@ -594,6 +595,58 @@ func TestEqualOperator(t *testing.T) {
 	assert.Equal(t, p1, p2, "p1 != p2 (even if attributes are equal)")
 }
 
+func TestPrefixAsMapKey(t *testing.T) {
+	m := map[Prefix]int{
+		NewPfx(IPv4FromOctets(10, 0, 0, 0), 8): 1,
+	}
+
+	assert.Equal(t, 1, m[NewPfx(IPv4FromOctets(10, 0, 0, 0), 8)])
+	assert.Equal(t, 0, m[NewPfx(IPv4FromOctets(10, 0, 0, 0), 16)])
+}
+
+func TestPrefixAllocations(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		p := NewPfx(IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0), 48)
+		q := PrefixFromNetIPPrefix(p.ToNetIPPrefix())
+		if p != q {
+			t.Fail()
+		}
+	})
+
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestNetIPPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		pfx      Prefix
+		expected netip.Prefix
+	}{
+		{
+			name:     "IPv4",
+			pfx:      NewPfx(IPv4FromOctets(10, 0, 0, 0), 8),
+			expected: netip.MustParsePrefix("10.0.0.0/8"),
+		},
+		{
+			name:     "IPv4 default route",
+			pfx:      NewPfx(IPv4(0), 0),
+			expected: netip.MustParsePrefix("0.0.0.0/0"),
+		},
+		{
+			name:     "IPv6",
+			pfx:      NewPfx(IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0), 48),
+			expected: netip.MustParsePrefix("2001:678:1e0::/48"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.pfx.ToNetIPPrefix())
+			assert.Equal(t, test.pfx, PrefixFromNetIPPrefix(test.expected))
+		})
+	}
+}
+
 func TestValid(t *testing.T) {
 	tests := []struct {
 		name     string
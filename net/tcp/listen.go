@@ -1,7 +1,9 @@
 package tcp
 
 import (
+	"fmt"
 	"net"
+	"runtime"
 	"syscall"
 
 	"github.com/pkg/errors"
@@ -89,9 +91,19 @@ func (l *Listener) SetTCPMD5(peerAddr net.IP, secret string) error {
 		return nil
 	}
 
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("TCP MD5 authentication is not supported on %s", runtime.GOOS)
+	}
+
 	return setTCPMD5Option(l.fd, peerAddr, secret)
 }
 
+// RemoveTCPMD5 removes the TCP md5 secret for addr
+func (l *Listener) RemoveTCPMD5(peerAddr net.IP) error {
+	// An empty key deletes the signature entry of the peer
+	return l.SetTCPMD5(peerAddr, "")
+}
+
 // AcceptTCP accepts a new TCP connection
 func (l *Listener) AcceptTCP() (*Conn, error) {
 	fd, sa, err := syscall.Accept(l.fd)
@@ -109,7 +121,7 @@ func (l *Listener) AcceptTCP() (*Conn, error) {
 		raddr.IP = net.IP(x.Addr[:])
 		raddr.Port = x.Port
 	case *syscall.SockaddrInet6:
-		x := sa.(*syscall.SockaddrInet4)
+		x := sa.(*syscall.SockaddrInet6)
 		raddr.IP = net.IP(x.Addr[:])
 		raddr.Port = x.Port
 	}
@@ -120,3 +132,9 @@ func (l *Listener) AcceptTCP() (*Conn, error) {
 		raddr: raddr,
 	}, nil
 }
+
+// Close closes the listener. A blocked AcceptTCP() call will return an error.
+func (l *Listener) Close() error {
+	syscall.Shutdown(l.fd, syscall.SHUT_RDWR)
+	return syscall.Close(l.fd)
+}
@@ -35,10 +35,11 @@ func buildTCPMD5Sig(addr net.IP, key string) tcpMD5sig {
 		keylen:    uint16(len(key)),
 	}
 
+	// ss holds the rest of a sockaddr_in or sockaddr_in6 following the family
 	if family == syscall.AF_INET {
 		copy(t.ss[2:], addr.To4())
 	} else {
-		copy(t.ss[2:], addr.To16())
+		copy(t.ss[6:], addr.To16())
 	}
 
 	copy(t.key[0:], []byte(key))
@@ -0,0 +1,53 @@
+package tcp
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTCPMD5Sig(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       net.IP
+		key        string
+		wantFamily uint16
+		wantOffset int
+		wantAddr   []byte
+	}{
+		{
+			name:       "IPv4",
+			addr:       net.ParseIP("192.0.2.1"),
+			key:        "secret",
+			wantFamily: syscall.AF_INET,
+			wantOffset: 2,
+			wantAddr:   []byte{192, 0, 2, 1},
+		},
+		{
+			name:       "IPv6",
+			addr:       net.ParseIP("2001:db8::1"),
+			key:        "secret",
+			wantFamily: syscall.AF_INET6,
+			wantOffset: 6,
+			wantAddr:   net.ParseIP("2001:db8::1").To16(),
+		},
+		{
+			name:       "Removal",
+			addr:       net.ParseIP("192.0.2.1"),
+			wantFamily: syscall.AF_INET,
+			wantOffset: 2,
+			wantAddr:   []byte{192, 0, 2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		sig := buildTCPMD5Sig(test.addr, test.key)
+
+		assert.Equalf(t, test.wantFamily, sig.ssFamily, "Test %q", test.name)
+		assert.Equalf(t, test.wantAddr, sig.ss[test.wantOffset:test.wantOffset+len(test.wantAddr)], "Test %q", test.name)
+		assert.Equalf(t, uint16(len(test.key)), sig.keylen, "Test %q", test.name)
+		assert.Equalf(t, test.key, string(sig.key[:sig.keylen]), "Test %q", test.name)
+	}
+}
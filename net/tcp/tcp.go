@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 )
 
 // Conn is TCP connection
@@ -96,6 +97,15 @@ func (c *Conn) SetTTL(ttl uint8) error {
 	return syscall.SetsockoptInt(c.fd, syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, int(ttl))
 }
 
+// SetMinTTL sets the lowest TTL (hop limit) of packets accepted on the connection, e.g. for GTSM (RFC5082)
+func (c *Conn) SetMinTTL(ttl uint8) error {
+	if c.raddr.IP.To4() != nil {
+		return syscall.SetsockoptInt(c.fd, syscall.SOL_IP, syscall.IP_MINTTL, int(ttl))
+	}
+
+	return syscall.SetsockoptInt(c.fd, syscall.IPPROTO_IPV6, unix.IPV6_MINHOPCOUNT, int(ttl))
+}
+
 // SetDontRoute sets the SO_DONTROUTE option
 func (c *Conn) SetDontRoute() error {
 	return syscall.SetsockoptInt(c.fd, syscall.SOL_SOCKET, syscall.SO_DONTROUTE, 1)
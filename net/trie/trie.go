@@ -0,0 +1,319 @@
+// Package trie provides a path compressed binary trie (patricia trie) keyed by prefixes
+package trie
+
+import (
+	"math/bits"
+
+	"github.com/bio-routing/bio-rd/net"
+)
+
+// Trie maps prefixes to values of type V. IPv4 and IPv6 prefixes are kept apart.
+// Host bits of prefixes are ignored, e.g. 10.0.0.1/8 and 10.0.0.0/8 are the same key.
+// Trie is not safe for concurrent use.
+type Trie[V any] struct {
+	v4  *node[V]
+	v6  *node[V]
+	len int
+}
+
+type key struct {
+	higher uint64
+	lower  uint64
+	pfxlen uint8
+}
+
+type node[V any] struct {
+	key      key
+	pfx      net.Prefix
+	value    V
+	hasValue bool
+	children [2]*node[V]
+}
+
+// New creates an empty trie
+func New[V any]() *Trie[V] {
+	return &Trie[V]{}
+}
+
+// Len returns the number of prefixes in the trie
+func (t *Trie[V]) Len() int {
+	return t.len
+}
+
+// Insert sets the value of pfx. It returns true if pfx was not in the trie before.
+func (t *Trie[V]) Insert(pfx *net.Prefix, v V) bool {
+	k, v4 := keyOf(pfx)
+	p := t.root(v4)
+	for {
+		n := *p
+		if n == nil {
+			*p = newNode(k, v4, v)
+			t.len++
+			return true
+		}
+
+		c := commonLen(n.key, k)
+		switch {
+		case c == n.key.pfxlen && c == k.pfxlen:
+			isNew := !n.hasValue
+			n.value = v
+			n.hasValue = true
+			if isNew {
+				t.len++
+			}
+			return isNew
+		case c == n.key.pfxlen:
+			p = &n.children[k.bit(c)]
+			continue
+		case c == k.pfxlen:
+			m := newNode(k, v4, v)
+			m.children[n.key.bit(c)] = n
+			*p = m
+		default:
+			glueKey := k.truncate(c)
+			glue := &node[V]{
+				key: glueKey,
+				pfx: glueKey.prefix(v4),
+			}
+			glue.children[k.bit(c)] = newNode(k, v4, v)
+			glue.children[n.key.bit(c)] = n
+			*p = glue
+		}
+
+		t.len++
+		return true
+	}
+}
+
+// Get gets the value of pfx
+func (t *Trie[V]) Get(pfx *net.Prefix) (V, bool) {
+	k, v4 := keyOf(pfx)
+	n := *t.root(v4)
+	for n != nil && commonLen(n.key, k) == n.key.pfxlen {
+		if n.key.pfxlen == k.pfxlen {
+			return n.value, n.hasValue
+		}
+
+		n = n.children[k.bit(n.key.pfxlen)]
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Remove removes pfx from the trie. It returns false if pfx was not in the trie.
+func (t *Trie[V]) Remove(pfx *net.Prefix) bool {
+	k, v4 := keyOf(pfx)
+	return t.remove(t.root(v4), k)
+}
+
+func (t *Trie[V]) remove(p **node[V], k key) bool {
+	n := *p
+	if n == nil || commonLen(n.key, k) < n.key.pfxlen {
+		return false
+	}
+
+	if n.key.pfxlen == k.pfxlen {
+		if !n.hasValue {
+			return false
+		}
+
+		var zero V
+		n.value = zero
+		n.hasValue = false
+		t.len--
+	} else if !t.remove(&n.children[k.bit(n.key.pfxlen)], k) {
+		return false
+	}
+
+	*p = n.compact()
+	return true
+}
+
+// compact returns the node replacing n after removing a value below or at n
+func (n *node[V]) compact() *node[V] {
+	if n.hasValue {
+		return n
+	}
+
+	if n.children[0] == nil {
+		return n.children[1]
+	}
+
+	if n.children[1] == nil {
+		return n.children[0]
+	}
+
+	return n
+}
+
+// LongestPrefixMatch finds the most specific prefix in the trie covering (or being equal to) pfx
+func (t *Trie[V]) LongestPrefixMatch(pfx *net.Prefix) (net.Prefix, V, bool) {
+	var match *node[V]
+	t.covering(pfx, func(n *node[V]) bool {
+		match = n
+		return true
+	})
+
+	if match == nil {
+		var zero V
+		return net.Prefix{}, zero, false
+	}
+
+	return match.pfx, match.value, true
+}
+
+// Covering calls f for all prefixes in the trie covering (or being equal to) pfx, least specific first.
+// Iteration stops if f returns false.
+func (t *Trie[V]) Covering(pfx *net.Prefix, f func(pfx net.Prefix, v V) bool) {
+	t.covering(pfx, func(n *node[V]) bool {
+		return f(n.pfx, n.value)
+	})
+}
+
+func (t *Trie[V]) covering(pfx *net.Prefix, f func(n *node[V]) bool) {
+	k, v4 := keyOf(pfx)
+	n := *t.root(v4)
+	for n != nil && commonLen(n.key, k) == n.key.pfxlen {
+		if n.hasValue && !f(n) {
+			return
+		}
+
+		if n.key.pfxlen == k.pfxlen {
+			return
+		}
+
+		n = n.children[k.bit(n.key.pfxlen)]
+	}
+}
+
+// Walk calls f for all prefixes in the trie. IPv4 prefixes are visited first. Less specific prefixes are
+// visited before the more specifics they cover. Iteration stops if f returns false.
+func (t *Trie[V]) Walk(f func(pfx net.Prefix, v V) bool) {
+	if !t.v4.walk(f) {
+		return
+	}
+
+	t.v6.walk(f)
+}
+
+// WalkSubtree calls f for all prefixes in the trie covered by (or being equal to) pfx in the same order as Walk.
+// Iteration stops if f returns false.
+func (t *Trie[V]) WalkSubtree(pfx *net.Prefix, f func(pfx net.Prefix, v V) bool) {
+	k, v4 := keyOf(pfx)
+	n := *t.root(v4)
+	for n != nil {
+		c := commonLen(n.key, k)
+		if c == k.pfxlen {
+			n.walk(f)
+			return
+		}
+
+		if c < n.key.pfxlen {
+			return
+		}
+
+		n = n.children[k.bit(n.key.pfxlen)]
+	}
+}
+
+func (n *node[V]) walk(f func(pfx net.Prefix, v V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.hasValue && !f(n.pfx, n.value) {
+		return false
+	}
+
+	return n.children[0].walk(f) && n.children[1].walk(f)
+}
+
+func (t *Trie[V]) root(v4 bool) **node[V] {
+	if v4 {
+		return &t.v4
+	}
+
+	return &t.v6
+}
+
+func newNode[V any](k key, v4 bool, v V) *node[V] {
+	return &node[V]{
+		key:      k,
+		pfx:      k.prefix(v4),
+		value:    v,
+		hasValue: true,
+	}
+}
+
+// keyOf returns the key of pfx. IPv4 addresses are stored in the most significant bits.
+func keyOf(pfx *net.Prefix) (key, bool) {
+	addr := pfx.Addr()
+	if addr.IsIPv4() {
+		return key{
+			higher: uint64(addr.ToUint32()) << 32,
+			pfxlen: pfx.Pfxlen(),
+		}.truncate(pfx.Pfxlen()), true
+	}
+
+	return key{
+		higher: addr.Higher(),
+		lower:  addr.Lower(),
+		pfxlen: pfx.Pfxlen(),
+	}.truncate(pfx.Pfxlen()), false
+}
+
+// truncate shortens k to l bits and clears all following bits
+func (k key) truncate(l uint8) key {
+	switch {
+	case l == 0:
+		return key{}
+	case l <= 64:
+		return key{
+			higher: k.higher & (^uint64(0) << (64 - l)),
+			pfxlen: l,
+		}
+	}
+
+	return key{
+		higher: k.higher,
+		lower:  k.lower & (^uint64(0) << (128 - l)),
+		pfxlen: l,
+	}
+}
+
+// bit returns the bit at position pos (0 being the most significant bit)
+func (k key) bit(pos uint8) int {
+	if pos < 64 {
+		return int(k.higher >> (63 - pos) & 1)
+	}
+
+	return int(k.lower >> (127 - pos) & 1)
+}
+
+func (k key) prefix(v4 bool) net.Prefix {
+	if v4 {
+		return net.NewPfx(net.IPv4(uint32(k.higher>>32)), k.pfxlen)
+	}
+
+	return net.NewPfx(net.IPv6(k.higher, k.lower), k.pfxlen)
+}
+
+// commonLen returns the number of leading bits a and b have in common (limited by their lengths)
+func commonLen(a, b key) uint8 {
+	l := a.pfxlen
+	if b.pfxlen < l {
+		l = b.pfxlen
+	}
+
+	c := uint8(bits.LeadingZeros64(a.higher ^ b.higher))
+	if c == 64 {
+		c += uint8(bits.LeadingZeros64(a.lower ^ b.lower))
+	}
+
+	if c > l {
+		return l
+	}
+
+	return c
+}
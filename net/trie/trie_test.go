@@ -0,0 +1,228 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func pfx(s string) *net.Prefix {
+	p, err := net.PrefixFromString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func newTestTrie(pfxs ...string) *Trie[string] {
+	t := New[string]()
+	for _, p := range pfxs {
+		t.Insert(pfx(p), p)
+	}
+
+	return t
+}
+
+func collect(walk func(f func(pfx net.Prefix, v string) bool)) []string {
+	ret := make([]string, 0)
+	walk(func(_ net.Prefix, v string) bool {
+		ret = append(ret, v)
+		return true
+	})
+
+	return ret
+}
+
+func TestInsertGetRemove(t *testing.T) {
+	tr := newTestTrie("10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16", "0.0.0.0/0", "2001:db8::/32")
+	assert.Equal(t, 5, tr.Len())
+
+	assert.False(t, tr.Insert(pfx("10.1.0.0/16"), "10.1.0.0/16 again"))
+	assert.False(t, tr.Insert(pfx("10.1.2.3/16"), "host bits"))
+	assert.Equal(t, 5, tr.Len())
+
+	v, ok := tr.Get(pfx("10.1.0.0/16"))
+	assert.True(t, ok)
+	assert.Equal(t, "host bits", v)
+
+	_, ok = tr.Get(pfx("10.0.0.0/9"))
+	assert.False(t, ok)
+
+	_, ok = tr.Get(pfx("10.0.0.0/8"))
+	assert.True(t, ok)
+
+	_, ok = tr.Get(pfx("2001:db8::/32"))
+	assert.True(t, ok)
+
+	_, ok = tr.Get(pfx("32.1.13.184/32"))
+	assert.False(t, ok, "IPv4 and IPv6 must be kept apart")
+
+	assert.True(t, tr.Remove(pfx("10.0.0.0/8")))
+	assert.False(t, tr.Remove(pfx("10.0.0.0/8")))
+	assert.False(t, tr.Remove(pfx("10.3.0.0/16")))
+	assert.Equal(t, 4, tr.Len())
+
+	_, ok = tr.Get(pfx("10.0.0.0/8"))
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"0.0.0.0/0", "host bits", "10.2.0.0/16", "2001:db8::/32"}, collect(tr.Walk))
+}
+
+func TestLongestPrefixMatch(t *testing.T) {
+	tr := newTestTrie("10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24", "192.168.0.0/16", "::/0", "2001:db8::/32")
+
+	tests := []struct {
+		name     string
+		needle   string
+		expected string
+		wantFail bool
+	}{
+		{
+			name:     "exact match",
+			needle:   "10.1.0.0/16",
+			expected: "10.1.0.0/16",
+		},
+		{
+			name:     "host",
+			needle:   "10.1.1.1/32",
+			expected: "10.1.1.0/24",
+		},
+		{
+			name:     "between",
+			needle:   "10.1.2.0/24",
+			expected: "10.1.0.0/16",
+		},
+		{
+			name:     "less specific than all",
+			needle:   "10.0.0.0/7",
+			wantFail: true,
+		},
+		{
+			name:     "no match",
+			needle:   "172.16.0.1/32",
+			wantFail: true,
+		},
+		{
+			name:     "IPv6",
+			needle:   "2001:db8:1::/48",
+			expected: "2001:db8::/32",
+		},
+		{
+			name:     "IPv6 default",
+			needle:   "2001:db9::/32",
+			expected: "::/0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, v, ok := tr.LongestPrefixMatch(pfx(test.needle))
+			if test.wantFail {
+				assert.False(t, ok)
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, test.expected, v)
+			assert.Equal(t, *pfx(test.expected), p)
+		})
+	}
+}
+
+func TestCoveringAndSubtree(t *testing.T) {
+	tr := newTestTrie("0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24", "10.2.0.0/16", "11.0.0.0/8")
+
+	assert.Equal(t, []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24"}, collect(func(f func(net.Prefix, string) bool) {
+		tr.Covering(pfx("10.1.1.128/25"), f)
+	}))
+
+	assert.Equal(t, []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24", "10.2.0.0/16"}, collect(func(f func(net.Prefix, string) bool) {
+		tr.WalkSubtree(pfx("10.0.0.0/8"), f)
+	}))
+
+	assert.Equal(t, []string{"10.1.0.0/16", "10.1.1.0/24"}, collect(func(f func(net.Prefix, string) bool) {
+		tr.WalkSubtree(pfx("10.1.0.0/15"), f)
+	}))
+
+	assert.Equal(t, []string{}, collect(func(f func(net.Prefix, string) bool) {
+		tr.WalkSubtree(pfx("12.0.0.0/8"), f)
+	}))
+
+	n := 0
+	tr.Walk(func(net.Prefix, string) bool {
+		n++
+		return n < 2
+	})
+	assert.Equal(t, 2, n, "Walk must stop if f returns false")
+}
+
+// TestRandom compares the trie against a brute force implementation
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tr := New[int]()
+	ref := make(map[net.Prefix]int)
+
+	randomPfx := func() *net.Prefix {
+		l := uint8(r.Intn(17))
+		p := net.NewPfx(net.IPv4(uint32(r.Intn(1<<4))<<28|uint32(r.Intn(1<<4))<<12), l)
+		b := p.BaseAddr()
+		return net.NewPfx(*b, l).Ptr()
+	}
+
+	for i := 0; i < 5000; i++ {
+		p := randomPfx()
+		if r.Intn(3) == 0 {
+			_, exists := ref[*p]
+			delete(ref, *p)
+			assert.Equal(t, exists, tr.Remove(p))
+		} else {
+			_, exists := ref[*p]
+			ref[*p] = i
+			assert.Equal(t, !exists, tr.Insert(p, i))
+		}
+
+		assert.Equal(t, len(ref), tr.Len())
+
+		needle := randomPfx()
+		bestLen := -1
+		best := 0
+		covering := 0
+		covered := 0
+		for q, v := range ref {
+			if q.Equal(needle) || q.Contains(needle) {
+				covering++
+				if int(q.Pfxlen()) > bestLen {
+					bestLen = int(q.Pfxlen())
+					best = v
+				}
+			}
+
+			if q.Equal(needle) || needle.Contains(&q) {
+				covered++
+			}
+		}
+
+		_, v, ok := tr.LongestPrefixMatch(needle)
+		assert.Equal(t, bestLen >= 0, ok)
+		if ok {
+			assert.Equal(t, best, v)
+		}
+
+		n := 0
+		tr.Covering(needle, func(net.Prefix, int) bool {
+			n++
+			return true
+		})
+		assert.Equal(t, covering, n)
+
+		n = 0
+		tr.WalkSubtree(needle, func(net.Prefix, int) bool {
+			n++
+			return true
+		})
+		assert.Equal(t, covered, n)
+	}
+}
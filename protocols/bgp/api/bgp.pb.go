@@ -24,6 +24,59 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type LookupRoutesRequest_RIB int32
+
+const (
+	LookupRoutesRequest_LocRIB    LookupRoutesRequest_RIB = 0
+	LookupRoutesRequest_AdjRIBIn  LookupRoutesRequest_RIB = 1
+	LookupRoutesRequest_AdjRIBOut LookupRoutesRequest_RIB = 2
+)
+
+var LookupRoutesRequest_RIB_name = map[int32]string{
+	0: "LocRIB",
+	1: "AdjRIBIn",
+	2: "AdjRIBOut",
+}
+
+var LookupRoutesRequest_RIB_value = map[string]int32{
+	"LocRIB":    0,
+	"AdjRIBIn":  1,
+	"AdjRIBOut": 2,
+}
+
+func (x LookupRoutesRequest_RIB) String() string {
+	return proto.EnumName(LookupRoutesRequest_RIB_name, int32(x))
+}
+
+func (LookupRoutesRequest_RIB) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_2d4ce551e16bb738, []int{4, 0}
+}
+
+type LookupRoutesRequest_MatchType int32
+
+const (
+	LookupRoutesRequest_Exact        LookupRoutesRequest_MatchType = 0
+	LookupRoutesRequest_LongestMatch LookupRoutesRequest_MatchType = 1
+)
+
+var LookupRoutesRequest_MatchType_name = map[int32]string{
+	0: "Exact",
+	1: "LongestMatch",
+}
+
+var LookupRoutesRequest_MatchType_value = map[string]int32{
+	"Exact":        0,
+	"LongestMatch": 1,
+}
+
+func (x LookupRoutesRequest_MatchType) String() string {
+	return proto.EnumName(LookupRoutesRequest_MatchType_name, int32(x))
+}
+
+func (LookupRoutesRequest_MatchType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_2d4ce551e16bb738, []int{4, 1}
+}
+
 type ListSessionsRequest struct {
 	Filter               *SessionFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
@@ -204,11 +257,133 @@ func (m *DumpRIBRequest) GetSafi() uint32 {
 	return 0
 }
 
+type LookupRoutesRequest struct {
+	Peer                 *api.IP                       `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	Afi                  uint32                        `protobuf:"varint,2,opt,name=afi,proto3" json:"afi,omitempty"`
+	Safi                 uint32                        `protobuf:"varint,3,opt,name=safi,proto3" json:"safi,omitempty"`
+	Rib                  LookupRoutesRequest_RIB       `protobuf:"varint,4,opt,name=rib,proto3,enum=bio.bgp.LookupRoutesRequest_RIB" json:"rib,omitempty"`
+	Pfx                  *api.Prefix                   `protobuf:"bytes,5,opt,name=pfx,proto3" json:"pfx,omitempty"`
+	MatchType            LookupRoutesRequest_MatchType `protobuf:"varint,6,opt,name=match_type,json=matchType,proto3,enum=bio.bgp.LookupRoutesRequest_MatchType" json:"match_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
+	XXX_unrecognized     []byte                        `json:"-"`
+	XXX_sizecache        int32                         `json:"-"`
+}
+
+func (m *LookupRoutesRequest) Reset()         { *m = LookupRoutesRequest{} }
+func (m *LookupRoutesRequest) String() string { return proto.CompactTextString(m) }
+func (*LookupRoutesRequest) ProtoMessage()    {}
+func (*LookupRoutesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d4ce551e16bb738, []int{4}
+}
+
+func (m *LookupRoutesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LookupRoutesRequest.Unmarshal(m, b)
+}
+func (m *LookupRoutesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LookupRoutesRequest.Marshal(b, m, deterministic)
+}
+func (m *LookupRoutesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LookupRoutesRequest.Merge(m, src)
+}
+func (m *LookupRoutesRequest) XXX_Size() int {
+	return xxx_messageInfo_LookupRoutesRequest.Size(m)
+}
+func (m *LookupRoutesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LookupRoutesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LookupRoutesRequest proto.InternalMessageInfo
+
+func (m *LookupRoutesRequest) GetPeer() *api.IP {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *LookupRoutesRequest) GetAfi() uint32 {
+	if m != nil {
+		return m.Afi
+	}
+	return 0
+}
+
+func (m *LookupRoutesRequest) GetSafi() uint32 {
+	if m != nil {
+		return m.Safi
+	}
+	return 0
+}
+
+func (m *LookupRoutesRequest) GetRib() LookupRoutesRequest_RIB {
+	if m != nil {
+		return m.Rib
+	}
+	return LookupRoutesRequest_LocRIB
+}
+
+func (m *LookupRoutesRequest) GetPfx() *api.Prefix {
+	if m != nil {
+		return m.Pfx
+	}
+	return nil
+}
+
+func (m *LookupRoutesRequest) GetMatchType() LookupRoutesRequest_MatchType {
+	if m != nil {
+		return m.MatchType
+	}
+	return LookupRoutesRequest_Exact
+}
+
+type LookupRoutesResponse struct {
+	Routes               []*api1.Route `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *LookupRoutesResponse) Reset()         { *m = LookupRoutesResponse{} }
+func (m *LookupRoutesResponse) String() string { return proto.CompactTextString(m) }
+func (*LookupRoutesResponse) ProtoMessage()    {}
+func (*LookupRoutesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d4ce551e16bb738, []int{5}
+}
+
+func (m *LookupRoutesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LookupRoutesResponse.Unmarshal(m, b)
+}
+func (m *LookupRoutesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LookupRoutesResponse.Marshal(b, m, deterministic)
+}
+func (m *LookupRoutesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LookupRoutesResponse.Merge(m, src)
+}
+func (m *LookupRoutesResponse) XXX_Size() int {
+	return xxx_messageInfo_LookupRoutesResponse.Size(m)
+}
+func (m *LookupRoutesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LookupRoutesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LookupRoutesResponse proto.InternalMessageInfo
+
+func (m *LookupRoutesResponse) GetRoutes() []*api1.Route {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterEnum("bio.bgp.LookupRoutesRequest_RIB", LookupRoutesRequest_RIB_name, LookupRoutesRequest_RIB_value)
+	proto.RegisterEnum("bio.bgp.LookupRoutesRequest_MatchType", LookupRoutesRequest_MatchType_name, LookupRoutesRequest_MatchType_value)
 	proto.RegisterType((*ListSessionsRequest)(nil), "bio.bgp.ListSessionsRequest")
 	proto.RegisterType((*SessionFilter)(nil), "bio.bgp.SessionFilter")
 	proto.RegisterType((*ListSessionsResponse)(nil), "bio.bgp.ListSessionsResponse")
 	proto.RegisterType((*DumpRIBRequest)(nil), "bio.bgp.DumpRIBRequest")
+	proto.RegisterType((*LookupRoutesRequest)(nil), "bio.bgp.LookupRoutesRequest")
+	proto.RegisterType((*LookupRoutesResponse)(nil), "bio.bgp.LookupRoutesResponse")
 }
 
 func init() {
@@ -216,31 +391,43 @@ func init() {
 }
 
 var fileDescriptor_2d4ce551e16bb738 = []byte{
-	// 382 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0xcb, 0x6a, 0xdb, 0x40,
-	0x14, 0xb5, 0x6a, 0xe3, 0xc7, 0x55, 0x5d, 0xcc, 0xb4, 0xb4, 0xae, 0x68, 0xa9, 0xd1, 0xca, 0x0b,
-	0x57, 0x6a, 0xed, 0x55, 0x1b, 0xb2, 0x31, 0x4e, 0x40, 0x90, 0x17, 0xe3, 0x45, 0x42, 0x36, 0x46,
-	0x72, 0x46, 0xf2, 0x80, 0x35, 0x33, 0xd1, 0x8c, 0xfc, 0xa5, 0xf9, 0xa0, 0xa0, 0xd1, 0x58, 0x44,
-	0x21, 0x31, 0x78, 0x23, 0x5d, 0x9d, 0xc7, 0xe5, 0x1c, 0x71, 0xe1, 0x5f, 0x42, 0xd5, 0x26, 0x8f,
-	0xbc, 0x35, 0x4f, 0xfd, 0x88, 0xf2, 0xdf, 0x19, 0xcf, 0x15, 0x65, 0x49, 0x39, 0x3f, 0xf8, 0x22,
-	0xe3, 0x8a, 0xaf, 0xf9, 0x56, 0xfa, 0x51, 0x22, 0xfc, 0x50, 0xd0, 0xe2, 0xed, 0x69, 0x14, 0x75,
-	0x22, 0xca, 0xbd, 0x28, 0x11, 0x8e, 0x7f, 0x78, 0x07, 0x23, 0x4a, 0x3b, 0x19, 0x51, 0xa5, 0xd3,
-	0x99, 0x1d, 0x36, 0x14, 0x9f, 0x44, 0x5b, 0xf4, 0x64, 0x4c, 0xa7, 0xc7, 0x26, 0x95, 0x44, 0x4a,
-	0xca, 0x59, 0x69, 0x77, 0xcf, 0xe0, 0xf3, 0x05, 0x95, 0x6a, 0x59, 0x82, 0x12, 0x93, 0xc7, 0x9c,
-	0x48, 0x85, 0x3c, 0x68, 0xc7, 0x74, 0xab, 0x48, 0x36, 0xb4, 0x46, 0xd6, 0xd8, 0x9e, 0x7e, 0xf5,
-	0x4c, 0x2b, 0xcf, 0x28, 0xcf, 0x35, 0x8b, 0x8d, 0xca, 0xbd, 0x83, 0x7e, 0x8d, 0x40, 0x13, 0xb0,
-	0x19, 0xa1, 0xc9, 0x26, 0xe2, 0xd9, 0x8a, 0x0a, 0xb3, 0xc5, 0xd6, 0x5b, 0x8a, 0xc2, 0xc1, 0x0d,
-	0x86, 0x3d, 0x1f, 0x08, 0xf4, 0x1d, 0xba, 0xbb, 0x2c, 0x5e, 0xb1, 0x30, 0x25, 0xc3, 0x0f, 0x23,
-	0x6b, 0xdc, 0xc3, 0x9d, 0x5d, 0x16, 0x5f, 0x85, 0x29, 0x71, 0x17, 0xf0, 0xa5, 0x1e, 0x50, 0x0a,
-	0xce, 0x24, 0x41, 0x13, 0xe8, 0x9a, 0x26, 0x72, 0x68, 0x8d, 0x9a, 0x63, 0x7b, 0x3a, 0x78, 0x9d,
-	0x11, 0x57, 0x0a, 0xf7, 0x16, 0x3e, 0x2d, 0xf2, 0x54, 0xe0, 0x60, 0xbe, 0x6f, 0xf8, 0x0b, 0x5a,
-	0x82, 0x54, 0xfd, 0x6a, 0xc9, 0x34, 0x81, 0x06, 0xd0, 0x0c, 0x63, 0xaa, 0xe3, 0xf4, 0x71, 0x31,
-	0x22, 0x04, 0x2d, 0x59, 0x40, 0x4d, 0x0d, 0xe9, 0x79, 0xfa, 0x64, 0x01, 0xcc, 0x13, 0xb1, 0x24,
-	0xd9, 0x8e, 0xae, 0x09, 0xba, 0x84, 0x8f, 0x2f, 0xd3, 0xa2, 0x1f, 0x55, 0xa6, 0x37, 0xfe, 0xb2,
-	0xf3, 0xf3, 0x1d, 0xb6, 0xac, 0xe8, 0x36, 0xd0, 0x7f, 0xe8, 0x99, 0xd8, 0x01, 0x43, 0xdf, 0x2a,
-	0x75, 0xbd, 0x8a, 0x53, 0x16, 0x2f, 0x8f, 0x02, 0x17, 0x4f, 0xb7, 0xf1, 0xc7, 0x42, 0x27, 0x00,
-	0x46, 0x77, 0x9d, 0xab, 0x23, 0xcd, 0xf3, 0xbf, 0xf7, 0xfe, 0x91, 0x77, 0x15, 0xb5, 0x35, 0x34,
-	0x7b, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x47, 0x78, 0x29, 0x1d, 0x3b, 0x03, 0x00, 0x00,
+	// 561 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0xd1, 0x6e, 0xd3, 0x30,
+	0x14, 0x6d, 0x9a, 0xad, 0x5b, 0x6e, 0xb7, 0x11, 0x79, 0x13, 0x84, 0x8a, 0x89, 0x92, 0x07, 0x94,
+	0x87, 0x91, 0x40, 0xf7, 0x04, 0x08, 0x09, 0xa2, 0x0d, 0x29, 0x52, 0xc7, 0x26, 0x0f, 0x09, 0xc4,
+	0xcb, 0x94, 0x64, 0x4e, 0x6a, 0x58, 0x62, 0x13, 0x3b, 0x55, 0xf7, 0x3d, 0xfc, 0x12, 0x1f, 0x84,
+	0xe2, 0xa4, 0xa5, 0xa5, 0x5b, 0xa5, 0x4a, 0x3c, 0xf5, 0xfa, 0x9e, 0x73, 0x8f, 0xcf, 0xa9, 0xaf,
+	0x02, 0xaf, 0x53, 0x2a, 0x47, 0x65, 0xe4, 0xc6, 0x2c, 0xf3, 0x22, 0xca, 0x5e, 0x14, 0xac, 0x94,
+	0x34, 0x4f, 0xeb, 0xfa, 0xda, 0xe3, 0x05, 0x93, 0x2c, 0x66, 0x37, 0xc2, 0x8b, 0x52, 0xee, 0x85,
+	0x9c, 0x56, 0xbf, 0xae, 0xea, 0xa2, 0xad, 0x88, 0x32, 0x37, 0x4a, 0x79, 0xcf, 0x5b, 0xad, 0x91,
+	0x13, 0xa9, 0x26, 0x73, 0x22, 0xeb, 0xc9, 0xde, 0xf1, 0xea, 0x81, 0xea, 0x48, 0xd4, 0x88, 0xaa,
+	0x9a, 0xa1, 0x77, 0xeb, 0x3a, 0x15, 0x44, 0x08, 0xca, 0xf2, 0x7a, 0xdc, 0x3e, 0x85, 0xfd, 0x21,
+	0x15, 0xf2, 0xb2, 0x6e, 0x0a, 0x4c, 0x7e, 0x96, 0x44, 0x48, 0xe4, 0x42, 0x27, 0xa1, 0x37, 0x92,
+	0x14, 0x96, 0xd6, 0xd7, 0x9c, 0xee, 0xe0, 0xa1, 0xdb, 0xa4, 0x72, 0x1b, 0xe6, 0x47, 0x85, 0xe2,
+	0x86, 0x65, 0x7f, 0x85, 0xdd, 0x05, 0x00, 0x1d, 0x41, 0x37, 0x27, 0x34, 0x1d, 0x45, 0xac, 0xb8,
+	0xa2, 0xbc, 0x51, 0xe9, 0x2a, 0x95, 0x2a, 0x70, 0x70, 0x81, 0x61, 0x8a, 0x07, 0x1c, 0x3d, 0x86,
+	0xed, 0x71, 0x91, 0x5c, 0xe5, 0x61, 0x46, 0xac, 0x76, 0x5f, 0x73, 0x0c, 0xbc, 0x35, 0x2e, 0x92,
+	0x4f, 0x61, 0x46, 0xec, 0x13, 0x38, 0x58, 0x34, 0x28, 0x38, 0xcb, 0x05, 0x41, 0x47, 0xb0, 0xdd,
+	0x24, 0x11, 0x96, 0xd6, 0xd7, 0x9d, 0xee, 0xc0, 0xfc, 0xd7, 0x23, 0x9e, 0x31, 0xec, 0x2f, 0xb0,
+	0x77, 0x52, 0x66, 0x1c, 0x07, 0xfe, 0x34, 0xe1, 0x53, 0xd8, 0xe0, 0x84, 0x14, 0x77, 0x39, 0x53,
+	0x00, 0x32, 0x41, 0x0f, 0x13, 0xaa, 0xec, 0xec, 0xe2, 0xaa, 0x44, 0x08, 0x36, 0x44, 0xd5, 0xd2,
+	0x55, 0x4b, 0xd5, 0xf6, 0xef, 0x36, 0xec, 0x0f, 0x19, 0xfb, 0x51, 0x72, 0x5c, 0x3d, 0x8a, 0xf8,
+	0xbf, 0xf2, 0x68, 0x00, 0x7a, 0x41, 0x23, 0x6b, 0xa3, 0xaf, 0x39, 0x7b, 0x83, 0xfe, 0x2c, 0xe0,
+	0x1d, 0x37, 0xba, 0x55, 0xb6, 0x8a, 0x8c, 0x9e, 0x81, 0xce, 0x93, 0x89, 0xb5, 0xa9, 0x6e, 0x7e,
+	0x30, 0xbb, 0xf9, 0xa2, 0x20, 0x09, 0x9d, 0xe0, 0x0a, 0x43, 0xa7, 0x00, 0x59, 0x28, 0xe3, 0xd1,
+	0x95, 0xbc, 0xe5, 0xc4, 0xea, 0x28, 0xf5, 0xe7, 0x2b, 0xd5, 0xcf, 0x2a, 0xfa, 0xe7, 0x5b, 0x4e,
+	0xb0, 0x91, 0x4d, 0x4b, 0xdb, 0x05, 0x1d, 0x07, 0x3e, 0x02, 0xe8, 0x0c, 0x59, 0x8c, 0x03, 0xdf,
+	0x6c, 0xa1, 0x1d, 0xd8, 0xfe, 0x70, 0xfd, 0x1d, 0x07, 0x7e, 0x90, 0x9b, 0x1a, 0xda, 0x05, 0xa3,
+	0x3e, 0x9d, 0x97, 0xd2, 0x6c, 0xdb, 0x0e, 0x18, 0x33, 0x1d, 0x64, 0xc0, 0xe6, 0xe9, 0x24, 0x8c,
+	0xa5, 0xd9, 0x42, 0x26, 0xec, 0x0c, 0x59, 0x9e, 0x12, 0x21, 0x15, 0x6c, 0x6a, 0xf6, 0x7b, 0x38,
+	0x58, 0x74, 0xd1, 0xbc, 0xba, 0x03, 0x1d, 0xb5, 0xfc, 0x8b, 0x6f, 0xae, 0x5a, 0xae, 0xa2, 0xe2,
+	0x06, 0x1f, 0xfc, 0x6a, 0x03, 0xf8, 0x29, 0xbf, 0x24, 0xc5, 0x98, 0xc6, 0x04, 0x9d, 0xc1, 0xce,
+	0xfc, 0x1a, 0xa1, 0x27, 0x7f, 0xd3, 0x2e, 0xaf, 0x7f, 0xef, 0xf0, 0x1e, 0xb4, 0x76, 0x61, 0xb7,
+	0xd0, 0x1b, 0x30, 0x9a, 0x7d, 0x0a, 0x72, 0xf4, 0x68, 0xc6, 0x5e, 0xdc, 0xb1, 0xde, 0x92, 0x3b,
+	0xbb, 0xf5, 0x52, 0x43, 0x6f, 0x01, 0x1a, 0xde, 0x79, 0x29, 0xd7, 0x1d, 0xae, 0x72, 0xcc, 0xfd,
+	0x31, 0xf3, 0x39, 0x96, 0x5f, 0xad, 0x77, 0x78, 0x0f, 0x3a, 0xcd, 0xe1, 0xbf, 0xfa, 0xe6, 0xad,
+	0xf9, 0xfd, 0x88, 0x3a, 0xaa, 0x75, 0xfc, 0x67, 0x00, 0x0a, 0x3a, 0x8e, 0x50, 0x23, 0x05, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -258,6 +445,7 @@ type BgpServiceClient interface {
 	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
 	DumpRIBIn(ctx context.Context, in *DumpRIBRequest, opts ...grpc.CallOption) (BgpService_DumpRIBInClient, error)
 	DumpRIBOut(ctx context.Context, in *DumpRIBRequest, opts ...grpc.CallOption) (BgpService_DumpRIBOutClient, error)
+	LookupRoutes(ctx context.Context, in *LookupRoutesRequest, opts ...grpc.CallOption) (*LookupRoutesResponse, error)
 }
 
 type bgpServiceClient struct {
@@ -341,11 +529,21 @@ func (x *bgpServiceDumpRIBOutClient) Recv() (*api1.Route, error) {
 	return m, nil
 }
 
+func (c *bgpServiceClient) LookupRoutes(ctx context.Context, in *LookupRoutesRequest, opts ...grpc.CallOption) (*LookupRoutesResponse, error) {
+	out := new(LookupRoutesResponse)
+	err := c.cc.Invoke(ctx, "/bio.bgp.BgpService/LookupRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BgpServiceServer is the server API for BgpService service.
 type BgpServiceServer interface {
 	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
 	DumpRIBIn(*DumpRIBRequest, BgpService_DumpRIBInServer) error
 	DumpRIBOut(*DumpRIBRequest, BgpService_DumpRIBOutServer) error
+	LookupRoutes(context.Context, *LookupRoutesRequest) (*LookupRoutesResponse, error)
 }
 
 func RegisterBgpServiceServer(s *grpc.Server, srv BgpServiceServer) {
@@ -412,6 +610,24 @@ func (x *bgpServiceDumpRIBOutServer) Send(m *api1.Route) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _BgpService_LookupRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BgpServiceServer).LookupRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bio.bgp.BgpService/LookupRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BgpServiceServer).LookupRoutes(ctx, req.(*LookupRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BgpService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "bio.bgp.BgpService",
 	HandlerType: (*BgpServiceServer)(nil),
@@ -420,6 +636,10 @@ var _BgpService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListSessions",
 			Handler:    _BgpService_ListSessions_Handler,
 		},
+		{
+			MethodName: "LookupRoutes",
+			Handler:    _BgpService_LookupRoutes_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
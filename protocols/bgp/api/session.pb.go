@@ -62,17 +62,19 @@ func (Session_State) EnumDescriptor() ([]byte, []int) {
 }
 
 type Session struct {
-	LocalAddress         *api.IP       `protobuf:"bytes,1,opt,name=local_address,json=localAddress,proto3" json:"local_address,omitempty"`
-	NeighborAddress      *api.IP       `protobuf:"bytes,2,opt,name=neighbor_address,json=neighborAddress,proto3" json:"neighbor_address,omitempty"`
-	LocalAsn             uint32        `protobuf:"varint,3,opt,name=local_asn,json=localAsn,proto3" json:"local_asn,omitempty"`
-	PeerAsn              uint32        `protobuf:"varint,4,opt,name=peer_asn,json=peerAsn,proto3" json:"peer_asn,omitempty"`
-	Status               Session_State `protobuf:"varint,5,opt,name=status,proto3,enum=bio.bgp.Session_State" json:"status,omitempty"`
-	Stats                *SessionStats `protobuf:"bytes,6,opt,name=stats,proto3" json:"stats,omitempty"`
-	EstablishedSince     uint64        `protobuf:"varint,7,opt,name=established_since,json=establishedSince,proto3" json:"established_since,omitempty"`
-	Description          string        `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	LocalAddress             *api.IP       `protobuf:"bytes,1,opt,name=local_address,json=localAddress,proto3" json:"local_address,omitempty"`
+	NeighborAddress          *api.IP       `protobuf:"bytes,2,opt,name=neighbor_address,json=neighborAddress,proto3" json:"neighbor_address,omitempty"`
+	LocalAsn                 uint32        `protobuf:"varint,3,opt,name=local_asn,json=localAsn,proto3" json:"local_asn,omitempty"`
+	PeerAsn                  uint32        `protobuf:"varint,4,opt,name=peer_asn,json=peerAsn,proto3" json:"peer_asn,omitempty"`
+	Status                   Session_State `protobuf:"varint,5,opt,name=status,proto3,enum=bio.bgp.Session_State" json:"status,omitempty"`
+	Stats                    *SessionStats `protobuf:"bytes,6,opt,name=stats,proto3" json:"stats,omitempty"`
+	EstablishedSince         uint64        `protobuf:"varint,7,opt,name=established_since,json=establishedSince,proto3" json:"established_since,omitempty"`
+	Description              string        `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	LastNotificationSent     *Notification `protobuf:"bytes,9,opt,name=last_notification_sent,json=lastNotificationSent,proto3" json:"last_notification_sent,omitempty"`
+	LastNotificationReceived *Notification `protobuf:"bytes,10,opt,name=last_notification_received,json=lastNotificationReceived,proto3" json:"last_notification_received,omitempty"`
+	XXX_NoUnkeyedLiteral     struct{}      `json:"-"`
+	XXX_unrecognized         []byte        `json:"-"`
+	XXX_sizecache            int32         `json:"-"`
 }
 
 func (m *Session) Reset()         { *m = Session{} }
@@ -156,6 +158,83 @@ func (m *Session) GetDescription() string {
 	return ""
 }
 
+func (m *Session) GetLastNotificationSent() *Notification {
+	if m != nil {
+		return m.LastNotificationSent
+	}
+	return nil
+}
+
+func (m *Session) GetLastNotificationReceived() *Notification {
+	if m != nil {
+		return m.LastNotificationReceived
+	}
+	return nil
+}
+
+type Notification struct {
+	ErrorCode            uint32   `protobuf:"varint,1,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorSubcode         uint32   `protobuf:"varint,2,opt,name=error_subcode,json=errorSubcode,proto3" json:"error_subcode,omitempty"`
+	Description          string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Time                 uint64   `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Notification) Reset()         { *m = Notification{} }
+func (m *Notification) String() string { return proto.CompactTextString(m) }
+func (*Notification) ProtoMessage()    {}
+func (*Notification) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5b53032c0bb76d75, []int{1}
+}
+
+func (m *Notification) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Notification.Unmarshal(m, b)
+}
+func (m *Notification) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Notification.Marshal(b, m, deterministic)
+}
+func (m *Notification) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Notification.Merge(m, src)
+}
+func (m *Notification) XXX_Size() int {
+	return xxx_messageInfo_Notification.Size(m)
+}
+func (m *Notification) XXX_DiscardUnknown() {
+	xxx_messageInfo_Notification.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Notification proto.InternalMessageInfo
+
+func (m *Notification) GetErrorCode() uint32 {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return 0
+}
+
+func (m *Notification) GetErrorSubcode() uint32 {
+	if m != nil {
+		return m.ErrorSubcode
+	}
+	return 0
+}
+
+func (m *Notification) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Notification) GetTime() uint64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
 type SessionStats struct {
 	MessagesIn           uint64   `protobuf:"varint,1,opt,name=messages_in,json=messagesIn,proto3" json:"messages_in,omitempty"`
 	MessagesOut          uint64   `protobuf:"varint,2,opt,name=messages_out,json=messagesOut,proto3" json:"messages_out,omitempty"`
@@ -172,7 +251,7 @@ func (m *SessionStats) Reset()         { *m = SessionStats{} }
 func (m *SessionStats) String() string { return proto.CompactTextString(m) }
 func (*SessionStats) ProtoMessage()    {}
 func (*SessionStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_5b53032c0bb76d75, []int{1}
+	return fileDescriptor_5b53032c0bb76d75, []int{2}
 }
 
 func (m *SessionStats) XXX_Unmarshal(b []byte) error {
@@ -238,6 +317,7 @@ func (m *SessionStats) GetRoutesExported() uint64 {
 func init() {
 	proto.RegisterEnum("bio.bgp.Session_State", Session_State_name, Session_State_value)
 	proto.RegisterType((*Session)(nil), "bio.bgp.Session")
+	proto.RegisterType((*Notification)(nil), "bio.bgp.Notification")
 	proto.RegisterType((*SessionStats)(nil), "bio.bgp.SessionStats")
 }
 
@@ -246,36 +326,42 @@ func init() {
 }
 
 var fileDescriptor_5b53032c0bb76d75 = []byte{
-	// 484 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0x86, 0x71, 0xeb, 0x38, 0xe9, 0x38, 0x69, 0xdc, 0x15, 0x20, 0x03, 0x07, 0x4c, 0x2e, 0x44,
-	0xaa, 0xb0, 0xa1, 0x48, 0xdc, 0x38, 0x94, 0xd2, 0x43, 0x4e, 0x45, 0x9b, 0x1b, 0x97, 0xc8, 0xf6,
-	0x4e, 0x9d, 0x45, 0xce, 0xae, 0xe5, 0xd9, 0x54, 0xbc, 0x1f, 0x8f, 0xc2, 0x8b, 0xa0, 0x5d, 0x3b,
-	0xc1, 0x02, 0x09, 0x89, 0xdb, 0xce, 0x3f, 0xdf, 0x3f, 0x99, 0xd1, 0x1f, 0xc3, 0xc7, 0x4a, 0x9a,
-	0xed, 0xbe, 0x48, 0x4b, 0xbd, 0xcb, 0x0a, 0xa9, 0xdf, 0xb4, 0x7a, 0x6f, 0xa4, 0xaa, 0xba, 0xb7,
-	0xc8, 0x9a, 0x56, 0x1b, 0x5d, 0xea, 0x9a, 0xb2, 0xa2, 0x6a, 0xb2, 0xbc, 0x91, 0x19, 0x21, 0x91,
-	0xd4, 0x2a, 0x75, 0x1d, 0x36, 0x2e, 0xa4, 0x4e, 0x8b, 0xaa, 0x79, 0x9e, 0xfd, 0x7b, 0x8e, 0x42,
-	0xe3, 0xdc, 0x0a, 0x4d, 0xe7, 0x5c, 0xfc, 0x38, 0x85, 0xf1, 0xba, 0x9b, 0xc5, 0xde, 0xc2, 0xac,
-	0xd6, 0x65, 0x5e, 0x6f, 0x72, 0x21, 0x5a, 0x24, 0x8a, 0xbd, 0xc4, 0x5b, 0x86, 0x57, 0x61, 0x6a,
-	0xa7, 0x5b, 0xcb, 0xea, 0x0b, 0x9f, 0x3a, 0xe2, 0xba, 0x03, 0xd8, 0x07, 0x88, 0x14, 0xca, 0x6a,
-	0x5b, 0xe8, 0xf6, 0x68, 0x3a, 0xf9, 0xdb, 0x34, 0x3f, 0x40, 0x07, 0xdf, 0x0b, 0x38, 0xeb, 0x7f,
-	0x89, 0x54, 0x7c, 0x9a, 0x78, 0xcb, 0x19, 0x9f, 0x74, 0x83, 0x49, 0xb1, 0x67, 0x30, 0x69, 0x10,
-	0x5b, 0xd7, 0xf3, 0x5d, 0x6f, 0x6c, 0x6b, 0xdb, 0x4a, 0x21, 0x20, 0x93, 0x9b, 0x3d, 0xc5, 0xa3,
-	0xc4, 0x5b, 0x9e, 0x5f, 0x3d, 0x4d, 0xfb, 0xc3, 0xd3, 0xfe, 0x86, 0x74, 0x6d, 0x72, 0x83, 0xbc,
-	0xa7, 0xd8, 0x25, 0x8c, 0xec, 0x8b, 0xe2, 0xc0, 0x2d, 0xf5, 0xe4, 0x4f, 0xdc, 0xd2, 0xc4, 0x3b,
-	0x86, 0x5d, 0xc2, 0x05, 0x92, 0xc9, 0x8b, 0x5a, 0xd2, 0x16, 0xc5, 0x86, 0xa4, 0x2a, 0x31, 0x1e,
-	0x27, 0xde, 0xd2, 0xe7, 0xd1, 0xa0, 0xb1, 0xb6, 0x3a, 0x4b, 0x20, 0x14, 0x48, 0x65, 0x2b, 0x1b,
-	0x23, 0xb5, 0x8a, 0x27, 0x89, 0xb7, 0x3c, 0xe3, 0x43, 0x69, 0xf1, 0x0d, 0x46, 0x6e, 0x19, 0x36,
-	0x85, 0xc9, 0x67, 0x49, 0x79, 0x51, 0xa3, 0x88, 0x1e, 0xb1, 0x09, 0xf8, 0x2b, 0x51, 0x63, 0xe4,
-	0xb1, 0x10, 0xc6, 0x37, 0x5a, 0x29, 0x2c, 0x4d, 0x74, 0xc2, 0x00, 0x82, 0xeb, 0xd2, 0xc8, 0x07,
-	0x8c, 0x4e, 0xad, 0xe1, 0xae, 0x41, 0xb5, 0x46, 0x65, 0x22, 0x9f, 0x5d, 0xc0, 0xcc, 0x56, 0x37,
-	0x5a, 0xdd, 0xcb, 0x76, 0x87, 0x22, 0x1a, 0xb1, 0x39, 0x84, 0xb7, 0xbf, 0x17, 0x8a, 0x82, 0xc5,
-	0x4f, 0x0f, 0xa6, 0xc3, 0x93, 0xd8, 0x4b, 0x08, 0x77, 0x48, 0x94, 0x57, 0x48, 0x1b, 0xa9, 0x5c,
-	0x90, 0x3e, 0x87, 0x83, 0xb4, 0x52, 0xec, 0x15, 0x4c, 0x8f, 0x80, 0xde, 0x1b, 0x97, 0x9a, 0xcf,
-	0x8f, 0xa6, 0xbb, 0xbd, 0x61, 0x8f, 0x61, 0x74, 0x5f, 0xe7, 0x0d, 0xb9, 0x80, 0x7c, 0xde, 0x15,
-	0xec, 0x35, 0xcc, 0xed, 0x9f, 0x0a, 0x69, 0xd3, 0x62, 0x89, 0xf2, 0x01, 0x85, 0x0b, 0xc9, 0xe7,
-	0xe7, 0x9d, 0xcc, 0x7b, 0x75, 0x00, 0xca, 0x5d, 0xa3, 0x5b, 0x83, 0xc2, 0x85, 0x76, 0x04, 0x57,
-	0xbd, 0x3a, 0x00, 0xf1, 0x7b, 0x0f, 0x06, 0x43, 0xf0, 0xb6, 0x57, 0x3f, 0xbd, 0xfb, 0x9a, 0xfd,
-	0xe7, 0x67, 0x52, 0x04, 0x4e, 0x7a, 0xff, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x87, 0xfb, 0xe6, 0x6a,
-	0x60, 0x03, 0x00, 0x00,
+	// 585 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x94, 0xdf, 0x6e, 0xd3, 0x30,
+	0x14, 0xc6, 0xc9, 0x96, 0xfe, 0x3b, 0x6d, 0xb7, 0xcc, 0x1a, 0x53, 0x18, 0x42, 0x94, 0x72, 0x41,
+	0xa5, 0x89, 0x04, 0x86, 0xc4, 0x1d, 0x17, 0x63, 0xec, 0xa2, 0x42, 0x62, 0xc8, 0xbd, 0xe3, 0xa6,
+	0xca, 0x9f, 0xb3, 0xce, 0x28, 0xb5, 0x23, 0x1f, 0x67, 0xe2, 0x11, 0x78, 0x4f, 0xc4, 0x7b, 0x20,
+	0x3b, 0xe9, 0x16, 0xb5, 0xd2, 0x24, 0xee, 0xec, 0xef, 0xfc, 0xbe, 0xd3, 0xcf, 0x3e, 0x6e, 0xe0,
+	0xd3, 0x4a, 0x98, 0xdb, 0x2a, 0x8d, 0x32, 0xb5, 0x8e, 0x53, 0xa1, 0xde, 0x6a, 0x55, 0x19, 0x21,
+	0x57, 0xf5, 0x3a, 0x8f, 0x4b, 0xad, 0x8c, 0xca, 0x54, 0x41, 0x71, 0xba, 0x2a, 0xe3, 0xa4, 0x14,
+	0x31, 0x21, 0x91, 0x50, 0x32, 0x72, 0x15, 0xd6, 0x4b, 0x85, 0x8a, 0xd2, 0x55, 0x79, 0x1a, 0x3f,
+	0xde, 0x47, 0xa2, 0x71, 0x6e, 0x89, 0xa6, 0x76, 0x4e, 0xff, 0xfa, 0xd0, 0x5b, 0xd4, 0xbd, 0xd8,
+	0x3b, 0x18, 0x17, 0x2a, 0x4b, 0x8a, 0x65, 0x92, 0xe7, 0x1a, 0x89, 0x42, 0x6f, 0xe2, 0xcd, 0x86,
+	0xe7, 0xc3, 0xc8, 0x76, 0xb7, 0x96, 0xf9, 0x77, 0x3e, 0x72, 0xc4, 0x45, 0x0d, 0xb0, 0x8f, 0x10,
+	0x48, 0x14, 0xab, 0xdb, 0x54, 0xe9, 0x7b, 0xd3, 0xde, 0xae, 0xe9, 0x70, 0x03, 0x6d, 0x7c, 0xcf,
+	0x61, 0xd0, 0xfc, 0x12, 0xc9, 0x70, 0x7f, 0xe2, 0xcd, 0xc6, 0xbc, 0x5f, 0x37, 0x26, 0xc9, 0x9e,
+	0x41, 0xbf, 0x44, 0xd4, 0xae, 0xe6, 0xbb, 0x5a, 0xcf, 0xee, 0x6d, 0x29, 0x82, 0x2e, 0x99, 0xc4,
+	0x54, 0x14, 0x76, 0x26, 0xde, 0xec, 0xe0, 0xfc, 0x24, 0x6a, 0x0e, 0x1e, 0x35, 0x67, 0x88, 0x16,
+	0x26, 0x31, 0xc8, 0x1b, 0x8a, 0x9d, 0x41, 0xc7, 0xae, 0x28, 0xec, 0xba, 0x50, 0x4f, 0xb7, 0x71,
+	0x4b, 0x13, 0xaf, 0x19, 0x76, 0x06, 0x47, 0x48, 0x26, 0x49, 0x0b, 0x41, 0xb7, 0x98, 0x2f, 0x49,
+	0xc8, 0x0c, 0xc3, 0xde, 0xc4, 0x9b, 0xf9, 0x3c, 0x68, 0x15, 0x16, 0x56, 0x67, 0x13, 0x18, 0xe6,
+	0x48, 0x99, 0x16, 0xa5, 0x11, 0x4a, 0x86, 0xfd, 0x89, 0x37, 0x1b, 0xf0, 0xb6, 0xc4, 0xbe, 0xc2,
+	0x49, 0x91, 0x90, 0x59, 0x4a, 0x65, 0xc4, 0x8d, 0xc8, 0x12, 0x2b, 0x2e, 0x09, 0xa5, 0x09, 0x07,
+	0x5b, 0x61, 0xbe, 0xb5, 0x08, 0x7e, 0x6c, 0x4d, 0x6d, 0x65, 0x81, 0xd2, 0xb0, 0x05, 0x9c, 0xee,
+	0x36, 0xd3, 0x98, 0xa1, 0xb8, 0xc3, 0x3c, 0x84, 0xc7, 0x1a, 0x86, 0xdb, 0x0d, 0x79, 0x63, 0x9b,
+	0xfe, 0x84, 0x8e, 0xbb, 0x2e, 0x36, 0x82, 0xfe, 0x17, 0x41, 0x49, 0x5a, 0x60, 0x1e, 0x3c, 0x61,
+	0x7d, 0xf0, 0xe7, 0x79, 0x81, 0x81, 0xc7, 0x86, 0xd0, 0xbb, 0x54, 0x52, 0x62, 0x66, 0x82, 0x3d,
+	0x06, 0xd0, 0xbd, 0xc8, 0x8c, 0xb8, 0xc3, 0x60, 0xdf, 0x1a, 0xae, 0x4b, 0x74, 0xd1, 0x02, 0x9f,
+	0x1d, 0xc1, 0xd8, 0xee, 0x2e, 0x95, 0xbc, 0x11, 0x7a, 0x8d, 0x79, 0xd0, 0x61, 0x87, 0x30, 0xbc,
+	0x7a, 0xb8, 0xb2, 0xa0, 0x3b, 0xfd, 0xed, 0xc1, 0xa8, 0x1d, 0x82, 0xbd, 0x00, 0x40, 0xad, 0x95,
+	0x5e, 0x66, 0x2a, 0x47, 0xf7, 0xd2, 0xc6, 0x7c, 0xe0, 0x94, 0x4b, 0x95, 0x23, 0x7b, 0x0d, 0xe3,
+	0xba, 0x4c, 0x55, 0xea, 0x88, 0x3d, 0x47, 0x8c, 0x9c, 0xb8, 0xa8, 0xb5, 0xed, 0x21, 0xec, 0xef,
+	0x0e, 0x81, 0x81, 0x6f, 0xc4, 0x1a, 0xdd, 0x3b, 0xf2, 0xb9, 0x5b, 0x4f, 0xff, 0x78, 0x30, 0x6a,
+	0xcf, 0x9f, 0xbd, 0x84, 0xe1, 0x1a, 0x89, 0x92, 0x15, 0xd2, 0x52, 0x48, 0x97, 0xc5, 0xe7, 0xb0,
+	0x91, 0xe6, 0x92, 0xbd, 0x82, 0xd1, 0x3d, 0xa0, 0x2a, 0xe3, 0xb2, 0xf8, 0xfc, 0xde, 0x74, 0x5d,
+	0x19, 0x76, 0x0c, 0x9d, 0x9b, 0x22, 0x29, 0xc9, 0x85, 0xf0, 0x79, 0xbd, 0x61, 0x6f, 0xe0, 0xd0,
+	0xfe, 0x03, 0x91, 0x1e, 0x66, 0x55, 0x27, 0x39, 0xa8, 0xe5, 0xcd, 0x28, 0x5a, 0xa0, 0x58, 0x97,
+	0x4a, 0x1b, 0xcc, 0xc3, 0x4e, 0x1b, 0x9c, 0x37, 0x6a, 0x0b, 0xc4, 0x5f, 0x0d, 0xd8, 0x6d, 0x83,
+	0x57, 0x8d, 0xfa, 0xf9, 0xfd, 0x8f, 0xf8, 0x3f, 0xbf, 0x29, 0x69, 0xd7, 0x49, 0x1f, 0xfe, 0x0d,
+	0x00, 0x22, 0x61, 0xc1, 0x9f, 0x8d, 0x04, 0x00, 0x00,
 }
@@ -13,4 +13,25 @@ type BGPAddressFamilyMetrics struct {
 
 	// RoutesAccepted is the number of routes we sent
 	RoutesSent uint64
+
+	// PrefixLimit is the maximum number of routes accepted, 0 if unlimited
+	PrefixLimit uint64
+
+	// PrefixLimitWarning is set if RoutesReceived reached a warning threshold of the prefix limit
+	PrefixLimitWarning bool
+
+	// PrefixLimitThreshold is the highest warning threshold (percentage of PrefixLimit) RoutesReceived reached, 0 if none
+	PrefixLimitThreshold uint8
+
+	// OriginValidation is set if routes received are validated against VRPs (RFC6811)
+	OriginValidation bool
+
+	// RoutesValid is the number of received routes with valid origin
+	RoutesValid uint64
+
+	// RoutesInvalid is the number of received routes with invalid origin
+	RoutesInvalid uint64
+
+	// RoutesNotFound is the number of received routes not covered by any VRP
+	RoutesNotFound uint64
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/histogram"
 )
 
 const (
@@ -45,6 +46,73 @@ type BGPPeerMetrics struct {
 	// UpdatesReceived is the number of update messages we sent on this session
 	UpdatesSent uint64
 
+	// Flaps is the number of times the session dropped out of established state
+	Flaps uint64
+
+	// PrefixLimitHits is the number of times the session was torn down for exceeding a prefix limit
+	PrefixLimitHits uint64
+
+	// MessagesReceived and MessagesSent are the numbers of messages other than updates of all sessions with the peer
+	MessagesReceived BGPMessageCounts
+	MessagesSent     BGPMessageCounts
+
+	// PrefixesAdvertisedReceived and PrefixesWithdrawnReceived are the numbers of prefixes advertised and withdrawn by
+	// the updates received from the peer
+	PrefixesAdvertisedReceived uint64
+	PrefixesWithdrawnReceived  uint64
+
+	// PrefixesAdvertisedSent and PrefixesWithdrawnSent are the numbers of prefixes advertised and withdrawn by the updates
+	// sent to the peer
+	PrefixesAdvertisedSent uint64
+	PrefixesWithdrawnSent  uint64
+
+	// MalformedAttributes is the number of updates received with malformed path attributes
+	MalformedAttributes uint64
+
+	// AttributeErrors are the numbers of malformed path attributes by the way they were handled (RFC7606)
+	AttributeErrors BGPAttributeErrorCounts
+
+	// LastNotificationSent and LastNotificationReceived are the last NOTIFICATIONs sent to and received from the peer,
+	// nil if there was none
+	LastNotificationSent     *BGPNotification
+	LastNotificationReceived *BGPNotification
+
+	// FSMTransitions is the number of state changes of the FSMs of the peer
+	FSMTransitions uint64
+
+	// RIBLatency is the time from receipt of an UPDATE until it has been processed by Loc-RIB, FIB and adj-RIBs-out
+	RIBLatency histogram.Snapshot
+
+	// AdjRIBOutLatency is the time from a route entering adj-RIB-out until it is sent to the peer
+	AdjRIBOutLatency histogram.Snapshot
+
 	// AddressFamilies provides metrics on AFI/SAFI level
 	AddressFamilies []*BGPAddressFamilyMetrics
 }
+
+// BGPMessageCounts are the numbers of BGP messages by type
+type BGPMessageCounts struct {
+	Opens          uint64
+	Keepalives     uint64
+	Notifications  uint64
+	RouteRefreshes uint64
+}
+
+// BGPAttributeErrorCounts are the numbers of malformed path attributes by error handling action
+type BGPAttributeErrorCounts struct {
+	TreatAsWithdraw  uint64
+	AttributeDiscard uint64
+	SessionReset     uint64
+}
+
+// BGPNotification is a NOTIFICATION sent or received
+type BGPNotification struct {
+	ErrorCode    uint8
+	ErrorSubcode uint8
+
+	// Description is human readable including decoded data such as the offending attribute or a shutdown communication
+	Description string
+
+	// Time is when the NOTIFICATION was sent or received
+	Time time.Time
+}
@@ -20,10 +20,15 @@ const (
 	IPv6Len           = 16
 	ClusterIDLen      = 4
 
+	ExtendedCommunityLen  = 8
+	RouteDistinguisherLen = 8
+	LabelLen              = 3
+
 	OpenMsg         = 1
 	UpdateMsg       = 2
 	NotificationMsg = 3
 	KeepaliveMsg    = 4
+	RouteRefreshMsg = 5
 
 	MessageHeaderError      = 1
 	OpenMessageError        = 2
@@ -77,6 +82,9 @@ const (
 	AS4AggregatorAttr    = 18
 	LargeCommunitiesAttr = 32
 
+	// ExtendedCommunitiesAttr is the EXTENDED_COMMUNITIES attribute (RFC4360)
+	ExtendedCommunitiesAttr = 16
+
 	// ORIGIN values
 	IGP        = 0
 	EGP        = 1
@@ -95,6 +103,8 @@ const (
 	IPv4AFI                      = 1
 	IPv6AFI                      = 2
 	UnicastSAFI                  = 1
+	LabeledUnicastSAFI           = 4
+	MPLSVPNSAFI                  = 128
 	CapabilitiesParamType        = 2
 	MultiProtocolCapabilityCode  = 1
 	MultiProtocolReachNLRICode   = 14
@@ -118,6 +128,9 @@ type BGPError struct {
 	ErrorCode    uint8
 	ErrorSubCode uint8
 	ErrorStr     string
+
+	// Data is sent as data of the NOTIFICATION, e.g. the erroneous attribute
+	Data []byte
 }
 
 func (b BGPError) Error() string {
@@ -146,6 +159,7 @@ type BGPOpen struct {
 type BGPNotification struct {
 	ErrorCode    uint8
 	ErrorSubcode uint8
+	Data         []byte
 }
 
 type PathAttribute struct {
@@ -166,6 +180,8 @@ func AFIName(afi uint16) string {
 		return "IPv4"
 	case IPv6AFI:
 		return "IPv6"
+	case LinkStateAFI:
+		return "BGP-LS"
 	default:
 		return "Unknown AFI"
 	}
@@ -2,9 +2,14 @@ package packet
 
 // DecodeOptions represents options for the BGP message decoder
 type DecodeOptions struct {
-	AddPathIPv4Unicast bool
-	AddPathIPv6Unicast bool
-	Use32BitASN        bool
+	AddPathIPv4Unicast        bool
+	AddPathIPv6Unicast        bool
+	AddPathIPv4LabeledUnicast bool
+	AddPathIPv6LabeledUnicast bool
+	Use32BitASN               bool
+
+	// RevisedErrorHandling handles malformed path attributes according to RFC7606 instead of failing to decode the update
+	RevisedErrorHandling bool
 }
 
 func (d *DecodeOptions) addPath(afi int, safi int) bool {
@@ -13,11 +18,15 @@ func (d *DecodeOptions) addPath(afi int, safi int) bool {
 		switch safi {
 		case UnicastSAFI:
 			return d.AddPathIPv4Unicast
+		case LabeledUnicastSAFI:
+			return d.AddPathIPv4LabeledUnicast
 		}
 	case IPv6AFI:
 		switch safi {
 		case UnicastSAFI:
 			return d.AddPathIPv6Unicast
+		case LabeledUnicastSAFI:
+			return d.AddPathIPv6LabeledUnicast
 		}
 	}
 
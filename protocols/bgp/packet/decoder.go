@@ -3,10 +3,11 @@ package packet
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -41,7 +42,11 @@ func decodeMsgBody(buf *bytes.Buffer, msgType uint8, l uint16, opt *DecodeOption
 	case KeepaliveMsg:
 		return nil, nil // Nothing to decode in Keepalive message
 	case NotificationMsg:
-		return decodeNotificationMsg(buf)
+		return decodeNotificationMsg(buf, l)
+	case RouteRefreshMsg:
+		return decodeRouteRefreshMsg(buf, l)
+	case CapabilityMsg:
+		return decodeCapabilityMsg(buf, l)
 	}
 	return nil, fmt.Errorf("Unknown message type: %d", msgType)
 }
@@ -64,7 +69,11 @@ func decodeUpdateMsg(buf *bytes.Buffer, l uint16, opt *DecodeOptions) (*BGPUpdat
 		return msg, err
 	}
 
-	msg.PathAttributes, err = decodePathAttrs(buf, msg.TotalPathAttrLen, opt)
+	if opt.RevisedErrorHandling {
+		msg.PathAttributes, msg.AttributeErrors, err = decodePathAttrsRevised(buf, msg.TotalPathAttrLen, opt)
+	} else {
+		msg.PathAttributes, err = decodePathAttrs(buf, msg.TotalPathAttrLen, opt)
+	}
 	if err != nil {
 		return msg, err
 	}
@@ -77,10 +86,17 @@ func decodeUpdateMsg(buf *bytes.Buffer, l uint16, opt *DecodeOptions) (*BGPUpdat
 		}
 	}
 
+	if opt.RevisedErrorHandling {
+		msg.checkMandatoryAttrs()
+		if msg.TreatAsWithdraw() {
+			msg.convertToWithdraw()
+		}
+	}
+
 	return msg, nil
 }
 
-func decodeNotificationMsg(buf *bytes.Buffer) (*BGPNotification, error) {
+func decodeNotificationMsg(buf *bytes.Buffer, l uint16) (*BGPNotification, error) {
 	msg := &BGPNotification{}
 
 	fields := []interface{}{
@@ -93,7 +109,15 @@ func decodeNotificationMsg(buf *bytes.Buffer) (*BGPNotification, error) {
 		return msg, err
 	}
 
-	if msg.ErrorCode > Cease {
+	if l > 2 {
+		msg.Data = make([]byte, l-2)
+		_, err = io.ReadFull(buf, msg.Data)
+		if err != nil {
+			return msg, errors.Wrap(err, "Unable to read data")
+		}
+	}
+
+	if msg.ErrorCode > RouteRefreshMessageError {
 		return msg, fmt.Errorf("Invalid error code: %d", msg.ErrorSubcode)
 	}
 
@@ -123,6 +147,10 @@ func decodeNotificationMsg(buf *bytes.Buffer) (*BGPNotification, error) {
 		if msg.ErrorSubcode > OutOfResources {
 			return invalidErrCode(msg)
 		}
+	case RouteRefreshMessageError:
+		if msg.ErrorSubcode != InvalidRouteRefreshMessageLength {
+			return invalidErrCode(msg)
+		}
 	default:
 		return invalidErrCode(msg)
 	}
@@ -257,6 +285,34 @@ func decodeCapability(buf *bytes.Buffer) (Capability, error) {
 			return cap, errors.Wrap(err, "Unable to decode 4 octet ASN capability")
 		}
 		cap.Value = asn4Cap
+	case GracefulRestartCapabilityCode:
+		grCap, err := decodeGracefulRestartCapability(buf, cap.Length)
+		if err != nil {
+			return cap, errors.Wrap(err, "Unable to decode graceful restart capability")
+		}
+		cap.Value = grCap
+	case ExtendedNextHopCapabilityCode:
+		enhCap, err := decodeExtendedNextHopCapability(buf, cap.Length)
+		if err != nil {
+			return cap, errors.Wrap(err, "Unable to decode extended next hop capability")
+		}
+		cap.Value = enhCap
+	case RouteRefreshCapabilityCode:
+		if cap.Length != 0 {
+			return cap, fmt.Errorf("Invalid route refresh capability length %d", cap.Length)
+		}
+		cap.Value = RouteRefreshCapability{}
+	case EnhancedRouteRefreshCapabilityCode:
+		if cap.Length != 0 {
+			return cap, fmt.Errorf("Invalid enhanced route refresh capability length %d", cap.Length)
+		}
+		cap.Value = EnhancedRouteRefreshCapability{}
+	case DynamicCapabilityCode:
+		dynCap, err := decodeDynamicCapability(buf, cap.Length)
+		if err != nil {
+			return cap, errors.Wrap(err, "Unable to decode dynamic capability")
+		}
+		cap.Value = dynCap
 	default:
 		for i := uint8(0); i < cap.Length; i++ {
 			_, err := buf.ReadByte()
@@ -309,6 +365,66 @@ func decodeAddPathCapability(buf *bytes.Buffer, capLength uint8) (AddPathCapabil
 	return addPathCaps, nil
 }
 
+func decodeGracefulRestartCapability(buf *bytes.Buffer, capLength uint8) (GracefulRestartCapability, error) {
+	grCap := GracefulRestartCapability{}
+
+	if capLength < 2 || (capLength-2)%gracefulRestartTupleSize != 0 {
+		return grCap, fmt.Errorf("Invalid caplength %d", capLength)
+	}
+
+	flagsTime := uint16(0)
+	err := decode.DecodeUint16(buf, &flagsTime)
+	if err != nil {
+		return grCap, err
+	}
+
+	grCap.RestartState = flagsTime&gracefulRestartStateFlag != 0
+	grCap.RestartTime = flagsTime & MaxGracefulRestartTime
+
+	for capLength -= 2; capLength >= gracefulRestartTupleSize; capLength -= gracefulRestartTupleSize {
+		t := GracefulRestartCapabilityTuple{}
+		flags := uint8(0)
+		fields := []interface{}{
+			&t.AFI,
+			&t.SAFI,
+			&flags,
+		}
+		err := decode.Decode(buf, fields)
+		if err != nil {
+			return grCap, err
+		}
+
+		t.ForwardingState = flags&gracefulRestartForwardingStateFlag != 0
+		grCap.Tuples = append(grCap.Tuples, t)
+	}
+
+	return grCap, nil
+}
+
+func decodeExtendedNextHopCapability(buf *bytes.Buffer, capLength uint8) (ExtendedNextHopCapability, error) {
+	if capLength%extendedNextHopTupleSize != 0 {
+		return nil, fmt.Errorf("Invalid caplength %d", capLength)
+	}
+
+	enhCap := make(ExtendedNextHopCapability, 0, capLength/extendedNextHopTupleSize)
+	for ; capLength > 0; capLength -= extendedNextHopTupleSize {
+		t := ExtendedNextHopCapabilityTuple{}
+		fields := []interface{}{
+			&t.AFI,
+			&t.SAFI,
+			&t.NextHopAFI,
+		}
+		err := decode.Decode(buf, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		enhCap = append(enhCap, t)
+	}
+
+	return enhCap, nil
+}
+
 func decodeASN4Capability(buf *bytes.Buffer) (ASN4Capability, error) {
 	asn4Cap := ASN4Capability{}
 	fields := []interface{}{
@@ -343,7 +459,8 @@ func validateOpen(msg *BGPOpen) error {
 }
 
 func isValidIdentifier(id uint32) bool {
-	addr := net.IP(convert.Uint32Byte(id))
+	b := endian.Uint32Array(id)
+	addr := net.IP(b[:])
 	if addr.IsLoopback() {
 		return false
 	}
@@ -411,7 +528,7 @@ func decodeHeader(buf *bytes.Buffer) (*BGPHeader, error) {
 		}
 	}
 
-	if hdr.Type > KeepaliveMsg || hdr.Type == 0 {
+	if hdr.Type > CapabilityMsg || hdr.Type == 0 {
 		return hdr, BGPError{
 			ErrorCode:    MessageHeaderError,
 			ErrorSubCode: BadMessageType,
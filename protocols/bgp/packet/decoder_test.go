@@ -9,7 +9,7 @@ import (
 	"github.com/bio-routing/bio-rd/net"
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/types"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -393,7 +393,7 @@ func TestDecodeNotificationMsg(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		res, err := decodeNotificationMsg(bytes.NewBuffer(test.input))
+		res, err := decodeNotificationMsg(bytes.NewBuffer(test.input), uint16(len(test.input)))
 
 		if test.wantFail {
 			if err != nil {
@@ -1238,9 +1238,57 @@ func TestDecodeMsgBody(t *testing.T) {
 	}{
 		{
 			name:     "Unknown msgType",
-			msgType:  5,
+			msgType:  7,
 			wantFail: true,
 		},
+		{
+			name:    "Route refresh",
+			buffer:  bytes.NewBuffer([]byte{0, 2, 1, 1}),
+			msgType: RouteRefreshMsg,
+			length:  4,
+			expected: &BGPRouteRefresh{
+				AFI:     IPv6AFI,
+				Subtype: BeginningOfRouteRefresh,
+				SAFI:    UnicastSAFI,
+			},
+		},
+		{
+			name:     "Route refresh with invalid length",
+			buffer:   bytes.NewBuffer([]byte{0, 2, 1, 1, 0}),
+			msgType:  RouteRefreshMsg,
+			length:   5,
+			wantFail: true,
+			expected: (*BGPRouteRefresh)(nil),
+		},
+		{
+			name:    "Capability",
+			buffer:  bytes.NewBuffer([]byte{1, 1, 4, 0, 2, 0, 1}),
+			msgType: CapabilityMsg,
+			length:  7,
+			expected: &BGPCapability{
+				Changes: []CapabilityChange{
+					{
+						Action: DynamicCapabilityRemove,
+						Capability: Capability{
+							Code:   MultiProtocolCapabilityCode,
+							Length: 4,
+							Value: MultiProtocolCapability{
+								AFI:  IPv6AFI,
+								SAFI: UnicastSAFI,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "Capability with invalid action",
+			buffer:   bytes.NewBuffer([]byte{2, 1, 4, 0, 2, 0, 1}),
+			msgType:  CapabilityMsg,
+			length:   7,
+			wantFail: true,
+			expected: (*BGPCapability)(nil),
+		},
 	}
 
 	for _, test := range tests {
@@ -1317,9 +1365,9 @@ func TestDecodeHeader(t *testing.T) {
 			},
 		},
 		{
-			// Invalid message type 5
+			// Invalid message type 7
 			testNum:  4,
-			input:    []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 19, 5},
+			input:    []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 19, 7},
 			wantFail: true,
 			expected: &BGPHeader{
 				Length: 19,
@@ -1422,27 +1470,27 @@ func TestIsValidIdentifier(t *testing.T) {
 	}{
 		{
 			name:     "Valid #1",
-			input:    convert.Uint32b([]byte{8, 8, 8, 8}),
+			input:    endian.Uint32([]byte{8, 8, 8, 8}),
 			expected: true,
 		},
 		{
 			name:     "Multicast",
-			input:    convert.Uint32b([]byte{239, 8, 8, 8}),
+			input:    endian.Uint32([]byte{239, 8, 8, 8}),
 			expected: false,
 		},
 		{
 			name:     "Loopback",
-			input:    convert.Uint32b([]byte{127, 8, 8, 8}),
+			input:    endian.Uint32([]byte{127, 8, 8, 8}),
 			expected: false,
 		},
 		{
 			name:     "First byte 0",
-			input:    convert.Uint32b([]byte{0, 8, 8, 8}),
+			input:    endian.Uint32([]byte{0, 8, 8, 8}),
 			expected: false,
 		},
 		{
 			name:     "All bytes 255",
-			input:    convert.Uint32b([]byte{255, 255, 255, 255}),
+			input:    endian.Uint32([]byte{255, 255, 255, 255}),
 			expected: false,
 		},
 	}
@@ -1463,7 +1511,7 @@ func TestValidateOpenMessage(t *testing.T) {
 			name: "Valid #1",
 			input: &BGPOpen{
 				Version:       4,
-				BGPIdentifier: convert.Uint32b([]byte{8, 8, 8, 8}),
+				BGPIdentifier: endian.Uint32([]byte{8, 8, 8, 8}),
 			},
 			wantFail: false,
 		},
@@ -1471,7 +1519,7 @@ func TestValidateOpenMessage(t *testing.T) {
 			name: "Invalid Identifier",
 			input: &BGPOpen{
 				Version:       4,
-				BGPIdentifier: convert.Uint32b([]byte{0, 8, 8, 8}),
+				BGPIdentifier: endian.Uint32([]byte{0, 8, 8, 8}),
 			},
 			wantFail: true,
 		},
@@ -1635,6 +1683,38 @@ func TestDecodeCapability(t *testing.T) {
 			},
 			wantFail: false,
 		},
+		{
+			name:  "Route Refresh",
+			input: []byte{2, 0},
+			expected: Capability{
+				Code:  RouteRefreshCapabilityCode,
+				Value: RouteRefreshCapability{},
+			},
+		},
+		{
+			name:  "Enhanced Route Refresh",
+			input: []byte{70, 0},
+			expected: Capability{
+				Code:  EnhancedRouteRefreshCapabilityCode,
+				Value: EnhancedRouteRefreshCapability{},
+			},
+		},
+		{
+			name:     "Route Refresh with invalid length",
+			input:    []byte{2, 1, 0},
+			wantFail: true,
+		},
+		{
+			name:  "Dynamic Capability",
+			input: []byte{67, 1, 1},
+			expected: Capability{
+				Code:   DynamicCapabilityCode,
+				Length: 1,
+				Value: DynamicCapability{
+					Codes: []uint8{MultiProtocolCapabilityCode},
+				},
+			},
+		},
 		{
 			name:  "MP Capability (IPv6)",
 			input: []byte{1, 4, 0, 2, 0, 1},
@@ -1721,3 +1801,130 @@ func TestDecodeAddPathCapability(t *testing.T) {
 		assert.Equal(t, test.expected, cap)
 	}
 }
+
+func TestDecodeGracefulRestartCapability(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected GracefulRestartCapability
+		wantFail bool
+	}{
+		{
+			name:  "Restarting with IPv4 and IPv6 unicast",
+			input: []byte{0x80, 120, 0, 1, 1, 0x80, 0, 2, 1, 0},
+			expected: GracefulRestartCapability{
+				RestartState: true,
+				RestartTime:  120,
+				Tuples: []GracefulRestartCapabilityTuple{
+					{
+						AFI:             IPv4AFI,
+						SAFI:            UnicastSAFI,
+						ForwardingState: true,
+					},
+					{
+						AFI:  IPv6AFI,
+						SAFI: UnicastSAFI,
+					},
+				},
+			},
+		},
+		{
+			name:  "Helper without address families",
+			input: []byte{0x0f, 0xff},
+			expected: GracefulRestartCapability{
+				RestartTime: MaxGracefulRestartTime,
+			},
+		},
+		{
+			name:     "Incomplete tuple",
+			input:    []byte{0, 120, 0, 1, 1},
+			wantFail: true,
+		},
+		{
+			name:     "Empty",
+			input:    []byte{},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		buf := bytes.NewBuffer(test.input)
+		cap, err := decodeGracefulRestartCapability(buf, uint8(len(test.input)))
+		if err != nil {
+			if test.wantFail {
+				continue
+			}
+
+			t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+			continue
+		}
+
+		if test.wantFail {
+			t.Errorf("Unexpected success for test %q", test.name)
+			continue
+		}
+
+		assert.Equal(t, test.expected, cap, test.name)
+
+		serialized := bytes.NewBuffer(nil)
+		cap.serialize(serialized)
+		assert.Equal(t, test.input, serialized.Bytes(), test.name)
+	}
+}
+
+func TestDecodeExtendedNextHopCapability(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected ExtendedNextHopCapability
+		wantFail bool
+	}{
+		{
+			name:  "IPv4 unicast and VPN via IPv6",
+			input: []byte{0, 1, 0, 1, 0, 2, 0, 1, 0, 128, 0, 2},
+			expected: ExtendedNextHopCapability{
+				{
+					AFI:        IPv4AFI,
+					SAFI:       UnicastSAFI,
+					NextHopAFI: IPv6AFI,
+				},
+				{
+					AFI:        IPv4AFI,
+					SAFI:       MPLSVPNSAFI,
+					NextHopAFI: IPv6AFI,
+				},
+			},
+		},
+		{
+			name:     "Incomplete tuple",
+			input:    []byte{0, 1, 0, 1, 0},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		buf := bytes.NewBuffer(test.input)
+		cap, err := decodeExtendedNextHopCapability(buf, uint8(len(test.input)))
+		if err != nil {
+			if test.wantFail {
+				continue
+			}
+
+			t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+			continue
+		}
+
+		if test.wantFail {
+			t.Errorf("Unexpected success for test %q", test.name)
+			continue
+		}
+
+		assert.Equal(t, test.expected, cap, test.name)
+		assert.True(t, cap.Supports(IPv4AFI, UnicastSAFI, IPv6AFI), test.name)
+		assert.False(t, cap.Supports(IPv6AFI, UnicastSAFI, IPv4AFI), test.name)
+
+		serialized := bytes.NewBuffer(nil)
+		cap.serialize(serialized)
+		assert.Equal(t, test.input, serialized.Bytes(), test.name)
+	}
+}
@@ -0,0 +1,283 @@
+package packet
+
+import (
+	"fmt"
+	"strings"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+// Dump returns a human readable multi line representation of a decoded message
+func (m *BGPMessage) Dump() string {
+	b := &strings.Builder{}
+
+	switch body := m.Body.(type) {
+	case *BGPOpen:
+		fmt.Fprintf(b, "OPEN (length %d)\n", m.Header.Length)
+		body.dump(b)
+	case *BGPUpdate:
+		fmt.Fprintf(b, "UPDATE (length %d)\n", m.Header.Length)
+		body.dump(b)
+	case *BGPNotification:
+		fmt.Fprintf(b, "NOTIFICATION (length %d)\n", m.Header.Length)
+		fmt.Fprintf(b, "  Error code: %d, subcode: %d\n", body.ErrorCode, body.ErrorSubcode)
+	case *BGPRouteRefresh:
+		fmt.Fprintf(b, "ROUTE-REFRESH (length %d)\n", m.Header.Length)
+		fmt.Fprintf(b, "  %s: %s\n", routeRefreshSubtypeName(body.Subtype), afiSAFIName(body.AFI, body.SAFI))
+	case *BGPCapability:
+		fmt.Fprintf(b, "CAPABILITY (length %d)\n", m.Header.Length)
+		for _, c := range body.Changes {
+			fmt.Fprintf(b, "  %s: %s\n", capabilityActionName(c.Action), c.Capability.dump())
+		}
+	default:
+		if m.Header.Type == KeepaliveMsg {
+			fmt.Fprintf(b, "KEEPALIVE (length %d)\n", m.Header.Length)
+			break
+		}
+
+		fmt.Fprintf(b, "Message type %d (length %d)\n", m.Header.Type, m.Header.Length)
+	}
+
+	return b.String()
+}
+
+func (o *BGPOpen) dump(b *strings.Builder) {
+	fmt.Fprintf(b, "  Version: %d\n", o.Version)
+	fmt.Fprintf(b, "  ASN: %d\n", o.ASN)
+	fmt.Fprintf(b, "  Hold time: %d\n", o.HoldTime)
+	fmt.Fprintf(b, "  BGP identifier: %s\n", bnet.IPv4(o.BGPIdentifier).Ptr().String())
+
+	for _, p := range o.OptParams {
+		caps, ok := p.Value.(Capabilities)
+		if !ok {
+			fmt.Fprintf(b, "  Optional parameter type %d\n", p.Type)
+			continue
+		}
+
+		for _, c := range caps {
+			fmt.Fprintf(b, "  Capability: %s\n", c.dump())
+		}
+	}
+}
+
+func (c Capability) dump() string {
+	switch v := c.Value.(type) {
+	case MultiProtocolCapability:
+		return fmt.Sprintf("multiprotocol %s", afiSAFIName(v.AFI, v.SAFI))
+	case AddPathCapability:
+		tuples := make([]string, 0, len(v))
+		for _, t := range v {
+			tuples = append(tuples, fmt.Sprintf("%s %s", afiSAFIName(t.AFI, t.SAFI), addPathModeName(t.SendReceive)))
+		}
+
+		return fmt.Sprintf("add-path %s", strings.Join(tuples, ", "))
+	case ASN4Capability:
+		return fmt.Sprintf("4-octet ASN %d", v.ASN4)
+	case GracefulRestartCapability:
+		families := make([]string, 0, len(v.Tuples))
+		for _, t := range v.Tuples {
+			f := afiSAFIName(t.AFI, t.SAFI)
+			if t.ForwardingState {
+				f += " (forwarding state preserved)"
+			}
+			families = append(families, f)
+		}
+
+		restarting := ""
+		if v.RestartState {
+			restarting = " restarting"
+		}
+
+		return fmt.Sprintf("graceful restart%s, restart time %ds: %s", restarting, v.RestartTime, strings.Join(families, ", "))
+	case RouteRefreshCapability:
+		return "route refresh"
+	case EnhancedRouteRefreshCapability:
+		return "enhanced route refresh"
+	case DynamicCapability:
+		codes := make([]string, 0, len(v.Codes))
+		for _, c := range v.Codes {
+			codes = append(codes, fmt.Sprintf("%d", c))
+		}
+
+		return fmt.Sprintf("dynamic capability %s", strings.Join(codes, ", "))
+	}
+
+	return fmt.Sprintf("code %d (length %d)", c.Code, c.Length)
+}
+
+func (u *BGPUpdate) dump(b *strings.Builder) {
+	for n := u.WithdrawnRoutes; n != nil; n = n.Next {
+		fmt.Fprintf(b, "  Withdrawn: %s\n", n.dump())
+	}
+
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		fmt.Fprintf(b, "  %s\n", pa.dump())
+	}
+
+	for n := u.NLRI; n != nil; n = n.Next {
+		fmt.Fprintf(b, "  NLRI: %s\n", n.dump())
+	}
+}
+
+func (n *NLRI) dump() string {
+	ret := n.Prefix.String()
+	if n.RouteDistinguisher != 0 {
+		ret = fmt.Sprintf("%d:%d:%s", n.RouteDistinguisher>>32, uint32(n.RouteDistinguisher), ret)
+	}
+
+	if len(n.Labels) > 0 {
+		labels := make([]string, len(n.Labels))
+		for i, l := range n.Labels {
+			labels[i] = fmt.Sprintf("%d", l)
+		}
+
+		ret = fmt.Sprintf("%s label %s", ret, strings.Join(labels, "/"))
+	}
+
+	if n.PathIdentifier != 0 {
+		return fmt.Sprintf("%s (path ID %d)", ret, n.PathIdentifier)
+	}
+
+	return ret
+}
+
+func (pa *PathAttribute) dump() string {
+	switch v := pa.Value.(type) {
+	case *types.ASPath:
+		return fmt.Sprintf("AS path: %s", v.String())
+	case *bnet.IP:
+		return fmt.Sprintf("Next hop: %s", v.String())
+	case *types.Communities:
+		return fmt.Sprintf("Communities: %s", v.String())
+	case *types.LargeCommunities:
+		return fmt.Sprintf("Large communities: %s", v.String())
+	case *types.ExtendedCommunities:
+		return fmt.Sprintf("Extended communities: %s", v.String())
+	case *types.ClusterList:
+		return fmt.Sprintf("Cluster list: %s", v.String())
+	case LinkStateAttribute:
+		return fmt.Sprintf("BGP-LS attribute: %d TLVs", len(v))
+	case TunnelEncapsulation:
+		return fmt.Sprintf("Tunnel encapsulation: %d tunnels", len(v))
+	case types.Aggregator:
+		return fmt.Sprintf("Aggregator: AS%d %s", v.ASN, bnet.IPv4(v.Address).Ptr().String())
+	case MultiProtocolReachNLRI:
+		nlris := make([]string, 0)
+		for n := v.NLRI; n != nil; n = n.Next {
+			nlris = append(nlris, n.dump())
+		}
+		for _, n := range v.LinkState {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.RouteTargets {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.FlowSpec {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.SRPolicies {
+			nlris = append(nlris, n.String())
+		}
+
+		if v.NextHop == nil {
+			return fmt.Sprintf("MP reach %s: NLRI %s", afiSAFIName(v.AFI, v.SAFI), strings.Join(nlris, ", "))
+		}
+
+		return fmt.Sprintf("MP reach %s: next hop %s, NLRI %s", afiSAFIName(v.AFI, v.SAFI), v.NextHop.String(), strings.Join(nlris, ", "))
+	case MultiProtocolUnreachNLRI:
+		nlris := make([]string, 0)
+		for n := v.NLRI; n != nil; n = n.Next {
+			nlris = append(nlris, n.dump())
+		}
+		for _, n := range v.LinkState {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.RouteTargets {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.FlowSpec {
+			nlris = append(nlris, n.String())
+		}
+		for _, n := range v.SRPolicies {
+			nlris = append(nlris, n.String())
+		}
+
+		return fmt.Sprintf("MP unreach %s: %s", afiSAFIName(v.AFI, v.SAFI), strings.Join(nlris, ", "))
+	}
+
+	switch pa.TypeCode {
+	case OriginAttr:
+		return fmt.Sprintf("Origin: %s", originName(pa.Value.(uint8)))
+	case MEDAttr:
+		return fmt.Sprintf("MED: %d", pa.Value.(uint32))
+	case LocalPrefAttr:
+		return fmt.Sprintf("Local pref: %d", pa.Value.(uint32))
+	case AtomicAggrAttr:
+		return "Atomic aggregate"
+	case OriginatorIDAttr:
+		return fmt.Sprintf("Originator ID: %s", bnet.IPv4(pa.Value.(uint32)).Ptr().String())
+	}
+
+	return fmt.Sprintf("Attribute type %d (length %d): %v", pa.TypeCode, pa.Length, pa.Value)
+}
+
+func originName(o uint8) string {
+	switch o {
+	case IGP:
+		return "IGP"
+	case EGP:
+		return "EGP"
+	case INCOMPLETE:
+		return "incomplete"
+	}
+
+	return fmt.Sprintf("unknown (%d)", o)
+}
+
+func afiSAFIName(afi uint16, safi uint8) string {
+	switch safi {
+	case UnicastSAFI:
+		return fmt.Sprintf("%s unicast", AFIName(afi))
+	case LabeledUnicastSAFI:
+		return fmt.Sprintf("%s labeled unicast", AFIName(afi))
+	case MPLSVPNSAFI:
+		return fmt.Sprintf("%s VPN", AFIName(afi))
+	case LinkStateSAFI:
+		return "BGP-LS"
+	case RouteTargetConstraintSAFI:
+		return "route target constraint"
+	case FlowSpecSAFI:
+		return fmt.Sprintf("%s FlowSpec", AFIName(afi))
+	case SRPolicySAFI:
+		return fmt.Sprintf("%s SR Policy", AFIName(afi))
+	}
+
+	return fmt.Sprintf("%s SAFI %d", AFIName(afi), safi)
+}
+
+func routeRefreshSubtypeName(s uint8) string {
+	switch s {
+	case RouteRefreshRequest:
+		return "Request"
+	case BeginningOfRouteRefresh:
+		return "Beginning of route refresh"
+	case EndOfRouteRefresh:
+		return "End of route refresh"
+	}
+
+	return fmt.Sprintf("Subtype %d", s)
+}
+
+func addPathModeName(m uint8) string {
+	switch m {
+	case AddPathReceive:
+		return "receive"
+	case AddPathSend:
+		return "send"
+	case AddPathSendReceive:
+		return "send/receive"
+	}
+
+	return fmt.Sprintf("unknown (%d)", m)
+}
@@ -0,0 +1,109 @@
+package packet
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *BGPMessage
+		expected string
+	}{
+		{
+			name: "Keepalive",
+			msg: &BGPMessage{
+				Header: &BGPHeader{Length: 19, Type: KeepaliveMsg},
+			},
+			expected: "KEEPALIVE (length 19)\n",
+		},
+		{
+			name: "Notification",
+			msg: &BGPMessage{
+				Header: &BGPHeader{Length: 21, Type: NotificationMsg},
+				Body:   &BGPNotification{ErrorCode: Cease, ErrorSubcode: AdminShut},
+			},
+			expected: "NOTIFICATION (length 21)\n  Error code: 6, subcode: 2\n",
+		},
+		{
+			name: "Open",
+			msg: &BGPMessage{
+				Header: &BGPHeader{Length: 37, Type: OpenMsg},
+				Body: &BGPOpen{
+					Version:       4,
+					ASN:           ASTransASN,
+					HoldTime:      90,
+					BGPIdentifier: bnet.IPv4FromOctets(10, 0, 0, 1).Ptr().ToUint32(),
+					OptParams: []OptParam{
+						{
+							Type: CapabilitiesParamType,
+							Value: Capabilities{
+								{Code: ASN4CapabilityCode, Value: ASN4Capability{ASN4: 4200000000}},
+								{Code: MultiProtocolCapabilityCode, Value: MultiProtocolCapability{AFI: IPv6AFI, SAFI: UnicastSAFI}},
+								{Code: AddPathCapabilityCode, Value: AddPathCapability{{AFI: IPv4AFI, SAFI: UnicastSAFI, SendReceive: AddPathSendReceive}}},
+								{Code: GracefulRestartCapabilityCode, Value: GracefulRestartCapability{RestartState: true, RestartTime: 120, Tuples: []GracefulRestartCapabilityTuple{{AFI: IPv4AFI, SAFI: UnicastSAFI, ForwardingState: true}}}},
+							},
+						},
+					},
+				},
+			},
+			expected: "OPEN (length 37)\n" +
+				"  Version: 4\n" +
+				"  ASN: 23456\n" +
+				"  Hold time: 90\n" +
+				"  BGP identifier: 10.0.0.1\n" +
+				"  Capability: 4-octet ASN 4200000000\n" +
+				"  Capability: multiprotocol IPv6 unicast\n" +
+				"  Capability: add-path IPv4 unicast send/receive\n" +
+				"  Capability: graceful restart restarting, restart time 120s: IPv4 unicast (forwarding state preserved)\n",
+		},
+		{
+			name: "Update",
+			msg: &BGPMessage{
+				Header: &BGPHeader{Length: 60, Type: UpdateMsg},
+				Body: &BGPUpdate{
+					WithdrawnRoutes: &NLRI{
+						Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+					},
+					PathAttributes: &PathAttribute{
+						TypeCode: OriginAttr,
+						Value:    uint8(IGP),
+						Next: &PathAttribute{
+							TypeCode: ASPathAttr,
+							Value: &types.ASPath{
+								{Type: types.ASSequence, ASNs: []uint32{65001, 65002}},
+							},
+							Next: &PathAttribute{
+								TypeCode: NextHopAttr,
+								Value:    bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+								Next: &PathAttribute{
+									TypeCode: LocalPrefAttr,
+									Value:    uint32(200),
+								},
+							},
+						},
+					},
+					NLRI: &NLRI{
+						PathIdentifier: 7,
+						Prefix:         bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24).Ptr(),
+					},
+				},
+			},
+			expected: "UPDATE (length 60)\n" +
+				"  Withdrawn: 192.0.2.0/24\n" +
+				"  Origin: IGP\n" +
+				"  AS path: 65001 65002\n" +
+				"  Next hop: 10.0.0.2\n" +
+				"  Local pref: 200\n" +
+				"  NLRI: 198.51.100.0/24 (path ID 7)\n",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.msg.Dump(), test.name)
+	}
+}
@@ -0,0 +1,122 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CapabilityMsg is the CAPABILITY message changing capabilities of an established session (dynamic capability)
+	CapabilityMsg = 6
+
+	// DynamicCapabilityCode is the code of the dynamic capability
+	DynamicCapabilityCode = 67
+
+	// DynamicCapabilityAdvertise announces a capability in a CAPABILITY message
+	DynamicCapabilityAdvertise = 0
+
+	// DynamicCapabilityRemove withdraws a capability in a CAPABILITY message
+	DynamicCapabilityRemove = 1
+)
+
+// DynamicCapability announces the ability to handle CAPABILITY messages for the listed capability codes
+type DynamicCapability struct {
+	Codes []uint8
+}
+
+func (d DynamicCapability) serialize(buf *bytes.Buffer) {
+	buf.Write(d.Codes)
+}
+
+// Supports checks if capability code c may be changed on an established session. An empty list does not restrict the codes.
+func (d DynamicCapability) Supports(c uint8) bool {
+	if len(d.Codes) == 0 {
+		return true
+	}
+
+	for _, x := range d.Codes {
+		if x == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeDynamicCapability(buf *bytes.Buffer, l uint8) (DynamicCapability, error) {
+	d := DynamicCapability{
+		Codes: make([]uint8, l),
+	}
+
+	err := decode.DecodeBytes(buf, d.Codes)
+	if err != nil {
+		return d, errors.Wrap(err, "Read failed")
+	}
+
+	return d, nil
+}
+
+// BGPCapability is a CAPABILITY message
+type BGPCapability struct {
+	Changes []CapabilityChange
+}
+
+// CapabilityChange advertises or removes a capability
+type CapabilityChange struct {
+	Action     uint8
+	Capability Capability
+}
+
+// SerializeCapabilityMsg serializes a CAPABILITY message. Each change is encoded as action followed by the capability
+// as in the OPEN message.
+func SerializeCapabilityMsg(msg *BGPCapability) []byte {
+	body := bytes.NewBuffer(nil)
+	for _, c := range msg.Changes {
+		body.WriteByte(c.Action)
+		c.Capability.serialize(body)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, HeaderLen+body.Len()))
+	serializeHeader(buf, uint16(HeaderLen+body.Len()), CapabilityMsg)
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
+
+func decodeCapabilityMsg(buf *bytes.Buffer, l uint16) (*BGPCapability, error) {
+	msg := &BGPCapability{}
+
+	read := uint16(0)
+	for read < l {
+		c := CapabilityChange{}
+		err := decode.DecodeUint8(buf, &c.Action)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode action")
+		}
+
+		if c.Action != DynamicCapabilityAdvertise && c.Action != DynamicCapabilityRemove {
+			return nil, fmt.Errorf("Invalid capability action %d", c.Action)
+		}
+
+		c.Capability, err = decodeCapability(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode capability")
+		}
+
+		msg.Changes = append(msg.Changes, c)
+		read += uint16(c.Capability.Length) + 3
+	}
+
+	return msg, nil
+}
+
+func capabilityActionName(action uint8) string {
+	if action == DynamicCapabilityRemove {
+		return "Remove"
+	}
+
+	return "Advertise"
+}
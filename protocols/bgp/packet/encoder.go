@@ -3,7 +3,7 @@ package packet
 import (
 	"bytes"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 func SerializeKeepaliveMsg() []byte {
@@ -15,11 +15,12 @@ func SerializeKeepaliveMsg() []byte {
 }
 
 func SerializeNotificationMsg(msg *BGPNotification) []byte {
-	notificationLen := uint16(21)
+	notificationLen := uint16(21 + len(msg.Data))
 	buf := bytes.NewBuffer(make([]byte, 0, notificationLen))
 	serializeHeader(buf, notificationLen, NotificationMsg)
 	buf.WriteByte(msg.ErrorCode)
 	buf.WriteByte(msg.ErrorSubcode)
+	buf.Write(msg.Data)
 
 	return buf.Bytes()
 }
@@ -34,9 +35,9 @@ func SerializeOpenMsg(msg *BGPOpen) []byte {
 	serializeHeader(buf, openLen, OpenMsg)
 
 	buf.WriteByte(msg.Version)
-	buf.Write(convert.Uint16Byte(msg.ASN))
-	buf.Write(convert.Uint16Byte(msg.HoldTime))
-	buf.Write(convert.Uint32Byte(msg.BGPIdentifier))
+	endian.WriteUint16(buf, msg.ASN)
+	endian.WriteUint16(buf, msg.HoldTime)
+	endian.WriteUint32(buf, msg.BGPIdentifier)
 
 	buf.WriteByte(uint8(len(optParams)))
 	buf.Write(optParams)
@@ -58,6 +59,6 @@ func serializeOptParams(buf *bytes.Buffer, params []OptParam) {
 
 func serializeHeader(buf *bytes.Buffer, length uint16, typ uint8) {
 	buf.Write([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
-	buf.Write(convert.Uint16Byte(length))
+	endian.WriteUint16(buf, length)
 	buf.WriteByte(typ)
 }
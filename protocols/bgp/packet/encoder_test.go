@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"testing"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +60,58 @@ func TestSerializeNotificationMsg(t *testing.T) {
 	}
 }
 
+func TestSerializeRouteRefreshMsg(t *testing.T) {
+	expected := []byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x00, 0x17, // Length
+		0x05,       // Type
+		0x00, 0x01, // AFI
+		0x02, // Subtype
+		0x01, // SAFI
+	}
+	res := SerializeRouteRefreshMsg(&BGPRouteRefresh{
+		AFI:     IPv4AFI,
+		Subtype: EndOfRouteRefresh,
+		SAFI:    UnicastSAFI,
+	})
+
+	assert.Equal(t, expected, res)
+}
+
+func TestSerializeCapabilityMsg(t *testing.T) {
+	expected := []byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x00, 0x1a, // Length
+		0x06,       // Type
+		0x00,       // Action
+		0x01,       // Capability code
+		0x04,       // Capability length
+		0x00, 0x02, // AFI
+		0x00, // Reserved
+		0x01, // SAFI
+	}
+	res := SerializeCapabilityMsg(&BGPCapability{
+		Changes: []CapabilityChange{
+			{
+				Action: DynamicCapabilityAdvertise,
+				Capability: Capability{
+					Code: MultiProtocolCapabilityCode,
+					Value: MultiProtocolCapability{
+						AFI:  IPv6AFI,
+						SAFI: UnicastSAFI,
+					},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expected, res)
+
+	msg, err := Decode(bytes.NewBuffer(res), &DecodeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, DynamicCapabilityAdvertise, int(msg.Body.(*BGPCapability).Changes[0].Action))
+}
+
 func TestSerializeOpenMsg(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -72,7 +124,7 @@ func TestSerializeOpenMsg(t *testing.T) {
 				Version:       4,
 				ASN:           15169,
 				HoldTime:      120,
-				BGPIdentifier: convert.Uint32([]byte{100, 111, 120, 130}),
+				BGPIdentifier: endian.Uint32([]byte{130, 120, 111, 100}),
 				OptParmLen:    0,
 			},
 			expected: []byte{
@@ -0,0 +1,596 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// FlowSpecSAFI is the SAFI of dissemination of flow specification rules (RFC8955, RFC8956)
+	FlowSpecSAFI = 133
+
+	// flowSpecMaxLen is the maximum length of a FlowSpec NLRI (RFC8955 4.1)
+	flowSpecMaxLen = 0xfff
+
+	// flowSpecExtendedLen is the length from which on the NLRI length is encoded in two octets
+	flowSpecExtendedLen = 240
+)
+
+// FlowSpec component types (RFC8955 4.2.2, RFC8956 3)
+const (
+	FlowSpecDestinationPrefix = 1
+	FlowSpecSourcePrefix      = 2
+	FlowSpecIPProtocol        = 3
+	FlowSpecPort              = 4
+	FlowSpecDestinationPort   = 5
+	FlowSpecSourcePort        = 6
+	FlowSpecICMPType          = 7
+	FlowSpecICMPCode          = 8
+	FlowSpecTCPFlags          = 9
+	FlowSpecPacketLength      = 10
+	FlowSpecDSCP              = 11
+	FlowSpecFragment          = 12
+	FlowSpecFlowLabel         = 13
+)
+
+// FlowSpec operator flags (RFC8955 4.2.1)
+const (
+	FlowSpecOpEnd = 0x80
+	FlowSpecOpAnd = 0x40
+
+	// FlowSpecOpLess, FlowSpecOpGreater and FlowSpecOpEqual are the comparisons of numeric operators
+	FlowSpecOpLess    = 0x04
+	FlowSpecOpGreater = 0x02
+	FlowSpecOpEqual   = 0x01
+
+	// FlowSpecOpNot and FlowSpecOpMatch are the flags of bitmask operators
+	FlowSpecOpNot   = 0x02
+	FlowSpecOpMatch = 0x01
+
+	flowSpecOpLenMask = 0x30
+	flowSpecOpFlags   = 0x4f
+)
+
+// FlowSpec fragment bitmask values (RFC8955 4.2.2.12)
+const (
+	FlowSpecFragmentDontFragment = 0x01
+	FlowSpecFragmentIsFragment   = 0x02
+	FlowSpecFragmentFirst        = 0x04
+	FlowSpecFragmentLast         = 0x08
+)
+
+var flowSpecComponentNames = map[uint8]string{
+	FlowSpecDestinationPrefix: "dst",
+	FlowSpecSourcePrefix:      "src",
+	FlowSpecIPProtocol:        "proto",
+	FlowSpecPort:              "port",
+	FlowSpecDestinationPort:   "dport",
+	FlowSpecSourcePort:        "sport",
+	FlowSpecICMPType:          "icmp-type",
+	FlowSpecICMPCode:          "icmp-code",
+	FlowSpecTCPFlags:          "tcp-flags",
+	FlowSpecPacketLength:      "length",
+	FlowSpecDSCP:              "dscp",
+	FlowSpecFragment:          "fragment",
+	FlowSpecFlowLabel:         "flow-label",
+}
+
+// FlowSpecOperator is an operator and value pair of a numeric or bitmask component. Op only holds the AND and
+// comparison flags, the end of list and length bits are derived when serializing.
+type FlowSpecOperator struct {
+	Op    uint8
+	Value uint64
+}
+
+func (o FlowSpecOperator) valueLen() uint8 {
+	switch {
+	case o.Value > 0xffffffff:
+		return 8
+	case o.Value > 0xffff:
+		return 4
+	case o.Value > 0xff:
+		return 2
+	}
+
+	return 1
+}
+
+// FlowSpecComponent is a component of a flow specification. Prefix components use Prefix (and Offset for IPv6), all
+// other components use Operators.
+type FlowSpecComponent struct {
+	Type      uint8
+	Prefix    *bnet.Prefix
+	Offset    uint8
+	Operators []FlowSpecOperator
+}
+
+// IsPrefix returns if the component matches a source or destination prefix
+func (c *FlowSpecComponent) IsPrefix() bool {
+	return c.Type == FlowSpecDestinationPrefix || c.Type == FlowSpecSourcePrefix
+}
+
+// IsBitmask returns if the operators of the component are bitmask operators
+func (c *FlowSpecComponent) IsBitmask() bool {
+	return c.Type == FlowSpecTCPFlags || c.Type == FlowSpecFragment
+}
+
+// Matches evaluates the operators of the component for value v. Terms joined by AND bind stronger than OR.
+func (c *FlowSpecComponent) Matches(v uint64) bool {
+	ret := false
+	group := true
+	for i, o := range c.Operators {
+		if i > 0 && o.Op&FlowSpecOpAnd == 0 {
+			ret = ret || group
+			group = true
+		}
+
+		group = group && c.matchesOperator(o, v)
+	}
+
+	return ret || group
+}
+
+func (c *FlowSpecComponent) matchesOperator(o FlowSpecOperator, v uint64) bool {
+	if c.IsBitmask() {
+		var m bool
+		if o.Op&FlowSpecOpMatch != 0 {
+			m = v&o.Value == o.Value
+		} else {
+			m = v&o.Value != 0
+		}
+
+		if o.Op&FlowSpecOpNot != 0 {
+			return !m
+		}
+
+		return m
+	}
+
+	return (o.Op&FlowSpecOpLess != 0 && v < o.Value) ||
+		(o.Op&FlowSpecOpGreater != 0 && v > o.Value) ||
+		(o.Op&FlowSpecOpEqual != 0 && v == o.Value)
+}
+
+func (c *FlowSpecComponent) serializeValue(buf *bytes.Buffer, afi uint16) {
+	if c.IsPrefix() {
+		buf.WriteByte(c.Prefix.Pfxlen())
+		if afi == IPv6AFI {
+			buf.WriteByte(c.Offset)
+		}
+
+		buf.Write(prefixPattern(c.Prefix, c.Offset))
+		return
+	}
+
+	for i, o := range c.Operators {
+		l := o.valueLen()
+		op := o.Op & flowSpecOpFlags
+		switch l {
+		case 2:
+			op |= 0x10
+		case 4:
+			op |= 0x20
+		case 8:
+			op |= 0x30
+		}
+
+		if i == len(c.Operators)-1 {
+			op |= FlowSpecOpEnd
+		}
+
+		buf.WriteByte(op)
+		switch l {
+		case 1:
+			buf.WriteByte(uint8(o.Value))
+		case 2:
+			endian.WriteUint16(buf, uint16(o.Value))
+		case 4:
+			endian.WriteUint32(buf, uint32(o.Value))
+		case 8:
+			endian.WriteUint64(buf, o.Value)
+		}
+	}
+}
+
+// prefixPattern gets the bits from offset to the length of the prefix shifted to the start of the returned bytes
+func prefixPattern(pfx *bnet.Prefix, offset uint8) []byte {
+	addr := pfx.Addr().Bytes()
+	bits := pfx.Pfxlen() - offset
+	ret := make([]byte, BytesInAddr(bits))
+	for i := uint8(0); i < bits; i++ {
+		pos := offset + i
+		if addr[pos/8]&(0x80>>(pos%8)) != 0 {
+			ret[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	return ret
+}
+
+func (c *FlowSpecComponent) String() string {
+	name, ok := flowSpecComponentNames[c.Type]
+	if !ok {
+		name = fmt.Sprintf("type%d", c.Type)
+	}
+
+	if c.IsPrefix() {
+		if c.Offset > 0 {
+			return fmt.Sprintf("%s %s offset %d", name, c.Prefix.String(), c.Offset)
+		}
+
+		return fmt.Sprintf("%s %s", name, c.Prefix.String())
+	}
+
+	ret := name + " "
+	for i, o := range c.Operators {
+		if i > 0 {
+			if o.Op&FlowSpecOpAnd != 0 {
+				ret += "&"
+			} else {
+				ret += "|"
+			}
+		}
+
+		ret += c.operatorString(o)
+	}
+
+	return ret
+}
+
+func (c *FlowSpecComponent) operatorString(o FlowSpecOperator) string {
+	if c.IsBitmask() {
+		op := ""
+		if o.Op&FlowSpecOpNot != 0 {
+			op = "!"
+		}
+
+		if o.Op&FlowSpecOpMatch != 0 {
+			op += "="
+		}
+
+		return fmt.Sprintf("%s0x%x", op, o.Value)
+	}
+
+	op := ""
+	if o.Op&FlowSpecOpLess != 0 {
+		op += "<"
+	}
+
+	if o.Op&FlowSpecOpGreater != 0 {
+		op += ">"
+	}
+
+	if o.Op&FlowSpecOpEqual != 0 {
+		op += "="
+	}
+
+	switch op {
+	case "":
+		return "false"
+	case "<>":
+		op = "!="
+	case "<>=":
+		return "true"
+	}
+
+	return fmt.Sprintf("%s%d", op, o.Value)
+}
+
+// FlowSpecNLRI is a flow specification of the FlowSpec address family of AFI (RFC8955 4, RFC8956 3)
+type FlowSpecNLRI struct {
+	AFI        uint16
+	Components []*FlowSpecComponent
+}
+
+// Component gets the component of type t or nil if the flow specification has none
+func (n *FlowSpecNLRI) Component(t uint8) *FlowSpecComponent {
+	for _, c := range n.Components {
+		if c.Type == t {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// String returns a human readable representation of the flow specification
+func (n *FlowSpecNLRI) String() string {
+	parts := make([]string, 0, len(n.Components))
+	for _, c := range n.Components {
+		parts = append(parts, c.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Key gets the wire format of the flow specification which identifies it
+func (n *FlowSpecNLRI) Key() string {
+	return string(n.value())
+}
+
+func (n *FlowSpecNLRI) value() []byte {
+	buf := bytes.NewBuffer(nil)
+	for _, c := range n.Components {
+		buf.WriteByte(c.Type)
+		c.serializeValue(buf, n.AFI)
+	}
+
+	return buf.Bytes()
+}
+
+func (n *FlowSpecNLRI) serialize(buf *bytes.Buffer) {
+	v := n.value()
+	if len(v) < flowSpecExtendedLen {
+		buf.WriteByte(uint8(len(v)))
+	} else {
+		endian.WriteUint16(buf, 0xf000|uint16(len(v)))
+	}
+
+	buf.Write(v)
+}
+
+// Compare compares the precedence of two flow specifications (RFC8955 5.1, RFC8956 4). It returns -1 if n has
+// precedence over x, 1 if x has precedence over n and 0 if both are equal.
+func (n *FlowSpecNLRI) Compare(x *FlowSpecNLRI) int {
+	for i := 0; i < len(n.Components) && i < len(x.Components); i++ {
+		a := n.Components[i]
+		b := x.Components[i]
+
+		// The flow specification having a component of a lower type has precedence
+		if a.Type != b.Type {
+			if a.Type < b.Type {
+				return -1
+			}
+
+			return 1
+		}
+
+		var c int
+		if a.IsPrefix() {
+			c = comparePrefixComponents(a, b)
+		} else {
+			c = compareValues(a, b, n.AFI)
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	// The flow specification having more components has precedence
+	switch {
+	case len(n.Components) > len(x.Components):
+		return -1
+	case len(n.Components) < len(x.Components):
+		return 1
+	}
+
+	return 0
+}
+
+// comparePrefixComponents gives precedence to the lower offset, the lower IP value of the common prefix bits and
+// the more specific prefix
+func comparePrefixComponents(a *FlowSpecComponent, b *FlowSpecComponent) int {
+	if a.Offset != b.Offset {
+		if a.Offset < b.Offset {
+			return -1
+		}
+
+		return 1
+	}
+
+	common := a.Prefix.Pfxlen()
+	if b.Prefix.Pfxlen() < common {
+		common = b.Prefix.Pfxlen()
+	}
+
+	addrA := bnet.NewPfx(*a.Prefix.Addr(), common).Ptr().BaseAddr()
+	addrB := bnet.NewPfx(*b.Prefix.Addr(), common).Ptr().BaseAddr()
+	if c := addrA.Compare(addrB); c != 0 {
+		return int(c)
+	}
+
+	switch {
+	case a.Prefix.Pfxlen() > b.Prefix.Pfxlen():
+		return -1
+	case a.Prefix.Pfxlen() < b.Prefix.Pfxlen():
+		return 1
+	}
+
+	return 0
+}
+
+// compareValues compares the wire format of components giving precedence to the lower value and, if one is a prefix
+// of the other, to the longer one
+func compareValues(a *FlowSpecComponent, b *FlowSpecComponent, afi uint16) int {
+	bufA := bytes.NewBuffer(nil)
+	a.serializeValue(bufA, afi)
+	bufB := bytes.NewBuffer(nil)
+	b.serializeValue(bufB, afi)
+
+	va := bufA.Bytes()
+	vb := bufB.Bytes()
+	common := len(va)
+	if len(vb) < common {
+		common = len(vb)
+	}
+
+	if c := bytes.Compare(va[:common], vb[:common]); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(va) > len(vb):
+		return -1
+	case len(va) < len(vb):
+		return 1
+	}
+
+	return 0
+}
+
+func decodeFlowSpecNLRIs(b []byte, afi uint16) ([]*FlowSpecNLRI, error) {
+	if afi != IPv4AFI && afi != IPv6AFI {
+		return nil, fmt.Errorf("Unsupported FlowSpec AFI %d", afi)
+	}
+
+	ret := make([]*FlowSpecNLRI, 0)
+	for len(b) > 0 {
+		l := int(b[0])
+		hdrLen := 1
+		if b[0]&0xf0 == 0xf0 {
+			if len(b) < 2 {
+				return nil, fmt.Errorf("FlowSpec NLRI length truncated")
+			}
+
+			l = int(endian.Uint16(b) & flowSpecMaxLen)
+			hdrLen = 2
+		}
+
+		if len(b) < hdrLen+l {
+			return nil, fmt.Errorf("FlowSpec NLRI truncated")
+		}
+
+		n, err := decodeFlowSpecNLRI(b[hdrLen:hdrLen+l], afi)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, n)
+		b = b[hdrLen+l:]
+	}
+
+	return ret, nil
+}
+
+func decodeFlowSpecNLRI(b []byte, afi uint16) (*FlowSpecNLRI, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("Empty FlowSpec NLRI")
+	}
+
+	n := &FlowSpecNLRI{
+		AFI: afi,
+	}
+
+	for len(b) > 0 {
+		c := &FlowSpecComponent{
+			Type: b[0],
+		}
+
+		// Components have to be ordered by type and must not be repeated (RFC8955 4.2)
+		if len(n.Components) > 0 && n.Components[len(n.Components)-1].Type >= c.Type {
+			return nil, fmt.Errorf("FlowSpec component %d out of order", c.Type)
+		}
+
+		var consumed int
+		var err error
+		if c.IsPrefix() {
+			consumed, err = c.decodePrefix(b[1:], afi)
+		} else {
+			consumed, err = c.decodeOperators(b[1:])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		n.Components = append(n.Components, c)
+		b = b[1+consumed:]
+	}
+
+	return n, nil
+}
+
+func (c *FlowSpecComponent) decodePrefix(b []byte, afi uint16) (int, error) {
+	if _, ok := flowSpecComponentNames[c.Type]; !ok {
+		return 0, fmt.Errorf("Unknown FlowSpec component type %d", c.Type)
+	}
+
+	if len(b) < 1 {
+		return 0, fmt.Errorf("FlowSpec prefix component truncated")
+	}
+
+	pfxLen := b[0]
+	hdrLen := 1
+	if afi == IPv6AFI {
+		if len(b) < 2 {
+			return 0, fmt.Errorf("FlowSpec prefix component truncated")
+		}
+
+		c.Offset = b[1]
+		hdrLen = 2
+	}
+
+	addrLen := afiAddrLenBytes[afi]
+	if pfxLen > addrLen*8 || c.Offset > pfxLen {
+		return 0, fmt.Errorf("Invalid FlowSpec prefix length %d offset %d", pfxLen, c.Offset)
+	}
+
+	patternLen := int(BytesInAddr(pfxLen - c.Offset))
+	if len(b) < hdrLen+patternLen {
+		return 0, fmt.Errorf("FlowSpec prefix component truncated")
+	}
+
+	pattern := b[hdrLen : hdrLen+patternLen]
+	addr := make([]byte, addrLen)
+	for i := uint8(0); i < pfxLen-c.Offset; i++ {
+		if pattern[i/8]&(0x80>>(i%8)) != 0 {
+			pos := c.Offset + i
+			addr[pos/8] |= 0x80 >> (pos % 8)
+		}
+	}
+
+	ip, err := bnet.IPFromBytes(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Prefix = bnet.NewPfx(ip, pfxLen).Dedup()
+	return hdrLen + patternLen, nil
+}
+
+func (c *FlowSpecComponent) decodeOperators(b []byte) (int, error) {
+	if _, ok := flowSpecComponentNames[c.Type]; !ok {
+		return 0, fmt.Errorf("Unknown FlowSpec component type %d", c.Type)
+	}
+
+	consumed := 0
+	for {
+		if len(b) < consumed+1 {
+			return 0, fmt.Errorf("FlowSpec component %d truncated", c.Type)
+		}
+
+		op := b[consumed]
+		l := 1 << ((op & flowSpecOpLenMask) >> 4)
+		if len(b) < consumed+1+l {
+			return 0, fmt.Errorf("FlowSpec component %d truncated", c.Type)
+		}
+
+		v := b[consumed+1 : consumed+1+l]
+		var value uint64
+		switch l {
+		case 1:
+			value = uint64(v[0])
+		case 2:
+			value = uint64(endian.Uint16(v))
+		case 4:
+			value = uint64(endian.Uint32(v))
+		case 8:
+			value = endian.Uint64(v)
+		}
+
+		c.Operators = append(c.Operators, FlowSpecOperator{
+			Op:    op & flowSpecOpFlags,
+			Value: value,
+		})
+		consumed += 1 + l
+
+		if op&FlowSpecOpEnd != 0 {
+			return consumed, nil
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeFlowSpecNLRIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		afi      uint16
+		input    []byte
+		wantFail bool
+		expected string
+	}{
+		{
+			name: "Destination prefix, protocol and port (RFC8955 example 1)",
+			afi:  IPv4AFI,
+			input: []byte{
+				0x0b,
+				0x01, 0x18, 10, 0, 1,
+				0x03, 0x81, 6,
+				0x04, 0x81, 25,
+			},
+			expected: "dst 10.0.1.0/24 proto =6 port =25",
+		},
+		{
+			name: "Port ranges (RFC8955 example 2)",
+			afi:  IPv4AFI,
+			input: []byte{
+				0x10,
+				0x01, 0x08, 10,
+				0x02, 0x18, 192, 0, 1,
+				0x04, 0x03, 0x89, 0x45, 0x8b, 0x91, 0x1f, 0x90,
+			},
+			expected: "dst 10.0.0.0/8 src 192.0.1.0/24 port >=137&<=139|=8080",
+		},
+		{
+			name: "IPv6 destination prefix with offset",
+			afi:  IPv6AFI,
+			input: []byte{
+				0x05,
+				0x01, 0x20, 0x10, 0x0d, 0xb8,
+			},
+			expected: "dst 0:DB8:0:0:0:0:0:0/32 offset 16",
+		},
+		{
+			name: "Components out of order",
+			afi:  IPv4AFI,
+			input: []byte{
+				0x06,
+				0x03, 0x81, 6,
+				0x01, 0x08, 10,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Truncated operator list",
+			afi:  IPv4AFI,
+			input: []byte{
+				0x03,
+				0x05, 0x11, 0x1f,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		nlris, err := decodeFlowSpecNLRIs(test.input, test.afi)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) || !assert.Len(t, nlris, 1, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, nlris[0].String(), "Test %q", test.name)
+
+		buf := bytes.NewBuffer(nil)
+		nlris[0].serialize(buf)
+		assert.Equal(t, test.input, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestFlowSpecMPReachNLRI(t *testing.T) {
+	n := MultiProtocolReachNLRI{
+		AFI:  IPv4AFI,
+		SAFI: FlowSpecSAFI,
+		FlowSpec: []*FlowSpecNLRI{
+			{
+				AFI: IPv4AFI,
+				Components: []*FlowSpecComponent{
+					{
+						Type:   FlowSpecDestinationPrefix,
+						Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+					},
+				},
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	n.serialize(buf, &EncodeOptions{})
+	assert.Equal(t, []byte{0, 1, FlowSpecSAFI, 0, 0, 5, 1, 24, 192, 0, 2}, buf.Bytes())
+
+	decoded, err := deserializeMultiProtocolReachNLRI(buf.Bytes(), &DecodeOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Nil(t, decoded.NextHop)
+	assert.Equal(t, n.FlowSpec, decoded.FlowSpec)
+}
+
+func TestFlowSpecCompare(t *testing.T) {
+	dst := func(addr bnet.IP, l uint8) *FlowSpecComponent {
+		return &FlowSpecComponent{
+			Type:   FlowSpecDestinationPrefix,
+			Prefix: bnet.NewPfx(addr, l).Ptr(),
+		}
+	}
+	src := &FlowSpecComponent{
+		Type:   FlowSpecSourcePrefix,
+		Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+	}
+	proto := func(p uint64) *FlowSpecComponent {
+		return &FlowSpecComponent{
+			Type:      FlowSpecIPProtocol,
+			Operators: []FlowSpecOperator{{Op: FlowSpecOpEqual, Value: p}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		a        []*FlowSpecComponent
+		b        []*FlowSpecComponent
+		expected int
+	}{
+		{
+			name:     "More specific destination",
+			a:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 1, 0), 24)},
+			b:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 0, 0), 16)},
+			expected: -1,
+		},
+		{
+			name:     "Lower destination",
+			a:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 2, 0), 24)},
+			b:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 1, 0), 24)},
+			expected: 1,
+		},
+		{
+			name:     "Destination before source",
+			a:        []*FlowSpecComponent{src},
+			b:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 0, 0), 8)},
+			expected: 1,
+		},
+		{
+			name:     "More components",
+			a:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 0, 0), 8), src},
+			b:        []*FlowSpecComponent{dst(bnet.IPv4FromOctets(10, 0, 0, 0), 8)},
+			expected: -1,
+		},
+		{
+			name:     "Lower protocol value",
+			a:        []*FlowSpecComponent{proto(6)},
+			b:        []*FlowSpecComponent{proto(17)},
+			expected: -1,
+		},
+		{
+			name:     "Equal",
+			a:        []*FlowSpecComponent{proto(6)},
+			b:        []*FlowSpecComponent{proto(6)},
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		a := &FlowSpecNLRI{AFI: IPv4AFI, Components: test.a}
+		b := &FlowSpecNLRI{AFI: IPv4AFI, Components: test.b}
+		assert.Equal(t, test.expected, a.Compare(b), "Test %q", test.name)
+		assert.Equal(t, -test.expected, b.Compare(a), "Test %q", test.name)
+	}
+}
+
+func TestFlowSpecComponentMatches(t *testing.T) {
+	ports := &FlowSpecComponent{
+		Type: FlowSpecPort,
+		Operators: []FlowSpecOperator{
+			{Op: FlowSpecOpGreater | FlowSpecOpEqual, Value: 137},
+			{Op: FlowSpecOpAnd | FlowSpecOpLess | FlowSpecOpEqual, Value: 139},
+			{Op: FlowSpecOpEqual, Value: 8080},
+		},
+	}
+	syn := &FlowSpecComponent{
+		Type: FlowSpecTCPFlags,
+		Operators: []FlowSpecOperator{
+			{Op: FlowSpecOpMatch, Value: 0x02},
+			{Op: FlowSpecOpAnd | FlowSpecOpNot, Value: 0x10},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		component *FlowSpecComponent
+		value     uint64
+		expected  bool
+	}{
+		{
+			name:      "Port within range",
+			component: ports,
+			value:     138,
+			expected:  true,
+		},
+		{
+			name:      "Port outside range",
+			component: ports,
+			value:     140,
+		},
+		{
+			name:      "Second term",
+			component: ports,
+			value:     8080,
+			expected:  true,
+		},
+		{
+			name:      "SYN",
+			component: syn,
+			value:     0x02,
+			expected:  true,
+		},
+		{
+			name:      "SYN ACK",
+			component: syn,
+			value:     0x12,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.component.Matches(test.value), "Test %q", test.name)
+	}
+}
@@ -0,0 +1,532 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LinkStateAFI is the address family of BGP-LS (RFC7752)
+	LinkStateAFI = 16388
+
+	// LinkStateSAFI is the SAFI of BGP-LS (RFC7752)
+	LinkStateSAFI = 71
+
+	// LinkStateAttr is the BGP-LS attribute (RFC7752 3.3)
+	LinkStateAttr = 29
+
+	// BGP-LS NLRI types (RFC7752 3.2)
+	LinkStateNodeNLRI       = 1
+	LinkStateLinkNLRI       = 2
+	LinkStateIPv4PrefixNLRI = 3
+	LinkStateIPv6PrefixNLRI = 4
+
+	// BGP-LS protocol IDs (RFC7752 3.2)
+	LinkStateProtocolISISL1 = 1
+	LinkStateProtocolISISL2 = 2
+	LinkStateProtocolOSPFv2 = 3
+	LinkStateProtocolDirect = 4
+	LinkStateProtocolStatic = 5
+	LinkStateProtocolOSPFv3 = 6
+
+	// Descriptor TLVs (RFC7752 3.2.1 - 3.2.3)
+	linkStateLocalNodeDescriptors  = 256
+	linkStateRemoteNodeDescriptors = 257
+	linkStateLinkIdentifiers       = 258
+	linkStateIPv4InterfaceAddr     = 259
+	linkStateIPv4NeighborAddr      = 260
+	linkStateIPv6InterfaceAddr     = 261
+	linkStateIPv6NeighborAddr      = 262
+	linkStateMultiTopologyID       = 263
+	linkStateOSPFRouteType         = 264
+	linkStateIPReachability        = 265
+	linkStateASN                   = 512
+	linkStateBGPLSIdentifier       = 513
+	linkStateOSPFAreaID            = 514
+	linkStateIGPRouterID           = 515
+
+	// Attribute TLVs (RFC7752 3.3)
+	LinkStateNodeFlags     = 1024
+	LinkStateNodeName      = 1026
+	LinkStateISISAreaID    = 1027
+	LinkStateIPv4RouterID  = 1028
+	LinkStateIPv6RouterID  = 1029
+	LinkStateRemoteIPv4RID = 1030
+	LinkStateRemoteIPv6RID = 1031
+	LinkStateMaxBandwidth  = 1089
+	LinkStateTEMetric      = 1092
+	LinkStateIGPMetric     = 1095
+	LinkStatePrefixMetric  = 1155
+
+	linkStateTLVHeaderLen  = 4
+	linkStateNLRIHeaderLen = 4
+
+	// linkStateNLRIFixedLen is the length of the protocol ID and identifier fields
+	linkStateNLRIFixedLen = 9
+)
+
+// LinkStateNLRI is a node, link or prefix of an IGP topology (RFC7752 3.2)
+type LinkStateNLRI struct {
+	Type       uint16
+	ProtocolID uint8
+	Identifier uint64
+	LocalNode  LinkStateNodeDescriptor
+
+	// RemoteNode and Link are set for link NLRIs
+	RemoteNode LinkStateNodeDescriptor
+	Link       LinkStateLinkDescriptor
+
+	// Prefix is set for prefix NLRIs
+	Prefix LinkStatePrefixDescriptor
+}
+
+// LinkStateNodeDescriptor identifies a node (RFC7752 3.2.1.4). Zero values are not encoded.
+type LinkStateNodeDescriptor struct {
+	ASN         uint32
+	BGPLSID     uint32
+	OSPFAreaID  uint32
+	IGPRouterID []byte
+}
+
+// LinkStateLinkDescriptor identifies a link between two nodes (RFC7752 3.2.2). Zero values are not encoded.
+type LinkStateLinkDescriptor struct {
+	LocalID       uint32
+	RemoteID      uint32
+	InterfaceAddr *bnet.IP
+	NeighborAddr  *bnet.IP
+	MTID          uint16
+}
+
+// LinkStatePrefixDescriptor identifies a prefix originated by a node (RFC7752 3.2.3). Zero values are not encoded.
+type LinkStatePrefixDescriptor struct {
+	MTID          uint16
+	OSPFRouteType uint8
+	Prefix        *bnet.Prefix
+}
+
+// LinkStateTLV is a TLV of the BGP-LS attribute
+type LinkStateTLV struct {
+	Type  uint16
+	Value []byte
+}
+
+// LinkStateAttribute is the value of the BGP-LS attribute (RFC7752 3.3)
+type LinkStateAttribute []LinkStateTLV
+
+// NewLinkStateTLVUint32 creates a TLV carrying a 32 bit value
+func NewLinkStateTLVUint32(t uint16, v uint32) LinkStateTLV {
+	return LinkStateTLV{
+		Type:  t,
+		Value: uint32Bytes(v),
+	}
+}
+
+// Get gets the value of the first TLV of type t, nil if there is none
+func (a LinkStateAttribute) Get(t uint16) []byte {
+	for _, tlv := range a {
+		if tlv.Type == t {
+			return tlv.Value
+		}
+	}
+
+	return nil
+}
+
+func (a LinkStateAttribute) serialize(buf *bytes.Buffer) {
+	for _, tlv := range a {
+		writeLinkStateTLV(buf, tlv.Type, tlv.Value)
+	}
+}
+
+func decodeLinkStateAttribute(b []byte) (LinkStateAttribute, error) {
+	ret := make(LinkStateAttribute, 0)
+	err := walkLinkStateTLVs(b, func(t uint16, v []byte) error {
+		ret = append(ret, LinkStateTLV{
+			Type:  t,
+			Value: v,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (pa *PathAttribute) decodeLinkState(buf *bytes.Buffer) error {
+	b := make([]byte, pa.Length)
+	err := decode.Decode(buf, []interface{}{&b})
+	if err != nil {
+		return err
+	}
+
+	a, err := decodeLinkStateAttribute(b)
+	if err != nil {
+		return err
+	}
+
+	pa.Value = a
+	return nil
+}
+
+func (pa *PathAttribute) serializeLinkState(buf *bytes.Buffer) uint16 {
+	a := pa.Value.(LinkStateAttribute)
+	if len(a) == 0 {
+		return 0
+	}
+
+	pa.Optional = true
+	pa.Transitive = false
+
+	tempBuf := &bytes.Buffer{}
+	a.serialize(tempBuf)
+
+	return pa.serializeGeneric(tempBuf.Bytes(), buf)
+}
+
+// Key gets a string uniquely identifying the NLRI
+func (n *LinkStateNLRI) Key() string {
+	buf := &bytes.Buffer{}
+	n.serialize(buf)
+
+	return buf.String()
+}
+
+// String returns a human readable representation of the NLRI
+func (n *LinkStateNLRI) String() string {
+	parts := []string{
+		fmt.Sprintf("protocol %d", n.ProtocolID),
+		fmt.Sprintf("local node %s", n.LocalNode.String()),
+	}
+
+	switch n.Type {
+	case LinkStateNodeNLRI:
+		return fmt.Sprintf("node [%s]", strings.Join(parts, ", "))
+	case LinkStateLinkNLRI:
+		parts = append(parts, fmt.Sprintf("remote node %s", n.RemoteNode.String()))
+		if n.Link.InterfaceAddr != nil {
+			parts = append(parts, fmt.Sprintf("interface %s", n.Link.InterfaceAddr.String()))
+		}
+		if n.Link.NeighborAddr != nil {
+			parts = append(parts, fmt.Sprintf("neighbor %s", n.Link.NeighborAddr.String()))
+		}
+
+		return fmt.Sprintf("link [%s]", strings.Join(parts, ", "))
+	case LinkStateIPv4PrefixNLRI, LinkStateIPv6PrefixNLRI:
+		if n.Prefix.Prefix != nil {
+			parts = append(parts, fmt.Sprintf("prefix %s", n.Prefix.Prefix.String()))
+		}
+
+		return fmt.Sprintf("prefix [%s]", strings.Join(parts, ", "))
+	}
+
+	return fmt.Sprintf("type %d [%s]", n.Type, strings.Join(parts, ", "))
+}
+
+// String returns a human readable representation of the node descriptor
+func (d *LinkStateNodeDescriptor) String() string {
+	return fmt.Sprintf("AS%d/%s", d.ASN, hex.EncodeToString(d.IGPRouterID))
+}
+
+func (n *LinkStateNLRI) serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	body.WriteByte(n.ProtocolID)
+	endian.WriteUint64(body, n.Identifier)
+
+	writeLinkStateTLV(body, linkStateLocalNodeDescriptors, n.LocalNode.serialize())
+
+	switch n.Type {
+	case LinkStateLinkNLRI:
+		writeLinkStateTLV(body, linkStateRemoteNodeDescriptors, n.RemoteNode.serialize())
+		n.Link.serialize(body)
+	case LinkStateIPv4PrefixNLRI, LinkStateIPv6PrefixNLRI:
+		n.Prefix.serialize(body)
+	}
+
+	endian.WriteUint16(buf, n.Type)
+	endian.WriteUint16(buf, uint16(body.Len()))
+	buf.Write(body.Bytes())
+}
+
+func (d *LinkStateNodeDescriptor) serialize() []byte {
+	buf := &bytes.Buffer{}
+	if d.ASN != 0 {
+		writeLinkStateTLV(buf, linkStateASN, uint32Bytes(d.ASN))
+	}
+
+	if d.BGPLSID != 0 {
+		writeLinkStateTLV(buf, linkStateBGPLSIdentifier, uint32Bytes(d.BGPLSID))
+	}
+
+	if d.OSPFAreaID != 0 {
+		writeLinkStateTLV(buf, linkStateOSPFAreaID, uint32Bytes(d.OSPFAreaID))
+	}
+
+	if d.IGPRouterID != nil {
+		writeLinkStateTLV(buf, linkStateIGPRouterID, d.IGPRouterID)
+	}
+
+	return buf.Bytes()
+}
+
+func (d *LinkStateLinkDescriptor) serialize(buf *bytes.Buffer) {
+	if d.LocalID != 0 || d.RemoteID != 0 {
+		writeLinkStateTLV(buf, linkStateLinkIdentifiers, append(uint32Bytes(d.LocalID), uint32Bytes(d.RemoteID)...))
+	}
+
+	if d.InterfaceAddr != nil {
+		t := uint16(linkStateIPv6InterfaceAddr)
+		if d.InterfaceAddr.IsIPv4() {
+			t = linkStateIPv4InterfaceAddr
+		}
+
+		writeLinkStateTLV(buf, t, d.InterfaceAddr.Bytes())
+	}
+
+	if d.NeighborAddr != nil {
+		t := uint16(linkStateIPv6NeighborAddr)
+		if d.NeighborAddr.IsIPv4() {
+			t = linkStateIPv4NeighborAddr
+		}
+
+		writeLinkStateTLV(buf, t, d.NeighborAddr.Bytes())
+	}
+
+	if d.MTID != 0 {
+		writeLinkStateTLV(buf, linkStateMultiTopologyID, []byte{byte(d.MTID >> 8), byte(d.MTID)})
+	}
+}
+
+func (d *LinkStatePrefixDescriptor) serialize(buf *bytes.Buffer) {
+	if d.MTID != 0 {
+		writeLinkStateTLV(buf, linkStateMultiTopologyID, []byte{byte(d.MTID >> 8), byte(d.MTID)})
+	}
+
+	if d.OSPFRouteType != 0 {
+		writeLinkStateTLV(buf, linkStateOSPFRouteType, []byte{d.OSPFRouteType})
+	}
+
+	if d.Prefix != nil {
+		pfxLen := d.Prefix.Pfxlen()
+		v := append([]byte{pfxLen}, d.Prefix.Addr().Bytes()[:BytesInAddr(pfxLen)]...)
+		writeLinkStateTLV(buf, linkStateIPReachability, v)
+	}
+}
+
+func writeLinkStateTLV(buf *bytes.Buffer, t uint16, v []byte) {
+	endian.WriteUint16(buf, t)
+	endian.WriteUint16(buf, uint16(len(v)))
+	buf.Write(v)
+}
+
+func uint32Bytes(v uint32) []byte {
+	return []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+// walkLinkStateTLVs calls f for each TLV of b
+func walkLinkStateTLVs(b []byte, f func(t uint16, v []byte) error) error {
+	for len(b) > 0 {
+		if len(b) < linkStateTLVHeaderLen {
+			return fmt.Errorf("TLV header truncated")
+		}
+
+		t := uint16(b[0])<<8 | uint16(b[1])
+		l := int(b[2])<<8 | int(b[3])
+		if len(b) < linkStateTLVHeaderLen+l {
+			return fmt.Errorf("TLV %d truncated", t)
+		}
+
+		err := f(t, b[linkStateTLVHeaderLen:linkStateTLVHeaderLen+l])
+		if err != nil {
+			return errors.Wrapf(err, "Unable to decode TLV %d", t)
+		}
+
+		b = b[linkStateTLVHeaderLen+l:]
+	}
+
+	return nil
+}
+
+func decodeLinkStateNLRIs(b []byte) ([]*LinkStateNLRI, error) {
+	ret := make([]*LinkStateNLRI, 0)
+	for len(b) > 0 {
+		if len(b) < linkStateNLRIHeaderLen {
+			return nil, fmt.Errorf("BGP-LS NLRI header truncated")
+		}
+
+		t := uint16(b[0])<<8 | uint16(b[1])
+		l := int(b[2])<<8 | int(b[3])
+		if len(b) < linkStateNLRIHeaderLen+l {
+			return nil, fmt.Errorf("BGP-LS NLRI of type %d truncated", t)
+		}
+
+		n, err := decodeLinkStateNLRI(t, b[linkStateNLRIHeaderLen:linkStateNLRIHeaderLen+l])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode BGP-LS NLRI of type %d", t)
+		}
+
+		// NLRIs of unknown types are skipped (RFC7752 3.2)
+		if n != nil {
+			ret = append(ret, n)
+		}
+
+		b = b[linkStateNLRIHeaderLen+l:]
+	}
+
+	return ret, nil
+}
+
+func decodeLinkStateNLRI(t uint16, b []byte) (*LinkStateNLRI, error) {
+	switch t {
+	case LinkStateNodeNLRI, LinkStateLinkNLRI, LinkStateIPv4PrefixNLRI, LinkStateIPv6PrefixNLRI:
+	default:
+		return nil, nil
+	}
+
+	if len(b) < linkStateNLRIFixedLen {
+		return nil, fmt.Errorf("Invalid length %d", len(b))
+	}
+
+	n := &LinkStateNLRI{
+		Type:       t,
+		ProtocolID: b[0],
+	}
+
+	err := decode.Decode(bytes.NewBuffer(b[1:linkStateNLRIFixedLen]), []interface{}{&n.Identifier})
+	if err != nil {
+		return nil, err
+	}
+
+	haveLocalNode := false
+	err = walkLinkStateTLVs(b[linkStateNLRIFixedLen:], func(tlvType uint16, v []byte) error {
+		switch tlvType {
+		case linkStateLocalNodeDescriptors:
+			haveLocalNode = true
+			return n.LocalNode.decode(v)
+		case linkStateRemoteNodeDescriptors:
+			return n.RemoteNode.decode(v)
+		case linkStateIPReachability:
+			if t == LinkStateIPv4PrefixNLRI || t == LinkStateIPv6PrefixNLRI {
+				return n.Prefix.decodeReachability(v, t == LinkStateIPv6PrefixNLRI)
+			}
+		case linkStateMultiTopologyID:
+			if len(v) < 2 {
+				return fmt.Errorf("Invalid length %d", len(v))
+			}
+
+			mtid := (uint16(v[0])<<8 | uint16(v[1])) & 0x0fff
+			n.Link.MTID = mtid
+			n.Prefix.MTID = mtid
+		case linkStateOSPFRouteType:
+			if len(v) != 1 {
+				return fmt.Errorf("Invalid length %d", len(v))
+			}
+
+			n.Prefix.OSPFRouteType = v[0]
+		default:
+			return n.Link.decodeTLV(tlvType, v)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !haveLocalNode {
+		return nil, fmt.Errorf("Local node descriptors missing")
+	}
+
+	if t != LinkStateLinkNLRI {
+		n.Link = LinkStateLinkDescriptor{}
+	}
+
+	if t != LinkStateIPv4PrefixNLRI && t != LinkStateIPv6PrefixNLRI {
+		n.Prefix = LinkStatePrefixDescriptor{}
+	}
+
+	return n, nil
+}
+
+func (d *LinkStateNodeDescriptor) decode(b []byte) error {
+	return walkLinkStateTLVs(b, func(t uint16, v []byte) error {
+		switch t {
+		case linkStateASN, linkStateBGPLSIdentifier, linkStateOSPFAreaID:
+			if len(v) != 4 {
+				return fmt.Errorf("Invalid length %d", len(v))
+			}
+
+			x := uint32(v[0])<<24 | uint32(v[1])<<16 | uint32(v[2])<<8 | uint32(v[3])
+			switch t {
+			case linkStateASN:
+				d.ASN = x
+			case linkStateBGPLSIdentifier:
+				d.BGPLSID = x
+			case linkStateOSPFAreaID:
+				d.OSPFAreaID = x
+			}
+		case linkStateIGPRouterID:
+			d.IGPRouterID = v
+		}
+
+		return nil
+	})
+}
+
+func (d *LinkStateLinkDescriptor) decodeTLV(t uint16, v []byte) error {
+	switch t {
+	case linkStateLinkIdentifiers:
+		if len(v) != 8 {
+			return fmt.Errorf("Invalid length %d", len(v))
+		}
+
+		return decode.Decode(bytes.NewBuffer(v), []interface{}{&d.LocalID, &d.RemoteID})
+	case linkStateIPv4InterfaceAddr, linkStateIPv6InterfaceAddr, linkStateIPv4NeighborAddr, linkStateIPv6NeighborAddr:
+		ip, err := bnet.IPFromBytes(v)
+		if err != nil {
+			return err
+		}
+
+		if t == linkStateIPv4InterfaceAddr || t == linkStateIPv6InterfaceAddr {
+			d.InterfaceAddr = ip.Dedup()
+		} else {
+			d.NeighborAddr = ip.Dedup()
+		}
+	}
+
+	return nil
+}
+
+func (d *LinkStatePrefixDescriptor) decodeReachability(v []byte, ipv6 bool) error {
+	if len(v) < 1 {
+		return fmt.Errorf("Invalid length %d", len(v))
+	}
+
+	addrLen := IPv4Len
+	if ipv6 {
+		addrLen = IPv6Len
+	}
+
+	pfxLen := v[0]
+	if int(pfxLen) > addrLen*8 || len(v) != 1+int(BytesInAddr(pfxLen)) {
+		return fmt.Errorf("Invalid prefix length %d", pfxLen)
+	}
+
+	addr := make([]byte, addrLen)
+	copy(addr, v[1:])
+	ip, err := bnet.IPFromBytes(addr)
+	if err != nil {
+		return err
+	}
+
+	d.Prefix = bnet.NewPfx(ip, pfxLen).Dedup()
+	return nil
+}
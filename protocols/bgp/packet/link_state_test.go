@@ -0,0 +1,216 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeLinkStateNLRI(t *testing.T) {
+	tests := []struct {
+		name     string
+		nlri     *LinkStateNLRI
+		expected []byte
+	}{
+		{
+			name: "Node",
+			nlri: &LinkStateNLRI{
+				Type:       LinkStateNodeNLRI,
+				ProtocolID: LinkStateProtocolISISL2,
+				LocalNode: LinkStateNodeDescriptor{
+					ASN:         65000,
+					IGPRouterID: []byte{1, 2, 3, 4, 5, 6},
+				},
+			},
+			expected: []byte{
+				0, 1, // Type
+				0, 31, // Length
+				2,                      // Protocol ID
+				0, 0, 0, 0, 0, 0, 0, 0, // Identifier
+				1, 0, 0, 18, // Local node descriptors
+				2, 0, 0, 4, 0, 0, 0xfd, 0xe8, // AS
+				2, 3, 0, 6, 1, 2, 3, 4, 5, 6, // IGP router ID
+			},
+		},
+		{
+			name: "IPv4 prefix",
+			nlri: &LinkStateNLRI{
+				Type:       LinkStateIPv4PrefixNLRI,
+				ProtocolID: LinkStateProtocolISISL2,
+				LocalNode: LinkStateNodeDescriptor{
+					IGPRouterID: []byte{1, 2, 3, 4, 5, 6},
+				},
+				Prefix: LinkStatePrefixDescriptor{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Dedup(),
+				},
+			},
+			expected: []byte{
+				0, 3, // Type
+				0, 29, // Length
+				2,                      // Protocol ID
+				0, 0, 0, 0, 0, 0, 0, 0, // Identifier
+				1, 0, 0, 10, // Local node descriptors
+				2, 3, 0, 6, 1, 2, 3, 4, 5, 6, // IGP router ID
+				1, 9, 0, 2, 8, 10, // IP reachability
+			},
+		},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		test.nlri.serialize(buf)
+		assert.Equal(t, test.expected, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestLinkStateUpdate(t *testing.T) {
+	nlris := []*LinkStateNLRI{
+		{
+			Type:       LinkStateNodeNLRI,
+			ProtocolID: LinkStateProtocolISISL2,
+			Identifier: 1,
+			LocalNode: LinkStateNodeDescriptor{
+				ASN:         65000,
+				BGPLSID:     1,
+				IGPRouterID: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		{
+			Type:       LinkStateLinkNLRI,
+			ProtocolID: LinkStateProtocolISISL2,
+			LocalNode: LinkStateNodeDescriptor{
+				IGPRouterID: []byte{1, 2, 3, 4, 5, 6},
+			},
+			RemoteNode: LinkStateNodeDescriptor{
+				IGPRouterID: []byte{1, 2, 3, 4, 5, 7},
+			},
+			Link: LinkStateLinkDescriptor{
+				LocalID:       1,
+				RemoteID:      2,
+				InterfaceAddr: bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+				NeighborAddr:  bnet.IPv4FromOctets(192, 0, 2, 2).Dedup(),
+			},
+		},
+		{
+			Type:       LinkStateIPv6PrefixNLRI,
+			ProtocolID: LinkStateProtocolISISL2,
+			LocalNode: LinkStateNodeDescriptor{
+				IGPRouterID: []byte{1, 2, 3, 4, 5, 6},
+			},
+			Prefix: LinkStatePrefixDescriptor{
+				MTID:   2,
+				Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Dedup(),
+			},
+		},
+	}
+
+	attr := LinkStateAttribute{
+		{
+			Type:  LinkStateNodeName,
+			Value: []byte("router1"),
+		},
+		NewLinkStateTLVUint32(LinkStatePrefixMetric, 10),
+	}
+
+	u := &BGPUpdate{
+		PathAttributes: &PathAttribute{
+			TypeCode: OriginAttr,
+			Value:    uint8(IGP),
+			Next: &PathAttribute{
+				TypeCode: ASPathAttr,
+				Value:    &types.ASPath{},
+				Next: &PathAttribute{
+					TypeCode: MultiProtocolReachNLRICode,
+					Value: MultiProtocolReachNLRI{
+						AFI:       LinkStateAFI,
+						SAFI:      LinkStateSAFI,
+						NextHop:   bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+						LinkState: nlris,
+					},
+					Next: &PathAttribute{
+						TypeCode: LinkStateAttr,
+						Value:    attr,
+					},
+				},
+			},
+		},
+	}
+
+	opt := &EncodeOptions{
+		Use32BitASN: true,
+	}
+	b, err := u.SerializeUpdate(opt)
+	if err != nil {
+		t.Fatalf("Unable to serialize update: %v", err)
+	}
+
+	m, err := Decode(bytes.NewBuffer(b), &DecodeOptions{
+		Use32BitASN: true,
+	})
+	if err != nil {
+		t.Fatalf("Unable to decode update: %v", err)
+	}
+
+	var mp MultiProtocolReachNLRI
+	var decodedAttr LinkStateAttribute
+	for pa := m.Body.(*BGPUpdate).PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case MultiProtocolReachNLRICode:
+			mp = pa.Value.(MultiProtocolReachNLRI)
+		case LinkStateAttr:
+			decodedAttr = pa.Value.(LinkStateAttribute)
+		}
+	}
+
+	assert.Equal(t, uint16(LinkStateAFI), mp.AFI)
+	assert.Equal(t, uint8(LinkStateSAFI), mp.SAFI)
+	assert.Equal(t, nlris, mp.LinkState)
+	assert.Equal(t, attr, decodedAttr)
+	assert.Equal(t, []byte("router1"), decodedAttr.Get(LinkStateNodeName))
+}
+
+func TestDecodeLinkStateNLRIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantFail bool
+		expected int
+	}{
+		{
+			name: "Unknown type is skipped",
+			input: []byte{
+				0, 99, 0, 2, 1, 2,
+			},
+			expected: 0,
+		},
+		{
+			name: "Missing local node descriptors",
+			input: []byte{
+				0, 1, 0, 9,
+				2, 0, 0, 0, 0, 0, 0, 0, 0,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Truncated",
+			input: []byte{
+				0, 1, 0, 20, 2,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		nlris, err := decodeLinkStateNLRIs(test.input)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, len(nlris), "Test %q", test.name)
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -16,25 +16,65 @@ type MultiProtocolReachNLRI struct {
 	SAFI    uint8
 	NextHop *bnet.IP
 	NLRI    *NLRI
+
+	// LinkState holds the NLRIs of the BGP-LS address family (RFC7752)
+	LinkState []*LinkStateNLRI
+
+	// RouteTargets holds the NLRIs of the route target constraint address family (RFC4684)
+	RouteTargets []*RouteTargetNLRI
+
+	// FlowSpec holds the NLRIs of the FlowSpec address families (RFC8955, RFC8956)
+	FlowSpec []*FlowSpecNLRI
+
+	// SRPolicies holds the NLRIs of the SR Policy address families (RFC9830)
+	SRPolicies []*SRPolicyNLRI
 }
 
 func (n *MultiProtocolReachNLRI) serialize(buf *bytes.Buffer, opt *EncodeOptions) uint16 {
-	nextHop := n.NextHop.Bytes()
+	var nextHop []byte
+	if n.NextHop != nil {
+		// FlowSpec routes do not need a next hop (RFC8955 6)
+		nextHop = n.NextHop.Bytes()
+	}
 
-	tempBuf := bytes.NewBuffer(nil)
-	tempBuf.Write(convert.Uint16Byte(n.AFI))
-	tempBuf.WriteByte(n.SAFI)
-	tempBuf.WriteByte(uint8(len(nextHop)))
-	tempBuf.Write(nextHop)
-	tempBuf.WriteByte(0) // RESERVED
+	if n.SAFI == MPLSVPNSAFI {
+		// the next hop is prefixed by a zero route distinguisher (RFC4364 4.3.2, RFC4659 3.2)
+		nextHop = append(make([]byte, RouteDistinguisherLen), nextHop...)
+	}
 
-	for cur := n.NLRI; cur != nil; cur = cur.Next {
-		cur.serialize(tempBuf, opt.UseAddPath)
+	start := buf.Len()
+	endian.WriteUint16(buf, n.AFI)
+	buf.WriteByte(n.SAFI)
+	buf.WriteByte(uint8(len(nextHop)))
+	buf.Write(nextHop)
+	buf.WriteByte(0) // RESERVED
+
+	for _, n := range n.LinkState {
+		n.serialize(buf)
 	}
 
-	buf.Write(tempBuf.Bytes())
+	for _, n := range n.RouteTargets {
+		n.serialize(buf)
+	}
+
+	for _, n := range n.FlowSpec {
+		n.serialize(buf)
+	}
+
+	for _, n := range n.SRPolicies {
+		n.serialize(buf)
+	}
+
+	for cur := n.NLRI; cur != nil; cur = cur.Next {
+		if IsLabeledSAFI(n.SAFI) {
+			cur.serializeLabeled(buf, opt.UseAddPath, n.SAFI)
+			continue
+		}
+
+		cur.serialize(buf, opt.UseAddPath)
+	}
 
-	return uint16(tempBuf.Len())
+	return uint16(buf.Len() - start)
 }
 
 func deserializeMultiProtocolReachNLRI(b []byte, opt *DecodeOptions) (MultiProtocolReachNLRI, error) {
@@ -64,16 +104,29 @@ func deserializeMultiProtocolReachNLRI(b []byte, opt *DecodeOptions) (MultiProto
 			fmt.Errorf("Failed to decode next hop IP: expected %d bytes for NLRI, only %d remaining", nextHopLength, budget)
 	}
 
-	firstNextHopLength := nextHopLength
-	if nextHopLength == 32 {
+	nextHopStart := uint8(0)
+	nextHopEnd := nextHopLength
+	if n.SAFI == MPLSVPNSAFI {
+		// skip the route distinguisher of VPN next hops (RFC4364 4.3.2, RFC4659 3.2)
+		if nextHopLength <= RouteDistinguisherLen {
+			return MultiProtocolReachNLRI{}, fmt.Errorf("Invalid VPN next hop length %d", nextHopLength)
+		}
+
+		nextHopStart = RouteDistinguisherLen
+		if nextHopLength == 2*(RouteDistinguisherLen+IPv6Len) {
+			nextHopEnd = RouteDistinguisherLen + IPv6Len
+		}
+	} else if nextHopLength == 32 {
 		// second next-hop is lladdr (see rfc2545 sec 3 par 2)
-		firstNextHopLength = 16
+		nextHopEnd = 16
 	}
-	nh, err := bnet.IPFromBytes(variable[:firstNextHopLength])
-	if err != nil {
-		return MultiProtocolReachNLRI{}, errors.Wrap(err, "Failed to decode next hop IP")
+	if nextHopLength > 0 || n.SAFI != FlowSpecSAFI {
+		nh, err := bnet.IPFromBytes(variable[nextHopStart:nextHopEnd])
+		if err != nil {
+			return MultiProtocolReachNLRI{}, errors.Wrap(err, "Failed to decode next hop IP")
+		}
+		n.NextHop = nh.Dedup()
 	}
-	n.NextHop = nh.Dedup()
 	budget -= int(nextHopLength)
 
 	if budget == 0 {
@@ -82,8 +135,44 @@ func deserializeMultiProtocolReachNLRI(b []byte, opt *DecodeOptions) (MultiProto
 
 	variable = variable[1+nextHopLength:] // 1 <- RESERVED field
 
+	if n.SAFI == RouteTargetConstraintSAFI {
+		n.RouteTargets, err = decodeRouteTargetNLRIs(variable)
+		if err != nil {
+			return MultiProtocolReachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.SAFI == FlowSpecSAFI {
+		n.FlowSpec, err = decodeFlowSpecNLRIs(variable, n.AFI)
+		if err != nil {
+			return MultiProtocolReachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.SAFI == SRPolicySAFI {
+		n.SRPolicies, err = decodeSRPolicyNLRIs(variable, n.AFI)
+		if err != nil {
+			return MultiProtocolReachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.AFI == LinkStateAFI {
+		n.LinkState, err = decodeLinkStateNLRIs(variable)
+		if err != nil {
+			return MultiProtocolReachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
 	buf := bytes.NewBuffer(variable)
-	nlri, err := decodeNLRIs(buf, uint16(buf.Len()), n.AFI, opt.addPath(int(n.AFI), int(n.SAFI)))
+	nlri, err := decodeMultiProtocolNLRIs(buf, uint16(buf.Len()), n.AFI, n.SAFI, opt.addPath(int(n.AFI), int(n.SAFI)), false)
 	if err != nil {
 		return MultiProtocolReachNLRI{}, err
 	}
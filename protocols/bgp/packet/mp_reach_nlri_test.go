@@ -54,6 +54,68 @@ func TestSerializeMultiProtocolReachNLRI(t *testing.T) {
 			},
 			addPath: true,
 		},
+		{
+			name: "IPv4 prefix via IPv6 next hop (RFC8950)",
+			nlri: MultiProtocolReachNLRI{
+				AFI:     IPv4AFI,
+				SAFI:    UnicastSAFI,
+				NextHop: bnet.IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0x2).Dedup(),
+				NLRI: &NLRI{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Dedup(),
+				},
+			},
+			expected: []byte{
+				0x00, 0x01, // AFI
+				0x01,                                                                                                 // SAFI
+				0x10, 0x20, 0x01, 0x06, 0x78, 0x01, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, // NextHop
+				0x00,         // RESERVED
+				24, 10, 1, 2, // Prefix
+			},
+		},
+		{
+			name: "Labeled IPv4 unicast prefix",
+			nlri: MultiProtocolReachNLRI{
+				AFI:     IPv4AFI,
+				SAFI:    LabeledUnicastSAFI,
+				NextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+				NLRI: &NLRI{
+					Labels: []uint32{ImplicitNullLabel},
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Dedup(),
+				},
+			},
+			expected: []byte{
+				0x00, 0x01, // AFI
+				0x04,               // SAFI
+				0x04, 192, 0, 2, 1, // NextHop
+				0x00,             // RESERVED
+				0x30,             // Length
+				0x00, 0x00, 0x31, // Label
+				10, 1, 2, // Prefix
+			},
+		},
+		{
+			name: "VPNv4 prefix",
+			nlri: MultiProtocolReachNLRI{
+				AFI:     IPv4AFI,
+				SAFI:    MPLSVPNSAFI,
+				NextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+				NLRI: &NLRI{
+					Labels:             []uint32{100},
+					RouteDistinguisher: 65000<<32 + 1,
+					Prefix:             bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Dedup(),
+				},
+			},
+			expected: []byte{
+				0x00, 0x01, // AFI
+				0x80,                                                               // SAFI
+				0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 192, 0, 2, 1, // NextHop
+				0x00,             // RESERVED
+				0x70,             // Length
+				0x00, 0x06, 0x41, // Label
+				0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, // RD
+				10, 1, 2, // Prefix
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 // MultiProtocolUnreachNLRI represents network layer withdraw information for one prefix of an IP address family (rfc4760)
@@ -13,20 +13,51 @@ type MultiProtocolUnreachNLRI struct {
 	AFI  uint16
 	SAFI uint8
 	NLRI *NLRI
+
+	// LinkState holds the NLRIs of the BGP-LS address family (RFC7752)
+	LinkState []*LinkStateNLRI
+
+	// RouteTargets holds the NLRIs of the route target constraint address family (RFC4684)
+	RouteTargets []*RouteTargetNLRI
+
+	// FlowSpec holds the NLRIs of the FlowSpec address families (RFC8955, RFC8956)
+	FlowSpec []*FlowSpecNLRI
+
+	// SRPolicies holds the NLRIs of the SR Policy address families (RFC9830)
+	SRPolicies []*SRPolicyNLRI
 }
 
 func (n *MultiProtocolUnreachNLRI) serialize(buf *bytes.Buffer, opt *EncodeOptions) uint16 {
-	tempBuf := bytes.NewBuffer(nil)
-	tempBuf.Write(convert.Uint16Byte(n.AFI))
-	tempBuf.WriteByte(n.SAFI)
+	start := buf.Len()
+	endian.WriteUint16(buf, n.AFI)
+	buf.WriteByte(n.SAFI)
 
-	for cur := n.NLRI; cur != nil; cur = cur.Next {
-		cur.serialize(tempBuf, opt.UseAddPath)
+	for _, n := range n.LinkState {
+		n.serialize(buf)
+	}
+
+	for _, n := range n.RouteTargets {
+		n.serialize(buf)
 	}
 
-	buf.Write(tempBuf.Bytes())
+	for _, n := range n.FlowSpec {
+		n.serialize(buf)
+	}
 
-	return uint16(tempBuf.Len())
+	for _, n := range n.SRPolicies {
+		n.serialize(buf)
+	}
+
+	for cur := n.NLRI; cur != nil; cur = cur.Next {
+		if IsLabeledSAFI(n.SAFI) {
+			cur.serializeLabeled(buf, opt.UseAddPath, n.SAFI)
+			continue
+		}
+
+		cur.serialize(buf, opt.UseAddPath)
+	}
+
+	return uint16(buf.Len() - start)
 }
 
 func deserializeMultiProtocolUnreachNLRI(b []byte, opt *DecodeOptions) (MultiProtocolUnreachNLRI, error) {
@@ -52,8 +83,44 @@ func deserializeMultiProtocolUnreachNLRI(b []byte, opt *DecodeOptions) (MultiPro
 		return n, nil
 	}
 
+	if n.SAFI == RouteTargetConstraintSAFI {
+		n.RouteTargets, err = decodeRouteTargetNLRIs(nlris)
+		if err != nil {
+			return MultiProtocolUnreachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.SAFI == FlowSpecSAFI {
+		n.FlowSpec, err = decodeFlowSpecNLRIs(nlris, n.AFI)
+		if err != nil {
+			return MultiProtocolUnreachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.SAFI == SRPolicySAFI {
+		n.SRPolicies, err = decodeSRPolicyNLRIs(nlris, n.AFI)
+		if err != nil {
+			return MultiProtocolUnreachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
+	if n.AFI == LinkStateAFI {
+		n.LinkState, err = decodeLinkStateNLRIs(nlris)
+		if err != nil {
+			return MultiProtocolUnreachNLRI{}, err
+		}
+
+		return n, nil
+	}
+
 	buf := bytes.NewBuffer(nlris)
-	nlri, err := decodeNLRIs(buf, uint16(buf.Len()), n.AFI, opt.addPath(int(n.AFI), int(n.SAFI)))
+	nlri, err := decodeMultiProtocolNLRIs(buf, uint16(buf.Len()), n.AFI, n.SAFI, opt.addPath(int(n.AFI), int(n.SAFI)), true)
 	if err != nil {
 		return MultiProtocolUnreachNLRI{}, err
 	}
@@ -48,6 +48,25 @@ func TestSerializeMultiProtocolUnreachNLRI(t *testing.T) {
 			},
 			addPath: true,
 		},
+		{
+			name: "VPNv6 prefix",
+			nlri: MultiProtocolUnreachNLRI{
+				AFI:  IPv6AFI,
+				SAFI: MPLSVPNSAFI,
+				NLRI: &NLRI{
+					RouteDistinguisher: 65000<<32 + 1,
+					Prefix:             bnet.NewPfx(bnet.IPv6FromBlocks(0x2620, 0x110, 0x9000, 0, 0, 0, 0, 0), 44).Dedup(),
+				},
+			},
+			expected: []byte{
+				0x00, 0x02, // AFI
+				0x80,             // SAFI
+				0x84,             // Length
+				0x80, 0x00, 0x00, // Withdraw label
+				0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, // RD
+				0x26, 0x20, 0x01, 0x10, 0x90, 0x00, // Prefix
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -7,19 +7,39 @@ import (
 
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
 const (
 	PathIdentifierLen = 4
+
+	// WithdrawLabel is sent as label of withdrawn labeled NLRIs (RFC8277 2.4)
+	WithdrawLabel = 0x800000
+
+	// ImplicitNullLabel is advertised for routes the upstream router has to pop the label for (RFC3032)
+	ImplicitNullLabel = 3
+
+	labelBottomOfStack = 0x01
 )
 
 // NLRI represents a Network Layer Reachability Information
 type NLRI struct {
 	PathIdentifier uint32
-	Prefix         *bnet.Prefix
-	Next           *NLRI
+
+	// Labels is the MPLS label stack of labeled address families (RFC8277)
+	Labels []uint32
+
+	// RouteDistinguisher is the route distinguisher of VPN address families (RFC4364)
+	RouteDistinguisher uint64
+
+	Prefix *bnet.Prefix
+	Next   *NLRI
+}
+
+// IsLabeledSAFI returns if NLRIs of the SAFI carry a label stack
+func IsLabeledSAFI(safi uint8) bool {
+	return safi == LabeledUnicastSAFI || safi == MPLSVPNSAFI
 }
 
 func decodeNLRIs(buf *bytes.Buffer, length uint16, afi uint16, addPath bool) (*NLRI, error) {
@@ -50,6 +70,118 @@ func decodeNLRIs(buf *bytes.Buffer, length uint16, afi uint16, addPath bool) (*N
 	return ret, nil
 }
 
+// decodeMultiProtocolNLRIs decodes the NLRIs of a MP_REACH_NLRI or MP_UNREACH_NLRI attribute
+func decodeMultiProtocolNLRIs(buf *bytes.Buffer, length uint16, afi uint16, safi uint8, addPath bool, withdraw bool) (*NLRI, error) {
+	if !IsLabeledSAFI(safi) {
+		return decodeNLRIs(buf, length, afi, addPath)
+	}
+
+	var ret *NLRI
+	var eol *NLRI
+	p := uint16(0)
+
+	for p < length {
+		nlri, consumed, err := decodeLabeledNLRI(buf, afi, safi, addPath, withdraw)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode NLRI")
+		}
+		p += consumed
+
+		if ret == nil {
+			ret = nlri
+			eol = nlri
+			continue
+		}
+
+		eol.Next = nlri
+		eol = nlri
+	}
+
+	return ret, nil
+}
+
+// decodeLabeledNLRI decodes an NLRI carrying a label stack (RFC8277) and for VPN SAFIs a route distinguisher (RFC4364).
+// The label field of withdrawn NLRIs is ignored.
+func decodeLabeledNLRI(buf *bytes.Buffer, afi uint16, safi uint8, addPath bool, withdraw bool) (*NLRI, uint16, error) {
+	nlri := &NLRI{}
+	consumed := uint16(0)
+
+	if addPath {
+		err := decode.Decode(buf, []interface{}{
+			&nlri.PathIdentifier,
+		})
+		if err != nil {
+			return nil, consumed, errors.Wrap(err, "Unable to decode path identifier")
+		}
+
+		consumed += PathIdentifierLen
+	}
+
+	bits, err := buf.ReadByte()
+	if err != nil {
+		return nil, consumed, err
+	}
+	consumed++
+	remaining := int(bits)
+
+	label := make([]byte, LabelLen)
+	for {
+		remaining -= LabelLen * 8
+		if remaining < 0 {
+			return nil, consumed, fmt.Errorf("NLRI length %d too short for label stack", bits)
+		}
+
+		n, _ := buf.Read(label)
+		consumed += uint16(n)
+		if n < LabelLen {
+			return nil, consumed, fmt.Errorf("expected %d bytes for label, only %d remaining", LabelLen, n)
+		}
+
+		if withdraw {
+			break
+		}
+
+		nlri.Labels = append(nlri.Labels, endian.Uint24(label)>>4)
+		if label[2]&labelBottomOfStack != 0 {
+			break
+		}
+	}
+
+	if safi == MPLSVPNSAFI {
+		remaining -= RouteDistinguisherLen * 8
+		if remaining < 0 {
+			return nil, consumed, fmt.Errorf("NLRI length %d too short for route distinguisher", bits)
+		}
+
+		rd := make([]byte, RouteDistinguisherLen)
+		n, _ := buf.Read(rd)
+		consumed += uint16(n)
+		if n < RouteDistinguisherLen {
+			return nil, consumed, fmt.Errorf("expected %d bytes for route distinguisher, only %d remaining", RouteDistinguisherLen, n)
+		}
+
+		nlri.RouteDistinguisher = endian.Uint64(rd)
+	}
+
+	pfxLen := uint8(remaining)
+	numBytes := BytesInAddr(pfxLen)
+	addr := make([]byte, numBytes)
+
+	n, _ := buf.Read(addr)
+	consumed += uint16(n)
+	if n < int(numBytes) {
+		return nil, consumed, fmt.Errorf("expected %d bytes for NLRI, only %d remaining", numBytes, n)
+	}
+
+	pfx, err := deserializePrefix(addr, pfxLen, afi)
+	if err != nil {
+		return nil, consumed, err
+	}
+	nlri.Prefix = pfx
+
+	return nlri, consumed, nil
+}
+
 func decodeNLRI(buf *bytes.Buffer, afi uint16, addPath bool) (*NLRI, uint8, error) {
 	nlri := &NLRI{}
 
@@ -94,7 +226,7 @@ func (n *NLRI) serialize(buf *bytes.Buffer, addPath bool) uint8 {
 	numBytes := uint8(0)
 
 	if addPath {
-		buf.Write(convert.Uint32Byte(n.PathIdentifier))
+		endian.WriteUint32(buf, n.PathIdentifier)
 		numBytes += 4
 	}
 
@@ -108,6 +240,49 @@ func (n *NLRI) serialize(buf *bytes.Buffer, addPath bool) uint8 {
 	return numBytes
 }
 
+// serializeLabeled serializes an NLRI of a labeled SAFI. NLRIs without labels are sent with WithdrawLabel.
+func (n *NLRI) serializeLabeled(buf *bytes.Buffer, addPath bool, safi uint8) uint16 {
+	numBytes := uint16(0)
+
+	if addPath {
+		endian.WriteUint32(buf, n.PathIdentifier)
+		numBytes += PathIdentifierLen
+	}
+
+	labels := n.Labels
+	if len(labels) == 0 {
+		labels = []uint32{WithdrawLabel >> 4}
+	}
+
+	bits := len(labels)*LabelLen*8 + int(n.Prefix.Pfxlen())
+	if safi == MPLSVPNSAFI {
+		bits += RouteDistinguisherLen * 8
+	}
+	buf.WriteByte(uint8(bits))
+	numBytes++
+
+	for i, l := range labels {
+		v := l << 4
+		if i == len(labels)-1 && len(n.Labels) > 0 {
+			v |= labelBottomOfStack
+		}
+
+		endian.WriteUint24(buf, v)
+		numBytes += LabelLen
+	}
+
+	if safi == MPLSVPNSAFI {
+		endian.WriteUint64(buf, n.RouteDistinguisher)
+		numBytes += RouteDistinguisherLen
+	}
+
+	pfxNumBytes := BytesInAddr(n.Prefix.Pfxlen())
+	buf.Write(n.Prefix.Addr().Bytes()[:pfxNumBytes])
+	numBytes += uint16(pfxNumBytes)
+
+	return numBytes
+}
+
 // BytesInAddr gets the amount of bytes needed to encode an NLRI of prefix length pfxlen
 func BytesInAddr(pfxlen uint8) uint8 {
 	return uint8(math.Ceil(float64(pfxlen) / 8))
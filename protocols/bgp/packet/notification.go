@@ -0,0 +1,147 @@
+package packet
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+var errorCodeNames = map[uint8]string{
+	MessageHeaderError:       "Message Header Error",
+	OpenMessageError:         "OPEN Message Error",
+	UpdateMessageError:       "UPDATE Message Error",
+	HoldTimeExpired:          "Hold Timer Expired",
+	FiniteStateMachineError:  "Finite State Machine Error",
+	Cease:                    "Cease",
+	RouteRefreshMessageError: "ROUTE-REFRESH Message Error",
+}
+
+var errorSubcodeNames = map[uint8]map[uint8]string{
+	MessageHeaderError: {
+		ConnectionNotSync: "Connection Not Synchronized",
+		BadMessageLength:  "Bad Message Length",
+		BadMessageType:    "Bad Message Type",
+	},
+	OpenMessageError: {
+		UnsupportedVersionNumber:     "Unsupported Version Number",
+		BadPeerAS:                    "Bad Peer AS",
+		BadBGPIdentifier:             "Bad BGP Identifier",
+		UnsupportedOptionalParameter: "Unsupported Optional Parameter",
+		UnacceptableHoldTime:         "Unacceptable Hold Time",
+	},
+	UpdateMessageError: {
+		MalformedAttributeList:    "Malformed Attribute List",
+		UnrecognizedWellKnownAttr: "Unrecognized Well-known Attribute",
+		MissingWellKnownAttr:      "Missing Well-known Attribute",
+		AttrFlagsError:            "Attribute Flags Error",
+		AttrLengthError:           "Attribute Length Error",
+		InvalidOriginAttr:         "Invalid ORIGIN Attribute",
+		InvalidNextHopAttr:        "Invalid NEXT_HOP Attribute",
+		OptionalAttrError:         "Optional Attribute Error",
+		InvalidNetworkField:       "Invalid Network Field",
+		MalformedASPath:           "Malformed AS_PATH",
+	},
+	Cease: {
+		MaxPrefReached:                "Maximum Number of Prefixes Reached",
+		AdminShut:                     "Administrative Shutdown",
+		PeerDeconfigured:              "Peer De-configured",
+		AdminReset:                    "Administrative Reset",
+		ConnectionRejected:            "Connection Rejected",
+		OtherConfigChange:             "Other Configuration Change",
+		ConnectionCollisionResolution: "Connection Collision Resolution",
+		OutOfResources:                "Out of Resources",
+	},
+	RouteRefreshMessageError: {
+		InvalidRouteRefreshMessageLength: "Invalid Message Length",
+	},
+}
+
+// Description returns a human readable description of the NOTIFICATION including its decoded data
+func (n *BGPNotification) Description() string {
+	ret, ok := errorCodeNames[n.ErrorCode]
+	if !ok {
+		ret = fmt.Sprintf("Error code %d", n.ErrorCode)
+	}
+
+	if n.ErrorSubcode != 0 {
+		if name, ok := errorSubcodeNames[n.ErrorCode][n.ErrorSubcode]; ok {
+			ret += "/" + name
+		} else {
+			ret += fmt.Sprintf("/subcode %d", n.ErrorSubcode)
+		}
+	}
+
+	if d := n.dataDescription(); d != "" {
+		ret += ": " + d
+	}
+
+	return ret
+}
+
+func (n *BGPNotification) dataDescription() string {
+	if len(n.Data) == 0 {
+		return ""
+	}
+
+	switch n.ErrorCode {
+	case Cease:
+		if n.ErrorSubcode == AdminShut || n.ErrorSubcode == AdminReset {
+			if text, ok := n.ShutdownCommunication(); ok {
+				return fmt.Sprintf("%q", text)
+			}
+		}
+	case UpdateMessageError:
+		switch n.ErrorSubcode {
+		case MissingWellKnownAttr:
+			return fmt.Sprintf("attribute %d", n.Data[0])
+		case UnrecognizedWellKnownAttr, AttrFlagsError, AttrLengthError, InvalidOriginAttr, InvalidNextHopAttr, OptionalAttrError, MalformedASPath:
+			if len(n.Data) >= 2 {
+				return fmt.Sprintf("attribute %d", n.Data[1])
+			}
+		}
+	}
+
+	return fmt.Sprintf("data %x", n.Data)
+}
+
+// MaxShutdownCommunicationLen is the maximum length of a shutdown communication in bytes (RFC9003)
+const MaxShutdownCommunicationLen = 255
+
+// NewShutdownNotification creates an administrative shutdown or reset Cease NOTIFICATION. A communication exceeding
+// MaxShutdownCommunicationLen is truncated (RFC9003).
+func NewShutdownNotification(subcode uint8, communication string) *BGPNotification {
+	n := &BGPNotification{
+		ErrorCode:    Cease,
+		ErrorSubcode: subcode,
+	}
+
+	if communication == "" {
+		return n
+	}
+
+	b := []byte(communication)
+	if len(b) > MaxShutdownCommunicationLen {
+		b = b[:MaxShutdownCommunicationLen]
+
+		// Do not cut a multi byte character
+		for len(b) > 0 && !utf8.Valid(b) {
+			b = b[:len(b)-1]
+		}
+	}
+
+	n.Data = append([]byte{uint8(len(b))}, b...)
+	return n
+}
+
+// ShutdownCommunication gets the shutdown communication of an administrative shutdown or reset (RFC9003)
+func (n *BGPNotification) ShutdownCommunication() (string, bool) {
+	if n.ErrorCode != Cease || (n.ErrorSubcode != AdminShut && n.ErrorSubcode != AdminReset) || len(n.Data) == 0 {
+		return "", false
+	}
+
+	l := int(n.Data[0])
+	if l > len(n.Data)-1 || !utf8.Valid(n.Data[1:1+l]) {
+		return "", false
+	}
+
+	return string(n.Data[1 : 1+l]), true
+}
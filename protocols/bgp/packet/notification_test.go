@@ -0,0 +1,124 @@
+package packet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        *BGPNotification
+		expected string
+	}{
+		{
+			name: "Hold timer expired",
+			n: &BGPNotification{
+				ErrorCode: HoldTimeExpired,
+			},
+			expected: "Hold Timer Expired",
+		},
+		{
+			name: "Administrative shutdown with communication",
+			n: &BGPNotification{
+				ErrorCode:    Cease,
+				ErrorSubcode: AdminShut,
+				Data:         append([]byte{4}, "test"...),
+			},
+			expected: "Cease/Administrative Shutdown: \"test\"",
+		},
+		{
+			name: "Administrative shutdown with invalid communication length",
+			n: &BGPNotification{
+				ErrorCode:    Cease,
+				ErrorSubcode: AdminShut,
+				Data:         append([]byte{5}, "test"...),
+			},
+			expected: "Cease/Administrative Shutdown: data 0574657374",
+		},
+		{
+			name: "Optional attribute error",
+			n: &BGPNotification{
+				ErrorCode:    UpdateMessageError,
+				ErrorSubcode: OptionalAttrError,
+				Data:         []byte{0x80, MultiProtocolReachNLRICode, 0},
+			},
+			expected: "UPDATE Message Error/Optional Attribute Error: attribute 14",
+		},
+		{
+			name: "Missing well-known attribute",
+			n: &BGPNotification{
+				ErrorCode:    UpdateMessageError,
+				ErrorSubcode: MissingWellKnownAttr,
+				Data:         []byte{OriginAttr},
+			},
+			expected: "UPDATE Message Error/Missing Well-known Attribute: attribute 1",
+		},
+		{
+			name: "Unknown subcode",
+			n: &BGPNotification{
+				ErrorCode:    Cease,
+				ErrorSubcode: 42,
+			},
+			expected: "Cease/subcode 42",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.n.Description(), "Test %q", test.name)
+	}
+}
+
+func TestNotificationWithData(t *testing.T) {
+	n := &BGPNotification{
+		ErrorCode:    Cease,
+		ErrorSubcode: AdminReset,
+		Data:         append([]byte{7}, "upgrade"...),
+	}
+
+	msg, err := Decode(bytes.NewBuffer(SerializeNotificationMsg(n)), &DecodeOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, n, msg.Body)
+
+	text, ok := msg.Body.(*BGPNotification).ShutdownCommunication()
+	assert.True(t, ok)
+	assert.Equal(t, "upgrade", text)
+}
+
+func TestNewShutdownNotification(t *testing.T) {
+	tests := []struct {
+		name          string
+		communication string
+		expected      string
+	}{
+		{
+			name: "No communication",
+		},
+		{
+			name:          "Communication",
+			communication: "maintenance, ticket 1234",
+			expected:      "maintenance, ticket 1234",
+		},
+		{
+			name:          "Truncated communication",
+			communication: strings.Repeat("a", 254) + "äb",
+			expected:      strings.Repeat("a", 254),
+		},
+	}
+
+	for _, test := range tests {
+		n := NewShutdownNotification(AdminShut, test.communication)
+		assert.Equal(t, uint8(Cease), n.ErrorCode, "Test %q", test.name)
+		assert.Equal(t, uint8(AdminShut), n.ErrorSubcode, "Test %q", test.name)
+
+		text, ok := n.ShutdownCommunication()
+		assert.Equal(t, test.communication != "", ok, "Test %q", test.name)
+		assert.Equal(t, test.expected, text, "Test %q", test.name)
+	}
+}
@@ -3,7 +3,7 @@ package packet
 import (
 	"bytes"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 type Serializable interface {
@@ -50,7 +50,7 @@ type AddPathCapabilityTuple struct {
 }
 
 func (a AddPathCapabilityTuple) serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(a.AFI))
+	endian.WriteUint16(buf, a.AFI)
 	buf.WriteByte(a.SAFI)
 	buf.WriteByte(a.SendReceive)
 }
@@ -68,7 +68,7 @@ type ASN4Capability struct {
 }
 
 func (a ASN4Capability) serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint32Byte(a.ASN4))
+	endian.WriteUint32(buf, a.ASN4)
 }
 
 type MultiProtocolCapability struct {
@@ -77,7 +77,104 @@ type MultiProtocolCapability struct {
 }
 
 func (a MultiProtocolCapability) serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(a.AFI))
+	endian.WriteUint16(buf, a.AFI)
 	buf.WriteByte(0) // RESERVED
 	buf.WriteByte(a.SAFI)
 }
+
+const (
+	// GracefulRestartCapabilityCode is the code of the graceful restart capability (RFC4724)
+	GracefulRestartCapabilityCode = 64
+
+	// MaxGracefulRestartTime is the largest restart time (in seconds) the capability can carry
+	MaxGracefulRestartTime = 0x0fff
+
+	gracefulRestartStateFlag           = 0x8000
+	gracefulRestartForwardingStateFlag = 0x80
+	gracefulRestartTupleSize           = 4
+)
+
+// GracefulRestartCapability announces the ability to preserve forwarding state during a BGP restart (RFC4724)
+type GracefulRestartCapability struct {
+	RestartState bool
+	RestartTime  uint16
+	Tuples       []GracefulRestartCapabilityTuple
+}
+
+// GracefulRestartCapabilityTuple is an address family for which graceful restart is supported
+type GracefulRestartCapabilityTuple struct {
+	AFI             uint16
+	SAFI            uint8
+	ForwardingState bool
+}
+
+func (g GracefulRestartCapability) serialize(buf *bytes.Buffer) {
+	flagsTime := g.RestartTime & MaxGracefulRestartTime
+	if g.RestartState {
+		flagsTime |= gracefulRestartStateFlag
+	}
+	endian.WriteUint16(buf, flagsTime)
+
+	for _, t := range g.Tuples {
+		t.serialize(buf)
+	}
+}
+
+func (t GracefulRestartCapabilityTuple) serialize(buf *bytes.Buffer) {
+	endian.WriteUint16(buf, t.AFI)
+	buf.WriteByte(t.SAFI)
+
+	flags := uint8(0)
+	if t.ForwardingState {
+		flags |= gracefulRestartForwardingStateFlag
+	}
+	buf.WriteByte(flags)
+}
+
+// AddressFamily gets the tuple of an address family. Returns nil if the family is not covered.
+func (g GracefulRestartCapability) AddressFamily(afi uint16, safi uint8) *GracefulRestartCapabilityTuple {
+	for i := range g.Tuples {
+		if g.Tuples[i].AFI == afi && g.Tuples[i].SAFI == safi {
+			return &g.Tuples[i]
+		}
+	}
+
+	return nil
+}
+
+const (
+	// ExtendedNextHopCapabilityCode is the code of the extended next hop encoding capability (RFC8950)
+	ExtendedNextHopCapabilityCode = 5
+
+	extendedNextHopTupleSize = 6
+)
+
+// ExtendedNextHopCapability announces the ability to receive NLRI of an address family with next hops of another
+// address family, e.g. IPv4 routes via IPv6 next hops (RFC8950)
+type ExtendedNextHopCapability []ExtendedNextHopCapabilityTuple
+
+// ExtendedNextHopCapabilityTuple is an address family of NLRI and the address family of their next hops
+type ExtendedNextHopCapabilityTuple struct {
+	AFI        uint16
+	SAFI       uint16
+	NextHopAFI uint16
+}
+
+func (e ExtendedNextHopCapability) serialize(buf *bytes.Buffer) {
+	for _, t := range e {
+		endian.WriteUint16(buf, t.AFI)
+		endian.WriteUint16(buf, t.SAFI)
+		endian.WriteUint16(buf, t.NextHopAFI)
+	}
+}
+
+// Supports checks if NLRI of afi/safi with next hops of nextHopAFI are covered
+func (e ExtendedNextHopCapability) Supports(afi uint16, safi uint8, nextHopAFI uint16) bool {
+	for _, t := range e {
+		if t.AFI == afi && t.SAFI == uint16(safi) && t.NextHopAFI == nextHopAFI {
+			return true
+		}
+	}
+
+	return false
+}
@@ -8,8 +8,9 @@ import (
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/util/bufpool"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -76,28 +77,46 @@ func decodePathAttrs(buf *bytes.Buffer, tpal uint16, opt *DecodeOptions) (*PathA
 func decodePathAttr(buf *bytes.Buffer, opt *DecodeOptions) (pa *PathAttribute, consumed uint16, err error) {
 	pa = &PathAttribute{}
 
+	consumed, err = pa.decodeHeader(buf)
+	if err != nil {
+		return nil, consumed, err
+	}
+
+	err = pa.decodeValue(buf, opt)
+	if err != nil {
+		return nil, consumed, err
+	}
+
+	return pa, consumed + pa.Length, nil
+}
+
+func (pa *PathAttribute) decodeHeader(buf *bytes.Buffer) (consumed uint16, err error) {
 	err = decodePathAttrFlags(buf, pa)
 	if err != nil {
-		return nil, consumed, errors.Wrap(err, "Unable to get path attribute flags")
+		return consumed, errors.Wrap(err, "Unable to get path attribute flags")
 	}
 	consumed++
 
 	err = decode.DecodeUint8(buf, &pa.TypeCode)
 	if err != nil {
-		return nil, consumed, err
+		return consumed, err
 	}
 	consumed++
 
 	n, err := pa.setLength(buf)
 	if err != nil {
-		return nil, consumed, err
+		return consumed, err
 	}
 	consumed += uint16(n)
 
+	return consumed, nil
+}
+
+func (pa *PathAttribute) decodeValue(buf *bytes.Buffer, opt *DecodeOptions) error {
 	switch pa.TypeCode {
 	case OriginAttr:
 		if err := pa.decodeOrigin(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode Origin")
+			return errors.Wrap(err, "Failed to decode Origin")
 		}
 	case ASPathAttr:
 		asnLength := uint8(2)
@@ -106,66 +125,78 @@ func decodePathAttr(buf *bytes.Buffer, opt *DecodeOptions) (pa *PathAttribute, c
 		}
 
 		if err := pa.decodeASPath(buf, asnLength); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode AS Path")
+			return errors.Wrap(err, "Failed to decode AS Path")
 		}
 	/* Don't decodeAS4Paths yet: The rest of the software does not support it right yet!
 	case AS4PathAttr:
 		if err := pa.decodeASPath(buf, 4); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode AS4 Path")
+			return errors.Wrap(err, "Failed to decode AS4 Path")
 		}*/
 	case NextHopAttr:
 		if err := pa.decodeNextHop(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode Next-Hop")
+			return errors.Wrap(err, "Failed to decode Next-Hop")
 		}
 	case MEDAttr:
 		if err := pa.decodeMED(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode MED")
+			return errors.Wrap(err, "Failed to decode MED")
 		}
 	case LocalPrefAttr:
 		if err := pa.decodeLocalPref(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode local pref")
+			return errors.Wrap(err, "Failed to decode local pref")
 		}
 	case AggregatorAttr:
 		if err := pa.decodeAggregator(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode Aggregator")
+			return errors.Wrap(err, "Failed to decode Aggregator")
 		}
 	case AtomicAggrAttr:
 		// Nothing to do for 0 octet long attribute
 	case CommunitiesAttr:
 		if err := pa.decodeCommunities(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode Community")
+			return errors.Wrap(err, "Failed to decode Community")
 		}
 	case OriginatorIDAttr:
 		if err := pa.decodeOriginatorID(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode OriginatorID")
+			return errors.Wrap(err, "Failed to decode OriginatorID")
 		}
 	case ClusterListAttr:
 		if err := pa.decodeClusterList(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode OriginatorID")
+			return errors.Wrap(err, "Failed to decode OriginatorID")
 		}
 	case MultiProtocolReachNLRICode:
 		if err := pa.decodeMultiProtocolReachNLRI(buf, opt); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to multi protocol reachable NLRI")
+			return errors.Wrap(err, "Failed to multi protocol reachable NLRI")
 		}
 	case MultiProtocolUnreachNLRICode:
 		if err := pa.decodeMultiProtocolUnreachNLRI(buf, opt); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to multi protocol unreachable NLRI")
+			return errors.Wrap(err, "Failed to multi protocol unreachable NLRI")
 		}
 	case AS4AggregatorAttr:
 		if err := pa.decodeAS4Aggregator(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to skip not supported AS4Aggregator")
+			return errors.Wrap(err, "Failed to skip not supported AS4Aggregator")
 		}
 	case LargeCommunitiesAttr:
 		if err := pa.decodeLargeCommunities(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode large communities")
+			return errors.Wrap(err, "Failed to decode large communities")
+		}
+	case ExtendedCommunitiesAttr:
+		if err := pa.decodeExtendedCommunities(buf); err != nil {
+			return errors.Wrap(err, "Failed to decode extended communities")
+		}
+	case LinkStateAttr:
+		if err := pa.decodeLinkState(buf); err != nil {
+			return errors.Wrap(err, "Failed to decode BGP-LS attribute")
+		}
+	case TunnelEncapAttr:
+		if err := pa.decodeTunnelEncap(buf); err != nil {
+			return errors.Wrap(err, "Failed to decode tunnel encapsulation attribute")
 		}
 	default:
 		if err := pa.decodeUnknown(buf); err != nil {
-			return nil, consumed, errors.Wrap(err, "Failed to decode unknown attribute")
+			return errors.Wrap(err, "Failed to decode unknown attribute")
 		}
 	}
 
-	return pa, consumed + pa.Length, nil
+	return nil
 }
 
 func (pa *PathAttribute) decodeMultiProtocolReachNLRI(buf *bytes.Buffer, opt *DecodeOptions) error {
@@ -373,6 +404,31 @@ func (pa *PathAttribute) decodeCommunities(buf *bytes.Buffer) error {
 	return nil
 }
 
+func (pa *PathAttribute) decodeExtendedCommunities(buf *bytes.Buffer) error {
+	if pa.Length%ExtendedCommunityLen != 0 {
+		return fmt.Errorf("Unable to read extended community path attribute. Length %d is not divisible by 8", pa.Length)
+	}
+
+	count := pa.Length / ExtendedCommunityLen
+	coms := make(types.ExtendedCommunities, count)
+
+	b := make([]byte, ExtendedCommunityLen)
+	for i := uint16(0); i < count; i++ {
+		n, err := buf.Read(b)
+		if err != nil {
+			return err
+		}
+		if n != ExtendedCommunityLen {
+			return fmt.Errorf("Unable to read extended community. Expected %d bytes but got only %d", ExtendedCommunityLen, n)
+		}
+
+		coms[i] = types.ExtendedCommunity(endian.Uint64(b))
+	}
+
+	pa.Value = &coms
+	return nil
+}
+
 func (pa *PathAttribute) decodeLargeCommunities(buf *bytes.Buffer) error {
 	if pa.Length%LargeCommunityLen != 0 {
 		return fmt.Errorf("Unable to read large community path attribute. Length %d is not divisible by 12", pa.Length)
@@ -529,6 +585,8 @@ func (pa *PathAttribute) Serialize(buf *bytes.Buffer, opt *EncodeOptions) uint16
 		pathAttrLen = uint16(pa.serializeCommunities(buf))
 	case LargeCommunitiesAttr:
 		pathAttrLen = uint16(pa.serializeLargeCommunities(buf))
+	case ExtendedCommunitiesAttr:
+		pathAttrLen = pa.serializeExtendedCommunities(buf)
 	case MultiProtocolReachNLRICode:
 		pathAttrLen = pa.serializeMultiProtocolReachNLRI(buf, opt)
 	case MultiProtocolUnreachNLRICode:
@@ -537,6 +595,10 @@ func (pa *PathAttribute) Serialize(buf *bytes.Buffer, opt *EncodeOptions) uint16
 		pathAttrLen = uint16(pa.serializeOriginatorID(buf))
 	case ClusterListAttr:
 		pathAttrLen = uint16(pa.serializeClusterList(buf))
+	case LinkStateAttr:
+		pathAttrLen = pa.serializeLinkState(buf)
+	case TunnelEncapAttr:
+		pathAttrLen = pa.serializeTunnelEncap(buf)
 	default:
 		pathAttrLen = pa.serializeUnknownAttribute(buf)
 	}
@@ -565,16 +627,17 @@ func (pa *PathAttribute) serializeASPath(buf *bytes.Buffer, opt *EncodeOptions)
 	}
 
 	length := uint16(0)
-	segmentsBuf := bytes.NewBuffer(nil)
+	segmentsBuf := bufpool.Get()
+	defer bufpool.Put(segmentsBuf)
 	for _, segment := range *pa.Value.(*types.ASPath) {
 		segmentsBuf.WriteByte(segment.Type)
 		segmentsBuf.WriteByte(uint8(len(segment.ASNs)))
 
 		for _, asn := range segment.ASNs {
 			if opt.Use32BitASN {
-				segmentsBuf.Write(convert.Uint32Byte(asn))
+				endian.WriteUint32(segmentsBuf, asn)
 			} else {
-				segmentsBuf.Write(convert.Uint16Byte(uint16(asn)))
+				endian.WriteUint16(segmentsBuf, uint16(asn))
 			}
 		}
 		length += 2 + uint16(len(segment.ASNs))*asnLength
@@ -589,7 +652,7 @@ func (pa *PathAttribute) serializeASPath(buf *bytes.Buffer, opt *EncodeOptions)
 	if length < 256 {
 		buf.WriteByte(uint8(length))
 	} else {
-		buf.Write(convert.Uint16Byte(length))
+		endian.WriteUint16(buf, length)
 	}
 
 	buf.Write(segmentsBuf.Bytes())
@@ -616,7 +679,7 @@ func (pa *PathAttribute) serializeMED(buf *bytes.Buffer) uint8 {
 	buf.WriteByte(MEDAttr)
 	length := uint8(4)
 	buf.WriteByte(length)
-	buf.Write(convert.Uint32Byte(pa.Value.(uint32)))
+	endian.WriteUint32(buf, pa.Value.(uint32))
 	return length + 3
 }
 
@@ -627,7 +690,7 @@ func (pa *PathAttribute) serializeLocalpref(buf *bytes.Buffer) uint8 {
 	buf.WriteByte(LocalPrefAttr)
 	length := uint8(4)
 	buf.WriteByte(length)
-	buf.Write(convert.Uint32Byte(pa.Value.(uint32)))
+	endian.WriteUint32(buf, pa.Value.(uint32))
 	return length + 3
 }
 
@@ -651,8 +714,8 @@ func (pa *PathAttribute) serializeAggregator(buf *bytes.Buffer) uint8 {
 	buf.WriteByte(length)
 
 	aggregator := pa.Value.(types.Aggregator)
-	buf.Write(convert.Uint16Byte(aggregator.ASN))
-	buf.Write(convert.Uint32Byte(aggregator.Address))
+	endian.WriteUint16(buf, aggregator.ASN)
+	endian.WriteUint32(buf, aggregator.Address)
 
 	return length + 3
 }
@@ -682,12 +745,12 @@ func (pa *PathAttribute) serializeCommunities(buf *bytes.Buffer) uint16 {
 	if length < 256 {
 		buf.WriteByte(uint8(length))
 	} else {
-		buf.Write(convert.Uint16Byte(length))
+		endian.WriteUint16(buf, length)
 		length++
 	}
 
 	for _, com := range *coms {
-		buf.Write(convert.Uint32Byte(com))
+		endian.WriteUint32(buf, com)
 	}
 
 	return length + 3
@@ -718,14 +781,49 @@ func (pa *PathAttribute) serializeLargeCommunities(buf *bytes.Buffer) uint16 {
 	if length < 256 {
 		buf.WriteByte(uint8(length))
 	} else {
-		buf.Write(convert.Uint16Byte(length))
+		endian.WriteUint16(buf, length)
+		length++
+	}
+
+	for _, com := range *coms {
+		endian.WriteUint32(buf, com.GlobalAdministrator)
+		endian.WriteUint32(buf, com.DataPart1)
+		endian.WriteUint32(buf, com.DataPart2)
+	}
+
+	return length + 3
+}
+
+func (pa *PathAttribute) serializeExtendedCommunities(buf *bytes.Buffer) uint16 {
+	if pa.Value == nil {
+		return 0
+	}
+
+	coms := pa.Value.(*types.ExtendedCommunities)
+	if len(*coms) == 0 {
+		return 0
+	}
+
+	length := uint16(ExtendedCommunityLen * len(*coms))
+
+	attrFlags := uint8(0)
+	attrFlags = setOptional(attrFlags)
+	attrFlags = setTransitive(attrFlags)
+	if length > 255 {
+		attrFlags = setExtendedLength(attrFlags)
+	}
+	buf.WriteByte(attrFlags)
+	buf.WriteByte(ExtendedCommunitiesAttr)
+
+	if length < 256 {
+		buf.WriteByte(uint8(length))
+	} else {
+		endian.WriteUint16(buf, length)
 		length++
 	}
 
 	for _, com := range *coms {
-		buf.Write(convert.Uint32Byte(com.GlobalAdministrator))
-		buf.Write(convert.Uint32Byte(com.DataPart1))
-		buf.Write(convert.Uint32Byte(com.DataPart2))
+		endian.WriteUint64(buf, uint64(com))
 	}
 
 	return length + 3
@@ -739,7 +837,7 @@ func (pa *PathAttribute) serializeOriginatorID(buf *bytes.Buffer) uint8 {
 	length := uint8(4)
 	buf.WriteByte(length)
 	oid := pa.Value.(uint32)
-	buf.Write(convert.Uint32Byte(oid))
+	endian.WriteUint32(buf, oid)
 	return 7
 }
 
@@ -762,7 +860,7 @@ func (pa *PathAttribute) serializeClusterList(buf *bytes.Buffer) uint8 {
 	buf.WriteByte(length)
 
 	for _, cid := range *cids {
-		buf.Write(convert.Uint32Byte(cid))
+		endian.WriteUint32(buf, cid)
 	}
 
 	return length + 3
@@ -798,7 +896,8 @@ func (pa *PathAttribute) serializeMultiProtocolReachNLRI(buf *bytes.Buffer, opt
 	v := pa.Value.(MultiProtocolReachNLRI)
 	pa.Optional = true
 
-	tempBuf := bytes.NewBuffer(nil)
+	tempBuf := bufpool.Get()
+	defer bufpool.Put(tempBuf)
 	v.serialize(tempBuf, opt)
 
 	return pa.serializeGeneric(tempBuf.Bytes(), buf)
@@ -808,7 +907,8 @@ func (pa *PathAttribute) serializeMultiProtocolUnreachNLRI(buf *bytes.Buffer, op
 	v := pa.Value.(MultiProtocolUnreachNLRI)
 	pa.Optional = true
 
-	tempBuf := bytes.NewBuffer(nil)
+	tempBuf := bufpool.Get()
+	defer bufpool.Put(tempBuf)
 	v.serialize(tempBuf, opt)
 
 	return pa.serializeGeneric(tempBuf.Bytes(), buf)
@@ -889,6 +989,15 @@ func (pa *PathAttribute) AddOptionalPathAttributes(p *route.Path) *PathAttribute
 		current = largeCommunities
 	}
 
+	if p.BGPPath.ExtendedCommunities != nil && len(*p.BGPPath.ExtendedCommunities) > 0 {
+		extendedCommunities := &PathAttribute{
+			TypeCode: ExtendedCommunitiesAttr,
+			Value:    p.BGPPath.ExtendedCommunities,
+		}
+		current.Next = extendedCommunities
+		current = extendedCommunities
+	}
+
 	return current
 }
 
@@ -983,3 +1092,17 @@ func PathAttributes(p *route.Path, iBGP bool, rrClient bool) (*PathAttribute, er
 
 	return asPath, nil
 }
+
+// SerializePathAttributes serializes a linked list of path attributes to wire format
+func SerializePathAttributes(pa *PathAttribute, opt *EncodeOptions) []byte {
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+
+	for cur := pa; cur != nil; cur = cur.Next {
+		cur.Serialize(buf, opt)
+	}
+
+	ret := make([]byte, buf.Len())
+	copy(ret, buf.Bytes())
+	return ret
+}
@@ -779,6 +779,52 @@ func TestDecodeLargeCommunity(t *testing.T) {
 	}
 }
 
+func TestDecodeExtendedCommunities(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantFail bool
+		expected *PathAttribute
+	}{
+		{
+			name: "Two extended communities",
+			input: []byte{
+				0x00, 0x02, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64, // target:65000:100
+				0x03, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, // encapsulation
+			},
+			expected: &PathAttribute{
+				Length: 16,
+				Value: &types.ExtendedCommunities{
+					0x0002fde800000064,
+					0x030c000000000008,
+				},
+			},
+		},
+		{
+			name: "Invalid length",
+			input: []byte{
+				0x00, 0x02, 0xfd, 0xe8,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		pa := &PathAttribute{
+			Length: uint16(len(test.input)),
+		}
+		err := pa.decodeExtendedCommunities(bytes.NewBuffer(test.input))
+
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.expected, pa, "Test %q", test.name)
+	}
+}
+
 func TestDecodeCommunity(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -978,6 +1024,71 @@ func TestDecodeMultiProtocolReachNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid VPNv4 MP_REACH_NLRI",
+			input: []byte{
+				0x00, 0x01, // AFI
+				0x80,                                                               // SAFI
+				0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 192, 0, 2, 1, // NextHop
+				0x00,                               // RESERVED
+				0x88,                               // Length
+				0x00, 0x06, 0x40, 0x00, 0x0c, 0x81, // Labels
+				0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, // RD
+				10, 1, 2, // Prefix
+			},
+			opt: &DecodeOptions{},
+			expected: &PathAttribute{
+				Length: 35,
+				Value: MultiProtocolReachNLRI{
+					AFI:     IPv4AFI,
+					SAFI:    MPLSVPNSAFI,
+					NextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+					NLRI: &NLRI{
+						Labels:             []uint32{100, 200},
+						RouteDistinguisher: 65000<<32 + 1,
+						Prefix:             bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Ptr(),
+					},
+				},
+			},
+		},
+		{
+			name: "valid labeled IPv4 unicast MP_REACH_NLRI",
+			input: []byte{
+				0x00, 0x01, // AFI
+				0x04,               // SAFI
+				0x04, 192, 0, 2, 1, // NextHop
+				0x00,             // RESERVED
+				0x30,             // Length
+				0x00, 0x3e, 0x81, // Label
+				10, 1, 2, // Prefix
+			},
+			opt: &DecodeOptions{},
+			expected: &PathAttribute{
+				Length: 16,
+				Value: MultiProtocolReachNLRI{
+					AFI:     IPv4AFI,
+					SAFI:    LabeledUnicastSAFI,
+					NextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+					NLRI: &NLRI{
+						Labels: []uint32{1000},
+						Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Ptr(),
+					},
+				},
+			},
+		},
+		{
+			name: "VPNv4 MP_REACH_NLRI with label stack exceeding NLRI",
+			input: []byte{
+				0x00, 0x01, // AFI
+				0x80,                                                               // SAFI
+				0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 192, 0, 2, 1, // NextHop
+				0x00,             // RESERVED
+				0x18,             // Length
+				0x00, 0x06, 0x40, // Label without bottom of stack
+			},
+			opt:      &DecodeOptions{},
+			wantFail: true,
+		},
 		{
 			name: "MP_REACH_NLRI with invalid length",
 			input: []byte{
@@ -1116,6 +1227,28 @@ func TestDecodeMultiProtocolUnreachNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid VPNv4 MP_UNREACH_NLRI",
+			input: []byte{
+				0x00, 0x01, // AFI
+				0x80,             // SAFI
+				0x70,             // Length
+				0x80, 0x00, 0x00, // Withdraw label
+				0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, // RD
+				10, 1, 2, // Prefix
+			},
+			expected: &PathAttribute{
+				Length: 18,
+				Value: MultiProtocolUnreachNLRI{
+					AFI:  IPv4AFI,
+					SAFI: MPLSVPNSAFI,
+					NLRI: &NLRI{
+						RouteDistinguisher: 65000<<32 + 1,
+						Prefix:             bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 2, 0), 24).Ptr(),
+					},
+				},
+			},
+		},
 		{
 			name: "MP_UNREACH_NLRI with invalid length",
 			input: []byte{
@@ -1719,6 +1852,48 @@ func TestSerializeLargeCommunities(t *testing.T) {
 	}
 }
 
+func TestSerializeExtendedCommunities(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       *PathAttribute
+		expected    []byte
+		expectedLen uint16
+	}{
+		{
+			name: "Route target",
+			input: &PathAttribute{
+				TypeCode: ExtendedCommunitiesAttr,
+				Value: &types.ExtendedCommunities{
+					0x0002fde800000064,
+				},
+			},
+			expected: []byte{
+				0xc0,                                           // Attribute flags
+				16,                                             // Type
+				8,                                              // Length
+				0x00, 0x02, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64, // target:65000:100
+			},
+			expectedLen: 11,
+		},
+		{
+			name: "Empty list",
+			input: &PathAttribute{
+				TypeCode: ExtendedCommunitiesAttr,
+				Value:    &types.ExtendedCommunities{},
+			},
+			expected:    []byte{},
+			expectedLen: 0,
+		},
+	}
+
+	for _, test := range tests {
+		buf := bytes.NewBuffer([]byte{})
+		n := test.input.serializeExtendedCommunities(buf)
+		assert.Equalf(t, test.expectedLen, n, "Test %q", test.name)
+		assert.Equalf(t, test.expected, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
 func TestSerializeCommunities(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -2127,6 +2302,65 @@ func TestSerialize(t *testing.T) {
 	}
 }
 
+func TestSerializeUpdateToAppends(t *testing.T) {
+	u := &BGPUpdate{
+		NLRI: &NLRI{
+			Prefix: bnet.NewPfx(bnet.IPv4FromOctets(8, 8, 8, 0), 24).Ptr(),
+		},
+	}
+
+	buf := bytes.NewBuffer([]byte{1, 2, 3})
+	err := u.SerializeUpdateTo(buf, &EncodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected failure: %v", err)
+	}
+
+	expected, err := u.SerializeUpdate(&EncodeOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected failure: %v", err)
+	}
+
+	assert.Equal(t, append([]byte{1, 2, 3}, expected...), buf.Bytes())
+}
+
+func BenchmarkSerializeUpdateTo(b *testing.B) {
+	u := &BGPUpdate{
+		PathAttributes: &PathAttribute{
+			TypeCode: OriginAttr,
+			Value:    uint8(0),
+			Next: &PathAttribute{
+				TypeCode: ASPathAttr,
+				Value: &types.ASPath{
+					{
+						Type: 2,
+						ASNs: []uint32{100, 155, 200},
+					},
+				},
+				Next: &PathAttribute{
+					TypeCode: NextHopAttr,
+					Value:    bnet.IPv4FromOctets(10, 20, 30, 40).Ptr(),
+				},
+			},
+		},
+		NLRI: &NLRI{
+			Prefix: bnet.NewPfx(bnet.IPv4FromOctets(8, 8, 8, 0), 24).Ptr(),
+		},
+	}
+	opt := &EncodeOptions{
+		Use32BitASN: true,
+	}
+
+	buf := bytes.NewBuffer(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		err := u.SerializeUpdateTo(buf, opt)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestSerializeAddPath(t *testing.T) {
 	tests := []struct {
 		name     string
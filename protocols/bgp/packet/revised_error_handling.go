@@ -0,0 +1,247 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// AttributeErrorAction is the way a malformed path attribute is handled (RFC7606)
+type AttributeErrorAction uint8
+
+const (
+	// SessionReset tears down the session with a NOTIFICATION
+	SessionReset AttributeErrorAction = iota
+
+	// TreatAsWithdraw handles all NLRIs of the UPDATE as withdrawn
+	TreatAsWithdraw
+
+	// AttributeDiscard drops the malformed attribute and processes the UPDATE without it
+	AttributeDiscard
+)
+
+func (a AttributeErrorAction) String() string {
+	switch a {
+	case SessionReset:
+		return "session-reset"
+	case TreatAsWithdraw:
+		return "treat-as-withdraw"
+	case AttributeDiscard:
+		return "attribute-discard"
+	}
+
+	return "unknown"
+}
+
+// AttributeError is a malformed path attribute of an UPDATE handled without resetting the session
+type AttributeError struct {
+	TypeCode uint8
+	Action   AttributeErrorAction
+	Err      error
+}
+
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("Malformed attribute %d (%s): %v", e.TypeCode, e.Action, e.Err)
+}
+
+func attributeErrorAction(typeCode uint8) AttributeErrorAction {
+	switch typeCode {
+	case MultiProtocolReachNLRICode, MultiProtocolUnreachNLRICode:
+		return SessionReset
+	case AtomicAggrAttr, AggregatorAttr, AS4PathAttr, AS4AggregatorAttr:
+		return AttributeDiscard
+	}
+
+	return TreatAsWithdraw
+}
+
+func updateMessageError(subCode uint8, data []byte, format string, a ...interface{}) BGPError {
+	return BGPError{
+		ErrorCode:    UpdateMessageError,
+		ErrorSubCode: subCode,
+		ErrorStr:     fmt.Sprintf(format, a...),
+		Data:         data,
+	}
+}
+
+// decodePathAttrsRevised decodes path attributes handling malformed ones according to RFC7606.
+// Only errors that require a session reset are returned as error.
+func decodePathAttrsRevised(buf *bytes.Buffer, tpal uint16, opt *DecodeOptions) (*PathAttribute, []*AttributeError, error) {
+	if tpal == 0 {
+		return nil, nil, nil
+	}
+
+	if buf.Len() < int(tpal) {
+		return nil, nil, updateMessageError(MalformedAttributeList, nil, "Total path attribute length %d exceeds message", tpal)
+	}
+
+	attrs := bytes.NewBuffer(buf.Next(int(tpal)))
+
+	var ret *PathAttribute
+	var eol *PathAttribute
+	var attrErrs []*AttributeError
+	seen := make(map[uint8]struct{})
+
+	for attrs.Len() > 0 {
+		raw := attrs.Bytes()
+		pa := &PathAttribute{}
+		hdrLen, err := pa.decodeHeader(attrs)
+		if err != nil {
+			return nil, nil, updateMessageError(MalformedAttributeList, nil, "Unable to decode path attribute header: %v", err)
+		}
+
+		if attrs.Len() < int(pa.Length) {
+			return nil, nil, updateMessageError(AttrLengthError, raw[:hdrLen], "Length %d of attribute %d exceeds attribute list", pa.Length, pa.TypeCode)
+		}
+
+		if _, dup := seen[pa.TypeCode]; dup {
+			if pa.TypeCode == MultiProtocolReachNLRICode || pa.TypeCode == MultiProtocolUnreachNLRICode {
+				return nil, nil, updateMessageError(MalformedAttributeList, nil, "Attribute %d appears more than once", pa.TypeCode)
+			}
+
+			attrs.Next(int(pa.Length))
+			attrErrs = append(attrErrs, &AttributeError{
+				TypeCode: pa.TypeCode,
+				Action:   AttributeDiscard,
+				Err:      fmt.Errorf("Attribute appears more than once"),
+			})
+			continue
+		}
+		seen[pa.TypeCode] = struct{}{}
+
+		v := bytes.NewBuffer(attrs.Next(int(pa.Length)))
+		err = pa.decodeValue(v, opt)
+		if err == nil && v.Len() > 0 {
+			err = fmt.Errorf("%d trailing bytes", v.Len())
+		}
+
+		if err != nil {
+			a := attributeErrorAction(pa.TypeCode)
+			if a == SessionReset {
+				return nil, nil, updateMessageError(OptionalAttrError, raw[:hdrLen+pa.Length], "Malformed attribute %d: %v", pa.TypeCode, err)
+			}
+
+			attrErrs = append(attrErrs, &AttributeError{
+				TypeCode: pa.TypeCode,
+				Action:   a,
+				Err:      err,
+			})
+			continue
+		}
+
+		if ret == nil {
+			ret = pa
+			eol = pa
+		} else {
+			eol.Next = pa
+			eol = pa
+		}
+	}
+
+	return ret, attrErrs, nil
+}
+
+// checkMandatoryAttrs reports missing well-known mandatory attributes of an UPDATE carrying NLRIs
+func (b *BGPUpdate) checkMandatoryAttrs() {
+	haveMPReach := false
+	present := make(map[uint8]bool)
+	for pa := b.PathAttributes; pa != nil; pa = pa.Next {
+		present[pa.TypeCode] = true
+		if pa.TypeCode == MultiProtocolReachNLRICode {
+			haveMPReach = true
+		}
+	}
+
+	if b.NLRI == nil && !haveMPReach {
+		return
+	}
+
+	mandatory := []uint8{OriginAttr, ASPathAttr}
+	if b.NLRI != nil {
+		mandatory = append(mandatory, NextHopAttr)
+	}
+
+	for _, typeCode := range mandatory {
+		if present[typeCode] || b.hasAttributeError(typeCode) {
+			continue
+		}
+
+		b.AttributeErrors = append(b.AttributeErrors, &AttributeError{
+			TypeCode: typeCode,
+			Action:   TreatAsWithdraw,
+			Err:      fmt.Errorf("Missing well-known attribute"),
+		})
+	}
+}
+
+func (b *BGPUpdate) hasAttributeError(typeCode uint8) bool {
+	for _, e := range b.AttributeErrors {
+		if e.TypeCode == typeCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TreatAsWithdraw returns if the NLRIs of the update have to be handled as withdrawn because of malformed attributes
+func (b *BGPUpdate) TreatAsWithdraw() bool {
+	for _, e := range b.AttributeErrors {
+		if e.Action == TreatAsWithdraw {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertToWithdraw turns all NLRIs of the update into withdrawals and removes all other attributes
+func (b *BGPUpdate) convertToWithdraw() {
+	if b.NLRI != nil {
+		if b.WithdrawnRoutes == nil {
+			b.WithdrawnRoutes = b.NLRI
+		} else {
+			eol := b.WithdrawnRoutes
+			for eol.Next != nil {
+				eol = eol.Next
+			}
+			eol.Next = b.NLRI
+		}
+		b.NLRI = nil
+	}
+
+	var ret *PathAttribute
+	var eol *PathAttribute
+	for pa := b.PathAttributes; pa != nil; pa = pa.Next {
+		var w *PathAttribute
+		switch pa.TypeCode {
+		case MultiProtocolUnreachNLRICode:
+			w = pa.Copy()
+		case MultiProtocolReachNLRICode:
+			mp := pa.Value.(MultiProtocolReachNLRI)
+			w = &PathAttribute{
+				TypeCode:       MultiProtocolUnreachNLRICode,
+				Optional:       true,
+				ExtendedLength: true,
+				Value: MultiProtocolUnreachNLRI{
+					AFI:          mp.AFI,
+					SAFI:         mp.SAFI,
+					NLRI:         mp.NLRI,
+					LinkState:    mp.LinkState,
+					RouteTargets: mp.RouteTargets,
+					FlowSpec:     mp.FlowSpec,
+				},
+			}
+		default:
+			continue
+		}
+
+		if ret == nil {
+			ret = w
+		} else {
+			eol.Next = w
+		}
+		eol = w
+	}
+
+	b.PathAttributes = ret
+}
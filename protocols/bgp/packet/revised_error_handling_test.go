@@ -0,0 +1,162 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeUpdateMsgRevisedErrorHandling(t *testing.T) {
+	origin := []byte{0x40, OriginAttr, 1, IGP}
+	asPath := []byte{0x40, ASPathAttr, 0}
+	nextHop := []byte{0x40, NextHopAttr, 4, 10, 0, 0, 1}
+	nlri := []byte{8, 10}
+
+	tests := []struct {
+		name              string
+		attrs             [][]byte
+		nlri              []byte
+		wantErr           bool
+		wantActions       []AttributeErrorAction
+		wantWithdrawn     bool
+		wantAttributes    []uint8
+		wantMPUnreachNLRI bool
+	}{
+		{
+			name:           "Valid update",
+			attrs:          [][]byte{origin, asPath, nextHop},
+			nlri:           nlri,
+			wantAttributes: []uint8{OriginAttr, ASPathAttr, NextHopAttr},
+		},
+		{
+			name: "Malformed MED",
+			attrs: [][]byte{origin, asPath, nextHop,
+				{0x80, MEDAttr, 3, 0, 0, 1},
+			},
+			nlri:          nlri,
+			wantActions:   []AttributeErrorAction{TreatAsWithdraw},
+			wantWithdrawn: true,
+		},
+		{
+			name: "Malformed aggregator",
+			attrs: [][]byte{origin, asPath, nextHop,
+				{0xc0, AggregatorAttr, 3, 0, 0, 1},
+			},
+			nlri:           nlri,
+			wantActions:    []AttributeErrorAction{AttributeDiscard},
+			wantAttributes: []uint8{OriginAttr, ASPathAttr, NextHopAttr},
+		},
+		{
+			name: "Atomic aggregate with trailing bytes",
+			attrs: [][]byte{origin, asPath, nextHop,
+				{0x40, AtomicAggrAttr, 1, 0},
+			},
+			nlri:           nlri,
+			wantActions:    []AttributeErrorAction{AttributeDiscard},
+			wantAttributes: []uint8{OriginAttr, ASPathAttr, NextHopAttr},
+		},
+		{
+			name:           "Duplicate origin",
+			attrs:          [][]byte{origin, asPath, nextHop, origin},
+			nlri:           nlri,
+			wantActions:    []AttributeErrorAction{AttributeDiscard},
+			wantAttributes: []uint8{OriginAttr, ASPathAttr, NextHopAttr},
+		},
+		{
+			name:          "Missing next-hop",
+			attrs:         [][]byte{origin, asPath},
+			nlri:          nlri,
+			wantActions:   []AttributeErrorAction{TreatAsWithdraw},
+			wantWithdrawn: true,
+		},
+		{
+			name: "Malformed MP_REACH_NLRI",
+			attrs: [][]byte{origin, asPath,
+				{0x80, MultiProtocolReachNLRICode, 3, 0, 2, 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MP_REACH_NLRI with malformed local pref",
+			attrs: [][]byte{origin, asPath,
+				{0x40, LocalPrefAttr, 2, 0, 100},
+				{
+					0x80, MultiProtocolReachNLRICode, 24,
+					0, 2, 1, // AFI, SAFI
+					16, 0x20, 0x01, 0x06, 0x78, 0x01, 0xe0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // Next hop
+					0,              // Reserved
+					16, 0x20, 0x01, // 2001::/16
+				},
+			},
+			wantActions:       []AttributeErrorAction{TreatAsWithdraw},
+			wantMPUnreachNLRI: true,
+		},
+		{
+			name: "Attribute length exceeds attribute list",
+			attrs: [][]byte{origin, asPath,
+				{0x40, NextHopAttr, 8, 10, 0, 0, 1},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		body := []byte{0, 0}
+		attrs := []byte{}
+		for _, a := range test.attrs {
+			attrs = append(attrs, a...)
+		}
+		body = append(body, byte(len(attrs)>>8), byte(len(attrs)))
+		body = append(body, attrs...)
+		body = append(body, test.nlri...)
+
+		u, err := decodeUpdateMsg(bytes.NewBuffer(body), uint16(len(body)), &DecodeOptions{
+			RevisedErrorHandling: true,
+		})
+		if test.wantErr {
+			_, ok := err.(BGPError)
+			assert.True(t, ok, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		actions := make([]AttributeErrorAction, 0)
+		for _, e := range u.AttributeErrors {
+			actions = append(actions, e.Action)
+		}
+		if test.wantActions == nil {
+			test.wantActions = []AttributeErrorAction{}
+		}
+		assert.Equal(t, test.wantActions, actions, "Test %q", test.name)
+
+		if test.wantWithdrawn {
+			assert.Nil(t, u.NLRI, "Test %q", test.name)
+			if assert.NotNil(t, u.WithdrawnRoutes, "Test %q", test.name) {
+				assert.Equal(t, "10.0.0.0/8", u.WithdrawnRoutes.Prefix.String(), "Test %q", test.name)
+			}
+		}
+
+		if test.wantMPUnreachNLRI {
+			if assert.NotNil(t, u.PathAttributes, "Test %q", test.name) {
+				assert.Nil(t, u.PathAttributes.Next, "Test %q", test.name)
+				mp := u.PathAttributes.Value.(MultiProtocolUnreachNLRI)
+				assert.Equal(t, "2001:0:0:0:0:0:0:0/16", mp.NLRI.Prefix.String(), "Test %q", test.name)
+			}
+		}
+
+		_, _, eor := u.IsEndOfRIB()
+		assert.False(t, eor, "Test %q", test.name)
+
+		if test.wantAttributes != nil {
+			typeCodes := make([]uint8, 0)
+			for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+				typeCodes = append(typeCodes, pa.TypeCode)
+			}
+			assert.Equal(t, test.wantAttributes, typeCodes, "Test %q", test.name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// RouteRefreshCapabilityCode is the code of the route refresh capability (RFC2918)
+	RouteRefreshCapabilityCode = 2
+
+	// EnhancedRouteRefreshCapabilityCode is the code of the enhanced route refresh capability (RFC7313)
+	EnhancedRouteRefreshCapabilityCode = 70
+
+	// RouteRefreshLen is the length of a ROUTE-REFRESH message
+	RouteRefreshLen = HeaderLen + 4
+
+	// ROUTE-REFRESH message subtypes (RFC7313 3.2)
+	RouteRefreshRequest     = 0
+	BeginningOfRouteRefresh = 1
+	EndOfRouteRefresh       = 2
+
+	// RouteRefreshMessageError is the NOTIFICATION error code for malformed ROUTE-REFRESH messages (RFC7313 5)
+	RouteRefreshMessageError = 7
+
+	// InvalidRouteRefreshMessageLength is the only ROUTE-REFRESH message error subcode
+	InvalidRouteRefreshMessageLength = 1
+)
+
+// BGPRouteRefresh is a ROUTE-REFRESH message (RFC2918, RFC7313)
+type BGPRouteRefresh struct {
+	AFI     uint16
+	Subtype uint8
+	SAFI    uint8
+}
+
+// SerializeRouteRefreshMsg serializes a ROUTE-REFRESH message
+func SerializeRouteRefreshMsg(msg *BGPRouteRefresh) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, RouteRefreshLen))
+	serializeHeader(buf, RouteRefreshLen, RouteRefreshMsg)
+	endian.WriteUint16(buf, msg.AFI)
+	buf.WriteByte(msg.Subtype)
+	buf.WriteByte(msg.SAFI)
+
+	return buf.Bytes()
+}
+
+func decodeRouteRefreshMsg(buf *bytes.Buffer, l uint16) (*BGPRouteRefresh, error) {
+	if l != RouteRefreshLen-HeaderLen {
+		return nil, BGPError{
+			ErrorCode:    RouteRefreshMessageError,
+			ErrorSubCode: InvalidRouteRefreshMessageLength,
+			ErrorStr:     fmt.Sprintf("Invalid ROUTE-REFRESH message length: %d", l+HeaderLen),
+		}
+	}
+
+	msg := &BGPRouteRefresh{}
+	fields := []interface{}{
+		&msg.AFI,
+		&msg.Subtype,
+		&msg.SAFI,
+	}
+
+	err := decode.Decode(buf, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// RouteRefreshCapability announces the ability to handle ROUTE-REFRESH messages (RFC2918)
+type RouteRefreshCapability struct{}
+
+func (r RouteRefreshCapability) serialize(buf *bytes.Buffer) {}
+
+// EnhancedRouteRefreshCapability announces the ability to handle BoRR and EoRR markers (RFC7313)
+type EnhancedRouteRefreshCapability struct{}
+
+func (e EnhancedRouteRefreshCapability) serialize(buf *bytes.Buffer) {}
@@ -0,0 +1,92 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// RouteTargetConstraintSAFI is the SAFI of route target membership NLRIs (RFC4684)
+	RouteTargetConstraintSAFI = 132
+
+	// RouteTargetNLRIMaxLen is the length in bits of a route target membership NLRI covering the whole route target
+	RouteTargetNLRIMaxLen = 96
+
+	routeTargetOriginASBits = 32
+)
+
+// RouteTargetNLRI is a route target membership NLRI (RFC4684 4). The NLRI is a prefix of Length bits of the origin AS
+// followed by the route target. Length 0 is the default route target requesting all VPN routes.
+type RouteTargetNLRI struct {
+	Length      uint8
+	OriginAS    uint32
+	RouteTarget types.ExtendedCommunity
+}
+
+// NewRouteTargetNLRI creates a route target membership NLRI covering a whole route target
+func NewRouteTargetNLRI(originAS uint32, rt types.ExtendedCommunity) *RouteTargetNLRI {
+	return &RouteTargetNLRI{
+		Length:      RouteTargetNLRIMaxLen,
+		OriginAS:    originAS,
+		RouteTarget: rt,
+	}
+}
+
+// Matches returns if VPN routes carrying route target rt are requested by the NLRI
+func (n *RouteTargetNLRI) Matches(rt types.ExtendedCommunity) bool {
+	if n.Length <= routeTargetOriginASBits {
+		return true
+	}
+
+	bits := n.Length - routeTargetOriginASBits
+	mask := ^uint64(0) << (64 - bits)
+	return uint64(n.RouteTarget)&mask == uint64(rt)&mask
+}
+
+// String returns a human readable representation of the NLRI
+func (n *RouteTargetNLRI) String() string {
+	if n.Length == 0 {
+		return "default route target"
+	}
+
+	return fmt.Sprintf("AS%d %s/%d", n.OriginAS, n.RouteTarget.String(), n.Length)
+}
+
+func (n *RouteTargetNLRI) serialize(buf *bytes.Buffer) {
+	b := make([]byte, RouteTargetNLRIMaxLen/8)
+	endian.PutUint32(b, n.OriginAS)
+	endian.PutUint64(b[4:], uint64(n.RouteTarget))
+
+	buf.WriteByte(n.Length)
+	buf.Write(b[:BytesInAddr(n.Length)])
+}
+
+func decodeRouteTargetNLRIs(b []byte) ([]*RouteTargetNLRI, error) {
+	ret := make([]*RouteTargetNLRI, 0)
+	for len(b) > 0 {
+		l := b[0]
+		if l != 0 && (l < routeTargetOriginASBits || l > RouteTargetNLRIMaxLen) {
+			return nil, fmt.Errorf("Invalid route target membership NLRI length %d", l)
+		}
+
+		numBytes := int(BytesInAddr(l))
+		if len(b) < 1+numBytes {
+			return nil, fmt.Errorf("Route target membership NLRI truncated")
+		}
+
+		v := make([]byte, RouteTargetNLRIMaxLen/8)
+		copy(v, b[1:1+numBytes])
+		ret = append(ret, &RouteTargetNLRI{
+			Length:      l,
+			OriginAS:    endian.Uint32(v),
+			RouteTarget: types.ExtendedCommunity(endian.Uint64(v[4:])),
+		})
+
+		b = b[1+numBytes:]
+	}
+
+	return ret, nil
+}
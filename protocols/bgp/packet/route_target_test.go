@@ -0,0 +1,142 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteTargetNLRIMatches(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 100)
+	other, _ := types.NewRouteTarget(65000, 200)
+
+	tests := []struct {
+		name     string
+		nlri     *RouteTargetNLRI
+		rt       types.ExtendedCommunity
+		expected bool
+	}{
+		{
+			name:     "Default route target",
+			nlri:     &RouteTargetNLRI{},
+			rt:       rt,
+			expected: true,
+		},
+		{
+			name:     "Same route target",
+			nlri:     NewRouteTargetNLRI(65001, rt),
+			rt:       rt,
+			expected: true,
+		},
+		{
+			name:     "Other route target",
+			nlri:     NewRouteTargetNLRI(65001, rt),
+			rt:       other,
+			expected: false,
+		},
+		{
+			name: "Route target prefix",
+			nlri: &RouteTargetNLRI{
+				Length:      64,
+				OriginAS:    65001,
+				RouteTarget: rt,
+			},
+			rt:       other,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.nlri.Matches(test.rt), "Test %q", test.name)
+	}
+}
+
+func TestRouteTargetUpdate(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 100)
+	nlris := []*RouteTargetNLRI{
+		NewRouteTargetNLRI(65001, rt),
+		{
+			Length:      80,
+			OriginAS:    65001,
+			RouteTarget: rt &^ 0xffff,
+		},
+		{},
+	}
+
+	u := &BGPUpdate{
+		PathAttributes: &PathAttribute{
+			TypeCode: OriginAttr,
+			Value:    uint8(IGP),
+			Next: &PathAttribute{
+				TypeCode: ASPathAttr,
+				Value:    &types.ASPath{},
+				Next: &PathAttribute{
+					TypeCode: MultiProtocolReachNLRICode,
+					Value: MultiProtocolReachNLRI{
+						AFI:          IPv4AFI,
+						SAFI:         RouteTargetConstraintSAFI,
+						NextHop:      bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+						RouteTargets: nlris,
+					},
+				},
+			},
+		},
+	}
+
+	opt := &EncodeOptions{
+		Use32BitASN: true,
+	}
+	b, err := u.SerializeUpdate(opt)
+	if err != nil {
+		t.Fatalf("Unable to serialize update: %v", err)
+	}
+
+	m, err := Decode(bytes.NewBuffer(b), &DecodeOptions{
+		Use32BitASN: true,
+	})
+	if err != nil {
+		t.Fatalf("Unable to decode update: %v", err)
+	}
+
+	var mp MultiProtocolReachNLRI
+	for pa := m.Body.(*BGPUpdate).PathAttributes; pa != nil; pa = pa.Next {
+		if pa.TypeCode == MultiProtocolReachNLRICode {
+			mp = pa.Value.(MultiProtocolReachNLRI)
+		}
+	}
+
+	assert.Equal(t, uint8(RouteTargetConstraintSAFI), mp.SAFI)
+	assert.Equal(t, nlris, mp.RouteTargets)
+}
+
+func TestDecodeRouteTargetNLRIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantFail bool
+	}{
+		{
+			name:     "Invalid length",
+			input:    []byte{16, 0, 0},
+			wantFail: true,
+		},
+		{
+			name:     "Truncated",
+			input:    []byte{96, 0, 0, 0xfd, 0xe9},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := decodeRouteTargetNLRIs(test.input)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,492 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// SRPolicySAFI is the SAFI of segment routing policies (RFC9830)
+	SRPolicySAFI = 73
+
+	// TunnelEncapAttr is the Tunnel Encapsulation attribute (RFC9012)
+	TunnelEncapAttr = 23
+
+	// TunnelTypeSRPolicy is the tunnel type carrying the candidate path of an SR Policy (RFC9830 2.2)
+	TunnelTypeSRPolicy = 15
+
+	// srPolicyNLRIHeaderLen is the length of distinguisher and color of an SR Policy NLRI
+	srPolicyNLRIHeaderLen = 8
+
+	// tunnelEncapExtendedSubTLV is the first sub-TLV type with a two octet length (RFC9012 2)
+	tunnelEncapExtendedSubTLV = 128
+)
+
+// SR Policy sub-TLV types (RFC9830 2.4)
+const (
+	SRPolicyPreferenceSubTLV        = 12
+	SRPolicyBindingSIDSubTLV        = 13
+	SRPolicyENLPSubTLV              = 14
+	SRPolicyPrioritySubTLV          = 15
+	SRPolicySegmentListSubTLV       = 128
+	SRPolicyCandidatePathNameSubTLV = 129
+	SRPolicyNameSubTLV              = 130
+)
+
+// Segment types and sub-TLVs of segment lists (RFC9830 2.4.4)
+const (
+	// SRSegmentTypeA is an SR-MPLS label
+	SRSegmentTypeA = 1
+
+	// SRSegmentTypeB is an SRv6 SID
+	SRSegmentTypeB = 13
+
+	srSegmentListWeightSubTLV = 9
+)
+
+// SRPolicyNLRI identifies a candidate path of a segment routing policy (RFC9830 2.1)
+type SRPolicyNLRI struct {
+	Distinguisher uint32
+	Color         uint32
+	Endpoint      *bnet.IP
+}
+
+// Key gets a string uniquely identifying the NLRI
+func (n *SRPolicyNLRI) Key() string {
+	buf := &bytes.Buffer{}
+	n.serialize(buf)
+
+	return buf.String()
+}
+
+func (n *SRPolicyNLRI) String() string {
+	return fmt.Sprintf("[%d][%d][%s]", n.Distinguisher, n.Color, n.Endpoint.String())
+}
+
+func (n *SRPolicyNLRI) serialize(buf *bytes.Buffer) {
+	endpoint := n.Endpoint.Bytes()
+	buf.WriteByte(uint8((srPolicyNLRIHeaderLen + len(endpoint)) * 8))
+	endian.WriteUint32(buf, n.Distinguisher)
+	endian.WriteUint32(buf, n.Color)
+	buf.Write(endpoint)
+}
+
+func decodeSRPolicyNLRIs(b []byte, afi uint16) ([]*SRPolicyNLRI, error) {
+	endpointLen, ok := afiAddrLenBytes[afi]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported SR Policy AFI %d", afi)
+	}
+
+	ret := make([]*SRPolicyNLRI, 0)
+	for len(b) > 0 {
+		l := int(b[0])
+		if l != (srPolicyNLRIHeaderLen+int(endpointLen))*8 {
+			return nil, fmt.Errorf("Invalid SR Policy NLRI length %d for AFI %d", l, afi)
+		}
+
+		l /= 8
+		if len(b) < 1+l {
+			return nil, fmt.Errorf("SR Policy NLRI truncated")
+		}
+
+		endpoint, err := bnet.IPFromBytes(b[1+srPolicyNLRIHeaderLen : 1+l])
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, &SRPolicyNLRI{
+			Distinguisher: endian.Uint32(b[1:5]),
+			Color:         endian.Uint32(b[5:9]),
+			Endpoint:      endpoint.Dedup(),
+		})
+		b = b[1+l:]
+	}
+
+	return ret, nil
+}
+
+// TunnelEncapsulation is the value of the Tunnel Encapsulation attribute (RFC9012)
+type TunnelEncapsulation []*TunnelEncapTLV
+
+// TunnelEncapTLV describes a tunnel of the Tunnel Encapsulation attribute. The sub-TLVs of SR Policy tunnels are decoded
+// into SRPolicy, the ones of other tunnel types are kept as they are.
+type TunnelEncapTLV struct {
+	Type     uint16
+	SRPolicy *SRPolicy
+	SubTLVs  []byte
+}
+
+// SRPolicy is a candidate path of a segment routing policy (RFC9830 2.4). Optional sub-TLVs are nil if absent.
+type SRPolicy struct {
+	Preference        *uint32
+	Priority          *uint8
+	BindingSID        *SRBindingSID
+	ENLP              *uint8
+	SegmentLists      []*SRSegmentList
+	CandidatePathName string
+	PolicyName        string
+}
+
+// SRBindingSID is the binding SID of a candidate path (RFC9830 2.4.2). It is an SRv6 SID if SID is set, an MPLS label
+// otherwise. A zero label without SID is encoded as binding SID sub-TLV without SID.
+type SRBindingSID struct {
+	Flags uint8
+	Label uint32
+	SID   *bnet.IP
+}
+
+// SRSegmentList is a segment list of a candidate path (RFC9830 2.4.4). A weight of 0 is not encoded.
+type SRSegmentList struct {
+	Weight   uint32
+	Segments []*SRSegment
+}
+
+// SRSegment is a segment of a segment list. Type A segments carry an MPLS label, type B segments an SRv6 SID.
+type SRSegment struct {
+	Type  uint8
+	Flags uint8
+	Label uint32
+	SID   *bnet.IP
+}
+
+func (t TunnelEncapsulation) serialize(buf *bytes.Buffer) {
+	for _, tlv := range t {
+		v := tlv.SubTLVs
+		if tlv.SRPolicy != nil {
+			b := &bytes.Buffer{}
+			tlv.SRPolicy.serialize(b)
+			v = b.Bytes()
+		}
+
+		endian.WriteUint16(buf, tlv.Type)
+		endian.WriteUint16(buf, uint16(len(v)))
+		buf.Write(v)
+	}
+}
+
+func (p *SRPolicy) serialize(buf *bytes.Buffer) {
+	if p.Preference != nil {
+		v := make([]byte, 6)
+		endian.PutUint32(v[2:], *p.Preference)
+		writeTunnelEncapSubTLV(buf, SRPolicyPreferenceSubTLV, v)
+	}
+
+	if p.BindingSID != nil {
+		writeTunnelEncapSubTLV(buf, SRPolicyBindingSIDSubTLV, p.BindingSID.value())
+	}
+
+	if p.ENLP != nil {
+		writeTunnelEncapSubTLV(buf, SRPolicyENLPSubTLV, []byte{0, 0, *p.ENLP})
+	}
+
+	if p.Priority != nil {
+		writeTunnelEncapSubTLV(buf, SRPolicyPrioritySubTLV, []byte{*p.Priority, 0})
+	}
+
+	if p.CandidatePathName != "" {
+		writeTunnelEncapSubTLV(buf, SRPolicyCandidatePathNameSubTLV, append([]byte{0}, p.CandidatePathName...))
+	}
+
+	if p.PolicyName != "" {
+		writeTunnelEncapSubTLV(buf, SRPolicyNameSubTLV, append([]byte{0}, p.PolicyName...))
+	}
+
+	for _, l := range p.SegmentLists {
+		writeTunnelEncapSubTLV(buf, SRPolicySegmentListSubTLV, l.value())
+	}
+}
+
+func (b *SRBindingSID) value() []byte {
+	v := []byte{b.Flags, 0}
+	if b.SID != nil {
+		return append(v, b.SID.Bytes()...)
+	}
+
+	if b.Label != 0 {
+		l := make([]byte, 4)
+		endian.PutUint32(l, b.Label<<12)
+		return append(v, l...)
+	}
+
+	return v
+}
+
+func (l *SRSegmentList) value() []byte {
+	buf := bytes.NewBuffer([]byte{0})
+	if l.Weight != 0 {
+		v := make([]byte, 6)
+		endian.PutUint32(v[2:], l.Weight)
+		writeTunnelEncapSubTLV(buf, srSegmentListWeightSubTLV, v)
+	}
+
+	for _, s := range l.Segments {
+		v := []byte{s.Flags, 0}
+		switch s.Type {
+		case SRSegmentTypeA:
+			l := make([]byte, 4)
+			endian.PutUint32(l, s.Label<<12)
+			v = append(v, l...)
+		case SRSegmentTypeB:
+			v = append(v, s.SID.Bytes()...)
+		}
+
+		writeTunnelEncapSubTLV(buf, s.Type, v)
+	}
+
+	return buf.Bytes()
+}
+
+func writeTunnelEncapSubTLV(buf *bytes.Buffer, t uint8, v []byte) {
+	buf.WriteByte(t)
+	if t >= tunnelEncapExtendedSubTLV {
+		endian.WriteUint16(buf, uint16(len(v)))
+	} else {
+		buf.WriteByte(uint8(len(v)))
+	}
+
+	buf.Write(v)
+}
+
+// walkTunnelEncapSubTLVs calls f for each sub-TLV in b
+func walkTunnelEncapSubTLVs(b []byte, f func(t uint8, v []byte) error) error {
+	for len(b) > 0 {
+		t := b[0]
+		hdrLen := 2
+		if t >= tunnelEncapExtendedSubTLV {
+			hdrLen = 3
+		}
+
+		if len(b) < hdrLen {
+			return fmt.Errorf("Sub-TLV %d header truncated", t)
+		}
+
+		l := int(b[1])
+		if hdrLen == 3 {
+			l = int(endian.Uint16(b[1:3]))
+		}
+
+		if len(b) < hdrLen+l {
+			return fmt.Errorf("Sub-TLV %d truncated", t)
+		}
+
+		err := f(t, b[hdrLen:hdrLen+l])
+		if err != nil {
+			return err
+		}
+
+		b = b[hdrLen+l:]
+	}
+
+	return nil
+}
+
+func decodeTunnelEncapsulation(b []byte) (TunnelEncapsulation, error) {
+	ret := make(TunnelEncapsulation, 0)
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, fmt.Errorf("Tunnel TLV header truncated")
+		}
+
+		t := endian.Uint16(b[0:2])
+		l := int(endian.Uint16(b[2:4]))
+		if len(b) < 4+l {
+			return nil, fmt.Errorf("Tunnel TLV %d truncated", t)
+		}
+
+		tlv := &TunnelEncapTLV{
+			Type: t,
+		}
+
+		v := b[4 : 4+l]
+		if t == TunnelTypeSRPolicy {
+			p, err := decodeSRPolicy(v)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to decode SR Policy: %v", err)
+			}
+
+			tlv.SRPolicy = p
+		} else {
+			tlv.SubTLVs = v
+		}
+
+		ret = append(ret, tlv)
+		b = b[4+l:]
+	}
+
+	return ret, nil
+}
+
+func decodeSRPolicy(b []byte) (*SRPolicy, error) {
+	p := &SRPolicy{}
+	err := walkTunnelEncapSubTLVs(b, func(t uint8, v []byte) error {
+		switch t {
+		case SRPolicyPreferenceSubTLV:
+			if len(v) != 6 {
+				return fmt.Errorf("Invalid preference length %d", len(v))
+			}
+
+			pref := endian.Uint32(v[2:])
+			p.Preference = &pref
+		case SRPolicyBindingSIDSubTLV:
+			bsid, err := decodeSRBindingSID(v)
+			if err != nil {
+				return err
+			}
+
+			p.BindingSID = bsid
+		case SRPolicyENLPSubTLV:
+			if len(v) != 3 {
+				return fmt.Errorf("Invalid ENLP length %d", len(v))
+			}
+
+			enlp := v[2]
+			p.ENLP = &enlp
+		case SRPolicyPrioritySubTLV:
+			if len(v) != 2 {
+				return fmt.Errorf("Invalid priority length %d", len(v))
+			}
+
+			prio := v[0]
+			p.Priority = &prio
+		case SRPolicyCandidatePathNameSubTLV, SRPolicyNameSubTLV:
+			if len(v) < 1 {
+				return fmt.Errorf("Invalid name length %d", len(v))
+			}
+
+			if t == SRPolicyNameSubTLV {
+				p.PolicyName = string(v[1:])
+			} else {
+				p.CandidatePathName = string(v[1:])
+			}
+		case SRPolicySegmentListSubTLV:
+			l, err := decodeSRSegmentList(v)
+			if err != nil {
+				return err
+			}
+
+			p.SegmentLists = append(p.SegmentLists, l)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func decodeSRBindingSID(v []byte) (*SRBindingSID, error) {
+	if len(v) < 2 {
+		return nil, fmt.Errorf("Invalid binding SID length %d", len(v))
+	}
+
+	bsid := &SRBindingSID{
+		Flags: v[0],
+	}
+
+	switch len(v) - 2 {
+	case 0:
+	case 4:
+		bsid.Label = endian.Uint32(v[2:]) >> 12
+	case IPv6Len:
+		sid, err := bnet.IPFromBytes(v[2:])
+		if err != nil {
+			return nil, err
+		}
+
+		bsid.SID = sid.Dedup()
+	default:
+		return nil, fmt.Errorf("Invalid binding SID length %d", len(v))
+	}
+
+	return bsid, nil
+}
+
+func decodeSRSegmentList(b []byte) (*SRSegmentList, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("Segment list truncated")
+	}
+
+	l := &SRSegmentList{}
+	err := walkTunnelEncapSubTLVs(b[1:], func(t uint8, v []byte) error {
+		switch t {
+		case srSegmentListWeightSubTLV:
+			if len(v) != 6 {
+				return fmt.Errorf("Invalid weight length %d", len(v))
+			}
+
+			l.Weight = endian.Uint32(v[2:])
+		case SRSegmentTypeA:
+			if len(v) != 6 {
+				return fmt.Errorf("Invalid type A segment length %d", len(v))
+			}
+
+			l.Segments = append(l.Segments, &SRSegment{
+				Type:  t,
+				Flags: v[0],
+				Label: endian.Uint32(v[2:]) >> 12,
+			})
+		case SRSegmentTypeB:
+			// The SID may be followed by the SRv6 endpoint behavior and SID structure
+			if len(v) != 2+IPv6Len && len(v) != 2+IPv6Len+8 {
+				return fmt.Errorf("Invalid type B segment length %d", len(v))
+			}
+
+			sid, err := bnet.IPFromBytes(v[2 : 2+IPv6Len])
+			if err != nil {
+				return err
+			}
+
+			l.Segments = append(l.Segments, &SRSegment{
+				Type:  t,
+				Flags: v[0],
+				SID:   sid.Dedup(),
+			})
+		default:
+			return fmt.Errorf("Unsupported segment type %d", t)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (pa *PathAttribute) decodeTunnelEncap(buf *bytes.Buffer) error {
+	b := make([]byte, pa.Length)
+	err := decode.Decode(buf, []interface{}{&b})
+	if err != nil {
+		return err
+	}
+
+	t, err := decodeTunnelEncapsulation(b)
+	if err != nil {
+		return err
+	}
+
+	pa.Value = t
+	return nil
+}
+
+func (pa *PathAttribute) serializeTunnelEncap(buf *bytes.Buffer) uint16 {
+	t := pa.Value.(TunnelEncapsulation)
+	if len(t) == 0 {
+		return 0
+	}
+
+	pa.Optional = true
+	pa.Transitive = true
+
+	tempBuf := &bytes.Buffer{}
+	t.serialize(tempBuf)
+
+	return pa.serializeGeneric(tempBuf.Bytes(), buf)
+}
@@ -0,0 +1,241 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSRPolicyNLRIs(t *testing.T) {
+	tests := []struct {
+		name     string
+		afi      uint16
+		input    []byte
+		wantFail bool
+		expected []*SRPolicyNLRI
+	}{
+		{
+			name: "IPv4",
+			afi:  IPv4AFI,
+			input: []byte{
+				96,
+				0, 0, 0, 1, // Distinguisher
+				0, 0, 0, 100, // Color
+				192, 0, 2, 1, // Endpoint
+			},
+			expected: []*SRPolicyNLRI{
+				{
+					Distinguisher: 1,
+					Color:         100,
+					Endpoint:      bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				},
+			},
+		},
+		{
+			name: "IPv6",
+			afi:  IPv6AFI,
+			input: []byte{
+				192,
+				0, 0, 0, 2, // Distinguisher
+				0, 0, 0, 200, // Color
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // Endpoint
+			},
+			expected: []*SRPolicyNLRI{
+				{
+					Distinguisher: 2,
+					Color:         200,
+					Endpoint:      bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+				},
+			},
+		},
+		{
+			name: "Length does not match AFI",
+			afi:  IPv6AFI,
+			input: []byte{
+				96,
+				0, 0, 0, 1,
+				0, 0, 0, 100,
+				192, 0, 2, 1,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Truncated",
+			afi:  IPv4AFI,
+			input: []byte{
+				96,
+				0, 0, 0, 1,
+				0, 0, 0, 100,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		nlris, err := decodeSRPolicyNLRIs(test.input, test.afi)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, nlris, "Test %q", test.name)
+
+		buf := &bytes.Buffer{}
+		for _, n := range nlris {
+			n.serialize(buf)
+		}
+		assert.Equal(t, test.input, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestDecodeTunnelEncapsulation(t *testing.T) {
+	pref := uint32(100)
+	prio := uint8(10)
+
+	tests := []struct {
+		name     string
+		input    []byte
+		wantFail bool
+		expected TunnelEncapsulation
+	}{
+		{
+			name: "SR-MPLS policy",
+			input: []byte{
+				0, 15, 0, 48, // SR Policy tunnel
+				12, 6, 0, 0, 0, 0, 0, 100, // Preference
+				13, 6, 0, 0, 0xf4, 0x24, 0, 0, // Binding SID 1000000
+				15, 2, 10, 0, // Priority
+				129, 0, 5, 0, 'b', 'l', 'u', 'e', // Candidate path name
+				128, 0, 17, 0, // Segment list
+				9, 6, 0, 0, 0, 0, 0, 2, // Weight
+				1, 6, 0, 0, 0x00, 0x3e, 0x80, 0, // Label 1000
+			},
+			expected: TunnelEncapsulation{
+				{
+					Type: TunnelTypeSRPolicy,
+					SRPolicy: &SRPolicy{
+						Preference: &pref,
+						Priority:   &prio,
+						BindingSID: &SRBindingSID{
+							Label: 1000000,
+						},
+						CandidatePathName: "blue",
+						SegmentLists: []*SRSegmentList{
+							{
+								Weight: 2,
+								Segments: []*SRSegment{
+									{
+										Type:  SRSegmentTypeA,
+										Label: 1000,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SRv6 policy",
+			input: []byte{
+				0, 15, 0, 24, // SR Policy tunnel
+				128, 0, 21, 0, // Segment list
+				13, 18, 0, 0, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // SRv6 SID
+			},
+			expected: TunnelEncapsulation{
+				{
+					Type: TunnelTypeSRPolicy,
+					SRPolicy: &SRPolicy{
+						SegmentLists: []*SRSegmentList{
+							{
+								Segments: []*SRSegment{
+									{
+										Type: SRSegmentTypeB,
+										SID:  bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Other tunnel type",
+			input: []byte{
+				0, 8, 0, 2, // VXLAN
+				1, 0,
+			},
+			expected: TunnelEncapsulation{
+				{
+					Type:    8,
+					SubTLVs: []byte{1, 0},
+				},
+			},
+		},
+		{
+			name: "Truncated sub-TLV",
+			input: []byte{
+				0, 15, 0, 4,
+				12, 6, 0, 0,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unsupported segment type",
+			input: []byte{
+				0, 15, 0, 10,
+				128, 0, 7, 0,
+				2, 4, 0, 0, 0, 0,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		te, err := decodeTunnelEncapsulation(test.input)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, te, "Test %q", test.name)
+
+		buf := &bytes.Buffer{}
+		te.serialize(buf)
+		assert.Equal(t, test.input, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestSerializeTunnelEncapAttribute(t *testing.T) {
+	pa := &PathAttribute{
+		TypeCode: TunnelEncapAttr,
+		Value: TunnelEncapsulation{
+			{
+				Type:    8,
+				SubTLVs: []byte{1, 0},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	pa.Serialize(buf, &EncodeOptions{})
+	assert.Equal(t, []byte{0xc0, TunnelEncapAttr, 6, 0, 8, 0, 2, 1, 0}, buf.Bytes())
+
+	x, _, err := decodePathAttr(buf, &DecodeOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, pa.Value, x.Value)
+}
@@ -4,7 +4,8 @@ import (
 	"bytes"
 	"fmt"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/bufpool"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 type BGPUpdate struct {
@@ -13,119 +14,138 @@ type BGPUpdate struct {
 	TotalPathAttrLen   uint16
 	PathAttributes     *PathAttribute
 	NLRI               *NLRI
+
+	// SerializedPathAttributes are path attributes in wire format written after PathAttributes.
+	// It allows to share the encoding of attributes between updates.
+	SerializedPathAttributes []byte
+
+	// AttributeErrors are the malformed attributes handled according to RFC7606 when decoding the update
+	AttributeErrors []*AttributeError
 }
 
 // SerializeUpdate serializes an BGPUpdate to wire format
 func (b *BGPUpdate) SerializeUpdate(opt *EncodeOptions) ([]byte, error) {
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+
+	err := b.SerializeUpdateTo(buf, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]byte, buf.Len())
+	copy(ret, buf.Bytes())
+	return ret, nil
+}
+
+// SerializeUpdateAddPath serializes an BGPUpdate to wire format
+func (b *BGPUpdate) SerializeUpdateAddPath(opt *EncodeOptions) ([]byte, error) {
+	return b.SerializeUpdate(opt)
+}
+
+// SerializeUpdateTo appends the wire format of the update to buf. Nothing is written on error.
+func (b *BGPUpdate) SerializeUpdateTo(buf *bytes.Buffer, opt *EncodeOptions) error {
 	budget := MaxLen - MinLen
-	buf := bytes.NewBuffer(nil)
 
-	withdrawBuf := bytes.NewBuffer(nil)
+	withdrawBuf := bufpool.Get()
+	defer bufpool.Put(withdrawBuf)
 	for withdraw := b.WithdrawnRoutes; withdraw != nil; withdraw = withdraw.Next {
 		budget -= int(withdraw.serialize(withdrawBuf, opt.UseAddPath))
 		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
+			return fmt.Errorf("update too long")
 		}
 	}
 
-	pathAttributesBuf := bytes.NewBuffer(nil)
+	pathAttributesBuf := bufpool.Get()
+	defer bufpool.Put(pathAttributesBuf)
 	for pa := b.PathAttributes; pa != nil; pa = pa.Next {
 		paLen := int(pa.Serialize(pathAttributesBuf, opt))
 		budget -= paLen
 		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
+			return fmt.Errorf("update too long")
 		}
 	}
 
-	nlriBuf := bytes.NewBuffer(nil)
+	budget -= len(b.SerializedPathAttributes)
+	if budget < 0 {
+		return fmt.Errorf("update too long")
+	}
+	pathAttributesBuf.Write(b.SerializedPathAttributes)
+
+	nlriBuf := bufpool.Get()
+	defer bufpool.Put(nlriBuf)
 	for nlri := b.NLRI; nlri != nil; nlri = nlri.Next {
 		budget -= int(nlri.serialize(nlriBuf, opt.UseAddPath))
 		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
+			return fmt.Errorf("update too long")
 		}
 	}
 
 	withdrawnRoutesLen := withdrawBuf.Len()
 	if withdrawnRoutesLen > 65535 {
-		return nil, fmt.Errorf("Invalid Withdrawn Routes Length: %d", withdrawnRoutesLen)
+		return fmt.Errorf("Invalid Withdrawn Routes Length: %d", withdrawnRoutesLen)
 	}
 
 	totalPathAttributesLen := pathAttributesBuf.Len()
 	if totalPathAttributesLen > 65535 {
-		return nil, fmt.Errorf("Invalid Total Path Attribute Length: %d", totalPathAttributesLen)
+		return fmt.Errorf("Invalid Total Path Attribute Length: %d", totalPathAttributesLen)
 	}
 
 	totalLength := 2 + withdrawnRoutesLen + totalPathAttributesLen + 2 + nlriBuf.Len() + 19
 	if totalLength > 4096 {
-		return nil, fmt.Errorf("Update too long: %d bytes", totalLength)
+		return fmt.Errorf("Update too long: %d bytes", totalLength)
 	}
 
+	buf.Grow(totalLength)
 	serializeHeader(buf, uint16(totalLength), UpdateMsg)
 
-	buf.Write(convert.Uint16Byte(uint16(withdrawnRoutesLen)))
+	endian.WriteUint16(buf, uint16(withdrawnRoutesLen))
 	buf.Write(withdrawBuf.Bytes())
 
-	buf.Write(convert.Uint16Byte(uint16(totalPathAttributesLen)))
+	endian.WriteUint16(buf, uint16(totalPathAttributesLen))
 	buf.Write(pathAttributesBuf.Bytes())
 
 	buf.Write(nlriBuf.Bytes())
 
-	return buf.Bytes(), nil
+	return nil
 }
 
-func (b *BGPUpdate) SerializeUpdateAddPath(opt *EncodeOptions) ([]byte, error) {
-	budget := MaxLen - MinLen
-	buf := bytes.NewBuffer(nil)
-
-	withdrawBuf := bytes.NewBuffer(nil)
-	for withdraw := b.WithdrawnRoutes; withdraw != nil; withdraw = withdraw.Next {
-		budget -= int(withdraw.serialize(withdrawBuf, opt.UseAddPath))
-		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
-		}
+// EndOfRIB creates the End-of-RIB marker of an address family (RFC4724 2). For IPv4 unicast this is an
+// empty update, for every other family an update carrying only an empty MP_UNREACH_NLRI attribute.
+func EndOfRIB(afi uint16, safi uint8) *BGPUpdate {
+	if afi == IPv4AFI && safi == UnicastSAFI {
+		return &BGPUpdate{}
 	}
 
-	pathAttributesBuf := bytes.NewBuffer(nil)
-	for pa := b.PathAttributes; pa != nil; pa = pa.Next {
-		paLen := int(pa.Serialize(pathAttributesBuf, opt))
-		budget -= paLen
-		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
-		}
+	return &BGPUpdate{
+		PathAttributes: &PathAttribute{
+			TypeCode: MultiProtocolUnreachNLRICode,
+			Value: MultiProtocolUnreachNLRI{
+				AFI:  afi,
+				SAFI: safi,
+			},
+		},
 	}
+}
 
-	nlriBuf := bytes.NewBuffer(nil)
-	for nlri := b.NLRI; nlri != nil; nlri = nlri.Next {
-		budget -= int(nlri.serialize(nlriBuf, opt.UseAddPath))
-		if budget < 0 {
-			return nil, fmt.Errorf("update too long")
-		}
+// IsEndOfRIB checks if the update is an End-of-RIB marker and returns the address family it refers to
+func (b *BGPUpdate) IsEndOfRIB() (afi uint16, safi uint8, ok bool) {
+	if b.WithdrawnRoutes != nil || b.NLRI != nil || b.AttributeErrors != nil {
+		return 0, 0, false
 	}
 
-	withdrawnRoutesLen := withdrawBuf.Len()
-	if withdrawnRoutesLen > 65535 {
-		return nil, fmt.Errorf("Invalid Withdrawn Routes Length: %d", withdrawnRoutesLen)
+	if b.PathAttributes == nil {
+		return IPv4AFI, UnicastSAFI, true
 	}
 
-	totalPathAttributesLen := pathAttributesBuf.Len()
-	if totalPathAttributesLen > 65535 {
-		return nil, fmt.Errorf("Invalid Total Path Attribute Length: %d", totalPathAttributesLen)
+	if b.PathAttributes.Next != nil || b.PathAttributes.TypeCode != MultiProtocolUnreachNLRICode {
+		return 0, 0, false
 	}
 
-	totalLength := 2 + withdrawnRoutesLen + totalPathAttributesLen + 2 + nlriBuf.Len() + 19
-	if totalLength > 4096 {
-		return nil, fmt.Errorf("Update too long: %d bytes", totalLength)
+	n := b.PathAttributes.Value.(MultiProtocolUnreachNLRI)
+	if n.NLRI != nil {
+		return 0, 0, false
 	}
 
-	serializeHeader(buf, uint16(totalLength), UpdateMsg)
-
-	buf.Write(convert.Uint16Byte(uint16(withdrawnRoutesLen)))
-	buf.Write(withdrawBuf.Bytes())
-
-	buf.Write(convert.Uint16Byte(uint16(totalPathAttributesLen)))
-	buf.Write(pathAttributesBuf.Bytes())
-
-	buf.Write(nlriBuf.Bytes())
-
-	return buf.Bytes(), nil
+	return n.AFI, n.SAFI, true
 }
@@ -0,0 +1,115 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndOfRIB(t *testing.T) {
+	tests := []struct {
+		name     string
+		afi      uint16
+		safi     uint8
+		expected []byte
+	}{
+		{
+			name: "IPv4 unicast",
+			afi:  IPv4AFI,
+			safi: UnicastSAFI,
+			expected: []byte{
+				255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+				0, 23, UpdateMsg,
+				0, 0, // Withdrawn Routes Length
+				0, 0, // Total Path Attribute Length
+			},
+		},
+		{
+			name: "IPv6 unicast",
+			afi:  IPv6AFI,
+			safi: UnicastSAFI,
+			expected: []byte{
+				255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+				0, 29, UpdateMsg,
+				0, 0, // Withdrawn Routes Length
+				0, 6, // Total Path Attribute Length
+				0x80, MultiProtocolUnreachNLRICode, 3, // Optional, MP_UNREACH_NLRI, length
+				0, 2, 1, // AFI, SAFI
+			},
+		},
+	}
+
+	for _, test := range tests {
+		b, err := EndOfRIB(test.afi, test.safi).SerializeUpdate(&EncodeOptions{})
+		if err != nil {
+			t.Errorf("Unexpected failure for test %q: %v", test.name, err)
+			continue
+		}
+
+		assert.Equal(t, test.expected, b, test.name)
+
+		msg, err := Decode(bytes.NewBuffer(b), &DecodeOptions{})
+		if err != nil {
+			t.Errorf("Unable to decode marker of test %q: %v", test.name, err)
+			continue
+		}
+
+		afi, safi, ok := msg.Body.(*BGPUpdate).IsEndOfRIB()
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.afi, afi, test.name)
+		assert.Equal(t, test.safi, safi, test.name)
+	}
+}
+
+func TestIsEndOfRIB(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   *BGPUpdate
+		expected bool
+	}{
+		{
+			name: "Withdraw",
+			update: &BGPUpdate{
+				WithdrawnRoutes: &NLRI{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+				},
+			},
+		},
+		{
+			name: "MP_UNREACH_NLRI with prefixes",
+			update: &BGPUpdate{
+				PathAttributes: &PathAttribute{
+					TypeCode: MultiProtocolUnreachNLRICode,
+					Value: MultiProtocolUnreachNLRI{
+						AFI:  IPv6AFI,
+						SAFI: UnicastSAFI,
+						NLRI: &NLRI{
+							Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Origin only",
+			update: &BGPUpdate{
+				PathAttributes: &PathAttribute{
+					TypeCode: OriginAttr,
+					Value:    uint8(0),
+				},
+			},
+		},
+		{
+			name:     "Empty update",
+			update:   &BGPUpdate{},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, _, ok := test.update.IsEndOfRIB()
+		assert.Equal(t, test.expected, ok, test.name)
+	}
+}
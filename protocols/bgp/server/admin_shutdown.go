@@ -0,0 +1,288 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/sirupsen/logrus"
+)
+
+// drainExportFilter withdraws all paths sent to a peer being shut down administratively
+var drainExportFilter = filter.NewFilter("admin-shutdown-drain", []*filter.Term{
+	filter.NewTerm("drain", nil, []actions.Action{
+		actions.NewRejectAction(),
+	}),
+})
+
+// adminState is the administrative state of a peer. Peers shut down administratively are kept down until started again.
+type adminState struct {
+	mu       sync.Mutex
+	down     bool
+	draining bool
+	timer    *time.Timer
+
+	// message is the shutdown communication (RFC9003) sent when closing the session of a peer shut down
+	message string
+
+	// reset is set if the session is being reset administratively with resetMessage as shutdown communication
+	reset        bool
+	resetMessage string
+}
+
+func (a *adminState) shutdown(message string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.down {
+		return false
+	}
+
+	a.down = true
+	a.draining = true
+	a.message = message
+	return true
+}
+
+func (a *adminState) setReset(message string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.reset = true
+	a.resetMessage = message
+}
+
+// takeReset gets and clears a pending administrative reset
+func (a *adminState) takeReset() (reset bool, message string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	reset, message = a.reset, a.resetMessage
+	a.reset = false
+	a.resetMessage = ""
+	return reset, message
+}
+
+// shutdownMessage gets if the peer is shut down and the shutdown communication to send
+func (a *adminState) shutdownMessage() (down bool, message string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.down, a.message
+}
+
+func (a *adminState) setTimer(t *time.Timer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.timer = t
+}
+
+// drained ends draining. Returns false if the peer has been started again meanwhile.
+func (a *adminState) drained() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.draining = false
+	a.timer = nil
+	return a.down
+}
+
+// start clears the administrative shutdown. Returns if the peer was down and if it was still draining.
+func (a *adminState) start() (wasDown bool, wasDraining bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+
+	wasDown, wasDraining = a.down, a.draining
+	a.down = false
+	a.draining = false
+	a.message = ""
+	return wasDown, wasDraining
+}
+
+func (a *adminState) isDown() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.down
+}
+
+func (a *adminState) isDraining() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.draining
+}
+
+// selectPeers gets the peer with address addr, all peers if addr is nil
+func (b *bgpServer) selectPeers(addr *bnet.IP) ([]*peer, error) {
+	if addr == nil {
+		return b.peers.list(), nil
+	}
+
+	p := b.peers.get(addr)
+	if p == nil {
+		return nil, fmt.Errorf("peer %s not found", addr.String())
+	}
+
+	return []*peer{p}, nil
+}
+
+// AdminShutdown shuts down a peer (all peers if addr is nil) for maintenance. First all paths sent to the peer are
+// withdrawn and all paths received from it get the lowest local preference. After drainTime the session is closed with
+// an Administrative Shutdown Cease NOTIFICATION (RFC4486) carrying message as shutdown communication (RFC9003) and
+// kept down until AdminStart is called. The configured shutdown communication of the peer is sent if message is empty.
+func (b *bgpServer) AdminShutdown(addr *bnet.IP, drainTime time.Duration, message string) error {
+	peers, err := b.selectPeers(addr)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peers {
+		p.adminShutdown(drainTime, message)
+	}
+
+	return nil
+}
+
+// AdminReset closes the sessions of a peer (all peers if addr is nil) with an Administrative Reset Cease NOTIFICATION
+// (RFC4486) carrying message as shutdown communication (RFC9003). The sessions are reestablished afterwards.
+func (b *bgpServer) AdminReset(addr *bnet.IP, message string) error {
+	peers, err := b.selectPeers(addr)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peers {
+		p.adminReset(message)
+	}
+
+	return nil
+}
+
+// AdminStart starts a peer (all peers if addr is nil) shut down by AdminShutdown again
+func (b *bgpServer) AdminStart(addr *bnet.IP) error {
+	peers, err := b.selectPeers(addr)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peers {
+		p.adminStart()
+	}
+
+	return nil
+}
+
+func (p *peer) adminShutdown(drainTime time.Duration, message string) {
+	if !p.admin.shutdown(message) {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":       p.addr.String(),
+		"drain_time": drainTime,
+		"message":    message,
+	}).Info("Shutting down peer administratively")
+
+	p.refreshFilterChains()
+	p.admin.setTimer(time.AfterFunc(drainTime, p.adminStop))
+}
+
+// adminStop closes the sessions of a drained peer
+func (p *peer) adminStop() {
+	if !p.admin.drained() {
+		return
+	}
+
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	for _, fsm := range p.fsms {
+		fsm.eventCh <- ManualStop
+	}
+}
+
+func (p *peer) adminReset(message string) {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	if p.stopped || p.admin.isDown() {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":    p.addr.String(),
+		"message": message,
+	}).Info("Resetting peer administratively")
+
+	p.admin.setReset(message)
+	for _, fsm := range p.fsms {
+		fsm.eventCh <- ManualStop
+	}
+}
+
+func (p *peer) adminStart() {
+	wasDown, wasDraining := p.admin.start()
+	if !wasDown {
+		return
+	}
+
+	log.WithField("peer", p.addr.String()).Info("Starting peer shut down administratively")
+
+	// The session has not been closed yet
+	if wasDraining {
+		p.refreshFilterChains()
+		return
+	}
+
+	if p.heldForPrefixLimit() {
+		return
+	}
+
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	if p.stopped || p.passive || len(p.fsms) == 0 {
+		return
+	}
+
+	go p.fsms[0].activate()
+}
+
+// ceaseNotification gets the Cease NOTIFICATION sent when a session is stopped manually
+func (p *peer) ceaseNotification() *packet.BGPNotification {
+	if down, message := p.admin.shutdownMessage(); down {
+		return packet.NewShutdownNotification(packet.AdminShut, p.shutdownCommunication(message))
+	}
+
+	if reset, message := p.admin.takeReset(); reset {
+		return packet.NewShutdownNotification(packet.AdminReset, p.shutdownCommunication(message))
+	}
+
+	return &packet.BGPNotification{
+		ErrorCode: packet.Cease,
+	}
+}
+
+// shutdownCommunication gets the shutdown communication to send, the configured one if message is empty
+func (p *peer) shutdownCommunication(message string) string {
+	if message == "" && p.config != nil {
+		return p.config.ShutdownCommunication
+	}
+
+	return message
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminShutdown(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+
+	rib := locRIB.New("inet.0")
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config:   &PeerConfig{},
+		passive:  true,
+		ipv4: &peerAddressFamily{
+			rib:               rib,
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	p.fsms = append(p.fsms, s.fsm)
+	u := grTestUpdate(pfx)
+	u.PathAttributes.Next.Next = &packet.PathAttribute{
+		TypeCode: packet.LocalPrefAttr,
+		Value:    uint32(100),
+		Next:     u.PathAttributes.Next.Next,
+	}
+	s.update(u, time.Now())
+
+	localPref := func() uint32 {
+		return rib.Get(pfx).BestPath().BGPPath.BGPPathA.LocalPref
+	}
+	assert.Equal(t, uint32(100), localPref())
+
+	// Starting the peer again while draining restores exports without closing the session
+	p.adminShutdown(time.Hour, "")
+	assert.True(t, p.admin.isDraining())
+	assert.Equal(t, drainExportFilter, s.fsm.ipv4Unicast.effectiveExportFilterChain()[0])
+	assert.Equal(t, uint32(0), localPref(), "Paths of a draining peer must get the lowest local preference")
+
+	p.adminStart()
+	assert.False(t, p.admin.isDown())
+	assert.NotContains(t, s.fsm.ipv4Unicast.effectiveExportFilterChain(), drainExportFilter)
+	assert.Equal(t, uint32(100), localPref())
+
+	// The session is closed after the drain time
+	p.adminShutdown(0, "maintenance")
+	select {
+	case e := <-s.fsm.eventCh:
+		assert.Equal(t, ManualStop, e)
+	case <-time.After(time.Second):
+		t.Fatalf("Session has not been stopped after the drain time")
+	}
+
+	assert.False(t, p.admin.isDraining())
+	assert.Equal(t, packet.NewShutdownNotification(packet.AdminShut, "maintenance"), p.ceaseNotification())
+	next, _ := s.manualStop()
+	_, idle := next.(*idleState)
+	assert.True(t, idle)
+
+	p.adminStart()
+	assert.Equal(t, &packet.BGPNotification{ErrorCode: packet.Cease}, p.ceaseNotification())
+}
+
+func TestAdminReset(t *testing.T) {
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config: &PeerConfig{
+			ShutdownCommunication: "configured",
+		},
+		passive: true,
+		ipv4: &peerAddressFamily{
+			rib:               locRIB.New("inet.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	p.fsms = append(p.fsms, s.fsm)
+
+	go p.adminReset("")
+	select {
+	case e := <-s.fsm.eventCh:
+		assert.Equal(t, ManualStop, e)
+	case <-time.After(time.Second):
+		t.Fatalf("Session has not been reset")
+	}
+
+	assert.Equal(t, packet.NewShutdownNotification(packet.AdminReset, "configured"), p.ceaseNotification())
+	assert.Equal(t, &packet.BGPNotification{ErrorCode: packet.Cease}, p.ceaseNotification(), "A reset must only be sent once")
+	assert.False(t, p.admin.isDown())
+}
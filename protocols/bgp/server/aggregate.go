@@ -0,0 +1,469 @@
+package server
+
+import (
+	"sort"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+)
+
+const aggregateLocalPref = 100
+
+// AggregateConfig configures an aggregate generated while more specific BGP routes exist (RFC4271 9.2.2.2)
+type AggregateConfig struct {
+	Prefix  *bnet.Prefix
+	LocalAS uint32
+
+	// SummaryOnly suppresses the advertisement of the more specific routes to all peers
+	SummaryOnly bool
+
+	// ASSet carries the AS numbers of the more specific routes as AS_SET instead of setting ATOMIC_AGGREGATE
+	ASSet bool
+}
+
+func (c *AggregateConfig) equal(x *AggregateConfig) bool {
+	return c.Prefix.Equal(x.Prefix) && c.LocalAS == x.LocalAS && c.SummaryOnly == x.SummaryOnly && c.ASSet == x.ASSet
+}
+
+// aggregator generates the aggregates of a RIB. It watches the best paths of the RIB for contributing routes.
+type aggregator struct {
+	rib      *locRIB.LocRIB
+	routerID uint32
+
+	// guarded by mu, which must not be held while calling the Loc-RIB
+	mu          sync.Mutex
+	aggregates  []*aggregate
+	withdrawn   []*aggregate
+	suppression *filter.Filter
+
+	processMu sync.Mutex
+	dirtyCh   chan struct{}
+}
+
+type aggregate struct {
+	AggregateConfig
+	contributors map[bnet.Prefix]*route.Path
+	installed    *route.Path
+	dirty        bool
+}
+
+func newAggregator(rib *locRIB.LocRIB, routerID uint32) *aggregator {
+	return &aggregator{
+		rib:      rib,
+		routerID: routerID,
+		dirtyCh:  make(chan struct{}, 1),
+	}
+}
+
+// ReplaceAggregates replaces the aggregates generated in a RIB
+func (b *bgpServer) ReplaceAggregates(rib *locRIB.LocRIB, aggregates []AggregateConfig) {
+	a := b.aggregator(rib, len(aggregates) > 0)
+	if a == nil {
+		return
+	}
+
+	if !a.replace(aggregates) {
+		return
+	}
+
+	for _, p := range b.peers.list() {
+		p.refreshFilterChains()
+	}
+}
+
+// aggregator gets the aggregator of a RIB. It is created and started on first use if create is set.
+func (b *bgpServer) aggregator(rib *locRIB.LocRIB, create bool) *aggregator {
+	b.aggregatorsMu.Lock()
+	defer b.aggregatorsMu.Unlock()
+
+	if a, ok := b.aggregators[rib]; ok || !create {
+		return a
+	}
+
+	if b.aggregators == nil {
+		b.aggregators = make(map[*locRIB.LocRIB]*aggregator)
+	}
+
+	a := newAggregator(rib, b.routerID)
+	b.aggregators[rib] = a
+	rib.Register(a)
+	go a.run()
+
+	return a
+}
+
+// aggregateSuppression gets the filter rejecting the more specifics of summary only aggregates of a RIB, nil if there are none
+func (b *bgpServer) aggregateSuppression(rib *locRIB.LocRIB) *filter.Filter {
+	if b == nil {
+		return nil
+	}
+
+	a := b.aggregator(rib, false)
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.suppression
+}
+
+// replace replaces the aggregates. Returns true if the suppressed prefixes changed.
+func (a *aggregator) replace(configs []AggregateConfig) bool {
+	a.mu.Lock()
+
+	aggregates := make([]*aggregate, 0, len(configs))
+	for i := range configs {
+		if ag := a.find(&configs[i]); ag != nil {
+			aggregates = append(aggregates, ag)
+			continue
+		}
+
+		aggregates = append(aggregates, &aggregate{
+			AggregateConfig: configs[i],
+			contributors:    make(map[bnet.Prefix]*route.Path),
+		})
+	}
+
+	for _, ag := range a.aggregates {
+		if !containsAggregate(aggregates, ag) {
+			a.withdrawn = append(a.withdrawn, ag)
+		}
+	}
+
+	old := summaryOnlyPrefixes(a.aggregates)
+	a.aggregates = aggregates
+	a.suppression = newAggregateSuppressionFilter(aggregates)
+	changed := old != summaryOnlyPrefixes(aggregates)
+	a.mu.Unlock()
+
+	// Contributors of new aggregates are learned from the current best paths
+	a.rib.RefreshClient(a)
+	a.signal()
+
+	return changed
+}
+
+func (a *aggregator) find(c *AggregateConfig) *aggregate {
+	for _, ag := range a.aggregates {
+		if ag.equal(c) {
+			return ag
+		}
+	}
+
+	return nil
+}
+
+func containsAggregate(aggregates []*aggregate, ag *aggregate) bool {
+	for _, x := range aggregates {
+		if x == ag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func summaryOnlyPrefixes(aggregates []*aggregate) string {
+	s := ""
+	for _, ag := range aggregates {
+		if ag.SummaryOnly {
+			s += ag.Prefix.String() + " "
+		}
+	}
+
+	return s
+}
+
+func newAggregateSuppressionFilter(aggregates []*aggregate) *filter.Filter {
+	terms := make([]*filter.Term, 0)
+	for _, ag := range aggregates {
+		if !ag.SummaryOnly {
+			continue
+		}
+
+		terms = append(terms, filter.NewTerm(ag.Prefix.String(), []*filter.TermCondition{
+			filter.NewTermConditionWithRouteFilters(filter.NewRouteFilter(ag.Prefix, filter.NewLongerMatcher())),
+		}, []actions.Action{
+			actions.NewRejectAction(),
+		}))
+	}
+
+	if len(terms) == 0 {
+		return nil
+	}
+
+	return filter.NewFilter("aggregate-summary-only", terms)
+}
+
+func (a *aggregator) signal() {
+	select {
+	case a.dirtyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (a *aggregator) run() {
+	defer crashdump.Recover()
+
+	for range a.dirtyCh {
+		a.process()
+	}
+}
+
+type aggregateChange struct {
+	pfx *bnet.Prefix
+	old *route.Path
+	new *route.Path
+}
+
+// process installs, replaces and removes aggregates whose contributors changed
+func (a *aggregator) process() {
+	a.processMu.Lock()
+	defer a.processMu.Unlock()
+
+	a.mu.Lock()
+	changes := make([]aggregateChange, 0)
+	for _, ag := range a.withdrawn {
+		if ag.installed != nil {
+			changes = append(changes, aggregateChange{pfx: ag.Prefix, old: ag.installed})
+		}
+	}
+	a.withdrawn = nil
+
+	for _, ag := range a.aggregates {
+		if !ag.dirty {
+			continue
+		}
+
+		ag.dirty = false
+		p := ag.path(a.routerID)
+		if p == nil && ag.installed == nil || p != nil && ag.installed != nil && p.Compare(ag.installed) {
+			continue
+		}
+
+		changes = append(changes, aggregateChange{pfx: ag.Prefix, old: ag.installed, new: p})
+		ag.installed = p
+	}
+	a.mu.Unlock()
+
+	for _, c := range changes {
+		if c.old != nil {
+			a.rib.RemovePath(c.pfx, c.old)
+		}
+
+		if c.new != nil {
+			a.rib.AddPath(c.pfx, c.new)
+		}
+	}
+}
+
+// path builds the aggregate path from the contributing paths, nil if there are none (RFC4271 9.2.2.2)
+func (ag *aggregate) path(routerID uint32) *route.Path {
+	if len(ag.contributors) == 0 {
+		return nil
+	}
+
+	zero := bnet.IPv4(0)
+	if !ag.Prefix.Addr().IsIPv4() {
+		zero = bnet.IPv6(0, 0)
+	}
+
+	aggregatorASN := uint16(packet.ASTransASN)
+	if ag.LocalAS <= 0xffff {
+		aggregatorASN = uint16(ag.LocalAS)
+	}
+
+	pa := &route.BGPPathA{
+		NextHop:       zero.Ptr(),
+		Source:        zero.Ptr(),
+		LocalPref:     aggregateLocalPref,
+		BGPIdentifier: routerID,
+		Origin:        packet.IGP,
+		Aggregator: &types.Aggregator{
+			Address: routerID,
+			ASN:     aggregatorASN,
+		},
+	}
+
+	paths := make([]*route.BGPPath, 0, len(ag.contributors))
+	asPathLost := false
+	for _, p := range ag.contributors {
+		paths = append(paths, p.BGPPath)
+
+		// INCOMPLETE wins over EGP, EGP over IGP
+		if p.BGPPath.BGPPathA.Origin > pa.Origin {
+			pa.Origin = p.BGPPath.BGPPathA.Origin
+		}
+
+		if p.BGPPath.BGPPathA.AtomicAggregate {
+			pa.AtomicAggregate = true
+		}
+
+		if p.BGPPath.ASPathLen > 0 {
+			asPathLost = true
+		}
+	}
+
+	asPath := types.ASPath{}
+	if ag.ASSet {
+		asPath = aggregateASPath(paths)
+	} else if asPathLost {
+		pa.AtomicAggregate = true
+	}
+
+	return &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA:  pa,
+			ASPath:    &asPath,
+			ASPathLen: asPath.Length(),
+			Local:     true,
+		},
+	}
+}
+
+// aggregateASPath builds the AS path of an aggregate: The AS_SEQUENCE all paths start with followed by an AS_SET
+// of all other AS numbers of the paths
+func aggregateASPath(paths []*route.BGPPath) types.ASPath {
+	var common []uint32
+	for i, p := range paths {
+		seq := leadingSequence(p.ASPath)
+		if i == 0 {
+			common = seq
+			continue
+		}
+
+		n := 0
+		for n < len(common) && n < len(seq) && common[n] == seq[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	inCommon := make(map[uint32]struct{}, len(common))
+	for _, asn := range common {
+		inCommon[asn] = struct{}{}
+	}
+
+	set := make(map[uint32]struct{})
+	for _, p := range paths {
+		if p.ASPath == nil {
+			continue
+		}
+
+		for _, seg := range *p.ASPath {
+			for _, asn := range seg.ASNs {
+				if _, ok := inCommon[asn]; !ok {
+					set[asn] = struct{}{}
+				}
+			}
+		}
+	}
+
+	res := types.ASPath{}
+	if len(common) > 0 {
+		res = append(res, types.ASPathSegment{
+			Type: types.ASSequence,
+			ASNs: append([]uint32(nil), common...),
+		})
+	}
+
+	if len(set) > 0 {
+		asns := make([]uint32, 0, len(set))
+		for asn := range set {
+			asns = append(asns, asn)
+		}
+		sort.Slice(asns, func(i, j int) bool { return asns[i] < asns[j] })
+
+		res = append(res, types.ASPathSegment{
+			Type: types.ASSet,
+			ASNs: asns,
+		})
+	}
+
+	return res
+}
+
+func leadingSequence(p *types.ASPath) []uint32 {
+	if p == nil || len(*p) == 0 || (*p)[0].Type != types.ASSequence {
+		return nil
+	}
+
+	return (*p)[0].ASNs
+}
+
+// contribute adds or removes the best path of pfx from all aggregates covering it
+func (a *aggregator) contribute(pfx *bnet.Prefix, p *route.Path, add bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	changed := false
+	for _, ag := range a.aggregates {
+		if !ag.Prefix.Contains(pfx) {
+			continue
+		}
+
+		current, exists := ag.contributors[*pfx]
+		if add {
+			ag.contributors[*pfx] = p
+		} else if exists && current.Compare(p) {
+			delete(ag.contributors, *pfx)
+		} else {
+			continue
+		}
+
+		ag.dirty = true
+		changed = true
+	}
+
+	if changed {
+		a.signal()
+	}
+}
+
+// AddPath adds a BGP best path to the contributors of the aggregates covering pfx
+func (a *aggregator) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	if p.Type == route.BGPPathType {
+		a.contribute(pfx, p, true)
+	}
+
+	return nil
+}
+
+// AddPathInitialDump adds a path
+func (a *aggregator) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return a.AddPath(pfx, p)
+}
+
+// RemovePath removes a BGP best path from the contributors of the aggregates covering pfx
+func (a *aggregator) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	if p.Type == route.BGPPathType {
+		a.contribute(pfx, p, false)
+	}
+
+	return true
+}
+
+// ReplacePath replaces a contributing path
+func (a *aggregator) ReplacePath(pfx *bnet.Prefix, old *route.Path, new *route.Path) {
+	a.RemovePath(pfx, old)
+	a.AddPath(pfx, new)
+}
+
+// RefreshRoute sets the contributing path of pfx to its current best path
+func (a *aggregator) RefreshRoute(pfx *bnet.Prefix, paths []*route.Path) {
+	if len(paths) == 0 || paths[0].Type != route.BGPPathType {
+		return
+	}
+
+	a.contribute(pfx, paths[0], true)
+}
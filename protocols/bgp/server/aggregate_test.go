@@ -0,0 +1,155 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func aggregateTestPath(origin uint8, asPath types.ASPath) *route.Path {
+	return &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				NextHop:   bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				Source:    bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				LocalPref: 100,
+				Origin:    origin,
+			},
+			ASPath:    &asPath,
+			ASPathLen: asPath.Length(),
+		},
+	}
+}
+
+func TestAggregateASPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []types.ASPath
+		expected types.ASPath
+	}{
+		{
+			name: "Common sequence only",
+			paths: []types.ASPath{
+				{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}},
+				{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}},
+			},
+			expected: types.ASPath{
+				{Type: types.ASSequence, ASNs: []uint32{65001, 65002}},
+			},
+		},
+		{
+			name: "Common sequence and set",
+			paths: []types.ASPath{
+				{{Type: types.ASSequence, ASNs: []uint32{65001, 65003}}},
+				{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}, {Type: types.ASSet, ASNs: []uint32{65004}}},
+			},
+			expected: types.ASPath{
+				{Type: types.ASSequence, ASNs: []uint32{65001}},
+				{Type: types.ASSet, ASNs: []uint32{65002, 65003, 65004}},
+			},
+		},
+		{
+			name: "Nothing in common",
+			paths: []types.ASPath{
+				{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+				{{Type: types.ASSequence, ASNs: []uint32{65002, 65001}}},
+			},
+			expected: types.ASPath{
+				{Type: types.ASSet, ASNs: []uint32{65001, 65002}},
+			},
+		},
+		{
+			name: "Locally originated",
+			paths: []types.ASPath{
+				{},
+				{},
+			},
+			expected: types.ASPath{},
+		},
+	}
+
+	for _, test := range tests {
+		paths := make([]*route.BGPPath, 0)
+		for _, p := range test.paths {
+			paths = append(paths, aggregateTestPath(packet.IGP, p).BGPPath)
+		}
+
+		assert.Equal(t, test.expected, aggregateASPath(paths), "Test %q", test.name)
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	aggPfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 0, 0), 16).Ptr()
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(10, 2, 0, 0), 16).Ptr()
+	routerID := bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32()
+
+	tests := []struct {
+		name           string
+		asSet          bool
+		expectedASPath types.ASPath
+		expectedAtomic bool
+	}{
+		{
+			name:           "Without AS set",
+			expectedASPath: types.ASPath{},
+			expectedAtomic: true,
+		},
+		{
+			name:  "With AS set",
+			asSet: true,
+			expectedASPath: types.ASPath{
+				{Type: types.ASSequence, ASNs: []uint32{65001}},
+				{Type: types.ASSet, ASNs: []uint32{65002, 65003}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		rib := locRIB.New("inet.0")
+		a := newAggregator(rib, routerID)
+		rib.Register(a)
+
+		pathA := aggregateTestPath(packet.IGP, types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}})
+		pathB := aggregateTestPath(packet.INCOMPLETE, types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001, 65003}}})
+
+		// Contributors existing before the aggregate has been configured are picked up
+		rib.AddPath(pfxA, pathA)
+		a.replace([]AggregateConfig{
+			{
+				Prefix:      aggPfx,
+				LocalAS:     65000,
+				SummaryOnly: true,
+				ASSet:       test.asSet,
+			},
+		})
+		rib.AddPath(pfxB, pathB)
+		a.process()
+
+		r := rib.Get(aggPfx)
+		if !assert.NotNil(t, r, "Test %q", test.name) {
+			continue
+		}
+
+		p := r.BestPath().BGPPath
+		assert.True(t, p.Local, "Test %q", test.name)
+		assert.Equal(t, test.expectedASPath, *p.ASPath, "Test %q", test.name)
+		assert.Equal(t, test.expectedAtomic, p.BGPPathA.AtomicAggregate, "Test %q", test.name)
+		assert.Equal(t, uint8(packet.INCOMPLETE), p.BGPPathA.Origin, "Test %q", test.name)
+		assert.Equal(t, &types.Aggregator{Address: routerID, ASN: 65000}, p.BGPPathA.Aggregator, "Test %q", test.name)
+
+		assert.True(t, a.suppression.Process(pfxA, pathA).Reject, "Test %q: More specifics must be suppressed", test.name)
+		assert.False(t, a.suppression.Process(aggPfx, r.BestPath()).Reject, "Test %q: Aggregate must not be suppressed", test.name)
+
+		rib.RemovePath(pfxA, pathA)
+		rib.RemovePath(pfxB, pathB)
+		a.process()
+		assert.Equal(t, 0, len(rib.Get(aggPfx).Paths()), "Test %q: Aggregate must be removed without contributors", test.name)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+const (
+	// attributeCacheGenerationSize is the number of entries after which a new generation of the cache is started
+	attributeCacheGenerationSize = 1 << 16
+)
+
+// attributeCache shares the wire format of path attributes between the update senders of an update group,
+// so attributes of a path are serialized only once for all members. Entries not used for two generations are dropped.
+type attributeCache struct {
+	mu       sync.Mutex
+	current  map[attributeCacheKey]*serializedAttributes
+	previous map[attributeCacheKey]*serializedAttributes
+}
+
+type attributeCacheKey struct {
+	hash          string
+	iBGP          bool
+	rrClient      bool
+	use32BitASN   bool
+	multiProtocol bool
+}
+
+// serializedAttributes are the path attributes of a path in wire format
+type serializedAttributes struct {
+	attrs []byte
+
+	// nextHop is the next hop of multi protocol updates, which is carried in the MP_REACH_NLRI attribute instead
+	nextHop *bnet.IP
+}
+
+func newAttributeCache() *attributeCache {
+	return &attributeCache{
+		current: make(map[attributeCacheKey]*serializedAttributes),
+	}
+}
+
+func (c *attributeCache) get(k attributeCacheKey) (*serializedAttributes, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a, ok := c.current[k]; ok {
+		return a, true
+	}
+
+	a, ok := c.previous[k]
+	if ok {
+		c.add(k, a)
+	}
+
+	return a, ok
+}
+
+func (c *attributeCache) set(k attributeCacheKey, a *serializedAttributes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(k, a)
+}
+
+func (c *attributeCache) add(k attributeCacheKey, a *serializedAttributes) {
+	if len(c.current) >= attributeCacheGenerationSize {
+		c.previous = c.current
+		c.current = make(map[attributeCacheKey]*serializedAttributes)
+	}
+
+	c.current[k] = a
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/protocols/bgp/api"
+	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
 	"github.com/bio-routing/bio-rd/route"
 
 	bnet "github.com/bio-routing/bio-rd/net"
@@ -22,8 +23,83 @@ func NewBGPAPIServer(s BGPServer) *BGPAPIServer {
 	}
 }
 
+// ListSessions lists the sessions matching a filter with their state, statistics and last NOTIFICATIONs
 func (s *BGPAPIServer) ListSessions(ctx context.Context, in *api.ListSessionsRequest) (*api.ListSessionsResponse, error) {
-	return nil, fmt.Errorf("Not implemented yet")
+	m, err := s.srv.Metrics()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.ListSessionsResponse{}
+	for _, p := range m.Peers {
+		if !sessionFilterMatches(in.Filter, p) {
+			continue
+		}
+
+		resp.Sessions = append(resp.Sessions, sessionToProto(p))
+	}
+
+	return resp, nil
+}
+
+func sessionFilterMatches(f *api.SessionFilter, p *metrics.BGPPeerMetrics) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.NeighborIp != nil && !bnet.IPFromProtoIP(f.NeighborIp).Equal(p.IP) {
+		return false
+	}
+
+	if f.VrfName != "" && f.VrfName != p.VRF {
+		return false
+	}
+
+	return true
+}
+
+func sessionToProto(p *metrics.BGPPeerMetrics) *api.Session {
+	s := &api.Session{
+		NeighborAddress: p.IP.ToProto(),
+		LocalAsn:        p.LocalASN,
+		PeerAsn:         p.ASN,
+		Status:          api.Session_State(p.State),
+		Stats: &api.SessionStats{
+			MessagesIn:  p.UpdatesReceived + messageCount(p.MessagesReceived),
+			MessagesOut: p.UpdatesSent + messageCount(p.MessagesSent),
+			Flaps:       p.Flaps,
+		},
+		LastNotificationSent:     notificationToProto(p.LastNotificationSent),
+		LastNotificationReceived: notificationToProto(p.LastNotificationReceived),
+	}
+
+	if p.Up {
+		s.EstablishedSince = uint64(p.Since.Unix())
+	}
+
+	for _, f := range p.AddressFamilies {
+		s.Stats.RoutesReceived += f.RoutesReceived
+		s.Stats.RoutesExported += f.RoutesSent
+	}
+
+	return s
+}
+
+func messageCount(c metrics.BGPMessageCounts) uint64 {
+	return c.Opens + c.Keepalives + c.Notifications + c.RouteRefreshes
+}
+
+func notificationToProto(n *metrics.BGPNotification) *api.Notification {
+	if n == nil {
+		return nil
+	}
+
+	return &api.Notification{
+		ErrorCode:    uint32(n.ErrorCode),
+		ErrorSubcode: uint32(n.ErrorSubcode),
+		Description:  n.Description,
+		Time:         uint64(n.Time.Unix()),
+	}
 }
 
 // DumpRIBIn dumps the RIB in of a peer for a given AFI/SAFI
@@ -62,6 +138,72 @@ func (s *BGPAPIServer) DumpRIBOut(in *api.DumpRIBRequest, stream api.BgpService_
 	return nil
 }
 
+// LookupRoutes looks up the routes for a prefix in the Loc-RIB, the Adj-RIB-In or the Adj-RIB-Out of a peer
+func (s *BGPAPIServer) LookupRoutes(ctx context.Context, in *api.LookupRoutesRequest) (*api.LookupRoutesResponse, error) {
+	if in.Pfx == nil {
+		return nil, fmt.Errorf("Prefix is missing")
+	}
+
+	rib, err := s.lookupRIB(in)
+	if err != nil {
+		return nil, err
+	}
+
+	pfx := bnet.NewPrefixFromProtoPrefix(in.Pfx)
+	var r *route.Route
+	switch in.MatchType {
+	case api.LookupRoutesRequest_Exact:
+		r = rib.Get(pfx)
+	case api.LookupRoutesRequest_LongestMatch:
+		// LPM returns all covering routes, the most specific one last
+		if res := rib.LPM(pfx); len(res) > 0 {
+			r = res[len(res)-1]
+		}
+	default:
+		return nil, fmt.Errorf("Unknown match type %d", in.MatchType)
+	}
+
+	resp := &api.LookupRoutesResponse{}
+	if r != nil {
+		resp.Routes = routesToProto([]*route.Route{r})
+	}
+
+	return resp, nil
+}
+
+type ribLookup interface {
+	Get(pfx *bnet.Prefix) *route.Route
+	LPM(pfx *bnet.Prefix) []*route.Route
+}
+
+func (s *BGPAPIServer) lookupRIB(in *api.LookupRoutesRequest) (ribLookup, error) {
+	peer := bnet.IPFromProtoIP(in.Peer)
+	afi, safi := uint16(in.Afi), uint8(in.Safi)
+
+	switch in.Rib {
+	case api.LookupRoutesRequest_LocRIB:
+		if r := s.srv.GetLocRIB(peer, afi, safi); r != nil {
+			return r, nil
+		}
+
+		return nil, fmt.Errorf("Unable to get LocRIB")
+	case api.LookupRoutesRequest_AdjRIBIn:
+		if r := s.srv.GetRIBIn(peer, afi, safi); r != nil {
+			return r, nil
+		}
+
+		return nil, fmt.Errorf("Unable to get AdjRIBIn")
+	case api.LookupRoutesRequest_AdjRIBOut:
+		if r := s.srv.GetRIBOut(peer, afi, safi); r != nil {
+			return r, nil
+		}
+
+		return nil, fmt.Errorf("Unable to get AdjRIBOut")
+	}
+
+	return nil, fmt.Errorf("Unknown RIB %d", in.Rib)
+}
+
 func routesToProto(dump []*route.Route) []*routeapi.Route {
 	routes := make([]*routeapi.Route, len(dump))
 	for i := range dump {
@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"log"
 	"net"
 	"testing"
 	"time"
@@ -16,6 +15,8 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
 	"github.com/bio-routing/bio-rd/routingtable/adjRIBOut"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
@@ -358,3 +359,204 @@ func TestDumpRIBInOut(t *testing.T) {
 		assert.Equal(t, expected, results, test.name)
 	}
 }
+
+func TestLookupRoutes(t *testing.T) {
+	peerAddr := bnet.IPv4FromOctets(10, 0, 0, 0)
+	fsm := &FSM{
+		ipv4Unicast: &fsmAddressFamily{
+			adjRIBIn:  adjRIBIn.New(filter.NewAcceptAllFilterChain(), routingtable.NewContributingASNs(), 0, 0, true),
+			adjRIBOut: adjRIBOut.New(nil, &routingtable.Neighbor{Type: route.BGPPathType, RouteServerClient: true, Address: bnet.IPv4(123).Ptr()}, filter.NewAcceptAllFilterChain(), false),
+		},
+	}
+	p := &peer{
+		addr: peerAddr.Ptr(),
+		fsms: []*FSM{fsm},
+		ipv4: &peerAddressFamily{
+			rib: locRIB.New("inet.0"),
+		},
+	}
+	apisrv := &BGPAPIServer{
+		srv: &bgpServer{
+			peers: &peerManager{
+				peers: map[bnet.IP]*peer{
+					peerAddr: p,
+				},
+			},
+		},
+	}
+
+	for _, pfx := range []*bnet.Prefix{
+		bnet.NewPfx(bnet.IPv4FromOctets(20, 0, 0, 0), 8).Ptr(),
+		bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 0, 0), 16).Ptr(),
+	} {
+		path := &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					OriginatorID: 1,
+					NextHop:      bnet.IPv4FromOctets(100, 100, 100, 100).Ptr(),
+					Source:       bnet.IPv4FromOctets(100, 100, 100, 100).Ptr(),
+				},
+			},
+		}
+
+		fsm.ipv4Unicast.adjRIBIn.AddPath(pfx, path)
+		fsm.ipv4Unicast.adjRIBOut.AddPath(pfx, path)
+		p.ipv4.rib.AddPath(pfx, path)
+	}
+
+	tests := []struct {
+		name     string
+		req      *api.LookupRoutesRequest
+		expected []*bnet.Prefix
+		wantFail bool
+	}{
+		{
+			name: "Exact match in Adj-RIB-In",
+			req: &api.LookupRoutesRequest{
+				Peer: peerAddr.ToProto(),
+				Afi:  packet.IPv4AFI,
+				Safi: packet.UnicastSAFI,
+				Rib:  api.LookupRoutesRequest_AdjRIBIn,
+				Pfx:  bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 0, 0), 16).ToProto(),
+			},
+			expected: []*bnet.Prefix{
+				bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 0, 0), 16).Ptr(),
+			},
+		},
+		{
+			name: "No exact match in Adj-RIB-Out",
+			req: &api.LookupRoutesRequest{
+				Peer: peerAddr.ToProto(),
+				Afi:  packet.IPv4AFI,
+				Safi: packet.UnicastSAFI,
+				Rib:  api.LookupRoutesRequest_AdjRIBOut,
+				Pfx:  bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 2, 0), 24).ToProto(),
+			},
+		},
+		{
+			name: "Longest match in Adj-RIB-Out",
+			req: &api.LookupRoutesRequest{
+				Peer:      peerAddr.ToProto(),
+				Afi:       packet.IPv4AFI,
+				Safi:      packet.UnicastSAFI,
+				Rib:       api.LookupRoutesRequest_AdjRIBOut,
+				Pfx:       bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 2, 0), 24).ToProto(),
+				MatchType: api.LookupRoutesRequest_LongestMatch,
+			},
+			expected: []*bnet.Prefix{
+				bnet.NewPfx(bnet.IPv4FromOctets(20, 1, 0, 0), 16).Ptr(),
+			},
+		},
+		{
+			name: "Longest match in Loc-RIB",
+			req: &api.LookupRoutesRequest{
+				Peer:      peerAddr.ToProto(),
+				Afi:       packet.IPv4AFI,
+				Safi:      packet.UnicastSAFI,
+				Rib:       api.LookupRoutesRequest_LocRIB,
+				Pfx:       bnet.NewPfx(bnet.IPv4FromOctets(20, 2, 0, 0), 16).ToProto(),
+				MatchType: api.LookupRoutesRequest_LongestMatch,
+			},
+			expected: []*bnet.Prefix{
+				bnet.NewPfx(bnet.IPv4FromOctets(20, 0, 0, 0), 8).Ptr(),
+			},
+		},
+		{
+			name: "Unknown peer",
+			req: &api.LookupRoutesRequest{
+				Peer: bnet.IPv4FromOctets(10, 0, 0, 1).ToProto(),
+				Afi:  packet.IPv4AFI,
+				Safi: packet.UnicastSAFI,
+				Rib:  api.LookupRoutesRequest_LocRIB,
+				Pfx:  bnet.NewPfx(bnet.IPv4FromOctets(20, 0, 0, 0), 8).ToProto(),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Address family not configured",
+			req: &api.LookupRoutesRequest{
+				Peer: peerAddr.ToProto(),
+				Afi:  packet.IPv6AFI,
+				Safi: packet.UnicastSAFI,
+				Rib:  api.LookupRoutesRequest_AdjRIBIn,
+				Pfx:  bnet.NewPfx(bnet.IPv6(0x20010db800000000, 0), 32).ToProto(),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Prefix missing",
+			req: &api.LookupRoutesRequest{
+				Peer: peerAddr.ToProto(),
+				Afi:  packet.IPv4AFI,
+				Safi: packet.UnicastSAFI,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := apisrv.LookupRoutes(context.Background(), test.req)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		var pfxs []*bnet.Prefix
+		for _, r := range res.Routes {
+			pfxs = append(pfxs, bnet.NewPrefixFromProtoPrefix(r.Pfx))
+		}
+
+		assert.Equal(t, test.expected, pfxs, "Test %q", test.name)
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	v, _ := vrf.New("list-sessions", 65000)
+	s := newBGPServer(0, nil)
+	for _, addr := range []bnet.IP{bnet.IPv4FromOctets(10, 0, 0, 1), bnet.IPv4FromOctets(10, 0, 0, 2)} {
+		p := &peer{
+			addr:     addr.Ptr(),
+			peerASN:  65001,
+			localASN: 65000,
+			vrf:      v,
+		}
+		s.peers.add(p)
+	}
+
+	p := s.peers.get(bnet.IPv4FromOctets(10, 0, 0, 1).Ptr())
+	p.notifications.sent(&packet.BGPNotification{
+		ErrorCode:    packet.UpdateMessageError,
+		ErrorSubcode: packet.OptionalAttrError,
+		Data:         []byte{0x80, packet.MultiProtocolReachNLRICode, 0},
+	})
+	p.notifications.received(&packet.BGPNotification{
+		ErrorCode:    packet.Cease,
+		ErrorSubcode: packet.AdminShut,
+		Data:         append([]byte{11}, "maintenance"...),
+	})
+
+	apisrv := &BGPAPIServer{srv: s}
+	resp, err := apisrv.ListSessions(context.Background(), &api.ListSessionsRequest{
+		Filter: &api.SessionFilter{
+			NeighborIp: bnet.IPv4FromOctets(10, 0, 0, 1).ToProto(),
+		},
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, resp.Sessions, 1) {
+		return
+	}
+
+	sess := resp.Sessions[0]
+	assert.Equal(t, uint32(65001), sess.PeerAsn)
+	assert.Equal(t, "UPDATE Message Error/Optional Attribute Error: attribute 14", sess.LastNotificationSent.Description)
+	assert.Equal(t, uint32(packet.Cease), sess.LastNotificationReceived.ErrorCode)
+	assert.Equal(t, "Cease/Administrative Shutdown: \"maintenance\"", sess.LastNotificationReceived.Description)
+
+	resp, err = apisrv.ListSessions(context.Background(), &api.ListSessionsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Sessions, 2)
+}
@@ -0,0 +1,451 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultBMPReconnectInterval is the interval between connection attempts to a BMP station if not configured otherwise
+	DefaultBMPReconnectInterval = 30 * time.Second
+
+	bmpDialTimeout  = 10 * time.Second
+	bmpWriteTimeout = 30 * time.Second
+
+	// bmpQueueLen is the number of messages buffered for a station. A station not keeping up is disconnected and
+	// gets a full dump after reconnecting.
+	bmpQueueLen = 65536
+)
+
+// BMPStationConfig is the config of a BMP monitoring station the server reports its peers to (RFC7854)
+type BMPStationConfig struct {
+	// Address is the host:port of the station
+	Address string
+
+	// ReconnectInterval is the interval between connection attempts, DefaultBMPReconnectInterval if 0
+	ReconnectInterval time.Duration
+
+	// StatsInterval is the interval statistics reports are sent in, 0 disables statistics reports
+	StatsInterval time.Duration
+
+	// AdjRIBOut enables the monitoring of the post-policy adj-RIB-out of all peers (RFC8671)
+	AdjRIBOut bool
+
+	// LocRIB enables the monitoring of the Loc-RIBs (RFC9069)
+	LocRIB bool
+}
+
+// bmpRIB is a RIB route monitoring messages are generated from
+type bmpRIB uint8
+
+const (
+	bmpAdjRIBIn bmpRIB = iota
+	bmpAdjRIBOut
+	bmpLocRIB
+)
+
+// bmpExporter streams the state of all peers to the configured BMP stations
+type bmpExporter struct {
+	server *bgpServer
+
+	mu       sync.RWMutex
+	stations map[string]*bmpStation
+
+	locRIBs   map[*locRIB.LocRIB]*bmpLocRIBInstance
+	locRIBsMu sync.Mutex
+}
+
+func newBMPExporter(server *bgpServer) *bmpExporter {
+	return &bmpExporter{
+		server:   server,
+		stations: make(map[string]*bmpStation),
+		locRIBs:  make(map[*locRIB.LocRIB]*bmpLocRIBInstance),
+	}
+}
+
+// AddBMPStation adds a BMP station or updates the config of the station with the same address
+func (b *bgpServer) AddBMPStation(c BMPStationConfig) error {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("Invalid BMP station address %q: %v", c.Address, err)
+	}
+
+	b.bmp.add(c)
+	return nil
+}
+
+// RemoveBMPStation disconnects and removes the BMP station with the given address
+func (b *bgpServer) RemoveBMPStation(addr string) {
+	b.bmp.remove(addr)
+}
+
+// GetBMPStations gets the configs of all BMP stations
+func (b *bgpServer) GetBMPStations() []BMPStationConfig {
+	return b.bmp.list()
+}
+
+func (e *bmpExporter) add(c BMPStationConfig) {
+	e.mu.Lock()
+	old := e.stations[c.Address]
+	if old != nil && old.config == c {
+		e.mu.Unlock()
+		return
+	}
+
+	s := newBMPStation(e, c)
+	e.stations[c.Address] = s
+	e.mu.Unlock()
+
+	// Stations are stopped without holding the lock as saying goodbye to a slow station takes a while
+	if old != nil {
+		old.stop()
+	}
+
+	s.start()
+}
+
+func (e *bmpExporter) remove(addr string) {
+	e.mu.Lock()
+	s, found := e.stations[addr]
+	delete(e.stations, addr)
+	e.mu.Unlock()
+
+	if found {
+		s.stop()
+	}
+}
+
+// stop disconnects all stations
+func (e *bmpExporter) stop() {
+	for _, c := range e.list() {
+		e.remove(c.Address)
+	}
+
+	e.stopLocRIBMonitoring()
+}
+
+func (e *bmpExporter) list() []BMPStationConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	res := make([]BMPStationConfig, 0, len(e.stations))
+	for _, s := range e.stations {
+		res = append(res, s.config)
+	}
+
+	return res
+}
+
+// active checks if any station monitoring rib is connected, so messages nobody receives are not even generated
+func (e *bmpExporter) active(rib bmpRIB) bool {
+	if e == nil {
+		return false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, s := range e.stations {
+		if s.monitors(rib) && s.connected() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publish queues msg for all connected stations monitoring rib
+func (e *bmpExporter) publish(rib bmpRIB, msg []byte) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, s := range e.stations {
+		if s.monitors(rib) {
+			s.enqueue(msg)
+		}
+	}
+}
+
+// peerUp reports a session of fsm being established
+func (e *bmpExporter) peerUp(fsm *FSM) {
+	if !e.active(bmpAdjRIBIn) {
+		return
+	}
+
+	e.publish(bmpAdjRIBIn, bmpPeerUp(fsm))
+}
+
+// peerDown reports a session of fsm going down. deconfigured is set if the peer has been removed.
+func (e *bmpExporter) peerDown(fsm *FSM, deconfigured bool) {
+	if !e.active(bmpAdjRIBIn) {
+		return
+	}
+
+	e.publish(bmpAdjRIBIn, bmpPeerDown(fsm, deconfigured))
+}
+
+// routeMonitoring reports an UPDATE message as received from the peer (pre-policy)
+func (e *bmpExporter) routeMonitoring(fsm *FSM, update []byte) {
+	if !e.active(bmpAdjRIBIn) {
+		return
+	}
+
+	e.publish(bmpAdjRIBIn, bmpRouteMonitoring(fsm, fsm.prePolicyFlags(), update, time.Now()))
+}
+
+// dump gets the messages bringing a newly connected station with config c up to date
+func (e *bmpExporter) dump(c BMPStationConfig) [][]byte {
+	res := make([][]byte, 0)
+	for _, fsm := range e.server.establishedFSMs() {
+		res = append(res, bmpPeerUp(fsm))
+		for _, f := range fsm.initializedAddressFamilies() {
+			res = append(res, f.bmpDump(c)...)
+		}
+	}
+
+	if c.LocRIB {
+		res = append(res, e.dumpLocRIBs()...)
+	}
+
+	return res
+}
+
+// stats gets the statistics reports of all peers
+func (e *bmpExporter) stats() [][]byte {
+	res := make([][]byte, 0)
+	for _, fsm := range e.server.establishedFSMs() {
+		res = append(res, bmpStatsReport(fsm))
+	}
+
+	return res
+}
+
+// bmpStation is the connection to a BMP station
+type bmpStation struct {
+	exporter *bmpExporter
+	config   BMPStationConfig
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+
+	// queue is nil while the station is not connected
+	queue   chan []byte
+	queueMu sync.Mutex
+}
+
+func newBMPStation(e *bmpExporter, c BMPStationConfig) *bmpStation {
+	return &bmpStation{
+		exporter: e,
+		config:   c,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func (s *bmpStation) start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// stop sends a termination message to the station and disconnects
+func (s *bmpStation) stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *bmpStation) reconnectInterval() time.Duration {
+	if s.config.ReconnectInterval == 0 {
+		return DefaultBMPReconnectInterval
+	}
+
+	return s.config.ReconnectInterval
+}
+
+func (s *bmpStation) run() {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+
+	for {
+		err := s.connectAndServe()
+		if err != nil {
+			log.WithError(err).WithField("station", s.config.Address).Warning("BMP station disconnected")
+		}
+
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(s.reconnectInterval()):
+		}
+	}
+}
+
+func (s *bmpStation) connectAndServe() error {
+	c, err := net.DialTimeout("tcp", s.config.Address, bmpDialTimeout)
+	if err != nil {
+		return fmt.Errorf("Unable to connect: %v", err)
+	}
+	defer c.Close()
+
+	log.WithFields(logrus.Fields{
+		"station": s.config.Address,
+	}).Info("Connected to BMP station")
+
+	err = s.write(c, bmpInitiation(s.exporter.server.routerID))
+	if err != nil {
+		return err
+	}
+
+	// Changes occurring during the dump are queued and sent afterwards
+	queue := s.connect()
+	defer s.disconnect()
+
+	for _, msg := range s.exporter.dump(s.config) {
+		err = s.write(c, msg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var statsCh <-chan time.Time
+	if s.config.StatsInterval > 0 {
+		t := time.NewTicker(s.config.StatsInterval)
+		defer t.Stop()
+		statsCh = t.C
+	}
+
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return fmt.Errorf("Station is not keeping up, %d messages queued", bmpQueueLen)
+			}
+
+			err = s.write(c, msg)
+		case <-statsCh:
+			for _, msg := range s.exporter.stats() {
+				err = s.write(c, msg)
+				if err != nil {
+					break
+				}
+			}
+		case <-s.stopCh:
+			return s.terminate(c, queue)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// terminate sends the messages still queued followed by a termination message
+func (s *bmpStation) terminate(c net.Conn, queue chan []byte) error {
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return nil
+			}
+
+			err := s.write(c, msg)
+			if err != nil {
+				return err
+			}
+		default:
+			return s.write(c, bmpTermination())
+		}
+	}
+}
+
+func (s *bmpStation) write(c net.Conn, msg []byte) error {
+	c.SetWriteDeadline(time.Now().Add(bmpWriteTimeout))
+	_, err := c.Write(msg)
+	if err != nil {
+		return fmt.Errorf("Write failed: %v", err)
+	}
+
+	return nil
+}
+
+func (s *bmpStation) connect() chan []byte {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	s.queue = make(chan []byte, bmpQueueLen)
+	return s.queue
+}
+
+func (s *bmpStation) disconnect() {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	s.queue = nil
+}
+
+// monitors checks if messages about rib are sent to the station. Peer state and the adj-RIB-in are always monitored.
+func (s *bmpStation) monitors(rib bmpRIB) bool {
+	switch rib {
+	case bmpAdjRIBOut:
+		return s.config.AdjRIBOut
+	case bmpLocRIB:
+		return s.config.LocRIB
+	}
+
+	return true
+}
+
+func (s *bmpStation) connected() bool {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	return s.queue != nil
+}
+
+// enqueue queues msg if the station is connected. The queue is closed if it is full.
+func (s *bmpStation) enqueue(msg []byte) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if s.queue == nil {
+		return
+	}
+
+	select {
+	case s.queue <- msg:
+	default:
+		close(s.queue)
+		s.queue = nil
+	}
+}
+
+func serializeBMPMsg(msg interface{ Serialize(buf *bytes.Buffer) }) []byte {
+	buf := &bytes.Buffer{}
+	msg.Serialize(buf)
+	return buf.Bytes()
+}
+
+func bmpInitiation(routerID uint32) []byte {
+	return serializeBMPMsg(&bmppkt.InitiationMessage{
+		TLVs: []*bmppkt.InformationTLV{
+			bmppkt.NewInformationTLV(bmppkt.InformationTypeSysDescr, []byte("bio-rd")),
+			bmppkt.NewInformationTLV(bmppkt.InformationTypeSysName, []byte(routerIDString(routerID))),
+		},
+	})
+}
+
+func bmpTermination() []byte {
+	return serializeBMPMsg(&bmppkt.TerminationMessage{
+		TLVs: []*bmppkt.InformationTLV{
+			bmppkt.NewInformationTLV(bmppkt.InformationTypeString, []byte("Station removed")),
+		},
+	})
+}
+
+func routerIDString(id uint32) string {
+	return net.IPv4(byte(id>>24), byte(id>>16), byte(id>>8), byte(id)).String()
+}
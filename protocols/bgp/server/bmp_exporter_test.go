@@ -0,0 +1,127 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddBMPStation(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		wantFail bool
+	}{
+		{
+			name:    "Valid address",
+			address: "192.0.2.1:11019",
+		},
+		{
+			name:     "Port missing",
+			address:  "192.0.2.1",
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		b := newBGPServer(0, nil)
+		err := b.AddBMPStation(BMPStationConfig{
+			Address:           test.address,
+			ReconnectInterval: time.Hour,
+		})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			assert.Empty(t, b.GetBMPStations(), "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, 1, len(b.GetBMPStations()), "Test %q", test.name)
+
+		b.RemoveBMPStation(test.address)
+		assert.Empty(t, b.GetBMPStations(), "Test %q", test.name)
+	}
+}
+
+func TestBMPExporter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer l.Close()
+
+	b := newBGPServer(0x0a000001, nil)
+	err = b.AddBMPStation(BMPStationConfig{
+		Address: l.Addr().String(),
+	})
+	if err != nil {
+		t.Fatalf("Unable to add BMP station: %v", err)
+	}
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Unable to accept: %v", err)
+	}
+	defer c.Close()
+
+	msg := recvTestBMPMsg(t, c)
+	assert.Equal(t, &bmppkt.InitiationMessage{
+		CommonHeader: &bmppkt.CommonHeader{
+			Version:   bmppkt.BMPVersion,
+			MsgLength: 28,
+			MsgType:   bmppkt.InitiationMessageType,
+		},
+		TLVs: []*bmppkt.InformationTLV{
+			bmppkt.NewInformationTLV(bmppkt.InformationTypeSysDescr, []byte("bio-rd")),
+			bmppkt.NewInformationTLV(bmppkt.InformationTypeSysName, []byte("10.0.0.1")),
+		},
+	}, msg)
+
+	for !b.bmp.active(bmpAdjRIBIn) {
+		time.Sleep(time.Millisecond)
+	}
+
+	fsm := newFSM(&peer{
+		addr:    bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		peerASN: 65001,
+	})
+	fsm.neighborID = 0x0a000002
+	fsm.supports4OctetASN = true
+
+	update := packet.SerializeKeepaliveMsg()
+	b.bmp.routeMonitoring(fsm, update)
+
+	msg = recvTestBMPMsg(t, c)
+	rm, ok := msg.(*bmppkt.RouteMonitoringMsg)
+	if !ok {
+		t.Fatalf("Unexpected message %T", msg)
+	}
+	assert.Equal(t, uint32(65001), rm.PerPeerHeader.PeerAS)
+	assert.Equal(t, uint32(0x0a000002), rm.PerPeerHeader.PeerBGPID)
+	assert.Equal(t, uint8(0), rm.PerPeerHeader.PeerFlags)
+	assert.Equal(t, update, rm.BGPUpdate)
+
+	b.RemoveBMPStation(l.Addr().String())
+	msg = recvTestBMPMsg(t, c)
+	assert.IsType(t, &bmppkt.TerminationMessage{}, msg)
+}
+
+func recvTestBMPMsg(t *testing.T, c net.Conn) bmppkt.Msg {
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	raw, err := recvBMPMsg(c)
+	if err != nil {
+		t.Fatalf("Unable to receive BMP message: %v", err)
+	}
+
+	msg, err := bmppkt.Decode(raw)
+	if err != nil {
+		t.Fatalf("Unable to decode BMP message: %v", err)
+	}
+
+	return msg
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+)
+
+// bmpLocRIBInstance is a Loc-RIB reported to BMP stations as Loc-RIB instance peer (RFC9069)
+type bmpLocRIBInstance struct {
+	rib      *locRIB.LocRIB
+	afi      uint16
+	name     string
+	rd       uint64
+	localASN uint32
+	routerID uint32
+	monitor  *bmpRouteMonitor
+}
+
+// monitorLocRIB starts the monitoring of the Loc-RIB of an address family. Loc-RIBs stay monitored until the server is stopped.
+func (e *bmpExporter) monitorLocRIB(f *fsmAddressFamily) {
+	// Labeled unicast paths share the Loc-RIB with unicast paths
+	if f.safi != packet.UnicastSAFI || f.rib == nil {
+		return
+	}
+
+	e.locRIBsMu.Lock()
+	if _, found := e.locRIBs[f.rib]; found {
+		e.locRIBsMu.Unlock()
+		return
+	}
+
+	l := newBMPLocRIBInstance(e, f)
+	e.locRIBs[f.rib] = l
+	e.locRIBsMu.Unlock()
+
+	if e.active(bmpLocRIB) {
+		e.publish(bmpLocRIB, l.peerUp())
+	}
+
+	f.rib.RegisterWithOptions(l.monitor, routingtable.ClientOptions{BestOnly: true})
+}
+
+func newBMPLocRIBInstance(e *bmpExporter, f *fsmAddressFamily) *bmpLocRIBInstance {
+	l := &bmpLocRIBInstance{
+		rib:      f.rib,
+		afi:      f.afi,
+		name:     f.rib.Name(),
+		localASN: f.fsm.peer.localASN,
+		routerID: e.server.routerID,
+	}
+
+	if v := f.fsm.peer.vrf; v != nil {
+		l.name = fmt.Sprintf("%s/%s", v.Name(), l.name)
+		l.rd = v.RD()
+	}
+
+	enc := &bmpUpdateEncoder{
+		afi:           f.afi,
+		safi:          packet.UnicastSAFI,
+		multiProtocol: f.afi != packet.IPv4AFI,
+		iBGP:          true,
+	}
+	l.monitor = newBMPRouteMonitor(bmpLocRIB, enc, l.header, e)
+
+	return l
+}
+
+func (e *bmpExporter) stopLocRIBMonitoring() {
+	e.locRIBsMu.Lock()
+	defer e.locRIBsMu.Unlock()
+
+	for rib, l := range e.locRIBs {
+		l.monitor.close()
+		rib.Unregister(l.monitor)
+		delete(e.locRIBs, rib)
+	}
+}
+
+// dumpLocRIBs gets peer up and route monitoring messages of all monitored Loc-RIBs
+func (e *bmpExporter) dumpLocRIBs() [][]byte {
+	e.locRIBsMu.Lock()
+	locRIBs := make([]*bmpLocRIBInstance, 0, len(e.locRIBs))
+	for _, l := range e.locRIBs {
+		locRIBs = append(locRIBs, l)
+	}
+	e.locRIBsMu.Unlock()
+
+	res := make([][]byte, 0)
+	for _, l := range locRIBs {
+		res = append(res, l.dump()...)
+	}
+
+	return res
+}
+
+// header gets the per peer header of the Loc-RIB instance peer (RFC9069 4.1)
+func (l *bmpLocRIBInstance) header(t time.Time) *bmppkt.PerPeerHeader {
+	h := &bmppkt.PerPeerHeader{
+		PeerType:          bmppkt.LocRIBInstancePeer,
+		PeerDistinguisher: l.rd,
+		PeerAS:            l.localASN,
+		PeerBGPID:         l.routerID,
+	}
+
+	h.SetTimestamp(t)
+	return h
+}
+
+// peerUp announces the Loc-RIB instance peer. The OPEN messages are fabricated from the local settings (RFC9069 5.1).
+func (l *bmpLocRIBInstance) peerUp() []byte {
+	asn := uint16(l.localASN)
+	if l.localASN > uint32(^uint16(0)) {
+		asn = packet.ASTransASN
+	}
+
+	open := packet.SerializeOpenMsg(&packet.BGPOpen{
+		Version:       BGPVersion,
+		ASN:           asn,
+		BGPIdentifier: l.routerID,
+		OptParams: []packet.OptParam{
+			{
+				Type: packet.CapabilitiesParamType,
+				Value: packet.Capabilities{
+					multiProtocolCapability(l.afi, packet.UnicastSAFI),
+					{
+						Code: packet.ASN4CapabilityCode,
+						Value: packet.ASN4Capability{
+							ASN4: l.localASN,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	info := &bytes.Buffer{}
+	bmppkt.NewInformationTLV(bmppkt.InformationTypeVRFTableName, []byte(l.name)).Serialize(info)
+
+	return serializeBMPMsg(&bmppkt.PeerUpNotification{
+		PerPeerHeader:   l.header(time.Now()),
+		SentOpenMsg:     open,
+		ReceivedOpenMsg: open,
+		Information:     info.Bytes(),
+	})
+}
+
+// dump gets the peer up message followed by the best paths and End-of-RIB
+func (l *bmpLocRIBInstance) dump() [][]byte {
+	m := newBMPRouteMonitor(bmpLocRIB, l.monitor.enc, l.header, nil)
+	for _, r := range l.rib.Dump() {
+		p := r.BestPath()
+		if p != nil {
+			m.AddPath(r.Prefix(), p)
+		}
+	}
+	m.endOfRIB()
+
+	return append([][]byte{l.peerUp()}, m.msgs...)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMPStationMonitors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   BMPStationConfig
+		rib      bmpRIB
+		expected bool
+	}{
+		{
+			name:     "adj-RIB-in is always monitored",
+			rib:      bmpAdjRIBIn,
+			expected: true,
+		},
+		{
+			name: "adj-RIB-out not enabled",
+			rib:  bmpAdjRIBOut,
+		},
+		{
+			name: "adj-RIB-out enabled",
+			config: BMPStationConfig{
+				AdjRIBOut: true,
+			},
+			rib:      bmpAdjRIBOut,
+			expected: true,
+		},
+		{
+			name: "Loc-RIB not enabled",
+			config: BMPStationConfig{
+				AdjRIBOut: true,
+			},
+			rib: bmpLocRIB,
+		},
+		{
+			name: "Loc-RIB enabled",
+			config: BMPStationConfig{
+				LocRIB: true,
+			},
+			rib:      bmpLocRIB,
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		s := newBMPStation(nil, test.config)
+		assert.Equal(t, test.expected, s.monitors(test.rib), "Test %q", test.name)
+	}
+}
+
+func TestBMPLocRIBInstanceDump(t *testing.T) {
+	rib := locRIB.New("inet.0")
+	rib.AddPath(bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24).Ptr(), &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				NextHop:   bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				LocalPref: 100,
+			},
+			ASPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65001},
+				},
+			},
+		},
+	})
+
+	l := &bmpLocRIBInstance{
+		rib:      rib,
+		afi:      packet.IPv4AFI,
+		name:     "inet.0",
+		localASN: 65000,
+		routerID: 0x0a000001,
+	}
+	l.monitor = newBMPRouteMonitor(bmpLocRIB, &bmpUpdateEncoder{
+		afi:  packet.IPv4AFI,
+		safi: packet.UnicastSAFI,
+		iBGP: true,
+	}, l.header, nil)
+
+	msgs := l.dump()
+	if !assert.Equal(t, 3, len(msgs)) {
+		return
+	}
+
+	msg, err := bmppkt.Decode(msgs[0])
+	if err != nil {
+		t.Fatalf("Unable to decode peer up: %v", err)
+	}
+
+	up := msg.(*bmppkt.PeerUpNotification)
+	assert.Equal(t, uint8(bmppkt.LocRIBInstancePeer), up.PerPeerHeader.PeerType)
+	assert.Equal(t, uint32(65000), up.PerPeerHeader.PeerAS)
+	assert.Equal(t, uint32(0x0a000001), up.PerPeerHeader.PeerBGPID)
+	assert.Equal(t, []byte{0, 3, 0, 6, 'i', 'n', 'e', 't', '.', '0'}, up.Information)
+
+	for _, raw := range msgs[1:] {
+		msg, err := bmppkt.Decode(raw)
+		if err != nil {
+			t.Fatalf("Unable to decode route monitoring message: %v", err)
+		}
+
+		rm := msg.(*bmppkt.RouteMonitoringMsg)
+		assert.Equal(t, uint8(bmppkt.LocRIBInstancePeer), rm.PerPeerHeader.PeerType)
+		assert.Equal(t, uint8(0), rm.PerPeerHeader.PeerFlags)
+	}
+}
@@ -0,0 +1,413 @@
+package server
+
+import (
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/pkg/errors"
+)
+
+// bmp gets the BMP exporter of the server, nil if there is none
+func (fsm *FSM) bmp() *bmpExporter {
+	if fsm.peer.server == nil {
+		return nil
+	}
+
+	return fsm.peer.server.bmp
+}
+
+// prePolicyFlags gets the per peer header flags of UPDATE messages as received from the peer
+func (fsm *FSM) prePolicyFlags() uint8 {
+	if fsm.supports4OctetASN {
+		return 0
+	}
+
+	return bmppkt.PeerFlagA
+}
+
+// bgpMessage trims a received message buffer to the length given in the BGP header
+func bgpMessage(data []byte) []byte {
+	if len(data) < packet.MinLen {
+		return data
+	}
+
+	l := int(data[16])*256 + int(data[17])
+	if l > len(data) {
+		return data
+	}
+
+	return data[:l]
+}
+
+func bmpPerPeerHeader(fsm *FSM, flags uint8, t time.Time) *bmppkt.PerPeerHeader {
+	h := &bmppkt.PerPeerHeader{
+		PeerType:  bmppkt.GlobalInstancePeer,
+		PeerFlags: flags,
+		PeerAS:    fsm.peer.peerASN,
+		PeerBGPID: fsm.neighborID,
+	}
+
+	if v := fsm.peer.vrf; v != nil && v.RD() != 0 {
+		h.PeerType = bmppkt.RDInstancePeer
+		h.PeerDistinguisher = v.RD()
+	}
+
+	if !fsm.peer.addr.IsIPv4() {
+		h.PeerFlags |= bmppkt.PeerFlagV
+	}
+
+	h.SetPeerAddress(fsm.peer.addr.Bytes())
+	h.SetTimestamp(t)
+	return h
+}
+
+// bmpAddrPort gets the address and port of a connection endpoint
+func bmpAddrPort(a net.Addr) (addr [16]byte, port uint16) {
+	if a == nil {
+		return
+	}
+
+	host, p, err := net.SplitHostPort(a.String())
+	if err != nil {
+		return
+	}
+
+	ip, err := bnet.IPFromString(host)
+	if err != nil {
+		return
+	}
+
+	b := ip.Bytes()
+	copy(addr[16-len(b):], b)
+
+	n, _ := strconv.ParseUint(p, 10, 16)
+	return addr, uint16(n)
+}
+
+func bmpPeerUp(fsm *FSM) []byte {
+	msg := &bmppkt.PeerUpNotification{
+		PerPeerHeader:   bmpPerPeerHeader(fsm, 0, fsm.establishedTime),
+		SentOpenMsg:     fsm.sentOpen,
+		ReceivedOpenMsg: fsm.receivedOpen,
+	}
+
+	if fsm.con != nil {
+		msg.LocalAddress, msg.LocalPort = bmpAddrPort(fsm.con.LocalAddr())
+		_, msg.RemotePort = bmpAddrPort(fsm.con.RemoteAddr())
+	}
+
+	return serializeBMPMsg(msg)
+}
+
+// bmpPeerDown reports the reason a session went down: The NOTIFICATION received or sent if any
+func bmpPeerDown(fsm *FSM, deconfigured bool) []byte {
+	msg := &bmppkt.PeerDownNotification{
+		PerPeerHeader: bmpPerPeerHeader(fsm, 0, time.Now()),
+	}
+
+	switch {
+	case deconfigured:
+		msg.Reason = bmppkt.PeerDownDeconfigured
+	case fsm.notificationReceived != nil:
+		msg.Reason = bmppkt.PeerDownRemoteNotification
+		msg.Data = fsm.notificationReceived
+	case fsm.notificationSent != nil:
+		msg.Reason = bmppkt.PeerDownLocalNotification
+		msg.Data = fsm.notificationSent
+	default:
+		msg.Reason = bmppkt.PeerDownRemoteNoData
+	}
+
+	return serializeBMPMsg(msg)
+}
+
+func bmpRouteMonitoring(fsm *FSM, flags uint8, update []byte, t time.Time) []byte {
+	return serializeBMPMsg(&bmppkt.RouteMonitoringMsg{
+		PerPeerHeader: bmpPerPeerHeader(fsm, flags, t),
+		BGPUpdate:     update,
+	})
+}
+
+func bmpStatsReport(fsm *FSM) []byte {
+	adjRIBIn := uint64(0)
+	adjRIBOut := uint64(0)
+	perAFI := make([]*bmppkt.InformationTLV, 0)
+	for _, f := range fsm.initializedAddressFamilies() {
+		in := uint64(f.adjRIBIn.RouteCount())
+		out := uint64(f.adjRIBOut.RouteCount())
+		adjRIBIn += in
+		adjRIBOut += out
+		perAFI = append(perAFI,
+			bmppkt.NewAFIGaugeStat(bmppkt.StatAdjRIBInRoutesPerAFI, f.afi, f.safi, in),
+			bmppkt.NewAFIGaugeStat(bmppkt.StatAdjRIBOutPostPolicyRoutesPerAFI, f.afi, f.safi, out))
+	}
+
+	stats := []*bmppkt.InformationTLV{
+		bmppkt.NewGaugeStat(bmppkt.StatAdjRIBInRoutes, adjRIBIn),
+		bmppkt.NewGaugeStat(bmppkt.StatAdjRIBOutPostPolicyRoutes, adjRIBOut),
+	}
+
+	return serializeBMPMsg(&bmppkt.StatsReport{
+		PerPeerHeader: bmpPerPeerHeader(fsm, 0, time.Now()),
+		Stats:         append(stats, perAFI...),
+	})
+}
+
+// bmpUpdateEncoder serializes paths into UPDATE messages of an address family
+type bmpUpdateEncoder struct {
+	afi           uint16
+	safi          uint8
+	multiProtocol bool
+	addPath       bool
+	iBGP          bool
+}
+
+// bmpEncoder gets an encoder for paths of the address family as exchanged with the peer
+func (f *fsmAddressFamily) bmpEncoder(addPath bool) *bmpUpdateEncoder {
+	return &bmpUpdateEncoder{
+		afi:           f.afi,
+		safi:          f.safi,
+		multiProtocol: f.afi != packet.IPv4AFI || f.safi != packet.UnicastSAFI || f.multiProtocol,
+		addPath:       addPath,
+		iBGP:          !f.fsm.peer.isEBGP(),
+	}
+}
+
+// update serializes an UPDATE message announcing or withdrawing a path
+func (e *bmpUpdateEncoder) update(pfx *bnet.Prefix, p *route.Path, withdraw bool) ([]byte, error) {
+	opt := &packet.EncodeOptions{
+		Use32BitASN: true,
+		UseAddPath:  e.addPath,
+	}
+
+	nlri := &packet.NLRI{
+		PathIdentifier: p.BGPPath.PathIdentifier,
+		Prefix:         pfx,
+		Labels:         p.BGPPath.Labels,
+	}
+
+	if withdraw {
+		u := &packet.BGPUpdate{
+			WithdrawnRoutes: nlri,
+		}
+
+		if e.multiProtocol {
+			u = &packet.BGPUpdate{
+				PathAttributes: &packet.PathAttribute{
+					TypeCode: packet.MultiProtocolUnreachNLRICode,
+					Value: packet.MultiProtocolUnreachNLRI{
+						AFI:  e.afi,
+						SAFI: e.safi,
+						NLRI: nlri,
+					},
+				},
+			}
+		}
+
+		return u.SerializeUpdate(opt)
+	}
+
+	pa, err := packet.PathAttributes(p, e.iBGP, p.BGPPath.ClusterList != nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to get path attributes")
+	}
+
+	u := &packet.BGPUpdate{
+		PathAttributes: pa,
+		NLRI:           nlri,
+	}
+
+	if e.multiProtocol {
+		attrs, nextHop := copyAttributesWithoutNextHop(pa)
+		u = &packet.BGPUpdate{
+			PathAttributes: &packet.PathAttribute{
+				TypeCode: packet.MultiProtocolReachNLRICode,
+				Value: packet.MultiProtocolReachNLRI{
+					AFI:     e.afi,
+					SAFI:    e.safi,
+					NextHop: nextHop,
+					NLRI:    nlri,
+				},
+				Next: attrs,
+			},
+		}
+	}
+
+	return u.SerializeUpdate(opt)
+}
+
+func (e *bmpUpdateEncoder) endOfRIB() ([]byte, error) {
+	return packet.EndOfRIB(e.afi, e.safi).SerializeUpdate(&packet.EncodeOptions{Use32BitASN: true})
+}
+
+// bmpHeader gets the per peer header of route monitoring messages for paths of the peer
+func (f *fsmAddressFamily) bmpHeader(flags uint8) func(t time.Time) *bmppkt.PerPeerHeader {
+	return func(t time.Time) *bmppkt.PerPeerHeader {
+		return bmpPerPeerHeader(f.fsm, flags, t)
+	}
+}
+
+// bmpDump gets the route monitoring messages of all paths of the RIBs monitored by a station, each followed by End-of-RIB
+func (f *fsmAddressFamily) bmpDump(c BMPStationConfig) [][]byte {
+	pre := newBMPRouteMonitor(bmpAdjRIBIn, f.bmpEncoder(f.addPathRX), f.bmpHeader(f.fsm.prePolicyFlags()), nil)
+	for _, r := range f.adjRIBIn.Dump() {
+		for _, p := range r.Paths() {
+			pre.AddPath(r.Prefix(), p)
+		}
+	}
+	pre.endOfRIB()
+
+	post := newBMPRouteMonitor(bmpAdjRIBIn, f.bmpEncoder(f.addPathRX), f.bmpHeader(bmppkt.PeerFlagL), nil)
+	if a, ok := f.adjRIBIn.(*adjRIBIn.AdjRIBIn); ok {
+		a.UpdateNewClient(post)
+	}
+	post.endOfRIB()
+
+	res := append(pre.msgs, post.msgs...)
+	if !c.AdjRIBOut {
+		return res
+	}
+
+	out := f.bmpAdjRIBOutMonitor(nil)
+	for _, r := range f.adjRIBOut.Dump() {
+		for _, p := range r.Paths() {
+			out.AddPath(r.Prefix(), p)
+		}
+	}
+	out.endOfRIB()
+
+	return append(res, out.msgs...)
+}
+
+func (f *fsmAddressFamily) bmpAdjRIBOutMonitor(e *bmpExporter) *bmpRouteMonitor {
+	return newBMPRouteMonitor(bmpAdjRIBOut, f.bmpEncoder(!f.addPathTX.BestOnly), f.bmpHeader(bmppkt.PeerFlagO|bmppkt.PeerFlagL), e)
+}
+
+// bmpMonitorInit registers route monitors for post-policy paths with the adj-RIBs if the server has a BMP exporter
+func (f *fsmAddressFamily) bmpMonitorInit() {
+	e := f.fsm.bmp()
+	if e == nil {
+		return
+	}
+
+	f.bmpAdjRIBIn = newBMPRouteMonitor(bmpAdjRIBIn, f.bmpEncoder(f.addPathRX), f.bmpHeader(bmppkt.PeerFlagL), e)
+	f.adjRIBIn.Register(f.bmpAdjRIBIn)
+
+	f.bmpAdjRIBOut = f.bmpAdjRIBOutMonitor(e)
+	f.adjRIBOut.Register(f.bmpAdjRIBOut)
+
+	e.monitorLocRIB(f)
+}
+
+func (f *fsmAddressFamily) bmpMonitorDispose() {
+	if f.bmpAdjRIBIn == nil {
+		return
+	}
+
+	// Paths are not withdrawn one by one as the peer down message implies that
+	f.bmpAdjRIBIn.close()
+	f.adjRIBIn.Unregister(f.bmpAdjRIBIn)
+	f.bmpAdjRIBIn = nil
+
+	f.bmpAdjRIBOut.close()
+	f.adjRIBOut.Unregister(f.bmpAdjRIBOut)
+	f.bmpAdjRIBOut = nil
+}
+
+// bmpRouteMonitor converts paths of a RIB into route monitoring messages. These are published to the exporter if set
+// or collected otherwise.
+type bmpRouteMonitor struct {
+	rib      bmpRIB
+	enc      *bmpUpdateEncoder
+	header   func(t time.Time) *bmppkt.PerPeerHeader
+	exporter *bmpExporter
+	msgs     [][]byte
+	closed   uint32
+}
+
+func newBMPRouteMonitor(rib bmpRIB, enc *bmpUpdateEncoder, header func(t time.Time) *bmppkt.PerPeerHeader, e *bmpExporter) *bmpRouteMonitor {
+	return &bmpRouteMonitor{
+		rib:      rib,
+		enc:      enc,
+		header:   header,
+		exporter: e,
+	}
+}
+
+func (m *bmpRouteMonitor) close() {
+	atomic.StoreUint32(&m.closed, 1)
+}
+
+func (m *bmpRouteMonitor) report(pfx *bnet.Prefix, p *route.Path, withdraw bool) {
+	if atomic.LoadUint32(&m.closed) != 0 || p.BGPPath == nil {
+		return
+	}
+
+	if m.exporter != nil && !m.exporter.active(m.rib) {
+		return
+	}
+
+	u, err := m.enc.update(pfx, p, withdraw)
+	if err != nil {
+		log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to serialize BMP route monitoring message")
+		return
+	}
+
+	m.out(u)
+}
+
+func (m *bmpRouteMonitor) out(update []byte) {
+	msg := serializeBMPMsg(&bmppkt.RouteMonitoringMsg{
+		PerPeerHeader: m.header(time.Now()),
+		BGPUpdate:     update,
+	})
+
+	if m.exporter != nil {
+		m.exporter.publish(m.rib, msg)
+		return
+	}
+
+	m.msgs = append(m.msgs, msg)
+}
+
+func (m *bmpRouteMonitor) endOfRIB() {
+	u, err := m.enc.endOfRIB()
+	if err != nil {
+		log.WithError(err).Error("Unable to serialize End-of-RIB")
+		return
+	}
+
+	m.out(u)
+}
+
+// AddPath reports an announcement
+func (m *bmpRouteMonitor) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	m.report(pfx, p, false)
+	return nil
+}
+
+// AddPathInitialDump reports an announcement
+func (m *bmpRouteMonitor) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return m.AddPath(pfx, p)
+}
+
+// RemovePath reports a withdrawal
+func (m *bmpRouteMonitor) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	m.report(pfx, p, true)
+	return true
+}
+
+// ReplacePath reports the announcement of the new path
+func (m *bmpRouteMonitor) ReplacePath(pfx *bnet.Prefix, old *route.Path, new *route.Path) {
+	m.report(pfx, new, false)
+}
+
+// RefreshRoute is here to fulfill an interface
+func (m *bmpRouteMonitor) RefreshRoute(*bnet.Prefix, []*route.Path) {}
@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
@@ -17,7 +17,7 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/routingtable/vrf"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 type RouterInterface interface {
@@ -42,7 +42,7 @@ type Router struct {
 	reconnectTimer   *time.Timer
 	vrfRegistry      *vrf.VRFRegistry
 	neighborManager  *neighborManager
-	logger           *log.Logger
+	logger           *logrus.Logger
 	runMu            sync.Mutex
 	stop             chan struct{}
 
@@ -82,7 +82,7 @@ func newRouter(addr net.IP, port uint16) *Router {
 		dialTimeout:      time.Second * 5,
 		vrfRegistry:      vrf.NewVRFRegistry(),
 		neighborManager:  newNeighborManager(),
-		logger:           log.New(),
+		logger:           logrus.New(),
 		stop:             make(chan struct{}),
 		ribClients:       make(map[afiClient]struct{}),
 	}
@@ -229,7 +229,11 @@ func (r *Router) processTerminationMsg(msg *bmppkt.TerminationMessage) {
 		case stringType:
 			logMsg += fmt.Sprintf("Message: %q", string(tlv.Information))
 		case reasonType:
-			reason := convert.Uint16b(tlv.Information[:1])
+			reason := uint16(unspecReason)
+			if len(tlv.Information) >= 2 {
+				reason = endian.Uint16(tlv.Information)
+			}
+
 			switch reason {
 			case adminDown:
 				logMsg += "Session administratively down"
@@ -252,7 +256,7 @@ func (r *Router) processTerminationMsg(msg *bmppkt.TerminationMessage) {
 }
 
 func (r *Router) processPeerDownNotification(msg *bmppkt.PeerDownNotification) {
-	r.logger.WithFields(log.Fields{
+	r.logger.WithFields(logrus.Fields{
 		"address":            r.address.String(),
 		"router":             r.name,
 		"peer_distinguisher": vrf.RouteDistinguisherHumanReadable(msg.PerPeerHeader.PeerDistinguisher),
@@ -268,7 +272,7 @@ func (r *Router) processPeerDownNotification(msg *bmppkt.PeerDownNotification) {
 
 func (r *Router) processPeerUpNotification(msg *bmppkt.PeerUpNotification) error {
 	atomic.AddUint64(&r.counters.peerUpNotificationMessages, 1)
-	r.logger.WithFields(log.Fields{
+	r.logger.WithFields(logrus.Fields{
 		"address":            r.address.String(),
 		"router":             r.name,
 		"peer_distinguisher": vrf.RouteDistinguisherHumanReadable(msg.PerPeerHeader.PeerDistinguisher),
@@ -11,10 +11,10 @@ import (
 	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
 	bmppkt "github.com/bio-routing/bio-rd/protocols/bmp/packet"
 	"github.com/bio-routing/bio-rd/routingtable"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -63,13 +63,13 @@ func (b *BMPServer) AddRouter(addr net.IP, port uint16) {
 		for {
 			select {
 			case <-r.stop:
-				log.WithFields(log.Fields{
+				log.WithFields(logrus.Fields{
 					"component": "bmp_server",
 					"address":   conString(r.address.String(), r.port),
 				}).Info("Stop event: Stopping reconnect routine")
 				return
 			case <-r.reconnectTimer.C:
-				log.WithFields(log.Fields{
+				log.WithFields(logrus.Fields{
 					"component": "bmp_server",
 					"address":   conString(r.address.String(), r.port),
 				}).Info("Reconnect timer expired: Establishing connection")
@@ -77,7 +77,7 @@ func (b *BMPServer) AddRouter(addr net.IP, port uint16) {
 
 			c, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", r.address.String(), r.port), r.dialTimeout)
 			if err != nil {
-				log.WithError(err).WithFields(log.Fields{
+				log.WithError(err).WithFields(logrus.Fields{
 					"component": "bmp_server",
 					"address":   conString(r.address.String(), r.port),
 				}).Info("Unable to connect to BMP router")
@@ -93,7 +93,7 @@ func (b *BMPServer) AddRouter(addr net.IP, port uint16) {
 			atomic.StoreUint32(&r.established, 1)
 			r.reconnectTime = r.reconnectTimeMin
 			r.reconnectTimer = time.NewTimer(time.Second * time.Duration(r.reconnectTime))
-			log.WithFields(log.Fields{
+			log.WithFields(logrus.Fields{
 				"component": "bmp_server",
 				"address":   conString(r.address.String(), r.port),
 			}).Info("Connected")
@@ -101,12 +101,12 @@ func (b *BMPServer) AddRouter(addr net.IP, port uint16) {
 			err = r.serve(c)
 			atomic.StoreUint32(&r.established, 0)
 			if err != nil {
-				r.logger.WithFields(log.Fields{
+				r.logger.WithFields(logrus.Fields{
 					"component": "bmp_server",
 					"address":   conString(r.address.String(), r.port),
 				}).WithError(err).Error("r.serve() failed")
 			} else {
-				r.logger.WithFields(log.Fields{
+				r.logger.WithFields(logrus.Fields{
 					"component": "bmp_server",
 					"address":   conString(r.address.String(), r.port),
 				}).Info("r.Serve returned without error. Stopping reconnect routine")
@@ -158,7 +158,7 @@ func recvBMPMsg(c net.Conn) (msg []byte, err error) {
 		return nil, errors.Wrap(err, "Read failed")
 	}
 
-	l := convert.Uint32b(buffer[1:5])
+	l := endian.Uint32(buffer[1:5])
 	if l > defaultBufferLen {
 		tmp := buffer
 		buffer = make([]byte, l)
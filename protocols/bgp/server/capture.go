@@ -0,0 +1,140 @@
+package server
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/pcap"
+	"github.com/pkg/errors"
+)
+
+// captureRegistry holds all active packet captures of a BGP server
+type captureRegistry struct {
+	captures map[uint64]*capture
+	nextID   uint64
+	mu       sync.RWMutex
+}
+
+// capture writes all BGP messages exchanged with peer (all peers if nil) to w
+type capture struct {
+	peer *bnet.IP
+	w    *pcap.Writer
+}
+
+func newCaptureRegistry() *captureRegistry {
+	return &captureRegistry{
+		captures: make(map[uint64]*capture),
+	}
+}
+
+func (r *captureRegistry) add(peer *bnet.IP, w io.Writer) (uint64, error) {
+	pw, err := pcap.NewWriter(w, pcap.LinkTypeRaw)
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to create pcap writer")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.captures[r.nextID] = &capture{
+		peer: peer,
+		w:    pw,
+	}
+
+	return r.nextID, nil
+}
+
+func (r *captureRegistry) remove(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.captures, id)
+}
+
+func (r *captureRegistry) active() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.captures) > 0
+}
+
+func (r *captureRegistry) record(peer *bnet.IP, flow *pcap.TCPFlow, outbound bool, payload []byte) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pkt []byte
+	ts := time.Now()
+	for _, c := range r.captures {
+		if c.peer != nil && !c.peer.Equal(peer) {
+			continue
+		}
+
+		if pkt == nil {
+			pkt = flow.Packet(outbound, payload)
+		}
+
+		err := c.w.WritePacket(ts, pkt)
+		if err != nil {
+			log.Warningf("Unable to write captured packet: %v", err)
+		}
+	}
+}
+
+// captureConn hands copies of all data sent and received on a connection to the capture registry
+type captureConn struct {
+	net.Conn
+	peer     *bnet.IP
+	flow     *pcap.TCPFlow
+	registry *captureRegistry
+}
+
+func newCaptureConn(c net.Conn, peer *bnet.IP, r *captureRegistry) *captureConn {
+	return &captureConn{
+		Conn:     c,
+		peer:     peer,
+		flow:     pcap.NewTCPFlow(tcpAddr(c.LocalAddr()), tcpAddr(c.RemoteAddr())),
+		registry: r,
+	}
+}
+
+func tcpAddr(a net.Addr) *net.TCPAddr {
+	if t, ok := a.(*net.TCPAddr); ok && t != nil {
+		return t
+	}
+
+	return &net.TCPAddr{
+		IP: net.IPv4zero,
+	}
+}
+
+func (c *captureConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 && c.registry.active() {
+		c.registry.record(c.peer, c.flow, false, b[:n])
+	}
+
+	return n, err
+}
+
+func (c *captureConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 && c.registry.active() {
+		c.registry.record(c.peer, c.flow, true, b[:n])
+	}
+
+	return n, err
+}
+
+// StartCapture starts capturing all BGP messages exchanged with peer (all peers if nil) to w in pcap format
+func (b *bgpServer) StartCapture(peer *bnet.IP, w io.Writer) (uint64, error) {
+	return b.captures.add(peer, w)
+}
+
+// StopCapture stops a packet capture
+func (b *bgpServer) StopCapture(id uint64) {
+	b.captures.remove(id)
+}
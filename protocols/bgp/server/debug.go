@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	debugMessagesPerSecond = 10
+)
+
+// packetDebugger logs decoded messages received from a peer. At most debugMessagesPerSecond messages are logged per second.
+type packetDebugger struct {
+	enabled    bool
+	tokens     int
+	lastRefill time.Time
+	suppressed uint64
+	mu         sync.Mutex
+}
+
+func (d *packetDebugger) setEnabled(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.enabled = enabled
+	d.tokens = debugMessagesPerSecond
+	d.lastRefill = time.Now()
+	d.suppressed = 0
+}
+
+func (d *packetDebugger) isEnabled() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.enabled
+}
+
+// allow reports if a message may be logged and how many messages have been suppressed since the last logged one
+func (d *packetDebugger) allow(now time.Time) (bool, uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.enabled {
+		return false, 0
+	}
+
+	refill := int(now.Sub(d.lastRefill) / (time.Second / debugMessagesPerSecond))
+	if refill > 0 {
+		d.tokens += refill
+		if d.tokens > debugMessagesPerSecond {
+			d.tokens = debugMessagesPerSecond
+		}
+		d.lastRefill = now
+	}
+
+	if d.tokens == 0 {
+		d.suppressed++
+		return false, 0
+	}
+
+	d.tokens--
+	suppressed := d.suppressed
+	d.suppressed = 0
+	return true, suppressed
+}
+
+func (d *packetDebugger) log(peer *bnet.IP, msg *packet.BGPMessage) {
+	ok, suppressed := d.allow(time.Now())
+	if !ok {
+		return
+	}
+
+	if suppressed > 0 {
+		log.WithField("peer", peer.String()).Infof("Debug: %d messages suppressed by rate limit", suppressed)
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer": peer.String(),
+	}).Infof("Debug: received %s", msg.Dump())
+}
+
+// SetPeerDebug enables or disables logging of all decoded messages received from a peer
+func (b *bgpServer) SetPeerDebug(addr *bnet.IP, enabled bool) error {
+	p := b.peers.get(addr)
+	if p == nil {
+		return fmt.Errorf("peer %s not found", addr.String())
+	}
+
+	p.debug.setEnabled(enabled)
+	return nil
+}
+
+func (fsm *FSM) debugMessage(msg *packet.BGPMessage) {
+	if !fsm.peer.debug.isEnabled() {
+		return
+	}
+
+	fsm.peer.debug.log(fsm.peer.addr, msg)
+}
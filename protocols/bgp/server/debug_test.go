@@ -0,0 +1,36 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacketDebuggerAllow(t *testing.T) {
+	d := &packetDebugger{}
+	now := time.Now()
+
+	ok, _ := d.allow(now)
+	assert.False(t, ok, "disabled")
+
+	d.setEnabled(true)
+	d.lastRefill = now
+	for i := 0; i < debugMessagesPerSecond; i++ {
+		ok, _ := d.allow(now)
+		assert.True(t, ok, "burst")
+	}
+
+	ok, _ = d.allow(now)
+	assert.False(t, ok, "rate limited")
+	ok, _ = d.allow(now)
+	assert.False(t, ok, "rate limited")
+
+	ok, suppressed := d.allow(now.Add(time.Second / debugMessagesPerSecond))
+	assert.True(t, ok, "refilled")
+	assert.Equal(t, uint64(2), suppressed)
+
+	d.setEnabled(false)
+	ok, _ = d.allow(now.Add(time.Minute))
+	assert.False(t, ok, "disabled again")
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/sirupsen/logrus"
+)
+
+type addressFamilyKey struct {
+	afi  uint16
+	safi uint8
+}
+
+// dynamicCapability announces that address families can be added and removed with CAPABILITY messages
+func dynamicCapability() packet.Capability {
+	return packet.Capability{
+		Code: packet.DynamicCapabilityCode,
+		Value: packet.DynamicCapability{
+			Codes: []uint8{packet.MultiProtocolCapabilityCode},
+		},
+	}
+}
+
+// ReplaceAddressFamilies adds and removes the unicast and labeled unicast address families of a peer according to c.
+// Sessions which negotiated dynamic capability keep running, all other sessions are restarted.
+func (b *bgpServer) ReplaceAddressFamilies(c PeerConfig) error {
+	p := b.peers.get(c.PeerAddress)
+	if p == nil {
+		return fmt.Errorf("peer %s not found", c.PeerAddress.String())
+	}
+
+	resolved, _, err := b.resolvePeerGroup(c)
+	if err != nil {
+		return err
+	}
+
+	if !addressFamiliesChanged(p.config, &resolved) {
+		return nil
+	}
+
+	if p.config.NeedsRestart(&resolved) || !p.dynamicCapabilityNegotiated() {
+		p.stop()
+		b.unregisterPeer(p)
+		return b.AddPeer(c)
+	}
+
+	return p.replaceAddressFamilies(&resolved)
+}
+
+func addressFamiliesChanged(a, b *PeerConfig) bool {
+	return (a.IPv4 == nil) != (b.IPv4 == nil) ||
+		(a.IPv6 == nil) != (b.IPv6 == nil) ||
+		(a.IPv4LabeledUnicast == nil) != (b.IPv4LabeledUnicast == nil) ||
+		(a.IPv6LabeledUnicast == nil) != (b.IPv6LabeledUnicast == nil)
+}
+
+// dynamicCapabilityNegotiated checks if the peer has established sessions only, all of them supporting dynamic capability
+func (p *peer) dynamicCapabilityNegotiated() bool {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	if len(p.fsms) == 0 {
+		return false
+	}
+
+	for _, fsm := range p.fsms {
+		fsm.stateMu.RLock()
+		established := isEstablishedState(fsm.state)
+		fsm.stateMu.RUnlock()
+
+		if !established || !fsm.dynamicCapability {
+			return false
+		}
+	}
+
+	return true
+}
+
+// replaceAddressFamilies changes the address families of the peer and passes the change to its sessions
+func (p *peer) replaceAddressFamilies(c *PeerConfig) error {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	err := p.setAddressFamilies(c)
+	if err != nil {
+		return err
+	}
+
+	cfg := *p.config
+	cfg.IPv4 = c.IPv4
+	cfg.IPv6 = c.IPv6
+	cfg.IPv4LabeledUnicast = c.IPv4LabeledUnicast
+	cfg.IPv6LabeledUnicast = c.IPv6LabeledUnicast
+	p.config = &cfg
+
+	for _, fsm := range p.fsms {
+		select {
+		case fsm.addressFamiliesCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// syncAddressFamilies creates the address families added to the peer and removes the ones removed from it
+func (fsm *FSM) syncAddressFamilies() (added []*fsmAddressFamily, removed []*fsmAddressFamily) {
+	for _, x := range []struct {
+		f    **fsmAddressFamily
+		afi  uint16
+		safi uint8
+	}{
+		{f: &fsm.ipv4Unicast, afi: packet.IPv4AFI, safi: packet.UnicastSAFI},
+		{f: &fsm.ipv6Unicast, afi: packet.IPv6AFI, safi: packet.UnicastSAFI},
+		{f: &fsm.ipv4LabeledUnicast, afi: packet.IPv4AFI, safi: packet.LabeledUnicastSAFI},
+		{f: &fsm.ipv6LabeledUnicast, afi: packet.IPv6AFI, safi: packet.LabeledUnicastSAFI},
+	} {
+		pf := fsm.peer.addressFamily(x.afi, x.safi)
+		switch {
+		case pf != nil && *x.f == nil:
+			f := newFSMAddressFamily(x.afi, x.safi, pf, fsm)
+			f.multiProtocol = fsm.peerMultiProtocol(x.afi, x.safi)
+			*x.f = f
+			added = append(added, f)
+		case pf == nil && *x.f != nil:
+			removed = append(removed, *x.f)
+			*x.f = nil
+		}
+	}
+
+	return added, removed
+}
+
+// peerMultiProtocol checks if the peer advertised the multi protocol capability for an address family
+func (fsm *FSM) peerMultiProtocol(afi uint16, safi uint8) bool {
+	if afi == packet.IPv4AFI && safi == packet.UnicastSAFI && !fsm.peer.ipv4MultiProtocolAdvertised {
+		return false
+	}
+
+	_, ok := fsm.peerAddressFamilies[addressFamilyKey{afi: afi, safi: safi}]
+	return ok
+}
+
+// usable checks if routes of the address family can be exchanged: IPv4 unicast works without the multi protocol capability,
+// all other address families have to be supported by the peer
+func (f *fsmAddressFamily) usable() bool {
+	return f.multiProtocol || (f.afi == packet.IPv4AFI && f.safi == packet.UnicastSAFI)
+}
+
+// addressFamiliesChanged starts or stops address families changed on the peer and announces the changes in a CAPABILITY message
+func (s *establishedState) addressFamiliesChanged() (state, string) {
+	added, removed := s.fsm.syncAddressFamilies()
+	if len(added) == 0 && len(removed) == 0 {
+		return newEstablishedState(s.fsm), s.fsm.reason
+	}
+
+	msg := &packet.BGPCapability{}
+	for _, f := range removed {
+		msg.Changes = append(msg.Changes, packet.CapabilityChange{
+			Action:     packet.DynamicCapabilityRemove,
+			Capability: multiProtocolCapability(f.afi, f.safi),
+		})
+	}
+
+	for _, f := range added {
+		msg.Changes = append(msg.Changes, packet.CapabilityChange{
+			Action:     packet.DynamicCapabilityAdvertise,
+			Capability: multiProtocolCapability(f.afi, f.safi),
+		})
+	}
+
+	_, err := s.fsm.con.Write(packet.SerializeCapabilityMsg(msg))
+	if err != nil {
+		return s.tcpFailure(err)
+	}
+
+	for _, f := range removed {
+		f.dispose(false)
+	}
+
+	n, err := s.neighbor()
+	if err != nil {
+		return s.tcpFailure(err)
+	}
+
+	for _, f := range added {
+		if f.usable() {
+			f.init(n)
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":    s.fsm.peer.addr.String(),
+		"added":   len(added),
+		"removed": len(removed),
+	}).Info("Address families changed by dynamic capability")
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
+
+// capability handles a CAPABILITY message: Address families are started or stopped as the peer adds or removes them
+func (s *establishedState) capability(msg *packet.BGPCapability) (state, string) {
+	if !s.fsm.dynamicCapability {
+		s.fsm.sendNotification(packet.MessageHeaderError, packet.BadMessageType)
+		s.uninit(false)
+		stopTimer(s.fsm.connectRetryTimer)
+		s.fsm.con.Close()
+		s.fsm.connectRetryCounter++
+		return newIdleState(s.fsm), "Received CAPABILITY message without dynamic capability"
+	}
+
+	n, err := s.neighbor()
+	if err != nil {
+		return s.tcpFailure(err)
+	}
+
+	for _, c := range msg.Changes {
+		mpCap, ok := c.Capability.Value.(packet.MultiProtocolCapability)
+		if !ok {
+			continue
+		}
+
+		k := addressFamilyKey{afi: mpCap.AFI, safi: mpCap.SAFI}
+		if c.Action == packet.DynamicCapabilityAdvertise {
+			s.fsm.peerAddressFamilies[k] = struct{}{}
+		} else {
+			delete(s.fsm.peerAddressFamilies, k)
+		}
+
+		f := s.fsm.addressFamily(mpCap.AFI, mpCap.SAFI)
+		if f == nil {
+			continue
+		}
+
+		f.multiProtocol = s.fsm.peerMultiProtocol(mpCap.AFI, mpCap.SAFI)
+		if f.initialized && !f.usable() {
+			f.dispose(false)
+		}
+
+		if !f.initialized && f.usable() {
+			f.init(n)
+		}
+	}
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
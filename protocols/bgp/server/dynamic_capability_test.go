@@ -0,0 +1,82 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func ipv6CapabilityMsg(action uint8) *packet.BGPCapability {
+	return &packet.BGPCapability{
+		Changes: []packet.CapabilityChange{
+			{
+				Action:     action,
+				Capability: multiProtocolCapability(packet.IPv6AFI, packet.UnicastSAFI),
+			},
+		},
+	}
+}
+
+func TestDynamicCapability(t *testing.T) {
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config:   &PeerConfig{DynamicCapability: true},
+		ipv4: &peerAddressFamily{
+			rib:               locRIB.New("inet.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	p.fsms = append(p.fsms, s.fsm)
+	defer s.manualStop()
+	s.fsm.dynamicCapability = true
+
+	p.ipv6 = &peerAddressFamily{
+		rib:               locRIB.New("inet6.0"),
+		importFilterChain: filter.NewAcceptAllFilterChain(),
+		exportFilterChain: filter.NewAcceptAllFilterChain(),
+	}
+
+	next, _ := s.addressFamiliesChanged()
+	assert.True(t, isEstablishedState(next))
+	assert.NotNil(t, s.fsm.ipv6Unicast)
+	assert.False(t, s.fsm.ipv6Unicast.initialized, "IPv6 must not be started before the peer advertised it")
+	assert.True(t, s.fsm.ipv4Unicast.initialized, "IPv4 must keep running")
+
+	next, _ = s.capability(ipv6CapabilityMsg(packet.DynamicCapabilityAdvertise))
+	assert.True(t, isEstablishedState(next))
+	assert.True(t, s.fsm.ipv6Unicast.initialized)
+
+	s.capability(ipv6CapabilityMsg(packet.DynamicCapabilityRemove))
+	assert.False(t, s.fsm.ipv6Unicast.initialized)
+
+	p.ipv6 = nil
+	s.addressFamiliesChanged()
+	assert.Nil(t, s.fsm.ipv6Unicast)
+	assert.True(t, s.fsm.ipv4Unicast.initialized)
+}
+
+func TestCapabilityWithoutDynamicCapability(t *testing.T) {
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config:   &PeerConfig{},
+		ipv4: &peerAddressFamily{
+			rib:               locRIB.New("inet.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	next, _ := s.capability(ipv6CapabilityMsg(packet.DynamicCapabilityAdvertise))
+	_, idle := next.(*idleState)
+	assert.True(t, idle, "CAPABILITY messages must not be accepted without dynamic capability")
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"fmt"
+	"net/netip"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/sirupsen/logrus"
+)
+
+// ListenRange accepts BGP sessions from all addresses within Prefix (dynamic neighbors). Peers are instantiated from
+// Template when a connection comes in and are removed again as soon as their session goes down.
+type ListenRange struct {
+	Prefix *bnet.Prefix
+
+	// Template is the config of instantiated peers. PeerAddress is set to the remote address of the connection.
+	// Dynamic peers are always passive and don't support graceful restart or TCP MD5 authentication.
+	Template PeerConfig
+
+	// MaxPeers limits the number of peers instantiated for the range, 0 means unlimited
+	MaxPeers uint
+}
+
+type listenRange struct {
+	ListenRange
+	prefix netip.Prefix
+
+	// guarded by listenRanges.mu
+	peers uint
+}
+
+type listenRanges struct {
+	ranges []*listenRange
+	mu     sync.Mutex
+}
+
+func newListenRanges() *listenRanges {
+	return &listenRanges{}
+}
+
+// set adds a listen range or updates the range for the same prefix. Returns the range and its config before the update.
+func (l *listenRanges) set(lr ListenRange) (*listenRange, *ListenRange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pfx := lr.Prefix.ToNetIPPrefix().Masked()
+	for _, r := range l.ranges {
+		if r.prefix == pfx {
+			old := r.ListenRange
+			r.ListenRange = lr
+			return r, &old
+		}
+	}
+
+	r := &listenRange{
+		ListenRange: lr,
+		prefix:      pfx,
+	}
+	l.ranges = append(l.ranges, r)
+	return r, nil
+}
+
+func (l *listenRanges) remove(pfx *bnet.Prefix) *listenRange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := pfx.ToNetIPPrefix().Masked()
+	for i, r := range l.ranges {
+		if r.prefix == p {
+			l.ranges = append(l.ranges[:i], l.ranges[i+1:]...)
+			return r
+		}
+	}
+
+	return nil
+}
+
+func (l *listenRanges) list() []ListenRange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res := make([]ListenRange, 0, len(l.ranges))
+	for _, r := range l.ranges {
+		res = append(res, r.ListenRange)
+	}
+
+	return res
+}
+
+// acquire reserves a peer in the most specific range containing addr. Returns nil if addr is in no range.
+func (l *listenRanges) acquire(addr *bnet.IP) (*listenRange, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a := addr.ToNetIPAddr()
+	var match *listenRange
+	for _, r := range l.ranges {
+		if !r.prefix.Contains(a) {
+			continue
+		}
+
+		if match == nil || r.prefix.Bits() > match.prefix.Bits() {
+			match = r
+		}
+	}
+
+	if match == nil {
+		return nil, nil
+	}
+
+	if match.MaxPeers != 0 && match.peers >= match.MaxPeers {
+		return nil, fmt.Errorf("Limit of %d peers for listen range %s reached", match.MaxPeers, match.prefix.String())
+	}
+
+	match.peers++
+	return match, nil
+}
+
+func (l *listenRanges) release(r *listenRange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.peers--
+}
+
+// AddListenRange adds a listen range. Adding a range for a prefix already configured replaces its config.
+// Dynamic peers of the range are removed if the change requires new sessions, otherwise their filters are replaced.
+func (b *bgpServer) AddListenRange(lr ListenRange) error {
+	if lr.Prefix == nil {
+		return fmt.Errorf("Listen range without prefix")
+	}
+
+	if lr.Template.AuthenticationKey != "" {
+		return fmt.Errorf("TCP MD5 authentication is not supported for listen range %s", lr.Prefix.String())
+	}
+
+	r, old := b.listenRanges.set(lr)
+	if old == nil {
+		log.WithField("prefix", lr.Prefix.String()).Info("Added BGP listen range")
+		return nil
+	}
+
+	restart := old.Template.NeedsRestart(&lr.Template)
+	for _, p := range b.dynamicPeers(r) {
+		if restart {
+			p.stop()
+			b.unregisterPeer(p)
+			continue
+		}
+
+		for _, afc := range []*AddressFamilyConfig{lr.Template.IPv4, lr.Template.IPv6, lr.Template.IPv4LabeledUnicast, lr.Template.IPv6LabeledUnicast} {
+			if afc != nil {
+				p.replaceImportFilterChain(afc.ImportFilterChain)
+				p.replaceExportFilterChain(afc.ExportFilterChain)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// RemoveListenRange removes a listen range and all peers instantiated for it
+func (b *bgpServer) RemoveListenRange(pfx *bnet.Prefix) {
+	r := b.listenRanges.remove(pfx)
+	if r == nil {
+		return
+	}
+
+	for _, p := range b.dynamicPeers(r) {
+		p.stop()
+		b.unregisterPeer(p)
+	}
+
+	log.WithField("prefix", pfx.String()).Info("Removed BGP listen range")
+}
+
+// GetListenRanges gets all listen ranges
+func (b *bgpServer) GetListenRanges() []ListenRange {
+	return b.listenRanges.list()
+}
+
+// IsDynamicPeer checks if a peer has been instantiated for a listen range
+func (b *bgpServer) IsDynamicPeer(addr *bnet.IP) bool {
+	p := b.peers.get(addr)
+	return p != nil && p.listenRange != nil
+}
+
+func (b *bgpServer) dynamicPeers(r *listenRange) []*peer {
+	res := make([]*peer, 0)
+	for _, p := range b.peers.list() {
+		if p.listenRange == r {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// dynamicPeer instantiates a peer for an incoming connection from addr if addr is within a listen range
+func (b *bgpServer) dynamicPeer(addr *bnet.IP) *peer {
+	r, err := b.listenRanges.acquire(addr)
+	if err != nil {
+		log.WithError(err).WithField("source", addr.String()).Warning("Rejecting dynamic BGP peer")
+		return nil
+	}
+
+	if r == nil {
+		return nil
+	}
+
+	c, member, err := b.resolvePeerGroup(r.Template)
+	if err == nil {
+		c.PeerAddress = addr
+		c.Passive = true
+		c.ActiveOnly = false
+		c.ReconnectInterval = 0
+		c.GracefulRestart = nil
+	}
+
+	var p *peer
+	if err == nil {
+		p, err = newPeer(c, b)
+	}
+
+	if err != nil {
+		b.listenRanges.release(r)
+		log.WithError(err).WithField("source", addr.String()).Error("Unable to instantiate dynamic BGP peer")
+		return nil
+	}
+
+	p.routerID = c.RouterID
+	p.listenRange = r
+	p.memberConfig = member
+
+	b.peers.add(p)
+	if p.routeReflectorClient {
+		b.clusterIDs.Add(p.clusterID)
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer_address": addr.String(),
+		"listen_range": r.prefix.String(),
+		"peer_as":      c.PeerAS,
+	}).Info("Added dynamic BGP peer")
+
+	return p
+}
+
+// removeDynamicPeer removes a dynamic peer after the session of fsm went down and terminates fsm
+func (b *bgpServer) removeDynamicPeer(fsm *FSM) {
+	p := fsm.peer
+	b.unregisterPeer(p)
+
+	p.fsmsMu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		for _, f := range p.fsms {
+			if f != fsm {
+				f.eventCh <- ManualStop
+			}
+		}
+	}
+	p.fsmsMu.Unlock()
+
+	fsm.cease()
+	log.WithField("peer_address", p.addr.String()).Info("Removed dynamic BGP peer")
+}
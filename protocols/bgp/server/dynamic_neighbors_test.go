@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenRangesAcquire(t *testing.T) {
+	l := newListenRanges()
+	l.set(ListenRange{
+		Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+	})
+	l.set(ListenRange{
+		Prefix:   bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 0, 0), 16).Ptr(),
+		MaxPeers: 1,
+	})
+
+	tests := []struct {
+		name           string
+		addr           *bnet.IP
+		wantFail       bool
+		expectedPrefix string
+	}{
+		{
+			name:           "Most specific range",
+			addr:           bnet.IPv4FromOctets(10, 1, 0, 1).Ptr(),
+			expectedPrefix: "10.1.0.0/16",
+		},
+		{
+			name:     "Limit reached",
+			addr:     bnet.IPv4FromOctets(10, 1, 0, 2).Ptr(),
+			wantFail: true,
+		},
+		{
+			name:           "Less specific range",
+			addr:           bnet.IPv4FromOctets(10, 2, 0, 1).Ptr(),
+			expectedPrefix: "10.0.0.0/8",
+		},
+		{
+			name: "No range",
+			addr: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		},
+		{
+			name: "IPv6 address",
+			addr: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+		},
+	}
+
+	for _, test := range tests {
+		r, err := l.acquire(test.addr)
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		if test.expectedPrefix == "" {
+			assert.Nilf(t, r, "Test %q", test.name)
+			continue
+		}
+
+		assert.Equalf(t, test.expectedPrefix, r.prefix.String(), "Test %q", test.name)
+	}
+}
+
+func TestDynamicPeer(t *testing.T) {
+	b := newBGPServer(100, nil)
+	err := b.AddListenRange(ListenRange{
+		Prefix: bnet.NewPfx(bnet.IPv4FromOctets(127, 0, 0, 0), 8).Ptr(),
+		Template: PeerConfig{
+			LocalAS:  65000,
+			PeerAS:   65001,
+			HoldTime: time.Second * 90,
+		},
+	})
+	assert.NoError(t, err)
+
+	err = b.AddListenRange(ListenRange{
+		Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+		Template: PeerConfig{
+			AuthenticationKey: "secret",
+		},
+	})
+	assert.Error(t, err, "TCP MD5 authentication")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer l.Close()
+
+	b.acceptCh = make(chan net.Conn)
+	go b.incomingConnectionWorker()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Unable to connect: %v", err)
+	}
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Unable to accept: %v", err)
+	}
+	b.acceptCh <- c
+
+	addr := bnet.IPv4FromOctets(127, 0, 0, 1).Ptr()
+	assert.True(t, waitFor(func() bool { return b.IsDynamicPeer(addr) }), "Dynamic peer not instantiated")
+	assert.True(t, b.GetPeerConfig(addr).Passive)
+
+	client.Close()
+	assert.True(t, waitFor(func() bool { return b.peers.get(addr) == nil }), "Dynamic peer not removed")
+
+	b.listenRanges.mu.Lock()
+	assert.Equal(t, uint(0), b.listenRanges.ranges[0].peers)
+	b.listenRanges.mu.Unlock()
+}
+
+func waitFor(f func() bool) bool {
+	for i := 0; i < 200; i++ {
+		if f() {
+			return true
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
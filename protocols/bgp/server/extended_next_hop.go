@@ -0,0 +1,46 @@
+package server
+
+import (
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// extendedNextHopCapability announces that we accept IPv4 unicast routes with IPv6 next hops (RFC8950)
+func extendedNextHopCapability() packet.Capability {
+	return packet.Capability{
+		Code: packet.ExtendedNextHopCapabilityCode,
+		Value: packet.ExtendedNextHopCapability{
+			{
+				AFI:        packet.IPv4AFI,
+				SAFI:       packet.UnicastSAFI,
+				NextHopAFI: packet.IPv6AFI,
+			},
+		},
+	}
+}
+
+// ipv4MultiProtocol determines if IPv4 unicast is negotiated with the multi protocol capability. IPv6 next hops can
+// only be sent in MP_REACH_NLRI attributes, so this is implied by the extended next hop encoding.
+func (pc *PeerConfig) ipv4MultiProtocol() bool {
+	return pc.IPv4 != nil && (pc.AdvertiseIPv4MultiProtocol || pc.ExtendedNextHop)
+}
+
+func (s *openSentState) processExtendedNextHopCapability(cap packet.ExtendedNextHopCapability) {
+	s.fsm.extendedNextHop = s.fsm.peer.config != nil && s.fsm.peer.config.ExtendedNextHop &&
+		cap.Supports(packet.IPv4AFI, packet.UnicastSAFI, packet.IPv6AFI)
+}
+
+// nextHopEncodable checks if the next hop of p can be sent to the peer. IPv4 routes with IPv6 next hops require the
+// extended next hop encoding.
+func (u *UpdateSender) nextHopEncodable(p *route.Path) bool {
+	if u.addressFamily.afi != packet.IPv4AFI || u.addressFamily.safi != packet.UnicastSAFI {
+		return true
+	}
+
+	nextHop := p.NextHop()
+	if nextHop == nil || nextHop.IsIPv4() {
+		return true
+	}
+
+	return u.addressFamily.multiProtocol && u.fsm.extendedNextHop
+}
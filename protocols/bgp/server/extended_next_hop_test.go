@@ -0,0 +1,157 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	btesting "github.com/bio-routing/bio-rd/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedNextHop(t *testing.T) {
+	tests := []struct {
+		name             string
+		configured       bool
+		peerCap          packet.ExtendedNextHopCapability
+		nextHop          *bnet.IP
+		expectNegotiated bool
+		expectEncodable  bool
+	}{
+		{
+			name:            "IPv4 next hop without extended next hop",
+			nextHop:         bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+			expectEncodable: true,
+		},
+		{
+			name:    "IPv6 next hop without extended next hop",
+			nextHop: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+		},
+		{
+			name:       "IPv6 next hop not supported by the peer",
+			configured: true,
+			peerCap: packet.ExtendedNextHopCapability{
+				{
+					AFI:        packet.IPv4AFI,
+					SAFI:       packet.MPLSVPNSAFI,
+					NextHopAFI: packet.IPv6AFI,
+				},
+			},
+			nextHop: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+		},
+		{
+			name:    "IPv6 next hop not configured",
+			peerCap: extendedNextHopCapability().Value.(packet.ExtendedNextHopCapability),
+			nextHop: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+		},
+		{
+			name:             "IPv6 next hop with extended next hop",
+			configured:       true,
+			peerCap:          extendedNextHopCapability().Value.(packet.ExtendedNextHopCapability),
+			nextHop:          bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+			expectNegotiated: true,
+			expectEncodable:  true,
+		},
+	}
+
+	for _, test := range tests {
+		cfg := &PeerConfig{
+			IPv4:            &AddressFamilyConfig{},
+			ExtendedNextHop: test.configured,
+		}
+
+		fsm := newFSM(&peer{
+			config:                      cfg,
+			ipv4:                        &peerAddressFamily{},
+			ipv4MultiProtocolAdvertised: cfg.ipv4MultiProtocol(),
+		})
+		fsm.con = &btesting.MockConn{}
+
+		s := &openSentState{
+			fsm: fsm,
+		}
+		s.processMultiProtocolCapability(packet.MultiProtocolCapability{
+			AFI:  packet.IPv4AFI,
+			SAFI: packet.UnicastSAFI,
+		})
+		if test.peerCap != nil {
+			s.processExtendedNextHopCapability(test.peerCap)
+		}
+
+		assert.Equal(t, test.expectNegotiated, fsm.extendedNextHop, "Test %q", test.name)
+
+		u := &UpdateSender{
+			fsm:           fsm,
+			addressFamily: fsm.ipv4Unicast,
+		}
+		p := &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					NextHop: test.nextHop,
+				},
+			},
+		}
+		assert.Equal(t, test.expectEncodable, u.nextHopEncodable(p), "Test %q", test.name)
+	}
+}
+
+func TestNextHopNotEncodable(t *testing.T) {
+	fsm := newFSM(&peer{
+		addr: bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+	})
+	fsm.ipv4Unicast = newFSMAddressFamily(packet.IPv4AFI, packet.UnicastSAFI, &peerAddressFamily{
+		rib:               locRIB.New("inet.0"),
+		importFilterChain: filter.NewAcceptAllFilterChain(),
+		exportFilterChain: filter.NewAcceptAllFilterChain(),
+	}, fsm)
+	fsm.ipv4Unicast.addPathTX = routingtable.ClientOptions{BestOnly: true}
+	con := btesting.NewMockConn()
+	fsm.con = con
+
+	u := newUpdateSender(fsm.ipv4Unicast)
+	u.attrCache = newAttributeCache()
+
+	path := func(nextHop *bnet.IP) *route.Path {
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref: 100,
+					NextHop:   nextHop,
+					Source:    bnet.IPv4(0).Ptr(),
+				},
+				ASPath: &types.ASPath{},
+			},
+		}
+	}
+
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+
+	u.AddPath(pfx, path(bnet.IPv4FromOctets(192, 0, 2, 1).Ptr()))
+	u.Start(time.Millisecond)
+	time.Sleep(time.Millisecond * 50)
+	u.Destroy()
+	assert.NotEqual(t, 0, con.Buf.Len(), "Path with IPv4 next hop must be advertised")
+	con.Buf.Reset()
+
+	// The best path changes to one with an IPv6 next hop while extended next hop encoding is not negotiated
+	u.AddPath(pfx, path(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr()))
+	u.Start(time.Millisecond)
+	time.Sleep(time.Millisecond * 50)
+	u.Destroy()
+
+	expected := []byte{
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 25, 2,
+		0, 2, 8, 10, // Withdrawn routes
+		0, 0,
+	}
+	assert.Equal(t, expected, con.Buf.Bytes(), "Path advertised before must be withdrawn")
+}
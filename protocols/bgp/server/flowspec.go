@@ -0,0 +1,437 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/sirupsen/logrus"
+)
+
+// FlowSpecRule is a flow specification received from a peer (RFC8955, RFC8956). The traffic filtering actions are
+// encoded in the extended communities, the next hop is only set for redirects to IP.
+type FlowSpecRule struct {
+	Peer                *bnet.IP
+	NLRI                *packet.FlowSpecNLRI
+	NextHop             *bnet.IP
+	ExtendedCommunities types.ExtendedCommunities
+}
+
+// FlowSpecClient is notified about the FlowSpec rules of all peers
+type FlowSpecClient interface {
+	// UpdateFlowSpec is called with all rules ordered by precedence (RFC8955 5.1) whenever a rule changes
+	UpdateFlowSpec(rules []*FlowSpecRule)
+}
+
+// RegisterFlowSpecClient registers a client for FlowSpec rules. The client is updated with the current rules.
+func (b *bgpServer) RegisterFlowSpecClient(c FlowSpecClient) {
+	b.flowSpec.register(c)
+}
+
+// UnregisterFlowSpecClient unregisters a client for FlowSpec rules
+func (b *bgpServer) UnregisterFlowSpecClient(c FlowSpecClient) {
+	b.flowSpec.unregister(c)
+}
+
+// GetFlowSpecRules gets the FlowSpec rules of all peers ordered by precedence
+func (b *bgpServer) GetFlowSpecRules() []*FlowSpecRule {
+	b.flowSpec.mu.Lock()
+	defer b.flowSpec.mu.Unlock()
+
+	return b.flowSpec.rules()
+}
+
+// flowSpecTable holds the FlowSpec rules received from all peers
+type flowSpecTable struct {
+	mu      sync.Mutex
+	peers   map[bnet.IP]map[string]*FlowSpecRule
+	clients map[FlowSpecClient]struct{}
+}
+
+func newFlowSpecTable() *flowSpecTable {
+	return &flowSpecTable{
+		peers:   make(map[bnet.IP]map[string]*FlowSpecRule),
+		clients: make(map[FlowSpecClient]struct{}),
+	}
+}
+
+func (t *flowSpecTable) register(c FlowSpecClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clients[c] = struct{}{}
+	c.UpdateFlowSpec(t.rules())
+}
+
+func (t *flowSpecTable) unregister(c FlowSpecClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.clients, c)
+}
+
+// update adds and removes rules of a peer and notifies the clients if anything changed
+func (t *flowSpecTable) update(peer bnet.IP, add []*FlowSpecRule, remove []*packet.FlowSpecNLRI) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rules := t.peers[peer]
+	if rules == nil {
+		rules = make(map[string]*FlowSpecRule)
+	}
+
+	changed := false
+	for _, n := range remove {
+		key := n.Key()
+		if _, ok := rules[key]; ok {
+			delete(rules, key)
+			changed = true
+		}
+	}
+
+	for _, r := range add {
+		rules[r.NLRI.Key()] = r
+		changed = true
+	}
+
+	if len(rules) == 0 {
+		delete(t.peers, peer)
+	} else {
+		t.peers[peer] = rules
+	}
+
+	if changed {
+		t.notify()
+	}
+}
+
+// removePeer removes all rules of a peer
+func (t *flowSpecTable) removePeer(peer bnet.IP) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.peers[peer]; !ok {
+		return
+	}
+
+	delete(t.peers, peer)
+	t.notify()
+}
+
+func (t *flowSpecTable) notify() {
+	rules := t.rules()
+	for c := range t.clients {
+		c.UpdateFlowSpec(rules)
+	}
+}
+
+// rules gets the rules of all peers ordered by precedence. A rule received from multiple peers is taken from the peer
+// with the lowest address.
+func (t *flowSpecTable) rules() []*FlowSpecRule {
+	byKey := make(map[string]*FlowSpecRule)
+	for _, rules := range t.peers {
+		for key, r := range rules {
+			if x, ok := byKey[key]; ok && x.Peer.Compare(r.Peer) < 0 {
+				continue
+			}
+
+			byKey[key] = r
+		}
+	}
+
+	ret := make([]*FlowSpecRule, 0, len(byKey))
+	for _, r := range byKey {
+		ret = append(ret, r)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].NLRI.AFI != ret[j].NLRI.AFI {
+			return ret[i].NLRI.AFI < ret[j].NLRI.AFI
+		}
+
+		return ret[i].NLRI.Compare(ret[j].NLRI) < 0
+	})
+
+	return ret
+}
+
+// flowSpecAddressFamily receives FlowSpec rules from a peer. FlowSpec rules are not advertised to peers.
+type flowSpecAddressFamily struct {
+	fsm          *FSM
+	afis         []uint16
+	noValidation bool
+
+	// negotiated holds the AFIs the peer advertised the multi protocol capability for FlowSpec for
+	negotiated  map[uint16]struct{}
+	initialized bool
+
+	// received holds all rules received from the peer by NLRI key, including rejected ones, so they can be
+	// re-evaluated when the import filter chain changes
+	mu                sync.Mutex
+	importFilterChain filter.Chain
+	received          map[string]*flowSpecReceived
+}
+
+// flowSpecReceived is a rule as received from the peer, before validation and import filtering
+type flowSpecReceived struct {
+	nlri    *packet.FlowSpecNLRI
+	nextHop *bnet.IP
+	path    *route.Path
+}
+
+func newFlowSpecAddressFamily(fsm *FSM, afis []uint16) *flowSpecAddressFamily {
+	return &flowSpecAddressFamily{
+		fsm:               fsm,
+		afis:              afis,
+		noValidation:      fsm.peer.config.FlowSpecNoValidation,
+		negotiated:        make(map[uint16]struct{}),
+		importFilterChain: filterOrDefault(fsm.peer.flowSpecImportFilterChain),
+		received:          make(map[string]*flowSpecReceived),
+	}
+}
+
+func (f *flowSpecAddressFamily) negotiate(afi uint16) {
+	for _, x := range f.afis {
+		if x == afi {
+			f.negotiated[afi] = struct{}{}
+		}
+	}
+}
+
+func (f *flowSpecAddressFamily) init() {
+	f.initialized = true
+
+	opts := &packet.EncodeOptions{
+		Use32BitASN: f.fsm.supports4OctetASN,
+	}
+
+	for afi := range f.negotiated {
+		err := serializeAndSendUpdate(f.fsm.con, packet.EndOfRIB(afi, packet.FlowSpecSAFI), opts)
+		if err != nil {
+			log.WithField("peer", f.fsm.peer.addr.String()).WithError(err).Error("Unable to send FlowSpec End-of-RIB")
+			continue
+		}
+
+		atomic.AddUint64(&f.fsm.counters.updatesSent, 1)
+	}
+}
+
+func (f *flowSpecAddressFamily) dispose() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.initialized && f.fsm.peer.server != nil {
+		f.fsm.peer.server.flowSpec.removePeer(*f.fsm.peer.addr)
+	}
+
+	f.negotiated = make(map[uint16]struct{})
+	f.received = make(map[string]*flowSpecReceived)
+	f.initialized = false
+}
+
+// replaceImportFilterChain replaces the import filter chain and re-evaluates all rules received
+func (f *flowSpecAddressFamily) replaceImportFilterChain(c filter.Chain) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c.Equal(f.importFilterChain) {
+		return
+	}
+
+	f.importFilterChain = c
+	if !f.initialized || f.fsm.peer.server == nil {
+		return
+	}
+
+	add := make([]*FlowSpecRule, 0)
+	remove := make([]*packet.FlowSpecNLRI, 0)
+	for _, r := range f.received {
+		rule := f.accept(r)
+		if rule == nil {
+			remove = append(remove, r.nlri)
+			continue
+		}
+
+		add = append(add, rule)
+	}
+
+	f.fsm.peer.server.flowSpec.update(*f.fsm.peer.addr, add, remove)
+}
+
+// processUpdate processes the FlowSpec rules of an update. Rules failing validation or rejected by the import filter
+// chain are withdrawn in case an earlier version of the rule has been accepted.
+func (f *flowSpecAddressFamily) processUpdate(u *packet.BGPUpdate) {
+	path := f.fsm.newRoutePath()
+	processAttributes(u.PathAttributes, path)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	add := make([]*FlowSpecRule, 0)
+	remove := make([]*packet.FlowSpecNLRI, 0)
+	rejected := 0
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.MultiProtocolReachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolReachNLRI)
+			if mp.SAFI != packet.FlowSpecSAFI || !f.isNegotiated(mp.AFI) {
+				continue
+			}
+
+			for _, n := range mp.FlowSpec {
+				r := &flowSpecReceived{
+					nlri:    n,
+					nextHop: mp.NextHop,
+					path:    path,
+				}
+				f.received[n.Key()] = r
+
+				rule := f.accept(r)
+				if rule == nil {
+					rejected++
+					remove = append(remove, n)
+					continue
+				}
+
+				add = append(add, rule)
+			}
+		case packet.MultiProtocolUnreachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolUnreachNLRI)
+			if mp.SAFI != packet.FlowSpecSAFI || !f.isNegotiated(mp.AFI) {
+				continue
+			}
+
+			for _, n := range mp.FlowSpec {
+				delete(f.received, n.Key())
+				remove = append(remove, n)
+			}
+		}
+	}
+
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":      f.fsm.peer.addr.String(),
+		"added":     len(add),
+		"rejected":  rejected,
+		"withdrawn": len(remove) - rejected,
+	}).Debug("Received FlowSpec rules")
+
+	if f.fsm.peer.server != nil {
+		f.fsm.peer.server.flowSpec.update(*f.fsm.peer.addr, add, remove)
+	}
+}
+
+// accept validates a received rule and applies the import filter chain. It returns nil if the rule is rejected.
+// The traffic filtering actions of the rule are taken from the extended communities of the filtered path. mu must be held.
+func (f *flowSpecAddressFamily) accept(r *flowSpecReceived) *FlowSpecRule {
+	if !f.noValidation {
+		err := f.validate(r)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"peer": f.fsm.peer.addr.String(),
+				"rule": r.nlri.String(),
+			}).WithError(err).Debug("FlowSpec rule failed validation")
+			return nil
+		}
+	}
+
+	p, reject := f.importFilterChain.Process(flowSpecFilterPrefix(r.nlri), r.path.Copy())
+	if reject {
+		return nil
+	}
+
+	rule := &FlowSpecRule{
+		Peer:    f.fsm.peer.addr,
+		NLRI:    r.nlri,
+		NextHop: r.nextHop,
+	}
+
+	if p.BGPPath.ExtendedCommunities != nil {
+		rule.ExtendedCommunities = *p.BGPPath.ExtendedCommunities
+	}
+
+	return rule
+}
+
+// validate checks a rule against the unicast routes (RFC8955 6): The best path of the best matching unicast route of
+// the destination prefix must have been received from the peer. Rules received via eBGP must have an empty AS path or
+// one starting with the AS of the peer.
+func (f *flowSpecAddressFamily) validate(r *flowSpecReceived) error {
+	dst := r.nlri.Component(packet.FlowSpecDestinationPrefix)
+	if dst == nil {
+		return fmt.Errorf("No destination prefix")
+	}
+
+	if r.path.BGPPath.BGPPathA.EBGP && !flowSpecASPathValid(r.path.BGPPath.ASPath, f.fsm.peer.peerASN) {
+		return fmt.Errorf("AS path does not start with AS%d", f.fsm.peer.peerASN)
+	}
+
+	rib := f.unicastRIB(r.nlri.AFI)
+	if rib == nil {
+		return fmt.Errorf("No unicast RIB")
+	}
+
+	// LPM returns the matching routes from the least to the most specific one
+	routes := rib.LPM(dst.Prefix)
+	if len(routes) == 0 {
+		return fmt.Errorf("No unicast route to %s", dst.Prefix.String())
+	}
+
+	best := routes[len(routes)-1].BestPath()
+	if best == nil || best.BGPPath == nil || best.BGPPath.BGPPathA.Source == nil || best.BGPPath.BGPPathA.Source.Compare(f.fsm.peer.addr) != 0 {
+		return fmt.Errorf("Best path to %s not received from peer", routes[len(routes)-1].Prefix().String())
+	}
+
+	return nil
+}
+
+func (f *flowSpecAddressFamily) unicastRIB(afi uint16) *locRIB.LocRIB {
+	v := f.fsm.peer.vrf
+	if v == nil {
+		return nil
+	}
+
+	if afi == packet.IPv6AFI {
+		return v.IPv6UnicastRIB()
+	}
+
+	return v.IPv4UnicastRIB()
+}
+
+// flowSpecASPathValid checks if an AS path received via eBGP is empty or starts with the AS of the peer (RFC8955 6)
+func flowSpecASPathValid(p *types.ASPath, peerASN uint32) bool {
+	if p == nil || len(*p) == 0 {
+		return true
+	}
+
+	seg := (*p)[0]
+	return seg.Type == types.ASSequence && len(seg.ASNs) > 0 && seg.ASNs[0] == peerASN
+}
+
+// flowSpecFilterPrefix gets the prefix a rule is matched by in filters: its destination prefix or the default route
+func flowSpecFilterPrefix(n *packet.FlowSpecNLRI) *bnet.Prefix {
+	if dst := n.Component(packet.FlowSpecDestinationPrefix); dst != nil {
+		return dst.Prefix
+	}
+
+	if n.AFI == packet.IPv6AFI {
+		return bnet.NewPfx(bnet.IPv6(0, 0), 0).Ptr()
+	}
+
+	return bnet.NewPfx(bnet.IPv4(0), 0).Ptr()
+}
+
+func (f *flowSpecAddressFamily) isNegotiated(afi uint16) bool {
+	_, ok := f.negotiated[afi]
+	return ok
+}
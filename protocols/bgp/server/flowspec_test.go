@@ -0,0 +1,347 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/stretchr/testify/assert"
+)
+
+type flowSpecClientMock struct {
+	rules []*FlowSpecRule
+}
+
+func (c *flowSpecClientMock) UpdateFlowSpec(rules []*FlowSpecRule) {
+	c.rules = rules
+}
+
+func flowSpecTestNLRI(addr bnet.IP, pfxLen uint8) *packet.FlowSpecNLRI {
+	return &packet.FlowSpecNLRI{
+		AFI: packet.IPv4AFI,
+		Components: []*packet.FlowSpecComponent{
+			{
+				Type:   packet.FlowSpecDestinationPrefix,
+				Prefix: bnet.NewPfx(addr, pfxLen).Ptr(),
+			},
+		},
+	}
+}
+
+func flowSpecTestUpdate(reach bool, afi uint16, coms types.ExtendedCommunities, nlris ...*packet.FlowSpecNLRI) *packet.BGPUpdate {
+	if !reach {
+		return &packet.BGPUpdate{
+			PathAttributes: &packet.PathAttribute{
+				TypeCode: packet.MultiProtocolUnreachNLRICode,
+				Value: packet.MultiProtocolUnreachNLRI{
+					AFI:      afi,
+					SAFI:     packet.FlowSpecSAFI,
+					FlowSpec: nlris,
+				},
+			},
+		}
+	}
+
+	return &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:      afi,
+				SAFI:     packet.FlowSpecSAFI,
+				FlowSpec: nlris,
+			},
+			Next: &packet.PathAttribute{
+				TypeCode: packet.ExtendedCommunitiesAttr,
+				Value:    &coms,
+			},
+		},
+	}
+}
+
+// flowSpecTestASPath prepends an AS_PATH attribute to an update
+func flowSpecTestASPath(u *packet.BGPUpdate, asns ...uint32) *packet.BGPUpdate {
+	u.PathAttributes = &packet.PathAttribute{
+		TypeCode: packet.ASPathAttr,
+		Value: &types.ASPath{
+			{
+				Type: types.ASSequence,
+				ASNs: asns,
+			},
+		},
+		Next: u.PathAttributes,
+	}
+
+	return u
+}
+
+func flowSpecTestPath(source bnet.IP) *route.Path {
+	return &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				Source: source.Ptr(),
+			},
+		},
+	}
+}
+
+func TestFlowSpecAddressFamily(t *testing.T) {
+	discard := types.ExtendedCommunities{0x8006000000000000}
+	wide := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 0, 0), 8)
+	specific := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 1, 0), 24)
+	otherPeer := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 2, 0), 24)
+	noRoute := flowSpecTestNLRI(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	noDestination := &packet.FlowSpecNLRI{
+		AFI: packet.IPv4AFI,
+		Components: []*packet.FlowSpecComponent{
+			{
+				Type:   packet.FlowSpecSourcePrefix,
+				Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+			},
+		},
+	}
+
+	rejectSpecific := filter.Chain{
+		filter.NewFilter("REJECT_SPECIFIC", []*filter.Term{
+			filter.NewTerm("REJECT_SPECIFIC", []*filter.TermCondition{
+				filter.NewTermConditionWithRouteFilters(filter.NewRouteFilter(specific.Components[0].Prefix, filter.NewExactMatcher())),
+			}, []actions.Action{
+				&actions.RejectAction{},
+			}),
+			filter.NewTerm("ACCEPT", []*filter.TermCondition{}, []actions.Action{
+				&actions.AcceptAction{},
+			}),
+		}),
+	}
+
+	tests := []struct {
+		name         string
+		peerASN      uint32
+		noValidation bool
+		importFilter filter.Chain
+		updates      []*packet.BGPUpdate
+		dispose      bool
+		expected     []*packet.FlowSpecNLRI
+	}{
+		{
+			name: "Rules ordered by precedence",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, specific),
+			},
+			expected: []*packet.FlowSpecNLRI{specific, wide},
+		},
+		{
+			name: "Rule withdrawn",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, specific),
+				flowSpecTestUpdate(false, packet.IPv4AFI, nil, specific),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name: "AFI not negotiated",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv6AFI, discard, wide),
+			},
+			expected: []*packet.FlowSpecNLRI{},
+		},
+		{
+			name: "Session down",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, specific),
+			},
+			dispose:  true,
+			expected: []*packet.FlowSpecNLRI{},
+		},
+		{
+			name: "Best path received from other peer",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, otherPeer),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name: "No unicast route",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, noRoute),
+			},
+			expected: []*packet.FlowSpecNLRI{},
+		},
+		{
+			name: "No destination prefix",
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, noDestination),
+			},
+			expected: []*packet.FlowSpecNLRI{},
+		},
+		{
+			name:         "Validation disabled",
+			noValidation: true,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, otherPeer, noRoute, noDestination),
+			},
+			expected: []*packet.FlowSpecNLRI{otherPeer, noRoute, noDestination},
+		},
+		{
+			name:    "eBGP AS path starting with peer AS",
+			peerASN: 65001,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestASPath(flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide), 65001, 65002),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name:    "eBGP empty AS path",
+			peerASN: 65001,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name:    "eBGP AS path not starting with peer AS",
+			peerASN: 65001,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestASPath(flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide), 65002, 65001),
+			},
+			expected: []*packet.FlowSpecNLRI{},
+		},
+		{
+			name:         "Rejected by import filter",
+			importFilter: rejectSpecific,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, specific),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name:    "Accepted rule replaced by rejected one",
+			peerASN: 65001,
+			updates: []*packet.BGPUpdate{
+				flowSpecTestASPath(flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide, specific), 65001),
+				flowSpecTestASPath(flowSpecTestUpdate(true, packet.IPv4AFI, discard, specific), 65002),
+			},
+			expected: []*packet.FlowSpecNLRI{wide},
+		},
+		{
+			name:         "No import filter",
+			importFilter: filter.Chain{},
+			updates: []*packet.BGPUpdate{
+				flowSpecTestUpdate(true, packet.IPv4AFI, discard, wide),
+			},
+			expected: []*packet.FlowSpecNLRI{},
+		},
+	}
+
+	for _, test := range tests {
+		s := newBGPServer(0, nil)
+		c := &flowSpecClientMock{}
+		s.RegisterFlowSpecClient(c)
+
+		addr := bnet.IPv4FromOctets(10, 0, 0, 2)
+		v := vrf.NewVRFRegistry().CreateVRFIfNotExists("master", 0)
+		v.IPv4UnicastRIB().AddPath(wide.Components[0].Prefix, flowSpecTestPath(addr))
+		v.IPv4UnicastRIB().AddPath(specific.Components[0].Prefix, flowSpecTestPath(addr))
+		v.IPv4UnicastRIB().AddPath(otherPeer.Components[0].Prefix, flowSpecTestPath(bnet.IPv4FromOctets(10, 0, 0, 3)))
+
+		importFilter := test.importFilter
+		if importFilter == nil {
+			importFilter = filter.NewAcceptAllFilterChain()
+		}
+
+		peerASN := test.peerASN
+		if peerASN == 0 {
+			peerASN = 65000
+		}
+
+		p := &peer{
+			server:                    s,
+			addr:                      addr.Ptr(),
+			localASN:                  65000,
+			peerASN:                   peerASN,
+			vrf:                       v,
+			flowSpecImportFilterChain: importFilter,
+			config: &PeerConfig{
+				IPv4FlowSpec:         true,
+				FlowSpecNoValidation: test.noValidation,
+			},
+		}
+		fsm := newFSM(p)
+		fsm.flowSpec.negotiate(packet.IPv4AFI)
+		fsm.flowSpec.initialized = true
+
+		for _, u := range test.updates {
+			fsm.flowSpec.processUpdate(u)
+		}
+
+		if test.dispose {
+			fsm.flowSpec.dispose()
+		}
+
+		nlris := make([]*packet.FlowSpecNLRI, 0)
+		for _, r := range c.rules {
+			nlris = append(nlris, r.NLRI)
+			assert.Equal(t, discard, r.ExtendedCommunities, "Test %q", test.name)
+		}
+
+		assert.Equal(t, test.expected, nlris, "Test %q", test.name)
+		assert.Equal(t, c.rules, s.GetFlowSpecRules(), "Test %q", test.name)
+	}
+}
+
+func TestFlowSpecReplaceImportFilterChain(t *testing.T) {
+	wide := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 0, 0), 8)
+	specific := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 1, 0), 24)
+
+	s := newBGPServer(0, nil)
+	c := &flowSpecClientMock{}
+	s.RegisterFlowSpecClient(c)
+
+	p := &peer{
+		server:                    s,
+		addr:                      bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+		flowSpecImportFilterChain: filter.NewDrainFilterChain(),
+		config: &PeerConfig{
+			IPv4FlowSpec:         true,
+			FlowSpecNoValidation: true,
+		},
+	}
+	fsm := newFSM(p)
+	fsm.flowSpec.negotiate(packet.IPv4AFI)
+	fsm.flowSpec.initialized = true
+
+	fsm.flowSpec.processUpdate(flowSpecTestUpdate(true, packet.IPv4AFI, nil, wide, specific))
+	assert.Len(t, c.rules, 0)
+
+	fsm.replaceImportFilterChain(filter.NewAcceptAllFilterChain())
+	assert.Len(t, c.rules, 2)
+
+	fsm.replaceImportFilterChain(filter.NewDrainFilterChain())
+	assert.Len(t, c.rules, 0)
+}
+
+func TestFlowSpecTableMultiplePeers(t *testing.T) {
+	n := flowSpecTestNLRI(bnet.IPv4FromOctets(10, 0, 0, 0), 8)
+	peerA := bnet.IPv4FromOctets(10, 0, 0, 1)
+	peerB := bnet.IPv4FromOctets(10, 0, 0, 2)
+
+	tbl := newFlowSpecTable()
+	tbl.update(peerB, []*FlowSpecRule{{Peer: peerB.Ptr(), NLRI: n}}, nil)
+	tbl.update(peerA, []*FlowSpecRule{{Peer: peerA.Ptr(), NLRI: n}}, nil)
+
+	rules := tbl.rules()
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, peerA, *rules[0].Peer)
+	}
+
+	tbl.removePeer(peerA)
+	rules = tbl.rules()
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, peerB, *rules[0].Peer)
+	}
+}
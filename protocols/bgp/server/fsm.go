@@ -6,13 +6,15 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bio-routing/bio-rd/net/tcp"
 	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/util/crashdump"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -69,12 +71,43 @@ type FSM struct {
 
 	local net.IP
 
-	ribsInitialized bool
-	ipv4Unicast     *fsmAddressFamily
-	ipv6Unicast     *fsmAddressFamily
+	ribsInitialized    bool
+	ipv4Unicast        *fsmAddressFamily
+	ipv6Unicast        *fsmAddressFamily
+	ipv4LabeledUnicast *fsmAddressFamily
+	ipv6LabeledUnicast *fsmAddressFamily
+	ipv4VPN            *vpnAddressFamily
+	ipv6VPN            *vpnAddressFamily
+	linkState          *linkStateAddressFamily
+	rtc                *rtcAddressFamily
+	flowSpec           *flowSpecAddressFamily
+	srPolicy           *srPolicyAddressFamily
 
 	supports4OctetASN bool
 
+	// peerGracefulRestart is the graceful restart capability received from the peer, nil if not advertised
+	peerGracefulRestart *packet.GracefulRestartCapability
+
+	// routeRefresh and enhancedRouteRefresh are set if the peer advertised the capabilities (RFC2918, RFC7313)
+	routeRefresh         bool
+	enhancedRouteRefresh bool
+	softResetCh          chan int
+
+	// extendedNextHop is set if IPv4 unicast routes can be sent with IPv6 next hops (RFC8950)
+	extendedNextHop bool
+
+	// dynamicCapability is set if address families can be added and removed with CAPABILITY messages
+	dynamicCapability bool
+
+	// peerAddressFamilies are the address families the peer advertised the multi protocol capability for
+	peerAddressFamilies map[addressFamilyKey]struct{}
+
+	// addressFamiliesCh signals a change of the address families of the peer
+	addressFamiliesCh chan struct{}
+
+	// injectCh passes UPDATE messages not received from the peer (e.g. MRT replay) to the established state
+	injectCh chan *packet.BGPUpdate
+
 	neighborID uint32
 	state      state
 	stateMu    sync.RWMutex
@@ -83,6 +116,14 @@ type FSM struct {
 
 	establishedTime time.Time
 
+	// sentOpen and receivedOpen are the OPEN messages of the session, reported to BMP stations on peer up
+	sentOpen     []byte
+	receivedOpen []byte
+
+	// notificationSent and notificationReceived are the NOTIFICATION messages ending the established session if any
+	notificationSent     []byte
+	notificationReceived []byte
+
 	connectionCancelFunc context.CancelFunc
 }
 
@@ -104,16 +145,20 @@ func NewActiveFSM(peer *peer) *FSM {
 
 func newFSM(peer *peer) *FSM {
 	f := &FSM{
-		connectRetryTime: time.Minute,
-		peer:             peer,
-		eventCh:          make(chan int),
-		conCh:            make(chan net.Conn),
-		conErrCh:         make(chan error),
-		initiateCon:      make(chan struct{}),
-		msgRecvCh:        make(chan []byte),
-		msgRecvFailCh:    make(chan error),
-		stopMsgRecvCh:    make(chan struct{}),
-		counters:         fsmCounters{},
+		connectRetryTime:    time.Minute,
+		peer:                peer,
+		eventCh:             make(chan int),
+		conCh:               make(chan net.Conn),
+		conErrCh:            make(chan error),
+		initiateCon:         make(chan struct{}),
+		msgRecvCh:           make(chan []byte),
+		msgRecvFailCh:       make(chan error),
+		stopMsgRecvCh:       make(chan struct{}),
+		softResetCh:         make(chan int, 2),
+		addressFamiliesCh:   make(chan struct{}, 1),
+		injectCh:            make(chan *packet.BGPUpdate),
+		peerAddressFamilies: make(map[addressFamilyKey]struct{}),
+		counters:            fsmCounters{},
 	}
 
 	if peer.ipv4 != nil {
@@ -124,26 +169,72 @@ func newFSM(peer *peer) *FSM {
 		f.ipv6Unicast = newFSMAddressFamily(packet.IPv6AFI, packet.UnicastSAFI, peer.ipv6, f)
 	}
 
+	if peer.ipv4LabeledUnicast != nil {
+		f.ipv4LabeledUnicast = newFSMAddressFamily(packet.IPv4AFI, packet.LabeledUnicastSAFI, peer.ipv4LabeledUnicast, f)
+	}
+
+	if peer.ipv6LabeledUnicast != nil {
+		f.ipv6LabeledUnicast = newFSMAddressFamily(packet.IPv6AFI, packet.LabeledUnicastSAFI, peer.ipv6LabeledUnicast, f)
+	}
+
+	if peer.config != nil && peer.config.IPv4VPN != nil {
+		f.ipv4VPN = newVPNAddressFamily(packet.IPv4AFI, peer.config.IPv4VPN, f)
+	}
+
+	if peer.config != nil && peer.config.IPv6VPN != nil {
+		f.ipv6VPN = newVPNAddressFamily(packet.IPv6AFI, peer.config.IPv6VPN, f)
+	}
+
+	if peer.config != nil && peer.config.LinkState {
+		f.linkState = newLinkStateAddressFamily(f)
+	}
+
+	if peer.config != nil && peer.config.RouteTargetConstraint {
+		f.rtc = newRTCAddressFamily(f)
+	}
+
+	if peer.config != nil && (peer.config.IPv4FlowSpec || peer.config.IPv6FlowSpec) {
+		afis := make([]uint16, 0, 2)
+		if peer.config.IPv4FlowSpec {
+			afis = append(afis, packet.IPv4AFI)
+		}
+
+		if peer.config.IPv6FlowSpec {
+			afis = append(afis, packet.IPv6AFI)
+		}
+
+		f.flowSpec = newFlowSpecAddressFamily(f, afis)
+	}
+
+	if peer.config != nil && (peer.config.IPv4SRPolicy || peer.config.IPv6SRPolicy) {
+		afis := make([]uint16, 0, 2)
+		if peer.config.IPv4SRPolicy {
+			afis = append(afis, packet.IPv4AFI)
+		}
+
+		if peer.config.IPv6SRPolicy {
+			afis = append(afis, packet.IPv6AFI)
+		}
+
+		f.srPolicy = newSRPolicyAddressFamily(f, afis)
+	}
+
 	return f
 }
 
 func (fsm *FSM) replaceImportFilterChain(c filter.Chain) {
-	if fsm.ipv4Unicast != nil {
-		fsm.ipv4Unicast.replaceImportFilterChain(c)
+	for _, f := range fsm.addressFamilies() {
+		f.replaceImportFilterChain(c)
 	}
 
-	if fsm.ipv6Unicast != nil {
-		fsm.ipv6Unicast.replaceImportFilterChain(c)
+	if fsm.flowSpec != nil {
+		fsm.flowSpec.replaceImportFilterChain(c)
 	}
 }
 
 func (fsm *FSM) replaceExportFilterChain(c filter.Chain) {
-	if fsm.ipv4Unicast != nil {
-		fsm.ipv4Unicast.replaceExportFilterChain(c)
-	}
-
-	if fsm.ipv6Unicast != nil {
-		fsm.ipv6Unicast.replaceExportFilterChain(c)
+	for _, f := range fsm.addressFamilies() {
+		f.replaceExportFilterChain(c)
 	}
 }
 
@@ -152,18 +243,86 @@ func (fsm *FSM) updateLastUpdateOrKeepalive() {
 }
 
 func (fsm *FSM) addressFamily(afi uint16, safi uint8) *fsmAddressFamily {
-	if safi != packet.UnicastSAFI {
-		return nil
+	switch safi {
+	case packet.UnicastSAFI:
+		switch afi {
+		case packet.IPv4AFI:
+			return fsm.ipv4Unicast
+		case packet.IPv6AFI:
+			return fsm.ipv6Unicast
+		}
+	case packet.LabeledUnicastSAFI:
+		switch afi {
+		case packet.IPv4AFI:
+			return fsm.ipv4LabeledUnicast
+		case packet.IPv6AFI:
+			return fsm.ipv6LabeledUnicast
+		}
 	}
 
-	switch afi {
-	case packet.IPv4AFI:
-		return fsm.ipv4Unicast
-	case packet.IPv6AFI:
-		return fsm.ipv6Unicast
-	default:
+	return nil
+}
+
+// labeledUnicastAddressFamilies gets the configured labeled unicast address families
+func (fsm *FSM) labeledUnicastAddressFamilies() []*fsmAddressFamily {
+	ret := make([]*fsmAddressFamily, 0, 2)
+	for _, f := range []*fsmAddressFamily{fsm.ipv4LabeledUnicast, fsm.ipv6LabeledUnicast} {
+		if f != nil {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+// addressFamilies gets the configured address families whether initialized or not
+func (fsm *FSM) addressFamilies() []*fsmAddressFamily {
+	ret := make([]*fsmAddressFamily, 0, 4)
+	for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast, fsm.ipv4LabeledUnicast, fsm.ipv6LabeledUnicast} {
+		if f != nil {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+// initializedAddressFamilies gets the unicast and labeled unicast address families of the established session
+func (fsm *FSM) initializedAddressFamilies() []*fsmAddressFamily {
+	ret := make([]*fsmAddressFamily, 0, 4)
+	for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast, fsm.ipv4LabeledUnicast, fsm.ipv6LabeledUnicast} {
+		if f != nil && f.initialized {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+// vpnAddressFamilies gets the configured VPN address families
+func (fsm *FSM) vpnAddressFamilies() []*vpnAddressFamily {
+	ret := make([]*vpnAddressFamily, 0, 2)
+	for _, f := range []*vpnAddressFamily{fsm.ipv4VPN, fsm.ipv6VPN} {
+		if f != nil {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+// gracefulRestart returns if graceful restart was negotiated for the session
+func (fsm *FSM) gracefulRestart() bool {
+	return fsm.peer.config != nil && fsm.peer.config.GracefulRestart != nil && fsm.peerGracefulRestart != nil
+}
+
+// restart gets the restart state of the server, nil if it is not restarting
+func (fsm *FSM) restart() *restartState {
+	if fsm.peer.server == nil {
 		return nil
 	}
+
+	return fsm.peer.server.restart
 }
 
 func (fsm *FSM) start() {
@@ -176,10 +335,13 @@ func (fsm *FSM) start() {
 }
 
 func (fsm *FSM) activate() {
+	defer crashdump.Recover()
+
 	fsm.eventCh <- AutomaticStart
 }
 
 func (fsm *FSM) run() {
+	defer crashdump.Recover()
 	defer fsm.cancelRunningGoRoutines()
 
 	next, reason := fsm.state.run()
@@ -188,20 +350,45 @@ func (fsm *FSM) run() {
 		oldState := stateName(fsm.state)
 
 		if oldState != newState {
-			log.WithFields(log.Fields{
+			atomic.AddUint64(&fsm.peer.counters.fsmTransitions, 1)
+			log.WithFields(logrus.Fields{
 				"peer":       fsm.peer.addr.String(),
 				"last_state": oldState,
 				"new_state":  newState,
 				"reason":     reason,
 			}).Info("FSM: Neighbor state change")
+			fsm.recordEvent(fmt.Sprintf("%s -> %s", oldState, newState), reason)
+			fsm.recordTransition(oldState, newState, reason)
+			fsm.emitEvent(PeerEvent{
+				Type:     PeerEventStateChange,
+				OldState: oldState,
+				NewState: newState,
+				Reason:   reason,
+			})
+		}
+
+		if oldState == stateNameEstablished && newState != stateNameEstablished {
+			fsm.bmp().peerDown(fsm, newState == stateNameCease)
 		}
 
 		if newState == stateNameCease {
 			return
 		}
 
+		// Dynamic peers only exist as long as their session
+		if oldState != newState && newState == stateNameIdle && fsm.peer.listenRange != nil {
+			go fsm.peer.server.removeDynamicPeer(fsm)
+		}
+
 		if oldState != newState && newState == stateNameEstablished {
 			fsm.establishedTime = time.Now()
+			fsm.notificationSent = nil
+			fsm.notificationReceived = nil
+			fsm.bmp().peerUp(fsm)
+		}
+
+		if oldState == stateNameEstablished && newState != stateNameEstablished {
+			atomic.AddUint64(&fsm.peer.counters.flaps, 1)
 		}
 
 		fsm.stateMu.Lock()
@@ -212,6 +399,22 @@ func (fsm *FSM) run() {
 	}
 }
 
+func (fsm *FSM) recordEvent(msg string, reason string) {
+	if fsm.peer.server == nil {
+		return
+	}
+
+	fsm.peer.server.eventLog.Record("bgp", fsm.peer.addr.String(), msg, reason)
+}
+
+func (fsm *FSM) recordTransition(from string, to string, reason string) {
+	if fsm.peer.server == nil {
+		return
+	}
+
+	fsm.peer.flightRecorder().Recorder("bgp", fsm.peer.addr.String()).Record(from, to, reason)
+}
+
 func (fsm *FSM) cancelRunningGoRoutines() {
 	if fsm.connectionCancelFunc != nil {
 		fsm.connectionCancelFunc()
@@ -239,20 +442,31 @@ func stateName(s state) string {
 	}
 }
 
+// setConnection sets the connection of the FSM. The connection is wrapped to allow packet capturing and recording.
+func (fsm *FSM) setConnection(c net.Conn) {
+	if fsm.peer.config != nil && fsm.peer.config.RecordDirectory != "" {
+		rc, err := newRecordConn(c, fsm.peer.config.RecordDirectory, fsm.peer.addr)
+		if err != nil {
+			log.WithError(err).WithField("peer", fsm.peer.addr.String()).Error("Unable to record session")
+		} else {
+			c = rc
+		}
+	}
+
+	if fsm.peer.server == nil {
+		fsm.con = c
+		return
+	}
+
+	fsm.con = newCaptureConn(c, fsm.peer.addr, fsm.peer.server.captures)
+}
+
 func (fsm *FSM) cease() {
 	fsm.eventCh <- Cease
 }
 
 func (fsm *FSM) sockSettings(c net.Conn) error {
-	ttl := fsm.peer.ttl
-	setNoRoute := false
-	if ttl == 0 {
-		if fsm.peer.isEBGP() {
-			ttl = 1
-			setNoRoute = true
-		}
-	}
-
+	ttl, setNoRoute := fsm.peer.outgoingTTL()
 	if setNoRoute {
 		err := setDontRoute(c)
 		if err != nil {
@@ -267,14 +481,24 @@ func (fsm *FSM) sockSettings(c net.Conn) error {
 		}
 	}
 
+	if minTTL := fsm.peer.minTTL(); minTTL != 0 {
+		err := setMinTTL(c, minTTL)
+		if err != nil {
+			return errors.Wrap(err, "Unable to set minimum TTL")
+		}
+	}
+
 	return nil
 }
 
 func (fsm *FSM) tcpConnector(ctx context.Context) {
+	defer crashdump.Recover()
+
 	for {
 		select {
 		case <-fsm.initiateCon:
-			c, err := tcp.Dial(&net.TCPAddr{IP: fsm.local}, &net.TCPAddr{IP: fsm.peer.addr.ToNetIP(), Port: BGPPORT}, fsm.peer.ttl, fsm.peer.config.AuthenticationKey, fsm.peer.ttl == 0)
+			ttl, noRoute := fsm.peer.outgoingTTL()
+			c, err := tcp.Dial(&net.TCPAddr{IP: fsm.local}, &net.TCPAddr{IP: fsm.peer.addr.ToNetIP(), Port: BGPPORT}, ttl, fsm.peer.config.AuthenticationKey, noRoute)
 			if err != nil {
 				select {
 				case fsm.conErrCh <- err:
@@ -302,19 +526,23 @@ func (fsm *FSM) tcpConnect() {
 }
 
 func (fsm *FSM) msgReceiver() error {
+	defer crashdump.Recover()
+
 	for {
 		msg, err := recvMsg(fsm.con)
 		if err != nil {
 			fsm.msgRecvFailCh <- err
 			return nil
 		}
+		fsm.peer.counters.messagesReceived.count(msg[packet.HeaderLen-1])
 		fsm.msgRecvCh <- msg
 	}
 }
 
 func (fsm *FSM) decodeOptions() *packet.DecodeOptions {
 	ret := &packet.DecodeOptions{
-		Use32BitASN: fsm.supports4OctetASN,
+		Use32BitASN:          fsm.supports4OctetASN,
+		RevisedErrorHandling: true,
 	}
 
 	ipv4unicast := fsm.addressFamily(packet.IPv4AFI, packet.UnicastSAFI)
@@ -327,16 +555,24 @@ func (fsm *FSM) decodeOptions() *packet.DecodeOptions {
 		ret.AddPathIPv6Unicast = ipv6unicast.addPathRX
 	}
 
+	if fsm.ipv4LabeledUnicast != nil {
+		ret.AddPathIPv4LabeledUnicast = fsm.ipv4LabeledUnicast.addPathRX
+	}
+
+	if fsm.ipv6LabeledUnicast != nil {
+		ret.AddPathIPv6LabeledUnicast = fsm.ipv6LabeledUnicast.addPathRX
+	}
+
 	return ret
 }
 
 func (fsm *FSM) startConnectRetryTimer() {
-	fsm.connectRetryTimer = time.NewTimer(fsm.connectRetryTime)
+	fsm.connectRetryTimer = time.NewTimer(jitter(fsm.connectRetryTime))
 }
 
 func (fsm *FSM) resetConnectRetryTimer() {
 	stopTimer(fsm.connectRetryTimer)
-	fsm.connectRetryTimer.Reset(fsm.connectRetryTime)
+	fsm.connectRetryTimer.Reset(jitter(fsm.connectRetryTime))
 }
 
 func (fsm *FSM) resetConnectRetryCounter() {
@@ -345,12 +581,14 @@ func (fsm *FSM) resetConnectRetryCounter() {
 
 func (fsm *FSM) sendOpen() error {
 	msg := packet.SerializeOpenMsg(fsm.openMessage())
+	fsm.sentOpen = msg
 
 	_, err := fsm.con.Write(msg)
 	if err != nil {
 		return errors.Wrap(err, "Unable to send OPEN message")
 	}
 
+	fsm.peer.counters.messagesSent.count(packet.OpenMsg)
 	return nil
 }
 
@@ -360,26 +598,37 @@ func (fsm *FSM) openMessage() *packet.BGPOpen {
 		ASN:           fsm.local16BitASN(),
 		HoldTime:      uint16(fsm.peer.holdTime / time.Second),
 		BGPIdentifier: fsm.peer.routerID,
-		OptParams:     fsm.peer.optOpenParams,
+		OptParams:     fsm.peer.openParams(),
 	}
 }
 
 func (fsm *FSM) local16BitASN() uint16 {
-	if fsm.peer.localASN > uint32(^uint16(0)) {
+	asn := fsm.peer.sessionASN()
+	if asn > uint32(^uint16(0)) {
 		return packet.ASTransASN
 	}
 
-	return uint16(fsm.peer.localASN)
+	return uint16(asn)
 }
 
 func (fsm *FSM) sendNotification(errorCode uint8, errorSubCode uint8) error {
-	msg := packet.SerializeNotificationMsg(&packet.BGPNotification{})
+	return fsm.sendNotificationMsg(&packet.BGPNotification{
+		ErrorCode:    errorCode,
+		ErrorSubcode: errorSubCode,
+	})
+}
+
+func (fsm *FSM) sendNotificationMsg(n *packet.BGPNotification) error {
+	msg := packet.SerializeNotificationMsg(n)
+	fsm.notificationSent = msg
 
 	_, err := fsm.con.Write(msg)
 	if err != nil {
 		return errors.Wrap(err, "Unable to send NOTIFICATION message")
 	}
 
+	fsm.peer.counters.messagesSent.count(packet.NotificationMsg)
+	fsm.peer.notifications.sent(n)
 	return nil
 }
 
@@ -391,6 +640,7 @@ func (fsm *FSM) sendKeepalive() error {
 		return errors.Wrap(err, "Unable to send KEEPALIVE message")
 	}
 
+	fsm.peer.counters.messagesSent.count(packet.KeepaliveMsg)
 	return nil
 }
 
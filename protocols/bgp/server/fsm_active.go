@@ -59,7 +59,7 @@ func (s *activeState) connectionSuccess(con net.Conn) (state, string) {
 		return newIdleState(s.fsm), fmt.Sprintf("Unable to set socket options: %v", err)
 	}
 
-	s.fsm.con = con
+	s.fsm.setConnection(con)
 	stopTimer(s.fsm.connectRetryTimer)
 	err = s.fsm.sendOpen()
 	if err != nil {
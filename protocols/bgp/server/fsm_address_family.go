@@ -12,6 +12,7 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable/adjRIBOut"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/sirupsen/logrus"
 )
 
 // fsmAddressFamily holds RIBs and the UpdateSender of an peer for an AFI/SAFI combination
@@ -24,6 +25,9 @@ type fsmAddressFamily struct {
 	adjRIBOut routingtable.AdjRIBOut
 	rib       *locRIB.LocRIB
 
+	// ribClient gets the paths of the adj-RIB-in: The Loc-RIB or its next hop tracker
+	ribClient routingtable.RouteTableClient
+
 	importFilterChain filter.Chain
 	exportFilterChain filter.Chain
 
@@ -34,6 +38,35 @@ type fsmAddressFamily struct {
 
 	multiProtocol bool
 
+	// localAddress is the next hop of paths we advertise labels for (labeled unicast only)
+	localAddress *bnet.IP
+	labels       *labelAllocator
+
+	// advertisementDeferred is set while the server restarts and the Loc-RIB is not yet sent to the peer
+	advertisementDeferred bool
+
+	// staleTimer limits the time paths retained from the previous session wait for End-of-RIB
+	staleTimer *time.Timer
+
+	// updateGroup is the update group shared with peer group members with identical outbound settings
+	updateGroup *updateGroup
+
+	prefixLimit         *PrefixLimit
+	prefixLimitWarned   bool
+	prefixLimitExceeded bool
+
+	// prefixLimitThreshold is the highest warning threshold of the prefix limit reached
+	prefixLimitThreshold uint8
+
+	advertisementInterval       time.Duration
+	noAdvertisementIntervalIBGP bool
+
+	nextHopSelf route.NextHopSelfMode
+
+	// bmpAdjRIBIn and bmpAdjRIBOut report post-policy paths to BMP stations
+	bmpAdjRIBIn  *bmpRouteMonitor
+	bmpAdjRIBOut *bmpRouteMonitor
+
 	initialized bool
 }
 
@@ -43,8 +76,14 @@ func newFSMAddressFamily(afi uint16, safi uint8, family *peerAddressFamily, fsm
 		safi:              safi,
 		fsm:               fsm,
 		rib:               family.rib,
+		ribClient:         family.ribClient(),
 		importFilterChain: family.importFilterChain,
 		exportFilterChain: family.exportFilterChain,
+		prefixLimit:       family.prefixLimit,
+
+		advertisementInterval:       family.advertisementInterval,
+		noAdvertisementIntervalIBGP: family.noAdvertisementIntervalIBGP,
+		nextHopSelf:                 family.nextHopSelf,
 		addPathTX: routingtable.ClientOptions{
 			BestOnly: true,
 		},
@@ -57,7 +96,11 @@ func (f *fsmAddressFamily) replaceImportFilterChain(c filter.Chain) {
 	}
 
 	f.importFilterChain = c
-	f.adjRIBIn.ReplaceFilterChain(c)
+	if !f.initialized {
+		return
+	}
+
+	f.adjRIBIn.ReplaceFilterChain(f.effectiveImportFilterChain())
 }
 
 func (f *fsmAddressFamily) replaceExportFilterChain(c filter.Chain) {
@@ -66,7 +109,12 @@ func (f *fsmAddressFamily) replaceExportFilterChain(c filter.Chain) {
 	}
 
 	f.exportFilterChain = c
-	f.adjRIBOut.ReplaceFilterChain(c)
+	if !f.initialized {
+		return
+	}
+
+	f.leaveUpdateGroup()
+	f.adjRIBOut.ReplaceFilterChain(f.effectiveExportFilterChain())
 }
 
 func (f *fsmAddressFamily) dumpRIBOut() []*route.Route {
@@ -78,29 +126,179 @@ func (f *fsmAddressFamily) dumpRIBIn() []*route.Route {
 }
 
 func (f *fsmAddressFamily) init(n *routingtable.Neighbor) {
+	// The neighbor is shared by all address families of the session
+	if f.nextHopSelf != route.NextHopSelfDisabled {
+		afn := *n
+		afn.NextHopSelf = f.nextHopSelf
+		n = &afn
+	}
+
 	contributingASNs := f.rib.GetContributingASNs()
 
-	f.adjRIBIn = adjRIBIn.New(f.importFilterChain, contributingASNs, f.fsm.peer.routerID, f.fsm.peer.clusterID, f.addPathRX)
+	a := f.resumeStale()
+	resumed := a != nil
+	if !resumed {
+		a = adjRIBIn.New(f.effectiveImportFilterChain(), contributingASNs, f.fsm.peer.routerID, f.fsm.peer.clusterID, f.addPathRX)
+	}
+	f.adjRIBIn = a
 	contributingASNs.Add(f.fsm.peer.localASN)
 
-	f.adjRIBIn.Register(f.rib)
+	if f.fsm.peer.server != nil {
+		a.SetOriginValidator(f.fsm.peer.server.originValidator(f.fsm.peer.localASN))
+		a.SetClusterIDs(f.fsm.peer.server.clusterIDs)
+	}
+
+	if c := f.fsm.peer.config; c != nil {
+		a.SetReflectionChecks(!c.SkipOriginatorIDCheck, !c.SkipClusterListCheck)
+		a.SetAllowASIn(c.AllowASIn)
+	}
+
+	if !resumed {
+		f.adjRIBIn.Register(f.ribClient)
+	}
 
-	f.adjRIBOut = adjRIBOut.New(f.rib, n, f.exportFilterChain, !f.addPathTX.BestOnly)
+	o := adjRIBOut.New(f.rib, n, f.effectiveExportFilterChain(), !f.addPathTX.BestOnly)
+	f.adjRIBOut = o
+	f.joinUpdateGroup(n, o)
+	f.bmpMonitorInit()
+
+	if packet.IsLabeledSAFI(f.safi) {
+		f.localAddress = n.LocalAddress
+		f.labels = f.fsm.peer.server.labelAllocator(f.rib)
+	}
 
 	f.updateSender = newUpdateSender(f)
 	f.updateSender.Start(time.Millisecond * 5)
 
 	f.adjRIBOut.Register(f.updateSender)
 
-	f.rib.RegisterWithOptions(f.adjRIBOut, f.addPathTX)
+	f.advertisementDeferred = f.fsm.restart().restarting()
+	if !f.advertisementDeferred {
+		f.startAdvertisement()
+	}
+
 	f.initialized = true
 }
 
+// joinUpdateGroup shares export filtering with all peer group members with identical outbound settings
+func (f *fsmAddressFamily) joinUpdateGroup(n *routingtable.Neighbor, o *adjRIBOut.AdjRIBOut) {
+	p := f.fsm.peer
+	if p.server == nil || p.config == nil || p.config.PeerGroup == "" {
+		return
+	}
+
+	key := updateGroupKey{
+		peerGroup:                  p.config.PeerGroup,
+		afi:                        f.afi,
+		safi:                       f.safi,
+		localASN:                   n.LocalASN,
+		iBGP:                       n.IBGP,
+		routeServerClient:          n.RouteServerClient,
+		routeReflectorClient:       n.RouteReflectorClient,
+		noClientToClientReflection: n.NoClientToClientReflection,
+		ignoreWellKnownCommunities: n.IgnoreWellKnownCommunities,
+		bilateralPeer:              n.BilateralPeer,
+		clusterID:                  n.ClusterID,
+		addPathTX:                  f.addPathTX,
+		removePrivateAS:            n.RemovePrivateAS,
+		asOverride:                 n.ASOverride,
+		migrationASN:               n.MigrationASN,
+		replaceAS:                  n.ReplaceAS,
+		nextHopSelf:                n.NextHopSelf,
+	}
+
+	// Paths are scrubbed and overridden depending on the AS of the peer
+	if n.RemovePrivateAS != route.PrivateASKeep || n.ASOverride {
+		key.peerASN = n.PeerASN
+	}
+	if n.LocalAddress != nil {
+		key.localAddress = *n.LocalAddress
+	}
+
+	f.updateGroup = p.server.updateGroups.join(key, f.effectiveExportFilterChain())
+	o.SetExportCache(f.updateGroup.cache)
+}
+
+func (f *fsmAddressFamily) leaveUpdateGroup() {
+	if f.updateGroup == nil {
+		return
+	}
+
+	f.fsm.peer.server.updateGroups.leave(f.updateGroup)
+	f.updateGroup = nil
+}
+
+// startAdvertisement sends the Loc-RIB to the peer followed by End-of-RIB if graceful restart was negotiated
+func (f *fsmAddressFamily) startAdvertisement() {
+	f.advertisementDeferred = false
+	if p := f.fsm.peer; p.orrGroup != "" && p.server != nil {
+		p.server.orr.register(p.orrGroup, f)
+	} else {
+		f.rib.RegisterWithOptions(f.adjRIBOut, f.addPathTX)
+	}
+
+	if f.fsm.gracefulRestart() {
+		f.updateSender.sendEndOfRIB()
+	}
+}
+
+// gracefulRestart returns if graceful restart was negotiated for the address family
+func (f *fsmAddressFamily) gracefulRestart() bool {
+	return f.fsm.gracefulRestart() && f.fsm.peerGracefulRestart.AddressFamily(f.afi, f.safi) != nil
+}
+
+// resumeStale takes over the paths retained from the previous session. They are kept only if the peer preserved its
+// forwarding state and are replaced by the peers updates until it sends End-of-RIB or the stale path timer expires (RFC4724 4.2).
+func (f *fsmAddressFamily) resumeStale() *adjRIBIn.AdjRIBIn {
+	pf := f.fsm.peer.addressFamily(f.afi, f.safi)
+	if pf == nil {
+		return nil
+	}
+
+	if !f.gracefulRestart() || !f.fsm.peerGracefulRestart.AddressFamily(f.afi, f.safi).ForwardingState {
+		pf.flushStale()
+		return nil
+	}
+
+	a := pf.takeStale(f.addPathRX)
+	if a == nil {
+		return nil
+	}
+
+	peerAddr := f.fsm.peer.addr.String()
+	f.staleTimer = time.AfterFunc(f.fsm.peer.config.GracefulRestart.stalePathTime(), func() {
+		log.WithFields(logrus.Fields{
+			"peer":    peerAddr,
+			"removed": a.RemoveStale(),
+		}).Info("Stale path timer expired")
+	})
+
+	return a
+}
+
+// endOfRIB processes an End-of-RIB marker: Paths not refreshed since a restart of the peer are removed
+func (f *fsmAddressFamily) endOfRIB() {
+	if f.staleTimer != nil {
+		f.staleTimer.Stop()
+		f.staleTimer = nil
+	}
+
+	removed := f.adjRIBIn.(*adjRIBIn.AdjRIBIn).RemoveStale()
+	if removed > 0 {
+		log.WithFields(logrus.Fields{
+			"peer":    f.fsm.peer.addr.String(),
+			"removed": removed,
+		}).Info("Removed stale paths after End-of-RIB")
+	}
+
+	f.fsm.restart().endOfRIB(f.fsm.peer.addr, f.afi, f.safi)
+}
+
 func (f *fsmAddressFamily) bmpInit() {
 	f.adjRIBIn = adjRIBIn.New(filter.NewAcceptAllFilterChain(), &routingtable.ContributingASNs{}, f.fsm.peer.routerID, f.fsm.peer.clusterID, f.addPathRX)
 
 	if f.rib != nil {
-		f.adjRIBIn.Register(f.rib)
+		f.adjRIBIn.Register(f.ribClient)
 	}
 
 	f.initialized = true
@@ -111,21 +309,40 @@ func (f *fsmAddressFamily) bmpDispose() {
 
 	f.adjRIBIn.(*adjRIBIn.AdjRIBIn).Flush()
 
-	f.adjRIBIn.Unregister(f.rib)
+	f.adjRIBIn.Unregister(f.ribClient)
 
 	f.adjRIBIn = nil
 }
 
-func (f *fsmAddressFamily) dispose() {
+// dispose tears down the RIBs of the address family. With retainStale set and graceful restart negotiated
+// the paths learned from the peer stay in the Loc-RIB as stale for the restart time the peer advertised.
+func (f *fsmAddressFamily) dispose(retainStale bool) {
 	if !f.initialized {
 		return
 	}
 
+	if f.staleTimer != nil {
+		f.staleTimer.Stop()
+		f.staleTimer = nil
+	}
+
 	f.rib.GetContributingASNs().Remove(f.fsm.peer.localASN)
-	f.adjRIBIn.Unregister(f.rib)
+	f.bmpMonitorDispose()
+	if retainStale && f.gracefulRestart() {
+		restartTime := time.Duration(f.fsm.peerGracefulRestart.RestartTime) * time.Second
+		f.fsm.peer.addressFamily(f.afi, f.safi).retainStale(f.fsm.peer, f.adjRIBIn.(*adjRIBIn.AdjRIBIn), f.addPathRX, restartTime)
+	} else {
+		// Move traffic to backup paths before withdrawing the paths one by one (BGP PIC)
+		f.rib.PeerDown(f.fsm.peer.addr)
+		f.adjRIBIn.Unregister(f.ribClient)
+	}
 	f.rib.Unregister(f.adjRIBOut)
+	if p := f.fsm.peer; p.orrGroup != "" && p.server != nil {
+		p.server.orr.unregister(p.orrGroup, f)
+	}
 	f.adjRIBOut.Unregister(f.updateSender)
 	f.updateSender.Destroy()
+	f.leaveUpdateGroup()
 
 	f.adjRIBIn = nil
 	f.adjRIBOut = nil
@@ -134,12 +351,12 @@ func (f *fsmAddressFamily) dispose() {
 }
 
 func (f *fsmAddressFamily) processUpdate(u *packet.BGPUpdate) {
-	if f.safi != packet.UnicastSAFI {
+	if f.safi != packet.UnicastSAFI && f.safi != packet.LabeledUnicastSAFI {
 		return
 	}
 
 	f.multiProtocolUpdates(u)
-	if f.afi == packet.IPv4AFI {
+	if f.afi == packet.IPv4AFI && f.safi == packet.UnicastSAFI {
 		f.withdraws(u)
 		f.updates(u)
 	}
@@ -153,16 +370,18 @@ func (f *fsmAddressFamily) withdraws(u *packet.BGPUpdate) {
 
 func (f *fsmAddressFamily) updates(u *packet.BGPUpdate) {
 	for r := u.NLRI; r != nil; r = r.Next {
-		path := f.newRoutePath()
-		f.processAttributes(u.PathAttributes, path)
+		path := f.fsm.newRoutePath()
+		processAttributes(u.PathAttributes, path)
+		f.fsm.prependMigrationAS(path)
 
 		f.adjRIBIn.AddPath(r.Prefix, path)
 	}
 }
 
 func (f *fsmAddressFamily) multiProtocolUpdates(u *packet.BGPUpdate) {
-	path := f.newRoutePath()
-	f.processAttributes(u.PathAttributes, path)
+	path := f.fsm.newRoutePath()
+	processAttributes(u.PathAttributes, path)
+	f.fsm.prependMigrationAS(path)
 
 	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
 		switch pa.TypeCode {
@@ -174,16 +393,23 @@ func (f *fsmAddressFamily) multiProtocolUpdates(u *packet.BGPUpdate) {
 	}
 }
 
-func (f *fsmAddressFamily) newRoutePath() *route.Path {
-	return &route.Path{
+func (fsm *FSM) newRoutePath() *route.Path {
+	p := &route.Path{
 		Type: route.BGPPathType,
 		BGPPath: &route.BGPPath{
 			BGPPathA: &route.BGPPathA{
-				Source: f.fsm.peer.addr,
-				EBGP:   f.fsm.peer.localASN != f.fsm.peer.peerASN,
+				Source: fsm.peer.addr,
+				EBGP:   fsm.peer.localASN != fsm.peer.peerASN,
 			},
+			Multipath: fsm.peer.multipath,
 		},
 	}
+
+	if fsm.peer.routeReflectorClient {
+		p.BGPPath.ClientClusterID = fsm.peer.clusterID
+	}
+
+	return p
 }
 
 func (f *fsmAddressFamily) multiProtocolUpdate(path *route.Path, nlri packet.MultiProtocolReachNLRI) {
@@ -194,7 +420,16 @@ func (f *fsmAddressFamily) multiProtocolUpdate(path *route.Path, nlri packet.Mul
 	path.BGPPath.BGPPathA.NextHop = nlri.NextHop
 
 	for n := nlri.NLRI; n != nil; n = n.Next {
-		f.adjRIBIn.AddPath(n.Prefix, path)
+		if !packet.IsLabeledSAFI(f.safi) {
+			f.adjRIBIn.AddPath(n.Prefix, path)
+			continue
+		}
+
+		// Labels are per prefix, so each prefix gets its own path
+		p := path.Copy()
+		p.BGPPath.PathIdentifier = n.PathIdentifier
+		p.BGPPath.Labels = n.Labels
+		f.adjRIBIn.AddPath(n.Prefix, p)
 	}
 }
 
@@ -208,7 +443,7 @@ func (f *fsmAddressFamily) multiProtocolWithdraw(path *route.Path, nlri packet.M
 	}
 }
 
-func (f *fsmAddressFamily) processAttributes(attrs *packet.PathAttribute, path *route.Path) {
+func processAttributes(attrs *packet.PathAttribute, path *route.Path) {
 	for pa := attrs; pa != nil; pa = pa.Next {
 		switch pa.TypeCode {
 		case packet.OriginAttr:
@@ -231,6 +466,8 @@ func (f *fsmAddressFamily) processAttributes(attrs *packet.PathAttribute, path *
 			path.BGPPath.Communities = pa.Value.(*types.Communities)
 		case packet.LargeCommunitiesAttr:
 			path.BGPPath.LargeCommunities = pa.Value.(*types.LargeCommunities)
+		case packet.ExtendedCommunitiesAttr:
+			path.BGPPath.ExtendedCommunities = pa.Value.(*types.ExtendedCommunities)
 		case packet.OriginatorIDAttr:
 			path.BGPPath.BGPPathA.OriginatorID = pa.Value.(uint32)
 		case packet.ClusterListAttr:
@@ -238,7 +475,7 @@ func (f *fsmAddressFamily) processAttributes(attrs *packet.PathAttribute, path *
 		case packet.MultiProtocolReachNLRICode:
 		case packet.MultiProtocolUnreachNLRICode:
 		default:
-			unknownAttr := f.processUnknownAttribute(pa)
+			unknownAttr := processUnknownAttribute(pa)
 			if unknownAttr != nil {
 				path.BGPPath.UnknownAttributes = append(path.BGPPath.UnknownAttributes, *unknownAttr)
 			}
@@ -246,7 +483,7 @@ func (f *fsmAddressFamily) processAttributes(attrs *packet.PathAttribute, path *
 	}
 }
 
-func (f *fsmAddressFamily) processUnknownAttribute(attr *packet.PathAttribute) *types.UnknownPathAttribute {
+func processUnknownAttribute(attr *packet.PathAttribute) *types.UnknownPathAttribute {
 	if !attr.Transitive {
 		return nil
 	}
@@ -57,7 +57,7 @@ func TestFSMAFIInitDispose(t *testing.T) {
 	assert.Equal(t, true, f.initialized)
 
 	// Dispose
-	f.dispose()
+	f.dispose(false)
 
 	f.updateSender.wg.Wait()
 	assert.Equal(t, false, f.rib.GetContributingASNs().IsContributingASN(15169))
@@ -100,12 +100,10 @@ func TestProcessAttributes(t *testing.T) {
 		Next: unknown1,
 	}
 
-	f := &fsmAddressFamily{}
-
 	p := &route.Path{
 		BGPPath: &route.BGPPath{},
 	}
-	f.processAttributes(asPath, p)
+	processAttributes(asPath, p)
 
 	expectedCodes := []uint8{200, 100}
 	expectedValues := [][]byte{{5, 6}, {1, 2, 3, 4}}
@@ -42,7 +42,7 @@ func (s *connectState) connectionSuccess(c net.Conn) (state, string) {
 		return newIdleState(s.fsm), fmt.Sprintf("Unable to set socket options: %v", err)
 	}
 
-	s.fsm.con = c
+	s.fsm.setConnection(c)
 	stopTimer(s.fsm.connectRetryTimer)
 	err = s.fsm.sendOpen()
 	if err != nil {
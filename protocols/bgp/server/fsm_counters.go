@@ -1,11 +1,169 @@
 package server
 
+import (
+	"sync/atomic"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/util/histogram"
+)
+
 type fsmCounters struct {
 	updatesReceived uint64
 	updatesSent     uint64
 }
 
 func (c *fsmCounters) reset() {
-	c.updatesReceived = 0
-	c.updatesSent = 0
+	atomic.StoreUint64(&c.updatesReceived, 0)
+	atomic.StoreUint64(&c.updatesSent, 0)
+}
+
+type peerCounters struct {
+	flaps               uint64
+	prefixLimitExceeded uint64
+
+	// ribLatency is the time from receipt of an UPDATE until all its routes are processed by the RIBs
+	ribLatency histogram.Histogram
+
+	// adjRIBOutLatency is the time routes are queued in adj-RIB-out until they are sent
+	adjRIBOutLatency histogram.Histogram
+
+	// Counters of all sessions of the peer. Unlike the update counters of an FSM they are kept when a session goes down.
+	messagesReceived           messageCounters
+	messagesSent               messageCounters
+	prefixesAdvertisedReceived uint64
+	prefixesWithdrawnReceived  uint64
+	prefixesAdvertisedSent     uint64
+	prefixesWithdrawnSent      uint64
+	malformedAttributes        uint64
+	attributeErrors            attributeErrorCounters
+	fsmTransitions             uint64
+}
+
+func (c *peerCounters) reset() {
+	atomic.StoreUint64(&c.flaps, 0)
+	atomic.StoreUint64(&c.prefixLimitExceeded, 0)
+	c.ribLatency.Reset()
+	c.adjRIBOutLatency.Reset()
+
+	c.messagesReceived.reset()
+	c.messagesSent.reset()
+	atomic.StoreUint64(&c.prefixesAdvertisedReceived, 0)
+	atomic.StoreUint64(&c.prefixesWithdrawnReceived, 0)
+	atomic.StoreUint64(&c.prefixesAdvertisedSent, 0)
+	atomic.StoreUint64(&c.prefixesWithdrawnSent, 0)
+	atomic.StoreUint64(&c.malformedAttributes, 0)
+	c.attributeErrors.reset()
+	atomic.StoreUint64(&c.fsmTransitions, 0)
+}
+
+// updateReceived counts the prefixes advertised and withdrawn by an update received
+func (c *peerCounters) updateReceived(u *packet.BGPUpdate) {
+	advertised, withdrawn := prefixCounts(u)
+	atomic.AddUint64(&c.prefixesAdvertisedReceived, advertised)
+	atomic.AddUint64(&c.prefixesWithdrawnReceived, withdrawn)
+}
+
+// updateSent counts the prefixes advertised and withdrawn by an update sent
+func (c *peerCounters) updateSent(u *packet.BGPUpdate) {
+	advertised, withdrawn := prefixCounts(u)
+	atomic.AddUint64(&c.prefixesAdvertisedSent, advertised)
+	atomic.AddUint64(&c.prefixesWithdrawnSent, withdrawn)
+}
+
+// prefixCounts gets the number of NLRIs advertised and withdrawn by an update
+func prefixCounts(u *packet.BGPUpdate) (advertised uint64, withdrawn uint64) {
+	advertised = nlriCount(u.NLRI)
+	withdrawn = nlriCount(u.WithdrawnRoutes)
+
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.MultiProtocolReachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolReachNLRI)
+			advertised += nlriCount(mp.NLRI) + uint64(len(mp.LinkState)+len(mp.RouteTargets)+len(mp.FlowSpec))
+		case packet.MultiProtocolUnreachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolUnreachNLRI)
+			withdrawn += nlriCount(mp.NLRI) + uint64(len(mp.LinkState)+len(mp.RouteTargets)+len(mp.FlowSpec))
+		}
+	}
+
+	return advertised, withdrawn
+}
+
+func nlriCount(n *packet.NLRI) uint64 {
+	ret := uint64(0)
+	for ; n != nil; n = n.Next {
+		ret++
+	}
+
+	return ret
+}
+
+// messageCounters count the BGP messages other than UPDATEs by type
+type messageCounters struct {
+	opens          uint64
+	keepalives     uint64
+	notifications  uint64
+	routeRefreshes uint64
+}
+
+func (c *messageCounters) count(msgType uint8) {
+	switch msgType {
+	case packet.OpenMsg:
+		atomic.AddUint64(&c.opens, 1)
+	case packet.KeepaliveMsg:
+		atomic.AddUint64(&c.keepalives, 1)
+	case packet.NotificationMsg:
+		atomic.AddUint64(&c.notifications, 1)
+	case packet.RouteRefreshMsg:
+		atomic.AddUint64(&c.routeRefreshes, 1)
+	}
+}
+
+func (c *messageCounters) reset() {
+	atomic.StoreUint64(&c.opens, 0)
+	atomic.StoreUint64(&c.keepalives, 0)
+	atomic.StoreUint64(&c.notifications, 0)
+	atomic.StoreUint64(&c.routeRefreshes, 0)
+}
+
+func (c *messageCounters) metrics() metrics.BGPMessageCounts {
+	return metrics.BGPMessageCounts{
+		Opens:          atomic.LoadUint64(&c.opens),
+		Keepalives:     atomic.LoadUint64(&c.keepalives),
+		Notifications:  atomic.LoadUint64(&c.notifications),
+		RouteRefreshes: atomic.LoadUint64(&c.routeRefreshes),
+	}
+}
+
+// attributeErrorCounters count malformed path attributes by the way they were handled (RFC7606)
+type attributeErrorCounters struct {
+	treatAsWithdraw  uint64
+	attributeDiscard uint64
+	sessionReset     uint64
+}
+
+func (c *attributeErrorCounters) count(a packet.AttributeErrorAction) {
+	switch a {
+	case packet.TreatAsWithdraw:
+		atomic.AddUint64(&c.treatAsWithdraw, 1)
+	case packet.AttributeDiscard:
+		atomic.AddUint64(&c.attributeDiscard, 1)
+	case packet.SessionReset:
+		atomic.AddUint64(&c.sessionReset, 1)
+	}
+}
+
+func (c *attributeErrorCounters) reset() {
+	atomic.StoreUint64(&c.treatAsWithdraw, 0)
+	atomic.StoreUint64(&c.attributeDiscard, 0)
+	atomic.StoreUint64(&c.sessionReset, 0)
+}
+
+func (c *attributeErrorCounters) metrics() metrics.BGPAttributeErrorCounts {
+	return metrics.BGPAttributeErrorCounts{
+		TreatAsWithdraw:  atomic.LoadUint64(&c.treatAsWithdraw),
+		AttributeDiscard: atomic.LoadUint64(&c.attributeDiscard),
+		SessionReset:     atomic.LoadUint64(&c.sessionReset),
+	}
 }
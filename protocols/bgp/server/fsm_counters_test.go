@@ -0,0 +1,103 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixCounts(t *testing.T) {
+	tests := []struct {
+		name               string
+		update             *packet.BGPUpdate
+		expectedAdvertised uint64
+		expectedWithdrawn  uint64
+	}{
+		{
+			name: "IPv4 unicast",
+			update: &packet.BGPUpdate{
+				WithdrawnRoutes: &packet.NLRI{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+				},
+				NLRI: &packet.NLRI{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+					Next: &packet.NLRI{
+						Prefix: bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24).Ptr(),
+					},
+				},
+			},
+			expectedAdvertised: 2,
+			expectedWithdrawn:  1,
+		},
+		{
+			name: "Multi protocol",
+			update: &packet.BGPUpdate{
+				PathAttributes: &packet.PathAttribute{
+					TypeCode: packet.MultiProtocolReachNLRICode,
+					Value: packet.MultiProtocolReachNLRI{
+						AFI:  packet.IPv6AFI,
+						SAFI: packet.UnicastSAFI,
+						NLRI: &packet.NLRI{
+							Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+						},
+					},
+					Next: &packet.PathAttribute{
+						TypeCode: packet.MultiProtocolUnreachNLRICode,
+						Value: packet.MultiProtocolUnreachNLRI{
+							AFI:  packet.IPv6AFI,
+							SAFI: packet.UnicastSAFI,
+							NLRI: &packet.NLRI{
+								Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb9, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+							},
+						},
+					},
+				},
+			},
+			expectedAdvertised: 1,
+			expectedWithdrawn:  1,
+		},
+		{
+			name:   "End-of-RIB",
+			update: packet.EndOfRIB(packet.IPv6AFI, packet.UnicastSAFI),
+		},
+	}
+
+	for _, test := range tests {
+		advertised, withdrawn := prefixCounts(test.update)
+		assert.Equal(t, test.expectedAdvertised, advertised, "Test %q", test.name)
+		assert.Equal(t, test.expectedWithdrawn, withdrawn, "Test %q", test.name)
+	}
+}
+
+func TestMessageCounters(t *testing.T) {
+	c := messageCounters{}
+	for _, msgType := range []uint8{packet.OpenMsg, packet.KeepaliveMsg, packet.KeepaliveMsg, packet.NotificationMsg, packet.RouteRefreshMsg, packet.UpdateMsg} {
+		c.count(msgType)
+	}
+
+	assert.Equal(t, metrics.BGPMessageCounts{
+		Opens:          1,
+		Keepalives:     2,
+		Notifications:  1,
+		RouteRefreshes: 1,
+	}, c.metrics())
+}
+
+func TestAttributeErrorCounters(t *testing.T) {
+	c := attributeErrorCounters{}
+	for _, a := range []packet.AttributeErrorAction{packet.TreatAsWithdraw, packet.AttributeDiscard, packet.AttributeDiscard, packet.SessionReset} {
+		c.count(a)
+	}
+
+	assert.Equal(t, metrics.BGPAttributeErrorCounts{
+		TreatAsWithdraw:  1,
+		AttributeDiscard: 2,
+		SessionReset:     1,
+	}, c.metrics())
+
+	c.reset()
+	assert.Equal(t, metrics.BGPAttributeErrorCounts{}, c.metrics())
+}
@@ -12,7 +12,7 @@ import (
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 type establishedState struct {
@@ -51,8 +51,18 @@ func (s establishedState) run() (state, string) {
 			return s.keepaliveTimerExpired()
 		case <-time.After(time.Second):
 			return s.checkHoldtimer()
+		case <-s.advertisementDeferral():
+			return s.startAdvertisement()
+		case direction := <-s.fsm.softResetCh:
+			return s.softReset(direction)
+		case <-s.fsm.addressFamiliesCh:
+			return s.addressFamiliesChanged()
 		case recvMsg := <-s.fsm.msgRecvCh:
 			return s.msgReceived(recvMsg, opt)
+		case u := <-s.fsm.injectCh:
+			return s.update(u, time.Now())
+		case err := <-s.fsm.msgRecvFailCh:
+			return s.tcpFailure(err)
 		}
 	}
 }
@@ -66,45 +76,159 @@ func (s *establishedState) checkHoldtimer() (state, string) {
 }
 
 func (s *establishedState) init() error {
+	n, err := s.neighbor()
+	if err != nil {
+		return err
+	}
+
+	s.skipEndOfRIBWait()
+	s.fsm.peer.reconnectBackoff.reset()
+
+	if s.fsm.ipv4Unicast != nil {
+		s.fsm.ipv4Unicast.init(n)
+	}
+
+	if s.fsm.ipv6Unicast != nil {
+		s.fsm.ipv6Unicast.init(n)
+	}
+
+	for _, f := range s.fsm.labeledUnicastAddressFamilies() {
+		if f.multiProtocol {
+			f.init(n)
+		}
+	}
+
+	for _, f := range s.fsm.vpnAddressFamilies() {
+		if f.negotiated {
+			f.init(n.LocalAddress)
+		}
+	}
+
+	if s.fsm.linkState != nil && s.fsm.linkState.negotiated {
+		s.fsm.linkState.init(n.LocalAddress)
+	}
+
+	if s.fsm.rtc.constrains() {
+		s.fsm.rtc.init(n.LocalAddress)
+	}
+
+	if s.fsm.flowSpec != nil && len(s.fsm.flowSpec.negotiated) > 0 {
+		s.fsm.flowSpec.init()
+	}
+
+	if s.fsm.srPolicy != nil && len(s.fsm.srPolicy.negotiated) > 0 {
+		s.fsm.srPolicy.init()
+	}
+
+	s.fsm.ribsInitialized = true
+	return nil
+}
+
+// neighbor gets the neighbor the RIBs of the session are connected to
+func (s *establishedState) neighbor() (*routingtable.Neighbor, error) {
 	host, _, err := net.SplitHostPort(s.fsm.con.LocalAddr().String())
 	if err != nil {
-		return errors.Wrap(err, "Unable to get local address")
+		return nil, errors.Wrap(err, "Unable to get local address")
 	}
 	localAddr, err := bnet.IPFromString(host)
 	if err != nil {
-		return errors.Wrap(err, "Unable to parse address")
+		return nil, errors.Wrap(err, "Unable to parse address")
 	}
 
 	n := &routingtable.Neighbor{
-		Type:                 route.BGPPathType,
-		Address:              s.fsm.peer.addr,
-		IBGP:                 s.fsm.peer.localASN == s.fsm.peer.peerASN,
-		LocalASN:             s.fsm.peer.localASN,
-		RouteServerClient:    s.fsm.peer.routeServerClient,
-		LocalAddress:         localAddr.Dedup(),
-		RouteReflectorClient: s.fsm.peer.routeReflectorClient,
-		ClusterID:            s.fsm.peer.clusterID,
+		Type:                       route.BGPPathType,
+		Address:                    s.fsm.peer.addr,
+		IBGP:                       s.fsm.peer.localASN == s.fsm.peer.peerASN,
+		LocalASN:                   s.fsm.peer.localASN,
+		PeerASN:                    s.fsm.peer.peerASN,
+		RemovePrivateAS:            s.fsm.peer.removePrivateAS,
+		ASOverride:                 s.fsm.peer.asOverride,
+		RouteServerClient:          s.fsm.peer.routeServerClient,
+		LocalAddress:               localAddr.Dedup(),
+		RouteReflectorClient:       s.fsm.peer.routeReflectorClient,
+		ClusterID:                  s.fsm.peer.clusterID,
+		NoClientToClientReflection: s.fsm.peer.noClientToClientReflection,
+		IgnoreWellKnownCommunities: s.fsm.peer.ignoreWellKnownCommunities,
+		BilateralPeer:              s.fsm.peer.bilateralPeer,
 	}
 
-	if s.fsm.ipv4Unicast != nil {
-		s.fsm.ipv4Unicast.init(n)
+	if m := s.fsm.peer.localASMigration; m != nil {
+		n.MigrationASN = m.ASN
+		n.ReplaceAS = m.ReplaceAS
 	}
 
-	if s.fsm.ipv6Unicast != nil {
-		s.fsm.ipv6Unicast.init(n)
+	return n, nil
+}
+
+// skipEndOfRIBWait stops a restarting server from waiting for End-of-RIB of peers that won't send it
+// or are restarting themselves (RFC4724 4.1)
+func (s *establishedState) skipEndOfRIBWait() {
+	r := s.fsm.restart()
+	if !s.fsm.gracefulRestart() || s.fsm.peerGracefulRestart.RestartState {
+		r.removePeer(s.fsm.peer.addr)
+		return
+	}
+
+	for _, f := range []*fsmAddressFamily{s.fsm.ipv4Unicast, s.fsm.ipv6Unicast} {
+		if f != nil && !f.gracefulRestart() {
+			r.endOfRIB(s.fsm.peer.addr, f.afi, f.safi)
+		}
+	}
+}
+
+// advertisementDeferral gets a channel closed when deferred advertisements may start. Returns nil if nothing is deferred.
+func (s *establishedState) advertisementDeferral() <-chan struct{} {
+	for _, f := range []*fsmAddressFamily{s.fsm.ipv4Unicast, s.fsm.ipv6Unicast, s.fsm.ipv4LabeledUnicast, s.fsm.ipv6LabeledUnicast} {
+		if f != nil && f.advertisementDeferred {
+			return s.fsm.restart().deferralDone()
+		}
 	}
 
-	s.fsm.ribsInitialized = true
 	return nil
 }
 
-func (s *establishedState) uninit() {
+func (s *establishedState) startAdvertisement() (state, string) {
+	for _, f := range []*fsmAddressFamily{s.fsm.ipv4Unicast, s.fsm.ipv6Unicast, s.fsm.ipv4LabeledUnicast, s.fsm.ipv6LabeledUnicast} {
+		if f != nil && f.advertisementDeferred {
+			f.startAdvertisement()
+		}
+	}
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
+
+// uninit tears down the RIBs. If retainStale is set the paths of the peer are kept for graceful restart.
+func (s *establishedState) uninit(retainStale bool) {
 	if s.fsm.ipv4Unicast != nil {
-		s.fsm.ipv4Unicast.dispose()
+		s.fsm.ipv4Unicast.dispose(retainStale)
 	}
 
 	if s.fsm.ipv6Unicast != nil {
-		s.fsm.ipv6Unicast.dispose()
+		s.fsm.ipv6Unicast.dispose(retainStale)
+	}
+
+	for _, f := range s.fsm.labeledUnicastAddressFamilies() {
+		f.dispose(false)
+	}
+
+	for _, f := range s.fsm.vpnAddressFamilies() {
+		f.dispose()
+	}
+
+	if s.fsm.linkState != nil {
+		s.fsm.linkState.dispose()
+	}
+
+	if s.fsm.rtc != nil {
+		s.fsm.rtc.dispose()
+	}
+
+	if s.fsm.flowSpec != nil {
+		s.fsm.flowSpec.dispose()
+	}
+
+	if s.fsm.srPolicy != nil {
+		s.fsm.srPolicy.dispose()
 	}
 
 	s.fsm.counters.reset()
@@ -113,8 +237,8 @@ func (s *establishedState) uninit() {
 }
 
 func (s *establishedState) manualStop() (state, string) {
-	s.fsm.sendNotification(packet.Cease, 0)
-	s.uninit()
+	s.fsm.sendNotificationMsg(s.fsm.peer.ceaseNotification())
+	s.uninit(false)
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	s.fsm.connectRetryCounter = 0
@@ -123,7 +247,7 @@ func (s *establishedState) manualStop() (state, string) {
 
 func (s *establishedState) automaticStop() (state, string) {
 	s.fsm.sendNotification(packet.Cease, 0)
-	s.uninit()
+	s.uninit(false)
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	s.fsm.connectRetryCounter++
@@ -132,24 +256,55 @@ func (s *establishedState) automaticStop() (state, string) {
 
 func (s *establishedState) cease() (state, string) {
 	s.fsm.sendNotification(packet.Cease, 0)
-	s.uninit()
+	s.uninit(false)
 	s.fsm.con.Close()
 	return newCeaseState(), "Cease"
 }
 
 func (s *establishedState) holdTimerExpired() (state, string) {
 	s.fsm.sendNotification(packet.HoldTimeExpired, 0)
-	s.uninit()
+	s.uninit(false)
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	s.fsm.connectRetryCounter++
 	return newIdleState(s.fsm), "Holdtimer expired"
 }
 
+// tcpFailure handles the loss of the TCP connection. Paths are retained if graceful restart was negotiated.
+func (s *establishedState) tcpFailure(err error) (state, string) {
+	s.uninit(true)
+	stopTimer(s.fsm.connectRetryTimer)
+	s.fsm.con.Close()
+	s.fsm.connectRetryCounter++
+	return newIdleState(s.fsm), fmt.Sprintf("TCP connection failure: %v", err)
+}
+
+// prefixLimitExceeded gets the first address family exceeding its prefix limit
+func (s *establishedState) prefixLimitExceeded() *fsmAddressFamily {
+	for _, f := range []*fsmAddressFamily{s.fsm.ipv4Unicast, s.fsm.ipv6Unicast, s.fsm.ipv4LabeledUnicast, s.fsm.ipv6LabeledUnicast} {
+		if f != nil && f.checkPrefixLimit() {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// maxPrefixReached tears down the session after the prefix limit of f has been exceeded (RFC4486)
+func (s *establishedState) maxPrefixReached(f *fsmAddressFamily) (state, string) {
+	s.fsm.peer.holdForPrefixLimit(f.prefixLimit.RestartInterval)
+	s.fsm.sendNotification(packet.Cease, packet.MaxPrefReached)
+	s.uninit(false)
+	stopTimer(s.fsm.connectRetryTimer)
+	s.fsm.con.Close()
+	s.fsm.connectRetryCounter++
+	return newIdleState(s.fsm), fmt.Sprintf("Prefix limit of %d exceeded for AFI %d SAFI %d", f.prefixLimit.Max, f.afi, f.safi)
+}
+
 func (s *establishedState) keepaliveTimerExpired() (state, string) {
 	err := s.fsm.sendKeepalive()
 	if err != nil {
-		s.uninit()
+		s.uninit(true)
 		stopTimer(s.fsm.connectRetryTimer)
 		s.fsm.con.Close()
 		s.fsm.connectRetryCounter++
@@ -161,11 +316,20 @@ func (s *establishedState) keepaliveTimerExpired() (state, string) {
 }
 
 func (s *establishedState) msgReceived(data []byte, opt *packet.DecodeOptions) (state, string) {
+	received := time.Now()
 	msg, err := packet.Decode(bytes.NewBuffer(data), opt)
 	if err != nil {
-		switch bgperr := err.(type) {
+		switch bgperr := errors.Cause(err).(type) {
 		case packet.BGPError:
-			s.fsm.sendNotification(bgperr.ErrorCode, bgperr.ErrorSubCode)
+			if bgperr.ErrorCode == packet.UpdateMessageError && bgperr.ErrorSubCode != packet.InvalidNetworkField {
+				atomic.AddUint64(&s.fsm.peer.counters.malformedAttributes, 1)
+				s.fsm.peer.counters.attributeErrors.count(packet.SessionReset)
+			}
+			s.fsm.sendNotificationMsg(&packet.BGPNotification{
+				ErrorCode:    bgperr.ErrorCode,
+				ErrorSubcode: bgperr.ErrorSubCode,
+				Data:         bgperr.Data,
+			})
 		}
 		stopTimer(s.fsm.connectRetryTimer)
 		if s.fsm.con != nil {
@@ -175,14 +339,24 @@ func (s *establishedState) msgReceived(data []byte, opt *packet.DecodeOptions) (
 		return newIdleState(s.fsm), fmt.Sprintf("Failed to decode BGP message: %v", err)
 	}
 
+	s.fsm.debugMessage(msg)
+
 	switch msg.Header.Type {
 	case packet.NotificationMsg:
-		fmt.Println(data)
+		s.fsm.notificationReceived = bgpMessage(data)
+		s.fsm.notificationReceivedEvent(msg.Body.(*packet.BGPNotification))
 		return s.notification()
 	case packet.UpdateMsg:
-		return s.update(msg.Body.(*packet.BGPUpdate))
+		raw := bgpMessage(data)
+		s.fsm.bmp().routeMonitoring(s.fsm, raw)
+		s.fsm.mrt().update(s.fsm, raw)
+		return s.update(msg.Body.(*packet.BGPUpdate), received)
 	case packet.KeepaliveMsg:
 		return s.keepaliveReceived()
+	case packet.RouteRefreshMsg:
+		return s.routeRefresh(msg.Body.(*packet.BGPRouteRefresh))
+	case packet.CapabilityMsg:
+		return s.capability(msg.Body.(*packet.BGPCapability))
 	default:
 		return s.unexpectedMessage()
 	}
@@ -190,25 +364,52 @@ func (s *establishedState) msgReceived(data []byte, opt *packet.DecodeOptions) (
 
 func (s *establishedState) notification() (state, string) {
 	stopTimer(s.fsm.connectRetryTimer)
-	s.uninit()
+	s.uninit(false)
 	s.fsm.con.Close()
 	s.fsm.connectRetryCounter++
 	return newIdleState(s.fsm), "Received NOTIFICATION"
 }
 
-func (s *establishedState) update(u *packet.BGPUpdate) (state, string) {
+func (s *establishedState) update(u *packet.BGPUpdate, received time.Time) (state, string) {
 	atomic.AddUint64(&s.fsm.counters.updatesReceived, 1)
+	s.fsm.peer.counters.updateReceived(u)
+	s.attributeErrors(u)
 
 	if s.fsm.holdTime != 0 {
 		s.fsm.updateLastUpdateOrKeepalive()
 	}
 
-	if s.fsm.ipv4Unicast != nil {
-		s.fsm.ipv4Unicast.processUpdate(u)
+	if afi, safi, ok := u.IsEndOfRIB(); ok {
+		return s.endOfRIB(afi, safi)
 	}
 
-	if s.fsm.ipv6Unicast != nil {
-		s.fsm.ipv6Unicast.processUpdate(u)
+	for _, f := range s.fsm.initializedAddressFamilies() {
+		f.processUpdate(u)
+	}
+
+	for _, f := range s.fsm.vpnAddressFamilies() {
+		if f.initialized {
+			f.processUpdate(u)
+		}
+	}
+
+	if s.fsm.rtc != nil && s.fsm.rtc.initialized {
+		s.fsm.rtc.processUpdate(u)
+	}
+
+	if s.fsm.flowSpec != nil && s.fsm.flowSpec.initialized {
+		s.fsm.flowSpec.processUpdate(u)
+	}
+
+	if s.fsm.srPolicy != nil && s.fsm.srPolicy.initialized {
+		s.fsm.srPolicy.processUpdate(u)
+	}
+
+	// RIB propagation is synchronous, so at this point Loc-RIB, FIB and adj-RIBs-out have been updated
+	s.fsm.peer.counters.ribLatency.Observe(time.Since(received))
+
+	if f := s.prefixLimitExceeded(); f != nil {
+		return s.maxPrefixReached(f)
 	}
 
 	afi, safi := s.updateAddressFamily(u)
@@ -234,6 +435,32 @@ func (s *establishedState) update(u *packet.BGPUpdate) (state, string) {
 	return newEstablishedState(s.fsm), s.fsm.reason
 }
 
+// attributeErrors counts and logs the malformed attributes handled according to RFC7606
+func (s *establishedState) attributeErrors(u *packet.BGPUpdate) {
+	if len(u.AttributeErrors) == 0 {
+		return
+	}
+
+	atomic.AddUint64(&s.fsm.peer.counters.malformedAttributes, 1)
+	for _, e := range u.AttributeErrors {
+		s.fsm.peer.counters.attributeErrors.count(e.Action)
+		log.WithFields(logrus.Fields{
+			"peer":      s.fsm.peer.addr.String(),
+			"attribute": e.TypeCode,
+			"action":    e.Action.String(),
+		}).Warnf("Received malformed path attribute: %v", e.Err)
+	}
+}
+
+func (s *establishedState) endOfRIB(afi uint16, safi uint8) (state, string) {
+	f := s.fsm.addressFamily(afi, safi)
+	if f != nil && f.initialized {
+		f.endOfRIB()
+	}
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
+
 func (s *establishedState) updateAddressFamily(u *packet.BGPUpdate) (afi uint16, safi uint8) {
 	if u.WithdrawnRoutes != nil || u.NLRI != nil {
 		return packet.IPv4AFI, packet.UnicastSAFI
@@ -263,7 +490,7 @@ func (s *establishedState) keepaliveReceived() (state, string) {
 
 func (s *establishedState) unexpectedMessage() (state, string) {
 	s.fsm.sendNotification(packet.FiniteStateMachineError, 0)
-	s.uninit()
+	s.uninit(false)
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	s.fsm.connectRetryCounter++
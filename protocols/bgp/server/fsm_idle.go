@@ -17,8 +17,13 @@ func newIdleState(fsm *FSM) *idleState {
 
 func (s idleState) run() (state, string) {
 	if s.fsm.peer.reconnectInterval != 0 {
-		time.Sleep(s.fsm.peer.reconnectInterval)
-		go s.fsm.activate()
+		// The interval grows with each failed attempt to avoid all peers reconnecting at once, e.g. after a restart
+		time.Sleep(s.fsm.peer.reconnectDelay())
+
+		// Sessions held down after exceeding a prefix limit or shut down administratively are restarted when released
+		if !s.fsm.peer.heldForPrefixLimit() && !s.fsm.peer.admin.isDown() {
+			go s.fsm.activate()
+		}
 	}
 	for {
 		event := <-s.fsm.eventCh
@@ -51,7 +51,7 @@ func (s *openConfirmState) checkHoldtimer() (state, string) {
 }
 
 func (s *openConfirmState) manualStop() (state, string) {
-	s.fsm.sendNotification(packet.Cease, 0)
+	s.fsm.sendNotificationMsg(s.fsm.peer.ceaseNotification())
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	s.fsm.resetConnectRetryCounter()
@@ -104,6 +104,8 @@ func (s *openConfirmState) msgReceived(data []byte, opt *packet.DecodeOptions) (
 		s.fsm.connectRetryCounter++
 		return newIdleState(s.fsm), fmt.Sprintf("Failed to decode BGP message: %v", err)
 	}
+
+	s.fsm.debugMessage(msg)
 	switch msg.Header.Type {
 	case packet.NotificationMsg:
 		return s.notification(msg)
@@ -118,6 +120,7 @@ func (s *openConfirmState) notification(msg *packet.BGPMessage) (state, string)
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	nMsg := msg.Body.(*packet.BGPNotification)
+	s.fsm.notificationReceivedEvent(nMsg)
 	if nMsg.ErrorCode != packet.UnsupportedVersionNumber {
 		s.fsm.connectRetryCounter++
 	}
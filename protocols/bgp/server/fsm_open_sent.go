@@ -55,7 +55,7 @@ func (s *openSentState) checkHoldtimer() (state, string) {
 }
 
 func (s *openSentState) manualStop() (state, string) {
-	s.fsm.sendNotification(packet.Cease, 0)
+	s.fsm.sendNotificationMsg(s.fsm.peer.ceaseNotification())
 	s.fsm.resetConnectRetryTimer()
 	s.fsm.con.Close()
 	s.fsm.resetConnectRetryCounter()
@@ -96,10 +96,13 @@ func (s *openSentState) msgReceived(data []byte, opt *packet.DecodeOptions) (sta
 		s.fsm.connectRetryCounter++
 		return newIdleState(s.fsm), fmt.Sprintf("Failed to decode BGP message: %v", err)
 	}
+
+	s.fsm.debugMessage(msg)
 	switch msg.Header.Type {
 	case packet.NotificationMsg:
 		return s.notification(msg)
 	case packet.OpenMsg:
+		s.fsm.receivedOpen = bgpMessage(data)
 		return s.openMsgReceived(msg.Body.(*packet.BGPOpen))
 	default:
 		return s.unexpectedMessage()
@@ -144,6 +147,13 @@ func (s *openSentState) handleOpenMessage(openMsg *packet.BGPOpen) (state, strin
 	}
 
 	s.peerASNRcvd = uint32(openMsg.ASN)
+	s.fsm.peerGracefulRestart = nil
+	s.fsm.routeRefresh = false
+	s.fsm.enhancedRouteRefresh = false
+	s.fsm.dynamicCapability = false
+	s.fsm.extendedNextHop = false
+	s.fsm.peerAddressFamilies = make(map[addressFamilyKey]struct{})
+	s.fsm.syncAddressFamilies()
 	s.processOpenOptions(openMsg.OptParams)
 
 	if s.peerASNRcvd != s.fsm.peer.peerASN {
@@ -184,15 +194,67 @@ func (s *openSentState) processCapability(cap packet.Capability) {
 		s.processASN4Capability(cap.Value.(packet.ASN4Capability))
 	case packet.MultiProtocolCapabilityCode:
 		s.processMultiProtocolCapability(cap.Value.(packet.MultiProtocolCapability))
+	case packet.GracefulRestartCapabilityCode:
+		grCap := cap.Value.(packet.GracefulRestartCapability)
+		s.fsm.peerGracefulRestart = &grCap
+	case packet.ExtendedNextHopCapabilityCode:
+		s.processExtendedNextHopCapability(cap.Value.(packet.ExtendedNextHopCapability))
+	case packet.RouteRefreshCapabilityCode:
+		s.fsm.routeRefresh = true
+	case packet.EnhancedRouteRefreshCapabilityCode:
+		s.fsm.enhancedRouteRefresh = true
+	case packet.DynamicCapabilityCode:
+		dynCap := cap.Value.(packet.DynamicCapability)
+		s.fsm.dynamicCapability = s.fsm.peer.config != nil && s.fsm.peer.config.DynamicCapability && dynCap.Supports(packet.MultiProtocolCapabilityCode)
 	}
 }
 
 func (s *openSentState) processMultiProtocolCapability(cap packet.MultiProtocolCapability) {
-	if cap.SAFI != packet.UnicastSAFI {
+	s.fsm.peerAddressFamilies[addressFamilyKey{afi: cap.AFI, safi: cap.SAFI}] = struct{}{}
+
+	if cap.AFI == packet.LinkStateAFI && cap.SAFI == packet.LinkStateSAFI {
+		if s.fsm.linkState != nil {
+			s.fsm.linkState.negotiated = true
+		}
+
 		return
 	}
 
-	if cap.AFI == packet.IPv4AFI && !s.fsm.peer.ipv4MultiProtocolAdvertised {
+	if cap.AFI == packet.IPv4AFI && cap.SAFI == packet.RouteTargetConstraintSAFI {
+		if s.fsm.rtc != nil {
+			s.fsm.rtc.negotiated = true
+		}
+
+		return
+	}
+
+	if cap.SAFI == packet.FlowSpecSAFI {
+		if s.fsm.flowSpec != nil {
+			s.fsm.flowSpec.negotiate(cap.AFI)
+		}
+
+		return
+	}
+
+	if cap.SAFI == packet.SRPolicySAFI {
+		if s.fsm.srPolicy != nil {
+			s.fsm.srPolicy.negotiate(cap.AFI)
+		}
+
+		return
+	}
+
+	if cap.SAFI == packet.MPLSVPNSAFI {
+		for _, f := range s.fsm.vpnAddressFamilies() {
+			if f.afi == cap.AFI {
+				f.negotiated = true
+			}
+		}
+
+		return
+	}
+
+	if cap.SAFI == packet.UnicastSAFI && cap.AFI == packet.IPv4AFI && !s.fsm.peer.ipv4MultiProtocolAdvertised {
 		return
 	}
 
@@ -204,10 +266,6 @@ func (s *openSentState) processMultiProtocolCapability(cap packet.MultiProtocolC
 
 func (s *openSentState) processAddPathCapability(addPathCap packet.AddPathCapability) {
 	for _, addPathCapTuple := range addPathCap {
-		if addPathCapTuple.SAFI != packet.UnicastSAFI {
-			continue
-		}
-
 		f := s.fsm.addressFamily(addPathCapTuple.AFI, addPathCapTuple.SAFI)
 		if f == nil {
 			continue
@@ -248,6 +306,7 @@ func (s *openSentState) notification(msg *packet.BGPMessage) (state, string) {
 	stopTimer(s.fsm.connectRetryTimer)
 	s.fsm.con.Close()
 	nMsg := msg.Body.(*packet.BGPNotification)
+	s.fsm.notificationReceivedEvent(nMsg)
 	if nMsg.ErrorCode != packet.UnsupportedVersionNumber {
 		s.fsm.connectRetryCounter++
 	}
@@ -222,10 +222,10 @@ func TestFSM255UpdatesIPv6(t *testing.T) {
 	for i := uint8(0); i < 255; i++ {
 		update := []byte{
 			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
-			0x00, 35, // Length
+			0x00, 39, // Length
 			0x02,       // UPDATE
 			0x00, 0x00, // withdrawn routes
-			0x00, 0x0c,
+			0x00, 0x10,
 			0x90, 0x0f,
 			0x00, 12, // Length
 			0x00, 0x02, // AFI
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultGracefulRestartTime is the default restart time advertised to peers
+	DefaultGracefulRestartTime = 120 * time.Second
+
+	// DefaultStalePathTime is the default time stale paths are kept after a peer re-established its session
+	DefaultStalePathTime = 360 * time.Second
+
+	// DefaultSelectionDeferralTime is the default time advertisements are deferred after a restart
+	DefaultSelectionDeferralTime = 360 * time.Second
+)
+
+// GracefulRestartConfig configures graceful restart for a peer (RFC4724)
+type GracefulRestartConfig struct {
+	// RestartTime is advertised to the peer as the time it should wait for us to come back after a restart
+	RestartTime time.Duration
+
+	// StalePathTime is the time paths of a restarted peer are kept after re-establishment when it doesn't send End-of-RIB
+	StalePathTime time.Duration
+}
+
+func (c *GracefulRestartConfig) restartTime() uint16 {
+	t := c.RestartTime
+	if t == 0 {
+		t = DefaultGracefulRestartTime
+	}
+
+	if t > packet.MaxGracefulRestartTime*time.Second {
+		return packet.MaxGracefulRestartTime
+	}
+
+	return uint16(t / time.Second)
+}
+
+func (c *GracefulRestartConfig) stalePathTime() time.Duration {
+	if c.StalePathTime == 0 {
+		return DefaultStalePathTime
+	}
+
+	return c.StalePathTime
+}
+
+// staleRIB is the adj-RIB-in of a peer whose session went down while graceful restart was negotiated.
+// Its paths stay in the Loc-RIB until the peer comes back or its restart time expires.
+type staleRIB struct {
+	adjRIBIn  *adjRIBIn.AdjRIBIn
+	addPathRX bool
+	timer     *time.Timer
+}
+
+// retainStale keeps the paths of a lost session as stale for up to restartTime (RFC4724 4.2)
+func (f *peerAddressFamily) retainStale(p *peer, a *adjRIBIn.AdjRIBIn, addPathRX bool, restartTime time.Duration) {
+	f.staleMu.Lock()
+	defer f.staleMu.Unlock()
+
+	a.MarkStale()
+	f.stale = &staleRIB{
+		adjRIBIn:  a,
+		addPathRX: addPathRX,
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":         p.addr.String(),
+		"stale_paths":  a.StaleCount(),
+		"restart_time": restartTime,
+	}).Info("Retaining paths of restarting peer")
+
+	stale := f.stale
+	stale.timer = time.AfterFunc(restartTime, func() {
+		f.staleMu.Lock()
+		defer f.staleMu.Unlock()
+
+		if f.stale != stale {
+			return
+		}
+
+		log.WithField("peer", p.addr.String()).Info("Restart time expired, removing stale paths")
+		f.flushStaleLocked()
+	})
+}
+
+// takeStale hands the retained adj-RIB-in over to a re-established session. Returns nil if there is none or it can't be reused.
+func (f *peerAddressFamily) takeStale(addPathRX bool) *adjRIBIn.AdjRIBIn {
+	f.staleMu.Lock()
+	defer f.staleMu.Unlock()
+
+	if f.stale == nil {
+		return nil
+	}
+
+	if f.stale.addPathRX != addPathRX {
+		f.flushStaleLocked()
+		return nil
+	}
+
+	f.stale.timer.Stop()
+	a := f.stale.adjRIBIn
+	f.stale = nil
+
+	return a
+}
+
+// flushStale removes all retained paths
+func (f *peerAddressFamily) flushStale() {
+	f.staleMu.Lock()
+	defer f.staleMu.Unlock()
+
+	f.flushStaleLocked()
+}
+
+func (f *peerAddressFamily) flushStaleLocked() {
+	if f.stale == nil {
+		return
+	}
+
+	f.stale.timer.Stop()
+	f.stale.adjRIBIn.RemoveStale()
+	f.stale.adjRIBIn.Unregister(f.ribClient())
+	f.stale = nil
+}
+
+// restartState tracks a restart of the local speaker (RFC4724 4.1). Advertisements to peers are deferred
+// until all graceful restart capable peers sent End-of-RIB or the selection deferral timer expired.
+type restartState struct {
+	forwardingStatePreserved bool
+
+	mu      sync.Mutex
+	pending map[restartKey]struct{}
+	done    chan struct{}
+	timer   *time.Timer
+}
+
+type restartKey struct {
+	peer bnet.IP
+	afi  uint16
+	safi uint8
+}
+
+func newRestartState(selectionDeferralTime time.Duration, forwardingStatePreserved bool) *restartState {
+	r := &restartState{
+		forwardingStatePreserved: forwardingStatePreserved,
+		pending:                  make(map[restartKey]struct{}),
+		done:                     make(chan struct{}),
+	}
+
+	r.timer = time.AfterFunc(selectionDeferralTime, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.completeLocked("Selection deferral timer expired")
+	})
+
+	return r
+}
+
+// restarting returns if the restart is still in progress. It is safe to call restarting on a nil restartState.
+func (r *restartState) restarting() bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) == 0 {
+		r.completeLocked("No End-of-RIB pending")
+	}
+
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// deferralDone gets a channel closed once advertisements may start. Returns nil on a nil restartState.
+func (r *restartState) deferralDone() <-chan struct{} {
+	if r == nil {
+		return nil
+	}
+
+	return r.done
+}
+
+// wait registers an address family of a peer End-of-RIB is expected from
+func (r *restartState) wait(peer *bnet.IP, afi uint16, safi uint8) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+
+	r.pending[restartKey{peer: *peer, afi: afi, safi: safi}] = struct{}{}
+}
+
+// endOfRIB marks an address family of a peer as converged
+func (r *restartState) endOfRIB(peer *bnet.IP, afi uint16, safi uint8) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.pending, restartKey{peer: *peer, afi: afi, safi: safi})
+	if len(r.pending) == 0 {
+		r.completeLocked("Received End-of-RIB from all peers")
+	}
+}
+
+// removePeer stops waiting for End-of-RIB from a peer
+func (r *restartState) removePeer(peer *bnet.IP) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.pending {
+		if k.peer == *peer {
+			delete(r.pending, k)
+		}
+	}
+
+	if len(r.pending) == 0 {
+		r.completeLocked("Received End-of-RIB from all peers")
+	}
+}
+
+func (r *restartState) completeLocked(reason string) {
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+
+	r.timer.Stop()
+	r.pending = nil
+	close(r.done)
+	log.Infof("Graceful restart completed: %s", reason)
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func grCapability(restartTime uint16, forwardingState bool) *packet.GracefulRestartCapability {
+	return &packet.GracefulRestartCapability{
+		RestartTime: restartTime,
+		Tuples: []packet.GracefulRestartCapabilityTuple{
+			{
+				AFI:             packet.IPv4AFI,
+				SAFI:            packet.UnicastSAFI,
+				ForwardingState: forwardingState,
+			},
+		},
+	}
+}
+
+func grTestUpdate(pfxs ...*bnet.Prefix) *packet.BGPUpdate {
+	u := &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.OriginAttr,
+			Value:    uint8(0),
+			Next: &packet.PathAttribute{
+				TypeCode: packet.NextHopAttr,
+				Value:    bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+			},
+		},
+	}
+
+	for _, pfx := range pfxs {
+		u.NLRI = &packet.NLRI{
+			Prefix: pfx,
+			Next:   u.NLRI,
+		}
+	}
+
+	return u
+}
+
+func grTestSession(p *peer, peerCap *packet.GracefulRestartCapability) *establishedState {
+	fsm := newFSM(p)
+	fsm.con = fakeConn{}
+	fsm.peerGracefulRestart = peerCap
+	fsm.connectRetryTimer = time.NewTimer(time.Minute)
+	s := newEstablishedState(fsm)
+	s.init()
+
+	return s
+}
+
+func waitForRouteCount(rib *locRIB.LocRIB, expected int64) bool {
+	for i := 0; i < 200; i++ {
+		if rib.RouteCount() == expected {
+			return true
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestGracefulRestartHelper(t *testing.T) {
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(11, 0, 0, 0), 8).Ptr()
+
+	tests := []struct {
+		name                   string
+		localConfig            *GracefulRestartConfig
+		peerCap                *packet.GracefulRestartCapability
+		reconnectCap           *packet.GracefulRestartCapability
+		expectedAfterLoss      int64
+		expectedAfterReconnect int64
+		expectedAfterEndOfRIB  int64
+	}{
+		{
+			name:                   "Forwarding state preserved",
+			localConfig:            &GracefulRestartConfig{},
+			peerCap:                grCapability(120, false),
+			reconnectCap:           grCapability(120, true),
+			expectedAfterLoss:      2,
+			expectedAfterReconnect: 2,
+			expectedAfterEndOfRIB:  1,
+		},
+		{
+			name:                   "Forwarding state not preserved",
+			localConfig:            &GracefulRestartConfig{},
+			peerCap:                grCapability(120, false),
+			reconnectCap:           grCapability(120, false),
+			expectedAfterLoss:      2,
+			expectedAfterReconnect: 0,
+			expectedAfterEndOfRIB:  1,
+		},
+		{
+			name:                   "Peer without graceful restart after reconnect",
+			localConfig:            &GracefulRestartConfig{},
+			peerCap:                grCapability(120, false),
+			expectedAfterLoss:      2,
+			expectedAfterReconnect: 0,
+			expectedAfterEndOfRIB:  1,
+		},
+		{
+			name:                   "Restart time expired",
+			localConfig:            &GracefulRestartConfig{},
+			peerCap:                grCapability(0, false),
+			reconnectCap:           grCapability(120, true),
+			expectedAfterLoss:      0,
+			expectedAfterReconnect: 0,
+			expectedAfterEndOfRIB:  1,
+		},
+		{
+			name:                   "Not negotiated",
+			peerCap:                grCapability(120, false),
+			reconnectCap:           grCapability(120, true),
+			expectedAfterLoss:      0,
+			expectedAfterReconnect: 0,
+			expectedAfterEndOfRIB:  1,
+		},
+	}
+
+	for _, test := range tests {
+		rib := locRIB.New("inet.0")
+		p := &peer{
+			addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+			routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+			config: &PeerConfig{
+				GracefulRestart: test.localConfig,
+			},
+			ipv4: &peerAddressFamily{
+				rib:               rib,
+				importFilterChain: filter.NewAcceptAllFilterChain(),
+				exportFilterChain: filter.NewAcceptAllFilterChain(),
+			},
+		}
+
+		s := grTestSession(p, test.peerCap)
+		s.update(grTestUpdate(pfxA, pfxB), time.Now())
+		assert.Equalf(t, int64(2), rib.RouteCount(), "Test %q", test.name)
+
+		s.tcpFailure(fmt.Errorf("connection reset"))
+		assert.Truef(t, waitForRouteCount(rib, test.expectedAfterLoss), "Test %q: unexpected route count after session loss: %d", test.name, rib.RouteCount())
+
+		s = grTestSession(p, test.reconnectCap)
+		assert.Equalf(t, test.expectedAfterReconnect, rib.RouteCount(), "Test %q: unexpected route count after reconnect", test.name)
+
+		s.update(grTestUpdate(pfxA), time.Now())
+		s.update(packet.EndOfRIB(packet.IPv4AFI, packet.UnicastSAFI), time.Now())
+		assert.Equalf(t, test.expectedAfterEndOfRIB, rib.RouteCount(), "Test %q: unexpected route count after End-of-RIB", test.name)
+		assert.Truef(t, rib.ContainsPfxPath(pfxA, rib.Dump()[0].Paths()[0]), "Test %q: refreshed path missing", test.name)
+
+		s.manualStop()
+		assert.Equalf(t, int64(0), rib.RouteCount(), "Test %q: unexpected route count after stop", test.name)
+	}
+}
+
+func TestRestartState(t *testing.T) {
+	peerA := bnet.IPv4FromOctets(10, 0, 0, 1).Ptr()
+	peerB := bnet.IPv4FromOctets(10, 0, 0, 2).Ptr()
+
+	tests := []struct {
+		name               string
+		deferral           time.Duration
+		wait               []*bnet.IP
+		endOfRIB           []*bnet.IP
+		removed            []*bnet.IP
+		expectedRestarting bool
+	}{
+		{
+			name:               "Nothing to wait for",
+			deferral:           time.Minute,
+			expectedRestarting: false,
+		},
+		{
+			name:               "Waiting for one peer",
+			deferral:           time.Minute,
+			wait:               []*bnet.IP{peerA, peerB},
+			endOfRIB:           []*bnet.IP{peerA},
+			expectedRestarting: true,
+		},
+		{
+			name:               "End-of-RIB from all peers",
+			deferral:           time.Minute,
+			wait:               []*bnet.IP{peerA, peerB},
+			endOfRIB:           []*bnet.IP{peerA},
+			removed:            []*bnet.IP{peerB},
+			expectedRestarting: false,
+		},
+		{
+			name:               "Selection deferral timer expired",
+			deferral:           time.Millisecond,
+			wait:               []*bnet.IP{peerA},
+			expectedRestarting: false,
+		},
+	}
+
+	for _, test := range tests {
+		r := newRestartState(test.deferral, true)
+		for _, p := range test.wait {
+			r.wait(p, packet.IPv4AFI, packet.UnicastSAFI)
+		}
+
+		for _, p := range test.endOfRIB {
+			r.endOfRIB(p, packet.IPv4AFI, packet.UnicastSAFI)
+		}
+
+		for _, p := range test.removed {
+			r.removePeer(p)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equalf(t, test.expectedRestarting, r.restarting(), "Test %q", test.name)
+	}
+}
+
+func TestGracefulRestartCapability(t *testing.T) {
+	p := &peer{
+		server: &bgpServer{
+			restart: newRestartState(time.Minute, true),
+		},
+		config: &PeerConfig{
+			GracefulRestart: &GracefulRestartConfig{
+				RestartTime: time.Hour * 2,
+			},
+		},
+		ipv6: &peerAddressFamily{},
+	}
+	p.server.restart.wait(bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(), packet.IPv6AFI, packet.UnicastSAFI)
+
+	assert.Equal(t, packet.Capability{
+		Code: packet.GracefulRestartCapabilityCode,
+		Value: packet.GracefulRestartCapability{
+			RestartState: true,
+			RestartTime:  packet.MaxGracefulRestartTime,
+			Tuples: []packet.GracefulRestartCapabilityTuple{
+				{
+					AFI:             packet.IPv6AFI,
+					SAFI:            packet.UnicastSAFI,
+					ForwardingState: true,
+				},
+			},
+		},
+	}, p.gracefulRestartCapability())
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+)
+
+// gracefulShutdownLocalPref is the local preference of paths drained by graceful shutdown (RFC8326 4.)
+const gracefulShutdownLocalPref = 0
+
+var (
+	// gracefulShutdownImportFilter lowers the local preference of paths carrying the graceful shutdown community
+	gracefulShutdownImportFilter = filter.NewFilter("graceful-shutdown", []*filter.Term{
+		filter.NewTerm("graceful-shutdown-community", []*filter.TermCondition{
+			filter.NewTermConditionWithCommunityFilters(filter.NewCommunityFilter(types.WellKnownCommunityGracefulShutdown)),
+		}, []actions.Action{
+			actions.NewSetLocalPrefAction(gracefulShutdownLocalPref),
+		}),
+	})
+
+	// maintenanceImportFilter lowers the local preference of all paths received from a peer in maintenance mode
+	maintenanceImportFilter = filter.NewFilter("maintenance-import", []*filter.Term{
+		filter.NewTerm("maintenance", nil, []actions.Action{
+			actions.NewSetLocalPrefAction(gracefulShutdownLocalPref),
+		}),
+	})
+
+	// maintenanceExportFilter attaches the graceful shutdown community to all paths sent to a peer in maintenance mode
+	maintenanceExportFilter = filter.NewFilter("maintenance-export", []*filter.Term{
+		filter.NewTerm("maintenance", nil, []actions.Action{
+			actions.NewAddCommunityAction(&types.Communities{types.WellKnownCommunityGracefulShutdown}),
+		}),
+	})
+)
+
+// maintenanceMode drains traffic from a peer before it is shut down (RFC8326)
+type maintenanceMode struct {
+	enabled bool
+	mu      sync.Mutex
+}
+
+func (m *maintenanceMode) set(enabled bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := m.enabled != enabled
+	m.enabled = enabled
+	return changed
+}
+
+func (m *maintenanceMode) isEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.enabled
+}
+
+// SetGracefulShutdown enables or disables the maintenance mode of a peer. In maintenance mode all paths sent to the peer
+// carry the graceful shutdown community and all paths received from it get the lowest local preference.
+func (b *bgpServer) SetGracefulShutdown(addr *bnet.IP, enabled bool) error {
+	p := b.peers.get(addr)
+	if p == nil {
+		return fmt.Errorf("peer %s not found", addr.String())
+	}
+
+	if !p.maintenance.set(enabled) {
+		return nil
+	}
+
+	p.refreshFilterChains()
+	return nil
+}
+
+// refreshFilterChains reapplies the import and export filter chains after the maintenance mode has changed
+func (p *peer) refreshFilterChains() {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	for _, fsm := range p.fsms {
+		for _, f := range fsm.initializedAddressFamilies() {
+			f.refreshFilterChains()
+		}
+	}
+}
+
+// effectiveImportFilterChain gets the import filter chain preceded by the graceful shutdown filters
+func (f *fsmAddressFamily) effectiveImportFilterChain() filter.Chain {
+	c := filter.Chain{gracefulShutdownImportFilter}
+	if f.fsm.peer.maintenance.isEnabled() || f.fsm.peer.admin.isDraining() {
+		c = append(c, maintenanceImportFilter)
+	}
+
+	return append(c, f.importFilterChain...)
+}
+
+// effectiveExportFilterChain gets the export filter chain preceded by the drain filter if the peer is being shut down,
+// the maintenance filter if the peer is in maintenance mode and the filter suppressing more specifics of summary only aggregates
+func (f *fsmAddressFamily) effectiveExportFilterChain() filter.Chain {
+	c := filter.Chain{}
+	if f.fsm.peer.admin.isDraining() {
+		c = append(c, drainExportFilter)
+	}
+
+	if f.fsm.peer.maintenance.isEnabled() {
+		c = append(c, maintenanceExportFilter)
+	}
+
+	if s := f.fsm.peer.server.aggregateSuppression(f.rib); s != nil {
+		c = append(c, s)
+	}
+
+	if len(c) == 0 {
+		return f.exportFilterChain
+	}
+
+	return append(c, f.exportFilterChain...)
+}
+
+func (f *fsmAddressFamily) refreshFilterChains() {
+	f.adjRIBIn.ReplaceFilterChain(f.effectiveImportFilterChain())
+	f.leaveUpdateGroup()
+	f.adjRIBOut.ReplaceFilterChain(f.effectiveExportFilterChain())
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(11, 0, 0, 0), 8).Ptr()
+
+	rib := locRIB.New("inet.0")
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config:   &PeerConfig{},
+		ipv4: &peerAddressFamily{
+			rib:               rib,
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	p.fsms = append(p.fsms, s.fsm)
+	defer s.manualStop()
+
+	withLocalPref := func(u *packet.BGPUpdate) *packet.BGPUpdate {
+		u.PathAttributes.Next.Next = &packet.PathAttribute{
+			TypeCode: packet.LocalPrefAttr,
+			Value:    uint32(200),
+			Next:     u.PathAttributes.Next.Next,
+		}
+
+		return u
+	}
+
+	gshut := grTestUpdate(pfxB)
+	gshut.PathAttributes.Next.Next = &packet.PathAttribute{
+		TypeCode: packet.CommunitiesAttr,
+		Value:    &types.Communities{types.WellKnownCommunityGracefulShutdown},
+	}
+
+	s.update(withLocalPref(grTestUpdate(pfxA)), time.Now())
+	s.update(withLocalPref(gshut), time.Now())
+
+	localPref := func(pfx *bnet.Prefix) uint32 {
+		return rib.Get(pfx).BestPath().BGPPath.BGPPathA.LocalPref
+	}
+
+	assert.Equal(t, uint32(200), localPref(pfxA))
+	assert.Equal(t, uint32(0), localPref(pfxB), "Paths with the graceful shutdown community must be drained")
+	assert.Equal(t, 1, len(s.fsm.ipv4Unicast.effectiveExportFilterChain()))
+
+	assert.True(t, p.maintenance.set(true))
+	p.refreshFilterChains()
+	assert.Equal(t, uint32(0), localPref(pfxA), "Paths of peers in maintenance must be drained")
+	assert.Equal(t, maintenanceExportFilter, s.fsm.ipv4Unicast.effectiveExportFilterChain()[0])
+
+	assert.False(t, p.maintenance.set(true))
+	assert.True(t, p.maintenance.set(false))
+	p.refreshFilterChains()
+	assert.Equal(t, uint32(200), localPref(pfxA))
+	assert.Equal(t, uint32(0), localPref(pfxB))
+	assert.Equal(t, 1, len(s.fsm.ipv4Unicast.effectiveExportFilterChain()))
+}
+
+func TestMaintenanceExportFilter(t *testing.T) {
+	pa := &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				NextHop:   bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+				LocalPref: 100,
+			},
+		},
+	}
+	res := maintenanceExportFilter.Process(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), pa)
+	assert.False(t, res.Terminate)
+	assert.Equal(t, &types.Communities{types.WellKnownCommunityGracefulShutdown}, res.Path.BGPPath.Communities)
+	assert.Nil(t, pa.BGPPath.Communities, "Original path must not be modified")
+}
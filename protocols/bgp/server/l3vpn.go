@@ -0,0 +1,579 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultVPNLocalPref is the local preference of VPN routes exported via iBGP if the VRF route has none
+	DefaultVPNLocalPref = 100
+)
+
+// VPNConfig configures an L3VPN address family of a peer (RFC4364, RFC4659). Received routes are imported into
+// all VRFs of the registry with a matching import route target, routes of VRFs with export route targets are advertised.
+type VPNConfig struct {
+	ImportFilterChain filter.Chain
+	ExportFilterChain filter.Chain
+	VRFs              *vrf.VRFRegistry
+}
+
+// filterChainsEqual checks if both configs use the same filter chains
+func (c *VPNConfig) filterChainsEqual(d *VPNConfig) bool {
+	if c == nil || d == nil {
+		return c == d
+	}
+
+	return c.ImportFilterChain.Equal(d.ImportFilterChain) && c.ExportFilterChain.Equal(d.ExportFilterChain)
+}
+
+// vpnAddressFamily holds the state of a VPN address family of a session
+type vpnAddressFamily struct {
+	afi  uint16
+	fsm  *FSM
+	cfg  *VPNConfig
+	opts *packet.EncodeOptions
+
+	importFilterChain filter.Chain
+	exportFilterChain filter.Chain
+
+	// negotiated is set if the peer advertised the multi protocol capability for the family
+	negotiated bool
+	nextHop    *bnet.IP
+
+	mu          sync.Mutex
+	imported    map[vpnRouteKey]*vpnImport
+	exports     []*vrfExport
+	initialized bool
+}
+
+// vpnRouteKey identifies a VPN route received from a peer
+type vpnRouteKey struct {
+	rd     uint64
+	pfx    bnet.Prefix
+	pathID uint32
+}
+
+// vpnImport is a received VPN route and the VRF RIBs it has been imported into
+type vpnImport struct {
+	path *route.Path
+	ribs []*locRIB.LocRIB
+}
+
+func newVPNAddressFamily(afi uint16, cfg *VPNConfig, fsm *FSM) *vpnAddressFamily {
+	return &vpnAddressFamily{
+		afi:               afi,
+		fsm:               fsm,
+		cfg:               cfg,
+		importFilterChain: filterOrDefault(cfg.ImportFilterChain),
+		exportFilterChain: filterOrDefault(cfg.ExportFilterChain),
+	}
+}
+
+// vrfLabel gets the label of VPN routes exported from a VRF. A single label is allocated per VRF (RFC4364 4.3.2).
+func (b *bgpServer) vrfLabel(v *vrf.VRF) (uint32, error) {
+	if b == nil {
+		return 0, fmt.Errorf("No BGP server")
+	}
+
+	b.labelsMu.Lock()
+	defer b.labelsMu.Unlock()
+
+	if l, ok := b.vpnLabels[v.RD()]; ok {
+		return l, nil
+	}
+
+	if b.labels == nil {
+		return 0, fmt.Errorf("No label manager configured")
+	}
+
+	l, err := b.labels.Allocate("bgp", fmt.Sprintf("VRF %s", v.Name()))
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to allocate label")
+	}
+
+	if b.vpnLabels == nil {
+		b.vpnLabels = make(map[uint64]uint32)
+	}
+	b.vpnLabels[v.RD()] = l
+
+	return l, nil
+}
+
+// vrfRIB gets the unicast RIB of a VRF for an AFI
+func vrfRIB(v *vrf.VRF, afi uint16) *locRIB.LocRIB {
+	switch afi {
+	case packet.IPv4AFI:
+		return v.IPv4UnicastRIB()
+	case packet.IPv6AFI:
+		return v.IPv6UnicastRIB()
+	}
+
+	return nil
+}
+
+func (f *vpnAddressFamily) init(localAddr *bnet.IP) {
+	f.mu.Lock()
+	f.opts = &packet.EncodeOptions{
+		Use32BitASN: f.fsm.supports4OctetASN,
+	}
+	f.imported = make(map[vpnRouteKey]*vpnImport)
+	f.nextHop = vpnNextHop(localAddr, f.afi)
+	f.initialized = true
+	f.mu.Unlock()
+
+	if f.cfg.VRFs == nil {
+		return
+	}
+
+	for _, v := range f.cfg.VRFs.List() {
+		rts := v.ExportRouteTargets()
+		rib := vrfRIB(v, f.afi)
+		if len(rts) == 0 || rib == nil {
+			continue
+		}
+
+		label, err := f.fsm.peer.server.vrfLabel(v)
+		if err != nil {
+			log.WithError(err).WithField("vrf", v.Name()).Error("Unable to export VRF")
+			continue
+		}
+
+		e := &vrfExport{
+			family:  f,
+			vrf:     v,
+			rib:     rib,
+			label:   label,
+			rts:     rts,
+			allowed: f.exportAllowed(rts),
+		}
+
+		f.mu.Lock()
+		f.exports = append(f.exports, e)
+		f.mu.Unlock()
+
+		rib.Register(e)
+	}
+}
+
+// vpnNextHop gets the next hop advertised for VPN routes. IPv6 VPN routes over IPv4 sessions carry an IPv4-mapped IPv6 next hop (RFC4659 3.2.1.2).
+func vpnNextHop(localAddr *bnet.IP, afi uint16) *bnet.IP {
+	if afi == packet.IPv6AFI && localAddr.IsIPv4() {
+		return bnet.IPv6(0, 0xffff<<32|uint64(localAddr.ToUint32())).Dedup()
+	}
+
+	return localAddr
+}
+
+func (f *vpnAddressFamily) dispose() {
+	f.mu.Lock()
+	exports := f.exports
+	f.exports = nil
+	f.mu.Unlock()
+
+	for _, e := range exports {
+		e.rib.Unregister(e)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, imp := range f.imported {
+		pfx := key.pfx
+		for _, rib := range imp.ribs {
+			rib.RemovePath(&pfx, imp.path)
+		}
+	}
+
+	f.imported = nil
+	f.negotiated = false
+	f.initialized = false
+}
+
+func (f *vpnAddressFamily) processUpdate(u *packet.BGPUpdate) {
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.MultiProtocolReachNLRICode:
+			nlri := pa.Value.(packet.MultiProtocolReachNLRI)
+			if nlri.AFI == f.afi && nlri.SAFI == packet.MPLSVPNSAFI {
+				f.updates(u.PathAttributes, nlri)
+			}
+		case packet.MultiProtocolUnreachNLRICode:
+			nlri := pa.Value.(packet.MultiProtocolUnreachNLRI)
+			if nlri.AFI == f.afi && nlri.SAFI == packet.MPLSVPNSAFI {
+				f.withdraws(nlri)
+			}
+		}
+	}
+}
+
+func (f *vpnAddressFamily) updates(attrs *packet.PathAttribute, nlri packet.MultiProtocolReachNLRI) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for n := nlri.NLRI; n != nil; n = n.Next {
+		key := vpnRouteKey{
+			rd:     n.RouteDistinguisher,
+			pfx:    *n.Prefix,
+			pathID: n.PathIdentifier,
+		}
+		f.withdraw(key)
+
+		path := f.fsm.newRoutePath()
+		processAttributes(attrs, path)
+		path.BGPPath.BGPPathA.NextHop = nlri.NextHop
+		path.BGPPath.Labels = n.Labels
+		path.BGPPath.RouteDistinguisher = n.RouteDistinguisher
+		path.BGPPath.PathIdentifier = n.PathIdentifier
+
+		if f.loop(path) {
+			continue
+		}
+
+		path, reject := f.importFilterChain.Process(n.Prefix, path)
+		if reject {
+			continue
+		}
+
+		f.importRoute(key, path)
+	}
+}
+
+// loop returns if a path has been advertised by us before
+func (f *vpnAddressFamily) loop(p *route.Path) bool {
+	if p.BGPPath.BGPPathA.OriginatorID != 0 && p.BGPPath.BGPPathA.OriginatorID == f.fsm.peer.routerID {
+		return true
+	}
+
+	if p.BGPPath.ASPath == nil {
+		return false
+	}
+
+	for _, segment := range *p.BGPPath.ASPath {
+		for _, asn := range segment.ASNs {
+			if asn == f.fsm.peer.localASN {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// importRoute adds a path to all VRFs with an import route target the path carries (RFC4364 4.3.1)
+func (f *vpnAddressFamily) importRoute(key vpnRouteKey, p *route.Path) {
+	if f.cfg.VRFs == nil {
+		return
+	}
+
+	imp := &vpnImport{
+		path: p,
+	}
+
+	for _, v := range f.cfg.VRFs.List() {
+		rib := vrfRIB(v, f.afi)
+		if rib == nil || !p.BGPPath.ExtendedCommunities.ContainsAny(v.ImportRouteTargets()) {
+			continue
+		}
+
+		pfx := key.pfx
+		rib.AddPath(&pfx, p)
+		imp.ribs = append(imp.ribs, rib)
+	}
+
+	if len(imp.ribs) > 0 {
+		f.imported[key] = imp
+	}
+}
+
+func (f *vpnAddressFamily) withdraws(nlri packet.MultiProtocolUnreachNLRI) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for n := nlri.NLRI; n != nil; n = n.Next {
+		f.withdraw(vpnRouteKey{
+			rd:     n.RouteDistinguisher,
+			pfx:    *n.Prefix,
+			pathID: n.PathIdentifier,
+		})
+	}
+}
+
+func (f *vpnAddressFamily) withdraw(key vpnRouteKey) {
+	imp, ok := f.imported[key]
+	if !ok {
+		return
+	}
+
+	pfx := key.pfx
+	for _, rib := range imp.ribs {
+		rib.RemovePath(&pfx, imp.path)
+	}
+
+	delete(f.imported, key)
+}
+
+// importedRouteCount gets the number of received routes imported into at least one VRF
+func (f *vpnAddressFamily) importedRouteCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.imported)
+}
+
+// exportAllowed returns if routes with route targets rts are advertised to the peer. With route target constraint
+// negotiated only routes of route targets the peer requested are advertised (RFC4684 3).
+func (f *vpnAddressFamily) exportAllowed(rts types.ExtendedCommunities) bool {
+	if !f.fsm.rtc.constrains() {
+		return true
+	}
+
+	return f.fsm.rtc.requested(rts)
+}
+
+// routeTargetsChanged advertises or withdraws the routes of VRFs whose route targets were requested or revoked by the peer
+func (f *vpnAddressFamily) routeTargetsChanged() {
+	f.mu.Lock()
+	exports := make([]*vrfExport, len(f.exports))
+	copy(exports, f.exports)
+	f.mu.Unlock()
+
+	for _, e := range exports {
+		if e.setAllowed(f.exportAllowed(e.rts)) {
+			e.rib.RefreshClient(e)
+		}
+	}
+}
+
+// exportPath converts a path of a VRF into a VPN path. Returns nil if the path must not be advertised.
+func (f *vpnAddressFamily) exportPath(e *vrfExport, pfx *bnet.Prefix, p *route.Path) *route.Path {
+	// VPN routes imported into the VRF are not advertised again
+	if p.BGPPath != nil && p.BGPPath.RouteDistinguisher != 0 {
+		return nil
+	}
+
+	p, reject := f.exportFilterChain.Process(pfx, p)
+	if reject {
+		return nil
+	}
+
+	path := &route.Path{
+		Type:    route.BGPPathType,
+		BGPPath: p.BGPPath.Copy(),
+	}
+
+	if path.BGPPath == nil {
+		path.BGPPath = &route.BGPPath{
+			BGPPathA: route.NewBGPPathA(),
+			ASPath:   &types.ASPath{},
+		}
+		path.BGPPath.BGPPathA.Origin = packet.INCOMPLETE
+	}
+
+	a := *path.BGPPath.BGPPathA
+	a.NextHop = f.nextHop
+	path.BGPPath.BGPPathA = &a
+	path.BGPPath.PathIdentifier = 0
+	path.BGPPath.Labels = []uint32{e.label}
+	path.BGPPath.RouteDistinguisher = e.vrf.RD()
+
+	coms := make(types.ExtendedCommunities, 0, len(e.rts))
+	if path.BGPPath.ExtendedCommunities != nil {
+		for _, c := range *path.BGPPath.ExtendedCommunities {
+			if !c.IsRouteTarget() {
+				coms = append(coms, c)
+			}
+		}
+	}
+	coms = append(coms, e.rts...)
+	path.BGPPath.ExtendedCommunities = &coms
+
+	if f.fsm.peer.isEBGP() {
+		if path.BGPPath.ASPath == nil {
+			path.BGPPath.ASPath = &types.ASPath{}
+		}
+
+		path.BGPPath.Prepend(f.fsm.peer.localASN, 1)
+	} else if path.BGPPath.BGPPathA.LocalPref == 0 {
+		path.BGPPath.BGPPathA.LocalPref = DefaultVPNLocalPref
+	}
+
+	return path
+}
+
+func (f *vpnAddressFamily) advertise(pfx *bnet.Prefix, p *route.Path) error {
+	attrs, err := packet.PathAttributes(p, !f.fsm.peer.isEBGP(), false)
+	if err != nil {
+		return errors.Wrap(err, "Unable to get path attributes")
+	}
+
+	// the next hop is part of MP_REACH_NLRI
+	for cur := attrs; cur.Next != nil; cur = cur.Next {
+		if cur.Next.TypeCode == packet.NextHopAttr {
+			cur.Next = cur.Next.Next
+			break
+		}
+	}
+
+	update := &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:     f.afi,
+				SAFI:    packet.MPLSVPNSAFI,
+				NextHop: p.BGPPath.BGPPathA.NextHop,
+				NLRI: &packet.NLRI{
+					Labels:             p.BGPPath.Labels,
+					RouteDistinguisher: p.BGPPath.RouteDistinguisher,
+					Prefix:             pfx,
+				},
+			},
+			Next: attrs,
+		},
+	}
+
+	return f.send(update)
+}
+
+func (f *vpnAddressFamily) withdrawRoute(pfx *bnet.Prefix, rd uint64) error {
+	update := &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolUnreachNLRICode,
+			Value: packet.MultiProtocolUnreachNLRI{
+				AFI:  f.afi,
+				SAFI: packet.MPLSVPNSAFI,
+				NLRI: &packet.NLRI{
+					RouteDistinguisher: rd,
+					Prefix:             pfx,
+				},
+			},
+		},
+	}
+
+	return f.send(update)
+}
+
+func (f *vpnAddressFamily) send(u *packet.BGPUpdate) error {
+	err := serializeAndSendUpdate(f.fsm.con, u, f.opts)
+	if err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&f.fsm.counters.updatesSent, 1)
+	f.fsm.peer.counters.updateSent(u)
+	return nil
+}
+
+// vrfExport advertises the best paths of a VRF RIB as VPN routes
+type vrfExport struct {
+	family *vpnAddressFamily
+	vrf    *vrf.VRF
+	rib    *locRIB.LocRIB
+	label  uint32
+	rts    types.ExtendedCommunities
+
+	// allowed is set if the peer requested the route targets of the VRF or did not negotiate route target constraint
+	allowedMu sync.Mutex
+	allowed   bool
+}
+
+func (e *vrfExport) isAllowed() bool {
+	e.allowedMu.Lock()
+	defer e.allowedMu.Unlock()
+
+	return e.allowed
+}
+
+// setAllowed sets if the routes of the VRF are advertised and returns if this changed
+func (e *vrfExport) setAllowed(allowed bool) bool {
+	e.allowedMu.Lock()
+	defer e.allowedMu.Unlock()
+
+	changed := e.allowed != allowed
+	e.allowed = allowed
+	return changed
+}
+
+// AddPath advertises a path
+func (e *vrfExport) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	if !e.isAllowed() {
+		return nil
+	}
+
+	vpnPath := e.family.exportPath(e, pfx, p)
+	if vpnPath == nil {
+		return nil
+	}
+
+	err := e.family.advertise(pfx, vpnPath)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"peer":   e.family.fsm.peer.addr.String(),
+			"vrf":    e.vrf.Name(),
+			"prefix": pfx.String(),
+		}).WithError(err).Error("Unable to advertise VPN route")
+	}
+
+	return err
+}
+
+// AddPathInitialDump advertises a path of the initial table dump
+func (e *vrfExport) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return e.AddPath(pfx, p)
+}
+
+// RemovePath withdraws a path
+func (e *vrfExport) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	if !e.isAllowed() {
+		return false
+	}
+
+	return e.withdraw(pfx, p)
+}
+
+func (e *vrfExport) withdraw(pfx *bnet.Prefix, p *route.Path) bool {
+	if e.family.exportPath(e, pfx, p) == nil {
+		return false
+	}
+
+	err := e.family.withdrawRoute(pfx, e.vrf.RD())
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"peer":   e.family.fsm.peer.addr.String(),
+			"vrf":    e.vrf.Name(),
+			"prefix": pfx.String(),
+		}).WithError(err).Error("Unable to withdraw VPN route")
+		return false
+	}
+
+	return true
+}
+
+// ReplacePath is here to fulfill an interface
+func (e *vrfExport) ReplacePath(*bnet.Prefix, *route.Path, *route.Path) {
+}
+
+// RefreshRoute advertises or withdraws the best path of a prefix after the route targets requested by the peer changed
+func (e *vrfExport) RefreshRoute(pfx *bnet.Prefix, paths []*route.Path) {
+	if len(paths) == 0 {
+		return
+	}
+
+	if e.isAllowed() {
+		e.AddPath(pfx, paths[0])
+		return
+	}
+
+	e.withdraw(pfx, paths[0])
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/stretchr/testify/assert"
+)
+
+func vpnTestUpdate(pfx *bnet.Prefix, rd uint64, asns []uint32, coms ...types.ExtendedCommunity) *packet.BGPUpdate {
+	ec := types.ExtendedCommunities(coms)
+	u := &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:     packet.IPv4AFI,
+				SAFI:    packet.MPLSVPNSAFI,
+				NextHop: bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+				NLRI: &packet.NLRI{
+					Labels:             []uint32{100},
+					RouteDistinguisher: rd,
+					Prefix:             pfx,
+				},
+			},
+			Next: &packet.PathAttribute{
+				TypeCode: packet.OriginAttr,
+				Value:    uint8(0),
+				Next: &packet.PathAttribute{
+					TypeCode: packet.ExtendedCommunitiesAttr,
+					Value:    &ec,
+				},
+			},
+		},
+	}
+
+	if len(asns) > 0 {
+		u.PathAttributes.Next.Next.Next = &packet.PathAttribute{
+			TypeCode: packet.ASPathAttr,
+			Value: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: asns,
+				},
+			},
+		}
+	}
+
+	return u
+}
+
+func vpnTestWithdraw(pfx *bnet.Prefix, rd uint64) *packet.BGPUpdate {
+	return &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolUnreachNLRICode,
+			Value: packet.MultiProtocolUnreachNLRI{
+				AFI:  packet.IPv4AFI,
+				SAFI: packet.MPLSVPNSAFI,
+				NLRI: &packet.NLRI{
+					RouteDistinguisher: rd,
+					Prefix:             pfx,
+				},
+			},
+		},
+	}
+}
+
+func vpnTestFamily(t *testing.T, reg *vrf.VRFRegistry, localASN uint32, peerASN uint32) *vpnAddressFamily {
+	lm, err := labelmanager.New(config.DefaultMPLSConfig())
+	if err != nil {
+		t.Fatalf("Unable to create label manager: %v", err)
+	}
+
+	p := &peer{
+		server:   &bgpServer{labels: lm},
+		addr:     bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		localASN: localASN,
+		peerASN:  peerASN,
+		config: &PeerConfig{
+			IPv4VPN: &VPNConfig{
+				ImportFilterChain: filter.NewAcceptAllFilterChain(),
+				ExportFilterChain: filter.NewAcceptAllFilterChain(),
+				VRFs:              reg,
+			},
+		},
+	}
+
+	fsm := newFSM(p)
+	fsm.con = fakeConn{}
+	fsm.ipv4VPN.init(bnet.IPv4FromOctets(10, 0, 0, 1).Ptr())
+
+	return fsm.ipv4VPN
+}
+
+func TestVPNImport(t *testing.T) {
+	rtA, _ := types.NewRouteTarget(65000, 1)
+	rtB, _ := types.NewRouteTarget(65000, 2)
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 0, 0), 24).Ptr()
+
+	tests := []struct {
+		name          string
+		update        *packet.BGPUpdate
+		expectedA     int64
+		expectedB     int64
+		expectedCount int
+	}{
+		{
+			name:          "Import into matching VRF",
+			update:        vpnTestUpdate(pfx, 0x0000fde800000001, nil, rtA),
+			expectedA:     1,
+			expectedB:     0,
+			expectedCount: 1,
+		},
+		{
+			name:          "Import into multiple VRFs",
+			update:        vpnTestUpdate(pfx, 0x0000fde800000001, nil, rtA, rtB),
+			expectedA:     1,
+			expectedB:     1,
+			expectedCount: 1,
+		},
+		{
+			name:          "No matching route target",
+			update:        vpnTestUpdate(pfx, 0x0000fde800000001, nil, 0x0003000000000001),
+			expectedA:     0,
+			expectedB:     0,
+			expectedCount: 0,
+		},
+		{
+			name:          "AS path loop",
+			update:        vpnTestUpdate(pfx, 0x0000fde800000001, []uint32{65001, 65000}, rtA),
+			expectedA:     0,
+			expectedB:     0,
+			expectedCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		reg := vrf.NewVRFRegistry()
+		vrfA := reg.CreateVRFIfNotExists("a", 1)
+		vrfA.SetRouteTargets(types.ExtendedCommunities{rtA}, nil)
+		vrfB := reg.CreateVRFIfNotExists("b", 2)
+		vrfB.SetRouteTargets(types.ExtendedCommunities{rtB}, nil)
+
+		f := vpnTestFamily(t, reg, 65000, 65000)
+		f.processUpdate(test.update)
+		assert.Equalf(t, test.expectedA, vrfA.IPv4UnicastRIB().RouteCount(), "Test %q: VRF a", test.name)
+		assert.Equalf(t, test.expectedB, vrfB.IPv4UnicastRIB().RouteCount(), "Test %q: VRF b", test.name)
+		assert.Equalf(t, test.expectedCount, f.importedRouteCount(), "Test %q", test.name)
+
+		if test.expectedA > 0 {
+			p := vrfA.IPv4UnicastRIB().Dump()[0].Paths()[0]
+			assert.Equalf(t, []uint32{100}, p.BGPPath.Labels, "Test %q", test.name)
+			assert.Equalf(t, uint64(0x0000fde800000001), p.BGPPath.RouteDistinguisher, "Test %q", test.name)
+		}
+
+		f.processUpdate(vpnTestWithdraw(pfx, 0x0000fde800000002))
+		assert.Equalf(t, test.expectedA, vrfA.IPv4UnicastRIB().RouteCount(), "Test %q: withdraw of other RD", test.name)
+
+		f.processUpdate(vpnTestWithdraw(pfx, 0x0000fde800000001))
+		assert.Equalf(t, int64(0), vrfA.IPv4UnicastRIB().RouteCount(), "Test %q: VRF a after withdraw", test.name)
+		assert.Equalf(t, int64(0), vrfB.IPv4UnicastRIB().RouteCount(), "Test %q: VRF b after withdraw", test.name)
+		assert.Equalf(t, 0, f.importedRouteCount(), "Test %q: after withdraw", test.name)
+	}
+}
+
+func TestVPNExportPath(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 1)
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 0, 0), 24).Ptr()
+
+	tests := []struct {
+		name     string
+		peerASN  uint32
+		path     *route.Path
+		expected *route.Path
+	}{
+		{
+			name:    "Static route via iBGP",
+			peerASN: 65000,
+			path: &route.Path{
+				Type: route.StaticPathType,
+				StaticPath: &route.StaticPath{
+					NextHop: bnet.IPv4FromOctets(192, 168, 1, 1).Ptr(),
+				},
+			},
+			expected: &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					BGPPathA: &route.BGPPathA{
+						NextHop:   bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+						Source:    bnet.IPv4(0).Ptr(),
+						Origin:    packet.INCOMPLETE,
+						LocalPref: DefaultVPNLocalPref,
+					},
+					ASPath:              &types.ASPath{},
+					ExtendedCommunities: &types.ExtendedCommunities{rt},
+					Labels:              []uint32{config.DefaultDynamicLabelStart},
+					RouteDistinguisher:  1,
+				},
+			},
+		},
+		{
+			name:    "Static route via eBGP",
+			peerASN: 65001,
+			path: &route.Path{
+				Type: route.StaticPathType,
+				StaticPath: &route.StaticPath{
+					NextHop: bnet.IPv4FromOctets(192, 168, 1, 1).Ptr(),
+				},
+			},
+			expected: &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					BGPPathA: &route.BGPPathA{
+						NextHop: bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+						Source:  bnet.IPv4(0).Ptr(),
+						Origin:  packet.INCOMPLETE,
+					},
+					ASPath: &types.ASPath{
+						{
+							Type: types.ASSequence,
+							ASNs: []uint32{65000},
+						},
+					},
+					ASPathLen:           1,
+					ExtendedCommunities: &types.ExtendedCommunities{rt},
+					Labels:              []uint32{config.DefaultDynamicLabelStart},
+					RouteDistinguisher:  1,
+				},
+			},
+		},
+		{
+			name:    "Imported VPN route",
+			peerASN: 65000,
+			path: &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					BGPPathA:           route.NewBGPPathA(),
+					RouteDistinguisher: 0x0000fde800000001,
+				},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		reg := vrf.NewVRFRegistry()
+		v := reg.CreateVRFIfNotExists("a", 1)
+		v.SetRouteTargets(nil, types.ExtendedCommunities{rt})
+
+		f := vpnTestFamily(t, reg, 65000, test.peerASN)
+		if !assert.Equalf(t, 1, len(f.exports), "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equalf(t, test.expected, f.exportPath(f.exports[0], pfx, test.path), "Test %q", test.name)
+		f.dispose()
+	}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/pkg/errors"
+)
+
+// labelOwner identifies BGP labeled unicast allocations in the label manager
+const labelOwner = "bgp-lu"
+
+// LabelBinding is a label forwarding entry: Packets received with LocalLabel are sent to NextHop with RemoteLabel
+type LabelBinding struct {
+	Prefix      bnet.Prefix
+	LocalLabel  uint32
+	NextHop     bnet.IP
+	RemoteLabel uint32
+}
+
+// LabelFIB is an MPLS capable forwarding table label bindings of labeled unicast routes are programmed into
+type LabelFIB interface {
+	AddLabelBinding(b LabelBinding) error
+	RemoveLabelBinding(b LabelBinding) error
+}
+
+// labelAllocator assigns the local labels of routes of a RIB we advertise as next hop via labeled unicast (RFC8277).
+// Labels are allocated per prefix on first advertisement and kept until the prefix is gone from the RIB.
+type labelAllocator struct {
+	labels *labelmanager.LabelManager
+	fib    LabelFIB
+
+	mu     sync.Mutex
+	routes map[bnet.Prefix]*labeledRoute
+}
+
+// labeledRoute holds all paths of a prefix and the local label assigned to it
+type labeledRoute struct {
+	route      *route.Route
+	localLabel uint32
+	installed  *LabelBinding
+}
+
+func newLabelAllocator(labels *labelmanager.LabelManager, fib LabelFIB) *labelAllocator {
+	return &labelAllocator{
+		labels: labels,
+		fib:    fib,
+		routes: make(map[bnet.Prefix]*labeledRoute),
+	}
+}
+
+// labelAllocator gets the label allocator of a RIB. It is created and registered with the RIB on first use.
+func (b *bgpServer) labelAllocator(rib *locRIB.LocRIB) *labelAllocator {
+	if b == nil {
+		return nil
+	}
+
+	b.labelsMu.Lock()
+	defer b.labelsMu.Unlock()
+
+	if a, ok := b.labelAllocators[rib]; ok {
+		return a
+	}
+
+	if b.labelAllocators == nil {
+		b.labelAllocators = make(map[*locRIB.LocRIB]*labelAllocator)
+	}
+
+	a := newLabelAllocator(b.labels, b.labelFIB)
+	b.labelAllocators[rib] = a
+
+	// All paths are needed to keep the label of a prefix stable when its best path changes
+	rib.RegisterWithOptions(a, routingtable.ClientOptions{
+		MaxPaths: math.MaxInt32,
+	})
+
+	return a
+}
+
+// label gets the local label advertised for a prefix. Returns the implicit null label if we are the egress router for the prefix.
+func (a *labelAllocator) label(pfx *bnet.Prefix) (uint32, error) {
+	if a == nil {
+		return 0, fmt.Errorf("No label allocator")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, ok := a.routes[*pfx]
+	if !ok {
+		// The prefix has been removed in the meantime, a withdraw is going to follow
+		return packet.ImplicitNullLabel, nil
+	}
+
+	if r.localLabel != 0 {
+		return r.localLabel, nil
+	}
+
+	if egress(r.route.BestPath()) {
+		r.localLabel = packet.ImplicitNullLabel
+		return r.localLabel, nil
+	}
+
+	if a.labels == nil {
+		return 0, fmt.Errorf("No label manager configured")
+	}
+
+	l, err := a.labels.Allocate(labelOwner, pfx.String())
+	if err != nil {
+		return 0, errors.Wrap(err, "Unable to allocate label")
+	}
+
+	r.localLabel = l
+	a.syncFIB(r)
+
+	return r.localLabel, nil
+}
+
+// egress returns if a path is a route without next hop, e.g. a directly connected network
+func egress(p *route.Path) bool {
+	if p == nil {
+		return true
+	}
+
+	nh := p.NextHop()
+	return nh == nil || (nh.Higher() == 0 && nh.Lower() == 0)
+}
+
+// AddPath adds a path of the RIB
+func (a *labelAllocator) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, ok := a.routes[*pfx]
+	if !ok {
+		r = &labeledRoute{
+			route: route.NewRoute(pfx, nil),
+		}
+		a.routes[*pfx] = r
+	}
+
+	r.route.AddPath(p)
+	r.route.PathSelection()
+	a.update(r)
+
+	return nil
+}
+
+// AddPathInitialDump adds a path during the initial dump of the RIB
+func (a *labelAllocator) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return a.AddPath(pfx, p)
+}
+
+// RemovePath removes a path of the RIB
+func (a *labelAllocator) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, ok := a.routes[*pfx]
+	if !ok {
+		return false
+	}
+
+	if r.route.RemovePath(p) > 0 {
+		r.route.PathSelection()
+		a.update(r)
+		return true
+	}
+
+	r.route = route.NewRoute(pfx, nil)
+	a.update(r)
+	delete(a.routes, *pfx)
+
+	return true
+}
+
+// ReplacePath is here to fulfill an interface
+func (a *labelAllocator) ReplacePath(*bnet.Prefix, *route.Path, *route.Path) {
+}
+
+// RefreshRoute is here to fulfill an interface
+func (a *labelAllocator) RefreshRoute(*bnet.Prefix, []*route.Path) {
+}
+
+// update releases the local label of a route if it is not needed anymore or has become unsuitable and updates the FIB.
+// Must be called with a.mu held.
+func (a *labelAllocator) update(r *labeledRoute) {
+	best := r.route.BestPath()
+	if best == nil || (r.localLabel == packet.ImplicitNullLabel && !egress(best)) || (r.localLabel >= labelmanager.MinLabel && egress(best)) {
+		a.releaseLabel(r)
+	}
+
+	a.syncFIB(r)
+}
+
+func (a *labelAllocator) releaseLabel(r *labeledRoute) {
+	if r.localLabel >= labelmanager.MinLabel && a.labels != nil {
+		a.labels.Release(r.localLabel)
+	}
+
+	r.localLabel = 0
+}
+
+// syncFIB programs the label binding of a route: Packets arriving with our local label are forwarded to the next hop
+// of the best path using the label it was advertised with, or as IP packets if the path is unlabeled. Must be called with a.mu held.
+func (a *labelAllocator) syncFIB(r *labeledRoute) {
+	var want *LabelBinding
+	if best := r.route.BestPath(); r.localLabel >= labelmanager.MinLabel && best != nil {
+		remote := uint32(packet.ImplicitNullLabel)
+		if best.BGPPath != nil && len(best.BGPPath.Labels) > 0 {
+			remote = best.BGPPath.Labels[0]
+		}
+
+		want = &LabelBinding{
+			Prefix:      *r.route.Prefix(),
+			LocalLabel:  r.localLabel,
+			NextHop:     *best.NextHop(),
+			RemoteLabel: remote,
+		}
+	}
+
+	if r.installed != nil && want != nil && *r.installed == *want {
+		return
+	}
+
+	if r.installed != nil {
+		if a.fib != nil {
+			err := a.fib.RemoveLabelBinding(*r.installed)
+			if err != nil {
+				log.WithError(err).Errorf("Unable to remove label binding for %s", r.installed.Prefix.String())
+			}
+		}
+
+		r.installed = nil
+	}
+
+	if want == nil {
+		return
+	}
+
+	r.installed = want
+	if a.fib == nil {
+		return
+	}
+
+	err := a.fib.AddLabelBinding(*want)
+	if err != nil {
+		log.WithError(err).Errorf("Unable to add label binding for %s", want.Prefix.String())
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLabelFIB struct {
+	bindings map[bnet.Prefix]LabelBinding
+}
+
+func (m *mockLabelFIB) AddLabelBinding(b LabelBinding) error {
+	m.bindings[b.Prefix] = b
+	return nil
+}
+
+func (m *mockLabelFIB) RemoveLabelBinding(b LabelBinding) error {
+	delete(m.bindings, b.Prefix)
+	return nil
+}
+
+func labeledTestPath(nextHop bnet.IP, labels ...uint32) *route.Path {
+	return &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				NextHop:   nextHop.Ptr(),
+				Source:    nextHop.Ptr(),
+				LocalPref: 100,
+			},
+			Labels: labels,
+		},
+	}
+}
+
+func TestLabelAllocator(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr()
+
+	tests := []struct {
+		name          string
+		path          *route.Path
+		expectedLabel uint32
+		expected      *LabelBinding
+	}{
+		{
+			name:          "Locally originated",
+			path:          labeledTestPath(bnet.IPv4(0)),
+			expectedLabel: packet.ImplicitNullLabel,
+		},
+		{
+			name:          "Labeled path",
+			path:          labeledTestPath(bnet.IPv4FromOctets(10, 0, 0, 2), 100),
+			expectedLabel: config.DefaultDynamicLabelStart,
+			expected: &LabelBinding{
+				Prefix:      *pfx,
+				LocalLabel:  config.DefaultDynamicLabelStart,
+				NextHop:     bnet.IPv4FromOctets(10, 0, 0, 2),
+				RemoteLabel: 100,
+			},
+		},
+		{
+			name:          "Unlabeled path",
+			path:          labeledTestPath(bnet.IPv4FromOctets(10, 0, 0, 3)),
+			expectedLabel: config.DefaultDynamicLabelStart,
+			expected: &LabelBinding{
+				Prefix:      *pfx,
+				LocalLabel:  config.DefaultDynamicLabelStart,
+				NextHop:     bnet.IPv4FromOctets(10, 0, 0, 3),
+				RemoteLabel: packet.ImplicitNullLabel,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		lm, err := labelmanager.New(config.DefaultMPLSConfig())
+		if err != nil {
+			t.Fatalf("Unable to create label manager: %v", err)
+		}
+
+		fib := &mockLabelFIB{bindings: make(map[bnet.Prefix]LabelBinding)}
+		b := &bgpServer{labels: lm, labelFIB: fib}
+		rib := locRIB.New("inet.0")
+		a := b.labelAllocator(rib)
+		assert.Equalf(t, a, b.labelAllocator(rib), "Test %q", test.name)
+
+		rib.AddPath(pfx, test.path)
+
+		l, err := a.label(pfx)
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.expectedLabel, l, "Test %q", test.name)
+
+		if test.expected == nil {
+			assert.Equalf(t, 0, len(fib.bindings), "Test %q", test.name)
+		} else {
+			assert.Equalf(t, *test.expected, fib.bindings[*pfx], "Test %q", test.name)
+		}
+
+		rib.RemovePath(pfx, test.path)
+		assert.Equalf(t, 0, len(fib.bindings), "Test %q: bindings left after removal", test.name)
+		assert.Equalf(t, 0, len(lm.Allocations()), "Test %q: labels left after removal", test.name)
+	}
+}
+
+func TestLabeledPath(t *testing.T) {
+	local := bnet.IPv4FromOctets(10, 0, 0, 1)
+
+	tests := []struct {
+		name     string
+		path     *route.Path
+		expected *route.Path
+	}{
+		{
+			name:     "Labeled path is kept",
+			path:     labeledTestPath(bnet.IPv4FromOctets(10, 0, 0, 2), 100),
+			expected: labeledTestPath(bnet.IPv4FromOctets(10, 0, 0, 2), 100),
+		},
+		{
+			name:     "Next hop self gets local label",
+			path:     labeledTestPath(local, 100),
+			expected: labeledTestPath(local),
+		},
+		{
+			name:     "Unlabeled path gets next hop self",
+			path:     labeledTestPath(bnet.IPv4FromOctets(10, 0, 0, 3)),
+			expected: labeledTestPath(local),
+		},
+	}
+
+	for _, test := range tests {
+		u := &UpdateSender{
+			addressFamily: &fsmAddressFamily{
+				afi:          packet.IPv4AFI,
+				safi:         packet.LabeledUnicastSAFI,
+				localAddress: local.Ptr(),
+			},
+		}
+
+		p := u.labeledPath(test.path)
+		assert.Equalf(t, test.expected.NextHop(), p.NextHop(), "Test %q", test.name)
+		assert.Equalf(t, test.expected.BGPPath.Labels, p.BGPPath.Labels, "Test %q", test.name)
+	}
+}
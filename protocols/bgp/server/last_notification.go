@@ -0,0 +1,55 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+)
+
+// lastNotifications keeps the last NOTIFICATIONs sent to and received from a peer across sessions
+type lastNotifications struct {
+	mu          sync.RWMutex
+	lastSentMsg *metrics.BGPNotification
+	lastRcvdMsg *metrics.BGPNotification
+}
+
+func notificationMetrics(n *packet.BGPNotification) *metrics.BGPNotification {
+	return &metrics.BGPNotification{
+		ErrorCode:    n.ErrorCode,
+		ErrorSubcode: n.ErrorSubcode,
+		Description:  n.Description(),
+		Time:         time.Now(),
+	}
+}
+
+func (l *lastNotifications) sent(n *packet.BGPNotification) {
+	m := notificationMetrics(n)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSentMsg = m
+}
+
+func (l *lastNotifications) received(n *packet.BGPNotification) {
+	m := notificationMetrics(n)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastRcvdMsg = m
+}
+
+func (l *lastNotifications) lastSent() *metrics.BGPNotification {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.lastSentMsg
+}
+
+func (l *lastNotifications) lastReceived() *metrics.BGPNotification {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.lastRcvdMsg
+}
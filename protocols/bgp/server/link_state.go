@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// linkStateLocalPref is the local preference of BGP-LS routes advertised via iBGP
+	linkStateLocalPref = 100
+)
+
+// LinkStateEntry is a node, link or prefix of an IGP topology exported via BGP-LS (RFC7752)
+type LinkStateEntry struct {
+	NLRI      *packet.LinkStateNLRI
+	Attribute packet.LinkStateAttribute
+}
+
+func (e *LinkStateEntry) equal(x *LinkStateEntry) bool {
+	if len(e.Attribute) != len(x.Attribute) {
+		return false
+	}
+
+	for i := range e.Attribute {
+		if e.Attribute[i].Type != x.Attribute[i].Type || !bytes.Equal(e.Attribute[i].Value, x.Attribute[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// linkStateTable holds the topology of all sources (e.g. IGP instances) and advertises it to BGP-LS peers
+type linkStateTable struct {
+	mu      sync.Mutex
+	sources map[string]map[string]*LinkStateEntry
+	clients map[*linkStateAddressFamily]struct{}
+}
+
+func newLinkStateTable() *linkStateTable {
+	return &linkStateTable{
+		sources: make(map[string]map[string]*LinkStateEntry),
+		clients: make(map[*linkStateAddressFamily]struct{}),
+	}
+}
+
+// UpdateLinkState replaces the topology exported via BGP-LS for a source. Changes are advertised to all BGP-LS peers.
+// An empty list of entries withdraws the topology of the source.
+func (b *bgpServer) UpdateLinkState(source string, entries []*LinkStateEntry) {
+	b.linkState.update(source, entries)
+}
+
+// GetLinkState gets the topology exported via BGP-LS
+func (b *bgpServer) GetLinkState() []*LinkStateEntry {
+	return b.linkState.dump()
+}
+
+func (t *linkStateTable) update(source string, entries []*LinkStateEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	old := t.sources[source]
+	current := make(map[string]*LinkStateEntry, len(entries))
+	for _, e := range entries {
+		key := e.NLRI.Key()
+		current[key] = e
+
+		if o, ok := old[key]; ok && o.equal(e) {
+			continue
+		}
+
+		for c := range t.clients {
+			c.advertise(e)
+		}
+	}
+
+	for key, e := range old {
+		if _, ok := current[key]; ok {
+			continue
+		}
+
+		for c := range t.clients {
+			c.withdraw(e.NLRI)
+		}
+	}
+
+	if len(current) == 0 {
+		delete(t.sources, source)
+		return
+	}
+
+	t.sources[source] = current
+}
+
+// dump gets all entries ordered by NLRI
+func (t *linkStateTable) dump() []*LinkStateEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.entries()
+}
+
+func (t *linkStateTable) entries() []*LinkStateEntry {
+	keys := make([]string, 0)
+	byKey := make(map[string]*LinkStateEntry)
+	for _, entries := range t.sources {
+		for key, e := range entries {
+			if _, ok := byKey[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			byKey[key] = e
+		}
+	}
+
+	sort.Strings(keys)
+	ret := make([]*LinkStateEntry, 0, len(keys))
+	for _, key := range keys {
+		ret = append(ret, byKey[key])
+	}
+
+	return ret
+}
+
+// register advertises the current topology followed by End-of-RIB to a peer and all changes from now on
+func (t *linkStateTable) register(f *linkStateAddressFamily) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, e := range t.entries() {
+		f.advertise(e)
+	}
+
+	err := f.send(packet.EndOfRIB(packet.LinkStateAFI, packet.LinkStateSAFI))
+	if err != nil {
+		log.WithField("peer", f.fsm.peer.addr.String()).WithError(err).Error("Unable to send BGP-LS End-of-RIB")
+	}
+
+	t.clients[f] = struct{}{}
+}
+
+func (t *linkStateTable) unregister(f *linkStateAddressFamily) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.clients, f)
+}
+
+// linkStateAddressFamily advertises the topology to a peer that negotiated BGP-LS. BGP-LS routes received from the peer are ignored.
+type linkStateAddressFamily struct {
+	fsm     *FSM
+	opts    *packet.EncodeOptions
+	nextHop *bnet.IP
+
+	// negotiated is set if the peer advertised the multi protocol capability for BGP-LS
+	negotiated  bool
+	initialized bool
+}
+
+func newLinkStateAddressFamily(fsm *FSM) *linkStateAddressFamily {
+	return &linkStateAddressFamily{
+		fsm: fsm,
+	}
+}
+
+func (f *linkStateAddressFamily) init(localAddr *bnet.IP) {
+	f.opts = &packet.EncodeOptions{
+		Use32BitASN: f.fsm.supports4OctetASN,
+	}
+	f.nextHop = localAddr
+	f.initialized = true
+
+	if f.fsm.peer.server != nil {
+		f.fsm.peer.server.linkState.register(f)
+	}
+}
+
+func (f *linkStateAddressFamily) dispose() {
+	if f.initialized && f.fsm.peer.server != nil {
+		f.fsm.peer.server.linkState.unregister(f)
+	}
+
+	f.negotiated = false
+	f.initialized = false
+}
+
+func (f *linkStateAddressFamily) advertise(e *LinkStateEntry) {
+	attrs, last := f.fsm.originatedPathAttributes(linkStateLocalPref)
+	if len(e.Attribute) > 0 {
+		last.Next = &packet.PathAttribute{
+			TypeCode: packet.LinkStateAttr,
+			Value:    e.Attribute,
+		}
+	}
+
+	err := f.send(&packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:       packet.LinkStateAFI,
+				SAFI:      packet.LinkStateSAFI,
+				NextHop:   f.nextHop,
+				LinkState: []*packet.LinkStateNLRI{e.NLRI},
+			},
+			Next: attrs,
+		},
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"peer": f.fsm.peer.addr.String(),
+			"nlri": e.NLRI.String(),
+		}).WithError(err).Error("Unable to advertise BGP-LS NLRI")
+	}
+}
+
+// originatedPathAttributes gets the ORIGIN, AS_PATH and (iBGP only) LOCAL_PREF attributes of routes originated by the session
+// itself, e.g. BGP-LS or route target membership routes. The last attribute of the list is returned too.
+func (fsm *FSM) originatedPathAttributes(localPref uint32) (*packet.PathAttribute, *packet.PathAttribute) {
+	asPath := &types.ASPath{}
+	if fsm.peer.isEBGP() {
+		asPath = &types.ASPath{
+			{
+				Type: types.ASSequence,
+				ASNs: []uint32{fsm.peer.localASN},
+			},
+		}
+	}
+
+	attrs := &packet.PathAttribute{
+		TypeCode: packet.OriginAttr,
+		Value:    uint8(packet.IGP),
+		Next: &packet.PathAttribute{
+			TypeCode: packet.ASPathAttr,
+			Value:    asPath,
+		},
+	}
+
+	last := attrs.Next
+	if !fsm.peer.isEBGP() {
+		last.Next = &packet.PathAttribute{
+			TypeCode: packet.LocalPrefAttr,
+			Value:    localPref,
+		}
+		last = last.Next
+	}
+
+	return attrs, last
+}
+
+func (f *linkStateAddressFamily) withdraw(n *packet.LinkStateNLRI) {
+	err := f.send(&packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolUnreachNLRICode,
+			Value: packet.MultiProtocolUnreachNLRI{
+				AFI:       packet.LinkStateAFI,
+				SAFI:      packet.LinkStateSAFI,
+				LinkState: []*packet.LinkStateNLRI{n},
+			},
+		},
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"peer": f.fsm.peer.addr.String(),
+			"nlri": n.String(),
+		}).WithError(err).Error("Unable to withdraw BGP-LS NLRI")
+	}
+}
+
+func (f *linkStateAddressFamily) send(u *packet.BGPUpdate) error {
+	err := serializeAndSendUpdate(f.fsm.con, u, f.opts)
+	if err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&f.fsm.counters.updatesSent, 1)
+	f.fsm.peer.counters.updateSent(u)
+	return nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingConn records the BGP messages written to it
+type recordingConn struct {
+	fakeConn
+	msgs [][]byte
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.msgs = append(c.msgs, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+// linkStateMsg describes a BGP-LS UPDATE: the number of advertised and withdrawn NLRIs
+type linkStateMsg struct {
+	reach   int
+	unreach int
+}
+
+func decodeLinkStateMsgs(t *testing.T, msgs [][]byte) []linkStateMsg {
+	ret := make([]linkStateMsg, 0)
+	for _, b := range msgs {
+		m, err := packet.Decode(bytes.NewBuffer(b), &packet.DecodeOptions{
+			Use32BitASN: true,
+		})
+		if err != nil {
+			t.Fatalf("Unable to decode message: %v", err)
+		}
+
+		res := linkStateMsg{}
+		for pa := m.Body.(*packet.BGPUpdate).PathAttributes; pa != nil; pa = pa.Next {
+			switch v := pa.Value.(type) {
+			case packet.MultiProtocolReachNLRI:
+				res.reach += len(v.LinkState)
+			case packet.MultiProtocolUnreachNLRI:
+				res.unreach += len(v.LinkState)
+			}
+		}
+
+		ret = append(ret, res)
+	}
+
+	return ret
+}
+
+func linkStateTestEntry(systemID byte, name string) *LinkStateEntry {
+	return &LinkStateEntry{
+		NLRI: &packet.LinkStateNLRI{
+			Type:       packet.LinkStateNodeNLRI,
+			ProtocolID: packet.LinkStateProtocolISISL2,
+			LocalNode: packet.LinkStateNodeDescriptor{
+				IGPRouterID: []byte{0, 0, 0, 0, 0, systemID},
+			},
+		},
+		Attribute: packet.LinkStateAttribute{
+			{
+				Type:  packet.LinkStateNodeName,
+				Value: []byte(name),
+			},
+		},
+	}
+}
+
+func TestLinkStateAdvertisement(t *testing.T) {
+	b := newBGPServer(0, nil)
+	b.UpdateLinkState("isis", []*LinkStateEntry{
+		linkStateTestEntry(1, "a"),
+	})
+
+	p := &peer{
+		server:   b,
+		addr:     bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+		localASN: 65000,
+		peerASN:  65000,
+		config: &PeerConfig{
+			LinkState: true,
+		},
+	}
+
+	fsm := newFSM(p)
+	con := &recordingConn{}
+	fsm.con = con
+	fsm.supports4OctetASN = true
+	fsm.linkState.negotiated = true
+	fsm.linkState.init(bnet.IPv4FromOctets(10, 0, 0, 1).Ptr())
+
+	assert.Equal(t, []linkStateMsg{{reach: 1}, {}}, decodeLinkStateMsgs(t, con.msgs), "Initial dump followed by End-of-RIB")
+
+	tests := []struct {
+		name     string
+		entries  []*LinkStateEntry
+		expected []linkStateMsg
+	}{
+		{
+			name: "Unchanged",
+			entries: []*LinkStateEntry{
+				linkStateTestEntry(1, "a"),
+			},
+			expected: []linkStateMsg{},
+		},
+		{
+			name: "Changed attribute and new node",
+			entries: []*LinkStateEntry{
+				linkStateTestEntry(1, "b"),
+				linkStateTestEntry(2, "c"),
+			},
+			expected: []linkStateMsg{{reach: 1}, {reach: 1}},
+		},
+		{
+			name: "Removed node",
+			entries: []*LinkStateEntry{
+				linkStateTestEntry(2, "c"),
+			},
+			expected: []linkStateMsg{{unreach: 1}},
+		},
+	}
+
+	for _, test := range tests {
+		con.msgs = nil
+		b.UpdateLinkState("isis", test.entries)
+		assert.Equal(t, test.expected, decodeLinkStateMsgs(t, con.msgs), "Test %q", test.name)
+	}
+
+	assert.Equal(t, 1, len(b.GetLinkState()))
+
+	fsm.linkState.dispose()
+	con.msgs = nil
+	b.UpdateLinkState("isis", nil)
+	assert.Equal(t, 0, len(con.msgs), "No updates after dispose")
+	assert.Equal(t, 0, len(b.GetLinkState()))
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// LocalASMigrationConfig presents another AS than the local AS to a peer (local-as), e.g. to migrate sessions between
+// ASes without coordinating with the peer
+type LocalASMigrationConfig struct {
+	// ASN is presented to the peer instead of the local AS
+	ASN uint32
+
+	// NoPrepend disables prepending ASN to AS paths received from the peer
+	NoPrepend bool
+
+	// ReplaceAS prepends only ASN instead of ASN and the local AS to AS paths sent to the peer
+	ReplaceAS bool
+}
+
+func (c *LocalASMigrationConfig) equal(x *LocalASMigrationConfig) bool {
+	if c == nil || x == nil {
+		return c == x
+	}
+
+	return *c == *x
+}
+
+// sessionASN gets the AS presented to the peer
+func (pc *PeerConfig) sessionASN() uint32 {
+	if pc.LocalASMigration != nil {
+		return pc.LocalASMigration.ASN
+	}
+
+	return pc.LocalAS
+}
+
+// sessionASN gets the AS presented to the peer
+func (p *peer) sessionASN() uint32 {
+	if p.localASMigration != nil {
+		return p.localASMigration.ASN
+	}
+
+	return p.localASN
+}
+
+// prependMigrationAS prepends the AS presented to the peer to a received path as if it had passed through that AS
+func (fsm *FSM) prependMigrationAS(p *route.Path) {
+	m := fsm.peer.localASMigration
+	if m == nil || m.NoPrepend {
+		return
+	}
+
+	// The AS path attribute is shared by all paths of an update
+	asPath := make(types.ASPath, 0)
+	if p.BGPPath.ASPath != nil {
+		asPath = make(types.ASPath, len(*p.BGPPath.ASPath))
+		copy(asPath, *p.BGPPath.ASPath)
+	}
+
+	p.BGPPath.ASPath = &asPath
+	p.BGPPath.Prepend(m.ASN, 1)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalASMigrationOpen(t *testing.T) {
+	p, err := newPeer(PeerConfig{
+		PeerAddress: bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		LocalAS:     202739,
+		PeerAS:      65001,
+		LocalASMigration: &LocalASMigrationConfig{
+			ASN: 65100,
+		},
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msg := newFSM(p).openMessage()
+	assert.Equal(t, uint16(65100), msg.ASN)
+	assert.Contains(t, msg.OptParams[0].Value.(packet.Capabilities), packet.Capability{
+		Code: packet.ASN4CapabilityCode,
+		Value: packet.ASN4Capability{
+			ASN4: 65100,
+		},
+	})
+}
+
+func TestPrependMigrationAS(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration *LocalASMigrationConfig
+		asPath    *types.ASPath
+		expected  *types.ASPath
+	}{
+		{
+			name:     "Without migration",
+			asPath:   &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+			expected: &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+		},
+		{
+			name:      "Prepend",
+			migration: &LocalASMigrationConfig{ASN: 65100},
+			asPath:    &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+			expected:  &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65100, 65001}}},
+		},
+		{
+			name:      "Prepend to empty AS path",
+			migration: &LocalASMigrationConfig{ASN: 65100},
+			expected:  &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65100}}},
+		},
+		{
+			name:      "No prepend",
+			migration: &LocalASMigrationConfig{ASN: 65100, NoPrepend: true},
+			asPath:    &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+			expected:  &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+		},
+	}
+
+	for _, test := range tests {
+		fsm := newFSM(&peer{
+			localASN:         202739,
+			peerASN:          65001,
+			localASMigration: test.migration,
+		})
+
+		var orig string
+		if test.asPath != nil {
+			orig = test.asPath.String()
+		}
+
+		p := fsm.newRoutePath()
+		p.BGPPath.ASPath = test.asPath
+		fsm.prependMigrationAS(p)
+
+		assert.Equal(t, test.expected, p.BGPPath.ASPath, "Test %q", test.name)
+		if test.asPath != nil {
+			assert.Equal(t, orig, test.asPath.String(), "Test %q: Received AS path must not be modified", test.name)
+		}
+	}
+}
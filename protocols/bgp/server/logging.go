@@ -0,0 +1,7 @@
+package server
+
+import (
+	"github.com/bio-routing/bio-rd/util/logging"
+)
+
+var log = logging.Subsystem("bgp")
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"sync/atomic"
+
 	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
 )
 
 type metricsService struct {
@@ -27,11 +31,27 @@ func (b *metricsService) peerMetrics() []*metrics.BGPPeerMetrics {
 
 func metricsForPeer(peer *peer) *metrics.BGPPeerMetrics {
 	m := &metrics.BGPPeerMetrics{
-		ASN:             peer.peerASN,
-		LocalASN:        peer.localASN,
-		IP:              peer.addr,
-		AddressFamilies: make([]*metrics.BGPAddressFamilyMetrics, 0),
-		VRF:             peer.vrf.Name(),
+		ASN:              peer.peerASN,
+		LocalASN:         peer.localASN,
+		IP:               peer.addr,
+		AddressFamilies:  make([]*metrics.BGPAddressFamilyMetrics, 0),
+		VRF:              peer.vrf.Name(),
+		Flaps:            atomic.LoadUint64(&peer.counters.flaps),
+		PrefixLimitHits:  atomic.LoadUint64(&peer.counters.prefixLimitExceeded),
+		RIBLatency:       peer.counters.ribLatency.Snapshot(),
+		AdjRIBOutLatency: peer.counters.adjRIBOutLatency.Snapshot(),
+
+		MessagesReceived:           peer.counters.messagesReceived.metrics(),
+		MessagesSent:               peer.counters.messagesSent.metrics(),
+		PrefixesAdvertisedReceived: atomic.LoadUint64(&peer.counters.prefixesAdvertisedReceived),
+		PrefixesWithdrawnReceived:  atomic.LoadUint64(&peer.counters.prefixesWithdrawnReceived),
+		PrefixesAdvertisedSent:     atomic.LoadUint64(&peer.counters.prefixesAdvertisedSent),
+		PrefixesWithdrawnSent:      atomic.LoadUint64(&peer.counters.prefixesWithdrawnSent),
+		MalformedAttributes:        atomic.LoadUint64(&peer.counters.malformedAttributes),
+		AttributeErrors:            peer.counters.attributeErrors.metrics(),
+		LastNotificationSent:       peer.notifications.lastSent(),
+		LastNotificationReceived:   peer.notifications.lastReceived(),
+		FSMTransitions:             atomic.LoadUint64(&peer.counters.fsmTransitions),
 	}
 
 	var fsms = peer.fsms
@@ -47,19 +67,17 @@ func metricsForPeer(peer *peer) *metrics.BGPPeerMetrics {
 		m.Since = fsm.establishedTime
 	}
 
-	m.UpdatesReceived = fsm.counters.updatesReceived
-	m.UpdatesSent = fsm.counters.updatesSent
+	m.UpdatesReceived = atomic.LoadUint64(&fsm.counters.updatesReceived)
+	m.UpdatesSent = atomic.LoadUint64(&fsm.counters.updatesSent)
 
 	fsm.stateMu.RLock()
 	defer fsm.stateMu.RUnlock()
 
 	if fsm.ribsInitialized {
-		if peer.ipv4 != nil {
-			m.AddressFamilies = append(m.AddressFamilies, metricsForFamily(fsm.ipv4Unicast))
-		}
-
-		if peer.ipv6 != nil {
-			m.AddressFamilies = append(m.AddressFamilies, metricsForFamily(fsm.ipv6Unicast))
+		for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast} {
+			if f != nil && f.adjRIBIn != nil {
+				m.AddressFamilies = append(m.AddressFamilies, metricsForFamily(f))
+			}
 		}
 	}
 
@@ -73,10 +91,25 @@ func metricsForFamily(family *fsmAddressFamily) *metrics.BGPAddressFamilyMetrics
 		RoutesReceived: uint64(family.adjRIBIn.RouteCount()),
 	}
 
+	if l := family.prefixLimit; l != nil {
+		m.PrefixLimit = l.Max
+		m.PrefixLimitThreshold = l.threshold(m.RoutesReceived)
+		m.PrefixLimitWarning = m.PrefixLimitThreshold != 0
+	}
+
 	if family.adjRIBOut != nil {
 		m.RoutesSent = uint64(family.adjRIBOut.RouteCount())
 	}
 
+	// Counting requires a walk over the adj-RIB-in, so it is only done with origin validation enabled
+	if family.fsm.peer.server != nil && family.fsm.peer.server.rov.enabled() {
+		counts := family.adjRIBIn.(*adjRIBIn.AdjRIBIn).ValidationStateCounts()
+		m.OriginValidation = true
+		m.RoutesValid = counts[vrp.Valid]
+		m.RoutesInvalid = counts[vrp.Invalid]
+		m.RoutesNotFound = counts[vrp.NotFound]
+	}
+
 	return m
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/bio-routing/bio-rd/util/histogram"
 	"github.com/stretchr/testify/assert"
 
 	bnet "github.com/bio-routing/bio-rd/net"
@@ -251,7 +252,59 @@ func TestMetrics(t *testing.T) {
 				t.Fatalf("unecpected error: %v", err)
 			}
 
+			for _, p := range actual.Peers {
+				assert.Equal(t, uint64(0), p.RIBLatency.Count)
+				assert.Equal(t, uint64(0), p.AdjRIBOutLatency.Count)
+				p.RIBLatency = histogram.Snapshot{}
+				p.AdjRIBOutLatency = histogram.Snapshot{}
+			}
+
 			assert.Equal(t, test.expected, actual)
 		})
 	}
 }
+
+func TestResetCounters(t *testing.T) {
+	vrf, _ := vrf.New("inet.0", 0)
+	s := newBGPServer(0, nil)
+
+	newTestPeer := func(addr uint32) *peer {
+		p := &peer{
+			server: s,
+			addr:   bnet.IPv4(addr).Ptr(),
+			vrf:    vrf,
+		}
+		p.counters.flaps = 2
+		p.counters.messagesReceived.count(packet.KeepaliveMsg)
+		p.counters.ribLatency.Observe(time.Millisecond)
+		fsm := newFSM(p)
+		fsm.state = newIdleState(fsm)
+		fsm.counters.updatesReceived = 10
+		fsm.counters.updatesSent = 20
+		p.fsms = append(p.fsms, fsm)
+		s.peers.add(p)
+		return p
+	}
+
+	a := newTestPeer(100)
+	b := newTestPeer(200)
+
+	err := s.ResetCounters(bnet.IPv4(100).Ptr())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), a.counters.flaps)
+	assert.Equal(t, uint64(0), a.counters.ribLatency.Snapshot().Count)
+	assert.Equal(t, metrics.BGPMessageCounts{}, a.counters.messagesReceived.metrics())
+	assert.Equal(t, uint64(0), a.fsms[0].counters.updatesReceived)
+	assert.Equal(t, uint64(0), a.fsms[0].counters.updatesSent)
+	assert.Equal(t, uint64(2), b.counters.flaps)
+	assert.Equal(t, uint64(1), b.counters.ribLatency.Snapshot().Count)
+	assert.Equal(t, uint64(10), b.fsms[0].counters.updatesReceived)
+
+	err = s.ResetCounters(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), b.counters.flaps)
+	assert.Equal(t, uint64(0), b.fsms[0].counters.updatesSent)
+
+	err = s.ResetCounters(bnet.IPv4(300).Ptr())
+	assert.Error(t, err)
+}
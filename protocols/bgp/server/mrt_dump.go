@@ -0,0 +1,449 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/bio-routing/bio-rd/util/mrt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// mrtFlushInterval is the interval buffered update log records are written to disk in
+	mrtFlushInterval = time.Second
+
+	mrtTimeFormat = "20060102.1504"
+)
+
+// MRTDumpConfig is the config of MRT dumps of the server (RFC6396)
+type MRTDumpConfig struct {
+	// Directory is the directory dump files are written to
+	Directory string
+
+	// RIBInterval is the interval adj-RIB-in snapshots are written in (TABLE_DUMP_V2), 0 disables snapshots
+	RIBInterval time.Duration
+
+	// UpdatesInterval is the interval the log of received UPDATE messages (BGP4MP) is rotated in, 0 disables the log
+	UpdatesInterval time.Duration
+}
+
+// mrtDumper writes MRT dumps of all peers
+type mrtDumper struct {
+	server *bgpServer
+
+	mu      sync.RWMutex
+	config  *MRTDumpConfig
+	updates *mrtFile
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// mrtFile is a dump file being written
+type mrtFile struct {
+	f   *os.File
+	buf *bufio.Writer
+	w   *mrt.Writer
+
+	// end is the time the file is rotated at
+	end time.Time
+}
+
+func newMRTDumper(server *bgpServer) *mrtDumper {
+	return &mrtDumper{
+		server: server,
+	}
+}
+
+// SetMRTDump configures MRT dumps, nil disables them
+func (b *bgpServer) SetMRTDump(c *MRTDumpConfig) error {
+	if c != nil {
+		fi, err := os.Stat(c.Directory)
+		if err != nil {
+			return errors.Wrap(err, "Unable to access MRT dump directory")
+		}
+
+		if !fi.IsDir() {
+			return fmt.Errorf("%q is not a directory", c.Directory)
+		}
+	}
+
+	b.mrt.configure(c)
+	return nil
+}
+
+func (d *mrtDumper) configure(c *MRTDumpConfig) {
+	d.mu.RLock()
+	old := d.config
+	d.mu.RUnlock()
+
+	if c == nil && old == nil || c != nil && old != nil && *c == *old {
+		return
+	}
+
+	d.stop()
+	if c == nil {
+		return
+	}
+
+	cfg := *c
+	stopCh := make(chan struct{})
+	d.mu.Lock()
+	d.config = &cfg
+	d.stopCh = stopCh
+	d.mu.Unlock()
+
+	if cfg.UpdatesInterval > 0 {
+		d.wg.Add(1)
+		go d.updatesWorker(cfg, stopCh)
+	}
+
+	if cfg.RIBInterval > 0 {
+		d.wg.Add(1)
+		go d.ribWorker(cfg, stopCh)
+	}
+}
+
+// stop stops all dumps and closes the update log
+func (d *mrtDumper) stop() {
+	d.mu.Lock()
+	if d.config == nil {
+		d.mu.Unlock()
+		return
+	}
+
+	close(d.stopCh)
+	d.config = nil
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+// mrt gets the MRT dumper of the server, nil if there is none
+func (fsm *FSM) mrt() *mrtDumper {
+	if fsm.peer.server == nil {
+		return nil
+	}
+
+	return fsm.peer.server.mrt
+}
+
+// logging checks if received UPDATE messages are logged
+func (d *mrtDumper) logging() bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.updates != nil
+}
+
+// update logs an UPDATE message received from the peer of fsm
+func (d *mrtDumper) update(fsm *FSM, msg []byte) {
+	if !d.logging() {
+		return
+	}
+
+	rec := &mrt.BGP4MPMessage{
+		PeerAS:  fsm.peer.peerASN,
+		LocalAS: fsm.peer.localASN,
+		PeerIP:  fsm.peer.addr,
+		LocalIP: mrtLocalIP(fsm),
+		Message: msg,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.updates == nil {
+		return
+	}
+
+	err := d.updates.w.WriteRecord(time.Now(), rec)
+	if err != nil {
+		log.WithError(err).Error("Unable to write MRT update log")
+	}
+}
+
+// mrtLocalIP gets the local address of the session of fsm, the unspecified address if unknown
+func mrtLocalIP(fsm *FSM) *bnet.IP {
+	if fsm.peer.localAddr != nil {
+		return fsm.peer.localAddr
+	}
+
+	if fsm.con != nil {
+		addr, _ := bmpAddrPort(fsm.con.LocalAddr())
+		if fsm.peer.addr.IsIPv4() {
+			ip, _ := bnet.IPFromBytes(addr[12:])
+			return &ip
+		}
+
+		ip, _ := bnet.IPFromBytes(addr[:])
+		return &ip
+	}
+
+	if fsm.peer.addr.IsIPv4() {
+		return bnet.IPv4(0).Ptr()
+	}
+
+	return bnet.IPv6(0, 0).Ptr()
+}
+
+func (d *mrtDumper) updatesWorker(c MRTDumpConfig, stopCh chan struct{}) {
+	defer crashdump.Recover()
+	defer d.wg.Done()
+
+	t := time.NewTicker(mrtFlushInterval)
+	defer t.Stop()
+
+	d.rotate(c, time.Now())
+	for {
+		select {
+		case <-stopCh:
+			d.closeUpdates()
+			return
+		case now := <-t.C:
+			d.rotate(c, now)
+		}
+	}
+}
+
+// rotate flushes the update log and starts a new file once the interval of the current one ended
+func (d *mrtDumper) rotate(c MRTDumpConfig, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.updates != nil {
+		err := d.updates.buf.Flush()
+		if err != nil {
+			log.WithError(err).Error("Unable to write MRT update log")
+		}
+
+		if now.Before(d.updates.end) {
+			return
+		}
+
+		d.updates.close()
+		d.updates = nil
+	}
+
+	start := now.Truncate(c.UpdatesInterval)
+	f, err := createMRTFile(filepath.Join(c.Directory, "updates."+start.Format(mrtTimeFormat)), os.O_APPEND)
+	if err != nil {
+		log.WithError(err).Error("Unable to create MRT update log")
+		return
+	}
+
+	f.end = start.Add(c.UpdatesInterval)
+	d.updates = f
+}
+
+func (d *mrtDumper) closeUpdates() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.updates != nil {
+		d.updates.close()
+		d.updates = nil
+	}
+}
+
+// createMRTFile opens path for writing, flag is either os.O_APPEND or os.O_TRUNC
+func createMRTFile(path string, flag int) (*mrtFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|flag, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open %q", path)
+	}
+
+	buf := bufio.NewWriter(f)
+	return &mrtFile{
+		f:   f,
+		buf: buf,
+		w:   mrt.NewWriter(buf),
+	}, nil
+}
+
+func (f *mrtFile) close() {
+	err := f.buf.Flush()
+	if err != nil {
+		log.WithError(err).Errorf("Unable to write %q", f.f.Name())
+	}
+
+	f.f.Close()
+}
+
+// ribWorker writes snapshots at multiples of the RIB interval
+func (d *mrtDumper) ribWorker(c MRTDumpConfig, stopCh chan struct{}) {
+	defer crashdump.Recover()
+	defer d.wg.Done()
+
+	for {
+		next := time.Now().Truncate(c.RIBInterval).Add(c.RIBInterval)
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		path := filepath.Join(c.Directory, "rib."+next.Format(mrtTimeFormat))
+		err := d.server.writeRIBSnapshot(path, next)
+		if err != nil {
+			log.WithError(err).Error("Unable to write MRT RIB snapshot")
+		}
+	}
+}
+
+// writeRIBSnapshot writes the adj-RIB-in of all established peers to path. The file is renamed into place once complete.
+func (b *bgpServer) writeRIBSnapshot(path string, ts time.Time) error {
+	f, err := createMRTFile(path+".tmp", os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	err = b.mrtRIBSnapshot(f.w, ts)
+	f.close()
+	if err != nil {
+		os.Remove(f.f.Name())
+		return err
+	}
+
+	return os.Rename(f.f.Name(), path)
+}
+
+// mrtRIBEntry is a path learned from a peer
+type mrtRIBEntry struct {
+	pfx   *bnet.Prefix
+	entry mrt.RIBEntry
+}
+
+// mrtRIBSnapshot writes a peer index table followed by the IPv4 and IPv6 unicast paths of all established peers
+func (b *bgpServer) mrtRIBSnapshot(w *mrt.Writer, ts time.Time) error {
+	fsms := b.establishedFSMs()
+
+	peers := &mrt.PeerIndexTable{
+		CollectorBGPID: b.routerID,
+		Peers:          make([]mrt.Peer, 0, len(fsms)),
+	}
+
+	entries := make([]mrtRIBEntry, 0)
+	for i, fsm := range fsms {
+		peers.Peers = append(peers.Peers, mrt.Peer{
+			BGPID: fsm.neighborID,
+			Addr:  fsm.peer.addr,
+			ASN:   fsm.peer.peerASN,
+		})
+
+		for _, f := range fsm.initializedAddressFamilies() {
+			if f.safi != packet.UnicastSAFI {
+				continue
+			}
+
+			for _, r := range f.adjRIBIn.Dump() {
+				for _, p := range r.Paths() {
+					attrs, err := mrtAttributes(p, f.afi, !fsm.peer.isEBGP())
+					if err != nil {
+						return errors.Wrapf(err, "Unable to encode path of %s", r.Prefix().String())
+					}
+
+					entries = append(entries, mrtRIBEntry{
+						pfx: r.Prefix(),
+						entry: mrt.RIBEntry{
+							PeerIndex:      uint16(i),
+							OriginatedTime: fsm.establishedTime,
+							Attributes:     attrs,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	err := w.WriteRecord(ts, peers)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lessPrefix(entries[i].pfx, entries[j].pfx)
+	})
+
+	var rib *mrt.RIB
+	seq := uint32(0)
+	for _, e := range entries {
+		if rib != nil && !rib.Prefix.Equal(e.pfx) {
+			err := w.WriteRecord(ts, rib)
+			if err != nil {
+				return err
+			}
+
+			rib = nil
+			seq++
+		}
+
+		if rib == nil {
+			rib = &mrt.RIB{
+				SequenceNumber: seq,
+				Prefix:         e.pfx,
+			}
+		}
+
+		rib.Entries = append(rib.Entries, e.entry)
+	}
+
+	if rib != nil {
+		return w.WriteRecord(ts, rib)
+	}
+
+	return nil
+}
+
+// lessPrefix orders IPv4 before IPv6 prefixes, then by address and length
+func lessPrefix(a *bnet.Prefix, b *bnet.Prefix) bool {
+	if a.Addr().IsIPv4() != b.Addr().IsIPv4() {
+		return a.Addr().IsIPv4()
+	}
+
+	if c := bytes.Compare(a.Addr().Bytes(), b.Addr().Bytes()); c != 0 {
+		return c < 0
+	}
+
+	return a.Pfxlen() < b.Pfxlen()
+}
+
+// mrtAttributes encodes the path attributes of a RIB entry. IPv6 next hops are carried in MP_REACH_NLRI.
+func mrtAttributes(p *route.Path, afi uint16, iBGP bool) ([]byte, error) {
+	pa, err := packet.PathAttributes(p, iBGP, p.BGPPath.ClusterList != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextHop *bnet.IP
+	if afi == packet.IPv6AFI {
+		pa, nextHop = copyAttributesWithoutNextHop(pa)
+	}
+
+	buf := &bytes.Buffer{}
+	opt := &packet.EncodeOptions{
+		Use32BitASN: true,
+	}
+	for a := pa; a != nil; a = a.Next {
+		a.Serialize(buf, opt)
+	}
+
+	if nextHop != nil {
+		buf.Write(mrt.MPReachNextHop(nextHop))
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMRTAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		afi      uint16
+		nextHop  *bnet.IP
+		expected []byte
+	}{
+		{
+			name:    "IPv4",
+			afi:     packet.IPv4AFI,
+			nextHop: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+			expected: []byte{
+				0x40, 2, 6, 2, 1, 0, 0, 0xfd, 0xe9, // AS_PATH
+				0x40, 1, 1, 0, // ORIGIN
+				0x40, 3, 4, 192, 0, 2, 1, // NEXT_HOP
+			},
+		},
+		{
+			name:    "IPv6",
+			afi:     packet.IPv6AFI,
+			nextHop: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+			expected: []byte{
+				0x40, 2, 6, 2, 1, 0, 0, 0xfd, 0xe9, // AS_PATH
+				0x40, 1, 1, 0, // ORIGIN
+				0x80, 14, 17, 16, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // MP_REACH_NLRI
+			},
+		},
+	}
+
+	for _, test := range tests {
+		p := &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					NextHop: test.nextHop,
+				},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001},
+					},
+				},
+			},
+		}
+
+		attrs, err := mrtAttributes(p, test.afi, false)
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, attrs, "Test %q", test.name)
+	}
+}
+
+func TestLessPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        *bnet.Prefix
+		b        *bnet.Prefix
+		expected bool
+	}{
+		{
+			name:     "IPv4 before IPv6",
+			a:        bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+			b:        bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+			expected: true,
+		},
+		{
+			name: "Higher address",
+			a:    bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr(),
+			b:    bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+		},
+		{
+			name:     "Shorter prefix",
+			a:        bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+			b:        bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 16).Ptr(),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, lessPrefix(test.a, test.b), "Test %q", test.name)
+	}
+}
+
+func TestMRTUpdateLog(t *testing.T) {
+	dir := t.TempDir()
+	c := MRTDumpConfig{
+		Directory:       dir,
+		UpdatesInterval: 15 * time.Minute,
+	}
+
+	now := time.Date(2020, 1, 2, 3, 20, 0, 0, time.UTC)
+	d := newMRTDumper(nil)
+	d.rotate(c, now)
+
+	fsm := newFSM(&peer{
+		addr:      bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		localAddr: bnet.IPv4FromOctets(192, 0, 2, 2).Ptr(),
+		peerASN:   65001,
+		localASN:  65000,
+	})
+	msg := packet.SerializeKeepaliveMsg()
+	d.update(fsm, msg)
+	d.closeUpdates()
+
+	b, err := os.ReadFile(filepath.Join(dir, "updates.20200102.0315"))
+	if err != nil {
+		t.Fatalf("Unable to read update log: %v", err)
+	}
+
+	assert.Equal(t, []byte{
+		0, 16, // Type
+		0, 4, // Subtype
+		0, 0, 0, 39, // Length
+		0, 0, 0xfd, 0xe9, // Peer AS
+		0, 0, 0xfd, 0xe8, // Local AS
+		0, 0, // Interface index
+		0, 1, // AFI
+		192, 0, 2, 1, // Peer address
+		192, 0, 2, 2, // Local address
+	}, b[4:32])
+	assert.Equal(t, msg, b[32:])
+}
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/bio-routing/bio-rd/util/mrt"
+	"github.com/pkg/errors"
+)
+
+const (
+	attrFlagOptional       = 0x80
+	attrFlagExtendedLength = 0x10
+)
+
+// MRTReplayOptions are the options of an MRT replay
+type MRTReplayOptions struct {
+	// Peer is the peer the routes are injected into the adj-RIB-in of. The session must be established.
+	Peer *bnet.IP
+
+	// SourcePeer selects the routes of a single peer of the dump, all peers if nil
+	SourcePeer *bnet.IP
+
+	// Speed is the factor the time between BGP4MP records is scaled by (1 = real time), 0 replays as fast as possible
+	Speed float64
+}
+
+// ReplayMRT injects the routes of a TABLE_DUMP_V2 RIB dump or the UPDATE messages of a BGP4MP log into the adj-RIB-in
+// of a peer as if they were received from it. It returns the number of UPDATE messages injected.
+func (b *bgpServer) ReplayMRT(ctx context.Context, r io.Reader, opt MRTReplayOptions) (uint64, error) {
+	if opt.Peer == nil {
+		return 0, fmt.Errorf("No peer given")
+	}
+
+	if opt.Speed < 0 {
+		return 0, fmt.Errorf("Invalid speed %f", opt.Speed)
+	}
+
+	fsm := b.establishedFSM(opt.Peer)
+	if fsm == nil {
+		return 0, fmt.Errorf("Peer %q not found or not established", opt.Peer.String())
+	}
+
+	rp := &mrtReplay{
+		fsm: fsm,
+		opt: opt,
+	}
+
+	return rp.run(ctx, mrt.NewReader(r))
+}
+
+// establishedFSM gets the established FSM of a peer, nil if there is none
+func (b *bgpServer) establishedFSM(addr *bnet.IP) *FSM {
+	for _, fsm := range b.establishedFSMs() {
+		if fsm.peer.addr.Equal(addr) {
+			return fsm
+		}
+	}
+
+	return nil
+}
+
+type mrtReplay struct {
+	fsm   *FSM
+	opt   MRTReplayOptions
+	peers *mrt.PeerIndexTable
+
+	// first is the timestamp of the first record, start the time it was replayed at
+	first time.Time
+	start time.Time
+
+	count uint64
+}
+
+func (rp *mrtReplay) run(ctx context.Context, r *mrt.Reader) (uint64, error) {
+	for {
+		ts, rec, err := r.Read()
+		if err == io.EOF {
+			return rp.count, nil
+		}
+		if err != nil {
+			return rp.count, err
+		}
+
+		err = rp.wait(ctx, ts)
+		if err != nil {
+			return rp.count, err
+		}
+
+		err = rp.record(ctx, rec)
+		if err != nil {
+			return rp.count, err
+		}
+	}
+}
+
+// wait delays the replay of a record according to its timestamp and the configured speed
+func (rp *mrtReplay) wait(ctx context.Context, ts time.Time) error {
+	if rp.opt.Speed == 0 {
+		return nil
+	}
+
+	if rp.start.IsZero() {
+		rp.first = ts
+		rp.start = time.Now()
+		return nil
+	}
+
+	d := time.Duration(float64(ts.Sub(rp.first))/rp.opt.Speed) - time.Since(rp.start)
+	if d <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (rp *mrtReplay) record(ctx context.Context, rec mrt.Record) error {
+	switch rec := rec.(type) {
+	case *mrt.PeerIndexTable:
+		rp.peers = rec
+	case *mrt.RIB:
+		return rp.rib(ctx, rec)
+	case *mrt.BGP4MPMessage:
+		return rp.message(ctx, rec)
+	}
+
+	return nil
+}
+
+func (rp *mrtReplay) rib(ctx context.Context, rib *mrt.RIB) error {
+	if rp.peers == nil {
+		return fmt.Errorf("RIB record without preceding peer index table")
+	}
+
+	for _, e := range rib.Entries {
+		if int(e.PeerIndex) >= len(rp.peers.Peers) {
+			return fmt.Errorf("Invalid peer index %d", e.PeerIndex)
+		}
+
+		if rp.opt.SourcePeer != nil && !rp.peers.Peers[e.PeerIndex].Addr.Equal(rp.opt.SourcePeer) {
+			continue
+		}
+
+		msg, err := mrtRIBEntryUpdate(rib.Prefix, e.Attributes)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to convert entry of %s", rib.Prefix.String())
+		}
+
+		err = rp.inject(ctx, msg, true)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to inject entry of %s", rib.Prefix.String())
+		}
+	}
+
+	return nil
+}
+
+func (rp *mrtReplay) message(ctx context.Context, m *mrt.BGP4MPMessage) error {
+	if rp.opt.SourcePeer != nil && !m.PeerIP.Equal(rp.opt.SourcePeer) {
+		return nil
+	}
+
+	if len(m.Message) < packet.HeaderLen || m.Message[packet.MarkerLen+2] != packet.UpdateMsg {
+		return nil
+	}
+
+	return rp.inject(ctx, m.Message, !m.AS2)
+}
+
+// inject decodes an UPDATE message and passes it to the established state of the FSM
+func (rp *mrtReplay) inject(ctx context.Context, msg []byte, as4 bool) error {
+	m, err := packet.Decode(bytes.NewBuffer(msg), &packet.DecodeOptions{
+		Use32BitASN: as4,
+	})
+	if err != nil {
+		return err
+	}
+
+	u, ok := m.Body.(*packet.BGPUpdate)
+	if !ok {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case rp.fsm.injectCh <- u:
+			rp.count++
+			return nil
+		case <-time.After(time.Second):
+			rp.fsm.stateMu.RLock()
+			established := isEstablishedState(rp.fsm.state)
+			rp.fsm.stateMu.RUnlock()
+
+			if !established {
+				return fmt.Errorf("Session went down")
+			}
+		}
+	}
+}
+
+// mrtRIBEntryUpdate builds an UPDATE message announcing pfx with the attributes of a RIB entry. The abbreviated
+// MP_REACH_NLRI of IPv6 entries (RFC6396 4.3.4) is expanded to a complete one carrying the prefix.
+func mrtRIBEntryUpdate(pfx *bnet.Prefix, attrs []byte) ([]byte, error) {
+	nlri := &bytes.Buffer{}
+	nlri.WriteByte(pfx.Pfxlen())
+	nlri.Write(pfx.Addr().Bytes()[:(pfx.Pfxlen()+7)/8])
+
+	pa := &bytes.Buffer{}
+	if pfx.Addr().IsIPv4() {
+		pa.Write(attrs)
+	} else {
+		err := expandMPReach(pa, attrs, nlri.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		nlri.Reset()
+	}
+
+	body := &bytes.Buffer{}
+	endian.WriteUint16(body, 0) // Withdrawn routes length
+	endian.WriteUint16(body, uint16(pa.Len()))
+	body.Write(pa.Bytes())
+	body.Write(nlri.Bytes())
+
+	if packet.MinLen+body.Len() > 0xffff {
+		return nil, fmt.Errorf("Attributes too long")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(bytes.Repeat([]byte{0xff}, packet.MarkerLen))
+	endian.WriteUint16(buf, uint16(packet.MinLen+body.Len()))
+	buf.WriteByte(packet.UpdateMsg)
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// expandMPReach copies attrs to buf replacing the next hop only MP_REACH_NLRI by one for IPv6 unicast carrying nlri
+func expandMPReach(buf *bytes.Buffer, attrs []byte, nlri []byte) error {
+	found := false
+	for len(attrs) > 0 {
+		if len(attrs) < 3 {
+			return fmt.Errorf("Attribute header truncated")
+		}
+
+		flags, typeCode := attrs[0], attrs[1]
+		hdrLen, l := 3, int(attrs[2])
+		if flags&attrFlagExtendedLength != 0 {
+			if len(attrs) < 4 {
+				return fmt.Errorf("Attribute header truncated")
+			}
+
+			hdrLen, l = 4, int(attrs[2])<<8|int(attrs[3])
+		}
+
+		if len(attrs) < hdrLen+l {
+			return fmt.Errorf("Attribute %d truncated", typeCode)
+		}
+
+		value := attrs[hdrLen : hdrLen+l]
+		if typeCode != packet.MultiProtocolReachNLRICode {
+			buf.Write(attrs[:hdrLen+l])
+			attrs = attrs[hdrLen+l:]
+			continue
+		}
+
+		if l < 1 || l < 1+int(value[0]) {
+			return fmt.Errorf("MP_REACH_NLRI truncated")
+		}
+
+		nextHop := value[:1+int(value[0])]
+		mp := &bytes.Buffer{}
+		endian.WriteUint16(mp, packet.IPv6AFI)
+		mp.WriteByte(packet.UnicastSAFI)
+		mp.Write(nextHop)
+		mp.WriteByte(0) // Reserved
+		mp.Write(nlri)
+
+		buf.WriteByte(attrFlagOptional | attrFlagExtendedLength)
+		buf.WriteByte(packet.MultiProtocolReachNLRICode)
+		endian.WriteUint16(buf, uint16(mp.Len()))
+		buf.Write(mp.Bytes())
+
+		found = true
+		attrs = attrs[hdrLen+l:]
+	}
+
+	if !found {
+		return fmt.Errorf("MP_REACH_NLRI missing")
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMRTRIBEntryUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		pfx      *bnet.Prefix
+		attrs    []byte
+		wantFail bool
+		nlri     *bnet.Prefix
+		mpReach  *packet.MultiProtocolReachNLRI
+	}{
+		{
+			name: "IPv4",
+			pfx:  bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 22).Ptr(),
+			attrs: []byte{
+				0x40, 1, 1, 0, // ORIGIN
+				0x40, 2, 6, 2, 1, 0, 0, 0xfd, 0xe9, // AS_PATH
+				0x40, 3, 4, 192, 0, 2, 1, // NEXT_HOP
+			},
+			nlri: bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 22).Ptr(),
+		},
+		{
+			name: "IPv6",
+			pfx:  bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0x100, 0, 0, 0, 0, 0), 40).Ptr(),
+			attrs: []byte{
+				0x40, 1, 1, 0, // ORIGIN
+				0x40, 2, 6, 2, 1, 0, 0, 0xfd, 0xe9, // AS_PATH
+				0x80, 14, 17, 16, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // MP_REACH_NLRI
+			},
+			mpReach: &packet.MultiProtocolReachNLRI{
+				AFI:     packet.IPv6AFI,
+				SAFI:    packet.UnicastSAFI,
+				NextHop: bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+			},
+		},
+		{
+			name: "IPv6 without MP_REACH_NLRI",
+			pfx:  bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+			attrs: []byte{
+				0x40, 1, 1, 0, // ORIGIN
+			},
+			wantFail: true,
+		},
+		{
+			name: "Truncated attribute",
+			pfx:  bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+			attrs: []byte{
+				0x40, 2, 6, 2, 1, 0,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		msg, err := mrtRIBEntryUpdate(test.pfx, test.attrs)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		m, err := packet.Decode(bytes.NewBuffer(msg), &packet.DecodeOptions{
+			Use32BitASN: true,
+		})
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		u := m.Body.(*packet.BGPUpdate)
+		if test.nlri != nil {
+			assert.Equal(t, test.nlri, u.NLRI.Prefix, "Test %q", test.name)
+		}
+
+		if test.mpReach != nil {
+			var mp *packet.MultiProtocolReachNLRI
+			for a := u.PathAttributes; a != nil; a = a.Next {
+				if a.TypeCode == packet.MultiProtocolReachNLRICode {
+					v := a.Value.(packet.MultiProtocolReachNLRI)
+					mp = &v
+				}
+			}
+
+			if !assert.NotNil(t, mp, "Test %q", test.name) {
+				continue
+			}
+
+			assert.Equal(t, test.mpReach.AFI, mp.AFI, "Test %q", test.name)
+			assert.Equal(t, test.mpReach.SAFI, mp.SAFI, "Test %q", test.name)
+			assert.Equal(t, test.mpReach.NextHop, mp.NextHop, "Test %q", test.name)
+			assert.Equal(t, test.pfx, mp.NLRI.Prefix, "Test %q", test.name)
+		}
+	}
+}
+
+func TestReplayMRTPeerNotEstablished(t *testing.T) {
+	b := newBGPServer(0, nil)
+	_, err := b.ReplayMRT(context.Background(), bytes.NewBuffer(nil), MRTReplayOptions{
+		Peer: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"math"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/sirupsen/logrus"
+)
+
+// nextHopTracker sits between the adj-RIB-ins of peers with next hop tracking and a Loc-RIB. Paths are installed only
+// while their next hop resolves to a non BGP route (e.g. static or IGP) of the same RIB. Resolution is rechecked whenever
+// a non BGP route of the RIB changes.
+type nextHopTracker struct {
+	rib *locRIB.LocRIB
+
+	// guarded by mu, which must not be taken while the Loc-RIB notifies the watcher
+	mu       sync.Mutex
+	nextHops map[bnet.IP]*trackedNextHop
+
+	pendingMu sync.Mutex
+	pending   []*bnet.Prefix
+	pendingCh chan struct{}
+}
+
+// trackedNextHop holds all paths using a next hop
+type trackedNextHop struct {
+	addr     *bnet.IP
+	resolved bool
+	paths    map[bnet.Prefix][]*route.Path
+}
+
+// nextHopWatcher receives changes of the Loc-RIB. It is separate from the tracker as both get BGP paths.
+type nextHopWatcher struct {
+	t *nextHopTracker
+}
+
+func newNextHopTracker(rib *locRIB.LocRIB) *nextHopTracker {
+	return &nextHopTracker{
+		rib:       rib,
+		nextHops:  make(map[bnet.IP]*trackedNextHop),
+		pendingCh: make(chan struct{}, 1),
+	}
+}
+
+// nextHopTracker gets the next hop tracker of a RIB. It is created and started on first use.
+func (b *bgpServer) nextHopTracker(rib *locRIB.LocRIB) *nextHopTracker {
+	b.nextHopTrackersMu.Lock()
+	defer b.nextHopTrackersMu.Unlock()
+
+	if t, ok := b.nextHopTrackers[rib]; ok {
+		return t
+	}
+
+	if b.nextHopTrackers == nil {
+		b.nextHopTrackers = make(map[*locRIB.LocRIB]*nextHopTracker)
+	}
+
+	t := newNextHopTracker(rib)
+	b.nextHopTrackers[rib] = t
+
+	// Resolving routes may be hidden behind better BGP paths, so all paths are needed
+	rib.RegisterWithOptions(&nextHopWatcher{t: t}, routingtable.ClientOptions{
+		MaxPaths: math.MaxInt32,
+	})
+	go t.run()
+
+	return t
+}
+
+func (t *nextHopTracker) run() {
+	defer crashdump.Recover()
+
+	for range t.pendingCh {
+		t.process()
+	}
+}
+
+// process rechecks the resolution of all next hops covered by changed routes
+func (t *nextHopTracker) process() {
+	t.pendingMu.Lock()
+	changed := t.pending
+	t.pending = nil
+	t.pendingMu.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, nh := range t.nextHops {
+		if !coveredByAny(nh.addr, changed) {
+			continue
+		}
+
+		resolved := t.resolve(nh.addr)
+		if resolved == nh.resolved {
+			continue
+		}
+
+		nh.resolved = resolved
+		log.WithFields(logrus.Fields{
+			"rib":      t.rib.Name(),
+			"next_hop": nh.addr.String(),
+			"resolved": resolved,
+		}).Info("Next hop resolution changed")
+
+		for pfx, paths := range nh.paths {
+			pfx := pfx
+			for _, p := range paths {
+				if resolved {
+					t.rib.AddPath(&pfx, p)
+				} else {
+					t.rib.RemovePath(&pfx, p)
+				}
+			}
+		}
+	}
+}
+
+func coveredByAny(addr *bnet.IP, pfxs []*bnet.Prefix) bool {
+	host := hostPrefix(addr)
+	for _, pfx := range pfxs {
+		if pfx.Equal(host) || pfx.Contains(host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hostPrefix(addr *bnet.IP) *bnet.Prefix {
+	if addr.IsIPv4() {
+		return bnet.NewPfx(*addr, 32).Ptr()
+	}
+
+	return bnet.NewPfx(*addr, 128).Ptr()
+}
+
+// resolve checks if the most specific route covering addr with a non BGP path exists. BGP paths never resolve
+// next hops to prevent recursion.
+func (t *nextHopTracker) resolve(addr *bnet.IP) bool {
+	routes := t.rib.LPM(hostPrefix(addr))
+	for i := len(routes) - 1; i >= 0; i-- {
+		for _, p := range routes[i].Paths() {
+			if p.Type != route.BGPPathType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (t *nextHopTracker) changed(pfx *bnet.Prefix) {
+	t.pendingMu.Lock()
+	t.pending = append(t.pending, pfx)
+	t.pendingMu.Unlock()
+
+	select {
+	case t.pendingCh <- struct{}{}:
+	default:
+	}
+}
+
+// AddPath tracks the next hop of a path and installs the path into the Loc-RIB if the next hop is resolved
+func (t *nextHopTracker) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.addPath(pfx, p)
+}
+
+func (t *nextHopTracker) addPath(pfx *bnet.Prefix, p *route.Path) error {
+	addr := p.NextHop()
+	if addr == nil {
+		return nil
+	}
+
+	nh, ok := t.nextHops[*addr]
+	if !ok {
+		nh = &trackedNextHop{
+			addr:     addr,
+			resolved: t.resolve(addr),
+			paths:    make(map[bnet.Prefix][]*route.Path),
+		}
+		t.nextHops[*addr] = nh
+	}
+
+	nh.paths[*pfx] = append(nh.paths[*pfx], p)
+	if !nh.resolved {
+		return nil
+	}
+
+	return t.rib.AddPath(pfx, p)
+}
+
+// AddPathInitialDump adds a path
+func (t *nextHopTracker) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return t.AddPath(pfx, p)
+}
+
+// RemovePath stops tracking a path and removes it from the Loc-RIB
+func (t *nextHopTracker) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.removePath(pfx, p)
+}
+
+func (t *nextHopTracker) removePath(pfx *bnet.Prefix, p *route.Path) bool {
+	addr := p.NextHop()
+	if addr == nil {
+		return true
+	}
+
+	nh, ok := t.nextHops[*addr]
+	if !ok {
+		return t.rib.RemovePath(pfx, p)
+	}
+
+	paths := nh.paths[*pfx]
+	for i := range paths {
+		if !paths[i].Compare(p) {
+			continue
+		}
+
+		paths = append(paths[:i], paths[i+1:]...)
+		break
+	}
+
+	if len(paths) == 0 {
+		delete(nh.paths, *pfx)
+	} else {
+		nh.paths[*pfx] = paths
+	}
+
+	if len(nh.paths) == 0 {
+		delete(t.nextHops, *addr)
+	}
+
+	if !nh.resolved {
+		return true
+	}
+
+	return t.rib.RemovePath(pfx, p)
+}
+
+// ReplacePath replaces a path changed by filtering or validation
+func (t *nextHopTracker) ReplacePath(pfx *bnet.Prefix, old *route.Path, new *route.Path) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.removePath(pfx, old)
+	t.addPath(pfx, new)
+}
+
+// RefreshRoute is here to fulfill an interface
+func (t *nextHopTracker) RefreshRoute(*bnet.Prefix, []*route.Path) {}
+
+// unresolvedCount gets the number of paths not installed due to unresolved next hops
+func (t *nextHopTracker) unresolvedCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, nh := range t.nextHops {
+		if nh.resolved {
+			continue
+		}
+
+		for _, paths := range nh.paths {
+			n += len(paths)
+		}
+	}
+
+	return n
+}
+
+// AddPath schedules the recheck of next hops covered by a non BGP route
+func (w *nextHopWatcher) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	if p.Type != route.BGPPathType {
+		w.t.changed(pfx)
+	}
+
+	return nil
+}
+
+// AddPathInitialDump adds a path
+func (w *nextHopWatcher) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return w.AddPath(pfx, p)
+}
+
+// RemovePath schedules the recheck of next hops covered by a non BGP route
+func (w *nextHopWatcher) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	if p.Type != route.BGPPathType {
+		w.t.changed(pfx)
+	}
+
+	return true
+}
+
+// ReplacePath schedules the recheck of next hops covered by a non BGP route
+func (w *nextHopWatcher) ReplacePath(pfx *bnet.Prefix, old *route.Path, new *route.Path) {
+	if old.Type != route.BGPPathType || new.Type != route.BGPPathType {
+		w.t.changed(pfx)
+	}
+}
+
+// RefreshRoute is here to fulfill an interface
+func (w *nextHopWatcher) RefreshRoute(*bnet.Prefix, []*route.Path) {}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"math"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextHopTracker(t *testing.T) {
+	rib := locRIB.New("inet.0")
+	tracker := newNextHopTracker(rib)
+	rib.RegisterWithOptions(&nextHopWatcher{t: tracker}, routingtable.ClientOptions{
+		MaxPaths: math.MaxInt32,
+	})
+
+	bgpPath := func(nh *bnet.IP) *route.Path {
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					NextHop:   nh,
+					Source:    bnet.IPv4FromOctets(192, 0, 2, 100).Ptr(),
+					LocalPref: 100,
+				},
+			},
+		}
+	}
+
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	nhNet := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr()
+	nhMoreSpecific := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 25).Ptr()
+	static := &route.Path{
+		Type: route.StaticPathType,
+		StaticPath: &route.StaticPath{
+			NextHop: bnet.IPv4FromOctets(198, 51, 100, 1).Ptr(),
+		},
+	}
+
+	p := bgpPath(bnet.IPv4FromOctets(192, 0, 2, 1).Ptr())
+	tracker.AddPath(pfx, p)
+	assert.Nil(t, rib.Get(pfx), "Paths with unresolved next hop must not be installed")
+	assert.Equal(t, 1, tracker.unresolvedCount())
+
+	rib.AddPath(nhNet, static)
+	tracker.process()
+	assert.NotNil(t, rib.Get(pfx), "Path must be installed after its next hop has been resolved")
+	assert.Equal(t, 0, tracker.unresolvedCount())
+
+	rib.AddPath(nhMoreSpecific, bgpPath(bnet.IPv4FromOctets(203, 0, 113, 1).Ptr()))
+	tracker.process()
+	assert.NotNil(t, rib.Get(pfx), "BGP routes must not affect resolution")
+
+	rib.RemovePath(nhNet, static)
+	tracker.process()
+	assert.Equal(t, 0, len(rib.Get(pfx).Paths()), "Path must be removed after its next hop became unresolved")
+	assert.Equal(t, 1, tracker.unresolvedCount())
+
+	tracker.RemovePath(pfx, p)
+	assert.Equal(t, 0, tracker.unresolvedCount())
+	assert.Equal(t, 0, len(tracker.nextHops))
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"math"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/util/dijkstra"
+)
+
+// orrGroups holds the optimal route reflection groups (RFC9107). Best paths advertised to the members of a group are
+// selected using the IGP costs from the location of the group instead of those of the route reflector.
+type orrGroups struct {
+	// mu is held while registering members with their Loc-RIB so they never miss a change of the IGP costs
+	mu     sync.Mutex
+	groups map[string]*orrGroup
+}
+
+type orrGroup struct {
+	costs   *route.IGPCostTable
+	members map[*fsmAddressFamily]struct{}
+}
+
+func newORRGroups() *orrGroups {
+	return &orrGroups{
+		groups: make(map[string]*orrGroup),
+	}
+}
+
+// SetORRIGPCosts sets the IGP costs from the location of optimal route reflection group name to the BGP next hops, e.g.
+// taken from the shortest path tree of the IGP rooted at the location of the clients of the group (see ORRIGPCosts).
+// Until costs are set (or if costs is nil) the members of the group are advertised the best paths of the Loc-RIB.
+func (b *bgpServer) SetORRIGPCosts(group string, costs *route.IGPCostTable) {
+	b.orr.setCosts(group, costs)
+}
+
+// ORRIGPCosts converts a shortest path tree rooted at the location of an optimal route reflection group into IGP costs.
+// addrs gets the addresses BGP next hops of node n are resolved to, e.g. its loopback addresses.
+func ORRIGPCosts[N comparable, E any](spt dijkstra.SPT[N, E], addrs func(n N) []bnet.IP) *route.IGPCostTable {
+	costs := make(map[bnet.IP]uint32)
+	for n, p := range spt {
+		if p.Distance < 0 {
+			continue
+		}
+
+		cost := uint32(math.MaxUint32)
+		if p.Distance < math.MaxUint32 {
+			cost = uint32(p.Distance)
+		}
+
+		for _, addr := range addrs(n) {
+			costs[addr] = cost
+		}
+	}
+
+	return route.NewIGPCostTable(costs)
+}
+
+func (o *orrGroups) group(name string) *orrGroup {
+	g, ok := o.groups[name]
+	if !ok {
+		g = &orrGroup{
+			members: make(map[*fsmAddressFamily]struct{}),
+		}
+		o.groups[name] = g
+	}
+
+	return g
+}
+
+func (g *orrGroup) igpCosts() route.IGPCosts {
+	if g.costs == nil {
+		return nil
+	}
+
+	return g.costs
+}
+
+func (o *orrGroups) setCosts(name string, costs *route.IGPCostTable) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	g := o.group(name)
+	g.costs = costs
+	for f := range g.members {
+		f.rib.SetClientIGPCosts(f.adjRIBOut, g.igpCosts())
+	}
+}
+
+// register registers the Adj-RIB-Out of f with its Loc-RIB using the IGP costs of group name
+func (o *orrGroups) register(name string, f *fsmAddressFamily) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	g := o.group(name)
+	g.members[f] = struct{}{}
+
+	opts := f.addPathTX
+	opts.IGPCosts = g.igpCosts()
+	f.rib.RegisterWithOptions(f.adjRIBOut, opts)
+}
+
+func (o *orrGroups) unregister(name string, f *fsmAddressFamily) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	g, ok := o.groups[name]
+	if !ok {
+		return
+	}
+
+	delete(g.members, f)
+	if len(g.members) == 0 && g.costs == nil {
+		delete(o.groups, name)
+	}
+}
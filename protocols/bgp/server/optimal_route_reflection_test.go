@@ -0,0 +1,98 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBOut"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/dijkstra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestORRIGPCosts(t *testing.T) {
+	a := dijkstra.Node{Name: "A"}
+	b := dijkstra.Node{Name: "B"}
+	c := dijkstra.Node{Name: "C"}
+
+	topo := dijkstra.NewTopology([]dijkstra.Node{a, b, c}, []dijkstra.Edge[dijkstra.Node, struct{}]{
+		{
+			NodeA:    a,
+			NodeB:    b,
+			Distance: 10,
+		},
+	})
+
+	addrs := map[dijkstra.Node][]bnet.IP{
+		a: {bnet.IPv4FromOctets(10, 0, 0, 1)},
+		b: {bnet.IPv4FromOctets(10, 0, 0, 2), bnet.IPv4FromOctets(10, 0, 1, 2)},
+		c: {bnet.IPv4FromOctets(10, 0, 0, 3)},
+	}
+
+	costs := ORRIGPCosts(topo.SPT(a), func(n dijkstra.Node) []bnet.IP {
+		return addrs[n]
+	})
+
+	assert.Equal(t, route.NewIGPCostTable(map[bnet.IP]uint32{
+		bnet.IPv4FromOctets(10, 0, 0, 1): 0,
+		bnet.IPv4FromOctets(10, 0, 0, 2): 10,
+		bnet.IPv4FromOctets(10, 0, 1, 2): 10,
+	}), costs)
+}
+
+func TestORRGroups(t *testing.T) {
+	nh1 := bnet.IPv4FromOctets(10, 0, 0, 1)
+	nh2 := bnet.IPv4FromOctets(10, 0, 0, 2)
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24).Ptr()
+
+	rib := locRIB.New("inet.0")
+	for _, nh := range []bnet.IP{nh1, nh2} {
+		rib.AddPath(pfx, &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					EBGP:      true,
+					LocalPref: 100,
+					NextHop:   nh.Ptr(),
+					Source:    nh.Ptr(),
+				},
+			},
+		})
+	}
+
+	f := &fsmAddressFamily{
+		rib: rib,
+		adjRIBOut: adjRIBOut.New(rib, &routingtable.Neighbor{
+			Type:                 route.BGPPathType,
+			Address:              bnet.IPv4FromOctets(10, 0, 0, 100).Ptr(),
+			IBGP:                 true,
+			RouteReflectorClient: true,
+		}, filter.NewAcceptAllFilterChain(), false),
+		addPathTX: routingtable.ClientOptions{
+			BestOnly: true,
+		},
+	}
+
+	nextHop := func() *bnet.IP {
+		return f.adjRIBOut.(*adjRIBOut.AdjRIBOut).Get(pfx).BestPath().BGPPath.BGPPathA.NextHop
+	}
+
+	o := newORRGroups()
+	o.register("pop1", f)
+	assert.Equal(t, nh1, *nextHop(), "Best path of the Loc-RIB without IGP costs")
+
+	o.setCosts("pop1", route.NewIGPCostTable(map[bnet.IP]uint32{
+		nh1: 20,
+		nh2: 10,
+	}))
+	assert.Equal(t, nh2, *nextHop(), "IGP costs of the group")
+
+	o.setCosts("pop1", nil)
+	assert.Equal(t, nh1, *nextHop(), "IGP costs removed")
+
+	o.unregister("pop1", f)
+	assert.Empty(t, o.groups)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/sirupsen/logrus"
+)
+
+// originValidation holds the VRPs received routes are validated against (RFC6811)
+type originValidation struct {
+	mu    sync.RWMutex
+	table *vrp.Table
+}
+
+func (o *originValidation) getTable() *vrp.Table {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.table
+}
+
+// enabled returns if VRPs have been set
+func (o *originValidation) enabled() bool {
+	return o.getTable() != nil
+}
+
+// peerOriginValidator validates the paths received from a peer
+type peerOriginValidator struct {
+	ov       *originValidation
+	localASN uint32
+}
+
+// Validate computes the origin validation state of a path. Paths are not validated as long as no VRPs have been set.
+func (v *peerOriginValidator) Validate(pfx *bnet.Prefix, p *route.Path) vrp.ValidationState {
+	t := v.ov.getTable()
+	if t == nil {
+		return vrp.NotValidated
+	}
+
+	return t.Validate(pfx, originASN(p, v.localASN))
+}
+
+// originASN gets the origin AS of a path. Returns nil if it can not be determined because the path ends with an AS_SET (RFC6907 2.1).
+// Routes with an empty AS path originate from the local AS.
+func originASN(p *route.Path, localASN uint32) *uint32 {
+	if p.BGPPath == nil || p.BGPPath.ASPath == nil || len(*p.BGPPath.ASPath) == 0 {
+		return &localASN
+	}
+
+	last := (*p.BGPPath.ASPath)[len(*p.BGPPath.ASPath)-1]
+	if last.Type != types.ASSequence {
+		return nil
+	}
+
+	return last.GetLastASN()
+}
+
+// SetVRPs sets the VRPs received routes are validated against. Origin validation is disabled until VRPs are set for the first time.
+// All routes already received are revalidated.
+func (b *bgpServer) SetVRPs(vrps []vrp.VRP) {
+	t := vrp.NewTable(vrps)
+
+	b.rov.mu.Lock()
+	b.rov.table = t
+	b.rov.mu.Unlock()
+
+	changed := 0
+	for _, p := range b.peers.list() {
+		changed += p.revalidate()
+	}
+
+	log.WithFields(logrus.Fields{
+		"vrps":    t.Count(),
+		"changed": changed,
+	}).Info("Updated VRPs for origin validation")
+}
+
+func (b *bgpServer) originValidator(localASN uint32) *peerOriginValidator {
+	return &peerOriginValidator{
+		ov:       &b.rov,
+		localASN: localASN,
+	}
+}
+
+// revalidate recomputes the origin validation state of all routes received from the peer. Returns the number of changed paths.
+func (p *peer) revalidate() int {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	changed := 0
+	for _, fsm := range p.fsms {
+		for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast, fsm.ipv4LabeledUnicast, fsm.ipv6LabeledUnicast} {
+			if f == nil || !f.initialized {
+				continue
+			}
+
+			changed += f.adjRIBIn.(*adjRIBIn.AdjRIBIn).Revalidate()
+		}
+	}
+
+	return changed
+}
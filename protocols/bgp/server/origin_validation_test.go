@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginASN(t *testing.T) {
+	asn := func(x uint32) *uint32 {
+		return &x
+	}
+
+	tests := []struct {
+		name     string
+		path     *route.Path
+		expected *uint32
+	}{
+		{
+			name: "Empty AS path",
+			path: &route.Path{
+				Type:    route.BGPPathType,
+				BGPPath: &route.BGPPath{},
+			},
+			expected: asn(65000),
+		},
+		{
+			name: "AS sequence",
+			path: &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					ASPath: &types.ASPath{
+						{Type: types.ASSequence, ASNs: []uint32{65001, 65002}},
+					},
+				},
+			},
+			expected: asn(65002),
+		},
+		{
+			name: "AS set",
+			path: &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					ASPath: &types.ASPath{
+						{Type: types.ASSequence, ASNs: []uint32{65001}},
+						{Type: types.ASSet, ASNs: []uint32{65002, 65003}},
+					},
+				},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, originASN(test.path, 65000), "Test %q", test.name)
+	}
+}
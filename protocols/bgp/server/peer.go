@@ -13,6 +13,7 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
 )
 
 type peer struct {
@@ -22,15 +23,23 @@ type peer struct {
 	localAddr *bnet.IP
 	ttl       uint8
 	passive   bool
-	peerASN   uint32
-	localASN  uint32
+	// activeOnly peers are never accepted connections from
+	activeOnly bool
+	peerASN    uint32
+	localASN   uint32
+
+	// ttlSecurityHops is the maximum number of hops to the peer with GTSM enabled, 0 if disabled
+	ttlSecurityHops uint8
 
 	// guarded by fsmsMu
-	fsms   []*FSM
-	fsmsMu sync.Mutex
+	fsms    []*FSM
+	stopped bool
+	fsmsMu  sync.Mutex
 
 	routerID                    uint32
 	reconnectInterval           time.Duration
+	maxReconnectInterval        time.Duration
+	reconnectBackoff            reconnectBackoff
 	keepaliveTime               time.Duration
 	holdTime                    time.Duration
 	optOpenParams               []packet.OptParam
@@ -38,10 +47,40 @@ type peer struct {
 	routeReflectorClient        bool
 	ipv4MultiProtocolAdvertised bool
 	clusterID                   uint32
-
-	vrf  *vrf.VRF
-	ipv4 *peerAddressFamily
-	ipv6 *peerAddressFamily
+	noClientToClientReflection  bool
+	ignoreWellKnownCommunities  bool
+	bilateralPeer               bool
+	orrGroup                    string
+	multipath                   route.MultipathMode
+	removePrivateAS             route.PrivateASMode
+	asOverride                  bool
+	localASMigration            *LocalASMigrationConfig
+
+	vrf                *vrf.VRF
+	ipv4               *peerAddressFamily
+	ipv6               *peerAddressFamily
+	ipv4LabeledUnicast *peerAddressFamily
+	ipv6LabeledUnicast *peerAddressFamily
+
+	// flowSpecImportFilterChain is the import filter chain of FlowSpec rules of sessions established later
+	flowSpecImportFilterChain filter.Chain
+
+	debug         packetDebugger
+	counters      peerCounters
+	maintenance   maintenanceMode
+	admin         adminState
+	notifications lastNotifications
+
+	// listenRange is the listen range a dynamic peer has been instantiated for, nil for configured peers
+	listenRange *listenRange
+
+	// memberConfig is the config of a peer group member as given, before inheriting the group settings
+	memberConfig *PeerConfig
+
+	// prefixLimitHold keeps the sessions down after a prefix limit has been exceeded
+	prefixLimitHold  bool
+	prefixLimitTimer *time.Timer
+	prefixLimitMu    sync.Mutex
 }
 
 // PeerConfig defines the configuration for a BGP session
@@ -57,15 +96,100 @@ type PeerConfig struct {
 	LocalAS                    uint32
 	PeerAS                     uint32
 	Passive                    bool
+	ActiveOnly                 bool
 	RouterID                   uint32
 	RouteServerClient          bool
 	RouteReflectorClient       bool
 	RouteReflectorClusterID    uint32
+	NoClientToClientReflection bool
+	SkipOriginatorIDCheck      bool
+	SkipClusterListCheck       bool
 	AdvertiseIPv4MultiProtocol bool
 	IPv4                       *AddressFamilyConfig
 	IPv6                       *AddressFamilyConfig
+	IPv4LabeledUnicast         *AddressFamilyConfig
+	IPv6LabeledUnicast         *AddressFamilyConfig
+	IPv4VPN                    *VPNConfig
+	IPv6VPN                    *VPNConfig
 	VRF                        *vrf.VRF
 	Description                string
+	GracefulRestart            *GracefulRestartConfig
+	PeerGroup                  string
+
+	// IgnoreWellKnownCommunities advertises paths to the peer regardless of NO_EXPORT, NO_ADVERTISE, NO_EXPORT_SUBCONFED and NOPEER
+	IgnoreWellKnownCommunities bool
+
+	// BilateralPeer marks the peer as bilateral peer, e.g. a settlement-free peer. Paths with NOPEER are not advertised to it (RFC3765).
+	BilateralPeer bool
+
+	// ORRGroup is the optimal route reflection group (RFC9107) of a route reflector client. Best paths advertised to the
+	// peer are selected using the IGP costs from the location of the group (see BGPServer.SetORRIGPCosts).
+	ORRGroup string
+
+	// ShutdownCommunication is sent with administrative shutdowns and resets of the session (RFC9003) unless a message is
+	// given by the operator
+	ShutdownCommunication string
+
+	// RecordDirectory enables recording the raw byte stream of each session into a file in this directory. Recordings
+	// can be replayed using ReplaySession.
+	RecordDirectory string
+
+	// LinkState enables the BGP-LS address family exporting the topology of the IGPs (RFC7752)
+	LinkState bool
+
+	// RouteTargetConstraint enables route target constraint (RFC4684). The import route targets of the VRFs of the VPN
+	// address families are advertised and VPN routes are only advertised for route targets the peer requested.
+	RouteTargetConstraint bool
+
+	// IPv4FlowSpec and IPv6FlowSpec enable receiving FlowSpec rules (RFC8955, RFC8956). Received rules are passed to the
+	// FlowSpec clients of the server (see BGPServer.RegisterFlowSpecClient).
+	IPv4FlowSpec bool
+	IPv6FlowSpec bool
+
+	// FlowSpecImportFilterChain is applied to received FlowSpec rules matching their destination prefix (the default
+	// route of the address family for rules without destination prefix). Rejected rules are ignored.
+	FlowSpecImportFilterChain filter.Chain
+
+	// FlowSpecNoValidation accepts FlowSpec rules failing the validation against the unicast routes (RFC8955 6),
+	// e.g. from a controller not advertising the unicast routes of the destinations
+	FlowSpecNoValidation bool
+
+	// IPv4SRPolicy and IPv6SRPolicy enable receiving SR Policy candidate paths (RFC9830), e.g. from a controller.
+	// Received policies are passed to the SR Policy clients of the server (see BGPServer.RegisterSRPolicyClient).
+	IPv4SRPolicy bool
+	IPv6SRPolicy bool
+
+	// Multipath determines which equal cost paths received from the peer are used together with paths from other peers
+	Multipath route.MultipathMode
+
+	// DynamicCapability allows adding and removing address families without restarting the session if the peer supports it
+	DynamicCapability bool
+
+	// RemovePrivateAS determines how private AS numbers are scrubbed from AS paths sent to the peer if it is an eBGP peer
+	RemovePrivateAS route.PrivateASMode
+
+	// LocalASMigration presents another AS than LocalAS to the peer
+	LocalASMigration *LocalASMigrationConfig
+
+	// AllowASIn is the number of occurrences of the local AS accepted in AS paths received from the peer
+	AllowASIn uint8
+
+	// ASOverride replaces the AS of the peer by the local AS in AS paths sent to it if it is an eBGP peer
+	ASOverride bool
+
+	// NextHopTracking installs paths received from the peer only while their next hop resolves to a non BGP route
+	NextHopTracking bool
+
+	// ExtendedNextHop sends IPv4 unicast routes with IPv6 next hops if the peer supports it (RFC8950)
+	ExtendedNextHop bool
+
+	// TTLSecurityHops enables GTSM (RFC5082) for a peer at most TTLSecurityHops hops away: Packets are sent with TTL 255
+	// and packets received with a TTL below 256 - TTLSecurityHops are dropped. TTL is ignored if set.
+	TTLSecurityHops uint8
+
+	// MaxReconnectInterval limits the interval between connection attempts. It doubles after each failed attempt
+	// starting at ReconnectInterval. DefaultMaxReconnectInterval is used if 0.
+	MaxReconnectInterval time.Duration
 }
 
 // AddressFamilyConfig represents all configuration parameters specific for an address family
@@ -74,6 +198,40 @@ type AddressFamilyConfig struct {
 	ExportFilterChain filter.Chain
 	AddPathSend       routingtable.ClientOptions
 	AddPathRecv       bool
+	PrefixLimit       *PrefixLimit
+
+	// AdvertisementInterval is the minimum interval between advertisements to the peer (MRAI). Changes within
+	// the interval are sent together when it expires. Withdrawals are not delayed. 0 sends changes right away.
+	AdvertisementInterval time.Duration
+
+	// NoAdvertisementIntervalIBGP disables the AdvertisementInterval on iBGP sessions including route reflector clients
+	NoAdvertisementIntervalIBGP bool
+
+	// NextHopSelf replaces the next hop of paths sent to the peer by the local address of the session. IPv4 paths
+	// sent over IPv6 sessions then carry an IPv6 next hop and are advertised only if ExtendedNextHop is negotiated (RFC8950).
+	NextHopSelf route.NextHopSelfMode
+}
+
+// withImportFilterChain returns a copy of the address family config using import filter chain c
+func (afc *AddressFamilyConfig) withImportFilterChain(c filter.Chain) *AddressFamilyConfig {
+	if afc == nil {
+		return nil
+	}
+
+	x := *afc
+	x.ImportFilterChain = c
+	return &x
+}
+
+// withExportFilterChain returns a copy of the address family config using export filter chain c
+func (afc *AddressFamilyConfig) withExportFilterChain(c filter.Chain) *AddressFamilyConfig {
+	if afc == nil {
+		return nil
+	}
+
+	x := *afc
+	x.ExportFilterChain = c
+	return &x
 }
 
 // NeedsRestart determines if the peer needs a restart on cfg change
@@ -102,10 +260,35 @@ func (pc *PeerConfig) NeedsRestart(x *PeerConfig) bool {
 		return true
 	}
 
+	if pc.RouteReflectorClusterID != x.RouteReflectorClusterID || pc.NoClientToClientReflection != x.NoClientToClientReflection {
+		return true
+	}
+
+	if pc.SkipOriginatorIDCheck != x.SkipOriginatorIDCheck || pc.SkipClusterListCheck != x.SkipClusterListCheck {
+		return true
+	}
+
+	if pc.IgnoreWellKnownCommunities != x.IgnoreWellKnownCommunities || pc.BilateralPeer != x.BilateralPeer {
+		return true
+	}
+
+	if pc.ORRGroup != x.ORRGroup {
+		return true
+	}
+
 	if pc.RouteServerClient != x.RouteServerClient {
 		return true
 	}
 
+	if pc.PeerGroup != x.PeerGroup {
+		return true
+	}
+
+	// The multipath mode is stored with the paths received
+	if pc.Multipath != x.Multipath {
+		return true
+	}
+
 	if pc.VRF != x.VRF {
 		return true
 	}
@@ -114,33 +297,223 @@ func (pc *PeerConfig) NeedsRestart(x *PeerConfig) bool {
 		return true
 	}
 
-	if pc.Passive != x.Passive {
+	if pc.Passive != x.Passive || pc.ActiveOnly != x.ActiveOnly {
+		return true
+	}
+
+	// VPN address families are negotiated via the multi protocol capability
+	if (pc.IPv4VPN == nil) != (x.IPv4VPN == nil) || (pc.IPv6VPN == nil) != (x.IPv6VPN == nil) {
+		return true
+	}
+
+	// Routes of VPN address families are not re-evaluated when their filter chains change
+	if !pc.IPv4VPN.filterChainsEqual(x.IPv4VPN) || !pc.IPv6VPN.filterChainsEqual(x.IPv6VPN) {
+		return true
+	}
+
+	if pc.LinkState != x.LinkState || pc.RouteTargetConstraint != x.RouteTargetConstraint {
+		return true
+	}
+
+	if pc.IPv4FlowSpec != x.IPv4FlowSpec || pc.IPv6FlowSpec != x.IPv6FlowSpec || pc.FlowSpecNoValidation != x.FlowSpecNoValidation {
+		return true
+	}
+
+	if pc.IPv4SRPolicy != x.IPv4SRPolicy || pc.IPv6SRPolicy != x.IPv6SRPolicy {
+		return true
+	}
+
+	// The restart time is advertised in the graceful restart capability
+	if (pc.GracefulRestart == nil) != (x.GracefulRestart == nil) {
+		return true
+	}
+
+	if pc.GracefulRestart != nil && pc.GracefulRestart.restartTime() != x.GracefulRestart.restartTime() {
+		return true
+	}
+
+	if pc.DynamicCapability != x.DynamicCapability {
+		return true
+	}
+
+	// The AS path is scrubbed when the session is set up
+	if pc.RemovePrivateAS != x.RemovePrivateAS {
+		return true
+	}
+
+	// The AS presented to the peer is sent in the OPEN message
+	if !pc.LocalASMigration.equal(x.LocalASMigration) {
+		return true
+	}
+
+	// AS paths are checked and rewritten when the session is set up
+	if pc.AllowASIn != x.AllowASIn || pc.ASOverride != x.ASOverride {
+		return true
+	}
+
+	// Socket options are set when the connection is set up
+	if pc.TTLSecurityHops != x.TTLSecurityHops {
+		return true
+	}
+
+	// The extended next hop encoding is negotiated with capabilities
+	if pc.ExtendedNextHop != x.ExtendedNextHop {
+		return true
+	}
+
+	// Paths are passed through the next hop tracker from the start of the session
+	if pc.NextHopTracking != x.NextHopTracking {
+		return true
+	}
+
+	// Address families and ADD-PATH are negotiated with capabilities on session setup. With dynamic capability
+	// address families can be added and removed later.
+	if pc.IPv4.needsRestart(x.IPv4, pc.DynamicCapability) || pc.IPv6.needsRestart(x.IPv6, pc.DynamicCapability) {
+		return true
+	}
+
+	if pc.IPv4LabeledUnicast.needsRestart(x.IPv4LabeledUnicast, pc.DynamicCapability) || pc.IPv6LabeledUnicast.needsRestart(x.IPv6LabeledUnicast, pc.DynamicCapability) {
 		return true
 	}
 
 	return false
 }
 
+func (afc *AddressFamilyConfig) needsRestart(x *AddressFamilyConfig, dynamicCapability bool) bool {
+	if afc == nil && x == nil {
+		return false
+	}
+
+	// ADD-PATH is negotiated on session setup only
+	if afc == nil {
+		return !dynamicCapability || x.addPath()
+	}
+
+	if x == nil {
+		return !dynamicCapability || afc.addPath()
+	}
+
+	if afc.AddPathRecv != x.AddPathRecv || afc.AddPathSend != x.AddPathSend {
+		return true
+	}
+
+	// The advertisement interval is applied on session setup
+	if afc.AdvertisementInterval != x.AdvertisementInterval || afc.NoAdvertisementIntervalIBGP != x.NoAdvertisementIntervalIBGP {
+		return true
+	}
+
+	// Next hop self is applied on session setup
+	if afc.NextHopSelf != x.NextHopSelf {
+		return true
+	}
+
+	// Prefix limits are applied on session setup
+	if afc.PrefixLimit == nil || x.PrefixLimit == nil {
+		return afc.PrefixLimit != x.PrefixLimit
+	}
+
+	return !afc.PrefixLimit.equal(x.PrefixLimit)
+}
+
+func (afc *AddressFamilyConfig) addPath() bool {
+	return afc.AddPathRecv || !afc.AddPathSend.BestOnly
+}
+
+// flightRecorder gets the flight recorder registry scoped to the VRF of the peer
+func (p *peer) flightRecorder() *flightrecorder.Registry {
+	if p.vrf == nil {
+		return p.server.flightRec
+	}
+
+	return p.server.flightRec.Scope(p.vrf.Name())
+}
+
 // replaceImportFilterChain replaces a peers import filter chain
 func (p *peer) replaceImportFilterChain(c filter.Chain) {
 	p.fsmsMu.Lock()
 	defer p.fsmsMu.Unlock()
 
-	for _, fsm := range p.fsms {
-		fsm.replaceImportFilterChain(c)
-	}
+	p.setImportFilterChain(c)
 }
 
-// replaceExportFilterChain replaces a peers import filter chain
+// replaceExportFilterChain replaces a peers export filter chain
 func (p *peer) replaceExportFilterChain(c filter.Chain) {
 	p.fsmsMu.Lock()
 	defer p.fsmsMu.Unlock()
 
+	p.setExportFilterChain(c)
+}
+
+// replaceFilterChains replaces a peers import and export filter chains at once. Established sessions are kept:
+// Paths received are re-evaluated and changes of paths sent are advertised.
+func (p *peer) replaceFilterChains(importChain filter.Chain, exportChain filter.Chain) {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	p.setImportFilterChain(importChain)
+	p.setExportFilterChain(exportChain)
+}
+
+// setImportFilterChain sets the import filter chain of all address families except VPN and of all sessions. fsmsMu must be held.
+func (p *peer) setImportFilterChain(c filter.Chain) {
+	if p.config != nil {
+		cfg := *p.config
+		cfg.IPv4 = cfg.IPv4.withImportFilterChain(c)
+		cfg.IPv6 = cfg.IPv6.withImportFilterChain(c)
+		cfg.IPv4LabeledUnicast = cfg.IPv4LabeledUnicast.withImportFilterChain(c)
+		cfg.IPv6LabeledUnicast = cfg.IPv6LabeledUnicast.withImportFilterChain(c)
+		cfg.FlowSpecImportFilterChain = c
+		p.config = &cfg
+	}
+
+	c = filterOrDefault(c)
+
+	// Sessions established later use the new chain as well
+	for _, f := range p.addressFamilies() {
+		f.importFilterChain = c
+	}
+
+	p.flowSpecImportFilterChain = c
+
+	for _, fsm := range p.fsms {
+		fsm.replaceImportFilterChain(c)
+	}
+}
+
+// setExportFilterChain sets the export filter chain of all address families except VPN and of all sessions. fsmsMu must be held.
+func (p *peer) setExportFilterChain(c filter.Chain) {
+	if p.config != nil {
+		cfg := *p.config
+		cfg.IPv4 = cfg.IPv4.withExportFilterChain(c)
+		cfg.IPv6 = cfg.IPv6.withExportFilterChain(c)
+		cfg.IPv4LabeledUnicast = cfg.IPv4LabeledUnicast.withExportFilterChain(c)
+		cfg.IPv6LabeledUnicast = cfg.IPv6LabeledUnicast.withExportFilterChain(c)
+		p.config = &cfg
+	}
+
+	c = filterOrDefault(c)
+
+	for _, f := range p.addressFamilies() {
+		f.exportFilterChain = c
+	}
+
 	for _, fsm := range p.fsms {
 		fsm.replaceExportFilterChain(c)
 	}
 }
 
+// addressFamilies gets the configured address families of the peer except VPN families
+func (p *peer) addressFamilies() []*peerAddressFamily {
+	ret := make([]*peerAddressFamily, 0, 4)
+	for _, f := range []*peerAddressFamily{p.ipv4, p.ipv6, p.ipv4LabeledUnicast, p.ipv6LabeledUnicast} {
+		if f != nil {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
 type peerAddressFamily struct {
 	rib *locRIB.LocRIB
 
@@ -149,6 +522,27 @@ type peerAddressFamily struct {
 
 	addPathSend    routingtable.ClientOptions
 	addPathReceive bool
+	prefixLimit    *PrefixLimit
+
+	advertisementInterval       time.Duration
+	noAdvertisementIntervalIBGP bool
+
+	nextHopSelf route.NextHopSelfMode
+
+	staleMu sync.Mutex
+	stale   *staleRIB
+
+	// nextHopTracker installs the paths into rib if next hop tracking is enabled
+	nextHopTracker *nextHopTracker
+}
+
+// ribClient gets the client of the adj-RIB-in installing paths into the Loc-RIB
+func (f *peerAddressFamily) ribClient() routingtable.RouteTableClient {
+	if f.nextHopTracker != nil {
+		return f.nextHopTracker
+	}
+
+	return f.rib
 }
 
 func (p *peer) dumpRIBIn(afi uint16, safi uint8) []*route.Route {
@@ -158,7 +552,7 @@ func (p *peer) dumpRIBIn(afi uint16, safi uint8) []*route.Route {
 
 	fsm := p.fsms[0]
 	f := fsm.addressFamily(afi, safi)
-	if f == nil {
+	if f == nil || f.adjRIBIn == nil {
 		return nil
 	}
 
@@ -172,7 +566,7 @@ func (p *peer) dumpRIBOut(afi uint16, safi uint8) []*route.Route {
 
 	fsm := p.fsms[0]
 	f := fsm.addressFamily(afi, safi)
-	if f == nil {
+	if f == nil || f.adjRIBOut == nil {
 		return nil
 	}
 
@@ -180,18 +574,24 @@ func (p *peer) dumpRIBOut(afi uint16, safi uint8) []*route.Route {
 }
 
 func (p *peer) addressFamily(afi uint16, safi uint8) *peerAddressFamily {
-	if safi != packet.UnicastSAFI {
-		return nil
+	switch safi {
+	case packet.UnicastSAFI:
+		switch afi {
+		case packet.IPv4AFI:
+			return p.ipv4
+		case packet.IPv6AFI:
+			return p.ipv6
+		}
+	case packet.LabeledUnicastSAFI:
+		switch afi {
+		case packet.IPv4AFI:
+			return p.ipv4LabeledUnicast
+		case packet.IPv6AFI:
+			return p.ipv6LabeledUnicast
+		}
 	}
 
-	switch afi {
-	case packet.IPv4AFI:
-		return p.ipv4
-	case packet.IPv6AFI:
-		return p.ipv6
-	default:
-		return nil
-	}
+	return nil
 }
 
 func (p *peer) collisionHandling(callingFSM *FSM) bool {
@@ -228,7 +628,7 @@ func (p *peer) collisionHandling(callingFSM *FSM) bool {
 
 func isOpenConfirmState(s state) bool {
 	switch s.(type) {
-	case openConfirmState:
+	case *openConfirmState:
 		return true
 	}
 
@@ -237,7 +637,7 @@ func isOpenConfirmState(s state) bool {
 
 func isEstablishedState(s state) bool {
 	switch s.(type) {
-	case establishedState:
+	case *establishedState:
 		return true
 	}
 
@@ -248,36 +648,38 @@ func isEstablishedState(s state) bool {
 // to the given rib. To actually connect the peer, call Start() on the returned peer.
 func newPeer(c PeerConfig, server *bgpServer) (*peer, error) {
 	p := &peer{
-		server:               server,
-		config:               &c,
-		addr:                 c.PeerAddress,
-		ttl:                  c.TTL,
-		passive:              c.Passive,
-		peerASN:              c.PeerAS,
-		localASN:             c.LocalAS,
-		fsms:                 make([]*FSM, 0),
-		reconnectInterval:    c.ReconnectInterval,
-		keepaliveTime:        c.KeepAlive,
-		holdTime:             c.HoldTime,
-		optOpenParams:        make([]packet.OptParam, 0),
-		routeServerClient:    c.RouteServerClient,
-		routeReflectorClient: c.RouteReflectorClient,
-		clusterID:            c.RouteReflectorClusterID,
-		vrf:                  c.VRF,
-	}
-
-	if c.IPv4 != nil {
-		p.ipv4 = &peerAddressFamily{
-			rib:               c.VRF.IPv4UnicastRIB(),
-			importFilterChain: filterOrDefault(c.IPv4.ImportFilterChain),
-			exportFilterChain: filterOrDefault(c.IPv4.ExportFilterChain),
-			addPathReceive:    c.IPv4.AddPathRecv,
-			addPathSend:       c.IPv4.AddPathSend,
-		}
-
-		if p.ipv4.rib == nil {
-			return nil, fmt.Errorf("No RIB for IPv4 unicast configured")
-		}
+		server:                     server,
+		config:                     &c,
+		addr:                       c.PeerAddress,
+		ttl:                        c.TTL,
+		ttlSecurityHops:            c.TTLSecurityHops,
+		passive:                    c.Passive,
+		activeOnly:                 c.ActiveOnly,
+		peerASN:                    c.PeerAS,
+		localASN:                   c.LocalAS,
+		fsms:                       make([]*FSM, 0),
+		reconnectInterval:          c.ReconnectInterval,
+		maxReconnectInterval:       c.MaxReconnectInterval,
+		keepaliveTime:              c.KeepAlive,
+		holdTime:                   c.HoldTime,
+		routeServerClient:          c.RouteServerClient,
+		routeReflectorClient:       c.RouteReflectorClient,
+		clusterID:                  c.RouteReflectorClusterID,
+		noClientToClientReflection: c.NoClientToClientReflection,
+		ignoreWellKnownCommunities: c.IgnoreWellKnownCommunities,
+		bilateralPeer:              c.BilateralPeer,
+		orrGroup:                   c.ORRGroup,
+		multipath:                  c.Multipath,
+		removePrivateAS:            c.RemovePrivateAS,
+		asOverride:                 c.ASOverride,
+		localASMigration:           c.LocalASMigration,
+		vrf:                        c.VRF,
+		flowSpecImportFilterChain:  filterOrDefault(c.FlowSpecImportFilterChain),
+	}
+
+	err := p.setAddressFamilies(&c)
+	if err != nil {
+		return nil, err
 	}
 
 	// If we are a route reflector and no ClusterID was set, use our RouterID
@@ -285,59 +687,178 @@ func newPeer(c PeerConfig, server *bgpServer) (*peer, error) {
 		p.clusterID = c.RouterID
 	}
 
-	caps := make(packet.Capabilities, 0)
+	if !p.passive {
+		p.fsms = append(p.fsms, NewActiveFSM(p))
+	}
 
-	caps = append(caps, addPathCapabilities(c)...)
+	return p, nil
+}
 
-	caps = append(caps, asn4Capability(c))
+// setAddressFamilies creates the address families configured in c and the capabilities of our OPEN message.
+// Address families already existing are kept.
+func (p *peer) setAddressFamilies(c *PeerConfig) error {
+	ipv4 := keepOrCreateAddressFamily(p.ipv4, c.IPv4, c.VRF.IPv4UnicastRIB)
+	if ipv4 != nil && ipv4.rib == nil {
+		return fmt.Errorf("No RIB for IPv4 unicast configured")
+	}
 
-	if c.IPv4 != nil && c.AdvertiseIPv4MultiProtocol {
-		caps = append(caps, multiProtocolCapability(packet.IPv4AFI))
-		p.ipv4MultiProtocolAdvertised = true
+	ipv6 := keepOrCreateAddressFamily(p.ipv6, c.IPv6, c.VRF.IPv6UnicastRIB)
+	if ipv6 != nil && ipv6.rib == nil {
+		return fmt.Errorf("No RIB for IPv6 unicast configured")
 	}
 
-	if c.IPv6 != nil {
-		p.ipv6 = &peerAddressFamily{
-			rib:               c.VRF.IPv6UnicastRIB(),
-			importFilterChain: filterOrDefault(c.IPv6.ImportFilterChain),
-			exportFilterChain: filterOrDefault(c.IPv6.ExportFilterChain),
-			addPathReceive:    c.IPv6.AddPathRecv,
-			addPathSend:       c.IPv6.AddPathSend,
-		}
-		caps = append(caps, multiProtocolCapability(packet.IPv6AFI))
+	// Labeled unicast routes share the RIB with unicast routes (RFC8277 5)
+	ipv4LabeledUnicast := keepOrCreateAddressFamily(p.ipv4LabeledUnicast, c.IPv4LabeledUnicast, c.VRF.IPv4UnicastRIB)
+	if ipv4LabeledUnicast != nil && ipv4LabeledUnicast.rib == nil {
+		return fmt.Errorf("No RIB for IPv4 labeled unicast configured")
+	}
+
+	ipv6LabeledUnicast := keepOrCreateAddressFamily(p.ipv6LabeledUnicast, c.IPv6LabeledUnicast, c.VRF.IPv6UnicastRIB)
+	if ipv6LabeledUnicast != nil && ipv6LabeledUnicast.rib == nil {
+		return fmt.Errorf("No RIB for IPv6 labeled unicast configured")
+	}
 
-		if p.ipv6.rib == nil {
-			return nil, fmt.Errorf("No RIB for IPv6 unicast configured")
+	if c.NextHopTracking && p.server != nil {
+		for _, f := range []*peerAddressFamily{ipv4, ipv6, ipv4LabeledUnicast, ipv6LabeledUnicast} {
+			if f != nil && f.nextHopTracker == nil {
+				f.nextHopTracker = p.server.nextHopTracker(f.rib)
+			}
 		}
 	}
 
-	p.optOpenParams = append(p.optOpenParams, packet.OptParam{
-		Type:  packet.CapabilitiesParamType,
-		Value: caps,
+	p.ipv4 = ipv4
+	p.ipv6 = ipv6
+	p.ipv4LabeledUnicast = ipv4LabeledUnicast
+	p.ipv6LabeledUnicast = ipv6LabeledUnicast
+	p.ipv4MultiProtocolAdvertised = c.ipv4MultiProtocol()
+	p.optOpenParams = []packet.OptParam{
+		{
+			Type:  packet.CapabilitiesParamType,
+			Value: openCapabilities(c),
+		},
+	}
+
+	return nil
+}
+
+func keepOrCreateAddressFamily(f *peerAddressFamily, c *AddressFamilyConfig, rib func() *locRIB.LocRIB) *peerAddressFamily {
+	if c == nil {
+		return nil
+	}
+
+	if f != nil {
+		return f
+	}
+
+	return newPeerAddressFamily(rib(), c)
+}
+
+// openCapabilities gets the capabilities of our OPEN message except graceful restart
+func openCapabilities(c *PeerConfig) packet.Capabilities {
+	caps := make(packet.Capabilities, 0)
+
+	caps = append(caps, addPathCapabilities(*c)...)
+
+	caps = append(caps, asn4Capability(*c))
+
+	caps = append(caps, packet.Capability{
+		Code:  packet.RouteRefreshCapabilityCode,
+		Value: packet.RouteRefreshCapability{},
+	}, packet.Capability{
+		Code:  packet.EnhancedRouteRefreshCapabilityCode,
+		Value: packet.EnhancedRouteRefreshCapability{},
 	})
 
-	if !p.passive {
-		p.fsms = append(p.fsms, NewActiveFSM(p))
+	if c.ipv4MultiProtocol() {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.UnicastSAFI))
 	}
 
-	return p, nil
+	if c.IPv4 != nil && c.ExtendedNextHop {
+		caps = append(caps, extendedNextHopCapability())
+	}
+
+	if c.IPv6 != nil {
+		caps = append(caps, multiProtocolCapability(packet.IPv6AFI, packet.UnicastSAFI))
+	}
+
+	if c.IPv4LabeledUnicast != nil {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.LabeledUnicastSAFI))
+	}
+
+	if c.IPv6LabeledUnicast != nil {
+		caps = append(caps, multiProtocolCapability(packet.IPv6AFI, packet.LabeledUnicastSAFI))
+	}
+
+	if c.IPv4VPN != nil {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.MPLSVPNSAFI))
+	}
+
+	if c.IPv6VPN != nil {
+		caps = append(caps, multiProtocolCapability(packet.IPv6AFI, packet.MPLSVPNSAFI))
+	}
+
+	if c.LinkState {
+		caps = append(caps, multiProtocolCapability(packet.LinkStateAFI, packet.LinkStateSAFI))
+	}
+
+	if c.RouteTargetConstraint {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.RouteTargetConstraintSAFI))
+	}
+
+	if c.IPv4FlowSpec {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.FlowSpecSAFI))
+	}
+
+	if c.IPv6FlowSpec {
+		caps = append(caps, multiProtocolCapability(packet.IPv6AFI, packet.FlowSpecSAFI))
+	}
+
+	if c.IPv4SRPolicy {
+		caps = append(caps, multiProtocolCapability(packet.IPv4AFI, packet.SRPolicySAFI))
+	}
+
+	if c.IPv6SRPolicy {
+		caps = append(caps, multiProtocolCapability(packet.IPv6AFI, packet.SRPolicySAFI))
+	}
+
+	if c.DynamicCapability {
+		caps = append(caps, dynamicCapability())
+	}
+
+	return caps
+}
+
+func newPeerAddressFamily(rib *locRIB.LocRIB, c *AddressFamilyConfig) *peerAddressFamily {
+	return &peerAddressFamily{
+		rib:               rib,
+		importFilterChain: filterOrDefault(c.ImportFilterChain),
+		exportFilterChain: filterOrDefault(c.ExportFilterChain),
+		addPathReceive:    c.AddPathRecv,
+		addPathSend:       c.AddPathSend,
+		prefixLimit:       c.PrefixLimit,
+
+		advertisementInterval:       c.AdvertisementInterval,
+		noAdvertisementIntervalIBGP: c.NoAdvertisementIntervalIBGP,
+
+		nextHopSelf: c.NextHopSelf,
+	}
 }
 
 func asn4Capability(c PeerConfig) packet.Capability {
 	return packet.Capability{
 		Code: packet.ASN4CapabilityCode,
 		Value: packet.ASN4Capability{
-			ASN4: c.LocalAS,
+			ASN4: c.sessionASN(),
 		},
 	}
 }
 
-func multiProtocolCapability(afi uint16) packet.Capability {
+func multiProtocolCapability(afi uint16, safi uint8) packet.Capability {
 	return packet.Capability{
 		Code: packet.MultiProtocolCapabilityCode,
 		Value: packet.MultiProtocolCapability{
 			AFI:  afi,
-			SAFI: packet.UnicastSAFI,
+			SAFI: safi,
 		},
 	}
 }
@@ -355,6 +876,16 @@ func addPathCapabilities(c PeerConfig) []packet.Capability {
 		caps = append(caps, cap)
 	}
 
+	enabled, cap = addPathCapabilityForFamily(c.IPv4LabeledUnicast, packet.IPv4AFI, packet.LabeledUnicastSAFI)
+	if enabled {
+		caps = append(caps, cap)
+	}
+
+	enabled, cap = addPathCapabilityForFamily(c.IPv6LabeledUnicast, packet.IPv6AFI, packet.LabeledUnicastSAFI)
+	if enabled {
+		caps = append(caps, cap)
+	}
+
 	return caps
 }
 
@@ -409,9 +940,77 @@ func (p *peer) stop() {
 	p.fsmsMu.Lock()
 	defer p.fsmsMu.Unlock()
 
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	p.stopPrefixLimitTimer()
+
 	for _, fsm := range p.fsms {
 		fsm.eventCh <- ManualStop
 	}
+
+	for _, f := range []*peerAddressFamily{p.ipv4, p.ipv6} {
+		if f != nil {
+			f.flushStale()
+		}
+	}
+}
+
+// openParams gets the optional parameters of our OPEN message. The graceful restart capability is added here
+// as its restart state depends on the server still being in its restart phase.
+func (p *peer) openParams() []packet.OptParam {
+	if p.config == nil || p.config.GracefulRestart == nil {
+		return p.optOpenParams
+	}
+
+	ret := make([]packet.OptParam, 0, len(p.optOpenParams))
+	for _, o := range p.optOpenParams {
+		caps, ok := o.Value.(packet.Capabilities)
+		if ok {
+			caps = append(append(make(packet.Capabilities, 0, len(caps)+1), caps...), p.gracefulRestartCapability())
+			o.Value = caps
+		}
+
+		ret = append(ret, o)
+	}
+
+	return ret
+}
+
+func (p *peer) gracefulRestartCapability() packet.Capability {
+	var restart *restartState
+	if p.server != nil {
+		restart = p.server.restart
+	}
+
+	restarting := restart.restarting()
+	grCap := packet.GracefulRestartCapability{
+		RestartState: restarting,
+		RestartTime:  p.config.GracefulRestart.restartTime(),
+	}
+
+	forwardingState := restarting && restart.forwardingStatePreserved
+	if p.ipv4 != nil {
+		grCap.Tuples = append(grCap.Tuples, packet.GracefulRestartCapabilityTuple{
+			AFI:             packet.IPv4AFI,
+			SAFI:            packet.UnicastSAFI,
+			ForwardingState: forwardingState,
+		})
+	}
+
+	if p.ipv6 != nil {
+		grCap.Tuples = append(grCap.Tuples, packet.GracefulRestartCapabilityTuple{
+			AFI:             packet.IPv6AFI,
+			SAFI:            packet.UnicastSAFI,
+			ForwardingState: forwardingState,
+		})
+	}
+
+	return packet.Capability{
+		Code:  packet.GracefulRestartCapabilityCode,
+		Value: grCap,
+	}
 }
 
 func (p *peer) isEBGP() bool {
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/sirupsen/logrus"
+)
+
+// PeerEventType is the type of a PeerEvent
+type PeerEventType uint8
+
+const (
+	// PeerEventStateChange is reported when the FSM of a peer changes its state
+	PeerEventStateChange PeerEventType = iota
+
+	// PeerEventNotificationReceived is reported when a NOTIFICATION message is received from a peer
+	PeerEventNotificationReceived
+
+	// PeerEventPrefixLimitWarning is reported when the warning threshold of a prefix limit is reached
+	PeerEventPrefixLimitWarning
+
+	// PeerEventPrefixLimitExceeded is reported when a prefix limit is exceeded
+	PeerEventPrefixLimitExceeded
+)
+
+func (t PeerEventType) String() string {
+	switch t {
+	case PeerEventStateChange:
+		return "state change"
+	case PeerEventNotificationReceived:
+		return "notification received"
+	case PeerEventPrefixLimitWarning:
+		return "prefix limit warning"
+	case PeerEventPrefixLimitExceeded:
+		return "prefix limit exceeded"
+	}
+
+	return "unknown"
+}
+
+// PeerEvent is an event of a BGP session. Only the fields of its type are set.
+type PeerEvent struct {
+	Type PeerEventType
+	Peer *bnet.IP
+	Time time.Time
+
+	// OldState, NewState and Reason describe a state change
+	OldState string
+	NewState string
+	Reason   string
+
+	// ErrorCode and ErrorSubcode are the error of a NOTIFICATION received
+	ErrorCode    uint8
+	ErrorSubcode uint8
+
+	// ShutdownCommunication is the message of an administrative shutdown or reset NOTIFICATION received (RFC9003)
+	ShutdownCommunication string
+
+	// AFI, SAFI, Prefixes, Limit and Threshold (percentage of Limit) describe a prefix limit event
+	AFI       uint16
+	SAFI      uint8
+	Prefixes  uint64
+	Limit     uint64
+	Threshold uint8
+}
+
+// PeerEventHandler is called for every event of a BGP session. It is called from the FSM of the peer, so it must not block.
+type PeerEventHandler func(e PeerEvent)
+
+// peerEvents holds the registered handlers for peer events
+type peerEvents struct {
+	handlers map[uint64]PeerEventHandler
+	nextID   uint64
+	mu       sync.RWMutex
+}
+
+func newPeerEvents() *peerEvents {
+	return &peerEvents{
+		handlers: make(map[uint64]PeerEventHandler),
+	}
+}
+
+// RegisterPeerEventHandler registers a handler for session state changes, NOTIFICATIONs received and prefix limit events of all peers
+func (b *bgpServer) RegisterPeerEventHandler(h PeerEventHandler) uint64 {
+	return b.peerEvents.add(h)
+}
+
+// UnregisterPeerEventHandler removes the handler with the ID RegisterPeerEventHandler returned
+func (b *bgpServer) UnregisterPeerEventHandler(id uint64) {
+	b.peerEvents.remove(id)
+}
+
+func (e *peerEvents) add(h PeerEventHandler) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.nextID++
+	e.handlers[e.nextID] = h
+	return e.nextID
+}
+
+func (e *peerEvents) remove(id uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.handlers, id)
+}
+
+func (e *peerEvents) emit(ev PeerEvent) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, h := range e.handlers {
+		h(ev)
+	}
+}
+
+func (fsm *FSM) emitEvent(ev PeerEvent) {
+	if fsm.peer.server == nil {
+		return
+	}
+
+	ev.Peer = fsm.peer.addr
+	ev.Time = time.Now()
+	fsm.peer.server.peerEvents.emit(ev)
+}
+
+func (fsm *FSM) notificationReceivedEvent(n *packet.BGPNotification) {
+	fsm.peer.notifications.received(n)
+
+	communication, ok := n.ShutdownCommunication()
+	if ok {
+		log.WithFields(logrus.Fields{
+			"peer":    fsm.peer.addr.String(),
+			"message": communication,
+		}).Warn("Received shutdown communication")
+	}
+
+	fsm.emitEvent(PeerEvent{
+		Type:                  PeerEventNotificationReceived,
+		ErrorCode:             n.ErrorCode,
+		ErrorSubcode:          n.ErrorSubcode,
+		ShutdownCommunication: communication,
+	})
+}
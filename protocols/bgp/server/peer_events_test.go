@@ -0,0 +1,82 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerEvents(t *testing.T) {
+	b := newBGPServer(0, nil)
+	events := make([]PeerEvent, 0)
+	id := b.RegisterPeerEventHandler(func(e PeerEvent) {
+		events = append(events, e)
+	})
+
+	addr := bnet.IPv4FromOctets(192, 0, 2, 1).Ptr()
+	fsm := &FSM{
+		peer: &peer{
+			addr:   addr,
+			server: b,
+		},
+	}
+
+	a := adjRIBIn.New(filter.NewAcceptAllFilterChain(), routingtable.NewContributingASNs(), 100, 0, false)
+	f := &fsmAddressFamily{
+		afi:  packet.IPv4AFI,
+		safi: packet.UnicastSAFI,
+		fsm:  fsm,
+		prefixLimit: &PrefixLimit{
+			Max:               4,
+			WarningThresholds: []uint8{50, 75},
+		},
+		adjRIBIn:    a,
+		initialized: true,
+	}
+
+	for i := 0; i < 5; i++ {
+		a.AddPath(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, uint8(i), 0), 24).Ptr(), &route.Path{
+			Type:    route.BGPPathType,
+			BGPPath: &route.BGPPath{BGPPathA: &route.BGPPathA{}},
+		})
+		f.checkPrefixLimit()
+	}
+
+	fsm.notificationReceivedEvent(&packet.BGPNotification{
+		ErrorCode:    packet.Cease,
+		ErrorSubcode: packet.AdministrativeShutdown,
+	})
+
+	b.UnregisterPeerEventHandler(id)
+	fsm.notificationReceivedEvent(&packet.BGPNotification{
+		ErrorCode: packet.Cease,
+	})
+
+	expected := []PeerEventType{
+		PeerEventPrefixLimitWarning,
+		PeerEventPrefixLimitWarning,
+		PeerEventPrefixLimitExceeded,
+		PeerEventNotificationReceived,
+	}
+
+	if !assert.Equal(t, len(expected), len(events)) {
+		return
+	}
+
+	for i, e := range events {
+		assert.Equal(t, expected[i], e.Type, "Event %d", i)
+		assert.Equal(t, addr, e.Peer, "Event %d", i)
+	}
+
+	assert.Equal(t, uint8(50), events[0].Threshold)
+	assert.Equal(t, uint8(75), events[1].Threshold)
+	assert.Equal(t, uint64(5), events[2].Prefixes)
+	assert.Equal(t, uint64(4), events[2].Limit)
+	assert.Equal(t, uint8(packet.AdministrativeShutdown), events[3].ErrorSubcode)
+}
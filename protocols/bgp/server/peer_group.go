@@ -0,0 +1,410 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBOut"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/pkg/errors"
+)
+
+// PeerGroupConfig defines settings shared by the members of a peer group. Members (peers with PeerConfig.PeerGroup
+// set to Name) inherit all settings of Template they don't set themselves. Boolean settings can only be enabled per member.
+type PeerGroupConfig struct {
+	Name     string
+	Template PeerConfig
+}
+
+type peerGroups struct {
+	groups map[string]*PeerGroupConfig
+	mu     sync.RWMutex
+}
+
+func newPeerGroups() *peerGroups {
+	return &peerGroups{
+		groups: make(map[string]*PeerGroupConfig),
+	}
+}
+
+func (g *peerGroups) get(name string) *PeerGroupConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.groups[name]
+}
+
+func (g *peerGroups) list() []PeerGroupConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	res := make([]PeerGroupConfig, 0, len(g.groups))
+	for _, x := range g.groups {
+		res = append(res, *x)
+	}
+
+	return res
+}
+
+// AddPeerGroup adds a peer group or replaces the config of an existing one. Members are updated to the new settings,
+// sessions are restarted only if the change requires it.
+func (b *bgpServer) AddPeerGroup(g PeerGroupConfig) error {
+	if g.Name == "" {
+		return fmt.Errorf("Peer group without name")
+	}
+
+	b.peerGroups.mu.Lock()
+	_, exists := b.peerGroups.groups[g.Name]
+	b.peerGroups.groups[g.Name] = &g
+	b.peerGroups.mu.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	for _, p := range b.peers.list() {
+		if p.memberConfig == nil || p.memberConfig.PeerGroup != g.Name {
+			continue
+		}
+
+		c := p.memberConfig.inherit(&g.Template)
+		afChanged := addressFamiliesChanged(p.config, &c)
+		if p.config.NeedsRestart(&c) || (afChanged && !p.dynamicCapabilityNegotiated()) {
+			p.stop()
+			b.unregisterPeer(p)
+
+			err := b.AddPeer(*p.memberConfig)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to restart member %s of peer group %q", p.addr.String(), g.Name)
+			}
+
+			continue
+		}
+
+		if afChanged {
+			err := p.replaceAddressFamilies(&c)
+			if err != nil {
+				return errors.Wrapf(err, "Unable to replace address families of member %s of peer group %q", p.addr.String(), g.Name)
+			}
+		}
+
+		importChain, exportChain := c.filterChains()
+		p.replaceImportFilterChain(importChain)
+		p.replaceExportFilterChain(exportChain)
+	}
+
+	return nil
+}
+
+// RemovePeerGroup removes a peer group. Groups with members can not be removed.
+func (b *bgpServer) RemovePeerGroup(name string) error {
+	for _, p := range b.peers.list() {
+		if p.memberConfig != nil && p.memberConfig.PeerGroup == name {
+			return fmt.Errorf("Peer group %q still has members", name)
+		}
+	}
+
+	b.peerGroups.mu.Lock()
+	defer b.peerGroups.mu.Unlock()
+
+	delete(b.peerGroups.groups, name)
+	return nil
+}
+
+// GetPeerGroups gets all peer groups
+func (b *bgpServer) GetPeerGroups() []PeerGroupConfig {
+	return b.peerGroups.list()
+}
+
+// resolvePeerGroup applies the settings of the peer group of c. Returns the resulting config and
+// the config of the member as given (nil if c is not a member of a peer group).
+func (b *bgpServer) resolvePeerGroup(c PeerConfig) (PeerConfig, *PeerConfig, error) {
+	if c.PeerGroup == "" {
+		return c, nil, nil
+	}
+
+	g := b.peerGroups.get(c.PeerGroup)
+	if g == nil {
+		return c, nil, fmt.Errorf("Peer group %q not found", c.PeerGroup)
+	}
+
+	return c.inherit(&g.Template), &c, nil
+}
+
+// inherit returns the config of a peer group member: All settings not set in pc are taken from the group template g
+func (pc *PeerConfig) inherit(g *PeerConfig) PeerConfig {
+	c := *pc
+
+	if c.AuthenticationKey == "" {
+		c.AuthenticationKey = g.AuthenticationKey
+	}
+
+	if c.ReconnectInterval == 0 {
+		c.ReconnectInterval = g.ReconnectInterval
+	}
+
+	if c.MaxReconnectInterval == 0 {
+		c.MaxReconnectInterval = g.MaxReconnectInterval
+	}
+
+	if c.KeepAlive == 0 {
+		c.KeepAlive = g.KeepAlive
+	}
+
+	if c.HoldTime == 0 {
+		c.HoldTime = g.HoldTime
+	}
+
+	if c.LocalAddress == nil {
+		c.LocalAddress = g.LocalAddress
+	}
+
+	if c.TTL == 0 {
+		c.TTL = g.TTL
+	}
+
+	if c.TTLSecurityHops == 0 {
+		c.TTLSecurityHops = g.TTLSecurityHops
+	}
+
+	if c.LocalAS == 0 {
+		c.LocalAS = g.LocalAS
+	}
+
+	if c.PeerAS == 0 {
+		c.PeerAS = g.PeerAS
+	}
+
+	if c.RouterID == 0 {
+		c.RouterID = g.RouterID
+	}
+
+	if c.RouteReflectorClusterID == 0 {
+		c.RouteReflectorClusterID = g.RouteReflectorClusterID
+	}
+
+	if c.VRF == nil {
+		c.VRF = g.VRF
+	}
+
+	if c.Description == "" {
+		c.Description = g.Description
+	}
+
+	if c.GracefulRestart == nil {
+		c.GracefulRestart = g.GracefulRestart
+	}
+
+	if c.Multipath == route.MultipathDisabled {
+		c.Multipath = g.Multipath
+	}
+
+	c.AdminEnabled = c.AdminEnabled || g.AdminEnabled
+	c.Passive = c.Passive || g.Passive
+	c.ActiveOnly = c.ActiveOnly || g.ActiveOnly
+	c.RouteServerClient = c.RouteServerClient || g.RouteServerClient
+	c.NextHopTracking = c.NextHopTracking || g.NextHopTracking
+	if c.RemovePrivateAS == route.PrivateASKeep {
+		c.RemovePrivateAS = g.RemovePrivateAS
+	}
+	if c.AllowASIn == 0 {
+		c.AllowASIn = g.AllowASIn
+	}
+	c.ASOverride = c.ASOverride || g.ASOverride
+	if c.LocalASMigration == nil {
+		c.LocalASMigration = g.LocalASMigration
+	}
+	c.RouteReflectorClient = c.RouteReflectorClient || g.RouteReflectorClient
+	c.NoClientToClientReflection = c.NoClientToClientReflection || g.NoClientToClientReflection
+	c.IgnoreWellKnownCommunities = c.IgnoreWellKnownCommunities || g.IgnoreWellKnownCommunities
+	c.BilateralPeer = c.BilateralPeer || g.BilateralPeer
+	if c.ORRGroup == "" {
+		c.ORRGroup = g.ORRGroup
+	}
+	if c.ShutdownCommunication == "" {
+		c.ShutdownCommunication = g.ShutdownCommunication
+	}
+	if c.RecordDirectory == "" {
+		c.RecordDirectory = g.RecordDirectory
+	}
+	c.SkipOriginatorIDCheck = c.SkipOriginatorIDCheck || g.SkipOriginatorIDCheck
+	c.SkipClusterListCheck = c.SkipClusterListCheck || g.SkipClusterListCheck
+	c.AdvertiseIPv4MultiProtocol = c.AdvertiseIPv4MultiProtocol || g.AdvertiseIPv4MultiProtocol
+	c.DynamicCapability = c.DynamicCapability || g.DynamicCapability
+	c.ExtendedNextHop = c.ExtendedNextHop || g.ExtendedNextHop
+	c.LinkState = c.LinkState || g.LinkState
+	c.RouteTargetConstraint = c.RouteTargetConstraint || g.RouteTargetConstraint
+	c.IPv4FlowSpec = c.IPv4FlowSpec || g.IPv4FlowSpec
+	c.IPv6FlowSpec = c.IPv6FlowSpec || g.IPv6FlowSpec
+	c.FlowSpecNoValidation = c.FlowSpecNoValidation || g.FlowSpecNoValidation
+	if len(c.FlowSpecImportFilterChain) == 0 {
+		c.FlowSpecImportFilterChain = g.FlowSpecImportFilterChain
+	}
+	c.IPv4SRPolicy = c.IPv4SRPolicy || g.IPv4SRPolicy
+	c.IPv6SRPolicy = c.IPv6SRPolicy || g.IPv6SRPolicy
+
+	c.IPv4 = c.IPv4.inherit(g.IPv4)
+	c.IPv6 = c.IPv6.inherit(g.IPv6)
+	c.IPv4LabeledUnicast = c.IPv4LabeledUnicast.inherit(g.IPv4LabeledUnicast)
+	c.IPv6LabeledUnicast = c.IPv6LabeledUnicast.inherit(g.IPv6LabeledUnicast)
+	c.IPv4VPN = c.IPv4VPN.inherit(g.IPv4VPN)
+	c.IPv6VPN = c.IPv6VPN.inherit(g.IPv6VPN)
+
+	return c
+}
+
+// filterChains gets the filter chains of the first configured address family. Peers receiving FlowSpec rules only
+// get the FlowSpec import filter chain.
+func (pc *PeerConfig) filterChains() (filter.Chain, filter.Chain) {
+	for _, afc := range []*AddressFamilyConfig{pc.IPv4, pc.IPv6, pc.IPv4LabeledUnicast, pc.IPv6LabeledUnicast} {
+		if afc != nil {
+			return afc.ImportFilterChain, afc.ExportFilterChain
+		}
+	}
+
+	return pc.FlowSpecImportFilterChain, nil
+}
+
+// inherit returns the address family config of a peer group member. Members without the address family get the groups config.
+func (afc *AddressFamilyConfig) inherit(g *AddressFamilyConfig) *AddressFamilyConfig {
+	if afc == nil {
+		return g
+	}
+
+	if g == nil {
+		return afc
+	}
+
+	c := *afc
+	if c.ImportFilterChain == nil {
+		c.ImportFilterChain = g.ImportFilterChain
+	}
+
+	if c.ExportFilterChain == nil {
+		c.ExportFilterChain = g.ExportFilterChain
+	}
+
+	if c.AddPathSend == (routingtable.ClientOptions{}) {
+		c.AddPathSend = g.AddPathSend
+	}
+
+	c.AddPathRecv = c.AddPathRecv || g.AddPathRecv
+	if c.NextHopSelf == route.NextHopSelfDisabled {
+		c.NextHopSelf = g.NextHopSelf
+	}
+
+	return &c
+}
+
+// inherit returns the VPN config of a peer group member. Members without the address family get the groups config.
+func (vc *VPNConfig) inherit(g *VPNConfig) *VPNConfig {
+	if vc == nil {
+		return g
+	}
+
+	if g == nil {
+		return vc
+	}
+
+	c := *vc
+	if c.ImportFilterChain == nil {
+		c.ImportFilterChain = g.ImportFilterChain
+	}
+
+	if c.ExportFilterChain == nil {
+		c.ExportFilterChain = g.ExportFilterChain
+	}
+
+	if c.VRFs == nil {
+		c.VRFs = g.VRFs
+	}
+
+	return &c
+}
+
+// updateGroupKey identifies peer group members with identical outbound settings per address family
+type updateGroupKey struct {
+	peerGroup                  string
+	afi                        uint16
+	safi                       uint8
+	localASN                   uint32
+	localAddress               bnet.IP
+	iBGP                       bool
+	routeServerClient          bool
+	routeReflectorClient       bool
+	noClientToClientReflection bool
+	ignoreWellKnownCommunities bool
+	bilateralPeer              bool
+	clusterID                  uint32
+	addPathTX                  routingtable.ClientOptions
+	removePrivateAS            route.PrivateASMode
+	asOverride                 bool
+	migrationASN               uint32
+	replaceAS                  bool
+	peerASN                    uint32
+	nextHopSelf                route.NextHopSelfMode
+}
+
+// updateGroup shares export filtering and serialized path attributes between the members of a peer group with identical outbound settings
+type updateGroup struct {
+	key         updateGroupKey
+	exportChain filter.Chain
+	cache       *adjRIBOut.ExportCache
+	attrs       *attributeCache
+
+	// guarded by updateGroups.mu
+	members uint
+}
+
+type updateGroups struct {
+	groups []*updateGroup
+	mu     sync.Mutex
+}
+
+func newUpdateGroups() *updateGroups {
+	return &updateGroups{}
+}
+
+// join gets the update group for key and exportChain, creating it if it doesn't exist yet
+func (u *updateGroups) join(key updateGroupKey, exportChain filter.Chain) *updateGroup {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, g := range u.groups {
+		if g.key == key && g.exportChain.Equal(exportChain) {
+			g.members++
+			return g
+		}
+	}
+
+	g := &updateGroup{
+		key:         key,
+		exportChain: exportChain,
+		cache:       adjRIBOut.NewExportCache(),
+		attrs:       newAttributeCache(),
+		members:     1,
+	}
+	u.groups = append(u.groups, g)
+
+	return g
+}
+
+func (u *updateGroups) leave(g *updateGroup) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	g.members--
+	if g.members > 0 {
+		return
+	}
+
+	for i, x := range u.groups {
+		if x == g {
+			u.groups = append(u.groups[:i], u.groups[i+1:]...)
+			return
+		}
+	}
+}
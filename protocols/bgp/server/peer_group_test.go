@@ -0,0 +1,171 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerConfigInherit(t *testing.T) {
+	accept := filter.NewAcceptAllFilterChain()
+	drain := filter.NewDrainFilterChain()
+
+	tests := []struct {
+		name     string
+		member   PeerConfig
+		group    PeerConfig
+		expected PeerConfig
+	}{
+		{
+			name: "Settings from group",
+			member: PeerConfig{
+				PeerGroup:   "foo",
+				PeerAddress: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+			},
+			group: PeerConfig{
+				LocalAS:   65000,
+				PeerAS:    65001,
+				HoldTime:  time.Second * 90,
+				Passive:   true,
+				Multipath: route.MultipathMultipleAS,
+				IPv4: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: drain,
+				},
+			},
+			expected: PeerConfig{
+				PeerGroup:   "foo",
+				PeerAddress: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				LocalAS:     65000,
+				PeerAS:      65001,
+				HoldTime:    time.Second * 90,
+				Passive:     true,
+				Multipath:   route.MultipathMultipleAS,
+				IPv4: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: drain,
+				},
+			},
+		},
+		{
+			name: "Member overrides",
+			member: PeerConfig{
+				PeerGroup:   "foo",
+				PeerAddress: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				PeerAS:      65002,
+				HoldTime:    time.Second * 30,
+				IPv4: &AddressFamilyConfig{
+					ExportFilterChain: accept,
+					AddPathRecv:       true,
+				},
+			},
+			group: PeerConfig{
+				LocalAS:  65000,
+				PeerAS:   65001,
+				HoldTime: time.Second * 90,
+				IPv4: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: drain,
+				},
+				IPv6: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: accept,
+				},
+			},
+			expected: PeerConfig{
+				PeerGroup:   "foo",
+				PeerAddress: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				LocalAS:     65000,
+				PeerAS:      65002,
+				HoldTime:    time.Second * 30,
+				IPv4: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: accept,
+					AddPathRecv:       true,
+				},
+				IPv6: &AddressFamilyConfig{
+					ImportFilterChain: accept,
+					ExportFilterChain: accept,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, test.member.inherit(&test.group), "Test %q", test.name)
+	}
+}
+
+func TestUpdateGroups(t *testing.T) {
+	u := newUpdateGroups()
+	key := updateGroupKey{
+		peerGroup: "foo",
+		afi:       1,
+		safi:      1,
+		localASN:  65000,
+	}
+
+	a := u.join(key, filter.NewAcceptAllFilterChain())
+	b := u.join(key, filter.NewAcceptAllFilterChain())
+	assert.True(t, a == b, "Members with identical settings must share an update group")
+
+	c := u.join(key, filter.NewDrainFilterChain())
+	assert.True(t, a != c, "Members with different export filters must not share an update group")
+
+	otherKey := key
+	otherKey.iBGP = true
+	d := u.join(otherKey, filter.NewAcceptAllFilterChain())
+	assert.True(t, a != d, "Members with different outbound settings must not share an update group")
+	assert.Equal(t, 3, len(u.groups))
+
+	u.leave(a)
+	assert.Equal(t, 3, len(u.groups))
+	u.leave(b)
+	assert.Equal(t, 2, len(u.groups))
+}
+
+func TestPeerGroups(t *testing.T) {
+	b := newBGPServer(100, nil)
+	v := vrf.NewVRFRegistry().CreateVRFIfNotExists("master", 0)
+
+	member := PeerConfig{
+		PeerGroup:    "foo",
+		PeerAddress:  bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		LocalAddress: bnet.IPv4FromOctets(192, 0, 2, 0).Ptr(),
+		Passive:      true,
+	}
+	assert.Error(t, b.AddPeer(member), "Unknown peer group")
+
+	assert.Error(t, b.AddPeerGroup(PeerGroupConfig{}), "Group without name")
+	err := b.AddPeerGroup(PeerGroupConfig{
+		Name: "foo",
+		Template: PeerConfig{
+			LocalAS:  65000,
+			PeerAS:   65001,
+			HoldTime: time.Second * 90,
+			VRF:      v,
+			IPv4: &AddressFamilyConfig{
+				ImportFilterChain: filter.NewAcceptAllFilterChain(),
+				ExportFilterChain: filter.NewAcceptAllFilterChain(),
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(b.GetPeerGroups()))
+
+	assert.NoError(t, b.AddPeer(member))
+	c := b.GetPeerConfig(member.PeerAddress)
+	assert.Equal(t, uint32(65001), c.PeerAS)
+	assert.NotNil(t, c.IPv4)
+
+	assert.Error(t, b.RemovePeerGroup("foo"), "Group with members")
+
+	b.DisposePeer(member.PeerAddress)
+	assert.NoError(t, b.RemovePeerGroup("foo"))
+	assert.Equal(t, 0, len(b.GetPeerGroups()))
+}
@@ -31,6 +31,19 @@ func (m *peerManager) remove(neighborIP *bnet.IP) {
 	delete(m.peers, *neighborIP)
 }
 
+// removePeer removes p if it is still the peer registered for its address. Returns if p has been removed.
+func (m *peerManager) removePeer(p *peer) bool {
+	m.peersMu.Lock()
+	defer m.peersMu.Unlock()
+
+	if m.peers[*p.GetAddr()] != p {
+		return false
+	}
+
+	delete(m.peers, *p.GetAddr())
+	return true
+}
+
 func (m *peerManager) get(neighborIP *bnet.IP) *peer {
 	m.peersMu.RLock()
 	defer m.peersMu.RUnlock()
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceFilterChains(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+
+	rib := locRIB.New("inet.0")
+	p := &peer{
+		addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+		config:   &PeerConfig{},
+		ipv4: &peerAddressFamily{
+			rib:               rib,
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+
+	s := grTestSession(p, nil)
+	p.fsms = append(p.fsms, s.fsm)
+	defer s.manualStop()
+
+	s.update(grTestUpdate(pfx), time.Now())
+	assert.Equal(t, int64(1), rib.RouteCount())
+
+	p.replaceFilterChains(filter.NewDrainFilterChain(), filter.NewDrainFilterChain())
+	assert.Equal(t, 0, len(rib.Get(pfx).Paths()), "Paths received must be re-evaluated without session reset")
+	assert.True(t, filter.NewDrainFilterChain().Equal(p.ipv4.importFilterChain), "New sessions must use the new import chain")
+	assert.True(t, filter.NewDrainFilterChain().Equal(p.ipv4.exportFilterChain), "New sessions must use the new export chain")
+	assert.True(t, filter.NewDrainFilterChain().Equal(s.fsm.ipv4Unicast.exportFilterChain))
+
+	p.replaceFilterChains(filter.NewAcceptAllFilterChain(), nil)
+	assert.Equal(t, 1, len(rib.Get(pfx).Paths()))
+	assert.True(t, filter.NewDrainFilterChain().Equal(p.ipv4.exportFilterChain), "Empty chains must default to drain")
+}
+
+func TestReloadVPNFilterChains(t *testing.T) {
+	b := newBGPServer(100, nil)
+	vrfReg := vrf.NewVRFRegistry()
+	v := vrfReg.CreateVRFIfNotExists("master", 0)
+
+	accept := filter.NewAcceptAllFilterChain()
+	drain := filter.NewDrainFilterChain()
+
+	newConfig := func(importChain filter.Chain, exportChain filter.Chain) PeerConfig {
+		return PeerConfig{
+			PeerAddress:  bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+			LocalAddress: bnet.IPv4FromOctets(192, 0, 2, 0).Dedup(),
+			LocalAS:      65000,
+			PeerAS:       65000,
+			HoldTime:     time.Second * 90,
+			Passive:      true,
+			VRF:          v,
+			IPv4: &AddressFamilyConfig{
+				ImportFilterChain: importChain,
+				ExportFilterChain: exportChain,
+			},
+			IPv4VPN: &VPNConfig{
+				ImportFilterChain: importChain,
+				ExportFilterChain: exportChain,
+				VRFs:              vrfReg,
+			},
+			IPv4FlowSpec:              true,
+			FlowSpecImportFilterChain: importChain,
+		}
+	}
+
+	// reload applies a new config the way the daemon does on config reload
+	reload := func(c PeerConfig) {
+		if !b.GetPeerConfig(c.PeerAddress).NeedsRestart(&c) {
+			assert.NoError(t, b.ReplaceFilterChains(c.PeerAddress, c.IPv4.ImportFilterChain, c.IPv4.ExportFilterChain))
+			return
+		}
+
+		b.DisposePeer(c.PeerAddress)
+		assert.NoError(t, b.AddPeer(c))
+	}
+
+	c := newConfig(accept, accept)
+	assert.NoError(t, b.AddPeer(c))
+	defer b.DisposePeer(c.PeerAddress)
+
+	assert.False(t, b.GetPeerConfig(c.PeerAddress).NeedsRestart(&c), "Unchanged config")
+
+	tests := []struct {
+		name        string
+		importChain filter.Chain
+		exportChain filter.Chain
+	}{
+		{
+			name:        "New import policy",
+			importChain: drain,
+			exportChain: accept,
+		},
+		{
+			name:        "New export policy",
+			importChain: drain,
+			exportChain: drain,
+		},
+		{
+			name:        "Both policies reverted",
+			importChain: accept,
+			exportChain: accept,
+		},
+	}
+
+	for _, test := range tests {
+		c := newConfig(test.importChain, test.exportChain)
+		assert.True(t, b.GetPeerConfig(c.PeerAddress).NeedsRestart(&c), "Test %q: VPN filter chains changed", test.name)
+
+		reload(c)
+
+		res := b.GetPeerConfig(c.PeerAddress)
+		assert.True(t, test.importChain.Equal(res.IPv4VPN.ImportFilterChain), "Test %q", test.name)
+		assert.True(t, test.exportChain.Equal(res.IPv4VPN.ExportFilterChain), "Test %q", test.name)
+		assert.True(t, test.importChain.Equal(res.IPv4.ImportFilterChain), "Test %q", test.name)
+		assert.True(t, test.exportChain.Equal(res.IPv4.ExportFilterChain), "Test %q", test.name)
+		assert.True(t, test.importChain.Equal(res.FlowSpecImportFilterChain), "Test %q", test.name)
+	}
+}
+
+func TestReplaceFilterChainsConfig(t *testing.T) {
+	b := newBGPServer(100, nil)
+	v := vrf.NewVRFRegistry().CreateVRFIfNotExists("master", 0)
+
+	c := PeerConfig{
+		PeerAddress:  bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		LocalAddress: bnet.IPv4FromOctets(192, 0, 2, 0).Dedup(),
+		LocalAS:      65000,
+		PeerAS:       65001,
+		HoldTime:     time.Second * 90,
+		Passive:      true,
+		VRF:          v,
+		IPv4: &AddressFamilyConfig{
+			ImportFilterChain: filter.NewAcceptAllFilterChain(),
+			ExportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+		IPv4FlowSpec:              true,
+		FlowSpecImportFilterChain: filter.NewAcceptAllFilterChain(),
+	}
+	assert.NoError(t, b.AddPeer(c))
+	defer b.DisposePeer(c.PeerAddress)
+
+	assert.NoError(t, b.ReplaceFilterChains(c.PeerAddress, filter.NewDrainFilterChain(), filter.NewDrainFilterChain()))
+
+	res := b.GetPeerConfig(c.PeerAddress)
+	assert.True(t, filter.NewDrainFilterChain().Equal(res.IPv4.ImportFilterChain))
+	assert.True(t, filter.NewDrainFilterChain().Equal(res.IPv4.ExportFilterChain))
+	assert.True(t, filter.NewDrainFilterChain().Equal(res.FlowSpecImportFilterChain))
+	assert.True(t, filter.NewAcceptAllFilterChain().Equal(c.IPv4.ImportFilterChain), "The callers config must not be modified")
+}
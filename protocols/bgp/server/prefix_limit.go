@@ -0,0 +1,192 @@
+package server
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PrefixLimit limits the number of prefixes received from a peer for an address family
+type PrefixLimit struct {
+	// Max is the maximum number of prefixes. The session is torn down with a Cease NOTIFICATION (RFC4486) if it is exceeded.
+	Max uint64
+
+	// WarningThreshold is the percentage of Max a warning is logged at, 0 disables the warning
+	WarningThreshold uint8
+
+	// WarningThresholds are further percentages of Max a warning is logged at, e.g. 80 and 90 for escalating warnings
+	WarningThresholds []uint8
+
+	// WarningOnly only logs exceeding Max instead of tearing down the session
+	WarningOnly bool
+
+	// RestartInterval is the time after which a session torn down is restarted. With 0 the session stays down until the peer is reconfigured.
+	// Dynamic peers are removed with their session, so their sessions are never held down.
+	RestartInterval time.Duration
+}
+
+func (l *PrefixLimit) equal(x *PrefixLimit) bool {
+	if l.Max != x.Max || l.WarningOnly != x.WarningOnly || l.RestartInterval != x.RestartInterval {
+		return false
+	}
+
+	a, b := l.thresholds(), x.thresholds()
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// thresholds gets all warning thresholds in ascending order
+func (l *PrefixLimit) thresholds() []uint8 {
+	ret := make([]uint8, 0, len(l.WarningThresholds)+1)
+	for _, t := range append([]uint8{l.WarningThreshold}, l.WarningThresholds...) {
+		if t == 0 {
+			continue
+		}
+
+		i := sort.Search(len(ret), func(i int) bool { return ret[i] >= t })
+		if i < len(ret) && ret[i] == t {
+			continue
+		}
+
+		ret = append(ret, 0)
+		copy(ret[i+1:], ret[i:])
+		ret[i] = t
+	}
+
+	return ret
+}
+
+// threshold gets the highest warning threshold n prefixes reached, 0 if none
+func (l *PrefixLimit) threshold(n uint64) uint8 {
+	ret := uint8(0)
+	for _, t := range l.thresholds() {
+		if n < l.Max*uint64(t)/100 {
+			break
+		}
+
+		ret = t
+	}
+
+	return ret
+}
+
+// warning checks if n prefixes reached a warning threshold
+func (l *PrefixLimit) warning(n uint64) bool {
+	return l.threshold(n) != 0
+}
+
+// checkPrefixLimit checks the number of prefixes received against the prefix limit. Returns true if the limit is exceeded and the session has to be torn down.
+func (f *fsmAddressFamily) checkPrefixLimit() bool {
+	l := f.prefixLimit
+	if l == nil || !f.initialized {
+		return false
+	}
+
+	n := uint64(f.adjRIBIn.RouteCount())
+	fields := logrus.Fields{
+		"peer":     f.fsm.peer.addr.String(),
+		"afi":      f.afi,
+		"safi":     f.safi,
+		"prefixes": n,
+		"limit":    l.Max,
+	}
+
+	if n > l.Max {
+		if !f.prefixLimitExceeded {
+			f.prefixLimitExceeded = true
+			log.WithFields(fields).Warning("Prefix limit exceeded")
+			f.prefixLimitEvent(PeerEventPrefixLimitExceeded, n, 100)
+		}
+
+		return !l.WarningOnly
+	}
+	f.prefixLimitExceeded = false
+
+	t := l.threshold(n)
+	if t == 0 {
+		f.prefixLimitWarned = false
+		f.prefixLimitThreshold = 0
+		return false
+	}
+
+	if !f.prefixLimitWarned || t > f.prefixLimitThreshold {
+		fields["threshold"] = t
+		log.WithFields(fields).Warning("Prefix limit warning threshold reached")
+		f.prefixLimitEvent(PeerEventPrefixLimitWarning, n, t)
+	}
+	f.prefixLimitWarned = true
+	f.prefixLimitThreshold = t
+
+	return false
+}
+
+func (f *fsmAddressFamily) prefixLimitEvent(t PeerEventType, n uint64, threshold uint8) {
+	f.fsm.emitEvent(PeerEvent{
+		Type:      t,
+		AFI:       f.afi,
+		SAFI:      f.safi,
+		Prefixes:  n,
+		Limit:     f.prefixLimit.Max,
+		Threshold: threshold,
+	})
+}
+
+// holdForPrefixLimit keeps the sessions of the peer down after a prefix limit has been exceeded. They are restarted after d, never if d is 0.
+func (p *peer) holdForPrefixLimit(d time.Duration) {
+	atomic.AddUint64(&p.counters.prefixLimitExceeded, 1)
+
+	p.prefixLimitMu.Lock()
+	defer p.prefixLimitMu.Unlock()
+
+	p.prefixLimitHold = true
+	if d != 0 {
+		p.prefixLimitTimer = time.AfterFunc(d, p.releasePrefixLimitHold)
+	}
+}
+
+func (p *peer) releasePrefixLimitHold() {
+	p.prefixLimitMu.Lock()
+	p.prefixLimitHold = false
+	p.prefixLimitTimer = nil
+	p.prefixLimitMu.Unlock()
+
+	log.WithField("peer", p.addr.String()).Info("Prefix limit restart interval expired, restarting session")
+
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	if p.stopped || p.passive || len(p.fsms) == 0 || p.admin.isDown() {
+		return
+	}
+
+	go p.fsms[0].activate()
+}
+
+// heldForPrefixLimit checks if the sessions of the peer are kept down after a prefix limit has been exceeded
+func (p *peer) heldForPrefixLimit() bool {
+	p.prefixLimitMu.Lock()
+	defer p.prefixLimitMu.Unlock()
+
+	return p.prefixLimitHold
+}
+
+func (p *peer) stopPrefixLimitTimer() {
+	p.prefixLimitMu.Lock()
+	defer p.prefixLimitMu.Unlock()
+
+	if p.prefixLimitTimer != nil {
+		p.prefixLimitTimer.Stop()
+		p.prefixLimitTimer = nil
+	}
+}
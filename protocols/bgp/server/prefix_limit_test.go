@@ -0,0 +1,138 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPrefixLimit(t *testing.T) {
+	tests := []struct {
+		name              string
+		limit             *PrefixLimit
+		prefixes          int
+		expected          bool
+		expectedWarned    bool
+		expectedExceed    bool
+		expectedWarning   bool
+		expectedThreshold uint8
+	}{
+		{
+			name:     "No limit",
+			prefixes: 10,
+		},
+		{
+			name: "Below warning threshold",
+			limit: &PrefixLimit{
+				Max:              10,
+				WarningThreshold: 80,
+			},
+			prefixes: 7,
+		},
+		{
+			name: "Warning threshold reached",
+			limit: &PrefixLimit{
+				Max:              10,
+				WarningThreshold: 80,
+			},
+			prefixes:          8,
+			expectedWarned:    true,
+			expectedWarning:   true,
+			expectedThreshold: 80,
+		},
+		{
+			name: "Highest of multiple warning thresholds reached",
+			limit: &PrefixLimit{
+				Max:               10,
+				WarningThreshold:  50,
+				WarningThresholds: []uint8{90, 80},
+			},
+			prefixes:          8,
+			expectedWarned:    true,
+			expectedWarning:   true,
+			expectedThreshold: 80,
+		},
+		{
+			name: "Limit reached",
+			limit: &PrefixLimit{
+				Max: 10,
+			},
+			prefixes: 10,
+		},
+		{
+			name: "Limit exceeded",
+			limit: &PrefixLimit{
+				Max: 10,
+			},
+			prefixes:       11,
+			expected:       true,
+			expectedExceed: true,
+		},
+		{
+			name: "Limit exceeded with warning only",
+			limit: &PrefixLimit{
+				Max:         10,
+				WarningOnly: true,
+			},
+			prefixes:       11,
+			expectedExceed: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := adjRIBIn.New(filter.NewAcceptAllFilterChain(), routingtable.NewContributingASNs(), 100, 0, false)
+		for i := 0; i < test.prefixes; i++ {
+			pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, uint8(i), 0), 24)
+			a.AddPath(pfx.Ptr(), &route.Path{
+				Type:    route.BGPPathType,
+				BGPPath: &route.BGPPath{BGPPathA: &route.BGPPathA{}},
+			})
+		}
+
+		f := &fsmAddressFamily{
+			afi:         1,
+			safi:        1,
+			fsm:         &FSM{peer: &peer{addr: bnet.IPv4FromOctets(192, 0, 2, 1).Ptr()}},
+			adjRIBIn:    a,
+			prefixLimit: test.limit,
+			initialized: true,
+		}
+
+		assert.Equalf(t, test.expected, f.checkPrefixLimit(), "Test %q", test.name)
+		assert.Equalf(t, test.expectedWarned, f.prefixLimitWarned, "Test %q", test.name)
+		assert.Equalf(t, test.expectedExceed, f.prefixLimitExceeded, "Test %q", test.name)
+		assert.Equalf(t, test.expectedThreshold, f.prefixLimitThreshold, "Test %q", test.name)
+
+		if test.limit != nil {
+			assert.Equalf(t, test.expectedWarning, test.limit.warning(uint64(test.prefixes)), "Test %q", test.name)
+		}
+	}
+}
+
+func TestPrefixLimitHold(t *testing.T) {
+	p := &peer{
+		addr:    bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+		passive: true,
+	}
+
+	p.holdForPrefixLimit(0)
+	assert.True(t, p.heldForPrefixLimit())
+	assert.Equal(t, uint64(1), p.counters.prefixLimitExceeded)
+	p.releasePrefixLimitHold()
+	assert.False(t, p.heldForPrefixLimit())
+
+	p.holdForPrefixLimit(time.Millisecond * 10)
+	assert.True(t, p.heldForPrefixLimit())
+	assert.True(t, waitFor(func() bool { return !p.heldForPrefixLimit() }), "Hold not released after restart interval")
+
+	p.holdForPrefixLimit(time.Millisecond * 10)
+	p.stopPrefixLimitTimer()
+	time.Sleep(time.Millisecond * 50)
+	assert.True(t, p.heldForPrefixLimit(), "Hold released after timer has been stopped")
+}
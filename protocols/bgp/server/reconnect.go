@@ -0,0 +1,66 @@
+package server
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultMaxReconnectInterval is the longest interval between reconnection attempts if not configured otherwise
+const DefaultMaxReconnectInterval = 5 * time.Minute
+
+// reconnectBackoff doubles the interval between reconnection attempts to a peer after each failed attempt
+type reconnectBackoff struct {
+	mu       sync.Mutex
+	attempts uint
+}
+
+// next gets the interval before the next reconnection attempt. It starts at base and is limited to max.
+func (b *reconnectBackoff) next(base time.Duration, max time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if max < base {
+		max = base
+	}
+
+	d := base
+	for i := uint(0); i < b.attempts && d < max; i++ {
+		d *= 2
+	}
+
+	if d > max {
+		d = max
+	}
+
+	b.attempts++
+	return jitter(d)
+}
+
+// reset starts over with the base interval once a session has been established
+func (b *reconnectBackoff) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts = 0
+}
+
+// jitter reduces d by a random amount of up to 25% to keep peers from reconnecting in lockstep, e.g. after a restart
+// of a route server (RFC4271 10)
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+
+	return d - time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// reconnectDelay gets the interval before the next attempt to connect to the peer
+func (p *peer) reconnectDelay() time.Duration {
+	max := p.maxReconnectInterval
+	if max == 0 {
+		max = DefaultMaxReconnectInterval
+	}
+
+	return p.reconnectBackoff.next(p.reconnectInterval, max)
+}
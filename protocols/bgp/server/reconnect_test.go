@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		peer     *peer
+		expected []time.Duration
+	}{
+		{
+			name: "Exponential backoff",
+			peer: &peer{
+				reconnectInterval:    10 * time.Second,
+				maxReconnectInterval: time.Minute,
+			},
+			expected: []time.Duration{
+				10 * time.Second,
+				20 * time.Second,
+				40 * time.Second,
+				time.Minute,
+				time.Minute,
+			},
+		},
+		{
+			name: "Default max reconnect interval",
+			peer: &peer{
+				reconnectInterval: 2 * time.Minute,
+			},
+			expected: []time.Duration{
+				2 * time.Minute,
+				4 * time.Minute,
+				DefaultMaxReconnectInterval,
+			},
+		},
+		{
+			name: "Max reconnect interval below reconnect interval",
+			peer: &peer{
+				reconnectInterval:    time.Minute,
+				maxReconnectInterval: time.Second,
+			},
+			expected: []time.Duration{
+				time.Minute,
+				time.Minute,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for i, expected := range test.expected {
+			d := test.peer.reconnectDelay()
+			assert.True(t, d <= expected && d >= expected-expected/4, "Test %q attempt %d: unexpected delay %v", test.name, i, d)
+		}
+
+		test.peer.reconnectBackoff.reset()
+		d := test.peer.reconnectDelay()
+		assert.True(t, d <= test.expected[0], "Test %q: unexpected delay %v after reset", test.name, d)
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Soft reset directions
+const (
+	softResetIn = iota
+	softResetOut
+)
+
+// SoftReset refreshes the routes of a peer without restarting the session. Inbound the peer is asked to
+// send its routes again using ROUTE-REFRESH (RFC2918), outbound all routes are sent to the peer again.
+func (b *bgpServer) SoftReset(addr *bnet.IP, inbound bool, outbound bool) error {
+	p := b.peers.get(addr)
+	if p == nil {
+		return fmt.Errorf("peer %s not found", addr.String())
+	}
+
+	if inbound {
+		err := p.softReset(softResetIn)
+		if err != nil {
+			return err
+		}
+	}
+
+	if outbound {
+		err := p.softReset(softResetOut)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// softReset passes a soft reset to the established FSM of the peer
+func (p *peer) softReset(direction int) error {
+	p.fsmsMu.Lock()
+	defer p.fsmsMu.Unlock()
+
+	for _, fsm := range p.fsms {
+		fsm.stateMu.RLock()
+		established := isEstablishedState(fsm.state)
+		fsm.stateMu.RUnlock()
+
+		if !established {
+			continue
+		}
+
+		if direction == softResetIn && !fsm.routeRefresh {
+			return fmt.Errorf("peer %s does not support route refresh", p.addr.String())
+		}
+
+		select {
+		case fsm.softResetCh <- direction:
+		default:
+			return fmt.Errorf("soft reset of peer %s already pending", p.addr.String())
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no established session to peer %s", p.addr.String())
+}
+
+func (fsm *FSM) sendRouteRefresh(afi uint16, safi uint8, subtype uint8) error {
+	msg := packet.SerializeRouteRefreshMsg(&packet.BGPRouteRefresh{
+		AFI:     afi,
+		Subtype: subtype,
+		SAFI:    safi,
+	})
+
+	_, err := fsm.con.Write(msg)
+	if err != nil {
+		return errors.Wrap(err, "Unable to send ROUTE-REFRESH message")
+	}
+
+	fsm.peer.counters.messagesSent.count(packet.RouteRefreshMsg)
+	return nil
+}
+
+// refreshableAddressFamilies gets the initialized address families route refresh is supported for
+func (fsm *FSM) refreshableAddressFamilies() []*fsmAddressFamily {
+	ret := make([]*fsmAddressFamily, 0)
+	for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast, fsm.ipv4LabeledUnicast, fsm.ipv6LabeledUnicast} {
+		if f != nil && f.initialized {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret
+}
+
+func (s *establishedState) softReset(direction int) (state, string) {
+	for _, f := range s.fsm.refreshableAddressFamilies() {
+		if direction == softResetOut {
+			f.resendRoutes()
+			continue
+		}
+
+		err := s.fsm.sendRouteRefresh(f.afi, f.safi, packet.RouteRefreshRequest)
+		if err != nil {
+			log.WithError(err).WithField("peer", s.fsm.peer.addr.String()).Error("Soft reset failed")
+			break
+		}
+	}
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
+
+// routeRefresh handles a ROUTE-REFRESH message. Requests for families not negotiated and unknown subtypes are ignored (RFC7313 5).
+func (s *establishedState) routeRefresh(msg *packet.BGPRouteRefresh) (state, string) {
+	f := s.fsm.addressFamily(msg.AFI, msg.SAFI)
+	if f == nil || !f.initialized {
+		return newEstablishedState(s.fsm), s.fsm.reason
+	}
+
+	switch msg.Subtype {
+	case packet.RouteRefreshRequest:
+		f.resendRoutes()
+	case packet.BeginningOfRouteRefresh:
+		if s.fsm.enhancedRouteRefresh {
+			f.beginRouteRefresh()
+		}
+	case packet.EndOfRouteRefresh:
+		if s.fsm.enhancedRouteRefresh {
+			f.endRouteRefresh()
+		}
+	}
+
+	return newEstablishedState(s.fsm), s.fsm.reason
+}
+
+// resendRoutes sends all routes of the adj-RIB-out to the peer again. With enhanced route refresh
+// the routes are enclosed in BoRR and EoRR markers (RFC7313 4).
+func (f *fsmAddressFamily) resendRoutes() {
+	if f.advertisementDeferred {
+		// The routes will be sent once advertisement starts
+		return
+	}
+
+	f.updateSender.resend(f.adjRIBOut.Dump(), f.fsm.enhancedRouteRefresh)
+}
+
+// beginRouteRefresh marks all paths received from the peer stale. Paths not refreshed until EoRR are removed then (RFC7313 4.2).
+func (f *fsmAddressFamily) beginRouteRefresh() {
+	f.adjRIBIn.(*adjRIBIn.AdjRIBIn).MarkStale()
+}
+
+func (f *fsmAddressFamily) endRouteRefresh() {
+	removed := f.adjRIBIn.(*adjRIBIn.AdjRIBIn).RemoveStale()
+	if removed > 0 {
+		log.WithFields(logrus.Fields{
+			"peer":    f.fsm.peer.addr.String(),
+			"removed": removed,
+		}).Info("Removed stale paths after route refresh")
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	btest "github.com/bio-routing/bio-rd/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteRefreshReceived(t *testing.T) {
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(11, 0, 0, 0), 8).Ptr()
+
+	tests := []struct {
+		name     string
+		enhanced bool
+		expected int64
+	}{
+		{
+			name:     "Enhanced route refresh removes stale paths",
+			enhanced: true,
+			expected: 1,
+		},
+		{
+			name:     "Markers are ignored without enhanced route refresh",
+			expected: 2,
+		},
+	}
+
+	for _, test := range tests {
+		rib := locRIB.New("inet.0")
+		p := &peer{
+			addr:     bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+			routerID: bnet.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32(),
+			config:   &PeerConfig{},
+			ipv4: &peerAddressFamily{
+				rib:               rib,
+				importFilterChain: filter.NewAcceptAllFilterChain(),
+				exportFilterChain: filter.NewAcceptAllFilterChain(),
+			},
+		}
+
+		s := grTestSession(p, nil)
+		s.fsm.routeRefresh = true
+		s.fsm.enhancedRouteRefresh = test.enhanced
+
+		s.update(grTestUpdate(pfxA, pfxB), time.Now())
+		s.routeRefresh(&packet.BGPRouteRefresh{AFI: packet.IPv4AFI, Subtype: packet.BeginningOfRouteRefresh, SAFI: packet.UnicastSAFI})
+		s.update(grTestUpdate(pfxA), time.Now())
+		s.routeRefresh(&packet.BGPRouteRefresh{AFI: packet.IPv4AFI, Subtype: packet.EndOfRouteRefresh, SAFI: packet.UnicastSAFI})
+		assert.Equalf(t, test.expected, rib.RouteCount(), "Test %q", test.name)
+
+		s.manualStop()
+	}
+}
+
+func TestUpdateSenderResend(t *testing.T) {
+	tests := []struct {
+		name     string
+		enhanced bool
+		expected []uint8
+	}{
+		{
+			name:     "Route refresh",
+			expected: []uint8{packet.UpdateMsg},
+		},
+		{
+			name:     "Enhanced route refresh",
+			enhanced: true,
+			expected: []uint8{packet.RouteRefreshMsg, packet.UpdateMsg, packet.RouteRefreshMsg},
+		},
+	}
+
+	for _, test := range tests {
+		fsm := newFSM(&peer{
+			addr: bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+		})
+		fsm.ipv4Unicast = newFSMAddressFamily(packet.IPv4AFI, packet.UnicastSAFI, &peerAddressFamily{
+			rib:               locRIB.New("inet.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		}, fsm)
+		fsm.con = btest.NewMockConn()
+
+		u := newUpdateSender(fsm.ipv4Unicast)
+		u.Start(time.Millisecond)
+
+		u.resend([]*route.Route{
+			route.NewRoute(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), &route.Path{
+				Type: route.BGPPathType,
+				BGPPath: &route.BGPPath{
+					BGPPathA: &route.BGPPathA{
+						NextHop:   bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+						Source:    bnet.IPv4FromOctets(10, 0, 0, 1).Ptr(),
+						LocalPref: 100,
+					},
+					ASPath: &types.ASPath{},
+				},
+			}),
+		}, test.enhanced)
+		time.Sleep(time.Millisecond * 50)
+		u.Destroy()
+
+		recvBuffer := make([]byte, 4096)
+		n, _ := fsm.con.Read(recvBuffer)
+		buf := bytes.NewBuffer(recvBuffer[:n])
+
+		msgTypes := make([]uint8, 0)
+		subtypes := make([]uint8, 0)
+		for buf.Len() > 0 {
+			msg, err := packet.Decode(buf, &packet.DecodeOptions{})
+			if err != nil {
+				t.Fatalf("Unable to decode message in test %q: %v", test.name, err)
+			}
+
+			msgTypes = append(msgTypes, msg.Header.Type)
+			if rr, ok := msg.Body.(*packet.BGPRouteRefresh); ok {
+				subtypes = append(subtypes, rr.Subtype)
+			}
+		}
+
+		assert.Equalf(t, test.expected, msgTypes, "Test %q", test.name)
+		if test.enhanced {
+			assert.Equalf(t, []uint8{packet.BeginningOfRouteRefresh, packet.EndOfRouteRefresh}, subtypes, "Test %q", test.name)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+const (
+	// rtcLocalPref is the local preference of route target membership routes advertised via iBGP
+	rtcLocalPref = 100
+)
+
+// rtcAddressFamily holds the route target membership of a session (RFC4684). The import route targets of the VRFs are
+// advertised to the peer and VPN routes are only advertised to the peer for route targets it advertised itself.
+type rtcAddressFamily struct {
+	fsm  *FSM
+	opts *packet.EncodeOptions
+
+	// negotiated is set if the peer advertised the multi protocol capability for route target constraint
+	negotiated bool
+
+	mu          sync.Mutex
+	received    map[packet.RouteTargetNLRI]struct{}
+	initialized bool
+}
+
+func newRTCAddressFamily(fsm *FSM) *rtcAddressFamily {
+	return &rtcAddressFamily{
+		fsm: fsm,
+	}
+}
+
+func (f *rtcAddressFamily) init(localAddr *bnet.IP) {
+	f.mu.Lock()
+	f.opts = &packet.EncodeOptions{
+		Use32BitASN: f.fsm.supports4OctetASN,
+	}
+	f.received = make(map[packet.RouteTargetNLRI]struct{})
+	f.initialized = true
+	f.mu.Unlock()
+
+	f.advertise(localAddr)
+}
+
+func (f *rtcAddressFamily) dispose() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.received = nil
+	f.negotiated = false
+	f.initialized = false
+}
+
+// importRouteTargets gets the import route targets of all VRFs of the VPN address families of the peer
+func (f *rtcAddressFamily) importRouteTargets() types.ExtendedCommunities {
+	ret := make(types.ExtendedCommunities, 0)
+	seen := make(map[types.ExtendedCommunity]struct{})
+
+	for _, v := range f.fsm.vpnAddressFamilies() {
+		if v.cfg.VRFs == nil {
+			continue
+		}
+
+		for _, vrf := range v.cfg.VRFs.List() {
+			for _, rt := range vrf.ImportRouteTargets() {
+				if _, ok := seen[rt]; ok {
+					continue
+				}
+
+				seen[rt] = struct{}{}
+				ret = append(ret, rt)
+			}
+		}
+	}
+
+	return ret
+}
+
+// advertise advertises a route target membership route for each import route target followed by End-of-RIB
+func (f *rtcAddressFamily) advertise(localAddr *bnet.IP) {
+	rts := f.importRouteTargets()
+	if len(rts) > 0 {
+		nlris := make([]*packet.RouteTargetNLRI, 0, len(rts))
+		for _, rt := range rts {
+			nlris = append(nlris, packet.NewRouteTargetNLRI(f.fsm.peer.localASN, rt))
+		}
+
+		attrs, _ := f.fsm.originatedPathAttributes(rtcLocalPref)
+		err := f.send(&packet.BGPUpdate{
+			PathAttributes: &packet.PathAttribute{
+				TypeCode: packet.MultiProtocolReachNLRICode,
+				Value: packet.MultiProtocolReachNLRI{
+					AFI:          packet.IPv4AFI,
+					SAFI:         packet.RouteTargetConstraintSAFI,
+					NextHop:      localAddr,
+					RouteTargets: nlris,
+				},
+				Next: attrs,
+			},
+		})
+		if err != nil {
+			log.WithField("peer", f.fsm.peer.addr.String()).WithError(err).Error("Unable to advertise route target membership")
+		}
+	}
+
+	err := f.send(packet.EndOfRIB(packet.IPv4AFI, packet.RouteTargetConstraintSAFI))
+	if err != nil {
+		log.WithField("peer", f.fsm.peer.addr.String()).WithError(err).Error("Unable to send route target membership End-of-RIB")
+	}
+}
+
+func (f *rtcAddressFamily) send(u *packet.BGPUpdate) error {
+	err := serializeAndSendUpdate(f.fsm.con, u, f.opts)
+	if err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&f.fsm.counters.updatesSent, 1)
+	f.fsm.peer.counters.updateSent(u)
+	return nil
+}
+
+// processUpdate processes the route target membership routes of an update. VPN routes are advertised or withdrawn
+// if the route targets requested by the peer changed.
+func (f *rtcAddressFamily) processUpdate(u *packet.BGPUpdate) {
+	changed := false
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.MultiProtocolReachNLRICode:
+			nlri := pa.Value.(packet.MultiProtocolReachNLRI)
+			if nlri.AFI == packet.IPv4AFI && nlri.SAFI == packet.RouteTargetConstraintSAFI {
+				changed = f.update(nlri.RouteTargets, true) || changed
+			}
+		case packet.MultiProtocolUnreachNLRICode:
+			nlri := pa.Value.(packet.MultiProtocolUnreachNLRI)
+			if nlri.AFI == packet.IPv4AFI && nlri.SAFI == packet.RouteTargetConstraintSAFI {
+				changed = f.update(nlri.RouteTargets, false) || changed
+			}
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	for _, v := range f.fsm.vpnAddressFamilies() {
+		if v.initialized {
+			v.routeTargetsChanged()
+		}
+	}
+}
+
+func (f *rtcAddressFamily) update(nlris []*packet.RouteTargetNLRI, reach bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	changed := false
+	for _, n := range nlris {
+		_, ok := f.received[*n]
+		if ok == reach {
+			continue
+		}
+
+		changed = true
+		if reach {
+			f.received[*n] = struct{}{}
+		} else {
+			delete(f.received, *n)
+		}
+	}
+
+	return changed
+}
+
+// constrains returns if VPN routes advertised to the peer are limited to the route targets it requested
+func (f *rtcAddressFamily) constrains() bool {
+	return f != nil && f.negotiated
+}
+
+// requested returns if the peer advertised route target membership for at least one of rts
+func (f *rtcAddressFamily) requested(rts types.ExtendedCommunities) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for n := range f.received {
+		for _, rt := range rts {
+			if n.Matches(rt) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/vrf"
+	"github.com/stretchr/testify/assert"
+)
+
+func rtcTestUpdate(reach bool, nlris ...*packet.RouteTargetNLRI) *packet.BGPUpdate {
+	if !reach {
+		return &packet.BGPUpdate{
+			PathAttributes: &packet.PathAttribute{
+				TypeCode: packet.MultiProtocolUnreachNLRICode,
+				Value: packet.MultiProtocolUnreachNLRI{
+					AFI:          packet.IPv4AFI,
+					SAFI:         packet.RouteTargetConstraintSAFI,
+					RouteTargets: nlris,
+				},
+			},
+		}
+	}
+
+	return &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:          packet.IPv4AFI,
+				SAFI:         packet.RouteTargetConstraintSAFI,
+				NextHop:      bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+				RouteTargets: nlris,
+			},
+		},
+	}
+}
+
+func TestRouteTargetConstraint(t *testing.T) {
+	rtA, _ := types.NewRouteTarget(65000, 1)
+	rtB, _ := types.NewRouteTarget(65000, 2)
+
+	tests := []struct {
+		name       string
+		negotiated bool
+		updates    []*packet.BGPUpdate
+		expected   bool
+	}{
+		{
+			name:     "Not negotiated",
+			expected: true,
+		},
+		{
+			name:       "No route targets requested",
+			negotiated: true,
+			expected:   false,
+		},
+		{
+			name:       "Route target requested",
+			negotiated: true,
+			updates: []*packet.BGPUpdate{
+				rtcTestUpdate(true, packet.NewRouteTargetNLRI(65001, rtB)),
+			},
+			expected: true,
+		},
+		{
+			name:       "Other route target requested",
+			negotiated: true,
+			updates: []*packet.BGPUpdate{
+				rtcTestUpdate(true, packet.NewRouteTargetNLRI(65001, rtA)),
+			},
+			expected: false,
+		},
+		{
+			name:       "Default route target requested",
+			negotiated: true,
+			updates: []*packet.BGPUpdate{
+				rtcTestUpdate(true, &packet.RouteTargetNLRI{}),
+			},
+			expected: true,
+		},
+		{
+			name:       "Route target withdrawn",
+			negotiated: true,
+			updates: []*packet.BGPUpdate{
+				rtcTestUpdate(true, packet.NewRouteTargetNLRI(65001, rtB)),
+				rtcTestUpdate(false, packet.NewRouteTargetNLRI(65001, rtB)),
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		lm, err := labelmanager.New(config.DefaultMPLSConfig())
+		if err != nil {
+			t.Fatalf("Unable to create label manager: %v", err)
+		}
+
+		reg := vrf.NewVRFRegistry()
+		v := reg.CreateVRFIfNotExists("a", 1)
+		v.SetRouteTargets(types.ExtendedCommunities{rtA}, types.ExtendedCommunities{rtB})
+
+		p := &peer{
+			server:   &bgpServer{labels: lm},
+			addr:     bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+			localASN: 65000,
+			peerASN:  65000,
+			config: &PeerConfig{
+				IPv4VPN: &VPNConfig{
+					ImportFilterChain: filter.NewAcceptAllFilterChain(),
+					ExportFilterChain: filter.NewAcceptAllFilterChain(),
+					VRFs:              reg,
+				},
+				RouteTargetConstraint: true,
+			},
+		}
+
+		fsm := newFSM(p)
+		fsm.con = fakeConn{}
+		fsm.rtc.negotiated = test.negotiated
+
+		localAddr := bnet.IPv4FromOctets(10, 0, 0, 1).Ptr()
+		fsm.ipv4VPN.init(localAddr)
+		if fsm.rtc.constrains() {
+			fsm.rtc.init(localAddr)
+		}
+
+		assert.Equal(t, types.ExtendedCommunities{rtA}, fsm.rtc.importRouteTargets(), "Test %q", test.name)
+
+		for _, u := range test.updates {
+			fsm.rtc.processUpdate(u)
+		}
+
+		if !assert.Equal(t, 1, len(fsm.ipv4VPN.exports), "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, fsm.ipv4VPN.exports[0].isAllowed(), "Test %q", test.name)
+		fsm.ipv4VPN.dispose()
+		fsm.rtc.dispose()
+	}
+}
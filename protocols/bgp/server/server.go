@@ -1,19 +1,32 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net"
+	"sync"
+	"time"
 
+	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/adjRIBOut"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
 
 	"github.com/bio-routing/bio-rd/routingtable/adjRIBIn"
 
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/metrics"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/bio-routing/bio-rd/util/eventlog"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
 	bnetutils "github.com/bio-routing/bio-rd/util/net"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -23,15 +36,48 @@ const (
 type bgpServer struct {
 	listenAddrs []string
 	listeners   []*TCPListener
+	listenersMu sync.Mutex
 	acceptCh    chan net.Conn
 	peers       *peerManager
 	routerID    uint32
 	metrics     *metricsService
+	captures    *captureRegistry
+	eventLog    *eventlog.EventLog
+	flightRec   *flightrecorder.Registry
+	restart     *restartState
+	rov         originValidation
+	clusterIDs  *routingtable.ClusterIDs
+
+	listenRanges *listenRanges
+	peerGroups   *peerGroups
+	updateGroups *updateGroups
+	bmp          *bmpExporter
+	mrt          *mrtDumper
+	linkState    *linkStateTable
+	flowSpec     *flowSpecTable
+	srPolicies   *srPolicyTable
+	serializer   *updateSerializer
+	peerEvents   *peerEvents
+	orr          *orrGroups
+
+	// guarded by labelsMu
+	labels          *labelmanager.LabelManager
+	labelFIB        LabelFIB
+	vpnLabels       map[uint64]uint32
+	labelAllocators map[*locRIB.LocRIB]*labelAllocator
+	labelsMu        sync.Mutex
+
+	nextHopTrackers   map[*locRIB.LocRIB]*nextHopTracker
+	nextHopTrackersMu sync.Mutex
+
+	aggregators   map[*locRIB.LocRIB]*aggregator
+	aggregatorsMu sync.Mutex
 }
 
 type BGPServer interface {
 	RouterID() uint32
 	Start() error
+	Stop()
 	AddPeer(PeerConfig) error
 	GetPeerConfig(*bnet.IP) *PeerConfig
 	DisposePeer(*bnet.IP)
@@ -39,9 +85,51 @@ type BGPServer interface {
 	Metrics() (*metrics.BGPMetrics, error)
 	GetRIBIn(peerIP *bnet.IP, afi uint16, safi uint8) *adjRIBIn.AdjRIBIn
 	GetRIBOut(peerIP *bnet.IP, afi uint16, safi uint8) *adjRIBOut.AdjRIBOut
+	GetLocRIB(peerIP *bnet.IP, afi uint16, safi uint8) *locRIB.LocRIB
 	ConnectMockPeer(peer PeerConfig, con net.Conn)
 	ReplaceImportFilterChain(peer *bnet.IP, c filter.Chain) error
 	ReplaceExportFilterChain(peer *bnet.IP, c filter.Chain) error
+	ReplaceFilterChains(peer *bnet.IP, importChain filter.Chain, exportChain filter.Chain) error
+	StartCapture(peer *bnet.IP, w io.Writer) (uint64, error)
+	StopCapture(id uint64)
+	SetEventLog(l *eventlog.EventLog)
+	SetFlightRecorder(r *flightrecorder.Registry)
+	SetRestarting(selectionDeferralTime time.Duration, forwardingStatePreserved bool)
+	SetLabelManager(lm *labelmanager.LabelManager)
+	SetLabelFIB(fib LabelFIB)
+	SetVRPs(vrps []vrp.VRP)
+	SetPeerDebug(addr *bnet.IP, enabled bool) error
+	SetGracefulShutdown(addr *bnet.IP, enabled bool) error
+	AdminShutdown(addr *bnet.IP, drainTime time.Duration, message string) error
+	AdminReset(addr *bnet.IP, message string) error
+	AdminStart(addr *bnet.IP) error
+	ReplaceAddressFamilies(c PeerConfig) error
+	ReplaceAggregates(rib *locRIB.LocRIB, aggregates []AggregateConfig)
+	ResetCounters(addr *bnet.IP) error
+	SoftReset(addr *bnet.IP, inbound bool, outbound bool) error
+	AddListenRange(r ListenRange) error
+	RemoveListenRange(pfx *bnet.Prefix)
+	GetListenRanges() []ListenRange
+	IsDynamicPeer(addr *bnet.IP) bool
+	AddPeerGroup(g PeerGroupConfig) error
+	RemovePeerGroup(name string) error
+	GetPeerGroups() []PeerGroupConfig
+	AddBMPStation(c BMPStationConfig) error
+	RemoveBMPStation(addr string)
+	GetBMPStations() []BMPStationConfig
+	SetMRTDump(c *MRTDumpConfig) error
+	ReplayMRT(ctx context.Context, r io.Reader, opt MRTReplayOptions) (uint64, error)
+	UpdateLinkState(source string, entries []*LinkStateEntry)
+	GetLinkState() []*LinkStateEntry
+	RegisterFlowSpecClient(c FlowSpecClient)
+	UnregisterFlowSpecClient(c FlowSpecClient)
+	GetFlowSpecRules() []*FlowSpecRule
+	RegisterSRPolicyClient(c SRPolicyClient)
+	UnregisterSRPolicyClient(c SRPolicyClient)
+	GetSRPolicies() []*SRPolicy
+	RegisterPeerEventHandler(h PeerEventHandler) uint64
+	UnregisterPeerEventHandler(id uint64)
+	SetORRIGPCosts(group string, costs *route.IGPCostTable)
 }
 
 // NewBGPServer creates a new instance of bgpServer
@@ -54,12 +142,67 @@ func newBGPServer(routerID uint32, addrs []string) *bgpServer {
 		peers:       newPeerManager(),
 		routerID:    routerID,
 		listenAddrs: addrs,
+		captures:    newCaptureRegistry(),
+		clusterIDs:  routingtable.NewClusterIDs(),
+
+		listenRanges: newListenRanges(),
+		peerGroups:   newPeerGroups(),
+		updateGroups: newUpdateGroups(),
+		linkState:    newLinkStateTable(),
+		flowSpec:     newFlowSpecTable(),
+		srPolicies:   newSRPolicyTable(),
+		serializer:   newUpdateSerializer(0),
+		peerEvents:   newPeerEvents(),
+		orr:          newORRGroups(),
 	}
 
 	server.metrics = &metricsService{server}
+	server.bmp = newBMPExporter(server)
+	server.mrt = newMRTDumper(server)
 	return server
 }
 
+// SetEventLog sets the event log session state changes are recorded to
+func (b *bgpServer) SetEventLog(l *eventlog.EventLog) {
+	b.eventLog = l
+}
+
+// SetFlightRecorder sets the flight recorder FSM transitions are recorded to. Recorders are scoped by the VRF of the peer.
+// Sessions dropping out of established state other than by being removed are considered unexpected.
+func (b *bgpServer) SetFlightRecorder(r *flightrecorder.Registry) {
+	r.SetUnexpected("bgp", func(from string, to string) bool {
+		return from == stateNameEstablished && to != stateNameCease
+	})
+	b.flightRec = r
+}
+
+// SetRestarting puts the server into graceful restart restarting mode (RFC4724 4.1). Peers are told we restarted and
+// advertisements are deferred until all graceful restart capable peers sent End-of-RIB or selectionDeferralTime passed.
+// Must be called before peers are added.
+func (b *bgpServer) SetRestarting(selectionDeferralTime time.Duration, forwardingStatePreserved bool) {
+	if selectionDeferralTime == 0 {
+		selectionDeferralTime = DefaultSelectionDeferralTime
+	}
+
+	b.restart = newRestartState(selectionDeferralTime, forwardingStatePreserved)
+}
+
+// SetLabelManager sets the label manager labels of VPN and labeled unicast routes are allocated from
+func (b *bgpServer) SetLabelManager(lm *labelmanager.LabelManager) {
+	b.labelsMu.Lock()
+	defer b.labelsMu.Unlock()
+
+	b.labels = lm
+}
+
+// SetLabelFIB sets the forwarding table label bindings of labeled unicast routes are programmed into
+func (b *bgpServer) SetLabelFIB(fib LabelFIB) {
+	b.labelsMu.Lock()
+	defer b.labelsMu.Unlock()
+
+	b.labelFIB = fib
+}
+
 func (b *bgpServer) RouterID() uint32 {
 	return b.routerID
 }
@@ -77,14 +220,43 @@ func (b *bgpServer) GetPeers() []*bnet.IP {
 
 func (b *bgpServer) Start() error {
 	if len(b.listenAddrs) > 0 {
+		b.listenersMu.Lock()
+		defer b.listenersMu.Unlock()
+
 		acceptCh := make(chan net.Conn, 4096)
+		listeners := make([]*TCPListener, 0, len(b.listenAddrs))
 		for _, addr := range b.listenAddrs {
-			l, err := NewTCPListener(addr, acceptCh)
+			l, err := newTCPListener(addr)
 			if err != nil {
+				for _, l := range listeners {
+					l.Close()
+				}
 				return errors.Wrapf(err, "Failed to start TCPListener for %s", addr)
 			}
-			b.listeners = append(b.listeners, l)
+			listeners = append(listeners, l)
 		}
+
+		// Keys of peers added before have to be in place before the first connection is accepted
+		for _, p := range b.peers.list() {
+			if p.config == nil || p.config.AuthenticationKey == "" {
+				continue
+			}
+
+			for _, l := range listeners {
+				err := l.setTCPMD5(p.addr.ToNetIP(), p.config.AuthenticationKey)
+				if err != nil {
+					for _, l := range listeners {
+						l.Close()
+					}
+					return errors.Wrapf(err, "Unable to set TCP MD5 secret for %s", p.addr.String())
+				}
+			}
+		}
+
+		for _, l := range listeners {
+			go l.serve(acceptCh)
+		}
+		b.listeners = listeners
 		b.acceptCh = acceptCh
 
 		go b.incomingConnectionWorker()
@@ -93,6 +265,23 @@ func (b *bgpServer) Start() error {
 	return nil
 }
 
+// Stop disposes all peers and closes all listeners
+func (b *bgpServer) Stop() {
+	for _, addr := range b.GetPeers() {
+		b.DisposePeer(addr)
+	}
+	b.bmp.stop()
+	b.mrt.stop()
+
+	b.listenersMu.Lock()
+	defer b.listenersMu.Unlock()
+
+	for _, l := range b.listeners {
+		l.Close()
+	}
+	b.listeners = nil
+}
+
 // ReplaceImportFilterChain replaces a peers import filter
 func (b *bgpServer) ReplaceImportFilterChain(peerIP *bnet.IP, c filter.Chain) error {
 	p := b.peers.get(peerIP)
@@ -104,7 +293,7 @@ func (b *bgpServer) ReplaceImportFilterChain(peerIP *bnet.IP, c filter.Chain) er
 	return nil
 }
 
-// ReplaceExportFilterChain replaces a peers import filter
+// ReplaceExportFilterChain replaces a peers export filter
 func (b *bgpServer) ReplaceExportFilterChain(peerIP *bnet.IP, c filter.Chain) error {
 	p := b.peers.get(peerIP)
 	if p == nil {
@@ -115,6 +304,17 @@ func (b *bgpServer) ReplaceExportFilterChain(peerIP *bnet.IP, c filter.Chain) er
 	return nil
 }
 
+// ReplaceFilterChains replaces a peers import and export filters at once without resetting its sessions
+func (b *bgpServer) ReplaceFilterChains(peerIP *bnet.IP, importChain filter.Chain, exportChain filter.Chain) error {
+	p := b.peers.get(peerIP)
+	if p == nil {
+		return fmt.Errorf("Peer %q not found", peerIP.String())
+	}
+
+	p.replaceFilterChains(importChain, exportChain)
+	return nil
+}
+
 func (b *bgpServer) GetRIBIn(peerIP *bnet.IP, afi uint16, safi uint8) *adjRIBIn.AdjRIBIn {
 	p := b.peers.get(peerIP)
 	if p == nil {
@@ -127,7 +327,7 @@ func (b *bgpServer) GetRIBIn(peerIP *bnet.IP, afi uint16, safi uint8) *adjRIBIn.
 
 	fsm := p.fsms[0]
 	f := fsm.addressFamily(afi, safi)
-	if f == nil {
+	if f == nil || f.adjRIBIn == nil {
 		return nil
 	}
 
@@ -146,28 +346,73 @@ func (b *bgpServer) GetRIBOut(peerIP *bnet.IP, afi uint16, safi uint8) *adjRIBOu
 
 	fsm := p.fsms[0]
 	f := fsm.addressFamily(afi, safi)
-	if f == nil {
+	if f == nil || f.adjRIBOut == nil {
 		return nil
 	}
 
 	return f.adjRIBOut.(*adjRIBOut.AdjRIBOut)
 }
 
+// GetLocRIB gets the Loc-RIB the routes of a peer for a given AFI/SAFI are installed into
+func (b *bgpServer) GetLocRIB(peerIP *bnet.IP, afi uint16, safi uint8) *locRIB.LocRIB {
+	p := b.peers.get(peerIP)
+	if p == nil {
+		return nil
+	}
+
+	f := p.addressFamily(afi, safi)
+	if f == nil {
+		return nil
+	}
+
+	return f.rib
+}
+
 func (b *bgpServer) incomingConnectionWorker() {
+	defer crashdump.Recover()
+
 	for {
 		c := <-b.acceptCh
 
 		peerAddr, _ := bnetutils.BIONetIPFromAddr(c.RemoteAddr().String())
 		peer := b.peers.get(peerAddr.Dedup())
+		if peer == nil {
+			peer = b.dynamicPeer(peerAddr.Dedup())
+		}
+
 		if peer == nil {
 			c.Close()
-			log.WithFields(log.Fields{
+			log.WithFields(logrus.Fields{
 				"source": c.RemoteAddr(),
 			}).Warning("TCP connection from unknown source")
 			continue
 		}
 
-		log.WithFields(log.Fields{
+		if peer.heldForPrefixLimit() {
+			c.Close()
+			log.WithFields(logrus.Fields{
+				"source": c.RemoteAddr(),
+			}).Info("Rejecting TCP connection of peer held down after exceeding a prefix limit")
+			continue
+		}
+
+		if peer.admin.isDown() {
+			c.Close()
+			log.WithFields(logrus.Fields{
+				"source": c.RemoteAddr(),
+			}).Info("Rejecting TCP connection of peer shut down administratively")
+			continue
+		}
+
+		if peer.activeOnly {
+			c.Close()
+			log.WithFields(logrus.Fields{
+				"source": c.RemoteAddr(),
+			}).Info("Rejecting TCP connection of active only peer")
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
 			"source": c.RemoteAddr(),
 		}).Info("Incoming TCP connection")
 
@@ -177,6 +422,11 @@ func (b *bgpServer) incomingConnectionWorker() {
 		fsm.startConnectRetryTimer()
 
 		peer.fsmsMu.Lock()
+		if peer.stopped {
+			peer.fsmsMu.Unlock()
+			c.Close()
+			continue
+		}
 		peer.fsms = append(peer.fsms, fsm)
 		peer.fsmsMu.Unlock()
 
@@ -194,6 +444,11 @@ func (b *bgpServer) ConnectMockPeer(peer PeerConfig, con net.Conn) {
 }
 
 func (b *bgpServer) AddPeer(c PeerConfig) error {
+	c, member, err := b.resolvePeerGroup(c)
+	if err != nil {
+		return err
+	}
+
 	c.LocalAddress = c.LocalAddress.Dedup()
 	c.PeerAddress = c.PeerAddress.Dedup()
 
@@ -201,23 +456,34 @@ func (b *bgpServer) AddPeer(c PeerConfig) error {
 	if err != nil {
 		return err
 	}
+	peer.memberConfig = member
 
-	if c.AuthenticationKey != "" {
-		for _, l := range b.listeners {
-			err = l.setTCPMD5(c.PeerAddress.ToNetIP(), c.AuthenticationKey)
-			if err != nil {
-				return errors.Wrap(err, "Unable to set TCP MD5 secret")
-			}
-		}
+	err = b.setTCPMD5(c.PeerAddress, c.AuthenticationKey)
+	if err != nil {
+		return err
 	}
 
 	peer.routerID = c.RouterID
+	if c.GracefulRestart != nil {
+		if c.IPv4 != nil {
+			b.restart.wait(c.PeerAddress, packet.IPv4AFI, packet.UnicastSAFI)
+		}
+
+		if c.IPv6 != nil {
+			b.restart.wait(c.PeerAddress, packet.IPv6AFI, packet.UnicastSAFI)
+		}
+	}
+
 	b.peers.add(peer)
+	if peer.routeReflectorClient {
+		b.clusterIDs.Add(peer.clusterID)
+	}
+
 	if !c.Passive {
 		peer.Start()
 	}
 
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"peer_address":  c.PeerAddress,
 		"local_address": c.LocalAddress,
 		"peer_as":       c.PeerAS,
@@ -237,6 +503,38 @@ func (b *bgpServer) GetPeerConfig(addr *bnet.IP) *PeerConfig {
 	return nil
 }
 
+// setTCPMD5 installs the TCP MD5 secret of a peer on all listeners (RFC2385)
+func (b *bgpServer) setTCPMD5(addr *bnet.IP, secret string) error {
+	if secret == "" {
+		return nil
+	}
+
+	b.listenersMu.Lock()
+	defer b.listenersMu.Unlock()
+
+	for _, l := range b.listeners {
+		err := l.setTCPMD5(addr.ToNetIP(), secret)
+		if err != nil {
+			return errors.Wrap(err, "Unable to set TCP MD5 secret")
+		}
+	}
+
+	return nil
+}
+
+// removeTCPMD5 removes the TCP MD5 secret of a peer from all listeners
+func (b *bgpServer) removeTCPMD5(addr *bnet.IP) {
+	b.listenersMu.Lock()
+	defer b.listenersMu.Unlock()
+
+	for _, l := range b.listeners {
+		err := l.removeTCPMD5(addr.ToNetIP())
+		if err != nil {
+			log.WithError(err).Errorf("Unable to remove TCP MD5 secret of %s", addr.String())
+		}
+	}
+}
+
 func (b *bgpServer) DisposePeer(addr *bnet.IP) {
 	p := b.peers.get(addr)
 	if p == nil {
@@ -245,7 +543,54 @@ func (b *bgpServer) DisposePeer(addr *bnet.IP) {
 
 	log.Infof("Disposing BGP session with %s", addr.String())
 	p.stop()
-	b.peers.remove(addr)
+	b.unregisterPeer(p)
+}
+
+// unregisterPeer removes a stopped peer and all state kept for it. Peers already removed or replaced are ignored.
+func (b *bgpServer) unregisterPeer(p *peer) {
+	if !b.peers.removePeer(p) {
+		return
+	}
+
+	if p.routeReflectorClient {
+		b.clusterIDs.Remove(p.clusterID)
+	}
+
+	if p.config != nil && p.config.AuthenticationKey != "" {
+		b.removeTCPMD5(p.addr)
+	}
+
+	if p.listenRange != nil {
+		b.listenRanges.release(p.listenRange)
+	}
+
+	b.restart.removePeer(p.addr)
+	p.flightRecorder().Remove("bgp", p.addr.String())
+}
+
+// ResetCounters resets the update and flap counters of a peer (all peers if addr is nil) without affecting the session
+func (b *bgpServer) ResetCounters(addr *bnet.IP) error {
+	peers := b.peers.list()
+	if addr != nil {
+		p := b.peers.get(addr)
+		if p == nil {
+			return fmt.Errorf("peer %s not found", addr.String())
+		}
+
+		peers = []*peer{p}
+	}
+
+	for _, p := range peers {
+		p.counters.reset()
+
+		p.fsmsMu.Lock()
+		for _, fsm := range p.fsms {
+			fsm.counters.reset()
+		}
+		p.fsmsMu.Unlock()
+	}
+
+	return nil
 }
 
 func (b *bgpServer) Metrics() (*metrics.BGPMetrics, error) {
@@ -255,3 +600,23 @@ func (b *bgpServer) Metrics() (*metrics.BGPMetrics, error) {
 
 	return b.metrics.metrics(), nil
 }
+
+// establishedFSMs gets the FSMs of all peers with an established session
+func (b *bgpServer) establishedFSMs() []*FSM {
+	res := make([]*FSM, 0)
+	for _, p := range b.peers.list() {
+		p.fsmsMu.Lock()
+		for _, fsm := range p.fsms {
+			fsm.stateMu.RLock()
+			established := isEstablishedState(fsm.state)
+			fsm.stateMu.RUnlock()
+
+			if established && fsm.ribsInitialized {
+				res = append(res, fsm)
+			}
+		}
+		p.fsmsMu.Unlock()
+	}
+
+	return res
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Session recordings hold the raw byte stream of a BGP session as read from and written to the socket.
+// Format (all integers in network byte order):
+//
+//	header: magic "BIORDREC", version (uint8), local and remote address (each uint8 length followed by the string)
+//	chunk:  timestamp (uint64, nanoseconds since the unix epoch), direction (uint8), length (uint32), data
+const (
+	sessionRecordMagic   = "BIORDREC"
+	sessionRecordVersion = 1
+
+	// RecordReceived and RecordSent are the directions of recorded chunks
+	RecordReceived = 0
+	RecordSent     = 1
+
+	recordChunkHeaderLen     = 13
+	sessionRecordMaxChunkLen = 1 << 24
+)
+
+// RecordChunk is a chunk of data read from or written to the socket of a recorded session
+type RecordChunk struct {
+	Timestamp time.Time
+	Direction uint8
+	Data      []byte
+}
+
+// recordConn writes all data sent and received on a connection to a session recording
+type recordConn struct {
+	net.Conn
+	f      *os.File
+	w      *bufio.Writer
+	mu     sync.Mutex
+	failed bool
+}
+
+// newRecordConn creates a recording of the session on c in dir. The file name consists of the peer address and the
+// current time.
+func newRecordConn(c net.Conn, dir string, peer *bnet.IP) (*recordConn, error) {
+	name := fmt.Sprintf("%s-%s.bgprec", strings.ReplaceAll(peer.String(), ":", "_"), time.Now().UTC().Format("20060102T150405.000000000Z"))
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to create session recording")
+	}
+
+	rc := &recordConn{
+		Conn: c,
+		f:    f,
+		w:    bufio.NewWriter(f),
+	}
+
+	err = writeRecordHeader(rc.w, addrString(c.LocalAddr()), addrString(c.RemoteAddr()))
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "Unable to write session recording header")
+	}
+
+	return rc, nil
+}
+
+func addrString(a net.Addr) string {
+	if a == nil {
+		return ""
+	}
+
+	return a.String()
+}
+
+func (c *recordConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.record(RecordReceived, b[:n])
+	}
+
+	return n, err
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.record(RecordSent, b[:n])
+	}
+
+	return n, err
+}
+
+func (c *recordConn) record(direction uint8, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failed {
+		return
+	}
+
+	err := writeRecordChunk(c.w, &RecordChunk{
+		Timestamp: time.Now(),
+		Direction: direction,
+		Data:      data,
+	})
+	if err == nil {
+		// Flushing each chunk keeps the recording complete if the process crashes while decoding a message
+		err = c.w.Flush()
+	}
+
+	if err != nil {
+		c.failed = true
+		log.WithError(err).WithFields(logrus.Fields{
+			"file": c.f.Name(),
+		}).Error("Unable to write session recording, recording stopped")
+	}
+}
+
+// Close closes the connection and the recording
+func (c *recordConn) Close() error {
+	err := c.Conn.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f != nil {
+		c.w.Flush()
+		c.f.Close()
+		c.f = nil
+		c.failed = true
+	}
+
+	return err
+}
+
+func writeRecordHeader(w io.Writer, local string, remote string) error {
+	if len(local) > 255 || len(remote) > 255 {
+		return fmt.Errorf("Address too long")
+	}
+
+	buf := bytes.NewBufferString(sessionRecordMagic)
+	buf.WriteByte(sessionRecordVersion)
+	buf.WriteByte(uint8(len(local)))
+	buf.WriteString(local)
+	buf.WriteByte(uint8(len(remote)))
+	buf.WriteString(remote)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeRecordChunk(w io.Writer, c *RecordChunk) error {
+	hdr := make([]byte, recordChunkHeaderLen)
+	endian.PutUint64(hdr[0:8], uint64(c.Timestamp.UnixNano()))
+	hdr[8] = c.Direction
+	endian.PutUint32(hdr[9:13], uint32(len(c.Data)))
+
+	_, err := w.Write(hdr)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(c.Data)
+	return err
+}
+
+// RecordReader reads session recordings
+type RecordReader struct {
+	r io.Reader
+
+	// LocalAddr and RemoteAddr are the addresses of the recorded session
+	LocalAddr  string
+	RemoteAddr string
+}
+
+// NewRecordReader creates a reader for a session recording and reads its header
+func NewRecordReader(r io.Reader) (*RecordReader, error) {
+	rr := &RecordReader{
+		r: bufio.NewReader(r),
+	}
+
+	hdr := make([]byte, len(sessionRecordMagic)+1)
+	_, err := io.ReadFull(rr.r, hdr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read header")
+	}
+
+	if string(hdr[:len(sessionRecordMagic)]) != sessionRecordMagic {
+		return nil, fmt.Errorf("Not a session recording")
+	}
+
+	if hdr[len(sessionRecordMagic)] != sessionRecordVersion {
+		return nil, fmt.Errorf("Unsupported session recording version %d", hdr[len(sessionRecordMagic)])
+	}
+
+	rr.LocalAddr, err = rr.readString()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read local address")
+	}
+
+	rr.RemoteAddr, err = rr.readString()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read remote address")
+	}
+
+	return rr, nil
+}
+
+func (rr *RecordReader) readString() (string, error) {
+	l := make([]byte, 1)
+	_, err := io.ReadFull(rr.r, l)
+	if err != nil {
+		return "", err
+	}
+
+	s := make([]byte, l[0])
+	_, err = io.ReadFull(rr.r, s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(s), nil
+}
+
+// Next reads the next chunk. It returns io.EOF at the end of the recording.
+func (rr *RecordReader) Next() (*RecordChunk, error) {
+	hdr := make([]byte, recordChunkHeaderLen)
+	_, err := io.ReadFull(rr.r, hdr)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		return nil, errors.Wrap(err, "Unable to read chunk header")
+	}
+
+	l := endian.Uint32(hdr[9:13])
+	if l > sessionRecordMaxChunkLen {
+		return nil, fmt.Errorf("Chunk of %d bytes exceeds maximum length", l)
+	}
+
+	c := &RecordChunk{
+		Timestamp: time.Unix(0, int64(endian.Uint64(hdr[0:8]))),
+		Direction: hdr[8],
+		Data:      make([]byte, l),
+	}
+
+	_, err = io.ReadFull(rr.r, c.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read chunk data")
+	}
+
+	return c, nil
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordConn(t *testing.T) {
+	dir := t.TempDir()
+	local, remote := net.Pipe()
+
+	rc, err := newRecordConn(local, dir, bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	go func() {
+		remote.Write([]byte{1, 2, 3})
+		buf := make([]byte, 2)
+		io.ReadFull(remote, buf)
+		remote.Close()
+	}()
+
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(rc, buf)
+	assert.NoError(t, err)
+	_, err = rc.Write([]byte{4, 5})
+	assert.NoError(t, err)
+	rc.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "2001_DB8_0_0_0_0_0_1-*.bgprec"))
+	if !assert.NoError(t, err) || !assert.Len(t, files, 1) {
+		return
+	}
+
+	f, err := os.Open(files[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	rr, err := NewRecordReader(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "pipe", rr.LocalAddr)
+	assert.Equal(t, "pipe", rr.RemoteAddr)
+
+	var chunks []*RecordChunk
+	for {
+		c, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.False(t, c.Timestamp.IsZero())
+		c.Timestamp = time.Time{}
+		chunks = append(chunks, c)
+	}
+
+	assert.Equal(t, []*RecordChunk{
+		{
+			Direction: RecordReceived,
+			Data:      []byte{1, 2, 3},
+		},
+		{
+			Direction: RecordSent,
+			Data:      []byte{4, 5},
+		},
+	}, chunks)
+}
+
+func TestRecordReaderInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Truncated header",
+			input: []byte("BIORD"),
+		},
+		{
+			name:  "Wrong magic",
+			input: []byte("MRTDUMPS\x01\x00\x00"),
+		},
+		{
+			name:  "Unsupported version",
+			input: []byte("BIORDREC\x02\x00\x00"),
+		},
+	}
+
+	for _, test := range tests {
+		_, err := NewRecordReader(bytes.NewReader(test.input))
+		assert.Error(t, err, "Test %q", test.name)
+	}
+}
+
+func testRecordOpen(asn uint32, routerID uint32) []byte {
+	return packet.SerializeOpenMsg(&packet.BGPOpen{
+		Version:       4,
+		ASN:           uint16(asn),
+		HoldTime:      90,
+		BGPIdentifier: routerID,
+		OptParams: []packet.OptParam{
+			{
+				Type: packet.CapabilitiesParamType,
+				Value: packet.Capabilities{
+					{
+						Code:   packet.ASN4CapabilityCode,
+						Length: 4,
+						Value: packet.ASN4Capability{
+							ASN4: asn,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func testRecording(t *testing.T, chunks []*RecordChunk) []byte {
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, writeRecordHeader(buf, "192.0.2.0:179", "192.0.2.1:41234"))
+	for _, c := range chunks {
+		assert.NoError(t, writeRecordChunk(buf, c))
+	}
+
+	return buf.Bytes()
+}
+
+func TestReplaySession(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	update := []byte{
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 47, // Length
+		2,    // UPDATE
+		0, 0, // Withdrawn routes length
+		0, 20, // Total path attribute length
+		0x40, 1, 1, 0, // ORIGIN
+		0x40, 2, 6, 2, 1, 0, 0, 0xfd, 0xe9, // AS_PATH
+		0x40, 3, 4, 192, 0, 2, 1, // NEXT_HOP
+		24, 198, 51, 100, // NLRI
+	}
+	malformedUpdate := []byte{
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 27, // Length
+		2,    // UPDATE
+		0, 0, // Withdrawn routes length
+		0, 100, // Total path attribute length
+		0x40, 1, 1, 0, // ORIGIN
+	}
+
+	sentOpen := &RecordChunk{Timestamp: ts, Direction: RecordSent, Data: testRecordOpen(65000, 0xc0000201)}
+	recvOpen := &RecordChunk{Timestamp: ts, Direction: RecordReceived, Data: testRecordOpen(65001, 0xc0000202)}
+	keepalive := &RecordChunk{Timestamp: ts, Direction: RecordReceived, Data: packet.SerializeKeepaliveMsg()}
+
+	tests := []struct {
+		name             string
+		chunks           []*RecordChunk
+		wantFail         bool
+		wantMessages     uint64
+		wantEstablished  bool
+		wantNotification bool
+		wantRoutes       int
+	}{
+		{
+			name: "Routes learned",
+			chunks: []*RecordChunk{
+				sentOpen,
+				recvOpen,
+				keepalive,
+				{Timestamp: ts, Direction: RecordReceived, Data: update},
+			},
+			wantMessages:    3,
+			wantEstablished: true,
+			wantRoutes:      1,
+		},
+		{
+			name: "OPEN received first and message split over chunks",
+			chunks: []*RecordChunk{
+				recvOpen,
+				sentOpen,
+				{Timestamp: ts, Direction: RecordReceived, Data: append(packet.SerializeKeepaliveMsg(), update[:30]...)},
+				{Timestamp: ts, Direction: RecordReceived, Data: update[30:]},
+			},
+			wantMessages:    3,
+			wantEstablished: true,
+			wantRoutes:      1,
+		},
+		{
+			name: "NOTIFICATION received",
+			chunks: []*RecordChunk{
+				sentOpen,
+				recvOpen,
+				{Timestamp: ts, Direction: RecordReceived, Data: update},
+				{Timestamp: ts, Direction: RecordReceived, Data: packet.SerializeNotificationMsg(&packet.BGPNotification{
+					ErrorCode:    packet.Cease,
+					ErrorSubcode: packet.AdministrativeShutdown,
+				})},
+				keepalive,
+			},
+			wantMessages:     3,
+			wantNotification: true,
+			wantRoutes:       1,
+		},
+		{
+			name: "Malformed UPDATE",
+			chunks: []*RecordChunk{
+				sentOpen,
+				recvOpen,
+				{Timestamp: ts, Direction: RecordReceived, Data: update},
+				{Timestamp: ts, Direction: RecordReceived, Data: malformedUpdate},
+			},
+			wantMessages: 3,
+			wantRoutes:   1,
+		},
+		{
+			name: "UPDATE before OPEN",
+			chunks: []*RecordChunk{
+				sentOpen,
+				{Timestamp: ts, Direction: RecordReceived, Data: update},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := ReplaySession(context.Background(), bytes.NewReader(testRecording(t, test.chunks)), SessionReplayOptions{})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.wantMessages, res.Messages, "Test %q", test.name)
+		assert.Equal(t, test.wantEstablished, res.Established, "Test %q", test.name)
+		assert.Equal(t, test.wantNotification, res.Notification != nil, "Test %q", test.name)
+		assert.Len(t, res.IPv4Routes, test.wantRoutes, "Test %q", test.name)
+		if !test.wantEstablished {
+			assert.NotEmpty(t, res.Reason, "Test %q", test.name)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+// SessionReplayOptions are the options of a session replay
+type SessionReplayOptions struct {
+	// Speed is the factor the time between received messages is scaled by (1 = real time), 0 replays as fast as possible
+	Speed float64
+
+	// MessageHandler is called for each message received in the recorded session before it is processed
+	MessageHandler func(ts time.Time, msg []byte)
+}
+
+// SessionReplayResult is the result of a session replay
+type SessionReplayResult struct {
+	// Messages is the number of messages received in the recorded session that have been processed
+	Messages uint64
+
+	// Established is true if the FSM is still established after processing all messages
+	Established bool
+
+	// Reason is the reason the FSM left the established state (e.g. a decode error)
+	Reason string
+
+	// Notification is the NOTIFICATION received in the recorded session, nil if none was received
+	Notification *packet.BGPNotification
+
+	// IPv4Routes and IPv6Routes are the contents of the adj-RIBs-in after the replay
+	IPv4Routes []*route.Route
+	IPv6Routes []*route.Route
+}
+
+// ReplaySession feeds the messages received in a session recording (see PeerConfig.RecordDirectory) through an FSM
+// and the decoder as if they were received from the peer. The FSM is configured by the OPEN messages exchanged in the
+// recorded session, so messages are decoded using the negotiated capabilities. Nothing is sent.
+// IPv4 and IPv6 unicast routes are kept in adj-RIBs-in, other address families are decoded only.
+func ReplaySession(ctx context.Context, r io.Reader, opt SessionReplayOptions) (*SessionReplayResult, error) {
+	if opt.Speed < 0 {
+		return nil, fmt.Errorf("Invalid speed %f", opt.Speed)
+	}
+
+	rr, err := NewRecordReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read session recording")
+	}
+
+	rp := &sessionReplay{
+		rr:  rr,
+		opt: opt,
+		res: &SessionReplayResult{},
+	}
+
+	err = rp.run(ctx)
+	if err != nil {
+		return rp.res, err
+	}
+
+	return rp.res, nil
+}
+
+type sessionReplay struct {
+	rr  *RecordReader
+	opt SessionReplayOptions
+	res *SessionReplayResult
+
+	sent     []byte
+	received []byte
+	sentOpen *packet.BGPOpen
+
+	// pending holds messages received before the OPEN message sent
+	pending [][]byte
+	fsm     *FSM
+
+	// first is the timestamp of the first message received, start the time it was replayed at
+	first time.Time
+	start time.Time
+}
+
+func (rp *sessionReplay) run(ctx context.Context) error {
+	defer rp.collectRoutes()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		c, err := rp.rr.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		done, err := rp.processChunk(ctx, c)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// processChunk processes all messages completed by a chunk. It returns true if the session ended.
+func (rp *sessionReplay) processChunk(ctx context.Context, c *RecordChunk) (bool, error) {
+	if c.Direction == RecordSent {
+		rp.sent = append(rp.sent, c.Data...)
+		for {
+			msg, err := nextMessage(&rp.sent)
+			if err != nil {
+				return false, errors.Wrap(err, "Invalid message sent")
+			}
+
+			if msg == nil {
+				break
+			}
+
+			err = rp.messageSent(msg)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		return rp.processPending(ctx, c.Timestamp)
+	}
+
+	rp.received = append(rp.received, c.Data...)
+	for {
+		msg, err := nextMessage(&rp.received)
+		if err != nil {
+			return false, errors.Wrap(err, "Invalid message received")
+		}
+
+		if msg == nil {
+			return false, nil
+		}
+
+		rp.pending = append(rp.pending, msg)
+		done, err := rp.processPending(ctx, c.Timestamp)
+		if err != nil || done {
+			return done, err
+		}
+	}
+}
+
+// nextMessage removes the next complete message from buf, nil if there is none
+func nextMessage(buf *[]byte) ([]byte, error) {
+	b := *buf
+	if len(b) < packet.MinLen {
+		return nil, nil
+	}
+
+	l := int(endian.Uint16(b[packet.MarkerLen : packet.MarkerLen+2]))
+	if l < packet.MinLen {
+		return nil, fmt.Errorf("Invalid message length %d", l)
+	}
+
+	if len(b) < l {
+		return nil, nil
+	}
+
+	msg := make([]byte, l)
+	copy(msg, b[:l])
+	*buf = b[l:]
+	return msg, nil
+}
+
+func (rp *sessionReplay) messageSent(msg []byte) error {
+	if rp.sentOpen != nil || msg[packet.MarkerLen+2] != packet.OpenMsg {
+		return nil
+	}
+
+	open, err := packet.DecodeOpenMsg(bytes.NewBuffer(msg[packet.HeaderLen:]))
+	if err != nil {
+		return errors.Wrap(err, "Unable to decode OPEN message sent")
+	}
+
+	rp.sentOpen = open
+	return nil
+}
+
+// processPending processes the messages received once the OPEN message sent is known
+func (rp *sessionReplay) processPending(ctx context.Context, ts time.Time) (bool, error) {
+	if rp.sentOpen == nil {
+		return false, nil
+	}
+
+	for len(rp.pending) > 0 {
+		msg := rp.pending[0]
+		rp.pending = rp.pending[1:]
+
+		err := rp.wait(ctx, ts)
+		if err != nil {
+			return false, err
+		}
+
+		if rp.opt.MessageHandler != nil {
+			rp.opt.MessageHandler(ts, msg)
+		}
+
+		rp.res.Messages++
+		done, err := rp.messageReceived(msg)
+		if err != nil || done {
+			return done, err
+		}
+	}
+
+	return false, nil
+}
+
+// wait delays the replay of a message according to its timestamp and the configured speed
+func (rp *sessionReplay) wait(ctx context.Context, ts time.Time) error {
+	if rp.opt.Speed == 0 {
+		return nil
+	}
+
+	if rp.start.IsZero() {
+		rp.first = ts
+		rp.start = time.Now()
+		return nil
+	}
+
+	d := time.Duration(float64(ts.Sub(rp.first))/rp.opt.Speed) - time.Since(rp.start)
+	if d <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (rp *sessionReplay) messageReceived(msg []byte) (bool, error) {
+	msgType := msg[packet.MarkerLen+2]
+	if rp.fsm == nil {
+		if msgType != packet.OpenMsg {
+			return false, fmt.Errorf("Received message of type %d before OPEN message", msgType)
+		}
+
+		open, err := packet.DecodeOpenMsg(bytes.NewBuffer(msg[packet.HeaderLen:]))
+		if err != nil {
+			rp.res.Reason = fmt.Sprintf("Failed to decode OPEN message: %v", err)
+			return true, nil
+		}
+
+		rp.fsm = newReplayFSM(rp.rr, rp.sentOpen, open)
+		rp.res.Established = true
+		return false, nil
+	}
+
+	// The session ends with a NOTIFICATION. It is not handed to the FSM to keep the routes learned.
+	if msgType == packet.NotificationMsg {
+		m, err := packet.Decode(bytes.NewBuffer(msg), rp.fsm.decodeOptions())
+		if err != nil {
+			rp.res.Reason = fmt.Sprintf("Failed to decode NOTIFICATION message: %v", err)
+		} else {
+			rp.res.Notification = m.Body.(*packet.BGPNotification)
+			rp.res.Reason = "Received NOTIFICATION"
+		}
+
+		rp.res.Established = false
+		return true, nil
+	}
+
+	s := rp.fsm.state.(*establishedState)
+	next, reason := s.msgReceived(msg, rp.fsm.decodeOptions())
+	if _, ok := next.(*establishedState); !ok {
+		rp.res.Established = false
+		rp.res.Reason = reason
+		return true, nil
+	}
+
+	rp.fsm.state = next
+	return false, nil
+}
+
+func (rp *sessionReplay) collectRoutes() {
+	if rp.fsm == nil {
+		return
+	}
+
+	if f := rp.fsm.ipv4Unicast; f != nil && f.adjRIBIn != nil {
+		rp.res.IPv4Routes = f.adjRIBIn.Dump()
+	}
+
+	if f := rp.fsm.ipv6Unicast; f != nil && f.adjRIBIn != nil {
+		rp.res.IPv6Routes = f.adjRIBIn.Dump()
+	}
+}
+
+// newReplayFSM creates an established FSM without a server as configured by the OPEN messages of a recorded session
+func newReplayFSM(rr *RecordReader, sentOpen *packet.BGPOpen, recvOpen *packet.BGPOpen) *FSM {
+	p := &peer{
+		routerID:  sentOpen.BGPIdentifier,
+		addr:      recordAddr(rr.RemoteAddr),
+		localAddr: recordAddr(rr.LocalAddr),
+		peerASN:   openASN(recvOpen),
+		localASN:  openASN(sentOpen),
+		ipv4: &peerAddressFamily{
+			rib:               locRIB.New("inet.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+		ipv6: &peerAddressFamily{
+			rib:               locRIB.New("inet6.0"),
+			importFilterChain: filter.NewAcceptAllFilterChain(),
+			exportFilterChain: filter.NewAcceptAllFilterChain(),
+		},
+	}
+	p.configureBySentOpen(sentOpen)
+
+	fsm := newFSM(p)
+	fsm.isBMP = true
+	fsm.con = fakeConn{}
+	p.fsms = []*FSM{fsm}
+
+	fsm.state = newOpenSentState(fsm)
+	fsm.state.(*openSentState).openMsgReceived(recvOpen)
+	for _, f := range []*fsmAddressFamily{fsm.ipv4Unicast, fsm.ipv6Unicast} {
+		f.bmpInit()
+	}
+
+	fsm.state = newEstablishedState(fsm)
+	return fsm
+}
+
+// openASN gets the ASN of the speaker sending an OPEN message
+func openASN(open *packet.BGPOpen) uint32 {
+	for _, caps := range getCaps(open.OptParams) {
+		for _, c := range caps {
+			if c.Code == packet.ASN4CapabilityCode {
+				return c.Value.(packet.ASN4Capability).ASN4
+			}
+		}
+	}
+
+	return uint32(open.ASN)
+}
+
+// recordAddr gets the IP address of an address of a recording, the unspecified address if it can not be parsed
+func recordAddr(s string) *bnet.IP {
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		host = s
+	}
+
+	addr, err := bnet.IPFromString(host)
+	if err != nil {
+		return bnet.IPv4(0).Ptr()
+	}
+
+	return addr.Dedup()
+}
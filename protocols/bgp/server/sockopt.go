@@ -17,6 +17,17 @@ func setTTL(c net.Conn, ttl uint8) error {
 	}
 }
 
+func setMinTTL(c net.Conn, ttl uint8) error {
+	// as c is an interface for testability reason we're checking here if the concrete type
+	// is a real TCP connection as only that supports setting a minimum TTL
+	switch c.(type) {
+	case *tcp.Conn:
+		return c.(*tcp.Conn).SetMinTTL(ttl)
+	default:
+		return nil
+	}
+}
+
 func setDontRoute(c net.Conn) error {
 	// as c is an interface for testability reason we're checking here if the concrete type
 	// is a real TCP connection as only that supports setting SetDontRoute()
@@ -0,0 +1,390 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/sirupsen/logrus"
+)
+
+// srPolicyDefaultPreference is the preference of candidate paths without preference sub-TLV (RFC9256 2.7)
+const srPolicyDefaultPreference = 100
+
+// SRPolicyCandidatePath is a candidate path of an SR Policy received from a peer (RFC9830). The originator of the
+// candidate path is the peer identified by its ASN and BGP identifier (RFC9256 2.4).
+type SRPolicyCandidatePath struct {
+	Peer          *bnet.IP
+	OriginatorASN uint32
+	OriginatorID  uint32
+	NLRI          *packet.SRPolicyNLRI
+	NextHop       *bnet.IP
+	Policy        *packet.SRPolicy
+}
+
+// Preference gets the preference of the candidate path
+func (c *SRPolicyCandidatePath) Preference() uint32 {
+	if c.Policy.Preference == nil {
+		return srPolicyDefaultPreference
+	}
+
+	return *c.Policy.Preference
+}
+
+// Valid checks if the candidate path has a usable segment list (RFC9256 5)
+func (c *SRPolicyCandidatePath) Valid() bool {
+	for _, l := range c.Policy.SegmentLists {
+		if len(l.Segments) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// better checks if c is preferred over x (RFC9256 2.9)
+func (c *SRPolicyCandidatePath) better(x *SRPolicyCandidatePath) bool {
+	if c.Valid() != x.Valid() {
+		return c.Valid()
+	}
+
+	if c.Preference() != x.Preference() {
+		return c.Preference() > x.Preference()
+	}
+
+	if c.OriginatorASN != x.OriginatorASN {
+		return c.OriginatorASN < x.OriginatorASN
+	}
+
+	if c.OriginatorID != x.OriginatorID {
+		return c.OriginatorID < x.OriginatorID
+	}
+
+	if c.NLRI.Distinguisher != x.NLRI.Distinguisher {
+		return c.NLRI.Distinguisher > x.NLRI.Distinguisher
+	}
+
+	return c.Peer.Compare(x.Peer) < 0
+}
+
+// SRPolicy is a segment routing policy identified by color and endpoint (RFC9256 2.1)
+type SRPolicy struct {
+	Color    uint32
+	Endpoint *bnet.IP
+
+	// CandidatePaths holds all candidate paths received for the policy, the preferred one first
+	CandidatePaths []*SRPolicyCandidatePath
+}
+
+// Active gets the candidate path instantiated for the policy, nil if no candidate path is valid
+func (p *SRPolicy) Active() *SRPolicyCandidatePath {
+	if len(p.CandidatePaths) == 0 || !p.CandidatePaths[0].Valid() {
+		return nil
+	}
+
+	return p.CandidatePaths[0]
+}
+
+// SRPolicyClient is notified about the SR Policies received from all peers
+type SRPolicyClient interface {
+	// UpdateSRPolicies is called with all policies ordered by color and endpoint whenever a candidate path changes
+	UpdateSRPolicies(policies []*SRPolicy)
+}
+
+// RegisterSRPolicyClient registers a client for SR Policies. The client is updated with the current policies.
+func (b *bgpServer) RegisterSRPolicyClient(c SRPolicyClient) {
+	b.srPolicies.register(c)
+}
+
+// UnregisterSRPolicyClient unregisters a client for SR Policies
+func (b *bgpServer) UnregisterSRPolicyClient(c SRPolicyClient) {
+	b.srPolicies.unregister(c)
+}
+
+// GetSRPolicies gets the SR Policies received from all peers ordered by color and endpoint
+func (b *bgpServer) GetSRPolicies() []*SRPolicy {
+	b.srPolicies.mu.Lock()
+	defer b.srPolicies.mu.Unlock()
+
+	return b.srPolicies.policies()
+}
+
+// srPolicyTable holds the SR Policy candidate paths received from all peers
+type srPolicyTable struct {
+	mu      sync.Mutex
+	peers   map[bnet.IP]map[string]*SRPolicyCandidatePath
+	clients map[SRPolicyClient]struct{}
+}
+
+func newSRPolicyTable() *srPolicyTable {
+	return &srPolicyTable{
+		peers:   make(map[bnet.IP]map[string]*SRPolicyCandidatePath),
+		clients: make(map[SRPolicyClient]struct{}),
+	}
+}
+
+func (t *srPolicyTable) register(c SRPolicyClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clients[c] = struct{}{}
+	c.UpdateSRPolicies(t.policies())
+}
+
+func (t *srPolicyTable) unregister(c SRPolicyClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.clients, c)
+}
+
+// update adds and removes candidate paths of a peer and notifies the clients if anything changed
+func (t *srPolicyTable) update(peer bnet.IP, add []*SRPolicyCandidatePath, remove []*packet.SRPolicyNLRI) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	paths := t.peers[peer]
+	if paths == nil {
+		paths = make(map[string]*SRPolicyCandidatePath)
+	}
+
+	changed := false
+	for _, n := range remove {
+		key := n.Key()
+		if _, ok := paths[key]; ok {
+			delete(paths, key)
+			changed = true
+		}
+	}
+
+	for _, c := range add {
+		paths[c.NLRI.Key()] = c
+		changed = true
+	}
+
+	if len(paths) == 0 {
+		delete(t.peers, peer)
+	} else {
+		t.peers[peer] = paths
+	}
+
+	if changed {
+		t.notify()
+	}
+}
+
+// removePeer removes all candidate paths of a peer
+func (t *srPolicyTable) removePeer(peer bnet.IP) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.peers[peer]; !ok {
+		return
+	}
+
+	delete(t.peers, peer)
+	t.notify()
+}
+
+func (t *srPolicyTable) notify() {
+	policies := t.policies()
+	for c := range t.clients {
+		c.UpdateSRPolicies(policies)
+	}
+}
+
+type srPolicyKey struct {
+	color    uint32
+	endpoint bnet.IP
+}
+
+// policies groups the candidate paths of all peers by policy
+func (t *srPolicyTable) policies() []*SRPolicy {
+	byKey := make(map[srPolicyKey]*SRPolicy)
+	for _, paths := range t.peers {
+		for _, c := range paths {
+			k := srPolicyKey{
+				color:    c.NLRI.Color,
+				endpoint: *c.NLRI.Endpoint,
+			}
+
+			p := byKey[k]
+			if p == nil {
+				p = &SRPolicy{
+					Color:    c.NLRI.Color,
+					Endpoint: c.NLRI.Endpoint,
+				}
+				byKey[k] = p
+			}
+
+			p.CandidatePaths = append(p.CandidatePaths, c)
+		}
+	}
+
+	ret := make([]*SRPolicy, 0, len(byKey))
+	for _, p := range byKey {
+		sort.Slice(p.CandidatePaths, func(i, j int) bool {
+			return p.CandidatePaths[i].better(p.CandidatePaths[j])
+		})
+
+		ret = append(ret, p)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Color != ret[j].Color {
+			return ret[i].Color < ret[j].Color
+		}
+
+		return ret[i].Endpoint.Compare(ret[j].Endpoint) < 0
+	})
+
+	return ret
+}
+
+// srPolicyAddressFamily receives SR Policy candidate paths from a peer, e.g. a controller. SR Policies are not
+// advertised to peers.
+type srPolicyAddressFamily struct {
+	fsm  *FSM
+	afis []uint16
+
+	// negotiated holds the AFIs the peer advertised the multi protocol capability for SR Policies for
+	negotiated  map[uint16]struct{}
+	initialized bool
+}
+
+func newSRPolicyAddressFamily(fsm *FSM, afis []uint16) *srPolicyAddressFamily {
+	return &srPolicyAddressFamily{
+		fsm:        fsm,
+		afis:       afis,
+		negotiated: make(map[uint16]struct{}),
+	}
+}
+
+func (f *srPolicyAddressFamily) negotiate(afi uint16) {
+	for _, x := range f.afis {
+		if x == afi {
+			f.negotiated[afi] = struct{}{}
+		}
+	}
+}
+
+func (f *srPolicyAddressFamily) init() {
+	f.initialized = true
+
+	opts := &packet.EncodeOptions{
+		Use32BitASN: f.fsm.supports4OctetASN,
+	}
+
+	for afi := range f.negotiated {
+		err := serializeAndSendUpdate(f.fsm.con, packet.EndOfRIB(afi, packet.SRPolicySAFI), opts)
+		if err != nil {
+			log.WithField("peer", f.fsm.peer.addr.String()).WithError(err).Error("Unable to send SR Policy End-of-RIB")
+			continue
+		}
+
+		atomic.AddUint64(&f.fsm.counters.updatesSent, 1)
+	}
+}
+
+func (f *srPolicyAddressFamily) dispose() {
+	if f.initialized && f.fsm.peer.server != nil {
+		f.fsm.peer.server.srPolicies.removePeer(*f.fsm.peer.addr)
+	}
+
+	f.negotiated = make(map[uint16]struct{})
+	f.initialized = false
+}
+
+// processUpdate processes the SR Policy candidate paths of an update. Candidate paths not targeted at this speaker
+// are treated as withdrawn (RFC9830 4.2.1).
+func (f *srPolicyAddressFamily) processUpdate(u *packet.BGPUpdate) {
+	var policy *packet.SRPolicy
+	targeted := false
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.TunnelEncapAttr:
+			for _, tlv := range pa.Value.(packet.TunnelEncapsulation) {
+				if tlv.SRPolicy != nil {
+					policy = tlv.SRPolicy
+					break
+				}
+			}
+		case packet.CommunitiesAttr:
+			for _, c := range *pa.Value.(*types.Communities) {
+				if c == types.WellKnownCommunityNoAdvertise {
+					targeted = true
+				}
+			}
+		case packet.ExtendedCommunitiesAttr:
+			for _, c := range *pa.Value.(*types.ExtendedCommunities) {
+				if srPolicyRouteTarget(c, f.fsm.peer.routerID) {
+					targeted = true
+				}
+			}
+		}
+	}
+
+	add := make([]*SRPolicyCandidatePath, 0)
+	remove := make([]*packet.SRPolicyNLRI, 0)
+	for pa := u.PathAttributes; pa != nil; pa = pa.Next {
+		switch pa.TypeCode {
+		case packet.MultiProtocolReachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolReachNLRI)
+			if mp.SAFI != packet.SRPolicySAFI || !f.isNegotiated(mp.AFI) {
+				continue
+			}
+
+			if policy == nil || !targeted {
+				remove = append(remove, mp.SRPolicies...)
+				continue
+			}
+
+			for _, n := range mp.SRPolicies {
+				add = append(add, &SRPolicyCandidatePath{
+					Peer:          f.fsm.peer.addr,
+					OriginatorASN: f.fsm.peer.peerASN,
+					OriginatorID:  f.fsm.neighborID,
+					NLRI:          n,
+					NextHop:       mp.NextHop,
+					Policy:        policy,
+				})
+			}
+		case packet.MultiProtocolUnreachNLRICode:
+			mp := pa.Value.(packet.MultiProtocolUnreachNLRI)
+			if mp.SAFI != packet.SRPolicySAFI || !f.isNegotiated(mp.AFI) {
+				continue
+			}
+
+			remove = append(remove, mp.SRPolicies...)
+		}
+	}
+
+	if len(add) == 0 && len(remove) == 0 {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"peer":      f.fsm.peer.addr.String(),
+		"added":     len(add),
+		"withdrawn": len(remove),
+	}).Debug("Received SR Policy candidate paths")
+
+	if f.fsm.peer.server != nil {
+		f.fsm.peer.server.srPolicies.update(*f.fsm.peer.addr, add, remove)
+	}
+}
+
+func (f *srPolicyAddressFamily) isNegotiated(afi uint16) bool {
+	_, ok := f.negotiated[afi]
+	return ok
+}
+
+// srPolicyRouteTarget checks if c is an IPv4 address specific route target carrying routerID (RFC9830 4.2.1)
+func srPolicyRouteTarget(c types.ExtendedCommunity, routerID uint32) bool {
+	return c.Type() == types.ExtendedCommunityTypeIPv4Address &&
+		c.SubType() == types.ExtendedCommunitySubTypeRouteTarget &&
+		uint32(c>>16) == routerID
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type srPolicyClientMock struct {
+	policies []*SRPolicy
+}
+
+func (c *srPolicyClientMock) UpdateSRPolicies(policies []*SRPolicy) {
+	c.policies = policies
+}
+
+func srPolicyTestNLRI(distinguisher uint32, color uint32) *packet.SRPolicyNLRI {
+	return &packet.SRPolicyNLRI{
+		Distinguisher: distinguisher,
+		Color:         color,
+		Endpoint:      bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+	}
+}
+
+func srPolicyTestPolicy(pref uint32, labels ...uint32) *packet.SRPolicy {
+	l := &packet.SRSegmentList{}
+	for _, label := range labels {
+		l.Segments = append(l.Segments, &packet.SRSegment{
+			Type:  packet.SRSegmentTypeA,
+			Label: label,
+		})
+	}
+
+	return &packet.SRPolicy{
+		Preference:   &pref,
+		SegmentLists: []*packet.SRSegmentList{l},
+	}
+}
+
+func srPolicyTestUpdate(reach bool, coms types.ExtendedCommunities, policy *packet.SRPolicy, nlris ...*packet.SRPolicyNLRI) *packet.BGPUpdate {
+	if !reach {
+		return &packet.BGPUpdate{
+			PathAttributes: &packet.PathAttribute{
+				TypeCode: packet.MultiProtocolUnreachNLRICode,
+				Value: packet.MultiProtocolUnreachNLRI{
+					AFI:        packet.IPv4AFI,
+					SAFI:       packet.SRPolicySAFI,
+					SRPolicies: nlris,
+				},
+			},
+		}
+	}
+
+	return &packet.BGPUpdate{
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:        packet.IPv4AFI,
+				SAFI:       packet.SRPolicySAFI,
+				NextHop:    bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+				SRPolicies: nlris,
+			},
+			Next: &packet.PathAttribute{
+				TypeCode: packet.ExtendedCommunitiesAttr,
+				Value:    &coms,
+				Next: &packet.PathAttribute{
+					TypeCode: packet.TunnelEncapAttr,
+					Value: packet.TunnelEncapsulation{
+						{
+							Type:     packet.TunnelTypeSRPolicy,
+							SRPolicy: policy,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSRPolicyAddressFamily(t *testing.T) {
+	// Route target 10.0.0.1:0 carrying the router ID
+	target := types.ExtendedCommunities{0x01020a0000010000}
+	other := types.ExtendedCommunities{0x01020a0000020000}
+
+	tests := []struct {
+		name           string
+		updates        []*packet.BGPUpdate
+		dispose        bool
+		expectedColors []uint32
+		expectedActive []uint32
+	}{
+		{
+			name: "Preferred candidate path active",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 10)),
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(200, 16002), srPolicyTestNLRI(2, 10)),
+			},
+			expectedColors: []uint32{10},
+			expectedActive: []uint32{2},
+		},
+		{
+			name: "Invalid candidate path not active",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 10)),
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(200), srPolicyTestNLRI(2, 10)),
+			},
+			expectedColors: []uint32{10},
+			expectedActive: []uint32{1},
+		},
+		{
+			name: "Candidate path withdrawn",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 10)),
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(200, 16002), srPolicyTestNLRI(2, 10)),
+				srPolicyTestUpdate(false, nil, nil, srPolicyTestNLRI(2, 10)),
+			},
+			expectedColors: []uint32{10},
+			expectedActive: []uint32{1},
+		},
+		{
+			name: "Policies ordered by color",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 20), srPolicyTestNLRI(1, 10)),
+			},
+			expectedColors: []uint32{10, 20},
+			expectedActive: []uint32{1, 1},
+		},
+		{
+			name: "Not targeted at this speaker",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, other, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 10)),
+			},
+		},
+		{
+			name: "Session down",
+			updates: []*packet.BGPUpdate{
+				srPolicyTestUpdate(true, target, srPolicyTestPolicy(100, 16001), srPolicyTestNLRI(1, 10)),
+			},
+			dispose: true,
+		},
+	}
+
+	for _, test := range tests {
+		s := newBGPServer(0, nil)
+		c := &srPolicyClientMock{}
+		s.RegisterSRPolicyClient(c)
+
+		p := &peer{
+			server:   s,
+			addr:     bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+			routerID: 0x0a000001,
+			peerASN:  65001,
+			config: &PeerConfig{
+				IPv4SRPolicy: true,
+			},
+		}
+		fsm := newFSM(p)
+		fsm.srPolicy.negotiate(packet.IPv4AFI)
+		fsm.srPolicy.initialized = true
+
+		for _, u := range test.updates {
+			fsm.srPolicy.processUpdate(u)
+		}
+
+		if test.dispose {
+			fsm.srPolicy.dispose()
+		}
+
+		var colors, active []uint32
+		for _, p := range c.policies {
+			colors = append(colors, p.Color)
+			if a := p.Active(); a != nil {
+				active = append(active, a.NLRI.Distinguisher)
+			}
+		}
+
+		assert.Equal(t, test.expectedColors, colors, "Test %q", test.name)
+		assert.Equal(t, test.expectedActive, active, "Test %q", test.name)
+		assert.Equal(t, c.policies, s.GetSRPolicies(), "Test %q", test.name)
+	}
+}
+
+func TestSRPolicyCandidatePathSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        *SRPolicyCandidatePath
+		b        *SRPolicyCandidatePath
+		expected bool
+	}{
+		{
+			name:     "Default preference",
+			a:        &SRPolicyCandidatePath{NLRI: srPolicyTestNLRI(1, 10), Policy: &packet.SRPolicy{SegmentLists: srPolicyTestPolicy(0, 1).SegmentLists}},
+			b:        &SRPolicyCandidatePath{NLRI: srPolicyTestNLRI(1, 10), Policy: srPolicyTestPolicy(99, 1)},
+			expected: true,
+		},
+		{
+			name:     "Lower originator ASN",
+			a:        &SRPolicyCandidatePath{OriginatorASN: 65001, NLRI: srPolicyTestNLRI(1, 10), Policy: srPolicyTestPolicy(100, 1)},
+			b:        &SRPolicyCandidatePath{OriginatorASN: 65000, NLRI: srPolicyTestNLRI(1, 10), Policy: srPolicyTestPolicy(100, 1)},
+			expected: false,
+		},
+		{
+			name:     "Higher distinguisher",
+			a:        &SRPolicyCandidatePath{NLRI: srPolicyTestNLRI(2, 10), Policy: srPolicyTestPolicy(100, 1)},
+			b:        &SRPolicyCandidatePath{NLRI: srPolicyTestNLRI(1, 10), Policy: srPolicyTestPolicy(100, 1)},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.a.better(test.b), "Test %q", test.name)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"net"
 
 	"github.com/bio-routing/bio-rd/net/tcp"
-	log "github.com/sirupsen/logrus"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -20,6 +21,18 @@ type TCPListener struct {
 
 // NewTCPListener creates a new TCPListener
 func NewTCPListener(addr string, ch chan net.Conn) (*TCPListener, error) {
+	tl, err := newTCPListener(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	go tl.serve(ch)
+
+	return tl, nil
+}
+
+// newTCPListener creates a TCPListener not accepting connections yet
+func newTCPListener(addr string) (*TCPListener, error) {
 	tcpaddr, err := net.ResolveTCPAddr("tcp", addr)
 	if err != nil {
 		return nil, err
@@ -30,29 +43,39 @@ func NewTCPListener(addr string, ch chan net.Conn) (*TCPListener, error) {
 		return nil, err
 	}
 
-	tl := &TCPListener{
+	return &TCPListener{
 		l:       l,
 		closeCh: make(chan struct{}),
-	}
+	}, nil
+}
 
-	go func(tl *TCPListener) error {
-		for {
-			conn, err := tl.l.AcceptTCP()
-			if err != nil {
-				close(tl.closeCh)
-				log.WithFields(log.Fields{
-					"Topic": "Peer",
-					"Error": err,
-				}).Warn("Failed to AcceptTCP")
-				return err
-			}
-			ch <- conn
-		}
-	}(tl)
+// serve accepts connections and passes them to ch until the listener is closed
+func (t *TCPListener) serve(ch chan net.Conn) error {
+	defer crashdump.Recover()
 
-	return tl, nil
+	for {
+		conn, err := t.l.AcceptTCP()
+		if err != nil {
+			close(t.closeCh)
+			log.WithFields(logrus.Fields{
+				"Topic": "Peer",
+				"Error": err,
+			}).Warn("Failed to AcceptTCP")
+			return err
+		}
+		ch <- conn
+	}
 }
 
 func (t *TCPListener) setTCPMD5(addr net.IP, secret string) error {
 	return t.l.SetTCPMD5(addr, secret)
 }
+
+func (t *TCPListener) removeTCPMD5(addr net.IP) error {
+	return t.l.RemoveTCPMD5(addr)
+}
+
+// Close stops the listener
+func (t *TCPListener) Close() error {
+	return t.l.Close()
+}
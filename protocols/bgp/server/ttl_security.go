@@ -0,0 +1,29 @@
+package server
+
+const maxTTL = 255
+
+// outgoingTTL gets the TTL of packets sent to the peer and if they must not be routed (directly connected eBGP peers)
+func (p *peer) outgoingTTL() (ttl uint8, dontRoute bool) {
+	if p.ttlSecurityHops != 0 {
+		return maxTTL, false
+	}
+
+	if p.ttl != 0 {
+		return p.ttl, false
+	}
+
+	if p.isEBGP() {
+		return 1, true
+	}
+
+	return 0, false
+}
+
+// minTTL gets the lowest TTL of packets accepted from the peer with GTSM enabled (RFC5082 3), 0 otherwise
+func (p *peer) minTTL() uint8 {
+	if p.ttlSecurityHops == 0 {
+		return 0
+	}
+
+	return uint8(maxTTL + 1 - uint16(p.ttlSecurityHops))
+}
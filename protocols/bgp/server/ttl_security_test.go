@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerTTL(t *testing.T) {
+	tests := []struct {
+		name              string
+		peer              *peer
+		expectedTTL       uint8
+		expectedDontRoute bool
+		expectedMinTTL    uint8
+	}{
+		{
+			name: "Directly connected eBGP peer",
+			peer: &peer{
+				localASN: 65000,
+				peerASN:  65001,
+			},
+			expectedTTL:       1,
+			expectedDontRoute: true,
+		},
+		{
+			name: "iBGP peer",
+			peer: &peer{
+				localASN: 65000,
+				peerASN:  65000,
+			},
+		},
+		{
+			name: "eBGP multihop",
+			peer: &peer{
+				localASN: 65000,
+				peerASN:  65001,
+				ttl:      3,
+			},
+			expectedTTL: 3,
+		},
+		{
+			name: "GTSM for a directly connected peer",
+			peer: &peer{
+				localASN:        65000,
+				peerASN:         65001,
+				ttlSecurityHops: 1,
+			},
+			expectedTTL:    255,
+			expectedMinTTL: 255,
+		},
+		{
+			name: "GTSM for a peer two hops away",
+			peer: &peer{
+				localASN:        65000,
+				peerASN:         65001,
+				ttl:             10,
+				ttlSecurityHops: 2,
+			},
+			expectedTTL:    255,
+			expectedMinTTL: 254,
+		},
+	}
+
+	for _, test := range tests {
+		ttl, dontRoute := test.peer.outgoingTTL()
+		assert.Equal(t, test.expectedTTL, ttl, "Test %q", test.name)
+		assert.Equal(t, test.expectedDontRoute, dontRoute, "Test %q", test.name)
+		assert.Equal(t, test.expectedMinTTL, test.peer.minTTL(), "Test %q", test.name)
+	}
+}
@@ -1,22 +1,25 @@
 package server
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/util/bufpool"
 	"github.com/pkg/errors"
-
-	log "github.com/sirupsen/logrus"
 )
 
 func serializeAndSendUpdate(out io.Writer, update serializeAbleUpdate, opt *packet.EncodeOptions) error {
-	updateBytes, err := update.SerializeUpdate(opt)
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+
+	err := update.SerializeUpdateTo(buf, opt)
 	if err != nil {
 		log.Errorf("Unable to serialize BGP Update: %v", err)
 		return nil
 	}
 
-	_, err = out.Write(updateBytes)
+	_, err = out.Write(buf.Bytes())
 	if err != nil {
 		return errors.Wrap(err, "Failed sending Update")
 	}
@@ -24,5 +27,5 @@ func serializeAndSendUpdate(out io.Writer, update serializeAbleUpdate, opt *pack
 }
 
 type serializeAbleUpdate interface {
-	SerializeUpdate(opt *packet.EncodeOptions) ([]byte, error)
+	SerializeUpdateTo(buf *bytes.Buffer, opt *packet.EncodeOptions) error
 }
@@ -13,8 +13,8 @@ import (
 
 type failingUpdate struct{}
 
-func (f *failingUpdate) SerializeUpdate(opt *packet.EncodeOptions) ([]byte, error) {
-	return nil, errors.New("general error")
+func (f *failingUpdate) SerializeUpdateTo(buf *bytes.Buffer, opt *packet.EncodeOptions) error {
+	return errors.New("general error")
 }
 
 type WriterByter interface {
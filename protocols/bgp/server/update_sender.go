@@ -14,7 +14,8 @@ import (
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
-	log "github.com/sirupsen/logrus"
+	"github.com/bio-routing/bio-rd/util/bufpool"
+	"github.com/bio-routing/bio-rd/util/crashdump"
 )
 
 // UpdateSender converts table changes into BGP update messages
@@ -25,15 +26,39 @@ type UpdateSender struct {
 	options       *packet.EncodeOptions
 	iBGP          bool
 	rrClient      bool
+	attrCache     *attributeCache
+	serializer    *updateSerializer
 	toSendMu      sync.Mutex
 	toSend        map[string]*pathPfxs
+	queued        map[nlriKey]string
+	toWithdraw    map[nlriKey]struct{}
+	withdrawOrder []nlriKey
+	endOfRIB      bool
+	endOfRefresh  bool
 	destroyCh     chan struct{}
 	wg            sync.WaitGroup
+
+	// advertisementInterval is the MRAI. Advertisements are held until it expired since lastAdvertisement.
+	advertisementInterval time.Duration
+	lastAdvertisement     time.Time
 }
 
 type pathPfxs struct {
-	path *route.Path
-	pfxs []*bnet.Prefix
+	path   *route.Path
+	pfxs   []*bnet.Prefix
+	queued time.Time
+}
+
+// pendingUpdates are the updates of a toSend entry being serialized
+type pendingUpdates struct {
+	jobs   []*serializeJob
+	queued time.Time
+}
+
+// nlriKey identifies a prefix advertised or withdrawn with a path identifier
+type nlriKey struct {
+	pfx    bnet.Prefix
+	pathID uint32
 }
 
 func newUpdateSender(f *fsmAddressFamily) *UpdateSender {
@@ -44,6 +69,8 @@ func newUpdateSender(f *fsmAddressFamily) *UpdateSender {
 		rrClient:      f.fsm.peer.routeReflectorClient,
 		destroyCh:     make(chan struct{}),
 		toSend:        make(map[string]*pathPfxs),
+		queued:        make(map[nlriKey]string),
+		toWithdraw:    make(map[nlriKey]struct{}),
 		options: &packet.EncodeOptions{
 			Use32BitASN: f.fsm.supports4OctetASN,
 			UseAddPath:  !f.addPathTX.BestOnly,
@@ -51,6 +78,18 @@ func newUpdateSender(f *fsmAddressFamily) *UpdateSender {
 	}
 	u.clientManager = routingtable.NewClientManager(u)
 
+	if !(u.iBGP && f.noAdvertisementIntervalIBGP) {
+		u.advertisementInterval = f.advertisementInterval
+	}
+
+	if f.updateGroup != nil {
+		u.attrCache = f.updateGroup.attrs
+	}
+
+	if f.fsm.peer.server != nil {
+		u.serializer = f.fsm.peer.server.serializer
+	}
+
 	return u
 }
 
@@ -74,22 +113,44 @@ func (u *UpdateSender) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error
 	return u.AddPath(pfx, p)
 }
 
-// AddPath adds path p for pfx to toSend queue
+// AddPath adds path p for pfx to toSend queue. Prefixes of paths with identical attributes are sent in the same updates.
+// A queued withdrawal of pfx is replaced. Paths with a next hop the peer can not decode are not advertised, pfx is
+// withdrawn instead as the path replaces the one advertised before.
 func (u *UpdateSender) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	k := nlriKey{
+		pfx:    *pfx,
+		pathID: p.BGPPath.PathIdentifier,
+	}
+
 	u.toSendMu.Lock()
 
+	if !u.nextHopEncodable(p) {
+		log.WithField("peer", u.fsm.peer.addr.String()).Debugf("Not advertising path with next hop %s: extended next hop encoding not negotiated", p.NextHop().String())
+		u.queueWithdrawal(k)
+		u.toSendMu.Unlock()
+		return nil
+	}
+
 	hash := p.BGPPath.ComputeHashWithPathID()
+	delete(u.toWithdraw, k)
+	if u.queued[k] == hash {
+		u.toSendMu.Unlock()
+		return nil
+	}
+	u.queued[k] = hash
+
 	if _, exists := u.toSend[hash]; exists {
 		u.toSend[hash].pfxs = append(u.toSend[hash].pfxs, pfx)
 		u.toSendMu.Unlock()
 		return nil
 	}
 
-	u.toSend[p.BGPPath.ComputeHashWithPathID()] = &pathPfxs{
+	u.toSend[hash] = &pathPfxs{
 		path: p,
 		pfxs: []*bnet.Prefix{
 			pfx,
 		},
+		queued: time.Now(),
 	}
 
 	u.toSendMu.Unlock()
@@ -103,10 +164,13 @@ func (u *UpdateSender) Dump() []*route.Route {
 
 // sender serializes BGP update messages
 func (u *UpdateSender) sender(aggrTime time.Duration) {
+	defer crashdump.Recover()
+
 	ticker := time.NewTicker(aggrTime)
 	var err error
-	var pathAttrs *packet.PathAttribute
+	var attrs *serializedAttributes
 	var budget int
+	pending := make([]pendingUpdates, 0, maxPendingUpdates)
 
 	for {
 		select {
@@ -117,10 +181,26 @@ func (u *UpdateSender) sender(aggrTime time.Duration) {
 		}
 
 		u.toSendMu.Lock()
+		withdrawals := u.dequeueWithdrawals()
+		u.toSendMu.Unlock()
+
+		u.sendWithdrawals(withdrawals)
+
+		u.toSendMu.Lock()
+		if !u.advertisementDue(time.Now()) {
+			u.toSendMu.Unlock()
+			continue
+		}
+
 		for key, pathNLRIs := range u.toSend {
 			budget = u.getBudget(pathNLRIs)
 
-			pathAttrs, err = packet.PathAttributes(pathNLRIs.path, u.iBGP, u.rrClient)
+			path := pathNLRIs.path
+			if packet.IsLabeledSAFI(u.addressFamily.safi) {
+				path = u.labeledPath(path)
+			}
+
+			attrs, err = u.serializedAttributes(path)
 			if err != nil {
 				log.Errorf("Unable to get path attributes: %v", err)
 				continue
@@ -128,13 +208,17 @@ func (u *UpdateSender) sender(aggrTime time.Duration) {
 
 			updatesPrefixes := make([][]*bnet.Prefix, 0, 1)
 			prefixes := make([]*bnet.Prefix, 0, 1)
-			for _, pfx := range pathNLRIs.pfxs {
+			for _, pfx := range u.dequeuePrefixes(key, pathNLRIs) {
 				budget -= int(packet.BytesInAddr(pfx.Pfxlen())) + 1
 
 				if u.options.UseAddPath {
 					budget -= packet.PathIdentifierLen
 				}
 
+				if packet.IsLabeledSAFI(u.addressFamily.safi) {
+					budget -= packet.LabelLen
+				}
+
 				if budget < 0 {
 					updatesPrefixes = append(updatesPrefixes, prefixes)
 					prefixes = make([]*bnet.Prefix, 0, 1)
@@ -150,10 +234,153 @@ func (u *UpdateSender) sender(aggrTime time.Duration) {
 			delete(u.toSend, key)
 			u.toSendMu.Unlock()
 
-			u.sendUpdates(pathAttrs, updatesPrefixes, pathNLRIs.path.BGPPath.PathIdentifier)
+			pending = append(pending, u.serializeUpdates(attrs, updatesPrefixes, path, pathNLRIs.queued))
+			if len(pending) == maxPendingUpdates {
+				u.writeUpdates(pending)
+				pending = pending[:0]
+			}
 			u.toSendMu.Lock()
 		}
 		u.toSendMu.Unlock()
+
+		u.writeUpdates(pending)
+		pending = pending[:0]
+
+		u.toSendMu.Lock()
+
+		done := len(u.toSend) == 0 && len(u.toWithdraw) == 0
+		sendEndOfRIB := u.endOfRIB && done
+		if sendEndOfRIB {
+			u.endOfRIB = false
+		}
+
+		sendEndOfRefresh := u.endOfRefresh && done
+		if sendEndOfRefresh {
+			u.endOfRefresh = false
+		}
+		u.toSendMu.Unlock()
+
+		if sendEndOfRIB {
+			u.sendEndOfRIBMarker()
+		}
+
+		if sendEndOfRefresh {
+			u.sendRouteRefreshMarker(packet.EndOfRouteRefresh)
+		}
+	}
+}
+
+// advertisementDue checks if queued advertisements may be sent at t. Since the advertisement interval is then
+// restarted, all changes queued until it expires are sent together. toSendMu must be held.
+func (u *UpdateSender) advertisementDue(t time.Time) bool {
+	if u.advertisementInterval == 0 || len(u.toSend) == 0 {
+		return true
+	}
+
+	if t.Sub(u.lastAdvertisement) < u.advertisementInterval {
+		return false
+	}
+
+	u.lastAdvertisement = t
+	return true
+}
+
+// dequeuePrefixes gets the prefixes queued for the toSend entry key. Prefixes advertised again with another path
+// or withdrawn since they were queued are skipped.
+func (u *UpdateSender) dequeuePrefixes(key string, pathNLRIs *pathPfxs) []*bnet.Prefix {
+	ret := make([]*bnet.Prefix, 0, len(pathNLRIs.pfxs))
+	for _, pfx := range pathNLRIs.pfxs {
+		k := nlriKey{
+			pfx:    *pfx,
+			pathID: pathNLRIs.path.BGPPath.PathIdentifier,
+		}
+
+		if u.queued[k] != key {
+			continue
+		}
+
+		delete(u.queued, k)
+		ret = append(ret, pfx)
+	}
+
+	return ret
+}
+
+// serializedAttributes gets the path attributes of path in wire format. They are shared with all members of the update group.
+func (u *UpdateSender) serializedAttributes(path *route.Path) (*serializedAttributes, error) {
+	k := attributeCacheKey{
+		hash:          path.BGPPath.ComputeHash(),
+		iBGP:          u.iBGP,
+		rrClient:      u.rrClient,
+		use32BitASN:   u.options.Use32BitASN,
+		multiProtocol: u.addressFamily.multiProtocol,
+	}
+
+	if u.attrCache != nil {
+		if a, ok := u.attrCache.get(k); ok {
+			return a, nil
+		}
+	}
+
+	pa, err := packet.PathAttributes(path, u.iBGP, u.rrClient)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &serializedAttributes{}
+	if u.addressFamily.multiProtocol {
+		pa, a.nextHop = copyAttributesWithoutNextHop(pa)
+	}
+	a.attrs = packet.SerializePathAttributes(pa, u.options)
+
+	if u.attrCache != nil {
+		u.attrCache.set(k, a)
+	}
+
+	return a, nil
+}
+
+// sendEndOfRIB queues an End-of-RIB marker to be sent once all queued updates are sent (RFC4724 2)
+func (u *UpdateSender) sendEndOfRIB() {
+	u.toSendMu.Lock()
+	defer u.toSendMu.Unlock()
+
+	u.endOfRIB = true
+}
+
+func (u *UpdateSender) sendEndOfRIBMarker() {
+	err := serializeAndSendUpdate(u.fsm.con, packet.EndOfRIB(u.addressFamily.afi, u.addressFamily.safi), u.options)
+	if err != nil {
+		log.Errorf("Failed to send End-of-RIB: %v", err)
+		return
+	}
+
+	atomic.AddUint64(&u.fsm.counters.updatesSent, 1)
+}
+
+// resend queues routes to be sent again. With enhanced set they are preceded by a BoRR marker and followed by an EoRR marker (RFC7313 4).
+func (u *UpdateSender) resend(routes []*route.Route, enhanced bool) {
+	if enhanced {
+		u.sendRouteRefreshMarker(packet.BeginningOfRouteRefresh)
+	}
+
+	for _, r := range routes {
+		for _, p := range r.Paths() {
+			u.AddPath(r.Prefix(), p)
+		}
+	}
+
+	if enhanced {
+		u.toSendMu.Lock()
+		u.endOfRefresh = true
+		u.toSendMu.Unlock()
+	}
+}
+
+func (u *UpdateSender) sendRouteRefreshMarker(subtype uint8) {
+	err := u.fsm.sendRouteRefresh(u.addressFamily.afi, u.addressFamily.safi, subtype)
+	if err != nil {
+		log.Errorf("Failed to send route refresh marker: %v", err)
 	}
 }
 
@@ -175,38 +402,64 @@ func (u *UpdateSender) updateOverhead() int {
 	return packet.AFILen + packet.SAFILen + 1 + addrLen - packet.IPv4Len + 1
 }
 
-func (u *UpdateSender) sendUpdates(pathAttrs *packet.PathAttribute, updatePrefixes [][]*bnet.Prefix, pathID uint32) {
-	var err error
+// serializeUpdates queues the updates for the prefixes of a path to the serializer
+func (u *UpdateSender) serializeUpdates(attrs *serializedAttributes, updatePrefixes [][]*bnet.Prefix, path *route.Path, queued time.Time) pendingUpdates {
+	ret := pendingUpdates{
+		jobs:   make([]*serializeJob, 0, len(updatePrefixes)),
+		queued: queued,
+	}
+
 	for _, prefixes := range updatePrefixes {
-		update := u.updateMessageForPrefixes(prefixes, pathAttrs, pathID)
+		update := u.updateMessageForPrefixes(prefixes, attrs, path)
 		if update == nil {
 			log.Errorf("Failed to create update: Neighbor does not support multi protocol.")
-			return
+			break
 		}
 
-		err = serializeAndSendUpdate(u.fsm.con, update, u.options)
-		if err != nil {
-			log.Errorf("Failed to serialize and send: %v", err)
+		ret.jobs = append(ret.jobs, u.serializer.serialize(update, u.options))
+	}
+
+	return ret
+}
+
+// writeUpdates sends serialized updates in the order they were queued
+func (u *UpdateSender) writeUpdates(pending []pendingUpdates) {
+	for _, p := range pending {
+		for _, j := range p.jobs {
+			b, err := j.wait()
+			if err != nil {
+				log.Errorf("Unable to serialize BGP Update: %v", err)
+				continue
+			}
+
+			_, err = u.fsm.con.Write(b.Bytes())
+			bufpool.Put(b)
+			if err != nil {
+				log.Errorf("Failed to send: %v", err)
+			}
+			atomic.AddUint64(&u.fsm.counters.updatesSent, 1)
+			u.fsm.peer.counters.updateSent(j.update)
 		}
-		atomic.AddUint64(&u.fsm.counters.updatesSent, 1)
+
+		u.fsm.peer.counters.adjRIBOutLatency.Observe(time.Since(p.queued))
 	}
 }
 
-func (u *UpdateSender) updateMessageForPrefixes(pfxs []*bnet.Prefix, pa *packet.PathAttribute, pathID uint32) *packet.BGPUpdate {
+func (u *UpdateSender) updateMessageForPrefixes(pfxs []*bnet.Prefix, attrs *serializedAttributes, path *route.Path) *packet.BGPUpdate {
 	if u.addressFamily.afi == packet.IPv4AFI && !u.addressFamily.multiProtocol {
-		return u.bgpUpdate(pfxs, pa, pathID)
+		return u.bgpUpdate(pfxs, attrs, path.BGPPath.PathIdentifier)
 	}
 
 	if u.addressFamily.multiProtocol {
-		return u.bgpUpdateMultiProtocol(pfxs, pa, pathID)
+		return u.bgpUpdateMultiProtocol(pfxs, attrs, path)
 	}
 
 	return nil
 }
 
-func (u *UpdateSender) bgpUpdate(pfxs []*bnet.Prefix, pa *packet.PathAttribute, pathID uint32) *packet.BGPUpdate {
+func (u *UpdateSender) bgpUpdate(pfxs []*bnet.Prefix, attrs *serializedAttributes, pathID uint32) *packet.BGPUpdate {
 	update := &packet.BGPUpdate{
-		PathAttributes: pa,
+		SerializedPathAttributes: attrs.attrs,
 	}
 
 	var nlri *packet.NLRI
@@ -222,31 +475,42 @@ func (u *UpdateSender) bgpUpdate(pfxs []*bnet.Prefix, pa *packet.PathAttribute,
 	return update
 }
 
-func (u *UpdateSender) bgpUpdateMultiProtocol(pfxs []*bnet.Prefix, pa *packet.PathAttribute, pathID uint32) *packet.BGPUpdate {
-	pa, nextHop := u.copyAttributesWithoutNextHop(pa)
-
-	attrs := &packet.PathAttribute{
-		TypeCode: packet.MultiProtocolReachNLRICode,
-		Value: packet.MultiProtocolReachNLRI{
-			AFI:     u.addressFamily.afi,
-			SAFI:    u.addressFamily.safi,
-			NextHop: nextHop,
-			NLRI:    u.nlriForPrefixes(pfxs, pathID),
-		},
+func (u *UpdateSender) bgpUpdateMultiProtocol(pfxs []*bnet.Prefix, attrs *serializedAttributes, path *route.Path) *packet.BGPUpdate {
+	nlri := u.nlriForPrefixes(pfxs, path)
+	if nlri == nil {
+		return nil
 	}
-	attrs.Next = pa
 
 	return &packet.BGPUpdate{
-		PathAttributes: attrs,
+		PathAttributes: &packet.PathAttribute{
+			TypeCode: packet.MultiProtocolReachNLRICode,
+			Value: packet.MultiProtocolReachNLRI{
+				AFI:     u.addressFamily.afi,
+				SAFI:    u.addressFamily.safi,
+				NextHop: attrs.nextHop,
+				NLRI:    nlri,
+			},
+		},
+		SerializedPathAttributes: attrs.attrs,
 	}
 }
 
-func (u *UpdateSender) nlriForPrefixes(pfxs []*bnet.Prefix, pathID uint32) *packet.NLRI {
+func (u *UpdateSender) nlriForPrefixes(pfxs []*bnet.Prefix, path *route.Path) *packet.NLRI {
 	var prev, res *packet.NLRI
 	for _, pfx := range pfxs {
 		cur := &packet.NLRI{
 			Prefix:         pfx,
-			PathIdentifier: pathID,
+			PathIdentifier: path.BGPPath.PathIdentifier,
+		}
+
+		if packet.IsLabeledSAFI(u.addressFamily.safi) {
+			labels, err := u.labels(pfx, path)
+			if err != nil {
+				log.Errorf("Unable to get label for %s: %v", pfx.String(), err)
+				continue
+			}
+
+			cur.Labels = labels
 		}
 
 		if res == nil {
@@ -262,7 +526,39 @@ func (u *UpdateSender) nlriForPrefixes(pfxs []*bnet.Prefix, pathID uint32) *pack
 	return res
 }
 
-func (u *UpdateSender) copyAttributesWithoutNextHop(pa *packet.PathAttribute) (attrs *packet.PathAttribute, nextHop *bnet.IP) {
+// labeledPath prepares a path for advertisement via labeled unicast. Paths learned without label or
+// with the next hop already set to ourselves get our address as next hop and local labels (RFC8277 2).
+func (u *UpdateSender) labeledPath(p *route.Path) *route.Path {
+	nextHop := p.NextHop()
+	local := u.addressFamily.localAddress
+	if len(p.BGPPath.Labels) > 0 && (local == nil || nextHop == nil || *nextHop != *local) {
+		return p
+	}
+
+	cp := p.Copy()
+	a := *cp.BGPPath.BGPPathA
+	a.NextHop = local
+	cp.BGPPath.BGPPathA = &a
+	cp.BGPPath.Labels = nil
+
+	return cp
+}
+
+// labels gets the labels advertised for pfx. Paths without labels get the local label of the prefix.
+func (u *UpdateSender) labels(pfx *bnet.Prefix, p *route.Path) ([]uint32, error) {
+	if len(p.BGPPath.Labels) > 0 {
+		return p.BGPPath.Labels, nil
+	}
+
+	l, err := u.addressFamily.labels.label(pfx)
+	if err != nil {
+		return nil, err
+	}
+
+	return []uint32{l}, nil
+}
+
+func copyAttributesWithoutNextHop(pa *packet.PathAttribute) (attrs *packet.PathAttribute, nextHop *bnet.IP) {
 	var curCopy, lastCopy *packet.PathAttribute
 	for cur := pa; cur != nil; cur = cur.Next {
 		if cur.TypeCode == packet.NextHopAttr {
@@ -282,18 +578,39 @@ func (u *UpdateSender) copyAttributesWithoutNextHop(pa *packet.PathAttribute) (a
 	return attrs, nextHop
 }
 
-// RemovePath withdraws prefix `pfx` from a peer
+// RemovePath queues the withdrawal of prefix `pfx`. Withdrawals are packed into as few updates as possible.
+// A queued advertisement of pfx is dropped.
 func (u *UpdateSender) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
-	err := u.withdrawPrefix(u.fsm.con, pfx, p)
+	err := u.checkWithdrawable(p)
 	if err != nil {
 		log.Errorf("Unable to withdraw prefix: %v", err)
 		return false
 	}
 
+	k := nlriKey{
+		pfx:    *pfx,
+		pathID: p.BGPPath.PathIdentifier,
+	}
+
+	u.toSendMu.Lock()
+	defer u.toSendMu.Unlock()
+
+	u.queueWithdrawal(k)
 	return true
 }
 
-func (u *UpdateSender) withdrawPrefix(out io.Writer, pfx *bnet.Prefix, p *route.Path) error {
+// queueWithdrawal queues the withdrawal of k and drops a queued advertisement of it. toSendMu must be held.
+func (u *UpdateSender) queueWithdrawal(k nlriKey) {
+	delete(u.queued, k)
+	if _, exists := u.toWithdraw[k]; exists {
+		return
+	}
+
+	u.toWithdraw[k] = struct{}{}
+	u.withdrawOrder = append(u.withdrawOrder, k)
+}
+
+func (u *UpdateSender) checkWithdrawable(p *route.Path) error {
 	if p.Type != route.BGPPathType {
 		return errors.New("wrong path type, expected BGPPathType")
 	}
@@ -302,32 +619,108 @@ func (u *UpdateSender) withdrawPrefix(out io.Writer, pfx *bnet.Prefix, p *route.
 		return errors.New("got nil BGPPath")
 	}
 
+	if u.addressFamily.afi != packet.IPv4AFI && !u.addressFamily.multiProtocol {
+		return fmt.Errorf(packet.AFIName(u.addressFamily.afi) + " was not negotiated")
+	}
+
+	return nil
+}
+
+// dequeueWithdrawals gets the queued withdrawals in the order they were queued. Caller must hold toSendMu.
+func (u *UpdateSender) dequeueWithdrawals() []nlriKey {
+	ret := make([]nlriKey, 0, len(u.toWithdraw))
+	for _, k := range u.withdrawOrder {
+		if _, ok := u.toWithdraw[k]; !ok {
+			continue
+		}
+
+		delete(u.toWithdraw, k)
+		ret = append(ret, k)
+	}
+
+	u.withdrawOrder = nil
+	return ret
+}
+
+// sendWithdrawals withdraws prefixes in as few updates as possible
+func (u *UpdateSender) sendWithdrawals(withdrawals []nlriKey) {
+	for len(withdrawals) > 0 {
+		budget := packet.MaxLen - packet.HeaderLen - packet.MinUpdateLen - u.withdrawOverhead()
+
+		var first, last *packet.NLRI
+		n := 0
+		for _, k := range withdrawals {
+			budget -= u.withdrawLen(k)
+			if budget < 0 && n > 0 {
+				break
+			}
+
+			pfx := k.pfx
+			cur := &packet.NLRI{
+				PathIdentifier: k.pathID,
+				Prefix:         &pfx,
+			}
+
+			if first == nil {
+				first = cur
+			} else {
+				last.Next = cur
+			}
+			last = cur
+			n++
+		}
+		withdrawals = withdrawals[n:]
+
+		err := u.withdraw(u.fsm.con, first)
+		if err != nil {
+			log.Errorf("Unable to withdraw prefixes: %v", err)
+			continue
+		}
+
+		atomic.AddUint64(&u.fsm.counters.updatesSent, 1)
+		atomic.AddUint64(&u.fsm.peer.counters.prefixesWithdrawnSent, uint64(n))
+	}
+}
+
+func (u *UpdateSender) withdrawOverhead() int {
 	if u.addressFamily.afi == packet.IPv4AFI && !u.addressFamily.multiProtocol {
-		return u.withdrawPrefixIPv4(out, pfx, p)
+		return 0
 	}
 
-	if !u.addressFamily.multiProtocol {
-		return fmt.Errorf(packet.AFIName(u.addressFamily.afi) + " was not negotiated")
+	// MP_UNREACH_NLRI attribute with extended length
+	return 4 + packet.AFILen + packet.SAFILen
+}
+
+func (u *UpdateSender) withdrawLen(k nlriKey) int {
+	l := int(packet.BytesInAddr(k.pfx.Pfxlen())) + 1
+	if u.options.UseAddPath {
+		l += packet.PathIdentifierLen
 	}
 
-	return u.withdrawPrefixMultiProtocol(out, pfx, p)
+	if packet.IsLabeledSAFI(u.addressFamily.safi) {
+		l += packet.LabelLen
+	}
+
+	return l
 }
 
-func (u *UpdateSender) withdrawPrefixIPv4(out io.Writer, pfx *bnet.Prefix, p *route.Path) error {
-	update := &packet.BGPUpdate{
-		WithdrawnRoutes: &packet.NLRI{
-			PathIdentifier: p.BGPPath.PathIdentifier,
-			Prefix:         pfx,
-		},
+func (u *UpdateSender) withdrawPrefix(out io.Writer, pfx *bnet.Prefix, p *route.Path) error {
+	err := u.checkWithdrawable(p)
+	if err != nil {
+		return err
 	}
 
-	return serializeAndSendUpdate(out, update, u.options)
+	return u.withdraw(out, &packet.NLRI{
+		PathIdentifier: p.BGPPath.PathIdentifier,
+		Prefix:         pfx,
+	})
 }
 
-func (u *UpdateSender) withdrawPrefixMultiProtocol(out io.Writer, pfx *bnet.Prefix, p *route.Path) error {
-	pathID := uint32(0)
-	if p.BGPPath != nil {
-		pathID = p.BGPPath.PathIdentifier
+func (u *UpdateSender) withdraw(out io.Writer, nlri *packet.NLRI) error {
+	if u.addressFamily.afi == packet.IPv4AFI && !u.addressFamily.multiProtocol {
+		return serializeAndSendUpdate(out, &packet.BGPUpdate{
+			WithdrawnRoutes: nlri,
+		}, u.options)
 	}
 
 	update := &packet.BGPUpdate{
@@ -336,10 +729,7 @@ func (u *UpdateSender) withdrawPrefixMultiProtocol(out io.Writer, pfx *bnet.Pref
 			Value: packet.MultiProtocolUnreachNLRI{
 				AFI:  u.addressFamily.afi,
 				SAFI: u.addressFamily.safi,
-				NLRI: &packet.NLRI{
-					PathIdentifier: pathID,
-					Prefix:         pfx,
-				},
+				NLRI: nlri,
 			},
 		},
 	}
@@ -384,517 +384,7 @@ func TestSender(t *testing.T) {
 			},
 			generateNLRIs: 1000,
 			expectedUpdates: [][]byte{
-				{
-					0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
-					0xff, 0xff, 0xff, 0xf, 0xf0, 0x2, 0x0, 0x0, 0xf, 0xd9, 0x90, 0xe, 0xf, 0xc7,
-					0x0, 0x2, 0x1, 0x10, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x0, 0x0, 0x0, 0x0, 0x0,
-					0x0, 0x0, 0x0, 0x0, 0x2, 0x0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30,
-					0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x40, 0x2, 0x0, 0x40, 0x1, 0x1, 0x0, 0x40,
-					0x5, 0x4, 0x0, 0x0, 0x0, 0x64, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
-					0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xb, 0xe4, 0x2, 0x0, 0x0, 0xb,
-					0xcd, 0x90, 0xe, 0xb, 0xbb, 0x0, 0x2, 0x1, 0x10, 0x20, 0x1, 0x6, 0x78, 0x1,
-					0xe0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2, 0x0, 0x30, 0x20, 0x1,
-					0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1,
-					0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1,
-					0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1,
-					0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1,
-					0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20,
-					0x1, 0x6, 0x78, 0x1, 0xe0, 0x30, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0x40, 0x2,
-					0x0, 0x40, 0x1, 0x1, 0x0, 0x40, 0x5, 0x4, 0x0, 0x0, 0x0, 0x64,
-				},
+				append(ipv6OverflowUpdate(0, 574), ipv6OverflowUpdate(574, 426)...),
 			},
 		},
 	}
@@ -940,7 +430,7 @@ func TestSender(t *testing.T) {
 
 					var pfx *bnet.Prefix
 					if test.afi == packet.IPv6AFI {
-						pfx = bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0), 48).Ptr()
+						pfx = bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0x678, uint16(i), 0, 0, 0, 0, 0), 48).Ptr()
 					} else {
 						pfx = bnet.NewPfx(bnet.IPv4FromOctets(10, 0, uint8(x), uint8(y)), 32).Ptr()
 					}
@@ -974,6 +464,90 @@ func TestSender(t *testing.T) {
 	}
 }
 
+func TestSenderPacking(t *testing.T) {
+	fsmA := newFSM(&peer{
+		addr: bnet.IPv4FromOctets(169, 254, 100, 100).Ptr(),
+	})
+	fsmA.ipv4Unicast = newFSMAddressFamily(packet.IPv4AFI, packet.UnicastSAFI, &peerAddressFamily{
+		rib:               locRIB.New("inet.0"),
+		importFilterChain: filter.NewAcceptAllFilterChain(),
+		exportFilterChain: filter.NewAcceptAllFilterChain(),
+	}, fsmA)
+	fsmA.ipv4Unicast.addPathTX = routingtable.ClientOptions{BestOnly: true}
+	fsmA.con = btest.NewMockConn()
+
+	u := newUpdateSender(fsmA.ipv4Unicast)
+	u.attrCache = newAttributeCache()
+
+	p := &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				LocalPref: 100,
+				NextHop:   bnet.IPv4(0).Ptr(),
+				Source:    bnet.IPv4(0).Ptr(),
+			},
+			ASPath: &types.ASPath{},
+		},
+	}
+
+	pfx := func(x uint8) *bnet.Prefix {
+		return bnet.NewPfx(bnet.IPv4FromOctets(x, 0, 0, 0), 8).Ptr()
+	}
+
+	u.AddPath(pfx(10), p)
+	u.AddPath(pfx(11), p)
+	u.AddPath(pfx(10), p)
+	u.RemovePath(pfx(12), p)
+	u.RemovePath(pfx(13), p)
+	u.AddPath(pfx(12), p)
+	u.RemovePath(pfx(11), p)
+
+	u.Start(time.Millisecond)
+	time.Sleep(time.Millisecond * 100)
+
+	recvBuffer := make([]byte, 8192)
+	nbytes, _ := fsmA.con.Read(recvBuffer)
+
+	expected := []byte{
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 27, 2,
+		0, 4, 8, 13, 8, 11, // Withdrawn routes
+		0, 0,
+
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 48, 2,
+		0, 0,
+		0, 21, 64, 2, 0, 64, 1, 1, 0, 64, 3, 4, 0, 0, 0, 0, 64, 5, 4, 0, 0, 0, 100,
+		8, 12, 8, 10,
+	}
+
+	assert.Equal(t, expected, recvBuffer[:nbytes])
+	assert.Equal(t, 1, len(u.attrCache.current), "Attributes serialized once")
+}
+
+// ipv6OverflowUpdate creates the update advertising 2001:678:<i>::/48 for n consecutive i with a next hop of 2001:678:1e0::2
+func ipv6OverflowUpdate(from int, n int) []byte {
+	mpLen := 21 + n*7
+	attrLen := 4 + mpLen + 14
+	msgLen := packet.HeaderLen + 4 + attrLen
+
+	b := []byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		byte(msgLen >> 8), byte(msgLen), 2,
+		0, 0,
+		byte(attrLen >> 8), byte(attrLen),
+		0x90, 0x0e, byte(mpLen >> 8), byte(mpLen),
+		0, 2, 1, 0x10, 0x20, 0x1, 0x6, 0x78, 0x1, 0xe0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0,
+	}
+
+	for i := from; i < from+n; i++ {
+		b = append(b, 0x30, 0x20, 0x1, 0x6, 0x78, byte(i>>8), byte(i))
+	}
+
+	return append(b, 0x40, 0x2, 0x0, 0x40, 0x1, 0x1, 0x0, 0x40, 0x5, 0x4, 0x0, 0x0, 0x0, 0x64)
+}
+
 func TestWithdrawPrefix(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -1149,3 +723,69 @@ func TestWithdrawPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestAdvertisementDue(t *testing.T) {
+	now := time.Now()
+	queued := map[string]*pathPfxs{
+		"x": {},
+	}
+
+	tests := []struct {
+		name              string
+		interval          time.Duration
+		toSend            map[string]*pathPfxs
+		lastAdvertisement time.Time
+		expected          bool
+		expectedLast      time.Time
+	}{
+		{
+			name:              "No interval",
+			toSend:            queued,
+			lastAdvertisement: now,
+			expected:          true,
+			expectedLast:      now,
+		},
+		{
+			name:         "First advertisement",
+			interval:     time.Second * 30,
+			toSend:       queued,
+			expected:     true,
+			expectedLast: now,
+		},
+		{
+			name:              "Interval running",
+			interval:          time.Second * 30,
+			toSend:            queued,
+			lastAdvertisement: now.Add(-time.Second * 10),
+			expected:          false,
+			expectedLast:      now.Add(-time.Second * 10),
+		},
+		{
+			name:              "Interval expired",
+			interval:          time.Second * 30,
+			toSend:            queued,
+			lastAdvertisement: now.Add(-time.Second * 30),
+			expected:          true,
+			expectedLast:      now,
+		},
+		{
+			name:              "Nothing queued",
+			interval:          time.Second * 30,
+			toSend:            map[string]*pathPfxs{},
+			lastAdvertisement: now.Add(-time.Second * 10),
+			expected:          true,
+			expectedLast:      now.Add(-time.Second * 10),
+		},
+	}
+
+	for _, test := range tests {
+		u := &UpdateSender{
+			toSend:                test.toSend,
+			advertisementInterval: test.interval,
+			lastAdvertisement:     test.lastAdvertisement,
+		}
+
+		assert.Equal(t, test.expected, u.advertisementDue(now), "Test %q", test.name)
+		assert.Equal(t, test.expectedLast, u.lastAdvertisement, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/util/bufpool"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+)
+
+const (
+	// serializerQueueLen is the number of updates queued per worker of the update serializer
+	serializerQueueLen = 64
+
+	// maxPendingUpdates is the number of toSend entries an update sender serializes ahead of sending
+	maxPendingUpdates = 64
+)
+
+// updateSerializer is a pool of workers serializing updates for all update senders of a server, so advertising
+// to many peers at once uses all cores. Workers are started with the first update and run for the lifetime of the process.
+type updateSerializer struct {
+	workers int
+	jobs    chan *serializeJob
+	once    sync.Once
+}
+
+// serializeJob is an update to be serialized. The result is available once done is closed.
+type serializeJob struct {
+	update *packet.BGPUpdate
+	opt    *packet.EncodeOptions
+	done   chan struct{}
+	result *bytes.Buffer
+	err    error
+}
+
+func newUpdateSerializer(workers int) *updateSerializer {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	return &updateSerializer{
+		workers: workers,
+		jobs:    make(chan *serializeJob, workers*serializerQueueLen),
+	}
+}
+
+// serialize queues an update for serialization. A nil serializer serializes right away.
+func (s *updateSerializer) serialize(u *packet.BGPUpdate, opt *packet.EncodeOptions) *serializeJob {
+	j := &serializeJob{
+		update: u,
+		opt:    opt,
+		done:   make(chan struct{}),
+	}
+
+	if s == nil {
+		j.run()
+		return j
+	}
+
+	s.once.Do(s.start)
+	s.jobs <- j
+	return j
+}
+
+func (s *updateSerializer) start() {
+	for i := 0; i < s.workers; i++ {
+		go s.worker()
+	}
+}
+
+func (s *updateSerializer) worker() {
+	defer crashdump.Recover()
+
+	for j := range s.jobs {
+		j.run()
+	}
+}
+
+func (j *serializeJob) run() {
+	buf := bufpool.Get()
+	err := j.update.SerializeUpdateTo(buf, j.opt)
+	if err != nil {
+		bufpool.Put(buf)
+		j.err = err
+	} else {
+		j.result = buf
+	}
+
+	close(j.done)
+}
+
+// wait waits for the update to be serialized. The caller must return the buffer to the bufpool once it has been sent.
+func (j *serializeJob) wait() (*bytes.Buffer, error) {
+	<-j.done
+	return j.result, j.err
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/util/bufpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSerializer(t *testing.T) {
+	opt := &packet.EncodeOptions{}
+	updates := make([]*packet.BGPUpdate, 0)
+	for i := 0; i < 200; i++ {
+		updates = append(updates, &packet.BGPUpdate{
+			WithdrawnRoutes: &packet.NLRI{
+				Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, uint8(i), 0, 0), 16).Ptr(),
+			},
+		})
+	}
+
+	tests := []struct {
+		name       string
+		serializer *updateSerializer
+	}{
+		{
+			name:       "Worker pool",
+			serializer: newUpdateSerializer(4),
+		},
+		{
+			name:       "Inline",
+			serializer: nil,
+		},
+	}
+
+	for _, test := range tests {
+		jobs := make([]*serializeJob, 0, len(updates))
+		for _, u := range updates {
+			jobs = append(jobs, test.serializer.serialize(u, opt))
+		}
+
+		for i, j := range jobs {
+			b, err := j.wait()
+			assert.NoError(t, err, "Test %q", test.name)
+
+			expected, _ := updates[i].SerializeUpdate(opt)
+			assert.Equal(t, expected, b.Bytes(), "Test %q: update %d", test.name, i)
+			bufpool.Put(b)
+		}
+	}
+}
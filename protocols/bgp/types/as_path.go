@@ -14,6 +14,12 @@ const (
 	// ASSequence is tha AS Path type used to indicate an AS Sequence (RFC4271)
 	ASSequence = 2
 
+	// ASConfedSequence is the AS Path type used to indicate an AS Sequence within a confederation (RFC5065)
+	ASConfedSequence = 3
+
+	// ASConfedSet is the AS Path type used to indicate an AS Set within a confederation (RFC5065)
+	ASConfedSet = 4
+
 	// MaxASNsSegment is the maximum number of ASNs in an AS segment
 	MaxASNsSegment = 255
 )
@@ -181,6 +187,11 @@ func (pa *ASPath) String() (ret string) {
 // Length returns the AS path length as used by path selection
 func (pa ASPath) Length() (ret uint16) {
 	for _, p := range pa {
+		// Confederation segments are not counted (RFC5065 5.3)
+		if p.Type == ASConfedSequence || p.Type == ASConfedSet {
+			continue
+		}
+
 		if p.Type == ASSet {
 			ret++
 			continue
@@ -190,3 +201,8 @@ func (pa ASPath) Length() (ret uint16) {
 
 	return
 }
+
+// IsPrivateASN checks if asn is reserved for private use (RFC6996)
+func IsPrivateASN(asn uint32) bool {
+	return (asn >= 64512 && asn <= 65534) || (asn >= 4200000000 && asn <= 4294967294)
+}
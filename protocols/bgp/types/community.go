@@ -11,6 +11,12 @@ const (
 	WellKnownCommunityNoExport = 0xFFFFFF01
 	// WellKnownCommunityNoAdvertise is the well known no advertise BGP community (RFC1997)
 	WellKnownCommunityNoAdvertise = 0xFFFFFF02
+	// WellKnownCommunityNoExportSubconfed is the well known no export subconfed BGP community (RFC1997)
+	WellKnownCommunityNoExportSubconfed = 0xFFFFFF03
+	// WellKnownCommunityNoPeer is the well known no peer BGP community (RFC3765)
+	WellKnownCommunityNoPeer = 0xFFFFFF04
+	// WellKnownCommunityGracefulShutdown is the well known graceful shutdown BGP community (RFC8326)
+	WellKnownCommunityGracefulShutdown = 0xFFFF0000
 )
 
 // CommunityStringForUint32 transforms a community into a human readable representation
@@ -0,0 +1,219 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ExtendedCommunityTypeTwoOctetAS is the transitive two-octet AS specific extended community type (RFC4360)
+	ExtendedCommunityTypeTwoOctetAS = 0x00
+
+	// ExtendedCommunityTypeIPv4Address is the transitive IPv4 address specific extended community type (RFC4360)
+	ExtendedCommunityTypeIPv4Address = 0x01
+
+	// ExtendedCommunityTypeFourOctetAS is the transitive four-octet AS specific extended community type (RFC5668)
+	ExtendedCommunityTypeFourOctetAS = 0x02
+
+	// ExtendedCommunityTypeTwoOctetASNonTransitive is the non-transitive two-octet AS specific extended community type (RFC4360)
+	ExtendedCommunityTypeTwoOctetASNonTransitive = 0x40
+
+	// ExtendedCommunitySubTypeRouteTarget is the route target sub-type (RFC4360)
+	ExtendedCommunitySubTypeRouteTarget = 0x02
+
+	// ExtendedCommunitySubTypeLinkBandwidth is the link bandwidth sub-type (draft-ietf-idr-link-bandwidth)
+	ExtendedCommunitySubTypeLinkBandwidth = 0x04
+
+	routeTargetPrefix = "target:"
+)
+
+// ExtendedCommunities is a list of extended communities
+type ExtendedCommunities []ExtendedCommunity
+
+func (ec *ExtendedCommunities) String() string {
+	if ec == nil {
+		return ""
+	}
+
+	ret := ""
+	for _, x := range *ec {
+		ret += x.String() + " "
+	}
+
+	return ret
+}
+
+// RouteTargets gets all route targets of the list
+func (ec *ExtendedCommunities) RouteTargets() ExtendedCommunities {
+	if ec == nil {
+		return nil
+	}
+
+	ret := make(ExtendedCommunities, 0)
+	for _, x := range *ec {
+		if x.IsRouteTarget() {
+			ret = append(ret, x)
+		}
+	}
+
+	return ret
+}
+
+// LinkBandwidth gets the bandwidth in bytes per second of the first link bandwidth community of the list
+func (ec *ExtendedCommunities) LinkBandwidth() (float32, bool) {
+	if ec == nil {
+		return 0, false
+	}
+
+	for _, x := range *ec {
+		if bw, ok := x.LinkBandwidth(); ok {
+			return bw, true
+		}
+	}
+
+	return 0, false
+}
+
+// ContainsAny returns if at least one of coms is in the list
+func (ec *ExtendedCommunities) ContainsAny(coms ExtendedCommunities) bool {
+	if ec == nil {
+		return false
+	}
+
+	for _, x := range *ec {
+		for _, y := range coms {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ExtendedCommunity represents an extended community (RFC4360) in its 8 byte wire format
+type ExtendedCommunity uint64
+
+// Type gets the high order type octet
+func (c ExtendedCommunity) Type() uint8 {
+	return uint8(c >> 56)
+}
+
+// SubType gets the low order type octet
+func (c ExtendedCommunity) SubType() uint8 {
+	return uint8(c >> 48)
+}
+
+// IsRouteTarget returns if the community is a route target
+func (c ExtendedCommunity) IsRouteTarget() bool {
+	if c.SubType() != ExtendedCommunitySubTypeRouteTarget {
+		return false
+	}
+
+	switch c.Type() {
+	case ExtendedCommunityTypeTwoOctetAS, ExtendedCommunityTypeIPv4Address, ExtendedCommunityTypeFourOctetAS:
+		return true
+	}
+
+	return false
+}
+
+// NewLinkBandwidth creates a link bandwidth community with a bandwidth in bytes per second
+func NewLinkBandwidth(asn uint16, bandwidth float32) ExtendedCommunity {
+	return ExtendedCommunity(ExtendedCommunityTypeTwoOctetASNonTransitive)<<56 |
+		ExtendedCommunity(ExtendedCommunitySubTypeLinkBandwidth)<<48 |
+		ExtendedCommunity(asn)<<32 |
+		ExtendedCommunity(math.Float32bits(bandwidth))
+}
+
+// LinkBandwidth gets the bandwidth in bytes per second if the community is a link bandwidth community
+func (c ExtendedCommunity) LinkBandwidth() (float32, bool) {
+	if c.SubType() != ExtendedCommunitySubTypeLinkBandwidth {
+		return 0, false
+	}
+
+	if c.Type() != ExtendedCommunityTypeTwoOctetASNonTransitive && c.Type() != ExtendedCommunityTypeTwoOctetAS {
+		return 0, false
+	}
+
+	return math.Float32frombits(uint32(c)), true
+}
+
+// String transitions an extended community to it's human readable representation
+func (c ExtendedCommunity) String() string {
+	if !c.IsRouteTarget() {
+		return fmt.Sprintf("0x%016x", uint64(c))
+	}
+
+	switch c.Type() {
+	case ExtendedCommunityTypeTwoOctetAS:
+		return fmt.Sprintf("%s%d:%d", routeTargetPrefix, uint16(c>>32), uint32(c))
+	case ExtendedCommunityTypeIPv4Address:
+		ip := uint32(c >> 16)
+		return fmt.Sprintf("%s%d.%d.%d.%d:%d", routeTargetPrefix, ip>>24, uint8(ip>>16), uint8(ip>>8), uint8(ip), uint16(c))
+	default:
+		return fmt.Sprintf("%s%d:%d", routeTargetPrefix, uint32(c>>16), uint16(c))
+	}
+}
+
+// NewRouteTarget creates a route target for an AS. The two-octet AS format is used for ASNs fitting into two octets.
+func NewRouteTarget(asn uint32, value uint32) (ExtendedCommunity, error) {
+	if asn <= 0xffff {
+		return ExtendedCommunity(ExtendedCommunityTypeTwoOctetAS)<<56 |
+			ExtendedCommunity(ExtendedCommunitySubTypeRouteTarget)<<48 |
+			ExtendedCommunity(asn)<<32 |
+			ExtendedCommunity(value), nil
+	}
+
+	if value > 0xffff {
+		return 0, fmt.Errorf("Value %d of four-octet AS route target exceeds 65535", value)
+	}
+
+	return ExtendedCommunity(ExtendedCommunityTypeFourOctetAS)<<56 |
+		ExtendedCommunity(ExtendedCommunitySubTypeRouteTarget)<<48 |
+		ExtendedCommunity(asn)<<16 |
+		ExtendedCommunity(value), nil
+}
+
+// ParseRouteTarget parses a human readable route target, e.g. target:65000:100 or 192.0.2.1:100
+func ParseRouteTarget(s string) (ExtendedCommunity, error) {
+	s = strings.TrimPrefix(s, routeTargetPrefix)
+
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return 0, fmt.Errorf("can not parse route target %s", s)
+	}
+
+	admin := s[:i]
+	if strings.Contains(admin, ".") {
+		ip := net.ParseIP(admin).To4()
+		if ip == nil {
+			return 0, fmt.Errorf("can not parse route target %s", s)
+		}
+
+		v, err := strconv.ParseUint(s[i+1:], 10, 16)
+		if err != nil {
+			return 0, err
+		}
+
+		return ExtendedCommunity(ExtendedCommunityTypeIPv4Address)<<56 |
+			ExtendedCommunity(ExtendedCommunitySubTypeRouteTarget)<<48 |
+			ExtendedCommunity(ip[0])<<40 | ExtendedCommunity(ip[1])<<32 | ExtendedCommunity(ip[2])<<24 | ExtendedCommunity(ip[3])<<16 |
+			ExtendedCommunity(v), nil
+	}
+
+	asn, err := strconv.ParseUint(admin, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseUint(s[i+1:], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return NewRouteTarget(uint32(asn), uint32(v))
+}
@@ -0,0 +1,142 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRouteTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected ExtendedCommunity
+		str      string
+		wantErr  bool
+	}{
+		{
+			name:     "Two-octet AS",
+			in:       "target:65000:100",
+			expected: 0x0002fde800000064,
+			str:      "target:65000:100",
+		},
+		{
+			name:     "Two-octet AS without prefix",
+			in:       "65000:100",
+			expected: 0x0002fde800000064,
+			str:      "target:65000:100",
+		},
+		{
+			name:     "Four-octet AS",
+			in:       "target:4200000000:100",
+			expected: 0x0202fa56ea000064,
+			str:      "target:4200000000:100",
+		},
+		{
+			name:    "Four-octet AS with too large value",
+			in:      "target:4200000000:70000",
+			wantErr: true,
+		},
+		{
+			name:     "IPv4 address",
+			in:       "target:192.0.2.1:100",
+			expected: 0x0102c00002010064,
+			str:      "target:192.0.2.1:100",
+		},
+		{
+			name:    "Invalid IPv4 address",
+			in:      "target:192.0.2:100",
+			wantErr: true,
+		},
+		{
+			name:    "Missing value",
+			in:      "target:65000",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		rt, err := ParseRouteTarget(test.in)
+		if test.wantErr {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.expected, rt, "Test %q", test.name)
+		assert.Truef(t, rt.IsRouteTarget(), "Test %q", test.name)
+		assert.Equalf(t, test.str, rt.String(), "Test %q", test.name)
+	}
+}
+
+func TestExtendedCommunitiesContainsAny(t *testing.T) {
+	rtA, _ := NewRouteTarget(65000, 1)
+	rtB, _ := NewRouteTarget(65000, 2)
+	other := ExtendedCommunity(0x0003000000000001)
+
+	tests := []struct {
+		name     string
+		coms     *ExtendedCommunities
+		rts      ExtendedCommunities
+		expected bool
+	}{
+		{
+			name:     "Match",
+			coms:     &ExtendedCommunities{other, rtB},
+			rts:      ExtendedCommunities{rtA, rtB},
+			expected: true,
+		},
+		{
+			name:     "No match",
+			coms:     &ExtendedCommunities{other, rtA},
+			rts:      ExtendedCommunities{rtB},
+			expected: false,
+		},
+		{
+			name:     "Nil",
+			rts:      ExtendedCommunities{rtB},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, test.coms.ContainsAny(test.rts), "Test %q", test.name)
+	}
+}
+
+func TestExtendedCommunitiesLinkBandwidth(t *testing.T) {
+	rt, _ := NewRouteTarget(65000, 1)
+
+	tests := []struct {
+		name     string
+		coms     *ExtendedCommunities
+		expected float32
+		ok       bool
+	}{
+		{
+			name:     "Link bandwidth",
+			coms:     &ExtendedCommunities{rt, NewLinkBandwidth(65000, 1.25e9)},
+			expected: 1.25e9,
+			ok:       true,
+		},
+		{
+			name:     "Transitive link bandwidth",
+			coms:     &ExtendedCommunities{ExtendedCommunity(0x0004fde84e6e6b28)},
+			expected: 1e9,
+			ok:       true,
+		},
+		{
+			name: "No link bandwidth",
+			coms: &ExtendedCommunities{rt},
+		},
+		{
+			name: "Nil",
+		},
+	}
+
+	for _, test := range tests {
+		bw, ok := test.coms.LinkBandwidth()
+		assert.Equalf(t, test.ok, ok, "Test %q", test.name)
+		assert.Equalf(t, test.expected, bw, "Test %q", test.name)
+	}
+}
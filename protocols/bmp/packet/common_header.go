@@ -4,7 +4,7 @@ import (
 	"bytes"
 
 	"github.com/bio-routing/bio-rd/util/decoder"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
@@ -22,10 +22,22 @@ type CommonHeader struct {
 // Serialize serializes a common header
 func (c *CommonHeader) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(c.Version)
-	buf.Write(convert.Uint32Byte(c.MsgLength))
+	endian.WriteUint32(buf, c.MsgLength)
 	buf.WriteByte(c.MsgType)
 }
 
+// serializeMsg serializes a message of type msgType consisting of a common header followed by body
+func serializeMsg(buf *bytes.Buffer, msgType uint8, body []byte) {
+	ch := &CommonHeader{
+		Version:   BMPVersion,
+		MsgLength: uint32(CommonHeaderLen + len(body)),
+		MsgType:   msgType,
+	}
+
+	ch.Serialize(buf)
+	buf.Write(body)
+}
+
 func decodeCommonHeader(buf *bytes.Buffer) (*CommonHeader, error) {
 	ch := &CommonHeader{}
 	fields := []interface{}{
@@ -1,6 +1,7 @@
 package packet
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -462,3 +463,163 @@ func TestDecode(t *testing.T) {
 		assert.Equalf(t, test.expected, m, "Test %q", test.name)
 	}
 }
+
+type serializableMsg interface {
+	Serialize(buf *bytes.Buffer)
+}
+
+func TestSerializeDecode(t *testing.T) {
+	pph := &PerPeerHeader{
+		PeerType:              GlobalInstancePeer,
+		PeerFlags:             PeerFlagL,
+		PeerAddress:           [16]byte{12: 192, 13: 0, 14: 2, 15: 1},
+		PeerAS:                65001,
+		PeerBGPID:             123,
+		Timestamp:             100,
+		TimestampMicroSeconds: 200,
+	}
+
+	openMsg := []byte{
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
+		0, 29, 1,
+		4, 253, 233, 0, 90, 0, 0, 0, 123, 0,
+	}
+
+	tests := []struct {
+		name     string
+		msg      serializableMsg
+		expected Msg
+	}{
+		{
+			name: "Initiation message",
+			msg: &InitiationMessage{
+				TLVs: []*InformationTLV{
+					NewInformationTLV(InformationTypeSysName, []byte("bio-rd")),
+				},
+			},
+			expected: &InitiationMessage{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + MinInformationTLVLen + 6,
+					MsgType:   InitiationMessageType,
+				},
+				TLVs: []*InformationTLV{
+					NewInformationTLV(InformationTypeSysName, []byte("bio-rd")),
+				},
+			},
+		},
+		{
+			name: "Peer up notification",
+			msg: &PeerUpNotification{
+				PerPeerHeader:   pph,
+				LocalAddress:    [16]byte{12: 192, 13: 0, 14: 2, 15: 2},
+				LocalPort:       179,
+				RemotePort:      1234,
+				SentOpenMsg:     openMsg,
+				ReceivedOpenMsg: openMsg,
+			},
+			expected: &PeerUpNotification{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + PerPeerHeaderLen + 20 + 2*29,
+					MsgType:   PeerUpNotificationType,
+				},
+				PerPeerHeader:   pph,
+				LocalAddress:    [16]byte{12: 192, 13: 0, 14: 2, 15: 2},
+				LocalPort:       179,
+				RemotePort:      1234,
+				SentOpenMsg:     openMsg,
+				ReceivedOpenMsg: openMsg,
+			},
+		},
+		{
+			name: "Peer down notification",
+			msg: &PeerDownNotification{
+				PerPeerHeader: pph,
+				Reason:        PeerDownLocalNotification,
+				Data:          []byte{1, 2, 3},
+			},
+			expected: &PeerDownNotification{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + PerPeerHeaderLen + 4,
+					MsgType:   PeerDownNotificationType,
+				},
+				PerPeerHeader: pph,
+				Reason:        PeerDownLocalNotification,
+				Data:          []byte{1, 2, 3},
+			},
+		},
+		{
+			name: "Route monitoring message",
+			msg: &RouteMonitoringMsg{
+				PerPeerHeader: pph,
+				BGPUpdate:     []byte{1, 2, 3, 4},
+			},
+			expected: &RouteMonitoringMsg{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + PerPeerHeaderLen + 4,
+					MsgType:   RouteMonitoringType,
+				},
+				PerPeerHeader: pph,
+				BGPUpdate:     []byte{1, 2, 3, 4},
+			},
+		},
+		{
+			name: "Stats report",
+			msg: &StatsReport{
+				PerPeerHeader: pph,
+				Stats: []*InformationTLV{
+					NewCounterStat(StatRejectedPrefixes, 3),
+					NewGaugeStat(StatAdjRIBInRoutes, 100),
+					NewAFIGaugeStat(StatAdjRIBInRoutesPerAFI, 2, 1, 100),
+				},
+			},
+			expected: &StatsReport{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + PerPeerHeaderLen + 4 + 3*MinInformationTLVLen + 4 + 8 + 11,
+					MsgType:   StatisticsReportType,
+				},
+				PerPeerHeader: pph,
+				StatsCount:    3,
+				Stats: []*InformationTLV{
+					{InformationType: StatRejectedPrefixes, InformationLength: 4, Information: []byte{0, 0, 0, 3}},
+					{InformationType: StatAdjRIBInRoutes, InformationLength: 8, Information: []byte{0, 0, 0, 0, 0, 0, 0, 100}},
+					{InformationType: StatAdjRIBInRoutesPerAFI, InformationLength: 11, Information: []byte{0, 2, 1, 0, 0, 0, 0, 0, 0, 0, 100}},
+				},
+			},
+		},
+		{
+			name: "Termination message",
+			msg: &TerminationMessage{
+				TLVs: []*InformationTLV{
+					NewInformationTLV(InformationTypeString, []byte("bye")),
+				},
+			},
+			expected: &TerminationMessage{
+				CommonHeader: &CommonHeader{
+					Version:   BMPVersion,
+					MsgLength: CommonHeaderLen + MinInformationTLVLen + 3,
+					MsgType:   TerminationMessageType,
+				},
+				TLVs: []*InformationTLV{
+					NewInformationTLV(InformationTypeString, []byte("bye")),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		test.msg.Serialize(buf)
+
+		msg, err := Decode(buf.Bytes())
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, msg, "Test %q", test.name)
+	}
+}
@@ -4,12 +4,37 @@ import (
 	"bytes"
 
 	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
 	MinInformationTLVLen = 4
+
+	// Information TLV types of initiation and peer up messages (RFC7854 4.4)
+	InformationTypeString   = 0
+	InformationTypeSysDescr = 1
+	InformationTypeSysName  = 2
+
+	// InformationTypeVRFTableName names the Loc-RIB instance in peer up messages (RFC9069 5.1)
+	InformationTypeVRFTableName = 3
 )
 
+// NewInformationTLV creates an information TLV of type t holding info
+func NewInformationTLV(t uint16, info []byte) *InformationTLV {
+	return &InformationTLV{
+		InformationType:   t,
+		InformationLength: uint16(len(info)),
+		Information:       info,
+	}
+}
+
+// Serialize serializes an information TLV
+func (t *InformationTLV) Serialize(buf *bytes.Buffer) {
+	endian.WriteUint16(buf, t.InformationType)
+	endian.WriteUint16(buf, uint16(len(t.Information)))
+	buf.Write(t.Information)
+}
+
 // InformationTLV represents an information TLV
 type InformationTLV struct {
 	InformationType   uint16
@@ -17,6 +17,16 @@ func (im *InitiationMessage) MsgType() uint8 {
 	return im.CommonHeader.MsgType
 }
 
+// Serialize serializes an initiation message
+func (im *InitiationMessage) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	for _, tlv := range im.TLVs {
+		tlv.Serialize(body)
+	}
+
+	serializeMsg(buf, InitiationMessageType, body.Bytes())
+}
+
 func decodeInitiationMessage(buf *bytes.Buffer, ch *CommonHeader) (Msg, error) {
 	im := &InitiationMessage{
 		CommonHeader: ch,
@@ -10,6 +10,13 @@ import (
 const (
 	reasonMin = 1
 	reasonMax = 3
+
+	// Peer down reasons (RFC7854 4.9)
+	PeerDownLocalNotification   = 1
+	PeerDownLocalNoNotification = 2
+	PeerDownRemoteNotification  = 3
+	PeerDownRemoteNoData        = 4
+	PeerDownDeconfigured        = 5
 )
 
 // PeerDownNotification represents a peer down notification
@@ -25,6 +32,16 @@ func (p *PeerDownNotification) MsgType() uint8 {
 	return p.CommonHeader.MsgType
 }
 
+// Serialize serializes a peer down notification
+func (p *PeerDownNotification) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	p.PerPeerHeader.Serialize(body)
+	body.WriteByte(p.Reason)
+	body.Write(p.Data)
+
+	serializeMsg(buf, PeerDownNotificationType, body.Bytes())
+}
+
 func decodePeerDownNotification(buf *bytes.Buffer, ch *CommonHeader) (*PeerDownNotification, error) {
 	p := &PeerDownNotification{
 		CommonHeader: ch,
@@ -4,6 +4,7 @@ import (
 	"bytes"
 
 	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -29,6 +30,20 @@ func (p *PeerUpNotification) MsgType() uint8 {
 	return p.CommonHeader.MsgType
 }
 
+// Serialize serializes a peer up notification
+func (p *PeerUpNotification) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	p.PerPeerHeader.Serialize(body)
+	body.Write(p.LocalAddress[:])
+	endian.WriteUint16(body, p.LocalPort)
+	endian.WriteUint16(body, p.RemotePort)
+	body.Write(p.SentOpenMsg)
+	body.Write(p.ReceivedOpenMsg)
+	body.Write(p.Information)
+
+	serializeMsg(buf, PeerUpNotificationType, body.Bytes())
+}
+
 func decodePeerUpNotification(buf *bytes.Buffer, ch *CommonHeader) (*PeerUpNotification, error) {
 	p := &PeerUpNotification{
 		CommonHeader: ch,
@@ -42,7 +57,7 @@ func decodePeerUpNotification(buf *bytes.Buffer, ch *CommonHeader) (*PeerUpNotif
 	p.PerPeerHeader = pph
 
 	fields := []interface{}{
-		&p.LocalAddress,
+		p.LocalAddress[:],
 		&p.LocalPort,
 		&p.RemotePort,
 	}
@@ -2,14 +2,30 @@ package packet
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/bio-routing/bio-rd/util/decoder"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
 	// PerPeerHeaderLen is the length of a per peer header
 	PerPeerHeaderLen = 42
+
+	// Peer types (RFC7854 4.2)
+	GlobalInstancePeer = 0
+	RDInstancePeer     = 1
+	LocalInstancePeer  = 2
+	LocRIBInstancePeer = 3 // RFC9069
+
+	// Peer flags (RFC7854 4.2)
+	PeerFlagV = 0b10000000 // IPv6 peer address
+	PeerFlagL = 0b01000000 // post-policy
+	PeerFlagA = 0b00100000 // legacy 2-byte AS_PATH format
+	PeerFlagO = 0b00010000 // adj-RIB-out (RFC8671)
+
+	// PeerFlagF marks a filtered Loc-RIB (RFC9069 4.2)
+	PeerFlagF = 0b10000000
 )
 
 // PerPeerHeader represents a BMP per peer header
@@ -28,12 +44,24 @@ type PerPeerHeader struct {
 func (p *PerPeerHeader) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(p.PeerType)
 	buf.WriteByte(p.PeerFlags)
-	buf.Write(convert.Uint64Byte(p.PeerDistinguisher))
+	endian.WriteUint64(buf, p.PeerDistinguisher)
 	buf.Write(p.PeerAddress[:])
-	buf.Write(convert.Uint32Byte(p.PeerAS))
-	buf.Write(convert.Uint32Byte(p.PeerBGPID))
-	buf.Write(convert.Uint32Byte(p.Timestamp))
-	buf.Write(convert.Uint32Byte(p.TimestampMicroSeconds))
+	endian.WriteUint32(buf, p.PeerAS)
+	endian.WriteUint32(buf, p.PeerBGPID)
+	endian.WriteUint32(buf, p.Timestamp)
+	endian.WriteUint32(buf, p.TimestampMicroSeconds)
+}
+
+// SetPeerAddress sets the peer address. IPv4 addresses are stored in the low-order 4 bytes.
+func (p *PerPeerHeader) SetPeerAddress(addr []byte) {
+	p.PeerAddress = [16]byte{}
+	copy(p.PeerAddress[16-len(addr):], addr)
+}
+
+// SetTimestamp sets the time the information was received or generated
+func (p *PerPeerHeader) SetTimestamp(t time.Time) {
+	p.Timestamp = uint32(t.Unix())
+	p.TimestampMicroSeconds = uint32(t.Nanosecond() / 1000)
 }
 
 func decodePerPeerHeader(buf *bytes.Buffer) (*PerPeerHeader, error) {
@@ -43,7 +71,7 @@ func decodePerPeerHeader(buf *bytes.Buffer) (*PerPeerHeader, error) {
 		&p.PeerType,
 		&p.PeerFlags,
 		&p.PeerDistinguisher,
-		&p.PeerAddress,
+		p.PeerAddress[:],
 		&p.PeerAS,
 		&p.PeerBGPID,
 		&p.Timestamp,
@@ -19,6 +19,15 @@ func (rm *RouteMonitoringMsg) MsgType() uint8 {
 	return rm.CommonHeader.MsgType
 }
 
+// Serialize serializes a route monitoring message
+func (rm *RouteMonitoringMsg) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	rm.PerPeerHeader.Serialize(body)
+	body.Write(rm.BGPUpdate)
+
+	serializeMsg(buf, RouteMonitoringType, body.Bytes())
+}
+
 func decodeRouteMonitoringMsg(buf *bytes.Buffer, ch *CommonHeader) (*RouteMonitoringMsg, error) {
 	rm := &RouteMonitoringMsg{
 		CommonHeader: ch,
@@ -4,9 +4,60 @@ import (
 	"bytes"
 
 	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
+// Statistics types (RFC7854 4.8)
+const (
+	StatRejectedPrefixes        = 0
+	StatDuplicatePrefixes       = 1
+	StatDuplicateWithdraws      = 2
+	StatClusterListLoops        = 3
+	StatASPathLoops             = 4
+	StatOriginatorIDLoops       = 5
+	StatASConfedLoops           = 6
+	StatAdjRIBInRoutes          = 7
+	StatLocRIBRoutes            = 8
+	StatAdjRIBInRoutesPerAFI    = 9
+	StatLocRIBRoutesPerAFI      = 10
+	StatUpdatesTreatAsWithdraw  = 11
+	StatPrefixesTreatAsWithdraw = 12
+	StatDuplicateUpdates        = 13
+
+	// Adj-RIB-out statistics (RFC8671 6)
+	StatAdjRIBOutPrePolicyRoutes        = 14
+	StatAdjRIBOutPostPolicyRoutes       = 15
+	StatAdjRIBOutPrePolicyRoutesPerAFI  = 16
+	StatAdjRIBOutPostPolicyRoutesPerAFI = 17
+)
+
+// NewGaugeStat creates a 64 bit gauge statistic of type t
+func NewGaugeStat(t uint16, v uint64) *InformationTLV {
+	buf := &bytes.Buffer{}
+	endian.WriteUint64(buf, v)
+
+	return NewInformationTLV(t, buf.Bytes())
+}
+
+// NewAFIGaugeStat creates a 64 bit gauge statistic of type t for an AFI/SAFI
+func NewAFIGaugeStat(t uint16, afi uint16, safi uint8, v uint64) *InformationTLV {
+	buf := &bytes.Buffer{}
+	endian.WriteUint16(buf, afi)
+	buf.WriteByte(safi)
+	endian.WriteUint64(buf, v)
+
+	return NewInformationTLV(t, buf.Bytes())
+}
+
+// NewCounterStat creates a 32 bit counter statistic of type t
+func NewCounterStat(t uint16, v uint32) *InformationTLV {
+	buf := &bytes.Buffer{}
+	endian.WriteUint32(buf, v)
+
+	return NewInformationTLV(t, buf.Bytes())
+}
+
 // StatsReport represents a stats report message
 type StatsReport struct {
 	CommonHeader  *CommonHeader
@@ -20,6 +71,18 @@ func (s *StatsReport) MsgType() uint8 {
 	return s.CommonHeader.MsgType
 }
 
+// Serialize serializes a stats report
+func (s *StatsReport) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	s.PerPeerHeader.Serialize(body)
+	endian.WriteUint32(body, uint32(len(s.Stats)))
+	for _, stat := range s.Stats {
+		stat.Serialize(body)
+	}
+
+	serializeMsg(buf, StatisticsReportType, body.Bytes())
+}
+
 func decodeStatsReport(buf *bytes.Buffer, ch *CommonHeader) (Msg, error) {
 	sr := &StatsReport{
 		CommonHeader: ch,
@@ -17,6 +17,16 @@ func (t *TerminationMessage) MsgType() uint8 {
 	return t.CommonHeader.MsgType
 }
 
+// Serialize serializes a termination message
+func (t *TerminationMessage) Serialize(buf *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	for _, tlv := range t.TLVs {
+		tlv.Serialize(body)
+	}
+
+	serializeMsg(buf, TerminationMessageType, body.Bytes())
+}
+
 func decodeTerminationMessage(buf *bytes.Buffer, ch *CommonHeader) (*TerminationMessage, error) {
 	tm := &TerminationMessage{
 		CommonHeader: ch,
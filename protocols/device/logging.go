@@ -0,0 +1,7 @@
+package device
+
+import (
+	"github.com/bio-routing/bio-rd/util/logging"
+)
+
+var log = logging.Subsystem("device")
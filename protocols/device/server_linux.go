@@ -3,7 +3,6 @@ package device
 import (
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
 )
 
@@ -0,0 +1,108 @@
+package flowspec
+
+import (
+	"math"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+// Traffic filtering action extended communities (RFC8955 7)
+const (
+	typeTrafficAction                = 0x80
+	typeRedirectIPv4Address          = 0x81
+	typeRedirectFourOctetAS          = 0x82
+	subTypeTrafficRateBytes          = 0x06
+	subTypeTrafficAction             = 0x07
+	subTypeRedirect                  = 0x08
+	subTypeTrafficMarking            = 0x09
+	subTypeTrafficRatePackets        = 0x0c
+	trafficActionSample       uint64 = 0x02
+	trafficActionTerminal     uint64 = 0x01
+
+	// typeRedirectIP is the redirect to IP next hop extended community (draft-ietf-idr-flowspec-redirect-ip).
+	// The next hop is taken from the MP_REACH_NLRI attribute.
+	typeRedirectIP    = 0x08
+	subTypeRedirectIP = 0x00
+)
+
+// Actions are the traffic filtering actions of a FlowSpec rule
+type Actions struct {
+	// RateBytes and RatePackets limit the traffic to bytes/packets per second. A rate of 0 discards all traffic.
+	RateBytes   *float32
+	RatePackets *float32
+
+	// Sample enables logging of the matched traffic
+	Sample bool
+
+	// Continue applies rules of lower precedence to the traffic as well (terminal action bit)
+	Continue bool
+
+	// RedirectVRF is the route target of the VRF traffic is redirected to
+	RedirectVRF *types.ExtendedCommunity
+
+	// RedirectIP is the next hop traffic is redirected to
+	RedirectIP *bnet.IP
+
+	// DSCP is the DSCP traffic is marked with
+	DSCP *uint8
+}
+
+// Discard returns if all traffic matched is dropped
+func (a *Actions) Discard() bool {
+	return (a.RateBytes != nil && *a.RateBytes == 0) || (a.RatePackets != nil && *a.RatePackets == 0)
+}
+
+// actions gets the traffic filtering actions of the extended communities of a rule. Of multiple rates the lowest one
+// is used.
+func actions(r *server.FlowSpecRule) *Actions {
+	a := &Actions{}
+	for _, c := range r.ExtendedCommunities {
+		switch {
+		case c.Type() == typeTrafficAction && c.SubType() == subTypeTrafficRateBytes:
+			a.RateBytes = lowerRate(a.RateBytes, rate(c))
+		case c.Type() == typeTrafficAction && c.SubType() == subTypeTrafficRatePackets:
+			a.RatePackets = lowerRate(a.RatePackets, rate(c))
+		case c.Type() == typeTrafficAction && c.SubType() == subTypeTrafficAction:
+			a.Sample = uint64(c)&trafficActionSample != 0
+			a.Continue = uint64(c)&trafficActionTerminal != 0
+		case c.SubType() == subTypeRedirect && (c.Type() == typeTrafficAction || c.Type() == typeRedirectIPv4Address || c.Type() == typeRedirectFourOctetAS):
+			rt := redirectRouteTarget(c)
+			a.RedirectVRF = &rt
+		case c.Type() == typeRedirectIP && c.SubType() == subTypeRedirectIP:
+			a.RedirectIP = r.NextHop
+		case c.Type() == typeTrafficAction && c.SubType() == subTypeTrafficMarking:
+			dscp := uint8(c) & 0x3f
+			a.DSCP = &dscp
+		}
+	}
+
+	return a
+}
+
+func rate(c types.ExtendedCommunity) float32 {
+	return math.Float32frombits(uint32(c))
+}
+
+func lowerRate(current *float32, r float32) *float32 {
+	if current != nil && *current <= r {
+		return current
+	}
+
+	return &r
+}
+
+// redirectRouteTarget gets the route target of a redirect extended community which shares the encoding of the
+// administrator and assigned number with the route target of the same type
+func redirectRouteTarget(c types.ExtendedCommunity) types.ExtendedCommunity {
+	t := uint64(types.ExtendedCommunityTypeTwoOctetAS)
+	switch c.Type() {
+	case typeRedirectIPv4Address:
+		t = types.ExtendedCommunityTypeIPv4Address
+	case typeRedirectFourOctetAS:
+		t = types.ExtendedCommunityTypeFourOctetAS
+	}
+
+	return types.ExtendedCommunity(t<<56 | uint64(types.ExtendedCommunitySubTypeRouteTarget)<<48 | uint64(c)&0xffffffffffff)
+}
@@ -0,0 +1,129 @@
+package flowspec
+
+import (
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/pkg/errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTable is the default nftables table FlowSpec rules are installed into
+	DefaultTable = "bio-rd-flowspec"
+)
+
+// Config is the configuration of the FlowSpec enforcement
+type Config struct {
+	// Table is the nftables table (family inet) rules are installed into. The table is owned by the enforcer.
+	Table string
+
+	// VRFMarks maps route targets of redirect to VRF actions to firewall marks. Policy routing rules have to steer
+	// marked traffic into the routing table of the VRF.
+	VRFMarks map[types.ExtendedCommunity]uint32
+
+	// NextHopMarks maps next hops of redirect to IP actions to firewall marks. Policy routing rules have to steer
+	// marked traffic towards the next hop.
+	NextHopMarks map[bnet.IP]uint32
+}
+
+// firewall applies nftables rulesets
+type firewall interface {
+	apply(ruleset string) error
+}
+
+// Enforcer installs the FlowSpec rules received via BGP (RFC8955, RFC8956) as nftables rules. Rules are installed in
+// order of precedence and the whole table is replaced atomically on each change, so withdrawn rules are removed.
+type Enforcer struct {
+	cfg      Config
+	fw       firewall
+	mu       sync.Mutex
+	rules    []*server.FlowSpecRule
+	updateCh chan struct{}
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates a new FlowSpec enforcer
+func New(cfg Config) (*Enforcer, error) {
+	fw, err := newFirewall()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to initialize firewall")
+	}
+
+	return newEnforcer(cfg, fw), nil
+}
+
+func newEnforcer(cfg Config, fw firewall) *Enforcer {
+	if cfg.Table == "" {
+		cfg.Table = DefaultTable
+	}
+
+	return &Enforcer{
+		cfg:      cfg,
+		fw:       fw,
+		updateCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start starts installing rules
+func (e *Enforcer) Start() {
+	e.wg.Add(1)
+	go e.run()
+}
+
+// Stop stops installing rules and removes all installed rules
+func (e *Enforcer) Stop() {
+	close(e.stopCh)
+	e.wg.Wait()
+
+	err := e.fw.apply(e.deleteTable())
+	if err != nil {
+		log.WithError(err).Error("Unable to remove FlowSpec rules")
+	}
+}
+
+// UpdateFlowSpec replaces the rules to install. Rules are installed asynchronously, intermediate states may be skipped.
+func (e *Enforcer) UpdateFlowSpec(rules []*server.FlowSpecRule) {
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+
+	select {
+	case e.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+func (e *Enforcer) run() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-e.updateCh:
+			e.install()
+		}
+	}
+}
+
+func (e *Enforcer) install() {
+	e.mu.Lock()
+	rules := e.rules
+	e.mu.Unlock()
+
+	ruleset, errs := e.ruleset(rules)
+	for _, err := range errs {
+		log.WithError(err).Warning("Skipping FlowSpec rule")
+	}
+
+	err := e.fw.apply(ruleset)
+	if err != nil {
+		log.WithError(err).Error("Unable to install FlowSpec rules")
+	}
+}
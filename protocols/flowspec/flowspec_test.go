@@ -0,0 +1,220 @@
+package flowspec
+
+import (
+	"sync"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type firewallMock struct {
+	mu       sync.Mutex
+	rulesets []string
+}
+
+func (f *firewallMock) apply(ruleset string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rulesets = append(f.rulesets, ruleset)
+	return nil
+}
+
+func (f *firewallMock) last() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.rulesets) == 0 {
+		return ""
+	}
+
+	return f.rulesets[len(f.rulesets)-1]
+}
+
+func dstPrefix(addr bnet.IP, l uint8) *packet.FlowSpecComponent {
+	return &packet.FlowSpecComponent{
+		Type:   packet.FlowSpecDestinationPrefix,
+		Prefix: bnet.NewPfx(addr, l).Ptr(),
+	}
+}
+
+func operators(t uint8, ops ...packet.FlowSpecOperator) *packet.FlowSpecComponent {
+	return &packet.FlowSpecComponent{
+		Type:      t,
+		Operators: ops,
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	rt, _ := types.NewRouteTarget(65000, 100)
+	nextHop := bnet.IPv4FromOctets(192, 0, 2, 1)
+
+	tests := []struct {
+		name       string
+		afi        uint16
+		components []*packet.FlowSpecComponent
+		coms       types.ExtendedCommunities
+		nextHop    *bnet.IP
+		wantFail   bool
+		expected   []string
+	}{
+		{
+			name: "Discard",
+			afi:  packet.IPv4AFI,
+			components: []*packet.FlowSpecComponent{
+				dstPrefix(bnet.IPv4FromOctets(10, 0, 1, 0), 24),
+				operators(packet.FlowSpecIPProtocol, packet.FlowSpecOperator{Op: packet.FlowSpecOpEqual, Value: 6}),
+				operators(packet.FlowSpecDestinationPort,
+					packet.FlowSpecOperator{Op: packet.FlowSpecOpGreater | packet.FlowSpecOpEqual, Value: 137},
+					packet.FlowSpecOperator{Op: packet.FlowSpecOpAnd | packet.FlowSpecOpLess | packet.FlowSpecOpEqual, Value: 139},
+					packet.FlowSpecOperator{Op: packet.FlowSpecOpEqual, Value: 8080},
+				),
+			},
+			coms: types.ExtendedCommunities{0x8006000000000000},
+			expected: []string{
+				`meta nfproto ipv4 ip daddr 10.0.1.0/24 meta l4proto 6 meta l4proto { 6, 17 } th dport { 137-139, 8080 } drop comment "dst 10.0.1.0/24 proto =6 dport >=137&<=139|=8080"`,
+			},
+		},
+		{
+			name: "Rate limit and marking on any port",
+			afi:  packet.IPv4AFI,
+			components: []*packet.FlowSpecComponent{
+				operators(packet.FlowSpecPort, packet.FlowSpecOperator{Op: packet.FlowSpecOpEqual, Value: 53}),
+			},
+			coms: types.ExtendedCommunities{
+				0x8006000044fa0000, // 2000 bytes/s
+				0x8009000000000012,
+			},
+			expected: []string{
+				`meta nfproto ipv4 meta l4proto { 6, 17 } th dport 53 ip dscp set 18 limit rate over 2000 bytes/second drop comment "port =53"`,
+				`meta nfproto ipv4 meta l4proto { 6, 17 } th dport 53 ip dscp set 18 accept comment "port =53"`,
+				`meta nfproto ipv4 meta l4proto { 6, 17 } th sport 53 ip dscp set 18 limit rate over 2000 bytes/second drop comment "port =53"`,
+				`meta nfproto ipv4 meta l4proto { 6, 17 } th sport 53 ip dscp set 18 accept comment "port =53"`,
+			},
+		},
+		{
+			name: "Redirect to VRF",
+			afi:  packet.IPv6AFI,
+			components: []*packet.FlowSpecComponent{
+				dstPrefix(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+				operators(packet.FlowSpecTCPFlags, packet.FlowSpecOperator{Op: packet.FlowSpecOpMatch, Value: 0x02}, packet.FlowSpecOperator{Op: packet.FlowSpecOpAnd | packet.FlowSpecOpNot, Value: 0xfd}),
+			},
+			coms: types.ExtendedCommunities{0x8008fde800000064},
+			expected: []string{
+				`meta nfproto ipv6 ip6 daddr 2001:DB8:0:0:0:0:0:0/32 tcp flags 2 meta mark set 0x00000064 accept comment "dst 2001:DB8:0:0:0:0:0:0/32 tcp-flags =0x2&!0xfd"`,
+			},
+		},
+		{
+			name: "Redirect to IP with sampling and terminal action",
+			afi:  packet.IPv4AFI,
+			components: []*packet.FlowSpecComponent{
+				operators(packet.FlowSpecFragment, packet.FlowSpecOperator{Op: packet.FlowSpecOpMatch, Value: packet.FlowSpecFragmentIsFragment}),
+			},
+			coms:    types.ExtendedCommunities{0x0800000000000000, 0x8007000000000003},
+			nextHop: &nextHop,
+			expected: []string{
+				`meta nfproto ipv4 ip frag-off & 0x3fff == 0x2000 log prefix "flowspec: " meta mark set 0x000000c8 comment "fragment =0x2"`,
+				`meta nfproto ipv4 ip frag-off & 0x1fff != 0 ip frag-off & 0x2000 != 0 log prefix "flowspec: " meta mark set 0x000000c8 comment "fragment =0x2"`,
+				`meta nfproto ipv4 ip frag-off & 0x1fff != 0 ip frag-off & 0x2000 == 0 log prefix "flowspec: " meta mark set 0x000000c8 comment "fragment =0x2"`,
+			},
+		},
+		{
+			name: "No mark for redirect",
+			afi:  packet.IPv4AFI,
+			components: []*packet.FlowSpecComponent{
+				dstPrefix(bnet.IPv4FromOctets(10, 0, 1, 0), 24),
+			},
+			coms:     types.ExtendedCommunities{0x8008fde90000000a},
+			wantFail: true,
+		},
+		{
+			name: "Never matching component",
+			afi:  packet.IPv4AFI,
+			components: []*packet.FlowSpecComponent{
+				operators(packet.FlowSpecDSCP, packet.FlowSpecOperator{Op: packet.FlowSpecOpGreater, Value: 63}),
+			},
+			expected: []string{},
+		},
+	}
+
+	e := newEnforcer(Config{
+		VRFMarks: map[types.ExtendedCommunity]uint32{
+			rt: 100,
+		},
+		NextHopMarks: map[bnet.IP]uint32{
+			nextHop: 200,
+		},
+	}, &firewallMock{})
+
+	for _, test := range tests {
+		lines, err := e.translate(&server.FlowSpecRule{
+			Peer: bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+			NLRI: &packet.FlowSpecNLRI{
+				AFI:        test.afi,
+				Components: test.components,
+			},
+			NextHop:             test.nextHop,
+			ExtendedCommunities: test.coms,
+		})
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoError(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, lines, "Test %q", test.name)
+	}
+}
+
+func TestEnforcer(t *testing.T) {
+	fw := &firewallMock{}
+	e := newEnforcer(Config{}, fw)
+
+	e.UpdateFlowSpec([]*server.FlowSpecRule{
+		{
+			Peer: bnet.IPv4FromOctets(10, 0, 0, 2).Ptr(),
+			NLRI: &packet.FlowSpecNLRI{
+				AFI: packet.IPv4AFI,
+				Components: []*packet.FlowSpecComponent{
+					dstPrefix(bnet.IPv4FromOctets(10, 0, 1, 0), 24),
+				},
+			},
+			ExtendedCommunities: types.ExtendedCommunities{0x8006000000000000},
+		},
+	})
+
+	expected := `table inet bio-rd-flowspec
+delete table inet bio-rd-flowspec
+table inet bio-rd-flowspec {
+	chain prerouting {
+		type filter hook prerouting priority -150; policy accept;
+		meta nfproto ipv4 ip daddr 10.0.1.0/24 drop comment "dst 10.0.1.0/24"
+	}
+}
+`
+	e.install()
+	assert.Equal(t, expected, fw.last())
+
+	// Withdrawn rules are removed with the next update
+	e.UpdateFlowSpec(nil)
+	e.install()
+	assert.Equal(t, `table inet bio-rd-flowspec
+delete table inet bio-rd-flowspec
+table inet bio-rd-flowspec {
+	chain prerouting {
+		type filter hook prerouting priority -150; policy accept;
+	}
+}
+`, fw.last())
+
+	e.Start()
+	e.Stop()
+	assert.Equal(t, "table inet bio-rd-flowspec\ndelete table inet bio-rd-flowspec\n", fw.last())
+}
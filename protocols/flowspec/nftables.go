@@ -0,0 +1,395 @@
+package flowspec
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	"github.com/bio-routing/bio-rd/protocols/bgp/server"
+)
+
+const (
+	// chainPriority is the priority of the mangle hook so marks set are considered by policy routing
+	chainPriority = -150
+
+	maxCommentLen = 128
+
+	// transportProtocols are the protocols port components match on (RFC8955 4.2.2.4)
+	transportProtocols = "meta l4proto { 6, 17 }"
+)
+
+// ruleset renders the nftables ruleset replacing the table of the enforcer. Rules are added in order of precedence.
+// Rules that can not be translated are skipped and returned as errors.
+func (e *Enforcer) ruleset(rules []*server.FlowSpecRule) (string, []error) {
+	var errs []error
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "table inet %s\n", e.cfg.Table)
+	fmt.Fprintf(b, "delete table inet %s\n", e.cfg.Table)
+	fmt.Fprintf(b, "table inet %s {\n", e.cfg.Table)
+	fmt.Fprintf(b, "\tchain prerouting {\n")
+	fmt.Fprintf(b, "\t\ttype filter hook prerouting priority %d; policy accept;\n", chainPriority)
+
+	for _, r := range rules {
+		lines, err := e.translate(r)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to install FlowSpec rule %q from %s: %v", r.NLRI.String(), r.Peer.String(), err))
+			continue
+		}
+
+		for _, l := range lines {
+			fmt.Fprintf(b, "\t\t%s\n", l)
+		}
+	}
+
+	fmt.Fprintf(b, "\t}\n")
+	fmt.Fprintf(b, "}\n")
+
+	return b.String(), errs
+}
+
+// deleteTable renders the commands removing the table of the enforcer
+func (e *Enforcer) deleteTable() string {
+	return fmt.Sprintf("table inet %s\ndelete table inet %s\n", e.cfg.Table, e.cfg.Table)
+}
+
+// translate translates a rule into nftables rules. Components matching one of multiple fields (e.g. port) result in
+// a rule for each field.
+func (e *Enforcer) translate(r *server.FlowSpecRule) ([]string, error) {
+	afi := r.NLRI.AFI
+	nfproto := "ipv4"
+	if afi == packet.IPv6AFI {
+		nfproto = "ipv6"
+	}
+
+	matches := []string{"meta nfproto " + nfproto}
+	for _, c := range r.NLRI.Components {
+		alternatives, err := matchExpressions(c, afi)
+		if err != nil {
+			return nil, err
+		}
+
+		matches = expand(matches, alternatives)
+	}
+
+	a := actions(r)
+	statements, err := e.statements(a, afi)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := fmt.Sprintf("comment %q", truncate(r.NLRI.String(), maxCommentLen))
+	ret := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if a.Discard() {
+			ret = append(ret, join(m, logStatement(a), "drop", comment))
+			continue
+		}
+
+		if a.RateBytes != nil {
+			ret = append(ret, join(m, statements, fmt.Sprintf("limit rate over %d bytes/second drop", roundRate(*a.RateBytes)), comment))
+		}
+
+		if a.RatePackets != nil {
+			ret = append(ret, join(m, statements, fmt.Sprintf("limit rate over %d/second drop", roundRate(*a.RatePackets)), comment))
+		}
+
+		if a.Continue {
+			if statements != "" && a.RateBytes == nil && a.RatePackets == nil {
+				ret = append(ret, join(m, statements, comment))
+			}
+
+			continue
+		}
+
+		ret = append(ret, join(m, statements, "accept", comment))
+	}
+
+	return ret, nil
+}
+
+// statements gets the statements of the actions other than rate limits
+func (e *Enforcer) statements(a *Actions, afi uint16) (string, error) {
+	family := "ip"
+	if afi == packet.IPv6AFI {
+		family = "ip6"
+	}
+
+	s := []string{logStatement(a)}
+	if a.RedirectVRF != nil {
+		mark, ok := e.cfg.VRFMarks[*a.RedirectVRF]
+		if !ok {
+			return "", fmt.Errorf("No mark configured for redirect to VRF %s", a.RedirectVRF.String())
+		}
+
+		s = append(s, fmt.Sprintf("meta mark set 0x%08x", mark))
+	}
+
+	if a.RedirectIP != nil {
+		mark, ok := e.cfg.NextHopMarks[*a.RedirectIP]
+		if !ok {
+			return "", fmt.Errorf("No mark configured for redirect to %s", a.RedirectIP.String())
+		}
+
+		s = append(s, fmt.Sprintf("meta mark set 0x%08x", mark))
+	}
+
+	if a.DSCP != nil {
+		s = append(s, fmt.Sprintf("%s dscp set %d", family, *a.DSCP))
+	}
+
+	return join(s...), nil
+}
+
+func logStatement(a *Actions) string {
+	if !a.Sample {
+		return ""
+	}
+
+	return `log prefix "flowspec: "`
+}
+
+// matchExpressions gets the alternative expressions matching a component. An empty expression matches all packets,
+// no expression matches no packet.
+func matchExpressions(c *packet.FlowSpecComponent, afi uint16) ([]string, error) {
+	family := "ip"
+	icmp := "icmp"
+	if afi == packet.IPv6AFI {
+		family = "ip6"
+		icmp = "icmpv6"
+	}
+
+	switch c.Type {
+	case packet.FlowSpecDestinationPrefix, packet.FlowSpecSourcePrefix:
+		if c.Offset > 0 {
+			return nil, fmt.Errorf("Prefix offsets are not supported")
+		}
+
+		field := "daddr"
+		if c.Type == packet.FlowSpecSourcePrefix {
+			field = "saddr"
+		}
+
+		return []string{fmt.Sprintf("%s %s %s", family, field, c.Prefix.String())}, nil
+	case packet.FlowSpecIPProtocol:
+		return numericMatch("meta l4proto", c, math.MaxUint8), nil
+	case packet.FlowSpecPort:
+		return append(
+			numericMatch(transportProtocols+" th dport", c, math.MaxUint16),
+			numericMatch(transportProtocols+" th sport", c, math.MaxUint16)...,
+		), nil
+	case packet.FlowSpecDestinationPort:
+		return numericMatch(transportProtocols+" th dport", c, math.MaxUint16), nil
+	case packet.FlowSpecSourcePort:
+		return numericMatch(transportProtocols+" th sport", c, math.MaxUint16), nil
+	case packet.FlowSpecICMPType:
+		return numericMatch(icmp+" type", c, math.MaxUint8), nil
+	case packet.FlowSpecICMPCode:
+		return numericMatch(icmp+" code", c, math.MaxUint8), nil
+	case packet.FlowSpecTCPFlags:
+		return bitmaskMatch("tcp flags", c, math.MaxUint8), nil
+	case packet.FlowSpecPacketLength:
+		return numericMatch("meta length", c, math.MaxUint16), nil
+	case packet.FlowSpecDSCP:
+		return numericMatch(family+" dscp", c, 0x3f), nil
+	case packet.FlowSpecFragment:
+		if afi != packet.IPv4AFI {
+			return nil, fmt.Errorf("Fragment matching is only supported for IPv4")
+		}
+
+		return fragmentMatch(c), nil
+	case packet.FlowSpecFlowLabel:
+		if afi != packet.IPv6AFI {
+			return nil, fmt.Errorf("Flow labels are only defined for IPv6")
+		}
+
+		return numericMatch("ip6 flowlabel", c, 0xfffff), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported component type %d", c.Type)
+}
+
+// numericMatch matches the values from 0 to max matched by the operators of a numeric component
+func numericMatch(field string, c *packet.FlowSpecComponent, max uint64) []string {
+	return setMatch(field, numericRanges(c, max), max)
+}
+
+// numericRanges gets the ranges of values from 0 to max matched by a numeric component. The result of the operators
+// only changes at their values, so evaluating one value per range in between is sufficient.
+func numericRanges(c *packet.FlowSpecComponent, max uint64) [][2]uint64 {
+	points := make([]uint64, 0, len(c.Operators))
+	for _, o := range c.Operators {
+		if o.Value <= max {
+			points = append(points, o.Value)
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i] < points[j]
+	})
+
+	ret := make([][2]uint64, 0)
+	add := func(from uint64, to uint64) {
+		if !c.Matches(from) {
+			return
+		}
+
+		if len(ret) > 0 && ret[len(ret)-1][1]+1 == from {
+			ret[len(ret)-1][1] = to
+			return
+		}
+
+		ret = append(ret, [2]uint64{from, to})
+	}
+
+	next := uint64(0)
+	for i, p := range points {
+		if i > 0 && p == points[i-1] {
+			continue
+		}
+
+		if p > next {
+			add(next, p-1)
+		}
+
+		add(p, p)
+		next = p + 1
+	}
+
+	if next <= max {
+		add(next, max)
+	}
+
+	return ret
+}
+
+// bitmaskMatch matches the values from 0 to max matched by the operators of a bitmask component
+func bitmaskMatch(field string, c *packet.FlowSpecComponent, max uint64) []string {
+	values := make([][2]uint64, 0)
+	for v := uint64(0); v <= max; v++ {
+		if c.Matches(v) {
+			values = append(values, [2]uint64{v, v})
+		}
+	}
+
+	if len(values) == int(max)+1 {
+		return []string{""}
+	}
+
+	return setMatch(field, values, max)
+}
+
+func setMatch(field string, ranges [][2]uint64, max uint64) []string {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	if len(ranges) == 1 && ranges[0][0] == 0 && ranges[0][1] == max {
+		return []string{""}
+	}
+
+	elements := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r[0] == r[1] {
+			elements = append(elements, fmt.Sprintf("%d", r[0]))
+			continue
+		}
+
+		elements = append(elements, fmt.Sprintf("%d-%d", r[0], r[1]))
+	}
+
+	if len(elements) == 1 {
+		return []string{field + " " + elements[0]}
+	}
+
+	return []string{fmt.Sprintf("%s { %s }", field, strings.Join(elements, ", "))}
+}
+
+// fragmentMatch matches the IPv4 fragmentation states (not fragmented, first, middle and last fragment) with and
+// without the don't fragment bit matched by a fragment component
+func fragmentMatch(c *packet.FlowSpecComponent) []string {
+	states := []struct {
+		value uint64
+		expr  string
+	}{
+		{
+			value: 0,
+			expr:  "ip frag-off & 0x3fff == 0",
+		},
+		{
+			value: packet.FlowSpecFragmentIsFragment | packet.FlowSpecFragmentFirst,
+			expr:  "ip frag-off & 0x3fff == 0x2000",
+		},
+		{
+			value: packet.FlowSpecFragmentIsFragment,
+			expr:  "ip frag-off & 0x1fff != 0 ip frag-off & 0x2000 != 0",
+		},
+		{
+			value: packet.FlowSpecFragmentIsFragment | packet.FlowSpecFragmentLast,
+			expr:  "ip frag-off & 0x1fff != 0 ip frag-off & 0x2000 == 0",
+		},
+	}
+
+	ret := make([]string, 0)
+	all := true
+	for _, s := range states {
+		withoutDF := c.Matches(s.value)
+		withDF := c.Matches(s.value | packet.FlowSpecFragmentDontFragment)
+		all = all && withoutDF && withDF
+
+		switch {
+		case withoutDF && withDF:
+			ret = append(ret, s.expr)
+		case withoutDF:
+			ret = append(ret, "ip frag-off & 0x4000 == 0 "+s.expr)
+		case withDF:
+			ret = append(ret, "ip frag-off & 0x4000 != 0 "+s.expr)
+		}
+	}
+
+	if all {
+		return []string{""}
+	}
+
+	return ret
+}
+
+// expand combines all matches with all alternatives
+func expand(matches []string, alternatives []string) []string {
+	ret := make([]string, 0, len(matches)*len(alternatives))
+	for _, m := range matches {
+		for _, a := range alternatives {
+			ret = append(ret, join(m, a))
+		}
+	}
+
+	return ret
+}
+
+func join(parts ...string) string {
+	ret := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+
+	return strings.Join(ret, " ")
+}
+
+func truncate(s string, l int) string {
+	if len(s) <= l {
+		return s
+	}
+
+	return s[:l]
+}
+
+func roundRate(r float32) uint64 {
+	if r < 1 {
+		return 1
+	}
+
+	return uint64(math.Ceil(float64(r)))
+}
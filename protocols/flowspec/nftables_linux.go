@@ -0,0 +1,36 @@
+package flowspec
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// nftables applies rulesets using the nft utility. A ruleset is applied in a single transaction.
+type nftables struct {
+	path string
+}
+
+func newFirewall() (firewall, error) {
+	path, err := exec.LookPath("nft")
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to find nft")
+	}
+
+	return &nftables{
+		path: path,
+	}, nil
+}
+
+func (n *nftables) apply(ruleset string) error {
+	cmd := exec.Command(n.path, "-f", "-")
+	cmd.Stdin = strings.NewReader(ruleset)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "nft failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
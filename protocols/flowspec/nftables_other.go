@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package flowspec
+
+import "errors"
+
+func newFirewall() (firewall, error) {
+	return nil, errors.New("FlowSpec enforcement is only implemented for Linux")
+}
@@ -0,0 +1,66 @@
+package interfaces
+
+import (
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/device"
+)
+
+// Config holds the protocol independent settings of an interface
+type Config struct {
+	Name          string
+	Cost          uint32
+	HelloInterval time.Duration
+	HoldTime      time.Duration
+	Passive       bool
+	BFD           bool
+}
+
+// Interface represents the state of a managed interface
+type Interface struct {
+	Config Config
+	Up     bool
+	Index  uint64
+	MTU    uint16
+	Addrs  []*bnet.Prefix
+}
+
+func newInterface(cfg Config) *Interface {
+	return &Interface{
+		Config: cfg,
+		Addrs:  make([]*bnet.Prefix, 0),
+	}
+}
+
+func (i *Interface) copy() *Interface {
+	n := &Interface{
+		Config: i.Config,
+		Up:     i.Up,
+		Index:  i.Index,
+		MTU:    i.MTU,
+		Addrs:  make([]*bnet.Prefix, len(i.Addrs)),
+	}
+
+	copy(n.Addrs, i.Addrs)
+	return n
+}
+
+// hasAddr checks if pfx is configured on the interface
+func (i *Interface) hasAddr(pfx *bnet.Prefix) bool {
+	return hasAddr(i.Addrs, pfx)
+}
+
+func hasAddr(addrs []*bnet.Prefix, pfx *bnet.Prefix) bool {
+	for _, a := range addrs {
+		if a.Equal(pfx) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isUp(phy *device.Device) bool {
+	return phy.OperState == device.IfOperUp
+}
@@ -0,0 +1,286 @@
+package interfaces
+
+import (
+	"fmt"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/device"
+)
+
+// Client is notified about interface and address state changes
+type Client interface {
+	InterfaceUp(*Interface)
+	InterfaceDown(*Interface)
+	AddressAdded(*Interface, *bnet.Prefix)
+	AddressRemoved(*Interface, *bnet.Prefix)
+}
+
+// Manager owns the per interface configuration and tracks interface and address
+// state learned from the device server. Protocols subscribe to the manager instead
+// of handling interfaces themselves. Clients are called without the managers state
+// lock held, so they may query the manager, but they must not subscribe, unsubscribe
+// or change interfaces from within a callback.
+type Manager struct {
+	ds device.Updater
+
+	// notifyMu serializes state changes with the delivery of their events, so clients see events in order.
+	// It is always acquired before mu.
+	notifyMu sync.Mutex
+
+	// guarded by mu
+	interfaces map[string]*managedInterface
+	clients    []Client
+	mu         sync.RWMutex
+}
+
+// managedInterface receives device updates for a single interface
+type managedInterface struct {
+	m     *Manager
+	iface *Interface
+}
+
+type eventType int
+
+const (
+	eventUp eventType = iota
+	eventDown
+	eventAddressAdded
+	eventAddressRemoved
+)
+
+// event is a client notification. Events are collected with mu held and delivered after releasing it.
+type event struct {
+	typ   eventType
+	iface *Interface
+	pfx   *bnet.Prefix
+}
+
+// NewManager creates a new interface manager
+func NewManager(ds device.Updater) *Manager {
+	return &Manager{
+		ds:         ds,
+		interfaces: make(map[string]*managedInterface),
+		clients:    make([]Client, 0),
+	}
+}
+
+// Subscribe registers a client for interface events. The client is informed about all interfaces being up
+// and their addresses immediately.
+func (m *Manager) Subscribe(c Client) {
+	m.notifyMu.Lock()
+	defer m.notifyMu.Unlock()
+
+	m.mu.Lock()
+	m.clients = append(m.clients, c)
+	events := make([]event, 0)
+	for _, mi := range m.interfaces {
+		if !mi.iface.Up {
+			continue
+		}
+
+		ifa := mi.iface.copy()
+		events = append(events, event{typ: eventUp, iface: ifa})
+		for _, a := range ifa.Addrs {
+			events = append(events, event{typ: eventAddressAdded, iface: ifa, pfx: a})
+		}
+	}
+	m.mu.Unlock()
+
+	deliver([]Client{c}, events)
+}
+
+// Unsubscribe unregisters a client. The client is not called anymore once Unsubscribe returned.
+func (m *Manager) Unsubscribe(c Client) {
+	m.notifyMu.Lock()
+	defer m.notifyMu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.clients {
+		if m.clients[i] != c {
+			continue
+		}
+
+		m.clients = append(m.clients[:i], m.clients[i+1:]...)
+		return
+	}
+}
+
+// AddInterface puts an interface under management
+func (m *Manager) AddInterface(cfg Config) error {
+	m.mu.Lock()
+	if _, exists := m.interfaces[cfg.Name]; exists {
+		m.mu.Unlock()
+		return fmt.Errorf("Interface %q exists already", cfg.Name)
+	}
+
+	mi := &managedInterface{
+		m:     m,
+		iface: newInterface(cfg),
+	}
+	m.interfaces[cfg.Name] = mi
+	m.mu.Unlock()
+
+	m.ds.Subscribe(mi, cfg.Name)
+	return nil
+}
+
+// UpdateInterface replaces the config of a managed interface
+func (m *Manager) UpdateInterface(cfg Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mi, exists := m.interfaces[cfg.Name]
+	if !exists {
+		return fmt.Errorf("Interface %q not found", cfg.Name)
+	}
+
+	mi.iface.Config = cfg
+	return nil
+}
+
+// RemoveInterface removes an interface from management. If the interface was up clients are notified it went down.
+func (m *Manager) RemoveInterface(name string) error {
+	m.notifyMu.Lock()
+	m.mu.Lock()
+	mi, exists := m.interfaces[name]
+	if !exists {
+		m.mu.Unlock()
+		m.notifyMu.Unlock()
+		return fmt.Errorf("Interface %q not found", name)
+	}
+
+	delete(m.interfaces, name)
+	events := make([]event, 0, 1)
+	if mi.iface.Up {
+		mi.iface.Up = false
+		events = append(events, event{typ: eventDown, iface: mi.iface.copy()})
+	}
+	clients := m.clientsCopy()
+	m.mu.Unlock()
+
+	deliver(clients, events)
+	m.notifyMu.Unlock()
+
+	m.ds.Unsubscribe(mi, name)
+	return nil
+}
+
+// GetInterface gets a copy of a managed interfaces state
+func (m *Manager) GetInterface(name string) *Interface {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mi, exists := m.interfaces[name]
+	if !exists {
+		return nil
+	}
+
+	return mi.iface.copy()
+}
+
+// ListInterfaces gets copies of all managed interfaces states
+func (m *Manager) ListInterfaces() []*Interface {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ret := make([]*Interface, 0, len(m.interfaces))
+	for _, mi := range m.interfaces {
+		ret = append(ret, mi.iface.copy())
+	}
+
+	return ret
+}
+
+// DeviceUpdate receives interface state from the device server and notifies clients about changes
+func (mi *managedInterface) DeviceUpdate(phy *device.Device) {
+	m := mi.m
+	m.notifyMu.Lock()
+	defer m.notifyMu.Unlock()
+
+	m.mu.Lock()
+	events := mi.update(phy)
+	clients := m.clientsCopy()
+	m.mu.Unlock()
+
+	deliver(clients, events)
+}
+
+// update applies device state to the interface and returns the resulting events. Must be called with m.mu held.
+func (mi *managedInterface) update(phy *device.Device) []event {
+	ifa := mi.iface
+	ifa.Index = phy.Index
+	ifa.MTU = phy.MTU
+
+	up := isUp(phy)
+	if !up {
+		ifa.Addrs = append(ifa.Addrs[:0], phy.Addrs...)
+		if !ifa.Up {
+			return nil
+		}
+
+		ifa.Up = false
+		return []event{{typ: eventDown, iface: ifa.copy()}}
+	}
+
+	removed := make([]*bnet.Prefix, 0)
+	for _, a := range ifa.Addrs {
+		if !hasAddr(phy.Addrs, a) {
+			removed = append(removed, a)
+		}
+	}
+
+	added := make([]*bnet.Prefix, 0)
+	for _, a := range phy.Addrs {
+		if !ifa.hasAddr(a) || !ifa.Up {
+			added = append(added, a)
+		}
+	}
+
+	ifa.Addrs = append(ifa.Addrs[:0], phy.Addrs...)
+	wasUp := ifa.Up
+	ifa.Up = true
+
+	c := ifa.copy()
+	events := make([]event, 0, len(removed)+len(added)+1)
+	if wasUp {
+		for _, a := range removed {
+			events = append(events, event{typ: eventAddressRemoved, iface: c, pfx: a})
+		}
+	} else {
+		events = append(events, event{typ: eventUp, iface: c})
+	}
+
+	for _, a := range added {
+		events = append(events, event{typ: eventAddressAdded, iface: c, pfx: a})
+	}
+
+	return events
+}
+
+// clientsCopy gets a copy of the subscribed clients. Must be called with m.mu held.
+func (m *Manager) clientsCopy() []Client {
+	ret := make([]Client, len(m.clients))
+	copy(ret, m.clients)
+	return ret
+}
+
+// deliver calls clients for all events
+func deliver(clients []Client, events []event) {
+	for _, e := range events {
+		for _, c := range clients {
+			switch e.typ {
+			case eventUp:
+				c.InterfaceUp(e.iface)
+			case eventDown:
+				c.InterfaceDown(e.iface)
+			case eventAddressAdded:
+				c.AddressAdded(e.iface, e.pfx)
+			case eventAddressRemoved:
+				c.AddressRemoved(e.iface, e.pfx)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package interfaces
+
+import (
+	"fmt"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/device"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	events []string
+}
+
+func (c *mockClient) InterfaceUp(i *Interface) {
+	c.events = append(c.events, fmt.Sprintf("up %s", i.Config.Name))
+}
+
+func (c *mockClient) InterfaceDown(i *Interface) {
+	c.events = append(c.events, fmt.Sprintf("down %s", i.Config.Name))
+}
+
+func (c *mockClient) AddressAdded(i *Interface, pfx *bnet.Prefix) {
+	c.events = append(c.events, fmt.Sprintf("add %s %s", i.Config.Name, pfx.String()))
+}
+
+func (c *mockClient) AddressRemoved(i *Interface, pfx *bnet.Prefix) {
+	c.events = append(c.events, fmt.Sprintf("del %s %s", i.Config.Name, pfx.String()))
+}
+
+func TestDeviceUpdates(t *testing.T) {
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 1), 24).Ptr()
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 1), 24).Ptr()
+
+	tests := []struct {
+		name     string
+		updates  []*device.Device
+		expected []string
+	}{
+		{
+			name: "Interface comes up with address",
+			updates: []*device.Device{
+				{
+					Name:      "eth0",
+					OperState: device.IfOperUp,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+			},
+			expected: []string{
+				"up eth0",
+				"add eth0 192.0.2.1/24",
+			},
+		},
+		{
+			name: "Address change on up interface",
+			updates: []*device.Device{
+				{
+					Name:      "eth0",
+					OperState: device.IfOperUp,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+				{
+					Name:      "eth0",
+					OperState: device.IfOperUp,
+					Addrs:     []*bnet.Prefix{pfxB},
+				},
+			},
+			expected: []string{
+				"up eth0",
+				"add eth0 192.0.2.1/24",
+				"del eth0 192.0.2.1/24",
+				"add eth0 198.51.100.1/24",
+			},
+		},
+		{
+			name: "Interface flaps",
+			updates: []*device.Device{
+				{
+					Name:      "eth0",
+					OperState: device.IfOperUp,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+				{
+					Name:      "eth0",
+					OperState: device.IfOperDown,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+				{
+					Name:      "eth0",
+					OperState: device.IfOperDown,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+				{
+					Name:      "eth0",
+					OperState: device.IfOperUp,
+					Addrs:     []*bnet.Prefix{pfxA},
+				},
+			},
+			expected: []string{
+				"up eth0",
+				"add eth0 192.0.2.1/24",
+				"down eth0",
+				"up eth0",
+				"add eth0 192.0.2.1/24",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		m := NewManager(&device.MockServer{})
+		c := &mockClient{}
+		m.Subscribe(c)
+
+		err := m.AddInterface(Config{
+			Name: "eth0",
+			Cost: 10,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		mi := m.interfaces["eth0"]
+		for _, u := range test.updates {
+			mi.DeviceUpdate(u)
+		}
+
+		assert.Equalf(t, test.expected, c.events, "Test %q", test.name)
+	}
+}
+
+func TestSubscribeAndRemove(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 1), 24).Ptr()
+	ds := &device.MockServer{}
+	m := NewManager(ds)
+
+	err := m.AddInterface(Config{Name: "eth0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = m.AddInterface(Config{Name: "eth0"})
+	assert.Error(t, err, "Adding duplicate interface")
+
+	m.interfaces["eth0"].DeviceUpdate(&device.Device{
+		Name:      "eth0",
+		Index:     3,
+		OperState: device.IfOperUp,
+		Addrs:     []*bnet.Prefix{pfx},
+	})
+
+	c := &mockClient{}
+	m.Subscribe(c)
+	assert.Equal(t, []string{"up eth0", "add eth0 192.0.2.1/24"}, c.events)
+	assert.Equal(t, uint64(3), m.GetInterface("eth0").Index)
+
+	err = m.UpdateInterface(Config{Name: "eth0", Passive: true})
+	assert.NoError(t, err)
+	assert.Equal(t, true, m.GetInterface("eth0").Config.Passive)
+
+	err = m.RemoveInterface("eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up eth0", "add eth0 192.0.2.1/24", "down eth0"}, c.events)
+	assert.Equal(t, true, ds.UnsubscribeCalled)
+	assert.Nil(t, m.GetInterface("eth0"))
+
+	err = m.RemoveInterface("eth0")
+	assert.Error(t, err, "Removing unknown interface")
+}
+
+// queryingClient calls back into the manager from its callbacks
+type queryingClient struct {
+	m   *Manager
+	mtu []uint16
+}
+
+func (c *queryingClient) InterfaceUp(i *Interface) {
+	c.mtu = append(c.mtu, c.m.GetInterface(i.Config.Name).MTU)
+}
+
+func (c *queryingClient) InterfaceDown(i *Interface) {
+	c.mtu = append(c.mtu, c.m.ListInterfaces()[0].MTU)
+}
+
+func (c *queryingClient) AddressAdded(i *Interface, pfx *bnet.Prefix) {}
+
+func (c *queryingClient) AddressRemoved(i *Interface, pfx *bnet.Prefix) {}
+
+func TestClientCallsManager(t *testing.T) {
+	m := NewManager(&device.MockServer{})
+	c := &queryingClient{m: m}
+	m.Subscribe(c)
+
+	err := m.AddInterface(Config{Name: "eth0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	m.interfaces["eth0"].DeviceUpdate(&device.Device{
+		Name:      "eth0",
+		MTU:       1500,
+		OperState: device.IfOperUp,
+	})
+	m.interfaces["eth0"].DeviceUpdate(&device.Device{
+		Name:      "eth0",
+		MTU:       9000,
+		OperState: device.IfOperDown,
+	})
+
+	assert.Equal(t, []uint16{1500, 9000}, c.mtu)
+}
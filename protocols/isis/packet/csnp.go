@@ -8,8 +8,8 @@ import (
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
 	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
 	umath "github.com/bio-routing/bio-rd/util/math"
-	"github.com/bio-routing/tflow2/convert"
 	"github.com/pkg/errors"
 )
 
@@ -126,7 +126,7 @@ func (c *CSNP) GetLSPEntries() []*LSPEntry {
 
 // Serialize serializes CSNPs
 func (c *CSNP) Serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(c.PDULength))
+	endian.WriteUint16(buf, c.PDULength)
 	buf.Write(c.SourceID.Serialize())
 	c.StartLSPID.Serialize(buf)
 	c.EndLSPID.Serialize(buf)
@@ -142,11 +142,12 @@ func DecodeCSNP(buf *bytes.Buffer) (*CSNP, error) {
 
 	fields := []interface{}{
 		&csnp.PDULength,
-		&csnp.SourceID,
-		&csnp.StartLSPID.SystemID,
+		csnp.SourceID.SystemID[:],
+		&csnp.SourceID.CircuitID,
+		csnp.StartLSPID.SystemID[:],
 		&csnp.StartLSPID.PseudonodeID,
 		&csnp.StartLSPID.LSPNumber,
-		&csnp.EndLSPID.SystemID,
+		csnp.EndLSPID.SystemID[:],
 		&csnp.EndLSPID.PseudonodeID,
 		&csnp.EndLSPID.LSPNumber,
 	}
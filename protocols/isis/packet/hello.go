@@ -6,7 +6,7 @@ import (
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 // L2Hello represents a broadcast L2 hello
@@ -91,8 +91,8 @@ func (h *P2PHello) GetIPInterfaceAddressesesTLV() *IPInterfaceAddressesTLV {
 func (h *P2PHello) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(h.CircuitType)
 	buf.Write(h.SystemID[:])
-	buf.Write(convert.Uint16Byte(h.HoldingTimer))
-	buf.Write(convert.Uint16Byte(h.PDULength))
+	endian.WriteUint16(buf, h.HoldingTimer)
+	endian.WriteUint16(buf, h.PDULength)
 	buf.WriteByte(h.LocalCircuitID)
 
 	for _, TLV := range h.TLVs {
@@ -106,7 +106,7 @@ func DecodeP2PHello(buf *bytes.Buffer) (*P2PHello, error) {
 
 	fields := []interface{}{
 		&pdu.CircuitType,
-		&pdu.SystemID,
+		pdu.SystemID[:],
 		&pdu.HoldingTimer,
 		&pdu.PDULength,
 		&pdu.LocalCircuitID,
@@ -132,12 +132,12 @@ func DecodeL2Hello(buf *bytes.Buffer) (*L2Hello, error) {
 	reserved := uint8(0)
 	fields := []interface{}{
 		&pdu.CircuitType,
-		&pdu.SystemID,
+		pdu.SystemID[:],
 		&pdu.HoldingTimer,
 		&pdu.PDULength,
 		&pdu.Priority,
 		&reserved,
-		&pdu.DesignatedIS,
+		pdu.DesignatedIS[:],
 	}
 
 	err := decode.Decode(buf, fields)
@@ -5,15 +5,15 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
+	"github.com/bio-routing/bio-rd/util/bufpool"
+	"github.com/bio-routing/bio-rd/util/checksum"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/bio-rd/util/math"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
 	LSPIDLen    = 8
 	LSPDUMinLen = 19
-	MODX        = 5802
 )
 
 // LSPID represents a Link State Packet ID
@@ -76,55 +76,21 @@ func (l *LSPDU) updateLength() {
 	}
 }
 
-func csum(input []byte) uint16 {
-	x := 0
-	y := 0
-	c0 := 0
-	c1 := 0
-	partialLen := 0
-	i := 0
-	left := len(input)
-
-	for left != 0 {
-		partialLen = math.Min(left, MODX)
-
-		for i = 0; i < partialLen; i++ {
-			c0 = c0 + int(input[i])
-			c1 += c0
-		}
-
-		c0 = c0 % 255
-		c1 = c1 % 255
-
-		left -= partialLen
-	}
-
-	z := ((len(input)-12-1)*c0 - c1)
-	x = int(z % 255)
-
-	if x < 0 {
-		x += 255
-	}
-
-	y = 510 - c0 - x
-	if y > 255 {
-		y -= 255
-	}
-	return (uint16(x) << 8) | (uint16(y) & 0xFF)
-}
-
 // SetChecksum sets the checksum of an LSPDU
 func (l *LSPDU) SetChecksum() {
-	buf := bytes.NewBuffer(nil)
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+
+	l.Checksum = 0
 	l.SerializeChecksumRelevant(buf)
-	l.Checksum = csum(buf.Bytes())
+	l.Checksum = checksum.Fletcher16(buf.Bytes(), LSPIDLen+4)
 }
 
 // SerializeChecksumRelevant serializes all fields after the Remaining Lifetime field.
 func (l *LSPDU) SerializeChecksumRelevant(buf *bytes.Buffer) {
 	l.LSPID.Serialize(buf)
-	buf.Write(convert.Uint32Byte(l.SequenceNumber))
-	buf.Write(convert.Uint16Byte(l.Checksum))
+	endian.WriteUint32(buf, l.SequenceNumber)
+	endian.WriteUint16(buf, l.Checksum)
 	buf.WriteByte(l.TypeBlock)
 
 	for _, TLV := range l.TLVs {
@@ -134,8 +100,8 @@ func (l *LSPDU) SerializeChecksumRelevant(buf *bytes.Buffer) {
 
 // Serialize serializes a linke state PDU
 func (l *LSPDU) Serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(l.Length))
-	buf.Write(convert.Uint16Byte(l.RemainingLifetime))
+	endian.WriteUint16(buf, l.Length)
+	endian.WriteUint16(buf, l.RemainingLifetime)
 	l.SerializeChecksumRelevant(buf)
 }
 
@@ -146,7 +112,7 @@ func DecodeLSPDU(buf *bytes.Buffer) (*LSPDU, error) {
 	fields := []interface{}{
 		&pdu.Length,
 		&pdu.RemainingLifetime,
-		&pdu.LSPID.SystemID,
+		pdu.LSPID.SystemID[:],
 		&pdu.LSPID.PseudonodeID,
 		&pdu.LSPID.LSPNumber,
 		&pdu.SequenceNumber,
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
@@ -23,10 +23,10 @@ type LSPEntry struct {
 
 // Serialize serializes an LSPEntry
 func (l *LSPEntry) Serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(l.RemainingLifetime))
+	endian.WriteUint16(buf, l.RemainingLifetime)
 	l.LSPID.Serialize(buf)
-	buf.Write(convert.Uint32Byte(l.SequenceNumber))
-	buf.Write(convert.Uint16Byte(l.LSPChecksum))
+	endian.WriteUint32(buf, l.SequenceNumber)
+	endian.WriteUint16(buf, l.LSPChecksum)
 }
 
 func decodeLSPEntry(buf *bytes.Buffer) (*LSPEntry, error) {
@@ -34,7 +34,7 @@ func decodeLSPEntry(buf *bytes.Buffer) (*LSPEntry, error) {
 
 	fields := []interface{}{
 		&lspEntry.RemainingLifetime,
-		&lspEntry.LSPID.SystemID,
+		lspEntry.LSPID.SystemID[:],
 		&lspEntry.LSPID.PseudonodeID,
 		&lspEntry.LSPID.LSPNumber,
 		&lspEntry.SequenceNumber,
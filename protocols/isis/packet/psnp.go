@@ -7,8 +7,8 @@ import (
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
 	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
 	umath "github.com/bio-routing/bio-rd/util/math"
-	"github.com/bio-routing/tflow2/convert"
 )
 
 const (
@@ -62,7 +62,7 @@ func newPSNP(sourceID types.SourceID, lspEntries []*LSPEntry) *PSNP {
 
 // Serialize serializes PSNPs
 func (c *PSNP) Serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint16Byte(c.PDULength))
+	endian.WriteUint16(buf, c.PDULength)
 	buf.Write(c.SourceID.Serialize())
 	NewLSPEntriesTLV(c.LSPEntries).Serialize(buf)
 }
@@ -73,7 +73,8 @@ func DecodePSNP(buf *bytes.Buffer) (*PSNP, error) {
 
 	fields := []interface{}{
 		&psnp.PDULength,
-		&psnp.SourceID,
+		psnp.SourceID.SystemID[:],
+		&psnp.SourceID.CircuitID,
 	}
 
 	err := decode.Decode(buf, fields)
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 // ChecksumTLVType is the type value of a checksum TLV
@@ -55,5 +55,5 @@ func readChecksumTLV(buf *bytes.Buffer, tlvType uint8, tlvLength uint8) (*Checks
 func (c *ChecksumTLV) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(c.TLVType)
 	buf.WriteByte(c.TLVLength)
-	buf.Write(convert.Uint16Byte(c.Checksum))
+	endian.WriteUint16(buf, c.Checksum)
 }
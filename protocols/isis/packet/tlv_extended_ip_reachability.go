@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 	"github.com/pkg/errors"
 )
 
@@ -90,9 +90,9 @@ type ExtendedIPReachability struct {
 
 // Serialize serializes an ExtendedIPReachability
 func (e *ExtendedIPReachability) Serialize(buf *bytes.Buffer) {
-	buf.Write(convert.Uint32Byte(e.Metric))
+	endian.WriteUint32(buf, e.Metric)
 	buf.WriteByte(e.UDSubBitPfxLen)
-	buf.Write(convert.Uint32Byte(e.Address))
+	endian.WriteUint32(buf, e.Address)
 
 	for i := range e.SubTLVs {
 		e.SubTLVs[i].Serialize(buf)
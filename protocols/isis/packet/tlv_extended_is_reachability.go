@@ -4,7 +4,7 @@ import (
 	"bytes"
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
@@ -121,8 +121,8 @@ func (l *LinkLocalRemoteIdentifiersSubTLV) Value() interface{} {
 func (l *LinkLocalRemoteIdentifiersSubTLV) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(l.TLVType)
 	buf.WriteByte(l.TLVLength)
-	buf.Write(convert.Uint32Byte(l.Local))
-	buf.Write(convert.Uint32Byte(l.Remote))
+	endian.WriteUint32(buf, l.Local)
+	endian.WriteUint32(buf, l.Remote)
 }
 
 // NewLinkLocalRemoteIdentifiersSubTLV creates a new LinkLocalRemoteIdentifiersSubTLV
@@ -161,7 +161,7 @@ func (s *IPv4AddressSubTLV) Value() interface{} {
 func (s *IPv4AddressSubTLV) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(s.TLVType)
 	buf.WriteByte(s.TLVLength)
-	buf.Write(convert.Uint32Byte(s.Address))
+	endian.WriteUint32(buf, s.Address)
 }
 
 // NewIPv4InterfaceAddressSubTLV creates a new IPv4 Interface Address Sub TLV
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 // IPInterfaceAddressesTLVType is the type value of an IP interface address TLV
@@ -67,6 +67,6 @@ func (i IPInterfaceAddressesTLV) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(i.TLVType)
 	buf.WriteByte(i.TLVLength)
 	for j := range i.IPv4Addresses {
-		buf.Write(convert.Uint32Byte(i.IPv4Addresses[j]))
+		endian.WriteUint32(buf, i.IPv4Addresses[j])
 	}
 }
@@ -27,7 +27,7 @@ func readISNeighborsTLV(buf *bytes.Buffer, tlvType uint8, tlvLength uint8) (*ISN
 		TLVLength: tlvLength,
 	}
 	fields := []interface{}{
-		&pdu.NeighborSNPA,
+		pdu.NeighborSNPA[:],
 	}
 
 	err := decode.Decode(buf, fields)
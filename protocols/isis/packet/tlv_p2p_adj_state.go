@@ -6,7 +6,7 @@ import (
 
 	"github.com/bio-routing/bio-rd/protocols/isis/types"
 	"github.com/bio-routing/bio-rd/util/decode"
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 )
 
 const (
@@ -45,7 +45,7 @@ func readP2PAdjacencyStateTLV(buf *bytes.Buffer, tlvType uint8, tlvLength uint8)
 		fields = []interface{}{
 			&pdu.AdjacencyState,
 			&pdu.ExtendedLocalCircuitID,
-			&pdu.NeighborSystemID,
+			pdu.NeighborSystemID[:],
 			&pdu.NeighborExtendedLocalCircuitID,
 		}
 	}
@@ -88,10 +88,10 @@ func (p P2PAdjacencyStateTLV) Serialize(buf *bytes.Buffer) {
 	buf.WriteByte(p.TLVType)
 	buf.WriteByte(p.TLVLength)
 	buf.WriteByte(p.AdjacencyState)
-	buf.Write(convert.Uint32Byte(p.ExtendedLocalCircuitID))
+	endian.WriteUint32(buf, p.ExtendedLocalCircuitID)
 
 	if p.TLVLength == P2PAdjacencyStateTLVLenWithNeighbor {
 		buf.Write(p.NeighborSystemID[:])
-		buf.Write(convert.Uint32Byte(p.NeighborExtendedLocalCircuitID))
+		endian.WriteUint32(buf, p.NeighborExtendedLocalCircuitID)
 	}
 }
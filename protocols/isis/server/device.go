@@ -8,8 +8,6 @@ import (
 	"github.com/bio-routing/bio-rd/protocols/device"
 	"github.com/bio-routing/bio-rd/protocols/isis/packet"
 	"github.com/pkg/errors"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type dev struct {
@@ -97,7 +95,7 @@ func (d *dev) enable() error {
 	go d.helloMethod()
 
 	log.Infof("ISIS: Interface %q is now up", d.name)
-	d.up = true
+	d.setUp(true, "interface up")
 	return nil
 }
 
@@ -110,10 +108,26 @@ func (d *dev) disable() error {
 	}
 
 	d.wg.Wait()
-	d.up = false
+	d.setUp(false, "interface down")
 	return nil
 }
 
+func (d *dev) setUp(up bool, reason string) {
+	if d.up != up && d.srv != nil {
+		d.srv.flightRecorder.Recorder("isis", d.name).Record(ifStateName(d.up), ifStateName(up), reason)
+	}
+
+	d.up = up
+}
+
+func ifStateName(up bool) string {
+	if up {
+		return "up"
+	}
+
+	return "down"
+}
+
 func (d *dev) receiverRoutine() {
 	// To be implemented
 }
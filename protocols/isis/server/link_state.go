@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"sort"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	bgppacket "github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/isis/packet"
+	"github.com/bio-routing/bio-rd/protocols/isis/types"
+)
+
+// linkStateSource is the name the topology is exported via BGP-LS under
+const linkStateSource = "isis"
+
+// LinkStateExporter exports an IGP topology via BGP-LS
+type LinkStateExporter interface {
+	UpdateLinkState(source string, entries []*bgpserver.LinkStateEntry)
+}
+
+// SetLinkStateExporter sets the exporter the topology of the LSDB is advertised to via BGP-LS (RFC7752), nil disables the export
+func (s *Server) SetLinkStateExporter(e LinkStateExporter) {
+	if s.linkStateExporter != nil && e == nil {
+		s.linkStateExporter.UpdateLinkState(linkStateSource, nil)
+	}
+
+	s.linkStateExporter = e
+	s.lsdb.exportLinkState()
+}
+
+// exportLinkState advertises the topology to the BGP-LS exporter of the server if there is one
+func (l *lsdb) exportLinkState() {
+	if l.srv == nil || l.srv.linkStateExporter == nil {
+		return
+	}
+
+	l.srv.linkStateExporter.UpdateLinkState(linkStateSource, l.linkStateEntries())
+}
+
+// linkStateEntries converts the LSDB into BGP-LS node, link and prefix NLRIs (RFC7752). Fragments of an LSP are merged.
+func (l *lsdb) linkStateEntries() []*bgpserver.LinkStateEntry {
+	l.lspsMu.RLock()
+	defer l.lspsMu.RUnlock()
+
+	ids := make([]packet.LSPID, 0, len(l.lsps))
+	for id := range l.lsps {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if c := ids[i].Compare(ids[j]); c != 0 {
+			return c < 0
+		}
+
+		return ids[i].LSPNumber < ids[j].LSPNumber
+	})
+
+	ret := make([]*bgpserver.LinkStateEntry, 0)
+	var node *bgpserver.LinkStateEntry
+	for _, id := range ids {
+		if node == nil || id.LSPNumber == 0 || !bytes.Equal(node.NLRI.LocalNode.IGPRouterID, linkStateRouterID(types.NewSourceID(id.SystemID, id.PseudonodeID))) {
+			node = linkStateNode(id)
+			ret = append(ret, node)
+		}
+
+		ret = append(ret, linkStateLSP(node, l.lsps[id].lspdu)...)
+	}
+
+	return ret
+}
+
+// linkStateRouterID gets the IGP router ID of a node: The system ID, followed by the circuit ID for pseudonodes (RFC7752 3.2.1.4)
+func linkStateRouterID(id types.SourceID) []byte {
+	if id.CircuitID == 0 {
+		return append([]byte(nil), id.SystemID[:]...)
+	}
+
+	return id.Serialize()
+}
+
+func linkStateNodeDescriptor(id types.SourceID) bgppacket.LinkStateNodeDescriptor {
+	return bgppacket.LinkStateNodeDescriptor{
+		IGPRouterID: linkStateRouterID(id),
+	}
+}
+
+func linkStateNode(id packet.LSPID) *bgpserver.LinkStateEntry {
+	return &bgpserver.LinkStateEntry{
+		NLRI: &bgppacket.LinkStateNLRI{
+			Type:       bgppacket.LinkStateNodeNLRI,
+			ProtocolID: bgppacket.LinkStateProtocolISISL2,
+			LocalNode:  linkStateNodeDescriptor(types.NewSourceID(id.SystemID, id.PseudonodeID)),
+		},
+	}
+}
+
+// linkStateLSP adds the node attributes of an LSP to node and gets its links and prefixes
+func linkStateLSP(node *bgpserver.LinkStateEntry, lsp *packet.LSPDU) []*bgpserver.LinkStateEntry {
+	ret := make([]*bgpserver.LinkStateEntry, 0)
+	for _, tlv := range lsp.TLVs {
+		switch tlv := tlv.(type) {
+		case *packet.DynamicHostNameTLV:
+			node.Attribute = append(node.Attribute, bgppacket.LinkStateTLV{
+				Type:  bgppacket.LinkStateNodeName,
+				Value: tlv.Hostname,
+			})
+		case *packet.AreaAddressesTLV:
+			for _, a := range tlv.AreaIDs {
+				node.Attribute = append(node.Attribute, bgppacket.LinkStateTLV{
+					Type:  bgppacket.LinkStateISISAreaID,
+					Value: a,
+				})
+			}
+		case *packet.TrafficEngineeringRouterIDTLV:
+			node.Attribute = append(node.Attribute, bgppacket.LinkStateTLV{
+				Type:  bgppacket.LinkStateIPv4RouterID,
+				Value: tlv.Address[:],
+			})
+		case *packet.ExtendedISReachabilityTLV:
+			for _, n := range tlv.Neighbors {
+				ret = append(ret, linkStateLink(node, n))
+			}
+		case *packet.ExtendedIPReachabilityTLV:
+			for _, r := range tlv.ExtendedIPReachabilities {
+				ret = append(ret, linkStatePrefix(node, r))
+			}
+		}
+	}
+
+	return ret
+}
+
+func linkStateLink(node *bgpserver.LinkStateEntry, n *packet.ExtendedISReachabilityNeighbor) *bgpserver.LinkStateEntry {
+	nlri := &bgppacket.LinkStateNLRI{
+		Type:       bgppacket.LinkStateLinkNLRI,
+		ProtocolID: node.NLRI.ProtocolID,
+		LocalNode:  node.NLRI.LocalNode,
+		RemoteNode: linkStateNodeDescriptor(n.NeighborID),
+	}
+
+	for _, tlv := range n.SubTLVs {
+		switch tlv := tlv.(type) {
+		case *packet.LinkLocalRemoteIdentifiersSubTLV:
+			nlri.Link.LocalID = tlv.Local
+			nlri.Link.RemoteID = tlv.Remote
+		case *packet.IPv4AddressSubTLV:
+			addr := bnet.IPv4(tlv.Address).Dedup()
+			switch tlv.TLVType {
+			case packet.IPv4InterfaceAddressSubTLVType:
+				nlri.Link.InterfaceAddr = addr
+			case packet.IPv4NeighborAddressSubTLVType:
+				nlri.Link.NeighborAddr = addr
+			}
+		}
+	}
+
+	return &bgpserver.LinkStateEntry{
+		NLRI: nlri,
+		Attribute: bgppacket.LinkStateAttribute{
+			{
+				Type:  bgppacket.LinkStateIGPMetric,
+				Value: append([]byte(nil), n.Metric[:]...),
+			},
+		},
+	}
+}
+
+func linkStatePrefix(node *bgpserver.LinkStateEntry, r *packet.ExtendedIPReachability) *bgpserver.LinkStateEntry {
+	return &bgpserver.LinkStateEntry{
+		NLRI: &bgppacket.LinkStateNLRI{
+			Type:       bgppacket.LinkStateIPv4PrefixNLRI,
+			ProtocolID: node.NLRI.ProtocolID,
+			LocalNode:  node.NLRI.LocalNode,
+			Prefix: bgppacket.LinkStatePrefixDescriptor{
+				Prefix: bnet.NewPfx(bnet.IPv4(r.Address), r.PfxLen()).Dedup(),
+			},
+		},
+		Attribute: bgppacket.LinkStateAttribute{
+			bgppacket.NewLinkStateTLVUint32(bgppacket.LinkStatePrefixMetric, r.Metric),
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	bgppacket "github.com/bio-routing/bio-rd/protocols/bgp/packet"
+	bgpserver "github.com/bio-routing/bio-rd/protocols/bgp/server"
+	"github.com/bio-routing/bio-rd/protocols/isis/packet"
+	"github.com/bio-routing/bio-rd/protocols/isis/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLinkStateExporter struct {
+	source  string
+	entries []*bgpserver.LinkStateEntry
+}
+
+func (m *mockLinkStateExporter) UpdateLinkState(source string, entries []*bgpserver.LinkStateEntry) {
+	m.source = source
+	m.entries = entries
+}
+
+func TestLinkStateEntries(t *testing.T) {
+	neighbor := packet.NewExtendedISReachabilityNeighbor(types.NewSourceID(types.SystemID{2, 2, 2, 2, 2, 2}, 0), [3]byte{0, 0, 10})
+	neighbor.AddSubTLV(packet.NewLinkLocalRemoteIdentifiersSubTLV(1, 2))
+	neighbor.AddSubTLV(packet.NewIPv4InterfaceAddressSubTLV(bnet.IPv4FromOctets(192, 0, 2, 1).Ptr().ToUint32()))
+	neighbor.AddSubTLV(packet.NewIPv4NeighborAddressSubTLV(bnet.IPv4FromOctets(192, 0, 2, 2).Ptr().ToUint32()))
+
+	isReach := packet.NewExtendedISReachabilityTLV()
+	isReach.Neighbors = append(isReach.Neighbors, neighbor)
+
+	ipReach := packet.NewExtendedIPReachabilityTLV()
+	ipReach.ExtendedIPReachabilities = append(ipReach.ExtendedIPReachabilities, &packet.ExtendedIPReachability{
+		Metric:         20,
+		UDSubBitPfxLen: 24,
+		Address:        bnet.IPv4FromOctets(10, 0, 0, 0).Ptr().ToUint32(),
+	})
+
+	l := &lsdb{
+		srv: &Server{},
+		lsps: map[packet.LSPID]*lsdbEntry{
+			{
+				SystemID:  types.SystemID{1, 1, 1, 1, 1, 1},
+				LSPNumber: 0,
+			}: {
+				lspdu: &packet.LSPDU{
+					TLVs: []packet.TLV{
+						packet.NewDynamicHostnameTLV([]byte("r1")),
+						packet.NewAreaAddressesTLV([]types.AreaID{{0x49, 0, 1}}),
+						packet.NewTrafficEngineeringRouterIDTLV([4]byte{10, 0, 0, 1}),
+					},
+				},
+			},
+			{
+				SystemID:  types.SystemID{1, 1, 1, 1, 1, 1},
+				LSPNumber: 1,
+			}: {
+				lspdu: &packet.LSPDU{
+					TLVs: []packet.TLV{
+						isReach,
+						ipReach,
+					},
+				},
+			},
+		},
+	}
+
+	localNode := bgppacket.LinkStateNodeDescriptor{
+		IGPRouterID: []byte{1, 1, 1, 1, 1, 1},
+	}
+
+	expected := []*bgpserver.LinkStateEntry{
+		{
+			NLRI: &bgppacket.LinkStateNLRI{
+				Type:       bgppacket.LinkStateNodeNLRI,
+				ProtocolID: bgppacket.LinkStateProtocolISISL2,
+				LocalNode:  localNode,
+			},
+			Attribute: bgppacket.LinkStateAttribute{
+				{
+					Type:  bgppacket.LinkStateNodeName,
+					Value: []byte("r1"),
+				},
+				{
+					Type:  bgppacket.LinkStateISISAreaID,
+					Value: []byte{0x49, 0, 1},
+				},
+				{
+					Type:  bgppacket.LinkStateIPv4RouterID,
+					Value: []byte{10, 0, 0, 1},
+				},
+			},
+		},
+		{
+			NLRI: &bgppacket.LinkStateNLRI{
+				Type:       bgppacket.LinkStateLinkNLRI,
+				ProtocolID: bgppacket.LinkStateProtocolISISL2,
+				LocalNode:  localNode,
+				RemoteNode: bgppacket.LinkStateNodeDescriptor{
+					IGPRouterID: []byte{2, 2, 2, 2, 2, 2},
+				},
+				Link: bgppacket.LinkStateLinkDescriptor{
+					LocalID:       1,
+					RemoteID:      2,
+					InterfaceAddr: bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+					NeighborAddr:  bnet.IPv4FromOctets(192, 0, 2, 2).Dedup(),
+				},
+			},
+			Attribute: bgppacket.LinkStateAttribute{
+				{
+					Type:  bgppacket.LinkStateIGPMetric,
+					Value: []byte{0, 0, 10},
+				},
+			},
+		},
+		{
+			NLRI: &bgppacket.LinkStateNLRI{
+				Type:       bgppacket.LinkStateIPv4PrefixNLRI,
+				ProtocolID: bgppacket.LinkStateProtocolISISL2,
+				LocalNode:  localNode,
+				Prefix: bgppacket.LinkStatePrefixDescriptor{
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 24).Dedup(),
+				},
+			},
+			Attribute: bgppacket.LinkStateAttribute{
+				bgppacket.NewLinkStateTLVUint32(bgppacket.LinkStatePrefixMetric, 20),
+			},
+		},
+	}
+
+	assert.Equal(t, expected, l.linkStateEntries())
+
+	e := &mockLinkStateExporter{}
+	l.srv.lsdb = l
+	l.srv.SetLinkStateExporter(e)
+	assert.Equal(t, "isis", e.source)
+	assert.Equal(t, expected, e.entries)
+
+	l.srv.SetLinkStateExporter(nil)
+	assert.Equal(t, 0, len(e.entries), "Topology withdrawn on removal of the exporter")
+}
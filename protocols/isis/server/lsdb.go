@@ -49,23 +49,30 @@ func (l *lsdb) decrementRemainingLifetimesRoutine(t btime.Ticker) {
 	for {
 		select {
 		case <-t.C():
-			l.decrementRemainingLifetimes()
+			if l.decrementRemainingLifetimes() {
+				l.exportLinkState()
+			}
 		case <-l.done:
 			return
 		}
 	}
 }
 
-func (l *lsdb) decrementRemainingLifetimes() {
+// decrementRemainingLifetimes ages all LSPs and reports if any expired LSP was removed
+func (l *lsdb) decrementRemainingLifetimes() bool {
 	l.lspsMu.Lock()
 	defer l.lspsMu.Unlock()
 
+	removed := false
 	for lspid, lspdbEntry := range l.lsps {
 		if lspdbEntry.lspdu.RemainingLifetime <= 1 {
 			delete(l.lsps, lspid)
+			removed = true
 			continue
 		}
 
 		lspdbEntry.lspdu.RemainingLifetime--
 	}
+
+	return removed
 }
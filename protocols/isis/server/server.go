@@ -5,10 +5,11 @@ import (
 
 	"github.com/bio-routing/bio-rd/config"
 	"github.com/bio-routing/bio-rd/protocols/device"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
 	btime "github.com/bio-routing/bio-rd/util/time"
 )
 
-//Server represents an ISIS server
+// Server represents an ISIS server
 type Server struct {
 	config         *config.ISISConfig
 	sequenceNumber uint32
@@ -16,6 +17,9 @@ type Server struct {
 	lsdb           *lsdb
 	stop           chan struct{}
 	ds             device.Updater
+	flightRecorder *flightrecorder.Registry
+
+	linkStateExporter LinkStateExporter
 }
 
 func New(cfg *config.ISISConfig, ds device.Updater) *Server {
@@ -31,6 +35,14 @@ func New(cfg *config.ISISConfig, ds device.Updater) *Server {
 	return s
 }
 
+// SetFlightRecorder sets the flight recorder interface state transitions are recorded to
+func (s *Server) SetFlightRecorder(r *flightrecorder.Registry) {
+	r.SetUnexpected("isis", func(from string, to string) bool {
+		return from == "up"
+	})
+	s.flightRecorder = r
+}
+
 func (s *Server) start() {
 	s.lsdb.start(btime.NewBIOTicker(time.Second))
 }
@@ -1,24 +1,78 @@
 package kernel
 
 import (
+	"math"
+	"sync"
+	"time"
+
 	"github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/histogram"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxWeight is the maximum weight of a next hop supported by the kernel
+	maxWeight = 256
 )
 
 type Kernel struct {
-	osKernel osKernel
+	osKernel     osKernel
+	table        int
+	fibLatencyMu sync.Mutex
+	fibLatency   map[uint8]*histogram.Histogram
+
+	// Paths installed per prefix. All of them are installed as weighted ECMP route if the kernel is registered for
+	// ECMP paths. groups holds the next hops installed per prefix, so path changes not affecting them are not installed.
+	paths   map[net.Prefix][]*route.Path
+	groups  map[net.Prefix][]nextHop
+	pathsMu sync.Mutex
+
+	// Next hop groups (BGP PIC)
+	nhGroups      map[uint64][]nextHop
+	nhGroupRoutes map[uint64]map[net.Prefix]struct{}
+	routeNHGroups map[net.Prefix]uint64
+	nhGroupsMu    sync.Mutex
+}
+
+// Metrics provides metrics of the kernel routing table
+type Metrics struct {
+	// FIBProgrammingLatency is the time taken to install or remove routes by protocol
+	FIBProgrammingLatency map[string]histogram.Snapshot
+}
+
+// nextHop is a member of an ECMP route. Traffic is distributed among the next hops proportionally to their weights.
+type nextHop struct {
+	addr    *net.IP
+	ifIndex uint64 // interface the next hop is bound to (required for link-local next hops)
+	weight  uint16
 }
 
 type osKernel interface {
-	AddPath(pfx *net.Prefix, path *route.Path) error
-	RemovePath(pfx *net.Prefix, path *route.Path) bool
+	ReplaceRoute(pfx *net.Prefix, nextHops []nextHop) error
+	DeleteRoute(pfx *net.Prefix) error
 	uninit() error
 }
 
 func New() (*Kernel, error) {
-	k := &Kernel{}
+	return NewWithTable(0)
+}
+
+// NewWithTable creates a new Kernel instance installing routes into the given kernel routing table (0 = main table)
+func NewWithTable(table int) (*Kernel, error) {
+	k := &Kernel{
+		table:         table,
+		fibLatency:    make(map[uint8]*histogram.Histogram),
+		paths:         make(map[net.Prefix][]*route.Path),
+		groups:        make(map[net.Prefix][]nextHop),
+		nhGroups:      make(map[uint64][]nextHop),
+		nhGroupRoutes: make(map[uint64]map[net.Prefix]struct{}),
+		routeNHGroups: make(map[net.Prefix]uint64),
+	}
 	err := k.init()
 	if err != nil {
 		return nil, err
@@ -31,12 +85,200 @@ func (k *Kernel) AddPathInitialDump(pfx *net.Prefix, path *route.Path) error {
 	return k.AddPath(pfx, path)
 }
 
+// AddPath installs path for pfx. With multiple paths for pfx (multipath, ADD-PATH) the route is replaced by a weighted
+// ECMP route via all their next hops.
 func (k *Kernel) AddPath(pfx *net.Prefix, path *route.Path) error {
-	return k.osKernel.AddPath(pfx, path)
+	defer k.observeFIBLatency(path, time.Now())
+
+	k.pathsMu.Lock()
+	defer k.pathsMu.Unlock()
+
+	paths := k.paths[*pfx]
+	for _, p := range paths {
+		if p.Equal(path) {
+			return nil
+		}
+	}
+
+	paths = append(paths, path)
+	err := k.replaceGroup(pfx, ecmpGroup(paths))
+	if err != nil {
+		return err
+	}
+
+	k.paths[*pfx] = paths
+	return nil
+}
+
+// replaceGroup replaces the next hops of the route for pfx. The route is left untouched if they did not change.
+// pathsMu must be held.
+func (k *Kernel) replaceGroup(pfx *net.Prefix, group []nextHop) error {
+	if installed, exists := k.groups[*pfx]; exists && groupEqual(installed, group) {
+		return nil
+	}
+
+	err := k.osKernel.ReplaceRoute(pfx, group)
+	if err != nil {
+		return err
+	}
+
+	k.groups[*pfx] = group
+	return nil
 }
 
+// RemovePath removes path for pfx. The route is removed with its last path.
 func (k *Kernel) RemovePath(pfx *net.Prefix, path *route.Path) bool {
-	return k.osKernel.RemovePath(pfx, path)
+	defer k.observeFIBLatency(path, time.Now())
+
+	k.pathsMu.Lock()
+	defer k.pathsMu.Unlock()
+
+	paths := k.paths[*pfx]
+	remaining := make([]*route.Path, 0, len(paths))
+	for _, p := range paths {
+		if !p.Equal(path) {
+			remaining = append(remaining, p)
+		}
+	}
+
+	if len(remaining) == len(paths) {
+		return false
+	}
+
+	if len(remaining) == 0 {
+		delete(k.paths, *pfx)
+		delete(k.groups, *pfx)
+		err := k.osKernel.DeleteRoute(pfx)
+		if err != nil {
+			log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to remove route")
+			return false
+		}
+
+		return true
+	}
+
+	k.paths[*pfx] = remaining
+	err := k.replaceGroup(pfx, ecmpGroup(remaining))
+	if err != nil {
+		log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to replace route")
+		return false
+	}
+
+	return true
+}
+
+// ecmpGroup gets the weighted next hops of paths. If all paths carry a link bandwidth community next hops are weighted
+// by the sum of the bandwidths of their paths, otherwise by their number of paths.
+func ecmpGroup(paths []*route.Path) []nextHop {
+	res := make([]nextHop, 0, len(paths))
+	bandwidths := make([]float64, 0, len(paths))
+	useBandwidth := true
+	for _, p := range paths {
+		nh := p.NextHop()
+		if nh == nil {
+			continue
+		}
+
+		bw, ok := linkBandwidth(p)
+		useBandwidth = useBandwidth && ok
+
+		ifIndex := p.NextHopIfIndex()
+		i := 0
+		for i < len(res) && !(res[i].addr.Equal(nh) && res[i].ifIndex == ifIndex) {
+			i++
+		}
+
+		if i == len(res) {
+			res = append(res, nextHop{
+				addr:    nh,
+				ifIndex: ifIndex,
+			})
+			bandwidths = append(bandwidths, 0)
+		}
+
+		res[i].weight++
+		bandwidths[i] += float64(bw)
+	}
+
+	if useBandwidth {
+		weightByBandwidth(res, bandwidths)
+	}
+
+	for i := range res {
+		if res[i].weight > maxWeight {
+			res[i].weight = maxWeight
+		}
+	}
+
+	return res
+}
+
+// weightByBandwidth sets the weights of next hops proportionally to their bandwidths. The next hop with the highest
+// bandwidth gets the maximum weight.
+func weightByBandwidth(nextHops []nextHop, bandwidths []float64) {
+	max := float64(0)
+	for _, bw := range bandwidths {
+		max = math.Max(max, bw)
+	}
+
+	if max <= 0 {
+		return
+	}
+
+	for i := range nextHops {
+		nextHops[i].weight = uint16(math.Max(1, math.Round(bandwidths[i]/max*maxWeight)))
+	}
+}
+
+func linkBandwidth(p *route.Path) (float32, bool) {
+	if p.Type != route.BGPPathType || p.BGPPath == nil {
+		return 0, false
+	}
+
+	return p.BGPPath.ExtendedCommunities.LinkBandwidth()
+}
+
+func groupEqual(a, b []nextHop) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].addr.Equal(b[i].addr) || a[i].ifIndex != b[i].ifIndex || a[i].weight != b[i].weight {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (k *Kernel) observeFIBLatency(path *route.Path, start time.Time) {
+	k.fibLatencyMu.Lock()
+	defer k.fibLatencyMu.Unlock()
+
+	h, exists := k.fibLatency[path.Type]
+	if !exists {
+		h = &histogram.Histogram{}
+		k.fibLatency[path.Type] = h
+	}
+
+	h.Observe(time.Since(start))
+}
+
+// Metrics returns metrics of the kernel routing table
+func (k *Kernel) Metrics() *Metrics {
+	k.fibLatencyMu.Lock()
+	defer k.fibLatencyMu.Unlock()
+
+	m := &Metrics{
+		FIBProgrammingLatency: make(map[string]histogram.Snapshot, len(k.fibLatency)),
+	}
+
+	for t, h := range k.fibLatency {
+		m.FIBProgrammingLatency[route.PathTypeName(t)] = h.Snapshot()
+	}
+
+	return m
 }
 
 func (k *Kernel) UpdateNewClient(routingtable.RouteTableClient) error {
@@ -82,3 +324,95 @@ func (k *Kernel) ReplacePath(*net.Prefix, *route.Path, *route.Path) {
 func (k *Kernel) RefreshRoute(*net.Prefix, []*route.Path) {
 
 }
+
+// SetNextHopGroup adds a next hop group or updates its active next hops. The kernel has no notion of next hop groups
+// here, so all routes of the group are replaced. This still avoids path selection for each of them on failures.
+// Next hops are weighted by the number of active paths via them. Routes are left untouched if the next hops did not change.
+func (k *Kernel) SetNextHopGroup(g locRIB.NextHopGroup) {
+	k.nhGroupsMu.Lock()
+	defer k.nhGroupsMu.Unlock()
+
+	nextHops := make([]nextHop, 0, len(g.Active))
+	for _, n := range g.Active {
+		if n.Address == nil {
+			continue
+		}
+
+		i := 0
+		for i < len(nextHops) && !nextHops[i].addr.Equal(n.Address) {
+			i++
+		}
+
+		if i == len(nextHops) {
+			nextHops = append(nextHops, nextHop{
+				addr: n.Address,
+			})
+		}
+
+		if nextHops[i].weight < maxWeight {
+			nextHops[i].weight++
+		}
+	}
+
+	if installed, exists := k.nhGroups[g.ID]; exists && groupEqual(installed, nextHops) {
+		return
+	}
+
+	k.nhGroups[g.ID] = nextHops
+	for pfx := range k.nhGroupRoutes[g.ID] {
+		pfx := pfx
+		err := k.osKernel.ReplaceRoute(&pfx, nextHops)
+		if err != nil {
+			log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to update route to next hop group")
+		}
+	}
+}
+
+// RemoveNextHopGroup removes a next hop group
+func (k *Kernel) RemoveNextHopGroup(id uint64) {
+	k.nhGroupsMu.Lock()
+	defer k.nhGroupsMu.Unlock()
+
+	delete(k.nhGroups, id)
+	delete(k.nhGroupRoutes, id)
+}
+
+// SetRoute installs the route for pfx via the active next hops of next hop group id
+func (k *Kernel) SetRoute(pfx *net.Prefix, id uint64) {
+	k.nhGroupsMu.Lock()
+	defer k.nhGroupsMu.Unlock()
+
+	if old, exists := k.routeNHGroups[*pfx]; exists {
+		delete(k.nhGroupRoutes[old], *pfx)
+	}
+
+	k.routeNHGroups[*pfx] = id
+	if _, exists := k.nhGroupRoutes[id]; !exists {
+		k.nhGroupRoutes[id] = make(map[net.Prefix]struct{})
+	}
+	k.nhGroupRoutes[id][*pfx] = struct{}{}
+
+	err := k.osKernel.ReplaceRoute(pfx, k.nhGroups[id])
+	if err != nil {
+		log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to install route")
+	}
+}
+
+// RemoveRoute removes the route for pfx
+func (k *Kernel) RemoveRoute(pfx *net.Prefix) {
+	k.nhGroupsMu.Lock()
+	defer k.nhGroupsMu.Unlock()
+
+	id, exists := k.routeNHGroups[*pfx]
+	if !exists {
+		return
+	}
+
+	delete(k.routeNHGroups, *pfx)
+	delete(k.nhGroupRoutes[id], *pfx)
+
+	err := k.osKernel.DeleteRoute(pfx)
+	if err != nil {
+		log.WithError(err).WithField("prefix", pfx.String()).Error("Unable to remove route")
+	}
+}
@@ -2,11 +2,10 @@ package kernel
 
 import (
 	"github.com/bio-routing/bio-rd/net"
-	"github.com/bio-routing/bio-rd/route"
 	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
-
-	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -14,7 +13,7 @@ const (
 )
 
 func (k *Kernel) init() error {
-	lk, err := newLinuxKernel()
+	lk, err := newLinuxKernel(k.table)
 	if err != nil {
 		return errors.Wrap(err, "Unable to initialize linux kernel")
 	}
@@ -28,19 +27,19 @@ func (k *Kernel) init() error {
 }
 
 type linuxKernel struct {
-	h      *netlink.Handle
-	routes map[*bnet.Prefix]struct{}
+	h     *netlink.Handle
+	table int
 }
 
-func newLinuxKernel() (*linuxKernel, error) {
+func newLinuxKernel(table int) (*linuxKernel, error) {
 	h, err := netlink.NewHandle()
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to get Netlink handle")
 	}
 
 	return &linuxKernel{
-		h:      h,
-		routes: make(map[*bnet.Prefix]struct{}),
+		h:     h,
+		table: table,
 	}, nil
 }
 
@@ -60,9 +59,15 @@ func (lk *linuxKernel) uninit() error {
 func (lk *linuxKernel) cleanup() error {
 	filter := &netlink.Route{
 		Protocol: protoBio,
+		Table:    lk.table,
+	}
+
+	filterMask := uint64(netlink.RT_FILTER_PROTOCOL)
+	if lk.table != 0 {
+		filterMask |= netlink.RT_FILTER_TABLE
 	}
 
-	routes, err := lk.h.RouteListFiltered(0, filter, netlink.RT_FILTER_PROTOCOL)
+	routes, err := lk.h.RouteListFiltered(0, filter, filterMask)
 	if err != nil {
 		return errors.Wrap(err, "Unable to get routes")
 	}
@@ -77,24 +82,106 @@ func (lk *linuxKernel) cleanup() error {
 	return nil
 }
 
-func (lk *linuxKernel) AddPath(pfx *net.Prefix, path *route.Path) error {
+// ReplaceRoute installs or replaces the route for pfx with a weighted ECMP route via nextHops. The route is replaced
+// in place, so forwarding for pfx is not interrupted.
+func (lk *linuxKernel) ReplaceRoute(pfx *net.Prefix, nextHops []nextHop) error {
+	if pfx.Addr().IsIPv4() && hasIPv6NextHop(nextHops) {
+		return lk.replaceRouteVia(pfx, nextHops)
+	}
+
+	err := lk.h.RouteReplace(lk.route(pfx, nextHops))
+	if err != nil {
+		return errors.Wrap(err, "Unable to replace route")
+	}
+
+	return nil
+}
+
+// route gets the netlink route for pfx via nextHops
+func (lk *linuxKernel) route(pfx *net.Prefix, nextHops []nextHop) *netlink.Route {
 	r := &netlink.Route{
 		Protocol: protoBio,
+		Table:    lk.table,
 		Dst:      pfx.GetIPNet(),
-		Gw:       path.NextHop().ToNetIP(),
 	}
 
-	if _, found := lk.routes[pfx]; !found {
-		err := lk.h.RouteAdd(r)
-		if err != nil {
-			return errors.Wrap(err, "Unable to add route")
+	if len(nextHops) == 1 {
+		r.Gw = nextHops[0].addr.ToNetIP()
+		r.LinkIndex = int(nextHops[0].ifIndex)
+	}
+
+	if len(nextHops) > 1 {
+		for _, nh := range nextHops {
+			r.MultiPath = append(r.MultiPath, &netlink.NexthopInfo{
+				LinkIndex: int(nh.ifIndex),
+				Gw:        nh.addr.ToNetIP(),
+				Hops:      hops(nh),
+			})
+		}
+	}
+
+	return r
+}
+
+// hops gets the rtnh_hops field of a next hop which is its weight minus one
+func hops(nh nextHop) int {
+	if nh.weight == 0 {
+		return 0
+	}
+
+	return int(nh.weight - 1)
+}
+
+func hasIPv6NextHop(nextHops []nextHop) bool {
+	for _, nh := range nextHops {
+		if !nh.addr.IsIPv4() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replaceRouteVia installs an IPv4 route via IPv6 next hops (RFC8950). The gateway of such routes has to be given
+// as RTA_VIA which the netlink package does not support.
+func (lk *linuxKernel) replaceRouteVia(pfx *net.Prefix, nextHops []nextHop) error {
+	table := uint32(unix.RT_TABLE_MAIN)
+	if lk.table != 0 {
+		table = uint32(lk.table)
+	}
+
+	msg := nl.NewRtMsg()
+	msg.Family = unix.AF_INET
+	msg.Protocol = protoBio
+	msg.Dst_len = pfx.Pfxlen()
+	msg.Table = unix.RT_TABLE_UNSPEC
+	if table < 256 {
+		msg.Table = uint8(table)
+	}
+
+	req := nl.NewNetlinkRequest(unix.RTM_NEWROUTE, unix.NLM_F_CREATE|unix.NLM_F_REPLACE|unix.NLM_F_ACK)
+	req.AddData(msg)
+	req.AddData(nl.NewRtAttr(unix.RTA_DST, pfx.Addr().ToNetIP().To4()))
+	req.AddData(nl.NewRtAttr(unix.RTA_TABLE, nl.Uint32Attr(table)))
+
+	if len(nextHops) == 1 {
+		req.AddData(nl.NewRtAttr(unix.RTA_VIA, rtVia(nextHops[0].addr)))
+	} else {
+		buf := []byte{}
+		for _, nh := range nextHops {
+			rtnh := &nl.RtNexthop{
+				Children: []nl.NetlinkRequestData{
+					nl.NewRtAttr(unix.RTA_VIA, rtVia(nh.addr)),
+				},
+			}
+			rtnh.Hops = uint8(hops(nh))
+			buf = append(buf, rtnh.Serialize()...)
 		}
 
-		lk.routes[pfx] = struct{}{}
-		return nil
+		req.AddData(nl.NewRtAttr(unix.RTA_MULTIPATH, buf))
 	}
 
-	err := lk.h.RouteReplace(r)
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
 	if err != nil {
 		return errors.Wrap(err, "Unable to replace route")
 	}
@@ -102,22 +189,32 @@ func (lk *linuxKernel) AddPath(pfx *net.Prefix, path *route.Path) error {
 	return nil
 }
 
-func (lk *linuxKernel) RemovePath(pfx *net.Prefix, path *route.Path) bool {
-	if _, found := lk.routes[pfx]; !found {
-		return false
+// rtVia encodes a next hop as struct rtvia
+func rtVia(nh *net.IP) []byte {
+	family := uint16(unix.AF_INET)
+	addr := nh.ToNetIP().To4()
+	if !nh.IsIPv4() {
+		family = unix.AF_INET6
+		addr = nh.ToNetIP().To16()
 	}
 
+	buf := make([]byte, 2, 2+len(addr))
+	nl.NativeEndian().PutUint16(buf, family)
+	return append(buf, addr...)
+}
+
+// DeleteRoute removes the route for pfx
+func (lk *linuxKernel) DeleteRoute(pfx *net.Prefix) error {
 	r := &netlink.Route{
 		Protocol: protoBio,
+		Table:    lk.table,
 		Dst:      pfx.GetIPNet(),
-		Gw:       path.NextHop().ToNetIP(),
 	}
 
 	err := lk.h.RouteDel(r)
 	if err != nil {
-		return false
+		return errors.Wrap(err, "Unable to remove route")
 	}
 
-	delete(lk.routes, pfx)
-	return true
+	return nil
 }
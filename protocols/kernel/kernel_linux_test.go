@@ -0,0 +1,49 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute(t *testing.T) {
+	ll := net.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1).Ptr()
+	global := net.IPv6FromBlocks(0x2001, 0xdb8, 0xffff, 0, 0, 0, 0, 1).Ptr()
+	pfx := net.NewPfx(net.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr()
+
+	tests := []struct {
+		name              string
+		nextHops          []nextHop
+		expectedLinkIndex int
+		expectedMultiPath []int
+	}{
+		{
+			name:              "Link-local next hop",
+			nextHops:          []nextHop{{addr: ll, ifIndex: 2, weight: 1}},
+			expectedLinkIndex: 2,
+		},
+		{
+			name:     "Global next hop",
+			nextHops: []nextHop{{addr: global, weight: 1}},
+		},
+		{
+			name:              "Link-local ECMP next hops",
+			nextHops:          []nextHop{{addr: ll, ifIndex: 2, weight: 1}, {addr: ll, ifIndex: 3, weight: 1}},
+			expectedMultiPath: []int{2, 3},
+		},
+	}
+
+	lk := &linuxKernel{}
+	for _, test := range tests {
+		r := lk.route(pfx, test.nextHops)
+		assert.Equal(t, test.expectedLinkIndex, r.LinkIndex, "Test %q", test.name)
+
+		var linkIndexes []int
+		for _, nh := range r.MultiPath {
+			assert.Equal(t, ll.ToNetIP(), nh.Gw, "Test %q", test.name)
+			linkIndexes = append(linkIndexes, nh.LinkIndex)
+		}
+		assert.Equal(t, test.expectedMultiPath, linkIndexes, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,230 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	"github.com/bio-routing/bio-rd/util/histogram"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOSKernel struct {
+	routes   map[net.Prefix][]nextHop
+	replaced int
+}
+
+func (m *mockOSKernel) ReplaceRoute(pfx *net.Prefix, nextHops []nextHop) error {
+	if m.routes == nil {
+		m.routes = make(map[net.Prefix][]nextHop)
+	}
+
+	m.routes[*pfx] = nextHops
+	m.replaced++
+	return nil
+}
+
+func (m *mockOSKernel) DeleteRoute(pfx *net.Prefix) error {
+	delete(m.routes, *pfx)
+	return nil
+}
+
+func (m *mockOSKernel) uninit() error {
+	return nil
+}
+
+func TestFIBProgrammingLatency(t *testing.T) {
+	k := &Kernel{
+		osKernel:   &mockOSKernel{},
+		fibLatency: make(map[uint8]*histogram.Histogram),
+		paths:      make(map[net.Prefix][]*route.Path),
+		groups:     make(map[net.Prefix][]nextHop),
+	}
+
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	nh := net.IPv4FromOctets(192, 0, 2, 1).Ptr()
+	bgpPath := &route.Path{Type: route.BGPPathType, BGPPath: &route.BGPPath{BGPPathA: &route.BGPPathA{NextHop: nh, Source: nh}}}
+	k.AddPath(pfx, &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{}})
+	k.AddPath(pfx, bgpPath)
+	k.RemovePath(pfx, bgpPath)
+
+	m := k.Metrics()
+	assert.Equal(t, 2, len(m.FIBProgrammingLatency))
+	assert.Equal(t, uint64(1), m.FIBProgrammingLatency["static"].Count)
+	assert.Equal(t, uint64(2), m.FIBProgrammingLatency["bgp"].Count)
+}
+
+func TestECMPRoutes(t *testing.T) {
+	osk := &mockOSKernel{}
+	k := &Kernel{
+		osKernel:   osk,
+		fibLatency: make(map[uint8]*histogram.Histogram),
+		paths:      make(map[net.Prefix][]*route.Path),
+		groups:     make(map[net.Prefix][]nextHop),
+	}
+
+	nh1 := net.IPv4FromOctets(10, 0, 0, 1).Ptr()
+	nh2 := net.IPv4FromOctets(10, 0, 0, 2).Ptr()
+	pfx := net.NewPfx(net.IPv4FromOctets(192, 0, 2, 0), 24)
+	p1 := &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{NextHop: nh1}}
+	p2 := &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{NextHop: nh2}}
+
+	assert.NoError(t, k.AddPath(&pfx, p1))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}}, osk.routes[pfx])
+
+	assert.NoError(t, k.AddPath(&pfx, p2))
+	assert.NoError(t, k.AddPath(&pfx, p2))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}, {addr: nh2, weight: 1}}, osk.routes[pfx])
+
+	assert.True(t, k.RemovePath(&pfx, p1))
+	assert.Equal(t, []nextHop{{addr: nh2, weight: 1}}, osk.routes[pfx])
+	assert.False(t, k.RemovePath(&pfx, p1))
+
+	assert.True(t, k.RemovePath(&pfx, p2))
+	assert.Equal(t, 0, len(osk.routes))
+	assert.Equal(t, 0, len(k.paths))
+	assert.Equal(t, 0, len(k.groups))
+}
+
+func TestLinkLocalNextHop(t *testing.T) {
+	osk := &mockOSKernel{}
+	k := &Kernel{
+		osKernel:   osk,
+		fibLatency: make(map[uint8]*histogram.Histogram),
+		paths:      make(map[net.Prefix][]*route.Path),
+		groups:     make(map[net.Prefix][]nextHop),
+	}
+
+	nh := net.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1).Ptr()
+	pfx := net.NewPfx(net.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32)
+	p1 := &route.Path{Type: route.RIPPathType, RIPPath: &route.RIPPath{NextHop: nh, IfIndex: 2, Metric: 1}}
+	p2 := &route.Path{Type: route.RIPPathType, RIPPath: &route.RIPPath{NextHop: nh, IfIndex: 3, Metric: 1}}
+
+	// The same link-local address on different interfaces are different next hops
+	assert.NoError(t, k.AddPath(&pfx, p1))
+	assert.Equal(t, []nextHop{{addr: nh, ifIndex: 2, weight: 1}}, osk.routes[pfx])
+
+	assert.NoError(t, k.AddPath(&pfx, p2))
+	assert.Equal(t, []nextHop{{addr: nh, ifIndex: 2, weight: 1}, {addr: nh, ifIndex: 3, weight: 1}}, osk.routes[pfx])
+
+	assert.True(t, k.RemovePath(&pfx, p1))
+	assert.Equal(t, []nextHop{{addr: nh, ifIndex: 3, weight: 1}}, osk.routes[pfx])
+}
+
+func TestWeightedECMPRoutes(t *testing.T) {
+	osk := &mockOSKernel{}
+	k := &Kernel{
+		osKernel:   osk,
+		fibLatency: make(map[uint8]*histogram.Histogram),
+		paths:      make(map[net.Prefix][]*route.Path),
+		groups:     make(map[net.Prefix][]nextHop),
+	}
+
+	nh1 := net.IPv4FromOctets(10, 0, 0, 1).Ptr()
+	nh2 := net.IPv4FromOctets(10, 0, 0, 2).Ptr()
+	pfx := net.NewPfx(net.IPv4FromOctets(192, 0, 2, 0), 24)
+	bgpPath := func(nh *net.IP, pathID uint32, coms ...types.ExtendedCommunity) *route.Path {
+		ec := types.ExtendedCommunities(coms)
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA:            &route.BGPPathA{NextHop: nh, Source: nh},
+				PathIdentifier:      pathID,
+				ExtendedCommunities: &ec,
+			},
+		}
+	}
+
+	// Next hops are weighted by their number of paths (ADD-PATH)
+	p1 := bgpPath(nh1, 1)
+	p2 := bgpPath(nh1, 2)
+	p3 := bgpPath(nh2, 1)
+	assert.NoError(t, k.AddPath(&pfx, p1))
+	assert.NoError(t, k.AddPath(&pfx, p2))
+	assert.NoError(t, k.AddPath(&pfx, p3))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 2}, {addr: nh2, weight: 1}}, osk.routes[pfx])
+	assert.Equal(t, 3, osk.replaced)
+
+	// Paths not changing the next hops are not installed
+	p4 := bgpPath(nh2, 2)
+	assert.NoError(t, k.AddPath(&pfx, p4))
+	assert.True(t, k.RemovePath(&pfx, p1))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}, {addr: nh2, weight: 2}}, osk.routes[pfx])
+	assert.True(t, k.RemovePath(&pfx, p2))
+	assert.True(t, k.RemovePath(&pfx, p3))
+	replaced := osk.replaced
+	assert.NoError(t, k.AddPath(&pfx, p3))
+	assert.True(t, k.RemovePath(&pfx, p3))
+	assert.Equal(t, replaced+2, osk.replaced)
+	assert.True(t, k.RemovePath(&pfx, p4))
+
+	// Next hops are weighted by link bandwidth if all paths carry it
+	p5 := bgpPath(nh1, 1, types.NewLinkBandwidth(65000, 1.25e9))
+	p6 := bgpPath(nh2, 1, types.NewLinkBandwidth(65000, 1.25e8))
+	assert.NoError(t, k.AddPath(&pfx, p5))
+	assert.NoError(t, k.AddPath(&pfx, p6))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 256}, {addr: nh2, weight: 26}}, osk.routes[pfx])
+
+	p7 := bgpPath(nh2, 2)
+	assert.NoError(t, k.AddPath(&pfx, p7))
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}, {addr: nh2, weight: 2}}, osk.routes[pfx])
+}
+
+func TestNextHopGroups(t *testing.T) {
+	osk := &mockOSKernel{}
+	k := &Kernel{
+		osKernel:      osk,
+		nhGroups:      make(map[uint64][]nextHop),
+		nhGroupRoutes: make(map[uint64]map[net.Prefix]struct{}),
+		routeNHGroups: make(map[net.Prefix]uint64),
+	}
+
+	nh1 := net.IPv4FromOctets(10, 0, 0, 1).Ptr()
+	nh2 := net.IPv4FromOctets(10, 0, 0, 2).Ptr()
+	pfxA := net.NewPfx(net.IPv4FromOctets(192, 0, 2, 0), 24)
+	pfxB := net.NewPfx(net.IPv4FromOctets(198, 51, 100, 0), 24)
+
+	g := locRIB.NextHopGroup{
+		ID:      1,
+		Primary: []locRIB.NextHop{{Address: nh1}},
+		Backup:  &locRIB.NextHop{Address: nh2},
+		Active:  []locRIB.NextHop{{Address: nh1}},
+	}
+	k.SetNextHopGroup(g)
+	k.SetRoute(&pfxA, 1)
+	k.SetRoute(&pfxB, 1)
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}}, osk.routes[pfxA])
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}}, osk.routes[pfxB])
+
+	g.Active = []locRIB.NextHop{*g.Backup}
+	k.SetNextHopGroup(g)
+	assert.Equal(t, []nextHop{{addr: nh2, weight: 1}}, osk.routes[pfxA])
+	assert.Equal(t, []nextHop{{addr: nh2, weight: 1}}, osk.routes[pfxB])
+
+	k.SetNextHopGroup(locRIB.NextHopGroup{
+		ID:     2,
+		Active: []locRIB.NextHop{{Address: nh1}, {Address: nh2}},
+	})
+	k.SetRoute(&pfxA, 2)
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 1}, {addr: nh2, weight: 1}}, osk.routes[pfxA])
+	assert.Equal(t, 1, len(k.nhGroupRoutes[1]))
+
+	k.RemoveRoute(&pfxB)
+	k.RemoveNextHopGroup(1)
+	assert.Equal(t, 1, len(osk.routes))
+	assert.Equal(t, 1, len(k.nhGroups))
+
+	// Next hops used by multiple active paths get a higher weight, unchanged groups are not installed
+	g = locRIB.NextHopGroup{
+		ID:     2,
+		Active: []locRIB.NextHop{{Address: nh1, Source: nh1}, {Address: nh1, Source: nh2}, {Address: nh2}},
+	}
+	k.SetNextHopGroup(g)
+	assert.Equal(t, []nextHop{{addr: nh1, weight: 2}, {addr: nh2, weight: 1}}, osk.routes[pfxA])
+
+	replaced := osk.replaced
+	k.SetNextHopGroup(g)
+	assert.Equal(t, replaced, osk.replaced)
+}
@@ -0,0 +1,265 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/decode"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Port is the LDP UDP (discovery) and TCP (session) port
+	Port = 646
+
+	// Version is the LDP protocol version
+	Version = 1
+
+	// PDUHeaderLen is the length of the PDU header (including version and length fields)
+	PDUHeaderLen = 10
+
+	// MessageHeaderLen is the length of a message header (including the message ID)
+	MessageHeaderLen = 8
+
+	// TLVHeaderLen is the length of a TLV header
+	TLVHeaderLen = 4
+
+	// DefaultLinkHelloHoldTime is the hold time used for link hellos proposing a hold time of 0
+	DefaultLinkHelloHoldTime = 15
+
+	// MaxPDULength is the default maximum PDU length (RFC 5036 3.5.3)
+	MaxPDULength = 4096
+
+	unknownBit = 0x8000
+	forwardBit = 0x4000
+)
+
+// Message types
+const (
+	NotificationMsg    = 0x0001
+	HelloMsg           = 0x0100
+	InitializationMsg  = 0x0200
+	KeepAliveMsg       = 0x0201
+	AddressMsg         = 0x0300
+	AddressWithdrawMsg = 0x0301
+	LabelMappingMsg    = 0x0400
+	LabelRequestMsg    = 0x0401
+	LabelWithdrawMsg   = 0x0402
+	LabelReleaseMsg    = 0x0403
+)
+
+// TLV types
+const (
+	FECTLVType                  = 0x0100
+	AddressListTLVType          = 0x0101
+	GenericLabelTLVType         = 0x0200
+	StatusTLVType               = 0x0300
+	CommonHelloParamsTLVType    = 0x0400
+	IPv4TransportAddressTLVType = 0x0401
+	CommonSessionParamsTLVType  = 0x0500
+)
+
+// PDU is an LDP protocol data unit
+type PDU struct {
+	LSRID      uint32
+	LabelSpace uint16
+	Messages   []*Message
+}
+
+// Message is an LDP message
+type Message struct {
+	Type       uint16
+	UnknownBit bool
+	ID         uint32
+	TLVs       []*TLV
+}
+
+// TLV is an LDP type length value parameter
+type TLV struct {
+	Type       uint16
+	UnknownBit bool
+	ForwardBit bool
+	Value      []byte
+}
+
+// Serialize serializes the PDU
+func (p *PDU) Serialize(buf *bytes.Buffer) {
+	msgs := 0
+	for _, m := range p.Messages {
+		msgs += m.length()
+	}
+
+	endian.WriteUint16(buf, Version)
+	endian.WriteUint16(buf, uint16(PDUHeaderLen-4+msgs))
+	endian.WriteUint32(buf, p.LSRID)
+	endian.WriteUint16(buf, p.LabelSpace)
+
+	for _, m := range p.Messages {
+		m.serialize(buf)
+	}
+}
+
+// PDULength gets the total length of a PDU from its first 4 bytes. This allows framing PDUs on a stream.
+func PDULength(header []byte) (int, error) {
+	if len(header) < 4 {
+		return 0, fmt.Errorf("PDU header too short")
+	}
+
+	if v := endian.Uint16(header); v != Version {
+		return 0, fmt.Errorf("Unsupported LDP version %d", v)
+	}
+
+	l := int(endian.Uint16(header[2:])) + 4
+	if l < PDUHeaderLen {
+		return 0, fmt.Errorf("Invalid PDU length %d", l)
+	}
+
+	return l, nil
+}
+
+// DecodePDU decodes a PDU
+func DecodePDU(b []byte) (*PDU, error) {
+	l, err := PDULength(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if l > len(b) {
+		return nil, fmt.Errorf("PDU length %d exceeds %d available bytes", l, len(b))
+	}
+
+	buf := bytes.NewBuffer(b[4:l])
+	p := &PDU{}
+	err = decode.Decode(buf, []interface{}{
+		&p.LSRID,
+		&p.LabelSpace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode PDU header")
+	}
+
+	for buf.Len() > 0 {
+		m, err := decodeMessage(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode message")
+		}
+
+		p.Messages = append(p.Messages, m)
+	}
+
+	return p, nil
+}
+
+// TLV returns the first TLV of type t or nil
+func (m *Message) TLV(t uint16) *TLV {
+	for _, tlv := range m.TLVs {
+		if tlv.Type == t {
+			return tlv
+		}
+	}
+
+	return nil
+}
+
+func (m *Message) length() int {
+	l := MessageHeaderLen
+	for _, t := range m.TLVs {
+		l += TLVHeaderLen + len(t.Value)
+	}
+
+	return l
+}
+
+func (m *Message) serialize(buf *bytes.Buffer) {
+	t := m.Type
+	if m.UnknownBit {
+		t |= unknownBit
+	}
+
+	endian.WriteUint16(buf, t)
+	endian.WriteUint16(buf, uint16(m.length()-4))
+	endian.WriteUint32(buf, m.ID)
+
+	for _, tlv := range m.TLVs {
+		tlv.serialize(buf)
+	}
+}
+
+func decodeMessage(buf *bytes.Buffer) (*Message, error) {
+	m := &Message{}
+	t := uint16(0)
+	l := uint16(0)
+	err := decode.Decode(buf, []interface{}{
+		&t,
+		&l,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.Type = t &^ unknownBit
+	m.UnknownBit = t&unknownBit != 0
+
+	if l < 4 || int(l) > buf.Len() {
+		return nil, fmt.Errorf("Invalid length %d of message type %d", l, m.Type)
+	}
+
+	body := bytes.NewBuffer(buf.Next(int(l)))
+	err = decode.DecodeUint32(body, &m.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for body.Len() > 0 {
+		tlv, err := decodeTLV(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode TLV of message type %d", m.Type)
+		}
+
+		m.TLVs = append(m.TLVs, tlv)
+	}
+
+	return m, nil
+}
+
+func (t *TLV) serialize(buf *bytes.Buffer) {
+	typ := t.Type
+	if t.UnknownBit {
+		typ |= unknownBit
+	}
+
+	if t.ForwardBit {
+		typ |= forwardBit
+	}
+
+	endian.WriteUint16(buf, typ)
+	endian.WriteUint16(buf, uint16(len(t.Value)))
+	buf.Write(t.Value)
+}
+
+func decodeTLV(buf *bytes.Buffer) (*TLV, error) {
+	t := uint16(0)
+	l := uint16(0)
+	err := decode.Decode(buf, []interface{}{
+		&t,
+		&l,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if int(l) > buf.Len() {
+		return nil, fmt.Errorf("TLV length %d exceeds %d available bytes", l, buf.Len())
+	}
+
+	tlv := &TLV{
+		Type:       t &^ (unknownBit | forwardBit),
+		UnknownBit: t&unknownBit != 0,
+		ForwardBit: t&forwardBit != 0,
+		Value:      make([]byte, l),
+	}
+	copy(tlv.Value, buf.Next(int(l)))
+
+	return tlv, nil
+}
@@ -0,0 +1,192 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPDUSerialize(t *testing.T) {
+	p := &PDU{
+		LSRID:      0x0a000001,
+		LabelSpace: 0,
+		Messages: []*Message{
+			{
+				Type: KeepAliveMsg,
+				ID:   7,
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	expected := []byte{
+		0, 1, // Version
+		0, 14, // Length
+		10, 0, 0, 1, // LSR ID
+		0, 0, // Label space
+		2, 1, // Type
+		0, 4, // Length
+		0, 0, 0, 7, // Message ID
+	}
+	assert.Equal(t, expected, buf.Bytes())
+}
+
+func TestDecodePDU(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantFail bool
+		expected *PDU
+	}{
+		{
+			name: "Hello",
+			input: []byte{
+				0, 1,
+				0, 30,
+				10, 0, 0, 1,
+				0, 0,
+				1, 0, // Hello
+				0, 20,
+				0, 0, 0, 1,
+				4, 0, // Common hello params
+				0, 4,
+				0, 15, 0, 0,
+				4, 1, // IPv4 transport address
+				0, 4,
+				10, 0, 0, 1,
+			},
+			expected: &PDU{
+				LSRID: 0x0a000001,
+				Messages: []*Message{
+					{
+						Type: HelloMsg,
+						ID:   1,
+						TLVs: []*TLV{
+							{
+								Type:  CommonHelloParamsTLVType,
+								Value: []byte{0, 15, 0, 0},
+							},
+							{
+								Type:  IPv4TransportAddressTLVType,
+								Value: []byte{10, 0, 0, 1},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Unknown and forward bits",
+			input: []byte{
+				0, 1,
+				0, 18,
+				10, 0, 0, 1,
+				0, 0,
+				0x8f, 0, // unknown message
+				0, 8,
+				0, 0, 0, 1,
+				0xc0, 0x99,
+				0, 0,
+			},
+			expected: &PDU{
+				LSRID: 0x0a000001,
+				Messages: []*Message{
+					{
+						Type:       0x0f00,
+						UnknownBit: true,
+						ID:         1,
+						TLVs: []*TLV{
+							{
+								Type:       0x0099,
+								UnknownBit: true,
+								ForwardBit: true,
+								Value:      []byte{},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Wrong version",
+			input: []byte{
+				0, 2,
+				0, 6,
+				10, 0, 0, 1,
+				0, 0,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Truncated PDU",
+			input: []byte{
+				0, 1,
+				0, 10,
+				10, 0, 0, 1,
+				0, 0,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Message length exceeds PDU",
+			input: []byte{
+				0, 1,
+				0, 14,
+				10, 0, 0, 1,
+				0, 0,
+				2, 1,
+				0, 5,
+				0, 0, 0, 7,
+			},
+			wantFail: true,
+		},
+		{
+			name: "TLV length exceeds message",
+			input: []byte{
+				0, 1,
+				0, 18,
+				10, 0, 0, 1,
+				0, 0,
+				2, 1,
+				0, 8,
+				0, 0, 0, 7,
+				4, 0,
+				0, 4,
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := DecodePDU(test.input)
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoErrorf(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equalf(t, test.expected, p, "Test %q", test.name)
+
+		buf := bytes.NewBuffer(nil)
+		p.Serialize(buf)
+		assert.Equalf(t, test.input, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestMessageTLV(t *testing.T) {
+	m := &Message{
+		TLVs: []*TLV{
+			{Type: FECTLVType},
+			{Type: GenericLabelTLVType, Value: []byte{1}},
+		},
+	}
+
+	assert.Equal(t, []byte{1}, m.TLV(GenericLabelTLVType).Value)
+	assert.Nil(t, m.TLV(StatusTLVType))
+}
@@ -0,0 +1,56 @@
+package packet
+
+import (
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+// AddressListTLV creates an Address List TLV of IPv4 addresses
+func AddressListTLV(addrs []bnet.IP) *TLV {
+	v := make([]byte, 2, 2+4*len(addrs))
+	endian.PutUint16(v, afiIPv4)
+	for _, a := range addrs {
+		v = append(v, a.Bytes()...)
+	}
+
+	return &TLV{
+		Type:  AddressListTLVType,
+		Value: v,
+	}
+}
+
+// DecodeAddressList decodes an Address List TLV
+func DecodeAddressList(t *TLV) ([]bnet.IP, error) {
+	if len(t.Value) < 2 {
+		return nil, fmt.Errorf("Address list too short")
+	}
+
+	addrLen := 0
+	switch afi := endian.Uint16(t.Value); afi {
+	case afiIPv4:
+		addrLen = 4
+	case afiIPv6:
+		addrLen = 16
+	default:
+		return nil, fmt.Errorf("Unsupported address family %d", afi)
+	}
+
+	v := t.Value[2:]
+	if len(v)%addrLen != 0 {
+		return nil, fmt.Errorf("Invalid address list length %d", len(t.Value))
+	}
+
+	ret := make([]bnet.IP, 0, len(v)/addrLen)
+	for ; len(v) > 0; v = v[addrLen:] {
+		ip, err := bnet.IPFromBytes(v[:addrLen])
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, ip)
+	}
+
+	return ret, nil
+}
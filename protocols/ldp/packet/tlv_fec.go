@@ -0,0 +1,121 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+const (
+	// WildcardFECElement matches all FECs
+	WildcardFECElement = 0x01
+
+	// PrefixFECElement identifies an address prefix
+	PrefixFECElement = 0x02
+
+	afiIPv4 = 1
+	afiIPv6 = 2
+)
+
+// FECElement is an element of a FEC TLV
+type FECElement struct {
+	Type   uint8
+	Prefix bnet.Prefix
+}
+
+// FECTLV creates a FEC TLV from elements
+func FECTLV(elements []FECElement) *TLV {
+	buf := bytes.NewBuffer(nil)
+	for _, e := range elements {
+		buf.WriteByte(e.Type)
+		if e.Type == WildcardFECElement {
+			continue
+		}
+
+		afi := uint16(afiIPv4)
+		if !e.Prefix.Addr().IsIPv4() {
+			afi = afiIPv6
+		}
+
+		buf.Write([]byte{byte(afi >> 8), byte(afi)})
+		buf.WriteByte(e.Prefix.Pfxlen())
+		buf.Write(e.Prefix.Addr().Bytes()[:prefixBytes(e.Prefix.Pfxlen())])
+	}
+
+	return &TLV{
+		Type:  FECTLVType,
+		Value: buf.Bytes(),
+	}
+}
+
+// PrefixFECTLV creates a FEC TLV for a single prefix
+func PrefixFECTLV(pfx bnet.Prefix) *TLV {
+	return FECTLV([]FECElement{
+		{
+			Type:   PrefixFECElement,
+			Prefix: pfx,
+		},
+	})
+}
+
+// DecodeFEC decodes a FEC TLV
+func DecodeFEC(t *TLV) ([]FECElement, error) {
+	ret := make([]FECElement, 0, 1)
+	v := t.Value
+	for len(v) > 0 {
+		typ := v[0]
+		v = v[1:]
+
+		if typ == WildcardFECElement {
+			ret = append(ret, FECElement{Type: WildcardFECElement})
+			continue
+		}
+
+		if typ != PrefixFECElement {
+			return nil, fmt.Errorf("Unsupported FEC element type %d", typ)
+		}
+
+		if len(v) < 3 {
+			return nil, fmt.Errorf("Prefix FEC element too short")
+		}
+
+		afi := uint16(v[0])<<8 | uint16(v[1])
+		pfxlen := v[2]
+		v = v[3:]
+
+		addrLen := 4
+		switch afi {
+		case afiIPv4:
+		case afiIPv6:
+			addrLen = 16
+		default:
+			return nil, fmt.Errorf("Unsupported address family %d", afi)
+		}
+
+		n := prefixBytes(pfxlen)
+		if int(pfxlen) > addrLen*8 || n > len(v) {
+			return nil, fmt.Errorf("Invalid prefix length %d", pfxlen)
+		}
+
+		addr := make([]byte, addrLen)
+		copy(addr, v[:n])
+		v = v[n:]
+
+		ip, err := bnet.IPFromBytes(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, FECElement{
+			Type:   PrefixFECElement,
+			Prefix: bnet.NewPfx(ip, pfxlen),
+		})
+	}
+
+	return ret, nil
+}
+
+func prefixBytes(pfxlen uint8) int {
+	return (int(pfxlen) + 7) / 8
+}
@@ -0,0 +1,72 @@
+package packet
+
+import (
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	targetedHelloFlag   = 0x8000
+	requestTargetedFlag = 0x4000
+)
+
+// CommonHelloParams is the Common Hello Parameters TLV (RFC 5036 3.5.2)
+type CommonHelloParams struct {
+	HoldTime        uint16
+	Targeted        bool
+	RequestTargeted bool
+}
+
+// TLV converts the parameters into a TLV
+func (c *CommonHelloParams) TLV() *TLV {
+	v := make([]byte, 4)
+	endian.PutUint16(v, c.HoldTime)
+
+	flags := uint16(0)
+	if c.Targeted {
+		flags |= targetedHelloFlag
+	}
+
+	if c.RequestTargeted {
+		flags |= requestTargetedFlag
+	}
+	endian.PutUint16(v[2:], flags)
+
+	return &TLV{
+		Type:  CommonHelloParamsTLVType,
+		Value: v,
+	}
+}
+
+// DecodeCommonHelloParams decodes a Common Hello Parameters TLV
+func DecodeCommonHelloParams(t *TLV) (*CommonHelloParams, error) {
+	if len(t.Value) != 4 {
+		return nil, fmt.Errorf("Invalid common hello parameters length %d", len(t.Value))
+	}
+
+	flags := endian.Uint16(t.Value[2:])
+	return &CommonHelloParams{
+		HoldTime:        endian.Uint16(t.Value),
+		Targeted:        flags&targetedHelloFlag != 0,
+		RequestTargeted: flags&requestTargetedFlag != 0,
+	}, nil
+}
+
+// IPv4TransportAddressTLV creates an IPv4 Transport Address TLV
+func IPv4TransportAddressTLV(addr bnet.IP) *TLV {
+	return &TLV{
+		Type:  IPv4TransportAddressTLVType,
+		Value: addr.Bytes(),
+	}
+}
+
+// DecodeIPv4TransportAddress decodes an IPv4 Transport Address TLV
+func DecodeIPv4TransportAddress(t *TLV) (bnet.IP, error) {
+	if len(t.Value) != 4 {
+		return bnet.IP{}, fmt.Errorf("Invalid IPv4 transport address length %d", len(t.Value))
+	}
+
+	return bnet.IPv4FromBytes(t.Value), nil
+}
@@ -0,0 +1,35 @@
+package packet
+
+import (
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// ImplicitNullLabel requests penultimate hop popping
+	ImplicitNullLabel = 3
+
+	// MaxLabel is the highest valid MPLS label
+	MaxLabel = 1<<20 - 1
+)
+
+// GenericLabelTLV creates a Generic Label TLV
+func GenericLabelTLV(label uint32) *TLV {
+	v := make([]byte, 4)
+	endian.PutUint32(v, label&MaxLabel)
+
+	return &TLV{
+		Type:  GenericLabelTLVType,
+		Value: v,
+	}
+}
+
+// DecodeGenericLabel decodes a Generic Label TLV
+func DecodeGenericLabel(t *TLV) (uint32, error) {
+	if len(t.Value) != 4 {
+		return 0, fmt.Errorf("Invalid generic label length %d", len(t.Value))
+	}
+
+	return endian.Uint32(t.Value) & MaxLabel, nil
+}
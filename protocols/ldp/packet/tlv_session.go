@@ -0,0 +1,73 @@
+package packet
+
+import (
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	commonSessionParamsLen = 14
+	labelAdvertisementFlag = 0x80
+	loopDetectionFlag      = 0x40
+)
+
+// CommonSessionParams is the Common Session Parameters TLV (RFC 5036 3.5.3)
+type CommonSessionParams struct {
+	ProtocolVersion uint16
+	KeepAliveTime   uint16
+
+	// DownstreamOnDemand selects the label advertisement discipline. Downstream unsolicited is used if false.
+	DownstreamOnDemand bool
+	LoopDetection      bool
+	PathVectorLimit    uint8
+	MaxPDULength       uint16
+
+	// ReceiverLSRID and ReceiverLabelSpace identify the LDP identifier of the receiver
+	ReceiverLSRID      uint32
+	ReceiverLabelSpace uint16
+}
+
+// TLV converts the parameters into a TLV
+func (c *CommonSessionParams) TLV() *TLV {
+	v := make([]byte, commonSessionParamsLen)
+	endian.PutUint16(v, c.ProtocolVersion)
+	endian.PutUint16(v[2:], c.KeepAliveTime)
+
+	if c.DownstreamOnDemand {
+		v[4] |= labelAdvertisementFlag
+	}
+
+	if c.LoopDetection {
+		v[4] |= loopDetectionFlag
+	}
+
+	v[5] = c.PathVectorLimit
+	endian.PutUint16(v[6:], c.MaxPDULength)
+	endian.PutUint32(v[8:], c.ReceiverLSRID)
+	endian.PutUint16(v[12:], c.ReceiverLabelSpace)
+
+	return &TLV{
+		Type:  CommonSessionParamsTLVType,
+		Value: v,
+	}
+}
+
+// DecodeCommonSessionParams decodes a Common Session Parameters TLV
+func DecodeCommonSessionParams(t *TLV) (*CommonSessionParams, error) {
+	if len(t.Value) != commonSessionParamsLen {
+		return nil, fmt.Errorf("Invalid common session parameters length %d", len(t.Value))
+	}
+
+	v := t.Value
+	return &CommonSessionParams{
+		ProtocolVersion:    endian.Uint16(v),
+		KeepAliveTime:      endian.Uint16(v[2:]),
+		DownstreamOnDemand: v[4]&labelAdvertisementFlag != 0,
+		LoopDetection:      v[4]&loopDetectionFlag != 0,
+		PathVectorLimit:    v[5],
+		MaxPDULength:       endian.Uint16(v[6:]),
+		ReceiverLSRID:      endian.Uint32(v[8:]),
+		ReceiverLabelSpace: endian.Uint16(v[12:]),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package packet
+
+import (
+	"fmt"
+
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+// Status codes (RFC 5036 3.9)
+const (
+	StatusSuccess                   = 0x00
+	StatusBadLDPIdentifier          = 0x01
+	StatusBadProtocolVersion        = 0x02
+	StatusBadPDULength              = 0x03
+	StatusUnknownMessageType        = 0x04
+	StatusBadMessageLength          = 0x05
+	StatusUnknownTLV                = 0x06
+	StatusBadTLVLength              = 0x07
+	StatusMalformedTLVValue         = 0x08
+	StatusHoldTimerExpired          = 0x09
+	StatusShutdown                  = 0x0a
+	StatusLoopDetected              = 0x0b
+	StatusUnknownFEC                = 0x0c
+	StatusNoRoute                   = 0x0d
+	StatusNoLabelResources          = 0x0e
+	StatusLabelResourcesAvailable   = 0x0f
+	StatusSessionRejectedNoHello    = 0x10
+	StatusSessionRejectedAdvMode    = 0x11
+	StatusSessionRejectedMaxPDU     = 0x12
+	StatusSessionRejectedLabelRange = 0x13
+	StatusKeepAliveTimerExpired     = 0x14
+	StatusLabelRequestAborted       = 0x15
+	StatusMissingMessageParameters  = 0x16
+	StatusUnsupportedAddressFamily  = 0x17
+	StatusSessionRejectedBadKATime  = 0x18
+	StatusInternalError             = 0x19
+
+	statusFatalBit   = 0x80000000
+	statusForwardBit = 0x40000000
+)
+
+// Status is the Status TLV
+type Status struct {
+	Code        uint32
+	Fatal       bool
+	Forward     bool
+	MessageID   uint32
+	MessageType uint16
+}
+
+// TLV converts the status into a TLV
+func (s *Status) TLV() *TLV {
+	v := make([]byte, 10)
+	code := s.Code
+	if s.Fatal {
+		code |= statusFatalBit
+	}
+
+	if s.Forward {
+		code |= statusForwardBit
+	}
+
+	endian.PutUint32(v, code)
+	endian.PutUint32(v[4:], s.MessageID)
+	endian.PutUint16(v[8:], s.MessageType)
+
+	return &TLV{
+		Type:  StatusTLVType,
+		Value: v,
+	}
+}
+
+// DecodeStatus decodes a Status TLV
+func DecodeStatus(t *TLV) (*Status, error) {
+	if len(t.Value) != 10 {
+		return nil, fmt.Errorf("Invalid status length %d", len(t.Value))
+	}
+
+	code := endian.Uint32(t.Value)
+	return &Status{
+		Code:        code &^ (statusFatalBit | statusForwardBit),
+		Fatal:       code&statusFatalBit != 0,
+		Forward:     code&statusForwardBit != 0,
+		MessageID:   endian.Uint32(t.Value[4:]),
+		MessageType: endian.Uint16(t.Value[8:]),
+	}, nil
+}
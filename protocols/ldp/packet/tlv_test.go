@@ -0,0 +1,176 @@
+package packet
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonHelloParams(t *testing.T) {
+	c := &CommonHelloParams{
+		HoldTime: 15,
+		Targeted: true,
+	}
+
+	tlv := c.TLV()
+	assert.Equal(t, []byte{0, 15, 0x80, 0}, tlv.Value)
+
+	d, err := DecodeCommonHelloParams(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, c, d)
+
+	_, err = DecodeCommonHelloParams(&TLV{Value: []byte{1}})
+	assert.Error(t, err)
+}
+
+func TestIPv4TransportAddress(t *testing.T) {
+	tlv := IPv4TransportAddressTLV(bnet.IPv4FromOctets(10, 0, 0, 1))
+	assert.Equal(t, []byte{10, 0, 0, 1}, tlv.Value)
+
+	addr, err := DecodeIPv4TransportAddress(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, bnet.IPv4FromOctets(10, 0, 0, 1), addr)
+}
+
+func TestCommonSessionParams(t *testing.T) {
+	c := &CommonSessionParams{
+		ProtocolVersion:    Version,
+		KeepAliveTime:      180,
+		LoopDetection:      true,
+		MaxPDULength:       4096,
+		ReceiverLSRID:      0x0a000002,
+		ReceiverLabelSpace: 0,
+	}
+
+	tlv := c.TLV()
+	assert.Equal(t, []byte{0, 1, 0, 180, 0x40, 0, 0x10, 0, 10, 0, 0, 2, 0, 0}, tlv.Value)
+
+	d, err := DecodeCommonSessionParams(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, c, d)
+}
+
+func TestFEC(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements []FECElement
+		expected []byte
+	}{
+		{
+			name: "IPv4 prefix",
+			elements: []FECElement{
+				{
+					Type:   PrefixFECElement,
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 0, 0), 16),
+				},
+			},
+			expected: []byte{2, 0, 1, 16, 10, 1},
+		},
+		{
+			name: "IPv4 host route and wildcard",
+			elements: []FECElement{
+				{
+					Type:   PrefixFECElement,
+					Prefix: bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 1), 32),
+				},
+				{
+					Type: WildcardFECElement,
+				},
+			},
+			expected: []byte{2, 0, 1, 32, 10, 0, 0, 1, 1},
+		},
+		{
+			name: "IPv6 prefix",
+			elements: []FECElement{
+				{
+					Type:   PrefixFECElement,
+					Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+				},
+			},
+			expected: []byte{2, 0, 2, 32, 0x20, 0x01, 0x0d, 0xb8},
+		},
+	}
+
+	for _, test := range tests {
+		tlv := FECTLV(test.elements)
+		assert.Equalf(t, test.expected, tlv.Value, "Test %q", test.name)
+
+		d, err := DecodeFEC(tlv)
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.elements, d, "Test %q", test.name)
+	}
+}
+
+func TestDecodeFECFail(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		{
+			name:  "Unsupported element",
+			value: []byte{0x80},
+		},
+		{
+			name:  "Truncated element",
+			value: []byte{2, 0, 1},
+		},
+		{
+			name:  "Prefix length too long",
+			value: []byte{2, 0, 1, 33, 10, 0, 0, 1, 1},
+		},
+		{
+			name:  "Unsupported AFI",
+			value: []byte{2, 0, 3, 0},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeFEC(&TLV{Value: test.value})
+		assert.Errorf(t, err, "Test %q", test.name)
+	}
+}
+
+func TestGenericLabel(t *testing.T) {
+	tlv := GenericLabelTLV(100042)
+	l, err := DecodeGenericLabel(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100042), l)
+
+	l, err = DecodeGenericLabel(&TLV{Value: []byte{0xff, 0xff, 0xff, 0xff}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(MaxLabel), l)
+}
+
+func TestAddressList(t *testing.T) {
+	addrs := []bnet.IP{
+		bnet.IPv4FromOctets(10, 0, 0, 1),
+		bnet.IPv4FromOctets(192, 168, 0, 1),
+	}
+
+	tlv := AddressListTLV(addrs)
+	assert.Equal(t, []byte{0, 1, 10, 0, 0, 1, 192, 168, 0, 1}, tlv.Value)
+
+	d, err := DecodeAddressList(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, addrs, d)
+
+	_, err = DecodeAddressList(&TLV{Value: []byte{0, 1, 10, 0}})
+	assert.Error(t, err)
+}
+
+func TestStatus(t *testing.T) {
+	s := &Status{
+		Code:        StatusShutdown,
+		Fatal:       true,
+		MessageID:   3,
+		MessageType: InitializationMsg,
+	}
+
+	tlv := s.TLV()
+	assert.Equal(t, []byte{0x80, 0, 0, 0x0a, 0, 0, 0, 3, 2, 0}, tlv.Value)
+
+	d, err := DecodeStatus(tlv)
+	assert.NoError(t, err)
+	assert.Equal(t, s, d)
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	btime "github.com/bio-routing/bio-rd/util/time"
+)
+
+type adjacencyKey struct {
+	lsrID  uint32
+	ifName string
+}
+
+type adjacency struct {
+	transportAddress bnet.IP
+	holdTime         uint16
+	expires          time.Time
+}
+
+func (s *Server) helloRoutine(t btime.Ticker) {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-t.C():
+			s.mu.Lock()
+			s.sendHellos()
+			s.expireAdjacencies(now)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// sendHellos sends link hellos on all enabled interfaces. Must be called with s.mu held.
+func (s *Server) sendHellos() {
+	buf := bytes.NewBuffer(nil)
+	s.helloPDU().Serialize(buf)
+
+	for _, ifc := range s.interfaces {
+		if !ifc.enabled {
+			continue
+		}
+
+		err := s.helloConn.send(ifc.name, buf.Bytes())
+		if err != nil {
+			log.Errorf("Unable to send hello on %q: %v", ifc.name, err)
+		}
+	}
+}
+
+func (s *Server) helloPDU() *packet.PDU {
+	params := &packet.CommonHelloParams{
+		HoldTime: s.config.HoldTime,
+	}
+
+	return &packet.PDU{
+		LSRID: s.lsrID,
+		Messages: []*packet.Message{
+			{
+				Type: packet.HelloMsg,
+				TLVs: []*packet.TLV{
+					params.TLV(),
+					packet.IPv4TransportAddressTLV(s.config.TransportAddress),
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) helloReceiver() {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+
+	for {
+		pkt, src, ifName, err := s.helloConn.recv()
+		if err != nil {
+			select {
+			case <-s.stop:
+			default:
+				log.Errorf("Unable to receive hello: %v", err)
+			}
+			return
+		}
+
+		s.processHello(pkt, src, ifName, time.Now())
+	}
+}
+
+func (s *Server) processHello(pkt []byte, src bnet.IP, ifName string, now time.Time) {
+	pdu, err := packet.DecodePDU(pkt)
+	if err != nil {
+		log.Debugf("Unable to decode hello from %s on %q: %v", src.String(), ifName, err)
+		return
+	}
+
+	if pdu.LSRID == s.lsrID {
+		return
+	}
+
+	for _, m := range pdu.Messages {
+		if m.Type != packet.HelloMsg {
+			continue
+		}
+
+		tlv := m.TLV(packet.CommonHelloParamsTLVType)
+		if tlv == nil {
+			log.Debugf("Hello from %s on %q lacks common hello parameters", src.String(), ifName)
+			return
+		}
+
+		params, err := packet.DecodeCommonHelloParams(tlv)
+		if err != nil {
+			log.Debugf("Invalid hello from %s on %q: %v", src.String(), ifName, err)
+			return
+		}
+
+		if params.Targeted {
+			// Extended discovery is not supported
+			return
+		}
+
+		transportAddress := src
+		if tlv := m.TLV(packet.IPv4TransportAddressTLVType); tlv != nil {
+			transportAddress, err = packet.DecodeIPv4TransportAddress(tlv)
+			if err != nil {
+				log.Debugf("Invalid hello from %s on %q: %v", src.String(), ifName, err)
+				return
+			}
+		}
+
+		s.mu.Lock()
+		s.updateAdjacency(pdu.LSRID, ifName, transportAddress, params.HoldTime, now)
+		s.mu.Unlock()
+		return
+	}
+}
+
+// updateAdjacency creates or refreshes an adjacency and makes sure a session to the peer exists. Must be called with s.mu held.
+func (s *Server) updateAdjacency(lsrID uint32, ifName string, transportAddress bnet.IP, holdTime uint16, now time.Time) {
+	ifc, ok := s.interfaces[ifName]
+	if !ok || !ifc.enabled {
+		return
+	}
+
+	// A hold time of 0 requests the default for link hellos. The smaller of both proposals is used.
+	if holdTime == 0 {
+		holdTime = packet.DefaultLinkHelloHoldTime
+	}
+
+	if s.config.HoldTime < holdTime {
+		holdTime = s.config.HoldTime
+	}
+
+	k := adjacencyKey{
+		lsrID:  lsrID,
+		ifName: ifName,
+	}
+
+	adj, ok := s.adjacencies[k]
+	if !ok {
+		adj = &adjacency{}
+		s.adjacencies[k] = adj
+		log.Infof("LDP: Adjacency to %s on %q is now up", ldpID(lsrID), ifName)
+	}
+
+	adj.transportAddress = transportAddress
+	adj.holdTime = holdTime
+	adj.expires = now.Add(time.Duration(holdTime) * time.Second)
+
+	s.ensureSession(lsrID, transportAddress)
+}
+
+// expireAdjacencies removes adjacencies we haven't received hellos for within their hold time. Must be called with s.mu held.
+func (s *Server) expireAdjacencies(now time.Time) {
+	for k, adj := range s.adjacencies {
+		if now.After(adj.expires) {
+			s.removeAdjacency(k, "hold time expired")
+		}
+	}
+}
+
+// removeAdjacency removes an adjacency and shuts down the session to the peer if it was its last one. Must be called with s.mu held.
+func (s *Server) removeAdjacency(k adjacencyKey, reason string) {
+	delete(s.adjacencies, k)
+	log.Infof("LDP: Adjacency to %s on %q is now down: %s", ldpID(k.lsrID), k.ifName, reason)
+
+	for other := range s.adjacencies {
+		if other.lsrID == k.lsrID {
+			return
+		}
+	}
+
+	if sess, ok := s.sessions[k.lsrID]; ok {
+		sess.shutdown(packet.StatusShutdown, "no adjacency left")
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+)
+
+type ldpInterface struct {
+	name    string
+	srv     *Server
+	up      bool
+	enabled bool
+	addrs   []bnet.IP
+}
+
+func newLDPInterface(srv *Server, name string) *ldpInterface {
+	return &ldpInterface{
+		name: name,
+		srv:  srv,
+	}
+}
+
+// InterfaceUp is called by the interface manager if an interface came up
+func (s *Server) InterfaceUp(ifa *interfaces.Interface) {
+	s.interfaceUpdate(ifa)
+}
+
+// InterfaceDown is called by the interface manager if an interface went down
+func (s *Server) InterfaceDown(ifa *interfaces.Interface) {
+	s.interfaceUpdate(ifa)
+}
+
+// AddressAdded is called by the interface manager if an address was added to an interface
+func (s *Server) AddressAdded(ifa *interfaces.Interface, pfx *bnet.Prefix) {
+	s.interfaceUpdate(ifa)
+}
+
+// AddressRemoved is called by the interface manager if an address was removed from an interface
+func (s *Server) AddressRemoved(ifa *interfaces.Interface, pfx *bnet.Prefix) {
+	s.interfaceUpdate(ifa)
+}
+
+// interfaceUpdate applies the state of a managed interface if LDP is enabled on it
+func (s *Server) interfaceUpdate(ifa *interfaces.Interface) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ifc, ok := s.interfaces[ifa.Config.Name]
+	if !ok {
+		return
+	}
+
+	ifc.update(ifa)
+}
+
+// update manages discovery on the interface according to its state. Must be called with s.mu held.
+func (ifc *ldpInterface) update(ifa *interfaces.Interface) {
+	ifc.addrs = make([]bnet.IP, 0, len(ifa.Addrs))
+	for _, a := range ifa.Addrs {
+		ifc.addrs = append(ifc.addrs, *a.Addr())
+	}
+
+	ifc.up = ifa.Up
+	if ifc.up {
+		ifc.srv.enableInterface(ifc)
+		return
+	}
+
+	ifc.srv.disableInterface(ifc)
+}
+
+// enableInterface joins the all routers group on an interface. Must be called with s.mu held.
+func (s *Server) enableInterface(ifc *ldpInterface) {
+	if ifc.enabled || s.helloConn == nil {
+		return
+	}
+
+	err := s.helloConn.joinGroup(ifc.name)
+	if err != nil {
+		log.Errorf("Unable to join multicast group on %q: %v", ifc.name, err)
+		return
+	}
+
+	ifc.enabled = true
+	log.Infof("LDP: Discovery on interface %q is now up", ifc.name)
+}
+
+// disableInterface stops discovery on an interface and drops its adjacencies. Must be called with s.mu held.
+func (s *Server) disableInterface(ifc *ldpInterface) {
+	if !ifc.enabled {
+		return
+	}
+
+	err := s.helloConn.leaveGroup(ifc.name)
+	if err != nil {
+		log.Errorf("Unable to leave multicast group on %q: %v", ifc.name, err)
+	}
+
+	ifc.enabled = false
+	for k := range s.adjacencies {
+		if k.ifName == ifc.name {
+			s.removeAdjacency(k, "interface down")
+		}
+	}
+
+	log.Infof("LDP: Discovery on interface %q is now down", ifc.name)
+}
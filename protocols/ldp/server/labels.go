@@ -0,0 +1,458 @@
+package server
+
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/pkg/errors"
+)
+
+// labelOwner identifies LDP allocations in the label manager
+const labelOwner = "ldp"
+
+// fec is a forwarding equivalence class. It holds the routes learned for a prefix and all label bindings for it.
+type fec struct {
+	prefix     bnet.Prefix
+	paths      []*route.Path
+	localLabel uint32
+
+	// remote holds the labels advertised by peers (by LSR ID). Mappings are retained even if the peer is not our next hop (liberal retention).
+	remote    map[uint32]uint32
+	installed map[LabelBinding]struct{}
+}
+
+// routed returns if we have a route to the FEC. Only routed FECs get a local label.
+func (f *fec) routed() bool {
+	return len(f.paths) > 0
+}
+
+// egress returns if we are the egress LSR for the FEC, i.e. if we have a route without next hop to it
+func (f *fec) egress() bool {
+	for _, p := range f.paths {
+		if nh := pathNextHop(p); nh == nil || (nh.Higher() == 0 && nh.Lower() == 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathNextHop(p *route.Path) *bnet.IP {
+	switch p.Type {
+	case route.BGPPathType, route.StaticPathType, route.FIBPathType, route.RIPPathType:
+		return p.NextHop()
+	}
+
+	return nil
+}
+
+// getFEC gets or creates a FEC. Must be called with s.mu held.
+func (s *Server) getFEC(pfx bnet.Prefix) *fec {
+	f, ok := s.fecs[pfx]
+	if !ok {
+		f = &fec{
+			prefix:    pfx,
+			remote:    make(map[uint32]uint32),
+			installed: make(map[LabelBinding]struct{}),
+		}
+		s.fecs[pfx] = f
+	}
+
+	return f
+}
+
+// gcFEC removes a FEC that doesn't hold any state anymore. Must be called with s.mu held.
+func (s *Server) gcFEC(f *fec) {
+	if len(f.paths) == 0 && len(f.remote) == 0 && len(f.installed) == 0 {
+		delete(s.fecs, f.prefix)
+	}
+}
+
+// AddPath adds a route we distribute a label for
+func (s *Server) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !pfx.Addr().IsIPv4() {
+		return nil
+	}
+
+	f := s.getFEC(*pfx)
+	for _, x := range f.paths {
+		if x.Equal(p) {
+			return nil
+		}
+	}
+
+	f.paths = append(f.paths, p)
+	return s.updateFEC(f)
+}
+
+// AddPathInitialDump adds a route during the initial dump of the RIB
+func (s *Server) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return s.AddPath(pfx, p)
+}
+
+// RemovePath removes a route
+func (s *Server) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.fecs[*pfx]
+	if !ok {
+		return false
+	}
+
+	found := false
+	for i, x := range f.paths {
+		if x.Equal(p) {
+			f.paths = append(f.paths[:i], f.paths[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	err := s.updateFEC(f)
+	if err != nil {
+		log.Errorf("Unable to update FEC %s: %v", pfx.String(), err)
+	}
+
+	return true
+}
+
+// ReplacePath replaces a route
+func (s *Server) ReplacePath(pfx *bnet.Prefix, old *route.Path, new *route.Path) {
+	s.RemovePath(pfx, old)
+	err := s.AddPath(pfx, new)
+	if err != nil {
+		log.Errorf("Unable to add path for %s: %v", pfx.String(), err)
+	}
+}
+
+// RefreshRoute is here to fulfill the RouteTableClient interface
+func (s *Server) RefreshRoute(*bnet.Prefix, []*route.Path) {}
+
+// updateFEC (re)assigns the local label of a FEC after its routes changed, advertises it and updates the FIB.
+// Must be called with s.mu held.
+func (s *Server) updateFEC(f *fec) error {
+	defer s.gcFEC(f)
+
+	if !f.routed() {
+		if f.localLabel != 0 {
+			s.withdrawLocalLabel(f)
+		}
+
+		s.syncFIB(f)
+		return nil
+	}
+
+	label := f.localLabel
+	if f.egress() {
+		label = packet.ImplicitNullLabel
+	} else if label == 0 || label == packet.ImplicitNullLabel {
+		l, err := s.labels.Allocate(labelOwner, f.prefix.String())
+		if err != nil {
+			return errors.Wrap(err, "Unable to allocate label")
+		}
+
+		label = l
+	}
+
+	if label != f.localLabel {
+		s.releaseLocalLabel(f)
+		f.localLabel = label
+
+		// In downstream unsolicited mode a new mapping replaces the previous one
+		for _, sess := range s.sessions {
+			if sess.state == operational {
+				sess.send(labelMessage(packet.LabelMappingMsg, f.prefix, f.localLabel))
+			}
+		}
+	}
+
+	s.syncFIB(f)
+	return nil
+}
+
+// withdrawLocalLabel withdraws the local label of a FEC from all peers. Must be called with s.mu held.
+func (s *Server) withdrawLocalLabel(f *fec) {
+	for _, sess := range s.sessions {
+		if sess.state == operational {
+			sess.send(labelMessage(packet.LabelWithdrawMsg, f.prefix, f.localLabel))
+		}
+	}
+
+	s.releaseLocalLabel(f)
+	f.localLabel = 0
+}
+
+func (s *Server) releaseLocalLabel(f *fec) {
+	if f.localLabel >= labelmanager.MinLabel {
+		s.labels.Release(f.localLabel)
+	}
+}
+
+func labelMessage(t uint16, pfx bnet.Prefix, label uint32) *packet.Message {
+	return &packet.Message{
+		Type: t,
+		TLVs: []*packet.TLV{
+			packet.PrefixFECTLV(pfx),
+			packet.GenericLabelTLV(label),
+		},
+	}
+}
+
+// syncFIB programs the label bindings of a FEC. Packets arriving with our local label are forwarded to the next hops
+// of the FEC using the label the session owning the next hop address advertised. Must be called with s.mu held.
+func (s *Server) syncFIB(f *fec) {
+	want := make(map[LabelBinding]struct{})
+	if f.localLabel != 0 && f.localLabel != packet.ImplicitNullLabel {
+		for _, p := range f.paths {
+			nh := pathNextHop(p)
+			if nh == nil {
+				continue
+			}
+
+			sess := s.sessionByAddress(*nh)
+			if sess == nil {
+				continue
+			}
+
+			remote, ok := f.remote[sess.peerLSRID]
+			if !ok {
+				continue
+			}
+
+			want[LabelBinding{
+				Prefix:      f.prefix,
+				LocalLabel:  f.localLabel,
+				NextHop:     *nh,
+				RemoteLabel: remote,
+			}] = struct{}{}
+		}
+	}
+
+	for b := range f.installed {
+		if _, ok := want[b]; ok {
+			continue
+		}
+
+		delete(f.installed, b)
+		if s.fib == nil {
+			continue
+		}
+
+		err := s.fib.RemoveLabelBinding(b)
+		if err != nil {
+			log.Errorf("Unable to remove label binding for %s: %v", f.prefix.String(), err)
+		}
+	}
+
+	for b := range want {
+		if _, ok := f.installed[b]; ok {
+			continue
+		}
+
+		f.installed[b] = struct{}{}
+		if s.fib == nil {
+			continue
+		}
+
+		err := s.fib.AddLabelBinding(b)
+		if err != nil {
+			log.Errorf("Unable to add label binding for %s: %v", f.prefix.String(), err)
+		}
+	}
+}
+
+func (s *Server) syncAllFIB() {
+	for _, f := range s.fecs {
+		s.syncFIB(f)
+		s.gcFEC(f)
+	}
+}
+
+// sessionByAddress finds the operational session of the peer an address belongs to. Must be called with s.mu held.
+func (s *Server) sessionByAddress(addr bnet.IP) *session {
+	for _, sess := range s.sessions {
+		if sess.state != operational {
+			continue
+		}
+
+		if _, ok := sess.addresses[addr]; ok {
+			return sess
+		}
+	}
+
+	return nil
+}
+
+// sessionUp advertises our addresses and label mappings to a new peer. Must be called with s.mu held.
+func (s *Server) sessionUp(sess *session) {
+	log.Infof("LDP: Session to %s is now operational", ldpID(sess.peerLSRID))
+
+	sess.send(&packet.Message{
+		Type: packet.AddressMsg,
+		TLVs: []*packet.TLV{
+			packet.AddressListTLV(s.localAddresses()),
+		},
+	})
+
+	for _, f := range s.fecs {
+		if f.localLabel != 0 {
+			sess.send(labelMessage(packet.LabelMappingMsg, f.prefix, f.localLabel))
+		}
+	}
+}
+
+// sessionDown drops all state learned from a peer. Must be called with s.mu held.
+func (s *Server) sessionDown(sess *session) {
+	for _, f := range s.fecs {
+		delete(f.remote, sess.peerLSRID)
+	}
+
+	s.syncAllFIB()
+}
+
+func (sess *session) processOperationalMessage(m *packet.Message) error {
+	s := sess.srv
+
+	switch m.Type {
+	case packet.KeepAliveMsg:
+		return nil
+	case packet.AddressMsg, packet.AddressWithdrawMsg:
+		tlv := m.TLV(packet.AddressListTLVType)
+		if tlv == nil {
+			return errors.New("Address message without address list")
+		}
+
+		addrs, err := packet.DecodeAddressList(tlv)
+		if err != nil {
+			sess.send(notification(packet.StatusUnsupportedAddressFamily, false))
+			return errors.Wrap(err, "Invalid address list")
+		}
+
+		for _, a := range addrs {
+			if m.Type == packet.AddressMsg {
+				sess.addresses[a] = struct{}{}
+			} else {
+				delete(sess.addresses, a)
+			}
+		}
+
+		s.syncAllFIB()
+		return nil
+	case packet.LabelMappingMsg:
+		elements, label, err := decodeLabelMessage(m)
+		if err != nil {
+			return errors.Wrap(err, "Invalid label mapping")
+		}
+
+		for _, e := range elements {
+			if e.Type != packet.PrefixFECElement {
+				continue
+			}
+
+			f := s.getFEC(e.Prefix)
+			f.remote[sess.peerLSRID] = label
+			s.syncFIB(f)
+		}
+
+		return nil
+	case packet.LabelWithdrawMsg:
+		elements, _, err := decodeLabelMessage(m)
+		if err != nil {
+			return errors.Wrap(err, "Invalid label withdraw")
+		}
+
+		for _, e := range elements {
+			if e.Type == packet.WildcardFECElement {
+				for _, f := range s.fecs {
+					s.removeRemoteLabel(f, sess)
+				}
+				continue
+			}
+
+			if f, ok := s.fecs[e.Prefix]; ok {
+				s.removeRemoteLabel(f, sess)
+			}
+		}
+
+		release := &packet.Message{
+			Type: packet.LabelReleaseMsg,
+			TLVs: m.TLVs,
+		}
+		sess.send(release)
+		return nil
+	case packet.LabelRequestMsg:
+		elements, err := decodeFEC(m)
+		if err != nil {
+			return errors.Wrap(err, "Invalid label request")
+		}
+
+		for _, e := range elements {
+			f, ok := s.fecs[e.Prefix]
+			if e.Type != packet.PrefixFECElement || !ok || f.localLabel == 0 {
+				sess.send(notification(packet.StatusNoRoute, false))
+				continue
+			}
+
+			sess.send(labelMessage(packet.LabelMappingMsg, f.prefix, f.localLabel))
+		}
+
+		return nil
+	case packet.LabelReleaseMsg:
+		return nil
+	}
+
+	if !m.UnknownBit {
+		sess.send(notification(packet.StatusUnknownMessageType, false))
+	}
+
+	return nil
+}
+
+// removeRemoteLabel removes the mapping a peer advertised for a FEC. Must be called with s.mu held.
+func (s *Server) removeRemoteLabel(f *fec, sess *session) {
+	delete(f.remote, sess.peerLSRID)
+	s.syncFIB(f)
+	s.gcFEC(f)
+}
+
+func decodeFEC(m *packet.Message) ([]packet.FECElement, error) {
+	tlv := m.TLV(packet.FECTLVType)
+	if tlv == nil {
+		return nil, errors.New("Missing FEC")
+	}
+
+	return packet.DecodeFEC(tlv)
+}
+
+// decodeLabelMessage decodes the FEC and the (optional) generic label of a label message
+func decodeLabelMessage(m *packet.Message) ([]packet.FECElement, uint32, error) {
+	elements, err := decodeFEC(m)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tlv := m.TLV(packet.GenericLabelTLVType)
+	if tlv == nil {
+		if m.Type == packet.LabelMappingMsg {
+			return nil, 0, errors.New("Missing label")
+		}
+
+		return elements, 0, nil
+	}
+
+	label, err := packet.DecodeGenericLabel(tlv)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return elements, label, nil
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
+	btime "github.com/bio-routing/bio-rd/util/time"
+	"github.com/pkg/errors"
+)
+
+// LabelBinding is a label forwarding entry: Packets received with LocalLabel are sent to NextHop with RemoteLabel
+type LabelBinding struct {
+	Prefix      bnet.Prefix
+	LocalLabel  uint32
+	NextHop     bnet.IP
+	RemoteLabel uint32
+}
+
+// LabelFIB is an MPLS capable forwarding table label bindings are programmed into
+type LabelFIB interface {
+	AddLabelBinding(b LabelBinding) error
+	RemoveLabelBinding(b LabelBinding) error
+}
+
+// Server represents an LDP server
+type Server struct {
+	config         *config.LDPConfig
+	lsrID          uint32
+	ifm            *interfaces.Manager
+	fib            LabelFIB
+	labels         *labelmanager.LabelManager
+	flightRecorder *flightrecorder.Registry
+
+	// mu protects all protocol state below
+	mu          sync.Mutex
+	interfaces  map[string]*ldpInterface
+	adjacencies map[adjacencyKey]*adjacency
+	sessions    map[uint32]*session
+	fecs        map[bnet.Prefix]*fec
+
+	helloConn helloConn
+	listener  net.Listener
+	dial      func(local, remote bnet.IP) (net.Conn, error)
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New creates a new LDP server. Interface state is taken from ifm, local labels are allocated from lm.
+func New(cfg *config.LDPConfig, ifm *interfaces.Manager, fib LabelFIB, lm *labelmanager.LabelManager) *Server {
+	if cfg.HelloInterval == 0 {
+		cfg.HelloInterval = config.DefaultLDPHelloInterval
+	}
+
+	if cfg.HoldTime == 0 {
+		cfg.HoldTime = config.DefaultLDPHoldTime
+	}
+
+	if cfg.KeepaliveTime == 0 {
+		cfg.KeepaliveTime = config.DefaultLDPKeepaliveTime
+	}
+
+	if cfg.TransportAddress == (bnet.IP{}) {
+		cfg.TransportAddress = cfg.LSRID
+	}
+
+	s := &Server{
+		config:      cfg,
+		lsrID:       cfg.LSRID.ToUint32(),
+		ifm:         ifm,
+		fib:         fib,
+		labels:      lm,
+		interfaces:  make(map[string]*ldpInterface),
+		adjacencies: make(map[adjacencyKey]*adjacency),
+		sessions:    make(map[uint32]*session),
+		fecs:        make(map[bnet.Prefix]*fec),
+		dial:        dialTCP,
+		stop:        make(chan struct{}),
+	}
+
+	for i := range cfg.Interfaces {
+		s.AddInterface(&cfg.Interfaces[i])
+	}
+
+	if ifm != nil {
+		ifm.Subscribe(s)
+	}
+
+	return s
+}
+
+// SetFlightRecorder sets the flight recorder session state transitions are recorded to
+func (s *Server) SetFlightRecorder(r *flightrecorder.Registry) {
+	r.SetUnexpected("ldp", func(from string, to string) bool {
+		return from == stateName(operational)
+	})
+	s.flightRecorder = r
+}
+
+// Start opens the discovery socket and the session listener and starts the protocol
+func (s *Server) Start() error {
+	hc, err := newBIOHelloConn()
+	if err != nil {
+		return errors.Wrap(err, "Unable to open discovery socket")
+	}
+
+	l, err := net.Listen("tcp4", net.JoinHostPort(s.config.TransportAddress.String(), strconv.Itoa(packet.Port)))
+	if err != nil {
+		hc.close()
+		return errors.Wrap(err, "Unable to listen for sessions")
+	}
+
+	s.start(hc, l, btime.NewBIOTicker(time.Duration(s.config.HelloInterval)*time.Second))
+	return nil
+}
+
+func (s *Server) start(hc helloConn, l net.Listener, helloTicker btime.Ticker) {
+	s.mu.Lock()
+	s.helloConn = hc
+	s.listener = l
+	for _, ifc := range s.interfaces {
+		if ifc.up {
+			s.enableInterface(ifc)
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.helloRoutine(helloTicker)
+
+	s.wg.Add(1)
+	go s.helloReceiver()
+
+	if l != nil {
+		s.wg.Add(1)
+		go s.acceptRoutine()
+	}
+}
+
+// Stop stops the server and tears down all sessions
+func (s *Server) Stop() {
+	close(s.stop)
+	if s.ifm != nil {
+		s.ifm.Unsubscribe(s)
+	}
+
+	s.mu.Lock()
+	for _, sess := range s.sessions {
+		sess.shutdown(packet.StatusShutdown, "server stopped")
+	}
+
+	if s.helloConn != nil {
+		s.helloConn.close()
+	}
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	s.labels.ReleaseOwner(labelOwner)
+}
+
+// AddInterface enables LDP discovery on an interface
+func (s *Server) AddInterface(ifcfg *config.LDPInterfaceConfig) error {
+	s.mu.Lock()
+	if _, ok := s.interfaces[ifcfg.Name]; ok {
+		s.mu.Unlock()
+		return fmt.Errorf("Interface %q exists already", ifcfg.Name)
+	}
+
+	ifc := newLDPInterface(s, ifcfg.Name)
+	s.interfaces[ifcfg.Name] = ifc
+	if s.ifm != nil {
+		if ifa := s.ifm.GetInterface(ifc.name); ifa != nil {
+			ifc.update(ifa)
+		}
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RemoveInterface disables LDP discovery on an interface
+func (s *Server) RemoveInterface(name string) error {
+	s.mu.Lock()
+	ifc, ok := s.interfaces[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("Interface %q not found", name)
+	}
+
+	s.disableInterface(ifc)
+	delete(s.interfaces, name)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// localAddresses returns the addresses advertised to peers. Must be called with s.mu held.
+func (s *Server) localAddresses() []bnet.IP {
+	ret := []bnet.IP{s.config.TransportAddress}
+	seen := map[bnet.IP]struct{}{
+		s.config.TransportAddress: {},
+	}
+
+	for _, ifc := range s.interfaces {
+		for _, a := range ifc.addrs {
+			if _, ok := seen[a]; ok || !a.IsIPv4() {
+				continue
+			}
+
+			seen[a] = struct{}{}
+			ret = append(ret, a)
+		}
+	}
+
+	return ret
+}
+
+func dialTCP(local, remote bnet.IP) (net.Conn, error) {
+	d := net.Dialer{
+		LocalAddr: &net.TCPAddr{IP: local.ToNetIP()},
+		Timeout:   10 * time.Second,
+	}
+
+	return d.Dial("tcp4", net.JoinHostPort(remote.String(), strconv.Itoa(packet.Port)))
+}
+
+func ldpID(lsrID uint32) string {
+	ip := bnet.IPv4(lsrID)
+	return ip.String() + ":0"
+}
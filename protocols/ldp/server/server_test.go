@@ -0,0 +1,362 @@
+package server
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/device"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"github.com/bio-routing/bio-rd/protocols/mpls/labelmanager"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFIB struct {
+	mu       sync.Mutex
+	bindings map[LabelBinding]struct{}
+}
+
+func newMockFIB() *mockFIB {
+	return &mockFIB{
+		bindings: make(map[LabelBinding]struct{}),
+	}
+}
+
+func (m *mockFIB) AddLabelBinding(b LabelBinding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bindings[b] = struct{}{}
+	return nil
+}
+
+func (m *mockFIB) RemoveLabelBinding(b LabelBinding) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.bindings, b)
+	return nil
+}
+
+func (m *mockFIB) has(b LabelBinding) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.bindings[b]
+	return ok
+}
+
+func (m *mockFIB) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.bindings)
+}
+
+// mockUpdater is a device server keeping the last subscribed client
+type mockUpdater struct {
+	c device.Client
+}
+
+func (m *mockUpdater) Subscribe(c device.Client, name string) {
+	m.c = c
+}
+
+func (m *mockUpdater) Unsubscribe(c device.Client, name string) {}
+
+func testLabelManager() *labelmanager.LabelManager {
+	lm, err := labelmanager.New(config.DefaultMPLSConfig())
+	if err != nil {
+		panic(err)
+	}
+
+	return lm
+}
+
+func newTestServer(lsrID bnet.IP, fib LabelFIB) (*Server, *mockHelloConn) {
+	s := New(&config.LDPConfig{
+		LSRID: lsrID,
+		Interfaces: []config.LDPInterfaceConfig{
+			{
+				Name: "eth0",
+			},
+		},
+	}, nil, fib, testLabelManager())
+
+	hc := newMockHelloConn()
+	s.helloConn = hc
+	s.InterfaceUp(&interfaces.Interface{
+		Config: interfaces.Config{Name: "eth0"},
+		Up:     true,
+	})
+
+	return s, hc
+}
+
+func helloFrom(s *Server) []byte {
+	buf := bytes.NewBuffer(nil)
+	s.helloPDU().Serialize(buf)
+	return buf.Bytes()
+}
+
+func waitFor(t *testing.T, f func() bool, msg string) {
+	for i := 0; i < 200; i++ {
+		if f() {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Timeout waiting for %s", msg)
+}
+
+func TestInterfaces(t *testing.T) {
+	s, hc := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 1), nil)
+	assert.True(t, hc.joined["eth0"])
+	assert.Error(t, s.AddInterface(&config.LDPInterfaceConfig{Name: "eth0"}))
+
+	addr := bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 0, 1), 24).Ptr()
+	s.AddressAdded(&interfaces.Interface{
+		Config: interfaces.Config{Name: "eth0"},
+		Up:     true,
+		Addrs:  []*bnet.Prefix{addr},
+	}, addr)
+	assert.Equal(t, []bnet.IP{bnet.IPv4FromOctets(10, 0, 0, 1), bnet.IPv4FromOctets(192, 168, 0, 1)}, s.localAddresses())
+
+	s.InterfaceDown(&interfaces.Interface{
+		Config: interfaces.Config{Name: "eth0"},
+	})
+	assert.False(t, hc.joined["eth0"])
+
+	assert.NoError(t, s.RemoveInterface("eth0"))
+	assert.Error(t, s.RemoveInterface("eth0"))
+}
+
+func TestInterfaceManager(t *testing.T) {
+	ds := &mockUpdater{}
+	m := interfaces.NewManager(ds)
+	assert.NoError(t, m.AddInterface(interfaces.Config{Name: "eth1"}))
+	ds.c.DeviceUpdate(&device.Device{
+		Name:      "eth1",
+		OperState: device.IfOperUp,
+		Addrs: []*bnet.Prefix{
+			bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 1, 1), 24).Ptr(),
+		},
+	})
+
+	s := New(&config.LDPConfig{
+		LSRID: bnet.IPv4FromOctets(10, 0, 0, 1),
+	}, m, nil, testLabelManager())
+	hc := newMockHelloConn()
+	s.helloConn = hc
+
+	// Interfaces LDP is enabled on at runtime take their state from the manager
+	assert.NoError(t, s.AddInterface(&config.LDPInterfaceConfig{Name: "eth1"}))
+	assert.True(t, hc.joined["eth1"])
+	assert.Equal(t, []bnet.IP{bnet.IPv4FromOctets(10, 0, 0, 1), bnet.IPv4FromOctets(192, 168, 1, 1)}, s.localAddresses())
+
+	ds.c.DeviceUpdate(&device.Device{
+		Name:      "eth1",
+		OperState: device.IfOperDown,
+	})
+	assert.False(t, hc.joined["eth1"])
+}
+
+func TestSendHellos(t *testing.T) {
+	s, hc := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 1), nil)
+	s.sendHellos()
+
+	assert.Equal(t, 1, len(hc.sent))
+	assert.Equal(t, "eth0", hc.sent[0].ifName)
+
+	pdu, err := packet.DecodePDU(hc.sent[0].pkt)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x0a000001), pdu.LSRID)
+
+	params, err := packet.DecodeCommonHelloParams(pdu.Messages[0].TLV(packet.CommonHelloParamsTLVType))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(config.DefaultLDPHoldTime), params.HoldTime)
+
+	addr, err := packet.DecodeIPv4TransportAddress(pdu.Messages[0].TLV(packet.IPv4TransportAddressTLVType))
+	assert.NoError(t, err)
+	assert.Equal(t, bnet.IPv4FromOctets(10, 0, 0, 1), addr)
+}
+
+func TestProcessHello(t *testing.T) {
+	s, _ := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 1), nil)
+	peer, _ := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 2), nil)
+	now := time.Now()
+
+	// Own hellos and hellos on interfaces without LDP are ignored
+	s.processHello(helloFrom(s), bnet.IPv4FromOctets(10, 0, 0, 1), "eth0", now)
+	s.processHello(helloFrom(peer), bnet.IPv4FromOctets(10, 0, 0, 2), "eth1", now)
+	assert.Equal(t, 0, len(s.adjacencies))
+
+	s.processHello(helloFrom(peer), bnet.IPv4FromOctets(192, 168, 0, 2), "eth0", now)
+	k := adjacencyKey{lsrID: 0x0a000002, ifName: "eth0"}
+	assert.Equal(t, &adjacency{
+		transportAddress: bnet.IPv4FromOctets(10, 0, 0, 2),
+		holdTime:         15,
+		expires:          now.Add(15 * time.Second),
+	}, s.adjacencies[k])
+
+	// The peer has the higher transport address so we are the passive side
+	sess := s.sessions[0x0a000002]
+	if assert.NotNil(t, sess) {
+		assert.False(t, sess.active)
+		assert.Equal(t, nonExistent, sess.state)
+	}
+
+	s.expireAdjacencies(now.Add(10 * time.Second))
+	assert.Equal(t, 1, len(s.adjacencies))
+
+	s.expireAdjacencies(now.Add(16 * time.Second))
+	assert.Equal(t, 0, len(s.adjacencies))
+	assert.Equal(t, 0, len(s.sessions))
+	assert.True(t, sess.closed)
+}
+
+func TestSession(t *testing.T) {
+	fibA := newMockFIB()
+	fibB := newMockFIB()
+	a, _ := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 1), fibA)
+	b, _ := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 2), fibB)
+
+	b.dial = func(local, remote bnet.IP) (net.Conn, error) {
+		assert.Equal(t, bnet.IPv4FromOctets(10, 0, 0, 2), local)
+		assert.Equal(t, bnet.IPv4FromOctets(10, 0, 0, 1), remote)
+
+		c1, c2 := net.Pipe()
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.handleConn(c2)
+		}()
+
+		return c1, nil
+	}
+
+	now := time.Now()
+	a.processHello(helloFrom(b), bnet.IPv4FromOctets(192, 168, 0, 2), "eth0", now)
+	b.processHello(helloFrom(a), bnet.IPv4FromOctets(192, 168, 0, 1), "eth0", now)
+
+	operational := func(s *Server, lsrID uint32) func() bool {
+		return func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			sess, ok := s.sessions[lsrID]
+			return ok && sess.state == operational && len(sess.addresses) > 0
+		}
+	}
+	waitFor(t, operational(a, 0x0a000002), "session on A")
+	waitFor(t, operational(b, 0x0a000001), "session on B")
+
+	a.mu.Lock()
+	assert.Equal(t, uint16(config.DefaultLDPKeepaliveTime), a.sessions[0x0a000002].keepaliveTime)
+	a.mu.Unlock()
+
+	// A is egress for 10.1.0.0/16 and advertises implicit null
+	pfx1 := bnet.NewPfx(bnet.IPv4FromOctets(10, 1, 0, 0), 16)
+	assert.NoError(t, a.AddPath(pfx1.Ptr(), staticPath(bnet.IPv4(0))))
+	assert.NoError(t, b.AddPath(pfx1.Ptr(), staticPath(bnet.IPv4FromOctets(10, 0, 0, 1))))
+
+	b1 := LabelBinding{
+		Prefix:      pfx1,
+		LocalLabel:  config.DefaultDynamicLabelStart,
+		NextHop:     bnet.IPv4FromOctets(10, 0, 0, 1),
+		RemoteLabel: packet.ImplicitNullLabel,
+	}
+	waitFor(t, func() bool { return fibB.has(b1) }, "binding for 10.1.0.0/16")
+
+	// Mappings are retained even before B has a route (liberal retention)
+	pfx2 := bnet.NewPfx(bnet.IPv4FromOctets(10, 2, 0, 0), 16)
+	assert.NoError(t, a.AddPath(pfx2.Ptr(), staticPath(bnet.IPv4FromOctets(192, 168, 1, 1))))
+	waitFor(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		f, ok := b.fecs[pfx2]
+		return ok && f.remote[0x0a000001] == config.DefaultDynamicLabelStart
+	}, "mapping for 10.2.0.0/16")
+
+	assert.NoError(t, b.AddPath(pfx2.Ptr(), staticPath(bnet.IPv4FromOctets(10, 0, 0, 1))))
+	b2 := LabelBinding{
+		Prefix:      pfx2,
+		LocalLabel:  config.DefaultDynamicLabelStart + 1,
+		NextHop:     bnet.IPv4FromOctets(10, 0, 0, 1),
+		RemoteLabel: config.DefaultDynamicLabelStart,
+	}
+	assert.True(t, fibB.has(b2))
+	assert.Equal(t, 0, fibA.len(), "A has no session for its next hop")
+
+	// Withdrawing the route on A removes the binding on B
+	assert.True(t, a.RemovePath(pfx1.Ptr(), staticPath(bnet.IPv4(0))))
+	waitFor(t, func() bool { return !fibB.has(b1) }, "withdraw of 10.1.0.0/16")
+	assert.True(t, fibB.has(b2))
+
+	// Session teardown removes all bindings learned from the peer
+	a.Stop()
+	waitFor(t, func() bool { return fibB.len() == 0 }, "bindings to be removed")
+
+	b.mu.Lock()
+	assert.Equal(t, 0, len(b.sessions))
+	b.mu.Unlock()
+	b.Stop()
+}
+
+func TestSessionRejectedWithoutAdjacency(t *testing.T) {
+	a, _ := newTestServer(bnet.IPv4FromOctets(10, 0, 0, 1), nil)
+	c1, c2 := net.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		a.handleConn(c2)
+		close(done)
+	}()
+
+	params := &packet.CommonSessionParams{
+		ProtocolVersion: packet.Version,
+		KeepAliveTime:   180,
+		ReceiverLSRID:   0x0a000001,
+	}
+	p := &packet.PDU{
+		LSRID: 0x0a000002,
+		Messages: []*packet.Message{
+			{
+				Type: packet.InitializationMsg,
+				TLVs: []*packet.TLV{params.TLV()},
+			},
+		},
+	}
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	go c1.Write(buf.Bytes())
+	pdu, err := readPDU(c1)
+	assert.NoError(t, err)
+
+	status, err := packet.DecodeStatus(pdu.Messages[0].TLV(packet.StatusTLVType))
+	assert.NoError(t, err)
+	assert.Equal(t, &packet.Status{
+		Code:  packet.StatusSessionRejectedNoHello,
+		Fatal: true,
+	}, status)
+	<-done
+}
+
+func staticPath(nh bnet.IP) *route.Path {
+	return &route.Path{
+		Type: route.StaticPathType,
+		StaticPath: &route.StaticPath{
+			NextHop: nh.Ptr(),
+		},
+	}
+}
@@ -0,0 +1,530 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/pkg/errors"
+)
+
+const (
+	// initTimeout limits the time a passive LSR waits for the Initialization message on a new connection
+	initTimeout = 15 * time.Second
+
+	// flushTimeout limits the time pending messages are tried to be sent when a session is closed
+	flushTimeout = time.Second
+)
+
+type sessionState uint8
+
+const (
+	nonExistent sessionState = iota
+	initialized
+	openSent
+	openRec
+	operational
+)
+
+func stateName(s sessionState) string {
+	switch s {
+	case nonExistent:
+		return "non-existent"
+	case initialized:
+		return "initialized"
+	case openSent:
+		return "opensent"
+	case openRec:
+		return "openrec"
+	case operational:
+		return "operational"
+	}
+
+	return "unknown"
+}
+
+// session is an LDP session to a peer. All fields except the send queue are protected by srv.mu.
+type session struct {
+	srv              *Server
+	peerLSRID        uint32
+	transportAddress bnet.IP
+	active           bool
+	state            sessionState
+	closed           bool
+	conn             net.Conn
+	keepaliveTime    uint16
+	nextMessageID    uint32
+	addresses        map[bnet.IP]struct{}
+	done             chan struct{}
+
+	queueMu     sync.Mutex
+	queue       [][]byte
+	queueNotify chan struct{}
+}
+
+func newSession(srv *Server, peerLSRID uint32, transportAddress bnet.IP) *session {
+	return &session{
+		srv:              srv,
+		peerLSRID:        peerLSRID,
+		transportAddress: transportAddress,
+		active:           srv.config.TransportAddress.Compare(&transportAddress) > 0,
+		keepaliveTime:    srv.config.KeepaliveTime,
+		nextMessageID:    1,
+		addresses:        make(map[bnet.IP]struct{}),
+		done:             make(chan struct{}),
+		queueNotify:      make(chan struct{}, 1),
+	}
+}
+
+// ensureSession creates a session to a peer we have an adjacency with. Must be called with s.mu held.
+func (s *Server) ensureSession(lsrID uint32, transportAddress bnet.IP) {
+	if _, ok := s.sessions[lsrID]; ok {
+		return
+	}
+
+	sess := newSession(s, lsrID, transportAddress)
+	s.sessions[lsrID] = sess
+
+	// The LSR with the higher transport address takes the active role and opens the TCP connection
+	if sess.active {
+		s.wg.Add(1)
+		go sess.connect()
+	}
+}
+
+func (sess *session) connect() {
+	defer crashdump.Recover()
+	defer sess.srv.wg.Done()
+
+	s := sess.srv
+	conn, err := s.dial(s.config.TransportAddress, sess.transportAddress)
+
+	s.mu.Lock()
+	if err != nil {
+		log.Infof("LDP: Unable to connect to %s: %v", ldpID(sess.peerLSRID), err)
+		sess.close("connect failed")
+		s.mu.Unlock()
+		return
+	}
+
+	if sess.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+
+	sess.attach(conn)
+	sess.sendInit()
+	sess.setState(openSent, "initialization sent")
+	s.mu.Unlock()
+
+	sess.receiveLoop()
+}
+
+// handleConn handles a connection accepted by the passive side of a session
+func (s *Server) handleConn(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(initTimeout))
+	pdu, err := readPDU(conn)
+	if err != nil {
+		log.Infof("LDP: Unable to read initialization from %s: %v", conn.RemoteAddr().String(), err)
+		conn.Close()
+		return
+	}
+
+	s.mu.Lock()
+	sess, ok := s.sessions[pdu.LSRID]
+	if !ok || sess.active || sess.conn != nil {
+		s.mu.Unlock()
+		log.Infof("LDP: Rejecting connection from %s: No matching adjacency", ldpID(pdu.LSRID))
+		rejectConn(conn, s.lsrID, packet.StatusSessionRejectedNoHello)
+		return
+	}
+
+	sess.attach(conn)
+	sess.processPDU(pdu)
+	s.mu.Unlock()
+
+	sess.receiveLoop()
+}
+
+func (s *Server) acceptRoutine() {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.stop:
+			default:
+				log.Errorf("Unable to accept connection: %v", err)
+			}
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// rejectConn sends a fatal notification on a connection not belonging to any session and closes it
+func rejectConn(conn net.Conn, lsrID uint32, code uint32) {
+	p := &packet.PDU{
+		LSRID: lsrID,
+		Messages: []*packet.Message{
+			notification(code, true),
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	conn.SetWriteDeadline(time.Now().Add(flushTimeout))
+	conn.Write(buf.Bytes())
+	conn.Close()
+}
+
+// attach attaches a connection to the session and starts sending. Must be called with s.mu held.
+func (sess *session) attach(conn net.Conn) {
+	sess.conn = conn
+	sess.setState(initialized, "connection established")
+
+	sess.srv.wg.Add(1)
+	go sess.sender()
+}
+
+func (sess *session) setState(state sessionState, reason string) {
+	if sess.state == state {
+		return
+	}
+
+	sess.srv.flightRecorder.Recorder("ldp", ldpID(sess.peerLSRID)).Record(stateName(sess.state), stateName(state), reason)
+	sess.state = state
+}
+
+func (sess *session) receiveLoop() {
+	s := sess.srv
+	for {
+		s.mu.Lock()
+		holdTime := time.Duration(sess.keepaliveTime) * time.Second
+		s.mu.Unlock()
+
+		sess.conn.SetReadDeadline(time.Now().Add(holdTime))
+		pdu, err := readPDU(sess.conn)
+
+		s.mu.Lock()
+		if sess.closed {
+			s.mu.Unlock()
+			return
+		}
+
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				sess.shutdown(packet.StatusKeepAliveTimerExpired, "keepalive timer expired")
+			} else {
+				log.Infof("LDP: Session to %s failed: %v", ldpID(sess.peerLSRID), err)
+				sess.close("read failed")
+			}
+
+			s.mu.Unlock()
+			return
+		}
+
+		sess.processPDU(pdu)
+		s.mu.Unlock()
+	}
+}
+
+// readPDU reads a single PDU from a stream
+func readPDU(r io.Reader) (*packet.PDU, error) {
+	hdr := make([]byte, 4)
+	_, err := io.ReadFull(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := packet.PDULength(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, l)
+	copy(buf, hdr)
+	_, err = io.ReadFull(r, buf[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	return packet.DecodePDU(buf)
+}
+
+// send queues messages to be sent to the peer. Must be called with s.mu held.
+func (sess *session) send(msgs ...*packet.Message) {
+	if sess.conn == nil || sess.closed {
+		return
+	}
+
+	for _, m := range msgs {
+		m.ID = sess.nextMessageID
+		sess.nextMessageID++
+	}
+
+	p := &packet.PDU{
+		LSRID:    sess.srv.lsrID,
+		Messages: msgs,
+	}
+
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	sess.queueMu.Lock()
+	sess.queue = append(sess.queue, buf.Bytes())
+	sess.queueMu.Unlock()
+
+	select {
+	case sess.queueNotify <- struct{}{}:
+	default:
+	}
+}
+
+// sender writes queued PDUs to the connection. Once the session is done pending PDUs are flushed and the connection is closed.
+func (sess *session) sender() {
+	defer crashdump.Recover()
+	defer sess.srv.wg.Done()
+
+	for {
+		select {
+		case <-sess.queueNotify:
+			if err := sess.flush(); err != nil {
+				log.Infof("LDP: Unable to send to %s: %v", ldpID(sess.peerLSRID), err)
+				sess.conn.Close()
+				<-sess.done
+				return
+			}
+		case <-sess.done:
+			sess.conn.SetWriteDeadline(time.Now().Add(flushTimeout))
+			sess.flush()
+			sess.conn.Close()
+			return
+		}
+	}
+}
+
+func (sess *session) flush() error {
+	sess.queueMu.Lock()
+	q := sess.queue
+	sess.queue = nil
+	sess.queueMu.Unlock()
+
+	for _, pdu := range q {
+		_, err := sess.conn.Write(pdu)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sess *session) keepaliveRoutine(interval time.Duration) {
+	defer crashdump.Recover()
+	defer sess.srv.wg.Done()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-sess.done:
+			return
+		case <-t.C:
+			sess.srv.mu.Lock()
+			sess.send(&packet.Message{Type: packet.KeepAliveMsg})
+			sess.srv.mu.Unlock()
+		}
+	}
+}
+
+func notification(code uint32, fatal bool) *packet.Message {
+	status := &packet.Status{
+		Code:  code,
+		Fatal: fatal,
+	}
+
+	return &packet.Message{
+		Type: packet.NotificationMsg,
+		TLVs: []*packet.TLV{
+			status.TLV(),
+		},
+	}
+}
+
+// shutdown sends a fatal notification and closes the session. Must be called with s.mu held.
+func (sess *session) shutdown(code uint32, reason string) {
+	sess.send(notification(code, true))
+	sess.close(reason)
+}
+
+// close closes the session and withdraws everything learned from the peer. Must be called with s.mu held.
+func (sess *session) close(reason string) {
+	if sess.closed {
+		return
+	}
+
+	wasOperational := sess.state == operational
+	sess.closed = true
+	sess.setState(nonExistent, reason)
+	close(sess.done)
+
+	s := sess.srv
+	if s.sessions[sess.peerLSRID] == sess {
+		delete(s.sessions, sess.peerLSRID)
+	}
+
+	if wasOperational {
+		log.Infof("LDP: Session to %s is now down: %s", ldpID(sess.peerLSRID), reason)
+		s.sessionDown(sess)
+	}
+}
+
+func (sess *session) sendInit() {
+	params := &packet.CommonSessionParams{
+		ProtocolVersion: packet.Version,
+		KeepAliveTime:   sess.srv.config.KeepaliveTime,
+		MaxPDULength:    packet.MaxPDULength,
+		ReceiverLSRID:   sess.peerLSRID,
+	}
+
+	sess.send(&packet.Message{
+		Type: packet.InitializationMsg,
+		TLVs: []*packet.TLV{
+			params.TLV(),
+		},
+	})
+}
+
+// processPDU runs the session state machine for a received PDU. Must be called with s.mu held.
+func (sess *session) processPDU(pdu *packet.PDU) {
+	if pdu.LSRID != sess.peerLSRID || pdu.LabelSpace != 0 {
+		sess.shutdown(packet.StatusBadLDPIdentifier, "bad LDP identifier")
+		return
+	}
+
+	for _, m := range pdu.Messages {
+		if sess.closed {
+			return
+		}
+
+		err := sess.processMessage(m)
+		if err != nil {
+			log.Infof("LDP: Error processing message from %s: %v", ldpID(sess.peerLSRID), err)
+		}
+	}
+}
+
+func (sess *session) processMessage(m *packet.Message) error {
+	if m.Type == packet.NotificationMsg {
+		return sess.processNotification(m)
+	}
+
+	switch sess.state {
+	case initialized, openSent:
+		if m.Type != packet.InitializationMsg {
+			sess.shutdown(packet.StatusShutdown, "unexpected message")
+			return nil
+		}
+
+		err := sess.processInit(m)
+		if err != nil {
+			return err
+		}
+
+		if sess.state == initialized {
+			sess.sendInit()
+		}
+
+		sess.send(&packet.Message{Type: packet.KeepAliveMsg})
+		sess.setState(openRec, "initialization received")
+
+		sess.srv.wg.Add(1)
+		go sess.keepaliveRoutine(time.Duration(sess.keepaliveTime) * time.Second / 3)
+		return nil
+	case openRec:
+		if m.Type != packet.KeepAliveMsg {
+			sess.shutdown(packet.StatusShutdown, "unexpected message")
+			return nil
+		}
+
+		sess.setState(operational, "keepalive received")
+		sess.srv.sessionUp(sess)
+		return nil
+	case operational:
+		return sess.processOperationalMessage(m)
+	}
+
+	return nil
+}
+
+func (sess *session) processNotification(m *packet.Message) error {
+	tlv := m.TLV(packet.StatusTLVType)
+	if tlv == nil {
+		return errors.New("Notification without status")
+	}
+
+	status, err := packet.DecodeStatus(tlv)
+	if err != nil {
+		return errors.Wrap(err, "Invalid status")
+	}
+
+	if status.Fatal {
+		sess.close("fatal notification received")
+		return nil
+	}
+
+	log.Infof("LDP: Received notification with status %d from %s", status.Code, ldpID(sess.peerLSRID))
+	return nil
+}
+
+func (sess *session) processInit(m *packet.Message) error {
+	tlv := m.TLV(packet.CommonSessionParamsTLVType)
+	if tlv == nil {
+		sess.shutdown(packet.StatusMissingMessageParameters, "missing session parameters")
+		return errors.New("Initialization without common session parameters")
+	}
+
+	params, err := packet.DecodeCommonSessionParams(tlv)
+	if err != nil {
+		sess.shutdown(packet.StatusMalformedTLVValue, "malformed session parameters")
+		return errors.Wrap(err, "Invalid common session parameters")
+	}
+
+	if params.ProtocolVersion != packet.Version {
+		sess.shutdown(packet.StatusBadProtocolVersion, "bad protocol version")
+		return errors.Errorf("Unsupported protocol version %d", params.ProtocolVersion)
+	}
+
+	if params.ReceiverLSRID != sess.srv.lsrID || params.ReceiverLabelSpace != 0 {
+		sess.shutdown(packet.StatusSessionRejectedNoHello, "receiver LDP identifier mismatch")
+		return errors.Errorf("Initialization for %s received", ldpID(params.ReceiverLSRID))
+	}
+
+	if params.KeepAliveTime == 0 {
+		sess.shutdown(packet.StatusSessionRejectedBadKATime, "bad keepalive time")
+		return errors.New("Keepalive time of 0 proposed")
+	}
+
+	// Downstream on demand is only used on ATM and frame relay links. On all other links downstream unsolicited is used.
+	if params.KeepAliveTime < sess.keepaliveTime {
+		sess.keepaliveTime = params.KeepAliveTime
+	}
+
+	return nil
+}
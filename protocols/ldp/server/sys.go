@@ -0,0 +1,175 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ldp/packet"
+	"golang.org/x/net/ipv4"
+)
+
+var allRouters = net.IPv4(224, 0, 0, 2)
+
+// helloConn sends and receives link hellos
+type helloConn interface {
+	joinGroup(ifName string) error
+	leaveGroup(ifName string) error
+	send(ifName string, pkt []byte) error
+	recv() (pkt []byte, src bnet.IP, ifName string, err error)
+	close() error
+}
+
+type bioHelloConn struct {
+	pc *ipv4.PacketConn
+}
+
+func newBIOHelloConn() (*bioHelloConn, error) {
+	c, err := net.ListenPacket("udp4", net.JoinHostPort("0.0.0.0", strconv.Itoa(packet.Port)))
+	if err != nil {
+		return nil, err
+	}
+
+	pc := ipv4.NewPacketConn(c)
+	for _, f := range []func() error{
+		func() error { return pc.SetControlMessage(ipv4.FlagInterface, true) },
+		func() error { return pc.SetMulticastTTL(1) },
+		func() error { return pc.SetMulticastLoopback(false) },
+	} {
+		if err := f(); err != nil {
+			pc.Close()
+			return nil, err
+		}
+	}
+
+	return &bioHelloConn{
+		pc: pc,
+	}, nil
+}
+
+func (c *bioHelloConn) joinGroup(ifName string) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	return c.pc.JoinGroup(ifi, &net.UDPAddr{IP: allRouters})
+}
+
+func (c *bioHelloConn) leaveGroup(ifName string) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	return c.pc.LeaveGroup(ifi, &net.UDPAddr{IP: allRouters})
+}
+
+func (c *bioHelloConn) send(ifName string, pkt []byte) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	cm := &ipv4.ControlMessage{
+		IfIndex: ifi.Index,
+	}
+
+	_, err = c.pc.WriteTo(pkt, cm, &net.UDPAddr{IP: allRouters, Port: packet.Port})
+	return err
+}
+
+func (c *bioHelloConn) recv() ([]byte, bnet.IP, string, error) {
+	buf := make([]byte, packet.MaxPDULength)
+	for {
+		n, cm, src, err := c.pc.ReadFrom(buf)
+		if err != nil {
+			return nil, bnet.IP{}, "", err
+		}
+
+		udpAddr, ok := src.(*net.UDPAddr)
+		if !ok || cm == nil {
+			continue
+		}
+
+		ifi, err := net.InterfaceByIndex(cm.IfIndex)
+		if err != nil {
+			continue
+		}
+
+		addr, err := bnet.IPFromBytes(udpAddr.IP.To4())
+		if err != nil {
+			continue
+		}
+
+		return buf[:n], addr, ifi.Name, nil
+	}
+}
+
+func (c *bioHelloConn) close() error {
+	return c.pc.Close()
+}
+
+type mockHelloPacket struct {
+	ifName string
+	pkt    []byte
+}
+
+type mockHelloConn struct {
+	mu     sync.Mutex
+	joined map[string]bool
+	sent   []mockHelloPacket
+	rx     chan mockHelloPacket
+	closed chan struct{}
+}
+
+func newMockHelloConn() *mockHelloConn {
+	return &mockHelloConn{
+		joined: make(map[string]bool),
+		rx:     make(chan mockHelloPacket),
+		closed: make(chan struct{}),
+	}
+}
+
+func (m *mockHelloConn) joinGroup(ifName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.joined[ifName] = true
+	return nil
+}
+
+func (m *mockHelloConn) leaveGroup(ifName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.joined, ifName)
+	return nil
+}
+
+func (m *mockHelloConn) send(ifName string, pkt []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, mockHelloPacket{
+		ifName: ifName,
+		pkt:    pkt,
+	})
+	return nil
+}
+
+func (m *mockHelloConn) recv() ([]byte, bnet.IP, string, error) {
+	select {
+	case p := <-m.rx:
+		return p.pkt, bnet.IPv4FromOctets(192, 168, 0, 2), p.ifName, nil
+	case <-m.closed:
+		return nil, bnet.IP{}, "", fmt.Errorf("closed")
+	}
+}
+
+func (m *mockHelloConn) close() error {
+	close(m.closed)
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package labelmanager hands out MPLS labels to all protocols of a router so their label spaces never collide
+package labelmanager
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/config"
+)
+
+const (
+	// MinLabel is the lowest label not reserved by RFC 3032
+	MinLabel = 16
+
+	// MaxLabel is the highest possible label
+	MaxLabel = 1<<20 - 1
+)
+
+// Block is a label range of the label manager
+type Block uint8
+
+const (
+	// Dynamic is the range of dynamically allocated labels
+	Dynamic Block = iota
+
+	// SRGB is the segment routing global block
+	SRGB
+
+	// SRLB is the segment routing local block
+	SRLB
+)
+
+func (b Block) String() string {
+	switch b {
+	case Dynamic:
+		return "dynamic"
+	case SRGB:
+		return "srgb"
+	case SRLB:
+		return "srlb"
+	}
+
+	return "unknown"
+}
+
+// Allocation is an allocated label
+type Allocation struct {
+	Label       uint32
+	Block       Block
+	Owner       string
+	Description string
+}
+
+// LabelManager allocates labels
+type LabelManager struct {
+	cfg         config.MPLSConfig
+	mu          sync.Mutex
+	dynamic     *pool
+	srlb        *pool
+	allocations map[uint32]*Allocation
+}
+
+// New creates a new label manager
+func New(cfg *config.MPLSConfig) (*LabelManager, error) {
+	ranges := []struct {
+		name string
+		r    config.LabelRange
+	}{
+		{name: "dynamic label range", r: cfg.DynamicLabels},
+		{name: "SRGB", r: cfg.SRGB},
+		{name: "SRLB", r: cfg.SRLB},
+	}
+
+	for i, x := range ranges {
+		if x.r.Size() == 0 || x.r.Start < MinLabel || x.r.End > MaxLabel {
+			return nil, fmt.Errorf("Invalid %s %d-%d", x.name, x.r.Start, x.r.End)
+		}
+
+		for _, y := range ranges[:i] {
+			if x.r.Overlaps(y.r) {
+				return nil, fmt.Errorf("%s overlaps with %s", x.name, y.name)
+			}
+		}
+	}
+
+	return &LabelManager{
+		cfg:         *cfg,
+		dynamic:     newPool(cfg.DynamicLabels),
+		srlb:        newPool(cfg.SRLB),
+		allocations: make(map[uint32]*Allocation),
+	}, nil
+}
+
+// DynamicLabels gets the range of dynamically allocated labels
+func (m *LabelManager) DynamicLabels() config.LabelRange {
+	return m.cfg.DynamicLabels
+}
+
+// SRGB gets the segment routing global block
+func (m *LabelManager) SRGB() config.LabelRange {
+	return m.cfg.SRGB
+}
+
+// SRLB gets the segment routing local block
+func (m *LabelManager) SRLB() config.LabelRange {
+	return m.cfg.SRLB
+}
+
+// Allocate allocates a label from the dynamic range
+func (m *LabelManager) Allocate(owner string, description string) (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.allocateFromPool(m.dynamic, Dynamic, owner, description)
+}
+
+// AllocateSRLB allocates a label from the SRLB, e.g. for an adjacency SID
+func (m *LabelManager) AllocateSRLB(owner string, description string) (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.allocateFromPool(m.srlb, SRLB, owner, description)
+}
+
+func (m *LabelManager) allocateFromPool(p *pool, b Block, owner string, description string) (uint32, error) {
+	l, err := p.allocate(m.allocations)
+	if err != nil {
+		return 0, err
+	}
+
+	m.allocations[l] = &Allocation{
+		Label:       l,
+		Block:       b,
+		Owner:       owner,
+		Description: description,
+	}
+
+	return l, nil
+}
+
+// AllocateSRGBIndex reserves the label of a SID index within the SRGB. An owner may allocate the same index repeatedly.
+func (m *LabelManager) AllocateSRGBIndex(owner string, description string, index uint32) (uint32, error) {
+	if index >= m.cfg.SRGB.Size() {
+		return 0, fmt.Errorf("SID index %d exceeds SRGB of size %d", index, m.cfg.SRGB.Size())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l := m.cfg.SRGB.Start + index
+	if a, ok := m.allocations[l]; ok {
+		if a.Owner != owner {
+			return 0, fmt.Errorf("SID index %d is in use by %s (%s)", index, a.Owner, a.Description)
+		}
+
+		a.Description = description
+		return l, nil
+	}
+
+	m.allocations[l] = &Allocation{
+		Label:       l,
+		Block:       SRGB,
+		Owner:       owner,
+		Description: description,
+	}
+
+	return l, nil
+}
+
+// Release releases a label
+func (m *LabelManager) Release(label uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.allocations[label]; !ok {
+		return fmt.Errorf("Label %d is not allocated", label)
+	}
+
+	delete(m.allocations, label)
+	return nil
+}
+
+// ReleaseOwner releases all labels of an owner and returns the number of labels released
+func (m *LabelManager) ReleaseOwner(owner string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for l, a := range m.allocations {
+		if a.Owner == owner {
+			delete(m.allocations, l)
+			n++
+		}
+	}
+
+	return n
+}
+
+// Allocations gets all allocated labels ordered by label
+func (m *LabelManager) Allocations() []Allocation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ret := make([]Allocation, 0, len(m.allocations))
+	for _, a := range m.allocations {
+		ret = append(ret, *a)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Label < ret[j].Label
+	})
+
+	return ret
+}
+
+// pool hands out labels of a range. Released labels are not reused before the rest of the range was used
+// to make sure peers don't confuse a new binding with a stale one.
+type pool struct {
+	r    config.LabelRange
+	next uint32
+}
+
+func newPool(r config.LabelRange) *pool {
+	return &pool{
+		r:    r,
+		next: r.Start,
+	}
+}
+
+func (p *pool) allocate(used map[uint32]*Allocation) (uint32, error) {
+	for i := uint32(0); i < p.r.Size(); i++ {
+		l := p.next
+		p.next++
+		if p.next > p.r.End {
+			p.next = p.r.Start
+		}
+
+		if _, ok := used[l]; !ok {
+			return l, nil
+		}
+	}
+
+	return 0, fmt.Errorf("No labels available in range %d-%d", p.r.Start, p.r.End)
+}
@@ -0,0 +1,150 @@
+package labelmanager
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() *config.MPLSConfig {
+	return &config.MPLSConfig{
+		DynamicLabels: config.LabelRange{Start: 100, End: 102},
+		SRGB:          config.LabelRange{Start: 16000, End: 16009},
+		SRLB:          config.LabelRange{Start: 15000, End: 15001},
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.MPLSConfig
+		wantFail bool
+	}{
+		{
+			name: "Defaults",
+			cfg:  config.DefaultMPLSConfig(),
+		},
+		{
+			name: "Reserved labels",
+			cfg: &config.MPLSConfig{
+				DynamicLabels: config.LabelRange{Start: 3, End: 100},
+				SRGB:          config.LabelRange{Start: 16000, End: 16009},
+				SRLB:          config.LabelRange{Start: 15000, End: 15001},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Label too high",
+			cfg: &config.MPLSConfig{
+				DynamicLabels: config.LabelRange{Start: 100, End: MaxLabel + 1},
+				SRGB:          config.LabelRange{Start: 16000, End: 16009},
+				SRLB:          config.LabelRange{Start: 15000, End: 15001},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Empty range",
+			cfg: &config.MPLSConfig{
+				DynamicLabels: config.LabelRange{Start: 100, End: 102},
+				SRGB:          config.LabelRange{Start: 16009, End: 16000},
+				SRLB:          config.LabelRange{Start: 15000, End: 15001},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Overlapping ranges",
+			cfg: &config.MPLSConfig{
+				DynamicLabels: config.LabelRange{Start: 100, End: 20000},
+				SRGB:          config.LabelRange{Start: 16000, End: 16009},
+				SRLB:          config.LabelRange{Start: 15000, End: 15001},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := New(test.cfg)
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	m, err := New(testConfig())
+	assert.NoError(t, err)
+
+	l, err := m.Allocate("ldp", "10.0.0.0/8")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100), l)
+
+	l, err = m.Allocate("ldp", "10.1.0.0/16")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(101), l)
+
+	assert.NoError(t, m.Release(100))
+	assert.Error(t, m.Release(100))
+
+	l, err = m.Allocate("bgp", "192.168.0.0/24")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(102), l, "released labels must not be reused immediately")
+
+	l, err = m.Allocate("bgp", "192.168.1.0/24")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100), l)
+
+	_, err = m.Allocate("bgp", "192.168.2.0/24")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, m.ReleaseOwner("bgp"))
+	assert.Equal(t, []Allocation{
+		{
+			Label:       101,
+			Block:       Dynamic,
+			Owner:       "ldp",
+			Description: "10.1.0.0/16",
+		},
+	}, m.Allocations())
+}
+
+func TestAllocateSegmentRouting(t *testing.T) {
+	m, err := New(testConfig())
+	assert.NoError(t, err)
+
+	l, err := m.AllocateSRGBIndex("isis", "10.0.0.1/32", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(16005), l)
+
+	l, err = m.AllocateSRGBIndex("isis", "10.0.0.1/32", 5)
+	assert.NoError(t, err, "repeated allocation by the same owner")
+	assert.Equal(t, uint32(16005), l)
+
+	_, err = m.AllocateSRGBIndex("ospf", "10.0.0.2/32", 5)
+	assert.Error(t, err, "index in use by other owner")
+
+	_, err = m.AllocateSRGBIndex("isis", "10.0.0.3/32", 10)
+	assert.Error(t, err, "index exceeds SRGB")
+
+	l, err = m.AllocateSRLB("isis", "adjacency eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(15000), l)
+
+	assert.Equal(t, []Allocation{
+		{
+			Label:       15000,
+			Block:       SRLB,
+			Owner:       "isis",
+			Description: "adjacency eth0",
+		},
+		{
+			Label:       16005,
+			Block:       SRGB,
+			Owner:       "isis",
+			Description: "10.0.0.1/32",
+		},
+	}, m.Allocations())
+}
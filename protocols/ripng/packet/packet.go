@@ -0,0 +1,134 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// Port is the RIPng UDP port
+	Port = 521
+
+	// Version is the RIPng version
+	Version = 1
+
+	// RequestCommand requests all or parts of the routing table
+	RequestCommand = 1
+
+	// ResponseCommand carries routing table entries
+	ResponseCommand = 2
+
+	// Infinity is the metric of unreachable destinations
+	Infinity = 16
+
+	// NextHopMetric marks a route table entry as next hop entry
+	NextHopMetric = 0xff
+
+	// HopLimit is the hop limit responses are sent with
+	HopLimit = 255
+
+	headerLen = 4
+
+	// RTELen is the length of a route table entry
+	RTELen = 20
+)
+
+// Packet is a RIPng packet
+type Packet struct {
+	Command uint8
+	RTEs    []RTE
+}
+
+// RTE is a route table entry. Next hop entries carry the next hop address as prefix and NextHopMetric as metric.
+type RTE struct {
+	Prefix   bnet.Prefix
+	RouteTag uint16
+	Metric   uint8
+}
+
+// NextHopRTE creates a next hop route table entry
+func NextHopRTE(nh bnet.IP) RTE {
+	return RTE{
+		Prefix: bnet.NewPfx(nh, 0),
+		Metric: NextHopMetric,
+	}
+}
+
+// IsNextHop returns if the route table entry is a next hop entry
+func (r *RTE) IsNextHop() bool {
+	return r.Metric == NextHopMetric
+}
+
+// IsWholeTableRequest returns if a request asks for the whole routing table (RFC 2080 2.4.1)
+func (p *Packet) IsWholeTableRequest() bool {
+	return p.Command == RequestCommand && len(p.RTEs) == 1 && p.RTEs[0].Prefix.Pfxlen() == 0 &&
+		p.RTEs[0].Prefix.Addr().Higher() == 0 && p.RTEs[0].Prefix.Addr().Lower() == 0 && p.RTEs[0].Metric == Infinity
+}
+
+// MaxRTEs returns the number of route table entries fitting into a packet sent on a link with the given MTU
+func MaxRTEs(mtu int) int {
+	// IPv6 and UDP header
+	return (mtu - 40 - 8 - headerLen) / RTELen
+}
+
+// Serialize serializes the packet
+func (p *Packet) Serialize(buf *bytes.Buffer) {
+	buf.WriteByte(p.Command)
+	buf.WriteByte(Version)
+	endian.WriteUint16(buf, 0)
+
+	for _, r := range p.RTEs {
+		buf.Write(r.Prefix.Addr().Bytes())
+		endian.WriteUint16(buf, r.RouteTag)
+		buf.WriteByte(r.Prefix.Pfxlen())
+		buf.WriteByte(r.Metric)
+	}
+}
+
+// Decode decodes a RIPng packet
+func Decode(b []byte) (*Packet, error) {
+	if len(b) < headerLen {
+		return nil, fmt.Errorf("Packet too short")
+	}
+
+	p := &Packet{
+		Command: b[0],
+	}
+
+	if p.Command != RequestCommand && p.Command != ResponseCommand {
+		return nil, fmt.Errorf("Unknown command %d", p.Command)
+	}
+
+	if b[1] != Version {
+		return nil, fmt.Errorf("Unsupported version %d", b[1])
+	}
+
+	b = b[headerLen:]
+	if len(b)%RTELen != 0 {
+		return nil, fmt.Errorf("Invalid packet length")
+	}
+
+	p.RTEs = make([]RTE, 0, len(b)/RTELen)
+	for ; len(b) > 0; b = b[RTELen:] {
+		addr, err := bnet.IPFromBytes(b[:16])
+		if err != nil {
+			return nil, err
+		}
+
+		pfxlen := b[18]
+		if pfxlen > 128 {
+			return nil, fmt.Errorf("Invalid prefix length %d", pfxlen)
+		}
+
+		p.RTEs = append(p.RTEs, RTE{
+			Prefix:   bnet.NewPfx(addr, pfxlen),
+			RouteTag: endian.Uint16(b[16:]),
+			Metric:   b[19],
+		})
+	}
+
+	return p, nil
+}
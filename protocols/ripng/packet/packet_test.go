@@ -0,0 +1,98 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeDecode(t *testing.T) {
+	p := &Packet{
+		Command: ResponseCommand,
+		RTEs: []RTE{
+			NextHopRTE(bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1)),
+			{
+				Prefix:   bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+				RouteTag: 42,
+				Metric:   3,
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	expected := []byte{
+		2, 1, 0, 0,
+		0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		0, 0, 0, 0xff,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 42, 32, 3,
+	}
+	assert.Equal(t, expected, buf.Bytes())
+
+	d, err := Decode(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+	assert.True(t, d.RTEs[0].IsNextHop())
+	assert.False(t, d.RTEs[1].IsNextHop())
+}
+
+func TestDecodeFail(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Too short",
+			input: []byte{2, 1},
+		},
+		{
+			name:  "Unknown command",
+			input: []byte{3, 1, 0, 0},
+		},
+		{
+			name:  "Unsupported version",
+			input: []byte{2, 2, 0, 0},
+		},
+		{
+			name:  "Truncated RTE",
+			input: []byte{2, 1, 0, 0, 1, 2, 3},
+		},
+		{
+			name: "Invalid prefix length",
+			input: []byte{
+				2, 1, 0, 0,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 129, 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Decode(test.input)
+		assert.Errorf(t, err, "Test %q", test.name)
+	}
+}
+
+func TestIsWholeTableRequest(t *testing.T) {
+	p := &Packet{
+		Command: RequestCommand,
+		RTEs: []RTE{
+			{
+				Prefix: bnet.NewPfx(bnet.IPv6(0, 0), 0),
+				Metric: Infinity,
+			},
+		},
+	}
+	assert.True(t, p.IsWholeTableRequest())
+
+	p.RTEs[0].Metric = 1
+	assert.False(t, p.IsWholeTableRequest())
+}
+
+func TestMaxRTEs(t *testing.T) {
+	assert.Equal(t, 72, MaxRTEs(1500))
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+	"github.com/bio-routing/bio-rd/protocols/ripng/packet"
+)
+
+const defaultMTU = 1500
+
+type ripInterface struct {
+	name            string
+	index           uint64
+	srv             *Server
+	metric          uint8
+	passive         bool
+	poisonedReverse bool
+	up              bool
+	enabled         bool
+	mtu             int
+	prefixes        []bnet.Prefix
+}
+
+func newRIPInterface(srv *Server, ifcfg *config.RIPngInterfaceConfig) *ripInterface {
+	metric := ifcfg.Metric
+	if metric == 0 {
+		metric = config.DefaultRIPngInterfaceMetric
+	}
+
+	return &ripInterface{
+		name:            ifcfg.Name,
+		srv:             srv,
+		metric:          metric,
+		passive:         ifcfg.Passive,
+		poisonedReverse: ifcfg.PoisonedReverse,
+		mtu:             defaultMTU,
+	}
+}
+
+// InterfaceUp is called by the interface manager if an interface came up
+func (s *Server) InterfaceUp(ifa *interfaces.Interface) {
+	s.interfaceUpdate(ifa)
+}
+
+// InterfaceDown is called by the interface manager if an interface went down
+func (s *Server) InterfaceDown(ifa *interfaces.Interface) {
+	s.interfaceUpdate(ifa)
+}
+
+// AddressAdded is called by the interface manager if an address was added to an interface
+func (s *Server) AddressAdded(ifa *interfaces.Interface, pfx *bnet.Prefix) {
+	s.interfaceUpdate(ifa)
+}
+
+// AddressRemoved is called by the interface manager if an address was removed from an interface
+func (s *Server) AddressRemoved(ifa *interfaces.Interface, pfx *bnet.Prefix) {
+	s.interfaceUpdate(ifa)
+}
+
+// interfaceUpdate applies the state of a managed interface if RIPng is enabled on it
+func (s *Server) interfaceUpdate(ifa *interfaces.Interface) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ifc, ok := s.interfaces[ifa.Config.Name]
+	if !ok {
+		return
+	}
+
+	ifc.update(ifa, time.Now())
+}
+
+// update manages RIPng on the interface according to its state. Must be called with s.mu held.
+func (ifc *ripInterface) update(ifa *interfaces.Interface, now time.Time) {
+	s := ifc.srv
+	ifc.index = ifa.Index
+	if ifa.MTU != 0 {
+		ifc.mtu = int(ifa.MTU)
+	}
+
+	ifc.up = ifa.Up
+	if !ifc.up {
+		s.disableInterface(ifc, now)
+		return
+	}
+
+	ifc.prefixes = connectedPrefixes(ifa.Addrs)
+	s.enableInterface(ifc)
+	s.updateConnected(now)
+}
+
+// connectedPrefixes gets the global IPv6 prefixes of an interface
+func connectedPrefixes(addrs []*bnet.Prefix) []bnet.Prefix {
+	ret := make([]bnet.Prefix, 0, len(addrs))
+	for _, a := range addrs {
+		if a.Addr().IsIPv4() || isLinkLocal(*a.Addr()) {
+			continue
+		}
+
+		ret = append(ret, bnet.NewPfx(*a.BaseAddr(), a.Pfxlen()))
+	}
+
+	return ret
+}
+
+func isLinkLocal(ip bnet.IP) bool {
+	return ip.Higher()>>54 == 0xfe80>>6
+}
+
+func isMulticast(ip bnet.IP) bool {
+	return ip.Higher()>>56 == 0xff
+}
+
+// enableInterface joins the RIPng group on an interface and asks neighbors for their routes. Must be called with s.mu held.
+func (s *Server) enableInterface(ifc *ripInterface) {
+	if ifc.enabled || s.sys == nil {
+		return
+	}
+
+	err := s.sys.joinGroup(ifc.name)
+	if err != nil {
+		log.Errorf("Unable to join multicast group on %q: %v", ifc.name, err)
+		return
+	}
+
+	ifc.enabled = true
+	log.Infof("RIPng: Interface %q is now up", ifc.name)
+
+	if ifc.passive {
+		return
+	}
+
+	req := &packet.Packet{
+		Command: packet.RequestCommand,
+		RTEs: []packet.RTE{
+			{
+				Prefix: bnet.NewPfx(bnet.IPv6(0, 0), 0),
+				Metric: packet.Infinity,
+			},
+		},
+	}
+	s.sendPacket(ifc, req, allRIPRouters, packet.Port)
+}
+
+// disableInterface stops RIPng on an interface and starts deletion of all routes via it. Must be called with s.mu held.
+func (s *Server) disableInterface(ifc *ripInterface, now time.Time) {
+	ifc.prefixes = nil
+	s.updateConnected(now)
+
+	for _, r := range s.routes {
+		if r.ifName == ifc.name && r.metric < packet.Infinity {
+			s.startDeletion(r, now)
+		}
+	}
+
+	if !ifc.enabled {
+		return
+	}
+
+	err := s.sys.leaveGroup(ifc.name)
+	if err != nil {
+		log.Errorf("Unable to leave multicast group on %q: %v", ifc.name, err)
+	}
+
+	ifc.enabled = false
+	log.Infof("RIPng: Interface %q is now down", ifc.name)
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ripng/packet"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+type ripRoute struct {
+	prefix    bnet.Prefix
+	metric    uint8
+	nextHop   bnet.IP
+	ifName    string
+	ifIndex   uint64
+	tag       uint16
+	connected bool
+
+	// timeout is the time the route expires if not refreshed
+	timeout time.Time
+
+	// garbage is the time a route in deletion is removed. It is zero for routes not in deletion.
+	garbage time.Time
+
+	// changed marks routes to be sent with the next triggered update
+	changed bool
+
+	installed *route.Path
+}
+
+// processPacket processes a received RIPng packet. Must be called with s.mu held.
+func (s *Server) processPacket(raw []byte, src bnet.IP, srcPort int, ifName string, hopLimit int, now time.Time) {
+	ifc, ok := s.interfaces[ifName]
+	if !ok || !ifc.enabled {
+		return
+	}
+
+	pkt, err := packet.Decode(raw)
+	if err != nil {
+		log.Warningf("RIPng: Unable to decode packet from %s on %q: %v", src.String(), ifName, err)
+		return
+	}
+
+	switch pkt.Command {
+	case packet.RequestCommand:
+		s.processRequest(ifc, pkt, src, srcPort)
+	case packet.ResponseCommand:
+		s.processResponse(ifc, pkt, src, srcPort, hopLimit, now)
+	}
+}
+
+func (s *Server) processRequest(ifc *ripInterface, pkt *packet.Packet, src bnet.IP, srcPort int) {
+	// Requests of other routers are only answered on interfaces we speak RIPng on.
+	// Requests of monitoring tools (sent from other ports) are answered anyway.
+	if ifc.passive && srcPort == packet.Port {
+		return
+	}
+
+	if pkt.IsWholeTableRequest() {
+		s.sendRTEs(ifc, s.updateRTEs(ifc, false, srcPort == packet.Port), src, srcPort)
+		return
+	}
+
+	rtes := make([]packet.RTE, 0, len(pkt.RTEs))
+	for _, rte := range pkt.RTEs {
+		rte.Metric = packet.Infinity
+		if r, ok := s.routes[rte.Prefix]; ok {
+			rte.Metric = r.metric
+			rte.RouteTag = r.tag
+		}
+
+		rtes = append(rtes, rte)
+	}
+
+	s.sendRTEs(ifc, rtes, src, srcPort)
+}
+
+func (s *Server) processResponse(ifc *ripInterface, pkt *packet.Packet, src bnet.IP, srcPort int, hopLimit int, now time.Time) {
+	// Sanity checks of RFC 2080 2.4.2
+	if srcPort != packet.Port || !isLinkLocal(src) || hopLimit != packet.HopLimit {
+		log.Debugf("RIPng: Ignoring response from [%s]:%d (hop limit %d) on %q", src.String(), srcPort, hopLimit, ifc.name)
+		return
+	}
+
+	nextHop := src
+	for _, rte := range pkt.RTEs {
+		if rte.IsNextHop() {
+			nextHop = src
+			if isLinkLocal(*rte.Prefix.Addr()) {
+				nextHop = *rte.Prefix.Addr()
+			}
+
+			continue
+		}
+
+		if !validRTE(&rte) {
+			log.Debugf("RIPng: Ignoring invalid RTE %s metric %d from %s", rte.Prefix.String(), rte.Metric, src.String())
+			continue
+		}
+
+		metric := int(rte.Metric) + int(ifc.metric)
+		if metric > packet.Infinity {
+			metric = packet.Infinity
+		}
+
+		pfx := bnet.NewPfx(*rte.Prefix.BaseAddr(), rte.Prefix.Pfxlen())
+		s.updateRoute(ifc, pfx, nextHop, rte.RouteTag, uint8(metric), now)
+	}
+}
+
+func validRTE(rte *packet.RTE) bool {
+	if rte.Metric < 1 || rte.Metric > packet.Infinity {
+		return false
+	}
+
+	return !isMulticast(*rte.Prefix.Addr()) && !isLinkLocal(*rte.Prefix.Addr())
+}
+
+// updateRoute applies a received route to the routing table (RFC 2080 2.4.2)
+func (s *Server) updateRoute(ifc *ripInterface, pfx bnet.Prefix, nextHop bnet.IP, tag uint16, metric uint8, now time.Time) {
+	timeout := time.Duration(s.config.Timeout) * time.Second
+
+	r, ok := s.routes[pfx]
+	if !ok {
+		if metric == packet.Infinity {
+			return
+		}
+
+		r = &ripRoute{
+			prefix:  pfx,
+			metric:  metric,
+			nextHop: nextHop,
+			ifName:  ifc.name,
+			ifIndex: ifc.index,
+			tag:     tag,
+			timeout: now.Add(timeout),
+			changed: true,
+		}
+		s.routes[pfx] = r
+		s.install(r)
+		s.triggeredUpdate = true
+		return
+	}
+
+	if r.connected {
+		return
+	}
+
+	sameRouter := r.ifName == ifc.name && r.nextHop.Compare(&nextHop) == 0
+	if sameRouter && metric < packet.Infinity {
+		r.timeout = now.Add(timeout)
+	}
+
+	if !(sameRouter && metric != r.metric) && metric >= r.metric {
+		return
+	}
+
+	r.nextHop = nextHop
+	r.ifName = ifc.name
+	r.ifIndex = ifc.index
+	r.tag = tag
+
+	if metric == packet.Infinity {
+		if r.metric < packet.Infinity {
+			s.startDeletion(r, now)
+		}
+
+		return
+	}
+
+	r.metric = metric
+	r.timeout = now.Add(timeout)
+	r.garbage = time.Time{}
+	r.changed = true
+	s.install(r)
+	s.triggeredUpdate = true
+}
+
+// startDeletion marks a route unreachable and removes it from the RIB. The route is advertised
+// with metric infinity until the garbage collection timer expires.
+func (s *Server) startDeletion(r *ripRoute, now time.Time) {
+	r.metric = packet.Infinity
+	r.connected = false
+	r.garbage = now.Add(time.Duration(s.config.GarbageCollection) * time.Second)
+	r.changed = true
+	s.uninstall(r)
+	s.triggeredUpdate = true
+}
+
+// expireRoutes runs the timeout and garbage collection timers of all routes
+func (s *Server) expireRoutes(now time.Time) {
+	for pfx, r := range s.routes {
+		if !r.garbage.IsZero() {
+			if !now.Before(r.garbage) {
+				delete(s.routes, pfx)
+			}
+
+			continue
+		}
+
+		if !r.connected && !now.Before(r.timeout) {
+			s.startDeletion(r, now)
+		}
+	}
+}
+
+// updateConnected syncs the routes of directly connected networks with the prefixes of all interfaces
+func (s *Server) updateConnected(now time.Time) {
+	desired := make(map[bnet.Prefix]*ripInterface)
+	for _, ifc := range s.sortedInterfaces() {
+		if !ifc.up {
+			continue
+		}
+
+		for _, pfx := range ifc.prefixes {
+			if _, ok := desired[pfx]; !ok {
+				desired[pfx] = ifc
+			}
+		}
+	}
+
+	for pfx, r := range s.routes {
+		if r.connected && desired[pfx] == nil {
+			s.startDeletion(r, now)
+		}
+	}
+
+	for pfx, ifc := range desired {
+		r, ok := s.routes[pfx]
+		if ok && r.connected && r.ifName == ifc.name && r.metric == ifc.metric {
+			continue
+		}
+
+		if ok {
+			s.uninstall(r)
+		}
+
+		s.routes[pfx] = &ripRoute{
+			prefix:    pfx,
+			metric:    ifc.metric,
+			ifName:    ifc.name,
+			connected: true,
+			changed:   true,
+		}
+		s.triggeredUpdate = true
+	}
+}
+
+// install adds or replaces the path of a learned route in the RIB
+func (s *Server) install(r *ripRoute) {
+	p := &route.Path{
+		Type: route.RIPPathType,
+		RIPPath: &route.RIPPath{
+			NextHop: r.nextHop.Dedup(),
+			IfIndex: r.ifIndex,
+			Metric:  r.metric,
+		},
+	}
+
+	s.uninstall(r)
+	s.rib.AddPath(r.prefix.Dedup(), p)
+	r.installed = p
+}
+
+func (s *Server) uninstall(r *ripRoute) {
+	if r.installed == nil {
+		return
+	}
+
+	s.rib.RemovePath(r.prefix.Dedup(), r.installed)
+	r.installed = nil
+}
+
+// sendUpdates sends all routes (only changed routes if triggered is set) on all interfaces
+func (s *Server) sendUpdates(triggered bool) {
+	for _, ifc := range s.sortedInterfaces() {
+		if !ifc.enabled || ifc.passive {
+			continue
+		}
+
+		rtes := s.updateRTEs(ifc, triggered, true)
+		if len(rtes) == 0 {
+			continue
+		}
+
+		s.sendRTEs(ifc, rtes, allRIPRouters, packet.Port)
+	}
+
+	for _, r := range s.routes {
+		r.changed = false
+	}
+}
+
+// updateRTEs gets the RTEs to advertise on an interface
+func (s *Server) updateRTEs(ifc *ripInterface, onlyChanged bool, splitHorizon bool) []packet.RTE {
+	routes := make([]*ripRoute, 0, len(s.routes))
+	for _, r := range s.routes {
+		if onlyChanged && !r.changed {
+			continue
+		}
+
+		routes = append(routes, r)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		c := routes[i].prefix.Addr().Compare(routes[j].prefix.Addr())
+		if c != 0 {
+			return c < 0
+		}
+
+		return routes[i].prefix.Pfxlen() < routes[j].prefix.Pfxlen()
+	})
+
+	rtes := make([]packet.RTE, 0, len(routes))
+	for _, r := range routes {
+		metric := r.metric
+		if splitHorizon && !r.connected && r.ifName == ifc.name {
+			if !ifc.poisonedReverse {
+				continue
+			}
+
+			metric = packet.Infinity
+		}
+
+		rtes = append(rtes, packet.RTE{
+			Prefix:   r.prefix,
+			RouteTag: r.tag,
+			Metric:   metric,
+		})
+	}
+
+	return rtes
+}
+
+// sendRTEs sends responses carrying rtes, split into as many packets as the interfaces MTU requires
+func (s *Server) sendRTEs(ifc *ripInterface, rtes []packet.RTE, dst bnet.IP, port int) {
+	max := packet.MaxRTEs(ifc.mtu)
+	for len(rtes) > 0 {
+		n := len(rtes)
+		if n > max {
+			n = max
+		}
+
+		s.sendPacket(ifc, &packet.Packet{
+			Command: packet.ResponseCommand,
+			RTEs:    rtes[:n],
+		}, dst, port)
+		rtes = rtes[n:]
+	}
+}
+
+func (s *Server) sendPacket(ifc *ripInterface, pkt *packet.Packet, dst bnet.IP, port int) {
+	buf := bytes.NewBuffer(nil)
+	pkt.Serialize(buf)
+
+	err := s.sys.send(ifc.name, buf.Bytes(), dst, port)
+	if err != nil {
+		log.Errorf("RIPng: Unable to send packet on %q: %v", ifc.name, err)
+	}
+}
+
+func (s *Server) sortedInterfaces() []*ripInterface {
+	ret := make([]*ripInterface, 0, len(s.interfaces))
+	for _, ifc := range s.interfaces {
+		ret = append(ret, ifc)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].name < ret[j].name
+	})
+
+	return ret
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	btime "github.com/bio-routing/bio-rd/util/time"
+	"github.com/pkg/errors"
+)
+
+// Server represents a RIPng server
+type Server struct {
+	config *config.RIPngConfig
+	ifm    *interfaces.Manager
+	rib    routingtable.RouteTableClient
+
+	// mu protects all protocol state below
+	mu                sync.Mutex
+	sys               sys
+	interfaces        map[string]*ripInterface
+	routes            map[bnet.Prefix]*ripRoute
+	nextUpdate        time.Time
+	triggeredUpdate   bool
+	nextTriggerUpdate time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new RIPng server. Interface state is taken from ifm, learned routes are redistributed into rib.
+func New(cfg *config.RIPngConfig, ifm *interfaces.Manager, rib routingtable.RouteTableClient) *Server {
+	if cfg.UpdateInterval == 0 {
+		cfg.UpdateInterval = config.DefaultRIPngUpdateInterval
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = config.DefaultRIPngTimeout
+	}
+
+	if cfg.GarbageCollection == 0 {
+		cfg.GarbageCollection = config.DefaultRIPngGarbageCollection
+	}
+
+	s := &Server{
+		config:     cfg,
+		ifm:        ifm,
+		rib:        rib,
+		interfaces: make(map[string]*ripInterface),
+		routes:     make(map[bnet.Prefix]*ripRoute),
+		stop:       make(chan struct{}),
+	}
+
+	for i := range cfg.Interfaces {
+		s.AddInterface(&cfg.Interfaces[i])
+	}
+
+	if ifm != nil {
+		ifm.Subscribe(s)
+	}
+
+	return s
+}
+
+// Start opens the RIPng socket and starts the protocol
+func (s *Server) Start() error {
+	sys, err := newBIOSys()
+	if err != nil {
+		return errors.Wrap(err, "Unable to open socket")
+	}
+
+	s.start(sys, btime.NewBIOTicker(time.Second))
+	return nil
+}
+
+func (s *Server) start(sys sys, t btime.Ticker) {
+	s.mu.Lock()
+	s.sys = sys
+	s.nextUpdate = time.Now().Add(s.updateInterval())
+	for _, ifc := range s.interfaces {
+		if ifc.up {
+			s.enableInterface(ifc)
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.timerRoutine(t)
+
+	s.wg.Add(1)
+	go s.receiver()
+}
+
+// Stop stops the server
+func (s *Server) Stop() {
+	close(s.stop)
+	if s.ifm != nil {
+		s.ifm.Unsubscribe(s)
+	}
+
+	s.mu.Lock()
+	if s.sys != nil {
+		s.sys.close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// AddInterface enables RIPng on an interface
+func (s *Server) AddInterface(ifcfg *config.RIPngInterfaceConfig) error {
+	s.mu.Lock()
+	if _, ok := s.interfaces[ifcfg.Name]; ok {
+		s.mu.Unlock()
+		return fmt.Errorf("Interface %q exists already", ifcfg.Name)
+	}
+
+	ifc := newRIPInterface(s, ifcfg)
+	s.interfaces[ifcfg.Name] = ifc
+	if s.ifm != nil {
+		if ifa := s.ifm.GetInterface(ifc.name); ifa != nil {
+			ifc.update(ifa, time.Now())
+		}
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RemoveInterface disables RIPng on an interface
+func (s *Server) RemoveInterface(name string) error {
+	s.mu.Lock()
+	ifc, ok := s.interfaces[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("Interface %q not found", name)
+	}
+
+	s.disableInterface(ifc, time.Now())
+	delete(s.interfaces, name)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *Server) timerRoutine(t btime.Ticker) {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-t.C():
+			s.mu.Lock()
+			s.tick(now)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// tick runs the route timers and sends regular and triggered updates when due. Must be called with s.mu held.
+func (s *Server) tick(now time.Time) {
+	s.expireRoutes(now)
+
+	if !now.Before(s.nextUpdate) {
+		s.sendUpdates(false)
+		s.nextUpdate = now.Add(s.updateInterval())
+		s.triggeredUpdate = false
+		return
+	}
+
+	if s.triggeredUpdate && !now.Before(s.nextTriggerUpdate) {
+		s.sendUpdates(true)
+		s.triggeredUpdate = false
+
+		// Triggered updates are rate limited to one every 1 to 5 seconds (RFC 2080 2.5.1)
+		s.nextTriggerUpdate = now.Add(time.Second + time.Duration(rand.Int63n(int64(4*time.Second))))
+	}
+}
+
+// updateInterval returns the update interval with a random offset of up to a sixth of the interval
+// to prevent updates of routers from synchronizing
+func (s *Server) updateInterval() time.Duration {
+	interval := time.Duration(s.config.UpdateInterval) * time.Second
+	jitter := interval / 6
+	return interval - jitter + time.Duration(rand.Int63n(int64(2*jitter)+1))
+}
+
+func (s *Server) receiver() {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+
+	for {
+		pkt, src, srcPort, ifName, hopLimit, err := s.sys.recv()
+		if err != nil {
+			select {
+			case <-s.stop:
+			default:
+				log.Errorf("Unable to receive: %v", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.processPacket(pkt, src, srcPort, ifName, hopLimit, time.Now())
+		s.mu.Unlock()
+	}
+}
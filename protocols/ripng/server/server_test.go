@@ -0,0 +1,466 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/device"
+	"github.com/bio-routing/bio-rd/protocols/interfaces"
+	"github.com/bio-routing/bio-rd/protocols/ripng/packet"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
+	btime "github.com/bio-routing/bio-rd/util/time"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	neighborA = bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1)
+	neighborB = bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 2)
+	pfx1      = bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 1, 0, 0, 0, 0, 0), 48)
+	pfx2      = bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 2, 0, 0, 0, 0, 0), 48)
+)
+
+// mockUpdater is a device server keeping the last subscribed client
+type mockUpdater struct {
+	c device.Client
+}
+
+func (m *mockUpdater) Subscribe(c device.Client, name string) {
+	m.c = c
+}
+
+func (m *mockUpdater) Unsubscribe(c device.Client, name string) {}
+
+func newTestServer() (*Server, *locRIB.LocRIB, *mockSys) {
+	rib := locRIB.New("test")
+	s := New(&config.RIPngConfig{
+		Interfaces: []config.RIPngInterfaceConfig{
+			{
+				Name: "eth0",
+			},
+			{
+				Name:            "eth1",
+				Metric:          2,
+				PoisonedReverse: true,
+			},
+		},
+	}, nil, rib)
+
+	sys := newMockSys()
+	s.sys = sys
+	for _, ifc := range s.interfaces {
+		ifc.up = true
+		s.enableInterface(ifc)
+	}
+	sys.sent = nil
+
+	return s, rib, sys
+}
+
+func serialize(pkt *packet.Packet) []byte {
+	buf := bytes.NewBuffer(nil)
+	pkt.Serialize(buf)
+	return buf.Bytes()
+}
+
+func ripPath(nh bnet.IP, metric uint8) *route.Path {
+	return &route.Path{
+		Type: route.RIPPathType,
+		RIPPath: &route.RIPPath{
+			NextHop: nh.Ptr(),
+			Metric:  metric,
+		},
+	}
+}
+
+func response(rtes ...packet.RTE) []byte {
+	return serialize(&packet.Packet{
+		Command: packet.ResponseCommand,
+		RTEs:    rtes,
+	})
+}
+
+func TestProcessResponse(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		packets  []mockPacket
+		srcPort  int
+		hopLimit int
+		expected map[bnet.Prefix]*route.Path
+	}{
+		{
+			name: "Learn routes",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 1}, packet.RTE{Prefix: pfx2, Metric: 3}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborA, 2),
+				pfx2: ripPath(neighborA, 4),
+			},
+		},
+		{
+			name: "Interface metric",
+			packets: []mockPacket{
+				{
+					ifName: "eth1",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 1}, packet.RTE{Prefix: pfx2, Metric: 15}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborA, 3),
+			},
+		},
+		{
+			name: "Better metric wins",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 5}),
+				},
+				{
+					ifName: "eth0",
+					dst:    neighborB,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 2}),
+				},
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 3}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborB, 3),
+			},
+		},
+		{
+			name: "Same router increases metric",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 2}),
+				},
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 7}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborA, 8),
+			},
+		},
+		{
+			name: "Same router withdraws",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 2}, packet.RTE{Prefix: pfx2, Metric: 2}),
+				},
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: packet.Infinity}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx2: ripPath(neighborA, 3),
+			},
+		},
+		{
+			name: "Next hop RTE",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.NextHopRTE(neighborB), packet.RTE{Prefix: pfx1, Metric: 1}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborB, 2),
+			},
+		},
+		{
+			name: "Invalid RTEs",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt: response(
+						packet.RTE{Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0xff02, 0, 0, 0, 0, 0, 0, 0), 16), Metric: 1},
+						packet.RTE{Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 0), 64), Metric: 1},
+						packet.RTE{Prefix: pfx2, Metric: 0},
+						packet.RTE{Prefix: pfx1, Metric: 1},
+					),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{
+				pfx1: ripPath(neighborA, 2),
+			},
+		},
+		{
+			name: "Wrong source port",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 1}),
+				},
+			},
+			srcPort:  1234,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{},
+		},
+		{
+			name: "Wrong hop limit",
+			packets: []mockPacket{
+				{
+					ifName: "eth0",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 1}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: 254,
+			expected: map[bnet.Prefix]*route.Path{},
+		},
+		{
+			name: "Unknown interface",
+			packets: []mockPacket{
+				{
+					ifName: "eth2",
+					dst:    neighborA,
+					pkt:    response(packet.RTE{Prefix: pfx1, Metric: 1}),
+				},
+			},
+			srcPort:  packet.Port,
+			hopLimit: packet.HopLimit,
+			expected: map[bnet.Prefix]*route.Path{},
+		},
+	}
+
+	for _, test := range tests {
+		s, rib, _ := newTestServer()
+		for _, p := range test.packets {
+			s.processPacket(p.pkt, p.dst, test.srcPort, p.ifName, test.hopLimit, now)
+		}
+
+		assert.Equal(t, len(test.expected), len(rib.Dump()), "Test %q", test.name)
+		for pfx, p := range test.expected {
+			assert.True(t, rib.ContainsPfxPath(pfx.Ptr(), p), "Test %q: %s", test.name, pfx.String())
+		}
+	}
+}
+
+func TestUpdateRTEs(t *testing.T) {
+	s, _, _ := newTestServer()
+	now := time.Now()
+
+	s.processPacket(response(packet.RTE{Prefix: pfx1, Metric: 1}), neighborA, packet.Port, "eth0", packet.HopLimit, now)
+	s.processPacket(response(packet.RTE{Prefix: pfx2, Metric: 1}), neighborB, packet.Port, "eth1", packet.HopLimit, now)
+
+	tests := []struct {
+		name         string
+		ifName       string
+		splitHorizon bool
+		expected     []packet.RTE
+	}{
+		{
+			name:         "Split horizon",
+			ifName:       "eth0",
+			splitHorizon: true,
+			expected: []packet.RTE{
+				{Prefix: pfx2, Metric: 3},
+			},
+		},
+		{
+			name:         "Poisoned reverse",
+			ifName:       "eth1",
+			splitHorizon: true,
+			expected: []packet.RTE{
+				{Prefix: pfx1, Metric: 2},
+				{Prefix: pfx2, Metric: packet.Infinity},
+			},
+		},
+		{
+			name:   "No split horizon",
+			ifName: "eth0",
+			expected: []packet.RTE{
+				{Prefix: pfx1, Metric: 2},
+				{Prefix: pfx2, Metric: 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		rtes := s.updateRTEs(s.interfaces[test.ifName], false, test.splitHorizon)
+		assert.Equal(t, test.expected, rtes, "Test %q", test.name)
+	}
+}
+
+func TestProcessRequest(t *testing.T) {
+	s, _, sys := newTestServer()
+	now := time.Now()
+
+	s.processPacket(response(packet.RTE{Prefix: pfx1, Metric: 1}), neighborA, packet.Port, "eth0", packet.HopLimit, now)
+
+	req := serialize(&packet.Packet{
+		Command: packet.RequestCommand,
+		RTEs: []packet.RTE{
+			{Prefix: pfx1},
+			{Prefix: pfx2},
+		},
+	})
+	s.processPacket(req, neighborB, 4321, "eth0", 64, now)
+
+	assert.Equal(t, []mockPacket{
+		{
+			ifName: "eth0",
+			dst:    neighborB,
+			port:   4321,
+			pkt: response(
+				packet.RTE{Prefix: pfx1, Metric: 2},
+				packet.RTE{Prefix: pfx2, Metric: packet.Infinity},
+			),
+		},
+	}, sys.sent)
+}
+
+func TestRouteTimers(t *testing.T) {
+	s, rib, sys := newTestServer()
+	now := time.Now()
+
+	s.processPacket(response(packet.RTE{Prefix: pfx1, Metric: 1}), neighborA, packet.Port, "eth0", packet.HopLimit, now)
+	assert.Equal(t, 1, len(rib.Dump()))
+
+	// Periodic update
+	s.nextUpdate = now
+	s.tick(now)
+	assert.Equal(t, []mockPacket{
+		{
+			ifName: "eth1",
+			dst:    allRIPRouters,
+			port:   packet.Port,
+			pkt:    response(packet.RTE{Prefix: pfx1, Metric: 2}),
+		},
+	}, sys.sent)
+	sys.sent = nil
+
+	// Timeout
+	now = now.Add(time.Duration(s.config.Timeout) * time.Second)
+	s.nextTriggerUpdate = now
+	s.tick(now)
+	assert.Equal(t, 0, len(rib.Dump()))
+	assert.Equal(t, uint8(packet.Infinity), s.routes[pfx1].metric)
+	assert.Equal(t, []mockPacket{
+		{
+			ifName: "eth1",
+			dst:    allRIPRouters,
+			port:   packet.Port,
+			pkt:    response(packet.RTE{Prefix: pfx1, Metric: packet.Infinity}),
+		},
+	}, sys.sent)
+
+	// Garbage collection
+	now = now.Add(time.Duration(s.config.GarbageCollection) * time.Second)
+	s.tick(now)
+	assert.Equal(t, 0, len(s.routes))
+}
+
+func TestInterfaceUpdate(t *testing.T) {
+	s, rib, sys := newTestServer()
+	now := time.Now()
+	ifc := s.interfaces["eth0"]
+
+	ds := &mockUpdater{}
+	m := interfaces.NewManager(ds)
+	m.Subscribe(s)
+	assert.NoError(t, m.AddInterface(interfaces.Config{Name: "eth0"}))
+
+	s.processPacket(response(packet.RTE{Prefix: pfx1, Metric: 1}), neighborA, packet.Port, "eth0", packet.HopLimit, now)
+
+	connected := bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0xff, 0, 0, 0, 0, 1), 64)
+	ll := bnet.NewPfx(bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 0x10), 64)
+	v4 := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 1), 24)
+
+	ds.c.DeviceUpdate(&device.Device{
+		Name:      "eth0",
+		Index:     2,
+		MTU:       1280,
+		OperState: device.IfOperUp,
+		Addrs:     []*bnet.Prefix{connected.Ptr(), ll.Ptr(), v4.Ptr()},
+	})
+
+	assert.Equal(t, 1280, ifc.mtu)
+	assert.Equal(t, uint64(2), ifc.index)
+	connectedNet := bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0xff, 0, 0, 0, 0, 0), 64)
+	assert.Equal(t, 2, len(s.routes))
+	assert.True(t, s.routes[connectedNet].connected)
+
+	ds.c.DeviceUpdate(&device.Device{
+		Name:      "eth0",
+		OperState: device.IfOperDown,
+	})
+
+	assert.False(t, sys.joined["eth0"])
+	assert.Equal(t, 0, len(rib.Dump()))
+	assert.Equal(t, uint8(packet.Infinity), s.routes[pfx1].metric)
+	assert.Equal(t, uint8(packet.Infinity), s.routes[connectedNet].metric)
+	assert.False(t, s.routes[connectedNet].connected)
+}
+
+func TestStartStop(t *testing.T) {
+	s := New(&config.RIPngConfig{
+		Interfaces: []config.RIPngInterfaceConfig{
+			{
+				Name: "eth0",
+			},
+		},
+	}, nil, locRIB.New("test"))
+	s.interfaces["eth0"].up = true
+
+	sys := newMockSys()
+	s.start(sys, btime.NewMockTicker())
+	s.Stop()
+
+	assert.True(t, sys.joined["eth0"])
+	assert.Equal(t, []mockPacket{
+		{
+			ifName: "eth0",
+			dst:    allRIPRouters,
+			port:   packet.Port,
+			pkt: serialize(&packet.Packet{
+				Command: packet.RequestCommand,
+				RTEs: []packet.RTE{
+					{Prefix: bnet.NewPfx(bnet.IPv6(0, 0), 0), Metric: packet.Infinity},
+				},
+			}),
+		},
+	}, sys.sent)
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/ripng/packet"
+	"golang.org/x/net/ipv6"
+)
+
+var allRIPRouters = bnet.IPv6FromBlocks(0xff02, 0, 0, 0, 0, 0, 0, 9)
+
+// sys sends and receives RIPng packets
+type sys interface {
+	joinGroup(ifName string) error
+	leaveGroup(ifName string) error
+	send(ifName string, pkt []byte, dst bnet.IP, port int) error
+	recv() (pkt []byte, src bnet.IP, srcPort int, ifName string, hopLimit int, err error)
+	close() error
+}
+
+type bioSys struct {
+	pc *ipv6.PacketConn
+}
+
+func newBIOSys() (*bioSys, error) {
+	c, err := net.ListenPacket("udp6", net.JoinHostPort("::", strconv.Itoa(packet.Port)))
+	if err != nil {
+		return nil, err
+	}
+
+	pc := ipv6.NewPacketConn(c)
+	for _, f := range []func() error{
+		func() error { return pc.SetControlMessage(ipv6.FlagInterface|ipv6.FlagHopLimit, true) },
+		func() error { return pc.SetMulticastHopLimit(packet.HopLimit) },
+		func() error { return pc.SetMulticastLoopback(false) },
+	} {
+		if err := f(); err != nil {
+			pc.Close()
+			return nil, err
+		}
+	}
+
+	return &bioSys{
+		pc: pc,
+	}, nil
+}
+
+func (b *bioSys) joinGroup(ifName string) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	return b.pc.JoinGroup(ifi, &net.UDPAddr{IP: allRIPRouters.ToNetIP()})
+}
+
+func (b *bioSys) leaveGroup(ifName string) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	return b.pc.LeaveGroup(ifi, &net.UDPAddr{IP: allRIPRouters.ToNetIP()})
+}
+
+func (b *bioSys) send(ifName string, pkt []byte, dst bnet.IP, port int) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return err
+	}
+
+	cm := &ipv6.ControlMessage{
+		IfIndex:  ifi.Index,
+		HopLimit: packet.HopLimit,
+	}
+
+	_, err = b.pc.WriteTo(pkt, cm, &net.UDPAddr{IP: dst.ToNetIP(), Port: port, Zone: ifName})
+	return err
+}
+
+func (b *bioSys) recv() ([]byte, bnet.IP, int, string, int, error) {
+	buf := make([]byte, 65535)
+	for {
+		n, cm, src, err := b.pc.ReadFrom(buf)
+		if err != nil {
+			return nil, bnet.IP{}, 0, "", 0, err
+		}
+
+		udpAddr, ok := src.(*net.UDPAddr)
+		if !ok || cm == nil {
+			continue
+		}
+
+		ifi, err := net.InterfaceByIndex(cm.IfIndex)
+		if err != nil {
+			continue
+		}
+
+		addr, err := bnet.IPFromBytes(udpAddr.IP.To16())
+		if err != nil {
+			continue
+		}
+
+		return buf[:n], addr, udpAddr.Port, ifi.Name, cm.HopLimit, nil
+	}
+}
+
+func (b *bioSys) close() error {
+	return b.pc.Close()
+}
+
+type mockPacket struct {
+	ifName string
+	pkt    []byte
+	dst    bnet.IP
+	port   int
+}
+
+type mockSys struct {
+	mu     sync.Mutex
+	joined map[string]bool
+	sent   []mockPacket
+	closed chan struct{}
+}
+
+func newMockSys() *mockSys {
+	return &mockSys{
+		joined: make(map[string]bool),
+		closed: make(chan struct{}),
+	}
+}
+
+func (m *mockSys) joinGroup(ifName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.joined[ifName] = true
+	return nil
+}
+
+func (m *mockSys) leaveGroup(ifName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.joined, ifName)
+	return nil
+}
+
+func (m *mockSys) send(ifName string, pkt []byte, dst bnet.IP, port int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, mockPacket{
+		ifName: ifName,
+		pkt:    pkt,
+		dst:    dst,
+		port:   port,
+	})
+	return nil
+}
+
+func (m *mockSys) recv() ([]byte, bnet.IP, int, string, int, error) {
+	<-m.closed
+	return nil, bnet.IP{}, 0, "", 0, fmt.Errorf("closed")
+}
+
+func (m *mockSys) close() error {
+	close(m.closed)
+	return nil
+}
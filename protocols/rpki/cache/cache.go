@@ -0,0 +1,357 @@
+// Package cache implements an RTR cache server serving VRPs to routers
+package cache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	"github.com/bio-routing/bio-rd/protocols/rpki/packet"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/pkg/errors"
+)
+
+// Server is an RTR cache server
+type Server struct {
+	config *config.RTRCacheConfig
+
+	// mu protects all state below
+	mu        sync.RWMutex
+	sessionID uint16
+	serial    uint32
+	ready     bool
+	vrps      vrp.Set
+	history   []delta
+	conns     map[*conn]struct{}
+
+	listener net.Listener
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// delta holds the changes leading to a serial
+type delta struct {
+	serial   uint32
+	announce []vrp.VRP
+	withdraw []vrp.VRP
+}
+
+// Status is the state of a cache server
+type Status struct {
+	SessionID uint16
+	Serial    uint32
+	Ready     bool
+	VRPs      int
+	Clients   int
+}
+
+// New creates a new RTR cache server. Clients get No Data Available errors until the first call of SetVRPs.
+func New(cfg *config.RTRCacheConfig) *Server {
+	if cfg.ListenAddress == "" {
+		cfg.ListenAddress = config.DefaultRTRCacheListenAddress
+	}
+
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = packet.DefaultRefreshInterval
+	}
+
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = packet.DefaultRetryInterval
+	}
+
+	if cfg.ExpireInterval == 0 {
+		cfg.ExpireInterval = packet.DefaultExpireInterval
+	}
+
+	if cfg.HistorySize == 0 {
+		cfg.HistorySize = config.DefaultRTRHistorySize
+	}
+
+	return &Server{
+		config:    cfg,
+		sessionID: newSessionID(),
+		vrps:      make(vrp.Set),
+		conns:     make(map[*conn]struct{}),
+		stop:      make(chan struct{}),
+	}
+}
+
+// newSessionID gets a random session ID. Routers reconnecting after a restart of the cache must see a new session ID
+// as the serial starts over (RFC 8210 5.1).
+func newSessionID() uint16 {
+	buf := make([]byte, 2)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return uint16(time.Now().UnixNano())
+	}
+
+	return binary.BigEndian.Uint16(buf)
+}
+
+// Start starts listening for RTR clients
+func (s *Server) Start() error {
+	l, err := net.Listen("tcp", s.config.ListenAddress)
+	if err != nil {
+		return errors.Wrap(err, "Unable to listen")
+	}
+
+	s.start(l)
+	return nil
+}
+
+func (s *Server) start(l net.Listener) {
+	s.listener = l
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+}
+
+// Stop stops the server and closes all client connections
+func (s *Server) Stop() {
+	close(s.stop)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	s.mu.Lock()
+	for c := range s.conns {
+		c.close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) acceptLoop() {
+	defer crashdump.Recover()
+	defer s.wg.Done()
+
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.stop:
+			default:
+				log.Errorf("RTR: Unable to accept connection: %v", err)
+			}
+			return
+		}
+
+		s.serve(c)
+	}
+}
+
+// serve starts serving a client connection
+func (s *Server) serve(c net.Conn) {
+	cc := newConn(s, c)
+
+	s.mu.Lock()
+	s.conns[cc] = struct{}{}
+	s.mu.Unlock()
+
+	log.Infof("RTR: Client %s connected", c.RemoteAddr().String())
+
+	s.wg.Add(2)
+	go cc.receiver()
+	go cc.notifier()
+}
+
+func (s *Server) removeConn(c *conn) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+// SetVRPs replaces the VRPs served. Clients are notified if anything changed.
+func (s *Server) SetVRPs(vrps []vrp.VRP) {
+	set := vrp.NewSet(vrps)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	announce, withdraw := s.vrps.Diff(set)
+	if s.ready && len(announce) == 0 && len(withdraw) == 0 {
+		return
+	}
+
+	s.vrps = set
+	if !s.ready {
+		// Clients that asked before got No Data Available and are waiting for a notification
+		s.ready = true
+		log.Infof("RTR: Serving %d VRPs with serial %d", len(set), s.serial)
+	} else {
+		s.serial++
+		s.history = append(s.history, delta{
+			serial:   s.serial,
+			announce: announce,
+			withdraw: withdraw,
+		})
+
+		if len(s.history) > s.config.HistorySize {
+			s.history = s.history[len(s.history)-s.config.HistorySize:]
+		}
+
+		log.Infof("RTR: Serving %d VRPs with serial %d (%d announced, %d withdrawn)", len(set), s.serial, len(announce), len(withdraw))
+	}
+
+	for c := range s.conns {
+		c.notify()
+	}
+}
+
+// Status gets the status of the server
+func (s *Server) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return Status{
+		SessionID: s.sessionID,
+		Serial:    s.serial,
+		Ready:     s.ready,
+		VRPs:      len(s.vrps),
+		Clients:   len(s.conns),
+	}
+}
+
+// fullResponse builds the response to a Reset Query
+func (s *Server) fullResponse(version uint8) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.ready {
+		return noData(version)
+	}
+
+	return s.response(version, s.vrps.List(), nil)
+}
+
+// incrementalResponse builds the response to a Serial Query
+func (s *Server) incrementalResponse(version uint8, sessionID uint16, serial uint32) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.ready {
+		return noData(version)
+	}
+
+	if sessionID != s.sessionID {
+		return cacheReset(version)
+	}
+
+	if serial == s.serial {
+		return s.response(version, nil, nil)
+	}
+
+	for i, d := range s.history {
+		if d.serial-1 == serial {
+			announce, withdraw := netChanges(s.history[i:])
+			return s.response(version, announce, withdraw)
+		}
+	}
+
+	// The client is too far behind or knows serials we never had
+	return cacheReset(version)
+}
+
+// response builds a Cache Response with the given changes. Must be called with s.mu held.
+func (s *Server) response(version uint8, announce []vrp.VRP, withdraw []vrp.VRP) []byte {
+	buf := bytes.NewBuffer(nil)
+	(&packet.PDU{
+		Version:   version,
+		Type:      packet.CacheResponseType,
+		SessionID: s.sessionID,
+	}).Serialize(buf)
+
+	for _, v := range withdraw {
+		packet.NewPrefixPDU(version, false, v.Prefix, v.MaxLength, v.ASN).Serialize(buf)
+	}
+
+	for _, v := range announce {
+		packet.NewPrefixPDU(version, true, v.Prefix, v.MaxLength, v.ASN).Serialize(buf)
+	}
+
+	(&packet.PDU{
+		Version:         version,
+		Type:            packet.EndOfDataType,
+		SessionID:       s.sessionID,
+		Serial:          s.serial,
+		RefreshInterval: s.config.RefreshInterval,
+		RetryInterval:   s.config.RetryInterval,
+		ExpireInterval:  s.config.ExpireInterval,
+	}).Serialize(buf)
+
+	return buf.Bytes()
+}
+
+func (s *Server) serialNotify(version uint8) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buf := bytes.NewBuffer(nil)
+	(&packet.PDU{
+		Version:   version,
+		Type:      packet.SerialNotifyType,
+		SessionID: s.sessionID,
+		Serial:    s.serial,
+	}).Serialize(buf)
+
+	return buf.Bytes()
+}
+
+func noData(version uint8) []byte {
+	buf := bytes.NewBuffer(nil)
+	packet.ErrorReport(version, packet.NoDataAvailable, nil, "No data available").Serialize(buf)
+	return buf.Bytes()
+}
+
+func cacheReset(version uint8) []byte {
+	buf := bytes.NewBuffer(nil)
+	(&packet.PDU{
+		Version: version,
+		Type:    packet.CacheResetType,
+	}).Serialize(buf)
+
+	return buf.Bytes()
+}
+
+// netChanges merges consecutive deltas. As announcements and withdrawals of a VRP alternate,
+// a VRP changed state if its first and last change are of the same kind.
+func netChanges(deltas []delta) (announce []vrp.VRP, withdraw []vrp.VRP) {
+	first := make(map[vrp.VRP]bool)
+	last := make(map[vrp.VRP]bool)
+	record := func(vrps []vrp.VRP, announced bool) {
+		for _, v := range vrps {
+			if _, ok := first[v]; !ok {
+				first[v] = announced
+			}
+
+			last[v] = announced
+		}
+	}
+
+	for _, d := range deltas {
+		record(d.withdraw, false)
+		record(d.announce, true)
+	}
+
+	for v, announced := range last {
+		if first[v] != announced {
+			continue
+		}
+
+		if announced {
+			announce = append(announce, v)
+		} else {
+			withdraw = append(withdraw, v)
+		}
+	}
+
+	vrp.Sort(announce)
+	vrp.Sort(withdraw)
+	return announce, withdraw
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/rpki/client"
+	"github.com/bio-routing/bio-rd/protocols/rpki/packet"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	vrpA = vrp.VRP{Prefix: bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24), MaxLength: 24, ASN: 64496}
+	vrpB = vrp.VRP{Prefix: bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24), MaxLength: 24, ASN: 64497}
+	vrpC = vrp.VRP{Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32), MaxLength: 48, ASN: 64498}
+)
+
+func TestNetChanges(t *testing.T) {
+	tests := []struct {
+		name             string
+		deltas           []delta
+		expectedAnnounce []vrp.VRP
+		expectedWithdraw []vrp.VRP
+	}{
+		{
+			name: "Single delta",
+			deltas: []delta{
+				{announce: []vrp.VRP{vrpA}, withdraw: []vrp.VRP{vrpB}},
+			},
+			expectedAnnounce: []vrp.VRP{vrpA},
+			expectedWithdraw: []vrp.VRP{vrpB},
+		},
+		{
+			name: "Announce and withdraw cancel out",
+			deltas: []delta{
+				{announce: []vrp.VRP{vrpA}},
+				{withdraw: []vrp.VRP{vrpA}, announce: []vrp.VRP{vrpC}},
+			},
+			expectedAnnounce: []vrp.VRP{vrpC},
+		},
+		{
+			name: "Withdraw and reannounce cancel out",
+			deltas: []delta{
+				{withdraw: []vrp.VRP{vrpA}},
+				{announce: []vrp.VRP{vrpA}},
+				{withdraw: []vrp.VRP{vrpB}},
+			},
+			expectedWithdraw: []vrp.VRP{vrpB},
+		},
+	}
+
+	for _, test := range tests {
+		announce, withdraw := netChanges(test.deltas)
+		assert.Equal(t, test.expectedAnnounce, announce, "Test %q", test.name)
+		assert.Equal(t, test.expectedWithdraw, withdraw, "Test %q", test.name)
+	}
+}
+
+func decodeAll(t *testing.T, b []byte) []*packet.PDU {
+	ret := make([]*packet.PDU, 0)
+	r := bytes.NewReader(b)
+	for r.Len() > 0 {
+		p, _, err := packet.Read(r)
+		if err != nil {
+			t.Fatalf("Unable to decode: %v", err)
+		}
+
+		ret = append(ret, p)
+	}
+
+	return ret
+}
+
+func TestIncrementalResponse(t *testing.T) {
+	s := New(&config.RTRCacheConfig{
+		HistorySize: 2,
+	})
+	s.sessionID = 100
+
+	assert.Equal(t, []*packet.PDU{
+		packet.ErrorReport(packet.Version1, packet.NoDataAvailable, []byte{}, "No data available"),
+	}, decodeAll(t, s.incrementalResponse(packet.Version1, 100, 0)))
+
+	s.SetVRPs([]vrp.VRP{vrpA})
+	s.SetVRPs([]vrp.VRP{vrpA, vrpB})
+	s.SetVRPs([]vrp.VRP{vrpB, vrpC})
+	s.SetVRPs([]vrp.VRP{vrpB, vrpC})
+	assert.Equal(t, uint32(2), s.serial)
+
+	endOfData := &packet.PDU{
+		Version:         packet.Version1,
+		Type:            packet.EndOfDataType,
+		SessionID:       100,
+		Serial:          2,
+		RefreshInterval: packet.DefaultRefreshInterval,
+		RetryInterval:   packet.DefaultRetryInterval,
+		ExpireInterval:  packet.DefaultExpireInterval,
+	}
+	cacheResponse := &packet.PDU{
+		Version:   packet.Version1,
+		Type:      packet.CacheResponseType,
+		SessionID: 100,
+	}
+	cacheReset := &packet.PDU{
+		Version: packet.Version1,
+		Type:    packet.CacheResetType,
+	}
+
+	tests := []struct {
+		name      string
+		sessionID uint16
+		serial    uint32
+		expected  []*packet.PDU
+	}{
+		{
+			name:      "Up to date",
+			sessionID: 100,
+			serial:    2,
+			expected:  []*packet.PDU{cacheResponse, endOfData},
+		},
+		{
+			name:      "One behind",
+			sessionID: 100,
+			serial:    1,
+			expected: []*packet.PDU{
+				cacheResponse,
+				packet.NewPrefixPDU(packet.Version1, false, vrpA.Prefix, vrpA.MaxLength, vrpA.ASN),
+				packet.NewPrefixPDU(packet.Version1, true, vrpC.Prefix, vrpC.MaxLength, vrpC.ASN),
+				endOfData,
+			},
+		},
+		{
+			name:      "Two behind",
+			sessionID: 100,
+			serial:    0,
+			expected: []*packet.PDU{
+				cacheResponse,
+				packet.NewPrefixPDU(packet.Version1, false, vrpA.Prefix, vrpA.MaxLength, vrpA.ASN),
+				packet.NewPrefixPDU(packet.Version1, true, vrpB.Prefix, vrpB.MaxLength, vrpB.ASN),
+				packet.NewPrefixPDU(packet.Version1, true, vrpC.Prefix, vrpC.MaxLength, vrpC.ASN),
+				endOfData,
+			},
+		},
+		{
+			name:      "Unknown serial",
+			sessionID: 100,
+			serial:    7,
+			expected:  []*packet.PDU{cacheReset},
+		},
+		{
+			name:      "Other session",
+			sessionID: 101,
+			serial:    2,
+			expected:  []*packet.PDU{cacheReset},
+		},
+	}
+
+	for _, test := range tests {
+		res := decodeAll(t, s.incrementalResponse(packet.Version1, test.sessionID, test.serial))
+		assert.Equal(t, test.expected, res, "Test %q", test.name)
+	}
+
+	// Serial 0 drops out of the history
+	s.SetVRPs([]vrp.VRP{vrpC})
+	assert.Equal(t, []*packet.PDU{cacheReset}, decodeAll(t, s.incrementalResponse(packet.Version1, 100, 0)))
+}
+
+func waitFor(t *testing.T, f func() bool, msg string) {
+	for i := 0; i < 200; i++ {
+		if f() {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Timeout waiting for %s", msg)
+}
+
+func TestSessionID(t *testing.T) {
+	// Caches get random session IDs. Four caches all sharing one ID are practically impossible.
+	ids := make(map[uint16]struct{})
+	for i := 0; i < 4; i++ {
+		ids[New(&config.RTRCacheConfig{}).sessionID] = struct{}{}
+	}
+
+	assert.True(t, len(ids) > 1, "All caches got the same session ID")
+}
+
+func TestClientServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+
+	s := New(&config.RTRCacheConfig{})
+	s.start(l)
+	defer s.Stop()
+
+	updates := make(chan []vrp.VRP, 10)
+	c := client.New(&config.RTRClientConfig{
+		Address:       l.Addr().String(),
+		RetryInterval: 1,
+	}, func(vrps []vrp.VRP) {
+		updates <- vrps
+	})
+	c.Start()
+	defer c.Stop()
+
+	waitFor(t, func() bool { return s.Status().Clients == 1 }, "client to connect")
+
+	s.SetVRPs([]vrp.VRP{vrpA, vrpB})
+	assert.Equal(t, []vrp.VRP{vrpA, vrpB}, <-updates)
+
+	// The client fetches changes after being notified
+	s.SetVRPs([]vrp.VRP{vrpB, vrpC})
+	assert.Equal(t, []vrp.VRP{vrpB, vrpC}, <-updates)
+	assert.Equal(t, []vrp.VRP{vrpB, vrpC}, c.VRPs())
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"bytes"
+	"net"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/protocols/rpki/packet"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+)
+
+// conn is a client connection
+type conn struct {
+	srv  *Server
+	c    net.Conn
+	name string
+
+	// version is the protocol version negotiated with the first PDU. It is -1 until then.
+	mu      sync.Mutex
+	version int
+
+	notifyCh  chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newConn(srv *Server, c net.Conn) *conn {
+	return &conn{
+		srv:      srv,
+		c:        c,
+		name:     c.RemoteAddr().String(),
+		version:  -1,
+		notifyCh: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+func (cc *conn) close() {
+	cc.closeOnce.Do(func() {
+		close(cc.done)
+		cc.c.Close()
+	})
+}
+
+// notify schedules a Serial Notify to the client without blocking
+func (cc *conn) notify() {
+	select {
+	case cc.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (cc *conn) notifier() {
+	defer crashdump.Recover()
+	defer cc.srv.wg.Done()
+
+	for {
+		select {
+		case <-cc.done:
+			return
+		case <-cc.notifyCh:
+			cc.mu.Lock()
+			version := cc.version
+			cc.mu.Unlock()
+
+			// We can't notify clients before we know which protocol version they speak
+			if version < 0 {
+				continue
+			}
+
+			cc.write(cc.srv.serialNotify(uint8(version)))
+		}
+	}
+}
+
+func (cc *conn) write(b []byte) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	_, err := cc.c.Write(b)
+	if err != nil {
+		log.Warningf("RTR: Unable to send to %s: %v", cc.name, err)
+		cc.close()
+	}
+}
+
+// fatal sends an error report and closes the connection
+func (cc *conn) fatal(version uint8, code uint16, erroneous []byte, text string) {
+	log.Warningf("RTR: Closing connection to %s: %s", cc.name, text)
+
+	buf := bytes.NewBuffer(nil)
+	packet.ErrorReport(version, code, erroneous, text).Serialize(buf)
+	cc.write(buf.Bytes())
+	cc.close()
+}
+
+func (cc *conn) receiver() {
+	defer crashdump.Recover()
+	defer cc.srv.wg.Done()
+	defer cc.srv.removeConn(cc)
+	defer cc.close()
+
+	for {
+		p, raw, err := packet.Read(cc.c)
+		if err != nil {
+			if raw != nil {
+				cc.fatal(packet.Version1, packet.CorruptData, raw, err.Error())
+				return
+			}
+
+			select {
+			case <-cc.done:
+			default:
+				log.Infof("RTR: Client %s disconnected: %v", cc.name, err)
+			}
+			return
+		}
+
+		if !cc.negotiateVersion(p, raw) {
+			return
+		}
+
+		switch p.Type {
+		case packet.ResetQueryType:
+			cc.write(cc.srv.fullResponse(p.Version))
+		case packet.SerialQueryType:
+			cc.write(cc.srv.incrementalResponse(p.Version, p.SessionID, p.Serial))
+		case packet.ErrorReportType:
+			log.Warningf("RTR: Client %s reported error %d: %s", cc.name, p.ErrorCode, p.ErrorText)
+			return
+		default:
+			cc.fatal(p.Version, packet.UnsupportedPDUType, raw, "Unsupported PDU type")
+			return
+		}
+	}
+}
+
+// negotiateVersion fixes the protocol version with the first PDU of the client (RFC 8210 7)
+func (cc *conn) negotiateVersion(p *packet.PDU, raw []byte) bool {
+	cc.mu.Lock()
+	version := cc.version
+	if version < 0 && p.Version <= packet.Version1 {
+		cc.version = int(p.Version)
+		version = cc.version
+	}
+	cc.mu.Unlock()
+
+	if p.Version > packet.Version1 {
+		cc.fatal(packet.Version1, packet.UnsupportedProtocolVersion, raw, "Unsupported protocol version")
+		return false
+	}
+
+	if int(p.Version) != version {
+		cc.fatal(uint8(version), packet.UnexpectedProtocolVersion, raw, "Unexpected protocol version")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,7 @@
+package cache
+
+import (
+	"github.com/bio-routing/bio-rd/util/logging"
+)
+
+var log = logging.Subsystem("rtr")
@@ -0,0 +1,428 @@
+// Package client implements an RTR client fetching VRPs from an RPKI cache
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	"github.com/bio-routing/bio-rd/protocols/rpki/packet"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/pkg/errors"
+)
+
+// UpdateFunc is called with all VRPs whenever the cache sent new data
+type UpdateFunc func(vrps []vrp.VRP)
+
+// Client is an RTR client
+type Client struct {
+	config   *config.RTRClientConfig
+	onUpdate UpdateFunc
+	dial     func(addr string) (net.Conn, error)
+
+	// mu protects all state below
+	mu         sync.RWMutex
+	version    uint8
+	sessionID  uint16
+	serial     uint32
+	hasData    bool
+	vrps       vrp.Set
+	lastUpdate time.Time
+	refresh    time.Duration
+	retry      time.Duration
+	expire     time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new RTR client. onUpdate may be nil.
+func New(cfg *config.RTRClientConfig, onUpdate UpdateFunc) *Client {
+	retry := time.Duration(cfg.RetryInterval) * time.Second
+	if retry == 0 {
+		retry = packet.DefaultRetryInterval * time.Second
+	}
+
+	return &Client{
+		config:   cfg,
+		onUpdate: onUpdate,
+		dial:     dialTCP,
+		version:  packet.Version1,
+		vrps:     make(vrp.Set),
+		refresh:  packet.DefaultRefreshInterval * time.Second,
+		retry:    retry,
+		expire:   packet.DefaultExpireInterval * time.Second,
+		stop:     make(chan struct{}),
+	}
+}
+
+func dialTCP(addr string) (net.Conn, error) {
+	return net.DialTimeout("tcp", addr, 10*time.Second)
+}
+
+// Start starts the client. It keeps (re)connecting to the cache until stopped.
+func (c *Client) Start() {
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop stops the client
+func (c *Client) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// Ready returns if the client has received data from the cache
+func (c *Client) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.hasData
+}
+
+// VRPs gets the current VRPs
+func (c *Client) VRPs() []vrp.VRP {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.vrps.List()
+}
+
+func (c *Client) run() {
+	defer crashdump.Recover()
+	defer c.wg.Done()
+
+	for {
+		err := c.connect()
+		if err != nil {
+			log.Warningf("RTR: Session to %s failed: %v", c.config.Address, err)
+		}
+
+		c.expireData()
+
+		c.mu.RLock()
+		retry := c.retry
+		c.mu.RUnlock()
+
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+// expireData drops the VRPs if we failed to refresh them within the expire interval
+func (c *Client) expireData() {
+	c.mu.Lock()
+	if !c.hasData || time.Since(c.lastUpdate) < c.expire {
+		c.mu.Unlock()
+		return
+	}
+
+	log.Warningf("RTR: Data from %s expired", c.config.Address)
+	c.hasData = false
+	c.vrps = make(vrp.Set)
+	c.mu.Unlock()
+
+	if c.onUpdate != nil {
+		c.onUpdate(nil)
+	}
+}
+
+func (c *Client) connect() error {
+	conn, err := c.dial(c.config.Address)
+	if err != nil {
+		return errors.Wrap(err, "Unable to connect")
+	}
+	defer conn.Close()
+
+	log.Infof("RTR: Connected to %s", c.config.Address)
+
+	err = c.session(conn)
+	if err == errDowngrade {
+		// Retry right away with the older protocol version
+		return c.connect()
+	}
+
+	return err
+}
+
+var errDowngrade = fmt.Errorf("Protocol version downgrade")
+
+type received struct {
+	pdu *packet.PDU
+	raw []byte
+	err error
+}
+
+// session runs the protocol on a connection until it fails or the client is stopped
+func (c *Client) session(conn net.Conn) error {
+	pdus := make(chan received)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			p, raw, err := packet.Read(conn)
+			select {
+			case pdus <- received{pdu: p, raw: raw, err: err}:
+			case <-done:
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	s := &session{
+		client: c,
+		conn:   conn,
+	}
+
+	err := s.query()
+	if err != nil {
+		return err
+	}
+
+	for {
+		c.mu.RLock()
+		refresh := c.refresh
+		c.mu.RUnlock()
+
+		select {
+		case <-c.stop:
+			return nil
+		case <-time.After(refresh):
+			if !s.queried {
+				err = s.query()
+			}
+		case r := <-pdus:
+			if r.err != nil {
+				if r.raw != nil {
+					s.sendError(packet.CorruptData, r.raw, r.err.Error())
+				}
+
+				return r.err
+			}
+
+			err = s.process(r.pdu, r.raw)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// session is the state of a single connection to the cache
+type session struct {
+	client *Client
+	conn   net.Conn
+
+	// queried is set while we wait for the response to a query
+	queried bool
+
+	// pending holds the VRPs as of the running transaction. It is nil if there is none.
+	pending vrp.Set
+	reset   bool
+}
+
+func (s *session) send(p *packet.PDU) error {
+	buf := bytes.NewBuffer(nil)
+	p.Serialize(buf)
+
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+func (s *session) sendError(code uint16, raw []byte, text string) {
+	s.client.mu.RLock()
+	version := s.client.version
+	s.client.mu.RUnlock()
+
+	s.send(packet.ErrorReport(version, code, raw, text))
+}
+
+// query asks for incremental updates if we have data of the current session and for all data otherwise
+func (s *session) query() error {
+	c := s.client
+	c.mu.RLock()
+	p := packet.ResetQuery(c.version)
+	if c.hasData {
+		p = packet.SerialQuery(c.version, c.sessionID, c.serial)
+	}
+	c.mu.RUnlock()
+
+	s.queried = true
+	s.reset = p.Type == packet.ResetQueryType
+	return s.send(p)
+}
+
+func (s *session) resetQuery() error {
+	c := s.client
+	c.mu.RLock()
+	version := c.version
+	c.mu.RUnlock()
+
+	s.queried = true
+	s.reset = true
+	return s.send(packet.ResetQuery(version))
+}
+
+func (s *session) process(p *packet.PDU, raw []byte) error {
+	c := s.client
+
+	c.mu.RLock()
+	version := c.version
+	c.mu.RUnlock()
+
+	if p.Type == packet.ErrorReportType {
+		if p.ErrorCode == packet.UnsupportedProtocolVersion && version > packet.Version0 {
+			c.mu.Lock()
+			c.version = packet.Version0
+			c.mu.Unlock()
+			return errDowngrade
+		}
+
+		if p.ErrorCode == packet.NoDataAvailable {
+			log.Infof("RTR: Cache %s has no data available yet", c.config.Address)
+			s.queried = false
+			return nil
+		}
+
+		return fmt.Errorf("Cache reported error %d: %s", p.ErrorCode, p.ErrorText)
+	}
+
+	if p.Version != version {
+		s.sendError(packet.UnexpectedProtocolVersion, raw, "Unexpected protocol version")
+		return fmt.Errorf("Unexpected protocol version %d", p.Version)
+	}
+
+	switch p.Type {
+	case packet.SerialNotifyType:
+		if !s.queried {
+			return s.query()
+		}
+	case packet.CacheResetType:
+		return s.resetQuery()
+	case packet.CacheResponseType:
+		return s.cacheResponse(p, raw)
+	case packet.IPv4PrefixType, packet.IPv6PrefixType:
+		return s.prefix(p, raw)
+	case packet.EndOfDataType:
+		return s.endOfData(p, raw)
+	case packet.RouterKeyType:
+		// BGPsec router keys are not supported
+	default:
+		s.sendError(packet.UnsupportedPDUType, raw, "Unsupported PDU type")
+		return fmt.Errorf("Unsupported PDU type %d", p.Type)
+	}
+
+	return nil
+}
+
+func (s *session) cacheResponse(p *packet.PDU, raw []byte) error {
+	c := s.client
+	if !s.queried || s.pending != nil {
+		s.sendError(packet.CorruptData, raw, "Unexpected Cache Response")
+		return fmt.Errorf("Unexpected Cache Response")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if s.reset {
+		s.pending = make(vrp.Set)
+		return nil
+	}
+
+	if p.SessionID != c.sessionID {
+		s.sendError(packet.CorruptData, raw, "Session ID mismatch")
+		return fmt.Errorf("Session ID changed from %d to %d", c.sessionID, p.SessionID)
+	}
+
+	s.pending = make(vrp.Set, len(c.vrps))
+	for v := range c.vrps {
+		s.pending[v] = struct{}{}
+	}
+
+	return nil
+}
+
+func (s *session) prefix(p *packet.PDU, raw []byte) error {
+	if s.pending == nil {
+		s.sendError(packet.CorruptData, raw, "Prefix outside of Cache Response")
+		return fmt.Errorf("Prefix outside of Cache Response")
+	}
+
+	v := vrp.VRP{
+		Prefix:    p.Prefix,
+		MaxLength: p.MaxLength,
+		ASN:       p.ASN,
+	}
+
+	_, exists := s.pending[v]
+	if p.Announce() {
+		if exists {
+			s.sendError(packet.DuplicateAnnouncement, raw, "Duplicate announcement")
+			return fmt.Errorf("Duplicate announcement of %s", v.String())
+		}
+
+		s.pending[v] = struct{}{}
+		return nil
+	}
+
+	if !exists {
+		s.sendError(packet.WithdrawalOfUnknownRecord, raw, "Withdrawal of unknown record")
+		return fmt.Errorf("Withdrawal of unknown %s", v.String())
+	}
+
+	delete(s.pending, v)
+	return nil
+}
+
+func (s *session) endOfData(p *packet.PDU, raw []byte) error {
+	c := s.client
+	if s.pending == nil {
+		s.sendError(packet.CorruptData, raw, "End of Data outside of Cache Response")
+		return fmt.Errorf("End of Data outside of Cache Response")
+	}
+
+	c.mu.Lock()
+	c.vrps = s.pending
+	c.sessionID = p.SessionID
+	c.serial = p.Serial
+	c.hasData = true
+	c.lastUpdate = time.Now()
+	if p.Version >= packet.Version1 {
+		setInterval(&c.refresh, p.RefreshInterval)
+		setInterval(&c.retry, p.RetryInterval)
+		setInterval(&c.expire, p.ExpireInterval)
+	}
+	vrps := c.vrps.List()
+	c.mu.Unlock()
+
+	s.pending = nil
+	s.queried = false
+
+	log.Infof("RTR: Received serial %d with %d VRPs from %s", p.Serial, len(vrps), c.config.Address)
+	if c.onUpdate != nil {
+		c.onUpdate(vrps)
+	}
+
+	return nil
+}
+
+// setInterval sets a timing parameter received from the cache. Zero values are ignored.
+func setInterval(d *time.Duration, seconds uint32) {
+	if seconds != 0 {
+		*d = time.Duration(seconds) * time.Second
+	}
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/rpki/packet"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pfxA = bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	pfxB = bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24)
+)
+
+func newTestSession() (*session, *Client) {
+	c := New(&config.RTRClientConfig{}, nil)
+	local, remote := net.Pipe()
+	go io.Copy(ioutil.Discard, remote)
+
+	return &session{
+		client: c,
+		conn:   local,
+	}, c
+}
+
+func cacheResponse(sessionID uint16) *packet.PDU {
+	return &packet.PDU{
+		Version:   packet.Version1,
+		Type:      packet.CacheResponseType,
+		SessionID: sessionID,
+	}
+}
+
+func endOfData(sessionID uint16, serial uint32) *packet.PDU {
+	return &packet.PDU{
+		Version:         packet.Version1,
+		Type:            packet.EndOfDataType,
+		SessionID:       sessionID,
+		Serial:          serial,
+		RefreshInterval: 60,
+		RetryInterval:   10,
+		ExpireInterval:  600,
+	}
+}
+
+func announce(pfx bnet.Prefix, asn uint32) *packet.PDU {
+	return packet.NewPrefixPDU(packet.Version1, true, pfx, pfx.Pfxlen(), asn)
+}
+
+func withdraw(pfx bnet.Prefix, asn uint32) *packet.PDU {
+	return packet.NewPrefixPDU(packet.Version1, false, pfx, pfx.Pfxlen(), asn)
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		pdus     []*packet.PDU
+		wantFail bool
+		expected []vrp.VRP
+	}{
+		{
+			name: "Full and incremental update",
+			pdus: []*packet.PDU{
+				cacheResponse(1),
+				announce(pfxA, 64496),
+				announce(pfxB, 64497),
+				endOfData(1, 1),
+				cacheResponse(1),
+				withdraw(pfxA, 64496),
+				endOfData(1, 2),
+			},
+			expected: []vrp.VRP{
+				{Prefix: pfxB, MaxLength: 24, ASN: 64497},
+			},
+		},
+		{
+			name: "Duplicate announcement",
+			pdus: []*packet.PDU{
+				cacheResponse(1),
+				announce(pfxA, 64496),
+				announce(pfxA, 64496),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Withdrawal of unknown record",
+			pdus: []*packet.PDU{
+				cacheResponse(1),
+				withdraw(pfxA, 64496),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Prefix outside of response",
+			pdus: []*packet.PDU{
+				announce(pfxA, 64496),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Session ID changed",
+			pdus: []*packet.PDU{
+				cacheResponse(1),
+				endOfData(1, 1),
+				cacheResponse(2),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Error report",
+			pdus: []*packet.PDU{
+				packet.ErrorReport(packet.Version1, packet.InternalError, nil, "oops"),
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unexpected version",
+			pdus: []*packet.PDU{
+				packet.ResetQuery(packet.Version0),
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		s, c := newTestSession()
+
+		var err error
+		for _, p := range test.pdus {
+			if p.Type == packet.CacheResponseType {
+				err = s.query()
+				if err != nil {
+					break
+				}
+			}
+
+			err = s.process(p, nil)
+			if err != nil {
+				break
+			}
+		}
+
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, c.VRPs(), "Test %q", test.name)
+	}
+}
+
+func TestVersionDowngrade(t *testing.T) {
+	s, c := newTestSession()
+
+	err := s.process(packet.ErrorReport(packet.Version1, packet.UnsupportedProtocolVersion, nil, ""), nil)
+	assert.Equal(t, errDowngrade, err)
+	assert.Equal(t, uint8(packet.Version0), c.version)
+}
@@ -0,0 +1,367 @@
+// Package packet implements the RPKI to router protocol (RFC 6810, RFC 8210)
+package packet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Version0 is the protocol version of RFC 6810
+	Version0 = 0
+
+	// Version1 is the protocol version of RFC 8210
+	Version1 = 1
+
+	// HeaderLen is the length of the PDU header
+	HeaderLen = 8
+
+	// MaxPDULength is the maximum length of PDUs we accept
+	MaxPDULength = 65536
+
+	// AnnounceFlag marks prefix PDUs announcing (instead of withdrawing) a VRP
+	AnnounceFlag = 1
+)
+
+// PDU types
+const (
+	SerialNotifyType  = 0
+	SerialQueryType   = 1
+	ResetQueryType    = 2
+	CacheResponseType = 3
+	IPv4PrefixType    = 4
+	IPv6PrefixType    = 6
+	EndOfDataType     = 7
+	CacheResetType    = 8
+	RouterKeyType     = 9
+	ErrorReportType   = 10
+)
+
+// Error codes
+const (
+	CorruptData                = 0
+	InternalError              = 1
+	NoDataAvailable            = 2
+	InvalidRequest             = 3
+	UnsupportedProtocolVersion = 4
+	UnsupportedPDUType         = 5
+	WithdrawalOfUnknownRecord  = 6
+	DuplicateAnnouncement      = 7
+	UnexpectedProtocolVersion  = 8
+)
+
+// Default timing parameters in seconds (RFC 8210 6)
+const (
+	DefaultRefreshInterval = 3600
+	DefaultRetryInterval   = 600
+	DefaultExpireInterval  = 7200
+)
+
+const (
+	ipv4PrefixLen     = 20
+	ipv6PrefixLen     = 32
+	serialLen         = 12
+	endOfDataLenV0    = 12
+	endOfDataLenV1    = 24
+	errorReportMinLen = 16
+)
+
+// PDU is an RTR protocol data unit. Fields not used by a PDU type are ignored.
+type PDU struct {
+	Version uint8
+	Type    uint8
+
+	// SessionID is the session ID of Serial Notify, Serial Query, Cache Response and End of Data PDUs
+	SessionID uint16
+
+	// ErrorCode is the error code of Error Report PDUs
+	ErrorCode uint16
+
+	// Serial is the serial number of Serial Notify, Serial Query and End of Data PDUs
+	Serial uint32
+
+	// Prefix PDU fields
+	Flags     uint8
+	Prefix    bnet.Prefix
+	MaxLength uint8
+	ASN       uint32
+
+	// End of Data timing parameters (version 1 only)
+	RefreshInterval uint32
+	RetryInterval   uint32
+	ExpireInterval  uint32
+
+	// Error Report fields
+	ErroneousPDU []byte
+	ErrorText    string
+
+	// Raw is the body of PDU types that are not decoded (e.g. Router Key)
+	Raw []byte
+}
+
+// Announce returns if a prefix PDU announces a VRP
+func (p *PDU) Announce() bool {
+	return p.Flags&AnnounceFlag != 0
+}
+
+// ResetQuery creates a Reset Query PDU
+func ResetQuery(version uint8) *PDU {
+	return &PDU{
+		Version: version,
+		Type:    ResetQueryType,
+	}
+}
+
+// SerialQuery creates a Serial Query PDU
+func SerialQuery(version uint8, sessionID uint16, serial uint32) *PDU {
+	return &PDU{
+		Version:   version,
+		Type:      SerialQueryType,
+		SessionID: sessionID,
+		Serial:    serial,
+	}
+}
+
+// ErrorReport creates an Error Report PDU
+func ErrorReport(version uint8, code uint16, erroneous []byte, text string) *PDU {
+	return &PDU{
+		Version:      version,
+		Type:         ErrorReportType,
+		ErrorCode:    code,
+		ErroneousPDU: erroneous,
+		ErrorText:    text,
+	}
+}
+
+// Serialize serializes the PDU
+func (p *PDU) Serialize(buf *bytes.Buffer) {
+	buf.WriteByte(p.Version)
+	buf.WriteByte(p.Type)
+
+	switch p.Type {
+	case SerialNotifyType, SerialQueryType:
+		endian.WriteUint16(buf, p.SessionID)
+		endian.WriteUint32(buf, serialLen)
+		endian.WriteUint32(buf, p.Serial)
+	case ResetQueryType, CacheResetType:
+		endian.WriteUint16(buf, 0)
+		endian.WriteUint32(buf, HeaderLen)
+	case CacheResponseType:
+		endian.WriteUint16(buf, p.SessionID)
+		endian.WriteUint32(buf, HeaderLen)
+	case IPv4PrefixType, IPv6PrefixType:
+		l := ipv4PrefixLen
+		if p.Type == IPv6PrefixType {
+			l = ipv6PrefixLen
+		}
+
+		endian.WriteUint16(buf, 0)
+		endian.WriteUint32(buf, uint32(l))
+		buf.WriteByte(p.Flags)
+		buf.WriteByte(p.Prefix.Pfxlen())
+		buf.WriteByte(p.MaxLength)
+		buf.WriteByte(0)
+		buf.Write(p.Prefix.Addr().Bytes())
+		endian.WriteUint32(buf, p.ASN)
+	case EndOfDataType:
+		endian.WriteUint16(buf, p.SessionID)
+		if p.Version == Version0 {
+			endian.WriteUint32(buf, endOfDataLenV0)
+			endian.WriteUint32(buf, p.Serial)
+			return
+		}
+
+		endian.WriteUint32(buf, endOfDataLenV1)
+		endian.WriteUint32(buf, p.Serial)
+		endian.WriteUint32(buf, p.RefreshInterval)
+		endian.WriteUint32(buf, p.RetryInterval)
+		endian.WriteUint32(buf, p.ExpireInterval)
+	case ErrorReportType:
+		endian.WriteUint16(buf, p.ErrorCode)
+		endian.WriteUint32(buf, uint32(errorReportMinLen+len(p.ErroneousPDU)+len(p.ErrorText)))
+		endian.WriteUint32(buf, uint32(len(p.ErroneousPDU)))
+		buf.Write(p.ErroneousPDU)
+		endian.WriteUint32(buf, uint32(len(p.ErrorText)))
+		buf.WriteString(p.ErrorText)
+	default:
+		endian.WriteUint16(buf, p.SessionID)
+		endian.WriteUint32(buf, uint32(HeaderLen+len(p.Raw)))
+		buf.Write(p.Raw)
+	}
+}
+
+// NewPrefixPDU creates an IPv4 or IPv6 prefix PDU
+func NewPrefixPDU(version uint8, announce bool, pfx bnet.Prefix, maxLength uint8, asn uint32) *PDU {
+	p := &PDU{
+		Version:   version,
+		Type:      IPv4PrefixType,
+		Prefix:    pfx,
+		MaxLength: maxLength,
+		ASN:       asn,
+	}
+
+	if !pfx.Addr().IsIPv4() {
+		p.Type = IPv6PrefixType
+	}
+
+	if announce {
+		p.Flags = AnnounceFlag
+	}
+
+	return p
+}
+
+// Read reads a PDU from r
+func Read(r io.Reader) (*PDU, []byte, error) {
+	hdr := make([]byte, HeaderLen)
+	_, err := io.ReadFull(r, hdr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := endian.Uint32(hdr[4:])
+	if l < HeaderLen || l > MaxPDULength {
+		return nil, hdr, fmt.Errorf("Invalid PDU length %d", l)
+	}
+
+	raw := make([]byte, l)
+	copy(raw, hdr)
+	_, err = io.ReadFull(r, raw[HeaderLen:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := Decode(raw)
+	return p, raw, err
+}
+
+// Decode decodes a PDU
+func Decode(b []byte) (*PDU, error) {
+	if len(b) < HeaderLen {
+		return nil, fmt.Errorf("PDU too short")
+	}
+
+	if int(endian.Uint32(b[4:])) != len(b) {
+		return nil, fmt.Errorf("Length mismatch")
+	}
+
+	p := &PDU{
+		Version:   b[0],
+		Type:      b[1],
+		SessionID: endian.Uint16(b[2:]),
+	}
+	body := b[HeaderLen:]
+
+	switch p.Type {
+	case SerialNotifyType, SerialQueryType:
+		if len(b) != serialLen {
+			return nil, fmt.Errorf("Invalid length %d", len(b))
+		}
+
+		p.Serial = endian.Uint32(body)
+	case ResetQueryType, CacheResetType, CacheResponseType:
+		if len(b) != HeaderLen {
+			return nil, fmt.Errorf("Invalid length %d", len(b))
+		}
+	case IPv4PrefixType, IPv6PrefixType:
+		err := p.decodePrefix(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode prefix")
+		}
+	case EndOfDataType:
+		err := p.decodeEndOfData(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode end of data")
+		}
+	case ErrorReportType:
+		err := p.decodeErrorReport(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to decode error report")
+		}
+	default:
+		p.Raw = body
+	}
+
+	return p, nil
+}
+
+func (p *PDU) decodePrefix(b []byte) error {
+	l, addrLen, maxPfxLen := ipv4PrefixLen, 4, uint8(32)
+	if p.Type == IPv6PrefixType {
+		l, addrLen, maxPfxLen = ipv6PrefixLen, 16, 128
+	}
+
+	if len(b) != l {
+		return fmt.Errorf("Invalid length %d", len(b))
+	}
+
+	p.SessionID = 0
+	p.Flags = b[8]
+	pfxLen := b[9]
+	p.MaxLength = b[10]
+	if pfxLen > maxPfxLen || p.MaxLength > maxPfxLen || p.MaxLength < pfxLen {
+		return fmt.Errorf("Invalid prefix length %d/max length %d", pfxLen, p.MaxLength)
+	}
+
+	addr, err := bnet.IPFromBytes(b[12 : 12+addrLen])
+	if err != nil {
+		return err
+	}
+
+	p.Prefix = bnet.NewPfx(addr, pfxLen)
+	p.ASN = endian.Uint32(b[12+addrLen:])
+	return nil
+}
+
+func (p *PDU) decodeEndOfData(b []byte) error {
+	if p.Version == Version0 {
+		if len(b) != endOfDataLenV0 {
+			return fmt.Errorf("Invalid length %d", len(b))
+		}
+
+		p.Serial = endian.Uint32(b[8:])
+		return nil
+	}
+
+	if len(b) != endOfDataLenV1 {
+		return fmt.Errorf("Invalid length %d", len(b))
+	}
+
+	p.Serial = endian.Uint32(b[8:])
+	p.RefreshInterval = endian.Uint32(b[12:])
+	p.RetryInterval = endian.Uint32(b[16:])
+	p.ExpireInterval = endian.Uint32(b[20:])
+	return nil
+}
+
+func (p *PDU) decodeErrorReport(b []byte) error {
+	p.ErrorCode = p.SessionID
+	p.SessionID = 0
+
+	if len(b) < errorReportMinLen {
+		return fmt.Errorf("Invalid length %d", len(b))
+	}
+
+	pduLen := int(endian.Uint32(b[8:]))
+	b = b[12:]
+	if pduLen > len(b)-4 {
+		return fmt.Errorf("Invalid encapsulated PDU length %d", pduLen)
+	}
+
+	p.ErroneousPDU = b[:pduLen]
+	b = b[pduLen:]
+
+	textLen := int(endian.Uint32(b))
+	b = b[4:]
+	if textLen != len(b) {
+		return fmt.Errorf("Invalid error text length %d", textLen)
+	}
+
+	p.ErrorText = string(b)
+	return nil
+}
@@ -0,0 +1,181 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		pdu      *PDU
+		expected []byte
+	}{
+		{
+			name: "Serial Notify",
+			pdu: &PDU{
+				Version:   Version1,
+				Type:      SerialNotifyType,
+				SessionID: 0x1234,
+				Serial:    42,
+			},
+			expected: []byte{1, 0, 0x12, 0x34, 0, 0, 0, 12, 0, 0, 0, 42},
+		},
+		{
+			name:     "Serial Query",
+			pdu:      SerialQuery(Version1, 0x1234, 42),
+			expected: []byte{1, 1, 0x12, 0x34, 0, 0, 0, 12, 0, 0, 0, 42},
+		},
+		{
+			name:     "Reset Query",
+			pdu:      ResetQuery(Version0),
+			expected: []byte{0, 2, 0, 0, 0, 0, 0, 8},
+		},
+		{
+			name: "Cache Response",
+			pdu: &PDU{
+				Version:   Version1,
+				Type:      CacheResponseType,
+				SessionID: 0x1234,
+			},
+			expected: []byte{1, 3, 0x12, 0x34, 0, 0, 0, 8},
+		},
+		{
+			name: "IPv4 Prefix",
+			pdu:  NewPrefixPDU(Version1, true, bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24), 24, 64496),
+			expected: []byte{
+				1, 4, 0, 0, 0, 0, 0, 20,
+				1, 24, 24, 0,
+				192, 0, 2, 0,
+				0, 0, 0xfb, 0xf0,
+			},
+		},
+		{
+			name: "IPv6 Prefix withdraw",
+			pdu:  NewPrefixPDU(Version1, false, bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32), 48, 64496),
+			expected: []byte{
+				1, 6, 0, 0, 0, 0, 0, 32,
+				0, 32, 48, 0,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0xfb, 0xf0,
+			},
+		},
+		{
+			name: "End of Data v0",
+			pdu: &PDU{
+				Version:   Version0,
+				Type:      EndOfDataType,
+				SessionID: 0x1234,
+				Serial:    42,
+			},
+			expected: []byte{0, 7, 0x12, 0x34, 0, 0, 0, 12, 0, 0, 0, 42},
+		},
+		{
+			name: "End of Data v1",
+			pdu: &PDU{
+				Version:         Version1,
+				Type:            EndOfDataType,
+				SessionID:       0x1234,
+				Serial:          42,
+				RefreshInterval: 3600,
+				RetryInterval:   600,
+				ExpireInterval:  7200,
+			},
+			expected: []byte{
+				1, 7, 0x12, 0x34, 0, 0, 0, 24,
+				0, 0, 0, 42,
+				0, 0, 0x0e, 0x10,
+				0, 0, 0x02, 0x58,
+				0, 0, 0x1c, 0x20,
+			},
+		},
+		{
+			name: "Cache Reset",
+			pdu: &PDU{
+				Version: Version1,
+				Type:    CacheResetType,
+			},
+			expected: []byte{1, 8, 0, 0, 0, 0, 0, 8},
+		},
+		{
+			name: "Error Report",
+			pdu:  ErrorReport(Version1, NoDataAvailable, []byte{1, 2, 0, 0, 0, 0, 0, 8}, "no"),
+			expected: []byte{
+				1, 10, 0, 2, 0, 0, 0, 26,
+				0, 0, 0, 8,
+				1, 2, 0, 0, 0, 0, 0, 8,
+				0, 0, 0, 2,
+				'n', 'o',
+			},
+		},
+	}
+
+	for _, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		test.pdu.Serialize(buf)
+		assert.Equal(t, test.expected, buf.Bytes(), "Test %q", test.name)
+
+		p, raw, err := Read(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Errorf("Unexpected error for test %q: %v", test.name, err)
+			continue
+		}
+
+		assert.Equal(t, test.expected, raw, "Test %q", test.name)
+		assert.Equal(t, test.pdu, p, "Test %q", test.name)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Too short",
+			input: []byte{1, 2, 0, 0},
+		},
+		{
+			name:  "Length mismatch",
+			input: []byte{1, 2, 0, 0, 0, 0, 0, 9},
+		},
+		{
+			name:  "Serial Query too short",
+			input: []byte{1, 1, 0, 0, 0, 0, 0, 8},
+		},
+		{
+			name: "Max length below prefix length",
+			input: []byte{
+				1, 4, 0, 0, 0, 0, 0, 20,
+				1, 24, 16, 0,
+				192, 0, 2, 0,
+				0, 0, 0xfb, 0xf0,
+			},
+		},
+		{
+			name: "IPv4 prefix length exceeds 32",
+			input: []byte{
+				1, 4, 0, 0, 0, 0, 0, 20,
+				1, 33, 33, 0,
+				192, 0, 2, 0,
+				0, 0, 0xfb, 0xf0,
+			},
+		},
+		{
+			name: "Error Report text length",
+			input: []byte{
+				1, 10, 0, 2, 0, 0, 0, 16,
+				0, 0, 0, 0,
+				0, 0, 0, 2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Decode(test.input)
+		assert.Error(t, err, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,92 @@
+package vrp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/pkg/errors"
+)
+
+// jsonASN is an AS number given as number or as "AS<number>" string
+type jsonASN uint32
+
+func (a *jsonASN) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		b = []byte(strings.TrimPrefix(strings.ToUpper(s), "AS"))
+	}
+
+	asn, err := strconv.ParseUint(string(b), 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid ASN %s", string(b))
+	}
+
+	*a = jsonASN(asn)
+	return nil
+}
+
+type jsonROA struct {
+	ASN       jsonASN `json:"asn"`
+	Prefix    string  `json:"prefix"`
+	MaxLength uint8   `json:"maxLength"`
+}
+
+type jsonFile struct {
+	ROAs []jsonROA `json:"roas"`
+}
+
+// LoadJSON loads VRPs in the JSON format exported by common validators (e.g. rpki-client, Routinator, OctoRPKI)
+func LoadJSON(r io.Reader) ([]VRP, error) {
+	f := jsonFile{}
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode JSON")
+	}
+
+	ret := make([]VRP, 0, len(f.ROAs))
+	for _, roa := range f.ROAs {
+		v, err := newVRP(roa.Prefix, roa.MaxLength, uint32(roa.ASN))
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, v)
+	}
+
+	return ret, nil
+}
+
+// newVRP creates a VRP. A max length of 0 defaults to the prefix length.
+func newVRP(prefix string, maxLength uint8, asn uint32) (VRP, error) {
+	pfx, err := bnet.PrefixFromString(prefix)
+	if err != nil {
+		return VRP{}, errors.Wrapf(err, "Invalid prefix %q", prefix)
+	}
+
+	maxPfxLen := uint8(128)
+	if pfx.Addr().IsIPv4() {
+		maxPfxLen = 32
+	}
+
+	if pfx.Pfxlen() > maxPfxLen {
+		return VRP{}, fmt.Errorf("Invalid prefix length %d", pfx.Pfxlen())
+	}
+
+	if maxLength == 0 {
+		maxLength = pfx.Pfxlen()
+	}
+
+	if maxLength < pfx.Pfxlen() || maxLength > maxPfxLen {
+		return VRP{}, fmt.Errorf("Invalid max length %d for %s", maxLength, prefix)
+	}
+
+	return VRP{
+		Prefix:    bnet.NewPfx(*pfx.BaseAddr(), pfx.Pfxlen()),
+		MaxLength: maxLength,
+		ASN:       asn,
+	}, nil
+}
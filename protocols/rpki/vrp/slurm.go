@@ -0,0 +1,128 @@
+package vrp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/pkg/errors"
+)
+
+// SLURM holds local exceptions to RPKI data (RFC 8416). BGPsec filters and assertions are ignored.
+type SLURM struct {
+	filters    []prefixFilter
+	assertions []VRP
+}
+
+type prefixFilter struct {
+	prefix *bnet.Prefix
+	asn    *uint32
+}
+
+type slurmFile struct {
+	Version                 int `json:"slurmVersion"`
+	ValidationOutputFilters struct {
+		PrefixFilters []struct {
+			Prefix string   `json:"prefix"`
+			ASN    *jsonASN `json:"asn"`
+		} `json:"prefixFilters"`
+	} `json:"validationOutputFilters"`
+	LocallyAddedAssertions struct {
+		PrefixAssertions []struct {
+			Prefix          string  `json:"prefix"`
+			ASN             jsonASN `json:"asn"`
+			MaxPrefixLength uint8   `json:"maxPrefixLength"`
+		} `json:"prefixAssertions"`
+	} `json:"locallyAddedAssertions"`
+}
+
+// LoadSLURM loads a SLURM file
+func LoadSLURM(r io.Reader) (*SLURM, error) {
+	f := slurmFile{}
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode JSON")
+	}
+
+	if f.Version != 1 {
+		return nil, fmt.Errorf("Unsupported SLURM version %d", f.Version)
+	}
+
+	s := &SLURM{}
+	for _, pf := range f.ValidationOutputFilters.PrefixFilters {
+		if pf.Prefix == "" && pf.ASN == nil {
+			return nil, fmt.Errorf("Prefix filter without prefix and ASN")
+		}
+
+		filter := prefixFilter{}
+		if pf.Prefix != "" {
+			pfx, err := bnet.PrefixFromString(pf.Prefix)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid prefix filter %q", pf.Prefix)
+			}
+
+			filter.prefix = pfx
+		}
+
+		if pf.ASN != nil {
+			asn := uint32(*pf.ASN)
+			filter.asn = &asn
+		}
+
+		s.filters = append(s.filters, filter)
+	}
+
+	for _, pa := range f.LocallyAddedAssertions.PrefixAssertions {
+		v, err := newVRP(pa.Prefix, pa.MaxPrefixLength, uint32(pa.ASN))
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid prefix assertion")
+		}
+
+		s.assertions = append(s.assertions, v)
+	}
+
+	return s, nil
+}
+
+// Apply removes all VRPs matching a filter and adds the locally asserted VRPs
+func (s *SLURM) Apply(vrps []VRP) []VRP {
+	ret := make(Set, len(vrps)+len(s.assertions))
+	for _, v := range vrps {
+		if !s.filtered(v) {
+			ret[v] = struct{}{}
+		}
+	}
+
+	for _, v := range s.assertions {
+		ret[v] = struct{}{}
+	}
+
+	return ret.List()
+}
+
+func (s *SLURM) filtered(v VRP) bool {
+	for _, f := range s.filters {
+		if f.prefix != nil && !covers(f.prefix, &v.Prefix) {
+			continue
+		}
+
+		if f.asn != nil && *f.asn != v.ASN {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// covers returns if x is equal to or more specific than pfx
+func covers(pfx *bnet.Prefix, x *bnet.Prefix) bool {
+	if pfx.Addr().IsIPv4() != x.Addr().IsIPv4() || x.Pfxlen() < pfx.Pfxlen() {
+		return false
+	}
+
+	y := bnet.NewPfx(*x.Addr(), pfx.Pfxlen())
+	return y.BaseAddr().Compare(pfx.BaseAddr()) == 0
+}
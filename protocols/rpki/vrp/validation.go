@@ -0,0 +1,98 @@
+package vrp
+
+import (
+	"fmt"
+	"net/netip"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// ValidationState is the origin validation state of a route (RFC6811)
+type ValidationState uint8
+
+// Validation states. NotValidated is used for routes origin validation is not done for.
+const (
+	NotValidated ValidationState = iota
+	Valid
+	NotFound
+	Invalid
+)
+
+// String returns the name of the state
+func (s ValidationState) String() string {
+	switch s {
+	case Valid:
+		return "valid"
+	case NotFound:
+		return "not-found"
+	case Invalid:
+		return "invalid"
+	default:
+		return "not-validated"
+	}
+}
+
+// ParseValidationState parses the name of a validation state
+func ParseValidationState(s string) (ValidationState, error) {
+	for _, x := range []ValidationState{NotValidated, Valid, NotFound, Invalid} {
+		if x.String() == s {
+			return x, nil
+		}
+	}
+
+	return NotValidated, fmt.Errorf("Unknown validation state %q", s)
+}
+
+// Table is a set of VRPs indexed for origin validation
+type Table struct {
+	vrps  map[netip.Prefix][]VRP
+	count int
+}
+
+// NewTable creates a table from a list of VRPs
+func NewTable(vrps []VRP) *Table {
+	t := &Table{
+		vrps: make(map[netip.Prefix][]VRP),
+	}
+
+	for _, v := range vrps {
+		pfx := v.Prefix.ToNetIPPrefix().Masked()
+		t.vrps[pfx] = append(t.vrps[pfx], v)
+		t.count++
+	}
+
+	return t
+}
+
+// Count returns the number of VRPs in the table
+func (t *Table) Count() int {
+	return t.count
+}
+
+// Validate computes the validation state of a route for pfx originated by origin. A nil origin
+// means the origin is unknown (e.g. the AS path ends with an AS_SET) and never matches a VRP.
+func (t *Table) Validate(pfx *bnet.Prefix, origin *uint32) ValidationState {
+	p := pfx.ToNetIPPrefix()
+	covered := false
+
+	for l := 0; l <= p.Bits(); l++ {
+		vrps, ok := t.vrps[netip.PrefixFrom(p.Addr(), l).Masked()]
+		if !ok {
+			continue
+		}
+
+		covered = true
+		for _, v := range vrps {
+			// VRPs for AS 0 never match (RFC6483 4)
+			if origin != nil && v.ASN != 0 && v.ASN == *origin && pfx.Pfxlen() <= v.MaxLength {
+				return Valid
+			}
+		}
+	}
+
+	if covered {
+		return Invalid
+	}
+
+	return NotFound
+}
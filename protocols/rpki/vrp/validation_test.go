@@ -0,0 +1,101 @@
+package vrp
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tbl := NewTable([]VRP{
+		v4VRP(192, 0, 2, 0, 24, 24, 64496),
+		v4VRP(198, 51, 100, 0, 22, 24, 64497),
+		v4VRP(203, 0, 113, 0, 24, 24, 0),
+		{
+			Prefix:    bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+			MaxLength: 48,
+			ASN:       64496,
+		},
+	})
+
+	asn := func(x uint32) *uint32 {
+		return &x
+	}
+
+	tests := []struct {
+		name     string
+		pfx      bnet.Prefix
+		origin   *uint32
+		expected ValidationState
+	}{
+		{
+			name:     "Exact match",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24),
+			origin:   asn(64496),
+			expected: Valid,
+		},
+		{
+			name:     "Wrong origin",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24),
+			origin:   asn(64511),
+			expected: Invalid,
+		},
+		{
+			name:     "More specific within max length",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 101, 0), 24),
+			origin:   asn(64497),
+			expected: Valid,
+		},
+		{
+			name:     "More specific exceeding max length",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 101, 0), 25),
+			origin:   asn(64497),
+			expected: Invalid,
+		},
+		{
+			name:     "Unknown origin",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24),
+			expected: Invalid,
+		},
+		{
+			name:     "AS 0",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(203, 0, 113, 0), 24),
+			origin:   asn(0),
+			expected: Invalid,
+		},
+		{
+			name:     "Not covered",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8),
+			origin:   asn(64496),
+			expected: NotFound,
+		},
+		{
+			name:     "Less specific is not covered",
+			pfx:      bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 0, 0), 16),
+			origin:   asn(64496),
+			expected: NotFound,
+		},
+		{
+			name:     "IPv6",
+			pfx:      bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0x100, 0, 0, 0, 0, 0), 48),
+			origin:   asn(64496),
+			expected: Valid,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, tbl.Validate(&test.pfx, test.origin), "Test %q", test.name)
+	}
+}
+
+func TestParseValidationState(t *testing.T) {
+	for _, s := range []ValidationState{NotValidated, Valid, NotFound, Invalid} {
+		x, err := ParseValidationState(s.String())
+		assert.NoErrorf(t, err, "Test %q", s.String())
+		assert.Equalf(t, s, x, "Test %q", s.String())
+	}
+
+	_, err := ParseValidationState("foo")
+	assert.Error(t, err)
+}
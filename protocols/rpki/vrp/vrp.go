@@ -0,0 +1,92 @@
+// Package vrp provides validated ROA payloads and ways to load them
+package vrp
+
+import (
+	"fmt"
+	"sort"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// VRP is a validated ROA payload
+type VRP struct {
+	Prefix    bnet.Prefix
+	MaxLength uint8
+	ASN       uint32
+}
+
+func (v VRP) String() string {
+	return fmt.Sprintf("%s-%d AS%d", v.Prefix.String(), v.MaxLength, v.ASN)
+}
+
+// Less defines a total order on VRPs (by address family, prefix, max length and ASN)
+func (v VRP) Less(w VRP) bool {
+	if v.Prefix.Addr().IsIPv4() != w.Prefix.Addr().IsIPv4() {
+		return v.Prefix.Addr().IsIPv4()
+	}
+
+	c := v.Prefix.Addr().Compare(w.Prefix.Addr())
+	if c != 0 {
+		return c < 0
+	}
+
+	if v.Prefix.Pfxlen() != w.Prefix.Pfxlen() {
+		return v.Prefix.Pfxlen() < w.Prefix.Pfxlen()
+	}
+
+	if v.MaxLength != w.MaxLength {
+		return v.MaxLength < w.MaxLength
+	}
+
+	return v.ASN < w.ASN
+}
+
+// Sort sorts VRPs
+func Sort(vrps []VRP) {
+	sort.Slice(vrps, func(i, j int) bool {
+		return vrps[i].Less(vrps[j])
+	})
+}
+
+// Set is a set of VRPs
+type Set map[VRP]struct{}
+
+// NewSet creates a set from a list of VRPs
+func NewSet(vrps []VRP) Set {
+	s := make(Set, len(vrps))
+	for _, v := range vrps {
+		s[v] = struct{}{}
+	}
+
+	return s
+}
+
+// List returns the sorted VRPs of the set
+func (s Set) List() []VRP {
+	ret := make([]VRP, 0, len(s))
+	for v := range s {
+		ret = append(ret, v)
+	}
+
+	Sort(ret)
+	return ret
+}
+
+// Diff returns the VRPs to announce and to withdraw to get from set s to set t
+func (s Set) Diff(t Set) (announce []VRP, withdraw []VRP) {
+	for v := range t {
+		if _, ok := s[v]; !ok {
+			announce = append(announce, v)
+		}
+	}
+
+	for v := range s {
+		if _, ok := t[v]; !ok {
+			withdraw = append(withdraw, v)
+		}
+	}
+
+	Sort(announce)
+	Sort(withdraw)
+	return announce, withdraw
+}
@@ -0,0 +1,182 @@
+package vrp
+
+import (
+	"strings"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func v4VRP(a, b, c, d uint8, pfxlen uint8, maxLength uint8, asn uint32) VRP {
+	return VRP{
+		Prefix:    bnet.NewPfx(bnet.IPv4FromOctets(a, b, c, d), pfxlen),
+		MaxLength: maxLength,
+		ASN:       asn,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := v4VRP(192, 0, 2, 0, 24, 24, 64496)
+	b := v4VRP(198, 51, 100, 0, 24, 24, 64497)
+	c := v4VRP(10, 0, 0, 0, 8, 16, 64498)
+
+	announce, withdraw := NewSet([]VRP{a, b}).Diff(NewSet([]VRP{b, c}))
+	assert.Equal(t, []VRP{c}, announce)
+	assert.Equal(t, []VRP{a}, withdraw)
+}
+
+func TestSort(t *testing.T) {
+	v6 := VRP{
+		Prefix:    bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+		MaxLength: 48,
+		ASN:       64496,
+	}
+
+	vrps := []VRP{
+		v6,
+		v4VRP(192, 0, 2, 0, 24, 24, 64497),
+		v4VRP(192, 0, 2, 0, 24, 24, 64496),
+		v4VRP(192, 0, 2, 0, 23, 24, 64496),
+		v4VRP(10, 0, 0, 0, 8, 8, 64496),
+	}
+	Sort(vrps)
+
+	assert.Equal(t, []VRP{
+		v4VRP(10, 0, 0, 0, 8, 8, 64496),
+		v4VRP(192, 0, 2, 0, 23, 24, 64496),
+		v4VRP(192, 0, 2, 0, 24, 24, 64496),
+		v4VRP(192, 0, 2, 0, 24, 24, 64497),
+		v6,
+	}, vrps)
+}
+
+func TestLoadJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantFail bool
+		expected []VRP
+	}{
+		{
+			name: "Valid",
+			input: `{"metadata": {"buildtime": "2020-01-01T00:00:00Z"}, "roas": [
+				{"asn": "AS64496", "prefix": "192.0.2.0/24", "maxLength": 24, "ta": "ripe"},
+				{"asn": 64497, "prefix": "198.51.100.0/22", "maxLength": 24, "ta": "arin"},
+				{"asn": "AS64498", "prefix": "2001:db8::/32", "ta": "apnic"}
+			]}`,
+			expected: []VRP{
+				v4VRP(192, 0, 2, 0, 24, 24, 64496),
+				v4VRP(198, 51, 100, 0, 22, 24, 64497),
+				{
+					Prefix:    bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+					MaxLength: 32,
+					ASN:       64498,
+				},
+			},
+		},
+		{
+			name:     "Invalid ASN",
+			input:    `{"roas": [{"asn": "ASX", "prefix": "192.0.2.0/24", "maxLength": 24}]}`,
+			wantFail: true,
+		},
+		{
+			name:     "Max length too short",
+			input:    `{"roas": [{"asn": 64496, "prefix": "192.0.2.0/24", "maxLength": 16}]}`,
+			wantFail: true,
+		},
+		{
+			name:     "Max length too long",
+			input:    `{"roas": [{"asn": 64496, "prefix": "192.0.2.0/24", "maxLength": 33}]}`,
+			wantFail: true,
+		},
+		{
+			name:     "Invalid prefix",
+			input:    `{"roas": [{"asn": 64496, "prefix": "192.0.2.0", "maxLength": 24}]}`,
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		vrps, err := LoadJSON(strings.NewReader(test.input))
+		if test.wantFail {
+			assert.Error(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, vrps, "Test %q", test.name)
+	}
+}
+
+func TestSLURM(t *testing.T) {
+	input := `{
+		"slurmVersion": 1,
+		"validationOutputFilters": {
+			"prefixFilters": [
+				{"prefix": "192.0.2.0/24", "comment": "All VRPs encompassed by prefix"},
+				{"asn": 64497, "comment": "All VRPs matching ASN"},
+				{"prefix": "198.51.100.0/24", "asn": 64498, "comment": "All VRPs encompassed by prefix, matching ASN"}
+			],
+			"bgpsecFilters": []
+		},
+		"locallyAddedAssertions": {
+			"prefixAssertions": [
+				{"asn": 64496, "prefix": "203.0.113.0/24", "comment": "My other important route"},
+				{"asn": 64496, "prefix": "2001:db8::/32", "maxPrefixLength": 48}
+			],
+			"bgpsecAssertions": []
+		}
+	}`
+
+	s, err := LoadSLURM(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	vrps := s.Apply([]VRP{
+		v4VRP(192, 0, 2, 0, 24, 24, 64499),
+		v4VRP(192, 0, 2, 128, 25, 25, 64499),
+		v4VRP(192, 0, 0, 0, 16, 24, 64499),
+		v4VRP(10, 0, 0, 0, 8, 8, 64497),
+		v4VRP(198, 51, 100, 0, 24, 24, 64498),
+		v4VRP(198, 51, 100, 0, 24, 24, 64499),
+		v4VRP(203, 0, 113, 0, 24, 24, 64496),
+	})
+
+	assert.Equal(t, []VRP{
+		v4VRP(192, 0, 0, 0, 16, 24, 64499),
+		v4VRP(198, 51, 100, 0, 24, 24, 64499),
+		v4VRP(203, 0, 113, 0, 24, 24, 64496),
+		{
+			Prefix:    bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32),
+			MaxLength: 48,
+			ASN:       64496,
+		},
+	}, vrps)
+}
+
+func TestLoadSLURMInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Wrong version",
+			input: `{"slurmVersion": 2}`,
+		},
+		{
+			name:  "Empty filter",
+			input: `{"slurmVersion": 1, "validationOutputFilters": {"prefixFilters": [{"comment": "x"}]}}`,
+		},
+		{
+			name:  "Invalid assertion",
+			input: `{"slurmVersion": 1, "locallyAddedAssertions": {"prefixAssertions": [{"asn": 1, "prefix": "x"}]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := LoadSLURM(strings.NewReader(test.input))
+		assert.Error(t, err, "Test %q", test.name)
+	}
+}
@@ -0,0 +1,162 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/checksum"
+	"github.com/bio-routing/bio-rd/util/endian"
+)
+
+const (
+	// Protocol is the IP protocol number of VRRP
+	Protocol = 112
+
+	// TTL is the TTL (hop limit) advertisements are sent with and have to be received with
+	TTL = 255
+
+	// Version2 is VRRP version 2 (RFC 3768)
+	Version2 = 2
+
+	// Version3 is VRRP version 3 (RFC 5798)
+	Version3 = 3
+
+	// AdvertisementType is the only VRRP packet type
+	AdvertisementType = 1
+
+	// PriorityOwner is the priority of the router owning the virtual addresses
+	PriorityOwner = 255
+
+	// PriorityStop is sent by a master giving up its role
+	PriorityStop = 0
+
+	headerLen     = 8
+	v2AuthDataLen = 8
+	maxInterval   = 0x0fff
+)
+
+// Advertisement is a VRRP advertisement
+type Advertisement struct {
+	Version  uint8
+	VRID     uint8
+	Priority uint8
+
+	// Interval is the advertisement interval in centiseconds. Version 2 advertisements carry whole seconds.
+	Interval  uint16
+	Addresses []bnet.IP
+}
+
+// Serialize serializes an advertisement. src and dst are the addresses of the IP packet carrying it
+// which are part of the version 3 checksum.
+func (a *Advertisement) Serialize(buf *bytes.Buffer, src bnet.IP, dst bnet.IP) {
+	start := buf.Len()
+
+	buf.WriteByte(a.Version<<4 | AdvertisementType)
+	buf.WriteByte(a.VRID)
+	buf.WriteByte(a.Priority)
+	buf.WriteByte(uint8(len(a.Addresses)))
+
+	if a.Version == Version2 {
+		buf.WriteByte(0) // Authentication type
+		buf.WriteByte(uint8(a.Interval / 100))
+	} else {
+		endian.WriteUint16(buf, a.Interval&maxInterval)
+	}
+
+	endian.WriteUint16(buf, 0) // Checksum
+
+	for _, addr := range a.Addresses {
+		buf.Write(addr.Bytes())
+	}
+
+	if a.Version == Version2 {
+		buf.Write(make([]byte, v2AuthDataLen))
+	}
+
+	msg := buf.Bytes()[start:]
+	endian.PutUint16(msg[6:], checksumOf(a.Version, msg, src, dst))
+}
+
+// Decode decodes an advertisement received from src to dst
+func Decode(b []byte, src bnet.IP, dst bnet.IP) (*Advertisement, error) {
+	if len(b) < headerLen {
+		return nil, fmt.Errorf("Advertisement too short")
+	}
+
+	a := &Advertisement{
+		Version:  b[0] >> 4,
+		VRID:     b[1],
+		Priority: b[2],
+	}
+
+	if t := b[0] & 0x0f; t != AdvertisementType {
+		return nil, fmt.Errorf("Unknown packet type %d", t)
+	}
+
+	addrLen := 4
+	if !src.IsIPv4() {
+		addrLen = 16
+	}
+
+	switch a.Version {
+	case Version2:
+		if !src.IsIPv4() {
+			return nil, fmt.Errorf("Version 2 advertisement received via IPv6")
+		}
+
+		if b[4] != 0 {
+			return nil, fmt.Errorf("Unsupported authentication type %d", b[4])
+		}
+
+		a.Interval = uint16(b[5]) * 100
+	case Version3:
+		a.Interval = endian.Uint16(b[4:]) & maxInterval
+	default:
+		return nil, fmt.Errorf("Unsupported version %d", a.Version)
+	}
+
+	count := int(b[3])
+	l := headerLen + count*addrLen
+	if a.Version == Version2 {
+		l += v2AuthDataLen
+	}
+
+	if len(b) < l {
+		return nil, fmt.Errorf("Advertisement with %d addresses too short", count)
+	}
+
+	b = b[:l]
+	if checksumOf(a.Version, b, src, dst) != 0 {
+		return nil, fmt.Errorf("Invalid checksum")
+	}
+
+	a.Addresses = make([]bnet.IP, 0, count)
+	for i := 0; i < count; i++ {
+		ip, err := bnet.IPFromBytes(b[headerLen+i*addrLen : headerLen+(i+1)*addrLen])
+		if err != nil {
+			return nil, err
+		}
+
+		a.Addresses = append(a.Addresses, ip)
+	}
+
+	return a, nil
+}
+
+// checksumOf computes the checksum of msg. Version 3 includes the IP pseudo header.
+// Applied to a message including a valid checksum it returns 0.
+func checksumOf(version uint8, msg []byte, src bnet.IP, dst bnet.IP) uint16 {
+	if version == Version2 {
+		return checksum.Internet(msg)
+	}
+
+	var sum uint32
+	if src.IsIPv4() {
+		sum = checksum.IPv4PseudoHeaderSum(src.ToNetIP(), dst.ToNetIP(), Protocol, uint16(len(msg)))
+	} else {
+		sum = checksum.IPv6PseudoHeaderSum(src.ToNetIP(), dst.ToNetIP(), Protocol, uint32(len(msg)))
+	}
+
+	return checksum.Fold(checksum.Sum(msg, sum))
+}
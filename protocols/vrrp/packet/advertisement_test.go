@@ -0,0 +1,170 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testSrc   = bnet.IPv4FromOctets(192, 168, 0, 2)
+	testDst   = bnet.IPv4FromOctets(224, 0, 0, 18)
+	testSrc6  = bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 2)
+	testDst6  = bnet.IPv6FromBlocks(0xff02, 0, 0, 0, 0, 0, 0, 0x12)
+	testVIP   = bnet.IPv4FromOctets(192, 168, 0, 1)
+	testVIPv6 = bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1)
+)
+
+func TestSerializeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		adv      *Advertisement
+		src      bnet.IP
+		dst      bnet.IP
+		expected []byte
+	}{
+		{
+			name: "Version 2",
+			adv: &Advertisement{
+				Version:   Version2,
+				VRID:      1,
+				Priority:  100,
+				Interval:  100,
+				Addresses: []bnet.IP{testVIP},
+			},
+			src: testSrc,
+			dst: testDst,
+			expected: []byte{
+				0x21, 1, 100, 1,
+				0, 1, // auth type, interval
+				0xba, 0x52, // checksum
+				192, 168, 0, 1,
+				0, 0, 0, 0, 0, 0, 0, 0, // auth data
+			},
+		},
+		{
+			name: "Version 3 IPv4",
+			adv: &Advertisement{
+				Version:   Version3,
+				VRID:      1,
+				Priority:  100,
+				Interval:  100,
+				Addresses: []bnet.IP{testVIP},
+			},
+			src: testSrc,
+			dst: testDst,
+			expected: []byte{
+				0x31, 1, 100, 1,
+				0, 100,
+				0x08, 0xb6,
+				192, 168, 0, 1,
+			},
+		},
+		{
+			name: "Version 3 IPv6",
+			adv: &Advertisement{
+				Version:   Version3,
+				VRID:      7,
+				Priority:  255,
+				Interval:  50,
+				Addresses: []bnet.IP{testVIPv6},
+			},
+			src: testSrc6,
+			dst: testDst6,
+		},
+	}
+
+	for _, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		test.adv.Serialize(buf, test.src, test.dst)
+		if test.expected != nil {
+			assert.Equalf(t, test.expected, buf.Bytes(), "Test %q", test.name)
+		}
+
+		a, err := Decode(buf.Bytes(), test.src, test.dst)
+		if !assert.NoErrorf(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equalf(t, test.adv, a, "Test %q", test.name)
+	}
+}
+
+func TestDecodeFail(t *testing.T) {
+	valid := []byte{
+		0x31, 1, 100, 1,
+		0, 100,
+		0x08, 0xb6,
+		192, 168, 0, 1,
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		src   bnet.IP
+	}{
+		{
+			name:  "Too short",
+			input: valid[:6],
+			src:   testSrc,
+		},
+		{
+			name:  "Address list truncated",
+			input: valid[:10],
+			src:   testSrc,
+		},
+		{
+			name: "Bad checksum",
+			input: []byte{
+				0x31, 1, 100, 1,
+				0, 100,
+				0x08, 0xb7,
+				192, 168, 0, 1,
+			},
+			src: testSrc,
+		},
+		{
+			name:  "Checksum with wrong source",
+			input: valid,
+			src:   bnet.IPv4FromOctets(192, 168, 0, 3),
+		},
+		{
+			name: "Unknown type",
+			input: []byte{
+				0x32, 1, 100, 1,
+				0, 100,
+				0x08, 0xb5,
+				192, 168, 0, 1,
+			},
+			src: testSrc,
+		},
+		{
+			name: "Unsupported version",
+			input: []byte{
+				0x41, 1, 100, 1,
+				0, 100,
+				0xf8, 0xb6,
+				192, 168, 0, 1,
+			},
+			src: testSrc,
+		},
+		{
+			name: "Version 2 authentication",
+			input: []byte{
+				0x21, 1, 100, 1,
+				1, 1,
+				0xb9, 0x52,
+				192, 168, 0, 1,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+			src: testSrc,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Decode(test.input, test.src, testDst)
+		assert.Errorf(t, err, "Test %q", test.name)
+	}
+}
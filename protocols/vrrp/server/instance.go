@@ -0,0 +1,380 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/vrrp/packet"
+	"github.com/bio-routing/bio-rd/util/crashdump"
+	"github.com/pkg/errors"
+)
+
+type state uint8
+
+const (
+	initialize state = iota
+	backup
+	master
+)
+
+func stateName(s state) string {
+	switch s {
+	case initialize:
+		return "initialize"
+	case backup:
+		return "backup"
+	case master:
+		return "master"
+	}
+
+	return "unknown"
+}
+
+type receivedAdvertisement struct {
+	adv *packet.Advertisement
+	src bnet.IP
+}
+
+// instance is a virtual router (RFC 5798 6.4). Protocol events are processed by a single goroutine.
+type instance struct {
+	srv *Server
+	cfg config.VRRPInstanceConfig
+	sys sys
+
+	mu                  sync.Mutex
+	state               state
+	primaryAddress      bnet.IP
+	masterAddress       bnet.IP
+	masterAdverInterval uint16
+	timer               *time.Timer
+	timerDuration       time.Duration
+
+	rx   chan receivedAdvertisement
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newInstance(srv *Server, cfg config.VRRPInstanceConfig, s sys) *instance {
+	return &instance{
+		srv:                 srv,
+		cfg:                 cfg,
+		sys:                 s,
+		masterAdverInterval: cfg.AdvertisementInterval,
+		rx:                  make(chan receivedAdvertisement),
+		stop:                make(chan struct{}),
+	}
+}
+
+func (i *instance) name() string {
+	return fmt.Sprintf("%s/%d", i.cfg.Interface, i.cfg.VRID)
+}
+
+func (i *instance) ipv6() bool {
+	return len(i.cfg.VirtualAddresses) > 0 && !i.cfg.VirtualAddresses[0].Addr().IsIPv4()
+}
+
+func (i *instance) start() error {
+	err := i.sys.open()
+	if err != nil {
+		return errors.Wrap(err, "Unable to open socket")
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.primaryAddress, err = i.sys.primaryAddress(i.cfg.VirtualAddresses)
+	if err != nil {
+		i.sys.close()
+		return errors.Wrap(err, "Unable to get primary address")
+	}
+
+	i.timer = time.NewTimer(time.Hour)
+	i.startup()
+
+	i.wg.Add(1)
+	go i.receiver()
+
+	i.wg.Add(1)
+	go i.eventLoop(i.timer.C)
+
+	return nil
+}
+
+func (i *instance) stopInstance() {
+	close(i.stop)
+
+	i.mu.Lock()
+	i.shutdown()
+	i.mu.Unlock()
+
+	i.sys.close()
+	i.wg.Wait()
+}
+
+func (i *instance) receiver() {
+	defer crashdump.Recover()
+	defer i.wg.Done()
+
+	for {
+		pkt, src, dst, ttl, err := i.sys.recv()
+		if err != nil {
+			select {
+			case <-i.stop:
+			default:
+				log.Errorf("Unable to receive on %s: %v", i.name(), err)
+			}
+			return
+		}
+
+		if ttl != packet.TTL {
+			log.Debugf("Discarding advertisement from %s on %s with TTL %d", src.String(), i.name(), ttl)
+			continue
+		}
+
+		adv, err := packet.Decode(pkt, src, dst)
+		if err != nil {
+			log.Debugf("Discarding invalid advertisement from %s on %s: %v", src.String(), i.name(), err)
+			continue
+		}
+
+		select {
+		case i.rx <- receivedAdvertisement{adv: adv, src: src}:
+		case <-i.stop:
+			return
+		}
+	}
+}
+
+func (i *instance) eventLoop(timer <-chan time.Time) {
+	defer crashdump.Recover()
+	defer i.wg.Done()
+
+	for {
+		select {
+		case <-i.stop:
+			return
+		case r := <-i.rx:
+			i.mu.Lock()
+			i.processAdvertisement(r.adv, r.src)
+			i.mu.Unlock()
+		case <-timer:
+			i.mu.Lock()
+			i.timerExpired()
+			i.mu.Unlock()
+		}
+	}
+}
+
+// setTimer (re)starts the instance timer. In backup state it is the master down timer, in master state the advertisement timer.
+func (i *instance) setTimer(d time.Duration) {
+	i.timerDuration = d
+	if i.timer == nil {
+		return
+	}
+
+	if !i.timer.Stop() {
+		select {
+		case <-i.timer.C:
+		default:
+		}
+	}
+
+	i.timer.Reset(d)
+}
+
+func (i *instance) setState(s state, reason string) {
+	if i.state == s {
+		return
+	}
+
+	log.Infof("VRRP: %s transitions from %s to %s: %s", i.name(), stateName(i.state), stateName(s), reason)
+	i.srv.flightRecorder.Recorder("vrrp", i.name()).Record(stateName(i.state), stateName(s), reason)
+	i.state = s
+}
+
+func (i *instance) advertisementInterval() time.Duration {
+	return time.Duration(i.cfg.AdvertisementInterval) * 10 * time.Millisecond
+}
+
+// skewTime is the time a backup waits in addition to three advertisement intervals. Backups with a higher priority wait less.
+func (i *instance) skewTime() time.Duration {
+	if i.cfg.Version == packet.Version2 {
+		return time.Duration(256-int(i.cfg.Priority)) * time.Second / 256
+	}
+
+	return time.Duration(256-int(i.cfg.Priority)) * time.Duration(i.masterAdverInterval) * 10 * time.Millisecond / 256
+}
+
+func (i *instance) masterDownInterval() time.Duration {
+	return 3*time.Duration(i.masterAdverInterval)*10*time.Millisecond + i.skewTime()
+}
+
+// startup leaves the initialize state. Must be called with i.mu held.
+func (i *instance) startup() {
+	if i.cfg.Priority == packet.PriorityOwner {
+		i.becomeMaster("address owner")
+		return
+	}
+
+	i.masterAdverInterval = i.cfg.AdvertisementInterval
+	i.setTimer(i.masterDownInterval())
+	i.setState(backup, "startup")
+}
+
+// shutdown returns to the initialize state. A master makes backups take over immediately. Must be called with i.mu held.
+func (i *instance) shutdown() {
+	if i.state == master {
+		i.sendAdvertisement(packet.PriorityStop)
+		i.removeAddresses()
+	}
+
+	if i.timer != nil {
+		i.timer.Stop()
+	}
+
+	i.setState(initialize, "shutdown")
+}
+
+// timerExpired handles expiry of the master down timer (backup) or the advertisement timer (master). Must be called with i.mu held.
+func (i *instance) timerExpired() {
+	switch i.state {
+	case backup:
+		i.becomeMaster("master down timer expired")
+	case master:
+		i.sendAdvertisement(i.cfg.Priority)
+		i.setTimer(i.advertisementInterval())
+	}
+}
+
+// processAdvertisement processes an advertisement received from src. Must be called with i.mu held.
+func (i *instance) processAdvertisement(adv *packet.Advertisement, src bnet.IP) {
+	if adv.VRID != i.cfg.VRID || adv.Version != i.cfg.Version {
+		return
+	}
+
+	if adv.Version == packet.Version2 && adv.Interval != i.cfg.AdvertisementInterval {
+		log.Infof("VRRP: Discarding advertisement from %s on %s: Advertisement interval mismatch", src.String(), i.name())
+		return
+	}
+
+	if !i.addressesMatch(adv.Addresses) && adv.Priority != packet.PriorityOwner {
+		log.Infof("VRRP: Advertisement from %s on %s has a different address list", src.String(), i.name())
+	}
+
+	switch i.state {
+	case backup:
+		if adv.Priority == packet.PriorityStop {
+			i.setTimer(i.skewTime())
+			return
+		}
+
+		if !i.cfg.Preempt || adv.Priority >= i.cfg.Priority {
+			i.learnMaster(adv, src)
+			i.setTimer(i.masterDownInterval())
+		}
+	case master:
+		if adv.Priority == packet.PriorityStop {
+			i.sendAdvertisement(i.cfg.Priority)
+			i.setTimer(i.advertisementInterval())
+			return
+		}
+
+		if adv.Priority > i.cfg.Priority || (adv.Priority == i.cfg.Priority && src.Compare(&i.primaryAddress) > 0) {
+			i.learnMaster(adv, src)
+			i.becomeBackup(fmt.Sprintf("preferred master %s", src.String()))
+		}
+	}
+}
+
+func (i *instance) learnMaster(adv *packet.Advertisement, src bnet.IP) {
+	i.masterAddress = src
+	if adv.Version == packet.Version3 {
+		i.masterAdverInterval = adv.Interval
+	}
+}
+
+func (i *instance) addressesMatch(addrs []bnet.IP) bool {
+	if len(addrs) != len(i.cfg.VirtualAddresses) {
+		return false
+	}
+
+	for j := range addrs {
+		if addrs[j] != *i.cfg.VirtualAddresses[j].Addr() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i *instance) becomeMaster(reason string) {
+	i.sendAdvertisement(i.cfg.Priority)
+	i.addAddresses()
+	i.masterAddress = i.primaryAddress
+	i.setTimer(i.advertisementInterval())
+	i.setState(master, reason)
+}
+
+func (i *instance) becomeBackup(reason string) {
+	if i.state == master {
+		i.removeAddresses()
+	}
+
+	i.setTimer(i.masterDownInterval())
+	i.setState(backup, reason)
+}
+
+func (i *instance) addAddresses() {
+	for _, pfx := range i.cfg.VirtualAddresses {
+		err := i.sys.addAddress(pfx)
+		if err != nil {
+			log.Errorf("Unable to add %s to %q: %v", pfx.String(), i.cfg.Interface, err)
+			continue
+		}
+
+		err = i.sys.announce(*pfx.Addr())
+		if err != nil {
+			log.Errorf("Unable to announce %s on %q: %v", pfx.Addr().String(), i.cfg.Interface, err)
+		}
+	}
+}
+
+func (i *instance) removeAddresses() {
+	for _, pfx := range i.cfg.VirtualAddresses {
+		err := i.sys.delAddress(pfx)
+		if err != nil {
+			log.Errorf("Unable to remove %s from %q: %v", pfx.String(), i.cfg.Interface, err)
+		}
+	}
+}
+
+func (i *instance) sendAdvertisement(priority uint8) {
+	adv := &packet.Advertisement{
+		Version:   i.cfg.Version,
+		VRID:      i.cfg.VRID,
+		Priority:  priority,
+		Interval:  i.cfg.AdvertisementInterval,
+		Addresses: make([]bnet.IP, 0, len(i.cfg.VirtualAddresses)),
+	}
+
+	for _, pfx := range i.cfg.VirtualAddresses {
+		adv.Addresses = append(adv.Addresses, *pfx.Addr())
+	}
+
+	dst := allVRRPRoutersIPv4
+	if i.ipv6() {
+		dst = allVRRPRoutersIPv6
+	}
+
+	buf := bytes.NewBuffer(nil)
+	adv.Serialize(buf, i.primaryAddress, dst)
+
+	err := i.sys.send(buf.Bytes(), i.primaryAddress, dst)
+	if err != nil {
+		log.Errorf("Unable to send advertisement on %s: %v", i.name(), err)
+	}
+}
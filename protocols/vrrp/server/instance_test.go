@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/vrrp/packet"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testPrimary = bnet.IPv4FromOctets(192, 168, 0, 2)
+	testVIP     = bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 0, 1), 24)
+)
+
+func newTestInstance(priority uint8, preempt bool) (*instance, *mockSys) {
+	cfg := config.VRRPInstanceConfig{
+		Interface:        "eth0",
+		VRID:             1,
+		Priority:         priority,
+		Preempt:          preempt,
+		VirtualAddresses: []bnet.Prefix{testVIP},
+	}
+	validateInstanceConfig(&cfg)
+
+	m := newMockSys(testPrimary)
+	i := newInstance(&Server{}, cfg, m)
+	i.primaryAddress = testPrimary
+	return i, m
+}
+
+func adv(priority uint8, interval uint16) *packet.Advertisement {
+	return &packet.Advertisement{
+		Version:   packet.Version3,
+		VRID:      1,
+		Priority:  priority,
+		Interval:  interval,
+		Addresses: []bnet.IP{*testVIP.Addr()},
+	}
+}
+
+func sentAdvertisement(t *testing.T, m *mockSys, n int) *packet.Advertisement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !assert.True(t, len(m.sent) > n) {
+		return nil
+	}
+
+	a, err := packet.Decode(m.sent[n].pkt, m.sent[n].src, m.sent[n].dst)
+	assert.NoError(t, err)
+	return a
+}
+
+func TestStartup(t *testing.T) {
+	i, m := newTestInstance(packet.PriorityOwner, true)
+	i.startup()
+	assert.Equal(t, master, i.state)
+	assert.Equal(t, time.Second, i.timerDuration)
+	assert.Equal(t, adv(packet.PriorityOwner, 100), sentAdvertisement(t, m, 0))
+	assert.Equal(t, allVRRPRoutersIPv4, m.sent[0].dst)
+	assert.Contains(t, m.addresses, testVIP)
+	assert.Equal(t, []bnet.IP{*testVIP.Addr()}, m.announced)
+
+	i, m = newTestInstance(100, true)
+	i.startup()
+	assert.Equal(t, backup, i.state)
+	assert.Equal(t, 3*time.Second+time.Second*156/256, i.timerDuration)
+	assert.Equal(t, 0, len(m.sent))
+	assert.Equal(t, 0, len(m.addresses))
+}
+
+func TestBackup(t *testing.T) {
+	tests := []struct {
+		name          string
+		preempt       bool
+		adv           *packet.Advertisement
+		expectedTimer time.Duration
+		expectedState state
+	}{
+		{
+			name:          "Master keeps advertising",
+			preempt:       true,
+			adv:           adv(200, 100),
+			expectedTimer: 3*time.Second + time.Second*156/256,
+			expectedState: backup,
+		},
+		{
+			name:          "Master advertises another interval",
+			preempt:       true,
+			adv:           adv(200, 200),
+			expectedTimer: 6*time.Second + 2*time.Second*156/256,
+			expectedState: backup,
+		},
+		{
+			name:          "Master shuts down",
+			preempt:       true,
+			adv:           adv(packet.PriorityStop, 100),
+			expectedTimer: time.Second * 156 / 256,
+			expectedState: backup,
+		},
+		{
+			name:          "Lower priority master is preempted",
+			preempt:       true,
+			adv:           adv(50, 200),
+			expectedTimer: time.Hour,
+			expectedState: backup,
+		},
+		{
+			name:          "Lower priority master without preemption",
+			preempt:       false,
+			adv:           adv(50, 100),
+			expectedTimer: 3*time.Second + time.Second*156/256,
+			expectedState: backup,
+		},
+		{
+			name:    "Other VRID is ignored",
+			preempt: true,
+			adv: &packet.Advertisement{
+				Version:  packet.Version3,
+				VRID:     2,
+				Priority: 200,
+				Interval: 100,
+			},
+			expectedTimer: time.Hour,
+			expectedState: backup,
+		},
+	}
+
+	for _, test := range tests {
+		i, _ := newTestInstance(100, test.preempt)
+		i.startup()
+		i.timerDuration = time.Hour
+
+		i.processAdvertisement(test.adv, bnet.IPv4FromOctets(192, 168, 0, 3))
+		assert.Equalf(t, test.expectedState, i.state, "Test %q", test.name)
+		assert.Equalf(t, test.expectedTimer, i.timerDuration, "Test %q", test.name)
+	}
+}
+
+func TestBackupTakeover(t *testing.T) {
+	i, m := newTestInstance(100, true)
+	i.startup()
+
+	i.timerExpired()
+	assert.Equal(t, master, i.state)
+	assert.Equal(t, time.Second, i.timerDuration)
+	assert.Equal(t, adv(100, 100), sentAdvertisement(t, m, 0))
+	assert.Contains(t, m.addresses, testVIP)
+	assert.Equal(t, testPrimary, i.masterAddress)
+
+	i.timerExpired()
+	assert.Equal(t, master, i.state)
+	assert.Equal(t, 2, len(m.sent))
+}
+
+func TestMaster(t *testing.T) {
+	tests := []struct {
+		name          string
+		adv           *packet.Advertisement
+		src           bnet.IP
+		expectedState state
+		expectedSent  int
+	}{
+		{
+			name:          "Higher priority",
+			adv:           adv(200, 100),
+			src:           bnet.IPv4FromOctets(192, 168, 0, 3),
+			expectedState: backup,
+			expectedSent:  1,
+		},
+		{
+			name:          "Same priority and higher address",
+			adv:           adv(100, 100),
+			src:           bnet.IPv4FromOctets(192, 168, 0, 3),
+			expectedState: backup,
+			expectedSent:  1,
+		},
+		{
+			name:          "Same priority and lower address",
+			adv:           adv(100, 100),
+			src:           bnet.IPv4FromOctets(192, 168, 0, 1),
+			expectedState: master,
+			expectedSent:  1,
+		},
+		{
+			name:          "Lower priority",
+			adv:           adv(50, 100),
+			src:           bnet.IPv4FromOctets(192, 168, 0, 3),
+			expectedState: master,
+			expectedSent:  1,
+		},
+		{
+			name:          "Other master shutting down",
+			adv:           adv(packet.PriorityStop, 100),
+			src:           bnet.IPv4FromOctets(192, 168, 0, 3),
+			expectedState: master,
+			expectedSent:  2,
+		},
+	}
+
+	for _, test := range tests {
+		i, m := newTestInstance(100, true)
+		i.becomeMaster("test")
+
+		i.processAdvertisement(test.adv, test.src)
+		assert.Equalf(t, test.expectedState, i.state, "Test %q", test.name)
+		assert.Equalf(t, test.expectedSent, len(m.sent), "Test %q", test.name)
+
+		if test.expectedState == backup {
+			assert.Equalf(t, 0, len(m.addresses), "Test %q", test.name)
+			assert.Equalf(t, test.src, i.masterAddress, "Test %q", test.name)
+		} else {
+			assert.Containsf(t, m.addresses, testVIP, "Test %q", test.name)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	i, m := newTestInstance(100, true)
+	i.becomeMaster("test")
+	i.shutdown()
+
+	assert.Equal(t, initialize, i.state)
+	assert.Equal(t, adv(packet.PriorityStop, 100), sentAdvertisement(t, m, 1))
+	assert.Equal(t, 0, len(m.addresses))
+}
+
+func TestVersion2IntervalMismatch(t *testing.T) {
+	i, _ := newTestInstance(100, true)
+	i.cfg.Version = packet.Version2
+	i.startup()
+	i.timerDuration = time.Hour
+
+	a := adv(200, 200)
+	a.Version = packet.Version2
+	i.processAdvertisement(a, bnet.IPv4FromOctets(192, 168, 0, 3))
+	assert.Equal(t, time.Hour, i.timerDuration)
+
+	a.Interval = 100
+	i.processAdvertisement(a, bnet.IPv4FromOctets(192, 168, 0, 3))
+	assert.Equal(t, 3*time.Second+time.Second*156/256, i.timerDuration)
+}
+
+func TestInstanceEvents(t *testing.T) {
+	i, m := newTestInstance(100, true)
+	assert.NoError(t, i.start())
+	assert.Equal(t, backup, i.state)
+
+	// A higher priority master keeps us in backup state
+	src := bnet.IPv4FromOctets(192, 168, 0, 3)
+	buf := bytes.NewBuffer(nil)
+	adv(200, 100).Serialize(buf, src, allVRRPRoutersIPv4)
+	m.rx <- mockPacket{
+		pkt: buf.Bytes(),
+		src: src,
+		dst: allVRRPRoutersIPv4,
+	}
+
+	// Wait for the event loop to process the advertisement
+	for j := 0; j < 100; j++ {
+		i.mu.Lock()
+		learned := i.masterAddress == src
+		i.mu.Unlock()
+		if learned {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	i.mu.Lock()
+	assert.Equal(t, src, i.masterAddress)
+	assert.Equal(t, backup, i.state)
+	i.mu.Unlock()
+
+	i.stopInstance()
+	assert.Equal(t, initialize, i.state)
+}
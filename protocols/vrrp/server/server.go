@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/vrrp/packet"
+	"github.com/bio-routing/bio-rd/util/flightrecorder"
+	"github.com/pkg/errors"
+)
+
+// Server represents a VRRP server
+type Server struct {
+	instances      []*instance
+	flightRecorder *flightrecorder.Registry
+	mu             sync.Mutex
+	running        bool
+}
+
+// InstanceStatus is the state of a virtual router
+type InstanceStatus struct {
+	Interface      string
+	VRID           uint8
+	State          string
+	Priority       uint8
+	PrimaryAddress bnet.IP
+	MasterAddress  bnet.IP
+}
+
+// New creates a new VRRP server
+func New(cfg *config.VRRPConfig) (*Server, error) {
+	s := &Server{}
+
+	for _, icfg := range cfg.Instances {
+		err := validateInstanceConfig(&icfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid config of VRID %d on %q", icfg.VRID, icfg.Interface)
+		}
+
+		ipv6 := !icfg.VirtualAddresses[0].Addr().IsIPv4()
+		s.instances = append(s.instances, newInstance(s, icfg, newBIOSys(icfg.Interface, ipv6)))
+	}
+
+	return s, nil
+}
+
+// validateInstanceConfig validates the config of a virtual router and fills in defaults
+func validateInstanceConfig(cfg *config.VRRPInstanceConfig) error {
+	if cfg.VRID == 0 {
+		return fmt.Errorf("VRID must be in range 1-255")
+	}
+
+	if cfg.Version == 0 {
+		cfg.Version = config.DefaultVRRPVersion
+	}
+
+	if cfg.Version != packet.Version2 && cfg.Version != packet.Version3 {
+		return fmt.Errorf("Unsupported version %d", cfg.Version)
+	}
+
+	if cfg.Priority == 0 {
+		cfg.Priority = config.DefaultVRRPPriority
+	}
+
+	if cfg.AdvertisementInterval == 0 {
+		cfg.AdvertisementInterval = config.DefaultVRRPAdvertisementInterval
+	}
+
+	if len(cfg.VirtualAddresses) == 0 {
+		return fmt.Errorf("No virtual addresses")
+	}
+
+	ipv4 := cfg.VirtualAddresses[0].Addr().IsIPv4()
+	for _, pfx := range cfg.VirtualAddresses {
+		if pfx.Addr().IsIPv4() != ipv4 {
+			return fmt.Errorf("Virtual addresses must be of the same address family")
+		}
+	}
+
+	if cfg.Version == packet.Version2 {
+		if !ipv4 {
+			return fmt.Errorf("Version 2 only supports IPv4")
+		}
+
+		if cfg.AdvertisementInterval%100 != 0 || cfg.AdvertisementInterval > 25500 {
+			return fmt.Errorf("Version 2 advertisement interval must be whole seconds up to 255")
+		}
+	} else if cfg.AdvertisementInterval > 4095 {
+		return fmt.Errorf("Advertisement interval must not exceed 4095 centiseconds")
+	}
+
+	return nil
+}
+
+// SetFlightRecorder sets the flight recorder virtual router state transitions are recorded to
+func (s *Server) SetFlightRecorder(r *flightrecorder.Registry) {
+	s.flightRecorder = r
+}
+
+// Start starts all virtual routers
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for j, i := range s.instances {
+		err := i.start()
+		if err != nil {
+			for _, started := range s.instances[:j] {
+				started.stopInstance()
+			}
+
+			return errors.Wrapf(err, "Unable to start %s", i.name())
+		}
+	}
+
+	s.running = true
+	return nil
+}
+
+// Stop stops all virtual routers. Virtual routers in master state hand over to their backups.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	for _, i := range s.instances {
+		i.stopInstance()
+	}
+
+	s.running = false
+}
+
+// Status gets the state of all virtual routers
+func (s *Server) Status() []InstanceStatus {
+	ret := make([]InstanceStatus, 0, len(s.instances))
+	for _, i := range s.instances {
+		i.mu.Lock()
+		ret = append(ret, InstanceStatus{
+			Interface:      i.cfg.Interface,
+			VRID:           i.cfg.VRID,
+			State:          stateName(i.state),
+			Priority:       i.cfg.Priority,
+			PrimaryAddress: i.primaryAddress,
+			MasterAddress:  i.masterAddress,
+		})
+		i.mu.Unlock()
+	}
+
+	return ret
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/config"
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateInstanceConfig(t *testing.T) {
+	v4 := bnet.NewPfx(bnet.IPv4FromOctets(192, 168, 0, 1), 24)
+	v6 := bnet.NewPfx(bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1), 64)
+
+	tests := []struct {
+		name     string
+		cfg      config.VRRPInstanceConfig
+		wantFail bool
+		expected config.VRRPInstanceConfig
+	}{
+		{
+			name: "Defaults",
+			cfg: config.VRRPInstanceConfig{
+				VRID:             1,
+				VirtualAddresses: []bnet.Prefix{v4},
+			},
+			expected: config.VRRPInstanceConfig{
+				VRID:                  1,
+				Version:               3,
+				Priority:              100,
+				AdvertisementInterval: 100,
+				VirtualAddresses:      []bnet.Prefix{v4},
+			},
+		},
+		{
+			name: "VRID 0",
+			cfg: config.VRRPInstanceConfig{
+				VirtualAddresses: []bnet.Prefix{v4},
+			},
+			wantFail: true,
+		},
+		{
+			name: "No addresses",
+			cfg: config.VRRPInstanceConfig{
+				VRID: 1,
+			},
+			wantFail: true,
+		},
+		{
+			name: "Mixed address families",
+			cfg: config.VRRPInstanceConfig{
+				VRID:             1,
+				VirtualAddresses: []bnet.Prefix{v4, v6},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Version 2 with IPv6",
+			cfg: config.VRRPInstanceConfig{
+				VRID:             1,
+				Version:          2,
+				VirtualAddresses: []bnet.Prefix{v6},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Version 2 with sub second interval",
+			cfg: config.VRRPInstanceConfig{
+				VRID:                  1,
+				Version:               2,
+				AdvertisementInterval: 50,
+				VirtualAddresses:      []bnet.Prefix{v4},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Interval too long",
+			cfg: config.VRRPInstanceConfig{
+				VRID:                  1,
+				AdvertisementInterval: 5000,
+				VirtualAddresses:      []bnet.Prefix{v4},
+			},
+			wantFail: true,
+		},
+		{
+			name: "Unsupported version",
+			cfg: config.VRRPInstanceConfig{
+				VRID:             1,
+				Version:          4,
+				VirtualAddresses: []bnet.Prefix{v4},
+			},
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateInstanceConfig(&test.cfg)
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.expected, test.cfg, "Test %q", test.name)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	i, _ := newTestInstance(100, true)
+	s := &Server{
+		instances: []*instance{i},
+	}
+	i.srv = s
+	i.becomeMaster("test")
+
+	assert.Equal(t, []InstanceStatus{
+		{
+			Interface:      "eth0",
+			VRID:           1,
+			State:          "master",
+			Priority:       100,
+			PrimaryAddress: testPrimary,
+			MasterAddress:  testPrimary,
+		},
+	}, s.Status())
+}
+
+func TestStartFail(t *testing.T) {
+	i1, m1 := newTestInstance(100, true)
+	i2, m2 := newTestInstance(100, true)
+	m2.wantFailOpen = true
+
+	s := &Server{
+		instances: []*instance{i1, i2},
+	}
+
+	assert.Error(t, s.Start())
+	assert.True(t, m1.closeRequested)
+	assert.False(t, m2.opened)
+}
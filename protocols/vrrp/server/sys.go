@@ -0,0 +1,156 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+var (
+	allVRRPRoutersIPv4 = bnet.IPv4FromOctets(224, 0, 0, 18)
+	allVRRPRoutersIPv6 = bnet.IPv6FromBlocks(0xff02, 0, 0, 0, 0, 0, 0, 0x12)
+	allNodesIPv6       = bnet.IPv6FromBlocks(0xff02, 0, 0, 0, 0, 0, 0, 1)
+)
+
+// sys abstracts the operating system facilities a virtual router needs
+type sys interface {
+	open() error
+	close() error
+	primaryAddress(exclude []bnet.Prefix) (bnet.IP, error)
+	send(pkt []byte, src bnet.IP, dst bnet.IP) error
+	recv() (pkt []byte, src bnet.IP, dst bnet.IP, ttl int, err error)
+	addAddress(pfx bnet.Prefix) error
+	delAddress(pfx bnet.Prefix) error
+	announce(addr bnet.IP) error
+}
+
+// gratuitousARP builds a gratuitous ARP request (RFC 5227) announcing addr at mac
+func gratuitousARP(mac net.HardwareAddr, addr bnet.IP) []byte {
+	pkt := []byte{
+		0, 1, // Ethernet
+		8, 0, // IPv4
+		6, 4, // Address lengths
+		0, 1, // Request
+	}
+
+	pkt = append(pkt, mac[:6]...)
+	pkt = append(pkt, addr.Bytes()...)
+	pkt = append(pkt, make([]byte, 6)...)
+	pkt = append(pkt, addr.Bytes()...)
+	return pkt
+}
+
+// unsolicitedNA builds an unsolicited neighbor advertisement (RFC 4861 7.2.6) for addr at mac.
+// The checksum is left to the kernel.
+func unsolicitedNA(mac net.HardwareAddr, addr bnet.IP) []byte {
+	pkt := []byte{
+		136, 0, // Neighbor advertisement
+		0, 0, // Checksum
+		0x20, 0, 0, 0, // Override flag
+	}
+
+	pkt = append(pkt, addr.Bytes()...)
+	pkt = append(pkt, 2, 1) // Target link-layer address option
+	pkt = append(pkt, mac[:6]...)
+	return pkt
+}
+
+type mockPacket struct {
+	pkt []byte
+	src bnet.IP
+	dst bnet.IP
+}
+
+type mockSys struct {
+	mu             sync.Mutex
+	wantFailOpen   bool
+	opened         bool
+	primary        bnet.IP
+	sent           []mockPacket
+	addresses      map[bnet.Prefix]struct{}
+	announced      []bnet.IP
+	rx             chan mockPacket
+	closed         chan struct{}
+	closeRequested bool
+}
+
+func newMockSys(primary bnet.IP) *mockSys {
+	return &mockSys{
+		primary:   primary,
+		addresses: make(map[bnet.Prefix]struct{}),
+		rx:        make(chan mockPacket),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (m *mockSys) open() error {
+	if m.wantFailOpen {
+		return fmt.Errorf("Fail")
+	}
+
+	m.opened = true
+	return nil
+}
+
+func (m *mockSys) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.closeRequested {
+		m.closeRequested = true
+		close(m.closed)
+	}
+
+	return nil
+}
+
+func (m *mockSys) primaryAddress(exclude []bnet.Prefix) (bnet.IP, error) {
+	return m.primary, nil
+}
+
+func (m *mockSys) send(pkt []byte, src bnet.IP, dst bnet.IP) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, mockPacket{
+		pkt: pkt,
+		src: src,
+		dst: dst,
+	})
+	return nil
+}
+
+func (m *mockSys) recv() ([]byte, bnet.IP, bnet.IP, int, error) {
+	select {
+	case p := <-m.rx:
+		return p.pkt, p.src, p.dst, 255, nil
+	case <-m.closed:
+		return nil, bnet.IP{}, bnet.IP{}, 0, fmt.Errorf("closed")
+	}
+}
+
+func (m *mockSys) addAddress(pfx bnet.Prefix) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addresses[pfx] = struct{}{}
+	return nil
+}
+
+func (m *mockSys) delAddress(pfx bnet.Prefix) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.addresses, pfx)
+	return nil
+}
+
+func (m *mockSys) announce(addr bnet.IP) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.announced = append(m.announced, addr)
+	return nil
+}
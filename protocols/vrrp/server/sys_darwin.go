@@ -0,0 +1,45 @@
+package server
+
+import (
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+type bioSys struct{}
+
+func newBIOSys(ifName string, ipv6 bool) *bioSys {
+	return &bioSys{}
+}
+
+func (b *bioSys) open() error {
+	return fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) close() error {
+	return fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) primaryAddress(exclude []bnet.Prefix) (bnet.IP, error) {
+	return bnet.IP{}, fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) send(pkt []byte, src bnet.IP, dst bnet.IP) error {
+	return fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) recv() ([]byte, bnet.IP, bnet.IP, int, error) {
+	return nil, bnet.IP{}, bnet.IP{}, 0, fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) addAddress(pfx bnet.Prefix) error {
+	return fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) delAddress(pfx bnet.Prefix) error {
+	return fmt.Errorf("Unsupported platform")
+}
+
+func (b *bioSys) announce(addr bnet.IP) error {
+	return fmt.Errorf("Unsupported platform")
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/vrrp/packet"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+type bioSys struct {
+	ifName string
+	ipv6   bool
+	ifi    *net.Interface
+	link   netlink.Link
+	conn4  *ipv4.PacketConn
+	conn6  *ipv6.PacketConn
+}
+
+func newBIOSys(ifName string, ipv6 bool) *bioSys {
+	return &bioSys{
+		ifName: ifName,
+		ipv6:   ipv6,
+	}
+}
+
+func (b *bioSys) open() error {
+	ifi, err := net.InterfaceByName(b.ifName)
+	if err != nil {
+		return errors.Wrap(err, "Unable to get interface")
+	}
+	b.ifi = ifi
+
+	b.link, err = netlink.LinkByName(b.ifName)
+	if err != nil {
+		return errors.Wrap(err, "Unable to get link")
+	}
+
+	if b.ipv6 {
+		return b.open6()
+	}
+
+	return b.open4()
+}
+
+func (b *bioSys) open4() error {
+	c, err := net.ListenPacket(fmt.Sprintf("ip4:%d", packet.Protocol), "0.0.0.0")
+	if err != nil {
+		return errors.Wrap(err, "Unable to open socket")
+	}
+
+	pc := ipv4.NewPacketConn(c)
+	for _, f := range []func() error{
+		func() error { return pc.JoinGroup(b.ifi, &net.IPAddr{IP: allVRRPRoutersIPv4.ToNetIP()}) },
+		func() error { return pc.SetControlMessage(ipv4.FlagTTL|ipv4.FlagDst|ipv4.FlagInterface, true) },
+		func() error { return pc.SetMulticastInterface(b.ifi) },
+		func() error { return pc.SetMulticastTTL(packet.TTL) },
+		func() error { return pc.SetMulticastLoopback(false) },
+	} {
+		if err := f(); err != nil {
+			pc.Close()
+			return errors.Wrap(err, "Unable to set socket option")
+		}
+	}
+
+	b.conn4 = pc
+	return nil
+}
+
+func (b *bioSys) open6() error {
+	c, err := net.ListenPacket(fmt.Sprintf("ip6:%d", packet.Protocol), "::")
+	if err != nil {
+		return errors.Wrap(err, "Unable to open socket")
+	}
+
+	pc := ipv6.NewPacketConn(c)
+	for _, f := range []func() error{
+		func() error { return pc.JoinGroup(b.ifi, &net.IPAddr{IP: allVRRPRoutersIPv6.ToNetIP()}) },
+		func() error {
+			return pc.SetControlMessage(ipv6.FlagHopLimit|ipv6.FlagDst|ipv6.FlagInterface, true)
+		},
+		func() error { return pc.SetMulticastInterface(b.ifi) },
+		func() error { return pc.SetMulticastHopLimit(packet.TTL) },
+		func() error { return pc.SetMulticastLoopback(false) },
+	} {
+		if err := f(); err != nil {
+			pc.Close()
+			return errors.Wrap(err, "Unable to set socket option")
+		}
+	}
+
+	b.conn6 = pc
+	return nil
+}
+
+func (b *bioSys) close() error {
+	if b.conn6 != nil {
+		return b.conn6.Close()
+	}
+
+	if b.conn4 != nil {
+		return b.conn4.Close()
+	}
+
+	return nil
+}
+
+// primaryAddress gets the address advertisements are sent from. IPv6 advertisements have to be sent from a link local address.
+func (b *bioSys) primaryAddress(exclude []bnet.Prefix) (bnet.IP, error) {
+	family := netlink.FAMILY_V4
+	if b.ipv6 {
+		family = netlink.FAMILY_V6
+	}
+
+	addrs, err := netlink.AddrList(b.link, family)
+	if err != nil {
+		return bnet.IP{}, errors.Wrap(err, "Unable to get addresses")
+	}
+
+	for _, a := range addrs {
+		if b.ipv6 && !a.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		ip, err := bnet.IPFromBytes(ipBytes(a.IP, b.ipv6))
+		if err != nil {
+			continue
+		}
+
+		if !excluded(ip, exclude) {
+			return ip, nil
+		}
+	}
+
+	return bnet.IP{}, fmt.Errorf("No usable address found on %q", b.ifName)
+}
+
+func excluded(ip bnet.IP, exclude []bnet.Prefix) bool {
+	for _, pfx := range exclude {
+		if *pfx.Addr() == ip {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ipBytes(ip net.IP, ipv6 bool) []byte {
+	if ipv6 {
+		return ip.To16()
+	}
+
+	return ip.To4()
+}
+
+func (b *bioSys) send(pkt []byte, src bnet.IP, dst bnet.IP) error {
+	if b.ipv6 {
+		cm := &ipv6.ControlMessage{
+			Src:      src.ToNetIP(),
+			IfIndex:  b.ifi.Index,
+			HopLimit: packet.TTL,
+		}
+
+		_, err := b.conn6.WriteTo(pkt, cm, &net.IPAddr{IP: dst.ToNetIP()})
+		return err
+	}
+
+	cm := &ipv4.ControlMessage{
+		Src:     src.ToNetIP(),
+		IfIndex: b.ifi.Index,
+	}
+
+	_, err := b.conn4.WriteTo(pkt, cm, &net.IPAddr{IP: dst.ToNetIP()})
+	return err
+}
+
+func (b *bioSys) recv() ([]byte, bnet.IP, bnet.IP, int, error) {
+	buf := make([]byte, 1500)
+	for {
+		var n, ifIndex, ttl int
+		var dstIP net.IP
+		var src net.Addr
+		var err error
+
+		if b.ipv6 {
+			var cm *ipv6.ControlMessage
+			n, cm, src, err = b.conn6.ReadFrom(buf)
+			if cm != nil {
+				ifIndex, ttl, dstIP = cm.IfIndex, cm.HopLimit, cm.Dst
+			}
+		} else {
+			var cm *ipv4.ControlMessage
+			n, cm, src, err = b.conn4.ReadFrom(buf)
+			if cm != nil {
+				ifIndex, ttl, dstIP = cm.IfIndex, cm.TTL, cm.Dst
+			}
+		}
+
+		if err != nil {
+			return nil, bnet.IP{}, bnet.IP{}, 0, err
+		}
+
+		ipAddr, ok := src.(*net.IPAddr)
+		if !ok || ifIndex != b.ifi.Index {
+			continue
+		}
+
+		srcIP, err := bnet.IPFromBytes(ipBytes(ipAddr.IP, b.ipv6))
+		if err != nil {
+			continue
+		}
+
+		dst, err := bnet.IPFromBytes(ipBytes(dstIP, b.ipv6))
+		if err != nil {
+			continue
+		}
+
+		return buf[:n], srcIP, dst, ttl, nil
+	}
+}
+
+func (b *bioSys) addAddress(pfx bnet.Prefix) error {
+	err := netlink.AddrAdd(b.link, &netlink.Addr{IPNet: pfx.GetIPNet()})
+	if err == syscall.EEXIST {
+		return nil
+	}
+
+	return err
+}
+
+func (b *bioSys) delAddress(pfx bnet.Prefix) error {
+	err := netlink.AddrDel(b.link, &netlink.Addr{IPNet: pfx.GetIPNet()})
+	if err == syscall.EADDRNOTAVAIL {
+		return nil
+	}
+
+	return err
+}
+
+// announce makes neighbors update their caches for addr using gratuitous ARP or an unsolicited neighbor advertisement
+func (b *bioSys) announce(addr bnet.IP) error {
+	if len(b.ifi.HardwareAddr) != 6 {
+		// Not an Ethernet like interface
+		return nil
+	}
+
+	if b.ipv6 {
+		c, err := net.ListenPacket("ip6:ipv6-icmp", "::")
+		if err != nil {
+			return errors.Wrap(err, "Unable to open ICMPv6 socket")
+		}
+		defer c.Close()
+
+		pc := ipv6.NewPacketConn(c)
+		cm := &ipv6.ControlMessage{
+			Src:      addr.ToNetIP(),
+			IfIndex:  b.ifi.Index,
+			HopLimit: packet.TTL,
+		}
+
+		_, err = pc.WriteTo(unsolicitedNA(b.ifi.HardwareAddr, addr), cm, &net.IPAddr{IP: allNodesIPv6.ToNetIP()})
+		return err
+	}
+
+	s, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_DGRAM, int(htons(syscall.ETH_P_ARP)))
+	if err != nil {
+		return errors.Wrap(err, "Unable to open packet socket")
+	}
+	defer syscall.Close(s)
+
+	ll := &syscall.SockaddrLinklayer{
+		Protocol: htons(syscall.ETH_P_ARP),
+		Ifindex:  b.ifi.Index,
+		Halen:    6,
+		Addr:     [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+
+	return syscall.Sendto(s, gratuitousARP(b.ifi.HardwareAddr, addr), 0, ll)
+}
+
+func htons(x uint16) uint16 {
+	return x<<8 | x>>8
+}
@@ -7,5 +7,7 @@ protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/net/api/*.proto
 protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/route/api/*.proto
 protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/protocols/bgp/api/*.proto
 protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/cmd/ris/api/*.proto
+protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/cmd/bio-rd/api/*.proto
+protoc --go_out=plugins=grpc:. github.com/bio-routing/bio-rd/util/telemetry/api/*.proto
 echo "Switching back to working directory"
 cd $dir
@@ -0,0 +1,148 @@
+package route
+
+import "sort"
+
+// BestPathOptions tune the BGP decision process, e.g. to match the behavior of other implementations during migrations.
+// A nil *BestPathOptions selects the default behavior of RFC4271.
+type BestPathOptions struct {
+	// AlwaysCompareMED compares the MED of paths received from different neighboring ASes
+	AlwaysCompareMED bool
+
+	// DeterministicMED selects the best path of each neighboring AS first and then the best of those, making the result
+	// independent of the order the paths have been received in
+	DeterministicMED bool
+
+	// IgnoreASPathLength skips the AS path length comparison
+	IgnoreASPathLength bool
+
+	// IgnoreRouterID skips the router ID (or originator ID) comparison when breaking ties
+	IgnoreRouterID bool
+
+	// PreferOldest keeps the current best path if a new external path is only preferred by breaking ties (RFC5004)
+	PreferOldest bool
+
+	// IGPCosts prefers paths with the lowest interior cost to their next hop. Paths are not compared by interior cost if nil.
+	IGPCosts IGPCosts
+}
+
+// Equal checks if o and x select the same paths
+func (o *BestPathOptions) Equal(x *BestPathOptions) bool {
+	if o == nil || x == nil {
+		return o == x
+	}
+
+	return *o == *x
+}
+
+func (o *BestPathOptions) alwaysCompareMED() bool {
+	return o != nil && o.AlwaysCompareMED
+}
+
+func (o *BestPathOptions) deterministicMED() bool {
+	return o != nil && o.DeterministicMED && !o.AlwaysCompareMED
+}
+
+func (o *BestPathOptions) ignoreASPathLength() bool {
+	return o != nil && o.IgnoreASPathLength
+}
+
+func (o *BestPathOptions) ignoreRouterID() bool {
+	return o != nil && o.IgnoreRouterID
+}
+
+func (o *BestPathOptions) preferOldest() bool {
+	return o != nil && o.PreferOldest
+}
+
+func (o *BestPathOptions) igpCosts() IGPCosts {
+	if o == nil {
+		return nil
+	}
+
+	return o.IGPCosts
+}
+
+// sortPaths sorts paths by preference, the best path first
+func sortPaths(paths []*Path, o *BestPathOptions) {
+	less := func(p, q *Path) bool {
+		return p.SelectWithOptions(q, o) == -1
+	}
+
+	if !o.deterministicMED() {
+		sort.Slice(paths, func(i, j int) bool {
+			return less(paths[i], paths[j])
+		})
+		return
+	}
+
+	// Paths are grouped by neighboring AS. Non BGP paths are kept in a group of their own.
+	groups := make([][]*Path, 0)
+	groupIdx := make(map[uint32]int)
+	var others []*Path
+	for _, p := range paths {
+		if p.Type != BGPPathType {
+			others = append(others, p)
+			continue
+		}
+
+		asn := p.BGPPath.neighborAS()
+		i, found := groupIdx[asn]
+		if !found {
+			i = len(groups)
+			groupIdx[asn] = i
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], p)
+	}
+
+	if len(others) > 0 {
+		groups = append(groups, others)
+	}
+
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool {
+			return less(g[i], g[j])
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return less(groups[i][0], groups[j][0])
+	})
+
+	n := 0
+	for _, g := range groups {
+		n += copy(paths[n:], g)
+	}
+}
+
+// keepOldest moves the previous best path prev back in front of paths if the new best path is only preferred by
+// breaking ties and both are external paths
+func keepOldest(paths []*Path, prev *Path, o *BestPathOptions) {
+	if !o.preferOldest() || prev == nil || len(paths) < 2 || paths[0] == prev {
+		return
+	}
+
+	best := paths[0]
+	if best.Type != BGPPathType || prev.Type != BGPPathType {
+		return
+	}
+
+	if !best.BGPPath.BGPPathA.EBGP || !prev.BGPPath.BGPPathA.EBGP {
+		return
+	}
+
+	if best.BGPPath.selectByAttributes(prev.BGPPath, o) != 0 {
+		return
+	}
+
+	for i := range paths {
+		if paths[i] != prev {
+			continue
+		}
+
+		copy(paths[1:i+1], paths[:i])
+		paths[0] = prev
+		return
+	}
+}
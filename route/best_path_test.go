@@ -0,0 +1,208 @@
+package route
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func bestPathTestPath(routerID uint32, neighborAS uint32, localPref uint32, med uint32, asPathLen uint16) *Path {
+	asns := []uint32{neighborAS}
+	for i := uint16(1); i < asPathLen; i++ {
+		asns = append(asns, 65100)
+	}
+
+	return &Path{
+		Type: BGPPathType,
+		BGPPath: &BGPPath{
+			BGPPathA: &BGPPathA{
+				EBGP:          true,
+				LocalPref:     localPref,
+				MED:           med,
+				BGPIdentifier: routerID,
+				NextHop:       bnet.IPv4(routerID).Ptr(),
+				Source:        bnet.IPv4(routerID).Ptr(),
+			},
+			ASPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: asns,
+				},
+			},
+			ASPathLen: asPathLen,
+		},
+	}
+}
+
+func TestPathSelectionWithOptions(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          *BestPathOptions
+		paths            []*Path
+		expectedRouterID uint32
+	}{
+		{
+			name: "Highest local pref",
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 0, 1),
+				bestPathTestPath(2, 65001, 200, 0, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Shortest AS path",
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 0, 3),
+				bestPathTestPath(2, 65001, 100, 0, 2),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "AS path length ignored",
+			options: &BestPathOptions{
+				IgnoreASPathLength: true,
+			},
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 0, 3),
+				bestPathTestPath(2, 65001, 100, 0, 2),
+			},
+			expectedRouterID: 1,
+		},
+		{
+			name: "Lowest MED of same neighboring AS",
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 20, 1),
+				bestPathTestPath(2, 65001, 100, 10, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "MED of different neighboring ASes not compared",
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 20, 1),
+				bestPathTestPath(2, 65002, 100, 10, 1),
+			},
+			expectedRouterID: 1,
+		},
+		{
+			name: "Always compare MED",
+			options: &BestPathOptions{
+				AlwaysCompareMED: true,
+			},
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 20, 1),
+				bestPathTestPath(2, 65002, 100, 10, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Router ID ignored",
+			options: &BestPathOptions{
+				IgnoreRouterID: true,
+			},
+			paths: []*Path{
+				func() *Path {
+					p := bestPathTestPath(1, 65001, 100, 0, 1)
+					p.BGPPath.BGPPathA.Source = bnet.IPv4(3).Ptr()
+					return p
+				}(),
+				bestPathTestPath(2, 65001, 100, 0, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Deterministic MED",
+			options: &BestPathOptions{
+				DeterministicMED: true,
+			},
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 20, 1),
+				bestPathTestPath(2, 65002, 100, 0, 1),
+				bestPathTestPath(3, 65001, 100, 10, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Prefer oldest external path",
+			options: &BestPathOptions{
+				PreferOldest: true,
+			},
+			paths: []*Path{
+				bestPathTestPath(2, 65002, 100, 0, 1),
+				bestPathTestPath(1, 65001, 100, 0, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Prefer oldest external path unless the new path has better attributes",
+			options: &BestPathOptions{
+				PreferOldest: true,
+			},
+			paths: []*Path{
+				bestPathTestPath(2, 65002, 100, 0, 2),
+				bestPathTestPath(1, 65001, 100, 0, 1),
+			},
+			expectedRouterID: 1,
+		},
+		{
+			name: "Lowest IGP cost",
+			options: &BestPathOptions{
+				IGPCosts: NewIGPCostTable(map[bnet.IP]uint32{
+					bnet.IPv4(1): 20,
+					bnet.IPv4(2): 10,
+				}),
+			},
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 0, 1),
+				bestPathTestPath(2, 65001, 100, 0, 1),
+			},
+			expectedRouterID: 2,
+		},
+		{
+			name: "Unreachable next hop",
+			options: &BestPathOptions{
+				IGPCosts: NewIGPCostTable(map[bnet.IP]uint32{
+					bnet.IPv4(2): 10,
+				}),
+			},
+			paths: []*Path{
+				bestPathTestPath(1, 65001, 100, 0, 1),
+				bestPathTestPath(2, 65001, 100, 0, 1),
+			},
+			expectedRouterID: 2,
+		},
+	}
+
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	for _, test := range tests {
+		// The first path is the current best path
+		r := NewRoute(&pfx, test.paths[0])
+		r.PathSelectionWithOptions(test.options)
+		for _, p := range test.paths[1:] {
+			r.AddPath(p)
+			r.PathSelectionWithOptions(test.options)
+		}
+
+		assert.Equal(t, test.expectedRouterID, r.BestPath().BGPPath.BGPPathA.BGPIdentifier, "Test %q", test.name)
+	}
+}
+
+func TestBestPathWithOptions(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	r := NewRoute(&pfx, bestPathTestPath(1, 65001, 100, 0, 1))
+	r.AddPath(bestPathTestPath(2, 65001, 100, 0, 1))
+	r.PathSelection()
+
+	o := &BestPathOptions{
+		IGPCosts: NewIGPCostTable(map[bnet.IP]uint32{
+			bnet.IPv4(1): 20,
+			bnet.IPv4(2): 10,
+		}),
+	}
+
+	assert.Equal(t, uint32(2), r.BestPathWithOptions(o).BGPPath.BGPPathA.BGPIdentifier)
+	assert.Equal(t, uint32(1), r.BestPath().BGPPath.BGPPathA.BGPIdentifier)
+	assert.Nil(t, (&Route{}).BestPathWithOptions(o))
+}
@@ -5,25 +5,91 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/bio-routing/tflow2/convert"
+	"github.com/bio-routing/bio-rd/util/endian"
 
 	bnet "github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/route/api"
 )
 
 // BGPPath represents a set of BGP path attributes
 type BGPPath struct {
-	BGPPathA          *BGPPathA
-	ASPath            *types.ASPath
-	ClusterList       *types.ClusterList
-	Communities       *types.Communities
-	LargeCommunities  *types.LargeCommunities
-	UnknownAttributes []types.UnknownPathAttribute
-	PathIdentifier    uint32
-	ASPathLen         uint16
+	BGPPathA            *BGPPathA
+	ASPath              *types.ASPath
+	ClusterList         *types.ClusterList
+	Communities         *types.Communities
+	LargeCommunities    *types.LargeCommunities
+	ExtendedCommunities *types.ExtendedCommunities
+	UnknownAttributes   []types.UnknownPathAttribute
+	PathIdentifier      uint32
+	ASPathLen           uint16
+
+	// Labels is the MPLS label stack of a labeled route (RFC8277)
+	Labels []uint32
+
+	// RouteDistinguisher is the route distinguisher of a VPN route (RFC4364), 0 for all other routes
+	RouteDistinguisher uint64
+
+	// ValidationState is the RPKI origin validation state of the path (RFC6811). It is local and never sent to peers.
+	ValidationState vrp.ValidationState
+
+	// ClientClusterID is the cluster ID of the route reflector client the path was received from, 0 for all other paths.
+	// It is local and never sent to peers.
+	ClientClusterID uint32
+
+	// Multipath is the multipath mode of the peer the path was received from. It is local and never sent to peers.
+	Multipath MultipathMode
+
+	// Local is set for paths originated by the router itself, e.g. aggregates. It is local and never sent to peers.
+	Local bool
 }
 
+// MultipathMode determines which equal cost BGP paths are used together (multipath)
+type MultipathMode uint8
+
+const (
+	// MultipathDisabled only uses the best path
+	MultipathDisabled MultipathMode = iota
+
+	// MultipathSameAS uses equal cost paths received from the same neighboring AS
+	MultipathSameAS
+
+	// MultipathMultipleAS uses equal cost paths regardless of the neighboring AS
+	MultipathMultipleAS
+)
+
+// PrivateASMode determines how private AS numbers are scrubbed from AS paths sent to eBGP peers
+type PrivateASMode uint8
+
+const (
+	// PrivateASKeep sends AS paths unchanged
+	PrivateASKeep PrivateASMode = iota
+
+	// PrivateASRemove removes the private AS numbers if the AS path consists of private AS numbers only
+	PrivateASRemove
+
+	// PrivateASRemoveAll removes all private AS numbers
+	PrivateASRemoveAll
+
+	// PrivateASReplace replaces all private AS numbers by the local AS
+	PrivateASReplace
+)
+
+// NextHopSelfMode determines if the next hop of paths sent to iBGP peers is replaced by the local address
+type NextHopSelfMode uint8
+
+const (
+	// NextHopSelfDisabled keeps the next hop of paths sent to iBGP peers unless they are originated locally
+	NextHopSelfDisabled NextHopSelfMode = iota
+
+	// NextHopSelfEnabled replaces the next hop of all paths except reflected ones (learned from and sent to iBGP peers)
+	NextHopSelfEnabled
+
+	// NextHopSelfForce replaces the next hop of all paths including reflected ones
+	NextHopSelfForce
+)
+
 // BGPPathA represents cachable BGP path attributes
 type BGPPathA struct {
 	NextHop         *bnet.IP
@@ -177,6 +243,11 @@ func (b *BGPPath) Length() uint16 {
 		largeCommunitiesLen += 3 + uint16(len(*b.LargeCommunities)*12)
 	}
 
+	extendedCommunitiesLen := uint16(0)
+	if b.ExtendedCommunities != nil && len(*b.ExtendedCommunities) != 0 {
+		extendedCommunitiesLen += 3 + uint16(len(*b.ExtendedCommunities)*8)
+	}
+
 	clusterListLen := uint16(0)
 	if b.ClusterList != nil && len(*b.ClusterList) != 0 {
 		clusterListLen += 3 + uint16(len(*b.ClusterList)*4)
@@ -194,17 +265,43 @@ func (b *BGPPath) Length() uint16 {
 		originatorID = 4
 	}
 
-	return communitiesLen + largeCommunitiesLen + 4*7 + 4 + originatorID + asPathLen + unknownAttributesLen
+	return communitiesLen + largeCommunitiesLen + extendedCommunitiesLen + 4*7 + 4 + originatorID + asPathLen + unknownAttributesLen
 }
 
-// ECMP determines if routes b and c are euqal in terms of ECMP
+// ECMP determines if routes b and c are euqal in terms of ECMP. Paths are only used together if multipath is enabled for both.
 func (b *BGPPath) ECMP(c *BGPPath) bool {
+	if b.Multipath == MultipathDisabled || c.Multipath == MultipathDisabled {
+		return false
+	}
+
+	if b.BGPPathA.EBGP != c.BGPPathA.EBGP {
+		return false
+	}
+
+	if (b.Multipath != MultipathMultipleAS || c.Multipath != MultipathMultipleAS) && b.neighborAS() != c.neighborAS() {
+		return false
+	}
+
 	return b.BGPPathA.LocalPref == c.BGPPathA.LocalPref &&
 		b.ASPathLen == c.ASPathLen &&
 		b.BGPPathA.MED == c.BGPPathA.MED &&
 		b.BGPPathA.Origin == c.BGPPathA.Origin
 }
 
+// neighborAS gets the AS the path has been received from, 0 for paths originated in the local AS
+func (b *BGPPath) neighborAS() uint32 {
+	if b.ASPath == nil {
+		return 0
+	}
+
+	seg := b.ASPath.GetFirstSequenceSegment()
+	if seg == nil || len(seg.ASNs) == 0 {
+		return 0
+	}
+
+	return seg.ASNs[0]
+}
+
 // Compare checks if paths are the same
 func (b *BGPPath) Compare(c *BGPPath) bool {
 	if b.PathIdentifier != c.PathIdentifier {
@@ -231,6 +328,46 @@ func (b *BGPPath) Compare(c *BGPPath) bool {
 		return false
 	}
 
+	if !b.compareExtendedCommunities(c) {
+		return false
+	}
+
+	if !b.compareLabels(c) {
+		return false
+	}
+
+	return b.RouteDistinguisher == c.RouteDistinguisher
+}
+
+func (b *BGPPath) compareExtendedCommunities(c *BGPPath) bool {
+	if b.ExtendedCommunities == nil || c.ExtendedCommunities == nil {
+		return b.ExtendedCommunities == c.ExtendedCommunities
+	}
+
+	if len(*b.ExtendedCommunities) != len(*c.ExtendedCommunities) {
+		return false
+	}
+
+	for i := range *b.ExtendedCommunities {
+		if (*b.ExtendedCommunities)[i] != (*c.ExtendedCommunities)[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *BGPPath) compareLabels(c *BGPPath) bool {
+	if len(b.Labels) != len(c.Labels) {
+		return false
+	}
+
+	for i := range b.Labels {
+		if b.Labels[i] != c.Labels[i] {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -369,8 +506,22 @@ func (b *BGPPath) Equal(c *BGPPath) bool {
 	return b.Select(c) == 0
 }
 
-// Select returns negative if b < c, 0 if paths are equal, positive if b > c
+// Select returns positive if b is preferred over c, 0 if paths are equal, negative if c is preferred over b
 func (b *BGPPath) Select(c *BGPPath) int8 {
+	return b.SelectWithOptions(c, nil)
+}
+
+// SelectWithOptions is Select using the best path options o
+func (b *BGPPath) SelectWithOptions(c *BGPPath, o *BestPathOptions) int8 {
+	if x := b.selectByAttributes(c, o); x != 0 {
+		return x
+	}
+
+	return b.selectByTiebreakers(c, o)
+}
+
+// selectByAttributes compares the paths up to the tie breaking steps not relating to the paths themselves
+func (b *BGPPath) selectByAttributes(c *BGPPath, o *BestPathOptions) int8 {
 	if c.BGPPathA.LocalPref < b.BGPPathA.LocalPref {
 		return 1
 	}
@@ -382,12 +533,14 @@ func (b *BGPPath) Select(c *BGPPath) int8 {
 	// 9.1.2.2.  Breaking Ties (Phase 2)
 
 	// a)
-	if c.ASPathLen > b.ASPathLen {
-		return 1
-	}
+	if !o.ignoreASPathLength() {
+		if c.ASPathLen > b.ASPathLen {
+			return 1
+		}
 
-	if c.ASPathLen < b.ASPathLen {
-		return -1
+		if c.ASPathLen < b.ASPathLen {
+			return -1
+		}
 	}
 
 	// b)
@@ -400,12 +553,14 @@ func (b *BGPPath) Select(c *BGPPath) int8 {
 	}
 
 	// c)
-	if c.BGPPathA.MED > b.BGPPathA.MED {
-		return 1
-	}
+	if o.alwaysCompareMED() || b.neighborAS() == c.neighborAS() {
+		if c.BGPPathA.MED > b.BGPPathA.MED {
+			return 1
+		}
 
-	if c.BGPPathA.MED < b.BGPPathA.MED {
-		return -1
+		if c.BGPPathA.MED < b.BGPPathA.MED {
+			return -1
+		}
 	}
 
 	// d)
@@ -417,54 +572,89 @@ func (b *BGPPath) Select(c *BGPPath) int8 {
 		return 1
 	}
 
-	// e) TODO: interior cost (hello IS-IS and OSPF)
+	// e)
+	if costs := o.igpCosts(); costs != nil {
+		return selectByIGPCost(b, c, costs)
+	}
 
-	// f) + RFC4456 9. (Route Reflection)
-	bgpIdentifierC := c.BGPPathA.BGPIdentifier
-	bgpIdentifierB := b.BGPPathA.BGPIdentifier
+	return 0
+}
 
-	// IF an OriginatorID (set by an RR) is present, use this instead of Originator
-	if c.BGPPathA.OriginatorID != 0 {
-		bgpIdentifierC = c.BGPPathA.OriginatorID
-	}
+// selectByIGPCost prefers the path with the lower interior cost to its next hop. Paths with reachable next hops are
+// preferred over those with unknown next hops.
+func selectByIGPCost(b, c *BGPPath, costs IGPCosts) int8 {
+	costB, okB := costs.IGPCost(b.BGPPathA.NextHop)
+	costC, okC := costs.IGPCost(c.BGPPathA.NextHop)
 
-	if b.BGPPathA.OriginatorID != 0 {
-		bgpIdentifierB = b.BGPPathA.OriginatorID
+	if okB != okC {
+		if okB {
+			return 1
+		}
+
+		return -1
 	}
 
-	if bgpIdentifierC < bgpIdentifierB {
+	if costB < costC {
 		return 1
 	}
 
-	if bgpIdentifierC > bgpIdentifierB {
+	if costB > costC {
 		return -1
 	}
 
+	return 0
+}
+
+// selectByTiebreakers prefers the path with the lowest router ID, cluster list length, peer address and next hop
+func (b *BGPPath) selectByTiebreakers(c *BGPPath, o *BestPathOptions) int8 {
+	// f) + RFC4456 9. (Route Reflection)
+	if !o.ignoreRouterID() {
+		bgpIdentifierC := c.BGPPathA.BGPIdentifier
+		bgpIdentifierB := b.BGPPathA.BGPIdentifier
+
+		// IF an OriginatorID (set by an RR) is present, use this instead of Originator
+		if c.BGPPathA.OriginatorID != 0 {
+			bgpIdentifierC = c.BGPPathA.OriginatorID
+		}
+
+		if b.BGPPathA.OriginatorID != 0 {
+			bgpIdentifierB = b.BGPPathA.OriginatorID
+		}
+
+		if bgpIdentifierB < bgpIdentifierC {
+			return 1
+		}
+
+		if bgpIdentifierB > bgpIdentifierC {
+			return -1
+		}
+	}
+
 	if c.ClusterList != nil && b.ClusterList != nil {
 		// Additionally check for the shorter ClusterList
-		if len(*c.ClusterList) < len(*b.ClusterList) {
+		if len(*b.ClusterList) < len(*c.ClusterList) {
 			return 1
 		}
 
-		if len(*c.ClusterList) > len(*b.ClusterList) {
+		if len(*b.ClusterList) > len(*c.ClusterList) {
 			return -1
 		}
 	}
 
 	// g)
-	if c.BGPPathA.Source.Compare(b.BGPPathA.Source) == -1 {
+	if b.BGPPathA.Source.Compare(c.BGPPathA.Source) == -1 {
 		return 1
 	}
 
-	if c.BGPPathA.Source.Compare(b.BGPPathA.Source) == 1 {
+	if b.BGPPathA.Source.Compare(c.BGPPathA.Source) == 1 {
 		return -1
 	}
 
-	if c.BGPPathA.NextHop.Compare(b.BGPPathA.NextHop) == -1 {
+	if b.BGPPathA.NextHop.Compare(c.BGPPathA.NextHop) == -1 {
 		return 1
 	}
 
-	if c.BGPPathA.NextHop.Compare(b.BGPPathA.NextHop) == 1 {
+	if b.BGPPathA.NextHop.Compare(c.BGPPathA.NextHop) == 1 {
 		return -1
 	}
 
@@ -556,9 +746,18 @@ func (b *BGPPath) String() string {
 	if b.LargeCommunities != nil {
 		fmt.Fprintf(buf, "LargeCommunities: %v", *b.LargeCommunities)
 	}
+	if b.ExtendedCommunities != nil {
+		fmt.Fprintf(buf, ", ExtendedCommunities: %s", b.ExtendedCommunities.String())
+	}
+	if b.RouteDistinguisher != 0 {
+		fmt.Fprintf(buf, ", RD: %d:%d", b.RouteDistinguisher>>32, uint32(b.RouteDistinguisher))
+	}
+	if len(b.Labels) > 0 {
+		fmt.Fprintf(buf, ", Labels: %v", b.Labels)
+	}
 
 	if b.BGPPathA.OriginatorID != 0 {
-		oid := convert.Uint32Byte(b.BGPPathA.OriginatorID)
+		oid := endian.Uint32Array(b.BGPPathA.OriginatorID)
 		fmt.Fprintf(buf, ", OriginatorID: %d.%d.%d.%d", oid[0], oid[1], oid[2], oid[3])
 	}
 	if b.ClusterList != nil {
@@ -601,9 +800,21 @@ func (b *BGPPath) Print() string {
 	if b.LargeCommunities != nil {
 		fmt.Fprintf(buf, "\t\tLargeCommunities: %v\n", *b.LargeCommunities)
 	}
+	if b.ExtendedCommunities != nil {
+		fmt.Fprintf(buf, "\t\tExtendedCommunities: %s\n", b.ExtendedCommunities.String())
+	}
+	if b.RouteDistinguisher != 0 {
+		fmt.Fprintf(buf, "\t\tRD: %d:%d\n", b.RouteDistinguisher>>32, uint32(b.RouteDistinguisher))
+	}
+	if len(b.Labels) > 0 {
+		fmt.Fprintf(buf, "\t\tLabels: %v\n", b.Labels)
+	}
+	if b.ValidationState != vrp.NotValidated {
+		fmt.Fprintf(buf, "\t\tRPKI: %s\n", b.ValidationState.String())
+	}
 
 	if b.BGPPathA.OriginatorID != 0 {
-		oid := convert.Uint32Byte(b.BGPPathA.OriginatorID)
+		oid := endian.Uint32Array(b.BGPPathA.OriginatorID)
 		fmt.Fprintf(buf, "\t\tOriginatorID: %d.%d.%d.%d\n", oid[0], oid[1], oid[2], oid[3])
 	}
 	if b.ClusterList != nil {
@@ -613,12 +824,106 @@ func (b *BGPPath) Print() string {
 	return buf.String()
 }
 
+// ScrubPrivateASNs removes or replaces private AS numbers of the AS path according to mode. The AS of the peer is kept
+// to preserve its loop detection and confederation segments are never changed.
+func (b *BGPPath) ScrubPrivateASNs(mode PrivateASMode, localASN uint32, peerASN uint32) {
+	if mode == PrivateASKeep || b.ASPath == nil {
+		return
+	}
+
+	scrub := func(asn uint32) bool {
+		return types.IsPrivateASN(asn) && asn != peerASN
+	}
+
+	if mode == PrivateASRemove {
+		for _, seg := range *b.ASPath {
+			if seg.Type != types.ASSequence && seg.Type != types.ASSet {
+				continue
+			}
+
+			for _, asn := range seg.ASNs {
+				if !scrub(asn) {
+					return
+				}
+			}
+		}
+	}
+
+	// Segments are rebuilt as their AS numbers may be shared with other paths
+	asPath := make(types.ASPath, 0, len(*b.ASPath))
+	for _, seg := range *b.ASPath {
+		if seg.Type != types.ASSequence && seg.Type != types.ASSet {
+			asPath = append(asPath, seg)
+			continue
+		}
+
+		asns := make([]uint32, 0, len(seg.ASNs))
+		for _, asn := range seg.ASNs {
+			if !scrub(asn) {
+				asns = append(asns, asn)
+				continue
+			}
+
+			if mode == PrivateASReplace {
+				asns = append(asns, localASN)
+			}
+		}
+
+		if len(asns) > 0 {
+			asPath = append(asPath, types.ASPathSegment{
+				Type: seg.Type,
+				ASNs: asns,
+			})
+		}
+	}
+
+	b.ASPath = &asPath
+	b.ASPathLen = asPath.Length()
+}
+
+// OverrideASN replaces all occurrences of peerASN in the AS path by localASN (as-override), e.g. to allow sites of a VPN
+// using the same AS to learn each others routes. Confederation segments are never changed.
+func (b *BGPPath) OverrideASN(peerASN uint32, localASN uint32) {
+	if b.ASPath == nil {
+		return
+	}
+
+	// Segments are rebuilt as their AS numbers may be shared with other paths
+	asPath := make(types.ASPath, 0, len(*b.ASPath))
+	for _, seg := range *b.ASPath {
+		if seg.Type != types.ASSequence && seg.Type != types.ASSet {
+			asPath = append(asPath, seg)
+			continue
+		}
+
+		asns := make([]uint32, len(seg.ASNs))
+		for i, asn := range seg.ASNs {
+			if asn == peerASN {
+				asn = localASN
+			}
+
+			asns[i] = asn
+		}
+
+		asPath = append(asPath, types.ASPathSegment{
+			Type: seg.Type,
+			ASNs: asns,
+		})
+	}
+
+	b.ASPath = &asPath
+}
+
 // Prepend the given BGPPath with the given ASN given times
 func (b *BGPPath) Prepend(asn uint32, times uint16) {
 	if times == 0 {
 		return
 	}
 
+	if b.ASPath == nil {
+		b.ASPath = &types.ASPath{}
+	}
+
 	if len(*b.ASPath) == 0 {
 		b.insertNewASSequence()
 	}
@@ -629,7 +934,7 @@ func (b *BGPPath) Prepend(asn uint32, times uint16) {
 	}
 
 	for i := 0; i < int(times); i++ {
-		if len(*b.ASPath) == types.MaxASNsSegment {
+		if len((*b.ASPath)[0].ASNs) == types.MaxASNsSegment {
 			b.insertNewASSequence()
 		}
 
@@ -643,6 +948,55 @@ func (b *BGPPath) Prepend(asn uint32, times uint16) {
 	b.ASPathLen = b.ASPath.Length()
 }
 
+// PrependSequence prepends the ASNs of a sequence times to the AS path, e.g. 65001 65002 twice results in 65001 65002 65001 65002
+func (b *BGPPath) PrependSequence(asns []uint32, times uint16) {
+	for i := 0; i < int(times); i++ {
+		for j := len(asns) - 1; j >= 0; j-- {
+			b.Prepend(asns[j], 1)
+		}
+	}
+}
+
+// TruncateASPath removes all ASNs beyond the first n from the AS path. AS sets count as a single ASN and confederation
+// segments are not counted (RFC5065 5.3), as for the AS path length used by path selection.
+func (b *BGPPath) TruncateASPath(n uint16) {
+	if b.ASPath == nil || b.ASPath.Length() <= n {
+		return
+	}
+
+	remaining := int(n)
+	truncated := make(types.ASPath, 0, len(*b.ASPath))
+	for _, seg := range *b.ASPath {
+		count := len(seg.ASNs)
+		switch seg.Type {
+		case types.ASConfedSequence, types.ASConfedSet:
+			count = 0
+		case types.ASSet:
+			count = 1
+		}
+
+		if count > remaining {
+			if seg.Type != types.ASSequence || remaining == 0 {
+				continue
+			}
+
+			count = remaining
+			seg.ASNs = seg.ASNs[:count]
+		}
+
+		asns := make([]uint32, len(seg.ASNs))
+		copy(asns, seg.ASNs)
+		truncated = append(truncated, types.ASPathSegment{
+			Type: seg.Type,
+			ASNs: asns,
+		})
+		remaining -= count
+	}
+
+	b.ASPath = &truncated
+	b.ASPathLen = truncated.Length()
+}
+
 func (b *BGPPath) insertNewASSequence() {
 	pa := make(types.ASPath, len(*b.ASPath)+1)
 	copy(pa[1:], (*b.ASPath))
@@ -680,6 +1034,17 @@ func (b *BGPPath) Copy() *BGPPath {
 		copy(*cp.LargeCommunities, *b.LargeCommunities)
 	}
 
+	if cp.ExtendedCommunities != nil {
+		extendedCommunities := make(types.ExtendedCommunities, len(*cp.ExtendedCommunities))
+		cp.ExtendedCommunities = &extendedCommunities
+		copy(*cp.ExtendedCommunities, *b.ExtendedCommunities)
+	}
+
+	if cp.Labels != nil {
+		cp.Labels = make([]uint32, len(b.Labels))
+		copy(cp.Labels, b.Labels)
+	}
+
 	if b.ClusterList != nil {
 		clusterList := make(types.ClusterList, len(*cp.ClusterList))
 		cp.ClusterList = &clusterList
@@ -703,7 +1068,7 @@ func (b *BGPPath) ComputeHash() string {
 		b.Communities.String(),
 		b.LargeCommunities.String(),
 		b.BGPPathA.OriginatorID,
-		b.ClusterList.String())
+		b.ClusterList.String()) + b.vpnHashSuffix()
 
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
 }
@@ -723,11 +1088,20 @@ func (b *BGPPath) ComputeHashWithPathID() string {
 		b.LargeCommunities.String(),
 		b.PathIdentifier,
 		b.BGPPathA.OriginatorID,
-		b.ClusterList.String())
+		b.ClusterList.String()) + b.vpnHashSuffix()
 
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
 }
 
+// vpnHashSuffix gets the attributes of labeled and VPN routes to include into hashes. It is empty for all other routes.
+func (b *BGPPath) vpnHashSuffix() string {
+	if b.ExtendedCommunities == nil && b.Labels == nil && b.RouteDistinguisher == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\t%s\t%v\t%d", b.ExtendedCommunities.String(), b.Labels, b.RouteDistinguisher)
+}
+
 // CommunitiesString returns the formated communities
 func (b *BGPPath) CommunitiesString() string {
 	str := &strings.Builder{}
@@ -750,7 +1124,7 @@ func (b *BGPPath) ClusterListString() string {
 		if i > 0 {
 			str.WriteByte(' ')
 		}
-		octes := convert.Uint32Byte(cid)
+		octes := endian.Uint32Array(cid)
 
 		fmt.Fprintf(str, "%d.%d.%d.%d", octes[0], octes[1], octes[2], octes[3])
 	}
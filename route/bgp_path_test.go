@@ -367,11 +367,116 @@ func TestBGPECMP(t *testing.T) {
 		{
 			name: "Equal",
 			p: &BGPPath{
-				BGPPathA: NewBGPPathA(),
+				BGPPathA:  NewBGPPathA(),
+				Multipath: MultipathSameAS,
 			},
 			q: &BGPPath{
+				BGPPathA:  NewBGPPathA(),
+				Multipath: MultipathSameAS,
+			},
+			expected: true,
+		},
+		{
+			name: "Multipath disabled",
+			p: &BGPPath{
 				BGPPathA: NewBGPPathA(),
 			},
+			q: &BGPPath{
+				BGPPathA:  NewBGPPathA(),
+				Multipath: MultipathSameAS,
+			},
+			expected: false,
+		},
+		{
+			name: "eBGP and iBGP",
+			p: &BGPPath{
+				BGPPathA: &BGPPathA{
+					EBGP: true,
+				},
+				Multipath: MultipathMultipleAS,
+			},
+			q: &BGPPath{
+				BGPPathA:  &BGPPathA{},
+				Multipath: MultipathMultipleAS,
+			},
+			expected: false,
+		},
+		{
+			name: "Same neighbor AS",
+			p: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathSameAS,
+			},
+			q: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65200},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathSameAS,
+			},
+			expected: true,
+		},
+		{
+			name: "Different neighbor AS",
+			p: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathSameAS,
+			},
+			q: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65002, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathMultipleAS,
+			},
+			expected: false,
+		},
+		{
+			name: "Different neighbor AS relaxed",
+			p: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathMultipleAS,
+			},
+			q: &BGPPath{
+				BGPPathA: &BGPPathA{},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65002, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathMultipleAS,
+			},
 			expected: true,
 		},
 		{
@@ -563,3 +668,86 @@ func TestBGPPathString(t *testing.T) {
 		assert.Equal(t, test.expectedPrint, test.input.Print())
 	}
 }
+
+func TestScrubPrivateASNs(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     PrivateASMode
+		peerASN  uint32
+		asPath   types.ASPath
+		expected types.ASPath
+	}{
+		{
+			name:     "Keep",
+			mode:     PrivateASKeep,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 3320}}},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 3320}}},
+		},
+		{
+			name:     "Remove with private ASNs only",
+			mode:     PrivateASRemove,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 4200000000}}},
+			expected: types.ASPath{},
+		},
+		{
+			name:     "Remove with public ASN",
+			mode:     PrivateASRemove,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 3320}}},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 3320}}},
+		},
+		{
+			name: "Remove all",
+			mode: PrivateASRemoveAll,
+			asPath: types.ASPath{
+				{Type: types.ASSequence, ASNs: []uint32{64512, 3320, 4294967294}},
+				{Type: types.ASSet, ASNs: []uint32{65000}},
+			},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{3320}}},
+		},
+		{
+			name:     "Remove all keeps 4 byte ASNs outside of the private range",
+			mode:     PrivateASRemoveAll,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{4199999999, 4294967295, 65535}}},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{4199999999, 4294967295, 65535}}},
+		},
+		{
+			name:     "Replace",
+			mode:     PrivateASReplace,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{64512, 3320, 4200000000}}},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{201701, 3320, 201701}}},
+		},
+		{
+			name:     "Peer AS is kept",
+			mode:     PrivateASRemoveAll,
+			peerASN:  65000,
+			asPath:   types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65000, 64512}}},
+			expected: types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65000}}},
+		},
+		{
+			name: "Confederation segments are not changed",
+			mode: PrivateASRemoveAll,
+			asPath: types.ASPath{
+				{Type: types.ASConfedSequence, ASNs: []uint32{64512, 64513}},
+				{Type: types.ASSequence, ASNs: []uint32{64514, 3320}},
+			},
+			expected: types.ASPath{
+				{Type: types.ASConfedSequence, ASNs: []uint32{64512, 64513}},
+				{Type: types.ASSequence, ASNs: []uint32{3320}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		asPath := test.asPath
+		orig := asPath.String()
+		p := &BGPPath{
+			ASPath:    &asPath,
+			ASPathLen: asPath.Length(),
+		}
+
+		p.ScrubPrivateASNs(test.mode, 201701, test.peerASN)
+		assert.Equal(t, test.expected, *p.ASPath, "Test %q", test.name)
+		assert.Equal(t, test.expected.Length(), p.ASPathLen, "Test %q", test.name)
+		assert.Equal(t, orig, test.asPath.String(), "Test %q: Original AS path must not be modified", test.name)
+	}
+}
@@ -0,0 +1,40 @@
+package route
+
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// IGPCosts provides the interior cost to the next hops of paths for the BGP decision process (RFC4271 9.1.2.2 e)
+type IGPCosts interface {
+	// IGPCost gets the cost to reach addr. It returns false if addr is not reachable.
+	IGPCost(addr *bnet.IP) (uint32, bool)
+}
+
+// IGPCostTable is an immutable set of IGP costs, e.g. the distances of a shortest path tree to the loopback addresses
+// of its nodes
+type IGPCostTable struct {
+	costs map[bnet.IP]uint32
+}
+
+// NewIGPCostTable creates a new IGP cost table
+func NewIGPCostTable(costs map[bnet.IP]uint32) *IGPCostTable {
+	t := &IGPCostTable{
+		costs: make(map[bnet.IP]uint32, len(costs)),
+	}
+
+	for addr, cost := range costs {
+		t.costs[addr] = cost
+	}
+
+	return t
+}
+
+// IGPCost gets the cost to reach addr
+func (t *IGPCostTable) IGPCost(addr *bnet.IP) (uint32, bool) {
+	if t == nil || addr == nil {
+		return 0, false
+	}
+
+	cost, ok := t.costs[*addr]
+	return cost, ok
+}
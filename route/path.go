@@ -13,10 +13,16 @@ type Path struct {
 	StaticPath *StaticPath
 	BGPPath    *BGPPath
 	FIBPath    *FIBPath
+	RIPPath    *RIPPath
 }
 
-// Select returns negative if p < q, 0 if paths are equal, positive if p > q
+// Select returns negative if p is preferred over q, 0 if paths are equal, positive if q is preferred over p
 func (p *Path) Select(q *Path) int8 {
+	return p.SelectWithOptions(q, nil)
+}
+
+// SelectWithOptions is Select using the BGP best path options o
+func (p *Path) SelectWithOptions(q *Path, o *BestPathOptions) int8 {
 	switch {
 	case p == nil && q == nil:
 		return 0
@@ -37,11 +43,14 @@ func (p *Path) Select(q *Path) int8 {
 
 	switch p.Type {
 	case BGPPathType:
-		return p.BGPPath.Select(q.BGPPath)
+		// BGPPath.Select returns positive for the better path
+		return q.BGPPath.SelectWithOptions(p.BGPPath, o)
 	case StaticPathType:
 		return p.StaticPath.Select(q.StaticPath)
 	case FIBPathType:
 		return p.FIBPath.Select(q.FIBPath)
+	case RIPPathType:
+		return p.RIPPath.Select(q.RIPPath)
 	}
 
 	return 0
@@ -49,6 +58,10 @@ func (p *Path) Select(q *Path) int8 {
 
 // ECMP checks if path p and q are equal enough to be considered for ECMP usage
 func (p *Path) ECMP(q *Path) bool {
+	if p.Type != q.Type {
+		return false
+	}
+
 	switch p.Type {
 	case BGPPathType:
 		return p.BGPPath.ECMP(q.BGPPath)
@@ -56,6 +69,8 @@ func (p *Path) ECMP(q *Path) bool {
 		return p.StaticPath.ECMP(q.StaticPath)
 	case FIBPathType:
 		return p.FIBPath.ECMP(q.FIBPath)
+	case RIPPathType:
+		return p.RIPPath.ECMP(q.RIPPath)
 	}
 
 	panic("Unknown path type")
@@ -93,6 +108,8 @@ func (p *Path) Compare(q *Path) bool {
 		return p.BGPPath.Compare(q.BGPPath)
 	case StaticPathType:
 		return p.StaticPath.Compare(q.StaticPath)
+	case RIPPathType:
+		return p.RIPPath.Equal(q.RIPPath)
 	}
 
 	return false
@@ -113,6 +130,8 @@ func (p *Path) Equal(q *Path) bool {
 		return p.BGPPath.Equal(q.BGPPath)
 	case StaticPathType:
 		return p.StaticPath.Equal(q.StaticPath)
+	case RIPPathType:
+		return p.RIPPath.Equal(q.RIPPath)
 	}
 
 	return p.Select(q) == 0
@@ -150,11 +169,33 @@ func (p *Path) String() string {
 		return p.BGPPath.String()
 	case FIBPathType:
 		return p.FIBPath.String()
+	case RIPPathType:
+		return p.RIPPath.String()
 	default:
 		return fmt.Sprintf("Unknown path type. Probably not implemented yet (%d)", p.Type)
 	}
 }
 
+// PathTypeName returns the name of the protocol of a path type
+func PathTypeName(t uint8) string {
+	switch t {
+	case StaticPathType:
+		return "static"
+	case BGPPathType:
+		return "bgp"
+	case OSPFPathType:
+		return "ospf"
+	case ISISPathType:
+		return "isis"
+	case FIBPathType:
+		return "fib"
+	case RIPPathType:
+		return "rip"
+	}
+
+	return "unknown"
+}
+
 // Print all known information about a route in human readable form
 func (p *Path) Print() string {
 	protocol := ""
@@ -165,6 +206,8 @@ func (p *Path) Print() string {
 		protocol = "BGP"
 	case FIBPathType:
 		protocol = "Netlink"
+	case RIPPathType:
+		protocol = "RIP"
 	}
 
 	ret := fmt.Sprintf("\tProtocol: %s\n", protocol)
@@ -175,6 +218,8 @@ func (p *Path) Print() string {
 		ret += p.BGPPath.Print()
 	case FIBPathType:
 		ret += p.FIBPath.Print()
+	case RIPPathType:
+		ret += p.RIPPath.Print()
 	}
 
 	return ret
@@ -189,6 +234,7 @@ func (p *Path) Copy() *Path {
 	cp := *p
 	cp.BGPPath = cp.BGPPath.Copy()
 	cp.StaticPath = cp.StaticPath.Copy()
+	cp.RIPPath = cp.RIPPath.Copy()
 
 	return &cp
 }
@@ -202,7 +248,18 @@ func (p *Path) NextHop() *bnet.IP {
 		return p.StaticPath.NextHop
 	case FIBPathType:
 		return p.FIBPath.NextHop
+	case RIPPathType:
+		return p.RIPPath.NextHop
 	}
 
 	panic("Unknown path type")
 }
+
+// NextHopIfIndex returns the index of the interface the next hop is bound to (0 if not bound to an interface)
+func (p *Path) NextHopIfIndex() uint64 {
+	if p.Type == RIPPathType {
+		return p.RIPPath.IfIndex
+	}
+
+	return 0
+}
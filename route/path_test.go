@@ -398,6 +398,7 @@ func TestECMP(t *testing.T) {
 						Source:    net.IPv4(0).Ptr(),
 					},
 					ASPathLen: 10,
+					Multipath: MultipathSameAS,
 				},
 			},
 			right: &Path{
@@ -411,6 +412,7 @@ func TestECMP(t *testing.T) {
 						Source:    net.IPv4(0).Ptr(),
 					},
 					ASPathLen: 10,
+					Multipath: MultipathSameAS,
 				},
 			},
 			ecmp: true,
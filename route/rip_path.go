@@ -0,0 +1,57 @@
+package route
+
+import (
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+)
+
+// RIPPath represents a path learned via RIP
+type RIPPath struct {
+	NextHop *bnet.IP
+	IfIndex uint64 // index of the interface the path was learned on. Next hops are link-local (RFC 2080 2.4.2)
+	Metric  uint8
+}
+
+// Select returns negative if s < t, 0 if paths are equal, positive if s > t. Paths with a lower metric are preferred.
+func (s *RIPPath) Select(t *RIPPath) int8 {
+	if s.Metric < t.Metric {
+		return 1
+	}
+
+	if s.Metric > t.Metric {
+		return -1
+	}
+
+	return s.NextHop.Compare(t.NextHop)
+}
+
+// Equal returns true if s and t are equal
+func (s *RIPPath) Equal(t *RIPPath) bool {
+	return s.Metric == t.Metric && s.IfIndex == t.IfIndex && s.NextHop.Compare(t.NextHop) == 0
+}
+
+// ECMP determines if path s and t are equal in terms of ECMP
+func (s *RIPPath) ECMP(t *RIPPath) bool {
+	return s.Metric == t.Metric
+}
+
+// Copy copies a RIP path
+func (s *RIPPath) Copy() *RIPPath {
+	if s == nil {
+		return nil
+	}
+
+	cp := *s
+	return &cp
+}
+
+// String converts a RIP path to a string
+func (s *RIPPath) String() string {
+	return fmt.Sprintf("NextHop: %s, IfIndex: %d, Metric: %d", s.NextHop.String(), s.IfIndex, s.Metric)
+}
+
+// Print all known information about a RIP path in human readable form
+func (s *RIPPath) Print() string {
+	return fmt.Sprintf("\t\tNextHop: %s\n\t\tIfIndex: %d\n\t\tMetric: %d\n", s.NextHop.String(), s.IfIndex, s.Metric)
+}
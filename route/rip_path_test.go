@@ -0,0 +1,71 @@
+package route
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRIPPathSelect(t *testing.T) {
+	nh1 := bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1).Ptr()
+	nh2 := bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 2).Ptr()
+
+	tests := []struct {
+		name     string
+		a        *RIPPath
+		b        *RIPPath
+		expected int8
+	}{
+		{
+			name:     "Lower metric is preferred",
+			a:        &RIPPath{NextHop: nh2, Metric: 1},
+			b:        &RIPPath{NextHop: nh1, Metric: 2},
+			expected: 1,
+		},
+		{
+			name:     "Higher metric",
+			a:        &RIPPath{NextHop: nh1, Metric: 3},
+			b:        &RIPPath{NextHop: nh2, Metric: 2},
+			expected: -1,
+		},
+		{
+			name:     "Next hop tie breaker",
+			a:        &RIPPath{NextHop: nh1, Metric: 2},
+			b:        &RIPPath{NextHop: nh2, Metric: 2},
+			expected: -1,
+		},
+		{
+			name:     "Equal",
+			a:        &RIPPath{NextHop: nh1, Metric: 2},
+			b:        &RIPPath{NextHop: nh1, Metric: 2},
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, test.a.Select(test.b), "Test %q", test.name)
+	}
+}
+
+func TestRIPPath(t *testing.T) {
+	nh := bnet.IPv6FromBlocks(0xfe80, 0, 0, 0, 0, 0, 0, 1)
+	p := &Path{
+		Type: RIPPathType,
+		RIPPath: &RIPPath{
+			NextHop: nh.Ptr(),
+			Metric:  2,
+		},
+	}
+
+	c := p.Copy()
+	assert.True(t, p.Equal(c))
+	assert.True(t, p.Compare(c))
+	assert.True(t, p.ECMP(c))
+	assert.Equal(t, nh, *c.NextHop())
+	assert.Equal(t, "rip", PathTypeName(c.Type))
+
+	c.RIPPath.Metric = 3
+	assert.False(t, p.Equal(c))
+	assert.Equal(t, uint8(2), p.RIPPath.Metric)
+}
@@ -2,7 +2,6 @@ package route
 
 import (
 	"fmt"
-	"sort"
 	"sync"
 
 	"github.com/bio-routing/bio-rd/net"
@@ -26,6 +25,9 @@ const (
 
 	// FIBPathType indicates a path is a FIB path
 	FIBPathType
+
+	// RIPPathType indicates a path is a RIP path
+	RIPPathType
 )
 
 // Route links a prefix to paths
@@ -133,6 +135,39 @@ func (r *Route) ECMPPaths() []*Path {
 	return ret
 }
 
+// BackupPath returns the best path not in the ECMP set using a next hop none of the ECMP paths uses.
+// It takes over forwarding if all ECMP paths fail (BGP PIC). nil if non exists.
+func (r *Route) BackupPath() *Path {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if int(r.ecmpPaths) >= len(r.paths) {
+		return nil
+	}
+
+	for _, p := range r.paths[r.ecmpPaths:] {
+		if !r.isECMPNextHop(p.NextHop()) {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (r *Route) isECMPNextHop(nh *net.IP) bool {
+	for _, p := range r.paths[:r.ecmpPaths] {
+		x := p.NextHop()
+		if x == nh || (x != nil && nh != nil && x.Equal(nh)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BestPath returns the current best path. nil if non exists
 func (r *Route) BestPath() *Path {
 	if r == nil {
@@ -145,6 +180,18 @@ func (r *Route) BestPath() *Path {
 	return r.paths[0]
 }
 
+// BestPathWithOptions selects the best path of route r using the BGP best path options o without changing the order of
+// the paths of r, e.g. to select the best path for a certain client
+func (r *Route) BestPathWithOptions(o *BestPathOptions) *Path {
+	paths := r.Paths()
+	if len(paths) == 0 {
+		return nil
+	}
+
+	sortPaths(paths, o)
+	return paths[0]
+}
+
 // AddPath adds path p to route r
 func (r *Route) AddPath(p *Path) {
 	if p == nil {
@@ -201,12 +248,21 @@ func removePath(paths []*Path, remove *Path) []*Path {
 
 // PathSelection recalculates the best path + active paths
 func (r *Route) PathSelection() {
+	r.PathSelectionWithOptions(nil)
+}
+
+// PathSelectionWithOptions recalculates the best path + active paths using the BGP best path options o
+func (r *Route) PathSelectionWithOptions(o *BestPathOptions) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	sort.Slice(r.paths, func(i, j int) bool {
-		return r.paths[i].Select(r.paths[j]) == -1
-	})
+	var prev *Path
+	if len(r.paths) > 0 {
+		prev = r.paths[0]
+	}
+
+	sortPaths(r.paths, o)
+	keepOldest(r.paths, prev, o)
 
 	r.updateEqualPathCount()
 }
@@ -296,15 +352,22 @@ func (r *Route) updateEqualPathCount() {
 		return
 	}
 
-	count := uint(1)
-	for i := 0; i < len(r.paths)-1; i++ {
-		if !r.paths[i].ECMP(r.paths[i+1]) {
-			break
+	// Paths equal to the best path in terms of ECMP are not necessarily adjacent (e.g. BGP multipath restricted
+	// to the neighboring AS), so they are moved in front of all other paths keeping their order.
+	ecmp := make([]*Path, 1, len(r.paths))
+	ecmp[0] = r.paths[0]
+	others := make([]*Path, 0, len(r.paths)-1)
+	for _, p := range r.paths[1:] {
+		if r.paths[0].ECMP(p) {
+			ecmp = append(ecmp, p)
+			continue
 		}
-		count++
+
+		others = append(others, p)
 	}
 
-	r.ecmpPaths = count
+	r.paths = append(ecmp, others...)
+	r.ecmpPaths = uint(len(ecmp))
 }
 
 func getBestProtocol(paths []*Path) uint8 {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bio-routing/bio-rd/net"
 	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 )
 
 func TestNewRoute(t *testing.T) {
@@ -443,6 +444,101 @@ func TestECMPPaths(t *testing.T) {
 	}
 }
 
+func TestBackupPath(t *testing.T) {
+	staticPath := func(nh uint32) *Path {
+		return &Path{
+			Type: StaticPathType,
+			StaticPath: &StaticPath{
+				NextHop: bnet.IPv4(nh).Ptr(),
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		route    *Route
+		expected *Path
+	}{
+		{
+			name:     "Nil route",
+			route:    nil,
+			expected: nil,
+		},
+		{
+			name: "Single path",
+			route: &Route{
+				ecmpPaths: 1,
+				paths:     []*Path{staticPath(1)},
+			},
+			expected: nil,
+		},
+		{
+			name: "Backup with different next hop",
+			route: &Route{
+				ecmpPaths: 1,
+				paths:     []*Path{staticPath(1), staticPath(2)},
+			},
+			expected: staticPath(2),
+		},
+		{
+			name: "Skip paths via ECMP next hops",
+			route: &Route{
+				ecmpPaths: 2,
+				paths:     []*Path{staticPath(1), staticPath(2), staticPath(1), staticPath(3)},
+			},
+			expected: staticPath(3),
+		},
+		{
+			name: "All paths via ECMP next hops",
+			route: &Route{
+				ecmpPaths: 1,
+				paths:     []*Path{staticPath(1), staticPath(1)},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, test.route.BackupPath(), "Test %q", test.name)
+	}
+}
+
+func TestPathSelectionMultipath(t *testing.T) {
+	bgpPath := func(routerID uint32, neighborAS uint32) *Path {
+		return &Path{
+			Type: BGPPathType,
+			BGPPath: &BGPPath{
+				BGPPathA: &BGPPathA{
+					EBGP:          true,
+					BGPIdentifier: routerID,
+					NextHop:       bnet.IPv4(routerID).Ptr(),
+					Source:        bnet.IPv4(routerID).Ptr(),
+				},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{neighborAS, 65100},
+					},
+				},
+				ASPathLen: 2,
+				Multipath: MultipathSameAS,
+			},
+		}
+	}
+
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	r := NewRoute(&pfx, bgpPath(1, 65001))
+	r.AddPath(bgpPath(2, 65002))
+	r.AddPath(bgpPath(3, 65001))
+	r.PathSelection()
+
+	// Paths from the neighboring AS of the best path are ECMP even if a path from another AS is selected in between
+	assert.Equal(t, uint(2), r.ECMPPathCount())
+	for _, p := range r.ECMPPaths() {
+		assert.Equal(t, r.BestPath().BGPPath.neighborAS(), p.BGPPath.neighborAS())
+	}
+}
+
 func TestRouteEqual(t *testing.T) {
 	tests := []struct {
 		a     *Route
@@ -0,0 +1,94 @@
+package route
+
+import (
+	"unsafe"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+var (
+	routeSize      = uint64(unsafe.Sizeof(Route{}))
+	prefixSize     = uint64(unsafe.Sizeof(net.Prefix{}))
+	pathSize       = uint64(unsafe.Sizeof(Path{}))
+	pointerSize    = uint64(unsafe.Sizeof(uintptr(0)))
+	staticPathSize = uint64(unsafe.Sizeof(StaticPath{}))
+	fibPathSize    = uint64(unsafe.Sizeof(FIBPath{}))
+	bgpPathSize    = uint64(unsafe.Sizeof(BGPPath{}))
+	bgpPathASize   = uint64(unsafe.Sizeof(BGPPathA{}))
+	asSegmentSize  = uint64(unsafe.Sizeof(types.ASPathSegment{}))
+	largeComSize   = uint64(unsafe.Sizeof(types.LargeCommunity{}))
+)
+
+// SizeEstimate estimates the memory used by the route and its paths in bytes.
+// Attributes shared between paths (e.g. deduplicated next hops) are counted for every path, so this is an upper bound.
+func (r *Route) SizeEstimate() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := routeSize + prefixSize + uint64(cap(r.paths))*pointerSize
+	for _, p := range r.paths {
+		s += p.SizeEstimate()
+	}
+
+	return s
+}
+
+// SizeEstimate estimates the memory used by the path in bytes
+func (p *Path) SizeEstimate() uint64 {
+	if p == nil {
+		return 0
+	}
+
+	s := pathSize
+	if p.StaticPath != nil {
+		s += staticPathSize
+	}
+
+	if p.FIBPath != nil {
+		s += fibPathSize
+	}
+
+	if p.BGPPath != nil {
+		s += p.BGPPath.sizeEstimate()
+	}
+
+	return s
+}
+
+func (b *BGPPath) sizeEstimate() uint64 {
+	s := bgpPathSize
+	if b.BGPPathA != nil {
+		s += bgpPathASize
+	}
+
+	if b.ASPath != nil {
+		for _, seg := range *b.ASPath {
+			s += asSegmentSize + uint64(cap(seg.ASNs))*4
+		}
+	}
+
+	if b.ClusterList != nil {
+		s += uint64(cap(*b.ClusterList)) * 4
+	}
+
+	if b.Communities != nil {
+		s += uint64(cap(*b.Communities)) * 4
+	}
+
+	if b.LargeCommunities != nil {
+		s += uint64(cap(*b.LargeCommunities)) * largeComSize
+	}
+
+	if b.ExtendedCommunities != nil {
+		s += uint64(cap(*b.ExtendedCommunities)) * 8
+	}
+
+	s += uint64(cap(b.Labels)) * 4
+
+	for _, u := range b.UnknownAttributes {
+		s += uint64(unsafe.Sizeof(u)) + uint64(cap(u.Value))
+	}
+
+	return s
+}
@@ -0,0 +1,31 @@
+package route
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeEstimate(t *testing.T) {
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+
+	empty := NewRoute(pfx, nil)
+	static := NewRoute(pfx, &Path{Type: StaticPathType, StaticPath: &StaticPath{}})
+	bgp := NewRoute(pfx, &Path{
+		Type: BGPPathType,
+		BGPPath: &BGPPath{
+			BGPPathA: NewBGPPathA(),
+			ASPath: &types.ASPath{
+				{Type: types.ASSequence, ASNs: []uint32{65001, 65002, 65003}},
+			},
+			Communities: &types.Communities{1, 2},
+		},
+	})
+
+	assert.True(t, empty.SizeEstimate() > 0)
+	assert.True(t, static.SizeEstimate() > empty.SizeEstimate())
+	assert.True(t, bgp.SizeEstimate() > static.SizeEstimate())
+	assert.Equal(t, uint64(0), (*Path)(nil).SizeEstimate())
+}
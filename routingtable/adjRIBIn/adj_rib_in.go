@@ -4,10 +4,10 @@ import (
 	"sync"
 
 	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
-	log "github.com/sirupsen/logrus"
 )
 
 // AdjRIBIn represents an Adjacency RIB In as described in RFC4271
@@ -20,6 +20,19 @@ type AdjRIBIn struct {
 	routerID          uint32
 	clusterID         uint32
 	addPathRX         bool
+	stale             map[net.Prefix]map[uint32]struct{}
+	validator         OriginValidator
+	allowASIn         uint8
+
+	// Route reflection loop detection (RFC4456 8)
+	skipOriginatorIDCheck bool
+	skipClusterListCheck  bool
+	clusterIDs            *routingtable.ClusterIDs
+}
+
+// OriginValidator computes the RPKI origin validation state of paths (RFC6811)
+type OriginValidator interface {
+	Validate(pfx *net.Prefix, p *route.Path) vrp.ValidationState
 }
 
 // New creates a new Adjacency RIB In
@@ -74,36 +87,155 @@ func (a *AdjRIBIn) ReplaceFilterChain(c filter.Chain) {
 			currentPath, currentReject := a.exportFilterChain.Process(route.Prefix(), path)
 			newPath, newReject := c.Process(route.Prefix(), path)
 
-			if currentReject && newReject {
+			a.propagateChange(route.Prefix(), currentPath, currentReject, newPath, newReject)
+		}
+	}
+
+	a.exportFilterChain = c
+}
+
+// SetOriginValidator sets the validator computing the origin validation state of received paths
+func (a *AdjRIBIn) SetOriginValidator(v OriginValidator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.validator = v
+}
+
+// SetAllowASIn sets the number of occurrences of our ASNs accepted in the AS path of received paths (allowas-in)
+func (a *AdjRIBIn) SetAllowASIn(n uint8) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.allowASIn = n
+}
+
+// SetReflectionChecks enables or disables the ORIGINATOR_ID and CLUSTER_LIST checks for received paths (RFC4456 8).
+// Both are enabled by default.
+func (a *AdjRIBIn) SetReflectionChecks(originatorID bool, clusterList bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.skipOriginatorIDCheck = !originatorID
+	a.skipClusterListCheck = !clusterList
+}
+
+// SetClusterIDs sets the cluster IDs CLUSTER_LISTs are checked against, e.g. when different cluster IDs are used per peer.
+// Only the cluster ID given to New is checked if not set.
+func (a *AdjRIBIn) SetClusterIDs(c *routingtable.ClusterIDs) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.clusterIDs = c
+}
+
+// Revalidate recomputes the origin validation state of all paths, e.g. after the VRPs changed.
+// Paths with a changed state are passed through the filter chain again. Returns the number of changed paths.
+func (a *AdjRIBIn) Revalidate() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.validator == nil {
+		return 0
+	}
+
+	changed := 0
+	for _, r := range a.rt.Dump() {
+		for _, path := range r.Paths() {
+			validated := a.validate(r.Prefix(), path)
+			if validated == path {
 				continue
 			}
 
-			if currentReject && !newReject {
-				for _, client := range a.clientManager.Clients() {
-					client.AddPath(route.Prefix(), newPath)
-				}
-
+			err := a.rt.Get(r.Prefix()).ReplacePath(path, validated)
+			if err != nil {
+				log.WithError(err).Errorf("Unable to update validation state of %s", r.Prefix().String())
 				continue
 			}
+			changed++
 
-			if !currentReject && newReject {
-				for _, client := range a.clientManager.Clients() {
-					client.RemovePath(route.Prefix(), newPath)
-				}
+			if a.ourASNsInPath(path) {
 				continue
 			}
 
-			if !currentReject && !newReject {
+			currentPath, currentReject := a.exportFilterChain.Process(r.Prefix(), path)
+			newPath, newReject := a.exportFilterChain.Process(r.Prefix(), validated)
+
+			// The state is not part of path comparison, so unchanged paths are replaced anyway to make it visible downstream
+			if !currentReject && !newReject && currentPath.Equal(newPath) {
 				for _, client := range a.clientManager.Clients() {
-					if !currentPath.Equal(newPath) {
-						client.ReplacePath(route.Prefix(), currentPath, newPath)
-					}
+					client.ReplacePath(r.Prefix(), currentPath, newPath)
 				}
+				continue
 			}
+
+			a.propagateChange(r.Prefix(), currentPath, currentReject, newPath, newReject)
 		}
 	}
 
-	a.exportFilterChain = c
+	return changed
+}
+
+// ValidationStateCounts gets the number of paths per origin validation state
+func (a *AdjRIBIn) ValidationStateCounts() map[vrp.ValidationState]uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ret := make(map[vrp.ValidationState]uint64)
+	for _, r := range a.rt.Dump() {
+		for _, p := range r.Paths() {
+			ret[p.BGPPath.ValidationState]++
+		}
+	}
+
+	return ret
+}
+
+// validate returns p with its origin validation state set. p is copied if the state changed.
+func (a *AdjRIBIn) validate(pfx *net.Prefix, p *route.Path) *route.Path {
+	if a.validator == nil {
+		return p
+	}
+
+	s := a.validator.Validate(pfx, p)
+	if s == p.BGPPath.ValidationState {
+		return p
+	}
+
+	bgpPath := *p.BGPPath
+	bgpPath.ValidationState = s
+
+	cp := *p
+	cp.BGPPath = &bgpPath
+	return &cp
+}
+
+// propagateChange informs clients about a path changed by filtering or validation
+func (a *AdjRIBIn) propagateChange(pfx *net.Prefix, currentPath *route.Path, currentReject bool, newPath *route.Path, newReject bool) {
+	if currentReject && newReject {
+		return
+	}
+
+	if currentReject && !newReject {
+		for _, client := range a.clientManager.Clients() {
+			client.AddPath(pfx, newPath)
+		}
+
+		return
+	}
+
+	if !currentReject && newReject {
+		for _, client := range a.clientManager.Clients() {
+			client.RemovePath(pfx, currentPath)
+		}
+		return
+	}
+
+	for _, client := range a.clientManager.Clients() {
+		if !currentPath.Equal(newPath) {
+			client.ReplacePath(pfx, currentPath, newPath)
+		}
+	}
 }
 
 func (a *AdjRIBIn) ReplacePath(pfx *net.Prefix, old *route.Path, new *route.Path) {
@@ -146,25 +278,44 @@ func (a *AdjRIBIn) AddPath(pfx *net.Prefix, p *route.Path) error {
 	return a.addPath(pfx, p)
 }
 
-// addPath replaces the path for prefix `pfx`. If the prefix doesn't exist it is added.
-func (a *AdjRIBIn) addPath(pfx *net.Prefix, p *route.Path) error {
+// reflectionLoop checks if a path has been reflected back to us
+func (a *AdjRIBIn) reflectionLoop(p *route.Path) bool {
 	// RFC4456 Sect. 8: Ignore route with our RouterID as OriginatorID
-	if p.BGPPath.BGPPathA.OriginatorID == a.routerID {
-		return nil
+	if !a.skipOriginatorIDCheck && p.BGPPath.BGPPathA.OriginatorID == a.routerID {
+		return true
 	}
 
 	// RFC4456 Sect. 8: Ignore routes which contain our ClusterID in their ClusterList
-	if p.BGPPath.ClusterList != nil && len(*p.BGPPath.ClusterList) > 0 {
-		for _, cid := range *p.BGPPath.ClusterList {
-			if cid == a.clusterID {
-				return nil
-			}
+	if a.skipClusterListCheck || p.BGPPath.ClusterList == nil {
+		return false
+	}
+
+	for _, cid := range *p.BGPPath.ClusterList {
+		if cid == a.clusterID || (a.clusterIDs != nil && a.clusterIDs.Contains(cid)) {
+			return true
 		}
 	}
 
+	return false
+}
+
+// addPath replaces the path for prefix `pfx`. If the prefix doesn't exist it is added.
+func (a *AdjRIBIn) addPath(pfx *net.Prefix, p *route.Path) error {
+	if a.reflectionLoop(p) {
+		return nil
+	}
+
+	p = a.validate(pfx, p)
+
 	if a.addPathRX {
+		// A refreshed path replaces its stale version
+		if a.unmarkStale(pfx, p) {
+			a.removePathsFromClients(pfx, a.removePathsWithID(pfx, p.BGPPath.PathIdentifier))
+		}
+
 		a.rt.AddPath(pfx, p)
 	} else {
+		a.unmarkStale(pfx, p)
 		oldPaths := a.rt.ReplacePath(pfx, p)
 		a.removePathsFromClients(pfx, oldPaths)
 	}
@@ -190,11 +341,18 @@ func (a *AdjRIBIn) ourASNsInPath(p *route.Path) bool {
 		return false
 	}
 
+	occurrences := make(map[uint32]uint8)
 	for _, pathSegment := range *p.BGPPath.ASPath {
 		for _, asn := range pathSegment.ASNs {
-			if a.contributingASNs.IsContributingASN(asn) {
+			if !a.contributingASNs.IsContributingASN(asn) {
+				continue
+			}
+
+			if occurrences[asn] == a.allowASIn {
 				return true
 			}
+
+			occurrences[asn]++
 		}
 	}
 
@@ -226,6 +384,7 @@ func (a *AdjRIBIn) removePath(pfx *net.Prefix, p *route.Path) bool {
 		}
 
 		a.rt.RemovePath(pfx, path)
+		a.unmarkStale(pfx, path)
 		removed = append(removed, path)
 	}
 
@@ -233,6 +392,98 @@ func (a *AdjRIBIn) removePath(pfx *net.Prefix, p *route.Path) bool {
 	return true
 }
 
+// MarkStale marks all paths as stale, e.g. when the session to a graceful restart capable peer was lost (RFC4724)
+func (a *AdjRIBIn) MarkStale() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stale = make(map[net.Prefix]map[uint32]struct{})
+	for _, r := range a.rt.Dump() {
+		ids := make(map[uint32]struct{})
+		for _, p := range r.Paths() {
+			ids[p.BGPPath.PathIdentifier] = struct{}{}
+		}
+
+		a.stale[*r.Prefix()] = ids
+	}
+}
+
+// RemoveStale removes all paths which have not been refreshed since MarkStale was called. Returns the number of removed paths.
+func (a *AdjRIBIn) RemoveStale() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	removed := 0
+	for pfx, ids := range a.stale {
+		pfx := pfx
+		for id := range ids {
+			paths := a.removePathsWithID(&pfx, id)
+			a.removePathsFromClients(&pfx, paths)
+			removed += len(paths)
+		}
+	}
+
+	a.stale = nil
+	return removed
+}
+
+// StaleCount returns the number of paths marked stale
+func (a *AdjRIBIn) StaleCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	n := 0
+	for _, ids := range a.stale {
+		n += len(ids)
+	}
+
+	return n
+}
+
+// unmarkStale clears the stale mark of a path being replaced or withdrawn. Returns if the path was stale.
+func (a *AdjRIBIn) unmarkStale(pfx *net.Prefix, p *route.Path) bool {
+	ids, ok := a.stale[*pfx]
+	if !ok {
+		return false
+	}
+
+	// Without ADD-PATH a path replaces whatever was known for the prefix
+	if !a.addPathRX {
+		delete(a.stale, *pfx)
+		return true
+	}
+
+	if _, ok := ids[p.BGPPath.PathIdentifier]; !ok {
+		return false
+	}
+
+	delete(ids, p.BGPPath.PathIdentifier)
+	if len(ids) == 0 {
+		delete(a.stale, *pfx)
+	}
+
+	return true
+}
+
+func (a *AdjRIBIn) removePathsWithID(pfx *net.Prefix, pathID uint32) []*route.Path {
+	r := a.rt.Get(pfx)
+	if r == nil {
+		return nil
+	}
+
+	removed := make([]*route.Path, 0, 1)
+	for _, p := range r.Paths() {
+		if p.BGPPath.PathIdentifier != pathID {
+			continue
+		}
+
+		a.rt.RemovePath(pfx, p)
+		removed = append(removed, p)
+	}
+
+	return removed
+}
+
 func (a *AdjRIBIn) removePathsFromClients(pfx *net.Prefix, paths []*route.Path) {
 	for _, path := range paths {
 		path, reject := a.exportFilterChain.Process(pfx, path)
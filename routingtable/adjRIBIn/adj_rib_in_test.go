@@ -5,9 +5,12 @@ import (
 
 	"github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/bio-routing/bio-rd/routingtable/locRIB"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -199,6 +202,137 @@ func TestAddPath(t *testing.T) {
 	}
 }
 
+func TestReflectionChecks(t *testing.T) {
+	routerID := net.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32()
+	clusterID := net.IPv4FromOctets(2, 2, 2, 2).Ptr().ToUint32()
+	otherClusterID := net.IPv4FromOctets(3, 3, 3, 3).Ptr().ToUint32()
+
+	clusterIDs := routingtable.NewClusterIDs()
+	clusterIDs.Add(otherClusterID)
+
+	tests := []struct {
+		name              string
+		originatorIDCheck bool
+		clusterListCheck  bool
+		clusterIDs        *routingtable.ClusterIDs
+		originatorID      uint32
+		clusterList       types.ClusterList
+		expected          int64
+	}{
+		{
+			name:              "Our RouterID as OriginatorID",
+			originatorIDCheck: true,
+			clusterListCheck:  true,
+			originatorID:      routerID,
+			expected:          0,
+		},
+		{
+			name:             "Our RouterID as OriginatorID without check",
+			clusterListCheck: true,
+			originatorID:     routerID,
+			expected:         1,
+		},
+		{
+			name:              "Our ClusterID within ClusterList without check",
+			originatorIDCheck: true,
+			clusterList:       types.ClusterList{clusterID},
+			expected:          1,
+		},
+		{
+			name:              "Cluster ID of another peer within ClusterList",
+			originatorIDCheck: true,
+			clusterListCheck:  true,
+			clusterIDs:        clusterIDs,
+			clusterList:       types.ClusterList{100, otherClusterID},
+			expected:          0,
+		},
+		{
+			name:              "Unknown cluster IDs within ClusterList",
+			originatorIDCheck: true,
+			clusterListCheck:  true,
+			clusterIDs:        clusterIDs,
+			clusterList:       types.ClusterList{100, 200},
+			expected:          1,
+		},
+	}
+
+	for _, test := range tests {
+		a := New(filter.NewAcceptAllFilterChain(), routingtable.NewContributingASNs(), routerID, clusterID, false)
+		a.SetReflectionChecks(test.originatorIDCheck, test.clusterListCheck)
+		a.SetClusterIDs(test.clusterIDs)
+
+		a.AddPath(net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref:    100,
+					OriginatorID: test.originatorID,
+				},
+				ClusterList: &test.clusterList,
+			},
+		})
+
+		assert.Equalf(t, test.expected, a.RouteCount(), "Test %q", test.name)
+	}
+}
+
+func TestAllowASIn(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowASIn uint8
+		asns      []uint32
+		expected  bool
+	}{
+		{
+			name:     "Local AS rejected by default",
+			asns:     []uint32{65001, 65000},
+			expected: false,
+		},
+		{
+			name:      "Local AS once with allowas-in 1",
+			allowASIn: 1,
+			asns:      []uint32{65001, 65000},
+			expected:  true,
+		},
+		{
+			name:      "Local AS twice with allowas-in 1",
+			allowASIn: 1,
+			asns:      []uint32{65000, 65001, 65000},
+			expected:  false,
+		},
+		{
+			name:      "Local AS twice with allowas-in 2",
+			allowASIn: 2,
+			asns:      []uint32{65000, 65001, 65000},
+			expected:  true,
+		},
+	}
+
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	for _, test := range tests {
+		rib := locRIB.New("inet.0")
+		rib.GetContributingASNs().Add(65000)
+
+		a := New(filter.NewAcceptAllFilterChain(), rib.GetContributingASNs(), 1, 1, false)
+		a.SetAllowASIn(test.allowASIn)
+		a.Register(rib)
+
+		a.AddPath(pfx, &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref: 100,
+				},
+				ASPath: &types.ASPath{
+					{Type: types.ASSequence, ASNs: test.asns},
+				},
+			},
+		})
+
+		assert.Equalf(t, test.expected, rib.Get(pfx) != nil, "Test %q", test.name)
+	}
+}
+
 func TestRemovePath(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -474,3 +608,209 @@ func TestUnregister(t *testing.T) {
 	assert.Equal(t, &routingtable.RemovePathParams{Pfx: pfxs[0], Path: paths[1]}, r[1], "Withdraw 2")
 	assert.Equal(t, &routingtable.RemovePathParams{Pfx: pfxs[1], Path: paths[2]}, r[2], "Withdraw 3")
 }
+
+func TestRemoveStale(t *testing.T) {
+	routerID := net.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32()
+	clusterID := net.IPv4FromOctets(2, 2, 2, 2).Ptr().ToUint32()
+
+	pfxA := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pfxB := net.NewPfx(net.IPv4FromOctets(11, 0, 0, 0), 8).Ptr()
+
+	path := func(pathID uint32, localPref uint32) *route.Path {
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				PathIdentifier: pathID,
+				BGPPathA: &route.BGPPathA{
+					LocalPref: localPref,
+					NextHop:   net.IPv4FromOctets(20, 0, 0, 0).Ptr(),
+					Source:    net.IPv4FromOctets(20, 0, 0, 0).Ptr(),
+				},
+			},
+		}
+	}
+
+	type pfxPath struct {
+		pfx  *net.Prefix
+		path *route.Path
+	}
+
+	tests := []struct {
+		name            string
+		addPath         bool
+		before          []pfxPath
+		refreshed       []pfxPath
+		withdrawn       []pfxPath
+		expectedStale   int
+		expectedRemoved int
+		expected        []*route.Route
+	}{
+		{
+			name: "Nothing refreshed",
+			before: []pfxPath{
+				{pfxA, path(0, 100)},
+				{pfxB, path(0, 100)},
+			},
+			expectedStale:   2,
+			expectedRemoved: 2,
+			expected:        []*route.Route{},
+		},
+		{
+			name: "One prefix refreshed, one withdrawn",
+			before: []pfxPath{
+				{pfxA, path(0, 100)},
+				{pfxB, path(0, 100)},
+			},
+			refreshed: []pfxPath{
+				{pfxA, path(0, 200)},
+			},
+			withdrawn: []pfxPath{
+				{pfxB, nil},
+			},
+			expectedStale:   0,
+			expectedRemoved: 0,
+			expected: []*route.Route{
+				route.NewRoute(pfxA, path(0, 200)),
+			},
+		},
+		{
+			name:    "ADD-PATH with one of two paths refreshed",
+			addPath: true,
+			before: []pfxPath{
+				{pfxA, path(1, 100)},
+				{pfxA, path(2, 100)},
+			},
+			refreshed: []pfxPath{
+				{pfxA, path(2, 200)},
+			},
+			expectedStale:   1,
+			expectedRemoved: 1,
+			expected: []*route.Route{
+				route.NewRoute(pfxA, path(2, 200)),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		a := New(filter.NewAcceptAllFilterChain(), routingtable.NewContributingASNs(), routerID, clusterID, test.addPath)
+		mc := routingtable.NewRTMockClient()
+		a.clientManager.RegisterWithOptions(mc, routingtable.ClientOptions{BestOnly: true})
+
+		for _, x := range test.before {
+			a.AddPath(x.pfx, x.path)
+		}
+
+		a.MarkStale()
+
+		for _, x := range test.refreshed {
+			a.AddPath(x.pfx, x.path)
+		}
+
+		for _, x := range test.withdrawn {
+			a.RemovePath(x.pfx, x.path)
+		}
+
+		assert.Equalf(t, test.expectedStale, a.StaleCount(), "Test %q", test.name)
+		assert.Equalf(t, test.expectedRemoved, a.RemoveStale(), "Test %q", test.name)
+		assert.Equalf(t, 0, a.StaleCount(), "Test %q", test.name)
+
+		routes := make([]*route.Route, 0)
+		for _, r := range a.rt.Dump() {
+			if len(r.Paths()) > 0 {
+				routes = append(routes, r)
+			}
+		}
+		assert.Equalf(t, test.expected, routes, "Test %q", test.name)
+	}
+}
+
+type mockValidator struct {
+	state vrp.ValidationState
+}
+
+func (m *mockValidator) Validate(pfx *net.Prefix, p *route.Path) vrp.ValidationState {
+	return m.state
+}
+
+func TestRevalidate(t *testing.T) {
+	routerID := net.IPv4FromOctets(1, 1, 1, 1).Ptr().ToUint32()
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+
+	rejectInvalid := filter.Chain{
+		filter.NewFilter("reject-invalid", []*filter.Term{
+			filter.NewTerm("invalid", []*filter.TermCondition{
+				filter.NewTermConditionWithValidationStates(vrp.Invalid),
+			}, []actions.Action{
+				actions.NewRejectAction(),
+			}),
+		}),
+	}
+
+	tests := []struct {
+		name            string
+		before          vrp.ValidationState
+		after           vrp.ValidationState
+		expectedChanged int
+		expectedPaths   int
+		expectedCounts  map[vrp.ValidationState]uint64
+	}{
+		{
+			name:            "Valid becomes invalid",
+			before:          vrp.Valid,
+			after:           vrp.Invalid,
+			expectedChanged: 1,
+			expectedPaths:   0,
+			expectedCounts:  map[vrp.ValidationState]uint64{vrp.Invalid: 1},
+		},
+		{
+			name:            "Invalid becomes not found",
+			before:          vrp.Invalid,
+			after:           vrp.NotFound,
+			expectedChanged: 1,
+			expectedPaths:   1,
+			expectedCounts:  map[vrp.ValidationState]uint64{vrp.NotFound: 1},
+		},
+		{
+			name:            "Unchanged",
+			before:          vrp.Valid,
+			after:           vrp.Valid,
+			expectedChanged: 0,
+			expectedPaths:   1,
+			expectedCounts:  map[vrp.ValidationState]uint64{vrp.Valid: 1},
+		},
+	}
+
+	for _, test := range tests {
+		v := &mockValidator{state: test.before}
+		a := New(rejectInvalid, routingtable.NewContributingASNs(), routerID, 0, false)
+		a.SetOriginValidator(v)
+
+		rib := locRIB.New("inet.0")
+		a.Register(rib)
+
+		a.AddPath(pfx, &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref: 100,
+					NextHop:   net.IPv4FromOctets(20, 0, 0, 0).Ptr(),
+					Source:    net.IPv4FromOctets(20, 0, 0, 0).Ptr(),
+				},
+				ASPath: &types.ASPath{},
+			},
+		})
+
+		v.state = test.after
+		assert.Equalf(t, test.expectedChanged, a.Revalidate(), "Test %q", test.name)
+		assert.Equalf(t, test.expectedCounts, a.ValidationStateCounts(), "Test %q", test.name)
+
+		paths := 0
+		for _, r := range rib.Dump() {
+			for _, p := range r.Paths() {
+				assert.Equalf(t, test.after, p.BGPPath.ValidationState, "Test %q", test.name)
+				paths++
+			}
+		}
+		assert.Equalf(t, test.expectedPaths, paths, "Test %q", test.name)
+	}
+}
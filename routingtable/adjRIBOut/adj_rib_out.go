@@ -12,7 +12,6 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/routingtable/locRIB"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 )
 
 // AdjRIBOut represents an Adjacency RIB Out with BGP add path
@@ -25,6 +24,7 @@ type AdjRIBOut struct {
 	pathIDManager            *pathIDManager
 	exportFilterChain        filter.Chain
 	exportFilterChainPending filter.Chain
+	exportCache              *ExportCache
 	mu                       sync.RWMutex
 }
 
@@ -74,14 +74,40 @@ func (a *AdjRIBOut) bgpChecks(pfx *bnet.Prefix, p *route.Path) (retPath *route.P
 	}
 
 	// Don't export routes learned via iBGP to an iBGP neighbor which is NOT a route reflection client
-	if !p.BGPPath.BGPPathA.EBGP && a.neighbor.IBGP && !a.neighbor.RouteReflectorClient {
+	if !p.BGPPath.BGPPathA.EBGP && !p.BGPPath.Local && a.neighbor.IBGP && !a.neighbor.RouteReflectorClient {
+		return nil, false
+	}
+
+	// Don't reflect routes learned from a client to clients of the same cluster if client-to-client reflection is disabled
+	if a.neighbor.RouteReflectorClient && a.neighbor.NoClientToClientReflection && p.BGPPath.ClientClusterID == a.neighbor.ClusterID {
 		return nil, false
 	}
 
 	// If the neighbor is an eBGP peer and not a Route Server client modify ASPath and Next Hop
 	p = p.Copy()
+
+	// BGPPathA is shared with the Loc-RIB path and other paths
+	pa := *p.BGPPath.BGPPathA
+	p.BGPPath.BGPPathA = &pa
+
 	if !a.neighbor.IBGP && !a.neighbor.RouteServerClient {
-		p.BGPPath.Prepend(a.neighbor.LocalASN, 1)
+		p.BGPPath.ScrubPrivateASNs(a.neighbor.RemovePrivateAS, a.neighbor.LocalASN, a.neighbor.PeerASN)
+		if a.neighbor.ASOverride {
+			p.BGPPath.OverrideASN(a.neighbor.PeerASN, a.neighbor.LocalASN)
+		}
+
+		if a.neighbor.MigrationASN == 0 || !a.neighbor.ReplaceAS {
+			p.BGPPath.Prepend(a.neighbor.LocalASN, 1)
+		}
+
+		if a.neighbor.MigrationASN != 0 {
+			p.BGPPath.Prepend(a.neighbor.MigrationASN, 1)
+		}
+		p.BGPPath.BGPPathA.NextHop = a.neighbor.LocalAddress
+	}
+
+	// Locally originated paths have no next hop of their own
+	if p.BGPPath.Local || a.nextHopSelf(p) {
 		p.BGPPath.BGPPathA.NextHop = a.neighbor.LocalAddress
 	}
 
@@ -116,18 +142,31 @@ func (a *AdjRIBOut) bgpChecks(pfx *bnet.Prefix, p *route.Path) (retPath *route.P
 	return p, true
 }
 
+// nextHopSelf checks if the next hop of p is replaced by the local address as configured for the neighbor
+func (a *AdjRIBOut) nextHopSelf(p *route.Path) bool {
+	switch a.neighbor.NextHopSelf {
+	case route.NextHopSelfForce:
+		return true
+	case route.NextHopSelfEnabled:
+		reflected := a.neighbor.IBGP && !p.BGPPath.BGPPathA.EBGP && !p.BGPPath.Local
+		return !reflected
+	}
+
+	return false
+}
+
 func (a *AdjRIBOut) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
 	return a.AddPath(pfx, p)
 }
 
 // AddPath adds path p to prefix `pfx`
 func (a *AdjRIBOut) AddPath(pfx *bnet.Prefix, p *route.Path) error {
-	p, propagate := a.bgpChecks(pfx, p)
+	checked, propagate := a.bgpChecks(pfx, p)
 	if !propagate {
 		return nil
 	}
 
-	p, reject := a.exportFilterChain.Process(pfx, p)
+	p, reject := a.exportFilter(pfx, p, checked)
 	if reject {
 		return nil
 	}
@@ -140,6 +179,35 @@ func (a *AdjRIBOut) AddPath(pfx *bnet.Prefix, p *route.Path) error {
 	return a.addPath(pfx, p)
 }
 
+// exportFilter processes the export filter chain for Loc-RIB path p after the BGP checks turned it into checked.
+// Results are taken from and added to the export cache if one is set.
+func (a *AdjRIBOut) exportFilter(pfx *bnet.Prefix, p *route.Path, checked *route.Path) (*route.Path, bool) {
+	a.mu.RLock()
+	c := a.exportCache
+	a.mu.RUnlock()
+
+	if c == nil {
+		return a.exportFilterChain.Process(pfx, checked)
+	}
+
+	if exported, reject, ok := c.get(pfx, p); ok {
+		return exported, reject
+	}
+
+	exported, reject := a.exportFilterChain.Process(pfx, checked)
+	c.set(pfx, p, exported, reject)
+	return exported, reject
+}
+
+// SetExportCache sets the cache export filter results are shared with other AdjRIBOuts through.
+// All AdjRIBOuts sharing a cache must have the same neighbor settings apart from the neighbor address and the same export filter chain.
+func (a *AdjRIBOut) SetExportCache(c *ExportCache) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.exportCache = c
+}
+
 func (a *AdjRIBOut) addPath(pfx *bnet.Prefix, p *route.Path) error {
 	if a.addPathTX {
 		pathID, err := a.pathIDManager.addPath(p)
@@ -169,6 +237,10 @@ func (a *AdjRIBOut) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if a.exportCache != nil {
+		a.exportCache.remove(pfx, p)
+	}
+
 	return a.removePath(pfx, p)
 }
 
@@ -294,6 +366,9 @@ func (a *AdjRIBOut) ReplaceFilterChain(c filter.Chain) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Results of the old filter chain can't be shared anymore
+	a.exportCache = nil
+
 	a.exportFilterChainPending = c
 	a.rib.RefreshClient(a)
 	a.exportFilterChain = c
@@ -947,6 +947,253 @@ func TestBestPathOnlyRRClient(t *testing.T) {
 	}
 }
 
+func TestNoClientToClientReflection(t *testing.T) {
+	clusterID := net.IPv4FromOctets(2, 2, 2, 2).Ptr().ToUint32()
+
+	tests := []struct {
+		name            string
+		clientClusterID uint32
+		expectedCount   int64
+	}{
+		{
+			name:          "Route from non-client",
+			expectedCount: 1,
+		},
+		{
+			name:            "Route from client of the same cluster",
+			clientClusterID: clusterID,
+			expectedCount:   0,
+		},
+		{
+			name:            "Route from client of another cluster",
+			clientClusterID: net.IPv4FromOctets(3, 3, 3, 3).Ptr().ToUint32(),
+			expectedCount:   1,
+		},
+	}
+
+	for _, test := range tests {
+		a := New(nil, &routingtable.Neighbor{
+			Type:                       route.BGPPathType,
+			LocalAddress:               net.IPv4FromOctets(127, 0, 0, 1).Ptr(),
+			Address:                    net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+			IBGP:                       true,
+			LocalASN:                   41981,
+			RouteReflectorClient:       true,
+			ClusterID:                  clusterID,
+			NoClientToClientReflection: true,
+		}, filter.NewAcceptAllFilterChain(), false)
+
+		a.AddPath(net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					Source:  net.IPv4FromOctets(127, 0, 0, 3).Ptr(),
+					NextHop: net.IPv4FromOctets(127, 0, 0, 3).Ptr(),
+				},
+				ASPath:          &types.ASPath{},
+				ClientClusterID: test.clientClusterID,
+			},
+		})
+
+		assert.Equalf(t, test.expectedCount, a.RouteCount(), "Test %q", test.name)
+	}
+}
+
+func TestLocalPathIBGP(t *testing.T) {
+	localAddr := net.IPv4FromOctets(127, 0, 0, 1).Ptr()
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	pa := &route.BGPPathA{
+		Source:  net.IPv4(0).Ptr(),
+		NextHop: net.IPv4(0).Ptr(),
+	}
+
+	a := New(nil, &routingtable.Neighbor{
+		Type:         route.BGPPathType,
+		LocalAddress: localAddr,
+		Address:      net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+		IBGP:         true,
+		LocalASN:     41981,
+	}, filter.NewAcceptAllFilterChain(), false)
+
+	a.AddPath(pfx, &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: pa,
+			ASPath:   &types.ASPath{},
+			Local:    true,
+		},
+	})
+
+	assert.Equal(t, int64(1), a.RouteCount(), "Locally originated paths must be sent to iBGP peers")
+	assert.Equal(t, localAddr, a.Dump()[0].BestPath().BGPPath.BGPPathA.NextHop)
+	assert.Equal(t, net.IPv4(0).Ptr(), pa.NextHop, "Loc-RIB path must not be modified")
+}
+
+func TestASOverride(t *testing.T) {
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	asPath := types.ASPath{
+		{Type: types.ASSequence, ASNs: []uint32{65001, 3320}},
+	}
+
+	a := New(nil, &routingtable.Neighbor{
+		Type:         route.BGPPathType,
+		LocalAddress: net.IPv4FromOctets(127, 0, 0, 1).Ptr(),
+		Address:      net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+		LocalASN:     41981,
+		PeerASN:      65001,
+		ASOverride:   true,
+	}, filter.NewAcceptAllFilterChain(), false)
+
+	a.AddPath(pfx, &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				Source:  net.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				NextHop: net.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				EBGP:    true,
+			},
+			ASPath:    &asPath,
+			ASPathLen: 2,
+		},
+	})
+
+	expected := types.ASPath{
+		{Type: types.ASSequence, ASNs: []uint32{41981, 41981, 3320}},
+	}
+	assert.Equal(t, expected, *a.Dump()[0].BestPath().BGPPath.ASPath)
+	assert.Equal(t, []uint32{65001, 3320}, asPath[0].ASNs, "Loc-RIB path must not be modified")
+}
+
+func TestLocalASMigration(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration uint32
+		replaceAS bool
+		expected  []uint32
+	}{
+		{
+			name:     "Without migration",
+			expected: []uint32{41981, 3320},
+		},
+		{
+			name:      "Migration AS and local AS",
+			migration: 65100,
+			expected:  []uint32{65100, 41981, 3320},
+		},
+		{
+			name:      "Replace AS",
+			migration: 65100,
+			replaceAS: true,
+			expected:  []uint32{65100, 3320},
+		},
+	}
+
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	for _, test := range tests {
+		a := New(nil, &routingtable.Neighbor{
+			Type:         route.BGPPathType,
+			LocalAddress: net.IPv4FromOctets(127, 0, 0, 1).Ptr(),
+			Address:      net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+			LocalASN:     41981,
+			PeerASN:      65001,
+			MigrationASN: test.migration,
+			ReplaceAS:    test.replaceAS,
+		}, filter.NewAcceptAllFilterChain(), false)
+
+		a.AddPath(pfx, &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					Source:  net.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+					NextHop: net.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+					EBGP:    true,
+				},
+				ASPath: &types.ASPath{
+					{Type: types.ASSequence, ASNs: []uint32{3320}},
+				},
+				ASPathLen: 1,
+			},
+		})
+
+		expected := types.ASPath{
+			{Type: types.ASSequence, ASNs: test.expected},
+		}
+		assert.Equal(t, expected, *a.Dump()[0].BestPath().BGPPath.ASPath, "Test %q", test.name)
+	}
+}
+
+func TestNextHopSelf(t *testing.T) {
+	localAddr := net.IPv4FromOctets(127, 0, 0, 1).Ptr()
+	nextHop := net.IPv4FromOctets(192, 0, 2, 1).Ptr()
+
+	tests := []struct {
+		name                 string
+		mode                 route.NextHopSelfMode
+		routeReflectorClient bool
+		ebgpPath             bool
+		expected             *net.IP
+	}{
+		{
+			name:     "Disabled, path learned via eBGP",
+			mode:     route.NextHopSelfDisabled,
+			ebgpPath: true,
+			expected: nextHop,
+		},
+		{
+			name:     "Enabled, path learned via eBGP",
+			mode:     route.NextHopSelfEnabled,
+			ebgpPath: true,
+			expected: localAddr,
+		},
+		{
+			name:                 "Enabled, reflected path",
+			mode:                 route.NextHopSelfEnabled,
+			routeReflectorClient: true,
+			expected:             nextHop,
+		},
+		{
+			name:                 "Force, reflected path",
+			mode:                 route.NextHopSelfForce,
+			routeReflectorClient: true,
+			expected:             localAddr,
+		},
+	}
+
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	for _, test := range tests {
+		a := New(nil, &routingtable.Neighbor{
+			Type:                 route.BGPPathType,
+			LocalAddress:         localAddr,
+			Address:              net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+			IBGP:                 true,
+			LocalASN:             41981,
+			PeerASN:              41981,
+			RouteReflectorClient: test.routeReflectorClient,
+			NextHopSelf:          test.mode,
+		}, filter.NewAcceptAllFilterChain(), false)
+
+		pa := &route.BGPPathA{
+			Source:  nextHop,
+			NextHop: nextHop,
+			EBGP:    test.ebgpPath,
+		}
+		a.AddPath(pfx, &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: pa,
+				ASPath:   &types.ASPath{},
+			},
+		})
+
+		if !assert.Equal(t, int64(1), a.RouteCount(), "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equal(t, test.expected, a.Dump()[0].BestPath().BGPPath.BGPPathA.NextHop, "Test %q", test.name)
+		assert.Equal(t, nextHop, pa.NextHop, "Test %q: Loc-RIB path must not be modified", test.name)
+	}
+}
+
 /*
  * Test for AddPath capable peer / AdjRIBOut
  */
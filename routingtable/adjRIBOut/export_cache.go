@@ -0,0 +1,78 @@
+package adjRIBOut
+
+import (
+	"sync"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// ExportCache shares the results of export filtering between AdjRIBOuts of neighbors with identical outbound settings
+// (e.g. members of a BGP peer group), so the export filter chain is processed only once per path for all of them.
+// Results are kept until the path is withdrawn.
+type ExportCache struct {
+	results map[bnet.Prefix]map[*route.Path]exportResult
+	mu      sync.Mutex
+}
+
+type exportResult struct {
+	path   *route.Path
+	reject bool
+}
+
+// NewExportCache creates a new export cache
+func NewExportCache() *ExportCache {
+	return &ExportCache{
+		results: make(map[bnet.Prefix]map[*route.Path]exportResult),
+	}
+}
+
+// Len returns the number of cached results
+func (c *ExportCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, paths := range c.results {
+		n += len(paths)
+	}
+
+	return n
+}
+
+// get gets the result of filtering p. The returned path is a copy owned by the caller.
+func (c *ExportCache) get(pfx *bnet.Prefix, p *route.Path) (*route.Path, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.results[*pfx][p]
+	if !ok {
+		return nil, false, false
+	}
+
+	return r.path.Copy(), r.reject, true
+}
+
+func (c *ExportCache) set(pfx *bnet.Prefix, p *route.Path, exported *route.Path, reject bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.results[*pfx]; !ok {
+		c.results[*pfx] = make(map[*route.Path]exportResult)
+	}
+
+	c.results[*pfx][p] = exportResult{
+		path:   exported.Copy(),
+		reject: reject,
+	}
+}
+
+func (c *ExportCache) remove(pfx *bnet.Prefix, p *route.Path) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.results[*pfx], p)
+	if len(c.results[*pfx]) == 0 {
+		delete(c.results, *pfx)
+	}
+}
@@ -0,0 +1,88 @@
+package adjRIBOut
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
+	"github.com/bio-routing/bio-rd/routingtable/filter"
+	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingAction struct {
+	calls int
+}
+
+func (a *countingAction) Do(p *net.Prefix, pa *route.Path) actions.Result {
+	a.calls++
+
+	modified := pa.Copy()
+	modified.BGPPath.BGPPathA.MED = 100
+	return actions.Result{Path: modified}
+}
+
+func (a *countingAction) Equal(x actions.Action) bool {
+	return a == x
+}
+
+func TestExportCache(t *testing.T) {
+	action := &countingAction{}
+	chain := filter.Chain{
+		filter.NewFilter("count", []*filter.Term{
+			filter.NewTerm("count", nil, []actions.Action{action}),
+		}),
+	}
+
+	cache := NewExportCache()
+	ribOuts := make([]*AdjRIBOut, 0)
+	for i := uint8(2); i < 5; i++ {
+		a := New(nil, &routingtable.Neighbor{
+			Type:         route.BGPPathType,
+			LocalAddress: net.IPv4FromOctets(127, 0, 0, 1).Ptr(),
+			Address:      net.IPv4FromOctets(127, 0, 0, i).Ptr(),
+			IBGP:         true,
+			LocalASN:     41981,
+		}, chain, true)
+		a.SetExportCache(cache)
+		ribOuts = append(ribOuts, a)
+	}
+
+	pfx := net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()
+	p := &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				EBGP:    true,
+				Source:  net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+				NextHop: net.IPv4FromOctets(127, 0, 0, 2).Ptr(),
+			},
+			ASPath: &types.ASPath{},
+		},
+	}
+
+	for _, a := range ribOuts {
+		a.AddPath(pfx, p)
+	}
+
+	assert.Equal(t, 1, action.calls, "Filter chain processed more than once")
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, int64(0), ribOuts[0].RouteCount(), "Path advertised to its source")
+	for _, a := range ribOuts[1:] {
+		assert.Equal(t, uint32(100), a.Get(pfx).Paths()[0].BGPPath.BGPPathA.MED)
+	}
+
+	// Path IDs are assigned per AdjRIBOut and must not leak into the cache
+	assert.True(t, ribOuts[1].Get(pfx).Paths()[0] != ribOuts[2].Get(pfx).Paths()[0], "Path shared between AdjRIBOuts")
+
+	for _, a := range ribOuts {
+		a.RemovePath(pfx, p)
+	}
+
+	assert.Equal(t, 0, cache.Len())
+	for _, a := range ribOuts {
+		assert.Equal(t, int64(0), a.RouteCount())
+	}
+}
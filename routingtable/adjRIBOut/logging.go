@@ -0,0 +1,7 @@
+package adjRIBOut
+
+import (
+	"github.com/bio-routing/bio-rd/util/logging"
+)
+
+var log = logging.Subsystem("rib")
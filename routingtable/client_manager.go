@@ -2,6 +2,8 @@ package routingtable
 
 import (
 	"sync"
+
+	"github.com/bio-routing/bio-rd/route"
 )
 
 type ClientManagerMaster interface {
@@ -13,6 +15,10 @@ type ClientOptions struct {
 	BestOnly bool
 	EcmpOnly bool
 	MaxPaths uint
+
+	// IGPCosts selects the best path for the client using its own view of the interior costs to the next hops instead of
+	// the one of the RIB, e.g. for optimal route reflection (RFC9107). The client only receives the best path.
+	IGPCosts route.IGPCosts
 }
 
 // GetMaxPaths calculates the maximum amount of wanted paths given that ecmpPaths paths exist
@@ -68,6 +74,19 @@ func (c *ClientManager) RegisterWithOptions(client RouteTableClient, opt ClientO
 	c.master.UpdateNewClient(client)
 }
 
+// SetOptions changes the options of a registered client. It returns false if the client is not registered.
+func (c *ClientManager) SetOptions(client RouteTableClient, opt ClientOptions) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.clients[client]; !ok {
+		return false
+	}
+
+	c.clients[client] = opt
+	return true
+}
+
 // Unregister unregisters a client
 func (c *ClientManager) Unregister(client RouteTableClient) bool {
 	c.mu.Lock()
@@ -0,0 +1,46 @@
+package routingtable
+
+import "sync"
+
+// ClusterIDs contains the route reflection cluster IDs in use to check CLUSTER_LISTs for possible routing loops (RFC4456 8).
+type ClusterIDs struct {
+	ids map[uint32]uint32
+	mu  sync.RWMutex
+}
+
+// NewClusterIDs creates an empty set of cluster IDs
+func NewClusterIDs() *ClusterIDs {
+	return &ClusterIDs{
+		ids: make(map[uint32]uint32),
+	}
+}
+
+// Add adds a cluster ID or increments the ref count of an existing one
+func (c *ClusterIDs) Add(id uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ids[id]++
+}
+
+// Remove decrements the ref count of a cluster ID and removes it once it is no longer used
+func (c *ClusterIDs) Remove(id uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ids[id] <= 1 {
+		delete(c.ids, id)
+		return
+	}
+
+	c.ids[id]--
+}
+
+// Contains checks if a cluster ID is in use
+func (c *ClusterIDs) Contains(id uint32) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.ids[id]
+	return ok
+}
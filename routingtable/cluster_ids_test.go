@@ -0,0 +1,55 @@
+package routingtable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterIDs(t *testing.T) {
+	c := NewClusterIDs()
+
+	tests := []struct {
+		name     string
+		run      func()
+		expected map[uint32]bool
+	}{
+		{
+			name:     "Empty",
+			run:      func() {},
+			expected: map[uint32]bool{100: false},
+		},
+		{
+			name:     "Add two IDs",
+			run:      func() { c.Add(100); c.Add(200) },
+			expected: map[uint32]bool{100: true, 200: true},
+		},
+		{
+			name:     "Add ID a second time",
+			run:      func() { c.Add(100) },
+			expected: map[uint32]bool{100: true, 200: true},
+		},
+		{
+			name:     "Remove ID added twice once",
+			run:      func() { c.Remove(100) },
+			expected: map[uint32]bool{100: true, 200: true},
+		},
+		{
+			name:     "Remove IDs",
+			run:      func() { c.Remove(100); c.Remove(200) },
+			expected: map[uint32]bool{100: false, 200: false},
+		},
+		{
+			name:     "Remove unknown ID",
+			run:      func() { c.Remove(300) },
+			expected: map[uint32]bool{300: false},
+		},
+	}
+
+	for _, test := range tests {
+		test.run()
+		for id, expected := range test.expected {
+			assert.Equalf(t, expected, c.Contains(id), "Test %q: cluster ID %d", test.name, id)
+		}
+	}
+}
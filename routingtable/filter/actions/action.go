@@ -17,3 +17,9 @@ type Result struct {
 	Reject    bool
 	Terminate bool
 }
+
+// copyBGPPathA gives a copied path its own copy of the cachable path attributes, which are shared with the original path
+func copyBGPPathA(pa *route.Path) {
+	a := *pa.BGPPath.BGPPathA
+	pa.BGPPath.BGPPathA = &a
+}
@@ -16,7 +16,7 @@ func NewAddCommunityAction(coms *types.Communities) *AddCommunityAction {
 	}
 }
 
-func (a *AddCommunityAction) Do(p net.Prefix, pa *route.Path) Result {
+func (a *AddCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
 	if pa.BGPPath == nil || len(*a.communities) == 0 {
 		return Result{Path: pa}
 	}
@@ -32,3 +32,14 @@ func (a *AddCommunityAction) Do(p net.Prefix, pa *route.Path) Result {
 
 	return Result{Path: modified}
 }
+
+// Equal compares actions
+func (a *AddCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *AddCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*AddCommunityAction).communities.String()
+}
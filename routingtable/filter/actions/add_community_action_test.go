@@ -54,7 +54,7 @@ func TestAddingCommunities(t *testing.T) {
 			}
 
 			a := NewAddCommunityAction(test.communities)
-			res := a.Do(net.Prefix{}, p)
+			res := a.Do(&net.Prefix{}, p)
 
 			assert.Equal(t, test.expected, res.Path.BGPPath.CommunitiesString())
 		})
@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// AddExtendedCommunityAction adds extended communities to a path
+type AddExtendedCommunityAction struct {
+	communities types.ExtendedCommunities
+}
+
+// NewAddExtendedCommunityAction creates a new AddExtendedCommunityAction
+func NewAddExtendedCommunityAction(coms types.ExtendedCommunities) *AddExtendedCommunityAction {
+	return &AddExtendedCommunityAction{
+		communities: coms,
+	}
+}
+
+func (a *AddExtendedCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || len(a.communities) == 0 {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	if modified.BGPPath.ExtendedCommunities == nil {
+		modified.BGPPath.ExtendedCommunities = &types.ExtendedCommunities{}
+	}
+
+	for _, c := range a.communities {
+		if !modified.BGPPath.ExtendedCommunities.ContainsAny(types.ExtendedCommunities{c}) {
+			*modified.BGPPath.ExtendedCommunities = append(*modified.BGPPath.ExtendedCommunities, c)
+		}
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *AddExtendedCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *AddExtendedCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*AddExtendedCommunityAction).communities.String()
+}
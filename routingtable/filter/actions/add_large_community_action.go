@@ -16,7 +16,7 @@ func NewAddLargeCommunityAction(coms *types.LargeCommunities) *AddLargeCommunity
 	}
 }
 
-func (a *AddLargeCommunityAction) Do(p net.Prefix, pa *route.Path) Result {
+func (a *AddLargeCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
 	if pa.BGPPath == nil || len(*a.communities) == 0 {
 		return Result{Path: pa}
 	}
@@ -29,3 +29,14 @@ func (a *AddLargeCommunityAction) Do(p net.Prefix, pa *route.Path) Result {
 	*modified.BGPPath.LargeCommunities = append(*modified.BGPPath.LargeCommunities, *a.communities...)
 	return Result{Path: modified}
 }
+
+// Equal compares actions
+func (a *AddLargeCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *AddLargeCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*AddLargeCommunityAction).communities.String()
+}
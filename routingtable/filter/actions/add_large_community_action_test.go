@@ -79,7 +79,7 @@ func TestAddingLargeCommunities(t *testing.T) {
 			}
 
 			a := NewAddLargeCommunityAction(test.communities)
-			res := a.Do(net.Prefix{}, p)
+			res := a.Do(&net.Prefix{}, p)
 
 			assert.Equal(t, test.expected, res.Path.BGPPath.LargeCommunitiesString())
 		})
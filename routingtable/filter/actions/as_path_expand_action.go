@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// ASPathExpandAction prepends the first ASN of the AS path. Export filters to eBGP peers are applied after the local ASN
+// has been prepended, so this prepends the own ASN there without the policy having to know it.
+type ASPathExpandAction struct {
+	times uint16
+}
+
+// NewASPathExpandAction creates an action prepending the first ASN of the AS path times
+func NewASPathExpandAction(times uint16) *ASPathExpandAction {
+	return &ASPathExpandAction{
+		times: times,
+	}
+}
+
+func (a *ASPathExpandAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || pa.BGPPath.ASPath == nil {
+		return Result{Path: pa}
+	}
+
+	path := *pa.BGPPath.ASPath
+	if len(path) == 0 || path[0].Type != types.ASSequence || len(path[0].ASNs) == 0 {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	modified.BGPPath.Prepend(path[0].ASNs[0], a.times)
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *ASPathExpandAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *ASPathExpandAction:
+	default:
+		return false
+	}
+
+	return a.times == b.(*ASPathExpandAction).times
+}
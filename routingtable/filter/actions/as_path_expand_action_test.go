@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestASPathExpand(t *testing.T) {
+	tests := []struct {
+		name         string
+		bgpPath      *route.BGPPath
+		expectedPath string
+	}{
+		{
+			name:         "Empty AS path",
+			bgpPath:      &route.BGPPath{ASPath: &types.ASPath{}},
+			expectedPath: "",
+		},
+		{
+			name: "Sequence",
+			bgpPath: &route.BGPPath{
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65000, 15169},
+					},
+				},
+			},
+			expectedPath: "65000 65000 65000 15169",
+		},
+		{
+			name: "Set",
+			bgpPath: &route.BGPPath{
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSet,
+						ASNs: []uint32{65000, 15169},
+					},
+				},
+			},
+			expectedPath: " (65000 15169)",
+		},
+	}
+
+	for _, test := range tests {
+		res := NewASPathExpandAction(2).Do(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), &route.Path{
+			BGPPath: test.bgpPath,
+		})
+
+		assert.Equal(t, test.expectedPath, res.Path.BGPPath.ASPath.String(), "Test %q", test.name)
+	}
+}
@@ -5,14 +5,21 @@ import (
 	"github.com/bio-routing/bio-rd/route"
 )
 
+// ASPathPrependAction prepends a sequence of ASNs to the AS path
 type ASPathPrependAction struct {
-	asn   uint32
+	asns  []uint32
 	times uint16
 }
 
+// NewASPathPrependAction creates an action prepending asn times
 func NewASPathPrependAction(asn uint32, times uint16) *ASPathPrependAction {
+	return NewASPathPrependSequenceAction([]uint32{asn}, times)
+}
+
+// NewASPathPrependSequenceAction creates an action prepending a sequence of ASNs times. The first ASN becomes the leftmost one.
+func NewASPathPrependSequenceAction(asns []uint32, times uint16) *ASPathPrependAction {
 	return &ASPathPrependAction{
-		asn:   asn,
+		asns:  asns,
 		times: times,
 	}
 }
@@ -22,8 +29,9 @@ func (a *ASPathPrependAction) Do(p *net.Prefix, pa *route.Path) Result {
 		return Result{Path: pa}
 	}
 
-	pa.BGPPath.Prepend(a.asn, a.times)
-	return Result{Path: pa}
+	modified := pa.Copy()
+	modified.BGPPath.PrependSequence(a.asns, a.times)
+	return Result{Path: modified}
 }
 
 // Equal compares actions
@@ -34,7 +42,7 @@ func (a *ASPathPrependAction) Equal(b Action) bool {
 		return false
 	}
 
-	if a.asn != b.(*ASPathPrependAction).asn {
+	if !equalASNs(a.asns, b.(*ASPathPrependAction).asns) {
 		return false
 	}
 
@@ -44,3 +52,17 @@ func (a *ASPathPrependAction) Equal(b Action) bool {
 
 	return true
 }
+
+func equalASNs(a []uint32, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
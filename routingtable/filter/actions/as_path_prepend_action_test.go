@@ -69,3 +69,47 @@ func TestAppendPath(t *testing.T) {
 		})
 	}
 }
+
+func TestPrependSequence(t *testing.T) {
+	tests := []struct {
+		name         string
+		asns         []uint32
+		times        uint16
+		bgpPath      *route.BGPPath
+		expectedPath string
+	}{
+		{
+			name:  "Sequence once",
+			asns:  []uint32{65001, 65002},
+			times: 1,
+			bgpPath: &route.BGPPath{
+				ASPath: &types.ASPath{
+					types.ASPathSegment{
+						Type: types.ASSequence,
+						ASNs: []uint32{15169},
+					},
+				},
+				ASPathLen: 1,
+			},
+			expectedPath: "65001 65002 15169",
+		},
+		{
+			name:         "Sequence twice to empty path",
+			asns:         []uint32{65001, 65002},
+			times:        2,
+			bgpPath:      &route.BGPPath{},
+			expectedPath: "65001 65002 65001 65002",
+		},
+	}
+
+	for _, test := range tests {
+		p := &route.Path{
+			BGPPath: test.bgpPath,
+		}
+		original := p.BGPPath.ASPath.String()
+
+		res := NewASPathPrependSequenceAction(test.asns, test.times).Do(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), p)
+		assert.Equal(t, test.expectedPath, res.Path.BGPPath.ASPath.String(), "Test %q", test.name)
+		assert.Equal(t, original, p.BGPPath.ASPath.String(), "Test %q: original path modified", test.name)
+	}
+}
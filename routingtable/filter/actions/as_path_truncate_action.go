@@ -0,0 +1,39 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// ASPathTruncateAction removes all ASNs beyond the first ones from the AS path
+type ASPathTruncateAction struct {
+	length uint16
+}
+
+// NewASPathTruncateAction creates an action limiting the AS path to length ASNs
+func NewASPathTruncateAction(length uint16) *ASPathTruncateAction {
+	return &ASPathTruncateAction{
+		length: length,
+	}
+}
+
+func (a *ASPathTruncateAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || pa.BGPPath.ASPath == nil || pa.BGPPath.ASPath.Length() <= a.length {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	modified.BGPPath.TruncateASPath(a.length)
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *ASPathTruncateAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *ASPathTruncateAction:
+	default:
+		return false
+	}
+
+	return a.length == b.(*ASPathTruncateAction).length
+}
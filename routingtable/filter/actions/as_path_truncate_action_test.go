@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestASPathTruncate(t *testing.T) {
+	tests := []struct {
+		name           string
+		length         uint16
+		asPath         *types.ASPath
+		expectedPath   string
+		expectedLength uint16
+	}{
+		{
+			name:   "Shorter path",
+			length: 3,
+			asPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65000, 15169},
+				},
+			},
+			expectedPath:   "65000 15169",
+			expectedLength: 2,
+		},
+		{
+			name:   "Truncate sequence",
+			length: 2,
+			asPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65000, 65001, 65002, 15169},
+				},
+			},
+			expectedPath:   "65000 65001",
+			expectedLength: 2,
+		},
+		{
+			name:   "Truncate set",
+			length: 2,
+			asPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65000, 65001},
+				},
+				{
+					Type: types.ASSet,
+					ASNs: []uint32{65002, 65003},
+				},
+			},
+			expectedPath:   "65000 65001",
+			expectedLength: 2,
+		},
+		{
+			name:   "Keep set",
+			length: 2,
+			asPath: &types.ASPath{
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65000},
+				},
+				{
+					Type: types.ASSet,
+					ASNs: []uint32{65002, 65003},
+				},
+				{
+					Type: types.ASSequence,
+					ASNs: []uint32{65004},
+				},
+			},
+			expectedPath:   "65000 (65002 65003)",
+			expectedLength: 2,
+		},
+	}
+
+	for _, test := range tests {
+		p := &route.Path{
+			BGPPath: &route.BGPPath{
+				ASPath:    test.asPath,
+				ASPathLen: test.asPath.Length(),
+			},
+		}
+		original := test.asPath.String()
+
+		res := NewASPathTruncateAction(test.length).Do(bnet.NewPfx(bnet.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(), p)
+		assert.Equal(t, test.expectedPath, res.Path.BGPPath.ASPath.String(), "Test %q", test.name)
+		assert.Equal(t, test.expectedLength, res.Path.BGPPath.ASPathLen, "Test %q", test.name)
+		assert.Equal(t, original, test.asPath.String(), "Test %q: original path modified", test.name)
+	}
+}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+// CommunityPattern selects communities to delete. Communities are matched in their textual form: 65000:100 for communities,
+// 65000:1:2 for large communities and e.g. target:65000:100 for extended communities.
+type CommunityPattern struct {
+	expr string
+	re   *regexp.Regexp
+}
+
+// NewCommunityPattern creates a community pattern. The pattern is either a community with any of its fields
+// being a wildcard (e.g. 65000:* or (65000,*)) or a regular expression enclosed in slashes (e.g. /^65000:1[0-9]{2}$/).
+func NewCommunityPattern(expr string) (*CommunityPattern, error) {
+	var s string
+	if len(expr) > 1 && strings.HasPrefix(expr, "/") && strings.HasSuffix(expr, "/") {
+		s = expr[1 : len(expr)-1]
+	} else {
+		fields := strings.Split(strings.ReplaceAll(strings.Trim(expr, "()"), ",", ":"), ":")
+		for i := range fields {
+			if fields[i] == "*" {
+				fields[i] = "[^:]+"
+				continue
+			}
+
+			fields[i] = regexp.QuoteMeta(fields[i])
+		}
+
+		s = "^" + strings.Join(fields, ":") + "$"
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid community pattern %q: %v", expr, err)
+	}
+
+	return &CommunityPattern{
+		expr: expr,
+		re:   re,
+	}, nil
+}
+
+func (c *CommunityPattern) String() string {
+	return c.expr
+}
+
+func (c *CommunityPattern) matches(s string) bool {
+	return c.re.MatchString(s)
+}
+
+func matchesAnyPattern(patterns []*CommunityPattern, s string) bool {
+	for _, p := range patterns {
+		if p.matches(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func patternsEqual(a, b []*CommunityPattern) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].expr != b[i].expr {
+			return false
+		}
+	}
+
+	return true
+}
+
+func communityString(c uint32) string {
+	return fmt.Sprintf("%d:%d", c>>16, c&0xffff)
+}
+
+func largeCommunityString(c types.LargeCommunity) string {
+	return fmt.Sprintf("%d:%d:%d", c.GlobalAdministrator, c.DataPart1, c.DataPart2)
+}
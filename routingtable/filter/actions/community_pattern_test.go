@@ -0,0 +1,97 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunityPattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		community string
+		wantFail  bool
+		expected  bool
+	}{
+		{
+			name:      "Exact value",
+			pattern:   "65000:100",
+			community: "65000:100",
+			expected:  true,
+		},
+		{
+			name:      "Exact value does not match",
+			pattern:   "65000:100",
+			community: "65000:1000",
+			expected:  false,
+		},
+		{
+			name:      "Parenthesized value",
+			pattern:   "(65000,100)",
+			community: "65000:100",
+			expected:  true,
+		},
+		{
+			name:      "Wildcard",
+			pattern:   "65000:*",
+			community: "65000:4711",
+			expected:  true,
+		},
+		{
+			name:      "Wildcard does not match other AS",
+			pattern:   "65000:*",
+			community: "65001:4711",
+			expected:  false,
+		},
+		{
+			name:      "Wildcard in large community",
+			pattern:   "65000:*:2",
+			community: "65000:1:2",
+			expected:  true,
+		},
+		{
+			name:      "Wildcard in route target",
+			pattern:   "target:*:100",
+			community: "target:192.0.2.1:100",
+			expected:  true,
+		},
+		{
+			name:      "Regular expression",
+			pattern:   "/^65000:1[0-9]{2}$/",
+			community: "65000:150",
+			expected:  true,
+		},
+		{
+			name:      "Regular expression does not match",
+			pattern:   "/^65000:1[0-9]{2}$/",
+			community: "65000:200",
+			expected:  false,
+		},
+		{
+			name:     "Invalid regular expression",
+			pattern:  "/65000:(/",
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := NewCommunityPattern(test.pattern)
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "Test %q", test.name)
+		assert.Equalf(t, test.expected, p.matches(test.community), "Test %q", test.name)
+	}
+}
+
+func mustCommunityPattern(expr string) *CommunityPattern {
+	p, err := NewCommunityPattern(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// DeleteCommunityAction removes all communities matching any of its patterns
+type DeleteCommunityAction struct {
+	patterns []*CommunityPattern
+}
+
+// NewDeleteCommunityAction creates a new DeleteCommunityAction
+func NewDeleteCommunityAction(patterns ...*CommunityPattern) *DeleteCommunityAction {
+	return &DeleteCommunityAction{
+		patterns: patterns,
+	}
+}
+
+func (a *DeleteCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || pa.BGPPath.Communities == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	coms := make(types.Communities, 0, len(*modified.BGPPath.Communities))
+	for _, c := range *modified.BGPPath.Communities {
+		if !matchesAnyPattern(a.patterns, communityString(c)) {
+			coms = append(coms, c)
+		}
+	}
+
+	modified.BGPPath.Communities = nil
+	if len(coms) > 0 {
+		modified.BGPPath.Communities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *DeleteCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *DeleteCommunityAction:
+	default:
+		return false
+	}
+
+	return patternsEqual(a.patterns, b.(*DeleteCommunityAction).patterns)
+}
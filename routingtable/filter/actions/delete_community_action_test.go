@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteCommunities(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *types.Communities
+		patterns []*CommunityPattern
+		expected *types.Communities
+	}{
+		{
+			name:     "No communities",
+			patterns: []*CommunityPattern{mustCommunityPattern("1:2")},
+		},
+		{
+			name:     "Delete by value",
+			current:  &types.Communities{65538, 196612}, // (1,2) (3,4)
+			patterns: []*CommunityPattern{mustCommunityPattern("1:2")},
+			expected: &types.Communities{196612},
+		},
+		{
+			name:     "Delete by wildcard",
+			current:  &types.Communities{65538, 65539, 196612}, // (1,2) (1,3) (3,4)
+			patterns: []*CommunityPattern{mustCommunityPattern("1:*")},
+			expected: &types.Communities{196612},
+		},
+		{
+			name:     "Delete all",
+			current:  &types.Communities{65538, 196612}, // (1,2) (3,4)
+			patterns: []*CommunityPattern{mustCommunityPattern("/.*/")},
+		},
+	}
+
+	for _, test := range tests {
+		p := &route.Path{
+			BGPPath: &route.BGPPath{
+				Communities: test.current,
+			},
+		}
+
+		res := NewDeleteCommunityAction(test.patterns...).Do(&net.Prefix{}, p)
+		assert.Equalf(t, test.expected, res.Path.BGPPath.Communities, "Test %q", test.name)
+	}
+}
+
+func TestDeleteLargeCommunities(t *testing.T) {
+	p := &route.Path{
+		BGPPath: &route.BGPPath{
+			LargeCommunities: &types.LargeCommunities{
+				{GlobalAdministrator: 65000, DataPart1: 1, DataPart2: 2},
+				{GlobalAdministrator: 65000, DataPart1: 3, DataPart2: 4},
+				{GlobalAdministrator: 65001, DataPart1: 1, DataPart2: 2},
+			},
+		},
+	}
+
+	res := NewDeleteLargeCommunityAction(mustCommunityPattern("65000:*:*")).Do(&net.Prefix{}, p)
+	assert.Equal(t, &types.LargeCommunities{
+		{GlobalAdministrator: 65001, DataPart1: 1, DataPart2: 2},
+	}, res.Path.BGPPath.LargeCommunities)
+	assert.Equal(t, 3, len(*p.BGPPath.LargeCommunities), "Original path must not be modified")
+}
+
+func TestDeleteExtendedCommunities(t *testing.T) {
+	rt1, _ := types.NewRouteTarget(65000, 100)
+	rt2, _ := types.NewRouteTarget(65001, 100)
+	p := &route.Path{
+		BGPPath: &route.BGPPath{
+			ExtendedCommunities: &types.ExtendedCommunities{rt1, rt2},
+		},
+	}
+
+	res := NewDeleteExtendedCommunityAction(mustCommunityPattern("target:65000:*")).Do(&net.Prefix{}, p)
+	assert.Equal(t, &types.ExtendedCommunities{rt2}, res.Path.BGPPath.ExtendedCommunities)
+}
+
+func TestDeleteCommunityActionEqual(t *testing.T) {
+	a := NewDeleteCommunityAction(mustCommunityPattern("1:*"))
+	assert.True(t, a.Equal(NewDeleteCommunityAction(mustCommunityPattern("1:*"))))
+	assert.False(t, a.Equal(NewDeleteCommunityAction(mustCommunityPattern("2:*"))))
+	assert.False(t, a.Equal(NewDeleteLargeCommunityAction(mustCommunityPattern("1:*"))))
+}
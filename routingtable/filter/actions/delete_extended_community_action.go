@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// DeleteExtendedCommunityAction removes all extended communities matching any of its patterns
+type DeleteExtendedCommunityAction struct {
+	patterns []*CommunityPattern
+}
+
+// NewDeleteExtendedCommunityAction creates a new DeleteExtendedCommunityAction
+func NewDeleteExtendedCommunityAction(patterns ...*CommunityPattern) *DeleteExtendedCommunityAction {
+	return &DeleteExtendedCommunityAction{
+		patterns: patterns,
+	}
+}
+
+func (a *DeleteExtendedCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || pa.BGPPath.ExtendedCommunities == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	coms := make(types.ExtendedCommunities, 0, len(*modified.BGPPath.ExtendedCommunities))
+	for _, c := range *modified.BGPPath.ExtendedCommunities {
+		if !matchesAnyPattern(a.patterns, c.String()) {
+			coms = append(coms, c)
+		}
+	}
+
+	modified.BGPPath.ExtendedCommunities = nil
+	if len(coms) > 0 {
+		modified.BGPPath.ExtendedCommunities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *DeleteExtendedCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *DeleteExtendedCommunityAction:
+	default:
+		return false
+	}
+
+	return patternsEqual(a.patterns, b.(*DeleteExtendedCommunityAction).patterns)
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// DeleteLargeCommunityAction removes all large communities matching any of its patterns
+type DeleteLargeCommunityAction struct {
+	patterns []*CommunityPattern
+}
+
+// NewDeleteLargeCommunityAction creates a new DeleteLargeCommunityAction
+func NewDeleteLargeCommunityAction(patterns ...*CommunityPattern) *DeleteLargeCommunityAction {
+	return &DeleteLargeCommunityAction{
+		patterns: patterns,
+	}
+}
+
+func (a *DeleteLargeCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil || pa.BGPPath.LargeCommunities == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	coms := make(types.LargeCommunities, 0, len(*modified.BGPPath.LargeCommunities))
+	for _, c := range *modified.BGPPath.LargeCommunities {
+		if !matchesAnyPattern(a.patterns, largeCommunityString(c)) {
+			coms = append(coms, c)
+		}
+	}
+
+	modified.BGPPath.LargeCommunities = nil
+	if len(coms) > 0 {
+		modified.BGPPath.LargeCommunities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *DeleteLargeCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *DeleteLargeCommunityAction:
+	default:
+		return false
+	}
+
+	return patternsEqual(a.patterns, b.(*DeleteLargeCommunityAction).patterns)
+}
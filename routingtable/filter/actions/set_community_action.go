@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// SetCommunityAction replaces all communities of a path. An empty list removes all of them.
+type SetCommunityAction struct {
+	communities types.Communities
+}
+
+// NewSetCommunityAction creates a new SetCommunityAction
+func NewSetCommunityAction(coms types.Communities) *SetCommunityAction {
+	return &SetCommunityAction{
+		communities: coms,
+	}
+}
+
+func (a *SetCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	modified.BGPPath.Communities = nil
+	if len(a.communities) > 0 {
+		coms := make(types.Communities, len(a.communities))
+		copy(coms, a.communities)
+		modified.BGPPath.Communities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *SetCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *SetCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*SetCommunityAction).communities.String()
+}
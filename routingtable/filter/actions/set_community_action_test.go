@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCommunities(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     *types.Communities
+		communities types.Communities
+		expected    *types.Communities
+	}{
+		{
+			name:        "Set on path without communities",
+			communities: types.Communities{65538},
+			expected:    &types.Communities{65538},
+		},
+		{
+			name:        "Replace",
+			current:     &types.Communities{65538, 196612},
+			communities: types.Communities{327686},
+			expected:    &types.Communities{327686},
+		},
+		{
+			name:    "Remove all",
+			current: &types.Communities{65538, 196612},
+		},
+	}
+
+	for _, test := range tests {
+		p := &route.Path{
+			BGPPath: &route.BGPPath{
+				Communities: test.current,
+			},
+		}
+
+		res := NewSetCommunityAction(test.communities).Do(&net.Prefix{}, p)
+		assert.Equalf(t, test.expected, res.Path.BGPPath.Communities, "Test %q", test.name)
+	}
+}
+
+func TestSetLargeCommunities(t *testing.T) {
+	p := &route.Path{
+		BGPPath: &route.BGPPath{
+			LargeCommunities: &types.LargeCommunities{
+				{GlobalAdministrator: 65000, DataPart1: 1, DataPart2: 2},
+			},
+		},
+	}
+
+	coms := types.LargeCommunities{
+		{GlobalAdministrator: 65001, DataPart1: 3, DataPart2: 4},
+	}
+	res := NewSetLargeCommunityAction(coms).Do(&net.Prefix{}, p)
+	assert.Equal(t, &coms, res.Path.BGPPath.LargeCommunities)
+}
+
+func TestAddSetExtendedCommunities(t *testing.T) {
+	rt1, _ := types.NewRouteTarget(65000, 100)
+	rt2, _ := types.NewRouteTarget(65001, 100)
+	p := &route.Path{
+		BGPPath: &route.BGPPath{
+			ExtendedCommunities: &types.ExtendedCommunities{rt1},
+		},
+	}
+
+	res := NewAddExtendedCommunityAction(types.ExtendedCommunities{rt1, rt2}).Do(&net.Prefix{}, p)
+	assert.Equal(t, &types.ExtendedCommunities{rt1, rt2}, res.Path.BGPPath.ExtendedCommunities)
+
+	res = NewSetExtendedCommunityAction(types.ExtendedCommunities{rt2}).Do(&net.Prefix{}, res.Path)
+	assert.Equal(t, &types.ExtendedCommunities{rt2}, res.Path.BGPPath.ExtendedCommunities)
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// SetExtendedCommunityAction replaces all extended communities of a path. An empty list removes all of them.
+type SetExtendedCommunityAction struct {
+	communities types.ExtendedCommunities
+}
+
+// NewSetExtendedCommunityAction creates a new SetExtendedCommunityAction
+func NewSetExtendedCommunityAction(coms types.ExtendedCommunities) *SetExtendedCommunityAction {
+	return &SetExtendedCommunityAction{
+		communities: coms,
+	}
+}
+
+func (a *SetExtendedCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	modified.BGPPath.ExtendedCommunities = nil
+	if len(a.communities) > 0 {
+		coms := make(types.ExtendedCommunities, len(a.communities))
+		copy(coms, a.communities)
+		modified.BGPPath.ExtendedCommunities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *SetExtendedCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *SetExtendedCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*SetExtendedCommunityAction).communities.String()
+}
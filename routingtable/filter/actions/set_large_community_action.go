@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// SetLargeCommunityAction replaces all large communities of a path. An empty list removes all of them.
+type SetLargeCommunityAction struct {
+	communities types.LargeCommunities
+}
+
+// NewSetLargeCommunityAction creates a new SetLargeCommunityAction
+func NewSetLargeCommunityAction(coms types.LargeCommunities) *SetLargeCommunityAction {
+	return &SetLargeCommunityAction{
+		communities: coms,
+	}
+}
+
+func (a *SetLargeCommunityAction) Do(p *net.Prefix, pa *route.Path) Result {
+	if pa.BGPPath == nil {
+		return Result{Path: pa}
+	}
+
+	modified := pa.Copy()
+	modified.BGPPath.LargeCommunities = nil
+	if len(a.communities) > 0 {
+		coms := make(types.LargeCommunities, len(a.communities))
+		copy(coms, a.communities)
+		modified.BGPPath.LargeCommunities = &coms
+	}
+
+	return Result{Path: modified}
+}
+
+// Equal compares actions
+func (a *SetLargeCommunityAction) Equal(b Action) bool {
+	switch b.(type) {
+	case *SetLargeCommunityAction:
+	default:
+		return false
+	}
+
+	return a.communities.String() == b.(*SetLargeCommunityAction).communities.String()
+}
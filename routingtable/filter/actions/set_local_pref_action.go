@@ -21,6 +21,7 @@ func (a *SetLocalPrefAction) Do(p *net.Prefix, pa *route.Path) Result {
 	}
 
 	modified := pa.Copy()
+	copyBGPPathA(modified)
 	modified.BGPPath.BGPPathA.LocalPref = a.pref
 	return Result{Path: modified}
 }
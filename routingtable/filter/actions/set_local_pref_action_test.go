@@ -37,6 +37,7 @@ func TestSetLocalPref(t *testing.T) {
 
 			if test.expectedLocalPref > 0 {
 				assert.Equal(t, test.expectedLocalPref, res.Path.BGPPath.BGPPathA.LocalPref)
+				assert.Equal(t, uint32(100), test.bgpPath.BGPPathA.LocalPref, "Original path must not be modified")
 			}
 		})
 	}
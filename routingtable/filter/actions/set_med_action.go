@@ -24,6 +24,7 @@ func (a *SetMEDAction) Do(p *net.Prefix, pa *route.Path) Result {
 	}
 
 	modified := pa.Copy()
+	copyBGPPathA(modified)
 	modified.BGPPath.BGPPathA.MED = a.med
 
 	return Result{Path: modified}
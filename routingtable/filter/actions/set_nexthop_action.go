@@ -22,6 +22,7 @@ func (a *SetNextHopAction) Do(p *net.Prefix, pa *route.Path) Result {
 	}
 
 	modified := pa.Copy()
+	copyBGPPathA(modified)
 	modified.BGPPath.BGPPathA.NextHop = a.ip
 
 	return Result{Path: modified}
@@ -0,0 +1,393 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+)
+
+// ASPathRegexSyntax is the syntax of an AS path regular expression
+type ASPathRegexSyntax uint8
+
+const (
+	// ASPathRegexJuniper is the Juniper style syntax: Terms are whole AS numbers (e.g. "65001 .* [65100-65199]+"),
+	// the expression has to match the complete AS path.
+	ASPathRegexJuniper ASPathRegexSyntax = iota
+
+	// ASPathRegexCisco is the Cisco style syntax: A regular expression matched against the AS path as string
+	// (e.g. "_65001_" or "^65001_[0-9]+$"). "_" matches the start or end of the path or a delimiter between AS numbers.
+	ASPathRegexCisco
+)
+
+// ParseASPathRegexSyntax parses the name of an AS path regex syntax
+func ParseASPathRegexSyntax(s string) (ASPathRegexSyntax, error) {
+	switch s {
+	case "", "juniper":
+		return ASPathRegexJuniper, nil
+	case "cisco":
+		return ASPathRegexCisco, nil
+	}
+
+	return 0, fmt.Errorf("Unknown AS path regex syntax %q", s)
+}
+
+const ciscoDelimiter = `(?:^|$|[ ,{}])`
+
+// asPathRegexCache holds all compiled AS path regular expressions. Filters using the same expression share the compiled automaton.
+var asPathRegexCache = struct {
+	mu sync.Mutex
+	m  map[asPathRegexKey]*regexp.Regexp
+}{
+	m: make(map[asPathRegexKey]*regexp.Regexp),
+}
+
+type asPathRegexKey struct {
+	expr   string
+	syntax ASPathRegexSyntax
+}
+
+// ASPathFilter matches paths with an AS path matching a regular expression
+type ASPathFilter struct {
+	expr   string
+	syntax ASPathRegexSyntax
+	re     *regexp.Regexp
+}
+
+// NewASPathFilter creates a new AS path filter
+func NewASPathFilter(expr string, syntax ASPathRegexSyntax) (*ASPathFilter, error) {
+	re, err := compileASPathRegex(asPathRegexKey{expr: expr, syntax: syntax})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ASPathFilter{
+		expr:   expr,
+		syntax: syntax,
+		re:     re,
+	}, nil
+}
+
+func compileASPathRegex(k asPathRegexKey) (*regexp.Regexp, error) {
+	asPathRegexCache.mu.Lock()
+	defer asPathRegexCache.mu.Unlock()
+
+	if re, exists := asPathRegexCache.m[k]; exists {
+		return re, nil
+	}
+
+	var s string
+	var err error
+	switch k.syntax {
+	case ASPathRegexJuniper:
+		s, err = translateJuniperASPathRegex(k.expr)
+	case ASPathRegexCisco:
+		s = strings.ReplaceAll(k.expr, "_", ciscoDelimiter)
+	default:
+		err = fmt.Errorf("Unknown AS path regex syntax %d", k.syntax)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid AS path regex %q: %v", k.expr, err)
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid AS path regex %q: %v", k.expr, err)
+	}
+
+	asPathRegexCache.m[k] = re
+	return re, nil
+}
+
+// Matches checks if AS path p matches the regular expression
+func (f *ASPathFilter) Matches(p *types.ASPath) bool {
+	if f.syntax == ASPathRegexJuniper {
+		return f.re.MatchString(asPathTokens(p))
+	}
+
+	return f.re.MatchString(asPathString(p))
+}
+
+func (f *ASPathFilter) equal(x *ASPathFilter) bool {
+	return f.expr == x.expr && f.syntax == x.syntax
+}
+
+// asPathMatcher matches AS paths against multiple filters at once. The expressions of all filters of a syntax are
+// combined into one, so the AS path is rendered and scanned once per syntax instead of once per filter.
+type asPathMatcher struct {
+	juniper *regexp.Regexp
+	cisco   *regexp.Regexp
+
+	// filters are matched one by one if their expressions can not be combined (e.g. exceeding RE2 limits)
+	filters []*ASPathFilter
+}
+
+func newASPathMatcher(filters []*ASPathFilter) *asPathMatcher {
+	m := &asPathMatcher{}
+	bySyntax := make(map[ASPathRegexSyntax][]*ASPathFilter)
+	for _, f := range filters {
+		bySyntax[f.syntax] = append(bySyntax[f.syntax], f)
+	}
+
+	for syntax, fs := range bySyntax {
+		re, err := combineASPathRegex(fs)
+		if err != nil {
+			m.filters = append(m.filters, fs...)
+			continue
+		}
+
+		switch syntax {
+		case ASPathRegexJuniper:
+			m.juniper = re
+		case ASPathRegexCisco:
+			m.cisco = re
+		}
+	}
+
+	return m
+}
+
+func combineASPathRegex(filters []*ASPathFilter) (*regexp.Regexp, error) {
+	if len(filters) == 1 {
+		return filters[0].re, nil
+	}
+
+	exprs := make([]string, 0, len(filters))
+	for _, f := range filters {
+		exprs = append(exprs, "(?:"+f.re.String()+")")
+	}
+
+	return regexp.Compile(strings.Join(exprs, "|"))
+}
+
+// matches checks if AS path p matches any of the filters
+func (m *asPathMatcher) matches(p *types.ASPath) bool {
+	if m.juniper != nil && m.juniper.MatchString(asPathTokens(p)) {
+		return true
+	}
+
+	if m.cisco != nil && m.cisco.MatchString(asPathString(p)) {
+		return true
+	}
+
+	for _, f := range m.filters {
+		if f.Matches(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// asPathString renders an AS path as matched by Cisco style expressions, e.g. "65001 65002 {65003,65004}"
+func asPathString(p *types.ASPath) string {
+	var b strings.Builder
+	writeASPath(&b, p)
+	return b.String()
+}
+
+// asPathTokens renders an AS path as matched by translated Juniper style expressions: Each AS number or AS set is terminated by a space
+func asPathTokens(p *types.ASPath) string {
+	var b strings.Builder
+	writeASPath(&b, p)
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
+
+func writeASPath(b *strings.Builder, p *types.ASPath) {
+	if p == nil {
+		return
+	}
+
+	for _, seg := range *p {
+		if seg.Type == types.ASSet {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+
+			b.WriteByte('{')
+			for i, asn := range seg.ASNs {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(strconv.FormatUint(uint64(asn), 10))
+			}
+			b.WriteByte('}')
+			continue
+		}
+
+		for _, asn := range seg.ASNs {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(strconv.FormatUint(uint64(asn), 10))
+		}
+	}
+}
+
+// translateJuniperASPathRegex translates a Juniper style expression into a regular expression matching the output of asPathTokens
+func translateJuniperASPathRegex(expr string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^(?:")
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '_':
+			i++
+		case c == '^' && i == 0, c == '$' && i == len(expr)-1:
+			// Expressions always match the complete path
+			i++
+		case c == '(' || c == ')' || c == '|' || c == '*' || c == '+' || c == '?':
+			b.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(expr[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("Unterminated repetition at position %d", i)
+			}
+
+			b.WriteString(expr[i : i+end+1])
+			i += end + 1
+		case c == '.':
+			b.WriteString(`(?:[^ ]+ )`)
+			i++
+		case c == '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("Unterminated set at position %d", i)
+			}
+
+			alternatives := make([]string, 0)
+			for _, term := range strings.Fields(expr[i+1 : i+end]) {
+				s, err := asnTermRegex(term)
+				if err != nil {
+					return "", err
+				}
+
+				alternatives = append(alternatives, s)
+			}
+
+			if len(alternatives) == 0 {
+				return "", fmt.Errorf("Empty set at position %d", i)
+			}
+
+			b.WriteString("(?:(?:" + strings.Join(alternatives, "|") + ") )")
+			i += end + 1
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(expr) && (expr[end] >= '0' && expr[end] <= '9' || expr[end] == '-') {
+				end++
+			}
+
+			s, err := asnTermRegex(expr[i:end])
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString("(?:" + s + " )")
+			i = end
+		default:
+			return "", fmt.Errorf("Unexpected character %q at position %d", c, i)
+		}
+	}
+
+	b.WriteString(")$")
+	return b.String(), nil
+}
+
+// asnTermRegex gets the regular expression for an AS number or range of AS numbers (e.g. "65100-65199")
+func asnTermRegex(term string) (string, error) {
+	parts := strings.Split(term, "-")
+	if len(parts) > 2 {
+		return "", fmt.Errorf("Invalid AS number range %q", term)
+	}
+
+	lo, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("Invalid AS number %q", parts[0])
+	}
+
+	if len(parts) == 1 {
+		return strconv.FormatUint(lo, 10), nil
+	}
+
+	hi, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("Invalid AS number %q", parts[1])
+	}
+
+	if lo > hi {
+		return "", fmt.Errorf("Invalid AS number range %q", term)
+	}
+
+	return strings.Join(numberRangeRegex(lo, hi), "|"), nil
+}
+
+// numberRangeRegex gets regular expressions matching all decimal numbers from lo to hi (inclusive)
+func numberRangeRegex(lo, hi uint64) []string {
+	los := strconv.FormatUint(lo, 10)
+	his := strconv.FormatUint(hi, 10)
+	if len(los) == len(his) {
+		return sameLengthRangeRegex(los, his)
+	}
+
+	// Split into ranges of numbers with the same number of digits
+	max := uint64(1)
+	for range los {
+		max *= 10
+	}
+
+	return append(sameLengthRangeRegex(los, strings.Repeat("9", len(los))), numberRangeRegex(max, hi)...)
+}
+
+func sameLengthRangeRegex(lo, hi string) []string {
+	if lo == hi {
+		return []string{lo}
+	}
+
+	if len(lo) == 1 {
+		return []string{fmt.Sprintf("[%c-%c]", lo[0], hi[0])}
+	}
+
+	if lo[0] == hi[0] {
+		res := sameLengthRangeRegex(lo[1:], hi[1:])
+		for i := range res {
+			res[i] = lo[:1] + res[i]
+		}
+
+		return res
+	}
+
+	n := len(lo) - 1
+	res := make([]string, 0)
+	first, last := lo[0], hi[0]
+
+	if lo[1:] != strings.Repeat("0", n) {
+		for _, s := range sameLengthRangeRegex(lo[1:], strings.Repeat("9", n)) {
+			res = append(res, lo[:1]+s)
+		}
+		first++
+	}
+
+	var upper []string
+	if hi[1:] != strings.Repeat("9", n) {
+		for _, s := range sameLengthRangeRegex(strings.Repeat("0", n), hi[1:]) {
+			upper = append(upper, hi[:1]+s)
+		}
+		last--
+	}
+
+	if first <= last {
+		res = append(res, fmt.Sprintf("[%c-%c]%s", first, last, strings.Repeat("[0-9]", n)))
+	}
+
+	return append(res, upper...)
+}
@@ -0,0 +1,255 @@
+package filter
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestASPathFilterMatches(t *testing.T) {
+	path := &types.ASPath{
+		{
+			Type: types.ASSequence,
+			ASNs: []uint32{65001, 65100, 65150},
+		},
+		{
+			Type: types.ASSet,
+			ASNs: []uint32{64512, 64513},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		syntax   ASPathRegexSyntax
+		path     *types.ASPath
+		wantFail bool
+		expected bool
+	}{
+		{
+			name:     "Juniper exact path",
+			expr:     "65001 65100 65150 .",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper partial path does not match",
+			expr:     "65001 65100",
+			path:     path,
+			expected: false,
+		},
+		{
+			name:     "Juniper wildcard",
+			expr:     "65001 .*",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper wildcard matching nothing",
+			expr:     "65001 .* 65100 65150 .",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper range",
+			expr:     ".* 65100-65199 .",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper set",
+			expr:     "^[64000-64999 65001] .*$",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper repetition",
+			expr:     "65001 65100-65199{2} .",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper alternation",
+			expr:     "(65002|65001) .*",
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Juniper AS number is not matched as prefix",
+			expr:     "6500 .*",
+			path:     path,
+			expected: false,
+		},
+		{
+			name:     "Juniper empty path",
+			expr:     "()",
+			path:     nil,
+			expected: true,
+		},
+		{
+			name:     "Juniper invalid AS number",
+			expr:     "4294967296",
+			wantFail: true,
+		},
+		{
+			name:     "Juniper invalid range",
+			expr:     "65100-65000",
+			wantFail: true,
+		},
+		{
+			name:     "Juniper invalid character",
+			expr:     "65001 a",
+			wantFail: true,
+		},
+		{
+			name:     "Cisco neighbor AS",
+			expr:     "^65001_",
+			syntax:   ASPathRegexCisco,
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Cisco transit AS",
+			expr:     "_65100_",
+			syntax:   ASPathRegexCisco,
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Cisco AS in set",
+			expr:     "_64513_",
+			syntax:   ASPathRegexCisco,
+			path:     path,
+			expected: true,
+		},
+		{
+			name:     "Cisco no match",
+			expr:     "_65002_",
+			syntax:   ASPathRegexCisco,
+			path:     path,
+			expected: false,
+		},
+		{
+			name:     "Cisco empty path",
+			expr:     "^$",
+			syntax:   ASPathRegexCisco,
+			path:     &types.ASPath{},
+			expected: true,
+		},
+		{
+			name:     "Cisco invalid regex",
+			expr:     "_(65001_",
+			syntax:   ASPathRegexCisco,
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		f, err := NewASPathFilter(test.expr, test.syntax)
+		if test.wantFail {
+			assert.Errorf(t, err, "Test %q", test.name)
+			continue
+		}
+
+		if !assert.NoErrorf(t, err, "Test %q", test.name) {
+			continue
+		}
+
+		assert.Equalf(t, test.expected, f.Matches(test.path), "Test %q", test.name)
+	}
+}
+
+func TestASPathFilterCache(t *testing.T) {
+	a, err := NewASPathFilter("65001 .*", ASPathRegexJuniper)
+	assert.NoError(t, err)
+
+	b, err := NewASPathFilter("65001 .*", ASPathRegexJuniper)
+	assert.NoError(t, err)
+
+	c, err := NewASPathFilter("65001 .*", ASPathRegexCisco)
+	assert.NoError(t, err)
+
+	assert.True(t, a.re == b.re, "Filters with the same expression must share the compiled regex")
+	assert.True(t, a.re != c.re, "Filters with different syntax must not share the compiled regex")
+	assert.True(t, a.equal(b))
+	assert.False(t, a.equal(c))
+}
+
+func TestASPathMatcher(t *testing.T) {
+	mustFilter := func(expr string, syntax ASPathRegexSyntax) *ASPathFilter {
+		f, err := NewASPathFilter(expr, syntax)
+		if err != nil {
+			panic(err)
+		}
+
+		return f
+	}
+
+	tests := []struct {
+		name     string
+		filters  []*ASPathFilter
+		path     *types.ASPath
+		expected bool
+	}{
+		{
+			name:     "Second Juniper expression matches",
+			filters:  []*ASPathFilter{mustFilter("65001 .*", ASPathRegexJuniper), mustFilter(".* 65003", ASPathRegexJuniper)},
+			path:     &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65002, 65003}}},
+			expected: true,
+		},
+		{
+			name:     "Combined Juniper expressions stay anchored",
+			filters:  []*ASPathFilter{mustFilter("65001", ASPathRegexJuniper), mustFilter("65002", ASPathRegexJuniper)},
+			path:     &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}},
+			expected: false,
+		},
+		{
+			name:     "Cisco expression matches with Juniper expression present",
+			filters:  []*ASPathFilter{mustFilter("65001", ASPathRegexJuniper), mustFilter("_65002$", ASPathRegexCisco)},
+			path:     &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001, 65002}}},
+			expected: true,
+		},
+		{
+			name:     "No filters",
+			path:     &types.ASPath{{Type: types.ASSequence, ASNs: []uint32{65001}}},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		m := newASPathMatcher(test.filters)
+		assert.Equalf(t, test.expected, m.matches(test.path), "Test %q", test.name)
+	}
+}
+
+func TestNumberRangeRegex(t *testing.T) {
+	tests := []struct {
+		lo uint64
+		hi uint64
+	}{
+		{lo: 0, hi: 9},
+		{lo: 7, hi: 123},
+		{lo: 65100, hi: 65199},
+		{lo: 64496, hi: 65551},
+		{lo: 999, hi: 1000},
+	}
+
+	for _, test := range tests {
+		re := regexp.MustCompile("^(?:" + asnRange(test.lo, test.hi) + ")$")
+		for n := uint64(0); n <= test.hi+1000; n++ {
+			expected := n >= test.lo && n <= test.hi
+			if re.MatchString(strconv.FormatUint(n, 10)) != expected {
+				t.Errorf("Range %d-%d: unexpected result for %d", test.lo, test.hi, n)
+				break
+			}
+		}
+	}
+}
+
+func asnRange(lo, hi uint64) string {
+	s, _ := asnTermRegex(strconv.FormatUint(lo, 10) + "-" + strconv.FormatUint(hi, 10))
+	return s
+}
@@ -6,7 +6,18 @@ type CommunityFilter struct {
 	community uint32
 }
 
+// NewCommunityFilter creates a filter matching paths with community c
+func NewCommunityFilter(c uint32) *CommunityFilter {
+	return &CommunityFilter{
+		community: c,
+	}
+}
+
 func (f *CommunityFilter) Matches(coms *types.Communities) bool {
+	if coms == nil {
+		return false
+	}
+
 	for _, com := range *coms {
 		if com == f.community {
 			return true
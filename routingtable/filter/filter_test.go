@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable/filter/actions"
 	"github.com/stretchr/testify/assert"
@@ -85,3 +86,29 @@ func TestProcessTerms(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessKeepsModifications(t *testing.T) {
+	f := NewFilter("some Name", []*Term{
+		NewTerm("add community", nil, []actions.Action{
+			actions.NewAddCommunityAction(&types.Communities{65538}),
+		}),
+		NewTerm("set local pref and accept", nil, []actions.Action{
+			actions.NewSetLocalPrefAction(50),
+			actions.NewAcceptAction(),
+		}),
+	})
+
+	p := &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				LocalPref: 100,
+			},
+		},
+	}
+
+	res := f.Process(net.NewPfx(net.IPv4(0), 0).Ptr(), p)
+	assert.False(t, res.Reject)
+	assert.Equal(t, &types.Communities{65538}, res.Path.BGPPath.Communities)
+	assert.Equal(t, uint32(50), res.Path.BGPPath.BGPPathA.LocalPref)
+}
@@ -1,34 +1,46 @@
 package filter
 
-import "github.com/bio-routing/bio-rd/net"
+import (
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/net/trie"
+)
 
 type PrefixList struct {
-	allowed []*net.Prefix
+	allowed *trie.Trie[[]*net.Prefix]
 	matcher PrefixMatcher
 }
 
 func NewPrefixList(pfxs ...*net.Prefix) *PrefixList {
-	l := &PrefixList{
-		allowed: pfxs,
-		matcher: NewExactMatcher(),
-	}
-	return l
+	return NewPrefixListWithMatcher(NewExactMatcher(), pfxs...)
 }
 
 func NewPrefixListWithMatcher(matcher PrefixMatcher, pfxs ...*net.Prefix) *PrefixList {
 	l := &PrefixList{
-		allowed: pfxs,
+		allowed: trie.New[[]*net.Prefix](),
 		matcher: matcher,
 	}
+
+	// the trie ignores host bits, so prefixes differing only in host bits share an entry
+	for _, pfx := range pfxs {
+		existing, _ := l.allowed.Get(pfx)
+		l.allowed.Insert(pfx, append(existing, pfx))
+	}
+
 	return l
 }
 
 func (l *PrefixList) Matches(p *net.Prefix) bool {
-	for _, a := range l.allowed {
-		if a.Equal(p) {
-			return true
+	found := false
+	l.allowed.Covering(p, func(_ net.Prefix, pfxs []*net.Prefix) bool {
+		for _, a := range pfxs {
+			if l.matcher.Match(a, p) {
+				found = true
+				return false
+			}
 		}
-	}
 
-	return false
+		return true
+	})
+
+	return found
 }
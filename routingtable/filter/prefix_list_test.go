@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixListMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     *PrefixList
+		prefix   *net.Prefix
+		expected bool
+	}{
+		{
+			name:     "exact match",
+			list:     NewPrefixList(net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()),
+			prefix:   net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr(),
+			expected: true,
+		},
+		{
+			name:     "exact matcher does not match more specifics",
+			list:     NewPrefixList(net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()),
+			prefix:   net.NewPfx(net.IPv4FromOctets(10, 1, 0, 0), 16).Ptr(),
+			expected: false,
+		},
+		{
+			name: "or longer matcher",
+			list: NewPrefixListWithMatcher(NewOrLongerMatcher(),
+				net.NewPfx(net.IPv4FromOctets(192, 168, 0, 0), 16).Ptr(),
+				net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()),
+			prefix:   net.NewPfx(net.IPv4FromOctets(10, 1, 0, 0), 16).Ptr(),
+			expected: true,
+		},
+		{
+			name:     "in range matcher",
+			list:     NewPrefixListWithMatcher(NewInRangeMatcher(9, 15), net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()),
+			prefix:   net.NewPfx(net.IPv4FromOctets(10, 1, 0, 0), 16).Ptr(),
+			expected: false,
+		},
+		{
+			name:     "no match",
+			list:     NewPrefixListWithMatcher(NewOrLongerMatcher(), net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 8).Ptr()),
+			prefix:   net.NewPfx(net.IPv4FromOctets(11, 0, 0, 0), 8).Ptr(),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.list.Matches(test.prefix))
+		})
+	}
+}
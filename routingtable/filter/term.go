@@ -19,8 +19,12 @@ type TermResult struct {
 	Reject    bool
 }
 
-// NewTerm creates a new term
+// NewTerm creates a new term. Its conditions are compiled right away, so they must not be modified afterwards.
 func NewTerm(name string, from []*TermCondition, then []actions.Action) *Term {
+	for _, f := range from {
+		f.compile()
+	}
+
 	t := &Term{
 		name: name,
 		from: from,
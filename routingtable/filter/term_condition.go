@@ -1,15 +1,39 @@
 package filter
 
 import (
+	"sync"
+
 	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/net/trie"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/route"
 )
 
+// TermCondition matches paths against prefix lists, route filters, communities, validation states and AS paths.
+// The filters are compiled into indexed structures once (when the term is created or on first use), so conditions
+// must not be modified afterwards.
 type TermCondition struct {
 	prefixLists           []*PrefixList
 	routeFilters          []*RouteFilter
 	communityFilters      []*CommunityFilter
 	largeCommunityFilters []*LargeCommunityFilter
+	validationStates      []vrp.ValidationState
+	asPathFilters         []*ASPathFilter
+
+	compileOnce sync.Once
+	compiled    *compiledCondition
+}
+
+// compiledCondition holds the filters of a condition indexed, so matching a path does not depend on the number of
+// route filters and communities of the condition: Route filters are kept in a trie by pattern, communities in sets
+// and the AS path expressions of each syntax are combined into one automaton.
+type compiledCondition struct {
+	routeFilters     *trie.Trie[[]*RouteFilter]
+	communities      map[uint32]struct{}
+	largeCommunities map[types.LargeCommunity]struct{}
+	validationStates [vrp.Invalid + 1]bool
+	asPath           *asPathMatcher
 }
 
 func NewTermCondition(prefixLists []*PrefixList, routeFilters []*RouteFilter) *TermCondition {
@@ -31,11 +55,82 @@ func NewTermConditionWithPrefixLists(filters ...*PrefixList) *TermCondition {
 	}
 }
 
+// NewTermConditionWithValidationStates creates a condition matching paths in one of the given RPKI origin validation states
+func NewTermConditionWithValidationStates(states ...vrp.ValidationState) *TermCondition {
+	return &TermCondition{
+		validationStates: states,
+	}
+}
+
+// MatchValidationStates additionally requires paths to be in one of the given RPKI origin validation states
+func (f *TermCondition) MatchValidationStates(states ...vrp.ValidationState) *TermCondition {
+	f.validationStates = append(f.validationStates, states...)
+	return f
+}
+
+// NewTermConditionWithCommunityFilters creates a condition matching paths with one of the given communities
+func NewTermConditionWithCommunityFilters(filters ...*CommunityFilter) *TermCondition {
+	return &TermCondition{
+		communityFilters: filters,
+	}
+}
+
+// NewTermConditionWithASPathFilters creates a condition matching paths with an AS path matching one of the given filters
+func NewTermConditionWithASPathFilters(filters ...*ASPathFilter) *TermCondition {
+	return &TermCondition{
+		asPathFilters: filters,
+	}
+}
+
+// MatchASPathFilters additionally requires paths to have an AS path matching one of the given filters
+func (f *TermCondition) MatchASPathFilters(filters ...*ASPathFilter) *TermCondition {
+	f.asPathFilters = append(f.asPathFilters, filters...)
+	return f
+}
+
 func (f *TermCondition) Matches(p *net.Prefix, pa *route.Path) bool {
+	f.compile()
+
 	return f.matchesPrefixListFilters(p) &&
 		f.matchesRouteFilters(p) &&
 		f.matchesCommunityFilters(pa) &&
-		f.matchesLargeCommunityFilters(pa)
+		f.matchesLargeCommunityFilters(pa) &&
+		f.matchesValidationStates(pa) &&
+		f.matchesASPathFilters(pa)
+}
+
+// compile builds the indexes of the condition unless done already
+func (f *TermCondition) compile() {
+	f.compileOnce.Do(func() {
+		c := &compiledCondition{
+			routeFilters:     trie.New[[]*RouteFilter](),
+			communities:      make(map[uint32]struct{}, len(f.communityFilters)),
+			largeCommunities: make(map[types.LargeCommunity]struct{}, len(f.largeCommunityFilters)),
+			asPath:           newASPathMatcher(f.asPathFilters),
+		}
+
+		// the trie ignores host bits, so patterns differing only in host bits share an entry
+		for _, rf := range f.routeFilters {
+			existing, _ := c.routeFilters.Get(rf.pattern)
+			c.routeFilters.Insert(rf.pattern, append(existing, rf))
+		}
+
+		for _, cf := range f.communityFilters {
+			c.communities[cf.community] = struct{}{}
+		}
+
+		for _, lcf := range f.largeCommunityFilters {
+			c.largeCommunities[lcf.community] = struct{}{}
+		}
+
+		for _, s := range f.validationStates {
+			if int(s) < len(c.validationStates) {
+				c.validationStates[s] = true
+			}
+		}
+
+		f.compiled = c
+	})
 }
 
 func (t *TermCondition) matchesPrefixListFilters(p *net.Prefix) bool {
@@ -57,13 +152,20 @@ func (t *TermCondition) matchesRouteFilters(p *net.Prefix) bool {
 		return true
 	}
 
-	for _, l := range t.routeFilters {
-		if l.Matches(p) {
-			return true
+	// All matchers require the pattern to cover the prefix
+	found := false
+	t.compiled.routeFilters.Covering(p, func(_ net.Prefix, filters []*RouteFilter) bool {
+		for _, rf := range filters {
+			if rf.Matches(p) {
+				found = true
+				return false
+			}
 		}
-	}
 
-	return false
+		return true
+	})
+
+	return found
 }
 
 func (t *TermCondition) matchesCommunityFilters(pa *route.Path) bool {
@@ -75,8 +177,12 @@ func (t *TermCondition) matchesCommunityFilters(pa *route.Path) bool {
 		return false
 	}
 
-	for _, l := range t.communityFilters {
-		if l.Matches(pa.BGPPath.Communities) {
+	if pa.BGPPath.Communities == nil {
+		return false
+	}
+
+	for _, com := range *pa.BGPPath.Communities {
+		if _, exists := t.compiled.communities[com]; exists {
 			return true
 		}
 	}
@@ -93,8 +199,12 @@ func (t *TermCondition) matchesLargeCommunityFilters(pa *route.Path) bool {
 		return false
 	}
 
-	for _, l := range t.largeCommunityFilters {
-		if l.Matches(pa.BGPPath.LargeCommunities) {
+	if pa.BGPPath.LargeCommunities == nil {
+		return false
+	}
+
+	for _, com := range *pa.BGPPath.LargeCommunities {
+		if _, exists := t.compiled.largeCommunities[com]; exists {
 			return true
 		}
 	}
@@ -102,6 +212,31 @@ func (t *TermCondition) matchesLargeCommunityFilters(pa *route.Path) bool {
 	return false
 }
 
+func (t *TermCondition) matchesValidationStates(pa *route.Path) bool {
+	if len(t.validationStates) == 0 {
+		return true
+	}
+
+	if pa.BGPPath == nil {
+		return false
+	}
+
+	s := pa.BGPPath.ValidationState
+	return int(s) < len(t.compiled.validationStates) && t.compiled.validationStates[s]
+}
+
+func (t *TermCondition) matchesASPathFilters(pa *route.Path) bool {
+	if len(t.asPathFilters) == 0 {
+		return true
+	}
+
+	if pa.BGPPath == nil {
+		return false
+	}
+
+	return t.compiled.asPath.matches(pa.BGPPath.ASPath)
+}
+
 func (t *TermCondition) equal(x *TermCondition) bool {
 	if len(t.routeFilters) != len(x.routeFilters) {
 		return false
@@ -115,12 +250,32 @@ func (t *TermCondition) equal(x *TermCondition) bool {
 		return false
 	}
 
+	if len(t.validationStates) != len(x.validationStates) {
+		return false
+	}
+
+	if len(t.asPathFilters) != len(x.asPathFilters) {
+		return false
+	}
+
 	for i := range t.routeFilters {
 		if !t.routeFilters[i].equal(x.routeFilters[i]) {
 			return false
 		}
 	}
 
+	for i := range t.validationStates {
+		if t.validationStates[i] != x.validationStates[i] {
+			return false
+		}
+	}
+
+	for i := range t.asPathFilters {
+		if !t.asPathFilters[i].equal(x.asPathFilters[i]) {
+			return false
+		}
+	}
+
 	// TODO: Compare community filters
 
 	// TODO: Compare large community filters
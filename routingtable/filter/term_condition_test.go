@@ -5,6 +5,7 @@ import (
 
 	"github.com/bio-routing/bio-rd/net"
 	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/protocols/rpki/vrp"
 	"github.com/bio-routing/bio-rd/route"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,6 +19,8 @@ func TestMatches(t *testing.T) {
 		routeFilters          []*RouteFilter
 		communityFilters      []*CommunityFilter
 		largeCommunityFilters []*LargeCommunityFilter
+		validationStates      []vrp.ValidationState
+		asPathFilters         []*ASPathFilter
 		expected              bool
 	}{
 		{
@@ -190,6 +193,71 @@ func TestMatches(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name:   "validation state matches",
+			prefix: net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			bgpPath: &route.BGPPath{
+				ValidationState: vrp.Invalid,
+			},
+			validationStates: []vrp.ValidationState{vrp.NotFound, vrp.Invalid},
+			expected:         true,
+		},
+		{
+			name:   "validation state does not match",
+			prefix: net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			bgpPath: &route.BGPPath{
+				ValidationState: vrp.Valid,
+			},
+			validationStates: []vrp.ValidationState{vrp.Invalid},
+			expected:         false,
+		},
+		{
+			name:             "validation state, bgp path is nil",
+			prefix:           net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			validationStates: []vrp.ValidationState{vrp.Invalid},
+			expected:         false,
+		},
+		{
+			name:   "AS path matches",
+			prefix: net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			bgpPath: &route.BGPPath{
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65002},
+					},
+				},
+			},
+			asPathFilters: []*ASPathFilter{
+				mustASPathFilter("65000 .*"),
+				mustASPathFilter("65001 .*"),
+			},
+			expected: true,
+		},
+		{
+			name:   "AS path does not match",
+			prefix: net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			bgpPath: &route.BGPPath{
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65002},
+					},
+				},
+			},
+			asPathFilters: []*ASPathFilter{
+				mustASPathFilter(".* 65003"),
+			},
+			expected: false,
+		},
+		{
+			name:   "AS path filter, bgp path is nil",
+			prefix: net.NewPfx(net.IPv4FromOctets(10, 0, 0, 0), 24).Ptr(),
+			asPathFilters: []*ASPathFilter{
+				mustASPathFilter(".*"),
+			},
+			expected: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -197,6 +265,8 @@ func TestMatches(t *testing.T) {
 			f := NewTermCondition(test.prefixLists, test.routeFilters)
 			f.communityFilters = test.communityFilters
 			f.largeCommunityFilters = test.largeCommunityFilters
+			f.validationStates = test.validationStates
+			f.asPathFilters = test.asPathFilters
 
 			pa := &route.Path{
 				BGPPath: test.bgpPath,
@@ -206,3 +276,29 @@ func TestMatches(t *testing.T) {
 		})
 	}
 }
+
+func mustASPathFilter(expr string) *ASPathFilter {
+	f, err := NewASPathFilter(expr, ASPathRegexJuniper)
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+func BenchmarkTermConditionRouteFilters(b *testing.B) {
+	routeFilters := make([]*RouteFilter, 0, 1<<16)
+	for i := 0; i < 1<<16; i++ {
+		routeFilters = append(routeFilters, NewRouteFilter(net.NewPfx(net.IPv4(uint32(i)<<16), 16).Ptr(), NewInRangeMatcher(16, 24)))
+	}
+
+	f := NewTermConditionWithRouteFilters(routeFilters...)
+	f.compile()
+	pa := &route.Path{}
+	pfx := net.NewPfx(net.IPv4FromOctets(203, 0, 113, 0), 24).Ptr()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Matches(pfx, pa)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/bio-routing/bio-rd/routingtable/filter"
 	"github.com/bio-routing/bio-rd/util/math"
-	log "github.com/sirupsen/logrus"
 )
 
 // LocRIB represents a routing information base
@@ -20,6 +19,18 @@ type LocRIB struct {
 	mu               sync.RWMutex
 	contributingASNs *routingtable.ContributingASNs
 	countTarget      *countTarget
+
+	// pic maintains next hop groups once a NextHopGroupClient registered, guarded by mu
+	pic *pic
+
+	// bestPathOptions tune the BGP decision process, guarded by mu
+	bestPathOptions *route.BestPathOptions
+
+	// routeCountByProtocol counts routes by the protocol of their best path, guarded by mu
+	routeCountByProtocol map[string]uint64
+
+	// sizeEstimate is the estimated memory used by all routes in bytes, guarded by mu
+	sizeEstimate uint64
 }
 
 type countTarget struct {
@@ -30,9 +41,10 @@ type countTarget struct {
 // New creates a new routing information base
 func New(name string) *LocRIB {
 	a := &LocRIB{
-		name:             name,
-		rt:               routingtable.NewRoutingTable(),
-		contributingASNs: routingtable.NewContributingASNs(),
+		name:                 name,
+		rt:                   routingtable.NewRoutingTable(),
+		contributingASNs:     routingtable.NewContributingASNs(),
+		routeCountByProtocol: make(map[string]uint64),
 	}
 	a.clientManager = routingtable.NewClientManager(a)
 
@@ -99,17 +111,7 @@ func (a *LocRIB) UpdateNewClient(client routingtable.RouteTableClient) error {
 
 	routes := a.rt.Dump()
 	for _, r := range routes {
-		n := uint(0)
-		if opts.BestOnly {
-			n = 1
-		} else if opts.EcmpOnly {
-			n = r.ECMPPathCount()
-		} else {
-			n = opts.MaxPaths
-			n = uint(math.Min(int(n), len(r.Paths())))
-		}
-
-		for _, p := range r.Paths()[:n] {
+		for _, p := range clientPaths(r, opts, a.bestPathOptions) {
 			client.AddPathInitialDump(r.Prefix(), p)
 		}
 	}
@@ -126,18 +128,57 @@ func (a *LocRIB) RefreshClient(client routingtable.RouteTableClient) {
 
 	routes := a.rt.Dump()
 	for _, r := range routes {
-		n := uint(0)
-		if opts.BestOnly {
-			n = 1
-		} else if opts.EcmpOnly {
-			n = r.ECMPPathCount()
-		} else {
-			n = opts.MaxPaths
-			n = uint(math.Min(int(n), len(r.Paths())))
+		client.RefreshRoute(r.Prefix(), clientPaths(r, opts, a.bestPathOptions))
+	}
+}
+
+// SetClientIGPCosts changes the IGP costs the best paths of a registered client are selected with
+// (see routingtable.ClientOptions) and propagates the best paths changed by that to the client
+func (a *LocRIB) SetClientIGPCosts(client routingtable.RouteTableClient, costs route.IGPCosts) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	oldOpts := a.clientManager.GetOptions(client)
+	newOpts := oldOpts
+	newOpts.IGPCosts = costs
+	if !a.clientManager.SetOptions(client, newOpts) {
+		return
+	}
+
+	for _, r := range a.rt.Dump() {
+		oldPaths := clientPaths(r, oldOpts, a.bestPathOptions)
+		newPaths := clientPaths(r, newOpts, a.bestPathOptions)
+
+		for _, p := range route.PathsDiff(oldPaths, newPaths) {
+			client.RemovePath(r.Prefix(), p)
+		}
+
+		for _, p := range route.PathsDiff(newPaths, oldPaths) {
+			client.AddPath(r.Prefix(), p)
+		}
+	}
+}
+
+// clientPaths gets the paths of route r propagated to a client with options opts given the best path options o of the RIB
+func clientPaths(r *route.Route, opts routingtable.ClientOptions, o *route.BestPathOptions) []*route.Path {
+	if opts.IGPCosts != nil {
+		clientOptions := route.BestPathOptions{}
+		if o != nil {
+			clientOptions = *o
+		}
+		clientOptions.IGPCosts = opts.IGPCosts
+
+		best := r.BestPathWithOptions(&clientOptions)
+		if best == nil {
+			return nil
 		}
 
-		client.RefreshRoute(r.Prefix(), r.Paths()[:n])
+		return []*route.Path{best}
 	}
+
+	n := opts.GetMaxPaths(r.ECMPPathCount())
+	limit := int(math.Min(int(n), len(r.Paths())))
+	return r.Paths()[:limit]
 }
 
 // RouteCount returns the number of stored routes
@@ -145,6 +186,65 @@ func (a *LocRIB) RouteCount() int64 {
 	return a.rt.GetRouteCount()
 }
 
+// RouteCountByProtocol gets the number of routes by the protocol of their best path
+func (a *LocRIB) RouteCountByProtocol() map[string]uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ret := make(map[string]uint64, len(a.routeCountByProtocol))
+	for proto, n := range a.routeCountByProtocol {
+		ret[proto] = n
+	}
+
+	return ret
+}
+
+// SizeEstimate gets the estimated memory used by all routes in bytes
+func (a *LocRIB) SizeEstimate() uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.sizeEstimate
+}
+
+// account adds a route in the table to the counters. Must be called with a.mu held.
+func (a *LocRIB) account(r *route.Route) {
+	if r == nil {
+		return
+	}
+
+	a.sizeEstimate += r.SizeEstimate()
+	if p := r.BestPath(); p != nil {
+		a.routeCountByProtocol[route.PathTypeName(p.Type)]++
+	}
+}
+
+// unaccount removes a route in the table from the counters before it is changed. Must be called with a.mu held.
+func (a *LocRIB) unaccount(r *route.Route) {
+	if r == nil {
+		return
+	}
+
+	s := r.SizeEstimate()
+	if s > a.sizeEstimate {
+		s = a.sizeEstimate
+	}
+	a.sizeEstimate -= s
+
+	p := r.BestPath()
+	if p == nil {
+		return
+	}
+
+	proto := route.PathTypeName(p.Type)
+	if a.routeCountByProtocol[proto] <= 1 {
+		delete(a.routeCountByProtocol, proto)
+		return
+	}
+
+	a.routeCountByProtocol[proto]--
+}
+
 func (a *LocRIB) AddPathInitialDump(pfx *net.Prefix, p *route.Path) error {
 	return a.AddPath(pfx, p)
 }
@@ -164,6 +264,7 @@ func (a *LocRIB) AddPath(pfx *net.Prefix, p *route.Path) error {
 		oldRoute = r.Copy()
 		routeExisted = true
 	}
+	a.unaccount(r)
 
 	// FIXME: in AddPath() we assume that the same reference of route (r) is modified (not responsibility of locRIB). If this implementation changes in the future this code will break.
 	a.rt.AddPath(pfx, p)
@@ -171,10 +272,12 @@ func (a *LocRIB) AddPath(pfx *net.Prefix, p *route.Path) error {
 		r = a.rt.Get(pfx)
 	}
 
-	r.PathSelection()
+	r.PathSelectionWithOptions(a.bestPathOptions)
+	a.account(r)
 	newRoute := r.Copy()
 
 	a.propagateChanges(oldRoute, newRoute)
+	a.updateNextHopGroup(pfx, newRoute)
 	if a.countTarget != nil {
 		if a.RouteCount() == int64(a.countTarget.target) {
 			a.countTarget.ch <- struct{}{}
@@ -200,13 +303,16 @@ func (a *LocRIB) RemovePath(pfx *net.Prefix, p *route.Path) bool {
 		return true
 	}
 
+	a.unaccount(r)
 	a.rt.RemovePath(pfx, p)
-	r.PathSelection()
+	r.PathSelectionWithOptions(a.bestPathOptions)
 
 	r = a.rt.Get(pfx)
+	a.account(r)
 	newRoute := r.Copy()
 
 	a.propagateChanges(oldRoute, newRoute)
+	a.updateNextHopGroup(pfx, newRoute)
 	return true
 }
 
@@ -221,31 +327,62 @@ func (a *LocRIB) ReplacePath(pfx *net.Prefix, oldPath *route.Path, newPath *rout
 	}
 
 	oldRoute := r.Copy()
+	a.unaccount(r)
 	err := r.ReplacePath(oldPath, newPath)
 	if err != nil {
+		a.account(r)
 		log.Errorf("Unable to replace path: %v", err)
 		return
 	}
 
-	r.PathSelection()
+	r.PathSelectionWithOptions(a.bestPathOptions)
+	a.account(r)
 	a.propagateChanges(oldRoute, r)
+	a.updateNextHopGroup(pfx, r)
+}
+
+// SetBestPathOptions sets the options of the BGP decision process and reselects the best paths of all routes
+func (a *LocRIB) SetBestPathOptions(o *route.BestPathOptions) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.bestPathOptions.Equal(o) {
+		return
+	}
+
+	old := a.bestPathOptions
+	a.bestPathOptions = o
+	for _, r := range a.rt.Dump() {
+		oldRoute := r.Copy()
+		a.unaccount(r)
+		r.PathSelectionWithOptions(o)
+		a.account(r)
+		a.propagateChangesWithOptions(oldRoute, r, old, o)
+		a.updateNextHopGroup(r.Prefix(), r)
+	}
+}
+
+func (a *LocRIB) updateNextHopGroup(pfx *net.Prefix, r *route.Route) {
+	if a.pic != nil {
+		a.pic.update(pfx, r)
+	}
 }
 
 func (a *LocRIB) propagateChanges(oldRoute *route.Route, newRoute *route.Route) {
-	a.removePathsFromClients(oldRoute, newRoute)
-	a.addPathsToClients(oldRoute, newRoute)
+	a.propagateChangesWithOptions(oldRoute, newRoute, a.bestPathOptions, a.bestPathOptions)
+}
+
+// propagateChangesWithOptions propagates changes of a route whose paths were selected using the best path options
+// oldOptions before and newOptions after the change
+func (a *LocRIB) propagateChangesWithOptions(oldRoute *route.Route, newRoute *route.Route, oldOptions, newOptions *route.BestPathOptions) {
+	a.removePathsFromClients(oldRoute, newRoute, oldOptions, newOptions)
+	a.addPathsToClients(oldRoute, newRoute, oldOptions, newOptions)
 }
 
-func (a *LocRIB) addPathsToClients(oldRoute *route.Route, newRoute *route.Route) {
+func (a *LocRIB) addPathsToClients(oldRoute *route.Route, newRoute *route.Route, oldOptions, newOptions *route.BestPathOptions) {
 	for _, client := range a.clientManager.Clients() {
 		opts := a.clientManager.GetOptions(client)
-		oldMaxPaths := opts.GetMaxPaths(oldRoute.ECMPPathCount())
-		newMaxPaths := opts.GetMaxPaths(newRoute.ECMPPathCount())
-
-		oldPathsLimit := int(math.Min(int(oldMaxPaths), len(oldRoute.Paths())))
-		newPathsLimit := int(math.Min(int(newMaxPaths), len(newRoute.Paths())))
-
-		advertise := route.PathsDiff(newRoute.Paths()[0:newPathsLimit], oldRoute.Paths()[0:oldPathsLimit])
+		advertise := route.PathsDiff(clientPaths(newRoute, opts, newOptions), clientPaths(oldRoute, opts, oldOptions))
 
 		for _, p := range advertise {
 			client.AddPath(newRoute.Prefix(), p)
@@ -253,16 +390,10 @@ func (a *LocRIB) addPathsToClients(oldRoute *route.Route, newRoute *route.Route)
 	}
 }
 
-func (a *LocRIB) removePathsFromClients(oldRoute *route.Route, newRoute *route.Route) {
+func (a *LocRIB) removePathsFromClients(oldRoute *route.Route, newRoute *route.Route, oldOptions, newOptions *route.BestPathOptions) {
 	for _, client := range a.clientManager.Clients() {
 		opts := a.clientManager.GetOptions(client)
-		oldMaxPaths := opts.GetMaxPaths(oldRoute.ECMPPathCount())
-		newMaxPaths := opts.GetMaxPaths(newRoute.ECMPPathCount())
-
-		oldPathsLimit := int(math.Min(int(oldMaxPaths), len(oldRoute.Paths())))
-		newPathsLimit := int(math.Min(int(newMaxPaths), len(newRoute.Paths())))
-
-		withdraw := route.PathsDiff(oldRoute.Paths()[0:oldPathsLimit], newRoute.Paths()[0:newPathsLimit])
+		withdraw := route.PathsDiff(clientPaths(oldRoute, opts, oldOptions), clientPaths(newRoute, opts, newOptions))
 
 		for _, p := range withdraw {
 			client.RemovePath(oldRoute.Prefix(), p)
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
 	"github.com/bio-routing/bio-rd/route"
+	"github.com/bio-routing/bio-rd/routingtable"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -97,3 +99,146 @@ func TestLocRIB_RemovePathUnknown(t *testing.T) {
 				},
 			}))
 }
+
+type mockBestPathClient struct {
+	paths map[bnet.Prefix]*route.Path
+}
+
+func (m *mockBestPathClient) AddPath(pfx *bnet.Prefix, p *route.Path) error {
+	m.paths[*pfx] = p
+	return nil
+}
+
+func (m *mockBestPathClient) AddPathInitialDump(pfx *bnet.Prefix, p *route.Path) error {
+	return m.AddPath(pfx, p)
+}
+
+func (m *mockBestPathClient) RemovePath(pfx *bnet.Prefix, p *route.Path) bool {
+	if m.paths[*pfx] != p {
+		return false
+	}
+
+	delete(m.paths, *pfx)
+	return true
+}
+
+func (m *mockBestPathClient) ReplacePath(*bnet.Prefix, *route.Path, *route.Path) {}
+
+func (m *mockBestPathClient) RefreshRoute(*bnet.Prefix, []*route.Path) {}
+
+func TestClientIGPCosts(t *testing.T) {
+	nh1 := bnet.IPv4FromOctets(10, 0, 0, 1)
+	nh2 := bnet.IPv4FromOctets(10, 0, 0, 2)
+	pfx := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+
+	bgpPath := func(nh bnet.IP) *route.Path {
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref: 100,
+					NextHop:   nh.Ptr(),
+					Source:    nh.Ptr(),
+				},
+			},
+		}
+	}
+	p1 := bgpPath(nh1)
+	p2 := bgpPath(nh2)
+
+	rib := New("inet.0")
+	rib.AddPath(&pfx, p1)
+
+	plain := &mockBestPathClient{paths: make(map[bnet.Prefix]*route.Path)}
+	rib.RegisterWithOptions(plain, routingtable.ClientOptions{BestOnly: true})
+
+	c := &mockBestPathClient{paths: make(map[bnet.Prefix]*route.Path)}
+	rib.RegisterWithOptions(c, routingtable.ClientOptions{
+		BestOnly: true,
+		IGPCosts: route.NewIGPCostTable(map[bnet.IP]uint32{
+			nh1: 20,
+			nh2: 10,
+		}),
+	})
+	assert.Equal(t, p1, c.paths[pfx], "Initial dump")
+
+	rib.AddPath(&pfx, p2)
+	assert.Equal(t, p1, plain.paths[pfx], "Best path of the RIB")
+	assert.Equal(t, p2, c.paths[pfx], "Lower IGP cost")
+
+	rib.SetClientIGPCosts(c, route.NewIGPCostTable(map[bnet.IP]uint32{
+		nh1: 10,
+		nh2: 20,
+	}))
+	assert.Equal(t, p1, c.paths[pfx], "IGP costs changed")
+
+	rib.RemovePath(&pfx, p1)
+	assert.Equal(t, p2, c.paths[pfx], "Best path removed")
+	assert.Equal(t, p2, plain.paths[pfx], "Best path of the RIB removed")
+}
+
+func TestRouteCounters(t *testing.T) {
+	nh1 := bnet.IPv4FromOctets(10, 0, 0, 1)
+	nh2 := bnet.IPv4FromOctets(10, 0, 0, 2)
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24)
+
+	bgpPath := func(nh bnet.IP, localPref uint32) *route.Path {
+		return &route.Path{
+			Type: route.BGPPathType,
+			BGPPath: &route.BGPPath{
+				BGPPathA: &route.BGPPathA{
+					LocalPref: localPref,
+					NextHop:   nh.Ptr(),
+					Source:    nh.Ptr(),
+				},
+				ASPath: &types.ASPath{
+					{
+						Type: types.ASSequence,
+						ASNs: []uint32{65001, 65002},
+					},
+				},
+			},
+		}
+	}
+	static := &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{NextHop: nh1.Ptr()}}
+	p1 := bgpPath(nh1, 100)
+	p2 := bgpPath(nh2, 100)
+	p3 := bgpPath(nh2, 200)
+
+	rib := New("inet.0")
+
+	// The counters are maintained incrementally and must always match a full walk of the table
+	check := func(msg string, expectedCounts map[string]uint64) {
+		size := uint64(0)
+		for _, r := range rib.Dump() {
+			size += r.SizeEstimate()
+		}
+
+		assert.Equal(t, expectedCounts, rib.RouteCountByProtocol(), msg)
+		assert.Equal(t, size, rib.SizeEstimate(), msg)
+	}
+
+	check("Empty RIB", map[string]uint64{})
+
+	rib.AddPath(&pfxA, static)
+	rib.AddPath(&pfxB, p1)
+	check("Routes added", map[string]uint64{"static": 1, "bgp": 1})
+
+	rib.AddPath(&pfxB, p2)
+	check("Second path added", map[string]uint64{"static": 1, "bgp": 1})
+
+	rib.ReplacePath(&pfxB, p2, p3)
+	check("Path replaced", map[string]uint64{"static": 1, "bgp": 1})
+
+	rib.SetBestPathOptions(&route.BestPathOptions{AlwaysCompareMED: true})
+	check("Best path options changed", map[string]uint64{"static": 1, "bgp": 1})
+
+	rib.RemovePath(&pfxB, p1)
+	rib.RemovePath(&pfxB, p3)
+	check("BGP route removed", map[string]uint64{"static": 1})
+
+	rib.RemovePath(&pfxA, static)
+	check("All routes removed", map[string]uint64{})
+	assert.Equal(t, uint64(0), rib.SizeEstimate())
+}
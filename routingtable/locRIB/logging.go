@@ -0,0 +1,7 @@
+package locRIB
+
+import (
+	"github.com/bio-routing/bio-rd/util/logging"
+)
+
+var log = logging.Subsystem("rib")
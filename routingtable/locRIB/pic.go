@@ -0,0 +1,381 @@
+package locRIB
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+)
+
+// NextHopGroupClient is a FIB installing routes pointing to shared next hop groups (hierarchical FIB).
+// On failure of a next hop only the groups using it are updated, so traffic moves to precomputed
+// backup paths in a time independent of the number of prefixes (BGP Prefix Independent Convergence).
+type NextHopGroupClient interface {
+	// SetNextHopGroup adds a next hop group or updates its active next hops
+	SetNextHopGroup(g NextHopGroup)
+
+	// RemoveNextHopGroup removes a next hop group no longer used by any route
+	RemoveNextHopGroup(id uint64)
+
+	// SetRoute points the route for pfx to next hop group id. The group has been set before.
+	SetRoute(pfx *net.Prefix, id uint64)
+
+	// RemoveRoute removes the route for pfx
+	RemoveRoute(pfx *net.Prefix)
+}
+
+// NextHop is a next hop of a next hop group
+type NextHop struct {
+	Address *net.IP
+
+	// Source is the neighbor the path has been learned from, nil for non BGP paths
+	Source *net.IP
+}
+
+func (n NextHop) equal(x NextHop) bool {
+	return ipEqual(n.Address, x.Address) && ipEqual(n.Source, x.Source)
+}
+
+func (n NextHop) String() string {
+	s := "<nil>"
+	if n.Address != nil {
+		s = n.Address.String()
+	}
+
+	if n.Source != nil {
+		s += "@" + n.Source.String()
+	}
+
+	return s
+}
+
+func ipEqual(a, b *net.IP) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(b)
+}
+
+func nextHopOf(p *route.Path) NextHop {
+	n := NextHop{
+		Address: p.NextHop(),
+	}
+
+	if p.Type == route.BGPPathType && p.BGPPath != nil && p.BGPPath.BGPPathA != nil {
+		n.Source = p.BGPPath.BGPPathA.Source
+	}
+
+	return n
+}
+
+// NextHopGroup is the set of next hops shared by all routes with the same ECMP paths and backup path
+type NextHopGroup struct {
+	ID uint64
+
+	// Primary are the next hops of the ECMP paths
+	Primary []NextHop
+
+	// Backup is the next hop of the backup path, nil if there is none
+	Backup *NextHop
+
+	// Active are the next hops used for forwarding: All primary next hops not failed or the backup next hop if all of them failed
+	Active []NextHop
+}
+
+type nextHopGroup struct {
+	NextHopGroup
+	key          string
+	refs         uint
+	failed       []bool
+	backupFailed bool
+}
+
+func newNextHopGroup(id uint64, key string, primary []NextHop, backup *NextHop) *nextHopGroup {
+	return &nextHopGroup{
+		NextHopGroup: NextHopGroup{
+			ID:      id,
+			Primary: primary,
+			Backup:  backup,
+			Active:  primary,
+		},
+		key:    key,
+		failed: make([]bool, len(primary)),
+	}
+}
+
+func (g *nextHopGroup) copy() NextHopGroup {
+	c := g.NextHopGroup
+	c.Active = make([]NextHop, len(g.Active))
+	copy(c.Active, g.Active)
+	return c
+}
+
+// fail marks all next hops matching f as failed and recomputes the active next hops. Returns true if they changed.
+func (g *nextHopGroup) fail(f func(NextHop) bool) bool {
+	changed := false
+	for i := range g.Primary {
+		if !g.failed[i] && f(g.Primary[i]) {
+			g.failed[i] = true
+			changed = true
+		}
+	}
+
+	if g.Backup != nil && !g.backupFailed && f(*g.Backup) {
+		g.backupFailed = true
+		changed = true
+	}
+
+	if !changed {
+		return false
+	}
+
+	active := make([]NextHop, 0, len(g.Primary))
+	for i := range g.Primary {
+		if !g.failed[i] {
+			active = append(active, g.Primary[i])
+		}
+	}
+
+	if len(active) == 0 && g.Backup != nil && !g.backupFailed {
+		active = append(active, *g.Backup)
+	}
+
+	// Without any next hop left the group keeps forwarding as before until path selection removed the routes
+	if len(active) == 0 || nextHopsEqual(active, g.Active) {
+		return false
+	}
+
+	g.Active = active
+	return true
+}
+
+func nextHopsEqual(a, b []NextHop) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nextHopGroupKey(primary []NextHop, backup *NextHop) string {
+	parts := make([]string, 0, len(primary)+1)
+	for _, n := range primary {
+		parts = append(parts, n.String())
+	}
+
+	b := ""
+	if backup != nil {
+		b = backup.String()
+	}
+
+	return fmt.Sprintf("%s|%s", strings.Join(parts, ","), b)
+}
+
+// pic maintains the next hop groups of all routes of a LocRIB. It is guarded by the LocRIBs lock.
+type pic struct {
+	clients []NextHopGroupClient
+	groups  map[string]*nextHopGroup
+	routes  map[net.Prefix]*nextHopGroup
+	lastID  uint64
+}
+
+func newPIC() *pic {
+	return &pic{
+		groups: make(map[string]*nextHopGroup),
+		routes: make(map[net.Prefix]*nextHopGroup),
+	}
+}
+
+// update assigns the next hop group matching the paths of r to pfx
+func (p *pic) update(pfx *net.Prefix, r *route.Route) {
+	ecmp := r.ECMPPaths()
+	if len(ecmp) == 0 {
+		p.remove(pfx)
+		return
+	}
+
+	primary := make([]NextHop, len(ecmp))
+	for i := range ecmp {
+		primary[i] = nextHopOf(ecmp[i])
+	}
+
+	var backup *NextHop
+	if b := r.BackupPath(); b != nil {
+		n := nextHopOf(b)
+		backup = &n
+	}
+
+	key := nextHopGroupKey(primary, backup)
+	old := p.routes[*pfx]
+	if old != nil && old.key == key {
+		return
+	}
+
+	g := p.groups[key]
+	if g == nil {
+		p.lastID++
+		g = newNextHopGroup(p.lastID, key, primary, backup)
+		p.groups[key] = g
+
+		for _, c := range p.clients {
+			c.SetNextHopGroup(g.copy())
+		}
+	}
+
+	g.refs++
+	p.routes[*pfx] = g
+	for _, c := range p.clients {
+		c.SetRoute(pfx, g.ID)
+	}
+
+	if old != nil {
+		p.release(old)
+	}
+}
+
+func (p *pic) remove(pfx *net.Prefix) {
+	g := p.routes[*pfx]
+	if g == nil {
+		return
+	}
+
+	delete(p.routes, *pfx)
+	for _, c := range p.clients {
+		c.RemoveRoute(pfx)
+	}
+
+	p.release(g)
+}
+
+func (p *pic) release(g *nextHopGroup) {
+	g.refs--
+	if g.refs > 0 {
+		return
+	}
+
+	delete(p.groups, g.key)
+	for _, c := range p.clients {
+		c.RemoveNextHopGroup(g.ID)
+	}
+}
+
+// fail switches all groups using next hops matching f to their remaining next hops. Returns the number of groups switched.
+func (p *pic) fail(f func(NextHop) bool) int {
+	n := 0
+	for _, g := range p.groups {
+		if !g.fail(f) {
+			continue
+		}
+
+		n++
+		for _, c := range p.clients {
+			c.SetNextHopGroup(g.copy())
+		}
+	}
+
+	return n
+}
+
+// dump sends all next hop groups and routes to a new client
+func (p *pic) dump(c NextHopGroupClient) {
+	for _, g := range p.groups {
+		c.SetNextHopGroup(g.copy())
+	}
+
+	for pfx, g := range p.routes {
+		pfx := pfx
+		c.SetRoute(&pfx, g.ID)
+	}
+}
+
+// RegisterNextHopGroupClient registers a FIB for hierarchical next hops. Next hop groups are maintained
+// as soon as the first client registers.
+func (a *LocRIB) RegisterNextHopGroupClient(c NextHopGroupClient) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pic == nil {
+		a.pic = newPIC()
+		for _, r := range a.rt.Dump() {
+			a.pic.update(r.Prefix(), r)
+		}
+	}
+
+	a.pic.clients = append(a.pic.clients, c)
+	a.pic.dump(c)
+}
+
+// UnregisterNextHopGroupClient unregisters a FIB for hierarchical next hops
+func (a *LocRIB) UnregisterNextHopGroupClient(c NextHopGroupClient) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pic == nil {
+		return
+	}
+
+	for i, x := range a.pic.clients {
+		if x == c {
+			a.pic.clients = append(a.pic.clients[:i], a.pic.clients[i+1:]...)
+			break
+		}
+	}
+
+	if len(a.pic.clients) == 0 {
+		a.pic = nil
+	}
+}
+
+// NextHopDown moves traffic off next hop nh by switching all next hop groups using it to their remaining
+// or backup next hops without waiting for path selection. Returns the number of groups switched.
+func (a *LocRIB) NextHopDown(nh *net.IP) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pic == nil {
+		return 0
+	}
+
+	return a.pic.fail(func(n NextHop) bool {
+		return ipEqual(n.Address, nh)
+	})
+}
+
+// PeerDown moves traffic off all paths learned from neighbor src like NextHopDown. Returns the number of groups switched.
+func (a *LocRIB) PeerDown(src *net.IP) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pic == nil {
+		return 0
+	}
+
+	return a.pic.fail(func(n NextHop) bool {
+		return n.Source != nil && ipEqual(n.Source, src)
+	})
+}
+
+// NextHopGroups gets all next hop groups. Returns nil if no next hop group client is registered.
+func (a *LocRIB) NextHopGroups() []NextHopGroup {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.pic == nil {
+		return nil
+	}
+
+	res := make([]NextHopGroup, 0, len(a.pic.groups))
+	for _, g := range a.pic.groups {
+		res = append(res, g.copy())
+	}
+
+	return res
+}
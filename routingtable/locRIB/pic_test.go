@@ -0,0 +1,110 @@
+package locRIB
+
+import (
+	"testing"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNextHopGroupClient struct {
+	groups map[uint64]NextHopGroup
+	routes map[bnet.Prefix]uint64
+}
+
+func newMockNextHopGroupClient() *mockNextHopGroupClient {
+	return &mockNextHopGroupClient{
+		groups: make(map[uint64]NextHopGroup),
+		routes: make(map[bnet.Prefix]uint64),
+	}
+}
+
+func (m *mockNextHopGroupClient) SetNextHopGroup(g NextHopGroup) {
+	m.groups[g.ID] = g
+}
+
+func (m *mockNextHopGroupClient) RemoveNextHopGroup(id uint64) {
+	delete(m.groups, id)
+}
+
+func (m *mockNextHopGroupClient) SetRoute(pfx *bnet.Prefix, id uint64) {
+	m.routes[*pfx] = id
+}
+
+func (m *mockNextHopGroupClient) RemoveRoute(pfx *bnet.Prefix) {
+	delete(m.routes, *pfx)
+}
+
+func TestPIC(t *testing.T) {
+	nh1 := bnet.IPv4FromOctets(10, 0, 0, 1).Ptr()
+	nh2 := bnet.IPv4FromOctets(10, 0, 0, 2).Ptr()
+	pfxA := bnet.NewPfx(bnet.IPv4FromOctets(192, 0, 2, 0), 24)
+	pfxB := bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 24)
+	pfxC := bnet.NewPfx(bnet.IPv4FromOctets(203, 0, 113, 0), 24)
+
+	staticPath := &route.Path{
+		Type: route.StaticPathType,
+		StaticPath: &route.StaticPath{
+			NextHop: nh1,
+		},
+	}
+	bgpPath := &route.Path{
+		Type: route.BGPPathType,
+		BGPPath: &route.BGPPath{
+			BGPPathA: &route.BGPPathA{
+				NextHop: nh2,
+				Source:  nh2,
+			},
+		},
+	}
+
+	rib := New("inet.0")
+	rib.AddPath(&pfxA, staticPath)
+
+	c := newMockNextHopGroupClient()
+	rib.RegisterNextHopGroupClient(c)
+	assert.Equal(t, 1, len(c.groups), "Initial dump")
+
+	rib.AddPath(&pfxA, bgpPath)
+	rib.AddPath(&pfxB, staticPath)
+	rib.AddPath(&pfxB, bgpPath)
+	rib.AddPath(&pfxC, bgpPath)
+
+	// Backup path via nh2 for pfxA and pfxB, only nh2 for pfxC
+	assert.Equal(t, 2, len(c.groups))
+	protected := c.groups[c.routes[pfxA]]
+	assert.Equal(t, c.routes[pfxA], c.routes[pfxB])
+	assert.Equal(t, []NextHop{{Address: nh1}}, protected.Primary)
+	assert.Equal(t, &NextHop{Address: nh2, Source: nh2}, protected.Backup)
+	assert.Equal(t, protected.Primary, protected.Active)
+	assert.NotEqual(t, c.routes[pfxA], c.routes[pfxC])
+
+	// Failure of nh1 switches the shared group to the backup without touching the routes
+	assert.Equal(t, 1, rib.NextHopDown(nh1))
+	assert.Equal(t, []NextHop{{Address: nh2, Source: nh2}}, c.groups[protected.ID].Active)
+	assert.Equal(t, protected.ID, c.routes[pfxA])
+
+	// Groups without next hops left keep forwarding until path selection removed their routes
+	assert.Equal(t, 0, rib.PeerDown(nh2))
+
+	// Path selection moves the routes to the group of the remaining path and removes the unused group
+	rib.RemovePath(&pfxA, staticPath)
+	rib.RemovePath(&pfxB, staticPath)
+	assert.Equal(t, 1, len(c.groups))
+	assert.Equal(t, c.routes[pfxC], c.routes[pfxA])
+	assert.Equal(t, c.routes[pfxC], c.routes[pfxB])
+
+	rib.RemovePath(&pfxC, bgpPath)
+	assert.Equal(t, 2, len(c.routes))
+
+	c2 := newMockNextHopGroupClient()
+	rib.RegisterNextHopGroupClient(c2)
+	assert.Equal(t, c.groups, c2.groups)
+	assert.Equal(t, c.routes, c2.routes)
+	assert.Equal(t, 1, len(rib.NextHopGroups()))
+
+	rib.UnregisterNextHopGroupClient(c)
+	rib.UnregisterNextHopGroupClient(c2)
+	assert.Nil(t, rib.NextHopGroups())
+}
@@ -1,6 +1,9 @@
 package routingtable
 
-import bnet "github.com/bio-routing/bio-rd/net"
+import (
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/route"
+)
 
 // Neighbor represents the attributes identifying a neighbor relationship
 type Neighbor struct {
@@ -19,6 +22,24 @@ type Neighbor struct {
 	// Local ASN of session
 	LocalASN uint32
 
+	// MigrationASN is presented to the neighbor instead of LocalASN if set (local-as)
+	MigrationASN uint32
+
+	// ReplaceAS prepends only MigrationASN instead of MigrationASN and LocalASN to AS paths sent to the neighbor
+	ReplaceAS bool
+
+	// PeerASN is the ASN of the neighbor
+	PeerASN uint32
+
+	// RemovePrivateAS determines how private ASNs are scrubbed from AS paths sent to the neighbor (eBGP only)
+	RemovePrivateAS route.PrivateASMode
+
+	// ASOverride replaces the ASN of the neighbor in AS paths sent to it by LocalASN (eBGP only)
+	ASOverride bool
+
+	// NextHopSelf determines if the next hop of paths sent to the neighbor is replaced by LocalAddress
+	NextHopSelf route.NextHopSelfMode
+
 	// RouteServerClient indicates if the peer is a route server client
 	RouteServerClient bool
 
@@ -27,4 +48,13 @@ type Neighbor struct {
 
 	// ClusterID is our route reflectors clusterID
 	ClusterID uint32
+
+	// NoClientToClientReflection prevents reflecting routes between clients of ClusterID, e.g. for fully meshed clients (RFC4456 5)
+	NoClientToClientReflection bool
+
+	// IgnoreWellKnownCommunities advertises paths to the neighbor regardless of NO_EXPORT, NO_ADVERTISE, NO_EXPORT_SUBCONFED and NOPEER
+	IgnoreWellKnownCommunities bool
+
+	// BilateralPeer marks the neighbor as bilateral peer, e.g. a settlement-free peer. Paths with NOPEER are not advertised to it (RFC3765).
+	BilateralPeer bool
 }
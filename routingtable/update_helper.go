@@ -24,18 +24,25 @@ func isOwnPath(p *route.Path, n *Neighbor) bool {
 	return false
 }
 
+// isDisallowedByCommunity checks the well-known communities restricting the advertisement of a path. Without
+// confederations NO_EXPORT_SUBCONFED restricts paths to the local AS just like NO_EXPORT (RFC1997).
 func isDisallowedByCommunity(p *route.Path, n *Neighbor) bool {
-	if p.BGPPath == nil || (p.BGPPath.Communities != nil && len(*p.BGPPath.Communities) == 0) {
-		return false
-	}
-
-	if p.BGPPath.Communities == nil {
+	if n.IgnoreWellKnownCommunities || p.BGPPath == nil || p.BGPPath.Communities == nil {
 		return false
 	}
 
 	for _, com := range *p.BGPPath.Communities {
-		if (com == types.WellKnownCommunityNoExport && !n.IBGP) || com == types.WellKnownCommunityNoAdvertise {
+		switch com {
+		case types.WellKnownCommunityNoAdvertise:
 			return true
+		case types.WellKnownCommunityNoExport, types.WellKnownCommunityNoExportSubconfed:
+			if !n.IBGP {
+				return true
+			}
+		case types.WellKnownCommunityNoPeer:
+			if n.BilateralPeer {
+				return true
+			}
 		}
 	}
 
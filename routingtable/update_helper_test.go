@@ -56,6 +56,40 @@ func TestShouldPropagateUpdate(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name:        "path with no-export-subconfed community",
+			communities: "(65535,65283)",
+			expected:    false,
+		},
+		{
+			name:        "path with no-export-subconfed community (iBGP)",
+			communities: "(65535,65283)",
+			neighbor: Neighbor{
+				IBGP: true,
+			},
+			expected: true,
+		},
+		{
+			name:        "path with nopeer community",
+			communities: "(65535,65284)",
+			expected:    true,
+		},
+		{
+			name:        "path with nopeer community (bilateral peer)",
+			communities: "(65535,65284)",
+			neighbor: Neighbor{
+				BilateralPeer: true,
+			},
+			expected: false,
+		},
+		{
+			name:        "path with no-advertise community (well-known communities ignored)",
+			communities: "(65535,65282)",
+			neighbor: Neighbor{
+				IgnoreWellKnownCommunities: true,
+			},
+			expected: true,
+		},
 	}
 
 	for _, test := range tests {
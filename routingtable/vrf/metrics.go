@@ -26,12 +26,16 @@ func MetricsForVRF(v *VRF) *metrics.VRFMetrics {
 	}
 
 	for family, rib := range v.ribs {
-		m.RIBs = append(m.RIBs, &metrics.RIBMetrics{
-			Name:       v.nameForRIB(rib),
-			AFI:        family.afi,
-			SAFI:       family.safi,
-			RouteCount: rib.Count(),
-		})
+		rm := &metrics.RIBMetrics{
+			Name:                 v.nameForRIB(rib),
+			AFI:                  family.afi,
+			SAFI:                 family.safi,
+			RouteCount:           rib.Count(),
+			RouteCountByProtocol: rib.RouteCountByProtocol(),
+			MemoryEstimate:       rib.SizeEstimate(),
+		}
+
+		m.RIBs = append(m.RIBs, rm)
 	}
 
 	return m
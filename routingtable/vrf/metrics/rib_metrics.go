@@ -13,4 +13,10 @@ type RIBMetrics struct {
 
 	// Number of routes in the RIB
 	RouteCount uint64
+
+	// Number of routes in the RIB by protocol of their best path
+	RouteCountByProtocol map[string]uint64
+
+	// Estimated memory used by the routes in the RIB in bytes
+	MemoryEstimate uint64
 }
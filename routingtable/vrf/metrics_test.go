@@ -15,8 +15,8 @@ import (
 func TestMetrics(t *testing.T) {
 	r := NewVRFRegistry()
 	green := r.CreateVRFIfNotExists("green", 0)
-	green.IPv4UnicastRIB().AddPath(bnet.NewPfx(bnet.IPv4FromOctets(8, 0, 0, 0), 8).Ptr(), &route.Path{})
-	green.IPv4UnicastRIB().AddPath(bnet.NewPfx(bnet.IPv4FromOctets(8, 0, 0, 0), 16).Ptr(), &route.Path{})
+	green.IPv4UnicastRIB().AddPath(bnet.NewPfx(bnet.IPv4FromOctets(8, 0, 0, 0), 8).Ptr(), &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{}})
+	green.IPv4UnicastRIB().AddPath(bnet.NewPfx(bnet.IPv4FromOctets(8, 0, 0, 0), 16).Ptr(), &route.Path{Type: route.StaticPathType, StaticPath: &route.StaticPath{}})
 	green.IPv6UnicastRIB().AddPath(bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0x678, 0x1e0, 0, 0, 0, 0, 0), 48).Ptr(), &route.Path{})
 
 	red := r.CreateVRFIfNotExists("red", 1)
@@ -29,16 +29,18 @@ func TestMetrics(t *testing.T) {
 			RD:   0,
 			RIBs: []*metrics.RIBMetrics{
 				{
-					Name:       "inet.0",
-					AFI:        afiIPv4,
-					SAFI:       safiUnicast,
-					RouteCount: 2,
+					Name:                 "inet.0",
+					AFI:                  afiIPv4,
+					SAFI:                 safiUnicast,
+					RouteCount:           2,
+					RouteCountByProtocol: map[string]uint64{"static": 2},
 				},
 				{
-					Name:       "inet6.0",
-					AFI:        afiIPv6,
-					SAFI:       safiUnicast,
-					RouteCount: 1,
+					Name:                 "inet6.0",
+					AFI:                  afiIPv6,
+					SAFI:                 safiUnicast,
+					RouteCount:           1,
+					RouteCountByProtocol: map[string]uint64{"unknown": 1},
 				},
 			},
 		},
@@ -47,16 +49,18 @@ func TestMetrics(t *testing.T) {
 			RD:   1,
 			RIBs: []*metrics.RIBMetrics{
 				{
-					Name:       "inet.0",
-					AFI:        afiIPv4,
-					SAFI:       safiUnicast,
-					RouteCount: 0,
+					Name:                 "inet.0",
+					AFI:                  afiIPv4,
+					SAFI:                 safiUnicast,
+					RouteCount:           0,
+					RouteCountByProtocol: map[string]uint64{},
 				},
 				{
-					Name:       "inet6.0",
-					AFI:        afiIPv6,
-					SAFI:       safiUnicast,
-					RouteCount: 2,
+					Name:                 "inet6.0",
+					AFI:                  afiIPv6,
+					SAFI:                 safiUnicast,
+					RouteCount:           2,
+					RouteCountByProtocol: map[string]uint64{"unknown": 2},
 				},
 			},
 		},
@@ -65,6 +69,13 @@ func TestMetrics(t *testing.T) {
 	actual := Metrics(r)
 	sortResult(actual)
 
+	for _, v := range actual {
+		for _, rib := range v.RIBs {
+			assert.Equal(t, rib.RouteCount > 0, rib.MemoryEstimate > 0, "memory estimate of %s %s", v.Name, rib.Name)
+			rib.MemoryEstimate = 0
+		}
+	}
+
 	assert.Equal(t, expected, actual)
 	_ = green
 }
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+	"github.com/bio-routing/bio-rd/route"
 	"github.com/bio-routing/bio-rd/routingtable/locRIB"
 	"github.com/pkg/errors"
 )
@@ -28,6 +30,9 @@ type VRF struct {
 	ribs               map[addressFamily]*locRIB.LocRIB
 	mu                 sync.Mutex
 	ribNames           map[string]*locRIB.LocRIB
+	importRouteTargets types.ExtendedCommunities
+	exportRouteTargets types.ExtendedCommunities
+	bestPathOptions    *route.BestPathOptions
 }
 
 // New creates a new VRF. The VRF is registered automatically to the global VRF registry.
@@ -64,6 +69,10 @@ func (v *VRF) createLocRIB(name string, family addressFamily) (*locRIB.LocRIB, e
 	}
 
 	rib := locRIB.New(name)
+	if v.bestPathOptions != nil {
+		rib.SetBestPathOptions(v.bestPathOptions)
+	}
+
 	v.ribs[family] = rib
 	v.ribNames[name] = rib
 
@@ -100,6 +109,47 @@ func (v *VRF) RD() uint64 {
 	return v.routeDistinguisher
 }
 
+// SetRouteTargets sets the route targets of VPN routes imported into the VRF and attached to routes exported from it (RFC4364 4.3.1)
+func (v *VRF) SetRouteTargets(importRTs types.ExtendedCommunities, exportRTs types.ExtendedCommunities) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.importRouteTargets = importRTs
+	v.exportRouteTargets = exportRTs
+}
+
+// SetBestPathOptions sets the options of the BGP decision process of all RIBs of the VRF
+func (v *VRF) SetBestPathOptions(o *route.BestPathOptions) {
+	v.mu.Lock()
+	v.bestPathOptions = o
+	ribs := make([]*locRIB.LocRIB, 0, len(v.ribs))
+	for _, rib := range v.ribs {
+		ribs = append(ribs, rib)
+	}
+	v.mu.Unlock()
+
+	// Clients of the RIBs might call back into the VRF
+	for _, rib := range ribs {
+		rib.SetBestPathOptions(o)
+	}
+}
+
+// ImportRouteTargets gets the route targets of VPN routes imported into the VRF
+func (v *VRF) ImportRouteTargets() types.ExtendedCommunities {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.importRouteTargets
+}
+
+// ExportRouteTargets gets the route targets attached to routes exported from the VRF
+func (v *VRF) ExportRouteTargets() types.ExtendedCommunities {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.exportRouteTargets
+}
+
 // Unregister removes this VRF from the global registry.
 func (v *VRF) Unregister() {
 	globalRegistry.UnregisterVRF(v)
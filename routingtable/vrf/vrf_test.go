@@ -3,6 +3,8 @@ package vrf
 import (
 	"testing"
 
+	"github.com/bio-routing/bio-rd/protocols/bgp/types"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -131,3 +133,12 @@ func TestParseHumanReadableRouteDistinguisher(t *testing.T) {
 		assert.Equal(t, test.expected, res, test.name)
 	}
 }
+
+func TestRouteTargets(t *testing.T) {
+	v := newUntrackedVRF("foo", 0)
+	assert.Empty(t, v.ImportRouteTargets())
+
+	v.SetRouteTargets(types.ExtendedCommunities{0x0002fde800000001}, types.ExtendedCommunities{0x0002fde800000002})
+	assert.Equal(t, types.ExtendedCommunities{0x0002fde800000001}, v.ImportRouteTargets())
+	assert.Equal(t, types.ExtendedCommunities{0x0002fde800000002}, v.ExportRouteTargets())
+}
@@ -0,0 +1,36 @@
+// Package bufpool provides pooled buffers to avoid allocating a fresh buffer per serialized packet
+package bufpool
+
+import (
+	"bytes"
+	"sync"
+)
+
+const (
+	// initialSize fits a maximum size BGP message
+	initialSize = 4096
+
+	// maxSize is the capacity above which buffers are not returned to the pool to limit memory usage
+	maxSize = 1 << 16
+)
+
+var pool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, initialSize))
+	},
+}
+
+// Get gets an empty buffer from the pool
+func Get() *bytes.Buffer {
+	return pool.Get().(*bytes.Buffer)
+}
+
+// Put returns buf to the pool. Neither buf nor slices obtained from it may be used afterwards.
+func Put(buf *bytes.Buffer) {
+	if buf.Cap() > maxSize {
+		return
+	}
+
+	buf.Reset()
+	pool.Put(buf)
+}
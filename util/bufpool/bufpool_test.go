@@ -0,0 +1,29 @@
+package bufpool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPut(t *testing.T) {
+	buf := Get()
+	assert.Equal(t, 0, buf.Len())
+
+	buf.WriteString("foo")
+	Put(buf)
+
+	buf = Get()
+	assert.Equal(t, 0, buf.Len(), "buffers from the pool must be empty")
+	Put(buf)
+}
+
+func TestPutDropsLargeBuffers(t *testing.T) {
+	buf := Get()
+	buf.Grow(maxSize + 1)
+	Put(buf)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, Get().Cap() <= maxSize)
+	}
+}
@@ -0,0 +1,75 @@
+// Package checksum implements the Fletcher checksum used by IS-IS LSPs and OSPF LSAs and the Internet checksum
+package checksum
+
+// fletcherChunk is the number of bytes that can be summed up before the sums need to be reduced.
+// 4096 bytes * 255 * 4096 stays far below the range of uint64.
+const fletcherChunk = 4096
+
+// Fletcher16 calculates the ISO 8473 Fletcher checksum of b (see RFC 1008) which is to be stored in b[offset:offset+2].
+// The checksum bytes in b have to be zero.
+func Fletcher16(b []byte, offset int) uint16 {
+	c0, c1 := fletcherSums(b)
+
+	x := mod255(int64(len(b)-offset-1)*c0 - c1)
+	y := mod255(-c0 - x)
+
+	return uint16(nonZero(x))<<8 | uint16(nonZero(y))
+}
+
+// Fletcher16Valid checks if b (including the checksum bytes) carries a valid Fletcher checksum
+func Fletcher16Valid(b []byte) bool {
+	c0, c1 := fletcherSums(b)
+	return c0 == 0 && c1 == 0
+}
+
+// UpdateFletcher16 sets b[pos] to value and adjusts the Fletcher checksum stored in b[offset:offset+2] accordingly.
+// This avoids recalculating the checksum over all of b. pos must not be within the checksum.
+func UpdateFletcher16(b []byte, offset int, pos int, value byte) {
+	d := int64(value) - int64(b[pos])
+	b[pos] = value
+
+	// Keep both sums at zero: dX + dY = -d and (L-k+1)*dX + (L-k)*dY = -(L-pos)*d with k = offset+1
+	x := mod255(int64(b[offset]) + int64(pos-offset-1)*d)
+	y := mod255(int64(b[offset+1]) + int64(offset-pos)*d)
+
+	b[offset] = byte(nonZero(x))
+	b[offset+1] = byte(nonZero(y))
+}
+
+func fletcherSums(b []byte) (c0 int64, c1 int64) {
+	for len(b) > 0 {
+		n := len(b)
+		if n > fletcherChunk {
+			n = fletcherChunk
+		}
+
+		for _, x := range b[:n] {
+			c0 += int64(x)
+			c1 += c0
+		}
+
+		c0 %= 255
+		c1 %= 255
+		b = b[n:]
+	}
+
+	return c0, c1
+}
+
+func mod255(x int64) int64 {
+	x %= 255
+	if x < 0 {
+		x += 255
+	}
+
+	return x
+}
+
+// nonZero maps 0 to 255 as the checksum bytes must not be zero (zero means no checksum)
+func nonZero(x int64) int64 {
+	if x == 0 {
+		return 255
+	}
+
+	return x
+}
@@ -0,0 +1,69 @@
+package checksum
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFletcher16(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		offset   int
+		expected uint16
+	}{
+		{
+			name: "IS-IS LSP",
+			input: []byte{
+				10, 20, 30, 40, 50, 60, 0, 0, // LSP ID
+				0, 0, 0, 1, // Sequence number
+				0, 0, // Checksum
+				3,                          // Type block
+				1, 6, 5, 0x49, 0, 1, 0, 16, // Area addresses TLV
+				0x81, 2, 0xcc, 0x8e, // Protocols supported TLV
+			},
+			offset:   12,
+			expected: 0x35ae,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Fletcher16(test.input, test.offset)
+			assert.Equal(t, test.expected, c)
+
+			test.input[test.offset] = byte(c >> 8)
+			test.input[test.offset+1] = byte(c)
+			assert.True(t, Fletcher16Valid(test.input))
+		})
+	}
+}
+
+func TestFletcher16Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, 2+r.Intn(10000))
+		r.Read(b)
+
+		offset := r.Intn(len(b) - 1)
+		b[offset] = 0
+		b[offset+1] = 0
+		c := Fletcher16(b, offset)
+		assert.NotEqual(t, byte(0), byte(c>>8))
+		assert.NotEqual(t, byte(0), byte(c))
+
+		b[offset] = byte(c >> 8)
+		b[offset+1] = byte(c)
+		assert.True(t, Fletcher16Valid(b))
+
+		pos := r.Intn(len(b))
+		if pos == offset || pos == offset+1 {
+			continue
+		}
+
+		UpdateFletcher16(b, offset, pos, byte(r.Intn(256)))
+		assert.True(t, Fletcher16Valid(b), "checksum not valid after update")
+	}
+}
@@ -0,0 +1,72 @@
+package checksum
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// Internet calculates the Internet checksum (RFC 1071) of b
+func Internet(b []byte) uint16 {
+	return Fold(Sum(b, 0))
+}
+
+// Sum adds the 16 bit words of b to the unfolded sum initial. This allows to checksum data spread over
+// several slices (e.g. a pseudo header and a payload). All slices but the last one must have an even length.
+func Sum(b []byte, initial uint32) uint32 {
+	sum := uint64(initial)
+	for len(b) >= 2 {
+		sum += uint64(binary.BigEndian.Uint16(b))
+		b = b[2:]
+	}
+
+	if len(b) == 1 {
+		sum += uint64(b[0]) << 8
+	}
+
+	for sum > 0xffffffff {
+		sum = sum>>32 + sum&0xffffffff
+	}
+
+	return uint32(sum)
+}
+
+// Fold folds an unfolded sum into the final checksum
+func Fold(sum uint32) uint16 {
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+
+	return ^uint16(sum)
+}
+
+// IPv4PseudoHeaderSum returns the unfolded sum of the IPv4 pseudo header (RFC 793) for an upper layer packet of length length
+func IPv4PseudoHeaderSum(src net.IP, dst net.IP, protocol uint8, length uint16) uint32 {
+	sum := Sum(src.To4(), 0)
+	sum = Sum(dst.To4(), sum)
+	return Sum([]byte{
+		0,
+		protocol,
+		byte(length >> 8),
+		byte(length),
+	}, sum)
+}
+
+// IPv6PseudoHeaderSum returns the unfolded sum of the IPv6 pseudo header (RFC 8200) for an upper layer packet of length length
+func IPv6PseudoHeaderSum(src net.IP, dst net.IP, nextHeader uint8, length uint32) uint32 {
+	sum := Sum(src.To16(), 0)
+	sum = Sum(dst.To16(), sum)
+	return Sum([]byte{
+		byte(length >> 24),
+		byte(length >> 16),
+		byte(length >> 8),
+		byte(length),
+		0, 0, 0,
+		nextHeader,
+	}, sum)
+}
+
+// UpdateInternet returns checksum adjusted for a 16 bit word of the checksummed data changing from old to new (RFC 1624)
+func UpdateInternet(checksum uint16, old uint16, new uint16) uint16 {
+	sum := uint32(^checksum) + uint32(^old) + uint32(new)
+	return Fold(sum)
+}
@@ -0,0 +1,82 @@
+package checksum
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternet(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected uint16
+	}{
+		{
+			name: "IPv4 header",
+			input: []byte{
+				0x45, 0x00, 0x00, 0x73, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11,
+				0x00, 0x00, // Checksum
+				0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0xc7,
+			},
+			expected: 0xb861,
+		},
+		{
+			name:     "RFC 1071 example",
+			input:    []byte{0x00, 0x01, 0xf2, 0x03, 0xf4, 0xf5, 0xf6, 0xf7},
+			expected: ^uint16(0xddf2),
+		},
+		{
+			name:     "odd length",
+			input:    []byte{0x00, 0x01, 0xf2},
+			expected: ^uint16(0xf201),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Internet(test.input))
+		})
+	}
+}
+
+func TestPseudoHeaderSums(t *testing.T) {
+	payload := []byte{1, 2, 3, 4, 5}
+
+	v4 := []byte{
+		192, 0, 2, 1,
+		192, 0, 2, 2,
+		0, 6, 0, 5,
+	}
+	assert.Equal(t, Internet(append(v4, payload...)),
+		Fold(Sum(payload, IPv4PseudoHeaderSum(net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2"), 6, 5))))
+
+	v6 := append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...)
+	v6 = append(v6, 0, 0, 0, 5, 0, 0, 0, 58)
+	assert.Equal(t, Internet(append(v6, payload...)),
+		Fold(Sum(payload, IPv6PseudoHeaderSum(net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), 58, 5))))
+}
+
+func TestUpdateInternet(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, 2*(1+r.Intn(100)))
+		r.Read(b)
+		c := Internet(b)
+
+		pos := 2 * r.Intn(len(b)/2)
+		old := binary.BigEndian.Uint16(b[pos:])
+		new := uint16(r.Intn(1 << 16))
+		binary.BigEndian.PutUint16(b[pos:], new)
+
+		// 0x0000 and 0xffff are equivalent in one's complement arithmetic
+		got := UpdateInternet(c, old, new)
+		expected := Internet(b)
+		if got != expected {
+			assert.Equal(t, uint16(0xffff), got^expected, "checksum mismatch after update")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package crashdump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxStackSize = 64 << 20
+)
+
+var (
+	defaultDumper   *Dumper
+	defaultDumperMu sync.RWMutex
+)
+
+// Section gets the state of a subsystem to be included in the dump. The result must be JSON serializable.
+type Section func() interface{}
+
+// Dump is the structured content of a crash dump file
+type Dump struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	Reason     string                 `json:"reason"`
+	Sections   map[string]interface{} `json:"sections"`
+	Goroutines string                 `json:"goroutines"`
+}
+
+// Dumper writes crash dumps
+type Dumper struct {
+	dir      string
+	name     string
+	sections map[string]Section
+	mu       sync.RWMutex
+}
+
+// New creates a new Dumper writing dumps named <name>-crash-<timestamp>.json to dir
+func New(dir string, name string) *Dumper {
+	return &Dumper{
+		dir:      dir,
+		name:     name,
+		sections: make(map[string]Section),
+	}
+}
+
+// AddSection adds a section to all future dumps
+func (d *Dumper) AddSection(name string, f Section) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sections[name] = f
+}
+
+// Write writes a dump and returns its path
+func (d *Dumper) Write(reason string) (string, error) {
+	dump := d.collect(reason)
+
+	b, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to marshal dump")
+	}
+
+	err = os.MkdirAll(d.dir, 0700)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to create dump directory")
+	}
+
+	path := filepath.Join(d.dir, fmt.Sprintf("%s-crash-%s.json", d.name, dump.Timestamp.Format("20060102T150405.000000000")))
+	err = ioutil.WriteFile(path, b, 0600)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to write dump")
+	}
+
+	return path, nil
+}
+
+func (d *Dumper) collect(reason string) *Dump {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	dump := &Dump{
+		Timestamp:  time.Now(),
+		Reason:     reason,
+		Sections:   make(map[string]interface{}),
+		Goroutines: goroutines(),
+	}
+
+	for name, f := range d.sections {
+		dump.Sections[name] = collectSection(f)
+	}
+
+	return dump
+}
+
+// collectSection runs a section. As the process is possibly in a broken state, a panicking section must not prevent the dump.
+func collectSection(f Section) (res interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = fmt.Sprintf("failed to collect section: %v", r)
+		}
+	}()
+
+	return f()
+}
+
+func goroutines() string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackSize {
+			return string(buf[:n])
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// Recover writes a dump if the calling goroutine panics and then continues panicking. Use it as `defer d.Recover()`.
+func (d *Dumper) Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	d.writePanic(r)
+	panic(r)
+}
+
+func (d *Dumper) writePanic(r interface{}) {
+	path, err := d.Write(fmt.Sprintf("panic: %v", r))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write crash dump: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Crash dump written to %s\n", path)
+}
+
+// SetDefault sets the dumper used by Recover and WritePanic
+func SetDefault(d *Dumper) {
+	defaultDumperMu.Lock()
+	defer defaultDumperMu.Unlock()
+
+	defaultDumper = d
+}
+
+func getDefault() *Dumper {
+	defaultDumperMu.RLock()
+	defer defaultDumperMu.RUnlock()
+
+	return defaultDumper
+}
+
+// Recover writes a dump using the default dumper if the calling goroutine panics and then continues panicking.
+// Long running goroutines of libraries use it as `defer crashdump.Recover()`. Without a default dumper it only
+// continues panicking.
+func Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	WritePanic(r)
+	panic(r)
+}
+
+// WritePanic writes a dump for a recovered panic using the default dumper, if set
+func WritePanic(r interface{}) {
+	d := getDefault()
+	if d == nil {
+		return
+	}
+
+	d.writePanic(r)
+}
@@ -0,0 +1,96 @@
+package crashdump
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crashdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := New(dir, "test")
+	d.AddSection("peers", func() interface{} {
+		return []string{"192.0.2.1"}
+	})
+	d.AddSection("broken", func() interface{} {
+		panic("nil map")
+	})
+
+	path, err := d.Write("fatal: something broke")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dump := &Dump{}
+	err = json.Unmarshal(b, dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "fatal: something broke", dump.Reason)
+	assert.Equal(t, []interface{}{"192.0.2.1"}, dump.Sections["peers"])
+	assert.Equal(t, "failed to collect section: nil map", dump.Sections["broken"])
+	assert.True(t, strings.Contains(dump.Goroutines, "TestWrite"))
+}
+
+func TestRecover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crashdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := New(dir, "test")
+	assert.PanicsWithValue(t, "boom", func() {
+		defer d.Recover()
+		panic("boom")
+	})
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(files))
+}
+
+func TestDefaultRecover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crashdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Without a default dumper the panic continues without a dump
+	assert.PanicsWithValue(t, "boom", func() {
+		defer Recover()
+		panic("boom")
+	})
+
+	SetDefault(New(dir, "test"))
+	defer SetDefault(nil)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer Recover()
+		panic("boom")
+	})
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(files))
+}
@@ -3,15 +3,16 @@ package decode
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
 
 	"github.com/pkg/errors"
 )
 
-// Decode reads fields from a buffer
+// Decode reads fields from a buffer. Fields of type *uint8, *uint16, *uint32, *uint64, []byte and *[]byte
+// are decoded without reflection. All other types fall back to binary.Read.
 func Decode(buf *bytes.Buffer, fields []interface{}) error {
-	var err error
 	for _, field := range fields {
-		err = binary.Read(buf, binary.BigEndian, field)
+		err := decodeField(buf, field)
 		if err != nil {
 			return errors.Wrap(err, "Unable to read from buffer")
 		}
@@ -19,6 +20,39 @@ func Decode(buf *bytes.Buffer, fields []interface{}) error {
 	return nil
 }
 
+func decodeField(buf *bytes.Buffer, field interface{}) error {
+	switch f := field.(type) {
+	case *uint8:
+		return DecodeUint8(buf, f)
+	case *uint16:
+		return DecodeUint16(buf, f)
+	case *uint32:
+		return DecodeUint32(buf, f)
+	case *uint64:
+		return DecodeUint64(buf, f)
+	case []byte:
+		return DecodeBytes(buf, f)
+	case *[]byte:
+		return DecodeBytes(buf, *f)
+	}
+
+	return binary.Read(buf, binary.BigEndian, field)
+}
+
+// next returns the next n bytes of buf. Errors match the ones of binary.Read.
+func next(buf *bytes.Buffer, n int) ([]byte, error) {
+	if buf.Len() >= n {
+		return buf.Next(n), nil
+	}
+
+	if buf.Len() == 0 {
+		return nil, io.EOF
+	}
+
+	buf.Next(buf.Len())
+	return nil, io.ErrUnexpectedEOF
+}
+
 // DecodeUint8 decodes an uint8
 func DecodeUint8(buf *bytes.Buffer, x *uint8) error {
 	y, err := buf.ReadByte()
@@ -32,42 +66,48 @@ func DecodeUint8(buf *bytes.Buffer, x *uint8) error {
 
 // DecodeUint16 decodes an uint16
 func DecodeUint16(buf *bytes.Buffer, x *uint16) error {
-	a, err := buf.ReadByte()
-	if err != nil {
-		return err
-	}
-
-	b, err := buf.ReadByte()
+	b, err := next(buf, 2)
 	if err != nil {
 		return err
 	}
 
-	*x = uint16(a)<<8 + uint16(b)
+	*x = binary.BigEndian.Uint16(b)
 	return nil
 }
 
 // DecodeUint32 decodes an uint32
 func DecodeUint32(buf *bytes.Buffer, x *uint32) error {
-	a, err := buf.ReadByte()
+	b, err := next(buf, 4)
 	if err != nil {
 		return err
 	}
 
-	b, err := buf.ReadByte()
+	*x = binary.BigEndian.Uint32(b)
+	return nil
+}
+
+// DecodeUint64 decodes an uint64
+func DecodeUint64(buf *bytes.Buffer, x *uint64) error {
+	b, err := next(buf, 8)
 	if err != nil {
 		return err
 	}
 
-	c, err := buf.ReadByte()
-	if err != nil {
-		return err
+	*x = binary.BigEndian.Uint64(b)
+	return nil
+}
+
+// DecodeBytes fills x from the buffer
+func DecodeBytes(buf *bytes.Buffer, x []byte) error {
+	if len(x) == 0 {
+		return nil
 	}
 
-	d, err := buf.ReadByte()
+	b, err := next(buf, len(x))
 	if err != nil {
 		return err
 	}
 
-	*x = uint32(a)<<24 + uint32(b)<<16 + uint32(c)<<8 + uint32(d)
+	copy(x, b)
 	return nil
 }
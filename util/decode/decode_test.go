@@ -0,0 +1,161 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	type testData struct {
+		a uint8
+		b uint16
+		c uint32
+		d uint64
+		e []byte
+		f [2]byte
+		g [3]byte
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected testData
+		wantFail bool
+	}{
+		{
+			name: "valid input",
+			input: []byte{
+				1,
+				0, 2,
+				0, 0, 0, 3,
+				0, 0, 0, 0, 0, 0, 0, 4,
+				5, 6,
+				7, 8,
+				9, 10, 11,
+			},
+			expected: testData{
+				a: 1,
+				b: 2,
+				c: 3,
+				d: 4,
+				e: []byte{5, 6},
+				f: [2]byte{7, 8},
+				g: [3]byte{9, 10, 11},
+			},
+		},
+		{
+			name: "input too short",
+			input: []byte{
+				1,
+				0, 2,
+				0, 0, 0, 3,
+				0, 0, 0, 0, 0, 0, 0,
+			},
+			wantFail: true,
+		},
+		{
+			name:     "input null",
+			wantFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := testData{
+				e: make([]byte, 2),
+			}
+
+			fields := []interface{}{
+				&s.a,
+				&s.b,
+				&s.c,
+				&s.d,
+				&s.e,
+				s.f[:],
+				&s.g,
+			}
+
+			err := Decode(bytes.NewBuffer(test.input), fields)
+			if err != nil {
+				if !test.wantFail {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if test.wantFail {
+				t.Fatalf("Expected error, got none")
+			}
+
+			assert.Equal(t, test.expected, s)
+		})
+	}
+}
+
+func TestDecodeErrorsMatchBinaryRead(t *testing.T) {
+	inputs := [][]byte{
+		nil,
+		{1},
+		{1, 2, 3},
+	}
+
+	for _, input := range inputs {
+		var x, y uint32
+		bufX := bytes.NewBuffer(input)
+		bufY := bytes.NewBuffer(input)
+
+		errX := DecodeUint32(bufX, &x)
+		errY := binary.Read(bufY, binary.BigEndian, &y)
+		assert.Equal(t, errY, errX, "input %v", input)
+		assert.Equal(t, bufY.Len(), bufX.Len(), "input %v", input)
+	}
+}
+
+var benchmarkInput = []byte{
+	1,
+	0, 2,
+	0, 0, 0, 3,
+	0, 0, 0, 0, 0, 0, 0, 4,
+	5, 6, 7, 8, 9, 10,
+}
+
+func BenchmarkDecode(b *testing.B) {
+	var u8 uint8
+	var u16 uint16
+	var u32 uint32
+	var u64 uint64
+	bs := make([]byte, 6)
+	fields := []interface{}{&u8, &u16, &u32, &u64, &bs}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := Decode(bytes.NewBuffer(benchmarkInput), fields)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryRead(b *testing.B) {
+	var u8 uint8
+	var u16 uint16
+	var u32 uint32
+	var u64 uint64
+	bs := make([]byte, 6)
+	fields := []interface{}{&u8, &u16, &u32, &u64, &bs}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := bytes.NewBuffer(benchmarkInput)
+		for _, f := range fields {
+			err := binary.Read(buf, binary.BigEndian, f)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -1,139 +1,335 @@
 package dijkstra
 
-// Topology represents a network topology
-type Topology struct {
-	nodes map[Node]int64
-	edges map[Node]map[Node]int64
+import (
+	"container/heap"
+	"sort"
+)
+
+// Topology represents a network topology with nodes of type N and edges carrying payloads of type E
+type Topology[N comparable, E any] struct {
+	// nodes maps all nodes to the order they were added in. The order makes results deterministic.
+	nodes     map[N]int
+	nextIndex int
+	edges     map[N]map[N]edgeAttributes[E]
+	reverse   map[N]map[N]edgeAttributes[E]
+	spts      map[*IncrementalSPT[N, E]]struct{}
+
+	// overloaded nodes are reachable but not used for transit
+	overloaded map[N]struct{}
+
+	// excluded edges are not used at all
+	excluded map[edgeKey[N]]struct{}
+}
+
+type edgeKey[N comparable] struct {
+	a N
+	b N
+}
+
+type edgeAttributes[E any] struct {
+	distance int64
+	payload  E
 }
 
-// Node represents a node in a graph
+// Node is a simple node type identified by name
 type Node struct {
 	Name string
 }
 
-// Edge represents a directed edge in a graph
-type Edge struct {
-	NodeA    Node
-	NodeB    Node
+// Edge represents a directed edge in a graph. Payload carries arbitrary data (e.g. interface IDs or SIDs).
+type Edge[N comparable, E any] struct {
+	NodeA    N
+	NodeB    N
 	Distance int64
+	Payload  E
 }
 
 // SPT represents a shortest path tree
-type SPT map[Node]Path
+type SPT[N comparable, E any] map[N]Path[N, E]
 
 // Path represents a path through a graph
-type Path struct {
-	Edges    []Edge
+type Path[N comparable, E any] struct {
+	Edges    []Edge[N, E]
 	Distance int64
+
+	// EqualCostEdges are all shortest paths to the node (including Edges). Only set if ECMP was requested.
+	EqualCostEdges [][]Edge[N, E]
+
+	// NextHops are the first hops of all shortest paths to the node. Only set if ECMP was requested.
+	NextHops []N
+}
+
+// SPTOptions controls the SPT calculation
+type SPTOptions struct {
+	// ECMP makes the SPT contain all equal cost paths per destination
+	ECMP bool
 }
 
 // NewTopology creates a new topology
-func NewTopology(nodes []Node, edges []Edge) *Topology {
-	t := &Topology{
-		nodes: make(map[Node]int64),
-		edges: make(map[Node]map[Node]int64),
+func NewTopology[N comparable, E any](nodes []N, edges []Edge[N, E]) *Topology[N, E] {
+	t := &Topology[N, E]{
+		nodes:   make(map[N]int),
+		edges:   make(map[N]map[N]edgeAttributes[E]),
+		reverse: make(map[N]map[N]edgeAttributes[E]),
+		spts:    make(map[*IncrementalSPT[N, E]]struct{}),
+
+		overloaded: make(map[N]struct{}),
+		excluded:   make(map[edgeKey[N]]struct{}),
 	}
 
 	for _, n := range nodes {
-		t.nodes[n] = -1
+		t.addNode(n)
 	}
 
 	for _, e := range edges {
-		if _, ok := t.edges[e.NodeA]; !ok {
-			t.edges[e.NodeA] = make(map[Node]int64)
-		}
-
-		t.edges[e.NodeA][e.NodeB] = e.Distance
+		t.setEdge(e)
 	}
 
 	return t
 }
 
-func (t *Topology) newSPT() SPT {
-	spt := make(SPT)
+func (t *Topology[N, E]) addNode(n N) {
+	t.nodes[n] = t.nextIndex
+	t.nextIndex++
+}
 
-	for n := range t.nodes {
-		spt[n] = Path{
-			Edges:    make([]Edge, 0),
-			Distance: -1,
-		}
+func (t *Topology[N, E]) edge(a, b N, attrs edgeAttributes[E]) Edge[N, E] {
+	return Edge[N, E]{
+		NodeA:    a,
+		NodeB:    b,
+		Distance: attrs.distance,
+		Payload:  attrs.payload,
 	}
+}
 
-	return spt
+// usable returns if the edge from a to b may be used in a calculation rooted at root
+func (t *Topology[N, E]) usable(a, b, root N) bool {
+	if _, ok := t.excluded[edgeKey[N]{a: a, b: b}]; ok {
+		return false
+	}
+
+	if a == root {
+		return true
+	}
+
+	_, overloaded := t.overloaded[a]
+	return !overloaded
+}
+
+// less orders nodes by the time they were added to the topology
+func (t *Topology[N, E]) less(a, b N) bool {
+	return t.nodes[a] < t.nodes[b]
 }
 
 // SPT calculates the shortest path tree
-func (t *Topology) SPT(from Node) SPT {
-	spt := t.newSPT()
+func (t *Topology[N, E]) SPT(from N) SPT[N, E] {
+	return t.SPTWithOptions(from, SPTOptions{})
+}
+
+// SPTWithOptions calculates the shortest path tree using options opts
+func (t *Topology[N, E]) SPTWithOptions(from N, opts SPTOptions) SPT[N, E] {
+	s := newShortestPaths(t, from, opts)
+	s.calculate()
+	return s.spt()
+}
+
+// shortestPaths is the state of a shortest path calculation
+type shortestPaths[N comparable, E any] struct {
+	t         *Topology[N, E]
+	root      N
+	opts      SPTOptions
+	distances map[N]int64
+
+	// predecessors holds the last edge of all shortest paths to a node
+	predecessors map[N][]Edge[N, E]
+}
+
+func newShortestPaths[N comparable, E any](t *Topology[N, E], root N, opts SPTOptions) *shortestPaths[N, E] {
+	return &shortestPaths[N, E]{
+		t:    t,
+		root: root,
+		opts: opts,
+	}
+}
 
-	tmp := spt[from]
-	tmp.Distance = 0
-	spt[from] = tmp
+func (s *shortestPaths[N, E]) calculate() {
+	s.distances = map[N]int64{
+		s.root: 0,
+	}
+	s.predecessors = make(map[N][]Edge[N, E])
 
-	unmarked := make(map[Node]struct{})
-	for n := range t.nodes {
-		if n == from {
+	q := &priorityQueue[N]{
+		{
+			node:     s.root,
+			distance: 0,
+		},
+	}
+	s.run(q, make(map[N]struct{}))
+}
+
+// run processes queued nodes in order of their distance. Distances of nodes in final are not changed.
+func (s *shortestPaths[N, E]) run(q *priorityQueue[N], final map[N]struct{}) {
+	for q.Len() > 0 {
+		item := heap.Pop(q).(queueItem[N])
+		if _, ok := final[item.node]; ok {
+			continue
+		}
+
+		if d, ok := s.distances[item.node]; !ok || d != item.distance {
+			// outdated queue item
 			continue
 		}
-		unmarked[n] = struct{}{}
-	}
-
-	for len(unmarked) > 0 {
-		for neighbor, distance := range t.edges[from] {
-			if spt[neighbor].Distance == -1 {
-				tmp := spt[neighbor]
-				tmp.Distance = spt[from].Distance + distance
-				tmp.Edges = make([]Edge, len(spt[from].Edges)+1)
-				copy(tmp.Edges, spt[from].Edges)
-				tmp.Edges[len(spt[from].Edges)] = Edge{
-					NodeA:    from,
-					NodeB:    neighbor,
-					Distance: distance,
-				}
-				spt[neighbor] = tmp
+
+		final[item.node] = struct{}{}
+
+		for neighbor, attrs := range s.t.edges[item.node] {
+			if _, ok := final[neighbor]; ok {
 				continue
 			}
 
-			if spt[from].Distance+distance < spt[neighbor].Distance {
-				tmp := spt[neighbor]
-				tmp.Distance = spt[from].Distance + distance
-				tmp.Edges = make([]Edge, len(spt[from].Edges)+1)
-				copy(tmp.Edges, spt[from].Edges)
-				tmp.Edges[len(spt[from].Edges)] = Edge{
-					NodeA:    from,
-					NodeB:    neighbor,
-					Distance: distance,
-				}
-				spt[neighbor] = tmp
+			if !s.t.usable(item.node, neighbor, s.root) {
 				continue
 			}
+
+			s.relax(s.t.edge(item.node, neighbor, attrs), q)
 		}
+	}
+}
 
-		var next *Node
-		nextDistance := int64(0)
-		for candidate := range unmarked {
-			if spt[candidate].Distance == -1 {
-				continue
-			}
+// relax records e if it provides a shorter (or with ECMP an equal cost) path to e.NodeB
+func (s *shortestPaths[N, E]) relax(e Edge[N, E], q *priorityQueue[N]) {
+	if _, ok := s.t.nodes[e.NodeB]; !ok {
+		return
+	}
 
-			if next == nil {
-				tmp := candidate
-				next = &tmp
-				nextDistance = spt[candidate].Distance
-				continue
+	d := s.distances[e.NodeA] + e.Distance
+	current, reached := s.distances[e.NodeB]
+	if !reached || d < current {
+		s.distances[e.NodeB] = d
+		s.predecessors[e.NodeB] = []Edge[N, E]{e}
+		heap.Push(q, queueItem[N]{
+			node:     e.NodeB,
+			distance: d,
+		})
+		return
+	}
+
+	if d == current && s.opts.ECMP && edgeIndex(s.predecessors[e.NodeB], e.NodeA) < 0 {
+		s.predecessors[e.NodeB] = append(s.predecessors[e.NodeB], e)
+	}
+}
+
+// edgeIndex returns the index of the edge starting at a in edges or -1
+func edgeIndex[N comparable, E any](edges []Edge[N, E], a N) int {
+	for i, e := range edges {
+		if e.NodeA == a {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *shortestPaths[N, E]) spt() SPT[N, E] {
+	spt := make(SPT[N, E])
+	paths := make(map[N][][]Edge[N, E])
+
+	for n := range s.t.nodes {
+		d, reached := s.distances[n]
+		if !reached {
+			spt[n] = Path[N, E]{
+				Edges:    make([]Edge[N, E], 0),
+				Distance: -1,
 			}
+			continue
+		}
 
-			if spt[candidate].Distance < nextDistance {
-				tmp := candidate
-				next = &tmp
-				nextDistance = spt[candidate].Distance
-				continue
+		if !s.opts.ECMP {
+			spt[n] = Path[N, E]{
+				Edges:    firstPath(n, s.predecessors),
+				Distance: d,
 			}
+			continue
 		}
 
-		from = *next
-		delete(unmarked, from)
+		all := s.allPaths(n, paths)
+		spt[n] = Path[N, E]{
+			Edges:          all[0],
+			Distance:       d,
+			EqualCostEdges: all,
+			NextHops:       s.nextHops(all),
+		}
 	}
 
 	return spt
 }
+
+// firstPath follows the first predecessor of each node back to the root
+func firstPath[N comparable, E any](n N, predecessors map[N][]Edge[N, E]) []Edge[N, E] {
+	hops := 0
+	for m := n; len(predecessors[m]) > 0; m = predecessors[m][0].NodeA {
+		hops++
+	}
+
+	ret := make([]Edge[N, E], hops)
+	for i := hops - 1; i >= 0; i-- {
+		ret[i] = predecessors[n][0]
+		n = ret[i].NodeA
+	}
+
+	return ret
+}
+
+// allPaths enumerates all shortest paths to n. Results are memoized in cache.
+func (s *shortestPaths[N, E]) allPaths(n N, cache map[N][][]Edge[N, E]) [][]Edge[N, E] {
+	if ret, ok := cache[n]; ok {
+		return ret
+	}
+
+	preds, ok := s.predecessors[n]
+	if !ok {
+		ret := [][]Edge[N, E]{make([]Edge[N, E], 0)}
+		cache[n] = ret
+		return ret
+	}
+
+	sort.Slice(preds, func(i, j int) bool {
+		return s.t.less(preds[i].NodeA, preds[j].NodeA)
+	})
+
+	ret := make([][]Edge[N, E], 0, len(preds))
+	for _, e := range preds {
+		for _, p := range s.allPaths(e.NodeA, cache) {
+			path := make([]Edge[N, E], len(p)+1)
+			copy(path, p)
+			path[len(p)] = e
+			ret = append(ret, path)
+		}
+	}
+
+	cache[n] = ret
+	return ret
+}
+
+func (s *shortestPaths[N, E]) nextHops(paths [][]Edge[N, E]) []N {
+	seen := make(map[N]struct{})
+	ret := make([]N, 0)
+	for _, p := range paths {
+		if len(p) == 0 {
+			continue
+		}
+
+		if _, ok := seen[p[0].NodeB]; ok {
+			continue
+		}
+
+		seen[p[0].NodeB] = struct{}{}
+		ret = append(ret, p[0].NodeB)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return s.t.less(ret[i], ret[j])
+	})
+
+	return ret
+}
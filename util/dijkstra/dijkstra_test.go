@@ -1,17 +1,25 @@
 package dijkstra
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+type (
+	testTopology = Topology[Node, struct{}]
+	testEdge     = Edge[Node, struct{}]
+	testPath     = Path[Node, struct{}]
+	testSPT      = SPT[Node, struct{}]
+)
+
 func TestSPT(t *testing.T) {
 	tests := []struct {
 		name     string
 		nodes    []Node
-		edges    []Edge
-		expected SPT
+		edges    []testEdge
+		expected testSPT
 	}{
 		{
 			name: "Test #1",
@@ -29,7 +37,7 @@ func TestSPT(t *testing.T) {
 					Name: "D",
 				},
 			},
-			edges: []Edge{
+			edges: []testEdge{
 				{
 					NodeA:    Node{Name: "A"},
 					NodeB:    Node{Name: "B"},
@@ -81,13 +89,13 @@ func TestSPT(t *testing.T) {
 					Distance: 1,
 				},
 			},
-			expected: SPT{
-				Node{Name: "A"}: Path{
-					Edges:    []Edge{},
+			expected: testSPT{
+				Node{Name: "A"}: testPath{
+					Edges:    []testEdge{},
 					Distance: 0,
 				},
-				Node{Name: "B"}: Path{
-					Edges: []Edge{
+				Node{Name: "B"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "B"},
@@ -96,8 +104,8 @@ func TestSPT(t *testing.T) {
 					},
 					Distance: 1,
 				},
-				Node{Name: "C"}: Path{
-					Edges: []Edge{
+				Node{Name: "C"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "B"},
@@ -116,8 +124,8 @@ func TestSPT(t *testing.T) {
 					},
 					Distance: 3,
 				},
-				Node{Name: "D"}: Path{
-					Edges: []Edge{
+				Node{Name: "D"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "B"},
@@ -152,7 +160,7 @@ func TestSPT(t *testing.T) {
 					Name: "E",
 				},
 			},
-			edges: []Edge{
+			edges: []testEdge{
 				{
 					NodeA:    Node{Name: "A"},
 					NodeB:    Node{Name: "B"},
@@ -179,13 +187,13 @@ func TestSPT(t *testing.T) {
 					Distance: 5,
 				},
 			},
-			expected: SPT{
-				Node{Name: "A"}: Path{
-					Edges:    []Edge{},
+			expected: testSPT{
+				Node{Name: "A"}: testPath{
+					Edges:    []testEdge{},
 					Distance: 0,
 				},
-				Node{Name: "B"}: Path{
-					Edges: []Edge{
+				Node{Name: "B"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "B"},
@@ -194,8 +202,8 @@ func TestSPT(t *testing.T) {
 					},
 					Distance: 1,
 				},
-				Node{Name: "C"}: Path{
-					Edges: []Edge{
+				Node{Name: "C"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "E"},
@@ -209,8 +217,8 @@ func TestSPT(t *testing.T) {
 					},
 					Distance: 8,
 				},
-				Node{Name: "D"}: Path{
-					Edges: []Edge{
+				Node{Name: "D"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "D"},
@@ -219,8 +227,8 @@ func TestSPT(t *testing.T) {
 					},
 					Distance: 2,
 				},
-				Node{Name: "E"}: Path{
-					Edges: []Edge{
+				Node{Name: "E"}: testPath{
+					Edges: []testEdge{
 						{
 							NodeA:    Node{Name: "A"},
 							NodeB:    Node{Name: "E"},
@@ -240,3 +248,173 @@ func TestSPT(t *testing.T) {
 		assert.Equalf(t, test.expected, spt, "Test %q", test.name)
 	}
 }
+
+func TestSPTECMP(t *testing.T) {
+	a, b, c, d, e := Node{Name: "A"}, Node{Name: "B"}, Node{Name: "C"}, Node{Name: "D"}, Node{Name: "E"}
+
+	tests := []struct {
+		name     string
+		nodes    []Node
+		edges    []testEdge
+		expected testSPT
+	}{
+		{
+			name:  "Diamond",
+			nodes: []Node{a, b, c, d, e},
+			edges: []testEdge{
+				{NodeA: a, NodeB: b, Distance: 1},
+				{NodeA: a, NodeB: c, Distance: 2},
+				{NodeA: b, NodeB: d, Distance: 2},
+				{NodeA: c, NodeB: d, Distance: 1},
+				{NodeA: d, NodeB: e, Distance: 1},
+			},
+			expected: testSPT{
+				a: testPath{
+					Edges:          []testEdge{},
+					Distance:       0,
+					EqualCostEdges: [][]testEdge{{}},
+					NextHops:       []Node{},
+				},
+				b: testPath{
+					Edges:          []testEdge{{NodeA: a, NodeB: b, Distance: 1}},
+					Distance:       1,
+					EqualCostEdges: [][]testEdge{{{NodeA: a, NodeB: b, Distance: 1}}},
+					NextHops:       []Node{b},
+				},
+				c: testPath{
+					Edges:          []testEdge{{NodeA: a, NodeB: c, Distance: 2}},
+					Distance:       2,
+					EqualCostEdges: [][]testEdge{{{NodeA: a, NodeB: c, Distance: 2}}},
+					NextHops:       []Node{c},
+				},
+				d: testPath{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: d, Distance: 2}},
+					Distance: 3,
+					EqualCostEdges: [][]testEdge{
+						{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: d, Distance: 2}},
+						{{NodeA: a, NodeB: c, Distance: 2}, {NodeA: c, NodeB: d, Distance: 1}},
+					},
+					NextHops: []Node{b, c},
+				},
+				e: testPath{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: d, Distance: 2}, {NodeA: d, NodeB: e, Distance: 1}},
+					Distance: 4,
+					EqualCostEdges: [][]testEdge{
+						{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: d, Distance: 2}, {NodeA: d, NodeB: e, Distance: 1}},
+						{{NodeA: a, NodeB: c, Distance: 2}, {NodeA: c, NodeB: d, Distance: 1}, {NodeA: d, NodeB: e, Distance: 1}},
+					},
+					NextHops: []Node{b, c},
+				},
+			},
+		},
+		{
+			name:  "Unreachable node",
+			nodes: []Node{a, b},
+			edges: []testEdge{},
+			expected: testSPT{
+				a: testPath{
+					Edges:          []testEdge{},
+					Distance:       0,
+					EqualCostEdges: [][]testEdge{{}},
+					NextHops:       []Node{},
+				},
+				b: testPath{
+					Edges:    []testEdge{},
+					Distance: -1,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		top := NewTopology(test.nodes, test.edges)
+		spt := top.SPTWithOptions(a, SPTOptions{ECMP: true})
+
+		assert.Equalf(t, test.expected, spt, "Test %q", test.name)
+	}
+}
+
+func BenchmarkSPT(b *testing.B) {
+	const size = 100
+
+	nodes := make([]Node, 0, size*size)
+	edges := make([]testEdge, 0, 4*size*size)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			n := Node{Name: fmt.Sprintf("%d-%d", x, y)}
+			nodes = append(nodes, n)
+
+			if x > 0 {
+				m := Node{Name: fmt.Sprintf("%d-%d", x-1, y)}
+				edges = append(edges, testEdge{NodeA: n, NodeB: m, Distance: int64(x + y)}, testEdge{NodeA: m, NodeB: n, Distance: int64(x + y)})
+			}
+
+			if y > 0 {
+				m := Node{Name: fmt.Sprintf("%d-%d", x, y-1)}
+				edges = append(edges, testEdge{NodeA: n, NodeB: m, Distance: int64(x * y)}, testEdge{NodeA: m, NodeB: n, Distance: int64(x * y)})
+			}
+		}
+	}
+
+	top := NewTopology(nodes, edges)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		top.SPT(nodes[0])
+	}
+}
+
+type testRouter struct {
+	RouterID uint32
+}
+
+type testLink struct {
+	Interface string
+	SIDs      []uint32
+}
+
+func TestSPTPayload(t *testing.T) {
+	r1, r2, r3 := testRouter{RouterID: 1}, testRouter{RouterID: 2}, testRouter{RouterID: 3}
+	top := NewTopology([]testRouter{r1, r2, r3}, []Edge[testRouter, testLink]{
+		{NodeA: r1, NodeB: r2, Distance: 10, Payload: testLink{Interface: "eth0", SIDs: []uint32{16002}}},
+		{NodeA: r2, NodeB: r3, Distance: 10, Payload: testLink{Interface: "eth1", SIDs: []uint32{16003}}},
+	})
+
+	spt := top.NewIncrementalSPT(r1, SPTOptions{})
+	assert.Equal(t, Path[testRouter, testLink]{
+		Edges: []Edge[testRouter, testLink]{
+			{NodeA: r1, NodeB: r2, Distance: 10, Payload: testLink{Interface: "eth0", SIDs: []uint32{16002}}},
+			{NodeA: r2, NodeB: r3, Distance: 10, Payload: testLink{Interface: "eth1", SIDs: []uint32{16003}}},
+		},
+		Distance: 20,
+	}, spt.SPT()[r3])
+
+	top.SetEdge(Edge[testRouter, testLink]{NodeA: r1, NodeB: r2, Distance: 10, Payload: testLink{Interface: "eth2"}})
+	assert.Equal(t, "eth2", spt.SPT()[r3].Edges[0].Payload.Interface)
+}
+
+func TestSPTOverloadAndExclusion(t *testing.T) {
+	a, b, c, d := Node{Name: "A"}, Node{Name: "B"}, Node{Name: "C"}, Node{Name: "D"}
+	top := NewTopology([]Node{a, b, c, d}, []testEdge{
+		{NodeA: a, NodeB: b, Distance: 1},
+		{NodeA: b, NodeB: c, Distance: 1},
+		{NodeA: a, NodeB: d, Distance: 5},
+		{NodeA: d, NodeB: c, Distance: 5},
+	})
+
+	top.SetOverloaded(b, true)
+	spt := top.SPT(a)
+	assert.Equal(t, int64(1), spt[b].Distance, "overloaded node is reachable")
+	assert.Equal(t, int64(10), spt[c].Distance, "overloaded node is not used for transit")
+	assert.Equal(t, int64(1), top.SPT(b)[c].Distance, "overload does not affect the root")
+	assert.Equal(t, 1, len(top.KShortestPaths(a, c, 3)))
+	for _, p := range top.DisjointPaths(a, c, LinkDisjoint) {
+		assert.Equal(t, int64(10), p.Distance)
+	}
+
+	top.SetEdgeExcluded(d, c, true)
+	assert.Equal(t, int64(-1), top.SPT(a)[c].Distance)
+
+	top.SetOverloaded(b, false)
+	assert.Equal(t, int64(2), top.SPT(a)[c].Distance)
+}
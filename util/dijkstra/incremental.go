@@ -0,0 +1,319 @@
+package dijkstra
+
+// IncrementalSPT is a shortest path tree which is updated incrementally when its topology changes.
+// Changes must be made through the Topology methods. Edge distances must be positive.
+type IncrementalSPT[N comparable, E any] struct {
+	sp *shortestPaths[N, E]
+}
+
+// NewIncrementalSPT calculates the shortest path tree rooted at from and keeps it up to date on topology changes
+func (t *Topology[N, E]) NewIncrementalSPT(from N, opts SPTOptions) *IncrementalSPT[N, E] {
+	s := &IncrementalSPT[N, E]{
+		sp: newShortestPaths(t, from, opts),
+	}
+	s.sp.calculate()
+	t.spts[s] = struct{}{}
+
+	return s
+}
+
+// SPT returns the current shortest path tree
+func (s *IncrementalSPT[N, E]) SPT() SPT[N, E] {
+	return s.sp.spt()
+}
+
+// Close stops updating the SPT on topology changes
+func (s *IncrementalSPT[N, E]) Close() {
+	delete(s.sp.t.spts, s)
+}
+
+// AddNode adds node n to the topology
+func (t *Topology[N, E]) AddNode(n N) {
+	if _, exists := t.nodes[n]; exists {
+		return
+	}
+	t.addNode(n)
+
+	for s := range t.spts {
+		if n == s.sp.root {
+			s.sp.calculate()
+			continue
+		}
+
+		for a, attrs := range t.reverse[n] {
+			s.sp.edgeAdded(t.edge(a, n, attrs))
+		}
+	}
+}
+
+// RemoveNode removes node n and all its edges from the topology
+func (t *Topology[N, E]) RemoveNode(n N) {
+	if _, exists := t.nodes[n]; !exists {
+		return
+	}
+
+	for a := range t.reverse[n] {
+		t.RemoveEdge(a, n)
+	}
+
+	for b := range t.edges[n] {
+		t.RemoveEdge(n, b)
+	}
+
+	delete(t.nodes, n)
+	delete(t.overloaded, n)
+	for s := range t.spts {
+		delete(s.sp.distances, n)
+		delete(s.sp.predecessors, n)
+	}
+}
+
+// SetEdge adds edge e to the topology or changes its distance and payload
+func (t *Topology[N, E]) SetEdge(e Edge[N, E]) {
+	old, exists := t.edges[e.NodeA][e.NodeB]
+	t.setEdge(e)
+
+	if exists && old.distance == e.Distance {
+		for s := range t.spts {
+			s.sp.payloadChanged(e)
+		}
+		return
+	}
+
+	for s := range t.spts {
+		if exists && e.Distance > old.distance {
+			s.sp.edgeRemoved(e)
+			continue
+		}
+
+		s.sp.edgeAdded(e)
+	}
+}
+
+// RemoveEdge removes the edge from a to b from the topology
+func (t *Topology[N, E]) RemoveEdge(a, b N) {
+	attrs, exists := t.edges[a][b]
+	if !exists {
+		return
+	}
+
+	delete(t.edges[a], b)
+	if len(t.edges[a]) == 0 {
+		delete(t.edges, a)
+	}
+
+	delete(t.reverse[b], a)
+	if len(t.reverse[b]) == 0 {
+		delete(t.reverse, b)
+	}
+
+	for s := range t.spts {
+		s.sp.edgeRemoved(t.edge(a, b, attrs))
+	}
+}
+
+// SetOverloaded marks node n as overloaded (e.g. IS-IS overload bit or OSPF R-bit cleared).
+// Overloaded nodes are reachable but paths do not transit them, unless the calculation is rooted at them.
+func (t *Topology[N, E]) SetOverloaded(n N, overloaded bool) {
+	_, current := t.overloaded[n]
+	if current == overloaded {
+		return
+	}
+
+	if overloaded {
+		t.overloaded[n] = struct{}{}
+	} else {
+		delete(t.overloaded, n)
+	}
+
+	t.outgoingEdgesChanged(n, overloaded)
+}
+
+// SetEdgeExcluded excludes the edge from a to b from all calculations or includes it again.
+// Exclusions are kept if the edge is removed and added again.
+func (t *Topology[N, E]) SetEdgeExcluded(a, b N, excluded bool) {
+	k := edgeKey[N]{a: a, b: b}
+	_, current := t.excluded[k]
+	if current == excluded {
+		return
+	}
+
+	if excluded {
+		t.excluded[k] = struct{}{}
+	} else {
+		delete(t.excluded, k)
+	}
+
+	attrs, exists := t.edges[a][b]
+	if !exists {
+		return
+	}
+
+	for s := range t.spts {
+		if excluded {
+			s.sp.edgeRemoved(t.edge(a, b, attrs))
+			continue
+		}
+
+		s.sp.edgeAdded(t.edge(a, b, attrs))
+	}
+}
+
+// outgoingEdgesChanged updates SPTs after all outgoing edges of n became unusable or usable
+func (t *Topology[N, E]) outgoingEdgesChanged(n N, unusable bool) {
+	for s := range t.spts {
+		if n == s.sp.root {
+			continue
+		}
+
+		for b, attrs := range t.edges[n] {
+			if unusable {
+				s.sp.edgeRemoved(t.edge(n, b, attrs))
+				continue
+			}
+
+			s.sp.edgeAdded(t.edge(n, b, attrs))
+		}
+	}
+}
+
+func (t *Topology[N, E]) setEdge(e Edge[N, E]) {
+	attrs := edgeAttributes[E]{
+		distance: e.Distance,
+		payload:  e.Payload,
+	}
+
+	if _, ok := t.edges[e.NodeA]; !ok {
+		t.edges[e.NodeA] = make(map[N]edgeAttributes[E])
+	}
+	t.edges[e.NodeA][e.NodeB] = attrs
+
+	if _, ok := t.reverse[e.NodeB]; !ok {
+		t.reverse[e.NodeB] = make(map[N]edgeAttributes[E])
+	}
+	t.reverse[e.NodeB][e.NodeA] = attrs
+}
+
+// payloadChanged replaces the recorded edge if e is on a shortest path
+func (s *shortestPaths[N, E]) payloadChanged(e Edge[N, E]) {
+	preds := s.predecessors[e.NodeB]
+	if i := edgeIndex(preds, e.NodeA); i >= 0 {
+		preds[i] = e
+	}
+}
+
+// edgeAdded updates the shortest paths after edge e was added, its distance decreased or it became usable
+func (s *shortestPaths[N, E]) edgeAdded(e Edge[N, E]) {
+	if _, reached := s.distances[e.NodeA]; !reached {
+		return
+	}
+
+	if !s.t.usable(e.NodeA, e.NodeB, s.root) {
+		return
+	}
+
+	q := &priorityQueue[N]{}
+	s.relax(e, q)
+	s.run(q, make(map[N]struct{}))
+}
+
+// edgeRemoved updates the shortest paths after edge e was removed, its distance increased or it became unusable.
+// Only nodes which lost all their shortest paths are recalculated.
+func (s *shortestPaths[N, E]) edgeRemoved(e Edge[N, E]) {
+	preds := s.predecessors[e.NodeB]
+	i := edgeIndex(preds, e.NodeA)
+	if i < 0 {
+		return
+	}
+
+	if len(preds) > 1 {
+		s.predecessors[e.NodeB] = append(preds[:i:i], preds[i+1:]...)
+		return
+	}
+	delete(s.predecessors, e.NodeB)
+
+	affected := s.affected(e.NodeB)
+	for n := range affected {
+		delete(s.distances, n)
+		delete(s.predecessors, n)
+	}
+
+	if s.opts.ECMP {
+		for n, preds := range s.predecessors {
+			s.predecessors[n] = withoutPredecessors(preds, affected)
+		}
+	}
+
+	final := make(map[N]struct{}, len(s.distances))
+	for n := range s.distances {
+		final[n] = struct{}{}
+	}
+
+	q := &priorityQueue[N]{}
+	for n := range affected {
+		for a, attrs := range s.t.reverse[n] {
+			if _, reached := s.distances[a]; !reached {
+				continue
+			}
+
+			if !s.t.usable(a, n, s.root) {
+				continue
+			}
+
+			s.relax(s.t.edge(a, n, attrs), q)
+		}
+	}
+
+	// Affected nodes lost all their shortest paths, so their distances increased.
+	// Hence there are no new equal cost paths from affected to unaffected nodes.
+	s.run(q, final)
+}
+
+// affected returns n and all nodes which have shortest paths only via n
+func (s *shortestPaths[N, E]) affected(n N) map[N]struct{} {
+	children := make(map[N][]N)
+	for c, preds := range s.predecessors {
+		for _, p := range preds {
+			children[p.NodeA] = append(children[p.NodeA], c)
+		}
+	}
+
+	affected := map[N]struct{}{
+		n: {},
+	}
+
+	queue := []N{n}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+
+		for _, c := range children[u] {
+			if _, ok := affected[c]; ok {
+				continue
+			}
+
+			if len(withoutPredecessors(s.predecessors[c], affected)) > 0 {
+				continue
+			}
+
+			affected[c] = struct{}{}
+			queue = append(queue, c)
+		}
+	}
+
+	return affected
+}
+
+// withoutPredecessors returns edges without the ones starting at nodes
+func withoutPredecessors[N comparable, E any](edges []Edge[N, E], nodes map[N]struct{}) []Edge[N, E] {
+	ret := make([]Edge[N, E], 0, len(edges))
+	for _, e := range edges {
+		if _, ok := nodes[e.NodeA]; ok {
+			continue
+		}
+
+		ret = append(ret, e)
+	}
+
+	return ret
+}
@@ -0,0 +1,127 @@
+package dijkstra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementalSPT(t *testing.T) {
+	a, b, c, d := Node{Name: "A"}, Node{Name: "B"}, Node{Name: "C"}, Node{Name: "D"}
+
+	top := NewTopology([]Node{a, b, c}, []testEdge{
+		{NodeA: a, NodeB: b, Distance: 1},
+		{NodeA: b, NodeB: c, Distance: 1},
+		{NodeA: a, NodeB: c, Distance: 5},
+	})
+	spt := top.NewIncrementalSPT(a, SPTOptions{})
+	defer spt.Close()
+
+	assert.Equal(t, int64(2), spt.SPT()[c].Distance)
+
+	top.SetEdge(testEdge{NodeA: b, NodeB: c, Distance: 10})
+	assert.Equal(t, testPath{Edges: []testEdge{{NodeA: a, NodeB: c, Distance: 5}}, Distance: 5}, spt.SPT()[c])
+
+	top.AddNode(d)
+	assert.Equal(t, int64(-1), spt.SPT()[d].Distance)
+
+	top.SetEdge(testEdge{NodeA: b, NodeB: d, Distance: 1})
+	top.SetEdge(testEdge{NodeA: d, NodeB: c, Distance: 1})
+	assert.Equal(t, int64(3), spt.SPT()[c].Distance)
+
+	top.RemoveNode(b)
+	_, exists := spt.SPT()[b]
+	assert.False(t, exists)
+	assert.Equal(t, int64(-1), spt.SPT()[d].Distance)
+	assert.Equal(t, int64(5), spt.SPT()[c].Distance)
+
+	top.RemoveEdge(a, c)
+	assert.Equal(t, int64(-1), spt.SPT()[c].Distance)
+}
+
+// TestIncrementalSPTRandom compares incrementally updated SPTs with full recalculations on random changes
+func TestIncrementalSPTRandom(t *testing.T) {
+	const numNodes = 25
+
+	for _, ecmp := range []bool{false, true} {
+		rnd := rand.New(rand.NewSource(1))
+		nodes := make([]Node, numNodes)
+		for i := range nodes {
+			nodes[i] = Node{Name: fmt.Sprintf("N%d", i)}
+		}
+
+		top := NewTopology(nodes, []testEdge{})
+		for i := 0; i < numNodes*3; i++ {
+			top.SetEdge(randomEdge(rnd, nodes))
+		}
+
+		opts := SPTOptions{ECMP: ecmp}
+		spt := top.NewIncrementalSPT(nodes[0], opts)
+
+		for i := 0; i < 2000; i++ {
+			switch op := rnd.Intn(13); {
+			case op < 5:
+				top.SetEdge(randomEdge(rnd, nodes))
+			case op < 9:
+				e := randomEdge(rnd, nodes)
+				top.RemoveEdge(e.NodeA, e.NodeB)
+			case op < 10:
+				n := nodes[1+rnd.Intn(numNodes-1)]
+				if _, exists := top.nodes[n]; exists {
+					top.RemoveNode(n)
+				} else {
+					top.AddNode(n)
+				}
+			case op < 11:
+				n := nodes[rnd.Intn(numNodes)]
+				_, overloaded := top.overloaded[n]
+				top.SetOverloaded(n, !overloaded)
+			default:
+				e := randomEdge(rnd, nodes)
+				_, excluded := top.excluded[edgeKey[Node]{a: e.NodeA, b: e.NodeB}]
+				top.SetEdgeExcluded(e.NodeA, e.NodeB, !excluded)
+			}
+
+			expected := top.SPTWithOptions(nodes[0], opts)
+			actual := spt.SPT()
+			if ecmp {
+				if !assert.Equal(t, expected, actual, "Change %d (ECMP)", i) {
+					return
+				}
+				continue
+			}
+
+			for n, p := range expected {
+				if !assert.Equal(t, p.Distance, actual[n].Distance, "Change %d: distance to %s", i, n.Name) {
+					return
+				}
+				assertValidPath(t, top, nodes[0], actual[n])
+			}
+		}
+	}
+}
+
+func randomEdge(rnd *rand.Rand, nodes []Node) testEdge {
+	return testEdge{
+		NodeA:    nodes[rnd.Intn(len(nodes))],
+		NodeB:    nodes[rnd.Intn(len(nodes))],
+		Distance: int64(1 + rnd.Intn(4)),
+	}
+}
+
+func assertValidPath(t *testing.T, top *testTopology, root Node, p testPath) {
+	sum := int64(0)
+	current := root
+	for _, e := range p.Edges {
+		assert.Equal(t, current, e.NodeA)
+		assert.Equal(t, top.edges[e.NodeA][e.NodeB].distance, e.Distance)
+		sum += e.Distance
+		current = e.NodeB
+	}
+
+	if p.Distance >= 0 {
+		assert.Equal(t, p.Distance, sum)
+	}
+}
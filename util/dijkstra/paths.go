@@ -0,0 +1,433 @@
+package dijkstra
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Disjointness selects the elements two paths should not share
+type Disjointness int
+
+const (
+	// LinkDisjoint paths do not share edges
+	LinkDisjoint Disjointness = iota
+
+	// NodeDisjoint paths do not share nodes other than source and destination
+	NodeDisjoint
+)
+
+// shortestPath calculates a shortest path from from to to not using excluded nodes and edges
+func (t *Topology[N, E]) shortestPath(from, to N, excludedNodes map[N]struct{}, excludedEdges map[edgeKey[N]]struct{}) (Path[N, E], bool) {
+	distances := map[N]int64{
+		from: 0,
+	}
+	predecessors := make(map[N]Edge[N, E])
+	final := make(map[N]struct{})
+
+	q := &priorityQueue[N]{
+		{
+			node:     from,
+			distance: 0,
+		},
+	}
+
+	for q.Len() > 0 {
+		item := heap.Pop(q).(queueItem[N])
+		if _, ok := final[item.node]; ok {
+			continue
+		}
+		final[item.node] = struct{}{}
+
+		if item.node == to {
+			ret := Path[N, E]{
+				Edges:    make([]Edge[N, E], 0),
+				Distance: item.distance,
+			}
+
+			for n := to; n != from; n = predecessors[n].NodeA {
+				ret.Edges = append(ret.Edges, predecessors[n])
+			}
+			reverseEdges(ret.Edges)
+
+			return ret, true
+		}
+
+		for neighbor, attrs := range t.edges[item.node] {
+			if _, ok := t.nodes[neighbor]; !ok {
+				continue
+			}
+
+			if _, ok := excludedNodes[neighbor]; ok {
+				continue
+			}
+
+			if _, ok := excludedEdges[edgeKey[N]{a: item.node, b: neighbor}]; ok {
+				continue
+			}
+
+			if !t.usable(item.node, neighbor, from) {
+				continue
+			}
+
+			d := item.distance + attrs.distance
+			if current, reached := distances[neighbor]; reached && d >= current {
+				continue
+			}
+
+			distances[neighbor] = d
+			predecessors[neighbor] = t.edge(item.node, neighbor, attrs)
+			heap.Push(q, queueItem[N]{
+				node:     neighbor,
+				distance: d,
+			})
+		}
+	}
+
+	return Path[N, E]{}, false
+}
+
+// KShortestPaths returns up to k loop free paths from from to to in order of increasing distance (Yen's algorithm)
+func (t *Topology[N, E]) KShortestPaths(from, to N, k int) []Path[N, E] {
+	ret := make([]Path[N, E], 0)
+	if k < 1 {
+		return ret
+	}
+
+	first, ok := t.shortestPath(from, to, nil, nil)
+	if !ok {
+		return ret
+	}
+	ret = append(ret, first)
+
+	candidates := make([]Path[N, E], 0)
+	for len(ret) < k {
+		prev := ret[len(ret)-1]
+
+		for i := range prev.Edges {
+			spurNode := prev.Edges[i].NodeA
+			root := prev.Edges[:i]
+
+			// do not find the deviations at spurNode we already know again
+			excludedEdges := make(map[edgeKey[N]]struct{})
+			for _, p := range ret {
+				if len(p.Edges) > i && edgesEqual(p.Edges[:i], root) {
+					excludedEdges[edgeKey[N]{a: p.Edges[i].NodeA, b: p.Edges[i].NodeB}] = struct{}{}
+				}
+			}
+
+			// keep paths loop free
+			excludedNodes := make(map[N]struct{})
+			for _, e := range root {
+				excludedNodes[e.NodeA] = struct{}{}
+			}
+
+			spur, ok := t.shortestPath(spurNode, to, excludedNodes, excludedEdges)
+			if !ok {
+				continue
+			}
+
+			candidate := Path[N, E]{
+				Edges:    make([]Edge[N, E], 0, len(root)+len(spur.Edges)),
+				Distance: pathDistance(root) + spur.Distance,
+			}
+			candidate.Edges = append(candidate.Edges, root...)
+			candidate.Edges = append(candidate.Edges, spur.Edges...)
+
+			if containsPath(ret, candidate) || containsPath(candidates, candidate) {
+				continue
+			}
+
+			candidates = append(candidates, candidate)
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		best := 0
+		for i := range candidates {
+			if candidates[i].Distance < candidates[best].Distance || (candidates[i].Distance == candidates[best].Distance && len(candidates[i].Edges) < len(candidates[best].Edges)) {
+				best = i
+			}
+		}
+
+		ret = append(ret, candidates[best])
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+
+	return ret
+}
+
+// arc is a directed edge of the auxiliary graph used for disjoint path calculation
+type arc[N comparable, E any] struct {
+	from     int
+	to       int
+	distance int64
+
+	// edge is the topology edge the arc represents. Arcs connecting the halves of a split node have none.
+	edge *Edge[N, E]
+}
+
+// DisjointPaths returns two paths from from to to which share as few edges (or nodes) as possible.
+// Among those the pair with the least total distance is returned (Bhandari's algorithm).
+// The paths are ordered by distance. If to is unreachable or equal to from nil is returned.
+func (t *Topology[N, E]) DisjointPaths(from, to N, d Disjointness) []Path[N, E] {
+	nodes := make([]N, 0, len(t.nodes))
+	for n := range t.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return t.less(nodes[i], nodes[j])
+	})
+
+	// With node disjointness every node is split into an in and an out half connected by an arc.
+	// Sharing a node then means sharing this arc.
+	in := make(map[N]int, len(nodes))
+	out := make(map[N]int, len(nodes))
+	arcs := make([]arc[N, E], 0)
+	for i, n := range nodes {
+		if d == NodeDisjoint {
+			in[n] = 2 * i
+			out[n] = 2*i + 1
+			arcs = append(arcs, arc[N, E]{
+				from: in[n],
+				to:   out[n],
+			})
+			continue
+		}
+
+		in[n] = i
+		out[n] = i
+	}
+
+	numVertices := len(nodes)
+	if d == NodeDisjoint {
+		numVertices *= 2
+	}
+
+	penalty := int64(1)
+	for _, n := range nodes {
+		for _, neighbor := range t.neighbors(n) {
+			if !t.usable(n, neighbor, from) {
+				continue
+			}
+
+			e := t.edge(n, neighbor, t.edges[n][neighbor])
+
+			arcs = append(arcs, arc[N, E]{
+				from:     out[n],
+				to:       in[neighbor],
+				distance: e.Distance,
+				edge:     &e,
+			})
+			penalty += 2 * e.Distance
+		}
+	}
+
+	if from == to {
+		return nil
+	}
+
+	if _, ok := t.nodes[from]; !ok {
+		return nil
+	}
+
+	if _, ok := t.nodes[to]; !ok {
+		return nil
+	}
+
+	src, dst := out[from], in[to]
+	first := bellmanFord(numVertices, arcs, src, dst)
+	if first == nil {
+		return nil
+	}
+
+	// Arcs of the first path are reversed with negative distance. Using them cancels out the first path's arc.
+	// Using them in forward direction is possible, but only at a penalty higher than any detour.
+	onFirst := make(map[int]struct{}, len(first))
+	for _, i := range first {
+		onFirst[i] = struct{}{}
+	}
+
+	modified := make([]arc[N, E], 0, len(arcs)+len(first))
+	origin := make([]int, 0, len(arcs)+len(first))
+	reversed := make([]bool, 0, len(arcs)+len(first))
+	for i, a := range arcs {
+		if _, ok := onFirst[i]; ok {
+			modified = append(modified, arc[N, E]{from: a.to, to: a.from, distance: -a.distance}, arc[N, E]{from: a.from, to: a.to, distance: a.distance + penalty})
+			origin = append(origin, i, i)
+			reversed = append(reversed, true, false)
+			continue
+		}
+
+		modified = append(modified, a)
+		origin = append(origin, i)
+		reversed = append(reversed, false)
+	}
+
+	second := bellmanFord(numVertices, modified, src, dst)
+	if second == nil {
+		return nil
+	}
+
+	usage := make([]int, len(arcs))
+	for _, i := range first {
+		usage[i]++
+	}
+
+	for _, i := range second {
+		if reversed[i] {
+			usage[origin[i]]--
+			continue
+		}
+
+		usage[origin[i]]++
+	}
+
+	ret := []Path[N, E]{
+		walk(arcs, usage, src, dst),
+		walk(arcs, usage, src, dst),
+	}
+
+	if ret[1].Distance < ret[0].Distance {
+		ret[0], ret[1] = ret[1], ret[0]
+	}
+
+	return ret
+}
+
+// neighbors returns the nodes n has edges to ordered by name
+func (t *Topology[N, E]) neighbors(n N) []N {
+	ret := make([]N, 0, len(t.edges[n]))
+	for neighbor := range t.edges[n] {
+		if _, ok := t.nodes[neighbor]; !ok {
+			continue
+		}
+
+		ret = append(ret, neighbor)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return t.less(ret[i], ret[j])
+	})
+
+	return ret
+}
+
+// bellmanFord returns the arcs of the shortest path from src to dst or nil if dst is unreachable.
+// Arcs may have negative distances but must not form negative cycles.
+func bellmanFord[N comparable, E any](numVertices int, arcs []arc[N, E], src, dst int) []int {
+	distances := make([]int64, numVertices)
+	reached := make([]bool, numVertices)
+	predecessors := make([]int, numVertices)
+	reached[src] = true
+
+	for i := 0; i < numVertices-1; i++ {
+		changed := false
+		for j, a := range arcs {
+			if !reached[a.from] {
+				continue
+			}
+
+			d := distances[a.from] + a.distance
+			if reached[a.to] && d >= distances[a.to] {
+				continue
+			}
+
+			distances[a.to] = d
+			reached[a.to] = true
+			predecessors[a.to] = j
+			changed = true
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	if !reached[dst] {
+		return nil
+	}
+
+	ret := make([]int, 0)
+	for v := dst; v != src; v = arcs[predecessors[v]].from {
+		ret = append(ret, predecessors[v])
+	}
+
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+
+	return ret
+}
+
+// walk follows used arcs from src to dst and consumes them
+func walk[N comparable, E any](arcs []arc[N, E], usage []int, src, dst int) Path[N, E] {
+	p := Path[N, E]{
+		Edges: make([]Edge[N, E], 0),
+	}
+
+	for v := src; v != dst; {
+		next := -1
+		for i, a := range arcs {
+			if a.from == v && usage[i] > 0 {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			break
+		}
+
+		usage[next]--
+		v = arcs[next].to
+
+		if arcs[next].edge != nil {
+			p.Edges = append(p.Edges, *arcs[next].edge)
+			p.Distance += arcs[next].distance
+		}
+	}
+
+	return p
+}
+
+func reverseEdges[N comparable, E any](edges []Edge[N, E]) {
+	for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+		edges[i], edges[j] = edges[j], edges[i]
+	}
+}
+
+func pathDistance[N comparable, E any](edges []Edge[N, E]) int64 {
+	ret := int64(0)
+	for _, e := range edges {
+		ret += e.Distance
+	}
+
+	return ret
+}
+
+// edgesEqual compares edges by their nodes. There is only one edge between two nodes.
+func edgesEqual[N comparable, E any](a, b []Edge[N, E]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].NodeA != b[i].NodeA || a[i].NodeB != b[i].NodeB {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsPath[N comparable, E any](paths []Path[N, E], p Path[N, E]) bool {
+	for _, x := range paths {
+		if edgesEqual(x.Edges, p.Edges) {
+			return true
+		}
+	}
+
+	return false
+}
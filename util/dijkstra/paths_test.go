@@ -0,0 +1,170 @@
+package dijkstra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bidirectional(edges ...testEdge) []testEdge {
+	ret := make([]testEdge, 0, len(edges)*2)
+	for _, e := range edges {
+		ret = append(ret, e, testEdge{NodeA: e.NodeB, NodeB: e.NodeA, Distance: e.Distance})
+	}
+
+	return ret
+}
+
+func TestKShortestPaths(t *testing.T) {
+	c, d, e, f, g, h := Node{Name: "C"}, Node{Name: "D"}, Node{Name: "E"}, Node{Name: "F"}, Node{Name: "G"}, Node{Name: "H"}
+	top := NewTopology([]Node{c, d, e, f, g, h}, []testEdge{
+		{NodeA: c, NodeB: d, Distance: 3},
+		{NodeA: c, NodeB: e, Distance: 2},
+		{NodeA: d, NodeB: f, Distance: 4},
+		{NodeA: e, NodeB: d, Distance: 1},
+		{NodeA: e, NodeB: f, Distance: 2},
+		{NodeA: e, NodeB: g, Distance: 3},
+		{NodeA: f, NodeB: g, Distance: 2},
+		{NodeA: f, NodeB: h, Distance: 1},
+		{NodeA: g, NodeB: h, Distance: 2},
+	})
+
+	tests := []struct {
+		name     string
+		k        int
+		to       Node
+		expected []testPath
+	}{
+		{
+			name: "Three shortest paths",
+			k:    3,
+			to:   h,
+			expected: []testPath{
+				{
+					Edges:    []testEdge{{NodeA: c, NodeB: e, Distance: 2}, {NodeA: e, NodeB: f, Distance: 2}, {NodeA: f, NodeB: h, Distance: 1}},
+					Distance: 5,
+				},
+				{
+					Edges:    []testEdge{{NodeA: c, NodeB: e, Distance: 2}, {NodeA: e, NodeB: g, Distance: 3}, {NodeA: g, NodeB: h, Distance: 2}},
+					Distance: 7,
+				},
+				{
+					Edges:    []testEdge{{NodeA: c, NodeB: d, Distance: 3}, {NodeA: d, NodeB: f, Distance: 4}, {NodeA: f, NodeB: h, Distance: 1}},
+					Distance: 8,
+				},
+			},
+		},
+		{
+			name: "Less paths than requested",
+			k:    5,
+			to:   e,
+			expected: []testPath{
+				{
+					Edges:    []testEdge{{NodeA: c, NodeB: e, Distance: 2}},
+					Distance: 2,
+				},
+			},
+		},
+		{
+			name:     "Unreachable",
+			k:        3,
+			to:       Node{Name: "X"},
+			expected: []testPath{},
+		},
+	}
+
+	for _, test := range tests {
+		paths := top.KShortestPaths(c, test.to, test.k)
+		assert.Equal(t, test.expected, paths, test.name)
+	}
+}
+
+func TestDisjointPaths(t *testing.T) {
+	a, b, c, d, e, f := Node{Name: "A"}, Node{Name: "B"}, Node{Name: "C"}, Node{Name: "D"}, Node{Name: "E"}, Node{Name: "F"}
+
+	tests := []struct {
+		name         string
+		nodes        []Node
+		edges        []testEdge
+		disjointness Disjointness
+		expected     []testPath
+	}{
+		{
+			// The shortest path A-B-C-F blocks both alternatives, so a shortest path first approach fails
+			name:  "Trap topology link disjoint",
+			nodes: []Node{a, b, c, d, e, f},
+			edges: bidirectional(
+				testEdge{NodeA: a, NodeB: b, Distance: 1},
+				testEdge{NodeA: b, NodeB: c, Distance: 1},
+				testEdge{NodeA: c, NodeB: f, Distance: 1},
+				testEdge{NodeA: a, NodeB: d, Distance: 2},
+				testEdge{NodeA: d, NodeB: c, Distance: 2},
+				testEdge{NodeA: b, NodeB: e, Distance: 2},
+				testEdge{NodeA: e, NodeB: f, Distance: 2},
+			),
+			disjointness: LinkDisjoint,
+			expected: []testPath{
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: e, Distance: 2}, {NodeA: e, NodeB: f, Distance: 2}},
+					Distance: 5,
+				},
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: d, Distance: 2}, {NodeA: d, NodeB: c, Distance: 2}, {NodeA: c, NodeB: f, Distance: 1}},
+					Distance: 5,
+				},
+			},
+		},
+		{
+			name:  "Shared node",
+			nodes: []Node{a, b, c, d, e},
+			edges: bidirectional(
+				testEdge{NodeA: a, NodeB: b, Distance: 1},
+				testEdge{NodeA: a, NodeB: c, Distance: 1},
+				testEdge{NodeA: b, NodeB: d, Distance: 1},
+				testEdge{NodeA: c, NodeB: d, Distance: 1},
+				testEdge{NodeA: d, NodeB: e, Distance: 1},
+				testEdge{NodeA: b, NodeB: e, Distance: 5},
+			),
+			disjointness: NodeDisjoint,
+			expected: []testPath{
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: c, Distance: 1}, {NodeA: c, NodeB: d, Distance: 1}, {NodeA: d, NodeB: e, Distance: 1}},
+					Distance: 3,
+				},
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: e, Distance: 5}},
+					Distance: 6,
+				},
+			},
+		},
+		{
+			name:  "No disjoint pair",
+			nodes: []Node{a, b, c},
+			edges: bidirectional(
+				testEdge{NodeA: a, NodeB: b, Distance: 1},
+				testEdge{NodeA: b, NodeB: c, Distance: 1},
+			),
+			disjointness: LinkDisjoint,
+			expected: []testPath{
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: c, Distance: 1}},
+					Distance: 2,
+				},
+				{
+					Edges:    []testEdge{{NodeA: a, NodeB: b, Distance: 1}, {NodeA: b, NodeB: c, Distance: 1}},
+					Distance: 2,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		top := NewTopology(test.nodes, test.edges)
+		to := test.nodes[len(test.nodes)-1]
+		paths := top.DisjointPaths(a, to, test.disjointness)
+		assert.Equal(t, test.expected, paths, test.name)
+	}
+
+	top := NewTopology([]Node{a, b}, []testEdge{})
+	assert.Nil(t, top.DisjointPaths(a, b, LinkDisjoint))
+}
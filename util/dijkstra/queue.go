@@ -0,0 +1,35 @@
+package dijkstra
+
+// queueItem is a node with its tentative distance
+type queueItem[N comparable] struct {
+	node     N
+	distance int64
+}
+
+// priorityQueue is a binary min heap of nodes ordered by distance. It implements heap.Interface.
+// Instead of decreasing keys nodes are pushed again, outdated items are skipped when popped.
+type priorityQueue[N comparable] []queueItem[N]
+
+func (q priorityQueue[N]) Len() int {
+	return len(q)
+}
+
+func (q priorityQueue[N]) Less(i, j int) bool {
+	return q[i].distance < q[j].distance
+}
+
+func (q priorityQueue[N]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *priorityQueue[N]) Push(x interface{}) {
+	*q = append(*q, x.(queueItem[N]))
+}
+
+func (q *priorityQueue[N]) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
@@ -0,0 +1,93 @@
+// Package endian provides allocation free big endian (network byte order) conversions
+package endian
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Uint16 reads an uint16 from b
+func Uint16(b []byte) uint16 {
+	return binary.BigEndian.Uint16(b)
+}
+
+// Uint24 reads a 24 bit value (e.g. an IS-IS wide metric) from b
+func Uint24(b []byte) uint32 {
+	_ = b[2] // bounds check hint to compiler
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// Uint32 reads an uint32 from b
+func Uint32(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b)
+}
+
+// Uint64 reads an uint64 from b
+func Uint64(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
+
+// PutUint16 puts v into b
+func PutUint16(b []byte, v uint16) {
+	binary.BigEndian.PutUint16(b, v)
+}
+
+// PutUint24 puts the lower 24 bits of v into b
+func PutUint24(b []byte, v uint32) {
+	_ = b[2] // bounds check hint to compiler
+	b[0] = byte(v >> 16)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v)
+}
+
+// PutUint32 puts v into b
+func PutUint32(b []byte, v uint32) {
+	binary.BigEndian.PutUint32(b, v)
+}
+
+// PutUint64 puts v into b
+func PutUint64(b []byte, v uint64) {
+	binary.BigEndian.PutUint64(b, v)
+}
+
+// Uint24Array converts the lower 24 bits of v into a 3 byte array as used for 24 bit metrics
+func Uint24Array(v uint32) [3]byte {
+	var b [3]byte
+	PutUint24(b[:], v)
+	return b
+}
+
+// Uint32Array converts v into a 4 byte array
+func Uint32Array(v uint32) [4]byte {
+	var b [4]byte
+	PutUint32(b[:], v)
+	return b
+}
+
+// WriteUint16 appends v to buf
+func WriteUint16(buf *bytes.Buffer, v uint16) {
+	var b [2]byte
+	PutUint16(b[:], v)
+	buf.Write(b[:])
+}
+
+// WriteUint24 appends the lower 24 bits of v to buf
+func WriteUint24(buf *bytes.Buffer, v uint32) {
+	var b [3]byte
+	PutUint24(b[:], v)
+	buf.Write(b[:])
+}
+
+// WriteUint32 appends v to buf
+func WriteUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+// WriteUint64 appends v to buf
+func WriteUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	PutUint64(b[:], v)
+	buf.Write(b[:])
+}
@@ -0,0 +1,54 @@
+package endian
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	b := make([]byte, 8)
+
+	PutUint16(b, 0x1234)
+	assert.Equal(t, []byte{0x12, 0x34}, b[:2])
+	assert.Equal(t, uint16(0x1234), Uint16(b))
+
+	PutUint24(b, 0xff123456)
+	assert.Equal(t, []byte{0x12, 0x34, 0x56}, b[:3])
+	assert.Equal(t, uint32(0x123456), Uint24(b))
+
+	PutUint32(b, 0x12345678)
+	assert.Equal(t, []byte{0x12, 0x34, 0x56, 0x78}, b[:4])
+	assert.Equal(t, uint32(0x12345678), Uint32(b))
+
+	PutUint64(b, 0x123456789abcdef0)
+	assert.Equal(t, []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}, b)
+	assert.Equal(t, uint64(0x123456789abcdef0), Uint64(b))
+
+	assert.Equal(t, [3]byte{0, 0, 123}, Uint24Array(123))
+	assert.Equal(t, [4]byte{10, 0, 0, 1}, Uint32Array(0x0a000001))
+}
+
+func TestWrite(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	WriteUint16(buf, 0x0102)
+	WriteUint24(buf, 0x030405)
+	WriteUint32(buf, 0x06070809)
+	WriteUint64(buf, 0x0a0b0c0d0e0f1011)
+
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}, buf.Bytes())
+}
+
+func TestWriteDoesNotAllocate(t *testing.T) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		WriteUint16(buf, 1)
+		WriteUint24(buf, 2)
+		WriteUint32(buf, 3)
+		WriteUint64(buf, 4)
+	})
+
+	assert.Equal(t, float64(0), allocs)
+}
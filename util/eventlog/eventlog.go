@@ -0,0 +1,113 @@
+package eventlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event represents a state change worth recording, e.g. a BGP session transition or a config change
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Subsystem string    `json:"subsystem"`
+	Object    string    `json:"object"`
+	Message   string    `json:"message"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+func (e *Event) String() string {
+	s := fmt.Sprintf("%s [%s] %s: %s", e.Timestamp.Format(time.RFC3339), e.Subsystem, e.Object, e.Message)
+	if e.Reason != "" {
+		s += fmt.Sprintf(" (%s)", e.Reason)
+	}
+
+	return s
+}
+
+// Sink receives a copy of every recorded event, e.g. for persistence
+type Sink interface {
+	Write(e *Event) error
+}
+
+// EventLog keeps the most recent events in a ring buffer and hands them to all sinks
+type EventLog struct {
+	events []Event
+	next   int
+	full   bool
+	sinks  []Sink
+	mu     sync.RWMutex
+}
+
+// New creates a new EventLog keeping up to size events
+func New(size int) *EventLog {
+	if size < 1 {
+		size = 1
+	}
+
+	return &EventLog{
+		events: make([]Event, size),
+		sinks:  make([]Sink, 0),
+	}
+}
+
+// AddSink adds a sink to the event log
+func (l *EventLog) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sinks = append(l.sinks, s)
+}
+
+// Record records an event. It is safe to call Record on a nil EventLog.
+func (l *EventLog) Record(subsystem string, object string, message string, reason string) {
+	if l == nil {
+		return
+	}
+
+	e := Event{
+		Timestamp: time.Now(),
+		Subsystem: subsystem,
+		Object:    object,
+		Message:   message,
+		Reason:    reason,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events[l.next] = e
+	l.next++
+	if l.next == len(l.events) {
+		l.next = 0
+		l.full = true
+	}
+
+	for _, s := range l.sinks {
+		s.Write(&e)
+	}
+}
+
+// Events gets all recorded events (oldest first) of the given subsystem (all subsystems if empty)
+func (l *EventLog) Events(subsystem string) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ret := make([]Event, 0)
+	start := 0
+	count := l.next
+	if l.full {
+		start = l.next
+		count = len(l.events)
+	}
+
+	for i := 0; i < count; i++ {
+		e := l.events[(start+i)%len(l.events)]
+		if subsystem != "" && e.Subsystem != subsystem {
+			continue
+		}
+
+		ret = append(ret, e)
+	}
+
+	return ret
+}
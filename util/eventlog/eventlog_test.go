@@ -0,0 +1,85 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messages(events []Event) []string {
+	ret := make([]string, 0, len(events))
+	for _, e := range events {
+		ret = append(ret, e.Message)
+	}
+
+	return ret
+}
+
+func TestEventLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		record    []string
+		subsystem string
+		expected  []string
+	}{
+		{
+			name:     "Empty",
+			size:     3,
+			expected: []string{},
+		},
+		{
+			name:     "Not full",
+			size:     3,
+			record:   []string{"a", "b"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Wrapped",
+			size:     3,
+			record:   []string{"a", "b", "c", "d", "e"},
+			expected: []string{"c", "d", "e"},
+		},
+		{
+			name:      "Filtered",
+			size:      3,
+			record:    []string{"a", "b"},
+			subsystem: "isis",
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		l := New(test.size)
+		for _, m := range test.record {
+			l.Record("bgp", "192.0.2.1", m, "")
+		}
+
+		assert.Equalf(t, test.expected, messages(l.Events(test.subsystem)), "Test %q", test.name)
+	}
+}
+
+func TestSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(10)
+	l.AddSink(NewWriterSink(buf))
+	l.Record("bgp", "192.0.2.1", "state change", "Received NOTIFICATION")
+
+	e := Event{}
+	err := json.Unmarshal(buf.Bytes(), &e)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal: %v", err)
+	}
+
+	assert.Equal(t, "bgp", e.Subsystem)
+	assert.Equal(t, "192.0.2.1", e.Object)
+	assert.Equal(t, "state change", e.Message)
+	assert.Equal(t, "Received NOTIFICATION", e.Reason)
+}
+
+func TestNilEventLog(t *testing.T) {
+	var l *EventLog
+	l.Record("bgp", "192.0.2.1", "state change", "")
+}
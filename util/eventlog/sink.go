@@ -0,0 +1,47 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// WriterSink writes events as JSON lines to a writer
+type WriterSink struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewWriterSink creates a new WriterSink
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		w: w,
+	}
+}
+
+// NewFileSink creates a WriterSink appending to the file at path
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open event log file")
+	}
+
+	return NewWriterSink(f), nil
+}
+
+// Write writes an event
+func (s *WriterSink) Write(e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal event")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package eventlog
+
+import (
+	"log/syslog"
+
+	"github.com/pkg/errors"
+)
+
+// SyslogSink writes events to syslog
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink creates a new SyslogSink using tag
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	w, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to connect to syslog")
+	}
+
+	return &SyslogSink{
+		w: w,
+	}, nil
+}
+
+// Write writes an event
+func (s *SyslogSink) Write(e *Event) error {
+	return s.w.Notice(e.String())
+}
@@ -0,0 +1,284 @@
+package flightrecorder
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Transition is a single state change of a state machine
+type Transition struct {
+	Timestamp time.Time
+	From      string
+	To        string
+	Reason    string
+}
+
+func (t *Transition) String() string {
+	return fmt.Sprintf("%s %s -> %s (%s)", t.Timestamp.Format(time.RFC3339Nano), t.From, t.To, t.Reason)
+}
+
+// UnexpectedFunc decides if a transition is unexpected and thus triggers an automatic dump
+type UnexpectedFunc func(from string, to string) bool
+
+// DumpFunc is called with the recorder of a state machine that made an unexpected transition
+type DumpFunc func(r *Recorder)
+
+// Registry holds the flight recorders of all state machines of all protocols. A registry can be scoped (e.g. to a
+// routing instance and VRF), so equally named state machines of different scopes get separate recorders.
+type Registry struct {
+	scope string
+	*registry
+}
+
+type registry struct {
+	size       int
+	recorders  map[string]map[recorderKey]*Recorder
+	unexpected map[string]UnexpectedFunc
+	onDump     DumpFunc
+	mu         sync.RWMutex
+}
+
+type recorderKey struct {
+	scope  string
+	object string
+}
+
+// NewRegistry creates a new registry. Every recorder keeps up to size transitions.
+func NewRegistry(size int) *Registry {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Registry{
+		registry: &registry{
+			size:       size,
+			recorders:  make(map[string]map[recorderKey]*Recorder),
+			unexpected: make(map[string]UnexpectedFunc),
+		},
+	}
+}
+
+// Scope gets a view of the registry whose recorders are kept apart from those of other scopes. Scopes nest,
+// e.g. Scope("default").Scope("master") is scope "default/master". It is safe to call Scope on a nil Registry.
+func (r *Registry) Scope(name string) *Registry {
+	if r == nil {
+		return nil
+	}
+
+	scope := name
+	if r.scope != "" {
+		scope = r.scope + "/" + name
+	}
+
+	return &Registry{
+		scope:    scope,
+		registry: r.registry,
+	}
+}
+
+// SetUnexpected sets the function classifying transitions of a protocols state machines as unexpected
+func (r *Registry) SetUnexpected(protocol string, f UnexpectedFunc) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unexpected[protocol] = f
+}
+
+// SetDumpFunc sets the function recorders are automatically dumped to on unexpected transitions
+func (r *Registry) SetDumpFunc(f DumpFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.onDump = f
+}
+
+// Recorder gets the recorder of a state machine, creating it if it doesn't exist.
+// It is safe to call Recorder on a nil Registry; the returned nil Recorder discards all transitions.
+func (r *Registry) Recorder(protocol string, object string) *Recorder {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.recorders[protocol]; !ok {
+		r.recorders[protocol] = make(map[recorderKey]*Recorder)
+	}
+
+	k := recorderKey{scope: r.scope, object: object}
+	rec, ok := r.recorders[protocol][k]
+	if !ok {
+		rec = &Recorder{
+			protocol:    protocol,
+			scope:       r.scope,
+			object:      object,
+			reg:         r.registry,
+			transitions: make([]Transition, r.size),
+		}
+		r.recorders[protocol][k] = rec
+	}
+
+	return rec
+}
+
+// Remove removes the recorder of a state machine of the registries scope
+func (r *Registry) Remove(protocol string, object string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.recorders[protocol], recorderKey{scope: r.scope, object: object})
+}
+
+// Recorders gets all recorders of a protocol (all protocols if empty) and object (all objects if empty) of all scopes
+func (r *Registry) Recorders(protocol string, object string) []*Recorder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ret := make([]*Recorder, 0)
+	for p, recs := range r.recorders {
+		if protocol != "" && p != protocol {
+			continue
+		}
+
+		for k, rec := range recs {
+			if object != "" && k.object != object {
+				continue
+			}
+
+			ret = append(ret, rec)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].protocol != ret[j].protocol {
+			return ret[i].protocol < ret[j].protocol
+		}
+
+		if ret[i].scope != ret[j].scope {
+			return ret[i].scope < ret[j].scope
+		}
+
+		return ret[i].object < ret[j].object
+	})
+
+	return ret
+}
+
+func (r *registry) isUnexpected(protocol string, from string, to string) (bool, DumpFunc) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f := r.unexpected[protocol]
+	if f == nil || r.onDump == nil {
+		return false, nil
+	}
+
+	return f(from, to), r.onDump
+}
+
+// Recorder keeps the most recent transitions of a single state machine
+type Recorder struct {
+	protocol    string
+	scope       string
+	object      string
+	reg         *registry
+	transitions []Transition
+	next        int
+	full        bool
+	mu          sync.RWMutex
+}
+
+// Protocol gets the protocol of the recorded state machine
+func (rec *Recorder) Protocol() string {
+	return rec.protocol
+}
+
+// Scope gets the scope of the recorded state machine, e.g. routing instance and VRF
+func (rec *Recorder) Scope() string {
+	return rec.scope
+}
+
+// Object gets the name of the recorded state machine, e.g. the peer address
+func (rec *Recorder) Object() string {
+	return rec.object
+}
+
+// Record records a transition. It is safe to call Record on a nil Recorder.
+func (rec *Recorder) Record(from string, to string, reason string) {
+	if rec == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	rec.transitions[rec.next] = Transition{
+		Timestamp: time.Now(),
+		From:      from,
+		To:        to,
+		Reason:    reason,
+	}
+	rec.next++
+	if rec.next == len(rec.transitions) {
+		rec.next = 0
+		rec.full = true
+	}
+	rec.mu.Unlock()
+
+	unexpected, dump := rec.reg.isUnexpected(rec.protocol, from, to)
+	if unexpected {
+		dump(rec)
+	}
+}
+
+// Transitions gets all recorded transitions (oldest first)
+func (rec *Recorder) Transitions() []Transition {
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+
+	start := 0
+	count := rec.next
+	if rec.full {
+		start = rec.next
+		count = len(rec.transitions)
+	}
+
+	ret := make([]Transition, 0, count)
+	for i := 0; i < count; i++ {
+		ret = append(ret, rec.transitions[(start+i)%len(rec.transitions)])
+	}
+
+	return ret
+}
+
+// Dump writes all recorded transitions in human readable form to w
+func (rec *Recorder) Dump(w io.Writer) error {
+	name := rec.object
+	if rec.scope != "" {
+		name = rec.scope + "/" + rec.object
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s:\n", rec.protocol, name)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range rec.Transitions() {
+		_, err = fmt.Fprintf(w, "  %s\n", t.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
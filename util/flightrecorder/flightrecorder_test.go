@@ -0,0 +1,135 @@
+package flightrecorder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		records  [][3]string
+		expected []Transition
+	}{
+		{
+			name: "Not wrapped",
+			size: 3,
+			records: [][3]string{
+				{"idle", "connect", "start"},
+				{"connect", "established", "ok"},
+			},
+			expected: []Transition{
+				{From: "idle", To: "connect", Reason: "start"},
+				{From: "connect", To: "established", Reason: "ok"},
+			},
+		},
+		{
+			name: "Wrapped",
+			size: 2,
+			records: [][3]string{
+				{"idle", "connect", "start"},
+				{"connect", "established", "ok"},
+				{"established", "idle", "hold timer expired"},
+			},
+			expected: []Transition{
+				{From: "connect", To: "established", Reason: "ok"},
+				{From: "established", To: "idle", Reason: "hold timer expired"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		r := NewRegistry(test.size)
+		rec := r.Recorder("bgp", "192.0.2.1")
+		for _, x := range test.records {
+			rec.Record(x[0], x[1], x[2])
+		}
+
+		res := rec.Transitions()
+		for i := range res {
+			assert.False(t, res[i].Timestamp.IsZero(), test.name)
+			res[i].Timestamp = test.expected[i].Timestamp
+		}
+
+		assert.Equal(t, test.expected, res, test.name)
+	}
+}
+
+func TestUnexpectedDump(t *testing.T) {
+	r := NewRegistry(10)
+	dumped := make([]string, 0)
+	r.SetDumpFunc(func(rec *Recorder) {
+		dumped = append(dumped, rec.Protocol()+" "+rec.Object())
+	})
+	r.SetUnexpected("bgp", func(from string, to string) bool {
+		return from == "established"
+	})
+
+	r.Recorder("bgp", "192.0.2.1").Record("connect", "established", "")
+	r.Recorder("isis", "eth0").Record("up", "down", "")
+	assert.Empty(t, dumped)
+
+	r.Recorder("bgp", "192.0.2.1").Record("established", "idle", "hold timer expired")
+	assert.Equal(t, []string{"bgp 192.0.2.1"}, dumped)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, r.Recorder("bgp", "192.0.2.1").Dump(buf))
+	assert.Contains(t, buf.String(), "bgp 192.0.2.1:\n")
+	assert.Contains(t, buf.String(), "established -> idle (hold timer expired)")
+}
+
+func TestRecorders(t *testing.T) {
+	r := NewRegistry(1)
+	r.Recorder("isis", "eth0")
+	r.Recorder("bgp", "192.0.2.2")
+	r.Recorder("bgp", "192.0.2.1")
+
+	names := func(recs []*Recorder) []string {
+		ret := make([]string, 0)
+		for _, rec := range recs {
+			ret = append(ret, rec.Protocol()+" "+rec.Object())
+		}
+		return ret
+	}
+
+	assert.Equal(t, []string{"bgp 192.0.2.1", "bgp 192.0.2.2", "isis eth0"}, names(r.Recorders("", "")))
+	assert.Equal(t, []string{"bgp 192.0.2.2"}, names(r.Recorders("bgp", "192.0.2.2")))
+
+	r.Remove("bgp", "192.0.2.2")
+	assert.Equal(t, []string{"bgp 192.0.2.1"}, names(r.Recorders("bgp", "")))
+
+	var nilReg *Registry
+	nilReg.Recorder("bgp", "x").Record("a", "b", "c")
+}
+
+func TestScopes(t *testing.T) {
+	r := NewRegistry(1)
+	red := r.Scope("default").Scope("red")
+	blue := r.Scope("default").Scope("blue")
+
+	red.Recorder("bgp", "192.0.2.1").Record("idle", "connect", "start")
+	blue.Recorder("bgp", "192.0.2.1").Record("idle", "active", "start")
+
+	recs := r.Recorders("bgp", "192.0.2.1")
+	assert.Equal(t, 2, len(recs))
+	assert.Equal(t, "default/blue", recs[0].Scope())
+	assert.Equal(t, "active", recs[0].Transitions()[0].To)
+	assert.Equal(t, "default/red", recs[1].Scope())
+	assert.Equal(t, "connect", recs[1].Transitions()[0].To)
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, recs[1].Dump(buf))
+	assert.Contains(t, buf.String(), "bgp default/red/192.0.2.1:\n")
+
+	// Removing a recorder does not affect the recorders of other scopes
+	blue.Remove("bgp", "192.0.2.1")
+	recs = r.Recorders("bgp", "")
+	assert.Equal(t, 1, len(recs))
+	assert.Equal(t, "default/red", recs[0].Scope())
+
+	var nilReg *Registry
+	nilReg.Scope("default").Recorder("bgp", "x").Record("a", "b", "c")
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check returns nil if the checked component is healthy
+type Check func() error
+
+// Checker aggregates liveness and readiness checks
+type Checker struct {
+	liveness  map[string]Check
+	readiness map[string]Check
+	mu        sync.RWMutex
+}
+
+// New creates a new Checker
+func New() *Checker {
+	return &Checker{
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+	}
+}
+
+// AddLivenessCheck adds a check that has to pass for the process to be considered alive
+func (c *Checker) AddLivenessCheck(name string, f Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.liveness[name] = f
+}
+
+// AddReadinessCheck adds a check that has to pass for the process to be considered ready to handle traffic
+func (c *Checker) AddReadinessCheck(name string, f Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readiness[name] = f
+}
+
+// Live runs all liveness checks
+func (c *Checker) Live() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return run(c.liveness)
+}
+
+// Ready runs all liveness and readiness checks
+func (c *Checker) Ready() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	err := run(c.liveness)
+	if err != nil {
+		return err
+	}
+
+	return run(c.readiness)
+}
+
+func run(checks map[string]Check) error {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failed := make([]string, 0)
+	for _, name := range names {
+		err := checks[name]()
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// LiveHandler returns an HTTP handler reporting liveness
+func (c *Checker) LiveHandler() http.Handler {
+	return handler(c.Live)
+}
+
+// ReadyHandler returns an HTTP handler reporting readiness
+func (c *Checker) ReadyHandler() http.Handler {
+	return handler(c.Ready)
+}
+
+func handler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		err := check()
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "%v\n", err)
+			return
+		}
+
+		fmt.Fprintf(w, "ok\n")
+	})
+}
+
+// SyncGRPC periodically updates the serving status of the overall server in s to reflect readiness
+func (c *Checker) SyncGRPC(s *health.Server, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		c.updateGRPC(s)
+		<-t.C
+	}
+}
+
+func (c *Checker) updateGRPC(s *health.Server) {
+	if c.Ready() != nil {
+		s.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+
+	s.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+}
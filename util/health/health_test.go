@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		liveness  map[string]Check
+		readiness map[string]Check
+		wantLive  bool
+		wantReady bool
+		wantErr   string
+	}{
+		{
+			name:      "No checks",
+			wantLive:  true,
+			wantReady: true,
+		},
+		{
+			name: "Not ready",
+			readiness: map[string]Check{
+				"bgp": func() error { return fmt.Errorf("not converged") },
+				"cfg": func() error { return nil },
+			},
+			wantLive:  true,
+			wantReady: false,
+			wantErr:   "bgp: not converged",
+		},
+		{
+			name: "Not live",
+			liveness: map[string]Check{
+				"b": func() error { return fmt.Errorf("stuck") },
+				"a": func() error { return fmt.Errorf("dead") },
+			},
+			readiness: map[string]Check{
+				"bgp": func() error { return nil },
+			},
+			wantLive:  false,
+			wantReady: false,
+			wantErr:   "a: dead; b: stuck",
+		},
+	}
+
+	for _, test := range tests {
+		c := New()
+		for name, f := range test.liveness {
+			c.AddLivenessCheck(name, f)
+		}
+		for name, f := range test.readiness {
+			c.AddReadinessCheck(name, f)
+		}
+
+		assert.Equal(t, test.wantLive, c.Live() == nil, test.name)
+
+		err := c.Ready()
+		assert.Equal(t, test.wantReady, err == nil, test.name)
+		if test.wantErr != "" {
+			assert.EqualError(t, err, test.wantErr, test.name)
+		}
+
+		rec := httptest.NewRecorder()
+		c.ReadyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+		wantCode := http.StatusOK
+		if !test.wantReady {
+			wantCode = http.StatusServiceUnavailable
+		}
+		assert.Equal(t, wantCode, rec.Code, test.name)
+
+		s := health.NewServer()
+		c.updateGRPC(s)
+		res, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err, test.name)
+		wantStatus := healthpb.HealthCheckResponse_SERVING
+		if !test.wantReady {
+			wantStatus = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		assert.Equal(t, wantStatus, res.Status, test.name)
+	}
+}
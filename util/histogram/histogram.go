@@ -0,0 +1,97 @@
+package histogram
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBounds are the upper bounds (in seconds) of the buckets used by a zero value Histogram
+var DefaultBounds = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observed durations in buckets. The zero value is ready to use with DefaultBounds.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+}
+
+// Snapshot is the state of a histogram at a point in time
+type Snapshot struct {
+	// Count is the number of observations
+	Count uint64
+
+	// Sum is the sum of all observations in seconds
+	Sum float64
+
+	// Buckets maps the upper bound of each bucket (in seconds) to the cumulative count of observations
+	Buckets map[float64]uint64
+}
+
+// New creates a histogram with the given bucket upper bounds in seconds
+func New(bounds []float64) *Histogram {
+	b := make([]float64, len(bounds))
+	copy(b, bounds)
+	sort.Float64s(b)
+
+	return &Histogram{
+		bounds: b,
+		counts: make([]uint64, len(b)),
+	}
+}
+
+func (h *Histogram) init() {
+	if h.bounds == nil {
+		h.bounds = DefaultBounds
+		h.counts = make([]uint64, len(h.bounds))
+	}
+}
+
+// Observe adds an observation
+func (h *Histogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.init()
+	i := sort.SearchFloat64s(h.bounds, d.Seconds())
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+
+	h.count++
+	h.sum += d
+}
+
+// Snapshot returns the current state of the histogram
+func (h *Histogram) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.init()
+	s := Snapshot{
+		Count:   h.count,
+		Sum:     h.sum.Seconds(),
+		Buckets: make(map[float64]uint64, len(h.bounds)),
+	}
+
+	cumulative := uint64(0)
+	for i, b := range h.bounds {
+		cumulative += h.counts[i]
+		s.Buckets[b] = cumulative
+	}
+
+	return s
+}
+
+// Reset removes all observations
+func (h *Histogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.init()
+	h.counts = make([]uint64, len(h.bounds))
+	h.count = 0
+	h.sum = 0
+}
@@ -0,0 +1,61 @@
+package histogram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name         string
+		bounds       []float64
+		observations []time.Duration
+		expected     Snapshot
+	}{
+		{
+			name:   "Empty",
+			bounds: []float64{0.1, 1},
+			expected: Snapshot{
+				Buckets: map[float64]uint64{0.1: 0, 1: 0},
+			},
+		},
+		{
+			name:         "Observations in all buckets",
+			bounds:       []float64{1, 0.1},
+			observations: []time.Duration{time.Millisecond * 50, time.Millisecond * 100, time.Millisecond * 500, time.Second * 2},
+			expected: Snapshot{
+				Count:   4,
+				Sum:     2.65,
+				Buckets: map[float64]uint64{0.1: 2, 1: 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		h := New(test.bounds)
+		for _, o := range test.observations {
+			h.Observe(o)
+		}
+
+		s := h.Snapshot()
+		assert.InDelta(t, test.expected.Sum, s.Sum, 1e-9, test.name)
+		s.Sum = test.expected.Sum
+		assert.Equal(t, test.expected, s, test.name)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var h Histogram
+	h.Observe(time.Millisecond)
+
+	s := h.Snapshot()
+	assert.Equal(t, uint64(1), s.Count)
+	assert.Equal(t, len(DefaultBounds), len(s.Buckets))
+	assert.Equal(t, uint64(1), s.Buckets[0.001])
+	assert.Equal(t, uint64(0), s.Buckets[0.0005])
+
+	h.Reset()
+	assert.Equal(t, uint64(0), h.Snapshot().Count)
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SubsystemField is the field carrying the subsystem name in all log entries
+	SubsystemField = "subsystem"
+)
+
+var (
+	subsystems   = make(map[string]*logrus.Logger)
+	subsystemsMu sync.RWMutex
+)
+
+// Subsystem gets the logger of a subsystem. Every subsystem has its own log level which can be changed at runtime.
+// Output and format are shared with the standard logger.
+func Subsystem(name string) *logrus.Entry {
+	subsystemsMu.Lock()
+	defer subsystemsMu.Unlock()
+
+	l, ok := subsystems[name]
+	if !ok {
+		std := logrus.StandardLogger()
+		l = logrus.New()
+		l.SetOutput(std.Out)
+		l.SetFormatter(std.Formatter)
+		l.SetLevel(std.GetLevel())
+		subsystems[name] = l
+	}
+
+	return l.WithField(SubsystemField, name)
+}
+
+// SetLevel sets the log level of a subsystem
+func SetLevel(subsystem string, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	l, ok := subsystems[subsystem]
+	if !ok {
+		return fmt.Errorf("unknown subsystem %q", subsystem)
+	}
+
+	l.SetLevel(lvl)
+	return nil
+}
+
+// SetDefaultLevel sets the log level of the standard logger and all subsystems
+func SetDefaultLevel(level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	logrus.SetLevel(lvl)
+	for _, l := range subsystems {
+		l.SetLevel(lvl)
+	}
+
+	return nil
+}
+
+// Levels gets the log levels of all subsystems
+func Levels() map[string]string {
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	ret := make(map[string]string, len(subsystems))
+	for name, l := range subsystems {
+		ret[name] = l.GetLevel().String()
+	}
+
+	return ret
+}
+
+// Subsystems gets the sorted names of all subsystems
+func Subsystems() []string {
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	ret := make([]string, 0, len(subsystems))
+	for name := range subsystems {
+		ret = append(ret, name)
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+// SetFormat sets the output format ("text" or "json") of the standard logger and all subsystems
+func SetFormat(format string) error {
+	var f logrus.Formatter
+	switch format {
+	case "text":
+		f = &logrus.TextFormatter{}
+	case "json":
+		f = &logrus.JSONFormatter{}
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	logrus.SetFormatter(f)
+	for _, l := range subsystems {
+		l.SetFormatter(f)
+	}
+
+	return nil
+}
+
+// SetOutput sets the output of the standard logger and all subsystems
+func SetOutput(w io.Writer) {
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+
+	logrus.SetOutput(w)
+	for _, l := range subsystems {
+		l.SetOutput(w)
+	}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubsystemLevels(t *testing.T) {
+	a := Subsystem("test_a")
+	b := Subsystem("test_b")
+
+	buf := &bytes.Buffer{}
+	SetOutput(buf)
+	assert.NoError(t, SetFormat("json"))
+	assert.NoError(t, SetDefaultLevel("info"))
+	assert.NoError(t, SetLevel("test_b", "debug"))
+
+	a.Debug("suppressed")
+	assert.Equal(t, 0, buf.Len())
+
+	b.WithField("peer", "192.0.2.1").Debug("shown")
+	entry := make(map[string]interface{})
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal log entry: %v", err)
+	}
+
+	assert.Equal(t, "test_b", entry[SubsystemField])
+	assert.Equal(t, "192.0.2.1", entry["peer"])
+	assert.Equal(t, "shown", entry["msg"])
+
+	assert.Equal(t, "info", Levels()["test_a"])
+	assert.Equal(t, "debug", Levels()["test_b"])
+
+	assert.Error(t, SetLevel("unknown", "debug"))
+	assert.Error(t, SetLevel("test_a", "foo"))
+	assert.Error(t, SetFormat("xml"))
+}
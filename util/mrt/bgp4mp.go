@@ -0,0 +1,108 @@
+package mrt
+
+import (
+	"bytes"
+	"fmt"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	afiIPv4 = 1
+	afiIPv6 = 2
+)
+
+// BGP4MPMessage is a BGP message exchanged with a peer (RFC6396 4.4.2, 4.4.3)
+type BGP4MPMessage struct {
+	// AS2 is set if AS numbers are encoded as 2 byte, in the message too (BGP4MP_MESSAGE)
+	AS2 bool
+
+	PeerAS         uint32
+	LocalAS        uint32
+	InterfaceIndex uint16
+	PeerIP         *bnet.IP
+	LocalIP        *bnet.IP
+
+	// Message is the BGP message including its header
+	Message []byte
+}
+
+// Type gets the record type
+func (m *BGP4MPMessage) Type() uint16 {
+	return TypeBGP4MP
+}
+
+// Subtype gets the record subtype
+func (m *BGP4MPMessage) Subtype() uint16 {
+	if m.AS2 {
+		return SubtypeBGP4MPMessage
+	}
+
+	return SubtypeBGP4MPMessageAS4
+}
+
+// Serialize serializes the message record. Both addresses must be of the same family.
+func (m *BGP4MPMessage) Serialize(buf *bytes.Buffer) {
+	if m.AS2 {
+		endian.WriteUint16(buf, uint16(m.PeerAS))
+		endian.WriteUint16(buf, uint16(m.LocalAS))
+	} else {
+		endian.WriteUint32(buf, m.PeerAS)
+		endian.WriteUint32(buf, m.LocalAS)
+	}
+	endian.WriteUint16(buf, m.InterfaceIndex)
+
+	if m.PeerIP.IsIPv4() {
+		endian.WriteUint16(buf, afiIPv4)
+	} else {
+		endian.WriteUint16(buf, afiIPv6)
+	}
+
+	buf.Write(m.PeerIP.Bytes())
+	buf.Write(m.LocalIP.Bytes())
+	buf.Write(m.Message)
+}
+
+func decodeBGP4MPMessage(buf *bytes.Buffer, as4 bool) (*BGP4MPMessage, error) {
+	m := &BGP4MPMessage{
+		AS2: !as4,
+	}
+
+	var err error
+	if as4 {
+		err = decoder.Decode(buf, []interface{}{&m.PeerAS, &m.LocalAS})
+	} else {
+		var peerAS, localAS uint16
+		err = decoder.Decode(buf, []interface{}{&peerAS, &localAS})
+		m.PeerAS, m.LocalAS = uint32(peerAS), uint32(localAS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var afi uint16
+	err = decoder.Decode(buf, []interface{}{&m.InterfaceIndex, &afi})
+	if err != nil {
+		return nil, err
+	}
+
+	if afi != afiIPv4 && afi != afiIPv6 {
+		return nil, fmt.Errorf("Unknown AFI %d", afi)
+	}
+
+	m.PeerIP, err = decodeIP(buf, afi == afiIPv6)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode peer address")
+	}
+
+	m.LocalIP, err = decodeIP(buf, afi == afiIPv6)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode local address")
+	}
+
+	m.Message = buf.Bytes()
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package mrt
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderLen is the length of the MRT common header
+	HeaderLen = 12
+
+	// Record types (RFC6396 4)
+	TypeTableDumpV2 = 13
+	TypeBGP4MP      = 16
+	TypeBGP4MPET    = 17
+
+	// TABLE_DUMP_V2 subtypes (RFC6396 4.3)
+	SubtypePeerIndexTable = 1
+	SubtypeRIBIPv4Unicast = 2
+	SubtypeRIBIPv6Unicast = 4
+
+	// BGP4MP subtypes (RFC6396 4.4)
+	SubtypeBGP4MPMessage    = 1
+	SubtypeBGP4MPMessageAS4 = 4
+)
+
+// Record is an MRT record
+type Record interface {
+	Type() uint16
+	Subtype() uint16
+	Serialize(buf *bytes.Buffer)
+}
+
+// Writer writes MRT records
+type Writer struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewWriter creates a new MRT writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w: w,
+	}
+}
+
+// WriteRecord writes record r with timestamp ts
+func (mw *Writer) WriteRecord(ts time.Time, r Record) error {
+	body := &bytes.Buffer{}
+	r.Serialize(body)
+
+	rec := &bytes.Buffer{}
+	endian.WriteUint32(rec, uint32(ts.Unix()))
+	endian.WriteUint16(rec, r.Type())
+	endian.WriteUint16(rec, r.Subtype())
+	endian.WriteUint32(rec, uint32(body.Len()))
+	rec.Write(body.Bytes())
+
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	_, err := mw.w.Write(rec.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Unable to write MRT record")
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package mrt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRecord(t *testing.T) {
+	ts := time.Unix(0x01020304, 0)
+
+	tests := []struct {
+		name     string
+		record   Record
+		expected []byte
+	}{
+		{
+			name: "Peer index table",
+			record: &PeerIndexTable{
+				CollectorBGPID: 0x0a000001,
+				ViewName:       "v",
+				Peers: []Peer{
+					{
+						BGPID: 0x0a000002,
+						Addr:  bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+						ASN:   65001,
+					},
+					{
+						BGPID: 0x0a000003,
+						Addr:  bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr(),
+						ASN:   4200000000,
+					},
+				},
+			},
+			expected: []byte{
+				1, 2, 3, 4, // Timestamp
+				0, 13, // Type
+				0, 1, // Subtype
+				0, 0, 0, 47, // Length
+				10, 0, 0, 1, // Collector BGP ID
+				0, 1, 'v', // View name
+				0, 2, // Peer count
+				2,           // Peer type
+				10, 0, 0, 2, // Peer BGP ID
+				192, 0, 2, 1, // Peer address
+				0, 0, 0xfd, 0xe9, // Peer AS
+				3,           // Peer type
+				10, 0, 0, 3, // Peer BGP ID
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // Peer address
+				0xfa, 0x56, 0xea, 0x00, // Peer AS
+			},
+		},
+		{
+			name: "IPv4 RIB",
+			record: &RIB{
+				SequenceNumber: 7,
+				Prefix:         bnet.NewPfx(bnet.IPv4FromOctets(198, 51, 100, 0), 22).Ptr(),
+				Entries: []RIBEntry{
+					{
+						PeerIndex:      1,
+						OriginatedTime: time.Unix(0x05060708, 0),
+						Attributes:     []byte{0x40, 1, 1, 0},
+					},
+				},
+			},
+			expected: []byte{
+				1, 2, 3, 4, // Timestamp
+				0, 13, // Type
+				0, 2, // Subtype
+				0, 0, 0, 22, // Length
+				0, 0, 0, 7, // Sequence number
+				22, 198, 51, 100, // Prefix
+				0, 1, // Entry count
+				0, 1, // Peer index
+				5, 6, 7, 8, // Originated time
+				0, 4, // Attribute length
+				0x40, 1, 1, 0, // ORIGIN
+			},
+		},
+		{
+			name: "IPv6 RIB",
+			record: &RIB{
+				Prefix: bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 32).Ptr(),
+			},
+			expected: []byte{
+				1, 2, 3, 4, // Timestamp
+				0, 13, // Type
+				0, 4, // Subtype
+				0, 0, 0, 11, // Length
+				0, 0, 0, 0, // Sequence number
+				32, 0x20, 0x01, 0x0d, 0xb8, // Prefix
+				0, 0, // Entry count
+			},
+		},
+		{
+			name: "BGP4MP message",
+			record: &BGP4MPMessage{
+				PeerAS:  65001,
+				LocalAS: 65000,
+				PeerIP:  bnet.IPv4FromOctets(192, 0, 2, 1).Ptr(),
+				LocalIP: bnet.IPv4FromOctets(192, 0, 2, 2).Ptr(),
+				Message: []byte{1, 2, 3},
+			},
+			expected: []byte{
+				1, 2, 3, 4, // Timestamp
+				0, 16, // Type
+				0, 4, // Subtype
+				0, 0, 0, 23, // Length
+				0, 0, 0xfd, 0xe9, // Peer AS
+				0, 0, 0xfd, 0xe8, // Local AS
+				0, 0, // Interface index
+				0, 1, // AFI
+				192, 0, 2, 1, // Peer address
+				192, 0, 2, 2, // Local address
+				1, 2, 3, // Message
+			},
+		},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		err := NewWriter(buf).WriteRecord(ts, test.record)
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, test.expected, buf.Bytes(), "Test %q", test.name)
+	}
+}
+
+func TestMPReachNextHop(t *testing.T) {
+	assert.Equal(t, []byte{
+		0x80, 14, 17, 16,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+	}, MPReachNextHop(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Ptr()))
+}
+
+func TestReadRecord(t *testing.T) {
+	ts := time.Unix(0x01020304, 0)
+
+	tests := []struct {
+		name   string
+		record Record
+	}{
+		{
+			name: "Peer index table",
+			record: &PeerIndexTable{
+				CollectorBGPID: 0x0a000001,
+				ViewName:       "v",
+				Peers: []Peer{
+					{
+						BGPID: 0x0a000002,
+						Addr:  bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+						ASN:   65001,
+					},
+					{
+						BGPID: 0x0a000003,
+						Addr:  bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 1).Dedup(),
+						ASN:   4200000000,
+					},
+				},
+			},
+		},
+		{
+			name: "IPv6 RIB",
+			record: &RIB{
+				SequenceNumber: 7,
+				Prefix:         bnet.NewPfx(bnet.IPv6FromBlocks(0x2001, 0xdb8, 0, 0, 0, 0, 0, 0), 33).Dedup(),
+				Entries: []RIBEntry{
+					{
+						PeerIndex:      1,
+						OriginatedTime: time.Unix(0x05060708, 0),
+						Attributes:     []byte{0x40, 1, 1, 0},
+					},
+				},
+			},
+		},
+		{
+			name: "BGP4MP message with 2 byte ASNs",
+			record: &BGP4MPMessage{
+				AS2:     true,
+				PeerAS:  65001,
+				LocalAS: 65000,
+				PeerIP:  bnet.IPv4FromOctets(192, 0, 2, 1).Dedup(),
+				LocalIP: bnet.IPv4FromOctets(192, 0, 2, 2).Dedup(),
+				Message: []byte{1, 2, 3},
+			},
+		},
+		{
+			name: "Unsupported record",
+			record: &RawRecord{
+				RecordType:    12,
+				RecordSubtype: 1,
+				Data:          []byte{1, 2, 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		err := NewWriter(buf).WriteRecord(ts, test.record)
+		assert.NoError(t, err, "Test %q", test.name)
+
+		r := NewReader(buf)
+		readTS, rec, err := r.Read()
+		assert.NoError(t, err, "Test %q", test.name)
+		assert.Equal(t, ts, readTS, "Test %q", test.name)
+		assert.Equal(t, test.record, rec, "Test %q", test.name)
+
+		_, _, err = r.Read()
+		assert.Equal(t, io.EOF, err, "Test %q", test.name)
+	}
+}
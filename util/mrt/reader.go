@@ -0,0 +1,173 @@
+package mrt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/pkg/errors"
+)
+
+// maxRecordLen limits the memory allocated for a record of a corrupt file
+const maxRecordLen = 1 << 24
+
+// RawRecord is a record of a type not decoded by the reader
+type RawRecord struct {
+	RecordType    uint16
+	RecordSubtype uint16
+	Data          []byte
+}
+
+// Type gets the record type
+func (r *RawRecord) Type() uint16 {
+	return r.RecordType
+}
+
+// Subtype gets the record subtype
+func (r *RawRecord) Subtype() uint16 {
+	return r.RecordSubtype
+}
+
+// Serialize serializes the record
+func (r *RawRecord) Serialize(buf *bytes.Buffer) {
+	buf.Write(r.Data)
+}
+
+// Reader reads MRT records
+type Reader struct {
+	r io.Reader
+}
+
+// NewReader creates a new MRT reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r: r,
+	}
+}
+
+// Read reads the next record. Records of unsupported types are returned as *RawRecord. io.EOF is returned at the end of the input.
+func (mr *Reader) Read() (time.Time, Record, error) {
+	hdr := make([]byte, HeaderLen)
+	_, err := io.ReadFull(mr.r, hdr)
+	if err == io.EOF {
+		return time.Time{}, nil, io.EOF
+	}
+	if err != nil {
+		return time.Time{}, nil, errors.Wrap(err, "Unable to read MRT header")
+	}
+
+	var sec, length uint32
+	var t, subtype uint16
+	err = decoder.Decode(bytes.NewBuffer(hdr), []interface{}{&sec, &t, &subtype, &length})
+	if err != nil {
+		return time.Time{}, nil, errors.Wrap(err, "Unable to decode MRT header")
+	}
+
+	if length > maxRecordLen {
+		return time.Time{}, nil, fmt.Errorf("MRT record too long: %d bytes", length)
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(mr.r, body)
+	if err != nil {
+		return time.Time{}, nil, errors.Wrap(err, "Unable to read MRT record")
+	}
+
+	ts := time.Unix(int64(sec), 0)
+	buf := bytes.NewBuffer(body)
+	if t == TypeBGP4MPET {
+		var usec uint32
+		err = decoder.Decode(buf, []interface{}{&usec})
+		if err != nil {
+			return time.Time{}, nil, errors.Wrap(err, "Unable to decode extended timestamp")
+		}
+
+		ts = ts.Add(time.Duration(usec) * time.Microsecond)
+	}
+
+	r, err := decodeRecord(t, subtype, buf)
+	if err != nil {
+		return time.Time{}, nil, errors.Wrapf(err, "Unable to decode MRT record of type %d subtype %d", t, subtype)
+	}
+
+	return ts, r, nil
+}
+
+func decodeRecord(t uint16, subtype uint16, buf *bytes.Buffer) (Record, error) {
+	switch t {
+	case TypeTableDumpV2:
+		switch subtype {
+		case SubtypePeerIndexTable:
+			return decodePeerIndexTable(buf)
+		case SubtypeRIBIPv4Unicast:
+			return decodeRIB(buf, false)
+		case SubtypeRIBIPv6Unicast:
+			return decodeRIB(buf, true)
+		}
+	case TypeBGP4MP, TypeBGP4MPET:
+		switch subtype {
+		case SubtypeBGP4MPMessage:
+			return decodeBGP4MPMessage(buf, false)
+		case SubtypeBGP4MPMessageAS4:
+			return decodeBGP4MPMessage(buf, true)
+		}
+	}
+
+	return &RawRecord{
+		RecordType:    t,
+		RecordSubtype: subtype,
+		Data:          buf.Bytes(),
+	}, nil
+}
+
+func decodeIP(buf *bytes.Buffer, ipv6 bool) (*bnet.IP, error) {
+	l := 4
+	if ipv6 {
+		l = 16
+	}
+
+	if buf.Len() < l {
+		return nil, fmt.Errorf("Address truncated")
+	}
+
+	ip, err := bnet.IPFromBytes(buf.Next(l))
+	if err != nil {
+		return nil, err
+	}
+
+	return ip.Dedup(), nil
+}
+
+func decodePrefix(buf *bytes.Buffer, ipv6 bool) (*bnet.Prefix, error) {
+	pfxLen, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read prefix length")
+	}
+
+	addrLen := 4
+	if ipv6 {
+		addrLen = 16
+	}
+
+	if int(pfxLen) > addrLen*8 {
+		return nil, fmt.Errorf("Invalid prefix length %d", pfxLen)
+	}
+
+	n := (int(pfxLen) + 7) / 8
+	if buf.Len() < n {
+		return nil, fmt.Errorf("Prefix truncated")
+	}
+
+	addr := make([]byte, addrLen)
+	copy(addr, buf.Next(n))
+
+	ip, err := bnet.IPFromBytes(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bnet.NewPfx(ip, pfxLen).Dedup(), nil
+}
@@ -0,0 +1,210 @@
+package mrt
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	bnet "github.com/bio-routing/bio-rd/net"
+	"github.com/bio-routing/bio-rd/util/decoder"
+	"github.com/bio-routing/bio-rd/util/endian"
+	"github.com/pkg/errors"
+)
+
+const (
+	peerTypeIPv6 = 0x01
+	peerTypeAS4  = 0x02
+)
+
+// Peer is an entry of the peer index table
+type Peer struct {
+	BGPID uint32
+	Addr  *bnet.IP
+	ASN   uint32
+}
+
+// PeerIndexTable lists the peers RIB entries refer to (RFC6396 4.3.1)
+type PeerIndexTable struct {
+	CollectorBGPID uint32
+	ViewName       string
+	Peers          []Peer
+}
+
+// Type gets the record type
+func (t *PeerIndexTable) Type() uint16 {
+	return TypeTableDumpV2
+}
+
+// Subtype gets the record subtype
+func (t *PeerIndexTable) Subtype() uint16 {
+	return SubtypePeerIndexTable
+}
+
+// Serialize serializes the peer index table
+func (t *PeerIndexTable) Serialize(buf *bytes.Buffer) {
+	endian.WriteUint32(buf, t.CollectorBGPID)
+	endian.WriteUint16(buf, uint16(len(t.ViewName)))
+	buf.WriteString(t.ViewName)
+	endian.WriteUint16(buf, uint16(len(t.Peers)))
+
+	for _, p := range t.Peers {
+		// AS numbers are always encoded as 4 byte
+		peerType := uint8(peerTypeAS4)
+		if !p.Addr.IsIPv4() {
+			peerType |= peerTypeIPv6
+		}
+
+		buf.WriteByte(peerType)
+		endian.WriteUint32(buf, p.BGPID)
+		buf.Write(p.Addr.Bytes())
+		endian.WriteUint32(buf, p.ASN)
+	}
+}
+
+func decodePeerIndexTable(buf *bytes.Buffer) (*PeerIndexTable, error) {
+	t := &PeerIndexTable{}
+
+	var viewNameLen uint16
+	err := decoder.Decode(buf, []interface{}{&t.CollectorBGPID, &viewNameLen})
+	if err != nil {
+		return nil, err
+	}
+
+	if buf.Len() < int(viewNameLen) {
+		return nil, fmt.Errorf("View name truncated")
+	}
+	t.ViewName = string(buf.Next(int(viewNameLen)))
+
+	var count uint16
+	err = decoder.Decode(buf, []interface{}{&count})
+	if err != nil {
+		return nil, err
+	}
+
+	t.Peers = make([]Peer, count)
+	for i := range t.Peers {
+		p := &t.Peers[i]
+
+		var peerType uint8
+		err := decoder.Decode(buf, []interface{}{&peerType, &p.BGPID})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode peer %d", i)
+		}
+
+		p.Addr, err = decodeIP(buf, peerType&peerTypeIPv6 != 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode address of peer %d", i)
+		}
+
+		if peerType&peerTypeAS4 != 0 {
+			err = decoder.Decode(buf, []interface{}{&p.ASN})
+		} else {
+			var asn uint16
+			err = decoder.Decode(buf, []interface{}{&asn})
+			p.ASN = uint32(asn)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode AS of peer %d", i)
+		}
+	}
+
+	return t, nil
+}
+
+// RIBEntry is the path of a prefix learned from a peer
+type RIBEntry struct {
+	PeerIndex      uint16
+	OriginatedTime time.Time
+
+	// Attributes are the BGP path attributes. IPv6 next hops are encoded as MP_REACH_NLRI holding only the next hop.
+	Attributes []byte
+}
+
+// RIB holds the paths of a unicast prefix (RFC6396 4.3.2)
+type RIB struct {
+	SequenceNumber uint32
+	Prefix         *bnet.Prefix
+	Entries        []RIBEntry
+}
+
+// Type gets the record type
+func (r *RIB) Type() uint16 {
+	return TypeTableDumpV2
+}
+
+// Subtype gets the record subtype
+func (r *RIB) Subtype() uint16 {
+	if r.Prefix.Addr().IsIPv4() {
+		return SubtypeRIBIPv4Unicast
+	}
+
+	return SubtypeRIBIPv6Unicast
+}
+
+// Serialize serializes the RIB record
+func (r *RIB) Serialize(buf *bytes.Buffer) {
+	endian.WriteUint32(buf, r.SequenceNumber)
+	serializePrefix(buf, r.Prefix)
+	endian.WriteUint16(buf, uint16(len(r.Entries)))
+
+	for _, e := range r.Entries {
+		endian.WriteUint16(buf, e.PeerIndex)
+		endian.WriteUint32(buf, uint32(e.OriginatedTime.Unix()))
+		endian.WriteUint16(buf, uint16(len(e.Attributes)))
+		buf.Write(e.Attributes)
+	}
+}
+
+func decodeRIB(buf *bytes.Buffer, ipv6 bool) (*RIB, error) {
+	r := &RIB{}
+
+	err := decoder.Decode(buf, []interface{}{&r.SequenceNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Prefix, err = decodePrefix(buf, ipv6)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to decode prefix")
+	}
+
+	var count uint16
+	err = decoder.Decode(buf, []interface{}{&count})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Entries = make([]RIBEntry, count)
+	for i := range r.Entries {
+		e := &r.Entries[i]
+
+		var originated uint32
+		var attrLen uint16
+		err := decoder.Decode(buf, []interface{}{&e.PeerIndex, &originated, &attrLen})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode entry %d", i)
+		}
+
+		if buf.Len() < int(attrLen) {
+			return nil, fmt.Errorf("Attributes of entry %d truncated", i)
+		}
+
+		e.OriginatedTime = time.Unix(int64(originated), 0)
+		e.Attributes = buf.Next(int(attrLen))
+	}
+
+	return r, nil
+}
+
+// serializePrefix writes the prefix length followed by the significant bytes of the prefix
+func serializePrefix(buf *bytes.Buffer, pfx *bnet.Prefix) {
+	l := pfx.Pfxlen()
+	buf.WriteByte(l)
+	buf.Write(pfx.Addr().Bytes()[:(l+7)/8])
+}
+
+// MPReachNextHop encodes the MP_REACH_NLRI attribute holding only next hop nh as used in RIB entries (RFC6396 4.3.4)
+func MPReachNextHop(nh *bnet.IP) []byte {
+	addr := nh.Bytes()
+	return append([]byte{0x80, 14, uint8(len(addr) + 1), uint8(len(addr))}, addr...)
+}
@@ -0,0 +1,70 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	magic        = 0xa1b2c3d4
+	versionMajor = 2
+	versionMinor = 4
+	snapLen      = 65535
+
+	// LinkTypeRaw is the link type for raw IPv4/IPv6 packets without link layer header
+	LinkTypeRaw = 101
+)
+
+// Writer writes packets in pcap format
+type Writer struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewWriter creates a new pcap writer and writes the file header for the given link type
+func NewWriter(w io.Writer, linkType uint32) (*Writer, error) {
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], magic)
+	binary.LittleEndian.PutUint16(hdr[4:6], versionMajor)
+	binary.LittleEndian.PutUint16(hdr[6:8], versionMinor)
+	binary.LittleEndian.PutUint32(hdr[16:20], snapLen)
+	binary.LittleEndian.PutUint32(hdr[20:24], linkType)
+
+	_, err := w.Write(hdr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to write pcap header")
+	}
+
+	return &Writer{
+		w: w,
+	}, nil
+}
+
+// WritePacket writes a packet record
+func (pw *Writer) WritePacket(ts time.Time, data []byte) error {
+	capLen := len(data)
+	if capLen > snapLen {
+		capLen = snapLen
+	}
+
+	rec := make([]byte, 16, 16+capLen)
+	binary.LittleEndian.PutUint32(rec[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(rec[4:8], uint32(ts.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(rec[8:12], uint32(capLen))
+	binary.LittleEndian.PutUint32(rec[12:16], uint32(len(data)))
+	rec = append(rec, data[:capLen]...)
+
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	_, err := pw.w.Write(rec)
+	if err != nil {
+		return errors.Wrap(err, "Unable to write packet record")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package pcap
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, LinkTypeRaw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = w.WritePacket(time.Unix(1, 2000), []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []byte{
+		0xd4, 0xc3, 0xb2, 0xa1, // Magic
+		2, 0, 4, 0, // Version
+		0, 0, 0, 0, // Timezone
+		0, 0, 0, 0, // Sigfigs
+		0xff, 0xff, 0, 0, // Snaplen
+		101, 0, 0, 0, // Link type
+		1, 0, 0, 0, // Seconds
+		2, 0, 0, 0, // Microseconds
+		3, 0, 0, 0, // Captured length
+		3, 0, 0, 0, // Original length
+		1, 2, 3,
+	}
+
+	assert.Equal(t, expected, buf.Bytes())
+}
+
+func TestTCPFlow(t *testing.T) {
+	f := NewTCPFlow(&net.TCPAddr{
+		IP:   net.IP{192, 0, 2, 1},
+		Port: 179,
+	}, &net.TCPAddr{
+		IP:   net.IP{192, 0, 2, 2},
+		Port: 50000,
+	})
+
+	out := f.Packet(true, []byte{0xaa, 0xbb})
+	expected := []byte{
+		0x45, 0x00, 0x00, 0x2a, // Version, IHL, TOS, Total length
+		0x00, 0x00, 0x00, 0x00, // Identification, Flags, Fragment offset
+		0x40, 0x06, 0xf6, 0xca, // TTL, Protocol, Checksum
+		192, 0, 2, 1, // Source
+		192, 0, 2, 2, // Destination
+		0x00, 0xb3, 0xc3, 0x50, // Ports
+		0, 0, 0, 1, // Sequence
+		0, 0, 0, 1, // Acknowledgement
+		0x50, 0x18, 0xff, 0xff, // Data offset, Flags, Window
+		0, 0, 0, 0, // Checksum, Urgent pointer
+		0xaa, 0xbb,
+	}
+	assert.Equal(t, expected, out)
+
+	in := f.Packet(false, []byte{0xcc})
+	assert.Equal(t, []byte{192, 0, 2, 2}, in[12:16], "source of inbound packet")
+	assert.Equal(t, []byte{0, 0, 0, 1}, in[24:28], "sequence of inbound packet")
+	assert.Equal(t, []byte{0, 0, 0, 3}, in[28:32], "acknowledgement of inbound packet")
+}
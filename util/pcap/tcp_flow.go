@@ -0,0 +1,99 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+
+	"github.com/bio-routing/bio-rd/util/checksum"
+)
+
+const (
+	ipv4HeaderLen = 20
+	ipv6HeaderLen = 40
+	tcpHeaderLen  = 20
+	protoTCP      = 6
+	tcpFlagsPSH   = 0x08
+	tcpFlagsACK   = 0x10
+	defaultTTL    = 64
+)
+
+// TCPFlow synthesizes raw IP/TCP packets for payload captured from a TCP stream.
+// Sequence and acknowledgement numbers are maintained per direction so dissectors
+// are able to reassemble the stream.
+type TCPFlow struct {
+	local  *net.TCPAddr
+	remote *net.TCPAddr
+	seqOut uint32
+	seqIn  uint32
+	mu     sync.Mutex
+}
+
+// NewTCPFlow creates a new TCPFlow between local and remote
+func NewTCPFlow(local *net.TCPAddr, remote *net.TCPAddr) *TCPFlow {
+	return &TCPFlow{
+		local:  local,
+		remote: remote,
+		seqOut: 1,
+		seqIn:  1,
+	}
+}
+
+// Packet builds a raw IP packet carrying payload. If outbound is true the packet is sent from local to remote.
+func (f *TCPFlow) Packet(outbound bool, payload []byte) []byte {
+	f.mu.Lock()
+	src, dst := f.remote, f.local
+	seq, ack := f.seqIn, f.seqOut
+	if outbound {
+		src, dst = f.local, f.remote
+		seq, ack = f.seqOut, f.seqIn
+		f.seqOut += uint32(len(payload))
+	} else {
+		f.seqIn += uint32(len(payload))
+	}
+	f.mu.Unlock()
+
+	tcpSegment := tcpHeader(src.Port, dst.Port, seq, ack)
+	tcpSegment = append(tcpSegment, payload...)
+
+	if src.IP.To4() != nil && dst.IP.To4() != nil {
+		return append(ipv4Header(src.IP.To4(), dst.IP.To4(), len(tcpSegment)), tcpSegment...)
+	}
+
+	return append(ipv6Header(src.IP.To16(), dst.IP.To16(), len(tcpSegment)), tcpSegment...)
+}
+
+func tcpHeader(srcPort int, dstPort int, seq uint32, ack uint32) []byte {
+	h := make([]byte, tcpHeaderLen)
+	binary.BigEndian.PutUint16(h[0:2], uint16(srcPort))
+	binary.BigEndian.PutUint16(h[2:4], uint16(dstPort))
+	binary.BigEndian.PutUint32(h[4:8], seq)
+	binary.BigEndian.PutUint32(h[8:12], ack)
+	h[12] = (tcpHeaderLen / 4) << 4
+	h[13] = tcpFlagsPSH | tcpFlagsACK
+	binary.BigEndian.PutUint16(h[14:16], 0xffff)
+	return h
+}
+
+func ipv4Header(src net.IP, dst net.IP, payloadLen int) []byte {
+	h := make([]byte, ipv4HeaderLen)
+	h[0] = 0x45
+	binary.BigEndian.PutUint16(h[2:4], uint16(ipv4HeaderLen+payloadLen))
+	h[8] = defaultTTL
+	h[9] = protoTCP
+	copy(h[12:16], src)
+	copy(h[16:20], dst)
+	binary.BigEndian.PutUint16(h[10:12], checksum.Internet(h))
+	return h
+}
+
+func ipv6Header(src net.IP, dst net.IP, payloadLen int) []byte {
+	h := make([]byte, ipv6HeaderLen)
+	h[0] = 0x60
+	binary.BigEndian.PutUint16(h[4:6], uint16(payloadLen))
+	h[6] = protoTCP
+	h[7] = defaultTTL
+	copy(h[8:24], src)
+	copy(h[24:40], dst)
+	return h
+}
@@ -0,0 +1,234 @@
+package servicewrapper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Authorizer decides if the caller is permitted to call a method
+type Authorizer interface {
+	// Authorize returns an error if the call of fullMethod (/package.Service/Method) is not permitted
+	Authorize(ctx context.Context, fullMethod string) error
+}
+
+// UnaryAuthzInterceptor rejects unary calls not permitted by a
+func UnaryAuthzInterceptor(a Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		err := a.Authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthzInterceptor rejects streaming calls not permitted by a
+func StreamAuthzInterceptor(a Authorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := a.Authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// Identity gets the identity of the caller: the common name of its verified client certificate or "" if unauthenticated
+func Identity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// Role is a permission level. Each role includes all lower roles.
+type Role uint8
+
+const (
+	// RoleNone permits nothing (as granted role) or is required for nothing (as method role)
+	RoleNone Role = iota
+
+	// RoleRead permits calling methods that don't change state
+	RoleRead
+
+	// RoleWrite permits calling all methods
+	RoleWrite
+)
+
+// ParseRole parses a role name (none, read or write)
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(s) {
+	case "none", "":
+		return RoleNone, nil
+	case "read":
+		return RoleRead, nil
+	case "write":
+		return RoleWrite, nil
+	}
+
+	return RoleNone, fmt.Errorf("unknown role %q", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return "read"
+	case RoleWrite:
+		return "write"
+	}
+
+	return "none"
+}
+
+// RoleAuthorizer authorizes calls by comparing the role granted to the callers identity with the role required by the method.
+// Methods require RoleWrite unless configured otherwise, so methods added later are not callable by read only identities
+// until they are marked read only. GRPC health checks require no role, server reflection requires RoleRead.
+type RoleAuthorizer struct {
+	anonymous  Role
+	identities map[string]Role
+	methods    map[string]Role
+	mu         sync.RWMutex
+}
+
+// NewRoleAuthorizer creates a RoleAuthorizer granting no permissions
+func NewRoleAuthorizer() *RoleAuthorizer {
+	return &RoleAuthorizer{
+		identities: make(map[string]Role),
+		methods: map[string]Role{
+			"/grpc.health.v1.Health/Check": RoleNone,
+			"/grpc.health.v1.Health/Watch": RoleNone,
+
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": RoleRead,
+		},
+	}
+}
+
+type roleAuthorizerConfig struct {
+	Anonymous  string            `yaml:"anonymous"`
+	Identities map[string]string `yaml:"identities"`
+	Methods    map[string]string `yaml:"methods"`
+}
+
+// LoadRoleAuthorizer creates a RoleAuthorizer from a YAML file, e.g.:
+//
+//	anonymous: none
+//	identities:
+//	  monitoring: read
+//	  admin: write
+//	methods:
+//	  /bio.bgp.BgpService/ListSessions: read
+func LoadRoleAuthorizer(path string) (*RoleAuthorizer, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read file")
+	}
+
+	cfg := &roleAuthorizerConfig{}
+	err = yaml.UnmarshalStrict(b, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to unmarshal")
+	}
+
+	a := NewRoleAuthorizer()
+	a.anonymous, err = ParseRole(cfg.Anonymous)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid anonymous role")
+	}
+
+	for id, r := range cfg.Identities {
+		role, err := ParseRole(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid role of identity %q", id)
+		}
+
+		a.SetIdentityRole(id, role)
+	}
+
+	for m, r := range cfg.Methods {
+		role, err := ParseRole(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid role of method %q", m)
+		}
+
+		a.SetMethodRole(m, role)
+	}
+
+	return a, nil
+}
+
+// SetIdentityRole sets the role granted to an identity
+func (a *RoleAuthorizer) SetIdentityRole(identity string, r Role) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.identities[identity] = r
+}
+
+// SetMethodRole sets the role required to call a method (/package.Service/Method)
+func (a *RoleAuthorizer) SetMethodRole(fullMethod string, r Role) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.methods[fullMethod] = r
+}
+
+// SetMethodRoleIfUnset sets the role required to call a method unless it has been configured already
+func (a *RoleAuthorizer) SetMethodRoleIfUnset(fullMethod string, r Role) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.methods[fullMethod]; ok {
+		return
+	}
+
+	a.methods[fullMethod] = r
+}
+
+// Authorize checks if the caller is permitted to call fullMethod
+func (a *RoleAuthorizer) Authorize(ctx context.Context, fullMethod string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	required, ok := a.methods[fullMethod]
+	if !ok {
+		required = RoleWrite
+	}
+
+	id := Identity(ctx)
+	granted := a.anonymous
+	if id != "" {
+		if r, ok := a.identities[id]; ok {
+			granted = r
+		}
+	}
+
+	if granted < required {
+		if id == "" {
+			return status.Errorf(codes.Unauthenticated, "%s requires role %s", fullMethod, required)
+		}
+
+		return status.Errorf(codes.PermissionDenied, "%q is not permitted to call %s (requires role %s)", id, fullMethod, required)
+	}
+
+	return nil
+}
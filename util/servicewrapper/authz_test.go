@@ -0,0 +1,128 @@
+package servicewrapper
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func contextWithIdentity(id string) context.Context {
+	if id == "" {
+		return context.Background()
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{
+					{
+						{Subject: pkix.Name{CommonName: id}},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestRoleAuthorizer(t *testing.T) {
+	a := NewRoleAuthorizer()
+	a.SetIdentityRole("monitoring", RoleRead)
+	a.SetIdentityRole("admin", RoleWrite)
+	a.SetMethodRole("/svc/Get", RoleRead)
+
+	tests := []struct {
+		name     string
+		identity string
+		method   string
+		wantCode codes.Code
+	}{
+		{
+			name:     "Anonymous read",
+			method:   "/svc/Get",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Anonymous health check",
+			method:   "/grpc.health.v1.Health/Check",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Read role reads",
+			identity: "monitoring",
+			method:   "/svc/Get",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Read role writes",
+			identity: "monitoring",
+			method:   "/svc/Set",
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "Read role calls unlisted method",
+			identity: "monitoring",
+			method:   "/svc/New",
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "Write role writes",
+			identity: "admin",
+			method:   "/svc/Set",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Unknown identity",
+			identity: "mallory",
+			method:   "/svc/Get",
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		err := a.Authorize(contextWithIdentity(test.identity), test.method)
+		assert.Equal(t, test.wantCode, status.Code(err), test.name)
+	}
+}
+
+func TestLoadRoleAuthorizer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "authz.yml")
+	err = ioutil.WriteFile(path, []byte("anonymous: read\nidentities:\n  admin: write\nmethods:\n  /svc/Get: read\n  /svc/Dump: write\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := LoadRoleAuthorizer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.SetMethodRoleIfUnset("/svc/Dump", RoleRead)
+	assert.NoError(t, a.Authorize(contextWithIdentity(""), "/svc/Get"))
+	assert.Error(t, a.Authorize(contextWithIdentity(""), "/svc/Dump"))
+	assert.NoError(t, a.Authorize(contextWithIdentity("admin"), "/svc/Dump"))
+
+	err = ioutil.WriteFile(path, []byte("anonymous: root\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadRoleAuthorizer(path)
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bio-routing/bio-rd/util/crashdump"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
@@ -18,6 +19,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -57,8 +59,14 @@ type grpcSrv struct {
 	srv  *grpc.Server
 }
 
-// New creates a new exarpc server wrapper
-func New(grpcPort uint16, h *http.Server, unaryInterceptors []grpc.UnaryServerInterceptor, streamInterceptors []grpc.StreamServerInterceptor, keepalivePol keepalive.EnforcementPolicy) (*Server, error) {
+// recoveryHandler writes a crash dump for a panicking handler. The call fails but the process keeps running.
+func recoveryHandler(p interface{}) error {
+	crashdump.WritePanic(p)
+	return status.Errorf(codes.Internal, "%v", p)
+}
+
+// New creates a new exarpc server wrapper. sec may be nil for an unauthenticated plaintext server.
+func New(grpcPort uint16, h *http.Server, unaryInterceptors []grpc.UnaryServerInterceptor, streamInterceptors []grpc.StreamServerInterceptor, keepalivePol keepalive.EnforcementPolicy, sec *SecurityConfig) (*Server, error) {
 	s := &Server{
 		grpcSrv: &grpcSrv{port: grpcPort},
 		httpSrv: h,
@@ -70,18 +78,27 @@ func New(grpcPort uint16, h *http.Server, unaryInterceptors []grpc.UnaryServerIn
 	unaryInterceptors = append(unaryInterceptors,
 		grpc_prometheus.UnaryServerInterceptor,
 		grpc_ctxtags.UnaryServerInterceptor(),
-		grpc_recovery.UnaryServerInterceptor(),
+		grpc_recovery.UnaryServerInterceptor(grpc_recovery.WithRecoveryHandler(recoveryHandler)),
 		grpc_logrus.UnaryServerInterceptor(logrusEntry, levelOpt),
 	)
 
 	streamInterceptors = append(streamInterceptors,
 		grpc_prometheus.StreamServerInterceptor,
 		grpc_ctxtags.StreamServerInterceptor(),
-		grpc_recovery.StreamServerInterceptor(),
+		grpc_recovery.StreamServerInterceptor(grpc_recovery.WithRecoveryHandler(recoveryHandler)),
 		grpc_logrus.StreamServerInterceptor(logrusEntry, levelOpt),
 	)
 
-	opts := make([]grpc.ServerOption, 0)
+	if sec != nil && sec.Authorizer != nil {
+		unaryInterceptors = append(unaryInterceptors, UnaryAuthzInterceptor(sec.Authorizer))
+		streamInterceptors = append(streamInterceptors, StreamAuthzInterceptor(sec.Authorizer))
+	}
+
+	opts, err := sec.serverOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	opts = append(opts, grpc_middleware.WithUnaryServerChain(unaryInterceptors...))
 	opts = append(opts, grpc_middleware.WithStreamServerChain(streamInterceptors...))
 	opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalivePol))
@@ -0,0 +1,116 @@
+package servicewrapper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// SecurityConfig configures transport security and authorization of the GRPC server
+type SecurityConfig struct {
+	// CertFile and KeyFile enable TLS if set
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile enables mutual TLS if set. Clients have to present a certificate signed by this CA.
+	ClientCAFile string
+
+	// Authorizer decides if a call is permitted. All calls are permitted if nil.
+	Authorizer Authorizer
+}
+
+func (c *SecurityConfig) serverOptions() ([]grpc.ServerOption, error) {
+	if c == nil || c.CertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to load certificate")
+	}
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		pool, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsCfg))}, nil
+}
+
+// ClientTLS creates a dial option for connecting to a GRPC server.
+// If caFile is empty an insecure connection is used. If certFile is set, the certificate is presented to the server (mutual TLS).
+func ClientTLS(caFile string, certFile string, keyFile string) (grpc.DialOption, error) {
+	if caFile == "" {
+		return grpc.WithInsecure(), nil
+	}
+
+	pool, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to load client certificate")
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)), nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read CA file")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %q", path)
+	}
+
+	return pool, nil
+}
+
+// NewSecurityConfig creates a SecurityConfig. Authorization is enabled if authzFile (see LoadRoleAuthorizer) is set.
+func NewSecurityConfig(certFile string, keyFile string, clientCAFile string, authzFile string) (*SecurityConfig, error) {
+	c := &SecurityConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: clientCAFile,
+	}
+
+	if authzFile != "" {
+		a, err := LoadRoleAuthorizer(authzFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to load authorization config")
+		}
+
+		c.Authorizer = a
+	}
+
+	return c, nil
+}
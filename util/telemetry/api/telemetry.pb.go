@@ -0,0 +1,252 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: github.com/bio-routing/bio-rd/util/telemetry/api/telemetry.proto
+
+package api
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Update struct {
+	Source               string   `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	TimestampNs          int64    `protobuf:"varint,3,opt,name=timestamp_ns,json=timestampNs,proto3" json:"timestamp_ns,omitempty"`
+	JsonValue            []byte   `protobuf:"bytes,4,opt,name=json_value,json=jsonValue,proto3" json:"json_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Update) Reset()         { *m = Update{} }
+func (m *Update) String() string { return proto.CompactTextString(m) }
+func (*Update) ProtoMessage()    {}
+func (*Update) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c46b4fe060346120, []int{0}
+}
+
+func (m *Update) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Update.Unmarshal(m, b)
+}
+func (m *Update) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Update.Marshal(b, m, deterministic)
+}
+func (m *Update) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Update.Merge(m, src)
+}
+func (m *Update) XXX_Size() int {
+	return xxx_messageInfo_Update.Size(m)
+}
+func (m *Update) XXX_DiscardUnknown() {
+	xxx_messageInfo_Update.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Update proto.InternalMessageInfo
+
+func (m *Update) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Update) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Update) GetTimestampNs() int64 {
+	if m != nil {
+		return m.TimestampNs
+	}
+	return 0
+}
+
+func (m *Update) GetJsonValue() []byte {
+	if m != nil {
+		return m.JsonValue
+	}
+	return nil
+}
+
+type PublishResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublishResponse) Reset()         { *m = PublishResponse{} }
+func (m *PublishResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishResponse) ProtoMessage()    {}
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c46b4fe060346120, []int{1}
+}
+
+func (m *PublishResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishResponse.Unmarshal(m, b)
+}
+func (m *PublishResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishResponse.Marshal(b, m, deterministic)
+}
+func (m *PublishResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishResponse.Merge(m, src)
+}
+func (m *PublishResponse) XXX_Size() int {
+	return xxx_messageInfo_PublishResponse.Size(m)
+}
+func (m *PublishResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*Update)(nil), "bio.telemetry.Update")
+	proto.RegisterType((*PublishResponse)(nil), "bio.telemetry.PublishResponse")
+}
+
+func init() {
+	proto.RegisterFile("github.com/bio-routing/bio-rd/util/telemetry/api/telemetry.proto", fileDescriptor_c46b4fe060346120)
+}
+
+var fileDescriptor_c46b4fe060346120 = []byte{
+	// 243 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x90, 0x41, 0x4b, 0xc3, 0x40,
+	0x10, 0x85, 0x8d, 0x2d, 0x95, 0x8e, 0x15, 0x71, 0x41, 0x09, 0x82, 0x12, 0x73, 0xca, 0xc5, 0x8d,
+	0xd4, 0x3f, 0x20, 0xea, 0x59, 0x24, 0xa8, 0x87, 0x5e, 0xca, 0x6e, 0x3a, 0x34, 0x2b, 0x9b, 0xcc,
+	0xb2, 0x3b, 0x5b, 0xf0, 0xdf, 0x4b, 0xd3, 0x5a, 0xb1, 0x37, 0x6f, 0xef, 0x7d, 0x0c, 0xc3, 0xc7,
+	0x83, 0x87, 0xa5, 0xe1, 0x26, 0x6a, 0x59, 0x53, 0x5b, 0x6a, 0x43, 0xb7, 0x9e, 0x22, 0x9b, 0x6e,
+	0xb9, 0xc9, 0x8b, 0x32, 0xb2, 0xb1, 0x25, 0xa3, 0xc5, 0x16, 0xd9, 0x7f, 0x95, 0xca, 0x99, 0xdf,
+	0x26, 0x9d, 0x27, 0x26, 0x71, 0xa2, 0x0d, 0xc9, 0x1d, 0xcc, 0x57, 0x30, 0x7a, 0x77, 0x0b, 0xc5,
+	0x28, 0x2e, 0x60, 0x14, 0x28, 0xfa, 0x1a, 0xd3, 0x24, 0x4b, 0x8a, 0x71, 0xb5, 0x6d, 0x42, 0xc0,
+	0xd0, 0x29, 0x6e, 0xd2, 0xc3, 0x9e, 0xf6, 0x59, 0xdc, 0xc0, 0x84, 0x4d, 0x8b, 0x81, 0x55, 0xeb,
+	0xe6, 0x5d, 0x48, 0x07, 0x59, 0x52, 0x0c, 0xaa, 0xe3, 0x1d, 0x7b, 0x09, 0xe2, 0x0a, 0xe0, 0x33,
+	0x50, 0x37, 0x5f, 0x29, 0x1b, 0x31, 0x1d, 0x66, 0x49, 0x31, 0xa9, 0xc6, 0x6b, 0xf2, 0xb1, 0x06,
+	0xf9, 0x19, 0x9c, 0xbe, 0x46, 0x6d, 0x4d, 0x68, 0x2a, 0x0c, 0x8e, 0xba, 0x80, 0xd3, 0x19, 0x88,
+	0xb7, 0x1f, 0xaf, 0x27, 0xb2, 0x16, 0x6b, 0x26, 0x2f, 0x9e, 0xe1, 0x68, 0x7b, 0x28, 0xce, 0xe5,
+	0x1f, 0x77, 0xb9, 0x11, 0xbf, 0xbc, 0xde, 0xc3, 0x7b, 0x7f, 0xf3, 0x83, 0x22, 0x79, 0x9c, 0xce,
+	0xee, 0xfe, 0xbb, 0x9c, 0x1e, 0xf5, 0x83, 0xdd, 0x7f, 0x0f, 0x00, 0xd8, 0x2c, 0xb3, 0x52, 0x74,
+	0x01, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TelemetryCollectorClient is the client API for TelemetryCollector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TelemetryCollectorClient interface {
+	Publish(ctx context.Context, opts ...grpc.CallOption) (TelemetryCollector_PublishClient, error)
+}
+
+type telemetryCollectorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTelemetryCollectorClient(cc *grpc.ClientConn) TelemetryCollectorClient {
+	return &telemetryCollectorClient{cc}
+}
+
+func (c *telemetryCollectorClient) Publish(ctx context.Context, opts ...grpc.CallOption) (TelemetryCollector_PublishClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TelemetryCollector_serviceDesc.Streams[0], "/bio.telemetry.TelemetryCollector/Publish", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &telemetryCollectorPublishClient{stream}
+	return x, nil
+}
+
+type TelemetryCollector_PublishClient interface {
+	Send(*Update) error
+	CloseAndRecv() (*PublishResponse, error)
+	grpc.ClientStream
+}
+
+type telemetryCollectorPublishClient struct {
+	grpc.ClientStream
+}
+
+func (x *telemetryCollectorPublishClient) Send(m *Update) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *telemetryCollectorPublishClient) CloseAndRecv() (*PublishResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PublishResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TelemetryCollectorServer is the server API for TelemetryCollector service.
+type TelemetryCollectorServer interface {
+	Publish(TelemetryCollector_PublishServer) error
+}
+
+func RegisterTelemetryCollectorServer(s *grpc.Server, srv TelemetryCollectorServer) {
+	s.RegisterService(&_TelemetryCollector_serviceDesc, srv)
+}
+
+func _TelemetryCollector_Publish_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TelemetryCollectorServer).Publish(&telemetryCollectorPublishServer{stream})
+}
+
+type TelemetryCollector_PublishServer interface {
+	SendAndClose(*PublishResponse) error
+	Recv() (*Update, error)
+	grpc.ServerStream
+}
+
+type telemetryCollectorPublishServer struct {
+	grpc.ServerStream
+}
+
+func (x *telemetryCollectorPublishServer) SendAndClose(m *PublishResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *telemetryCollectorPublishServer) Recv() (*Update, error) {
+	m := new(Update)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _TelemetryCollector_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bio.telemetry.TelemetryCollector",
+	HandlerType: (*TelemetryCollectorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Publish",
+			Handler:       _TelemetryCollector_Publish_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "github.com/bio-routing/bio-rd/util/telemetry/api/telemetry.proto",
+}
@@ -0,0 +1,220 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bio-routing/bio-rd/util/telemetry/api"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultSampleInterval    = time.Second * 10
+	defaultReconnectInterval = time.Second * 5
+)
+
+// SourceFunc returns the current state of a telemetry path. The result is JSON encoded.
+type SourceFunc func() interface{}
+
+// Subscription selects the paths published to a collector
+type Subscription struct {
+	// Path selects all sources equal to or below Path. An empty Path selects all sources.
+	Path string
+
+	// SampleInterval is the interval sources are sampled in
+	SampleInterval time.Duration
+
+	// OnChange suppresses samples equal to the last one sent
+	OnChange bool
+}
+
+// Collector is a telemetry collector state is pushed to
+type Collector struct {
+	Address       string
+	DialOptions   []grpc.DialOption
+	Subscriptions []Subscription
+}
+
+// Publisher pushes state to telemetry collectors
+type Publisher struct {
+	source            string
+	reconnectInterval time.Duration
+	sourcesMu         sync.RWMutex
+	sources           map[string]SourceFunc
+	stopCh            chan struct{}
+	wg                sync.WaitGroup
+}
+
+// NewPublisher creates a new publisher. source identifies this instance to collectors.
+func NewPublisher(source string) *Publisher {
+	return &Publisher{
+		source:            source,
+		reconnectInterval: defaultReconnectInterval,
+		sources:           make(map[string]SourceFunc),
+		stopCh:            make(chan struct{}),
+	}
+}
+
+// AddSource registers the state source for path
+func (p *Publisher) AddSource(path string, f SourceFunc) {
+	p.sourcesMu.Lock()
+	defer p.sourcesMu.Unlock()
+
+	p.sources[path] = f
+}
+
+// Start starts publishing to collectors
+func (p *Publisher) Start(collectors []Collector) {
+	for _, c := range collectors {
+		p.wg.Add(1)
+		go p.runCollector(c)
+	}
+}
+
+// Stop stops publishing and closes all collector connections
+func (p *Publisher) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *Publisher) stopped() bool {
+	select {
+	case <-p.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Publisher) runCollector(c Collector) {
+	defer p.wg.Done()
+
+	for {
+		err := p.publish(c)
+		if p.stopped() {
+			return
+		}
+
+		log.WithError(err).WithField("collector", c.Address).Warning("Telemetry stream failed. Reconnecting.")
+
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(p.reconnectInterval):
+		}
+	}
+}
+
+func (p *Publisher) publish(c Collector) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-p.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	conn, err := grpc.DialContext(ctx, c.Address, c.DialOptions...)
+	if err != nil {
+		return errors.Wrap(err, "Unable to dial")
+	}
+	defer conn.Close()
+
+	stream, err := api.NewTelemetryCollectorClient(conn).Publish(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open stream")
+	}
+
+	updates := make(chan *api.Update)
+	for _, s := range c.Subscriptions {
+		go p.sample(ctx, s, updates)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u := <-updates:
+			err := stream.Send(u)
+			if err != nil {
+				return errors.Wrap(err, "Unable to send update")
+			}
+		}
+	}
+}
+
+func (p *Publisher) sample(ctx context.Context, s Subscription, out chan<- *api.Update) {
+	interval := s.SampleInterval
+	if interval == 0 {
+		interval = defaultSampleInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	last := make(map[string][]byte)
+	for {
+		for _, path := range p.paths(s.Path) {
+			v, err := json.Marshal(p.sourceFunc(path)())
+			if err != nil {
+				log.WithError(err).WithField("path", path).Error("Unable to encode telemetry data")
+				continue
+			}
+
+			if s.OnChange && bytes.Equal(last[path], v) {
+				continue
+			}
+			last[path] = v
+
+			select {
+			case out <- &api.Update{
+				Source:      p.source,
+				Path:        path,
+				TimestampNs: time.Now().UnixNano(),
+				JsonValue:   v,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// paths returns all source paths selected by prefix
+func (p *Publisher) paths(prefix string) []string {
+	p.sourcesMu.RLock()
+	defer p.sourcesMu.RUnlock()
+
+	ret := make([]string, 0)
+	for path := range p.sources {
+		if prefix == "" || path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			ret = append(ret, path)
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+func (p *Publisher) sourceFunc(path string) SourceFunc {
+	p.sourcesMu.RLock()
+	defer p.sourcesMu.RUnlock()
+
+	return p.sources[path]
+}
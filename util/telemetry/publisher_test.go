@@ -0,0 +1,180 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bio-routing/bio-rd/util/telemetry/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testCollector struct {
+	updates chan *api.Update
+}
+
+func (c *testCollector) Publish(s api.TelemetryCollector_PublishServer) error {
+	for {
+		u, err := s.Recv()
+		if err != nil {
+			return err
+		}
+
+		c.updates <- u
+	}
+}
+
+func startTestCollector(t *testing.T) (*testCollector, []grpc.DialOption, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	c := &testCollector{
+		updates: make(chan *api.Update, 100),
+	}
+
+	s := grpc.NewServer()
+	api.RegisterTelemetryCollectorServer(s, c)
+	go s.Serve(lis)
+
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+	}
+
+	return c, opts, s.Stop
+}
+
+func (c *testCollector) next(t *testing.T) *api.Update {
+	select {
+	case u := <-c.updates:
+		return u
+	case <-time.After(time.Second * 5):
+		t.Fatalf("No update received")
+	}
+
+	return nil
+}
+
+func (c *testCollector) none(t *testing.T, d time.Duration) {
+	select {
+	case u := <-c.updates:
+		t.Fatalf("Unexpected update: %v", u)
+	case <-time.After(d):
+	}
+}
+
+func TestPublisherOnChange(t *testing.T) {
+	c, opts, stop := startTestCollector(t)
+	defer stop()
+
+	var counter int32
+	p := NewPublisher("router01")
+	p.AddSource("bgp/peers", func() interface{} {
+		return atomic.LoadInt32(&counter)
+	})
+	p.AddSource("rib/summary", func() interface{} {
+		return "rib"
+	})
+	p.Start([]Collector{
+		{
+			Address:     "bufnet",
+			DialOptions: opts,
+			Subscriptions: []Subscription{
+				{
+					Path:           "bgp",
+					SampleInterval: time.Millisecond * 10,
+					OnChange:       true,
+				},
+			},
+		},
+	})
+	defer p.Stop()
+
+	u := c.next(t)
+	assert.Equal(t, "router01", u.Source)
+	assert.Equal(t, "bgp/peers", u.Path)
+	assert.Equal(t, "0", string(u.JsonValue))
+	assert.NotZero(t, u.TimestampNs)
+
+	c.none(t, time.Millisecond*100)
+
+	atomic.StoreInt32(&counter, 1)
+	u = c.next(t)
+	assert.Equal(t, "bgp/peers", u.Path)
+	assert.Equal(t, "1", string(u.JsonValue))
+}
+
+func TestPublisherSampling(t *testing.T) {
+	c, opts, stop := startTestCollector(t)
+	defer stop()
+
+	p := NewPublisher("router01")
+	p.AddSource("rib/summary", func() interface{} {
+		return map[string]int{"inet.0": 1}
+	})
+	p.Start([]Collector{
+		{
+			Address:     "bufnet",
+			DialOptions: opts,
+			Subscriptions: []Subscription{
+				{
+					SampleInterval: time.Millisecond * 10,
+				},
+			},
+		},
+	})
+	defer p.Stop()
+
+	for i := 0; i < 3; i++ {
+		u := c.next(t)
+		assert.Equal(t, "rib/summary", u.Path)
+		assert.Equal(t, `{"inet.0":1}`, string(u.JsonValue))
+	}
+}
+
+func TestPaths(t *testing.T) {
+	p := NewPublisher("router01")
+	for _, path := range []string{"bgp/peers", "bgp/peers/metrics", "bgpfoo", "rib/summary"} {
+		p.AddSource(path, nil)
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{
+			name:     "All",
+			prefix:   "",
+			expected: []string{"bgp/peers", "bgp/peers/metrics", "bgpfoo", "rib/summary"},
+		},
+		{
+			name:     "Subtree",
+			prefix:   "bgp",
+			expected: []string{"bgp/peers", "bgp/peers/metrics"},
+		},
+		{
+			name:     "Subtree with trailing slash",
+			prefix:   "bgp/",
+			expected: []string{"bgp/peers", "bgp/peers/metrics"},
+		},
+		{
+			name:     "Exact",
+			prefix:   "rib/summary",
+			expected: []string{"rib/summary"},
+		},
+		{
+			name:     "None",
+			prefix:   "isis",
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, p.paths(test.prefix), test.name)
+	}
+}